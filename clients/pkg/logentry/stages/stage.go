@@ -15,27 +15,28 @@ import (
 )
 
 const (
-	StageTypeJSON         = "json"
-	StageTypeLogfmt       = "logfmt"
-	StageTypeRegex        = "regex"
-	StageTypeReplace      = "replace"
-	StageTypeMetric       = "metrics"
-	StageTypeLabel        = "labels"
-	StageTypeLabelDrop    = "labeldrop"
-	StageTypeTimestamp    = "timestamp"
-	StageTypeOutput       = "output"
-	StageTypeDocker       = "docker"
-	StageTypeCRI          = "cri"
-	StageTypeMatch        = "match"
-	StageTypeTemplate     = "template"
-	StageTypePipeline     = "pipeline"
-	StageTypeTenant       = "tenant"
-	StageTypeDrop         = "drop"
-	StageTypeLimit        = "limit"
-	StageTypeMultiline    = "multiline"
-	StageTypePack         = "pack"
-	StageTypeLabelAllow   = "labelallow"
-	StageTypeStaticLabels = "static_labels"
+	StageTypeJSON               = "json"
+	StageTypeLogfmt             = "logfmt"
+	StageTypeRegex              = "regex"
+	StageTypeReplace            = "replace"
+	StageTypeMetric             = "metrics"
+	StageTypeLabel              = "labels"
+	StageTypeLabelDrop          = "labeldrop"
+	StageTypeTimestamp          = "timestamp"
+	StageTypeOutput             = "output"
+	StageTypeDocker             = "docker"
+	StageTypeCRI                = "cri"
+	StageTypeMatch              = "match"
+	StageTypeTemplate           = "template"
+	StageTypePipeline           = "pipeline"
+	StageTypeTenant             = "tenant"
+	StageTypeDrop               = "drop"
+	StageTypeLimit              = "limit"
+	StageTypeMultiline          = "multiline"
+	StageTypePack               = "pack"
+	StageTypeLabelAllow         = "labelallow"
+	StageTypeStaticLabels       = "static_labels"
+	StageTypeStructuredMetadata = "structured_metadata"
 )
 
 // Processor takes an existing set of labels, timestamp and log entry and returns either a possibly mutated
@@ -209,6 +210,11 @@ func New(logger log.Logger, jobName *string, stageType string,
 		if err != nil {
 			return nil, err
 		}
+	case StageTypeStructuredMetadata:
+		s, err = newStructuredMetadataStage(logger, cfg)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, errors.Errorf("Unknown stage type: %s", stageType)
 	}
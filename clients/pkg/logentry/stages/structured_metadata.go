@@ -0,0 +1,83 @@
+package stages
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+const (
+	ErrEmptyStructuredMetadataStageConfig = "structured_metadata stage config cannot be empty"
+)
+
+// newStructuredMetadataStage creates a new stage attaching extracted data to the log entry as structured metadata.
+// It is configured like the labels stage, but the resulting labels are not part of the stream labels: they are
+// stored alongside each log entry as non-indexed labels, so high cardinality values like trace ids don't create
+// new streams.
+func newStructuredMetadataStage(logger log.Logger, configs interface{}) (Stage, error) {
+	cfgs := &LabelsConfig{}
+	err := mapstructure.Decode(configs, cfgs)
+	if err != nil {
+		return nil, err
+	}
+	if len(*cfgs) == 0 {
+		return nil, errors.New(ErrEmptyStructuredMetadataStageConfig)
+	}
+	err = validateLabelsConfig(*cfgs)
+	if err != nil {
+		return nil, err
+	}
+	return &structuredMetadataStage{
+		cfgs:   *cfgs,
+		logger: logger,
+	}, nil
+}
+
+// structuredMetadataStage sets the structured metadata of log entries from extracted data
+type structuredMetadataStage struct {
+	cfgs   LabelsConfig
+	logger log.Logger
+}
+
+// Run implements Stage
+func (s *structuredMetadataStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		for name, src := range s.cfgs {
+			value, ok := e.Extracted[*src]
+			if !ok {
+				continue
+			}
+			str, err := getString(value)
+			if err != nil {
+				if Debug {
+					level.Debug(s.logger).Log("msg", "failed to convert extracted structured metadata value to string", "err", err, "type", reflect.TypeOf(value))
+				}
+				continue
+			}
+			e.NonIndexedLabels = setNonIndexedLabel(e.NonIndexedLabels, name, str)
+		}
+		return e
+	})
+}
+
+// setNonIndexedLabel sets the value of the label name, replacing any previous value.
+// The labels are copied, as they may be shared with other entries.
+func setNonIndexedLabel(lbs []logproto.LabelAdapter, name, value string) []logproto.LabelAdapter {
+	res := make([]logproto.LabelAdapter, 0, len(lbs)+1)
+	for _, l := range lbs {
+		if l.Name != name {
+			res = append(res, l)
+		}
+	}
+	return append(res, logproto.LabelAdapter{Name: name, Value: value})
+}
+
+// Name implements Stage
+func (s *structuredMetadataStage) Name() string {
+	return StageTypeStructuredMetadata
+}
@@ -0,0 +1,71 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var testStructuredMetadataYaml = `
+pipeline_stages:
+- json:
+    expressions:
+      level:
+      trace_id:
+      request: request_id
+- labels:
+    level:
+- structured_metadata:
+    trace_id:
+    request_id: request
+`
+
+var testStructuredMetadataLogLine = `
+{
+	"level" : "WARN",
+	"trace_id": "5b8efff798038103d269b633813fc60c",
+	"request_id": "42"
+}
+`
+
+func TestStructuredMetadataPipeline(t *testing.T) {
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testStructuredMetadataYaml), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, testStructuredMetadataLogLine, time.Now()))[0]
+	assert.Equal(t, model.LabelSet{"level": "WARN"}, out.Labels)
+	assert.ElementsMatch(t, []logproto.LabelAdapter{
+		{Name: "trace_id", Value: "5b8efff798038103d269b633813fc60c"},
+		{Name: "request_id", Value: "42"},
+	}, out.NonIndexedLabels)
+}
+
+func TestStructuredMetadataStage(t *testing.T) {
+	src := "source"
+	st, err := newStructuredMetadataStage(util_log.Logger, LabelsConfig{"trace_id": &src})
+	require.NoError(t, err)
+
+	// Existing structured metadata of the same name is replaced, and entries without the extracted key are left as is.
+	e := newEntry(map[string]interface{}{"source": "new"}, nil, "", time.Now())
+	e.NonIndexedLabels = []logproto.LabelAdapter{{Name: "trace_id", Value: "old"}, {Name: "other", Value: "value"}}
+	missing := newEntry(map[string]interface{}{}, nil, "", time.Now())
+
+	out := processEntries(st, e, missing)
+	assert.Equal(t, []logproto.LabelAdapter{{Name: "other", Value: "value"}, {Name: "trace_id", Value: "new"}}, out[0].NonIndexedLabels)
+	assert.Nil(t, out[1].NonIndexedLabels)
+}
+
+func TestStructuredMetadataStage_InvalidConfig(t *testing.T) {
+	_, err := newStructuredMetadataStage(util_log.Logger, nil)
+	assert.EqualError(t, err, ErrEmptyStructuredMetadataStageConfig)
+
+	_, err = newStructuredMetadataStage(util_log.Logger, LabelsConfig{"invalid-name": nil})
+	assert.Error(t, err)
+}
@@ -33,18 +33,20 @@ import (
 
 // Config describes a job to scrape.
 type Config struct {
-	JobName           string                     `mapstructure:"job_name,omitempty" yaml:"job_name,omitempty"`
-	PipelineStages    stages.PipelineStages      `mapstructure:"pipeline_stages,omitempty" yaml:"pipeline_stages,omitempty"`
-	JournalConfig     *JournalTargetConfig       `mapstructure:"journal,omitempty" yaml:"journal,omitempty"`
-	SyslogConfig      *SyslogTargetConfig        `mapstructure:"syslog,omitempty" yaml:"syslog,omitempty"`
-	GcplogConfig      *GcplogTargetConfig        `mapstructure:"gcplog,omitempty" yaml:"gcplog,omitempty"`
-	PushConfig        *PushTargetConfig          `mapstructure:"loki_push_api,omitempty" yaml:"loki_push_api,omitempty"`
-	WindowsConfig     *WindowsEventsTargetConfig `mapstructure:"windows_events,omitempty" yaml:"windows_events,omitempty"`
-	KafkaConfig       *KafkaTargetConfig         `mapstructure:"kafka,omitempty" yaml:"kafka,omitempty"`
-	GelfConfig        *GelfTargetConfig          `mapstructure:"gelf,omitempty" yaml:"gelf,omitempty"`
-	CloudflareConfig  *CloudflareConfig          `mapstructure:"cloudflare,omitempty" yaml:"cloudflare,omitempty"`
-	HerokuDrainConfig *HerokuDrainTargetConfig   `mapstructure:"heroku_drain,omitempty" yaml:"heroku_drain,omitempty"`
-	RelabelConfigs    []*relabel.Config          `mapstructure:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
+	JobName                string                        `mapstructure:"job_name,omitempty" yaml:"job_name,omitempty"`
+	PipelineStages         stages.PipelineStages         `mapstructure:"pipeline_stages,omitempty" yaml:"pipeline_stages,omitempty"`
+	JournalConfig          *JournalTargetConfig          `mapstructure:"journal,omitempty" yaml:"journal,omitempty"`
+	SyslogConfig           *SyslogTargetConfig           `mapstructure:"syslog,omitempty" yaml:"syslog,omitempty"`
+	GcplogConfig           *GcplogTargetConfig           `mapstructure:"gcplog,omitempty" yaml:"gcplog,omitempty"`
+	PushConfig             *PushTargetConfig             `mapstructure:"loki_push_api,omitempty" yaml:"loki_push_api,omitempty"`
+	WindowsConfig          *WindowsEventsTargetConfig    `mapstructure:"windows_events,omitempty" yaml:"windows_events,omitempty"`
+	KafkaConfig            *KafkaTargetConfig            `mapstructure:"kafka,omitempty" yaml:"kafka,omitempty"`
+	GelfConfig             *GelfTargetConfig             `mapstructure:"gelf,omitempty" yaml:"gelf,omitempty"`
+	CloudflareConfig       *CloudflareConfig             `mapstructure:"cloudflare,omitempty" yaml:"cloudflare,omitempty"`
+	HerokuDrainConfig      *HerokuDrainTargetConfig      `mapstructure:"heroku_drain,omitempty" yaml:"heroku_drain,omitempty"`
+	KubernetesEventsConfig *KubernetesEventsTargetConfig `mapstructure:"kubernetes_events,omitempty" yaml:"kubernetes_events,omitempty"`
+	OTLPConfig             *OTLPTargetConfig             `mapstructure:"otlp,omitempty" yaml:"otlp,omitempty"`
+	RelabelConfigs         []*relabel.Config             `mapstructure:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
 	// List of Docker service discovery configurations.
 	DockerSDConfigs        []*moby.DockerSDConfig `mapstructure:"docker_sd_configs,omitempty" yaml:"docker_sd_configs,omitempty"`
 	ServiceDiscoveryConfig ServiceDiscoveryConfig `mapstructure:",squash" yaml:",inline"`
@@ -212,6 +214,11 @@ type WindowsEventsTargetConfig struct {
 	// Example: "Application"
 	EventlogName string `yaml:"eventlog_name"`
 
+	// EventlogNames subscribes to several eventlogs within the same target, each with its own bookmark.
+	// Takes precedence over EventlogName and can't be combined with an XML query.
+	// Example: ["Application", "System"]
+	EventlogNames []string `yaml:"eventlog_names"`
+
 	// xpath_query can be in defined short form like "Event/System[EventID=999]"
 	// or you can form a XML Query. Refer to the Consuming Events article:
 	// https://docs.microsoft.com/en-us/windows/win32/wes/consuming-events
@@ -220,11 +227,21 @@ type WindowsEventsTargetConfig struct {
 	// and then copying resulting XML here
 	Query string `yaml:"xpath_query"`
 
+	// Keywords only subscribes to events having at least one of the given keywords. Keywords are either
+	// standard keyword names like "Audit Failure" or hexadecimal masks like "0x10000000000000".
+	// They can't be combined with a custom xpath_query.
+	Keywords []string `yaml:"keywords"`
+
+	// LineFormat sets the format of the log lines: "json" serializes the event in JSON, "xml" uses the
+	// XML rendering of the event and "message" only keeps its rendered message. Defaults to "json".
+	LineFormat string `yaml:"line_format"`
+
 	// UseIncomingTimestamp sets the timestamp to the incoming windows messages
 	// timestamp if it's set.
 	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
 
 	// BookmarkPath sets the bookmark location on the filesystem.
+	// When empty, the bookmark of each eventlog is saved in the positions file.
 	// The bookmark contains the current position of the target in XML.
 	// When restarting or rollingout promtail, the target will continue to scrape events where it left off based on the bookmark position.
 	// The position is updated after each entry processed.
@@ -387,6 +404,38 @@ type HerokuDrainTargetConfig struct {
 	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
 }
 
+// KubernetesEventsTargetConfig describes a scrape config to watch the events of a Kubernetes cluster.
+type KubernetesEventsTargetConfig struct {
+	// KubeConfig is the path to the kubeconfig file used to connect to the cluster.
+	// If empty, the in-cluster configuration is used.
+	KubeConfig string `yaml:"kubeconfig_file"`
+
+	// Namespaces restricts the watched events to these namespaces. If empty, events of all namespaces are watched.
+	Namespaces []string `yaml:"namespaces"`
+
+	// Labels optionally holds labels to associate with each event.
+	Labels model.LabelSet `yaml:"labels"`
+
+	// UseIncomingTimestamp sets the timestamp of the log entry to the time the event was last observed. If false,
+	// promtail will assign the current timestamp to the log entry when it was processed.
+	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
+}
+
+// OTLPTargetConfig describes a scrape config that receives logs from OpenTelemetry SDKs and collectors,
+// over both OTLP/gRPC and OTLP/HTTP.
+type OTLPTargetConfig struct {
+	// Server is the weaveworks server config for listening connections. OTLP/gRPC is served on the
+	// gRPC listener and OTLP/HTTP on the HTTP one.
+	Server server.Config `yaml:"server"`
+
+	// Labels optionally holds labels to associate with each received log record.
+	Labels model.LabelSet `yaml:"labels"`
+
+	// UseIncomingTimestamp sets the timestamp of the log entry to the one of the log record. If false,
+	// promtail will assign the current timestamp to the log entry when it was processed.
+	UseIncomingTimestamp bool `yaml:"use_incoming_timestamp"`
+}
+
 // PushTargetConfig describes a scrape config that listens for Loki push messages.
 type PushTargetConfig struct {
 	// Server is the weaveworks server config for listening connections
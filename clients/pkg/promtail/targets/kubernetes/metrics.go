@@ -0,0 +1,45 @@
+package kubernetes
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds a set of Kubernetes events target metrics.
+type Metrics struct {
+	reg prometheus.Registerer
+
+	Entries        prometheus.Counter
+	WatchErrors    prometheus.Counter
+	ExpiredWatches prometheus.Counter
+}
+
+// NewMetrics creates a new set of Kubernetes events target metrics. If reg is non-nil, the
+// metrics will be registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	var m Metrics
+	m.reg = reg
+
+	m.Entries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_events_target_entries_total",
+		Help:      "Total number of successful entries sent via the Kubernetes events target",
+	})
+	m.WatchErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_events_target_watch_errors_total",
+		Help:      "Total number of errors watching the Kubernetes events API",
+	})
+	m.ExpiredWatches = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "kubernetes_events_target_expired_resource_versions_total",
+		Help:      "Total number of times the saved resource version expired, so that the watch restarted from the current events and events may have been missed",
+	})
+
+	if reg != nil {
+		reg.MustRegister(
+			m.Entries,
+			m.WatchErrors,
+			m.ExpiredWatches,
+		)
+	}
+
+	return &m
+}
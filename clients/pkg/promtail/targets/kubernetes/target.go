@@ -0,0 +1,352 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/common/model"
+	"go.uber.org/atomic"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/target"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+var defaultBackoff = backoff.Config{
+	MinBackoff: 1 * time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// Client lists and watches the events of a namespace, or of all namespaces if the namespace is empty.
+type Client interface {
+	List(ctx context.Context, namespace string, opts metav1.ListOptions) (*corev1.EventList, error)
+	Watch(ctx context.Context, namespace string, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+type clientset struct {
+	kubernetes.Interface
+}
+
+func (c clientset) List(ctx context.Context, namespace string, opts metav1.ListOptions) (*corev1.EventList, error) {
+	return c.CoreV1().Events(namespace).List(ctx, opts)
+}
+
+func (c clientset) Watch(ctx context.Context, namespace string, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.CoreV1().Events(namespace).Watch(ctx, opts)
+}
+
+// getClient returns a Client of the cluster configured in kubeconfig, or of the cluster promtail runs in.
+var getClient = func(kubeconfig string) (Client, error) {
+	var (
+		cfg *rest.Config
+		err error
+	)
+	if kubeconfig == "" {
+		cfg, err = rest.InClusterConfig()
+	} else {
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return clientset{c}, nil
+}
+
+// Target watches the Kubernetes events API and forwards each added or updated event as a log line.
+// The resource version of the last event is saved in the positions file, so that promtail resumes
+// watching where it stopped after a restart.
+type Target struct {
+	logger    log.Logger
+	handler   api.EntryHandler
+	positions positions.Positions
+	jobName   string
+	config    *scrapeconfig.KubernetesEventsTargetConfig
+	metrics   *Metrics
+
+	client  Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running *atomic.Bool
+}
+
+// NewTarget creates a new Target watching the events of the configured namespaces.
+func NewTarget(
+	metrics *Metrics,
+	logger log.Logger,
+	handler api.EntryHandler,
+	position positions.Positions,
+	jobName string,
+	config *scrapeconfig.KubernetesEventsTargetConfig,
+) (*Target, error) {
+	client, err := getClient(config.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Target{
+		logger:    logger,
+		handler:   handler,
+		positions: position,
+		jobName:   jobName,
+		config:    config,
+		metrics:   metrics,
+
+		client:  client,
+		ctx:     ctx,
+		cancel:  cancel,
+		running: atomic.NewBool(false),
+	}
+	t.start()
+	return t, nil
+}
+
+func (t *Target) namespaces() []string {
+	if len(t.config.Namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return t.config.Namespaces
+}
+
+func (t *Target) start() {
+	t.running.Store(true)
+	for _, namespace := range t.namespaces() {
+		t.wg.Add(1)
+		go func(namespace string) {
+			defer t.wg.Done()
+			t.run(namespace)
+		}(namespace)
+	}
+}
+
+// positionKey is the key of the resource version of the last event of namespace in the positions file.
+func (t *Target) positionKey(namespace string) string {
+	key := "kubernetes_events-" + t.jobName
+	if namespace != metav1.NamespaceAll {
+		key += "-" + namespace
+	}
+	return positions.CursorKey(key)
+}
+
+// run watches the events of namespace until the target is stopped, restarting the watch on errors.
+func (t *Target) run(namespace string) {
+	logger := log.With(t.logger, "namespace", namespace)
+	resourceVersion := t.positions.GetString(t.positionKey(namespace))
+	backoff := backoff.New(t.ctx, defaultBackoff)
+
+	for t.ctx.Err() == nil {
+		if resourceVersion == "" {
+			// Without a saved position start from the current events, as the older ones may already
+			// have been sent before the positions file was lost.
+			list, err := t.client.List(t.ctx, namespace, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				level.Error(logger).Log("msg", "failed to list Kubernetes events", "err", err)
+				t.metrics.WatchErrors.Inc()
+				backoff.Wait()
+				continue
+			}
+			resourceVersion = list.ResourceVersion
+		}
+
+		w, err := t.client.Watch(t.ctx, namespace, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			level.Error(logger).Log("msg", "failed to watch Kubernetes events", "err", err)
+			t.metrics.WatchErrors.Inc()
+			backoff.Wait()
+			continue
+		}
+		backoff.Reset()
+
+		resourceVersion, err = t.process(namespace, w, resourceVersion)
+		w.Stop()
+		if err == nil {
+			continue
+		}
+		if apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			level.Warn(logger).Log("msg", "resource version of Kubernetes events expired, restarting from the current events", "resource_version", resourceVersion, "err", err)
+			t.metrics.ExpiredWatches.Inc()
+			resourceVersion = ""
+			continue
+		}
+		level.Error(logger).Log("msg", "error watching Kubernetes events", "err", err)
+		t.metrics.WatchErrors.Inc()
+		backoff.Wait()
+	}
+}
+
+// process forwards the events received from w until it is closed, and returns the last resource version seen.
+func (t *Target) process(namespace string, w watch.Interface, resourceVersion string) (string, error) {
+	for {
+		var (
+			ev watch.Event
+			ok bool
+		)
+		select {
+		case <-t.ctx.Done():
+			return resourceVersion, nil
+		case ev, ok = <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+		}
+
+		switch ev.Type {
+		case watch.Error:
+			return resourceVersion, apierrors.FromObject(ev.Object)
+		case watch.Added, watch.Modified:
+			event, ok := ev.Object.(*corev1.Event)
+			if !ok {
+				continue
+			}
+			entry, err := t.entry(event)
+			if err != nil {
+				level.Warn(t.logger).Log("msg", "failed to format Kubernetes event", "err", err)
+				continue
+			}
+			select {
+			case t.handler.Chan() <- entry:
+				t.metrics.Entries.Inc()
+			case <-t.ctx.Done():
+				return resourceVersion, nil
+			}
+		}
+
+		// Bookmarks and deletions still move the resource version forward.
+		if obj, err := meta.Accessor(ev.Object); err == nil && obj.GetResourceVersion() != "" {
+			resourceVersion = obj.GetResourceVersion()
+			t.positions.PutString(t.positionKey(namespace), resourceVersion)
+		}
+	}
+}
+
+type eventLine struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Message   string `json:"message"`
+	Count     int32  `json:"count,omitempty"`
+	Component string `json:"component,omitempty"`
+	Host      string `json:"host,omitempty"`
+}
+
+// entry converts event to a log entry labeled with its namespace, reason and involved object.
+func (t *Target) entry(event *corev1.Event) (api.Entry, error) {
+	line, err := json.Marshal(eventLine{
+		Kind:      event.InvolvedObject.Kind,
+		Name:      event.InvolvedObject.Name,
+		Reason:    event.Reason,
+		Type:      event.Type,
+		Message:   event.Message,
+		Count:     event.Count,
+		Component: event.Source.Component,
+		Host:      event.Source.Host,
+	})
+	if err != nil {
+		return api.Entry{}, err
+	}
+
+	labels := t.config.Labels.Clone()
+	for name, value := range map[model.LabelName]string{
+		"namespace":            event.Namespace,
+		"reason":               event.Reason,
+		"type":                 event.Type,
+		"involved_object_kind": event.InvolvedObject.Kind,
+		"involved_object_name": event.InvolvedObject.Name,
+	} {
+		if value != "" {
+			labels[name] = model.LabelValue(value)
+		}
+	}
+
+	ts := time.Now()
+	if t.config.UseIncomingTimestamp {
+		if incoming := eventTimestamp(event); !incoming.IsZero() {
+			ts = incoming
+		}
+	}
+
+	return api.Entry{
+		Labels: labels,
+		Entry: logproto.Entry{
+			Timestamp: ts,
+			Line:      string(line),
+		},
+	}, nil
+}
+
+// eventTimestamp returns when event was last observed.
+func eventTimestamp(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
+
+func (t *Target) Stop() {
+	t.cancel()
+	t.wg.Wait()
+	t.running.Store(false)
+	t.handler.Stop()
+}
+
+func (t *Target) Type() target.TargetType {
+	return target.KubernetesEventsTargetType
+}
+
+func (t *Target) DiscoveredLabels() model.LabelSet {
+	return nil
+}
+
+func (t *Target) Labels() model.LabelSet {
+	return t.config.Labels
+}
+
+func (t *Target) Ready() bool {
+	return t.running.Load()
+}
+
+func (t *Target) Details() interface{} {
+	details := map[string]string{
+		"namespaces": strings.Join(t.config.Namespaces, ","),
+	}
+	for _, namespace := range t.namespaces() {
+		key := "resource_version"
+		if namespace != metav1.NamespaceAll {
+			key += "_" + namespace
+		}
+		details[key] = t.positions.GetString(t.positionKey(namespace))
+	}
+	return details
+}
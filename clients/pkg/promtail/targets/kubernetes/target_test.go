@@ -0,0 +1,166 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/grafana/loki/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+)
+
+type fakeClient struct {
+	mtx             sync.Mutex
+	listVersion     string
+	watchedVersions []string
+	watchers        chan *watch.FakeWatcher
+}
+
+func newFakeClient(listVersion string) *fakeClient {
+	return &fakeClient{
+		listVersion: listVersion,
+		watchers:    make(chan *watch.FakeWatcher, 10),
+	}
+}
+
+func (c *fakeClient) List(_ context.Context, _ string, _ metav1.ListOptions) (*corev1.EventList, error) {
+	return &corev1.EventList{ListMeta: metav1.ListMeta{ResourceVersion: c.listVersion}}, nil
+}
+
+func (c *fakeClient) Watch(_ context.Context, _ string, opts metav1.ListOptions) (watch.Interface, error) {
+	c.mtx.Lock()
+	c.watchedVersions = append(c.watchedVersions, opts.ResourceVersion)
+	c.mtx.Unlock()
+
+	w := watch.NewFake()
+	c.watchers <- w
+	return w, nil
+}
+
+func (c *fakeClient) versions() []string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]string(nil), c.watchedVersions...)
+}
+
+func newEvent(resourceVersion, reason, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "nginx.1",
+			ResourceVersion: resourceVersion,
+		},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "nginx"},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: "kubelet", Host: "node-1"},
+		LastTimestamp:  metav1.NewTime(time.Unix(10, 0)),
+	}
+}
+
+func newTestPositions(t *testing.T, logger log.Logger) positions.Positions {
+	ps, err := positions.New(logger, positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: t.TempDir() + "/positions.yml",
+	})
+	require.NoError(t, err)
+	t.Cleanup(ps.Stop)
+	return ps
+}
+
+func Test_KubernetesEventsTarget(t *testing.T) {
+	var (
+		logger = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+		cfg    = &scrapeconfig.KubernetesEventsTargetConfig{
+			Labels:               model.LabelSet{"job": "kubernetes-events"},
+			UseIncomingTimestamp: true,
+		}
+		client     = fake.New(func() {})
+		kubeClient = newFakeClient("1")
+		ps         = newTestPositions(t, logger)
+	)
+	getClient = func(string) (Client, error) { return kubeClient, nil }
+
+	// resume from the saved resource version.
+	ps.PutString(positions.CursorKey("kubernetes_events-test"), "100")
+
+	ta, err := NewTarget(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", cfg)
+	require.NoError(t, err)
+	require.True(t, ta.Ready())
+
+	w := <-kubeClient.watchers
+	w.Add(newEvent("101", "Scheduled", "Successfully assigned default/nginx to node-1"))
+	w.Modify(newEvent("102", "Pulled", "Container image \"nginx\" already present on machine"))
+	w.Action(watch.Bookmark, &corev1.Event{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "110"}})
+
+	require.Eventually(t, func() bool {
+		return ps.GetString(positions.CursorKey("kubernetes_events-test")) == "110"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"100"}, kubeClient.versions())
+
+	// an expired resource version restarts the watch from the current events.
+	w.Error(&metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusGone,
+		Reason: metav1.StatusReasonExpired,
+	})
+	<-kubeClient.watchers
+	require.Equal(t, []string{"100", "1"}, kubeClient.versions())
+
+	ta.Stop()
+
+	received := client.Received()
+	require.Len(t, received, 2)
+	require.Equal(t, model.LabelSet{
+		"job":                  "kubernetes-events",
+		"namespace":            "default",
+		"reason":               "Scheduled",
+		"type":                 "Normal",
+		"involved_object_kind": "Pod",
+		"involved_object_name": "nginx",
+	}, received[0].Labels)
+	require.Equal(t, time.Unix(10, 0), received[0].Timestamp)
+	require.JSONEq(t, `{"kind":"Pod","name":"nginx","reason":"Scheduled","type":"Normal","message":"Successfully assigned default/nginx to node-1","component":"kubelet","host":"node-1"}`, received[0].Line)
+	require.Equal(t, model.LabelValue("Pulled"), received[1].Labels["reason"])
+}
+
+func Test_KubernetesEventsTarget_Namespaces(t *testing.T) {
+	var (
+		logger     = log.NewNopLogger()
+		client     = fake.New(func() {})
+		kubeClient = newFakeClient("5")
+		ps         = newTestPositions(t, logger)
+	)
+	getClient = func(string) (Client, error) { return kubeClient, nil }
+
+	ta, err := NewTarget(NewMetrics(prometheus.NewRegistry()), logger, client, ps, "test", &scrapeconfig.KubernetesEventsTargetConfig{
+		Namespaces: []string{"default", "kube-system"},
+	})
+	require.NoError(t, err)
+
+	// without saved positions both namespaces start watching from the listed resource version.
+	<-kubeClient.watchers
+	<-kubeClient.watchers
+	require.Equal(t, []string{"5", "5"}, kubeClient.versions())
+
+	ta.Stop()
+	require.False(t, ta.Ready())
+	require.Equal(t, map[string]string{
+		"namespaces":                   "default,kube-system",
+		"resource_version_default":     "",
+		"resource_version_kube-system": "",
+	}, ta.Details())
+}
@@ -19,7 +19,9 @@ import (
 	"github.com/grafana/loki/clients/pkg/promtail/targets/heroku"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/journal"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/kafka"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/kubernetes"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/lokipush"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/otlp"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/stdin"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/syslog"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/target"
@@ -27,29 +29,33 @@ import (
 )
 
 const (
-	FileScrapeConfigs    = "fileScrapeConfigs"
-	JournalScrapeConfigs = "journalScrapeConfigs"
-	SyslogScrapeConfigs  = "syslogScrapeConfigs"
-	GcplogScrapeConfigs  = "gcplogScrapeConfigs"
-	PushScrapeConfigs    = "pushScrapeConfigs"
-	WindowsEventsConfigs = "windowsEventsConfigs"
-	KafkaConfigs         = "kafkaConfigs"
-	GelfConfigs          = "gelfConfigs"
-	CloudflareConfigs    = "cloudflareConfigs"
-	DockerConfigs        = "dockerConfigs"
-	DockerSDConfigs      = "dockerSDConfigs"
-	HerokuDrainConfigs   = "herokuDrainConfigs"
+	FileScrapeConfigs       = "fileScrapeConfigs"
+	JournalScrapeConfigs    = "journalScrapeConfigs"
+	SyslogScrapeConfigs     = "syslogScrapeConfigs"
+	GcplogScrapeConfigs     = "gcplogScrapeConfigs"
+	PushScrapeConfigs       = "pushScrapeConfigs"
+	WindowsEventsConfigs    = "windowsEventsConfigs"
+	KafkaConfigs            = "kafkaConfigs"
+	GelfConfigs             = "gelfConfigs"
+	CloudflareConfigs       = "cloudflareConfigs"
+	DockerConfigs           = "dockerConfigs"
+	DockerSDConfigs         = "dockerSDConfigs"
+	HerokuDrainConfigs      = "herokuDrainConfigs"
+	KubernetesEventsConfigs = "kubernetesEventsConfigs"
+	OTLPConfigs             = "otlpConfigs"
 )
 
 var (
-	fileMetrics        *file.Metrics
-	syslogMetrics      *syslog.Metrics
-	gcplogMetrics      *gcplog.Metrics
-	gelfMetrics        *gelf.Metrics
-	cloudflareMetrics  *cloudflare.Metrics
-	dockerMetrics      *docker.Metrics
-	journalMetrics     *journal.Metrics
-	herokuDrainMetrics *heroku.Metrics
+	fileMetrics             *file.Metrics
+	syslogMetrics           *syslog.Metrics
+	gcplogMetrics           *gcplog.Metrics
+	gelfMetrics             *gelf.Metrics
+	cloudflareMetrics       *cloudflare.Metrics
+	dockerMetrics           *docker.Metrics
+	journalMetrics          *journal.Metrics
+	herokuDrainMetrics      *heroku.Metrics
+	kubernetesEventsMetrics *kubernetes.Metrics
+	otlpMetrics             *otlp.Metrics
 )
 
 type targetManager interface {
@@ -111,6 +117,10 @@ func NewTargetManagers(
 			targetScrapeConfigs[DockerSDConfigs] = append(targetScrapeConfigs[DockerSDConfigs], cfg)
 		case cfg.HerokuDrainConfig != nil:
 			targetScrapeConfigs[HerokuDrainConfigs] = append(targetScrapeConfigs[HerokuDrainConfigs], cfg)
+		case cfg.KubernetesEventsConfig != nil:
+			targetScrapeConfigs[KubernetesEventsConfigs] = append(targetScrapeConfigs[KubernetesEventsConfigs], cfg)
+		case cfg.OTLPConfig != nil:
+			targetScrapeConfigs[OTLPConfigs] = append(targetScrapeConfigs[OTLPConfigs], cfg)
 		default:
 			return nil, fmt.Errorf("no valid target scrape config defined for %q", cfg.JobName)
 		}
@@ -154,6 +164,12 @@ func NewTargetManagers(
 	if len(targetScrapeConfigs[HerokuDrainConfigs]) > 0 && herokuDrainMetrics == nil {
 		herokuDrainMetrics = heroku.NewMetrics(reg)
 	}
+	if len(targetScrapeConfigs[KubernetesEventsConfigs]) > 0 && kubernetesEventsMetrics == nil {
+		kubernetesEventsMetrics = kubernetes.NewMetrics(reg)
+	}
+	if len(targetScrapeConfigs[OTLPConfigs]) > 0 && otlpMetrics == nil {
+		otlpMetrics = otlp.NewMetrics(reg)
+	}
 
 	for target, scrapeConfigs := range targetScrapeConfigs {
 		switch target {
@@ -229,8 +245,18 @@ func NewTargetManagers(
 				return nil, errors.Wrap(err, "failed to make Heroku drain target manager")
 			}
 			targetManagers = append(targetManagers, herokuDrainTargetManager)
+		case OTLPConfigs:
+			otlpTargetManager, err := otlp.NewTargetManager(otlpMetrics, logger, client, scrapeConfigs)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to make OTLP target manager")
+			}
+			targetManagers = append(targetManagers, otlpTargetManager)
 		case WindowsEventsConfigs:
-			windowsTargetManager, err := windows.NewTargetManager(reg, logger, client, scrapeConfigs)
+			pos, err := getPositionFile()
+			if err != nil {
+				return nil, err
+			}
+			windowsTargetManager, err := windows.NewTargetManager(reg, logger, pos, client, scrapeConfigs)
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to make windows target manager")
 			}
@@ -277,6 +303,16 @@ func NewTargetManagers(
 				return nil, errors.Wrap(err, "failed to make Docker service discovery target manager")
 			}
 			targetManagers = append(targetManagers, cfTargetManager)
+		case KubernetesEventsConfigs:
+			pos, err := getPositionFile()
+			if err != nil {
+				return nil, err
+			}
+			kubernetesEventsTargetManager, err := kubernetes.NewTargetManager(kubernetesEventsMetrics, logger, pos, client, scrapeConfigs)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to make Kubernetes events target manager")
+			}
+			targetManagers = append(targetManagers, kubernetesEventsTargetManager)
 		default:
 			return nil, errors.New("unknown scrape config")
 		}
@@ -0,0 +1,34 @@
+package otlp
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics holds a set of OTLP target metrics.
+type Metrics struct {
+	reg prometheus.Registerer
+
+	Entries *prometheus.CounterVec
+	Errors  *prometheus.CounterVec
+}
+
+// NewMetrics creates a new set of OTLP target metrics. If reg is non-nil, the
+// metrics will be registered.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	var m Metrics
+	m.reg = reg
+
+	m.Entries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "otlp_target_entries_total",
+		Help:      "Number of log records received by the OTLP target",
+	}, []string{"protocol"})
+	m.Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "promtail",
+		Name:      "otlp_target_parsing_errors_total",
+		Help:      "Number of OTLP requests which could not be decoded",
+	}, []string{"protocol"})
+
+	if reg != nil {
+		reg.MustRegister(m.Entries, m.Errors)
+	}
+	return &m
+}
@@ -0,0 +1,310 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/prometheus/prometheus/util/strutil"
+	"github.com/weaveworks/common/logging"
+	"github.com/weaveworks/common/server"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	lokiClient "github.com/grafana/loki/clients/pkg/promtail/client"
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/serverutils"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/target"
+
+	"github.com/grafana/loki/pkg/loghttp/push"
+	"github.com/grafana/loki/pkg/logproto"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+
+	// Internal labels exposing the OTLP fields of each log record to relabel_configs.
+	resourceAttributeLabelPrefix = "__otlp_resource_attribute_"
+	scopeAttributeLabelPrefix    = "__otlp_scope_attribute_"
+	attributeLabelPrefix         = "__otlp_attribute_"
+	scopeNameLabel               = "__otlp_scope_name"
+	severityTextLabel            = "__otlp_severity_text"
+)
+
+// Target receives logs over OTLP/gRPC and OTLP/HTTP. The resource, scope and record
+// attributes of each log record are exposed as internal labels, which relabel_configs
+// can turn into stream labels.
+type Target struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	logger         log.Logger
+	handler        api.EntryHandler
+	config         *scrapeconfig.OTLPTargetConfig
+	jobName        string
+	server         *server.Server
+	metrics        *Metrics
+	relabelConfigs []*relabel.Config
+}
+
+// NewTarget creates a new OTLP target listening on the gRPC and HTTP ports of its server config.
+func NewTarget(metrics *Metrics, logger log.Logger, handler api.EntryHandler, jobName string, config *scrapeconfig.OTLPTargetConfig, relabel []*relabel.Config) (*Target, error) {
+	t := &Target{
+		metrics:        metrics,
+		logger:         log.With(logger, "component", "otlp"),
+		handler:        handler,
+		jobName:        jobName,
+		config:         config,
+		relabelConfigs: relabel,
+	}
+
+	mergedServerConfigs, err := serverutils.MergeWithDefaults(config.Server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configs and override defaults when configuring OTLP target: %w", err)
+	}
+	// Set the config to the new combined config.
+	config.Server = mergedServerConfigs
+
+	if err := t.run(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Target) run() error {
+	level.Info(t.logger).Log("msg", "starting OTLP target", "job", t.jobName)
+
+	// To prevent metric collisions because all metrics are going to be registered in the global Prometheus registry.
+	metricsNamespace := "promtail_otlp_target_" + t.jobName
+	if !model.IsValidMetricName(model.LabelValue(metricsNamespace)) {
+		return fmt.Errorf("invalid prometheus-compatible job name: %s", t.jobName)
+	}
+	t.config.Server.MetricsNamespace = metricsNamespace
+
+	// We don't want the /debug and /metrics endpoints running, since this is not the main promtail HTTP server.
+	t.config.Server.RegisterInstrumentation = false
+	t.config.Server.Log = logging.GoKit(log.With(util_log.Logger, "component", "otlp"))
+
+	srv, err := server.New(t.config.Server)
+	if err != nil {
+		return err
+	}
+
+	t.server = srv
+	collogspb.RegisterLogsServiceServer(t.server.GRPC, t)
+	t.server.HTTP.Path("/v1/logs").Methods("POST").Handler(http.HandlerFunc(t.handleHTTP))
+
+	go func() {
+		err := srv.Run()
+		if err != nil {
+			level.Error(t.logger).Log("msg", "OTLP target shutdown with error", "err", err)
+		}
+	}()
+
+	return nil
+}
+
+// Export implements the OTLP LogsService.
+func (t *Target) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	var tenantID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("X-Scope-OrgID"); len(values) > 0 {
+			tenantID = values[0]
+		}
+	}
+	if err := t.process(ctx, req, tenantID, protocolGRPC); err != nil {
+		return nil, status.Error(codes.Canceled, err.Error())
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func (t *Target) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r, t.config.Server.GPRCServerMaxRecvMsgSize)
+	if err != nil {
+		t.metrics.Errors.WithLabelValues(protocolHTTP).Inc()
+		level.Warn(t.logger).Log("msg", "failed to decode incoming OTLP request", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := t.process(r.Context(), req, r.Header.Get("X-Scope-OrgID"), protocolHTTP); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// The response is an empty ExportLogsServiceResponse, in the encoding of the request.
+	if strings.HasPrefix(r.Header.Get("Content-Type"), contentTypeJSON) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte("{}"))
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeProtobuf)
+	w.WriteHeader(http.StatusOK)
+}
+
+// decodeRequest decodes an OTLP/HTTP request body of at most maxSize bytes, encoded
+// either as protobuf or JSON and optionally gzip compressed.
+func decodeRequest(r *http.Request, maxSize int) (*collogspb.ExportLogsServiceRequest, error) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+	buf, err := io.ReadAll(io.LimitReader(body, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > maxSize {
+		return nil, fmt.Errorf("request too large, limit is %d bytes", maxSize)
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	switch contentType := r.Header.Get("Content-Type"); {
+	case strings.HasPrefix(contentType, contentTypeProtobuf):
+		err = proto.Unmarshal(buf, &req)
+	case strings.HasPrefix(contentType, contentTypeJSON):
+		err = push.UnmarshalOTLPJSON(buf, &req)
+	default:
+		return nil, fmt.Errorf("Content-Type %q not supported, must be %q or %q", contentType, contentTypeProtobuf, contentTypeJSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// process sends the log records of req to the handler.
+func (t *Target) process(ctx context.Context, req *collogspb.ExportLogsServiceRequest, tenantID, protocol string) error {
+	entries := t.handler.Chan()
+	now := time.Now()
+
+	for _, rl := range req.GetResourceLogs() {
+		lb := labels.NewBuilder(nil)
+		setAttributeLabels(lb, resourceAttributeLabelPrefix, rl.GetResource().GetAttributes())
+
+		for _, sl := range rl.GetScopeLogs() {
+			scopeLabels := labels.NewBuilder(lb.Labels(nil))
+			setAttributeLabels(scopeLabels, scopeAttributeLabelPrefix, sl.GetScope().GetAttributes())
+			if name := sl.GetScope().GetName(); name != "" {
+				scopeLabels.Set(scopeNameLabel, name)
+			}
+
+			for _, lr := range sl.GetLogRecords() {
+				recordLabels := labels.NewBuilder(scopeLabels.Labels(nil))
+				setAttributeLabels(recordLabels, attributeLabelPrefix, lr.GetAttributes())
+				if lr.GetSeverityText() != "" {
+					recordLabels.Set(severityTextLabel, lr.GetSeverityText())
+				}
+				if tenantID != "" {
+					// If present, first inject the tenant ID in, so it can be relabeled if necessary
+					recordLabels.Set(lokiClient.ReservedLabelTenantID, tenantID)
+				}
+
+				processed := relabel.Process(recordLabels.Labels(nil), t.relabelConfigs...)
+				if len(t.relabelConfigs) > 0 && processed == nil {
+					// Dropped by relabeling.
+					continue
+				}
+
+				// Start with the set of labels fixed in the configuration
+				filtered := t.Labels().Clone()
+				for _, lbl := range processed {
+					if strings.HasPrefix(lbl.Name, "__") {
+						continue
+					}
+					filtered[model.LabelName(lbl.Name)] = model.LabelValue(lbl.Value)
+				}
+				// Then, inject it as the reserved label, so it's used by the remote write client
+				if tenantID != "" {
+					filtered[lokiClient.ReservedLabelTenantID] = model.LabelValue(tenantID)
+				}
+
+				ts := now
+				if t.config.UseIncomingTimestamp {
+					ts = recordTimestamp(lr, now)
+				}
+				select {
+				case entries <- api.Entry{
+					Labels: filtered,
+					Entry: logproto.Entry{
+						Timestamp: ts,
+						Line:      push.OTLPValueString(lr.GetBody()),
+					},
+				}:
+					t.metrics.Entries.WithLabelValues(protocol).Inc()
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func setAttributeLabels(lb *labels.Builder, prefix string, attrs []*commonpb.KeyValue) {
+	for _, attr := range attrs {
+		if value := push.OTLPValueString(attr.GetValue()); value != "" {
+			lb.Set(prefix+strutil.SanitizeLabelName(attr.GetKey()), value)
+		}
+	}
+}
+
+func recordTimestamp(lr *logspb.LogRecord, now time.Time) time.Time {
+	switch {
+	case lr.GetTimeUnixNano() != 0:
+		return time.Unix(0, int64(lr.GetTimeUnixNano()))
+	case lr.GetObservedTimeUnixNano() != 0:
+		return time.Unix(0, int64(lr.GetObservedTimeUnixNano()))
+	default:
+		return now
+	}
+}
+
+func (t *Target) Type() target.TargetType {
+	return target.OTLPTargetType
+}
+
+func (t *Target) DiscoveredLabels() model.LabelSet {
+	return nil
+}
+
+func (t *Target) Labels() model.LabelSet {
+	return t.config.Labels
+}
+
+func (t *Target) Ready() bool {
+	return true
+}
+
+func (t *Target) Details() interface{} {
+	return map[string]string{}
+}
+
+func (t *Target) Stop() error {
+	level.Info(t.logger).Log("msg", "stopping OTLP target", "job", t.jobName)
+	t.server.Shutdown()
+	t.handler.Stop()
+	return nil
+}
@@ -0,0 +1,186 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/server"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	lokiClient "github.com/grafana/loki/clients/pkg/promtail/client"
+	"github.com/grafana/loki/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+)
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func testRequest() *collogspb.ExportLogsServiceRequest {
+	return &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+					{Key: "service.name", Value: stringValue("checkout")},
+					{Key: "k8s.pod.name", Value: stringValue("checkout-1")},
+				}},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						Scope: &commonpb.InstrumentationScope{Name: "otelzap"},
+						LogRecords: []*logspb.LogRecord{
+							{
+								TimeUnixNano: 1670000000000000001,
+								SeverityText: "INFO",
+								Body:         stringValue("order placed"),
+							},
+							{
+								ObservedTimeUnixNano: 1670000000000000002,
+								SeverityText:         "DEBUG",
+								Body:                 stringValue("cache miss"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTestTarget creates a target listening on random ports. Each target needs a distinct job
+// name, as the metrics of its server are registered in the default registry.
+func newTestTarget(t *testing.T, jobName string) (*Target, *fake.Client) {
+	var serverConfig server.Config
+	serverConfig.RegisterFlags(flag.NewFlagSet("empty", flag.ContinueOnError))
+	serverConfig.HTTPListenAddress = "127.0.0.1"
+	serverConfig.HTTPListenPort = 0
+	serverConfig.GRPCListenAddress = "127.0.0.1"
+	serverConfig.GRPCListenPort = 0
+
+	client := fake.New(func() {})
+	relabelConfigs := []*relabel.Config{
+		{
+			Action:      relabel.LabelMap,
+			Regex:       relabel.MustNewRegexp("__otlp_resource_attribute_(.+)"),
+			Replacement: "$1",
+		},
+		{
+			SourceLabels: model.LabelNames{"__otlp_severity_text"},
+			Regex:        relabel.MustNewRegexp("DEBUG"),
+			Action:       relabel.Drop,
+		},
+	}
+	tgt, err := NewTarget(NewMetrics(prometheus.NewRegistry()), log.NewNopLogger(), client, jobName, &scrapeconfig.OTLPTargetConfig{
+		Server:               serverConfig,
+		Labels:               model.LabelSet{"job": "otlp"},
+		UseIncomingTimestamp: true,
+	}, relabelConfigs)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tgt.Stop() })
+	return tgt, client
+}
+
+func requireReceived(t *testing.T, client *fake.Client, expectedLabels model.LabelSet) {
+	require.Eventually(t, func() bool {
+		return len(client.Received()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	entry := client.Received()[0]
+	require.Equal(t, expectedLabels, entry.Labels)
+	require.Equal(t, "order placed", entry.Line)
+	require.Equal(t, time.Unix(0, 1670000000000000001), entry.Timestamp)
+}
+
+func TestTarget_HTTP(t *testing.T) {
+	body, err := proto.Marshal(testRequest())
+	require.NoError(t, err)
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	for i, tc := range []struct {
+		name        string
+		contentType string
+		encoding    string
+		body        []byte
+	}{
+		{name: "protobuf", contentType: "application/x-protobuf", body: body},
+		{name: "gzipped protobuf", contentType: "application/x-protobuf", encoding: "gzip", body: gzipped.Bytes()},
+		{name: "json", contentType: "application/json", body: []byte(`{"resourceLogs":[{
+			"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"k8s.pod.name","value":{"stringValue":"checkout-1"}}]},
+			"scopeLogs":[{"scope":{"name":"otelzap"},"logRecords":[
+				{"timeUnixNano":"1670000000000000001","severityText":"INFO","body":{"stringValue":"order placed"}},
+				{"observedTimeUnixNano":"1670000000000000002","severityText":"DEBUG","body":{"stringValue":"cache miss"}}
+			]}]
+		}]}`)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tgt, client := newTestTarget(t, fmt.Sprintf("http_%d", i))
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s/v1/logs", tgt.server.HTTPListenAddr()), bytes.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+			req.Header.Set("Content-Encoding", tc.encoding)
+			req.Header.Set("X-Scope-OrgID", "tenant-a")
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			requireReceived(t, client, model.LabelSet{
+				"job":                            "otlp",
+				"service_name":                   "checkout",
+				"k8s_pod_name":                   "checkout-1",
+				lokiClient.ReservedLabelTenantID: "tenant-a",
+			})
+		})
+	}
+}
+
+func TestTarget_HTTPInvalidRequest(t *testing.T) {
+	tgt, _ := newTestTarget(t, "http_invalid")
+
+	resp, err := http.Post(fmt.Sprintf("http://%s/v1/logs", tgt.server.HTTPListenAddr()), "text/plain", bytes.NewReader([]byte("foo")))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTarget_GRPC(t *testing.T) {
+	tgt, client := newTestTarget(t, "grpc")
+
+	conn, err := grpc.Dial(tgt.server.GRPCListenAddr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "X-Scope-OrgID", "tenant-b")
+	_, err = collogspb.NewLogsServiceClient(conn).Export(ctx, testRequest())
+	require.NoError(t, err)
+
+	requireReceived(t, client, model.LabelSet{
+		"job":                            "otlp",
+		"service_name":                   "checkout",
+		"k8s_pod_name":                   "checkout-1",
+		lokiClient.ReservedLabelTenantID: "tenant-b",
+	})
+}
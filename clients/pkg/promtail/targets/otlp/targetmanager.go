@@ -0,0 +1,76 @@
+package otlp
+
+import (
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/clients/pkg/logentry/stages"
+	"github.com/grafana/loki/clients/pkg/promtail/api"
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+	"github.com/grafana/loki/clients/pkg/promtail/targets/target"
+)
+
+// TargetManager manages a series of OTLP targets.
+type TargetManager struct {
+	logger  log.Logger
+	targets map[string]*Target
+}
+
+// NewTargetManager creates a new OTLP target manager.
+func NewTargetManager(
+	metrics *Metrics,
+	logger log.Logger,
+	client api.EntryHandler,
+	scrapeConfigs []scrapeconfig.Config,
+) (*TargetManager, error) {
+	tm := &TargetManager{
+		logger:  logger,
+		targets: make(map[string]*Target),
+	}
+
+	for _, cfg := range scrapeConfigs {
+		pipeline, err := stages.NewPipeline(log.With(logger, "component", "otlp_pipeline_"+cfg.JobName), cfg.PipelineStages, &cfg.JobName, metrics.reg)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := NewTarget(metrics, logger, pipeline.Wrap(client), cfg.JobName, cfg.OTLPConfig, cfg.RelabelConfigs)
+		if err != nil {
+			return nil, err
+		}
+
+		tm.targets[cfg.JobName] = t
+	}
+
+	return tm, nil
+}
+
+// Ready returns true if at least one OTLP target is ready.
+func (tm *TargetManager) Ready() bool {
+	for _, t := range tm.targets {
+		if t.Ready() {
+			return true
+		}
+	}
+	return false
+}
+
+func (tm *TargetManager) Stop() {
+	for name, t := range tm.targets {
+		if err := t.Stop(); err != nil {
+			level.Error(t.logger).Log("msg", "failed to stop OTLP target", "name", name, "err", err)
+		}
+	}
+}
+
+func (tm *TargetManager) ActiveTargets() map[string][]target.Target {
+	return tm.AllTargets()
+}
+
+func (tm *TargetManager) AllTargets() map[string][]target.Target {
+	result := make(map[string][]target.Target, len(tm.targets))
+	for k, v := range tm.targets {
+		result[k] = []target.Target{v}
+	}
+	return result
+}
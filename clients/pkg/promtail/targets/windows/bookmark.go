@@ -4,45 +4,80 @@
 package windows
 
 import (
+	"fmt"
 	"io"
 	"os"
 
 	"github.com/spf13/afero"
 
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/windows/win_eventlog"
 )
 
+// bookmarkStore persists the XML of a bookmark.
+type bookmarkStore interface {
+	load() (string, error)
+	save(bookmark string) error
+	close() error
+}
+
 type bookMark struct {
 	handle win_eventlog.EvtHandle
-	file   afero.File
+	store  bookmarkStore
 	isNew  bool
 
 	buf []byte
 }
 
-// newBookMark creates a new windows event bookmark.
-// The bookmark will be saved at the given path. Use save to save the current position for a given event.
-func newBookMark(path string) (*bookMark, error) {
-	// 16kb buffer for rendering bookmark
-	buf := make([]byte, 16<<10)
+// newBookMark creates a new windows event bookmark, loading its current position from store.
+// Use save to save the current position for a given event.
+func newBookMark(store bookmarkStore) (*bookMark, error) {
+	bookmark, err := store.load()
+	if err != nil {
+		return nil, err
+	}
+	// load the current bookmark, or create a new one if none was saved.
+	handle, err := win_eventlog.CreateBookmark(bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return &bookMark{
+		handle: handle,
+		store:  store,
+		isNew:  bookmark == "",
+		// 16kb buffer for rendering bookmark
+		buf: make([]byte, 16<<10),
+	}, nil
+}
 
+// save Saves the bookmark at the current event position.
+func (b *bookMark) save(event win_eventlog.EvtHandle) error {
+	newBookmark, err := win_eventlog.UpdateBookmark(b.handle, event, b.buf)
+	if err != nil {
+		return err
+	}
+	return b.store.save(newBookmark)
+}
+
+// close closes the bookmark store.
+func (b *bookMark) close() error {
+	return b.store.close()
+}
+
+// fileStore saves a bookmark at a path on the filesystem.
+type fileStore struct {
+	file afero.File
+}
+
+// newFileStore opens the bookmark file at path, creating it if none exists.
+func newFileStore(path string) (*fileStore, error) {
 	_, err := fs.Stat(path)
-	// creates a new bookmark file if none exists.
 	if os.IsNotExist(err) {
 		file, err := fs.Create(path)
 		if err != nil {
 			return nil, err
 		}
-		bm, err := win_eventlog.CreateBookmark("")
-		if err != nil {
-			return nil, err
-		}
-		return &bookMark{
-			handle: bm,
-			file:   file,
-			isNew:  true,
-			buf:    buf,
-		}, nil
+		return &fileStore{file: file}, nil
 	}
 	if err != nil {
 		return nil, err
@@ -52,41 +87,56 @@ func newBookMark(path string) (*bookMark, error) {
 	if err != nil {
 		return nil, err
 	}
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		return nil, err
-	}
-	fileString := string(fileContent)
-	// load the current bookmark.
-	bm, err := win_eventlog.CreateBookmark(fileString)
-	if err != nil {
-		return nil, err
-	}
-	return &bookMark{
-		handle: bm,
-		file:   file,
-		isNew:  fileString == "",
-		buf:    buf,
-	}, nil
+	return &fileStore{file: file}, nil
 }
 
-// save Saves the bookmark at the current event position.
-func (b *bookMark) save(event win_eventlog.EvtHandle) error {
-	newBookmark, err := win_eventlog.UpdateBookmark(b.handle, event, b.buf)
+func (s *fileStore) load() (string, error) {
+	fileContent, err := io.ReadAll(s.file)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if err := b.file.Truncate(0); err != nil {
+	return string(fileContent), nil
+}
+
+func (s *fileStore) save(bookmark string) error {
+	if err := s.file.Truncate(0); err != nil {
 		return err
 	}
-	if _, err := b.file.Seek(0, 0); err != nil {
+	if _, err := s.file.Seek(0, 0); err != nil {
 		return err
 	}
-	_, err = b.file.WriteString(newBookmark)
+	_, err := s.file.WriteString(bookmark)
 	return err
 }
 
-// close closes the current bookmark file.
-func (b *bookMark) close() error {
-	return b.file.Close()
+func (s *fileStore) close() error {
+	return s.file.Close()
+}
+
+// positionsStore saves a bookmark in the positions file.
+type positionsStore struct {
+	positions positions.Positions
+	key       string
+}
+
+// newPositionsStore saves the bookmark of the eventlog channel of job in the positions file.
+func newPositionsStore(p positions.Positions, job, channel string) *positionsStore {
+	return &positionsStore{
+		positions: p,
+		key:       positions.CursorKey(fmt.Sprintf("windows_events-%s-%s", job, channel)),
+	}
+}
+
+func (s *positionsStore) load() (string, error) {
+	return s.positions.GetString(s.key), nil
+}
+
+func (s *positionsStore) save(bookmark string) error {
+	s.positions.PutString(s.key, bookmark)
+	return nil
+}
+
+// close is a no-op, as the positions file is shared with the other targets.
+func (s *positionsStore) close() error {
+	return nil
 }
@@ -56,8 +56,15 @@ type Correlation struct {
 	RelatedActivityID string `json:"relatedActivityID,omitempty"`
 }
 
-// formatLine format a Loki log line from a windows event.
+// formatLine format a Loki log line from a windows event, using the configured line format.
 func formatLine(cfg *scrapeconfig.WindowsEventsTargetConfig, event win_eventlog.Event) (string, error) {
+	switch cfg.LineFormat {
+	case lineFormatXML:
+		return event.XML, nil
+	case lineFormatMessage:
+		return event.Message, nil
+	}
+
 	structuredEvent := Event{
 		Source:        event.Source.Name,
 		Channel:       event.Channel,
@@ -0,0 +1,94 @@
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+)
+
+const (
+	lineFormatJSON    = "json"
+	lineFormatXML     = "xml"
+	lineFormatMessage = "message"
+)
+
+// standardKeywords are the masks of the standard event keywords, see winmeta.xml.
+var standardKeywords = map[string]uint64{
+	"responsetime":    0x01000000000000,
+	"wdicontext":      0x02000000000000,
+	"wdidiagnostic":   0x04000000000000,
+	"sqm":             0x08000000000000,
+	"auditfailure":    0x10000000000000,
+	"auditsuccess":    0x20000000000000,
+	"correlationhint": 0x40000000000000,
+	"classic":         0x80000000000000,
+}
+
+// validateConfig validates cfg and sets the defaults of its optional fields.
+func validateConfig(cfg *scrapeconfig.WindowsEventsTargetConfig) error {
+	if cfg.Query == "" {
+		cfg.Query = "*"
+	}
+	if cfg.LineFormat == "" {
+		cfg.LineFormat = lineFormatJSON
+	}
+	switch cfg.LineFormat {
+	case lineFormatJSON, lineFormatXML, lineFormatMessage:
+	default:
+		return fmt.Errorf("invalid line_format %q, must be one of %q, %q or %q", cfg.LineFormat, lineFormatJSON, lineFormatXML, lineFormatMessage)
+	}
+	if len(cfg.EventlogNames) > 0 && isXMLQuery(cfg.Query) {
+		return errors.New("eventlog_names can't be combined with an XML xpath_query, select the eventlogs in the query instead")
+	}
+	if len(cfg.EventlogNames) > 1 && cfg.BookmarkPath != "" {
+		return errors.New("bookmark_path can't be used with several eventlog_names, bookmarks are saved in the positions file instead")
+	}
+	if len(cfg.Keywords) > 0 && cfg.Query != "*" {
+		return errors.New("keywords can't be combined with a custom xpath_query")
+	}
+	return nil
+}
+
+// channels returns the eventlogs the target subscribes to.
+func channels(cfg *scrapeconfig.WindowsEventsTargetConfig) []string {
+	if len(cfg.EventlogNames) > 0 {
+		return cfg.EventlogNames
+	}
+	return []string{cfg.EventlogName}
+}
+
+// subscriptionQuery returns the query of the subscriptions, filtering the configured keywords.
+func subscriptionQuery(cfg *scrapeconfig.WindowsEventsTargetConfig) (string, error) {
+	if len(cfg.Keywords) == 0 {
+		return cfg.Query, nil
+	}
+	filters := make([]string, 0, len(cfg.Keywords))
+	for _, keyword := range cfg.Keywords {
+		mask, err := parseKeyword(keyword)
+		if err != nil {
+			return "", err
+		}
+		filters = append(filters, fmt.Sprintf("band(Keywords,%d)", mask))
+	}
+	return fmt.Sprintf("*[System[%s]]", strings.Join(filters, " or ")), nil
+}
+
+// parseKeyword returns the mask of a standard keyword name or of a hexadecimal mask.
+func parseKeyword(keyword string) (uint64, error) {
+	name := strings.ToLower(strings.ReplaceAll(keyword, " ", ""))
+	if mask, ok := standardKeywords[name]; ok {
+		return mask, nil
+	}
+	mask, err := strconv.ParseUint(name, 0, 64)
+	if err != nil || mask == 0 {
+		return 0, fmt.Errorf("invalid keyword %q, must be a standard keyword name or a hexadecimal mask", keyword)
+	}
+	return mask, nil
+}
+
+func isXMLQuery(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "<")
+}
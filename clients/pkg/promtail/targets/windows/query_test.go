@@ -0,0 +1,53 @@
+package windows
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
+)
+
+func Test_validateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		cfg     scrapeconfig.WindowsEventsTargetConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: scrapeconfig.WindowsEventsTargetConfig{EventlogName: "Application"}},
+		{name: "several eventlogs", cfg: scrapeconfig.WindowsEventsTargetConfig{EventlogNames: []string{"Application", "System"}, Keywords: []string{"Classic"}}},
+		{name: "invalid line format", cfg: scrapeconfig.WindowsEventsTargetConfig{LineFormat: "text"}, wantErr: true},
+		{name: "eventlogs with xml query", cfg: scrapeconfig.WindowsEventsTargetConfig{EventlogNames: []string{"Application"}, Query: "<QueryList></QueryList>"}, wantErr: true},
+		{name: "eventlogs with bookmark path", cfg: scrapeconfig.WindowsEventsTargetConfig{EventlogNames: []string{"Application", "System"}, BookmarkPath: "bookmark.xml"}, wantErr: true},
+		{name: "keywords with custom query", cfg: scrapeconfig.WindowsEventsTargetConfig{Keywords: []string{"Classic"}, Query: "*[System[EventID=999]]"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateConfig(&tc.cfg)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "*", tc.cfg.Query)
+			require.Equal(t, lineFormatJSON, tc.cfg.LineFormat)
+		})
+	}
+}
+
+func Test_subscriptionQuery(t *testing.T) {
+	query, err := subscriptionQuery(&scrapeconfig.WindowsEventsTargetConfig{Query: "*[System[EventID=999]]"})
+	require.NoError(t, err)
+	require.Equal(t, "*[System[EventID=999]]", query)
+
+	query, err = subscriptionQuery(&scrapeconfig.WindowsEventsTargetConfig{Query: "*", Keywords: []string{"Audit Failure", "0x8"}})
+	require.NoError(t, err)
+	require.Equal(t, "*[System[band(Keywords,4503599627370496) or band(Keywords,8)]]", query)
+
+	_, err = subscriptionQuery(&scrapeconfig.WindowsEventsTargetConfig{Query: "*", Keywords: []string{"unknown"}})
+	require.Error(t, err)
+}
+
+func Test_channels(t *testing.T) {
+	require.Equal(t, []string{"Application"}, channels(&scrapeconfig.WindowsEventsTargetConfig{EventlogName: "Application"}))
+	require.Equal(t, []string{"Application", "System"}, channels(&scrapeconfig.WindowsEventsTargetConfig{EventlogName: "Security", EventlogNames: []string{"Application", "System"}}))
+}
@@ -14,11 +14,11 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/relabel"
 	"github.com/spf13/afero"
-	"golang.org/x/sys/windows"
 
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/clients/pkg/promtail/api"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
 	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/target"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/windows/win_eventlog"
@@ -29,13 +29,12 @@ import (
 var fs = afero.NewOsFs()
 
 type Target struct {
-	subscription  win_eventlog.EvtHandle
+	subscriptions []*subscription
 	handler       api.EntryHandler
 	cfg           *scrapeconfig.WindowsEventsTargetConfig
 	relabelConfig []*relabel.Config
 	logger        log.Logger
 
-	bm      *bookMark // bookmark to save positions.
 	fetcher *win_eventlog.EventFetcher
 
 	ready bool
@@ -44,55 +43,84 @@ type Target struct {
 	err   error
 }
 
+// subscription is the subscription to the events of an eventlog channel.
+type subscription struct {
+	channel string
+	handle  win_eventlog.EvtHandle
+	bm      *bookMark // bookmark to save positions.
+}
+
 // New create a new windows targets, that will fetch windows event logs and send them to Loki.
+// Each eventlog channel has its own subscription and bookmark. Bookmarks are saved in the positions
+// file unless a bookmark path is configured.
 func New(
 	logger log.Logger,
 	handler api.EntryHandler,
 	relabel []*relabel.Config,
+	positions positions.Positions,
+	jobName string,
 	cfg *scrapeconfig.WindowsEventsTargetConfig,
 ) (*Target, error) {
-	sigEvent, err := windows.CreateEvent(nil, 0, 0, nil)
-	if err != nil {
+	if err := validateConfig(cfg); err != nil {
 		return nil, err
 	}
-	defer windows.CloseHandle(sigEvent)
-
-	bm, err := newBookMark(cfg.BookmarkPath)
+	query, err := subscriptionQuery(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create bookmark using path=%s: %w", cfg.BookmarkPath, err)
+		return nil, err
 	}
 
 	t := &Target{
 		done:          make(chan struct{}),
 		cfg:           cfg,
-		bm:            bm,
 		relabelConfig: relabel,
 		logger:        logger,
 		handler:       handler,
 		fetcher:       win_eventlog.NewEventFetcher(),
 	}
 
-	if cfg.Query == "" {
-		cfg.Query = "*"
+	for _, channel := range channels(cfg) {
+		sub, err := subscribe(positions, jobName, channel, query, cfg.BookmarkPath)
+		if err != nil {
+			t.close()
+			return nil, err
+		}
+		t.subscriptions = append(t.subscriptions, sub)
 	}
 
-	var subsHandle win_eventlog.EvtHandle
-	if bm.isNew {
-		subsHandle, err = win_eventlog.EvtSubscribe(cfg.EventlogName, cfg.Query)
-	} else {
-		subsHandle, err = win_eventlog.EvtSubscribeWithBookmark(cfg.EventlogName, cfg.Query, bm.handle)
+	if t.cfg.PollInterval == 0 {
+		t.cfg.PollInterval = 3 * time.Second
 	}
+	go t.loop()
+	return t, nil
+}
 
+// subscribe subscribes to the events of channel matching query, starting after its bookmark if any.
+func subscribe(positions positions.Positions, jobName, channel, query, bookmarkPath string) (*subscription, error) {
+	var store bookmarkStore = newPositionsStore(positions, jobName, channel)
+	if bookmarkPath != "" {
+		fileStore, err := newFileStore(bookmarkPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bookmark using path=%s: %w", bookmarkPath, err)
+		}
+		store = fileStore
+	}
+	bm, err := newBookMark(store)
 	if err != nil {
-		return nil, fmt.Errorf("error subscribing to windows events: %w", err)
+		_ = store.close()
+		return nil, fmt.Errorf("failed to load bookmark of eventlog %q: %w", channel, err)
 	}
-	t.subscription = subsHandle
 
-	if t.cfg.PollInterval == 0 {
-		t.cfg.PollInterval = 3 * time.Second
+	var handle win_eventlog.EvtHandle
+	if bm.isNew {
+		handle, err = win_eventlog.EvtSubscribe(channel, query)
+	} else {
+		handle, err = win_eventlog.EvtSubscribeWithBookmark(channel, query, bm.handle)
 	}
-	go t.loop()
-	return t, nil
+	if err != nil {
+		_ = bm.close()
+		return nil, fmt.Errorf("error subscribing to windows events of eventlog %q: %w", channel, err)
+	}
+	return &subscription{channel: channel, handle: handle, bm: bm}, nil
 }
 
 // loop fetches new events and send them to via the Loki client.
@@ -107,30 +135,13 @@ func (t *Target) loop() {
 	}()
 
 	for {
-
-	loop:
-		for {
-			// fetch events until there's no more.
-			events, handles, err := t.fetcher.FetchEvents(t.subscription, t.cfg.Locale)
-			if err != nil {
-				if err != win_eventlog.ERROR_NO_MORE_ITEMS {
-					t.err = err
-					level.Error(util_log.Logger).Log("msg", "error fetching events", "err", err)
-				}
-				break loop
+		var err error
+		for _, sub := range t.subscriptions {
+			if subErr := t.fetch(sub); subErr != nil {
+				err = subErr
 			}
-			t.err = nil
-			// we have received events to handle.
-			for i, entry := range t.renderEntries(events) {
-				t.handler.Chan() <- entry
-				if err := t.bm.save(handles[i]); err != nil {
-					t.err = err
-					level.Error(util_log.Logger).Log("msg", "error saving bookmark", "err", err)
-				}
-			}
-			win_eventlog.Close(handles)
-
 		}
+		t.err = err
 		// no more messages we wait for next poll timer tick.
 		select {
 		case <-t.done:
@@ -140,6 +151,30 @@ func (t *Target) loop() {
 	}
 }
 
+// fetch sends the events of sub until there's no more, saving its bookmark after each event.
+func (t *Target) fetch(sub *subscription) error {
+	var lastErr error
+	for {
+		events, handles, err := t.fetcher.FetchEvents(sub.handle, t.cfg.Locale)
+		if err != nil {
+			if err != win_eventlog.ERROR_NO_MORE_ITEMS {
+				level.Error(util_log.Logger).Log("msg", "error fetching events", "channel", sub.channel, "err", err)
+				return err
+			}
+			return lastErr
+		}
+		// we have received events to handle.
+		for i, entry := range t.renderEntries(events) {
+			t.handler.Chan() <- entry
+			if err := sub.bm.save(handles[i]); err != nil {
+				lastErr = err
+				level.Error(util_log.Logger).Log("msg", "error saving bookmark", "channel", sub.channel, "err", err)
+			}
+		}
+		win_eventlog.Close(handles)
+	}
+}
+
 // renderEntries renders Loki entries from windows event logs
 func (t *Target) renderEntries(events []win_eventlog.Event) []api.Entry {
 	res := make([]api.Entry, 0, len(events))
@@ -217,18 +252,33 @@ func (t *Target) Labels() model.LabelSet {
 
 // Details returns target-specific details.
 func (t *Target) Details() interface{} {
+	details := map[string]string{
+		"channels": strings.Join(channels(t.cfg), ","),
+	}
 	if t.err != nil {
-		return map[string]string{"err": t.err.Error()}
+		details["err"] = t.err.Error()
 	}
-	return map[string]string{}
+	return details
 }
 
 func (t *Target) Stop() error {
 	close(t.done)
 	t.wg.Wait()
 	t.handler.Stop()
-	if err := t.bm.close(); err != nil {
+	if err := t.close(); err != nil {
 		return err
 	}
 	return t.err
 }
+
+// close closes the subscriptions and their bookmarks.
+func (t *Target) close() error {
+	var err error
+	for _, sub := range t.subscriptions {
+		win_eventlog.Close([]win_eventlog.EvtHandle{sub.handle})
+		if closeErr := sub.bm.close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/loki/clients/pkg/promtail/api"
 	"github.com/grafana/loki/clients/pkg/promtail/client/fake"
+	"github.com/grafana/loki/clients/pkg/promtail/positions"
 	"github.com/grafana/loki/clients/pkg/promtail/scrapeconfig"
 	"github.com/grafana/loki/clients/pkg/promtail/targets/windows/win_eventlog"
 
@@ -31,6 +32,16 @@ func init() {
 	util_log.InitLogger(cfg, nil, true, false)
 }
 
+func newTestPositions(t *testing.T) positions.Positions {
+	ps, err := positions.New(util_log.Logger, positions.Config{
+		SyncPeriod:    10 * time.Second,
+		PositionsFile: t.TempDir() + "/positions.yml",
+	})
+	require.NoError(t, err)
+	t.Cleanup(ps.Stop)
+	return ps
+}
+
 // Test that you can use to generate event logs locally.
 func Test_WriteLog(t *testing.T) {
 	l, err := eventlog.Open("myapp")
@@ -59,7 +70,7 @@ func Test_GetCreateBookrmark(t *testing.T) {
 	}
 	client := fake.New(func() {})
 	defer client.Stop()
-	ta, err := New(util_log.Logger, client, nil, &scrapeconfig.WindowsEventsTargetConfig{
+	ta, err := New(util_log.Logger, client, nil, newTestPositions(t), "windows", &scrapeconfig.WindowsEventsTargetConfig{
 		BookmarkPath: "c:foo.xml",
 		PollInterval: time.Microsecond,
 		Query: `<QueryList>
@@ -93,7 +104,7 @@ func Test_GetCreateBookrmark(t *testing.T) {
 
 	client = fake.New(func() {})
 	defer client.Stop()
-	ta, err = New(util_log.Logger, client, nil, &scrapeconfig.WindowsEventsTargetConfig{
+	ta, err = New(util_log.Logger, client, nil, newTestPositions(t), "windows", &scrapeconfig.WindowsEventsTargetConfig{
 		BookmarkPath: "c:foo.xml",
 		PollInterval: time.Microsecond,
 		Query: `<QueryList>
@@ -122,7 +133,7 @@ func Test_GetCreateBookrmark(t *testing.T) {
 func Test_renderEntries(t *testing.T) {
 	client := fake.New(func() {})
 	defer client.Stop()
-	ta, err := New(util_log.Logger, client, nil, &scrapeconfig.WindowsEventsTargetConfig{
+	ta, err := New(util_log.Logger, client, nil, newTestPositions(t), "windows", &scrapeconfig.WindowsEventsTargetConfig{
 		Labels:               model.LabelSet{"job": "windows-events"},
 		EventlogName:         "Application",
 		Query:                "*",
@@ -161,3 +172,88 @@ func Test_renderEntries(t *testing.T) {
 		},
 	}, entries)
 }
+
+func Test_PositionsBookmarks(t *testing.T) {
+	const name = "mylog"
+	const supports = eventlog.Error | eventlog.Warning | eventlog.Info
+	err := eventlog.InstallAsEventCreate(name, supports)
+	if err != nil {
+		t.Logf("Install failed: %s", err)
+	}
+	defer func() {
+		err = eventlog.Remove(name)
+		if err != nil {
+			t.Fatalf("Remove failed: %s", err)
+		}
+	}()
+	l, err := eventlog.Open(name)
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+	ps := newTestPositions(t)
+	cfg := func() *scrapeconfig.WindowsEventsTargetConfig {
+		return &scrapeconfig.WindowsEventsTargetConfig{
+			EventlogNames: []string{"Application", "System"},
+			Query:         "*[System[Provider[@Name='mylog']]]",
+			PollInterval:  time.Microsecond,
+			LineFormat:    "message",
+			Labels:        model.LabelSet{"job": "windows-events"},
+		}
+	}
+	received := func(client *fake.Client, message string) func() bool {
+		return func() bool {
+			for _, entry := range client.Received() {
+				if entry.Line == message && entry.Labels["channel"] == "Application" {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	client := fake.New(func() {})
+	defer client.Stop()
+	ta, err := New(util_log.Logger, client, nil, ps, "windows", cfg())
+	require.NoError(t, err)
+
+	now := time.Now().String()
+	l.Error(1, now)
+	require.Eventually(t, received(client, now), 5*time.Second, 500*time.Millisecond)
+	require.NoError(t, ta.Stop())
+	require.NotEmpty(t, ps.GetString(positions.CursorKey("windows_events-windows-Application")))
+
+	// events written while the target is stopped are read from the saved bookmark.
+	now = time.Now().String()
+	l.Error(1, now)
+
+	client = fake.New(func() {})
+	defer client.Stop()
+	ta, err = New(util_log.Logger, client, nil, ps, "windows", cfg())
+	require.NoError(t, err)
+	require.Eventually(t, received(client, now), 5*time.Second, 500*time.Millisecond)
+	require.NoError(t, ta.Stop())
+}
+
+func Test_renderEntries_LineFormat(t *testing.T) {
+	event := win_eventlog.Event{
+		Channel:  "Application",
+		Computer: "local",
+		Message:  "message",
+		XML:      `<Event><System><Channel>Application</Channel></System></Event>`,
+	}
+	for format, line := range map[string]string{
+		"message": "message",
+		"xml":     `<Event><System><Channel>Application</Channel></System></Event>`,
+	} {
+		client := fake.New(func() {})
+		ta, err := New(util_log.Logger, client, nil, newTestPositions(t), "windows", &scrapeconfig.WindowsEventsTargetConfig{
+			EventlogName: "Application",
+			LineFormat:   format,
+		})
+		require.NoError(t, err)
+		entries := ta.renderEntries([]win_eventlog.Event{event})
+		require.NoError(t, ta.Stop())
+		require.Len(t, entries, 1)
+		require.Equal(t, line, entries[0].Line)
+	}
+}
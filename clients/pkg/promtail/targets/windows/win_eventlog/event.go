@@ -52,6 +52,8 @@ type Event struct {
 	LevelText     string
 	TaskText      string
 	OpcodeText    string
+	// XML is the XML rendering of the event.
+	XML string `xml:"-"`
 }
 
 // UserData Application-provided XML data
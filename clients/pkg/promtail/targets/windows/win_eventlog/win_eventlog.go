@@ -475,6 +475,7 @@ func (w *EventFetcher) renderEvent(eventHandle EvtHandle, lang uint32) (Event, e
 		return event, err
 	}
 	err = xml.Unmarshal([]byte(eventXML), &event)
+	event.XML = string(eventXML)
 	if err != nil {
 		// We can return event without most text values,
 		// that way we will not loose information
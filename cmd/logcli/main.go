@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io"
 	"log"
 	"math"
 	"net/url"
@@ -25,7 +26,8 @@ var (
 	app        = kingpin.New("logcli", "A command-line for loki.").Version(version.Print("logcli"))
 	quiet      = app.Flag("quiet", "Suppress query metadata").Default("false").Short('q').Bool()
 	statistics = app.Flag("stats", "Show query statistics").Default("false").Bool()
-	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl]. raw suppresses log labels and timestamp.").Default("default").Short('o').Enum("default", "raw", "jsonl")
+	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl, ndjson, parquet]. raw suppresses log labels and timestamp. ndjson and parquet write all the labels of the streams, for archiving the results.").Default("default").Short('o').Enum("default", "raw", "jsonl", "ndjson", "parquet")
+	outputDest = app.Flag("output-destination", "Write the output to this file, or upload it to this s3://bucket/key URL, instead of stdout. The S3 credentials and region are taken from the AWS environment variables and config files.").Default("").String()
 	timezone   = app.Flag("timezone", "Specify the timezone to use when formatting output timestamps [Local, UTC]").Default("Local").Short('z').Enum("Local", "UTC")
 	cpuProfile = app.Flag("cpuprofile", "Specify the location for writing a CPU profile.").Default("").String()
 	memProfile = app.Flag("memprofile", "Specify the location for writing a memory profile.").Default("").String()
@@ -41,6 +43,8 @@ returned in a few output modes:
 	raw: log line
 	default: log timestamp + log labels + log line
 	jsonl: JSON response from Loki API of log line
+	ndjson: JSON object with the nanosecond timestamp, all the stream labels and the log line
+	parquet: parquet file with the timestamp, stream labels and line columns
 
 The output of the log can be specified with the "-o" flag, for
 example, "-o raw" for the raw output format.
@@ -107,6 +111,10 @@ It is possible to send an empty label matcher '{}' to return all streams.
 
 Use the --analyze-labels flag to get a summary of the labels found in all streams.
 This is helpful to find high cardinality labels.
+
+Use the --analyze-cardinality flag to get a cardinality report of the streams,
+listing the labels with the most values, the streams with the most chunks and
+labels which could be dropped to reduce the size of the index.
 `)
 	seriesQuery = newSeriesQuery(seriesCmd)
 )
@@ -181,16 +189,28 @@ func main() {
 			ColoredOutput: rangeQuery.ColoredOutput,
 		}
 
-		out, err := output.NewLogOutput(os.Stdout, *outputMode, outputOptions)
+		if *outputMode == "parquet" && (*tail || *follow || rangeQuery.ParallelDuration > 0) {
+			log.Fatalf("The parquet output mode doesn't support tailing or parallel queries")
+		}
+
+		dest, err := output.NewDestination(*outputDest)
+		if err != nil {
+			log.Fatalf("Unable to open output destination: %s", err)
+		}
+
+		out, err := output.NewLogOutput(dest, *outputMode, outputOptions)
 		if err != nil {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
 		if *tail || *follow {
 			rangeQuery.TailQuery(time.Duration(*delayFor)*time.Second, queryClient, out)
+		} else if rangeQuery.ParallelDuration > 0 {
+			rangeQuery.DoQueryParallel(queryClient, out, dest, *statistics)
 		} else {
 			rangeQuery.DoQuery(queryClient, out, *statistics)
 		}
+		closeOutput(out, dest)
 	case instantQueryCmd.FullCommand():
 		location, err := time.LoadLocation(*timezone)
 		if err != nil {
@@ -203,12 +223,18 @@ func main() {
 			ColoredOutput: instantQuery.ColoredOutput,
 		}
 
-		out, err := output.NewLogOutput(os.Stdout, *outputMode, outputOptions)
+		dest, err := output.NewDestination(*outputDest)
+		if err != nil {
+			log.Fatalf("Unable to open output destination: %s", err)
+		}
+
+		out, err := output.NewLogOutput(dest, *outputMode, outputOptions)
 		if err != nil {
 			log.Fatalf("Unable to create log output: %s", err)
 		}
 
 		instantQuery.DoQuery(queryClient, out, *statistics)
+		closeOutput(out, dest)
 	case labelsCmd.FullCommand():
 		labelsQuery.DoLabels(queryClient)
 	case seriesCmd.FullCommand():
@@ -216,6 +242,18 @@ func main() {
 	}
 }
 
+// closeOutput completes the output, such as the footer of parquet files, and the destination it's written to.
+func closeOutput(out output.LogOutput, dest io.Closer) {
+	if c, ok := out.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Fatalf("Unable to complete the output: %s", err)
+		}
+	}
+	if err := dest.Close(); err != nil {
+		log.Fatalf("Unable to close the output destination: %s", err)
+	}
+}
+
 func newQueryClient(app *kingpin.Application) client.Client {
 
 	client := &client.DefaultClient{
@@ -308,6 +346,9 @@ func newSeriesQuery(cmd *kingpin.CmdClause) *seriesquery.SeriesQuery {
 	cmd.Flag("from", "Start looking for logs at this absolute time (inclusive)").StringVar(&from)
 	cmd.Flag("to", "Stop looking for logs at this absolute time (exclusive)").StringVar(&to)
 	cmd.Flag("analyze-labels", "Printout a summary of labels including count of label value combinations, useful for debugging high cardinality series").BoolVar(&q.AnalyzeLabels)
+	cmd.Flag("analyze-cardinality", "Printout a cardinality report with the labels having the most values, the streams having the most chunks and suggestions of labels to drop, useful for debugging index bloat").BoolVar(&q.AnalyzeCardinality)
+	cmd.Flag("top", "Number of labels and streams listed in each section of the cardinality report").Default("10").IntVar(&q.Top)
+	cmd.Flag("max-stats-streams", "Maximum number of streams whose chunks are counted in the cardinality report, one index stats request is sent per stream").Default("1000").IntVar(&q.MaxStatsStreams)
 
 	return q
 }
@@ -347,6 +388,12 @@ func newQuery(instant bool, cmd *kingpin.CmdClause) *query.Query {
 		cmd.Flag("step", "Query resolution step width, for metric queries. Evaluate the query at the specified step over the time range.").DurationVar(&q.Step)
 		cmd.Flag("interval", "Query interval, for log queries. Return entries at the specified interval, ignoring those between. **This parameter is experimental, please see Issue 1779**").DurationVar(&q.Interval)
 		cmd.Flag("batch", "Query batch size to use until 'limit' is reached").Default("1000").IntVar(&q.BatchSize)
+		cmd.Flag("parallel-duration", "Split the query range in parts of this duration, downloaded concurrently to part files. The limit applies to each part. Disabled when 0.").Default("0").DurationVar(&q.ParallelDuration)
+		cmd.Flag("parallel-max-workers", "Maximum number of parts downloaded concurrently when using --parallel-duration.").Default("1").IntVar(&q.ParallelMaxWorkers)
+		cmd.Flag("part-path-prefix", "Path prefix of the part files when using --parallel-duration. Defaults to a prefix in the temporary directory derived from the query.").StringVar(&q.PartPathPrefix)
+		cmd.Flag("overwrite-completed-parts", "Download again the parts completed by a previous run when using --parallel-duration.").Default("false").BoolVar(&q.OverwriteCompleted)
+		cmd.Flag("merge-parts", "Print the parts in order once they are completed when using --parallel-duration.").Default("false").BoolVar(&q.MergeParts)
+		cmd.Flag("keep-parts", "Keep the part files after merging them when using --merge-parts.").Default("false").BoolVar(&q.KeepParts)
 
 	}
 
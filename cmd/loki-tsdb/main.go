@@ -0,0 +1,426 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+// errIndicesDiffer is returned by `diff` so the exit code reflects whether the indices differ.
+var errIndicesDiffer = errors.New("indices differ")
+
+type command struct {
+	usage string
+	run   func(w io.Writer, args []string) error
+}
+
+var commands = map[string]command{
+	"series":      {"series [-match <selector>] <index>: list the series in an index", runSeries},
+	"chunks":      {"chunks [-match <selector>] <index>: print the chunk metas of each series", runChunks},
+	"cardinality": {"cardinality <index>: show the number of series and chunks per tenant", runCardinality},
+	"verify":      {"verify <index>...: verify checksums and the structure of one or more indices", runVerify},
+	"diff":        {"diff <index> <index>: print the series and chunks which differ between two indices", runDiff},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [args]\n\nCommands:\n", os.Args[0])
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Stdout, os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses the flags of a command, ensuring exactly n positional arguments remain
+// (or at least one when n is negative).
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if (n < 0 && fs.NArg() == 0) || (n >= 0 && fs.NArg() != n) {
+		return nil, errors.Errorf("%s: unexpected number of arguments, see -help", fs.Name())
+	}
+	return fs.Args(), nil
+}
+
+func matchersFlag(fs *flag.FlagSet) func() ([]*labels.Matcher, error) {
+	match := fs.String("match", "", "Only include series matching this label selector, e.g. `{app=\"foo\"}`.")
+	return func() ([]*labels.Matcher, error) {
+		if *match == "" {
+			return nil, nil
+		}
+		return syntax.ParseMatchers(*match)
+	}
+}
+
+// openIndex opens the index at path. Compressed indices, as shipped to object storage,
+// are identified by their extension and decompressed to a temporary file first.
+func openIndex(path string) (*index.Reader, error) {
+	codec := storage.CompressionForFile(path)
+	if codec == "" {
+		return index.NewFileReader(path)
+	}
+
+	tmp, err := os.CreateTemp("", "loki-tsdb-*")
+	if err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	// the reader keeps its own reference to the file, which outlives the removal on unix
+	defer os.Remove(tmp.Name())
+
+	if err := storage.DownloadFileFromStorage(tmp.Name(), codec, false, log.NewNopLogger(), func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}); err != nil {
+		return nil, errors.Wrapf(err, "decompressing %s", path)
+	}
+	return index.NewFileReader(tmp.Name())
+}
+
+// forEachSeries opens the index at path and calls fn for every series matching all matchers,
+// in the order they are stored.
+func forEachSeries(path string, matchers []*labels.Matcher, fn func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) error) error {
+	r, err := openIndex(path)
+	if err != nil {
+		return errors.Wrapf(err, "opening index %s", path)
+	}
+	defer r.Close()
+
+	return forEachReaderSeries(r, matchers, fn)
+}
+
+func forEachReaderSeries(r *index.Reader, matchers []*labels.Matcher, fn func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) error) error {
+	name, value := index.AllPostingsKey()
+	p, err := r.Postings(name, nil, value)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ls   labels.Labels
+		chks []index.ChunkMeta
+	)
+Outer:
+	for p.Next() {
+		fp, err := r.Series(p.At(), &ls, &chks)
+		if err != nil {
+			return errors.Wrapf(err, "reading series %d", p.At())
+		}
+
+		for _, m := range matchers {
+			if !m.Matches(ls.Get(m.Name)) {
+				continue Outer
+			}
+		}
+
+		if err := fn(ls, model.Fingerprint(fp), chks); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
+
+func formatTime(ts int64) string {
+	return model.Time(ts).Time().UTC().Format(time.RFC3339Nano)
+}
+
+func runSeries(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("series", flag.ExitOnError)
+	matchers := matchersFlag(fs)
+	paths, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ms, err := matchers()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FINGERPRINT\tCHUNKS\tLABELS")
+	if err := forEachSeries(paths[0], ms, func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) error {
+		_, err := fmt.Fprintf(tw, "%016x\t%d\t%s\n", uint64(fp), len(chks), ls)
+		return err
+	}); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+func runChunks(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("chunks", flag.ExitOnError)
+	matchers := matchersFlag(fs)
+	paths, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	ms, err := matchers()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if err := forEachSeries(paths[0], ms, func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) error {
+		fmt.Fprintf(tw, "%s fingerprint=%016x\n", ls, uint64(fp))
+		for _, chk := range chks {
+			fmt.Fprintf(tw, "  checksum=%08x\tfrom=%s\tthrough=%s\tkb=%d\tentries=%d\n", chk.Checksum, formatTime(chk.MinTime), formatTime(chk.MaxTime), chk.KB, chk.Entries)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
+
+type cardinality struct {
+	series, chunks int
+	kb, entries    uint64
+}
+
+func runCardinality(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("cardinality", flag.ExitOnError)
+	paths, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	byTenant := map[string]*cardinality{}
+	if err := forEachSeries(paths[0], nil, func(ls labels.Labels, _ model.Fingerprint, chks []index.ChunkMeta) error {
+		// Per tenant indices don't embed the tenant label
+		tenant := ls.Get(tsdb.TenantLabel)
+		c, ok := byTenant[tenant]
+		if !ok {
+			c = &cardinality{}
+			byTenant[tenant] = c
+		}
+		c.series++
+		c.chunks += len(chks)
+		for _, chk := range chks {
+			c.kb += uint64(chk.KB)
+			c.entries += uint64(chk.Entries)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	tenants := make([]string, 0, len(byTenant))
+	for tenant := range byTenant {
+		tenants = append(tenants, tenant)
+	}
+	// sort by descending number of series
+	sort.Slice(tenants, func(i, j int) bool {
+		if byTenant[tenants[i]].series != byTenant[tenants[j]].series {
+			return byTenant[tenants[i]].series > byTenant[tenants[j]].series
+		}
+		return tenants[i] < tenants[j]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TENANT\tSERIES\tCHUNKS\tKB\tENTRIES")
+	for _, tenant := range tenants {
+		c := byTenant[tenant]
+		name := tenant
+		if name == "" {
+			name = "<none>"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", name, c.series, c.chunks, c.kb, c.entries)
+	}
+	return tw.Flush()
+}
+
+func runVerify(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	paths, err := parseArgs(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, path := range paths {
+		if err := verify(w, path); err != nil {
+			failed++
+			fmt.Fprintf(w, "%s: FAILED: %v\n", path, err)
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("%d of %d indices failed verification", failed, len(paths))
+	}
+	return nil
+}
+
+// verify opens the index, which validates its header and TOC checksum,
+// then decodes every series, ensuring series, labels and chunks are correctly ordered.
+func verify(w io.Writer, path string) error {
+	r, err := openIndex(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var (
+		series, chunks int
+		prevFp         model.Fingerprint
+		prevLs         labels.Labels
+	)
+	if err := forEachReaderSeries(r, nil, func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) error {
+		if series > 0 && (fp < prevFp || (fp == prevFp && labels.Compare(ls, prevLs) <= 0)) {
+			return errors.Errorf("series %s is out of order", ls)
+		}
+		if !sort.SliceIsSorted(ls, func(i, j int) bool { return ls[i].Name < ls[j].Name }) {
+			return errors.Errorf("labels of series %s are not sorted", ls)
+		}
+		for _, chk := range chks {
+			if chk.MinTime > chk.MaxTime {
+				return errors.Errorf("series %s: chunk %08x has from=%d after through=%d", ls, chk.Checksum, chk.MinTime, chk.MaxTime)
+			}
+		}
+		if !sort.IsSorted(index.ChunkMetas(chks)) {
+			return errors.Errorf("series %s: chunks are out of order", ls)
+		}
+
+		series++
+		chunks += len(chks)
+		prevFp, prevLs = fp, ls.Copy()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	from, through := r.Bounds()
+	fmt.Fprintf(w, "%s: OK version=%d checksum=%08x series=%d chunks=%d from=%s through=%s\n",
+		path, r.Version(), r.Checksum(), series, chunks, formatTime(from), formatTime(through))
+	return nil
+}
+
+type seriesEntry struct {
+	ls   labels.Labels
+	chks map[index.ChunkMeta]struct{}
+}
+
+func loadSeries(path string) (map[string]*seriesEntry, error) {
+	res := map[string]*seriesEntry{}
+	err := forEachSeries(path, nil, func(ls labels.Labels, _ model.Fingerprint, chks []index.ChunkMeta) error {
+		e := &seriesEntry{ls: ls.Copy(), chks: make(map[index.ChunkMeta]struct{}, len(chks))}
+		for _, chk := range chks {
+			e.chks[chk] = struct{}{}
+		}
+		res[ls.String()] = e
+		return nil
+	})
+	return res, err
+}
+
+func runDiff(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	paths, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	a, err := loadSeries(paths[0])
+	if err != nil {
+		return err
+	}
+	b, err := loadSeries(paths[1])
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		ea, inA := a[k]
+		eb, inB := b[k]
+		switch {
+		case !inB:
+			lines = append(lines, fmt.Sprintf("- %s (%d chunks)", k, len(ea.chks)))
+		case !inA:
+			lines = append(lines, fmt.Sprintf("+ %s (%d chunks)", k, len(eb.chks)))
+		default:
+			removed, added := chunkDiff(ea.chks, eb.chks), chunkDiff(eb.chks, ea.chks)
+			if len(removed) == 0 && len(added) == 0 {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("~ %s", k))
+			for _, chk := range removed {
+				lines = append(lines, "  - "+formatChunk(chk))
+			}
+			for _, chk := range added {
+				lines = append(lines, "  + "+formatChunk(chk))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+	return errIndicesDiffer
+}
+
+// chunkDiff returns the chunks in a which aren't in b, in order.
+func chunkDiff(a, b map[index.ChunkMeta]struct{}) (res index.ChunkMetas) {
+	for chk := range a {
+		if _, ok := b[chk]; !ok {
+			res = append(res, chk)
+		}
+	}
+	sort.Sort(res)
+	return res
+}
+
+func formatChunk(chk index.ChunkMeta) string {
+	return fmt.Sprintf("checksum=%08x from=%s through=%s kb=%d entries=%d", chk.Checksum, formatTime(chk.MinTime), formatTime(chk.MaxTime), chk.KB, chk.Entries)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+// fileIdentifier places the built index at an absolute path
+type fileIdentifier string
+
+func (f fileIdentifier) Name() string { return filepath.Base(string(f)) }
+func (f fileIdentifier) Path() string { return string(f) }
+
+type testSeries struct {
+	ls   labels.Labels
+	chks []index.ChunkMeta
+}
+
+func buildIndex(t *testing.T, dir string, series []testSeries) string {
+	b := tsdb.NewBuilder()
+	for _, s := range series {
+		b.AddSeries(s.ls, model.Fingerprint(s.ls.Hash()), s.chks)
+	}
+
+	id, err := b.Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) tsdb.Identifier {
+		return fileIdentifier(filepath.Join(dir, "index.tsdb"))
+	})
+	require.Nil(t, err)
+	return id.Path()
+}
+
+var testData = []testSeries{
+	{
+		ls: labels.FromStrings("app", "foo", tsdb.TenantLabel, "tenant1"),
+		chks: []index.ChunkMeta{
+			{Checksum: 1, MinTime: 1, MaxTime: 10, KB: 1, Entries: 10},
+			{Checksum: 2, MinTime: 5, MaxTime: 20, KB: 2, Entries: 20},
+		},
+	},
+	{
+		ls: labels.FromStrings("app", "bar", tsdb.TenantLabel, "tenant1"),
+		chks: []index.ChunkMeta{
+			{Checksum: 3, MinTime: 1, MaxTime: 10, KB: 1, Entries: 10},
+		},
+	},
+	{
+		ls: labels.FromStrings("app", "foo", tsdb.TenantLabel, "tenant2"),
+		chks: []index.ChunkMeta{
+			{Checksum: 4, MinTime: 1, MaxTime: 10, KB: 4, Entries: 40},
+		},
+	},
+}
+
+func TestSeries(t *testing.T) {
+	path := buildIndex(t, t.TempDir(), testData)
+
+	var buf bytes.Buffer
+	require.Nil(t, runSeries(&buf, []string{"-match", `{app="foo"}`, path}))
+
+	out := buf.String()
+	require.Contains(t, out, `{__loki_tenant__="tenant1", app="foo"}`)
+	require.Contains(t, out, `{__loki_tenant__="tenant2", app="foo"}`)
+	require.NotContains(t, out, `app="bar"`)
+}
+
+func TestCardinality(t *testing.T) {
+	path := buildIndex(t, t.TempDir(), testData)
+
+	var buf bytes.Buffer
+	require.Nil(t, runCardinality(&buf, []string{path}))
+	require.Equal(t, `TENANT   SERIES  CHUNKS  KB  ENTRIES
+tenant1  2       3       4   40
+tenant2  1       1       4   40
+`, buf.String())
+}
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := buildIndex(t, dir, testData)
+
+	var buf bytes.Buffer
+	require.Nil(t, runVerify(&buf, []string{path}))
+	require.Contains(t, buf.String(), "OK")
+
+	// truncating the file invalidates the TOC checksum
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	corrupt := filepath.Join(dir, "corrupt")
+	require.Nil(t, os.WriteFile(corrupt, data[:len(data)-1], 0o644))
+
+	buf.Reset()
+	require.Error(t, runVerify(&buf, []string{path, corrupt}))
+	require.Contains(t, buf.String(), corrupt+": FAILED")
+}
+
+func TestDiff(t *testing.T) {
+	a := buildIndex(t, t.TempDir(), testData)
+
+	var buf bytes.Buffer
+	require.Nil(t, runDiff(&buf, []string{a, a}))
+	require.Empty(t, buf.String())
+
+	modified := []testSeries{
+		{
+			// one chunk removed, one added
+			ls: testData[0].ls,
+			chks: []index.ChunkMeta{
+				testData[0].chks[0],
+				{Checksum: 5, MinTime: 1, MaxTime: 10, KB: 1, Entries: 10},
+			},
+		},
+		// testData[1] removed
+		testData[2],
+		{
+			ls:   labels.FromStrings("app", "baz", tsdb.TenantLabel, "tenant2"),
+			chks: []index.ChunkMeta{{Checksum: 6, MinTime: 1, MaxTime: 10}},
+		},
+	}
+	b := buildIndex(t, t.TempDir(), modified)
+
+	require.Equal(t, errIndicesDiffer, runDiff(&buf, []string{a, b}))
+	require.Equal(t, `- {__loki_tenant__="tenant1", app="bar"} (1 chunks)
+~ {__loki_tenant__="tenant1", app="foo"}
+  - checksum=00000002 from=1970-01-01T00:00:00.005Z through=1970-01-01T00:00:00.02Z kb=2 entries=20
+  + checksum=00000005 from=1970-01-01T00:00:00.001Z through=1970-01-01T00:00:00.01Z kb=1 entries=10
++ {__loki_tenant__="tenant2", app="baz"} (1 chunks)
+`, buf.String())
+}
+
+func TestCompressedIndex(t *testing.T) {
+	path := buildIndex(t, t.TempDir(), testData)
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, err = gw.Write(data)
+	require.Nil(t, err)
+	require.Nil(t, gw.Close())
+
+	zw, err := zstd.NewWriter(nil)
+	require.Nil(t, err)
+	zstded := zw.EncodeAll(data, nil)
+	require.Nil(t, zw.Close())
+
+	for ext, compressed := range map[string][]byte{".gz": gzipped.Bytes(), ".zst": zstded} {
+		t.Run(ext, func(t *testing.T) {
+			compressedPath := path + ext
+			require.Nil(t, os.WriteFile(compressedPath, compressed, 0o644))
+
+			var buf bytes.Buffer
+			require.Nil(t, runCardinality(&buf, []string{compressedPath}))
+			require.Equal(t, `TENANT   SERIES  CHUNKS  KB  ENTRIES
+tenant1  2       3       4   40
+tenant2  1       1       4   40
+`, buf.String())
+
+			buf.Reset()
+			require.Nil(t, runVerify(&buf, []string{compressedPath}))
+			require.Contains(t, buf.String(), "OK")
+		})
+	}
+}
@@ -24,6 +24,14 @@ import (
 func main() {
 	var config loki.ConfigWrapper
 
+	if len(os.Args) > 1 && os.Args[1] == migrateIndexCommand {
+		if err := migrateIndex(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "migrating index: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if loki.PrintVersion(os.Args[1:]) {
 		fmt.Println(version.Print("loki"))
 		os.Exit(0)
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/loki"
+	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/config"
+	index_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/migrate"
+	"github.com/grafana/loki/pkg/util/cfg"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const migrateIndexCommand = "migrate-index"
+
+// migrateIndex implements `loki migrate-index`, which converts the index of the periods using the boltdb-shipper
+// store to TSDB, uploading it where the TSDB shipper expects it so that those periods can then be switched to the
+// tsdb store without re-ingesting their logs.
+func migrateIndex(args []string) error {
+	fs := flag.NewFlagSet(migrateIndexCommand, flag.ExitOnError)
+	from := fs.String("migrate-index.from", "", "Day of the first table to migrate, in the YYYY-MM-DD format. Defaults to the start of the first boltdb-shipper period.")
+	to := fs.String("migrate-index.to", "", "Day of the last table to migrate, in the YYYY-MM-DD format. Defaults to yesterday, the tables still being written to must not be migrated.")
+	workingDir := fs.String("migrate-index.working-directory", filepath.Join(os.TempDir(), "loki-migrate-index"), "Directory where the boltdb-shipper index files are downloaded and the TSDBs are built.")
+
+	// the config file loader rejects unknown flags, so the flags of the command are parsed on their own
+	ownArgs, configArgs := splitMigrateIndexArgs(args)
+	if err := fs.Parse(ownArgs); err != nil {
+		return err
+	}
+
+	var lokiConfig loki.ConfigWrapper
+	if err := cfg.DynamicUnmarshal(&lokiConfig, configArgs, flag.NewFlagSet("config-file-loader", flag.ExitOnError)); err != nil {
+		return fmt.Errorf("failed parsing config: %w", err)
+	}
+	util_log.InitLogger(&lokiConfig.Server, prometheus.DefaultRegisterer, false, true)
+	if err := lokiConfig.Validate(); err != nil {
+		return fmt.Errorf("validating config: %w", err)
+	}
+
+	start, end := model.Time(0), model.Now().Add(-24*time.Hour)
+	if *from != "" {
+		t, err := time.Parse("2006-01-02", *from)
+		if err != nil {
+			return fmt.Errorf("invalid -migrate-index.from: %w", err)
+		}
+		start = model.TimeFromUnix(t.Unix())
+	}
+	if *to != "" {
+		t, err := time.Parse("2006-01-02", *to)
+		if err != nil {
+			return fmt.Errorf("invalid -migrate-index.to: %w", err)
+		}
+		end = model.TimeFromUnix(t.Unix())
+	}
+
+	storageCfg := lokiConfig.StorageConfig
+	if storageCfg.BoltDBShipperConfig.SharedStoreKeyPrefix == storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix {
+		// the tsdb shipper would otherwise try to open the boltdb files of the tables as TSDBs
+		return fmt.Errorf("the TSDB shipper must use a different shared store key prefix than the boltdb-shipper, both use %s", storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix)
+	}
+
+	clientMetrics := storage.NewClientMetrics()
+	defer clientMetrics.Unregister()
+
+	ctx := context.Background()
+	periods := lokiConfig.SchemaConfig.Configs
+	for i, period := range periods {
+		if period.IndexType != config.BoltDBShipperType {
+			continue
+		}
+
+		periodEnd := end
+		if i < len(periods)-1 && periods[i+1].From.Time.Add(-time.Millisecond) < periodEnd {
+			periodEnd = periods[i+1].From.Time.Add(-time.Millisecond)
+		}
+		periodStart := period.From.Time
+		if start > periodStart {
+			periodStart = start
+		}
+		if periodStart > periodEnd {
+			continue
+		}
+
+		source, err := newIndexStorageClient(storageCfg.BoltDBShipperConfig.SharedStoreType, storageCfg.BoltDBShipperConfig.SharedStoreKeyPrefix, period, storageCfg, clientMetrics)
+		if err != nil {
+			return err
+		}
+		dest, err := newIndexStorageClient(storageCfg.TSDBShipperConfig.SharedStoreType, storageCfg.TSDBShipperConfig.SharedStoreKeyPrefix, period, storageCfg, clientMetrics)
+		if err != nil {
+			return err
+		}
+		migrator := migrate.NewBoltDBMigrator(source, dest, storageCfg.TSDBShipperConfig.IndexCompression, *workingDir, util_log.Logger)
+
+		tablePeriod := model.Time(period.IndexTables.Period.Milliseconds())
+		for ts := periodStart - periodStart%tablePeriod; ts <= periodEnd; ts += tablePeriod {
+			tableName := period.IndexTables.TableFor(ts)
+			res, err := migrator.MigrateTable(ctx, tableName, period)
+			if err != nil {
+				return fmt.Errorf("migrating table %s: %w", tableName, err)
+			}
+			level.Info(util_log.Logger).Log("msg", "migrated table", "table", tableName, "tenants", res.Tenants, "skipped_tenants", res.SkippedTenants, "series", res.Series, "chunks", res.Chunks)
+		}
+
+		source.Stop()
+		dest.Stop()
+	}
+
+	return nil
+}
+
+// splitMigrateIndexArgs separates the flags of the command, along with their values, from the config flags.
+func splitMigrateIndexArgs(args []string) (own, config []string) {
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		if !strings.HasPrefix(name, migrateIndexCommand+".") {
+			config = append(config, args[i])
+			continue
+		}
+		own = append(own, args[i])
+		if !strings.Contains(name, "=") && i+1 < len(args) {
+			i++
+			own = append(own, args[i])
+		}
+	}
+	return own, config
+}
+
+// newIndexStorageClient creates the client of a shipper's index, stored in the period's object store unless
+// the shipper has its own.
+func newIndexStorageClient(storeType, prefix string, period config.PeriodConfig, storageCfg storage.Config, clientMetrics storage.ClientMetrics) (index_storage.Client, error) {
+	if storeType == "" {
+		storeType = period.ObjectType
+	}
+	objectClient, err := storage.NewObjectClient(storeType, storageCfg, nil, clientMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("creating object client for %s: %w", storeType, err)
+	}
+	return index_storage.NewIndexStorageClient(objectClient, prefix), nil
+}
@@ -0,0 +1,87 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/willf/bloom"
+)
+
+const (
+	// blockBloomNGramLength is the length in bytes of the n-grams of the lines added to the bloom filters of the
+	// blocks of chunks of format v5+. Line filters shorter than that can't skip blocks.
+	blockBloomNGramLength = 3
+	// blockBloomFalsePositiveRate is the false positive rate of a single n-gram lookup.
+	blockBloomFalsePositiveRate = 0.02
+)
+
+type requiredLineFiltersKey struct{}
+
+// WithRequiredLineFilters returns a context telling the iterators of chunks of format v5+ that the query only
+// selects lines containing all the given strings, which allows them to skip the blocks whose bloom filter shows
+// that none of their lines does.
+func WithRequiredLineFilters(ctx context.Context, contains []string) context.Context {
+	if len(contains) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requiredLineFiltersKey{}, contains)
+}
+
+func requiredLineFilters(ctx context.Context) []string {
+	contains, _ := ctx.Value(requiredLineFiltersKey{}).([]string)
+	return contains
+}
+
+// blockBloomBuilder collects the distinct n-grams of the lines of a block to size its bloom filter.
+type blockBloomBuilder struct {
+	ngrams map[[blockBloomNGramLength]byte]struct{}
+}
+
+func newBlockBloomBuilder() *blockBloomBuilder {
+	return &blockBloomBuilder{ngrams: map[[blockBloomNGramLength]byte]struct{}{}}
+}
+
+func (b *blockBloomBuilder) add(line string) {
+	var ngram [blockBloomNGramLength]byte
+	for i := 0; i+blockBloomNGramLength <= len(line); i++ {
+		copy(ngram[:], line[i:i+blockBloomNGramLength])
+		b.ngrams[ngram] = struct{}{}
+	}
+}
+
+// build returns the encoded bloom filter of the n-grams added so far, or nil if the lines had none.
+func (b *blockBloomBuilder) build() ([]byte, error) {
+	if len(b.ngrams) == 0 {
+		return nil, nil
+	}
+	f := bloom.NewWithEstimates(uint(len(b.ngrams)), blockBloomFalsePositiveRate)
+	for ngram := range b.ngrams {
+		f.Add(ngram[:])
+	}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockMayContainAll returns false if the encoded bloom filter of a block shows that at least one of the strings
+// is contained in no line of the block. Blocks without a filter, or with one which can't be decoded, may contain
+// anything.
+func blockMayContainAll(encoded []byte, contains []string) bool {
+	if len(encoded) == 0 || len(contains) == 0 {
+		return true
+	}
+	f := &bloom.BloomFilter{}
+	if _, err := f.ReadFrom(bytes.NewReader(encoded)); err != nil {
+		return true
+	}
+	for _, s := range contains {
+		for i := 0; i+blockBloomNGramLength <= len(s); i++ {
+			if !f.TestString(s[i : i+blockBloomNGramLength]) {
+				return false
+			}
+		}
+	}
+	return true
+}
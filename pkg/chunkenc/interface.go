@@ -21,6 +21,8 @@ var (
 	ErrInvalidSize     = errors.New("invalid size")
 	ErrInvalidFlag     = errors.New("invalid flag")
 	ErrInvalidChecksum = errors.New("invalid chunk checksum")
+
+	ErrNonIndexedLabelsUnsupported = errors.New("the head block format doesn't support non-indexed labels")
 )
 
 type errTooFarBehind struct {
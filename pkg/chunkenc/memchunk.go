@@ -31,6 +31,11 @@ const (
 	chunkFormatV1
 	chunkFormatV2
 	chunkFormatV3
+	// chunkFormatV4 stores the non-indexed labels of each entry after its line.
+	chunkFormatV4
+	// chunkFormatV5 stores the names and values of the non-indexed labels once per chunk, in a structured metadata
+	// section referenced by the entries, and a bloom filter of the n-grams of the lines of each block in its meta.
+	chunkFormatV5
 
 	DefaultChunkFormat = chunkFormatV3 // the currently used chunk format
 
@@ -43,7 +48,7 @@ const (
 	defaultBlockSize = 256 * 1024
 )
 
-var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt}
+var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithNonIndexedLabelsHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
 
 type HeadBlockFmt byte
 
@@ -55,6 +60,10 @@ func (f HeadBlockFmt) String() string {
 		return "ordered"
 	case f == UnorderedHeadBlockFmt:
 		return "unordered"
+	case f == UnorderedWithNonIndexedLabelsHeadBlockFmt:
+		return "unordered with non-indexed labels"
+	case f == UnorderedWithStructuredMetadataHeadBlockFmt:
+		return "unordered with structured metadata"
 	default:
 		return fmt.Sprintf("unknown: %v", byte(f))
 	}
@@ -65,7 +74,19 @@ func (f HeadBlockFmt) NewBlock() HeadBlock {
 	case f < UnorderedHeadBlockFmt:
 		return &headBlock{}
 	default:
-		return newUnorderedHeadBlock()
+		return newUnorderedHeadBlock(f)
+	}
+}
+
+// chunkFormat returns the format of the chunks created with a head block of this format.
+func (f HeadBlockFmt) chunkFormat() byte {
+	switch f {
+	case UnorderedWithNonIndexedLabelsHeadBlockFmt:
+		return chunkFormatV4
+	case UnorderedWithStructuredMetadataHeadBlockFmt:
+		return chunkFormatV5
+	default:
+		return DefaultChunkFormat
 	}
 }
 
@@ -77,6 +98,10 @@ const (
 	_
 	OrderedHeadBlockFmt
 	UnorderedHeadBlockFmt
+	UnorderedWithNonIndexedLabelsHeadBlockFmt
+	// UnorderedWithStructuredMetadataHeadBlockFmt is stored like UnorderedWithNonIndexedLabelsHeadBlockFmt,
+	// its blocks are cut in the chunk format v5.
+	UnorderedWithStructuredMetadataHeadBlockFmt
 )
 
 var magicNumber = uint32(0x12EE56A)
@@ -115,6 +140,9 @@ type MemChunk struct {
 	format   byte
 	encoding Encoding
 	headFmt  HeadBlockFmt
+
+	// The names and values of the non-indexed labels of the blocks, only used by chunks of format v5+.
+	symbols *symbolizer
 }
 
 type block struct {
@@ -126,6 +154,8 @@ type block struct {
 
 	offset           int // The offset of the block in the chunk.
 	uncompressedSize int // Total uncompressed size in bytes when the chunk is cut.
+
+	bloom []byte // The bloom filter of the n-grams of the lines, only stored by chunks of format v5+.
 }
 
 // This block holds the un-compressed entries. Once it has enough data, this is
@@ -159,7 +189,10 @@ func (hb *headBlock) Reset() {
 
 func (hb *headBlock) Bounds() (int64, int64) { return hb.mint, hb.maxt }
 
-func (hb *headBlock) Append(ts int64, line string) error {
+func (hb *headBlock) Append(ts int64, line string, nonIndexedLabels ...labels.Label) error {
+	if len(nonIndexedLabels) > 0 {
+		return ErrNonIndexedLabelsUnsupported
+	}
 	if !hb.IsEmpty() && hb.maxt > ts {
 		return ErrOutOfOrder
 	}
@@ -315,7 +348,7 @@ func (hb *headBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 	if version < UnorderedHeadBlockFmt {
 		return hb, nil
 	}
-	out := newUnorderedHeadBlock()
+	out := newUnorderedHeadBlock(version)
 
 	for _, e := range hb.entries {
 		if err := out.Append(e.t, e.s); err != nil {
@@ -332,17 +365,21 @@ type entry struct {
 
 // NewMemChunk returns a new in-mem chunk.
 func NewMemChunk(enc Encoding, head HeadBlockFmt, blockSize, targetSize int) *MemChunk {
-	return &MemChunk{
+	c := &MemChunk{
 		blockSize:  blockSize,  // The blockSize in bytes.
 		targetSize: targetSize, // Desired chunk size in compressed bytes
 		blocks:     []block{},
 
-		format: DefaultChunkFormat,
+		format: head.chunkFormat(),
 		head:   head.NewBlock(),
 
 		encoding: enc,
 		headFmt:  head,
 	}
+	if c.format >= chunkFormatV5 {
+		c.symbols = newSymbolizer()
+	}
+	return c
 }
 
 // NewByteChunk returns a MemChunk on the passed bytes.
@@ -366,26 +403,62 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 	switch version {
 	case chunkFormatV1:
 		bc.encoding = EncGZIP
-	case chunkFormatV2, chunkFormatV3:
+	case chunkFormatV2, chunkFormatV3, chunkFormatV4, chunkFormatV5:
 		// format v2+ has a byte for block encoding.
 		enc := Encoding(db.byte())
 		if db.err() != nil {
 			return nil, errors.Wrap(db.err(), "verifying encoding")
 		}
 		bc.encoding = enc
+		switch version {
+		case chunkFormatV4:
+			// entries appended to the chunk, e.g. when rebounding it, need to keep their non-indexed labels.
+			bc.headFmt = UnorderedWithNonIndexedLabelsHeadBlockFmt
+			bc.head = bc.headFmt.NewBlock()
+		case chunkFormatV5:
+			bc.headFmt = UnorderedWithStructuredMetadataHeadBlockFmt
+			bc.head = bc.headFmt.NewBlock()
+		}
 	default:
 		return nil, errors.Errorf("invalid version %d", version)
 	}
 
+	// format v5+ stores the offset of the structured metadata section before the metasOffset.
+	trailerSize := 8
+	if version >= chunkFormatV5 {
+		trailerSize += 8
+	}
+	if len(b) < trailerSize+4 {
+		return nil, errors.New("chunk too short")
+	}
 	metasOffset := binary.BigEndian.Uint64(b[len(b)-8:])
-	mb := b[metasOffset : len(b)-(8+4)] // storing the metasOffset + checksum of meta
+	if metasOffset > uint64(len(b)-(trailerSize+4)) {
+		return nil, errors.Errorf("invalid metas offset %d", metasOffset)
+	}
+	mb := b[metasOffset : len(b)-(trailerSize+4)] // storing the metasOffset + checksum of meta
 	db = decbuf{b: mb}
 
-	expCRC := binary.BigEndian.Uint32(b[len(b)-(8+4):])
+	expCRC := binary.BigEndian.Uint32(b[len(b)-(trailerSize+4):])
 	if expCRC != db.crc32() {
 		return nil, ErrInvalidChecksum
 	}
 
+	if version >= chunkFormatV5 {
+		symbolsOffset := binary.BigEndian.Uint64(b[len(b)-16:])
+		if symbolsOffset+4 > metasOffset {
+			return nil, errors.Errorf("invalid structured metadata offset %d", symbolsOffset)
+		}
+		sb := b[symbolsOffset : metasOffset-4]
+		if binary.BigEndian.Uint32(b[metasOffset-4:]) != crc32.Checksum(sb, castagnoliTable) {
+			return nil, ErrInvalidChecksum
+		}
+		symbols, err := symbolizerFromBytes(sb, getReaderPool(bc.encoding))
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding structured metadata")
+		}
+		bc.symbols = symbols
+	}
+
 	// Read the number of blocks.
 	num := db.uvarint()
 	bc.blocks = make([]block, 0, num)
@@ -401,10 +474,21 @@ func NewByteChunk(b []byte, blockSize, targetSize int) (*MemChunk, error) {
 
 		// Read offset and length.
 		blk.offset = db.uvarint()
-		if version == chunkFormatV3 {
+		if version >= chunkFormatV3 {
 			blk.uncompressedSize = db.uvarint()
 		}
 		l := db.uvarint()
+		if version >= chunkFormatV5 {
+			if bloom := db.bytes(db.uvarint()); len(bloom) > 0 {
+				blk.bloom = bloom
+			}
+		}
+		if db.err() != nil {
+			return nil, errors.Wrap(db.err(), "decoding block meta")
+		}
+		if blk.offset+l+4 > len(b) {
+			return nil, errors.Errorf("invalid block offset %d", blk.offset)
+		}
 		blk.b = b[blk.offset : blk.offset+l]
 
 		// Verify checksums.
@@ -460,15 +544,23 @@ func (c *MemChunk) BytesSize() int {
 		size += binary.MaxVarintLen64 // mint
 		size += binary.MaxVarintLen64 // maxt
 		size += binary.MaxVarintLen32 // offset
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			size += binary.MaxVarintLen32 // uncompressed size
 		}
 		size += binary.MaxVarintLen32 // len(b)
+		if c.format >= chunkFormatV5 {
+			size += binary.MaxVarintLen32 + len(b.bloom) // len(bloom) + bloom
+		}
 	}
 
 	// blockmeta
 	size += binary.MaxVarintLen32 // len  blocks
 
+	if c.format >= chunkFormatV5 {
+		size += c.symbols.uncompressedSize() + crc32.Size // structured metadata + crc
+		size += 8                                         // structured metadata offset
+	}
+
 	size += crc32.Size // metablock crc
 	size += 8          // metaoffset
 	return size
@@ -523,6 +615,24 @@ func (c *MemChunk) WriteTo(w io.Writer) (int64, error) {
 		offset += int64(n)
 	}
 
+	// Write the structured metadata section.
+	symbolsOffset := offset
+	if c.format >= chunkFormatV5 {
+		sb, err := c.symbols.serialise(getWriterPool(c.encoding))
+		if err != nil {
+			return offset, errors.Wrap(err, "serialise structured metadata")
+		}
+		crc32Hash.Reset()
+		if _, err := crc32Hash.Write(sb); err != nil {
+			return offset, errors.Wrap(err, "write structured metadata")
+		}
+		n, err := w.Write(crc32Hash.Sum(sb))
+		if err != nil {
+			return offset, errors.Wrap(err, "write structured metadata")
+		}
+		offset += int64(n)
+	}
+
 	metasOffset := offset
 	// Write the number of blocks.
 	eb.reset()
@@ -534,10 +644,14 @@ func (c *MemChunk) WriteTo(w io.Writer) (int64, error) {
 		eb.putVarint64(b.mint)
 		eb.putVarint64(b.maxt)
 		eb.putUvarint(b.offset)
-		if c.format == chunkFormatV3 {
+		if c.format >= chunkFormatV3 {
 			eb.putUvarint(b.uncompressedSize)
 		}
 		eb.putUvarint(len(b.b))
+		if c.format >= chunkFormatV5 {
+			eb.putUvarint(len(b.bloom))
+			eb.putBytes(b.bloom)
+		}
 	}
 	eb.putHash(crc32Hash)
 
@@ -547,8 +661,11 @@ func (c *MemChunk) WriteTo(w io.Writer) (int64, error) {
 	}
 	offset += int64(n)
 
-	// Write the metasOffset.
+	// Write the metasOffset, preceded by the offset of the structured metadata section for format v5+.
 	eb.reset()
+	if c.format >= chunkFormatV5 {
+		eb.putBE64int(int(symbolsOffset))
+	}
 	eb.putBE64int(int(metasOffset))
 	n, err = w.Write(eb.get())
 	if err != nil {
@@ -589,6 +706,10 @@ func MemchunkFromCheckpoint(chk, head []byte, desired HeadBlockFmt, blockSize in
 	if err != nil {
 		return nil, err
 	}
+	// The blocks of chunks storing non-indexed labels are cut from a head block which stores them as well.
+	if mc.format >= chunkFormatV4 {
+		desired = mc.headFmt
+	}
 	h, err := HeadFromCheckpoint(head, desired)
 	if err != nil {
 		return nil, err
@@ -604,6 +725,11 @@ func (c *MemChunk) Encoding() Encoding {
 	return c.encoding
 }
 
+// SupportsNonIndexedLabels tells if entries with non-indexed labels can be appended to the chunk.
+func (c *MemChunk) SupportsNonIndexedLabels() bool {
+	return c.format >= chunkFormatV4
+}
+
 // Size implements Chunk.
 func (c *MemChunk) Size() int {
 	ne := 0
@@ -674,7 +800,7 @@ func (c *MemChunk) Append(entry *logproto.Entry) error {
 		return ErrOutOfOrder
 	}
 
-	if err := c.head.Append(entryTimestamp, entry.Line); err != nil {
+	if err := c.head.Append(entryTimestamp, entry.Line, logproto.FromLabelAdaptersToLabels(entry.NonIndexedLabels)...); err != nil {
 		return err
 	}
 
@@ -722,6 +848,10 @@ func (c *MemChunk) reorder() error {
 }
 
 func (c *MemChunk) ConvertHead(desired HeadBlockFmt) error {
+	// The head block is cut into blocks of the chunk's format, which can't change.
+	if (desired.chunkFormat() >= chunkFormatV4 || c.format >= chunkFormatV4) && desired.chunkFormat() != c.format {
+		return errors.Errorf("cannot convert the %s head block of a chunk to %s", c.headFmt, desired)
+	}
 	if c.head != nil && c.head.Format() != desired {
 		newH, err := c.head.Convert(desired)
 		if err != nil {
@@ -740,7 +870,19 @@ func (c *MemChunk) cut() error {
 		return nil
 	}
 
-	b, err := c.head.Serialise(getWriterPool(c.encoding))
+	var (
+		b, bloom []byte
+		err      error
+	)
+	if c.format >= chunkFormatV5 {
+		hb, ok := c.head.(*unorderedHeadBlock)
+		if !ok {
+			return errors.Errorf("cannot cut the %s head block of a chunk of format v%d", c.head.Format(), c.format)
+		}
+		b, bloom, err = hb.serialiseWithSymbols(getWriterPool(c.encoding), c.symbols)
+	} else {
+		b, err = c.head.Serialise(getWriterPool(c.encoding))
+	}
 	if err != nil {
 		return err
 	}
@@ -752,6 +894,7 @@ func (c *MemChunk) cut() error {
 		mint:             mint,
 		maxt:             maxt,
 		uncompressedSize: c.head.UncompressedSize(),
+		bloom:            bloom,
 	})
 
 	c.cutBlockSize += len(b)
@@ -797,7 +940,7 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 		}
 		lastMax = b.maxt
 
-		blockItrs = append(blockItrs, encBlock{c.encoding, b}.Iterator(ctx, pipeline))
+		blockItrs = append(blockItrs, c.encBlock(b).Iterator(ctx, pipeline))
 	}
 
 	if !c.head.IsEmpty() {
@@ -871,7 +1014,7 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 			ordered = false
 		}
 		lastMax = b.maxt
-		its = append(its, encBlock{c.encoding, b}.SampleIterator(ctx, extractor))
+		its = append(its, c.encBlock(b).SampleIterator(ctx, extractor))
 	}
 
 	if !c.head.IsEmpty() {
@@ -903,7 +1046,7 @@ func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 
 	for _, b := range c.blocks {
 		if maxt >= b.mint && b.maxt >= mint {
-			blocks = append(blocks, encBlock{c.encoding, b})
+			blocks = append(blocks, c.encBlock(b))
 		}
 	}
 	return blocks
@@ -955,22 +1098,33 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
 // chances of chunk<>block encoding drift in the codebase as the latter is parameterized by the former.
 type encBlock struct {
-	enc Encoding
+	enc    Encoding
+	format byte
+	// the symbols referenced by the block, for format v5+.
+	symbols []string
 	block
 }
 
+func (c *MemChunk) encBlock(b block) encBlock {
+	eb := encBlock{enc: c.encoding, format: c.format, block: b}
+	if c.symbols != nil {
+		eb.symbols = c.symbols.snapshot()
+	}
+	return eb
+}
+
 func (b encBlock) Iterator(ctx context.Context, pipeline log.StreamPipeline) iter.EntryIterator {
-	if len(b.b) == 0 {
+	if len(b.b) == 0 || !blockMayContainAll(b.bloom, requiredLineFilters(ctx)) {
 		return iter.NoopIterator
 	}
-	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, pipeline)
+	return newEntryIterator(ctx, getReaderPool(b.enc), b.b, b.format, b.symbols, pipeline)
 }
 
 func (b encBlock) SampleIterator(ctx context.Context, extractor log.StreamSampleExtractor) iter.SampleIterator {
-	if len(b.b) == 0 {
+	if len(b.b) == 0 || !blockMayContainAll(b.bloom, requiredLineFilters(ctx)) {
 		return iter.NoopIterator
 	}
-	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, extractor)
+	return newSampleIterator(ctx, getReaderPool(b.enc), b.b, b.format, b.symbols, extractor)
 }
 
 func (b block) Offset() int {
@@ -1124,11 +1278,17 @@ type bufferedIterator struct {
 	buf      []byte // The buffer for a single entry.
 	currLine []byte // the current line, this is the same as the buffer but sliced the the line size.
 	currTs   int64
+	// the non-indexed labels of the current entry, only stored by chunks of format v4+.
+	currNonIndexedLabels labels.Labels
+	labelBuf             []byte // The buffer for reading non-indexed labels.
+	// the symbols referenced by the non-indexed labels of chunks of format v5+.
+	symbols []string
 
+	format byte
 	closed bool
 }
 
-func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte) *bufferedIterator {
+func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, symbols []string) *bufferedIterator {
 	stats := stats.FromContext(ctx)
 	stats.AddCompressedBytes(int64(len(b)))
 	return &bufferedIterator{
@@ -1136,6 +1296,8 @@ func newBufferedIterator(ctx context.Context, pool ReaderPool, b []byte) *buffer
 		origBytes: b,
 		reader:    nil, // will be initialized later
 		pool:      pool,
+		format:    format,
+		symbols:   symbols,
 	}
 }
 
@@ -1205,7 +1367,7 @@ func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
 		if si.buf != nil {
 			BytesBufferPool.Put(si.buf)
 		}
-		si.buf = BytesBufferPool.Get(lineSize).([]byte)
+		si.buf = getLineBuffer(lineSize)
 		if lineSize > cap(si.buf) {
 			si.err = fmt.Errorf("could not get a line buffer of size %d, actual %d", lineSize, cap(si.buf))
 			return 0, nil, false
@@ -1231,9 +1393,123 @@ func (si *bufferedIterator) moveNext() (int64, []byte, bool) {
 			return 0, nil, false
 		}
 	}
+
+	si.currNonIndexedLabels = nil
+	switch {
+	case si.format >= chunkFormatV5:
+		if si.currNonIndexedLabels, si.err = si.readNonIndexedLabelRefs(); si.err != nil {
+			return 0, nil, false
+		}
+	case si.format == chunkFormatV4:
+		if si.currNonIndexedLabels, si.err = si.readNonIndexedLabels(); si.err != nil {
+			return 0, nil, false
+		}
+	}
 	return ts, si.buf[:lineSize], true
 }
 
+// readNonIndexedLabels reads the count of non-indexed labels following a line
+// and the length prefixed name and value of each of them.
+func (si *bufferedIterator) readNonIndexedLabels() (labels.Labels, error) {
+	n, err := si.readUvarint()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	lbs := make(labels.Labels, n)
+	for i := range lbs {
+		if lbs[i].Name, err = si.readString(); err != nil {
+			return nil, err
+		}
+		if lbs[i].Value, err = si.readString(); err != nil {
+			return nil, err
+		}
+	}
+	return lbs, nil
+}
+
+// readNonIndexedLabelRefs reads the count of non-indexed labels following a line
+// and the references to the symbols of the name and value of each of them.
+func (si *bufferedIterator) readNonIndexedLabelRefs() (labels.Labels, error) {
+	n, err := si.readUvarint()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	if n > uint64(len(si.symbols)) {
+		return nil, fmt.Errorf("invalid data in chunk")
+	}
+	lbs := make(labels.Labels, n)
+	for i := range lbs {
+		if lbs[i].Name, err = si.readSymbol(); err != nil {
+			return nil, err
+		}
+		if lbs[i].Value, err = si.readSymbol(); err != nil {
+			return nil, err
+		}
+	}
+	return lbs, nil
+}
+
+// readSymbol reads the reference to a symbol and returns it.
+func (si *bufferedIterator) readSymbol() (string, error) {
+	ref, err := si.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	if ref >= uint64(len(si.symbols)) {
+		return "", fmt.Errorf("invalid symbol reference %d in chunk, %d symbols", ref, len(si.symbols))
+	}
+	return si.symbols[ref], nil
+}
+
+// readUvarint reads a uvarint, first from the bytes left in the read buffer.
+func (si *bufferedIterator) readUvarint() (uint64, error) {
+	for {
+		v, n := binary.Uvarint(si.readBuf[:si.readBufValid])
+		if n > 0 {
+			si.readBufValid = copy(si.readBuf[:], si.readBuf[n:si.readBufValid])
+			return v, nil
+		}
+		if n < 0 || si.readBufValid == len(si.readBuf) {
+			return 0, fmt.Errorf("invalid data in chunk")
+		}
+		r, err := si.reader.Read(si.readBuf[si.readBufValid:])
+		si.readBufValid += r
+		if err != nil && (err != io.EOF || r == 0) {
+			if err == io.EOF {
+				return 0, fmt.Errorf("invalid data in chunk")
+			}
+			return 0, err
+		}
+	}
+}
+
+// readString reads a length prefixed string, first from the bytes left in the read buffer.
+func (si *bufferedIterator) readString() (string, error) {
+	l, err := si.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	if l >= maxLineLength {
+		return "", fmt.Errorf("non-indexed label too long %d, maximum %d", l, maxLineLength)
+	}
+	if cap(si.labelBuf) < int(l) {
+		if si.labelBuf != nil {
+			BytesBufferPool.Put(si.labelBuf)
+		}
+		si.labelBuf = getLineBuffer(int(l))
+	}
+	b := si.labelBuf[:l]
+	n := copy(b, si.readBuf[:si.readBufValid])
+	si.readBufValid = copy(si.readBuf[:], si.readBuf[n:si.readBufValid])
+	if _, err := io.ReadFull(si.reader, b[n:]); err != nil {
+		if err == io.EOF {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return string(b), nil
+}
+
 func (si *bufferedIterator) Error() error { return si.err }
 
 func (si *bufferedIterator) Close() error {
@@ -1254,12 +1530,16 @@ func (si *bufferedIterator) close() {
 		BytesBufferPool.Put(si.buf)
 		si.buf = nil
 	}
+	if si.labelBuf != nil {
+		BytesBufferPool.Put(si.labelBuf)
+		si.labelBuf = nil
+	}
 	si.origBytes = nil
 }
 
-func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, pipeline log.StreamPipeline) iter.EntryIterator {
+func newEntryIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, symbols []string, pipeline log.StreamPipeline) iter.EntryIterator {
 	return &entryBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format, symbols),
 		pipeline:         pipeline,
 	}
 }
@@ -1282,21 +1562,22 @@ func (e *entryBufferedIterator) StreamHash() uint64 { return e.pipeline.BaseLabe
 
 func (e *entryBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		newLine, lbs, matches := e.pipeline.Process(e.currTs, e.currLine)
+		newLine, lbs, matches := e.pipeline.Process(e.currTs, e.currLine, e.currNonIndexedLabels...)
 		if !matches {
 			continue
 		}
 		e.cur.Timestamp = time.Unix(0, e.currTs)
 		e.cur.Line = string(newLine)
+		e.cur.NonIndexedLabels = logproto.FromLabelsToLabelAdapters(e.currNonIndexedLabels)
 		e.currLabels = lbs
 		return true
 	}
 	return false
 }
 
-func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, extractor log.StreamSampleExtractor) iter.SampleIterator {
+func newSampleIterator(ctx context.Context, pool ReaderPool, b []byte, format byte, symbols []string, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	it := &sampleBufferedIterator{
-		bufferedIterator: newBufferedIterator(ctx, pool, b),
+		bufferedIterator: newBufferedIterator(ctx, pool, b, format, symbols),
 		extractor:        extractor,
 	}
 	return it
@@ -1313,7 +1594,7 @@ type sampleBufferedIterator struct {
 
 func (e *sampleBufferedIterator) Next() bool {
 	for e.bufferedIterator.Next() {
-		val, labels, ok := e.extractor.Process(e.currTs, e.currLine)
+		val, labels, ok := e.extractor.Process(e.currTs, e.currLine, e.currNonIndexedLabels...)
 		if !ok {
 			continue
 		}
@@ -250,7 +250,11 @@ func TestReadFormatV1(t *testing.T) {
 func TestRoundtripV2(t *testing.T) {
 	for _, f := range HeadBlockFmts {
 		for _, enc := range testEncoding {
-			for _, version := range []byte{chunkFormatV2, chunkFormatV3} {
+			for _, version := range []byte{chunkFormatV2, chunkFormatV3, chunkFormatV4, chunkFormatV5} {
+				if (version >= chunkFormatV4 || f.chunkFormat() >= chunkFormatV4) && version != f.chunkFormat() {
+					// only chunks of format v4+ are cut from head blocks storing non-indexed labels, in their own format.
+					continue
+				}
 				t.Run(enc.String(), func(t *testing.T) {
 					t.Parallel()
 
@@ -708,10 +712,10 @@ func BenchmarkWrite(b *testing.B) {
 type nomatchPipeline struct{}
 
 func (nomatchPipeline) BaseLabels() log.LabelsResult { return log.EmptyLabelsResult }
-func (nomatchPipeline) Process(_ int64, line []byte) ([]byte, log.LabelsResult, bool) {
+func (nomatchPipeline) Process(_ int64, line []byte, _ ...labels.Label) ([]byte, log.LabelsResult, bool) {
 	return line, nil, false
 }
-func (nomatchPipeline) ProcessString(_ int64, line string) (string, log.LabelsResult, bool) {
+func (nomatchPipeline) ProcessString(_ int64, line string, _ ...labels.Label) (string, log.LabelsResult, bool) {
 	return line, nil, false
 }
 
@@ -1013,6 +1017,193 @@ func TestCheckpointEncoding(t *testing.T) {
 	}
 }
 
+func TestMemChunk_NonIndexedLabels(t *testing.T) {
+	genEntry := func(i int64) *logproto.Entry {
+		e := &logproto.Entry{
+			Timestamp: time.Unix(0, i),
+			Line:      fmt.Sprintf("line %d", i),
+		}
+		if i%2 == 0 {
+			e.NonIndexedLabels = []logproto.LabelAdapter{
+				{Name: "trace_id", Value: strconv.FormatInt(i, 10)},
+				{Name: "user", Value: strings.Repeat("u", int(i))},
+			}
+		}
+		return e
+	}
+
+	c := NewMemChunk(EncSnappy, UnorderedWithNonIndexedLabelsHeadBlockFmt, testBlockSize, testTargetSize)
+	require.True(t, c.SupportsNonIndexedLabels())
+	for i := int64(0); i < 10; i++ {
+		require.NoError(t, c.Append(genEntry(i)))
+	}
+	// cut half the entries into a block and keep the others in the head.
+	require.NoError(t, c.cut())
+	for i := int64(10); i < 20; i++ {
+		require.NoError(t, c.Append(genEntry(i)))
+	}
+
+	assertEntries := func(t *testing.T, c *MemChunk) {
+		t.Helper()
+		it, err := c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, 20), logproto.FORWARD, noopStreamPipeline)
+		require.NoError(t, err)
+		var i int64
+		for ; it.Next(); i++ {
+			require.Equal(t, *genEntry(i), it.Entry())
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, int64(20), i)
+
+		// non-indexed labels can be queried with the metadata stage.
+		expr, err := syntax.ParseLogSelector(`{app="foo"} | metadata | trace_id=~"1.*"`, true)
+		require.NoError(t, err)
+		p, err := expr.Pipeline()
+		require.NoError(t, err)
+		it, err = c.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, 20), logproto.FORWARD, p.ForStream(labels.Labels{{Name: "app", Value: "foo"}}))
+		require.NoError(t, err)
+		var lines []string
+		for it.Next() {
+			lines = append(lines, it.Entry().Line)
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, []string{"line 10", "line 12", "line 14", "line 16", "line 18"}, lines)
+
+		se, err := syntax.ParseSampleExpr(`count_over_time({app="foo"} | metadata | trace_id=~"1.*" [1s])`)
+		require.NoError(t, err)
+		ex, err := se.Extractor()
+		require.NoError(t, err)
+		sit := c.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, 20), ex.ForStream(labels.Labels{{Name: "app", Value: "foo"}}))
+		var samples int
+		for sit.Next() {
+			samples++
+		}
+		require.NoError(t, sit.Close())
+		require.Equal(t, 5, samples)
+	}
+	assertEntries(t, c)
+
+	var chk, head bytes.Buffer
+	require.NoError(t, c.SerializeForCheckpointTo(&chk, &head))
+	cpy, err := MemchunkFromCheckpoint(chk.Bytes(), head.Bytes(), UnorderedHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, err)
+	require.Equal(t, UnorderedWithNonIndexedLabelsHeadBlockFmt, cpy.headFmt)
+	assertEntries(t, cpy)
+
+	require.NoError(t, c.Close())
+	b, err := c.Bytes()
+	require.NoError(t, err)
+	loaded, err := NewByteChunk(b, testBlockSize, testTargetSize)
+	require.NoError(t, err)
+	assertEntries(t, loaded)
+
+	rebound, err := loaded.Rebound(time.Unix(0, 0), time.Unix(0, 19), nil)
+	require.NoError(t, err)
+	assertEntries(t, rebound.(*MemChunk))
+
+	// chunks cut from other head block formats can't store them.
+	for _, f := range []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt} {
+		c := NewMemChunk(EncSnappy, f, testBlockSize, testTargetSize)
+		require.False(t, c.SupportsNonIndexedLabels())
+		require.Equal(t, ErrNonIndexedLabelsUnsupported, c.Append(genEntry(0)))
+		require.Error(t, c.ConvertHead(UnorderedWithNonIndexedLabelsHeadBlockFmt))
+	}
+}
+
+func TestMemChunk_FormatV5(t *testing.T) {
+	genEntry := func(i int64, level string) *logproto.Entry {
+		return &logproto.Entry{
+			Timestamp: time.Unix(0, i),
+			Line:      fmt.Sprintf("level=%s msg=%d", level, i),
+			NonIndexedLabels: []logproto.LabelAdapter{
+				{Name: "trace_id", Value: strconv.FormatInt(i%3, 10)},
+				{Name: "user", Value: "foo"},
+			},
+		}
+	}
+	var entries []logproto.Entry
+
+	c := NewMemChunk(EncSnappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	require.Equal(t, chunkFormatV5, c.format)
+	require.True(t, c.SupportsNonIndexedLabels())
+	for i := int64(0); i < 10; i++ {
+		require.NoError(t, c.Append(genEntry(i, "info")))
+		entries = append(entries, *genEntry(i, "info"))
+	}
+	require.NoError(t, c.cut())
+	for i := int64(10); i < 20; i++ {
+		require.NoError(t, c.Append(genEntry(i, "error")))
+		entries = append(entries, *genEntry(i, "error"))
+	}
+	// the names and values of the labels are only stored once.
+	require.Equal(t, []string{"trace_id", "0", "user", "foo", "1", "2"}, c.symbols.snapshot())
+
+	assertEntries := func(t *testing.T, ctx context.Context, c *MemChunk, expected []logproto.Entry) {
+		t.Helper()
+		it, err := c.Iterator(ctx, time.Unix(0, 0), time.Unix(0, 20), logproto.FORWARD, noopStreamPipeline)
+		require.NoError(t, err)
+		var actual []logproto.Entry
+		for it.Next() {
+			actual = append(actual, it.Entry())
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, expected, actual)
+
+		sit := c.SampleIterator(ctx, time.Unix(0, 0), time.Unix(0, 20), countExtractor)
+		var samples int
+		for sit.Next() {
+			samples++
+		}
+		require.NoError(t, sit.Close())
+		require.Equal(t, len(expected), samples)
+	}
+	assertEntries(t, context.Background(), c, entries)
+
+	var chk, head bytes.Buffer
+	require.NoError(t, c.SerializeForCheckpointTo(&chk, &head))
+	cpy, err := MemchunkFromCheckpoint(chk.Bytes(), head.Bytes(), UnorderedHeadBlockFmt, testBlockSize, testTargetSize)
+	require.NoError(t, err)
+	require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, cpy.headFmt)
+	assertEntries(t, context.Background(), cpy, entries)
+	// new labels are interned after the ones of the checkpointed blocks.
+	require.NoError(t, cpy.Append(&logproto.Entry{Timestamp: time.Unix(0, 20), Line: "new", NonIndexedLabels: []logproto.LabelAdapter{{Name: "user", Value: "bar"}}}))
+	require.NoError(t, cpy.Close())
+	require.Equal(t, []string{"trace_id", "0", "user", "foo", "1", "2", "bar"}, cpy.symbols.snapshot())
+
+	require.NoError(t, c.Close())
+	require.Len(t, c.blocks, 2)
+	for _, b := range c.blocks {
+		require.NotEmpty(t, b.bloom)
+	}
+	b, err := c.Bytes()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(b), c.BytesSize())
+	loaded, err := NewByteChunk(b, testBlockSize, testTargetSize)
+	require.NoError(t, err)
+	require.Equal(t, chunkFormatV5, loaded.format)
+	require.Equal(t, UnorderedWithStructuredMetadataHeadBlockFmt, loaded.headFmt)
+	assertEntries(t, context.Background(), loaded, entries)
+
+	// the blocks whose bloom filter shows that no line contains the required line filters are skipped.
+	assertEntries(t, WithRequiredLineFilters(context.Background(), []string{"level=error"}), loaded, entries[10:])
+	assertEntries(t, WithRequiredLineFilters(context.Background(), []string{"level=info", "msg="}), loaded, entries[:10])
+	assertEntries(t, WithRequiredLineFilters(context.Background(), []string{"level=debug"}), loaded, nil)
+	// filters shorter than the n-grams can't skip blocks.
+	assertEntries(t, WithRequiredLineFilters(context.Background(), []string{"zz"}), loaded, entries)
+
+	rebound, err := loaded.Rebound(time.Unix(0, 0), time.Unix(0, 19), nil)
+	require.NoError(t, err)
+	assertEntries(t, context.Background(), rebound.(*MemChunk), entries)
+
+	// the head block can't be converted to a format cut in another chunk format.
+	require.Error(t, c.ConvertHead(UnorderedWithNonIndexedLabelsHeadBlockFmt))
+	require.Error(t, NewMemChunk(EncSnappy, UnorderedWithNonIndexedLabelsHeadBlockFmt, testBlockSize, testTargetSize).ConvertHead(UnorderedWithStructuredMetadataHeadBlockFmt))
+
+	// corrupted structured metadata is detected.
+	b[binary.BigEndian.Uint64(b[len(b)-16:])] ^= 0xff
+	_, err = NewByteChunk(b, testBlockSize, testTargetSize)
+	require.Error(t, err)
+}
+
 var (
 	streams = []logproto.Stream{}
 	series  = []logproto.Series{}
@@ -12,6 +12,8 @@ import (
 	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/util/pool"
 
 	"github.com/grafana/loki/pkg/logproto"
@@ -45,8 +47,11 @@ var (
 	Noop NoopPool
 
 	// BytesBufferPool is a bytes buffer used for lines decompressed.
-	// Buckets [0.5KB,1KB,2KB,4KB,8KB]
-	BytesBufferPool = pool.New(1<<9, 1<<13, 2, func(size int) interface{} { return make([]byte, 0, size) })
+	// Buckets [0.5KB,1KB,2KB,4KB,8KB,16KB,32KB,64KB]
+	BytesBufferPool = pool.New(1<<9, 1<<16, 2, func(size int) interface{} {
+		lineBufferPoolMetrics.misses.Inc()
+		return make([]byte, 0, size)
+	})
 
 	// SamplesPool pooling array of samples [512,1024,...,16k]
 	SamplesPool = pool.New(1<<9, 1<<14, 2, func(size int) interface{} { return make([]logproto.Sample, 0, size) })
@@ -74,6 +79,53 @@ var (
 	}
 )
 
+var (
+	poolGets = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki",
+		Subsystem: "chunkenc",
+		Name:      "pool_gets_total",
+		Help:      "Total number of objects requested from the pools used to read chunks.",
+	}, []string{"pool"})
+	poolMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki",
+		Subsystem: "chunkenc",
+		Name:      "pool_misses_total",
+		Help:      "Total number of objects requested from the pools used to read chunks which had to be allocated because the pool had none.",
+	}, []string{"pool"})
+
+	lineBufferPoolMetrics   = newPoolMetrics("line_buffer")
+	gzipReaderPoolMetrics   = newPoolMetrics("gzip_reader")
+	flateReaderPoolMetrics  = newPoolMetrics("flate_reader")
+	zstdReaderPoolMetrics   = newPoolMetrics("zstd_reader")
+	lz4ReaderPoolMetrics    = newPoolMetrics("lz4_reader")
+	snappyReaderPoolMetrics = newPoolMetrics("snappy_reader")
+)
+
+// poolMetrics counts the gets and misses of a pool, its hit rate being 1 - misses/gets.
+type poolMetrics struct {
+	gets, misses prometheus.Counter
+}
+
+func newPoolMetrics(pool string) poolMetrics {
+	return poolMetrics{
+		gets:   poolGets.WithLabelValues(pool),
+		misses: poolMisses.WithLabelValues(pool),
+	}
+}
+
+func (m poolMetrics) observe(hit bool) {
+	m.gets.Inc()
+	if !hit {
+		m.misses.Inc()
+	}
+}
+
+// getLineBuffer returns a buffer of BytesBufferPool able to hold size bytes.
+func getLineBuffer(size int) []byte {
+	lineBufferPoolMetrics.gets.Inc()
+	return BytesBufferPool.Get(size).([]byte)
+}
+
 func getWriterPool(enc Encoding) WriterPool {
 	return getReaderPool(enc).(WriterPool)
 }
@@ -119,7 +171,9 @@ type gzipBufferedReader struct {
 
 // GetReader gets or creates a new CompressionReader and reset it to read from src
 func (pool *GzipPool) GetReader(src io.Reader) (io.Reader, error) {
-	if r := pool.readers.Get(); r != nil {
+	r := pool.readers.Get()
+	gzipReaderPoolMetrics.observe(r != nil)
+	if r != nil {
 		reader := r.(*gzipBufferedReader)
 		err := reader.gzipReader.Reset(src)
 		if err != nil {
@@ -176,7 +230,9 @@ type FlatePool struct {
 
 // GetReader gets or creates a new CompressionReader and reset it to read from src
 func (pool *FlatePool) GetReader(src io.Reader) (io.Reader, error) {
-	if r := pool.readers.Get(); r != nil {
+	r := pool.readers.Get()
+	flateReaderPoolMetrics.observe(r != nil)
+	if r != nil {
 		reader := r.(flate.Resetter)
 		err := reader.Reset(src, nil)
 		if err != nil {
@@ -224,7 +280,9 @@ type ZstdPool struct {
 
 // GetReader gets or creates a new CompressionReader and reset it to read from src
 func (pool *ZstdPool) GetReader(src io.Reader) (io.Reader, error) {
-	if r := pool.readers.Get(); r != nil {
+	r := pool.readers.Get()
+	zstdReaderPoolMetrics.observe(r != nil)
+	if r != nil {
 		reader := r.(*zstd.Decoder)
 		err := reader.Reset(src)
 		if err != nil {
@@ -280,7 +338,9 @@ type lz4BufferedReader struct {
 // GetReader gets or creates a new CompressionReader and reset it to read from src
 func (pool *LZ4Pool) GetReader(src io.Reader) (io.Reader, error) {
 	var r *lz4BufferedReader
-	if pooled := pool.readers.Get(); pooled != nil {
+	pooled := pool.readers.Get()
+	lz4ReaderPoolMetrics.observe(pooled != nil)
+	if pooled != nil {
 		r = pooled.(*lz4BufferedReader)
 		r.lz4Reader.Reset(src)
 		r.Reader.Reset(r.lz4Reader)
@@ -331,7 +391,9 @@ type SnappyPool struct {
 
 // GetReader gets or creates a new CompressionReader and reset it to read from src
 func (pool *SnappyPool) GetReader(src io.Reader) (io.Reader, error) {
-	if r := pool.readers.Get(); r != nil {
+	r := pool.readers.Get()
+	snappyReaderPoolMetrics.observe(r != nil)
+	if r != nil {
 		reader := r.(*snappy.Reader)
 		reader.Reset(src)
 		return reader, nil
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,3 +59,32 @@ func TestPool(t *testing.T) {
 		_ = pprof.Lookup("goroutine").WriteTo(os.Stdout, 1)
 	}
 }
+
+func TestPoolMetrics(t *testing.T) {
+	gets, misses := testutil.ToFloat64(lineBufferPoolMetrics.gets), testutil.ToFloat64(lineBufferPoolMetrics.misses)
+
+	// buffers larger than the biggest bucket are never pooled.
+	BytesBufferPool.Put(getLineBuffer(1 << 17))
+	require.Equal(t, gets+1, testutil.ToFloat64(lineBufferPoolMetrics.gets))
+	require.Equal(t, misses+1, testutil.ToFloat64(lineBufferPoolMetrics.misses))
+
+	b := getLineBuffer(1 << 15)
+	require.GreaterOrEqual(t, cap(b), 1<<15)
+	BytesBufferPool.Put(b)
+	getLineBuffer(1 << 15)
+	require.Equal(t, gets+3, testutil.ToFloat64(lineBufferPoolMetrics.gets))
+	require.LessOrEqual(t, testutil.ToFloat64(lineBufferPoolMetrics.misses), misses+3)
+
+	var buf bytes.Buffer
+	w := Snappy.GetWriter(&buf)
+	_, err := w.Write([]byte("test"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	Snappy.PutWriter(w)
+
+	gets = testutil.ToFloat64(snappyReaderPoolMetrics.gets)
+	r, err := Snappy.GetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	Snappy.PutReader(r)
+	require.Equal(t, gets+1, testutil.ToFloat64(snappyReaderPoolMetrics.gets))
+}
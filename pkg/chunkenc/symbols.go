@@ -0,0 +1,108 @@
+package chunkenc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// symbolizer interns the names and values of the non-indexed labels of the entries of a chunk of format v5+.
+// It's stored once per chunk in its structured metadata section, and the blocks reference the symbols by index.
+// Symbols are only ever appended, so a snapshot of them stays valid while the chunk keeps being written.
+type symbolizer struct {
+	symbols []string
+	index   map[string]uint32
+}
+
+func newSymbolizer() *symbolizer {
+	return &symbolizer{index: map[string]uint32{}}
+}
+
+// add returns the reference of the symbol, interning it if it's new.
+func (s *symbolizer) add(symbol string) uint32 {
+	if ref, ok := s.index[symbol]; ok {
+		return ref
+	}
+	ref := uint32(len(s.symbols))
+	s.symbols = append(s.symbols, symbol)
+	s.index[symbol] = ref
+	return ref
+}
+
+// snapshot returns the symbols interned so far.
+func (s *symbolizer) snapshot() []string {
+	return s.symbols[:len(s.symbols):len(s.symbols)]
+}
+
+// uncompressedSize returns the maximum size of the serialised symbols before compression.
+func (s *symbolizer) uncompressedSize() int {
+	size := binary.MaxVarintLen32
+	for _, symbol := range s.symbols {
+		size += binary.MaxVarintLen32 + len(symbol)
+	}
+	return size
+}
+
+// serialise writes the number of symbols followed by each length prefixed symbol, compressed.
+func (s *symbolizer) serialise(pool WriterPool) ([]byte, error) {
+	inBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		inBuf.Reset()
+		serializeBytesBufferPool.Put(inBuf)
+	}()
+	outBuf := &bytes.Buffer{}
+
+	encBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(encBuf, uint64(len(s.symbols)))
+	inBuf.Write(encBuf[:n])
+	for _, symbol := range s.symbols {
+		n = binary.PutUvarint(encBuf, uint64(len(symbol)))
+		inBuf.Write(encBuf[:n])
+		inBuf.WriteString(symbol)
+	}
+
+	compressedWriter := pool.GetWriter(outBuf)
+	defer pool.PutWriter(compressedWriter)
+	if _, err := compressedWriter.Write(inBuf.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "appending symbols")
+	}
+	if err := compressedWriter.Close(); err != nil {
+		return nil, errors.Wrap(err, "flushing pending compress buffer")
+	}
+	return outBuf.Bytes(), nil
+}
+
+// symbolizerFromBytes reads the symbols written by serialise.
+func symbolizerFromBytes(b []byte, pool ReaderPool) (*symbolizer, error) {
+	r, err := pool.GetReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer pool.PutReader(r)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing symbols")
+	}
+
+	db := decbuf{b: data}
+	n := db.uvarint()
+	if db.err() != nil {
+		return nil, errors.Wrap(db.err(), "reading number of symbols")
+	}
+	s := newSymbolizer()
+	for i := 0; i < n; i++ {
+		symbol := string(db.bytes(db.uvarint()))
+		if db.err() != nil {
+			return nil, errors.Wrap(db.err(), "reading symbols")
+		}
+		s.add(symbol)
+	}
+	if len(s.symbols) != n {
+		return nil, fmt.Errorf("duplicate symbols in chunk")
+	}
+	return s, nil
+}
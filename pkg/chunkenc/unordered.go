@@ -34,7 +34,7 @@ type HeadBlock interface {
 	Entries() int
 	UncompressedSize() int
 	Convert(HeadBlockFmt) (HeadBlock, error)
-	Append(int64, string) error
+	Append(ts int64, line string, nonIndexedLabels ...labels.Label) error
 	Iterator(
 		ctx context.Context,
 		direction logproto.Direction,
@@ -52,6 +52,7 @@ type HeadBlock interface {
 }
 
 type unorderedHeadBlock struct {
+	format HeadBlockFmt
 	// Opted for range tree over skiplist for space reduction.
 	// Inserts: O(log(n))
 	// Scans: (O(k+log(n))) where k=num_scanned_entries & n=total_entries
@@ -62,13 +63,14 @@ type unorderedHeadBlock struct {
 	mint, maxt int64 // upper and lower bounds
 }
 
-func newUnorderedHeadBlock() *unorderedHeadBlock {
+func newUnorderedHeadBlock(format HeadBlockFmt) *unorderedHeadBlock {
 	return &unorderedHeadBlock{
-		rt: rangetree.New(1),
+		format: format,
+		rt:     rangetree.New(1),
 	}
 }
 
-func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return UnorderedHeadBlockFmt }
+func (hb *unorderedHeadBlock) Format() HeadBlockFmt { return hb.format }
 
 func (hb *unorderedHeadBlock) IsEmpty() bool {
 	return hb.size == 0
@@ -87,21 +89,30 @@ func (hb *unorderedHeadBlock) UncompressedSize() int {
 }
 
 func (hb *unorderedHeadBlock) Reset() {
-	x := newUnorderedHeadBlock()
+	x := newUnorderedHeadBlock(hb.format)
 	*hb = *x
 }
 
 // collection of entries belonging to the same nanosecond
 type nsEntries struct {
 	ts      int64
-	entries []string
+	entries []nsEntry
+}
+
+type nsEntry struct {
+	line             string
+	nonIndexedLabels labels.Labels
 }
 
 func (e *nsEntries) ValueAtDimension(_ uint64) int64 {
 	return e.ts
 }
 
-func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
+func (hb *unorderedHeadBlock) Append(ts int64, line string, nonIndexedLabels ...labels.Label) error {
+	if len(nonIndexedLabels) > 0 && hb.format < UnorderedWithNonIndexedLabelsHeadBlockFmt {
+		return ErrNonIndexedLabelsUnsupported
+	}
+
 	// This is an allocation hack. The rangetree lib does not
 	// support the ability to pass a "mutate" function during an insert
 	// and instead will displace any existing entry at the specified timestamp.
@@ -120,14 +131,14 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 		// entries at the same time with the same content, iterate through any existing
 		// entries and ignore the line if we already have an entry with the same content
 		for _, et := range displaced[0].(*nsEntries).entries {
-			if et == line {
+			if et.line == line && labels.Equal(et.nonIndexedLabels, nonIndexedLabels) {
 				e.entries = displaced[0].(*nsEntries).entries
 				return nil
 			}
 		}
-		e.entries = append(displaced[0].(*nsEntries).entries, line)
+		e.entries = append(displaced[0].(*nsEntries).entries, newNSEntry(line, nonIndexedLabels))
 	} else {
-		e.entries = []string{line}
+		e.entries = []nsEntry{newNSEntry(line, nonIndexedLabels)}
 	}
 
 	// Update hb metdata
@@ -140,11 +151,23 @@ func (hb *unorderedHeadBlock) Append(ts int64, line string) error {
 	}
 
 	hb.size += len(line)
+	for _, l := range nonIndexedLabels {
+		hb.size += len(l.Name) + len(l.Value)
+	}
 	hb.lines++
 
 	return nil
 }
 
+func newNSEntry(line string, nonIndexedLabels labels.Labels) nsEntry {
+	e := nsEntry{line: line}
+	if len(nonIndexedLabels) > 0 {
+		// the labels are retained beyond the append.
+		e.nonIndexedLabels = nonIndexedLabels.Copy()
+	}
+	return e
+}
+
 // Implements rangetree.Interval
 type interval struct {
 	mint, maxt int64
@@ -162,7 +185,7 @@ func (hb *unorderedHeadBlock) forEntries(
 	direction logproto.Direction,
 	mint,
 	maxt int64,
-	entryFn func(int64, string) error, // returning an error exits early
+	entryFn func(int64, string, labels.Labels) error, // returning an error exits early
 ) (err error) {
 	if hb.IsEmpty() || (maxt < hb.mint || hb.maxt < mint) {
 		return
@@ -191,9 +214,9 @@ func (hb *unorderedHeadBlock) forEntries(
 		}
 
 		for ; i < len(es.entries) && i >= 0; next() {
-			line := es.entries[i]
-			chunkStats.AddHeadChunkBytes(int64(len(line)))
-			err = entryFn(es.ts, line)
+			e := es.entries[i]
+			chunkStats.AddHeadChunkBytes(int64(len(e.line)))
+			err = entryFn(es.ts, e.line, e.nonIndexedLabels)
 
 		}
 	}
@@ -235,8 +258,8 @@ func (hb *unorderedHeadBlock) Iterator(
 		direction,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			newLine, parsedLbs, matches := pipeline.ProcessString(ts, line)
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
+			newLine, parsedLbs, matches := pipeline.ProcessString(ts, line, nonIndexedLabels...)
 			if !matches {
 				return nil
 			}
@@ -253,8 +276,9 @@ func (hb *unorderedHeadBlock) Iterator(
 			}
 
 			stream.Entries = append(stream.Entries, logproto.Entry{
-				Timestamp: time.Unix(0, ts),
-				Line:      newLine,
+				Timestamp:        time.Unix(0, ts),
+				Line:             newLine,
+				NonIndexedLabels: logproto.FromLabelsToLabelAdapters(nonIndexedLabels),
 			})
 			return nil
 		},
@@ -284,8 +308,8 @@ func (hb *unorderedHeadBlock) SampleIterator(
 		logproto.FORWARD,
 		mint,
 		maxt,
-		func(ts int64, line string) error {
-			value, parsedLabels, ok := extractor.ProcessString(ts, line)
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
+			value, parsedLabels, ok := extractor.ProcessString(ts, line, nonIndexedLabels...)
 			if !ok {
 				return nil
 			}
@@ -346,7 +370,7 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
 			n := binary.PutVarint(encBuf, ts)
 			inBuf.Write(encBuf[:n])
 
@@ -354,6 +378,20 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 			inBuf.Write(encBuf[:n])
 
 			inBuf.WriteString(line)
+
+			if hb.format >= UnorderedWithNonIndexedLabelsHeadBlockFmt {
+				n = binary.PutUvarint(encBuf, uint64(len(nonIndexedLabels)))
+				inBuf.Write(encBuf[:n])
+				for _, l := range nonIndexedLabels {
+					n = binary.PutUvarint(encBuf, uint64(len(l.Name)))
+					inBuf.Write(encBuf[:n])
+					inBuf.WriteString(l.Name)
+
+					n = binary.PutUvarint(encBuf, uint64(len(l.Value)))
+					inBuf.Write(encBuf[:n])
+					inBuf.WriteString(l.Value)
+				}
+			}
 			return nil
 		},
 	)
@@ -368,8 +406,66 @@ func (hb *unorderedHeadBlock) Serialise(pool WriterPool) ([]byte, error) {
 	return outBuf.Bytes(), nil
 }
 
+// serialiseWithSymbols creates a block of a chunk of format v5+ from an unorderedHeadBlock. The non-indexed labels
+// of the entries are interned in the chunk's symbols and referenced by the block, and the bloom filter of the
+// lines of the block is returned along with it.
+func (hb *unorderedHeadBlock) serialiseWithSymbols(pool WriterPool, symbols *symbolizer) ([]byte, []byte, error) {
+	inBuf := serializeBytesBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		inBuf.Reset()
+		serializeBytesBufferPool.Put(inBuf)
+	}()
+	outBuf := &bytes.Buffer{}
+
+	encBuf := make([]byte, binary.MaxVarintLen64)
+	compressedWriter := pool.GetWriter(outBuf)
+	defer pool.PutWriter(compressedWriter)
+
+	bloomBuilder := newBlockBloomBuilder()
+	_ = hb.forEntries(
+		context.Background(),
+		logproto.FORWARD,
+		0,
+		math.MaxInt64,
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
+			n := binary.PutVarint(encBuf, ts)
+			inBuf.Write(encBuf[:n])
+
+			n = binary.PutUvarint(encBuf, uint64(len(line)))
+			inBuf.Write(encBuf[:n])
+
+			inBuf.WriteString(line)
+			bloomBuilder.add(line)
+
+			n = binary.PutUvarint(encBuf, uint64(len(nonIndexedLabels)))
+			inBuf.Write(encBuf[:n])
+			for _, l := range nonIndexedLabels {
+				n = binary.PutUvarint(encBuf, uint64(symbols.add(l.Name)))
+				inBuf.Write(encBuf[:n])
+
+				n = binary.PutUvarint(encBuf, uint64(symbols.add(l.Value)))
+				inBuf.Write(encBuf[:n])
+			}
+			return nil
+		},
+	)
+
+	if _, err := compressedWriter.Write(inBuf.Bytes()); err != nil {
+		return nil, nil, errors.Wrap(err, "appending entry")
+	}
+	if err := compressedWriter.Close(); err != nil {
+		return nil, nil, errors.Wrap(err, "flushing pending compress buffer")
+	}
+
+	bloom, err := bloomBuilder.build()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "building block bloom filter")
+	}
+	return outBuf.Bytes(), bloom, nil
+}
+
 func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
-	if version > OrderedHeadBlockFmt {
+	if version == hb.format {
 		return hb, nil
 	}
 	out := version.NewBlock()
@@ -379,8 +475,8 @@ func (hb *unorderedHeadBlock) Convert(version HeadBlockFmt) (HeadBlock, error) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
-			return out.Append(ts, line)
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
+			return out.Append(ts, line, nonIndexedLabels...)
 		},
 	)
 	return out, err
@@ -392,7 +488,14 @@ func (hb *unorderedHeadBlock) CheckpointSize() int {
 	size += binary.MaxVarintLen32 * 2                                  // total entries + total size
 	size += binary.MaxVarintLen64 * 2                                  // mint,maxt
 	size += (binary.MaxVarintLen64 + binary.MaxVarintLen32) * hb.lines // ts + len of log line.
-	size += hb.size                                                    // uncompressed bytes of lines
+	size += hb.size                                                    // uncompressed bytes of lines and non-indexed labels
+	if hb.format >= UnorderedWithNonIndexedLabelsHeadBlockFmt {
+		size += binary.MaxVarintLen32 * hb.lines // number of non-indexed labels
+		_ = hb.forEntries(context.Background(), logproto.FORWARD, 0, math.MaxInt64, func(_ int64, _ string, nonIndexedLabels labels.Labels) error {
+			size += binary.MaxVarintLen32 * 2 * len(nonIndexedLabels) // len of names and values
+			return nil
+		})
+	}
 	return size
 }
 
@@ -432,7 +535,7 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, nonIndexedLabels labels.Labels) error {
 			eb.putVarint64(ts)
 			eb.putUvarint(len(line))
 			_, err = w.Write(eb.get())
@@ -445,6 +548,21 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 			if err != nil {
 				return errors.Wrap(err, "write headblock entry line")
 			}
+
+			if hb.format >= UnorderedWithNonIndexedLabelsHeadBlockFmt {
+				eb.putUvarint(len(nonIndexedLabels))
+				for _, l := range nonIndexedLabels {
+					eb.putUvarint(len(l.Name))
+					eb.putString(l.Name)
+					eb.putUvarint(len(l.Value))
+					eb.putString(l.Value)
+				}
+				_, err = w.Write(eb.get())
+				if err != nil {
+					return errors.Wrap(err, "write headblock entry non-indexed labels")
+				}
+				eb.reset()
+			}
 			return nil
 		},
 	)
@@ -454,7 +572,7 @@ func (hb *unorderedHeadBlock) CheckpointTo(w io.Writer) error {
 
 func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 	// ensure it's empty
-	*hb = *newUnorderedHeadBlock()
+	*hb = *newUnorderedHeadBlock(hb.format)
 
 	if len(b) < 1 {
 		return nil
@@ -467,8 +585,8 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		return errors.Wrap(db.err(), "verifying headblock header")
 	}
 
-	if version != UnorderedHeadBlockFmt.Byte() {
-		return errors.Errorf("incompatible headBlock version (%v), only V4 is currently supported", version)
+	if version != hb.format.Byte() {
+		return errors.Errorf("incompatible headBlock version (%v), only V%d is currently supported", version, hb.format)
 	}
 
 	n := db.uvarint()
@@ -481,7 +599,20 @@ func (hb *unorderedHeadBlock) LoadBytes(b []byte) error {
 		ts := db.varint64()
 		lineLn := db.uvarint()
 		line := string(db.bytes(lineLn))
-		if err := hb.Append(ts, line); err != nil {
+
+		var nonIndexedLabels labels.Labels
+		if hb.format >= UnorderedWithNonIndexedLabelsHeadBlockFmt {
+			nonIndexedLabels = make(labels.Labels, db.uvarint())
+			for j := range nonIndexedLabels {
+				nonIndexedLabels[j].Name = string(db.bytes(db.uvarint()))
+				nonIndexedLabels[j].Value = string(db.bytes(db.uvarint()))
+			}
+		}
+		if db.err() != nil {
+			break
+		}
+
+		if err := hb.Append(ts, line, nonIndexedLabels...); err != nil {
 			return err
 		}
 	}
@@ -508,7 +639,7 @@ func HeadFromCheckpoint(b []byte, desired HeadBlockFmt) (HeadBlock, error) {
 		return nil, errors.Wrap(db.err(), "verifying headblock header")
 	}
 	format := HeadBlockFmt(version)
-	if format > UnorderedHeadBlockFmt {
+	if format > UnorderedWithStructuredMetadataHeadBlockFmt {
 		return nil, fmt.Errorf("unexpected head block version: %v", format)
 	}
 
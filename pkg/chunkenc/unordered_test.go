@@ -30,7 +30,7 @@ func iterEq(t *testing.T, exp []entry, got iter.EntryIterator) {
 }
 
 func Test_forEntriesEarlyReturn(t *testing.T) {
-	hb := newUnorderedHeadBlock()
+	hb := newUnorderedHeadBlock(UnorderedHeadBlockFmt)
 	for i := 0; i < 10; i++ {
 		require.Nil(t, hb.Append(int64(i), fmt.Sprint(i)))
 	}
@@ -43,7 +43,7 @@ func Test_forEntriesEarlyReturn(t *testing.T) {
 		logproto.FORWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, _ labels.Labels) error {
 			forwardCt++
 			forwardStop = ts
 			if ts == 5 {
@@ -64,7 +64,7 @@ func Test_forEntriesEarlyReturn(t *testing.T) {
 		logproto.BACKWARD,
 		0,
 		math.MaxInt64,
-		func(ts int64, line string) error {
+		func(ts int64, line string, _ labels.Labels) error {
 			backwardCt++
 			backwardStop = ts
 			if ts == 5 {
@@ -163,7 +163,7 @@ func Test_Unordered_InsertRetrieval(t *testing.T) {
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			hb := newUnorderedHeadBlock()
+			hb := newUnorderedHeadBlock(UnorderedHeadBlockFmt)
 			for _, e := range tc.input {
 				require.Nil(t, hb.Append(e.t, e.s))
 			}
@@ -225,7 +225,7 @@ func Test_UnorderedBoundedIter(t *testing.T) {
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
-			hb := newUnorderedHeadBlock()
+			hb := newUnorderedHeadBlock(UnorderedHeadBlockFmt)
 			for _, e := range tc.input {
 				require.Nil(t, hb.Append(e.t, e.s))
 			}
@@ -244,7 +244,7 @@ func Test_UnorderedBoundedIter(t *testing.T) {
 }
 
 func TestHeadBlockInterop(t *testing.T) {
-	unordered, ordered := newUnorderedHeadBlock(), &headBlock{}
+	unordered, ordered := newUnorderedHeadBlock(UnorderedHeadBlockFmt), &headBlock{}
 	for i := 0; i < 100; i++ {
 		require.Nil(t, unordered.Append(int64(99-i), fmt.Sprint(99-i)))
 		require.Nil(t, ordered.Append(int64(i), fmt.Sprint(i)))
@@ -299,7 +299,7 @@ func BenchmarkHeadBlockWrites(b *testing.B) {
 	}
 
 	unorderedHeadBlockFn := func() func(int64, string) {
-		hb := newUnorderedHeadBlock()
+		hb := newUnorderedHeadBlock(UnorderedHeadBlockFmt)
 		return func(ts int64, line string) {
 			_ = hb.Append(ts, line)
 		}
@@ -638,7 +638,7 @@ func Test_HeadIteratorHash(t *testing.T) {
 	}
 
 	for name, b := range map[string]HeadBlock{
-		"unordered": newUnorderedHeadBlock(),
+		"unordered": newUnorderedHeadBlock(UnorderedHeadBlockFmt),
 		"ordered":   &headBlock{},
 	} {
 		t.Run(name, func(t *testing.T) {
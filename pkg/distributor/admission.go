@@ -0,0 +1,156 @@
+package distributor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/limiter"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// minAdmissionPollInterval bounds how often a delayed push checks the ingestion rate limiter again.
+	minAdmissionPollInterval = 10 * time.Millisecond
+
+	admissionAdmitted  = "admitted"
+	admissionTimedOut  = "timed_out"
+	admissionQueueFull = "queue_full"
+)
+
+// admissionQueue enforces the ingestion rate limit of the tenants. With ingestion_burst_max_delay set, the
+// pushes over the limit wait for it to allow them instead of being rejected right away, up to that delay and
+// ingestion_burst_max_queued_pushes pushes per tenant, which smooths the bursts of clients which don't back off.
+// The pushes of a tenant are admitted in order: once some wait, the next ones queue behind them even if the limiter
+// would allow them, so that the small pushes don't starve the large ones.
+type admissionQueue struct {
+	limits  Limits
+	limiter *limiter.RateLimiter
+
+	mtx sync.Mutex
+	// queues holds the waiting pushes of each tenant, the first one being the only one polling the limiter.
+	queues map[string][]*admissionWaiter
+
+	delayed *prometheus.CounterVec
+}
+
+type admissionWaiter struct {
+	// turn is closed once the push is the first of the queue.
+	turn chan struct{}
+}
+
+func newAdmissionQueue(limits Limits, rateLimiter *limiter.RateLimiter, delayed *prometheus.CounterVec) *admissionQueue {
+	return &admissionQueue{
+		limits:  limits,
+		limiter: rateLimiter,
+		queues:  map[string][]*admissionWaiter{},
+		delayed: delayed,
+	}
+}
+
+// admit reports whether n bytes of the tenant may be pushed, waiting for the rate limiter to allow them if the
+// limits of the tenant let the push be delayed.
+func (q *admissionQueue) admit(ctx context.Context, tenant string, n int) bool {
+	now := time.Now()
+	w, admitted, reason := q.enqueue(now, tenant, n)
+	if w == nil {
+		if reason != "" {
+			q.delayed.WithLabelValues(reason).Inc()
+		}
+		return admitted
+	}
+	defer q.dequeue(tenant, w)
+
+	deadline := now.Add(q.limits.IngestionBurstMaxDelay(tenant))
+	timer := time.NewTimer(deadline.Sub(now))
+	select {
+	case <-w.turn:
+		timer.Stop()
+	case <-timer.C:
+		q.delayed.WithLabelValues(admissionTimedOut).Inc()
+		return false
+	case <-ctx.Done():
+		timer.Stop()
+		q.delayed.WithLabelValues(admissionTimedOut).Inc()
+		return false
+	}
+
+	now = time.Now()
+	for {
+		if q.limiter.AllowN(now, tenant, n) {
+			q.delayed.WithLabelValues(admissionAdmitted).Inc()
+			return true
+		}
+
+		// the limiter refills at least n tokens in the time it takes to accumulate them from none
+		wait := time.Duration(float64(n) / q.limiter.Limit(now, tenant) * float64(time.Second))
+		if wait < minAdmissionPollInterval {
+			wait = minAdmissionPollInterval
+		}
+		if remaining := deadline.Sub(now); wait > remaining {
+			wait = remaining
+		}
+		if wait <= 0 {
+			q.delayed.WithLabelValues(admissionTimedOut).Inc()
+			return false
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			q.delayed.WithLabelValues(admissionTimedOut).Inc()
+			return false
+		case now = <-timer.C:
+		}
+	}
+}
+
+// enqueue admits the push right away if no push of the tenant is waiting and the limiter allows it. Otherwise it
+// returns the waiter of the push added to the queue of the tenant, or the reason why it's rejected right away.
+func (q *admissionQueue) enqueue(now time.Time, tenant string, n int) (*admissionWaiter, bool, string) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	queue := q.queues[tenant]
+	if len(queue) == 0 && q.limiter.AllowN(now, tenant, n) {
+		return nil, true, ""
+	}
+	// pushes larger than the burst are never allowed, there's no point in holding them
+	if q.limits.IngestionBurstMaxDelay(tenant) <= 0 || n > q.limiter.Burst(now, tenant) {
+		return nil, false, ""
+	}
+	if len(queue) >= q.limits.IngestionBurstMaxQueued(tenant) {
+		return nil, false, admissionQueueFull
+	}
+
+	w := &admissionWaiter{turn: make(chan struct{})}
+	if len(queue) == 0 {
+		close(w.turn)
+	}
+	q.queues[tenant] = append(queue, w)
+	return w, false, ""
+}
+
+// dequeue removes the push from the queue of the tenant, handing the turn over to the next one if it was first.
+func (q *admissionQueue) dequeue(tenant string, w *admissionWaiter) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	queue := q.queues[tenant]
+	for i := range queue {
+		if queue[i] != w {
+			continue
+		}
+		queue = append(queue[:i], queue[i+1:]...)
+		if i == 0 && len(queue) > 0 {
+			close(queue[0].turn)
+		}
+		break
+	}
+	if len(queue) == 0 {
+		delete(q.queues, tenant)
+		return
+	}
+	q.queues[tenant] = queue
+}
@@ -0,0 +1,210 @@
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+	"github.com/weaveworks/common/user"
+)
+
+// maxTrackedDiscardedStreams bounds the number of streams tracked per tenant between two reports,
+// so that a tenant sending many distinct invalid streams can't exhaust the memory of the distributor.
+const maxTrackedDiscardedStreams = 10000
+
+// DiscardReportConfig configures the periodic report of the streams whose lines were discarded.
+type DiscardReportConfig struct {
+	Interval       time.Duration `yaml:"interval"`
+	TopStreams     int           `yaml:"top_streams"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+}
+
+func (cfg *DiscardReportConfig) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.DurationVar(&cfg.Interval, prefix+".interval", 0, "Interval at which the streams with the most discarded lines of each tenant are reported. The report is logged and, if a webhook URL is configured, sent to the webhook. 0 disables the report.")
+	fs.IntVar(&cfg.TopStreams, prefix+".top-streams", 10, "Maximum number of streams reported per tenant, sorted by discarded bytes.")
+	fs.StringVar(&cfg.WebhookURL, prefix+".webhook-url", "", "URL to which the report of each tenant is posted as JSON, with the tenant ID in the X-Scope-OrgID header.")
+	fs.DurationVar(&cfg.WebhookTimeout, prefix+".webhook-timeout", 10*time.Second, "Timeout of the requests sent to the webhook.")
+}
+
+func (cfg *DiscardReportConfig) Validate() error {
+	if cfg.Interval > 0 && cfg.TopStreams <= 0 {
+		return errors.New("the number of reported streams must be greater than 0 when the discard report is enabled")
+	}
+	return nil
+}
+
+// DiscardReport is the report of the discarded lines of a tenant, as sent to the webhook.
+type DiscardReport struct {
+	Tenant  string            `json:"tenant"`
+	From    time.Time         `json:"from"`
+	Through time.Time         `json:"through"`
+	Streams []DiscardedStream `json:"streams"`
+}
+
+// DiscardedStream is a stream whose lines were discarded for a given reason.
+type DiscardedStream struct {
+	Reason string `json:"reason"`
+	Stream string `json:"stream"`
+	Lines  int    `json:"lines"`
+	Bytes  int    `json:"bytes"`
+}
+
+type discardKey struct {
+	reason, stream string
+}
+
+// discardReporter accumulates the discarded lines of each tenant by stream and reason,
+// and periodically reports the top offending streams so that tenants can fix their clients.
+type discardReporter struct {
+	services.Service
+
+	cfg    DiscardReportConfig
+	client *http.Client
+	logger log.Logger
+
+	mtx     sync.Mutex
+	from    time.Time
+	tenants map[string]map[discardKey]*DiscardedStream
+}
+
+func newDiscardReporter(cfg DiscardReportConfig, logger log.Logger) *discardReporter {
+	r := &discardReporter{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: cfg.WebhookTimeout},
+		logger:  logger,
+		from:    time.Now(),
+		tenants: map[string]map[discardKey]*DiscardedStream{},
+	}
+	r.Service = services.
+		NewTimerService(cfg.Interval, nil, r.iteration, r.stopping).
+		WithName("discard reporter")
+	return r
+}
+
+// record tracks discarded lines. It is a no-op on a nil reporter, which is the case when the report is disabled.
+func (r *discardReporter) record(tenant, reason, stream string, lines, bytes int) {
+	if r == nil {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	streams, ok := r.tenants[tenant]
+	if !ok {
+		streams = map[discardKey]*DiscardedStream{}
+		r.tenants[tenant] = streams
+	}
+	key := discardKey{reason: reason, stream: stream}
+	s, ok := streams[key]
+	if !ok {
+		if len(streams) >= maxTrackedDiscardedStreams {
+			return
+		}
+		s = &DiscardedStream{Reason: reason, Stream: stream}
+		streams[key] = s
+	}
+	s.Lines += lines
+	s.Bytes += bytes
+}
+
+func (r *discardReporter) iteration(ctx context.Context) error {
+	for _, report := range r.flush(time.Now()) {
+		r.report(ctx, report)
+	}
+	return nil
+}
+
+// stopping reports the discards accumulated since the last report, so that they aren't lost on shutdown.
+func (r *discardReporter) stopping(_ error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.WebhookTimeout)
+	defer cancel()
+	return r.iteration(ctx)
+}
+
+// flush returns the report of each tenant and resets the tracked discards.
+func (r *discardReporter) flush(now time.Time) []DiscardReport {
+	r.mtx.Lock()
+	tenants, from := r.tenants, r.from
+	r.tenants, r.from = map[string]map[discardKey]*DiscardedStream{}, now
+	r.mtx.Unlock()
+
+	reports := make([]DiscardReport, 0, len(tenants))
+	for tenant, streams := range tenants {
+		report := DiscardReport{
+			Tenant:  tenant,
+			From:    from,
+			Through: now,
+			Streams: make([]DiscardedStream, 0, len(streams)),
+		}
+		for _, s := range streams {
+			report.Streams = append(report.Streams, *s)
+		}
+		sort.Slice(report.Streams, func(i, j int) bool {
+			if report.Streams[i].Bytes != report.Streams[j].Bytes {
+				return report.Streams[i].Bytes > report.Streams[j].Bytes
+			}
+			return report.Streams[i].Lines > report.Streams[j].Lines
+		})
+		if len(report.Streams) > r.cfg.TopStreams {
+			report.Streams = report.Streams[:r.cfg.TopStreams]
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Tenant < reports[j].Tenant })
+	return reports
+}
+
+func (r *discardReporter) report(ctx context.Context, report DiscardReport) {
+	for _, s := range report.Streams {
+		level.Warn(r.logger).Log(
+			"msg", "discarded log lines",
+			"tenant", report.Tenant,
+			"reason", s.Reason,
+			"stream", s.Stream,
+			"lines", s.Lines,
+			"bytes", s.Bytes,
+		)
+	}
+
+	if r.cfg.WebhookURL == "" {
+		return
+	}
+	if err := r.send(ctx, report); err != nil {
+		level.Error(r.logger).Log("msg", "failed to send discard report to webhook", "tenant", report.Tenant, "err", err)
+	}
+}
+
+func (r *discardReporter) send(ctx context.Context, report DiscardReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(user.OrgIDHeaderName, report.Tenant)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
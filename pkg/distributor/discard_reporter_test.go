@@ -0,0 +1,79 @@
+package distributor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func TestDiscardReporter_Flush(t *testing.T) {
+	r := newDiscardReporter(DiscardReportConfig{Interval: time.Minute, TopStreams: 2}, log.NewNopLogger())
+
+	r.record("tenant-b", "line_too_long", `{app="a"}`, 1, 10)
+	r.record("tenant-a", "line_too_long", `{app="a"}`, 1, 10)
+	r.record("tenant-a", "line_too_long", `{app="a"}`, 1, 10)
+	r.record("tenant-a", "greater_than_max_sample_age", `{app="a"}`, 3, 30)
+	r.record("tenant-a", "line_too_long", `{app="b"}`, 1, 5)
+
+	reports := r.flush(time.Now())
+	require.Len(t, reports, 2)
+
+	require.Equal(t, "tenant-a", reports[0].Tenant)
+	require.Equal(t, []DiscardedStream{
+		{Reason: "greater_than_max_sample_age", Stream: `{app="a"}`, Lines: 3, Bytes: 30},
+		{Reason: "line_too_long", Stream: `{app="a"}`, Lines: 2, Bytes: 20},
+	}, reports[0].Streams)
+
+	require.Equal(t, "tenant-b", reports[1].Tenant)
+	require.Equal(t, []DiscardedStream{
+		{Reason: "line_too_long", Stream: `{app="a"}`, Lines: 1, Bytes: 10},
+	}, reports[1].Streams)
+
+	// The tracked discards are reset after each report.
+	require.Empty(t, r.flush(time.Now()))
+}
+
+func TestDiscardReporter_Webhook(t *testing.T) {
+	received := make(chan DiscardReport, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var report DiscardReport
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&report))
+		require.Equal(t, report.Tenant, req.Header.Get(user.OrgIDHeaderName))
+		received <- report
+	}))
+	defer srv.Close()
+
+	r := newDiscardReporter(DiscardReportConfig{
+		Interval:       time.Minute,
+		TopStreams:     10,
+		WebhookURL:     srv.URL,
+		WebhookTimeout: time.Second,
+	}, log.NewNopLogger())
+
+	r.record("tenant", "rate_limited", `{app="a"}`, 2, 20)
+	require.NoError(t, r.iteration(context.Background()))
+
+	report := <-received
+	require.Equal(t, "tenant", report.Tenant)
+	require.Equal(t, []DiscardedStream{{Reason: "rate_limited", Stream: `{app="a"}`, Lines: 2, Bytes: 20}}, report.Streams)
+}
+
+func TestExemplarStream(t *testing.T) {
+	require.Equal(t, `{app="a"}`, exemplarStream(`{app="a"}`))
+
+	long := exemplarStream(`{app="` + strings.Repeat("é", 200) + `"}`)
+	require.Equal(t, prometheus.ExemplarMaxRunes-len("stream"), utf8.RuneCountInString(long))
+	require.True(t, strings.HasSuffix(long, "…"))
+
+	require.True(t, utf8.ValidString(exemplarStream("{app=\"\xff\"}")))
+}
@@ -2,6 +2,7 @@ package distributor
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"math"
 	"math/rand"
@@ -9,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/grafana/loki/pkg/ingester"
 
@@ -28,17 +30,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 	"go.uber.org/atomic"
 
 	"github.com/grafana/loki/pkg/distributor/clientpool"
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/pkg/ingester/client"
+	"github.com/grafana/loki/pkg/loghttp/push"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
+	"github.com/grafana/loki/pkg/tenantusage"
 	"github.com/grafana/loki/pkg/usagestats"
 	"github.com/grafana/loki/pkg/util"
+	"github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/validation"
 )
@@ -61,12 +67,31 @@ type Config struct {
 	factory ring_client.PoolFactory `yaml:"-"`
 
 	RateStore RateStoreConfig `yaml:"rate_store"`
+
+	// OTLP configures how OpenTelemetry logs are turned into streams.
+	OTLP push.OTLPConfig `yaml:"otlp"`
+
+	// DiscardReport configures the periodic report of the streams with the most discarded lines.
+	DiscardReport DiscardReportConfig `yaml:"discard_report"`
+
+	// MaxDecompressedPushSize is the maximum size of the body of the HTTP push requests once decompressed.
+	MaxDecompressedPushSize flagext.ByteSize `yaml:"max_decompressed_push_size"`
 }
 
 // RegisterFlags registers distributor-related flags.
 func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
 	cfg.DistributorRing.RegisterFlags(fs)
 	cfg.RateStore.RegisterFlagsWithPrefix("distributor.rate-store", fs)
+	cfg.OTLP.RegisterFlagsWithPrefix("distributor.otlp", fs)
+	cfg.DiscardReport.RegisterFlagsWithPrefix("distributor.discard-report", fs)
+
+	cfg.MaxDecompressedPushSize = 100 << 20
+	fs.Var(&cfg.MaxDecompressedPushSize, "distributor.max-decompressed-push-size", "Maximum size of the body of the HTTP push requests once decompressed, checked while decompressing it so that small compressed bodies can't exhaust the memory of the distributors. 0 to disable.")
+}
+
+// Validate validates the distributor config.
+func (cfg *Config) Validate() error {
+	return cfg.DiscardReport.Validate()
 }
 
 // RateStore manages the ingestion rate of streams, populated by data fetched from ingesters.
@@ -77,6 +102,7 @@ type RateStore interface {
 // Distributor coordinates replicates and distribution of log streams.
 type Distributor struct {
 	services.Service
+	collogspb.UnimplementedLogsServiceServer
 
 	cfg              Config
 	clientCfg        client.Config
@@ -98,7 +124,13 @@ type Distributor struct {
 	subservicesWatcher *services.FailureWatcher
 	// Per-user rate limiter.
 	ingestionRateLimiter *limiter.RateLimiter
-	labelCache           *lru.Cache
+	// Holds the pushes over the per-user rate limit for up to ingestion_burst_max_delay.
+	admission *admissionQueue
+	// Per-stream rate limiter.
+	streamRateLimiter *streamRateLimiter
+	labelCache        *lru.Cache
+	// Accounts the entries pushed by each tenant.
+	tenantUsage *tenantusage.Tracker
 	// metrics
 	ingesterAppends        *prometheus.CounterVec
 	ingesterAppendFailures *prometheus.CounterVec
@@ -114,6 +146,7 @@ func New(
 	configs *runtime.TenantConfigs,
 	ingestersRing ring.ReadRing,
 	overrides *validation.Overrides,
+	tenantUsage *tenantusage.Tracker,
 	registerer prometheus.Registerer,
 ) (*Distributor, error) {
 	factory := cfg.factory
@@ -137,9 +170,15 @@ func New(
 	// Create the configured ingestion rate limit strategy (local or global).
 	var ingestionRateStrategy limiter.RateLimiterStrategy
 	var distributorsLifecycler *ring.Lifecycler
+	var streamRateRing ReadLifecycler
 	rateLimitStrat := validation.LocalIngestionRateStrategy
 
 	var servs []services.Service
+	if cfg.DiscardReport.Interval > 0 {
+		validator.discards = newDiscardReporter(cfg.DiscardReport, util_log.Logger)
+		servs = append(servs, validator.discards)
+	}
+
 	if overrides.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
 		rateLimitStrat = validation.GlobalIngestionRateStrategy
 		if err != nil {
@@ -153,6 +192,7 @@ func New(
 
 		servs = append(servs, distributorsLifecycler)
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(overrides, distributorsLifecycler)
+		streamRateRing = distributorsLifecycler
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(overrides)
 	}
@@ -172,7 +212,9 @@ func New(
 		validator:              validator,
 		pool:                   clientpool.NewPool(clientCfg.PoolConfig, ingestersRing, factory, util_log.Logger),
 		ingestionRateLimiter:   limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
+		streamRateLimiter:      newStreamRateLimiter(overrides, streamRateRing),
 		labelCache:             labelCache,
+		tenantUsage:            tenantUsage,
 		rateLimitStrat:         rateLimitStrat,
 		ingesterAppends: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 			Namespace: "loki",
@@ -202,6 +244,11 @@ func New(
 			Help:      "Total number of times the distributor has sharded streams",
 		}),
 	}
+	d.admission = newAdmissionQueue(overrides, d.ingestionRateLimiter, promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "distributor_delayed_pushes_total",
+		Help:      "The total number of pushes over the ingestion rate limit which were held for up to ingestion_burst_max_delay, by whether they were eventually admitted.",
+	}, []string{"outcome"}))
 	d.replicationFactor.Set(float64(ingestersRing.ReplicationFactor()))
 	rfStats.Set(int64(ingestersRing.ReplicationFactor()))
 
@@ -250,13 +297,23 @@ func (d *Distributor) stopping(_ error) error {
 
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 type streamTracker struct {
-	stream      logproto.Stream
+	stream logproto.Stream
+	// labels are the parsed labels of the pushed stream, without the shard label of the derived streams.
+	labels      labels.Labels
 	minSuccess  int
 	maxFailures int
 	succeeded   atomic.Int32
 	failed      atomic.Int32
 }
 
+// withLabels sets the parsed labels of the pushed stream of the given trackers.
+func withLabels(trackers []streamTracker, ls labels.Labels) []streamTracker {
+	for i := range trackers {
+		trackers[i].labels = ls
+	}
+	return trackers
+}
+
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 type pushTracker struct {
 	streamsPending atomic.Int32
@@ -287,7 +344,9 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	validatedLineCount := 0
 
 	var validationErr error
-	validationContext := d.validator.getValidationContextForTime(time.Now(), userID)
+	var streamRateErr *validation.ErrMaxStreamRate
+	now := time.Now()
+	validationContext := d.validator.getValidationContextForTime(now, userID)
 
 	for _, stream := range req.Streams {
 		// Return early if stream does not contain any entries
@@ -298,15 +357,13 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 		// Truncate first so subsequent steps have consistent line lengths
 		d.truncateLines(validationContext, &stream)
 
-		stream.Labels, stream.Hash, err = d.parseStreamLabels(validationContext, stream.Labels, &stream)
+		rawLabels := stream.Labels
+		var ls labels.Labels
+		stream.Labels, stream.Hash, ls, err = d.parseStreamLabels(validationContext, stream.Labels, &stream)
 		if err != nil {
 			validationErr = err
-			validation.DiscardedSamples.WithLabelValues(validation.InvalidLabels, userID).Add(float64(len(stream.Entries)))
-			bytes := 0
-			for _, e := range stream.Entries {
-				bytes += len(e.Line)
-			}
-			validation.DiscardedBytes.WithLabelValues(validation.InvalidLabels, userID).Add(float64(bytes))
+			stream.Labels = rawLabels
+			d.validator.discardStream(validation.InvalidLabels, userID, stream)
 			continue
 		}
 
@@ -333,43 +390,76 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 			}
 
 			n++
-			validatedLineSize += len(entry.Line)
-			validatedLineCount++
 			streamSize += len(entry.Line)
 		}
 		stream.Entries = stream.Entries[:n]
 
 		shardStreamsCfg := d.validator.Limits.ShardStreams(userID)
+		if n > 0 && !d.streamRateLimiter.AllowN(now, userID, stream.Hash, streamSize) {
+			rejectedLines, rejectedSize := n, streamSize
+			if shardStreamsCfg.Enabled && shardStreamsCfg.ShardRateLimitedStreams {
+				derivedKeys, derivedStreams, shardedLines, shardedSize := d.shardRateLimitedStream(now, userID, stream, shardStreamsCfg)
+				keys = append(keys, derivedKeys...)
+				streams = append(streams, withLabels(derivedStreams, ls)...)
+				validatedLineCount += shardedLines
+				validatedLineSize += shardedSize
+				rejectedLines -= shardedLines
+				rejectedSize -= shardedSize
+			}
+			if rejectedLines > 0 {
+				d.validator.discard(validation.MaxStreamRate, userID, stream.Labels, rejectedLines, rejectedSize)
+				if streamRateErr == nil {
+					streamRateErr = &validation.ErrMaxStreamRate{Tenant: userID, Limit: d.validator.MaxStreamRateBytes(userID)}
+				}
+				streamRateErr.Streams = append(streamRateErr.Streams, validation.RateLimitedStream{Labels: stream.Labels, Lines: rejectedLines, Bytes: rejectedSize})
+			}
+			continue
+		}
+		validatedLineSize += streamSize
+		validatedLineCount += n
+
 		if shardStreamsCfg.Enabled {
 			derivedKeys, derivedStreams := d.shardStream(stream, streamSize, userID)
 			keys = append(keys, derivedKeys...)
-			streams = append(streams, derivedStreams...)
+			streams = append(streams, withLabels(derivedStreams, ls)...)
 		} else {
 			keys = append(keys, util.TokenFor(userID, stream.Labels))
-			streams = append(streams, streamTracker{stream: stream})
+			streams = append(streams, streamTracker{stream: stream, labels: ls})
 		}
 	}
 
+	if streamRateErr != nil {
+		// Return a 429 identifying the rate limited streams, so that clients retry them.
+		validationErr = maxStreamRateError(streamRateErr)
+	}
+
 	// Return early if none of the streams contained entries
 	if len(streams) == 0 {
 		return &logproto.PushResponse{}, validationErr
 	}
 
-	now := time.Now()
-	if !d.ingestionRateLimiter.AllowN(now, userID, validatedLineSize) {
+	if !d.admission.admit(ctx, userID, validatedLineSize) {
 		// Return a 429 to indicate to the client they are being rate limited
-		validation.DiscardedSamples.WithLabelValues(validation.RateLimited, userID).Add(float64(validatedLineCount))
-		validation.DiscardedBytes.WithLabelValues(validation.RateLimited, userID).Add(float64(validatedLineSize))
+		for _, s := range streams {
+			d.validator.discardStream(validation.RateLimited, userID, s.stream)
+		}
 		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, validation.RateLimitedErrorMsg, userID, int(d.ingestionRateLimiter.Limit(now, userID)), validatedLineCount, validatedLineSize)
 	}
 
 	const maxExpectedReplicationSet = 5 // typical replication factor 3 plus one for inactive plus one for luck
 	var descs [maxExpectedReplicationSet]ring.InstanceDesc
 
+	// With shuffle sharding, the streams of the tenant are only replicated to the ingesters of its shard,
+	// which are evenly selected from each zone when zone-aware replication is enabled.
+	ingestersRing := d.ingestersRing
+	if size := d.validator.Limits.IngestionTenantShardSize(userID); size > 0 {
+		ingestersRing = d.ingestersRing.ShuffleShard(userID, size)
+	}
+
 	streamsByIngester := map[string][]*streamTracker{}
 	ingesterDescs := map[string]ring.InstanceDesc{}
 	for i, key := range keys {
-		replicationSet, err := d.ingestersRing.Get(key, ring.Write, descs[:0], nil, nil)
+		replicationSet, err := ingestersRing.Get(key, ring.Write, descs[:0], nil, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -403,12 +493,52 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	case err := <-tracker.err:
 		return nil, err
 	case <-tracker.done:
+		for i := range streams {
+			d.tenantUsage.AddIngested(userID, streams[i].labels, streams[i].stream.Entries)
+		}
 		return &logproto.PushResponse{}, validationErr
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
 }
 
+// Export implements the OTLP LogsService, converting OpenTelemetry logs into
+// streams before pushing them.
+func (d *Distributor) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pushReq := push.OTLPToPushRequest(req, d.cfg.OTLP, d.allowNonIndexedLabels(userID), time.Now())
+	if _, err := d.Push(ctx, pushReq); err != nil {
+		return nil, err
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+// allowNonIndexedLabels returns whether entries of the tenant may have non-indexed labels.
+func (d *Distributor) allowNonIndexedLabels(userID string) bool {
+	return d.validator.AllowNonIndexedLabels(userID) && d.validator.UnorderedWrites(userID)
+}
+
+// maxStreamRateError returns a 429 error whose body is the JSON encoding of the
+// rate limited streams, along with the error message.
+func maxStreamRateError(e *validation.ErrMaxStreamRate) error {
+	body, err := json.Marshal(struct {
+		Message string `json:"error"`
+		*validation.ErrMaxStreamRate
+	}{e.Error(), e})
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "%s", e.Error())
+	}
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+		Code:    http.StatusTooManyRequests,
+		Headers: []*httpgrpc.Header{{Key: "Content-Type", Values: []string{"application/json"}}},
+		Body:    body,
+	})
+}
+
 func min(x1, x2 int) int {
 	if x1 < x2 {
 		return x1
@@ -441,10 +571,60 @@ func (d *Distributor) shardStream(stream logproto.Stream, streamSize int, userID
 
 	if shardCount > len(stream.Entries) {
 		shardsOrder := d.randomizeShardsOrder(shardCount)
-		return d.divideEntriesBetweenShards(logger, userID, len(stream.Entries), shardStreamsCfg, stream, shardsOrder)
+		return d.divideEntriesBetweenShards(logger, userID, len(stream.Entries), shardStreamsCfg, stream, shardsOrder, "")
 	}
 
-	return d.divideEntriesBetweenShards(logger, userID, shardCount, shardStreamsCfg, stream, nil)
+	return d.divideEntriesBetweenShards(logger, userID, shardCount, shardStreamsCfg, stream, nil, "")
+}
+
+// shardRateLimitedStream splits a stream which exceeds max_stream_rate_bytes into shards small enough
+// to fit the limit, instead of rejecting its entries. Each shard is rate limited as its own stream.
+// It returns the accepted shards, with their number of lines and bytes.
+func (d *Distributor) shardRateLimitedStream(now time.Time, userID string, stream logproto.Stream, shardStreamsCfg *shardstreams.Config) ([]uint32, []streamTracker, int, int) {
+	streamSize := 0
+	for _, e := range stream.Entries {
+		streamSize += len(e.Line)
+	}
+
+	rate := d.rateStore.RateFor(stream.Hash)
+	// The stream is over its limit, so at least 2 shards are needed.
+	shardCount := calculateShards(rate, streamSize, d.validator.MaxStreamRateBytes(userID))
+	if shardCount < 2 {
+		shardCount = 2
+	}
+	shardCount = min(shardCount, len(stream.Entries))
+	if shardCount < 2 {
+		// A single entry can't be split.
+		return nil, nil, 0, 0
+	}
+
+	logger := log.With(util_log.WithUserID(userID, util_log.Logger), "stream", stream.Labels)
+	d.streamShardCount.Inc()
+	if shardStreamsCfg.LoggingEnabled {
+		level.Info(logger).Log("msg", "sharding rate limited stream", "shard_count", shardCount)
+	}
+
+	derivedKeys, derivedStreams := d.divideEntriesBetweenShards(logger, userID, shardCount, shardStreamsCfg, stream, nil, ingester.RateLimitedShardPrefix)
+
+	var (
+		keys          = make([]uint32, 0, len(derivedKeys))
+		streams       = make([]streamTracker, 0, len(derivedStreams))
+		lines, nBytes int
+	)
+	for i, shard := range derivedStreams {
+		shardSize := 0
+		for _, e := range shard.stream.Entries {
+			shardSize += len(e.Line)
+		}
+		if !d.streamRateLimiter.AllowN(now, userID, shard.stream.Hash, shardSize) {
+			continue
+		}
+		keys = append(keys, derivedKeys[i])
+		streams = append(streams, streamTracker{stream: shard.stream})
+		lines += len(shard.stream.Entries)
+		nBytes += shardSize
+	}
+	return keys, streams, lines, nBytes
 }
 
 func (d *Distributor) randomizeShardsOrder(shardCount int) []int {
@@ -457,7 +637,9 @@ func (d *Distributor) randomizeShardsOrder(shardCount int) []int {
 	return shardsOrder
 }
 
-func (d *Distributor) divideEntriesBetweenShards(logger log.Logger, userID string, shardCount int, shardStreamsCfg *shardstreams.Config, stream logproto.Stream, alternateShardsOrder []int) ([]uint32, []streamTracker) {
+// divideEntriesBetweenShards splits the stream into shards whose value of the shard label is the shard number,
+// prefixed with shardPrefix.
+func (d *Distributor) divideEntriesBetweenShards(logger log.Logger, userID string, shardCount int, shardStreamsCfg *shardstreams.Config, stream logproto.Stream, alternateShardsOrder []int, shardPrefix string) ([]uint32, []streamTracker) {
 	derivedKeys := make([]uint32, 0, shardCount)
 	derivedStreams := make([]streamTracker, 0, shardCount)
 	streamLabels := labelTemplate(stream.Labels)
@@ -468,7 +650,7 @@ func (d *Distributor) divideEntriesBetweenShards(logger log.Logger, userID strin
 		if len(alternateShardsOrder) > 0 {
 			j = alternateShardsOrder[i]
 		}
-		shard, ok := d.createShard(shardStreamsCfg, stream, streamLabels, streamPattern, shardCount, i, j)
+		shard, ok := d.createShard(shardStreamsCfg, stream, streamLabels, streamPattern, shardCount, i, shardPrefix+strconv.Itoa(j))
 		if !ok {
 			level.Error(logger).Log("msg", "couldn't create shard", "idx", i)
 			continue
@@ -501,13 +683,12 @@ func labelTemplate(lbls string) labels.Labels {
 	return streamLabels
 }
 
-func (d *Distributor) createShard(streamshardCfg *shardstreams.Config, stream logproto.Stream, lbls labels.Labels, streamPattern string, totalShards, spot, shardNumber int) (logproto.Stream, bool) {
+func (d *Distributor) createShard(streamshardCfg *shardstreams.Config, stream logproto.Stream, lbls labels.Labels, streamPattern string, totalShards, spot int, shardLabel string) (logproto.Stream, bool) {
 	lowerBound, upperBound, ok := d.boundsFor(stream, totalShards, spot, streamshardCfg.LoggingEnabled)
 	if !ok {
 		return logproto.Stream{}, false
 	}
 
-	shardLabel := strconv.Itoa(shardNumber)
 	lbls[len(lbls)-1] = labels.Label{Name: ingester.ShardLbName, Value: shardLabel}
 	return logproto.Stream{
 		Labels:  strings.Replace(streamPattern, ingester.ShardLbPlaceholder, shardLabel, 1),
@@ -561,6 +742,50 @@ func (d *Distributor) truncateLines(vContext validationContext, stream *logproto
 	validation.MutatedBytes.WithLabelValues(validation.LineTooLong, vContext.userID).Add(float64(truncatedBytes))
 }
 
+// truncateLabels drops the labels with a name too long, truncates the label values too long and drops the labels
+// above the maximum number of labels, instead of rejecting the stream. It returns whether the labels were mutated.
+func (d *Distributor) truncateLabels(vContext validationContext, ls labels.Labels, stream *logproto.Stream) (labels.Labels, bool) {
+	if !vContext.truncateLabels {
+		return ls, false
+	}
+
+	mutated := map[string]int{}
+	result := make(labels.Labels, 0, len(ls))
+	for _, l := range ls {
+		if len(l.Name) > vContext.maxLabelNameLength {
+			mutated[validation.LabelNameTooLong]++
+			continue
+		}
+		if len(l.Value) > vContext.maxLabelValueLength {
+			l.Value = truncateLabelValue(l.Value, vContext.maxLabelValueLength)
+			mutated[validation.LabelValueTooLong]++
+			if l.Value == "" {
+				continue
+			}
+		}
+		result = append(result, l)
+	}
+	// the labels are sorted, so the same ones are kept for all the pushes of the stream
+	if len(result) > vContext.maxLabelNamesPerSeries {
+		mutated[validation.MaxLabelNamesPerSeries] += len(result) - vContext.maxLabelNamesPerSeries
+		result = result[:vContext.maxLabelNamesPerSeries]
+	}
+
+	for reason, n := range mutated {
+		validation.MutatedLabels.WithLabelValues(reason, vContext.userID).Add(float64(n))
+		validation.MutatedSamples.WithLabelValues(reason, vContext.userID).Add(float64(len(stream.Entries)))
+	}
+	return result, len(mutated) > 0
+}
+
+// truncateLabelValue truncates a label value to at most max bytes, without splitting a multi-byte rune.
+func truncateLabelValue(value string, max int) string {
+	for max > 0 && !utf8.RuneStart(value[max]) {
+		max--
+	}
+	return value[:max]
+}
+
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (d *Distributor) sendStreams(ctx context.Context, ingester ring.InstanceDesc, streamTrackers []*streamTracker, pushTracker *pushTracker) {
 	err := d.sendStreamsErr(ctx, ingester, streamTrackers)
@@ -618,29 +843,37 @@ func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.Instance
 type labelData struct {
 	labels string
 	hash   uint64
+	parsed labels.Labels
 }
 
-func (d *Distributor) parseStreamLabels(vContext validationContext, key string, stream *logproto.Stream) (string, uint64, error) {
+// parseStreamLabels returns the validated labels of the stream, their hash and their parsed form, which must not be
+// modified as it's cached.
+func (d *Distributor) parseStreamLabels(vContext validationContext, key string, stream *logproto.Stream) (string, uint64, labels.Labels, error) {
 	if val, ok := d.labelCache.Get(key); ok {
 		labelVal := val.(labelData)
-		return labelVal.labels, labelVal.hash, nil
+		return labelVal.labels, labelVal.hash, labelVal.parsed, nil
 	}
 
 	ls, err := syntax.ParseLabels(key)
 	if err != nil {
-		return "", 0, httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidLabelsErrorMsg, key, err)
+		return "", 0, nil, httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidLabelsErrorMsg, key, err)
 	}
 
+	ls, truncated := d.truncateLabels(vContext, ls, stream)
+
 	// ensure labels are correctly sorted.
 	if err := d.validator.ValidateLabels(vContext, ls, *stream); err != nil {
-		return "", 0, err
+		return "", 0, nil, err
 	}
 
 	lsVal := ls.String()
 	lsHash := ls.Hash()
 
-	d.labelCache.Add(key, labelData{lsVal, lsHash})
-	return lsVal, lsHash, nil
+	// the truncated labels depend on the limits of the tenant, and are counted at each push.
+	if !truncated {
+		d.labelCache.Add(key, labelData{lsVal, lsHash, ls})
+	}
+	return lsVal, lsHash, ls, nil
 }
 
 // shardCountFor returns the right number of shards to be used by the given stream.
@@ -2,10 +2,12 @@ package distributor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,14 +23,20 @@ import (
 	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/grafana/loki/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/logproto"
@@ -393,6 +401,36 @@ func TestDistributorPushConcurrently(t *testing.T) {
 	}
 }
 
+func TestDistributorPushShuffleSharding(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionTenantShardSize = 3
+
+	distributors, ingesters := prepare(t, 1, 10, limits, nil)
+
+	streams := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		streams = append(streams, fmt.Sprintf(`{app="foo-%d"}`, i))
+	}
+	response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(10, 10, streams))
+	require.NoError(t, err)
+	require.Equal(t, &logproto.PushResponse{}, response)
+
+	// make sure the ingesters received the push requests
+	time.Sleep(10 * time.Millisecond)
+
+	// The streams of the tenant are only pushed to the ingesters of its shard.
+	var pushedTo int
+	for i := range ingesters {
+		ingesters[i].mu.Lock()
+		if len(ingesters[i].pushed) > 0 {
+			pushedTo++
+		}
+		ingesters[i].mu.Unlock()
+	}
+	require.Equal(t, 3, pushedTo)
+}
+
 func TestDistributorPushErrors(t *testing.T) {
 	limits := &validation.Limits{}
 	flagext.DefaultValues(limits)
@@ -467,6 +505,55 @@ func Test_TruncateLogLines(t *testing.T) {
 	})
 }
 
+func Test_TruncateLabels(t *testing.T) {
+	setup := func(truncate bool) (*Distributor, *mockIngester) {
+		limits := &validation.Limits{}
+		flagext.DefaultValues(limits)
+
+		limits.EnforceMetricName = false
+		limits.MaxLabelNameLength = 5
+		limits.MaxLabelValueLength = 2
+		limits.MaxLabelNamesPerSeries = 2
+		limits.TruncateLabels = truncate
+		ingester := &mockIngester{}
+		distributors, _ := prepare(t, 1, 5, limits, func(addr string) (ring_client.PoolClient, error) { return ingester, nil })
+		return distributors[0], ingester
+	}
+	request := func() *logproto.PushRequest {
+		return makeWriteRequestWithLabels(3, 10, []string{`{d="e", toolong="x", c="héllo", a="b"}`})
+	}
+
+	t.Run("it rejects the streams when TruncateLabels is false", func(t *testing.T) {
+		distributor, ingester := setup(false)
+		_, err := distributor.Push(ctx, request())
+		require.Error(t, err)
+		require.Empty(t, ingester.pushed)
+	})
+
+	t.Run("it drops and truncates the labels when TruncateLabels is true", func(t *testing.T) {
+		distributor, ingester := setup(true)
+		mutated := func(reason string) float64 {
+			return testutil.ToFloat64(validation.MutatedLabels.WithLabelValues(reason, "test"))
+		}
+		before := map[string]float64{}
+		for _, reason := range []string{validation.LabelNameTooLong, validation.LabelValueTooLong, validation.MaxLabelNamesPerSeries} {
+			before[reason] = mutated(reason)
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := distributor.Push(ctx, request())
+			require.NoError(t, err)
+			// the value is truncated before the multi-byte rune, and only the first labels are kept
+			require.Equal(t, `{a="b", c="h"}`, ingester.pushed[i].Streams[0].Labels)
+		}
+
+		// the labels are mutated again at each push
+		require.Equal(t, float64(2), mutated(validation.LabelNameTooLong)-before[validation.LabelNameTooLong])
+		require.Equal(t, float64(2), mutated(validation.LabelValueTooLong)-before[validation.LabelValueTooLong])
+		require.Equal(t, float64(2), mutated(validation.MaxLabelNamesPerSeries)-before[validation.MaxLabelNamesPerSeries])
+	})
+}
+
 func TestStreamShard(t *testing.T) {
 	// setup base stream.
 	baseStream := logproto.Stream{}
@@ -680,7 +767,7 @@ func Benchmark_SortLabelsOnPush(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		stream := request.Streams[0]
 		stream.Labels = `{buzz="f", a="b"}`
-		_, _, err := d.parseStreamLabels(vCtx, stream.Labels, &stream)
+		_, _, _, err := d.parseStreamLabels(vCtx, stream.Labels, &stream)
 		if err != nil {
 			panic("parseStreamLabels fail,err:" + err.Error())
 		}
@@ -954,6 +1041,253 @@ func TestDistributor_PushIngestionRateLimiter(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushIngestionBurstDelay(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRateStrategy = validation.LocalIngestionRateStrategy
+	limits.IngestionRateMB = 100 * (1.0 / float64(bytesInMB))
+	limits.IngestionBurstSizeMB = 100 * (1.0 / float64(bytesInMB))
+	limits.IngestionBurstMaxDelay = model.Duration(2 * time.Second)
+	limits.IngestionBurstMaxQueued = 1
+
+	distributors, _ := prepare(t, 1, 5, limits, nil)
+	d := distributors[0]
+
+	response, err := d.Push(ctx, makeWriteRequest(1, 100))
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+
+	// the limiter allows 50 more bytes after half a second, so the push is delayed rather than rejected
+	start := time.Now()
+	response, err = d.Push(ctx, makeWriteRequest(1, 50))
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+	require.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	require.Equal(t, 1.0, testutil.ToFloat64(d.admission.delayed.WithLabelValues(admissionAdmitted)))
+
+	// pushes larger than the burst are rejected right away
+	_, err = d.Push(ctx, makeWriteRequest(1, 150))
+	require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, validation.RateLimitedErrorMsg, "test", 100, 1, 150), err)
+
+	// only one push may wait at once, the other one is rejected right away
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := d.Push(ctx, makeWriteRequest(1, 100))
+			errs <- err
+		}()
+	}
+	var rejected int
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, validation.RateLimitedErrorMsg, "test", 100, 1, 100), err)
+			rejected++
+		}
+	}
+	require.Equal(t, 1, rejected)
+	require.Equal(t, 1.0, testutil.ToFloat64(d.admission.delayed.WithLabelValues(admissionQueueFull)))
+	require.Equal(t, 2.0, testutil.ToFloat64(d.admission.delayed.WithLabelValues(admissionAdmitted)))
+
+	// pushes which can't be admitted within the delay are rejected
+	limits.IngestionBurstMaxDelay = model.Duration(100 * time.Millisecond)
+	distributors, _ = prepare(t, 1, 5, limits, nil)
+	d = distributors[0]
+	_, err = d.Push(ctx, makeWriteRequest(1, 100))
+	require.NoError(t, err)
+	_, err = d.Push(ctx, makeWriteRequest(1, 100))
+	require.Equal(t, httpgrpc.Errorf(http.StatusTooManyRequests, validation.RateLimitedErrorMsg, "test", 100, 1, 100), err)
+	require.Equal(t, 1.0, testutil.ToFloat64(d.admission.delayed.WithLabelValues(admissionTimedOut)))
+}
+
+func TestDistributor_PushIngestionBurstDelayOrder(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRateStrategy = validation.LocalIngestionRateStrategy
+	limits.IngestionRateMB = 100 * (1.0 / float64(bytesInMB))
+	limits.IngestionBurstSizeMB = 100 * (1.0 / float64(bytesInMB))
+	limits.IngestionBurstMaxDelay = model.Duration(5 * time.Second)
+	limits.IngestionBurstMaxQueued = 2
+
+	distributors, _ := prepare(t, 1, 5, limits, nil)
+	d := distributors[0]
+
+	_, err := d.Push(ctx, makeWriteRequest(1, 100))
+	require.NoError(t, err)
+
+	// the small push, which the limiter would allow first, waits behind the large one queued before it
+	order := make(chan int, 2)
+	go func() {
+		_, err := d.Push(ctx, makeWriteRequest(1, 100))
+		require.NoError(t, err)
+		order <- 100
+	}()
+	time.Sleep(100 * time.Millisecond)
+	go func() {
+		_, err := d.Push(ctx, makeWriteRequest(1, 10))
+		require.NoError(t, err)
+		order <- 10
+	}()
+	require.Equal(t, 100, <-order)
+	require.Equal(t, 10, <-order)
+	require.Equal(t, 2.0, testutil.ToFloat64(d.admission.delayed.WithLabelValues(admissionAdmitted)))
+}
+
+func TestDistributor_PushStreamRateLimiter(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRateStrategy = validation.LocalIngestionRateStrategy
+	limits.MaxStreamRateBytes = 10
+
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+
+	response, err := distributors[0].Push(ctx, makeWriteRequestWithLabels(1, 8, []string{`{foo="bar"}`, `{foo="baz"}`}))
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+
+	// {foo="bar"} is now over its limit, but {foo="baz"} isn't.
+	request := makeWriteRequestWithLabels(1, 3, []string{`{foo="bar"}`})
+	request.Streams = append(request.Streams, makeWriteRequestWithLabels(2, 4, []string{`{foo="bar"}`}).Streams...)
+	request.Streams = append(request.Streams, makeWriteRequestWithLabels(1, 2, []string{`{foo="baz"}`}).Streams...)
+	_, err = distributors[0].Push(ctx, request)
+	require.Error(t, err)
+
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Equal(t, "application/json", resp.Headers[0].Values[0])
+
+	var body struct {
+		Error string `json:"error"`
+		validation.ErrMaxStreamRate
+	}
+	require.NoError(t, json.Unmarshal(resp.Body, &body))
+	require.Equal(t, "test", body.Tenant)
+	require.Equal(t, 10, body.Limit)
+	require.Equal(t, []validation.RateLimitedStream{
+		{Labels: `{foo="bar"}`, Lines: 1, Bytes: 3},
+		{Labels: `{foo="bar"}`, Lines: 2, Bytes: 8},
+	}, body.Streams)
+	require.Contains(t, body.Error, `{foo="bar"}`)
+
+	// Only {foo="baz"} was sent to the ingesters along with the first push.
+	pushed := func() interface{} {
+		var res []string
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				for _, s := range req.Streams {
+					res = append(res, s.Labels)
+				}
+			}
+			ingesters[i].mu.Unlock()
+		}
+		sort.Strings(res)
+		return res
+	}
+	test.Poll(t, time.Second, []string{`{foo="bar"}`, `{foo="bar"}`, `{foo="bar"}`, `{foo="baz"}`, `{foo="baz"}`, `{foo="baz"}`, `{foo="baz"}`, `{foo="baz"}`, `{foo="baz"}`}, pushed)
+}
+
+func TestDistributor_PushStreamRateLimiterShardsStreams(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.EnforceMetricName = false
+	limits.IngestionRateStrategy = validation.LocalIngestionRateStrategy
+	limits.MaxStreamRateBytes = 10
+	limits.ShardStreams = &shardstreams.Config{
+		Enabled:                 true,
+		DesiredRate:             1000,
+		ShardRateLimitedStreams: true,
+	}
+
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+
+	_, err := distributors[0].Push(ctx, makeWriteRequest(1, 8))
+	require.NoError(t, err)
+
+	// The stream is now over its limit, so it is split into shards which each fit the limit.
+	_, err = distributors[0].Push(ctx, makeWriteRequest(2, 8))
+	require.NoError(t, err)
+
+	// The shards are over their limit too.
+	_, err = distributors[0].Push(ctx, makeWriteRequest(2, 8))
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	pushed := func() interface{} {
+		var res []string
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				for _, s := range req.Streams {
+					res = append(res, s.Labels)
+				}
+			}
+			ingesters[i].mu.Unlock()
+		}
+		sort.Strings(res)
+		return res
+	}
+	test.Poll(t, time.Second, []string{
+		`{foo="bar", __stream_shard__="rate_limited_0"}`, `{foo="bar", __stream_shard__="rate_limited_0"}`, `{foo="bar", __stream_shard__="rate_limited_0"}`,
+		`{foo="bar", __stream_shard__="rate_limited_1"}`, `{foo="bar", __stream_shard__="rate_limited_1"}`, `{foo="bar", __stream_shard__="rate_limited_1"}`,
+		`{foo="bar"}`, `{foo="bar"}`, `{foo="bar"}`,
+	}, pushed)
+}
+
+func TestDistributor_Export(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.AllowNonIndexedLabels = true
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				{Key: "service.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "checkout"}}},
+				{Key: "host.name", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "node-1"}}},
+			}},
+			ScopeLogs: []*logspb.ScopeLogs{{
+				LogRecords: []*logspb.LogRecord{{
+					TimeUnixNano: uint64(time.Now().UnixNano()),
+					Body:         &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "order placed"}},
+				}},
+			}},
+		}},
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	resp, err := distributors[0].Export(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, resp.PartialSuccess)
+
+	pushed := func() interface{} {
+		var res []logproto.Stream
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				res = append(res, req.Streams...)
+			}
+			ingesters[i].mu.Unlock()
+		}
+		return len(res)
+	}
+	test.Poll(t, time.Second, 3, pushed)
+
+	ingesters[0].mu.Lock()
+	defer ingesters[0].mu.Unlock()
+	stream := ingesters[0].pushed[0].Streams[0]
+	require.Equal(t, `{service_name="checkout"}`, stream.Labels)
+	require.Equal(t, []logproto.LabelAdapter{{Name: "host_name", Value: "node-1"}}, stream.Entries[0].NonIndexedLabels)
+
+	_, err = distributors[0].Export(context.Background(), req)
+	require.Error(t, err)
+}
+
 func prepare(t *testing.T, numDistributors, numIngesters int, limits *validation.Limits, factory func(addr string) (ring_client.PoolClient, error)) ([]*Distributor, []mockIngester) {
 	t.Helper()
 
@@ -1027,7 +1361,7 @@ func prepare(t *testing.T, numDistributors, numIngesters int, limits *validation
 		overrides, err := validation.NewOverrides(*limits, nil)
 		require.NoError(t, err)
 
-		d, err := New(distributorConfig, clientConfig, runtime.DefaultTenantConfigs(), ingestersRing, overrides, prometheus.NewPedanticRegistry())
+		d, err := New(distributorConfig, clientConfig, runtime.DefaultTenantConfigs(), ingestersRing, overrides, nil, prometheus.NewPedanticRegistry())
 		require.NoError(t, err)
 		require.NoError(t, services.StartAndAwaitRunning(context.Background(), d))
 		distributors[i] = d
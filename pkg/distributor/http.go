@@ -1,9 +1,11 @@
 package distributor
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/weaveworks/common/httpgrpc"
 
@@ -12,12 +14,28 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/pkg/loghttp/push"
+	"github.com/grafana/loki/pkg/logproto"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/validation"
 )
 
+type requestParser func(logger log.Logger, tenantID string, r *http.Request) (*logproto.PushRequest, error)
+
 // PushHandler reads a snappy-compressed proto from the HTTP body.
 func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
+	d.pushHandler(w, r, func(logger log.Logger, tenantID string, r *http.Request) (*logproto.PushRequest, error) {
+		return push.ParseRequest(logger, tenantID, r, d.tenantsRetention, int(d.cfg.MaxDecompressedPushSize))
+	})
+}
+
+// OTLPPushHandler reads OpenTelemetry logs, encoded as protobuf or JSON, from the HTTP body.
+func (d *Distributor) OTLPPushHandler(w http.ResponseWriter, r *http.Request) {
+	d.pushHandler(w, r, func(logger log.Logger, tenantID string, r *http.Request) (*logproto.PushRequest, error) {
+		return push.ParseOTLPRequest(logger, tenantID, r, d.tenantsRetention, d.cfg.OTLP, d.allowNonIndexedLabels(tenantID), int(d.cfg.MaxDecompressedPushSize))
+	})
+}
+
+func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, parse requestParser) {
 	logger := util_log.WithContext(r.Context(), util_log.Logger)
 	tenantID, err := tenant.TenantID(r.Context())
 	if err != nil {
@@ -25,16 +43,23 @@ func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	req, err := push.ParseRequest(logger, tenantID, r, d.tenantsRetention)
+	req, err := parse(logger, tenantID, r)
 	if err != nil {
+		code := http.StatusBadRequest
+		var encErr *push.UnsupportedContentEncodingError
+		if errors.As(err, &encErr) {
+			// tell the client which encodings it can use instead.
+			code = http.StatusUnsupportedMediaType
+			w.Header().Set("Accept-Encoding", strings.Join(push.SupportedContentEncodings, ", "))
+		}
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
 				"msg", "push request failed",
-				"code", http.StatusBadRequest,
+				"code", code,
 				"err", err,
 			)
 		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), code)
 		return
 	}
 
@@ -70,7 +95,16 @@ func (d *Distributor) PushHandler(w http.ResponseWriter, r *http.Request) {
 				"err", body,
 			)
 		}
-		http.Error(w, body, int(resp.Code))
+		if len(resp.Headers) == 0 {
+			http.Error(w, body, int(resp.Code))
+			return
+		}
+		// Errors carrying headers, such as structured JSON errors, are written as is.
+		for _, h := range resp.Headers {
+			w.Header()[h.Key] = h.Values
+		}
+		w.WriteHeader(int(resp.Code))
+		_, _ = w.Write(resp.Body)
 	} else {
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
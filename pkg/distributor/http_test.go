@@ -4,11 +4,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/dskit/flagext"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
+	"github.com/grafana/loki/pkg/util/test"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -54,3 +59,83 @@ func TestDistributorRingHandler(t *testing.T) {
 		require.NotContains(t, string(body), "<th>Instance ID</th>")
 	})
 }
+
+func TestDistributorPushHandler_StructuredError(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRateStrategy = validation.LocalIngestionRateStrategy
+	limits.MaxStreamRateBytes = 1
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	body := `{"streams": [{"stream": {"foo": "bar"}, "values": [["` + strconv.FormatInt(time.Now().UnixNano(), 10) + `", "fizzbuzz"]]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/push", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec := httptest.NewRecorder()
+
+	distributors[0].PushHandler(rec, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Contains(t, rec.Body.String(), `"labels":"{foo=\"bar\"}"`)
+}
+
+func TestDistributorOTLPPushHandler(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	distributors, ingesters := prepare(t, 1, 3, limits, nil)
+
+	ts := strconv.FormatInt(time.Now().UnixNano(), 10)
+	body := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},"scopeLogs":[{"logRecords":[{"timeUnixNano":"` + ts + `","body":{"stringValue":"order placed"}}]}]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/otlp/v1/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec := httptest.NewRecorder()
+
+	distributors[0].OTLPPushHandler(rec, req)
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	pushed := func() interface{} {
+		var res []string
+		for i := range ingesters {
+			ingesters[i].mu.Lock()
+			for _, req := range ingesters[i].pushed {
+				for _, s := range req.Streams {
+					res = append(res, s.Labels+" "+s.Entries[0].Line)
+				}
+			}
+			ingesters[i].mu.Unlock()
+		}
+		return res
+	}
+	expected := `{service_name="checkout"} order placed`
+	test.Poll(t, time.Second, []string{expected, expected, expected}, pushed)
+
+	// Other encodings of logs are rejected.
+	req = httptest.NewRequest(http.MethodPost, "/otlp/v1/logs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/plain")
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec = httptest.NewRecorder()
+
+	distributors[0].OTLPPushHandler(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDistributorPushHandler_UnsupportedContentEncoding(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	distributors, _ := prepare(t, 1, 3, limits, nil)
+
+	body := `{"streams": [{"stream": {"foo": "bar"}, "values": [["` + strconv.FormatInt(time.Now().UnixNano(), 10) + `", "fizzbuzz"]]}]}`
+	req := httptest.NewRequest(http.MethodPost, "/loki/api/v1/push", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "br")
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	rec := httptest.NewRecorder()
+
+	distributors[0].PushHandler(rec, req)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	require.Equal(t, "gzip, deflate, snappy, zstd", rec.Header().Get("Accept-Encoding"))
+}
@@ -0,0 +1,349 @@
+// Package kafka implements the kafka-consumer target, which ingests log entries
+// published to Kafka topics instead of pushed over HTTP or gRPC.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/loki/pkg/loghttp/push"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/util/unmarshal"
+)
+
+// Formats of the Kafka messages.
+const (
+	FormatLokiProtobuf = "loki-protobuf"
+	FormatLokiJSON     = "loki-json"
+	FormatOTLPProtobuf = "otlp-protobuf"
+	FormatOTLPJSON     = "otlp-json"
+)
+
+// TenantHeader is the header of a Kafka message holding its tenant, which
+// overrides the configured tenant.
+const TenantHeader = "X-Scope-OrgID"
+
+var supportedFormats = []string{FormatLokiProtobuf, FormatLokiJSON, FormatOTLPProtobuf, FormatOTLPJSON}
+
+// Config configures the kafka-consumer target.
+type Config struct {
+	Brokers  flagext.StringSliceCSV `yaml:"brokers"`
+	Topics   flagext.StringSliceCSV `yaml:"topics"`
+	GroupID  string                 `yaml:"group_id"`
+	Version  string                 `yaml:"version"`
+	Assignor string                 `yaml:"assignor"`
+	Format   string                 `yaml:"format"`
+	TenantID string                 `yaml:"tenant_id"`
+
+	// Backoff between retries of pushes failing with a retryable error. Pushes
+	// are retried until they succeed, pausing the consumption of their partition.
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// RegisterFlagsWithPrefix registers the flags.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.Var(&cfg.Brokers, prefix+".brokers", "Comma separated list of the Kafka brokers to connect to.")
+	f.Var(&cfg.Topics, prefix+".topics", "Comma separated list of the Kafka topics to consume.")
+	f.StringVar(&cfg.GroupID, prefix+".group-id", "loki", "Kafka consumer group of the consumers. Partitions of the topics are balanced between the members of the group.")
+	f.StringVar(&cfg.Version, prefix+".version", "2.2.1", "Kafka version of the brokers.")
+	f.StringVar(&cfg.Assignor, prefix+".assignor", sarama.RangeBalanceStrategyName, "Strategy assigning partitions to the members of the consumer group. Supported values are range, roundrobin and sticky.")
+	f.StringVar(&cfg.Format, prefix+".format", FormatLokiProtobuf, fmt.Sprintf("Format of the Kafka messages. Supported values are %s.", strings.Join(supportedFormats, ", ")))
+	f.StringVar(&cfg.TenantID, prefix+".tenant-id", "", "Tenant of the messages without a "+TenantHeader+" header. Such messages are dropped if empty.")
+	f.DurationVar(&cfg.MinBackoff, prefix+".min-backoff", 100*time.Millisecond, "Minimum delay between retries of a failed push.")
+	f.DurationVar(&cfg.MaxBackoff, prefix+".max-backoff", 10*time.Second, "Maximum delay between retries of a failed push.")
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if len(cfg.Brokers) == 0 {
+		return errors.New("no Kafka brokers configured")
+	}
+	if len(cfg.Topics) == 0 {
+		return errors.New("no Kafka topics configured")
+	}
+	if cfg.GroupID == "" {
+		return errors.New("no Kafka consumer group configured")
+	}
+	for _, f := range supportedFormats {
+		if cfg.Format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported Kafka message format %q, must be one of %s", cfg.Format, strings.Join(supportedFormats, ", "))
+}
+
+// Pusher is the push path of the distributor.
+type Pusher interface {
+	Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error)
+	Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error)
+}
+
+type metrics struct {
+	messages *prometheus.CounterVec
+	retries  *prometheus.CounterVec
+	lag      *prometheus.GaugeVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		messages: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "kafka_consumer_messages_total",
+			Help:      "The total number of consumed Kafka messages by outcome, either pushed or dropped.",
+		}, []string{"topic", "outcome"}),
+		retries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "kafka_consumer_push_retries_total",
+			Help:      "The total number of retried pushes of Kafka messages.",
+		}, []string{"topic"}),
+		lag: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "loki",
+			Name:      "kafka_consumer_partition_lag",
+			Help:      "The number of messages of a partition not yet pushed.",
+		}, []string{"topic", "partition"}),
+	}
+}
+
+// Consumer consumes Kafka topics as a member of a consumer group and pushes
+// their messages to the distributor.
+//
+// The messages of a partition are pushed one at a time and their offset is
+// only committed once they are ingested, so that the messages of partitions
+// reassigned or of a restarting consumer are consumed again. A push failing
+// with a retryable error is retried until it succeeds, which stops the
+// consumption of its partition in the meantime.
+type Consumer struct {
+	services.Service
+
+	cfg     Config
+	pusher  Pusher
+	group   sarama.ConsumerGroup
+	logger  log.Logger
+	metrics *metrics
+}
+
+// New creates a Consumer joining the configured consumer group.
+func New(cfg Config, pusher Pusher, logger log.Logger, registerer prometheus.Registerer) (*Consumer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	config, err := saramaConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	group, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating consumer group client: %w", err)
+	}
+	return newConsumer(cfg, pusher, group, logger, registerer), nil
+}
+
+func newConsumer(cfg Config, pusher Pusher, group sarama.ConsumerGroup, logger log.Logger, registerer prometheus.Registerer) *Consumer {
+	c := &Consumer{
+		cfg:     cfg,
+		pusher:  pusher,
+		group:   group,
+		logger:  log.With(logger, "component", "kafka-consumer"),
+		metrics: newMetrics(registerer),
+	}
+	c.Service = services.NewBasicService(nil, c.running, c.stopping)
+	return c
+}
+
+func saramaConfig(cfg Config) (*sarama.Config, error) {
+	version, err := sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		return nil, err
+	}
+	config := sarama.NewConfig()
+	config.Version = version
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Consumer.Return.Errors = true
+
+	switch cfg.Assignor {
+	case sarama.StickyBalanceStrategyName:
+		config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategySticky
+	case sarama.RoundRobinBalanceStrategyName:
+		config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRoundRobin
+	case sarama.RangeBalanceStrategyName, "":
+		config.Consumer.Group.Rebalance.Strategy = sarama.BalanceStrategyRange
+	default:
+		return nil, fmt.Errorf("unrecognized consumer group partition assignor: %s", cfg.Assignor)
+	}
+	return config, nil
+}
+
+func (c *Consumer) running(ctx context.Context) error {
+	go func() {
+		for err := range c.group.Errors() {
+			level.Warn(c.logger).Log("msg", "error from the consumer group", "err", err)
+		}
+	}()
+
+	level.Info(c.logger).Log("msg", "starting consumer", "topics", strings.Join(c.cfg.Topics, ","), "group", c.cfg.GroupID)
+	b := backoff.New(ctx, backoff.Config{MinBackoff: c.cfg.MinBackoff, MaxBackoff: c.cfg.MaxBackoff})
+	for ctx.Err() == nil {
+		// Consume returns whenever the group rebalances, after which the claims
+		// are renewed by calling it again.
+		if err := c.group.Consume(ctx, c.cfg.Topics, c); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			level.Error(c.logger).Log("msg", "error from the consumer, retrying", "err", err)
+			b.Wait()
+			continue
+		}
+		b.Reset()
+	}
+	return nil
+}
+
+func (c *Consumer) stopping(_ error) error {
+	return c.group.Close()
+}
+
+// Setup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim implements sarama.ConsumerGroupHandler. It pushes the messages
+// of a partition in order until the session ends.
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	ctx := session.Context()
+	partition := strconv.Itoa(int(claim.Partition()))
+	level.Info(c.logger).Log("msg", "consuming partition", "topic", claim.Topic(), "partition", partition)
+
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if !c.push(ctx, msg) {
+				// The session ended while retrying, the message is left
+				// uncommitted to be consumed again.
+				return nil
+			}
+			session.MarkMessage(msg, "")
+			c.metrics.lag.WithLabelValues(msg.Topic, partition).Set(float64(claim.HighWaterMarkOffset() - msg.Offset - 1))
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// push pushes a message, retrying retryable errors. It returns false if the
+// context ended before the message was either pushed or dropped.
+func (c *Consumer) push(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	logger := log.With(c.logger, "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+
+	tenantID := c.cfg.TenantID
+	for _, h := range msg.Headers {
+		if h != nil && string(h.Key) == TenantHeader {
+			tenantID = string(h.Value)
+		}
+	}
+	if tenantID == "" {
+		level.Warn(logger).Log("msg", "dropping message without tenant")
+		c.metrics.messages.WithLabelValues(msg.Topic, "dropped").Inc()
+		return true
+	}
+	ctx = user.InjectOrgID(ctx, tenantID)
+
+	send, err := c.decode(msg.Value)
+	if err != nil {
+		level.Warn(logger).Log("msg", "dropping message which can't be decoded", "format", c.cfg.Format, "err", err)
+		c.metrics.messages.WithLabelValues(msg.Topic, "dropped").Inc()
+		return true
+	}
+
+	b := backoff.New(ctx, backoff.Config{MinBackoff: c.cfg.MinBackoff, MaxBackoff: c.cfg.MaxBackoff})
+	for {
+		err := send(ctx)
+		if err == nil {
+			c.metrics.messages.WithLabelValues(msg.Topic, "pushed").Inc()
+			return true
+		}
+		if !retryable(err) {
+			level.Warn(logger).Log("msg", "dropping message rejected by the distributor", "tenant", tenantID, "err", err)
+			c.metrics.messages.WithLabelValues(msg.Topic, "dropped").Inc()
+			return true
+		}
+		level.Warn(logger).Log("msg", "failed to push message, retrying", "tenant", tenantID, "err", err)
+		c.metrics.retries.WithLabelValues(msg.Topic).Inc()
+		b.Wait()
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+}
+
+// decode decodes a message according to the configured format, returning the
+// function pushing it.
+func (c *Consumer) decode(value []byte) (func(ctx context.Context) error, error) {
+	switch c.cfg.Format {
+	case FormatOTLPProtobuf, FormatOTLPJSON:
+		var req collogspb.ExportLogsServiceRequest
+		var err error
+		if c.cfg.Format == FormatOTLPProtobuf {
+			err = proto.Unmarshal(value, &req)
+		} else {
+			err = push.UnmarshalOTLPJSON(value, &req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			_, err := c.pusher.Export(ctx, &req)
+			return err
+		}, nil
+	default:
+		var req logproto.PushRequest
+		var err error
+		if c.cfg.Format == FormatLokiProtobuf {
+			err = req.Unmarshal(value)
+		} else {
+			err = unmarshal.DecodePushRequest(bytes.NewReader(value), &req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context) error {
+			_, err := c.pusher.Push(ctx, &req)
+			return err
+		}, nil
+	}
+}
+
+// retryable returns whether a push failing with err may succeed later. Requests
+// rejected by validation won't, unlike rate limited ones.
+func retryable(err error) bool {
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	if !ok {
+		return true
+	}
+	return resp.Code/100 != 4 || resp.Code == http.StatusTooManyRequests
+}
@@ -0,0 +1,250 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx context.Context
+
+	mtx    sync.Mutex
+	marked []int64
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return "logs" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return int64(len(c.messages)) }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+type pushed struct {
+	tenant string
+	req    *logproto.PushRequest
+	otlp   *collogspb.ExportLogsServiceRequest
+}
+
+type fakePusher struct {
+	mtx    sync.Mutex
+	pushed []pushed
+	// errs are returned by the next pushes.
+	errs []error
+}
+
+func (p *fakePusher) next(ctx context.Context, push pushed) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return err
+	}
+	push.tenant, _ = user.ExtractOrgID(ctx)
+	p.pushed = append(p.pushed, push)
+	return nil
+}
+
+func (p *fakePusher) Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error) {
+	return &logproto.PushResponse{}, p.next(ctx, pushed{req: req})
+}
+
+func (p *fakePusher) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	return &collogspb.ExportLogsServiceResponse{}, p.next(ctx, pushed{otlp: req})
+}
+
+func testConfig(format string) Config {
+	return Config{
+		Brokers:    []string{"localhost:9092"},
+		Topics:     []string{"logs"},
+		GroupID:    "loki",
+		Format:     format,
+		TenantID:   "default",
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+	}
+}
+
+func consume(ctx context.Context, t *testing.T, c *Consumer, msgs ...*sarama.ConsumerMessage) *fakeSession {
+	t.Helper()
+	claim := &fakeClaim{messages: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for i, msg := range msgs {
+		msg.Topic, msg.Offset = "logs", int64(i)
+		claim.messages <- msg
+	}
+	close(claim.messages)
+
+	session := &fakeSession{ctx: ctx}
+	require.NoError(t, c.ConsumeClaim(session, claim))
+	return session
+}
+
+func lokiMessage(t *testing.T, labels string, headers ...*sarama.RecordHeader) *sarama.ConsumerMessage {
+	req := logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  labels,
+		Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0).UTC(), Line: "line"}},
+	}}}
+	b, err := req.Marshal()
+	require.NoError(t, err)
+	return &sarama.ConsumerMessage{Value: b, Headers: headers}
+}
+
+func TestConsumer_ConsumeClaim(t *testing.T) {
+	pusher := &fakePusher{}
+	c := newConsumer(testConfig(FormatLokiProtobuf), pusher, nil, log.NewNopLogger(), prometheus.NewRegistry())
+
+	session := consume(context.Background(), t, c,
+		lokiMessage(t, `{app="foo"}`),
+		lokiMessage(t, `{app="bar"}`, &sarama.RecordHeader{Key: []byte(TenantHeader), Value: []byte("team-a")}),
+		&sarama.ConsumerMessage{Value: []byte("not protobuf")},
+	)
+
+	// Messages are committed in order, including the one which can't be decoded.
+	require.Equal(t, []int64{0, 1, 2}, session.marked)
+	require.Len(t, pusher.pushed, 2)
+	require.Equal(t, "default", pusher.pushed[0].tenant)
+	require.Equal(t, `{app="foo"}`, pusher.pushed[0].req.Streams[0].Labels)
+	require.Equal(t, "team-a", pusher.pushed[1].tenant)
+	require.Equal(t, `{app="bar"}`, pusher.pushed[1].req.Streams[0].Labels)
+}
+
+func TestConsumer_Retries(t *testing.T) {
+	pusher := &fakePusher{errs: []error{
+		errors.New("connection refused"),
+		httpgrpc.Errorf(http.StatusTooManyRequests, "rate limited"),
+		httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable"),
+	}}
+	c := newConsumer(testConfig(FormatLokiProtobuf), pusher, nil, log.NewNopLogger(), prometheus.NewRegistry())
+
+	session := consume(context.Background(), t, c, lokiMessage(t, `{app="foo"}`))
+
+	require.Equal(t, []int64{0}, session.marked)
+	require.Len(t, pusher.pushed, 1)
+}
+
+func TestConsumer_DropsRejectedMessages(t *testing.T) {
+	pusher := &fakePusher{errs: []error{httpgrpc.Errorf(http.StatusBadRequest, "entry too far behind")}}
+	cfg := testConfig(FormatLokiProtobuf)
+	cfg.TenantID = ""
+	c := newConsumer(cfg, pusher, nil, log.NewNopLogger(), prometheus.NewRegistry())
+
+	session := consume(context.Background(), t, c,
+		lokiMessage(t, `{app="foo"}`, &sarama.RecordHeader{Key: []byte(TenantHeader), Value: []byte("team-a")}),
+		// Dropped without any tenant.
+		lokiMessage(t, `{app="bar"}`),
+		lokiMessage(t, `{app="baz"}`, &sarama.RecordHeader{Key: []byte(TenantHeader), Value: []byte("team-a")}),
+	)
+
+	require.Equal(t, []int64{0, 1, 2}, session.marked)
+	require.Len(t, pusher.pushed, 1)
+	require.Equal(t, `{app="baz"}`, pusher.pushed[0].req.Streams[0].Labels)
+}
+
+func TestConsumer_DoesntCommitUnpushedMessages(t *testing.T) {
+	errs := make([]error, 1000)
+	for i := range errs {
+		errs[i] = httpgrpc.Errorf(http.StatusInternalServerError, "ingesters unavailable")
+	}
+	pusher := &fakePusher{errs: errs}
+	c := newConsumer(testConfig(FormatLokiProtobuf), pusher, nil, log.NewNopLogger(), prometheus.NewRegistry())
+
+	// The session ends, e.g. because of a rebalance, while the push is retried.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	session := consume(ctx, t, c, lokiMessage(t, `{app="foo"}`), lokiMessage(t, `{app="bar"}`))
+
+	require.Empty(t, session.marked)
+	require.Empty(t, pusher.pushed)
+}
+
+func TestConsumer_Formats(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		value  string
+		check  func(t *testing.T, p pushed)
+	}{
+		{
+			format: FormatLokiJSON,
+			value:  `{"streams": [{"stream": {"app": "foo"}, "values": [["1000000000", "line"]]}]}`,
+			check: func(t *testing.T, p pushed) {
+				require.Equal(t, `{app="foo"}`, p.req.Streams[0].Labels)
+				require.Equal(t, "line", p.req.Streams[0].Entries[0].Line)
+			},
+		},
+		{
+			format: FormatOTLPJSON,
+			value:  `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},"scopeLogs":[{"logRecords":[{"timeUnixNano":"1000000000","body":{"stringValue":"line"}}]}]}]}`,
+			check: func(t *testing.T, p pushed) {
+				require.Equal(t, "line", p.otlp.ResourceLogs[0].ScopeLogs[0].LogRecords[0].GetBody().GetStringValue())
+			},
+		},
+		{
+			format: FormatOTLPProtobuf,
+			value: func() string {
+				b, _ := proto.Marshal(&collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{ScopeLogs: []*logspb.ScopeLogs{{
+					LogRecords: []*logspb.LogRecord{{Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "line"}}}},
+				}}}}})
+				return string(b)
+			}(),
+			check: func(t *testing.T, p pushed) {
+				require.Equal(t, "line", p.otlp.ResourceLogs[0].ScopeLogs[0].LogRecords[0].GetBody().GetStringValue())
+			},
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			pusher := &fakePusher{}
+			c := newConsumer(testConfig(tc.format), pusher, nil, log.NewNopLogger(), prometheus.NewRegistry())
+
+			session := consume(context.Background(), t, c, &sarama.ConsumerMessage{Value: []byte(tc.value)})
+
+			require.Equal(t, []int64{0}, session.marked)
+			require.Len(t, pusher.pushed, 1)
+			tc.check(t, pusher.pushed[0])
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := testConfig(FormatLokiJSON)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Format = "avro"
+	require.Error(t, cfg.Validate())
+
+	cfg = testConfig(FormatLokiJSON)
+	cfg.Brokers = nil
+	require.Error(t, cfg.Validate())
+
+	cfg = testConfig(FormatLokiJSON)
+	cfg.Topics = nil
+	require.Error(t, cfg.Validate())
+}
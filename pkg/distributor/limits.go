@@ -16,13 +16,20 @@ type Limits interface {
 	MaxLabelNamesPerSeries(userID string) int
 	MaxLabelNameLength(userID string) int
 	MaxLabelValueLength(userID string) int
+	TruncateLabels(userID string) bool
 
 	CreationGracePeriod(userID string) time.Duration
 	RejectOldSamples(userID string) bool
 	RejectOldSamplesMaxAge(userID string) time.Duration
 
 	IncrementDuplicateTimestamps(userID string) bool
+	MaxStreamRateBytes(userID string) int
+	IngestionBurstMaxDelay(userID string) time.Duration
+	IngestionBurstMaxQueued(userID string) int
+	AllowNonIndexedLabels(userID string) bool
+	UnorderedWrites(userID string) bool
 
 	ShardStreams(userID string) *shardstreams.Config
+	IngestionTenantShardSize(userID string) int
 	AllByUserID() map[string]*validation.Limits
 }
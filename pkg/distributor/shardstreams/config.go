@@ -13,6 +13,9 @@ type Config struct {
 	// DesiredRate is the threshold used to shard the stream into smaller pieces.
 	// Expected to be in bytes.
 	DesiredRate flagext.ByteSize `yaml:"desired_rate" json:"desired_rate"`
+
+	// ShardRateLimitedStreams shards the streams which exceed max_stream_rate_bytes instead of rejecting their entries.
+	ShardRateLimitedStreams bool `yaml:"shard_rate_limited_streams" json:"shard_rate_limited_streams"`
 }
 
 func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
@@ -20,4 +23,5 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
 	fs.BoolVar(&cfg.LoggingEnabled, prefix+".logging-enabled", false, "Enable logging when sharding streams")
 	cfg.DesiredRate.Set("3mb") //nolint:errcheck
 	fs.Var(&cfg.DesiredRate, prefix+".desired-rate", "threshold used to cut a new shard. Default (3MB) means if a rate is above 3MB, it will be sharded.")
+	fs.BoolVar(&cfg.ShardRateLimitedStreams, prefix+".shard-rate-limited-streams", false, "Shard the streams which exceed max_stream_rate_bytes instead of rejecting their entries. Each shard is subject to the per-stream rate limit. Requires stream sharding to be enabled.")
 }
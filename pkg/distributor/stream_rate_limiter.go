@@ -0,0 +1,88 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// streamRateLimiterIdleTimeout is how long the limiter of a stream which
+	// received no push is kept before it is pruned.
+	streamRateLimiterIdleTimeout = 5 * time.Minute
+)
+
+type streamKey struct {
+	tenant string
+	hash   uint64
+}
+
+type streamLimiter struct {
+	lim      *rate.Limiter
+	lastSeen time.Time
+}
+
+// streamRateLimiter enforces the max_stream_rate_bytes limit of each stream.
+// Streams are identified by the hash of their labels before they are sharded,
+// so sharding a stream doesn't raise its limit.
+type streamRateLimiter struct {
+	limits Limits
+	// ring is used to evenly share the limit across distributors with the
+	// global ingestion rate strategy. It is nil with the local strategy.
+	ring ReadLifecycler
+
+	mtx       sync.Mutex
+	limiters  map[streamKey]*streamLimiter
+	nextPrune time.Time
+}
+
+func newStreamRateLimiter(limits Limits, ring ReadLifecycler) *streamRateLimiter {
+	return &streamRateLimiter{
+		limits:   limits,
+		ring:     ring,
+		limiters: map[streamKey]*streamLimiter{},
+	}
+}
+
+// AllowN reports whether n bytes of the stream may be pushed at time now.
+func (l *streamRateLimiter) AllowN(now time.Time, tenant string, hash uint64, n int) bool {
+	maxRate := l.limits.MaxStreamRateBytes(tenant)
+	if maxRate <= 0 {
+		return true
+	}
+	limit := rate.Limit(maxRate)
+	if l.ring != nil {
+		if distributors := l.ring.HealthyInstancesCount(); distributors > 0 {
+			limit /= rate.Limit(distributors)
+		}
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.pruneIdle(now)
+
+	key := streamKey{tenant: tenant, hash: hash}
+	sl, ok := l.limiters[key]
+	if !ok || sl.lim.Limit() != limit || sl.lim.Burst() != maxRate {
+		// The meaning of burst doesn't change for the global strategy, in order
+		// to keep it easier to understand for users / operators.
+		sl = &streamLimiter{lim: rate.NewLimiter(limit, maxRate)}
+		l.limiters[key] = sl
+	}
+	sl.lastSeen = now
+	return sl.lim.AllowN(now, n)
+}
+
+func (l *streamRateLimiter) pruneIdle(now time.Time) {
+	if now.Before(l.nextPrune) {
+		return
+	}
+	l.nextPrune = now.Add(streamRateLimiterIdleTimeout)
+	for key, sl := range l.limiters {
+		if now.Sub(sl.lastSeen) > streamRateLimiterIdleTimeout {
+			delete(l.limiters, key)
+		}
+	}
+}
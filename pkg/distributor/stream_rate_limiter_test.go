@@ -0,0 +1,60 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/validation"
+)
+
+type fakeReadLifecycler int
+
+func (f fakeReadLifecycler) HealthyInstancesCount() int { return int(f) }
+
+func TestStreamRateLimiter(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.MaxStreamRateBytes = 10
+	overrides, err := validation.NewOverrides(*limits, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	t.Run("local strategy", func(t *testing.T) {
+		l := newStreamRateLimiter(overrides, nil)
+		require.True(t, l.AllowN(now, "test", 1, 10))
+		require.False(t, l.AllowN(now, "test", 1, 1))
+		// other streams and tenants have their own limit.
+		require.True(t, l.AllowN(now, "test", 2, 10))
+		require.True(t, l.AllowN(now, "other", 1, 10))
+		require.True(t, l.AllowN(now.Add(time.Second), "test", 1, 10))
+	})
+
+	t.Run("global strategy shares the rate across distributors", func(t *testing.T) {
+		l := newStreamRateLimiter(overrides, fakeReadLifecycler(2))
+		require.True(t, l.AllowN(now, "test", 1, 10))
+		require.False(t, l.AllowN(now.Add(time.Second), "test", 1, 6))
+		require.True(t, l.AllowN(now.Add(time.Second), "test", 1, 5))
+	})
+
+	t.Run("idle streams are pruned", func(t *testing.T) {
+		l := newStreamRateLimiter(overrides, nil)
+		require.True(t, l.AllowN(now, "test", 1, 10))
+		require.True(t, l.AllowN(now.Add(streamRateLimiterIdleTimeout), "test", 2, 10))
+		require.Len(t, l.limiters, 2)
+		require.True(t, l.AllowN(now.Add(2*streamRateLimiterIdleTimeout+time.Second), "test", 2, 1))
+		require.Len(t, l.limiters, 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		limits.MaxStreamRateBytes = 0
+		overrides, err := validation.NewOverrides(*limits, nil)
+		require.NoError(t, err)
+		l := newStreamRateLimiter(overrides, nil)
+		require.True(t, l.AllowN(now, "test", 1, 1<<30))
+		require.Empty(t, l.limiters)
+	})
+}
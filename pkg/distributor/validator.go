@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/weaveworks/common/httpgrpc"
 
@@ -19,13 +22,16 @@ const (
 
 type Validator struct {
 	Limits
+
+	// discards reports the streams with the most discarded lines, nil when disabled.
+	discards *discardReporter
 }
 
 func NewValidator(l Limits) (*Validator, error) {
 	if l == nil {
 		return nil, errors.New("nil Limits")
 	}
-	return &Validator{l}, nil
+	return &Validator{Limits: l}, nil
 }
 
 type validationContext struct {
@@ -39,9 +45,12 @@ type validationContext struct {
 	maxLabelNamesPerSeries int
 	maxLabelNameLength     int
 	maxLabelValueLength    int
+	truncateLabels         bool
 
 	incrementDuplicateTimestamps bool
 
+	allowNonIndexedLabels bool
+
 	userID string
 }
 
@@ -56,7 +65,9 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		maxLabelNamesPerSeries:       v.MaxLabelNamesPerSeries(userID),
 		maxLabelNameLength:           v.MaxLabelNameLength(userID),
 		maxLabelValueLength:          v.MaxLabelValueLength(userID),
+		truncateLabels:               v.TruncateLabels(userID),
 		incrementDuplicateTimestamps: v.IncrementDuplicateTimestamps(userID),
+		allowNonIndexedLabels:        v.AllowNonIndexedLabels(userID) && v.UnorderedWrites(userID),
 	}
 }
 
@@ -70,14 +81,12 @@ func (v Validator) ValidateEntry(ctx validationContext, labels string, entry log
 	formatedRejectMaxAgeTime := time.Unix(0, ctx.rejectOldSampleMaxAge).Format(timeFormat)
 
 	if ctx.rejectOldSample && ts < ctx.rejectOldSampleMaxAge {
-		validation.DiscardedSamples.WithLabelValues(validation.GreaterThanMaxSampleAge, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.GreaterThanMaxSampleAge, ctx.userID).Add(float64(len(entry.Line)))
+		v.discard(validation.GreaterThanMaxSampleAge, ctx.userID, labels, 1, len(entry.Line))
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
 	}
 
 	if ts > ctx.creationGracePeriod {
-		validation.DiscardedSamples.WithLabelValues(validation.TooFarInFuture, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.TooFarInFuture, ctx.userID).Add(float64(len(entry.Line)))
+		v.discard(validation.TooFarInFuture, ctx.userID, labels, 1, len(entry.Line))
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
 	}
 
@@ -86,41 +95,48 @@ func (v Validator) ValidateEntry(ctx validationContext, labels string, entry log
 		// an orthogonal concept (we need not use ValidateLabels in this context)
 		// but the upstream cortex_validation pkg uses it, so we keep this
 		// for parity.
-		validation.DiscardedSamples.WithLabelValues(validation.LineTooLong, ctx.userID).Inc()
-		validation.DiscardedBytes.WithLabelValues(validation.LineTooLong, ctx.userID).Add(float64(len(entry.Line)))
+		v.discard(validation.LineTooLong, ctx.userID, labels, 1, len(entry.Line))
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
 	}
 
+	if len(entry.NonIndexedLabels) > 0 {
+		if !ctx.allowNonIndexedLabels {
+			v.discard(validation.DisallowedNonIndexedLabels, ctx.userID, labels, 1, len(entry.Line))
+			return httpgrpc.Errorf(http.StatusBadRequest, validation.DisallowedNonIndexedLabelsErrorMsg, labels)
+		}
+		for _, l := range entry.NonIndexedLabels {
+			if !model.LabelName(l.Name).IsValid() || len(l.Name) > ctx.maxLabelNameLength || len(l.Value) > ctx.maxLabelValueLength {
+				v.discard(validation.DisallowedNonIndexedLabels, ctx.userID, labels, 1, len(entry.Line))
+				return httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidNonIndexedLabelsErrorMsg, labels, l.Name)
+			}
+		}
+	}
+
 	return nil
 }
 
 // Validate labels returns an error if the labels are invalid
 func (v Validator) ValidateLabels(ctx validationContext, ls labels.Labels, stream logproto.Stream) error {
 	if len(ls) == 0 {
-		validation.DiscardedSamples.WithLabelValues(validation.MissingLabels, ctx.userID).Inc()
+		v.discardStream(validation.MissingLabels, ctx.userID, stream)
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.MissingLabelsErrorMsg)
 	}
 	numLabelNames := len(ls)
 	if numLabelNames > ctx.maxLabelNamesPerSeries {
-		validation.DiscardedSamples.WithLabelValues(validation.MaxLabelNamesPerSeries, ctx.userID).Inc()
-		bytes := 0
-		for _, e := range stream.Entries {
-			bytes += len(e.Line)
-		}
-		validation.DiscardedBytes.WithLabelValues(validation.MaxLabelNamesPerSeries, ctx.userID).Add(float64(bytes))
+		v.discardStream(validation.MaxLabelNamesPerSeries, ctx.userID, stream)
 		return httpgrpc.Errorf(http.StatusBadRequest, validation.MaxLabelNamesPerSeriesErrorMsg, stream.Labels, numLabelNames, ctx.maxLabelNamesPerSeries)
 	}
 
 	lastLabelName := ""
 	for _, l := range ls {
 		if len(l.Name) > ctx.maxLabelNameLength {
-			updateMetrics(validation.LabelNameTooLong, ctx.userID, stream)
+			v.discardStream(validation.LabelNameTooLong, ctx.userID, stream)
 			return httpgrpc.Errorf(http.StatusBadRequest, validation.LabelNameTooLongErrorMsg, stream.Labels, l.Name)
 		} else if len(l.Value) > ctx.maxLabelValueLength {
-			updateMetrics(validation.LabelValueTooLong, ctx.userID, stream)
+			v.discardStream(validation.LabelValueTooLong, ctx.userID, stream)
 			return httpgrpc.Errorf(http.StatusBadRequest, validation.LabelValueTooLongErrorMsg, stream.Labels, l.Value)
 		} else if cmp := strings.Compare(lastLabelName, l.Name); cmp == 0 {
-			updateMetrics(validation.DuplicateLabelNames, ctx.userID, stream)
+			v.discardStream(validation.DuplicateLabelNames, ctx.userID, stream)
 			return httpgrpc.Errorf(http.StatusBadRequest, validation.DuplicateLabelNamesErrorMsg, stream.Labels, l.Name)
 		}
 		lastLabelName = l.Name
@@ -128,11 +144,39 @@ func (v Validator) ValidateLabels(ctx validationContext, ls labels.Labels, strea
 	return nil
 }
 
-func updateMetrics(reason, userID string, stream logproto.Stream) {
-	validation.DiscardedSamples.WithLabelValues(reason, userID).Inc()
+// discardStream reports all the lines of the stream as discarded for the given reason.
+func (v Validator) discardStream(reason, userID string, stream logproto.Stream) {
 	bytes := 0
 	for _, e := range stream.Entries {
 		bytes += len(e.Line)
 	}
-	validation.DiscardedBytes.WithLabelValues(reason, userID).Add(float64(bytes))
+	v.discard(reason, userID, stream.Labels, len(stream.Entries), bytes)
+}
+
+// discard updates the discarded samples and bytes metrics, with the stream as exemplar so that
+// operators can find the offending streams, and records the stream in the discard report.
+func (v Validator) discard(reason, userID, stream string, lines, bytes int) {
+	exemplar := prometheus.Labels{"stream": exemplarStream(stream)}
+	addWithExemplar(validation.DiscardedSamples.WithLabelValues(reason, userID), float64(lines), exemplar)
+	addWithExemplar(validation.DiscardedBytes.WithLabelValues(reason, userID), float64(bytes), exemplar)
+	v.discards.record(userID, reason, stream, lines, bytes)
+}
+
+func addWithExemplar(c prometheus.Counter, v float64, exemplar prometheus.Labels) {
+	if adder, ok := c.(prometheus.ExemplarAdder); ok {
+		adder.AddWithExemplar(v, exemplar)
+		return
+	}
+	c.Add(v)
+}
+
+// exemplarStream makes the stream labels a valid exemplar label value, truncating them
+// to fit the maximum number of runes of the exemplar labels.
+func exemplarStream(stream string) string {
+	const maxRunes = prometheus.ExemplarMaxRunes - len("stream")
+	stream = strings.ToValidUTF8(stream, string(utf8.RuneError))
+	if utf8.RuneCountInString(stream) <= maxRunes {
+		return stream
+	}
+	return string([]rune(stream)[:maxRunes-1]) + "…"
 }
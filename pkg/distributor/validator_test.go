@@ -85,6 +85,54 @@ func TestValidator_ValidateEntry(t *testing.T) {
 			logproto.Entry{Timestamp: testTime, Line: "12345678901"},
 			httpgrpc.Errorf(http.StatusBadRequest, validation.LineTooLongErrorMsg, 10, testStreamLabels, 11),
 		},
+		{
+			"non-indexed labels disallowed",
+			"test",
+			nil,
+			logproto.Entry{Timestamp: testTime, Line: "test", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "abc"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.DisallowedNonIndexedLabelsErrorMsg, testStreamLabels),
+		},
+		{
+			"non-indexed labels allowed",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowNonIndexedLabels: true,
+					UnorderedWrites:       true,
+					MaxLabelNameLength:    1024,
+					MaxLabelValueLength:   2048,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "abc"}}},
+			nil,
+		},
+		{
+			"non-indexed labels without unordered writes",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowNonIndexedLabels: true,
+					MaxLabelNameLength:    1024,
+					MaxLabelValueLength:   2048,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "abc"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.DisallowedNonIndexedLabelsErrorMsg, testStreamLabels),
+		},
+		{
+			"invalid non-indexed label name",
+			"test",
+			fakeLimits{
+				&validation.Limits{
+					AllowNonIndexedLabels: true,
+					UnorderedWrites:       true,
+					MaxLabelNameLength:    1024,
+					MaxLabelValueLength:   2048,
+				},
+			},
+			logproto.Entry{Timestamp: testTime, Line: "test", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace-id", Value: "abc"}}},
+			httpgrpc.Errorf(http.StatusBadRequest, validation.InvalidNonIndexedLabelsErrorMsg, testStreamLabels, "trace-id"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,159 @@
+package ingester
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+
+	"github.com/grafana/loki/pkg/util"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// shutdownMarkerFile is created in the WAL directory by the prepare shutdown API, so that an ingester restarted
+// before its shutdown still flushes and leaves the ring when it's shut down.
+const shutdownMarkerFile = "shutdown_requested.txt"
+
+// PrepareShutdownHandler configures the ingester to flush its chunks and leave the ring on its next shutdown,
+// whatever its configuration, so that it can be scaled down by terminating it:
+//   - GET returns whether the ingester will flush and leave the ring on shutdown, as "set" or "unset".
+//   - POST prepares the shutdown.
+//   - DELETE reverts the ingester to its configuration.
+func (i *Ingester) PrepareShutdownHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if i.shutdownPrepared() {
+			util.WriteTextResponse(w, "set")
+		} else {
+			util.WriteTextResponse(w, "unset")
+		}
+	case http.MethodPost:
+		if err := i.setShutdownMarker(true); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to persist the shutdown marker", "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		i.prepareShutdown()
+		level.Info(util_log.Logger).Log("msg", "prepared the ingester to flush and leave the ring on shutdown")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if err := i.setShutdownMarker(false); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove the shutdown marker", "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		i.lifecycler.SetFlushOnShutdown(i.defaultFlushOnShutdown())
+		i.lifecycler.SetUnregisterOnShutdown(i.cfg.LifecyclerConfig.UnregisterOnShutdown)
+		level.Info(util_log.Logger).Log("msg", "reverted the shutdown of the ingester to its configuration")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// UnregisterHandler changes whether the ingester leaves the ring on shutdown, without changing whether it flushes:
+//   - GET returns {"unregister": <bool>}.
+//   - POST or PUT makes the ingester leave the ring on shutdown.
+//   - DELETE reverts the ingester to its configuration.
+func (i *Ingester) UnregisterHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		i.lifecycler.SetUnregisterOnShutdown(true)
+	case http.MethodDelete:
+		i.lifecycler.SetUnregisterOnShutdown(i.cfg.LifecyclerConfig.UnregisterOnShutdown)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	util.WriteJSONResponse(w, struct {
+		Unregister bool `json:"unregister"`
+	}{Unregister: i.lifecycler.ShouldUnregisterOnShutdown()})
+}
+
+// ForgetHandler removes the instance given by the `id` parameter from the ring, for instance an ingester whose
+// pod was deleted without leaving the ring. The ingester can't forget itself, which must be shut down instead.
+func (i *Ingester) ForgetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.FormValue("id")
+	if id == "" {
+		http.Error(w, "the id of the instance to forget is required", http.StatusBadRequest)
+		return
+	}
+	if id == i.lifecycler.ID {
+		http.Error(w, "an ingester can't forget itself, use /ingester/shutdown instead", http.StatusBadRequest)
+		return
+	}
+
+	found := false
+	err := i.lifecycler.KVStore.CAS(r.Context(), RingKey, func(in interface{}) (out interface{}, retry bool, err error) {
+		ringDesc, ok := in.(*ring.Desc)
+		if !ok || ringDesc == nil {
+			return nil, false, nil
+		}
+		if _, found = ringDesc.Ingesters[id]; !found {
+			return nil, false, nil
+		}
+		ringDesc.RemoveIngester(id)
+		return ringDesc, true, nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("instance %s not found in the ring", id), http.StatusNotFound)
+		return
+	}
+	level.Info(util_log.Logger).Log("msg", "forgot the instance from the ring", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (i *Ingester) defaultFlushOnShutdown() bool {
+	return !i.cfg.WAL.Enabled || i.cfg.WAL.FlushOnShutdown
+}
+
+func (i *Ingester) prepareShutdown() {
+	i.lifecycler.SetFlushOnShutdown(true)
+	i.lifecycler.SetUnregisterOnShutdown(true)
+}
+
+func (i *Ingester) shutdownPrepared() bool {
+	return i.lifecycler.FlushOnShutdown() && i.lifecycler.ShouldUnregisterOnShutdown()
+}
+
+// setShutdownMarker creates or removes the shutdown marker. The marker is only needed with the WAL, without which the
+// chunks are flushed on shutdown anyway.
+func (i *Ingester) setShutdownMarker(set bool) error {
+	if !i.cfg.WAL.Enabled {
+		return nil
+	}
+	path := filepath.Join(i.cfg.WAL.Dir, shutdownMarkerFile)
+	if !set {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(i.cfg.WAL.Dir, 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0o640)
+}
+
+// loadShutdownMarker prepares the shutdown if it was prepared before the ingester was restarted.
+func (i *Ingester) loadShutdownMarker() {
+	if !i.cfg.WAL.Enabled {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(i.cfg.WAL.Dir, shutdownMarkerFile)); err == nil {
+		level.Info(util_log.Logger).Log("msg", "found the shutdown marker, the ingester will flush and leave the ring on shutdown")
+		i.prepareShutdown()
+	}
+}
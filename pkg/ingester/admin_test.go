@@ -0,0 +1,126 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/ingester/client"
+	"github.com/grafana/loki/pkg/runtime"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func newAdminTestIngester(t *testing.T, cfg Config) *Ingester {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	store := &mockStore{chunks: map[string][]chunk.Chunk{}}
+
+	i, err := New(cfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	return i
+}
+
+func TestPrepareShutdownHandler(t *testing.T) {
+	walDir := t.TempDir()
+	cfg := defaultIngesterTestConfigWithWAL(t, walDir)
+	cfg.WAL.FlushOnShutdown = false
+	cfg.LifecyclerConfig.UnregisterOnShutdown = false
+	marker := filepath.Join(walDir, shutdownMarkerFile)
+
+	i := newAdminTestIngester(t, cfg)
+	call := func(method string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		i.PrepareShutdownHandler(w, httptest.NewRequest(method, "/ingester/prepare_shutdown", nil))
+		return w
+	}
+
+	require.Equal(t, "unset", call(http.MethodGet).Body.String())
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodPost).Code)
+	require.Equal(t, "set", call(http.MethodGet).Body.String())
+	require.FileExists(t, marker)
+
+	require.Equal(t, http.StatusNoContent, call(http.MethodDelete).Code)
+	require.Equal(t, "unset", call(http.MethodGet).Body.String())
+	require.NoFileExists(t, marker)
+
+	// The shutdown prepared before a restart still applies after it.
+	require.Equal(t, http.StatusNoContent, call(http.MethodPost).Code)
+	i.lifecycler.SetFlushOnShutdown(false)
+	i.lifecycler.SetUnregisterOnShutdown(false)
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), i))
+	require.FileExists(t, marker)
+
+	i = newAdminTestIngester(t, cfg)
+	require.Equal(t, "set", call(http.MethodGet).Body.String())
+
+	// The marker is removed once the ingester left the ring.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), i))
+	_, err := os.Stat(marker)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestUnregisterHandler(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.UnregisterOnShutdown = false
+	i := newAdminTestIngester(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	call := func(method string) bool {
+		w := httptest.NewRecorder()
+		i.UnregisterHandler(w, httptest.NewRequest(method, "/ingester/unregister", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		var res struct {
+			Unregister bool `json:"unregister"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res.Unregister
+	}
+
+	require.False(t, call(http.MethodGet))
+	require.True(t, call(http.MethodPut))
+	require.True(t, call(http.MethodGet))
+	require.False(t, call(http.MethodDelete))
+}
+
+func TestForgetHandler(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	i := newAdminTestIngester(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	kvStore := i.lifecycler.KVStore
+	require.NoError(t, kvStore.CAS(context.Background(), RingKey, func(in interface{}) (interface{}, bool, error) {
+		desc := in.(*ring.Desc)
+		desc.AddIngester("ingester-gone", "ingester-gone:9095", "", []uint32{42}, ring.ACTIVE, time.Now())
+		return desc, true, nil
+	}))
+
+	forget := func(id string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/ingester/ring/forget", strings.NewReader("id="+id))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		i.ForgetHandler(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, forget(i.lifecycler.ID))
+	require.Equal(t, http.StatusNotFound, forget("unknown"))
+	require.Equal(t, http.StatusNoContent, forget("ingester-gone"))
+
+	desc, err := kvStore.Get(context.Background(), RingKey)
+	require.NoError(t, err)
+	require.NotContains(t, desc.(*ring.Desc).Ingesters, "ingester-gone")
+	require.Contains(t, desc.(*ring.Desc).Ingesters, i.lifecycler.ID)
+}
@@ -129,6 +129,33 @@ func Test_Encoding_Entries(t *testing.T) {
 			},
 			version: WALRecordEntriesV2,
 		},
+		{
+			rec: &WALRecord{
+				entryIndexMap: make(map[uint64]int),
+				UserID:        "123",
+				RefEntries: []RefEntries{
+					{
+						Ref:     456,
+						Counter: 1,
+						Entries: []logproto.Entry{
+							{
+								Timestamp: time.Unix(1000, 0),
+								Line:      "first",
+								NonIndexedLabels: []logproto.LabelAdapter{
+									{Name: "trace_id", Value: "3c0e3dcd33e7"},
+									{Name: "user", Value: "bob"},
+								},
+							},
+							{
+								Timestamp: time.Unix(2000, 0),
+								Line:      "second",
+							},
+						},
+					},
+				},
+			},
+			version: WALRecordEntriesV3,
+		},
 	} {
 		decoded := recordPool.GetRecord()
 		buf := tc.rec.encodeEntries(tc.version, nil)
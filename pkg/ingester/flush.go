@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,6 +37,8 @@ const (
 	flushReasonForced = "forced"
 	flushReasonFull   = "full"
 	flushReasonSynced = "synced"
+
+	flushPriorityShift = 44
 )
 
 // Note: this is called both during the WAL replay (zero or more times)
@@ -73,19 +76,92 @@ func (i *Ingester) FlushHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// TenantFlushProgress is the number of chunks of a tenant which remain to be flushed.
+type TenantFlushProgress struct {
+	Tenant   string `json:"tenant"`
+	Priority int    `json:"priority"`
+	Chunks   int    `json:"chunks"`
+	Bytes    int    `json:"bytes"`
+}
+
+// FlushProgressHandler reports the chunks which remain to be flushed, by tenant in flush order,
+// so that operators can monitor the ingester while it is drained with /flush or /ingester/shutdown.
+func (i *Ingester) FlushProgressHandler(w http.ResponseWriter, _ *http.Request) {
+	tenants := i.flushProgress()
+
+	var chunks, bytes int
+	for _, t := range tenants {
+		chunks += t.Chunks
+		bytes += t.Bytes
+	}
+	queued := 0
+	for _, q := range i.flushQueues {
+		if q != nil {
+			queued += q.Length()
+		}
+	}
+
+	util.WriteJSONResponse(w, struct {
+		Chunks        int                   `json:"chunks"`
+		Bytes         int                   `json:"bytes"`
+		QueuedStreams int                   `json:"queued_streams"`
+		Tenants       []TenantFlushProgress `json:"tenants"`
+	}{
+		Chunks:        chunks,
+		Bytes:         bytes,
+		QueuedStreams: queued,
+		Tenants:       tenants,
+	})
+}
+
+func (i *Ingester) flushProgress() []TenantFlushProgress {
+	tenants := []TenantFlushProgress{}
+	for _, instance := range i.getInstances() {
+		progress := TenantFlushProgress{
+			Tenant:   instance.instanceID,
+			Priority: i.limiter.limits.FlushPriority(instance.instanceID),
+		}
+		_ = instance.streams.ForEach(func(s *stream) (bool, error) {
+			s.chunkMtx.RLock()
+			defer s.chunkMtx.RUnlock()
+			for _, c := range s.chunks {
+				if c.flushed.IsZero() {
+					progress.Chunks++
+					progress.Bytes += c.chunk.CompressedSize()
+				}
+			}
+			return true, nil
+		})
+		if progress.Chunks > 0 {
+			tenants = append(tenants, progress)
+		}
+	}
+	sort.Slice(tenants, func(a, b int) bool {
+		if tenants[a].Priority != tenants[b].Priority {
+			return tenants[a].Priority > tenants[b].Priority
+		}
+		return tenants[a].Tenant < tenants[b].Tenant
+	})
+	return tenants
+}
+
 type flushOp struct {
 	from      model.Time
 	userID    string
 	fp        model.Fingerprint
 	immediate bool
+	// priority is the flush priority of the tenant.
+	priority int
 }
 
 func (o *flushOp) Key() string {
 	return fmt.Sprintf("%s-%s-%v", o.userID, o.fp, o.immediate)
 }
 
+// Priority flushes the chunks of the tenants with the highest flush priority first, then the oldest chunks first.
+// Timestamps in milliseconds fit in flushPriorityShift bits until the year 2527.
 func (o *flushOp) Priority() int64 {
-	return -int64(o.from)
+	return int64(o.priority)<<flushPriorityShift - int64(o.from)
 }
 
 // sweepUsers periodically schedules series for flushing and garbage collects users with no series
@@ -98,14 +174,15 @@ func (i *Ingester) sweepUsers(immediate, mayRemoveStreams bool) {
 }
 
 func (i *Ingester) sweepInstance(instance *instance, immediate, mayRemoveStreams bool) {
+	priority := i.limiter.limits.FlushPriority(instance.instanceID)
 	_ = instance.streams.ForEach(func(s *stream) (bool, error) {
-		i.sweepStream(instance, s, immediate)
+		i.sweepStream(instance, s, immediate, priority)
 		i.removeFlushedChunks(instance, s, mayRemoveStreams)
 		return true, nil
 	})
 }
 
-func (i *Ingester) sweepStream(instance *instance, stream *stream, immediate bool) {
+func (i *Ingester) sweepStream(instance *instance, stream *stream, immediate bool, priority int) {
 	stream.chunkMtx.RLock()
 	defer stream.chunkMtx.RUnlock()
 	if len(stream.chunks) == 0 {
@@ -122,7 +199,7 @@ func (i *Ingester) sweepStream(instance *instance, stream *stream, immediate boo
 	firstTime, _ := stream.chunks[0].chunk.Bounds()
 	i.flushQueues[flushQueueIndex].Enqueue(&flushOp{
 		model.TimeFromUnixNano(firstTime.UnixNano()), instance.instanceID,
-		stream.fp, immediate,
+		stream.fp, immediate, priority,
 	})
 }
 
@@ -1,7 +1,10 @@
 package ingester
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"sort"
 	"sync"
@@ -33,6 +36,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk/fetcher"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -63,6 +67,57 @@ func TestChunkFlushingShutdown(t *testing.T) {
 	store.checkData(t, testData)
 }
 
+func TestFlushProgressHandler(t *testing.T) {
+	store, ing := newTestStore(t, defaultIngesterTestConfig(t), nil)
+	testData := pushTestSamples(t, ing)
+
+	progress := func() flushProgressResponse {
+		w := httptest.NewRecorder()
+		ing.FlushProgressHandler(w, httptest.NewRequest(http.MethodGet, "/ingester/flush_progress", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res flushProgressResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return res
+	}
+
+	res := progress()
+	require.Equal(t, 3*numSeries, res.Chunks)
+	require.Len(t, res.Tenants, 3)
+	for i, tenant := range res.Tenants {
+		require.Equal(t, fmt.Sprint(i+1), tenant.Tenant)
+		require.Equal(t, numSeries, tenant.Chunks)
+		require.Greater(t, tenant.Bytes, 0)
+	}
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	store.checkData(t, testData)
+
+	res = progress()
+	require.Zero(t, res.Chunks)
+	require.Empty(t, res.Tenants)
+}
+
+type flushProgressResponse struct {
+	Chunks  int                   `json:"chunks"`
+	Tenants []TenantFlushProgress `json:"tenants"`
+}
+
+func TestFlushOpPriority(t *testing.T) {
+	q := util.NewPriorityQueue(nil)
+	q.Enqueue(&flushOp{from: 1, userID: "old"})
+	q.Enqueue(&flushOp{from: 2, userID: "new"})
+	q.Enqueue(&flushOp{from: model.Now(), userID: "prioritized", priority: 1})
+	q.Enqueue(&flushOp{from: 0, userID: "deprioritized", priority: -validation.MaxFlushPriority})
+	q.Close()
+
+	var order []string
+	for o := q.Dequeue(); o != nil; o = q.Dequeue() {
+		order = append(order, o.(*flushOp).userID)
+	}
+	require.Equal(t, []string{"prioritized", "old", "new", "deprioritized"}, order)
+}
+
 type fullWAL struct{}
 
 func (fullWAL) Log(_ *WALRecord) error { return &os.PathError{Err: syscall.ENOSPC} }
@@ -350,6 +405,10 @@ func (s *testStore) Stats(ctx context.Context, userID string, from, through mode
 	return &stats.Stats{}, nil
 }
 
+func (s *testStore) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	return &logproto.VolumeResponse{}, nil
+}
+
 func pushTestSamples(t *testing.T, ing logproto.PusherServer) map[string][]logproto.Stream {
 	userIDs := []string{"1", "2", "3"}
 
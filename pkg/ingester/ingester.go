@@ -36,12 +36,14 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/chunk/fetcher"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	index_stats "github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	"github.com/grafana/loki/pkg/usagestats"
 	"github.com/grafana/loki/pkg/util"
 	errUtil "github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
-	"github.com/grafana/loki/pkg/util/wal"
+	"github.com/grafana/loki/pkg/util/paging"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -81,7 +83,9 @@ type Config struct {
 	TargetChunkSize     int               `yaml:"chunk_target_size"`
 	ChunkEncoding       string            `yaml:"chunk_encoding"`
 	parsedEncoding      chunkenc.Encoding `yaml:"-"` // placeholder for validated encoding
+	WriteChunkFormatV5  bool              `yaml:"write_chunk_format_v5"`
 	MaxChunkAge         time.Duration     `yaml:"max_chunk_age"`
+	OutOfOrderWindow    time.Duration     `yaml:"out_of_order_window"`
 	AutoForgetUnhealthy bool              `yaml:"autoforget_unhealthy"`
 
 	// Synchronization settings. Used to make sure that ingesters cut their chunks at the same moments.
@@ -105,6 +109,8 @@ type Config struct {
 	IndexShards int `yaml:"index_shards"`
 
 	MaxDroppedStreams int `yaml:"max_dropped_streams"`
+
+	DangerZoneAPIEnabled bool `yaml:"danger_zone_api_enabled"`
 }
 
 // RegisterFlags registers the flags.
@@ -113,7 +119,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.WAL.RegisterFlags(f)
 
 	f.IntVar(&cfg.MaxTransferRetries, "ingester.max-transfer-retries", 0, "Number of times to try and transfer chunks before falling back to flushing. If set to 0 or negative value, transfers are disabled.")
-	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "")
+	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "How many flushes can happen concurrently. Each flush worker has its own queue and the streams are spread over the queues by fingerprint, so the chunks of a stream are always flushed by the same worker.")
 	f.DurationVar(&cfg.FlushCheckPeriod, "ingester.flush-check-period", 30*time.Second, "")
 	f.DurationVar(&cfg.FlushOpTimeout, "ingester.flush-op-timeout", 10*time.Minute, "")
 	f.DurationVar(&cfg.RetainPeriod, "ingester.chunks-retain-period", 0, "")
@@ -121,14 +127,17 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.BlockSize, "ingester.chunks-block-size", 256*1024, "")
 	f.IntVar(&cfg.TargetChunkSize, "ingester.chunk-target-size", 1572864, "") // 1.5 MB
 	f.StringVar(&cfg.ChunkEncoding, "ingester.chunk-encoding", chunkenc.EncGZIP.String(), fmt.Sprintf("The algorithm to use for compressing chunk. (%s)", chunkenc.SupportedEncoding()))
+	f.BoolVar(&cfg.WriteChunkFormatV5, "ingester.write-chunk-format-v5", false, "Write the chunks of the streams accepting unordered writes in the format v5, which stores the non-indexed labels in a structured metadata section and a bloom filter of each block allowing queries with line filters to skip it. Every component reading chunks must support the format before it's enabled.")
 	f.DurationVar(&cfg.SyncPeriod, "ingester.sync-period", 0, "How often to cut chunks to synchronize ingesters.")
 	f.Float64Var(&cfg.SyncMinUtilization, "ingester.sync-min-utilization", 0, "Minimum utilization of chunk when doing synchronization.")
 	f.IntVar(&cfg.MaxReturnedErrors, "ingester.max-ignored-stream-errors", 10, "Maximum number of ignored stream errors to return. 0 to return all errors.")
 	f.DurationVar(&cfg.MaxChunkAge, "ingester.max-chunk-age", 2*time.Hour, "Maximum chunk age before flushing.")
+	f.DurationVar(&cfg.OutOfOrderWindow, "ingester.out-of-order-window", 0, "How far behind the most recent entry of a stream out-of-order entries are accepted when unordered writes are enabled. Entries within the window are buffered and reordered in the head block before being cut into compressed blocks. Must not exceed half of ingester.max-chunk-age. 0 means half of ingester.max-chunk-age.")
 	f.DurationVar(&cfg.QueryStoreMaxLookBackPeriod, "ingester.query-store-max-look-back-period", 0, "How far back should an ingester be allowed to query the store for data, for use only with boltdb-shipper/tsdb index and filesystem object store. -1 for infinite.")
 	f.BoolVar(&cfg.AutoForgetUnhealthy, "ingester.autoforget-unhealthy", false, "Enable to remove unhealthy ingesters from the ring after `ring.kvstore.heartbeat_timeout`")
 	f.IntVar(&cfg.IndexShards, "ingester.index-shards", index.DefaultIndexShards, "Shard factor used in the ingesters for the in process reverse index. This MUST be evenly divisible by ALL schema shard factors or Loki will not start.")
 	f.IntVar(&cfg.MaxDroppedStreams, "ingester.tailer.max-dropped-streams", 10, "Maximum number of dropped streams to keep in memory during tailing")
+	f.BoolVar(&cfg.DangerZoneAPIEnabled, "ingester.danger-zone-api-enabled", false, "Enable the /ingester/prepare_shutdown, /ingester/unregister and /ingester/ring/forget endpoints, which change how the ingester leaves the ring and remove instances from the ring.")
 }
 
 func (cfg *Config) Validate() error {
@@ -150,9 +159,23 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid ingester index shard factor: %d", cfg.IndexShards)
 	}
 
+	// a wider window would accept entries older than the chunks of the stream which are already cut and flushed
+	if cfg.OutOfOrderWindow < 0 || cfg.OutOfOrderWindow > cfg.MaxChunkAge/2 {
+		return fmt.Errorf("invalid ingester out-of-order window: %s, must be between 0 and half of the max chunk age (%s)", cfg.OutOfOrderWindow, cfg.MaxChunkAge/2)
+	}
+
 	return nil
 }
 
+// outOfOrderWindow returns how far behind the highest timestamp of a stream
+// entries are accepted with unordered writes.
+func (cfg *Config) outOfOrderWindow() time.Duration {
+	if cfg.OutOfOrderWindow > 0 {
+		return cfg.OutOfOrderWindow
+	}
+	return cfg.MaxChunkAge / 2
+}
+
 type Wrapper interface {
 	Wrap(wrapped Interface) Interface
 }
@@ -165,6 +188,15 @@ type ChunkStore interface {
 	GetChunkRefs(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([][]chunk.Chunk, []*fetcher.Fetcher, error)
 	GetSchemaConfigs() []config.PeriodConfig
 	Stats(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) (*index_stats.Stats, error)
+	Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error)
+}
+
+// TSDBHead is the head of the TSDB index the store writes for the flushed chunks, see tsdb.HeadManager.
+type TSDBHead interface {
+	HeadSeries(userID string, ls labels.Labels) (uint64, bool)
+	SeriesStats() []tsdb.TenantSeriesStats
+	LocalTSDBs() ([]tsdb.LocalTSDB, error)
+	Plan(ctx context.Context) ([]tsdb.BuildPlan, error)
 }
 
 // Interface is an interface for the Ingester
@@ -178,10 +210,17 @@ type Interface interface {
 
 	CheckReady(ctx context.Context) error
 	FlushHandler(w http.ResponseWriter, _ *http.Request)
+	FlushProgressHandler(w http.ResponseWriter, _ *http.Request)
 	GetOrCreateInstance(instanceID string) (*instance, error)
 	// deprecated
 	LegacyShutdownHandler(w http.ResponseWriter, r *http.Request)
 	ShutdownHandler(w http.ResponseWriter, r *http.Request)
+	SeriesStatsHandler(w http.ResponseWriter, _ *http.Request)
+	LocalTSDBsHandler(w http.ResponseWriter, _ *http.Request)
+	TSDBBuildPlanHandler(w http.ResponseWriter, r *http.Request)
+	PrepareShutdownHandler(w http.ResponseWriter, r *http.Request)
+	UnregisterHandler(w http.ResponseWriter, r *http.Request)
+	ForgetHandler(w http.ResponseWriter, r *http.Request)
 }
 
 // Ingester builds chunks for incoming log streams.
@@ -232,6 +271,9 @@ type Ingester struct {
 	chunkFilter chunk.RequestChunkFilterer
 
 	streamRateCalculator *StreamRateCalculator
+
+	// nil if the store doesn't write a TSDB index
+	tsdbHead TSDBHead
 }
 
 // New makes a new Ingester.
@@ -264,6 +306,12 @@ func New(cfg Config, clientConfig client.Config, store ChunkStore, limits *valid
 	}
 	i.replayController = newReplayController(metrics, cfg.WAL, &replayFlusher{i})
 
+	if s, ok := store.(interface{ TSDBHeadManager() *tsdb.HeadManager }); ok {
+		if hm := s.TSDBHeadManager(); hm != nil {
+			i.tsdbHead = hm
+		}
+	}
+
 	if cfg.WAL.Enabled {
 		if err := os.MkdirAll(cfg.WAL.Dir, os.ModePerm); err != nil {
 			// Best effort try to make path absolute for easier debugging.
@@ -415,7 +463,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 		defer endReplay()
 
 		level.Info(util_log.Logger).Log("msg", "recovering from checkpoint")
-		checkpointReader, checkpointCloser, err := newCheckpointReader(i.cfg.WAL.Dir)
+		checkpointReader, checkpointCloser, checkpointIdx, err := newCheckpointReader(i.cfg.WAL.Dir)
 		if err != nil {
 			return err
 		}
@@ -437,7 +485,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 		)
 
 		level.Info(util_log.Logger).Log("msg", "recovering from WAL")
-		segmentReader, segmentCloser, err := wal.NewWalReader(i.cfg.WAL.Dir, -1)
+		segmentReader, segmentCloser, err := newSegmentsReader(i.cfg.WAL.Dir, checkpointIdx)
 		if err != nil {
 			return err
 		}
@@ -464,6 +512,7 @@ func (i *Ingester) starting(ctx context.Context) error {
 	}
 
 	i.InitFlushQueues()
+	i.loadShutdownMarker()
 
 	// pass new context to lifecycler, so that it doesn't stop automatically when Ingester's service context is done
 	err := i.lifecycler.StartAsync(context.Background())
@@ -513,7 +562,12 @@ func (i *Ingester) stopping(_ error) error {
 	if i.flushOnShutdownSwitch.Get() {
 		i.lifecycler.SetFlushOnShutdown(true)
 	}
+	shutdownPrepared := i.shutdownPrepared()
 	errs.Add(services.StopAndAwaitTerminated(context.Background(), i.lifecycler))
+	if shutdownPrepared && errs.Err() == nil {
+		// The ingester left the ring, so the marker must not apply to its next run.
+		errs.Add(i.setShutdownMarker(false))
+	}
 
 	// Normally, flushers are stopped via lifecycler (in transferOut), but if lifecycler fails,
 	// we better stop them.
@@ -594,6 +648,65 @@ func (i *Ingester) ShutdownHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SeriesStatsHandler returns the active series of each tenant in the TSDB index heads of the ingester,
+// together with their series limit.
+func (i *Ingester) SeriesStatsHandler(w http.ResponseWriter, _ *http.Request) {
+	if i.tsdbHead == nil {
+		http.Error(w, "the ingester doesn't write a TSDB index", http.StatusNotFound)
+		return
+	}
+	stats := i.tsdbHead.SeriesStats()
+	if stats == nil {
+		stats = []tsdb.TenantSeriesStats{}
+	}
+	for j := range stats {
+		stats[j].Limit = i.limiter.MaxHeadSeriesPerUser(stats[j].Tenant)
+	}
+	util.WriteJSONResponse(w, struct {
+		Tenants []tsdb.TenantSeriesStats `json:"tenants"`
+	}{Tenants: stats})
+}
+
+// LocalTSDBsHandler returns the TSDB indices built by the ingester which are still held locally, on disk or in memory,
+// along with the number of series and chunks of each tenant present in them.
+func (i *Ingester) LocalTSDBsHandler(w http.ResponseWriter, _ *http.Request) {
+	if i.tsdbHead == nil {
+		http.Error(w, "the ingester doesn't write a TSDB index", http.StatusNotFound)
+		return
+	}
+	tsdbs, err := i.tsdbHead.LocalTSDBs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if tsdbs == nil {
+		tsdbs = []tsdb.LocalTSDB{}
+	}
+	util.WriteJSONResponse(w, struct {
+		TSDBs []tsdb.LocalTSDB `json:"tsdbs"`
+	}{TSDBs: tsdbs})
+}
+
+// TSDBBuildPlanHandler returns the TSDB indices which building the WALs of the TSDB index heads would produce,
+// without building them.
+func (i *Ingester) TSDBBuildPlanHandler(w http.ResponseWriter, r *http.Request) {
+	if i.tsdbHead == nil {
+		http.Error(w, "the ingester doesn't write a TSDB index", http.StatusNotFound)
+		return
+	}
+	plan, err := i.tsdbHead.Plan(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if plan == nil {
+		plan = []tsdb.BuildPlan{}
+	}
+	util.WriteJSONResponse(w, struct {
+		TSDBs []tsdb.BuildPlan `json:"tsdbs"`
+	}{TSDBs: plan})
+}
+
 // handleShutdown triggers the following operations:
 //   - Change the state of ring to stop accepting writes.
 //   - optional: Flush all the chunks.
@@ -642,7 +755,7 @@ func (i *Ingester) GetOrCreateInstance(instanceID string) (*instance, error) { /
 	inst, ok = i.instances[instanceID]
 	if !ok {
 		var err error
-		inst, err = newInstance(&i.cfg, i.periodicConfigs, instanceID, i.limiter, i.tenantConfigs, i.wal, i.metrics, i.flushOnShutdownSwitch, i.chunkFilter, i.streamRateCalculator)
+		inst, err = newInstance(&i.cfg, i.periodicConfigs, instanceID, i.limiter, i.tenantConfigs, i.wal, i.metrics, i.flushOnShutdownSwitch, i.chunkFilter, i.streamRateCalculator, i.tsdbHead)
 		if err != nil {
 			return nil, err
 		}
@@ -808,34 +921,123 @@ func (i *Ingester) GetChunkIDs(ctx context.Context, req *logproto.GetChunkIDsReq
 
 // Label returns the set of labels for the stream this ingester knows about.
 func (i *Ingester) Label(ctx context.Context, req *logproto.LabelRequest) (*logproto.LabelResponse, error) {
-	userID, err := tenant.TenantID(ctx)
+	resp := &logproto.LabelResponse{}
+	err := i.sendLabels(ctx, req, func(batch *logproto.LabelResponse) error {
+		resp.Values = append(resp.Values, batch.Values...)
+		resp.NextPageToken = batch.NextPageToken
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+// StreamLabel is like Label, sending the values in batches.
+func (i *Ingester) StreamLabel(req *logproto.LabelRequest, srv logproto.Querier_StreamLabelServer) error {
+	return i.sendLabels(srv.Context(), req, srv.Send)
+}
+
+// sendLabels calls send with the unique label names or values of the request, in batches of at most
+// paging.BatchSize values. The last batch, which is sent even if empty, carries the token of the next page.
+// The values are sent as they are iterated, except when paged: only the values of the page are kept then,
+// since the page needs to be sorted.
+func (i *Ingester) sendLabels(ctx context.Context, req *logproto.LabelRequest, send func(*logproto.LabelResponse) error) error {
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
+	ctx = paging.InjectPage(ctx, page)
+
+	if page.Enabled() {
+		collector, err := paging.NewCollector(page)
+		if err != nil {
+			return err
+		}
+		err = i.forEachLabel(ctx, req, func(value string) error {
+			collector.Add(value, value)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		values, next := collector.Values()
+		return paging.Batches(len(values), func(start, end int, last bool) error {
+			batch := &logproto.LabelResponse{Values: make([]string, 0, end-start)}
+			for _, value := range values[start:end] {
+				batch.Values = append(batch.Values, value.(string))
+			}
+			if last {
+				batch.NextPageToken = next
+			}
+			return send(batch)
+		})
+	}
+
+	seen := map[string]struct{}{}
+	batch := &logproto.LabelResponse{}
+	err := i.forEachLabel(ctx, req, func(value string) error {
+		if _, ok := seen[value]; ok {
+			return nil
+		}
+		seen[value] = struct{}{}
+
+		batch.Values = append(batch.Values, value)
+		if len(batch.Values) < paging.BatchSize {
+			return nil
+		}
+		err := send(batch)
+		batch = &logproto.LabelResponse{}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return send(batch)
+}
+
+// forEachLabel calls fn with the label names or values of the request, from the streams of the instance then
+// from the store. The same value may be passed several times.
+func (i *Ingester) forEachLabel(ctx context.Context, req *logproto.LabelRequest, fn func(value string) error) error {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return err
+	}
 
 	instance, err := i.GetOrCreateInstance(userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	resp, err := instance.Label(ctx, req)
+	if err := instance.forEachLabel(ctx, req, nil, fn); err != nil {
+		return err
+	}
+
+	storeValues, err := i.storeLabels(ctx, userID, req)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	for _, value := range storeValues {
+		if err := fn(value); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// storeLabels returns the label names or values of the request from the store, when the ingester queries it.
+func (i *Ingester) storeLabels(ctx context.Context, userID string, req *logproto.LabelRequest) ([]string, error) {
 	if req.Start == nil {
-		return resp, nil
+		return nil, nil
 	}
 
 	// Only continue if the active index type is one of async index store types or QueryStore flag is true.
 	asyncStoreMaxLookBack := i.asyncStoreMaxLookBack()
 	if asyncStoreMaxLookBack == 0 && !i.cfg.QueryStore {
-		return resp, nil
+		return nil, nil
 	}
 
 	var cs storage.Store
 	var ok bool
 	if cs, ok = i.store.(storage.Store); !ok {
-		return resp, nil
+		return nil, nil
 	}
 
 	maxLookBackPeriod := i.cfg.QueryStoreMaxLookBackPeriod
@@ -846,39 +1048,87 @@ func (i *Ingester) Label(ctx context.Context, req *logproto.LabelRequest) (*logp
 	start := adjustQueryStartTime(maxLookBackPeriod, *req.Start, time.Now())
 	if start.After(*req.End) {
 		// The request is older than we are allowed to query the store, just return what we have.
-		return resp, nil
+		return nil, nil
 	}
 	from, through := model.TimeFromUnixNano(start.UnixNano()), model.TimeFromUnixNano(req.End.UnixNano())
-	var storeValues []string
 	if req.Values {
-		storeValues, err = cs.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		storeValues, err = cs.LabelNamesForMetricName(ctx, userID, from, through, "logs")
-		if err != nil {
-			return nil, err
-		}
+		return cs.LabelValuesForMetricName(ctx, userID, from, through, "logs", req.Name)
 	}
-
-	return &logproto.LabelResponse{
-		Values: errUtil.MergeStringLists(resp.Values, storeValues),
-	}, nil
+	return cs.LabelNamesForMetricName(ctx, userID, from, through, "logs")
 }
 
 // Series queries the ingester for log stream identifiers (label sets) matching a set of matchers
 func (i *Ingester) Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
-	instanceID, err := tenant.TenantID(ctx)
+	resp := &logproto.SeriesResponse{}
+	err := i.sendSeries(ctx, req, func(batch *logproto.SeriesResponse) error {
+		resp.Series = append(resp.Series, batch.Series...)
+		resp.NextPageToken = batch.NextPageToken
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
+
+// StreamSeries is like Series, sending the series in batches.
+func (i *Ingester) StreamSeries(req *logproto.SeriesRequest, srv logproto.Querier_StreamSeriesServer) error {
+	return i.sendSeries(srv.Context(), req, srv.Send)
+}
+
+// sendSeries is like sendLabels, for the series of the request.
+func (i *Ingester) sendSeries(ctx context.Context, req *logproto.SeriesRequest, send func(*logproto.SeriesResponse) error) error {
+	instanceID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return err
+	}
 
 	instance, err := i.GetOrCreateInstance(instanceID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
+	if page.Enabled() {
+		collector, err := paging.NewCollector(page)
+		if err != nil {
+			return err
+		}
+		err = instance.forEachSeries(ctx, req, func(s *stream) error {
+			collector.Add(paging.SeriesKey(s.labels), logproto.SeriesIdentifier{Labels: s.labels.Map()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		series, next := collector.Values()
+		return paging.Batches(len(series), func(start, end int, last bool) error {
+			batch := &logproto.SeriesResponse{Series: make([]logproto.SeriesIdentifier, 0, end-start)}
+			for _, s := range series[start:end] {
+				batch.Series = append(batch.Series, s.(logproto.SeriesIdentifier))
+			}
+			if last {
+				batch.NextPageToken = next
+			}
+			return send(batch)
+		})
+	}
+
+	batch := &logproto.SeriesResponse{}
+	err = instance.forEachSeries(ctx, req, func(s *stream) error {
+		batch.Series = append(batch.Series, logproto.SeriesIdentifier{Labels: s.labels.Map()})
+		if len(batch.Series) < paging.BatchSize {
+			return nil
+		}
+		err := send(batch)
+		batch = &logproto.SeriesResponse{}
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	return instance.Series(ctx, req)
+	return send(batch)
 }
 
 func (i *Ingester) GetStats(ctx context.Context, req *logproto.IndexStatsRequest) (*logproto.IndexStatsResponse, error) {
@@ -925,6 +1175,49 @@ func (i *Ingester) GetStats(ctx context.Context, req *logproto.IndexStatsRequest
 	return &merged, nil
 }
 
+func (i *Ingester) GetVolume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	user, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := i.GetOrCreateInstance(user)
+	if err != nil {
+		return nil, err
+	}
+
+	matchers, err := syntax.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	type f func() (*logproto.VolumeResponse, error)
+	jobs := []f{
+		f(func() (*logproto.VolumeResponse, error) {
+			return instance.GetVolume(ctx, req)
+		}),
+		f(func() (*logproto.VolumeResponse, error) {
+			return i.store.Volume(ctx, user, req.From, req.Through, req.Limit, req.TargetLabels, req.AggregateBy, matchers...)
+		}),
+	}
+	resps := make([]*logproto.VolumeResponse, len(jobs))
+
+	if err := concurrency.ForEachJob(
+		ctx,
+		len(jobs),
+		2,
+		func(ctx context.Context, idx int) error {
+			res, err := jobs[idx]()
+			resps[idx] = res
+			return err
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return seriesvolume.Merge(req.Limit, resps...), nil
+}
+
 // Watch implements grpc_health_v1.HealthCheck.
 func (*Ingester) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health_WatchServer) error {
 	return nil
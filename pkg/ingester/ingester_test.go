@@ -2,6 +2,7 @@ package ingester
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
@@ -37,6 +38,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk/fetcher"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/util/paging"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -323,6 +325,10 @@ func (s *mockStore) Stats(ctx context.Context, userID string, from, through mode
 	return &stats.Stats{}, nil
 }
 
+func (s *mockStore) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	return &logproto.VolumeResponse{}, nil
+}
+
 func (s *mockStore) Stop() {}
 
 type mockQuerierServer struct {
@@ -542,6 +548,39 @@ func TestValidate(t *testing.T) {
 			},
 			err: true,
 		},
+		{
+			in: Config{
+				MaxChunkAge:      time.Minute,
+				OutOfOrderWindow: time.Hour,
+				ChunkEncoding:    chunkenc.EncGZIP.String(),
+				IndexShards:      index.DefaultIndexShards,
+			},
+			err: true,
+		},
+		{
+			in: Config{
+				MaxChunkAge:      time.Hour,
+				OutOfOrderWindow: 31 * time.Minute,
+				ChunkEncoding:    chunkenc.EncGZIP.String(),
+				IndexShards:      index.DefaultIndexShards,
+			},
+			err: true,
+		},
+		{
+			in: Config{
+				MaxChunkAge:      time.Hour,
+				OutOfOrderWindow: 30 * time.Minute,
+				ChunkEncoding:    chunkenc.EncGZIP.String(),
+				IndexShards:      index.DefaultIndexShards,
+			},
+			expected: Config{
+				MaxChunkAge:      time.Hour,
+				OutOfOrderWindow: 30 * time.Minute,
+				ChunkEncoding:    chunkenc.EncGZIP.String(),
+				parsedEncoding:   chunkenc.EncGZIP,
+				IndexShards:      index.DefaultIndexShards,
+			},
+		},
 	} {
 		t.Run(fmt.Sprint(i), func(t *testing.T) {
 			err := tc.in.Validate()
@@ -608,6 +647,65 @@ func Test_InMemoryLabels(t *testing.T) {
 	require.Equal(t, []string{"bar", "foo"}, res.Values)
 }
 
+func Test_StreamPagedLabelsAndSeries(t *testing.T) {
+	ctx, _ := user.InjectIntoGRPCRequest(user.InjectOrgID(context.Background(), "foo"))
+	ing, closer := createIngesterServer(t, defaultIngesterTestConfig(t))
+	defer closer()
+
+	req := logproto.PushRequest{}
+	for i := 0; i < 5; i++ {
+		req.Streams = append(req.Streams, logproto.Stream{
+			Labels:  fmt.Sprintf(`{foo="bar",bar="baz%d"}`, i),
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 0), Line: "line"}},
+		})
+	}
+	_, err := ing.Push(ctx, &req)
+	require.NoError(t, err)
+
+	start := time.Unix(0, 0)
+	var values []string
+	var pages int
+	labelReq := &logproto.LabelRequest{Start: &start, Name: "bar", Values: true, Limit: 2}
+	for {
+		stream, err := ing.StreamLabel(ctx, labelReq)
+		require.NoError(t, err)
+		var next string
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			values = append(values, resp.Values...)
+			next = resp.NextPageToken
+		}
+		pages++
+		if next == "" {
+			break
+		}
+		labelReq.PageToken = next
+	}
+	require.Equal(t, []string{"baz0", "baz1", "baz2", "baz3", "baz4"}, values)
+	require.Equal(t, 3, pages)
+
+	stream, err := ing.StreamSeries(ctx, &logproto.SeriesRequest{
+		Start:     time.Unix(0, 0),
+		End:       time.Unix(1, 0),
+		Limit:     2,
+		PageToken: paging.NextToken(`{bar="baz2", foo="bar"}`),
+	})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, []logproto.SeriesIdentifier{
+		{Labels: map[string]string{"foo": "bar", "bar": "baz3"}},
+		{Labels: map[string]string{"foo": "bar", "bar": "baz4"}},
+	}, resp.Series)
+	require.Equal(t, paging.NextToken(`{bar="baz4", foo="bar"}`), resp.NextPageToken)
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+}
+
 func Test_DedupeIngester(t *testing.T) {
 	var (
 		requests      = int64(400)
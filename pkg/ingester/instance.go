@@ -19,6 +19,7 @@ import (
 	"github.com/weaveworks/common/httpgrpc"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/ingester/index"
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
@@ -29,6 +30,7 @@ import (
 	"github.com/grafana/loki/pkg/runtime"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/pkg/usagestats"
 	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/util/deletion"
@@ -42,6 +44,9 @@ const (
 	// Possible values are only increasing integers starting from 0.
 	ShardLbName        = "__stream_shard__"
 	ShardLbPlaceholder = "__placeholder__"
+	// RateLimitedShardPrefix prefixes the values of ShardLbName of the shards of the streams over their rate
+	// limit, which the queriers merge back into the original streams.
+	RateLimitedShardPrefix = "rate_limited_"
 
 	queryBatchSize       = 128
 	queryBatchSampleSize = 512
@@ -97,6 +102,9 @@ type instance struct {
 
 	chunkFilter          chunk.RequestChunkFilterer
 	streamRateCalculator *StreamRateCalculator
+
+	// enforces the series limit of the tenant, nil if the store doesn't write a TSDB index
+	tsdbHead TSDBHead
 }
 
 func newInstance(
@@ -110,6 +118,7 @@ func newInstance(
 	flushOnShutdownSwitch *OnceSwitch,
 	chunkFilter chunk.RequestChunkFilterer,
 	streamRateCalculator *StreamRateCalculator,
+	tsdbHead TSDBHead,
 ) (*instance, error) {
 	invertedIndex, err := index.NewMultiInvertedIndex(periodConfigs, uint32(cfg.IndexShards))
 	if err != nil {
@@ -136,6 +145,8 @@ func newInstance(
 		chunkFilter: chunkFilter,
 
 		streamRateCalculator: streamRateCalculator,
+
+		tsdbHead: tsdbHead,
 	}
 	i.mapper = newFPMapper(i.getLabelsFromFingerprint)
 	return i, err
@@ -262,10 +273,36 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *WALRecord
 		}
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
+
+	// The series of the stream is written to the TSDB index head when its chunks are flushed, so the series limit is
+	// enforced here rather than failing the flush. Streams whose series is already in the head don't add a series.
+	if record != nil && i.tsdbHead != nil {
+		if series, ok := i.tsdbHead.HeadSeries(i.instanceID, labels); !ok {
+			if err := i.limiter.AssertMaxHeadSeriesPerUser(i.instanceID, int(series)); err != nil {
+				if i.configs.LogStreamCreation(i.instanceID) {
+					level.Debug(util_log.Logger).Log(
+						"msg", "failed to create stream, exceeded series limit",
+						"org_id", i.instanceID,
+						"err", err,
+						"stream", pushReqStream.Labels,
+					)
+				}
+
+				validation.DiscardedSamples.WithLabelValues(validation.SeriesLimit, i.instanceID).Add(float64(len(pushReqStream.Entries)))
+				bytes := 0
+				for _, e := range pushReqStream.Entries {
+					bytes += len(e.Line)
+				}
+				validation.DiscardedBytes.WithLabelValues(validation.SeriesLimit, i.instanceID).Add(float64(bytes))
+				return nil, httpgrpc.Errorf(http.StatusTooManyRequests, validation.SeriesLimitErrorMsg)
+			}
+		}
+	}
+
 	fp := i.getHashForLabels(labels)
 
 	sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(labels), fp)
-	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics)
+	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.chunkEncoding(), i.streamRateCalculator, i.metrics)
 
 	// record will be nil when replaying the wal (we don't want to rewrite wal entries as we replay them).
 	if record != nil {
@@ -294,9 +331,21 @@ func (i *instance) createStream(pushReqStream logproto.Stream, record *WALRecord
 	return s, nil
 }
 
+// chunkEncoding returns the encoding of new chunks of the tenant, which is the
+// ingester's one unless overridden in the tenant limits.
+func (i *instance) chunkEncoding() chunkenc.Encoding {
+	if name := i.limiter.ChunkEncoding(i.instanceID); name != "" {
+		// The encoding has been validated when loading the limits.
+		if enc, err := chunkenc.ParseEncoding(name); err == nil {
+			return enc
+		}
+	}
+	return i.cfg.parsedEncoding
+}
+
 func (i *instance) createStreamByFP(ls labels.Labels, fp model.Fingerprint) *stream {
 	sortedLabels := i.index.Add(logproto.FromLabelsToLabelAdapters(ls), fp)
-	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.streamRateCalculator, i.metrics)
+	s := newStream(i.cfg, i.limiter, i.instanceID, fp, sortedLabels, i.limiter.UnorderedWrites(i.instanceID), i.chunkEncoding(), i.streamRateCalculator, i.metrics)
 
 	i.streamsCreatedTotal.Inc()
 	memoryStreams.WithLabelValues(i.instanceID).Inc()
@@ -359,6 +408,8 @@ func (i *instance) Query(ctx context.Context, req logql.SelectLogParams) (iter.E
 
 	stats := stats.FromContext(ctx)
 	var iters []iter.EntryIterator
+	// the blocks of chunks which have a bloom filter are skipped if none of their lines can match.
+	ctx = chunkenc.WithRequiredLineFilters(ctx, syntax.RequiredLineFilters(expr))
 
 	shard, err := parseShardFromRequest(req.Shards)
 	if err != nil {
@@ -404,6 +455,7 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 
 	stats := stats.FromContext(ctx)
 	var iters []iter.SampleIterator
+	ctx = chunkenc.WithRequiredLineFilters(ctx, syntax.RequiredLineFilters(expr.Selector()))
 
 	var shard *astmapper.ShardAnnotation
 	shards, err := logql.ParseShards(req.Shards)
@@ -443,108 +495,117 @@ func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams
 // If label matchers are given only the matching streams are fetched from the index.
 // The label names or values are then retrieved from those matching streams.
 func (i *instance) Label(ctx context.Context, req *logproto.LabelRequest, matchers ...*labels.Matcher) (*logproto.LabelResponse, error) {
+	labels := make([]string, 0)
+	err := i.forEachLabel(ctx, req, matchers, func(label string) error {
+		labels = append(labels, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &logproto.LabelResponse{
+		Values: labels,
+	}, nil
+}
+
+// forEachLabel calls fn with each label name, or each value of the label when req.Values is set, of the streams
+// matching matchers. Without matchers, the labels come from the index and are unique, otherwise fn is called with the
+// labels of each stream.
+func (i *instance) forEachLabel(ctx context.Context, req *logproto.LabelRequest, matchers []*labels.Matcher, fn func(label string) error) error {
 	if len(matchers) == 0 {
 		var labels []string
+		var err error
 		if req.Values {
-			values, err := i.index.LabelValues(*req.Start, req.Name, nil)
-			if err != nil {
-				return nil, err
-			}
-			labels = make([]string, len(values))
-			copy(labels, values)
-			return &logproto.LabelResponse{
-				Values: labels,
-			}, nil
+			labels, err = i.index.LabelValues(*req.Start, req.Name, nil)
+		} else {
+			labels, err = i.index.LabelNames(*req.Start, nil)
 		}
-		names, err := i.index.LabelNames(*req.Start, nil)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		for _, label := range labels {
+			if err := fn(label); err != nil {
+				return err
+			}
 		}
-		labels = make([]string, len(names))
-		copy(labels, names)
-		return &logproto.LabelResponse{
-			Values: labels,
-		}, nil
+		return nil
 	}
 
-	labels := make([]string, 0)
-	err := i.forMatchingStreams(ctx, *req.Start, matchers, nil, func(s *stream) error {
+	return i.forMatchingStreams(ctx, *req.Start, matchers, nil, func(s *stream) error {
 		for _, label := range s.labels {
 			if req.Values && label.Name == req.Name {
-				labels = append(labels, label.Value)
+				if err := fn(label.Value); err != nil {
+					return err
+				}
 				continue
 			}
 			if !req.Values {
-				labels = append(labels, label.Name)
+				if err := fn(label.Name); err != nil {
+					return err
+				}
 			}
 		}
 		return nil
 	})
+}
+
+func (i *instance) Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
+	series := make([]logproto.SeriesIdentifier, 0)
+	err := i.forEachSeries(ctx, req, func(s *stream) error {
+		series = append(series, logproto.SeriesIdentifier{
+			Labels: s.labels.Map(),
+		})
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &logproto.LabelResponse{
-		Values: labels,
-	}, nil
+	return &logproto.SeriesResponse{Series: series}, nil
 }
 
-func (i *instance) Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
+// forEachSeries calls fn once with each stream matching the request.
+func (i *instance) forEachSeries(ctx context.Context, req *logproto.SeriesRequest, fn func(*stream) error) error {
 	groups, err := logql.Match(req.GetGroups())
 	if err != nil {
-		return nil, err
+		return err
 	}
 	shard, err := parseShardFromRequest(req.Shards)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var series []logproto.SeriesIdentifier
-
 	// If no matchers were supplied we include all streams.
 	if len(groups) == 0 {
-		series = make([]logproto.SeriesIdentifier, 0, i.streams.Len())
-		err = i.forMatchingStreams(ctx, req.Start, nil, shard, func(stream *stream) error {
+		return i.forMatchingStreams(ctx, req.Start, nil, shard, func(stream *stream) error {
 			// consider the stream only if it overlaps the request time range
 			if shouldConsiderStream(stream, req.Start, req.End) {
-				series = append(series, logproto.SeriesIdentifier{
-					Labels: stream.labels.Map(),
-				})
+				return fn(stream)
 			}
 			return nil
 		})
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		dedupedSeries := make(map[uint64]logproto.SeriesIdentifier)
-		for _, matchers := range groups {
-			err = i.forMatchingStreams(ctx, req.Start, matchers, shard, func(stream *stream) error {
-				// consider the stream only if it overlaps the request time range
-				if shouldConsiderStream(stream, req.Start, req.End) {
-					// exit early when this stream was added by an earlier group
-					key := stream.labelHash
-					if _, found := dedupedSeries[key]; found {
-						return nil
-					}
-
-					dedupedSeries[key] = logproto.SeriesIdentifier{
-						Labels: stream.labels.Map(),
-					}
-				}
+	}
+
+	seen := make(map[uint64]struct{})
+	for _, matchers := range groups {
+		err = i.forMatchingStreams(ctx, req.Start, matchers, shard, func(stream *stream) error {
+			// consider the stream only if it overlaps the request time range
+			if !shouldConsiderStream(stream, req.Start, req.End) {
 				return nil
-			})
-			if err != nil {
-				return nil, err
 			}
-		}
-		series = make([]logproto.SeriesIdentifier, 0, len(dedupedSeries))
-		for _, v := range dedupedSeries {
-			series = append(series, v)
+			// exit early when this stream was added by an earlier group
+			if _, found := seen[stream.labelHash]; found {
+				return nil
+			}
+			seen[stream.labelHash] = struct{}{}
+			return fn(stream)
+		})
+		if err != nil {
+			return err
 		}
 	}
-
-	return &logproto.SeriesResponse{Series: series}, nil
+	return nil
 }
 
 func (i *instance) GetStats(ctx context.Context, req *logproto.IndexStatsRequest) (*logproto.IndexStatsResponse, error) {
@@ -588,6 +649,49 @@ func (i *instance) GetStats(ctx context.Context, req *logproto.IndexStatsRequest
 	return res, nil
 }
 
+func (i *instance) GetVolume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	matchers, err := syntax.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	if err := seriesvolume.ValidateAggregateBy(req.AggregateBy); err != nil {
+		return nil, err
+	}
+
+	acc := seriesvolume.NewAccumulator(req.Limit)
+	targetLabels := seriesvolume.TargetLabels(req.TargetLabels, matchers)
+	from, through := req.From.Time(), req.Through.Time()
+
+	if err = i.forMatchingStreams(ctx, from, matchers, nil, func(s *stream) error {
+		if !shouldConsiderStream(s, from, through) {
+			return nil
+		}
+
+		var size uint64
+		s.chunkMtx.RLock()
+		for _, chk := range s.chunks {
+			// Only count the chunks which haven't been flushed yet:
+			// flushed chunks are counted by the index of the store.
+			chkFrom, chkThrough := chk.chunk.Bounds()
+			if chk.flushed.IsZero() && from.Before(chkThrough) && through.After(chkFrom) {
+				size += uint64(chk.chunk.UncompressedSize())
+			}
+		}
+		s.chunkMtx.RUnlock()
+
+		if size > 0 {
+			seriesvolume.Names(s.labels, req.AggregateBy, targetLabels, "", func(name string) {
+				acc.AddVolume(name, size)
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return acc.Volumes(), nil
+}
+
 func (i *instance) numStreams() int {
 	return i.streams.Len()
 }
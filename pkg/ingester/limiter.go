@@ -8,17 +8,20 @@ import (
 
 	"golang.org/x/time/rate"
 
+	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/validation"
 )
 
 const (
 	errMaxStreamsPerUserLimitExceeded = "tenant '%v' per-user streams limit exceeded, streams: %d exceeds calculated limit: %d (local limit: %d, global limit: %d, global/ingesters: %d)"
+	errMaxSeriesPerUserLimitExceeded  = "tenant '%v' per-user series limit exceeded in the tsdb head, series: %d exceeds calculated limit: %d (global limit: %d)"
 )
 
 // RingCount is the interface exposed by a ring implementation which allows
 // to count members
 type RingCount interface {
 	HealthyInstancesCount() int
+	ZonesCount() int
 }
 
 // Limiter implements primitives to get the maximum number of streams
@@ -67,6 +70,11 @@ func (l *Limiter) UnorderedWrites(userID string) bool {
 	return l.limits.UnorderedWrites(userID)
 }
 
+// ChunkEncoding returns the chunk encoding configured for the user, empty to use the ingester's one.
+func (l *Limiter) ChunkEncoding(userID string) string {
+	return l.limits.ChunkEncoding(userID)
+}
+
 // AssertMaxStreamsPerUser ensures limit has not been reached compared to the current
 // number of streams in input and returns an error if so.
 func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
@@ -84,7 +92,7 @@ func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
 	// We can assume that streams are evenly distributed across ingesters
 	// so we do convert the global limit into a local limit
 	globalLimit := l.limits.MaxGlobalStreamsPerUser(userID)
-	adjustedGlobalLimit := l.convertGlobalToLocalLimit(globalLimit)
+	adjustedGlobalLimit := l.convertGlobalToLocalLimit(userID, globalLimit)
 
 	// Set the calculated limit to the lesser of the local limit or the new calculated global limit
 	calculatedLimit := l.minNonZero(localLimit, adjustedGlobalLimit)
@@ -102,7 +110,29 @@ func (l *Limiter) AssertMaxStreamsPerUser(userID string, streams int) error {
 	return fmt.Errorf(errMaxStreamsPerUserLimitExceeded, userID, streams, calculatedLimit, localLimit, globalLimit, adjustedGlobalLimit)
 }
 
-func (l *Limiter) convertGlobalToLocalLimit(globalLimit int) int {
+// MaxHeadSeriesPerUser returns the maximum number of series of the tenant in the TSDB index heads of this ingester,
+// calculated from the global limit like the streams limit. It returns 0 if there is no limit.
+func (l *Limiter) MaxHeadSeriesPerUser(userID string) int {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if l.disabled {
+		return 0
+	}
+
+	return l.convertGlobalToLocalLimit(userID, l.limits.MaxGlobalSeriesPerUser(userID))
+}
+
+// AssertMaxHeadSeriesPerUser ensures the series limit of the TSDB index heads has not been reached compared to the
+// current number of series of the tenant in input and returns an error if so.
+func (l *Limiter) AssertMaxHeadSeriesPerUser(userID string, series int) error {
+	limit := l.MaxHeadSeriesPerUser(userID)
+	if limit == 0 || series < limit {
+		return nil
+	}
+	return fmt.Errorf(errMaxSeriesPerUserLimitExceeded, userID, series, limit, l.limits.MaxGlobalSeriesPerUser(userID))
+}
+
+func (l *Limiter) convertGlobalToLocalLimit(userID string, globalLimit int) int {
 	if globalLimit == 0 {
 		return 0
 	}
@@ -113,6 +143,18 @@ func (l *Limiter) convertGlobalToLocalLimit(globalLimit int) int {
 	// (global limit / number of ingesters) * replication factor
 	numIngesters := l.ring.HealthyInstancesCount()
 
+	// With shuffle sharding, the streams of the tenant are only distributed
+	// across the ingesters of its shard, evenly selected from each zone.
+	if shardSize := l.limits.IngestionTenantShardSize(userID); shardSize > 0 {
+		numZones := l.ring.ZonesCount()
+		if numZones < 1 {
+			numZones = 1
+		}
+		if inShard := util.ShuffleShardExpectedInstances(shardSize, numZones); inShard < numIngesters {
+			numIngesters = inShard
+		}
+	}
+
 	// May happen because the number of ingesters is asynchronously updated.
 	// If happens, we just temporarily ignore the global limit.
 	if numIngesters > 0 {
@@ -19,6 +19,8 @@ func TestLimiter_AssertMaxStreamsPerUser(t *testing.T) {
 		maxGlobalStreamsPerUser int
 		ringReplicationFactor   int
 		ringIngesterCount       int
+		ringZoneCount           int
+		shardSize               int
 		streams                 int
 		expected                error
 	}{
@@ -94,6 +96,34 @@ func TestLimiter_AssertMaxStreamsPerUser(t *testing.T) {
 			streams:                 3000,
 			expected:                fmt.Errorf(errMaxStreamsPerUserLimitExceeded, "test", 3000, 300, 500, 1000, 300),
 		},
+		"global limit is shared by the ingesters of the shard": {
+			maxLocalStreamsPerUser:  0,
+			maxGlobalStreamsPerUser: 1000,
+			ringReplicationFactor:   3,
+			ringIngesterCount:       10,
+			shardSize:               5,
+			streams:                 3000,
+			expected:                fmt.Errorf(errMaxStreamsPerUserLimitExceeded, "test", 3000, 600, 0, 1000, 600),
+		},
+		"global limit is shared by the ingesters of the shard in each zone": {
+			maxLocalStreamsPerUser:  0,
+			maxGlobalStreamsPerUser: 1000,
+			ringReplicationFactor:   3,
+			ringIngesterCount:       12,
+			ringZoneCount:           3,
+			shardSize:               4,
+			streams:                 3000,
+			expected:                fmt.Errorf(errMaxStreamsPerUserLimitExceeded, "test", 3000, 500, 0, 1000, 500),
+		},
+		"shard larger than the ring": {
+			maxLocalStreamsPerUser:  0,
+			maxGlobalStreamsPerUser: 1000,
+			ringReplicationFactor:   3,
+			ringIngesterCount:       10,
+			shardSize:               20,
+			streams:                 3000,
+			expected:                fmt.Errorf(errMaxStreamsPerUserLimitExceeded, "test", 3000, 300, 0, 1000, 300),
+		},
 	}
 
 	for testName, testData := range tests {
@@ -101,12 +131,13 @@ func TestLimiter_AssertMaxStreamsPerUser(t *testing.T) {
 
 		t.Run(testName, func(t *testing.T) {
 			// Mock the ring
-			ring := &ringCountMock{count: testData.ringIngesterCount}
+			ring := &ringCountMock{count: testData.ringIngesterCount, zones: testData.ringZoneCount}
 
 			// Mock limits
 			limits, err := validation.NewOverrides(validation.Limits{
-				MaxLocalStreamsPerUser:  testData.maxLocalStreamsPerUser,
-				MaxGlobalStreamsPerUser: testData.maxGlobalStreamsPerUser,
+				MaxLocalStreamsPerUser:   testData.maxLocalStreamsPerUser,
+				MaxGlobalStreamsPerUser:  testData.maxGlobalStreamsPerUser,
+				IngestionTenantShardSize: testData.shardSize,
 			}, nil)
 			require.NoError(t, err)
 
@@ -118,6 +149,22 @@ func TestLimiter_AssertMaxStreamsPerUser(t *testing.T) {
 	}
 }
 
+func TestLimiter_MaxHeadSeriesPerUser(t *testing.T) {
+	limits, err := validation.NewOverrides(validation.Limits{MaxGlobalSeriesPerUser: 1000}, nil)
+	require.NoError(t, err)
+
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 10}, 3)
+	assert.Equal(t, 300, limiter.MaxHeadSeriesPerUser("test"))
+
+	assert.NoError(t, limiter.AssertMaxHeadSeriesPerUser("test", 299))
+	assert.Error(t, limiter.AssertMaxHeadSeriesPerUser("test", 300))
+
+	// the limit is disabled while replaying the WAL
+	limiter.DisableForWALReplay()
+	assert.Equal(t, 0, limiter.MaxHeadSeriesPerUser("test"))
+	assert.NoError(t, limiter.AssertMaxHeadSeriesPerUser("test", 300))
+}
+
 func TestLimiter_minNonZero(t *testing.T) {
 	t.Parallel()
 
@@ -165,12 +212,17 @@ func TestLimiter_minNonZero(t *testing.T) {
 
 type ringCountMock struct {
 	count int
+	zones int
 }
 
 func (m *ringCountMock) HealthyInstancesCount() int {
 	return m.count
 }
 
+func (m *ringCountMock) ZonesCount() int {
+	return m.zones
+}
+
 // Assert some of the weirder (bug?) behavior of golang.org/x/time/rate
 func TestGoLimiter(t *testing.T) {
 	for _, tc := range []struct {
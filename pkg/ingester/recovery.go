@@ -14,6 +14,7 @@ import (
 
 	"github.com/grafana/loki/pkg/logproto"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	util_wal "github.com/grafana/loki/pkg/util/wal"
 )
 
 type WALReader interface {
@@ -30,22 +31,44 @@ func (NoopWALReader) Err() error     { return nil }
 func (NoopWALReader) Record() []byte { return nil }
 func (NoopWALReader) Close() error   { return nil }
 
-func newCheckpointReader(dir string) (WALReader, io.Closer, error) {
+// newCheckpointReader returns a reader of the most recent checkpoint along with its index,
+// which is -1 if there is no checkpoint.
+func newCheckpointReader(dir string) (WALReader, io.Closer, int, error) {
 	lastCheckpointDir, idx, err := lastCheckpoint(dir)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, -1, err
 	}
 	if idx < 0 {
 		level.Info(util_log.Logger).Log("msg", "no checkpoint found, treating as no-op")
 		var reader NoopWALReader
-		return reader, reader, nil
+		return reader, reader, -1, nil
 	}
 
 	r, err := wal.NewSegmentsReader(lastCheckpointDir)
+	if err != nil {
+		return nil, nil, -1, err
+	}
+	return wal.NewReader(r), r, idx, nil
+}
+
+// newSegmentsReader returns a reader of the WAL segments written after the checkpoint with the given index.
+// The checkpoint already contains the data of the previous segments, which may be left over
+// if their deletion failed, so they don't need to be replayed.
+func newSegmentsReader(dir string, checkpointIdx int) (WALReader, io.Closer, error) {
+	if checkpointIdx < 0 {
+		return util_wal.NewWalReader(dir, -1)
+	}
+
+	_, last, err := wal.Segments(dir)
 	if err != nil {
 		return nil, nil, err
 	}
-	return wal.NewReader(r), r, nil
+	if last <= checkpointIdx {
+		level.Info(util_log.Logger).Log("msg", "no WAL segment after the last checkpoint, treating as no-op", "checkpoint", checkpointIdx)
+		var reader NoopWALReader
+		return reader, reader, nil
+	}
+	return util_wal.NewWalReader(dir, checkpointIdx+1)
 }
 
 type Recoverer interface {
@@ -209,7 +232,7 @@ func (r *ingesterRecoverer) Close() {
 			s.unorderedWrites = isAllowed
 
 			if !isAllowed && old {
-				err := s.chunks[len(s.chunks)-1].chunk.ConvertHead(headBlockType(isAllowed))
+				err := s.chunks[len(s.chunks)-1].chunk.ConvertHead(headBlockType(isAllowed, false, r.ing.cfg.WriteChunkFormatV5))
 				if err != nil {
 					level.Warn(util_log.Logger).Log(
 						"msg", "error converting headblock",
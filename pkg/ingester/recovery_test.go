@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb/chunks"
 	"github.com/prometheus/prometheus/tsdb/record"
+	"github.com/prometheus/prometheus/tsdb/wal"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
 
@@ -277,3 +278,35 @@ func TestSeriesRecoveryNoDuplicates(t *testing.T) {
 	}
 	require.Equal(t, expected, result.resps[0].Streams)
 }
+
+func TestSegmentsReaderSkipsCheckpointedSegments(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := wal.New(nil, nil, dir, false)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			_, err := w.NextSegment()
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Log([]byte(fmt.Sprintf("segment-%d", i))))
+	}
+	require.NoError(t, w.Close())
+
+	read := func(checkpointIdx int) []string {
+		r, closer, err := newSegmentsReader(dir, checkpointIdx)
+		require.NoError(t, err)
+		defer closer.Close()
+
+		var res []string
+		for r.Next() {
+			res = append(res, string(r.Record()))
+		}
+		require.NoError(t, r.Err())
+		return res
+	}
+
+	require.Equal(t, []string{"segment-0", "segment-1", "segment-2"}, read(-1))
+	require.Equal(t, []string{"segment-2"}, read(1))
+	require.Empty(t, read(2))
+}
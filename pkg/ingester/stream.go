@@ -69,7 +69,12 @@ type stream struct {
 	// introduced to facilitate removing the ordering constraint.
 	entryCt int64
 
-	unorderedWrites      bool
+	unorderedWrites bool
+	// encoding is the compression used for new chunks of the stream.
+	encoding chunkenc.Encoding
+	// nonIndexedLabels is set once an entry with non-indexed labels is pushed,
+	// from then on the stream's chunks are cut in a format which stores them.
+	nonIndexedLabels     bool
 	streamRateCalculator *StreamRateCalculator
 }
 
@@ -88,7 +93,7 @@ type entryWithError struct {
 	e     error
 }
 
-func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites bool, streamRateCalculator *StreamRateCalculator, metrics *ingesterMetrics) *stream {
+func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.Fingerprint, labels labels.Labels, unorderedWrites bool, encoding chunkenc.Encoding, streamRateCalculator *StreamRateCalculator, metrics *ingesterMetrics) *stream {
 	hashNoShard, _ := labels.HashWithoutLabels(make([]byte, 0, 1024), ShardLbName)
 	return &stream{
 		limiter:              NewStreamRateLimiter(limits, tenant, 10*time.Second),
@@ -104,6 +109,7 @@ func newStream(cfg *Config, limits RateLimiterStrategy, tenant string, fp model.
 		streamRateCalculator: streamRateCalculator,
 
 		unorderedWrites: unorderedWrites,
+		encoding:        encoding,
 	}
 }
 
@@ -133,6 +139,9 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 		return 0, 0, err
 	}
 	s.chunks = chks
+	if len(chks) > 0 {
+		s.nonIndexedLabels = chks[len(chks)-1].chunk.SupportsNonIndexedLabels()
+	}
 	for _, c := range s.chunks {
 		entriesAdded += c.chunk.Size()
 		bytesAdded += c.chunk.UncompressedSize()
@@ -141,7 +150,7 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 }
 
 func (s *stream) NewChunk() *chunkenc.MemChunk {
-	return chunkenc.NewMemChunk(s.cfg.parsedEncoding, headBlockType(s.unorderedWrites), s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	return chunkenc.NewMemChunk(s.encoding, headBlockType(s.unorderedWrites, s.nonIndexedLabels, s.cfg.WriteChunkFormatV5), s.cfg.BlockSize, s.cfg.TargetChunkSize)
 }
 
 func (s *stream) Push(
@@ -210,11 +219,12 @@ func errorForFailedEntries(s *stream, failedEntriesWithError []entryWithError, t
 	lastEntryWithErr := failedEntriesWithError[len(failedEntriesWithError)-1]
 	_, ok := lastEntryWithErr.e.(*validation.ErrStreamRateLimit)
 	outOfOrder := chunkenc.IsOutOfOrderErr(lastEntryWithErr.e)
-	if !outOfOrder && !ok {
+	nonIndexedLabels := lastEntryWithErr.e == chunkenc.ErrNonIndexedLabelsUnsupported
+	if !outOfOrder && !nonIndexedLabels && !ok {
 		return lastEntryWithErr.e
 	}
 	var statusCode int
-	if outOfOrder {
+	if outOfOrder || nonIndexedLabels {
 		statusCode = http.StatusBadRequest
 	}
 	if ok {
@@ -301,6 +311,14 @@ func (s *stream) storeEntries(ctx context.Context, entries []logproto.Entry) (in
 	storedEntries := make([]logproto.Entry, 0, len(entries))
 	for i := 0; i < len(entries); i++ {
 		chunk := &s.chunks[len(s.chunks)-1]
+		if len(entries[i].NonIndexedLabels) > 0 && s.unorderedWrites && !chunk.chunk.SupportsNonIndexedLabels() {
+			s.nonIndexedLabels = true
+			if chunk.chunk.Size() == 0 && !chunk.closed {
+				chunk.chunk = s.NewChunk()
+			} else {
+				chunk = s.cutChunk(ctx)
+			}
+		}
 		if chunk.closed || !chunk.chunk.SpaceFor(&entries[i]) || s.cutChunkForSynchronization(entries[i].Timestamp, s.highestTs, chunk, s.cfg.SyncPeriod, s.cfg.SyncMinUtilization) {
 			chunk = s.cutChunk(ctx)
 		}
@@ -366,8 +384,9 @@ func (s *stream) validateEntries(entries []logproto.Entry, isReplay, rateLimitWh
 			continue
 		}
 
-		// The validity window for unordered writes is the highest timestamp present minus 1/2 * max-chunk-age.
-		cutoff := highestTs.Add(-s.cfg.MaxChunkAge / 2)
+		// The validity window for unordered writes is the highest timestamp present minus the out-of-order window,
+		// which defaults to 1/2 * max-chunk-age.
+		cutoff := highestTs.Add(-s.cfg.outOfOrderWindow())
 		if !isReplay && s.unorderedWrites && !highestTs.IsZero() && cutoff.After(entries[i].Timestamp) {
 			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], chunkenc.ErrTooFarBehind(cutoff)})
 			outOfOrderSamples++
@@ -577,8 +596,15 @@ func (s *stream) resetCounter() {
 	s.entryCt = 0
 }
 
-func headBlockType(unorderedWrites bool) chunkenc.HeadBlockFmt {
+func headBlockType(unorderedWrites, nonIndexedLabels, chunkFormatV5 bool) chunkenc.HeadBlockFmt {
 	if unorderedWrites {
+		if chunkFormatV5 {
+			// chunks of format v5 always support non-indexed labels.
+			return chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt
+		}
+		if nonIndexedLabels {
+			return chunkenc.UnorderedWithNonIndexedLabelsHeadBlockFmt
+		}
 		return chunkenc.UnorderedHeadBlockFmt
 	}
 	return chunkenc.OrderedHeadBlockFmt
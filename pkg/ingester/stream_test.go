@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/util/flagext"
 	"github.com/grafana/loki/pkg/validation"
 )
@@ -61,6 +62,7 @@ func TestMaxReturnedStreamsErrors(t *testing.T) {
 					{Name: "foo", Value: "bar"},
 				},
 				true,
+				chunkenc.EncGZIP,
 				NewStreamRateCalculator(),
 				NilMetrics,
 			)
@@ -108,6 +110,7 @@ func TestPushDeduplication(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -138,6 +141,7 @@ func TestPushRejectOldCounter(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -233,6 +237,7 @@ func TestUnorderedPush(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -312,6 +317,117 @@ func TestUnorderedPush(t *testing.T) {
 	require.Equal(t, false, sItr.Next())
 }
 
+func TestPushNonIndexedLabels(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	s := newStream(
+		&cfg,
+		limiter,
+		"fake",
+		model.Fingerprint(0),
+		labels.Labels{
+			{Name: "foo", Value: "bar"},
+		},
+		true,
+		chunkenc.EncGZIP,
+		NewStreamRateCalculator(),
+		NilMetrics,
+	)
+
+	_, err = s.Push(context.Background(), []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "x"},
+	}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.False(t, s.chunks[0].chunk.SupportsNonIndexedLabels())
+
+	// the first entry with non-indexed labels cuts a chunk which can store them.
+	_, err = s.Push(context.Background(), []logproto.Entry{
+		{Timestamp: time.Unix(2, 0), Line: "y", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "1"}}},
+		{Timestamp: time.Unix(3, 0), Line: "z"},
+	}, recordPool.GetRecord(), 0, true, false)
+	require.NoError(t, err)
+	require.Len(t, s.chunks, 2)
+	require.True(t, s.chunks[1].chunk.SupportsNonIndexedLabels())
+
+	// so do the chunks cut afterwards.
+	_ = s.cutChunk(context.Background())
+	require.True(t, s.chunks[2].chunk.SupportsNonIndexedLabels())
+
+	expr, err := syntax.ParseLogSelector(`{foo="bar"} | metadata | trace_id="1"`, true)
+	require.NoError(t, err)
+	p, err := expr.Pipeline()
+	require.NoError(t, err)
+	itr, err := s.Iterator(context.Background(), nil, time.Unix(0, 0), time.Unix(4, 0), logproto.FORWARD, p.ForStream(s.labels))
+	require.NoError(t, err)
+	iterEq(t, []logproto.Entry{{Timestamp: time.Unix(2, 0), Line: "y"}}, itr)
+
+	// chunks of streams without unordered writes can't store them.
+	s = newStream(
+		&cfg,
+		limiter,
+		"fake",
+		model.Fingerprint(0),
+		labels.Labels{
+			{Name: "foo", Value: "bar"},
+		},
+		false,
+		chunkenc.EncGZIP,
+		NewStreamRateCalculator(),
+		NilMetrics,
+	)
+	written, err := s.Push(context.Background(), []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "y", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "1"}}},
+	}, recordPool.GetRecord(), 0, true, false)
+	require.Equal(t, 0, written)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+}
+
+func TestPushChunkFormatV5(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.WriteChunkFormatV5 = true
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	for _, unorderedWrites := range []bool{true, false} {
+		s := newStream(
+			&cfg,
+			limiter,
+			"fake",
+			model.Fingerprint(0),
+			labels.Labels{
+				{Name: "foo", Value: "bar"},
+			},
+			unorderedWrites,
+			chunkenc.EncGZIP,
+			NewStreamRateCalculator(),
+			NilMetrics,
+		)
+
+		_, err = s.Push(context.Background(), []logproto.Entry{
+			{Timestamp: time.Unix(1, 0), Line: "x"},
+		}, recordPool.GetRecord(), 0, true, false)
+		require.NoError(t, err)
+		require.NoError(t, s.chunks[0].chunk.Close())
+		b, err := s.chunks[0].chunk.Bytes()
+		require.NoError(t, err)
+
+		// only the chunks of streams accepting unordered writes are written in the format v5.
+		if unorderedWrites {
+			require.Equal(t, byte(5), b[4])
+			require.True(t, s.chunks[0].chunk.SupportsNonIndexedLabels())
+		} else {
+			require.Equal(t, chunkenc.DefaultChunkFormat, b[4])
+			require.False(t, s.chunks[0].chunk.SupportsNonIndexedLabels())
+		}
+	}
+}
+
 func TestPushRateLimit(t *testing.T) {
 	l := validation.Limits{
 		PerStreamRateLimit:      10,
@@ -330,6 +446,7 @@ func TestPushRateLimit(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -364,6 +481,7 @@ func TestPushRateLimitAllOrNothing(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -397,6 +515,7 @@ func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 			{Name: "foo", Value: "bar"},
 		},
 		true,
+		chunkenc.EncGZIP,
 		NewStreamRateCalculator(),
 		NilMetrics,
 	)
@@ -426,6 +545,69 @@ func TestReplayAppendIgnoresValidityWindow(t *testing.T) {
 
 }
 
+func TestOutOfOrderWindow(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
+
+	for _, tc := range []struct {
+		name   string
+		window time.Duration
+		behind time.Duration
+		err    bool
+	}{
+		{name: "default window accepts", behind: 29 * time.Minute},
+		{name: "default window rejects", behind: 31 * time.Minute, err: true},
+		{name: "configured window accepts", window: 5 * time.Minute, behind: 4 * time.Minute},
+		{name: "configured window rejects", window: 5 * time.Minute, behind: 6 * time.Minute, err: true},
+		{name: "window larger than default", window: 50 * time.Minute, behind: 45 * time.Minute},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.MaxChunkAge = time.Hour
+			cfg.OutOfOrderWindow = tc.window
+
+			s := newStream(
+				cfg,
+				limiter,
+				"fake",
+				model.Fingerprint(0),
+				labels.Labels{
+					{Name: "foo", Value: "bar"},
+				},
+				true,
+				chunkenc.EncGZIP,
+				NewStreamRateCalculator(),
+				NilMetrics,
+			)
+
+			base := time.Unix(3600, 0)
+			_, err := s.Push(context.Background(), []logproto.Entry{
+				{Timestamp: base, Line: "1"},
+			}, recordPool.GetRecord(), 0, true, false)
+			require.NoError(t, err)
+
+			_, err = s.Push(context.Background(), []logproto.Entry{
+				{Timestamp: base.Add(-tc.behind), Line: "2"},
+			}, recordPool.GetRecord(), 0, true, false)
+			if tc.err {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "entry too far behind")
+				return
+			}
+			require.NoError(t, err)
+
+			// Entries accepted within the window are reordered in the head block.
+			it, err := s.Iterator(context.Background(), nil, base.Add(-time.Hour), base.Add(time.Second), logproto.FORWARD, log.NewNoopPipeline().ForStream(s.labels))
+			require.NoError(t, err)
+			iterEq(t, []logproto.Entry{
+				{Timestamp: base.Add(-tc.behind), Line: "2"},
+				{Timestamp: base, Line: "1"},
+			}, it)
+		})
+	}
+}
+
 func iterEq(t *testing.T, exp []logproto.Entry, got iter.EntryIterator) {
 	var i int
 	for got.Next() {
@@ -448,7 +630,7 @@ func Benchmark_PushStream(b *testing.B) {
 	require.NoError(b, err)
 	limiter := NewLimiter(limits, NilMetrics, &ringCountMock{count: 1}, 1)
 
-	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, NewStreamRateCalculator(), NilMetrics)
+	s := newStream(&Config{MaxChunkAge: 24 * time.Hour}, limiter, "fake", model.Fingerprint(0), ls, true, chunkenc.EncGZIP, NewStreamRateCalculator(), NilMetrics)
 	t, err := newTailer("foo", `{namespace="loki-dev"}`, &fakeTailServer{}, 10)
 	require.NoError(b, err)
 
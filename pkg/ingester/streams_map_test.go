@@ -7,6 +7,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -25,6 +26,7 @@ func TestStreamsMap(t *testing.T) {
 				{Name: "foo", Value: "bar"},
 			},
 			true,
+			chunkenc.EncGZIP,
 			NewStreamRateCalculator(),
 			NilMetrics,
 		),
@@ -37,6 +39,7 @@ func TestStreamsMap(t *testing.T) {
 				{Name: "bar", Value: "foo"},
 			},
 			true,
+			chunkenc.EncGZIP,
 			NewStreamRateCalculator(),
 			NilMetrics,
 		),
@@ -28,6 +28,7 @@ const (
 	labelsPath        = "/loki/api/v1/labels"
 	labelValuesPath   = "/loki/api/v1/label/%s/values"
 	seriesPath        = "/loki/api/v1/series"
+	statsPath         = "/loki/api/v1/index/stats"
 	tailPath          = "/loki/api/v1/tail"
 	defaultAuthHeader = "Authorization"
 )
@@ -41,6 +42,7 @@ type Client interface {
 	ListLabelNames(quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	ListLabelValues(name string, quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	Series(matchers []string, start, end time.Time, quiet bool) (*loghttp.SeriesResponse, error)
+	GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error)
 	LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error)
 	GetOrgID() string
 }
@@ -140,6 +142,20 @@ func (c *DefaultClient) Series(matchers []string, start, end time.Time, quiet bo
 	return &seriesResponse, nil
 }
 
+// GetStats uses the /loki/api/v1/index/stats endpoint to get the number of streams, chunks, entries and bytes of a query
+func (c *DefaultClient) GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error) {
+	params := util.NewQueryStringBuilder()
+	params.SetInt("start", start.UnixNano())
+	params.SetInt("end", end.UnixNano())
+	params.SetString("query", queryStr)
+
+	var statsResponse logproto.IndexStatsResponse
+	if err := c.doRequest(statsPath, params.Encode(), quiet, &statsResponse); err != nil {
+		return nil, err
+	}
+	return &statsResponse, nil
+}
+
 // LiveTailQueryConn uses /api/prom/tail to set up a websocket connection and returns it
 func (c *DefaultClient) LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error) {
 	params := util.NewQueryStringBuilder()
@@ -173,6 +173,10 @@ func (f *FileClient) Series(matchers []string, start, end time.Time, quiet bool)
 	}, nil
 }
 
+func (f *FileClient) GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error) {
+	return nil, fmt.Errorf("GetStats: %w", ErrNotSupported)
+}
+
 func (f *FileClient) LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error) {
 	return nil, fmt.Errorf("LiveTailQuery: %w", ErrNotSupported)
 }
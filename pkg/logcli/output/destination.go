@@ -0,0 +1,160 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// s3PartSize is the size of the parts of the multipart uploads to S3, which must be at least 5MiB.
+const s3PartSize = 8 << 20
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// NewDestination returns the writer of the output of a query: stdout when dest is empty or "-", an upload to S3
+// when dest is an s3://bucket/key URL, and the dest file otherwise. It must be closed for the output to be
+// complete. The S3 credentials and region are taken from the environment and the shared AWS config files.
+func NewDestination(dest string) (io.WriteCloser, error) {
+	switch {
+	case dest == "" || dest == "-":
+		return nopCloser{os.Stdout}, nil
+	case strings.HasPrefix(dest, "s3://"):
+		u, err := url.Parse(dest)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || key == "" {
+			return nil, fmt.Errorf("invalid S3 destination %s, expected s3://bucket/key", dest)
+		}
+		sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+		if err != nil {
+			return nil, fmt.Errorf("creating AWS session: %w", err)
+		}
+		return newS3Writer(context.Background(), s3.New(sess), u.Host, key), nil
+	default:
+		return os.Create(dest)
+	}
+}
+
+// s3Writer streams what is written to it to an S3 object, in a multipart upload once it exceeds a part.
+type s3Writer struct {
+	ctx         context.Context
+	client      s3iface.S3API
+	bucket, key string
+
+	buf      bytes.Buffer
+	uploadID *string
+	parts    []*s3.CompletedPart
+	err      error
+}
+
+func newS3Writer(ctx context.Context, client s3iface.S3API, bucket, key string) *s3Writer {
+	return &s3Writer{
+		ctx:    ctx,
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.buf.Write(p)
+	for w.buf.Len() >= s3PartSize {
+		if w.err = w.uploadPart(w.buf.Next(s3PartSize)); w.err != nil {
+			w.abort()
+			return 0, w.err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *s3Writer) uploadPart(part []byte) error {
+	if w.uploadID == nil {
+		out, err := w.client.CreateMultipartUploadWithContext(w.ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(w.bucket),
+			Key:    aws.String(w.key),
+		})
+		if err != nil {
+			return fmt.Errorf("creating multipart upload to s3://%s/%s: %w", w.bucket, w.key, err)
+		}
+		w.uploadID = out.UploadId
+	}
+
+	number := aws.Int64(int64(len(w.parts) + 1))
+	out, err := w.client.UploadPartWithContext(w.ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(w.bucket),
+		Key:        aws.String(w.key),
+		UploadId:   w.uploadID,
+		PartNumber: number,
+		Body:       bytes.NewReader(part),
+	})
+	if err != nil {
+		return fmt.Errorf("uploading part %d to s3://%s/%s: %w", *number, w.bucket, w.key, err)
+	}
+	w.parts = append(w.parts, &s3.CompletedPart{ETag: out.ETag, PartNumber: number})
+	return nil
+}
+
+// Close uploads what is left and completes the upload. Objects smaller than a part are uploaded at once.
+func (w *s3Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.uploadID == nil {
+		if _, err := w.client.PutObjectWithContext(w.ctx, &s3.PutObjectInput{
+			Bucket: aws.String(w.bucket),
+			Key:    aws.String(w.key),
+			Body:   bytes.NewReader(w.buf.Bytes()),
+		}); err != nil {
+			return fmt.Errorf("uploading s3://%s/%s: %w", w.bucket, w.key, err)
+		}
+		return nil
+	}
+
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(w.buf.Bytes()); err != nil {
+			w.abort()
+			return err
+		}
+	}
+	if _, err := w.client.CompleteMultipartUploadWithContext(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(w.bucket),
+		Key:             aws.String(w.key),
+		UploadId:        w.uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: w.parts},
+	}); err != nil {
+		w.abort()
+		return fmt.Errorf("completing multipart upload to s3://%s/%s: %w", w.bucket, w.key, err)
+	}
+	return nil
+}
+
+// abort aborts the multipart upload, if any, so that its parts are not kept around.
+func (w *s3Writer) abort() {
+	if w.uploadID == nil {
+		return
+	}
+	_, _ = w.client.AbortMultipartUploadWithContext(w.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(w.bucket),
+		Key:      aws.String(w.key),
+		UploadId: w.uploadID,
+	})
+}
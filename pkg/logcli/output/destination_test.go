@@ -0,0 +1,130 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3 struct {
+	s3iface.S3API
+
+	objects   map[string][]byte
+	uploads   map[string][][]byte
+	aborted   int
+	failParts bool
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, uploads: map[string][][]byte{}}
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	b, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[*in.Bucket+"/"+*in.Key] = b
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) CreateMultipartUploadWithContext(_ aws.Context, in *s3.CreateMultipartUploadInput, _ ...request.Option) (*s3.CreateMultipartUploadOutput, error) {
+	id := *in.Bucket + "/" + *in.Key
+	f.uploads[id] = nil
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPartWithContext(_ aws.Context, in *s3.UploadPartInput, _ ...request.Option) (*s3.UploadPartOutput, error) {
+	if f.failParts {
+		return nil, errors.New("part failed")
+	}
+	b, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.uploads[*in.UploadId] = append(f.uploads[*in.UploadId], b)
+	return &s3.UploadPartOutput{ETag: aws.String("etag")}, nil
+}
+
+func (f *fakeS3) CompleteMultipartUploadWithContext(_ aws.Context, in *s3.CompleteMultipartUploadInput, _ ...request.Option) (*s3.CompleteMultipartUploadOutput, error) {
+	parts := f.uploads[*in.UploadId]
+	if len(in.MultipartUpload.Parts) != len(parts) {
+		return nil, errors.New("missing parts")
+	}
+	f.objects[*in.Bucket+"/"+*in.Key] = bytes.Join(parts, nil)
+	delete(f.uploads, *in.UploadId)
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) AbortMultipartUploadWithContext(_ aws.Context, in *s3.AbortMultipartUploadInput, _ ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	delete(f.uploads, *in.UploadId)
+	f.aborted++
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestS3Writer(t *testing.T) {
+	client := newFakeS3()
+
+	// small objects are uploaded at once
+	w := newS3Writer(context.Background(), client, "bucket", "small")
+	_, err := w.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, []byte("hello"), client.objects["bucket/small"])
+
+	// larger ones in parts
+	data := bytes.Repeat([]byte("0123456789"), (2*s3PartSize+100)/10)
+	w = newS3Writer(context.Background(), client, "bucket", "large")
+	for b := data; len(b) > 0; {
+		n := 1000
+		if n > len(b) {
+			n = len(b)
+		}
+		_, err := w.Write(b[:n])
+		require.NoError(t, err)
+		b = b[n:]
+	}
+	require.Len(t, client.uploads["bucket/large"], 2)
+	require.NoError(t, w.Close())
+	require.Equal(t, data, client.objects["bucket/large"])
+	require.Empty(t, client.uploads)
+
+	// failed uploads are aborted
+	client.failParts = true
+	w = newS3Writer(context.Background(), client, "bucket", "failed")
+	_, err = w.Write(data)
+	require.Error(t, err)
+	require.Error(t, w.Close())
+	require.Equal(t, 1, client.aborted)
+	require.Empty(t, client.uploads)
+	require.NotContains(t, client.objects, "bucket/failed")
+}
+
+func TestNewDestination(t *testing.T) {
+	w, err := NewDestination("-")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	w, err = NewDestination(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("data"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte("data"), b)
+
+	_, err = NewDestination("s3://bucket")
+	require.Error(t, err)
+}
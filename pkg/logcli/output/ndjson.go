@@ -0,0 +1,48 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+// NDJSONOutput prints logs as newline delimited JSON objects holding all the labels of their stream, along
+// with their timestamp in nanoseconds, suitable for archiving the results of a query.
+type NDJSONOutput struct {
+	w       io.Writer
+	options *LogOutputOptions
+}
+
+// FormatAndPrintln formats a log entry as a JSON object on its own line
+func (o *NDJSONOutput) FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, maxLabelsLen int, line string) {
+	if lbls == nil {
+		lbls = loghttp.LabelSet{}
+	}
+	out, err := json.Marshal(struct {
+		Timestamp string           `json:"timestamp"`
+		Labels    loghttp.LabelSet `json:"labels"`
+		Line      string           `json:"line"`
+	}{
+		Timestamp: strconv.FormatInt(ts.UnixNano(), 10),
+		Labels:    lbls,
+		Line:      line,
+	})
+	if err != nil {
+		log.Fatalf("error marshalling entry: %s", err)
+	}
+
+	fmt.Fprintln(o.w, string(out))
+}
+
+// WithWriter returns a copy of the NDJSON output writing to w
+func (o *NDJSONOutput) WithWriter(w io.Writer) LogOutput {
+	return &NDJSONOutput{
+		w:       w,
+		options: o.options,
+	}
+}
@@ -0,0 +1,26 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+func TestNDJSONOutput_Format(t *testing.T) {
+	ts := time.Unix(0, 1680000000123456789)
+
+	var buf bytes.Buffer
+	// the labels are written even when they are disabled, the output being meant for archiving
+	out, err := NewLogOutput(&buf, "ndjson", &LogOutputOptions{Timezone: time.UTC, NoLabels: true})
+	require.NoError(t, err)
+	require.True(t, KeepsCommonLabels(out))
+
+	out.FormatAndPrintln(ts, loghttp.LabelSet{"app": "foo"}, 0, "hello")
+	out.FormatAndPrintln(ts, nil, 0, `"quoted"`)
+	require.Equal(t, `{"timestamp":"1680000000123456789","labels":{"app":"foo"},"line":"hello"}`+"\n"+
+		`{"timestamp":"1680000000123456789","labels":{},"line":"\"quoted\""}`+"\n", buf.String())
+}
@@ -29,6 +29,7 @@ var colorList = []*color.Color{
 // LogOutput is the interface any output mode must implement
 type LogOutput interface {
 	FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, maxLabelsLen int, line string)
+	WithWriter(w io.Writer) LogOutput
 }
 
 // LogOutputOptions defines options supported by LogOutput
@@ -60,11 +61,32 @@ func NewLogOutput(w io.Writer, mode string, options *LogOutputOptions) (LogOutpu
 			w:       w,
 			options: options,
 		}, nil
+	case "ndjson":
+		return &NDJSONOutput{
+			w:       w,
+			options: options,
+		}, nil
+	case "parquet":
+		return &ParquetOutput{
+			w:       w,
+			options: options,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unknown log output mode '%s'", mode)
 	}
 }
 
+// KeepsCommonLabels returns whether the output archives the entries along with all the labels of their stream,
+// rather than only the labels which differ between the streams of the result.
+func KeepsCommonLabels(out LogOutput) bool {
+	switch out.(type) {
+	case *NDJSONOutput, *ParquetOutput:
+		return true
+	default:
+		return false
+	}
+}
+
 func getColor(labels string) *color.Color {
 	hash := fnv.New32()
 	_, _ = hash.Write([]byte(labels))
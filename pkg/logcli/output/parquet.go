@@ -0,0 +1,73 @@
+package output
+
+import (
+	"io"
+	"log"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+// parquetRowGroupSize is the uncompressed size of the entries buffered before they are written as a row group.
+const parquetRowGroupSize = 32 << 20
+
+// parquetEntry is a row of the parquet files.
+type parquetEntry struct {
+	Timestamp int64  `parquet:"name=timestamp, type=INT64, logicaltype=TIMESTAMP, logicaltype.isadjustedtoutc=true, logicaltype.unit=NANOS"`
+	Labels    string `parquet:"name=labels, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Line      string `parquet:"name=line, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// ParquetOutput writes logs to a parquet file with the timestamp, labels and line columns, the labels of each
+// entry being all the labels of its stream. The entries are buffered in row groups, so the output must be closed
+// for the file to be complete.
+type ParquetOutput struct {
+	w       io.Writer
+	options *LogOutputOptions
+
+	pw *writer.ParquetWriter
+}
+
+// FormatAndPrintln buffers a log entry, writing a row group once enough entries are buffered
+func (o *ParquetOutput) FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, maxLabelsLen int, line string) {
+	pw, err := o.writer()
+	if err == nil {
+		err = pw.Write(parquetEntry{Timestamp: ts.UnixNano(), Labels: lbls.String(), Line: line})
+	}
+	if err != nil {
+		log.Fatalf("error writing parquet entry: %s", err)
+	}
+}
+
+// WithWriter returns a new parquet output writing to w
+func (o *ParquetOutput) WithWriter(w io.Writer) LogOutput {
+	return &ParquetOutput{
+		w:       w,
+		options: o.options,
+	}
+}
+
+// Close writes the buffered entries and the footer of the parquet file. It doesn't close the underlying writer.
+func (o *ParquetOutput) Close() error {
+	pw, err := o.writer()
+	if err != nil {
+		return err
+	}
+	return pw.WriteStop()
+}
+
+// writer returns the parquet writer, creating it on first use as it starts writing the file.
+func (o *ParquetOutput) writer() (*writer.ParquetWriter, error) {
+	if o.pw != nil {
+		return o.pw, nil
+	}
+	pw, err := writer.NewParquetWriterFromWriter(o.w, new(parquetEntry), 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+	o.pw = pw
+	return pw, nil
+}
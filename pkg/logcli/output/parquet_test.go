@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+// readParquet returns the entries of the file, the number of its row groups and the schema of its columns by name.
+func readParquet(t *testing.T, file []byte) ([]parquetEntry, int, map[string]*parquet.SchemaElement) {
+	f, err := buffer.NewBufferFile(file)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetReader(f, new(parquetEntry), 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+
+	entries := make([]parquetEntry, pr.GetNumRows())
+	require.NoError(t, pr.Read(&entries))
+
+	// the reader renames the schema elements after the fields of parquetEntry
+	schema := map[string]*parquet.SchemaElement{}
+	for i, e := range pr.Footer.Schema[1:] {
+		schema[pr.SchemaHandler.Infos[i+1].ExName] = e
+	}
+	return entries, len(pr.Footer.RowGroups), schema
+}
+
+func TestParquetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	out, err := NewLogOutput(&buf, "parquet", &LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+
+	lbls := loghttp.LabelSet{"app": "foo", "env": "prod"}
+	ts := time.Unix(0, 1680000000123456789)
+	var expected []parquetEntry
+	for i := 0; i < 20; i++ {
+		line := string(bytes.Repeat([]byte{'a' + byte(i)}, i))
+		out.FormatAndPrintln(ts.Add(time.Duration(i)), lbls, 0, line)
+		expected = append(expected, parquetEntry{Timestamp: ts.UnixNano() + int64(i), Labels: lbls.String(), Line: line})
+		if i == 9 {
+			// split the entries in two row groups
+			require.NoError(t, out.(*ParquetOutput).pw.Flush(true))
+		}
+	}
+	require.NoError(t, out.(*ParquetOutput).Close())
+
+	entries, rowGroups, schema := readParquet(t, buf.Bytes())
+	require.Equal(t, expected, entries)
+	require.Equal(t, 2, rowGroups)
+
+	require.Len(t, schema, 3)
+	for _, e := range schema {
+		require.Equal(t, parquet.FieldRepetitionType_REQUIRED, e.GetRepetitionType())
+	}
+	require.Equal(t, parquet.Type_INT64, schema["timestamp"].GetType())
+	timestamp := schema["timestamp"].GetLogicalType().GetTIMESTAMP()
+	require.True(t, timestamp.IsAdjustedToUTC)
+	require.True(t, timestamp.Unit.IsSetNANOS())
+	for _, name := range []string{"labels", "line"} {
+		require.Equal(t, parquet.Type_BYTE_ARRAY, schema[name].GetType())
+		require.Equal(t, parquet.ConvertedType_UTF8, schema[name].GetConvertedType())
+	}
+
+	// a file without entries is still valid
+	buf.Reset()
+	require.NoError(t, out.WithWriter(&buf).(*ParquetOutput).Close())
+	entries, _, _ = readParquet(t, buf.Bytes())
+	require.Empty(t, entries)
+}
@@ -0,0 +1,166 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/logcli/output"
+	"github.com/grafana/loki/pkg/logql/syntax"
+)
+
+const partTimeFormat = "20060102T150405Z"
+
+// parallelJob is the query of a part of the time range of a parallel query.
+type parallelJob struct {
+	start, end time.Time
+	part       *PartFile
+	done       chan struct{}
+	err        error
+}
+
+// DoQueryParallel splits the query range in parts of ParallelDuration, which are downloaded concurrently
+// by at most ParallelMaxWorkers workers, each part to its own part file. Parts completed by a previous
+// run are skipped unless OverwriteCompleted is set. With MergeParts, the parts are written to merged in
+// the order of the query as soon as they complete, and removed unless KeepParts is set.
+func (q *Query) DoQueryParallel(c client.Client, out output.LogOutput, merged io.Writer, statistics bool) {
+	if err := q.validateParallel(); err != nil {
+		log.Fatalf("Query failed: %+v", err)
+	}
+
+	jobs := q.parallelJobs()
+	queue := make(chan *parallelJob)
+	go func() {
+		defer close(queue)
+		for _, job := range jobs {
+			queue <- job
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.ParallelMaxWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				job.err = q.runParallelJob(c, out, job, statistics)
+				close(job.done)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		<-job.done
+		if job.err != nil {
+			log.Fatalf("Query of part %s failed: %+v", job.part.finalName, job.err)
+		}
+		if !q.MergeParts {
+			continue
+		}
+		if err := mergePart(job.part.finalName, merged, q.KeepParts); err != nil {
+			log.Fatalf("Merging part %s failed: %+v", job.part.finalName, err)
+		}
+	}
+	wg.Wait()
+}
+
+func (q *Query) validateParallel() error {
+	if q.isInstant() {
+		return errors.New("parallel mode is not supported for instant queries")
+	}
+	if q.ParallelDuration < time.Second {
+		return fmt.Errorf("parallel duration must be at least 1s, got %s", q.ParallelDuration)
+	}
+	if q.ParallelMaxWorkers < 1 {
+		return fmt.Errorf("parallel max workers must be at least 1, got %d", q.ParallelMaxWorkers)
+	}
+	expr, err := syntax.ParseExpr(q.QueryString)
+	if err != nil {
+		return err
+	}
+	if _, ok := expr.(syntax.LogSelectorExpr); !ok {
+		return errors.New("parallel mode is only supported for log queries")
+	}
+	return nil
+}
+
+// parallelJobs splits the query range in parts of ParallelDuration, ordered in the direction of the query.
+func (q *Query) parallelJobs() []*parallelJob {
+	prefix := q.PartPathPrefix
+	if prefix == "" {
+		// Default to a prefix derived from the query, so that running the same query again resumes the download.
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(q.QueryString))
+		prefix = filepath.Join(os.TempDir(), fmt.Sprintf("logcli_%x", h.Sum32()))
+	}
+
+	var jobs []*parallelJob
+	for start := q.Start; start.Before(q.End); start = start.Add(q.ParallelDuration) {
+		end := start.Add(q.ParallelDuration)
+		if end.After(q.End) {
+			end = q.End
+		}
+		name := fmt.Sprintf("%s_%s_%s.part", prefix, start.UTC().Format(partTimeFormat), end.UTC().Format(partTimeFormat))
+		job := &parallelJob{
+			start: start,
+			end:   end,
+			part:  NewPartFile(name),
+			done:  make(chan struct{}),
+		}
+		if q.Forward {
+			jobs = append(jobs, job)
+		} else {
+			jobs = append([]*parallelJob{job}, jobs...)
+		}
+	}
+	return jobs
+}
+
+// runParallelJob downloads the part of job, unless it was already completed.
+func (q *Query) runParallelJob(c client.Client, out output.LogOutput, job *parallelJob, statistics bool) error {
+	exists, err := job.part.Exists()
+	if err != nil {
+		return err
+	}
+	if exists && !q.OverwriteCompleted {
+		if !q.Quiet {
+			log.Printf("Skipping completed part %s\n", job.part.finalName)
+		}
+		return nil
+	}
+
+	if err := job.part.CreateTempFile(); err != nil {
+		return err
+	}
+	defer job.part.Close()
+
+	partQuery := *q
+	partQuery.Start = job.start
+	partQuery.End = job.end
+	partQuery.DoQuery(c, out.WithWriter(job.part), statistics)
+
+	return job.part.Finalize()
+}
+
+// mergePart copies the part file at path to w, removing it afterwards unless keep is set.
+func mergePart(path string, w io.Writer, keep bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || keep {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logcli/output"
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func newParallelTestQuery(t *testing.T, forward bool) (*Query, *testQueryClient) {
+	var entries []logproto.Entry
+	for i := 0; i < 10; i++ {
+		entries = append(entries, logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: fmt.Sprintf("line%d", i)})
+	}
+	tc := newTestQueryClient(logproto.Stream{Labels: `{test="parallel"}`, Entries: entries})
+	return &Query{
+		QueryString:        `{test="parallel"}`,
+		Start:              time.Unix(0, 0),
+		End:                time.Unix(10, 0),
+		Limit:              100,
+		BatchSize:          100,
+		Forward:            forward,
+		Quiet:              true,
+		ParallelDuration:   3 * time.Second,
+		ParallelMaxWorkers: 2,
+		PartPathPrefix:     filepath.Join(t.TempDir(), "export"),
+		MergeParts:         true,
+	}, tc
+}
+
+func TestDoQueryParallel(t *testing.T) {
+	for _, forward := range []bool{true, false} {
+		q, tc := newParallelTestQuery(t, forward)
+
+		var merged bytes.Buffer
+		q.DoQueryParallel(tc, output.NewRaw(nil, nil), &merged, false)
+
+		expected := []string{"line0", "line1", "line2", "line3", "line4", "line5", "line6", "line7", "line8", "line9"}
+		if !forward {
+			for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+				expected[i], expected[j] = expected[j], expected[i]
+			}
+		}
+		require.Equal(t, expected, strings.Fields(merged.String()))
+
+		// merged parts are removed.
+		parts, err := filepath.Glob(q.PartPathPrefix + "*")
+		require.NoError(t, err)
+		require.Empty(t, parts)
+	}
+}
+
+func TestDoQueryParallel_SkipCompletedParts(t *testing.T) {
+	q, tc := newParallelTestQuery(t, true)
+	q.MergeParts = false
+
+	q.DoQueryParallel(tc, output.NewRaw(nil, nil), nil, false)
+	parts, err := filepath.Glob(q.PartPathPrefix + "*.part")
+	require.NoError(t, err)
+	require.Len(t, parts, 4)
+	calls := tc.queryRangeCalls
+
+	// a second run only downloads the parts which weren't completed.
+	require.NoError(t, os.Remove(parts[0]))
+	q.DoQueryParallel(tc, output.NewRaw(nil, nil), nil, false)
+	require.Equal(t, 2, tc.queryRangeCalls-calls)
+
+	b, err := os.ReadFile(parts[0])
+	require.NoError(t, err)
+	require.Equal(t, "line0\nline1\nline2\n", string(b))
+}
+
+func TestParallelJobs(t *testing.T) {
+	q := &Query{
+		Start:            time.Unix(0, 0),
+		End:              time.Unix(7200, 0),
+		ParallelDuration: time.Hour,
+		PartPathPrefix:   "export",
+	}
+	jobs := q.parallelJobs()
+	require.Len(t, jobs, 2)
+	require.Equal(t, "export_19700101T010000Z_19700101T020000Z.part", jobs[0].part.finalName)
+	require.Equal(t, "export_19700101T000000Z_19700101T010000Z.part", jobs[1].part.finalName)
+
+	q.Forward = true
+	jobs = q.parallelJobs()
+	require.Equal(t, time.Unix(0, 0), jobs[0].start)
+	require.Equal(t, time.Unix(3600, 0), jobs[0].end)
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// PartFile is the file a part of a parallel query is written to. The part is written to a temporary
+// file first, which is renamed once the part is complete, so that interrupted parts are downloaded again.
+type PartFile struct {
+	finalName string
+	fd        *os.File
+	mtx       sync.Mutex
+}
+
+// NewPartFile creates a part file that is completed at path finalName.
+func NewPartFile(finalName string) *PartFile {
+	return &PartFile{finalName: finalName}
+}
+
+// Exists checks whether the part was already completed.
+func (f *PartFile) Exists() (bool, error) {
+	_, err := os.Stat(f.finalName)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// CreateTempFile creates the temporary file the part is written to.
+func (f *PartFile) CreateTempFile() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	fd, err := os.Create(f.finalName + ".tmp")
+	if err != nil {
+		return err
+	}
+	f.fd = fd
+	return nil
+}
+
+// Write writes b to the temporary file.
+func (f *PartFile) Write(b []byte) (int, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.fd == nil {
+		return 0, fmt.Errorf("part file %s is not open", f.finalName)
+	}
+	return f.fd.Write(b)
+}
+
+// Close closes the temporary file without completing the part.
+func (f *PartFile) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	return f.close()
+}
+
+func (f *PartFile) close() error {
+	if f.fd == nil {
+		return nil
+	}
+	err := f.fd.Close()
+	f.fd = nil
+	return err
+}
+
+// Finalize closes the temporary file and renames it to the final name of the part.
+func (f *PartFile) Finalize() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.fd == nil {
+		return fmt.Errorf("part file %s is not open", f.finalName)
+	}
+	tmpName := f.fd.Name()
+	if err := f.fd.Sync(); err != nil {
+		return err
+	}
+	if err := f.close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, f.finalName)
+}
@@ -61,6 +61,14 @@ type Query struct {
 	ColoredOutput          bool
 	LocalConfig            string
 	FetchSchemaFromStorage bool
+
+	// Parallel download of the query range, see DoQueryParallel.
+	ParallelDuration   time.Duration
+	ParallelMaxWorkers int
+	PartPathPrefix     string
+	OverwriteCompleted bool
+	MergeParts         bool
+	KeepParts          bool
 }
 
 // DoQuery executes the query and prints out the results
@@ -272,6 +280,7 @@ func GetObjectClient(conf loki.Config, cm storage.ClientMetrics) (chunk.ObjectCl
 	oc, err := storage.NewObjectClient(
 		conf.StorageConfig.BoltDBShipperConfig.SharedStoreType,
 		conf.StorageConfig,
+		nil,
 		cm,
 	)
 	if err != nil {
@@ -316,6 +325,9 @@ func (q *Query) isInstant() bool {
 
 func (q *Query) printStream(streams loghttp.Streams, out output.LogOutput, lastEntry []*loghttp.Entry) (int, []*loghttp.Entry) {
 	common := commonLabels(streams)
+	if output.KeepsCommonLabels(out) {
+		common = loghttp.LabelSet{}
+	}
 
 	// Remove the labels we want to show from common
 	if len(q.ShowLabelsKey) > 0 {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -160,6 +161,30 @@ func Test_subtract(t *testing.T) {
 	}
 }
 
+func Test_printStreamKeepsCommonLabels(t *testing.T) {
+	streams := func() loghttp.Streams {
+		return loghttp.Streams{
+			{Labels: loghttp.LabelSet{"app": "foo", "pod": "a"}, Entries: []loghttp.Entry{{Timestamp: time.Unix(0, 1), Line: "line1"}}},
+			{Labels: loghttp.LabelSet{"app": "foo", "pod": "b"}, Entries: []loghttp.Entry{{Timestamp: time.Unix(0, 2), Line: "line2"}}},
+		}
+	}
+	q := &Query{Forward: true, Quiet: true}
+
+	var buf bytes.Buffer
+	out, err := output.NewLogOutput(&buf, "jsonl", &output.LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+	q.printStream(streams(), out, nil)
+	require.Equal(t, `{"labels":{"pod":"a"},"line":"line1","timestamp":"1970-01-01T00:00:00.000000001Z"}`+"\n"+
+		`{"labels":{"pod":"b"},"line":"line2","timestamp":"1970-01-01T00:00:00.000000002Z"}`+"\n", buf.String())
+
+	buf.Reset()
+	out, err = output.NewLogOutput(&buf, "ndjson", &output.LogOutputOptions{Timezone: time.UTC})
+	require.NoError(t, err)
+	q.printStream(streams(), out, nil)
+	require.Equal(t, `{"timestamp":"1","labels":{"app":"foo","pod":"a"},"line":"line1"}`+"\n"+
+		`{"timestamp":"2","labels":{"app":"foo","pod":"b"},"line":"line2"}`+"\n", buf.String())
+}
+
 func Test_batch(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -505,6 +530,7 @@ func mustParseLabels(t *testing.T, s string) loghttp.LabelSet {
 
 type testQueryClient struct {
 	engine          *logql.Engine
+	mtx             sync.Mutex
 	queryRangeCalls int
 	orgID           string
 }
@@ -545,7 +571,9 @@ func (t *testQueryClient) QueryRange(queryStr string, limit int, from, through t
 			Statistics: v.Statistics,
 		},
 	}
+	t.mtx.Lock()
 	t.queryRangeCalls++
+	t.mtx.Unlock()
 	return q, nil
 }
 
@@ -561,6 +589,10 @@ func (t *testQueryClient) Series(matchers []string, from, through time.Time, qui
 	panic("implement me")
 }
 
+func (t *testQueryClient) GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error) {
+	panic("implement me")
+}
+
 func (t *testQueryClient) LiveTailQueryConn(queryStr string, delayFor time.Duration, limit int, start time.Time, quiet bool) (*websocket.Conn, error) {
 	panic("implement me")
 }
@@ -0,0 +1,112 @@
+package seriesquery
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+const (
+	// Labels with at least minDropSuggestionValues unique values, having a distinct value in at least
+	// dropSuggestionRatio of their streams, are suggested to be dropped.
+	minDropSuggestionValues = 10
+	dropSuggestionRatio     = 0.5
+)
+
+type streamDetails struct {
+	labels loghttp.LabelSet
+	chunks uint64
+	bytes  uint64
+}
+
+// analyzeCardinality prints a cardinality report of streams: the label names with the most unique values,
+// the streams with the most chunks and the labels which could be dropped to reduce the size of the index.
+func (q *SeriesQuery) analyzeCardinality(w io.Writer, c client.Client, streams []loghttp.LabelSet) {
+	lds := analyzeLabels(streams)
+
+	fmt.Fprintln(w, "Total Streams: ", len(streams))
+	fmt.Fprintln(w, "Unique Labels: ", len(lds))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Top Labels By Unique Values:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Label Name\tUnique Values\tFound In Streams\n")
+	for _, details := range lds[:q.limit(len(lds))] {
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", details.name, len(details.uniqueVals), details.inStreams)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	if len(streams) > q.MaxStatsStreams {
+		fmt.Fprintf(w, "Skipping Top Streams By Chunks: %d streams found, more than the maximum of %d.\n", len(streams), q.MaxStatsStreams)
+	} else {
+		sds := q.streamDetails(c, streams)
+		fmt.Fprintln(w, "Top Streams By Chunks:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Stream\tChunks\tBytes\n")
+		for _, details := range sds[:q.limit(len(sds))] {
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", details.labels, details.chunks, details.bytes)
+		}
+		tw.Flush()
+	}
+	fmt.Fprintln(w)
+
+	suggestions := dropSuggestions(lds)
+	if len(suggestions) == 0 {
+		fmt.Fprintln(w, "No labels to drop found.")
+		return
+	}
+	fmt.Fprintln(w, "Suggested Labels To Drop:")
+	for _, suggestion := range suggestions {
+		fmt.Fprintln(w, suggestion)
+	}
+}
+
+// streamDetails returns the chunks and bytes of each stream, sorted by decreasing number of chunks.
+func (q *SeriesQuery) streamDetails(c client.Client, streams []loghttp.LabelSet) []*streamDetails {
+	sds := make([]*streamDetails, 0, len(streams))
+	for _, stream := range streams {
+		stats, err := c.GetStats(stream.String(), q.Start, q.End, q.Quiet)
+		if err != nil {
+			log.Fatalf("Error doing request: %+v", err)
+		}
+		sds = append(sds, &streamDetails{
+			labels: stream,
+			chunks: stats.Chunks,
+			bytes:  stats.Bytes,
+		})
+	}
+	sort.SliceStable(sds, func(i, j int) bool {
+		return sds[i].chunks > sds[j].chunks
+	})
+	return sds
+}
+
+// dropSuggestions returns a suggestion for each label whose values are close to unique per stream.
+func dropSuggestions(lds []*labelDetails) []string {
+	var suggestions []string
+	for _, details := range lds {
+		uniqueVals := len(details.uniqueVals)
+		if uniqueVals < minDropSuggestionValues || float64(uniqueVals) < dropSuggestionRatio*float64(details.inStreams) {
+			continue
+		}
+		suggestions = append(suggestions, fmt.Sprintf(
+			"- %s: %d unique values in %d streams, consider dropping it or moving it to the log line.",
+			details.name, uniqueVals, details.inStreams,
+		))
+	}
+	return suggestions
+}
+
+// limit returns the number of the n items of a report section to print.
+func (q *SeriesQuery) limit(n int) int {
+	if q.Top > 0 && n > q.Top {
+		return q.Top
+	}
+	return n
+}
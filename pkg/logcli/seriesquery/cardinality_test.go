@@ -0,0 +1,65 @@
+package seriesquery
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logcli/client"
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+type statsClient struct {
+	client.Client
+	chunks map[string]uint64
+}
+
+func (c *statsClient) GetStats(queryStr string, _, _ time.Time, _ bool) (*logproto.IndexStatsResponse, error) {
+	return &logproto.IndexStatsResponse{Streams: 1, Chunks: c.chunks[queryStr], Bytes: 10 * c.chunks[queryStr]}, nil
+}
+
+func TestAnalyzeCardinality(t *testing.T) {
+	var streams []loghttp.LabelSet
+	chunks := map[string]uint64{}
+	for i := 0; i < 12; i++ {
+		stream := loghttp.LabelSet{"app": "foo", "request_id": fmt.Sprintf("%02d", i)}
+		streams = append(streams, stream)
+		chunks[stream.String()] = uint64(i)
+	}
+
+	var buf bytes.Buffer
+	q := &SeriesQuery{Top: 2, MaxStatsStreams: 100}
+	q.analyzeCardinality(&buf, &statsClient{chunks: chunks}, streams)
+
+	require.Equal(t, `Total Streams:  12
+Unique Labels:  2
+
+Top Labels By Unique Values:
+Label Name  Unique Values  Found In Streams
+request_id  12             12
+app         1              12
+
+Top Streams By Chunks:
+Stream                        Chunks  Bytes
+{app="foo", request_id="11"}  11      110
+{app="foo", request_id="10"}  10      100
+
+Suggested Labels To Drop:
+- request_id: 12 unique values in 12 streams, consider dropping it or moving it to the log line.
+`, buf.String())
+}
+
+func TestAnalyzeCardinality_MaxStatsStreams(t *testing.T) {
+	streams := []loghttp.LabelSet{{"app": "foo"}, {"app": "bar"}}
+
+	var buf bytes.Buffer
+	q := &SeriesQuery{MaxStatsStreams: 1}
+	q.analyzeCardinality(&buf, &statsClient{}, streams)
+
+	require.Contains(t, buf.String(), "Skipping Top Streams By Chunks: 2 streams found, more than the maximum of 1.")
+	require.Contains(t, buf.String(), "No labels to drop found.")
+}
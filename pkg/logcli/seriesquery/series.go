@@ -19,6 +19,13 @@ type SeriesQuery struct {
 	End           time.Time
 	AnalyzeLabels bool
 	Quiet         bool
+
+	// AnalyzeCardinality prints a cardinality report of the series instead of the series themselves.
+	AnalyzeCardinality bool
+	// Top is the number of label names and streams listed in the cardinality report.
+	Top int
+	// MaxStatsStreams is the maximum number of streams whose chunks are counted in the cardinality report.
+	MaxStatsStreams int
 }
 
 type labelDetails struct {
@@ -31,34 +38,14 @@ type labelDetails struct {
 func (q *SeriesQuery) DoSeries(c client.Client) {
 	streams := q.GetSeries(c)
 
-	if q.AnalyzeLabels {
-		labelMap := map[string]*labelDetails{}
-
-		for _, stream := range streams {
-			for labelName, labelValue := range stream {
-				if _, ok := labelMap[labelName]; ok {
-					labelMap[labelName].inStreams++
-					labelMap[labelName].uniqueVals[labelValue] = struct{}{}
-				} else {
-					labelMap[labelName] = &labelDetails{
-						name:       labelName,
-						inStreams:  1,
-						uniqueVals: map[string]struct{}{labelValue: {}},
-					}
-				}
-			}
-		}
-
-		lds := make([]*labelDetails, 0, len(labelMap))
-		for _, ld := range labelMap {
-			lds = append(lds, ld)
-		}
-		sort.Slice(lds, func(ld1, ld2 int) bool {
-			return len(lds[ld1].uniqueVals) > len(lds[ld2].uniqueVals)
-		})
+	switch {
+	case q.AnalyzeCardinality:
+		q.analyzeCardinality(os.Stdout, c, streams)
+	case q.AnalyzeLabels:
+		lds := analyzeLabels(streams)
 
 		fmt.Println("Total Streams: ", len(streams))
-		fmt.Println("Unique Labels: ", len(labelMap))
+		fmt.Println("Unique Labels: ", len(lds))
 		fmt.Println()
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
@@ -67,13 +54,43 @@ func (q *SeriesQuery) DoSeries(c client.Client) {
 			fmt.Fprintf(w, "%v\t%v\t%v\n", details.name, len(details.uniqueVals), details.inStreams)
 		}
 		w.Flush()
-
-	} else {
+	default:
 		for _, value := range streams {
 			fmt.Println(value)
 		}
 	}
+}
 
+// analyzeLabels returns the details of the labels of streams, sorted by decreasing number of unique values.
+func analyzeLabels(streams []loghttp.LabelSet) []*labelDetails {
+	labelMap := map[string]*labelDetails{}
+
+	for _, stream := range streams {
+		for labelName, labelValue := range stream {
+			if _, ok := labelMap[labelName]; ok {
+				labelMap[labelName].inStreams++
+				labelMap[labelName].uniqueVals[labelValue] = struct{}{}
+			} else {
+				labelMap[labelName] = &labelDetails{
+					name:       labelName,
+					inStreams:  1,
+					uniqueVals: map[string]struct{}{labelValue: {}},
+				}
+			}
+		}
+	}
+
+	lds := make([]*labelDetails, 0, len(labelMap))
+	for _, ld := range labelMap {
+		lds = append(lds, ld)
+	}
+	sort.Slice(lds, func(ld1, ld2 int) bool {
+		if len(lds[ld1].uniqueVals) == len(lds[ld2].uniqueVals) {
+			return lds[ld1].name < lds[ld2].name
+		}
+		return len(lds[ld1].uniqueVals) > len(lds[ld2].uniqueVals)
+	})
+	return lds
 }
 
 // GetSeries returns an array of label sets
@@ -8,16 +8,21 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/modern-go/reflect2"
+
+	"github.com/grafana/loki/pkg/logproto"
 )
 
 func init() {
 	jsoniter.RegisterExtension(&jsonExtension{})
 }
 
-// Entry represents a log entry.  It includes a log message and the time it occurred at.
+// Entry represents a log entry.  It includes a log message, the time it occurred at
+// and optionally its non-indexed labels.
+// Its memory layout must match logproto.Entry, which it is cast to and from.
 type Entry struct {
-	Timestamp time.Time
-	Line      string
+	Timestamp        time.Time
+	Line             string
+	NonIndexedLabels []logproto.LabelAdapter
 }
 
 func (e *Entry) UnmarshalJSON(data []byte) error {
@@ -26,8 +31,9 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 		parseError error
 	)
 	_, err := jsonparser.ArrayEach(data, func(value []byte, t jsonparser.ValueType, _ int, _ error) {
-		// assert that both items in array are of type string
-		if t != jsonparser.String {
+		// assert that the timestamp and the line are of type string
+		// and the optional non-indexed labels of type object.
+		if (i < 2 && t != jsonparser.String) || (i == 2 && t != jsonparser.Object) {
 			parseError = jsonparser.MalformedStringError
 			return
 		}
@@ -46,6 +52,22 @@ func (e *Entry) UnmarshalJSON(data []byte) error {
 				return
 			}
 			e.Line = v
+		case 2: // non-indexed labels
+			err := jsonparser.ObjectEach(value, func(key, val []byte, t jsonparser.ValueType, _ int) error {
+				if t != jsonparser.String {
+					return jsonparser.MalformedStringError
+				}
+				v, err := jsonparser.ParseString(val)
+				if err != nil {
+					return err
+				}
+				e.NonIndexedLabels = append(e.NonIndexedLabels, logproto.LabelAdapter{Name: string(key), Value: v})
+				return nil
+			})
+			if err != nil {
+				parseError = err
+				return
+			}
 		}
 		i++
 	})
@@ -67,6 +89,7 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 		i := 0
 		var ts time.Time
 		var line string
+		var nonIndexedLabels []logproto.LabelAdapter
 		ok := iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
 			var ok bool
 			switch i {
@@ -81,15 +104,27 @@ func (sliceEntryDecoder) Decode(ptr unsafe.Pointer, iter *jsoniter.Iterator) {
 					return false
 				}
 				return true
+			case 2:
+				iter.ReadMapCB(func(iter *jsoniter.Iterator, name string) bool {
+					value := iter.ReadString()
+					if iter.Error != nil {
+						return false
+					}
+					nonIndexedLabels = append(nonIndexedLabels, logproto.LabelAdapter{Name: name, Value: value})
+					return true
+				})
+				i++
+				return iter.Error == nil
 			default:
-				iter.ReportError("error reading entry", "array must contains 2 values")
+				iter.ReportError("error reading entry", "array must contains 2 or 3 values")
 				return false
 			}
 		})
 		if ok {
 			*((*[]Entry)(ptr)) = append(*((*[]Entry)(ptr)), Entry{
-				Timestamp: ts,
-				Line:      line,
+				Timestamp:        ts,
+				Line:             line,
+				NonIndexedLabels: nonIndexedLabels,
 			})
 			return true
 		}
@@ -126,6 +161,18 @@ func (entryEncoder) Encode(ptr unsafe.Pointer, stream *jsoniter.Stream) {
 	stream.WriteRaw(`"`)
 	stream.WriteMore()
 	stream.WriteStringWithHTMLEscaped(e.Line)
+	if len(e.NonIndexedLabels) > 0 {
+		stream.WriteMore()
+		stream.WriteObjectStart()
+		for i, l := range e.NonIndexedLabels {
+			if i > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField(l.Name)
+			stream.WriteStringWithHTMLEscaped(l.Value)
+		}
+		stream.WriteObjectEnd()
+	}
 	stream.WriteArrayEnd()
 }
 
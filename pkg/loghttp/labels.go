@@ -16,6 +16,8 @@ import (
 type LabelResponse struct {
 	Status string   `json:"status"`
 	Data   []string `json:"data,omitempty"`
+	// NextPageToken is the token of the next page of a paged query, empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 // LabelSet is a key/value pair mapping of labels
@@ -82,5 +84,10 @@ func ParseLabelQuery(r *http.Request) (*logproto.LabelRequest, error) {
 	}
 	req.Start = &start
 	req.End = &end
+
+	req.Limit, req.PageToken, err = page(r)
+	if err != nil {
+		return nil, err
+	}
 	return req, nil
 }
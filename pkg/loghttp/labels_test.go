@@ -41,6 +41,17 @@ func TestParseLabelQuery(t *testing.T) {
 				Start:  timePtr(time.Date(2017, 06, 10, 21, 42, 24, 760738998, time.UTC)),
 				End:    timePtr(time.Date(2017, 07, 10, 21, 42, 24, 760738998, time.UTC)),
 			}, false},
+		{"good with page",
+			&http.Request{
+				URL: mustParseURL(`?start=2017-06-10T21:42:24.760738998Z&end=2017-07-10T21:42:24.760738998Z&limit=10&page_token=Zm9v`),
+			}, &logproto.LabelRequest{
+				Start:     timePtr(time.Date(2017, 06, 10, 21, 42, 24, 760738998, time.UTC)),
+				End:       timePtr(time.Date(2017, 07, 10, 21, 42, 24, 760738998, time.UTC)),
+				Limit:     10,
+				PageToken: "Zm9v",
+			}, false},
+		{"bad limit", &http.Request{URL: mustParseURL(`?limit=-1`)}, nil, true},
+		{"bad page token", &http.Request{URL: mustParseURL(`?page_token=not%20a%20token`)}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 const (
@@ -30,6 +31,25 @@ func limit(r *http.Request) (uint32, error) {
 	return uint32(l), nil
 }
 
+// page parses the optional limit and page_token parameters of the label and series queries.
+// Without a limit all the values are returned at once.
+func page(r *http.Request) (uint32, string, error) {
+	l, err := parseInt(r.Form.Get("limit"), 0)
+	if err != nil {
+		return 0, "", err
+	}
+	if l < 0 {
+		return 0, "", errors.New("limit must be a positive value")
+	}
+	token := r.Form.Get("page_token")
+	if token != "" {
+		if _, err := paging.After(token); err != nil {
+			return 0, "", err
+		}
+	}
+	return uint32(l), token, nil
+}
+
 func query(r *http.Request) string {
 	return r.Form.Get("query")
 }
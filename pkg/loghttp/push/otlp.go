@@ -0,0 +1,343 @@
+package push
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/prometheus/model/labels"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/util"
+)
+
+const (
+	applicationProtobuf = "application/x-protobuf"
+
+	// unknownServiceName is the service name of streams without any label, as
+	// mandated by the OpenTelemetry semantic conventions.
+	unknownServiceName = "unknown_service"
+)
+
+// DefaultOTLPResourceAttributesAsLabels are the resource attributes turned into
+// stream labels by default. They identify where logs come from without being
+// too high cardinality.
+var DefaultOTLPResourceAttributesAsLabels = []string{
+	"service.name",
+	"service.namespace",
+	"service.instance.id",
+	"deployment.environment",
+	"cloud.region",
+	"cloud.availability_zone",
+	"k8s.cluster.name",
+	"k8s.namespace.name",
+	"k8s.pod.name",
+	"k8s.container.name",
+	"container.name",
+	"k8s.replicaset.name",
+	"k8s.deployment.name",
+	"k8s.statefulset.name",
+	"k8s.daemonset.name",
+	"k8s.cronjob.name",
+	"k8s.job.name",
+}
+
+// OTLPConfig is the policy mapping the attributes of OTLP logs to Loki labels.
+//
+// The listed resource and scope attributes become stream labels, with the
+// characters not allowed in label names replaced by underscores. All the other
+// attributes, the scope name, the severity text and the trace and span ids are
+// attached to the entries as non-indexed labels when the tenant allows them,
+// and are dropped otherwise.
+type OTLPConfig struct {
+	ResourceAttributesAsLabels flagext.StringSliceCSV `yaml:"resource_attributes_as_labels"`
+	ScopeAttributesAsLabels    flagext.StringSliceCSV `yaml:"scope_attributes_as_labels"`
+}
+
+// RegisterFlagsWithPrefix registers the flags.
+func (cfg *OTLPConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.ResourceAttributesAsLabels = DefaultOTLPResourceAttributesAsLabels
+	f.Var(&cfg.ResourceAttributesAsLabels, prefix+".resource-attributes-as-labels", "Comma separated list of OTLP resource attributes turned into stream labels.")
+	f.Var(&cfg.ScopeAttributesAsLabels, prefix+".scope-attributes-as-labels", "Comma separated list of OTLP instrumentation scope attributes turned into stream labels.")
+}
+
+// ParseOTLPRequest parses an OTLP/HTTP logs request, encoded either as protobuf
+// or JSON, into a push request according to the attribute-to-label policy.
+// The request fails if its body is larger than maxDecompressedSize bytes once
+// decompressed, 0 meaning no limit.
+func ParseOTLPRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention, cfg OTLPConfig, allowNonIndexedLabels bool, maxDecompressedSize int) (*logproto.PushRequest, error) {
+	return parseRequest(logger, userID, r, tenantsRetention, maxDecompressedSize, func(r *http.Request, body io.Reader, contentType string, maxSize int) (*logproto.PushRequest, error) {
+		var req collogspb.ExportLogsServiceRequest
+		switch contentType {
+		case applicationJSON:
+			b, err := io.ReadAll(body)
+			if err != nil {
+				return nil, err
+			}
+			if err := UnmarshalOTLPJSON(b, &req); err != nil {
+				return nil, err
+			}
+		case applicationProtobuf:
+			// unlike the Loki push API, the OTLP protobuf is only snappy-compressed when the client says so.
+			compression := util.NoCompression
+			if r.Header.Get(contentEnc) == "snappy" {
+				compression = util.RawSnappy
+			}
+			if err := util.ParseProtoReader(r.Context(), body, int(r.ContentLength), maxSize, &req, compression); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("Content-Type %q not supported, must be %q or %q", contentType, applicationProtobuf, applicationJSON)
+		}
+		return OTLPToPushRequest(&req, cfg, allowNonIndexedLabels, time.Now()), nil
+	})
+}
+
+// OTLPToPushRequest converts OTLP logs into a push request. Records are grouped
+// into one stream per distinct set of labels. Records without any timestamp
+// are given now.
+func OTLPToPushRequest(req *collogspb.ExportLogsServiceRequest, cfg OTLPConfig, allowNonIndexedLabels bool, now time.Time) *logproto.PushRequest {
+	var (
+		streams       []logproto.Stream
+		streamIndexes = map[string]int{}
+	)
+
+	for _, rl := range req.GetResourceLogs() {
+		resourceLabels, resourceNonIndexed := attributesToLabels(rl.GetResource().GetAttributes(), cfg.ResourceAttributesAsLabels)
+
+		for _, sl := range rl.GetScopeLogs() {
+			scopeLabels, scopeNonIndexed := attributesToLabels(sl.GetScope().GetAttributes(), cfg.ScopeAttributesAsLabels)
+			if name := sl.GetScope().GetName(); name != "" {
+				scopeNonIndexed = append(scopeNonIndexed, logproto.LabelAdapter{Name: "scope_name", Value: name})
+			}
+
+			lbs := streamLabels(resourceLabels, scopeLabels)
+			idx, ok := streamIndexes[lbs]
+			if !ok {
+				idx = len(streams)
+				streamIndexes[lbs] = idx
+				streams = append(streams, logproto.Stream{Labels: lbs})
+			}
+
+			for _, lr := range sl.GetLogRecords() {
+				entry := logproto.Entry{
+					Timestamp: recordTimestamp(lr, now),
+					Line:      OTLPValueString(lr.GetBody()),
+				}
+				if allowNonIndexedLabels {
+					entry.NonIndexedLabels = recordNonIndexedLabels(lr, resourceNonIndexed, scopeNonIndexed)
+				}
+				streams[idx].Entries = append(streams[idx].Entries, entry)
+			}
+		}
+	}
+
+	return &logproto.PushRequest{Streams: streams}
+}
+
+// attributesToLabels splits attributes into the ones listed in asLabels and the others.
+func attributesToLabels(attrs []*commonpb.KeyValue, asLabels []string) (labels.Labels, []logproto.LabelAdapter) {
+	var (
+		lbs        labels.Labels
+		nonIndexed []logproto.LabelAdapter
+	)
+	for _, attr := range attrs {
+		value := OTLPValueString(attr.GetValue())
+		if value == "" {
+			continue
+		}
+		name := otlpLabelName(attr.GetKey())
+		if util.StringsContain(asLabels, attr.GetKey()) {
+			lbs = append(lbs, labels.Label{Name: name, Value: value})
+			continue
+		}
+		nonIndexed = append(nonIndexed, logproto.LabelAdapter{Name: name, Value: value})
+	}
+	return lbs, nonIndexed
+}
+
+// streamLabels returns the string representation of the labels of a stream,
+// with scope labels overriding resource labels of the same name.
+func streamLabels(resource, scope labels.Labels) string {
+	b := labels.NewBuilder(resource)
+	for _, l := range scope {
+		b.Set(l.Name, l.Value)
+	}
+	lbs := b.Labels(nil)
+	if len(lbs) == 0 {
+		lbs = labels.Labels{{Name: "service_name", Value: unknownServiceName}}
+	}
+	// The builder returns the resource labels as is when there is no scope label.
+	sort.Sort(lbs)
+	return lbs.String()
+}
+
+func recordTimestamp(lr *logspb.LogRecord, now time.Time) time.Time {
+	switch {
+	case lr.GetTimeUnixNano() != 0:
+		return time.Unix(0, int64(lr.GetTimeUnixNano())).UTC()
+	case lr.GetObservedTimeUnixNano() != 0:
+		return time.Unix(0, int64(lr.GetObservedTimeUnixNano())).UTC()
+	default:
+		return now.UTC()
+	}
+}
+
+func recordNonIndexedLabels(lr *logspb.LogRecord, resource, scope []logproto.LabelAdapter) []logproto.LabelAdapter {
+	_, attrs := attributesToLabels(lr.GetAttributes(), nil)
+
+	nonIndexed := make([]logproto.LabelAdapter, 0, len(resource)+len(scope)+len(attrs)+3)
+	nonIndexed = append(nonIndexed, resource...)
+	nonIndexed = append(nonIndexed, scope...)
+	nonIndexed = append(nonIndexed, attrs...)
+	if lr.GetSeverityText() != "" {
+		nonIndexed = append(nonIndexed, logproto.LabelAdapter{Name: "severity_text", Value: lr.GetSeverityText()})
+	}
+	if len(lr.GetTraceId()) > 0 {
+		nonIndexed = append(nonIndexed, logproto.LabelAdapter{Name: "trace_id", Value: hex.EncodeToString(lr.GetTraceId())})
+	}
+	if len(lr.GetSpanId()) > 0 {
+		nonIndexed = append(nonIndexed, logproto.LabelAdapter{Name: "span_id", Value: hex.EncodeToString(lr.GetSpanId())})
+	}
+	if len(nonIndexed) == 0 {
+		return nil
+	}
+	return nonIndexed
+}
+
+// otlpLabelName turns an attribute name into a valid label name, e.g. service.name into service_name.
+func otlpLabelName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_' || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// UnmarshalOTLPJSON decodes an OTLP/JSON logs request. OTLP/JSON is the
+// protobuf JSON mapping, except for the trace and span ids of the log records
+// which are hex instead of base64 encoded. Unknown fields are ignored.
+func UnmarshalOTLPJSON(b []byte, req *collogspb.ExportLogsServiceRequest) error {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// keeps the 64 bit integers exact when encoding the document back.
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	for _, rl := range jsonObjects(doc, "resourceLogs", "resource_logs") {
+		for _, sl := range jsonObjects(rl, "scopeLogs", "scope_logs") {
+			for _, lr := range jsonObjects(sl, "logRecords", "log_records") {
+				for _, field := range []string{"traceId", "trace_id", "spanId", "span_id"} {
+					id, ok := lr[field].(string)
+					if !ok {
+						continue
+					}
+					raw, err := hex.DecodeString(id)
+					if err != nil {
+						return fmt.Errorf("invalid %s %q: %w", field, id, err)
+					}
+					lr[field] = base64.StdEncoding.EncodeToString(raw)
+				}
+			}
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, req)
+}
+
+// jsonObjects returns the objects of an array field, named after either its
+// JSON or its protobuf name.
+func jsonObjects(obj map[string]interface{}, names ...string) []map[string]interface{} {
+	var objs []map[string]interface{}
+	for _, name := range names {
+		values, _ := obj[name].([]interface{})
+		for _, v := range values {
+			if o, ok := v.(map[string]interface{}); ok {
+				objs = append(objs, o)
+			}
+		}
+	}
+	return objs
+}
+
+// OTLPValueString returns the string representation of an OTLP value: bytes
+// are base64 encoded and arrays and maps JSON encoded. Empty values are
+// represented by an empty string.
+func OTLPValueString(value *commonpb.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue, *commonpb.AnyValue_KvlistValue:
+		b, err := json.Marshal(otlpRawValue(value))
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	default:
+		return ""
+	}
+}
+
+// otlpRawValue returns the value of an OTLP value as a plain Go value to JSON encode it.
+func otlpRawValue(value *commonpb.AnyValue) interface{} {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(v.ArrayValue.GetValues()))
+		for _, av := range v.ArrayValue.GetValues() {
+			values = append(values, otlpRawValue(av))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := make(map[string]interface{}, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			values[kv.GetKey()] = otlpRawValue(kv.GetValue())
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,263 @@
+package push
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/loki/pkg/logproto"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func TestOTLPToPushRequest(t *testing.T) {
+	now := time.Unix(0, 42).UTC()
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{
+			{
+				Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+					{Key: "service.name", Value: stringValue("checkout")},
+					{Key: "k8s.namespace.name", Value: stringValue("shop")},
+					{Key: "host.name", Value: stringValue("node-1")},
+				}},
+				ScopeLogs: []*logspb.ScopeLogs{
+					{
+						Scope: &commonpb.InstrumentationScope{Name: "otelzap"},
+						LogRecords: []*logspb.LogRecord{
+							{
+								TimeUnixNano: 10,
+								SeverityText: "INFO",
+								Body:         stringValue("order placed"),
+								Attributes:   []*commonpb.KeyValue{{Key: "order.id", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 7}}}},
+								TraceId:      []byte{0xab, 0xcd},
+							},
+							{ObservedTimeUnixNano: 20, Body: stringValue("order shipped")},
+						},
+					},
+					{
+						Scope:      &commonpb.InstrumentationScope{Attributes: []*commonpb.KeyValue{{Key: "tier", Value: stringValue("gold")}}},
+						LogRecords: []*logspb.LogRecord{{Body: stringValue("vip order")}},
+					},
+				},
+			},
+			{
+				ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{{TimeUnixNano: 30, Body: stringValue("anonymous")}}}},
+			},
+		},
+	}
+	cfg := OTLPConfig{
+		ResourceAttributesAsLabels: DefaultOTLPResourceAttributesAsLabels,
+		ScopeAttributesAsLabels:    []string{"tier"},
+	}
+
+	t.Run("non-indexed labels allowed", func(t *testing.T) {
+		pushReq := OTLPToPushRequest(req, cfg, true, now)
+		require.Equal(t, []logproto.Stream{
+			{
+				Labels: `{k8s_namespace_name="shop", service_name="checkout"}`,
+				Entries: []logproto.Entry{
+					{
+						Timestamp: time.Unix(0, 10).UTC(),
+						Line:      "order placed",
+						NonIndexedLabels: []logproto.LabelAdapter{
+							{Name: "host_name", Value: "node-1"},
+							{Name: "scope_name", Value: "otelzap"},
+							{Name: "order_id", Value: "7"},
+							{Name: "severity_text", Value: "INFO"},
+							{Name: "trace_id", Value: "abcd"},
+						},
+					},
+					{
+						Timestamp: time.Unix(0, 20).UTC(),
+						Line:      "order shipped",
+						NonIndexedLabels: []logproto.LabelAdapter{
+							{Name: "host_name", Value: "node-1"},
+							{Name: "scope_name", Value: "otelzap"},
+						},
+					},
+				},
+			},
+			{
+				Labels: `{k8s_namespace_name="shop", service_name="checkout", tier="gold"}`,
+				Entries: []logproto.Entry{
+					{Timestamp: now, Line: "vip order", NonIndexedLabels: []logproto.LabelAdapter{{Name: "host_name", Value: "node-1"}}},
+				},
+			},
+			{
+				Labels:  `{service_name="unknown_service"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 30).UTC(), Line: "anonymous"}},
+			},
+		}, pushReq.Streams)
+	})
+
+	t.Run("non-indexed labels disallowed", func(t *testing.T) {
+		pushReq := OTLPToPushRequest(req, cfg, false, now)
+		require.Len(t, pushReq.Streams, 3)
+		for _, s := range pushReq.Streams {
+			for _, e := range s.Entries {
+				require.Empty(t, e.NonIndexedLabels)
+			}
+		}
+	})
+}
+
+func TestParseOTLPRequest(t *testing.T) {
+	req := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{Key: "service.name", Value: stringValue("checkout")}}},
+			ScopeLogs: []*logspb.ScopeLogs{{
+				LogRecords: []*logspb.LogRecord{{TimeUnixNano: 1670000000000000000, Body: stringValue("order placed")}},
+			}},
+		}},
+	}
+	protoBody, err := proto.Marshal(req)
+	require.NoError(t, err)
+	jsonBody := `{"resourceLogs":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}}]},"scopeLogs":[{"logRecords":[{"timeUnixNano":"1670000000000000000","body":{"stringValue":"order placed"}}]}]}]}`
+
+	for _, tc := range []struct {
+		name            string
+		body            string
+		contentType     string
+		contentEncoding string
+		valid           bool
+	}{
+		{name: "protobuf", body: string(protoBody), contentType: "application/x-protobuf", valid: true},
+		{name: "gzipped protobuf", body: gzipString(string(protoBody)), contentType: "application/x-protobuf", contentEncoding: "gzip", valid: true},
+		{name: "snappy protobuf", body: string(snappy.Encode(nil, protoBody)), contentType: "application/x-protobuf", contentEncoding: "snappy", valid: true},
+		{name: "zstd protobuf", body: zstdString(string(protoBody)), contentType: "application/x-protobuf", contentEncoding: "zstd", valid: true},
+		{name: "unsupported content encoding", body: string(protoBody), contentType: "application/x-protobuf", contentEncoding: "br"},
+		{name: "invalid protobuf", body: "\x0a\x10", contentType: "application/x-protobuf"},
+		{name: "json", body: jsonBody, contentType: "application/json", valid: true},
+		{name: "invalid json", body: `{"resourceLogs":`, contentType: "application/json"},
+		{name: "unsupported content type", body: jsonBody, contentType: "text/plain"},
+		{name: "missing content type", body: jsonBody},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/otlp/v1/logs", bytes.NewReader([]byte(tc.body)))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.contentEncoding != "" {
+				r.Header.Set("Content-Encoding", tc.contentEncoding)
+			}
+
+			pushReq, err := ParseOTLPRequest(util_log.Logger, "fake", r, nil, OTLPConfig{ResourceAttributesAsLabels: DefaultOTLPResourceAttributesAsLabels}, false, 0)
+			if !tc.valid {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []logproto.Stream{{
+				Labels:  `{service_name="checkout"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1670000000000000000).UTC(), Line: "order placed"}},
+			}}, pushReq.Streams)
+		})
+	}
+}
+
+func TestUnmarshalOTLPJSON(t *testing.T) {
+	body := `{
+		"resourceLogs": [{
+			"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]},
+			"scopeLogs": [{
+				"scope": {"name": "otelzap", "version": "v0.1.0"},
+				"logRecords": [{
+					"timeUnixNano": "1670000000000000001",
+					"observedTimeUnixNano": 1670000000000000002,
+					"severityNumber": 9,
+					"severityText": "INFO",
+					"body": {"stringValue": "order placed"},
+					"attributes": [
+						{"key": "retries", "value": {"intValue": "-2"}},
+						{"key": "user", "value": {"kvlistValue": {"values": [{"key": "id", "value": {"stringValue": "42"}}]}}}
+					],
+					"traceId": "5b8efff798038103d269b633813fc60c",
+					"spanId": "eee19b7ec3c1b174",
+					"unknownField": true
+				}]
+			}],
+			"schemaUrl": "https://opentelemetry.io/schemas/1.9.0"
+		}]
+	}`
+	expected := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{Key: "service.name", Value: stringValue("checkout")}}},
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope: &commonpb.InstrumentationScope{Name: "otelzap", Version: "v0.1.0"},
+				LogRecords: []*logspb.LogRecord{{
+					TimeUnixNano:         1670000000000000001,
+					ObservedTimeUnixNano: 1670000000000000002,
+					SeverityNumber:       logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+					SeverityText:         "INFO",
+					Body:                 stringValue("order placed"),
+					Attributes: []*commonpb.KeyValue{
+						{Key: "retries", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: -2}}},
+						{Key: "user", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+							Values: []*commonpb.KeyValue{{Key: "id", Value: stringValue("42")}},
+						}}}},
+					},
+					TraceId: []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c},
+					SpanId:  []byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74},
+				}},
+			}},
+			SchemaUrl: "https://opentelemetry.io/schemas/1.9.0",
+		}},
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	require.NoError(t, UnmarshalOTLPJSON([]byte(body), &req))
+	require.True(t, proto.Equal(expected, &req), "unexpected request %v", &req)
+
+	// the ids are hex encoded, not base64 encoded as in the protobuf JSON mapping.
+	require.Error(t, UnmarshalOTLPJSON([]byte(`{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"traceId": "W47/95gDgQPSabYzgT/GDA=="}]}]}]}`), &req))
+	require.Error(t, UnmarshalOTLPJSON([]byte(`{"resourceLogs": {}}`), &req))
+}
+
+func TestOTLPValueString(t *testing.T) {
+	for _, tc := range []struct {
+		v        *commonpb.AnyValue
+		expected string
+	}{
+		{nil, ""},
+		{&commonpb.AnyValue{}, ""},
+		{stringValue("foo"), "foo"},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}, "true"},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: -2}}, "-2"},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: 0.5}}, "0.5"},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte{1, 2}}}, "AQI="},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{
+			Values: []*commonpb.AnyValue{stringValue("a"), {Value: &commonpb.AnyValue_IntValue{IntValue: 1}}},
+		}}}, `["a",1]`},
+		{&commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+			Values: []*commonpb.KeyValue{{Key: "id", Value: stringValue("42")}},
+		}}}, `{"id":"42"}`},
+	} {
+		require.Equal(t, tc.expected, OTLPValueString(tc.v))
+	}
+}
+
+func TestOTLPLabelName(t *testing.T) {
+	for in, expected := range map[string]string{
+		"service.name":        "service_name",
+		"k8s.pod.name":        "k8s_pod_name",
+		"http.response-code":  "http_response_code",
+		"3rd.party":           "_3rd_party",
+		"already_valid_Label": "already_valid_Label",
+	} {
+		require.Equal(t, expected, otlpLabelName(in))
+	}
+	require.True(t, strings.HasPrefix(otlpLabelName("é"), "_"))
+}
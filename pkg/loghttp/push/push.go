@@ -8,11 +8,13 @@ import (
 	"math"
 	"mime"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/prometheus/model/labels"
@@ -47,11 +49,37 @@ var (
 
 const applicationJSON = "application/json"
 
+// SupportedContentEncodings are the values of the Content-Encoding header of the push requests which can be decoded.
+var SupportedContentEncodings = []string{"gzip", "deflate", "snappy", "zstd"}
+
+// UnsupportedContentEncodingError is returned for push requests whose Content-Encoding can't be decoded.
+type UnsupportedContentEncodingError struct {
+	ContentEncoding string
+}
+
+func (e *UnsupportedContentEncodingError) Error() string {
+	return fmt.Sprintf("Content-Encoding %q not supported, must be one of %s", e.ContentEncoding, strings.Join(SupportedContentEncodings, ", "))
+}
+
 type TenantsRetention interface {
 	RetentionPeriodFor(userID string, lbs labels.Labels) time.Duration
 }
 
-func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention) (*logproto.PushRequest, error) {
+// requestDecoder decodes the uncompressed body of a push request with the given content type.
+// The decoded request must not be larger than maxSize bytes.
+type requestDecoder func(r *http.Request, body io.Reader, contentType string, maxSize int) (*logproto.PushRequest, error)
+
+// ParseRequest parses a push request, failing if its body is larger than maxDecompressedSize bytes once
+// decompressed. 0 means no limit.
+func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention, maxDecompressedSize int) (*logproto.PushRequest, error) {
+	return parseRequest(logger, userID, r, tenantsRetention, maxDecompressedSize, decodeLokiRequest)
+}
+
+func parseRequest(logger log.Logger, userID string, r *http.Request, tenantsRetention TenantsRetention, maxDecompressedSize int, decode requestDecoder) (*logproto.PushRequest, error) {
+	if maxDecompressedSize <= 0 {
+		maxDecompressedSize = math.MaxInt32
+	}
+
 	// Body
 	var body io.Reader
 	// bodySize should always reflect the compressed size of the request body
@@ -76,16 +104,24 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 		flateReader := flate.NewReader(bodySize)
 		defer flateReader.Close()
 		body = flateReader
+	case "zstd":
+		zstdReader, err := zstd.NewReader(bodySize, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(maxDecompressedSize)))
+		if err != nil {
+			return nil, err
+		}
+		defer zstdReader.Close()
+		body = zstdReader
 	default:
-		return nil, fmt.Errorf("Content-Encoding %q not supported", contentEncoding)
+		return nil, &UnsupportedContentEncodingError{ContentEncoding: contentEncoding}
 	}
+	// the limit is enforced while decompressing, before the whole body is read into memory.
+	body = &maxSizeReader{r: body, max: int64(maxDecompressedSize)}
 
 	contentType := r.Header.Get(contentType)
 	var (
 		entriesSize      int64
 		streamLabelsSize int64
 		totalEntries     int64
-		req              logproto.PushRequest
 	)
 
 	contentType, _ /* params */, err := mime.ParseMediaType(contentType)
@@ -93,29 +129,9 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 		return nil, err
 	}
 
-	switch contentType {
-	case applicationJSON:
-
-		var err error
-
-		// todo once https://github.com/weaveworks/common/commit/73225442af7da93ec8f6a6e2f7c8aafaee3f8840 is in Loki.
-		// We can try to pass the body as bytes.buffer instead to avoid reading into another buffer.
-		if loghttp.GetVersion(r.RequestURI) == loghttp.VersionV1 {
-			err = unmarshal.DecodePushRequest(body, &req)
-		} else {
-			err = unmarshal2.DecodePushRequest(body, &req)
-		}
-
-		if err != nil {
-			return nil, err
-		}
-
-	default:
-		// When no content-type header is set or when it is set to
-		// `application/x-protobuf`: expect snappy compression.
-		if err := util.ParseProtoReader(r.Context(), body, int(r.ContentLength), math.MaxInt32, &req, util.RawSnappy); err != nil {
-			return nil, err
-		}
+	req, err := decode(r, body, contentType, maxDecompressedSize)
+	if err != nil {
+		return nil, err
 	}
 
 	mostRecentEntry := time.Unix(0, 0)
@@ -160,5 +176,61 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 		"totalSize", humanize.Bytes(uint64(entriesSize+streamLabelsSize)),
 		"mostRecentLagMs", time.Since(mostRecentEntry).Milliseconds(),
 	)
+	return req, nil
+}
+
+func decodeLokiRequest(r *http.Request, body io.Reader, contentType string, maxSize int) (*logproto.PushRequest, error) {
+	var req logproto.PushRequest
+
+	switch contentType {
+	case applicationJSON:
+
+		var err error
+
+		// todo once https://github.com/weaveworks/common/commit/73225442af7da93ec8f6a6e2f7c8aafaee3f8840 is in Loki.
+		// We can try to pass the body as bytes.buffer instead to avoid reading into another buffer.
+		if loghttp.GetVersion(r.RequestURI) == loghttp.VersionV1 {
+			err = unmarshal.DecodePushRequest(body, &req)
+		} else {
+			err = unmarshal2.DecodePushRequest(body, &req)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		// When no content-type header is set or when it is set to
+		// `application/x-protobuf`: expect snappy compression, unless
+		// the protobuf is compressed with zstd instead.
+		compression := util.RawSnappy
+		if r.Header.Get(contentEnc) == "zstd" {
+			compression = util.NoCompression
+		}
+		if err := util.ParseProtoReader(r.Context(), body, int(r.ContentLength), maxSize, &req, compression); err != nil {
+			return nil, err
+		}
+	}
+
 	return &req, nil
 }
+
+// maxSizeReader fails once more than max bytes were read, so that a small compressed body can't be
+// decompressed into an unbounded amount of memory.
+type maxSizeReader struct {
+	r         io.Reader
+	read, max int64
+}
+
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	if r.read > r.max {
+		return 0, fmt.Errorf("push request larger than max once decompressed (%d bytes)", r.max)
+	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read > r.max {
+		// no bytes are returned along with the error, since some decoders ignore errors when bytes are read.
+		return 0, fmt.Errorf("push request larger than max once decompressed (%d bytes)", r.max)
+	}
+	return n, err
+}
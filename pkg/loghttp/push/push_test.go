@@ -8,9 +8,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/pkg/logproto"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -27,6 +32,16 @@ func gzipString(source string) string {
 	return buf.String()
 }
 
+// Zstd source string and return compressed string
+func zstdString(source string) string {
+	zw, err := zstd.NewWriter(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer zw.Close()
+	return string(zw.EncodeAll([]byte(source), nil))
+}
+
 // Deflate source string and return compressed string
 func deflateString(source string) string {
 	var buf bytes.Buffer
@@ -94,6 +109,13 @@ func TestParseRequest(t *testing.T) {
 			contentEncoding: `deflate`,
 			valid:           true,
 		},
+		{
+			path:            `/loki/api/v1/push`,
+			body:            zstdString(`{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`),
+			contentType:     `application/json`,
+			contentEncoding: `zstd`,
+			valid:           true,
+		},
 		{
 			path:            `/loki/api/v1/push`,
 			body:            gzipString(`{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`),
@@ -101,6 +123,13 @@ func TestParseRequest(t *testing.T) {
 			contentEncoding: `snappy`,
 			valid:           false,
 		},
+		{
+			path:            `/loki/api/v1/push`,
+			body:            `{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`,
+			contentType:     `application/json`,
+			contentEncoding: `br`,
+			valid:           false,
+		},
 		{
 			path:            `/loki/api/v1/push`,
 			body:            gzipString(`{"streams": [{ "stream": { "foo": "bar2" }, "values": [ [ "1570818238000000000", "fizzbuzz" ] ] }]}`),
@@ -168,7 +197,7 @@ func TestParseRequest(t *testing.T) {
 		if len(test.contentEncoding) > 0 {
 			request.Header.Add("Content-Encoding", test.contentEncoding)
 		}
-		data, err := ParseRequest(util_log.Logger, "", request, nil)
+		data, err := ParseRequest(util_log.Logger, "", request, nil, 0)
 		if test.valid {
 			assert.Nil(t, err, "Should not give error for %d", index)
 			assert.NotNil(t, data, "Should give data for %d", index)
@@ -178,3 +207,63 @@ func TestParseRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestParseRequest_Protobuf(t *testing.T) {
+	req := logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  `{foo="bar"}`,
+		Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1570818238000000000).UTC(), Line: "fizzbuzz"}},
+	}}}
+	b, err := req.Marshal()
+	require.NoError(t, err)
+
+	for contentEncoding, body := range map[string]string{
+		"":       string(snappy.Encode(nil, b)),
+		"snappy": string(snappy.Encode(nil, b)),
+		// the zstd-compressed protobuf isn't snappy-compressed as well.
+		"zstd": zstdString(string(b)),
+	} {
+		r := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-protobuf")
+		if contentEncoding != "" {
+			r.Header.Set("Content-Encoding", contentEncoding)
+		}
+		data, err := ParseRequest(util_log.Logger, "", r, nil, 0)
+		require.NoError(t, err, contentEncoding)
+		require.Equal(t, req.Streams, data.Streams, contentEncoding)
+	}
+}
+
+func TestParseRequest_MaxDecompressedSize(t *testing.T) {
+	// a small body decompressed into a large one.
+	line := strings.Repeat("a", 1<<20)
+	body := `{"streams": [{ "stream": { "foo": "bar" }, "values": [ [ "1570818238000000000", "` + line + `" ] ] }]}`
+
+	for contentEncoding, compressed := range map[string]string{
+		"gzip":    gzipString(body),
+		"deflate": deflateString(body),
+		"zstd":    zstdString(body),
+	} {
+		require.Less(t, len(compressed), 1<<16)
+
+		r := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(compressed))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", contentEncoding)
+		_, err := ParseRequest(util_log.Logger, "", r, nil, 1<<16)
+		require.Error(t, err, contentEncoding)
+
+		r = httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(compressed))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", contentEncoding)
+		data, err := ParseRequest(util_log.Logger, "", r, nil, 2<<20)
+		require.NoError(t, err, contentEncoding)
+		require.Equal(t, line, data.Streams[0].Entries[0].Line)
+	}
+
+	r := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "br")
+	_, err := ParseRequest(util_log.Logger, "", r, nil, 0)
+	var encErr *UnsupportedContentEncodingError
+	require.ErrorAs(t, err, &encErr)
+	require.Equal(t, "br", encErr.ContentEncoding)
+}
@@ -233,6 +233,12 @@ func Test_QueryResponseUnmarshal(t *testing.T) {
 							{Timestamp: time.Unix(0, 2), Line: "some log line 2"},
 						},
 					},
+					Stream{
+						Labels: LabelSet{"foo": "baz"},
+						Entries: []Entry{
+							{Timestamp: time.Unix(0, 1), Line: "with non-indexed labels", NonIndexedLabels: []logproto.LabelAdapter{{Name: "trace_id", Value: "abc"}, {Name: "user", Value: "jane"}}},
+						},
+					},
 					Stream{
 						Labels: LabelSet{"bar": "buzz", "level": "err", "foo": "bar"},
 						Entries: []Entry{
@@ -12,6 +12,8 @@ import (
 type SeriesResponse struct {
 	Status string     `json:"status"`
 	Data   []LabelSet `json:"data"`
+	// NextPageToken is the token of the next page of a paged query, empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
 }
 
 func ParseSeriesQuery(r *http.Request) (*logproto.SeriesRequest, error) {
@@ -38,11 +40,18 @@ func ParseSeriesQuery(r *http.Request) (*logproto.SeriesRequest, error) {
 		}
 	}
 
+	limit, token, err := page(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &logproto.SeriesRequest{
-		Start:  start,
-		End:    end,
-		Groups: deduped,
-		Shards: shards(r),
+		Start:     start,
+		End:       end,
+		Groups:    deduped,
+		Shards:    shards(r),
+		Limit:     limit,
+		PageToken: token,
 	}, nil
 }
 
@@ -78,6 +78,29 @@ func TestParseSeriesQuery(t *testing.T) {
 			false,
 			mkSeriesRequest(t, "1000", "2000", []string{`{a="1"}`, `{b="2"}`, `{c="3"}`}),
 		},
+		{
+			"paged",
+			withForm(url.Values{
+				"start":      []string{"1000"},
+				"end":        []string{"2000"},
+				"limit":      []string{"10"},
+				"page_token": []string{"Zm9v"},
+			}),
+			false,
+			func() *logproto.SeriesRequest {
+				req := mkSeriesRequest(t, "1000", "2000", []string{})
+				req.Limit, req.PageToken = 10, "Zm9v"
+				return req
+			}(),
+		},
+		{
+			"bad page token",
+			withForm(url.Values{
+				"page_token": []string{"not a token"},
+			}),
+			true,
+			nil,
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			out, err := ParseSeriesQuery(tc.input)
@@ -0,0 +1,65 @@
+package loghttp
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
+)
+
+var errVolumeEndBeforeStart = errors.New("end timestamp must not be before start time")
+
+// ParseVolumeQuery parses a volume query: the stream selector of the data to aggregate, the time range,
+// the number of volumes to return, the labels to aggregate by and the aggregation.
+func ParseVolumeQuery(r *http.Request) (*logproto.VolumeRequest, error) {
+	start, end, err := bounds(r)
+	if err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, errVolumeEndBeforeStart
+	}
+
+	q := query(r)
+	if _, err := syntax.ParseMatchers(q); err != nil {
+		return nil, err
+	}
+
+	l, err := parseInt(r.Form.Get("limit"), seriesvolume.DefaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	if l <= 0 {
+		return nil, errors.New("limit must be a positive value")
+	}
+
+	aggregateBy := r.Form.Get("aggregateBy")
+	if err := seriesvolume.ValidateAggregateBy(aggregateBy); err != nil {
+		return nil, err
+	}
+
+	return &logproto.VolumeRequest{
+		From:         model.TimeFromUnixNano(start.UnixNano()),
+		Through:      model.TimeFromUnixNano(end.UnixNano()),
+		Matchers:     q,
+		Limit:        int32(l),
+		TargetLabels: targetLabels(r),
+		AggregateBy:  aggregateBy,
+	}, nil
+}
+
+// targetLabels parses the comma-separated targetLabels parameter.
+func targetLabels(r *http.Request) []string {
+	var res []string
+	for _, name := range strings.Split(r.Form.Get("targetLabels"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			res = append(res, name)
+		}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package loghttp
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestParseVolumeQuery(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		form      url.Values
+		shouldErr bool
+		expected  *logproto.VolumeRequest
+	}{
+		{
+			desc: "defaults",
+			form: url.Values{
+				"start": []string{"1000"},
+				"end":   []string{"2000"},
+				"query": []string{`{app="foo"}`},
+			},
+			expected: &logproto.VolumeRequest{
+				From:     model.TimeFromUnix(1000),
+				Through:  model.TimeFromUnix(2000),
+				Matchers: `{app="foo"}`,
+				Limit:    100,
+			},
+		},
+		{
+			desc: "target labels and aggregation",
+			form: url.Values{
+				"start":        []string{"1000"},
+				"end":          []string{"2000"},
+				"query":        []string{`{app=~".+"}`},
+				"limit":        []string{"10"},
+				"targetLabels": []string{"app, namespace,"},
+				"aggregateBy":  []string{"labels"},
+			},
+			expected: &logproto.VolumeRequest{
+				From:         model.TimeFromUnix(1000),
+				Through:      model.TimeFromUnix(2000),
+				Matchers:     `{app=~".+"}`,
+				Limit:        10,
+				TargetLabels: []string{"app", "namespace"},
+				AggregateBy:  "labels",
+			},
+		},
+		{
+			desc: "not a stream selector",
+			form: url.Values{
+				"query": []string{`{app="foo"} |= "bar"`},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "negative limit",
+			form: url.Values{
+				"query": []string{`{app="foo"}`},
+				"limit": []string{"-1"},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "unsupported aggregation",
+			form: url.Values{
+				"query":       []string{`{app="foo"}`},
+				"aggregateBy": []string{"values"},
+			},
+			shouldErr: true,
+		},
+		{
+			desc: "end before start",
+			form: url.Values{
+				"start": []string{"2000"},
+				"end":   []string{"1000"},
+				"query": []string{`{app="foo"}`},
+			},
+			shouldErr: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := ParseVolumeQuery(withForm(tc.form))
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, req)
+		})
+	}
+}
@@ -28,30 +28,33 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 func init() { proto.RegisterFile("pkg/logproto/indexgateway.proto", fileDescriptor_d27585148d0a52c8) }
 
 var fileDescriptor_d27585148d0a52c8 = []byte{
-	// 360 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xb1, 0x4e, 0xf3, 0x30,
-	0x14, 0x85, 0x6d, 0xfd, 0xd2, 0x2f, 0x30, 0x88, 0xc1, 0x4b, 0x51, 0x0a, 0x46, 0x42, 0x0c, 0x30,
-	0xd0, 0x20, 0x78, 0x03, 0x90, 0x1a, 0x55, 0x2a, 0x48, 0x14, 0x89, 0xa1, 0x03, 0xc2, 0x29, 0xb7,
-	0x69, 0xd4, 0x34, 0x0e, 0x89, 0x23, 0xe8, 0xc6, 0x23, 0xf0, 0x18, 0x3c, 0x0a, 0x63, 0xc7, 0x8e,
-	0xd4, 0x5d, 0x18, 0x3b, 0x33, 0xa1, 0x38, 0x4a, 0xeb, 0x56, 0xa9, 0xc4, 0x14, 0xfb, 0x9c, 0x73,
-	0xbf, 0xab, 0xf8, 0x5e, 0x72, 0x10, 0xf5, 0x3d, 0x3b, 0x10, 0x5e, 0x14, 0x0b, 0x29, 0x6c, 0x3f,
-	0x7c, 0x82, 0x57, 0x8f, 0x4b, 0x78, 0xe1, 0xc3, 0x9a, 0x96, 0xe8, 0x8e, 0xa9, 0x45, 0xae, 0x75,
-	0xea, 0xf9, 0xb2, 0x97, 0xba, 0xb5, 0x8e, 0x18, 0xd8, 0x9e, 0xf0, 0x84, 0xad, 0x63, 0x6e, 0xda,
-	0xd5, 0xb7, 0x1c, 0x93, 0x9d, 0xf2, 0x72, 0xab, 0xba, 0xc4, 0x2f, 0x0e, 0xb9, 0x79, 0xfe, 0xf3,
-	0x8f, 0x6c, 0x37, 0x32, 0xbc, 0x93, 0xe3, 0x69, 0x83, 0x90, 0xdb, 0x14, 0xe2, 0xa1, 0x16, 0x69,
-	0xb5, 0x36, 0xcf, 0x2f, 0xd4, 0x16, 0x3c, 0xa7, 0x90, 0x48, 0x6b, 0xaf, 0xdc, 0x4c, 0x22, 0x11,
-	0x26, 0x70, 0x86, 0x69, 0x93, 0x6c, 0x39, 0x20, 0xaf, 0x7a, 0x69, 0xd8, 0x6f, 0x41, 0x97, 0x1a,
-	0x71, 0x43, 0x2e, 0x60, 0xfb, 0x6b, 0xdc, 0x9c, 0x76, 0x88, 0x68, 0x9d, 0x6c, 0x3a, 0x20, 0xef,
-	0x20, 0xf6, 0x21, 0xa1, 0xd6, 0x52, 0x3a, 0x17, 0x0b, 0x52, 0xb5, 0xd4, 0x9b, 0x73, 0x1e, 0x48,
-	0xa5, 0xc9, 0x5d, 0x08, 0x6e, 0xf8, 0x00, 0x92, 0xba, 0x88, 0xaf, 0x41, 0xc6, 0x7e, 0x27, 0xbb,
-	0xd1, 0xe3, 0x45, 0xe5, 0x9a, 0x48, 0xd1, 0xa3, 0xb2, 0x92, 0x34, 0xf8, 0x8f, 0x64, 0x57, 0x4b,
-	0xf7, 0x3c, 0x48, 0x57, 0x1b, 0x9c, 0xac, 0x94, 0x95, 0x64, 0xfe, 0xd0, 0xc1, 0x21, 0x1b, 0xd9,
-	0x8f, 0x49, 0x2e, 0x13, 0x73, 0x40, 0xfa, 0xf9, 0xb5, 0x5a, 0x32, 0x20, 0xd3, 0x2c, 0x40, 0x97,
-	0xed, 0xd1, 0x84, 0xa1, 0xf1, 0x84, 0xa1, 0xd9, 0x84, 0xe1, 0x37, 0xc5, 0xf0, 0x87, 0x62, 0xf8,
-	0x53, 0x31, 0x3c, 0x52, 0x0c, 0x7f, 0x29, 0x86, 0xbf, 0x15, 0x43, 0x33, 0xc5, 0xf0, 0xfb, 0x94,
-	0xa1, 0xd1, 0x94, 0xa1, 0xf1, 0x94, 0xa1, 0xf6, 0x91, 0xb9, 0x7e, 0x31, 0xef, 0xf2, 0x90, 0xdb,
-	0x81, 0xe8, 0xfb, 0xb6, 0xb9, 0x67, 0xee, 0x7f, 0xfd, 0xb9, 0xf8, 0x0d, 0x00, 0x00, 0xff, 0xff,
-	0xf0, 0xa4, 0xc7, 0x87, 0xde, 0x02, 0x00, 0x00,
+	// 411 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x93, 0xc1, 0xae, 0xd2, 0x40,
+	0x14, 0x86, 0x3b, 0x1b, 0xa2, 0x23, 0x71, 0x31, 0x1b, 0x48, 0x91, 0x31, 0x31, 0x2e, 0x74, 0x21,
+	0x6d, 0xf4, 0x05, 0x8c, 0x26, 0x34, 0x44, 0x34, 0x11, 0x12, 0x16, 0x2c, 0x8c, 0x53, 0x3c, 0x2d,
+	0x0d, 0x6d, 0xa7, 0xb6, 0xd3, 0x28, 0x3b, 0x1f, 0xc1, 0xc7, 0xf0, 0x51, 0x5c, 0xb2, 0x24, 0xb9,
+	0x9b, 0x4b, 0xd9, 0xdc, 0x25, 0x8f, 0x70, 0xd3, 0x99, 0x14, 0x06, 0x6e, 0x49, 0x6e, 0x72, 0xc3,
+	0xaa, 0x33, 0xff, 0xff, 0x9f, 0xef, 0xa4, 0x67, 0x66, 0xf0, 0xf3, 0x64, 0xe1, 0x5b, 0x21, 0xf7,
+	0x93, 0x94, 0x0b, 0x6e, 0x05, 0xf1, 0x0f, 0xf8, 0xed, 0x33, 0x01, 0xbf, 0xd8, 0xb2, 0x27, 0x25,
+	0xf2, 0x54, 0xd7, 0x12, 0xd7, 0x7c, 0xe3, 0x07, 0x62, 0x9e, 0xbb, 0xbd, 0x19, 0x8f, 0x2c, 0x9f,
+	0xfb, 0xdc, 0x92, 0x31, 0x37, 0xf7, 0xe4, 0x4e, 0x61, 0xca, 0x95, 0x2a, 0x37, 0x3b, 0x47, 0xfc,
+	0x6a, 0xa1, 0xcc, 0xb7, 0x57, 0x0d, 0xdc, 0x1c, 0x94, 0x78, 0x47, 0xe1, 0xc9, 0x00, 0xe3, 0xaf,
+	0x39, 0xa4, 0x4b, 0x29, 0x92, 0x4e, 0x6f, 0x9f, 0x3f, 0xa8, 0x23, 0xf8, 0x99, 0x43, 0x26, 0xcc,
+	0x67, 0xf5, 0x66, 0x96, 0xf0, 0x38, 0x03, 0x1b, 0x91, 0x21, 0x7e, 0xe2, 0x80, 0xf8, 0x38, 0xcf,
+	0xe3, 0xc5, 0x08, 0x3c, 0xa2, 0xc5, 0x35, 0xb9, 0x82, 0x75, 0xcf, 0xb8, 0x8a, 0xf6, 0xc2, 0x20,
+	0x7d, 0xfc, 0xd8, 0x01, 0x31, 0x86, 0x34, 0x80, 0x8c, 0x98, 0x47, 0x69, 0x25, 0x56, 0xa4, 0x4e,
+	0xad, 0xb7, 0xe7, 0x7c, 0xc3, 0xad, 0x21, 0x73, 0x21, 0xfc, 0xc2, 0x22, 0xc8, 0xfa, 0x3c, 0xfd,
+	0x0c, 0x22, 0x0d, 0x66, 0xe5, 0x8e, 0xbc, 0x3a, 0x54, 0x9e, 0x89, 0x54, 0x3d, 0x5a, 0x27, 0x49,
+	0x8d, 0xff, 0x1d, 0xb7, 0xa5, 0x34, 0x61, 0x61, 0x7e, 0xda, 0xe0, 0xf5, 0x49, 0x59, 0x4d, 0xe6,
+	0x1e, 0x1d, 0x1c, 0xfc, 0xa8, 0xfc, 0x31, 0xc1, 0x44, 0xa6, 0x1f, 0x90, 0x1c, 0xbf, 0x54, 0x6b,
+	0x0e, 0x48, 0x37, 0xf7, 0xa0, 0x4f, 0xb8, 0x39, 0x16, 0x29, 0xb0, 0xe8, 0xc1, 0x53, 0xb5, 0x11,
+	0xf1, 0x70, 0x57, 0xc1, 0x2e, 0x39, 0x5d, 0x1b, 0x91, 0x39, 0xa6, 0x5a, 0x9f, 0x8b, 0x4d, 0xd9,
+	0x46, 0xe4, 0xbd, 0xbc, 0x71, 0x13, 0x1e, 0xe6, 0x11, 0x10, 0x2d, 0xa9, 0x94, 0x0a, 0xd1, 0xbe,
+	0x6b, 0x54, 0x8c, 0x0f, 0xd3, 0xd5, 0x86, 0x1a, 0xeb, 0x0d, 0x35, 0x76, 0x1b, 0x8a, 0xfe, 0x14,
+	0x14, 0xfd, 0x2b, 0x28, 0xfa, 0x5f, 0x50, 0xb4, 0x2a, 0x28, 0xba, 0x2e, 0x28, 0xba, 0x29, 0xa8,
+	0xb1, 0x2b, 0x28, 0xfa, 0xbb, 0xa5, 0xc6, 0x6a, 0x4b, 0x8d, 0xf5, 0x96, 0x1a, 0xd3, 0x97, 0xfa,
+	0xfb, 0x4e, 0x99, 0xc7, 0x62, 0x66, 0x85, 0x7c, 0x11, 0x58, 0xfa, 0x43, 0x76, 0x1b, 0xf2, 0xf3,
+	0xee, 0x76, 0x00, 0x2d, 0x28, 0x06, 0xde, 0x3f, 0x04, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -77,6 +80,15 @@ type IndexGatewayClient interface {
 	// Note: this MUST be the same as the variant defined in
 	// logproto.proto on the Querier service.
 	GetStats(ctx context.Context, in *IndexStatsRequest, opts ...grpc.CallOption) (*IndexStatsResponse, error)
+	// StreamSeries is the same as GetSeries, sending the series in batches.
+	StreamSeries(ctx context.Context, in *GetSeriesRequest, opts ...grpc.CallOption) (IndexGateway_StreamSeriesClient, error)
+	// StreamLabelNamesForMetricName is the same as LabelNamesForMetricName, sending the values in batches.
+	StreamLabelNamesForMetricName(ctx context.Context, in *LabelNamesForMetricNameRequest, opts ...grpc.CallOption) (IndexGateway_StreamLabelNamesForMetricNameClient, error)
+	// StreamLabelValuesForMetricName is the same as LabelValuesForMetricName, sending the values in batches.
+	StreamLabelValuesForMetricName(ctx context.Context, in *LabelValuesForMetricNameRequest, opts ...grpc.CallOption) (IndexGateway_StreamLabelValuesForMetricNameClient, error)
+	// Note: this MUST be the same as the variant defined in
+	// logproto.proto on the Querier service.
+	GetVolume(ctx context.Context, in *VolumeRequest, opts ...grpc.CallOption) (*VolumeResponse, error)
 }
 
 type indexGatewayClient struct {
@@ -164,6 +176,111 @@ func (c *indexGatewayClient) GetStats(ctx context.Context, in *IndexStatsRequest
 	return out, nil
 }
 
+func (c *indexGatewayClient) StreamSeries(ctx context.Context, in *GetSeriesRequest, opts ...grpc.CallOption) (IndexGateway_StreamSeriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexGateway_serviceDesc.Streams[1], "/indexgatewaypb.IndexGateway/StreamSeries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexGatewayStreamSeriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexGateway_StreamSeriesClient interface {
+	Recv() (*GetSeriesResponse, error)
+	grpc.ClientStream
+}
+
+type indexGatewayStreamSeriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexGatewayStreamSeriesClient) Recv() (*GetSeriesResponse, error) {
+	m := new(GetSeriesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexGatewayClient) StreamLabelNamesForMetricName(ctx context.Context, in *LabelNamesForMetricNameRequest, opts ...grpc.CallOption) (IndexGateway_StreamLabelNamesForMetricNameClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexGateway_serviceDesc.Streams[2], "/indexgatewaypb.IndexGateway/StreamLabelNamesForMetricName", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexGatewayStreamLabelNamesForMetricNameClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexGateway_StreamLabelNamesForMetricNameClient interface {
+	Recv() (*LabelResponse, error)
+	grpc.ClientStream
+}
+
+type indexGatewayStreamLabelNamesForMetricNameClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexGatewayStreamLabelNamesForMetricNameClient) Recv() (*LabelResponse, error) {
+	m := new(LabelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexGatewayClient) StreamLabelValuesForMetricName(ctx context.Context, in *LabelValuesForMetricNameRequest, opts ...grpc.CallOption) (IndexGateway_StreamLabelValuesForMetricNameClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexGateway_serviceDesc.Streams[3], "/indexgatewaypb.IndexGateway/StreamLabelValuesForMetricName", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexGatewayStreamLabelValuesForMetricNameClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexGateway_StreamLabelValuesForMetricNameClient interface {
+	Recv() (*LabelResponse, error)
+	grpc.ClientStream
+}
+
+type indexGatewayStreamLabelValuesForMetricNameClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexGatewayStreamLabelValuesForMetricNameClient) Recv() (*LabelResponse, error) {
+	m := new(LabelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexGatewayClient) GetVolume(ctx context.Context, in *VolumeRequest, opts ...grpc.CallOption) (*VolumeResponse, error) {
+	out := new(VolumeResponse)
+	err := c.cc.Invoke(ctx, "/indexgatewaypb.IndexGateway/GetVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IndexGatewayServer is the server API for IndexGateway service.
 type IndexGatewayServer interface {
 	/// QueryIndex reads the indexes required for given query & sends back the batch of rows
@@ -177,6 +294,15 @@ type IndexGatewayServer interface {
 	// Note: this MUST be the same as the variant defined in
 	// logproto.proto on the Querier service.
 	GetStats(context.Context, *IndexStatsRequest) (*IndexStatsResponse, error)
+	// StreamSeries is the same as GetSeries, sending the series in batches.
+	StreamSeries(*GetSeriesRequest, IndexGateway_StreamSeriesServer) error
+	// StreamLabelNamesForMetricName is the same as LabelNamesForMetricName, sending the values in batches.
+	StreamLabelNamesForMetricName(*LabelNamesForMetricNameRequest, IndexGateway_StreamLabelNamesForMetricNameServer) error
+	// StreamLabelValuesForMetricName is the same as LabelValuesForMetricName, sending the values in batches.
+	StreamLabelValuesForMetricName(*LabelValuesForMetricNameRequest, IndexGateway_StreamLabelValuesForMetricNameServer) error
+	// Note: this MUST be the same as the variant defined in
+	// logproto.proto on the Querier service.
+	GetVolume(context.Context, *VolumeRequest) (*VolumeResponse, error)
 }
 
 // UnimplementedIndexGatewayServer can be embedded to have forward compatible implementations.
@@ -201,6 +327,18 @@ func (*UnimplementedIndexGatewayServer) LabelValuesForMetricName(ctx context.Con
 func (*UnimplementedIndexGatewayServer) GetStats(ctx context.Context, req *IndexStatsRequest) (*IndexStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (*UnimplementedIndexGatewayServer) StreamSeries(req *GetSeriesRequest, srv IndexGateway_StreamSeriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSeries not implemented")
+}
+func (*UnimplementedIndexGatewayServer) StreamLabelNamesForMetricName(req *LabelNamesForMetricNameRequest, srv IndexGateway_StreamLabelNamesForMetricNameServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLabelNamesForMetricName not implemented")
+}
+func (*UnimplementedIndexGatewayServer) StreamLabelValuesForMetricName(req *LabelValuesForMetricNameRequest, srv IndexGateway_StreamLabelValuesForMetricNameServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLabelValuesForMetricName not implemented")
+}
+func (*UnimplementedIndexGatewayServer) GetVolume(ctx context.Context, req *VolumeRequest) (*VolumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolume not implemented")
+}
 
 func RegisterIndexGatewayServer(s *grpc.Server, srv IndexGatewayServer) {
 	s.RegisterService(&_IndexGateway_serviceDesc, srv)
@@ -317,6 +455,87 @@ func _IndexGateway_GetStats_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IndexGateway_StreamSeries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetSeriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexGatewayServer).StreamSeries(m, &indexGatewayStreamSeriesServer{stream})
+}
+
+type IndexGateway_StreamSeriesServer interface {
+	Send(*GetSeriesResponse) error
+	grpc.ServerStream
+}
+
+type indexGatewayStreamSeriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexGatewayStreamSeriesServer) Send(m *GetSeriesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexGateway_StreamLabelNamesForMetricName_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LabelNamesForMetricNameRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexGatewayServer).StreamLabelNamesForMetricName(m, &indexGatewayStreamLabelNamesForMetricNameServer{stream})
+}
+
+type IndexGateway_StreamLabelNamesForMetricNameServer interface {
+	Send(*LabelResponse) error
+	grpc.ServerStream
+}
+
+type indexGatewayStreamLabelNamesForMetricNameServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexGatewayStreamLabelNamesForMetricNameServer) Send(m *LabelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexGateway_StreamLabelValuesForMetricName_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LabelValuesForMetricNameRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexGatewayServer).StreamLabelValuesForMetricName(m, &indexGatewayStreamLabelValuesForMetricNameServer{stream})
+}
+
+type IndexGateway_StreamLabelValuesForMetricNameServer interface {
+	Send(*LabelResponse) error
+	grpc.ServerStream
+}
+
+type indexGatewayStreamLabelValuesForMetricNameServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexGatewayStreamLabelValuesForMetricNameServer) Send(m *LabelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexGateway_GetVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexGatewayServer).GetVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/indexgatewaypb.IndexGateway/GetVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexGatewayServer).GetVolume(ctx, req.(*VolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _IndexGateway_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "indexgatewaypb.IndexGateway",
 	HandlerType: (*IndexGatewayServer)(nil),
@@ -341,6 +560,10 @@ var _IndexGateway_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetStats",
 			Handler:    _IndexGateway_GetStats_Handler,
 		},
+		{
+			MethodName: "GetVolume",
+			Handler:    _IndexGateway_GetVolume_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -348,6 +571,21 @@ var _IndexGateway_serviceDesc = grpc.ServiceDesc{
 			Handler:       _IndexGateway_QueryIndex_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamSeries",
+			Handler:       _IndexGateway_StreamSeries_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLabelNamesForMetricName",
+			Handler:       _IndexGateway_StreamLabelNamesForMetricName_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamLabelValuesForMetricName",
+			Handler:       _IndexGateway_StreamLabelValuesForMetricName_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/logproto/indexgateway.proto",
 }
@@ -586,6 +586,10 @@ type LabelRequest struct {
 	Values bool       `protobuf:"varint,2,opt,name=values,proto3" json:"values,omitempty"`
 	Start  *time.Time `protobuf:"bytes,3,opt,name=start,proto3,stdtime" json:"start,omitempty"`
 	End    *time.Time `protobuf:"bytes,4,opt,name=end,proto3,stdtime" json:"end,omitempty"`
+	// Maximum number of values to return, 0 for all of them.
+	Limit uint32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Token of the page to return, the nextPageToken of the previous page.
+	PageToken string `protobuf:"bytes,6,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
 }
 
 func (m *LabelRequest) Reset()      { *m = LabelRequest{} }
@@ -648,8 +652,24 @@ func (m *LabelRequest) GetEnd() *time.Time {
 	return nil
 }
 
+func (m *LabelRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *LabelRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type LabelResponse struct {
 	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	// Token of the next page, set when the page holds the maximum number of values.
+	NextPageToken string `protobuf:"bytes,2,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
 }
 
 func (m *LabelResponse) Reset()      { *m = LabelResponse{} }
@@ -691,6 +711,13 @@ func (m *LabelResponse) GetValues() []string {
 	return nil
 }
 
+func (m *LabelResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type StreamAdapter struct {
 	Labels  string         `protobuf:"bytes,1,opt,name=labels,proto3" json:"labels"`
 	Entries []EntryAdapter `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries"`
@@ -754,6 +781,8 @@ func (m *StreamAdapter) GetHash() uint64 {
 type EntryAdapter struct {
 	Timestamp time.Time `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"ts"`
 	Line      string    `protobuf:"bytes,2,opt,name=line,proto3" json:"line"`
+	// nonIndexedLabels are stored in the chunks along with the line but are not part of the index.
+	NonIndexedLabels []LabelAdapter `protobuf:"bytes,3,rep,name=nonIndexedLabels,proto3,customtype=LabelAdapter" json:"nonIndexedLabels,omitempty"`
 }
 
 func (m *EntryAdapter) Reset()      { *m = EntryAdapter{} }
@@ -1088,6 +1117,10 @@ type SeriesRequest struct {
 	End    time.Time `protobuf:"bytes,2,opt,name=end,proto3,stdtime" json:"end"`
 	Groups []string  `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
 	Shards []string  `protobuf:"bytes,4,rep,name=shards,proto3" json:"shards,omitempty"`
+	// Maximum number of series to return, 0 for all of them.
+	Limit uint32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Token of the page to return, the nextPageToken of the previous page.
+	PageToken string `protobuf:"bytes,6,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
 }
 
 func (m *SeriesRequest) Reset()      { *m = SeriesRequest{} }
@@ -1150,8 +1183,24 @@ func (m *SeriesRequest) GetShards() []string {
 	return nil
 }
 
+func (m *SeriesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *SeriesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type SeriesResponse struct {
 	Series []SeriesIdentifier `protobuf:"bytes,1,rep,name=series,proto3" json:"series"`
+	// Token of the next page, set when the page holds the maximum number of series.
+	NextPageToken string `protobuf:"bytes,2,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
 }
 
 func (m *SeriesResponse) Reset()      { *m = SeriesResponse{} }
@@ -1193,6 +1242,13 @@ func (m *SeriesResponse) GetSeries() []SeriesIdentifier {
 	return nil
 }
 
+func (m *SeriesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type SeriesIdentifier struct {
 	Labels map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
@@ -1796,6 +1852,10 @@ type LabelValuesForMetricNameRequest struct {
 	From       github_com_prometheus_common_model.Time `protobuf:"varint,3,opt,name=from,proto3,customtype=github.com/prometheus/common/model.Time" json:"from"`
 	Through    github_com_prometheus_common_model.Time `protobuf:"varint,4,opt,name=through,proto3,customtype=github.com/prometheus/common/model.Time" json:"through"`
 	Matchers   string                                  `protobuf:"bytes,5,opt,name=matchers,proto3" json:"matchers,omitempty"`
+	// Maximum number of values to return, 0 for all of them.
+	Limit uint32 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Token of the page to return, the nextPageToken of the previous page.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (m *LabelValuesForMetricNameRequest) Reset()      { *m = LabelValuesForMetricNameRequest{} }
@@ -1851,10 +1911,28 @@ func (m *LabelValuesForMetricNameRequest) GetMatchers() string {
 	return ""
 }
 
+func (m *LabelValuesForMetricNameRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *LabelValuesForMetricNameRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type LabelNamesForMetricNameRequest struct {
 	MetricName string                                  `protobuf:"bytes,1,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
 	From       github_com_prometheus_common_model.Time `protobuf:"varint,2,opt,name=from,proto3,customtype=github.com/prometheus/common/model.Time" json:"from"`
 	Through    github_com_prometheus_common_model.Time `protobuf:"varint,3,opt,name=through,proto3,customtype=github.com/prometheus/common/model.Time" json:"through"`
+	// Maximum number of values to return, 0 for all of them.
+	Limit uint32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Token of the page to return, the nextPageToken of the previous page.
+	PageToken string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (m *LabelNamesForMetricNameRequest) Reset()      { *m = LabelNamesForMetricNameRequest{} }
@@ -1896,6 +1974,20 @@ func (m *LabelNamesForMetricNameRequest) GetMetricName() string {
 	return ""
 }
 
+func (m *LabelNamesForMetricNameRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *LabelNamesForMetricNameRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetChunkRefRequest struct {
 	From     github_com_prometheus_common_model.Time `protobuf:"varint,1,opt,name=from,proto3,customtype=github.com/prometheus/common/model.Time" json:"from"`
 	Through  github_com_prometheus_common_model.Time `protobuf:"varint,2,opt,name=through,proto3,customtype=github.com/prometheus/common/model.Time" json:"through"`
@@ -1988,6 +2080,10 @@ type GetSeriesRequest struct {
 	From     github_com_prometheus_common_model.Time `protobuf:"varint,1,opt,name=from,proto3,customtype=github.com/prometheus/common/model.Time" json:"from"`
 	Through  github_com_prometheus_common_model.Time `protobuf:"varint,2,opt,name=through,proto3,customtype=github.com/prometheus/common/model.Time" json:"through"`
 	Matchers string                                  `protobuf:"bytes,3,opt,name=matchers,proto3" json:"matchers,omitempty"`
+	// Maximum number of series to return, 0 for all of them.
+	Limit uint32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Token of the page to return, the next_page_token of the previous page.
+	PageToken string `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 }
 
 func (m *GetSeriesRequest) Reset()      { *m = GetSeriesRequest{} }
@@ -2029,8 +2125,24 @@ func (m *GetSeriesRequest) GetMatchers() string {
 	return ""
 }
 
+func (m *GetSeriesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *GetSeriesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type GetSeriesResponse struct {
 	Series []IndexSeries `protobuf:"bytes,1,rep,name=series,proto3" json:"series"`
+	// Token of the next page, set when the page holds the maximum number of series.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 }
 
 func (m *GetSeriesResponse) Reset()      { *m = GetSeriesResponse{} }
@@ -2072,6 +2184,13 @@ func (m *GetSeriesResponse) GetSeries() []IndexSeries {
 	return nil
 }
 
+func (m *GetSeriesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 // Series calls to the TSDB Index
 type IndexSeries struct {
 	Labels []LabelAdapter `protobuf:"bytes,1,rep,name=labels,proto3,customtype=LabelAdapter" json:"labels"`
@@ -2441,6 +2560,177 @@ func (m *IndexStatsResponse) GetEntries() uint64 {
 	return 0
 }
 
+type VolumeRequest struct {
+	From         github_com_prometheus_common_model.Time `protobuf:"varint,1,opt,name=from,proto3,customtype=github.com/prometheus/common/model.Time" json:"from"`
+	Through      github_com_prometheus_common_model.Time `protobuf:"varint,2,opt,name=through,proto3,customtype=github.com/prometheus/common/model.Time" json:"through"`
+	Matchers     string                                  `protobuf:"bytes,3,opt,name=matchers,proto3" json:"matchers,omitempty"`
+	Limit        int32                                   `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	TargetLabels []string                                `protobuf:"bytes,5,rep,name=targetLabels,proto3" json:"targetLabels,omitempty"`
+	AggregateBy  string                                  `protobuf:"bytes,6,opt,name=aggregateBy,proto3" json:"aggregateBy,omitempty"`
+}
+
+func (m *VolumeRequest) Reset()      { *m = VolumeRequest{} }
+func (*VolumeRequest) ProtoMessage() {}
+func (*VolumeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{46}
+}
+func (m *VolumeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *VolumeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_VolumeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *VolumeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VolumeRequest.Merge(m, src)
+}
+func (m *VolumeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *VolumeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VolumeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VolumeRequest proto.InternalMessageInfo
+
+func (m *VolumeRequest) GetMatchers() string {
+	if m != nil {
+		return m.Matchers
+	}
+	return ""
+}
+
+func (m *VolumeRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *VolumeRequest) GetTargetLabels() []string {
+	if m != nil {
+		return m.TargetLabels
+	}
+	return nil
+}
+
+func (m *VolumeRequest) GetAggregateBy() string {
+	if m != nil {
+		return m.AggregateBy
+	}
+	return ""
+}
+
+type VolumeResponse struct {
+	Volumes []Volume `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes"`
+	Limit   int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *VolumeResponse) Reset()      { *m = VolumeResponse{} }
+func (*VolumeResponse) ProtoMessage() {}
+func (*VolumeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{47}
+}
+func (m *VolumeResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *VolumeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_VolumeResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *VolumeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VolumeResponse.Merge(m, src)
+}
+func (m *VolumeResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *VolumeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VolumeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VolumeResponse proto.InternalMessageInfo
+
+func (m *VolumeResponse) GetVolumes() []Volume {
+	if m != nil {
+		return m.Volumes
+	}
+	return nil
+}
+
+func (m *VolumeResponse) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type Volume struct {
+	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+	Volume uint64 `protobuf:"varint,2,opt,name=volume,proto3" json:"volume"`
+}
+
+func (m *Volume) Reset()      { *m = Volume{} }
+func (*Volume) ProtoMessage() {}
+func (*Volume) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c28a5f14f1f4c79a, []int{48}
+}
+func (m *Volume) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Volume) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Volume.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Volume) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Volume.Merge(m, src)
+}
+func (m *Volume) XXX_Size() int {
+	return m.Size()
+}
+func (m *Volume) XXX_DiscardUnknown() {
+	xxx_messageInfo_Volume.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Volume proto.InternalMessageInfo
+
+func (m *Volume) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Volume) GetVolume() uint64 {
+	if m != nil {
+		return m.Volume
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("logproto.Direction", Direction_name, Direction_value)
 	proto.RegisterType((*StreamRatesRequest)(nil), "logproto.StreamRatesRequest")
@@ -2490,149 +2780,167 @@ func init() {
 	proto.RegisterType((*IndexQuery)(nil), "logproto.IndexQuery")
 	proto.RegisterType((*IndexStatsRequest)(nil), "logproto.IndexStatsRequest")
 	proto.RegisterType((*IndexStatsResponse)(nil), "logproto.IndexStatsResponse")
+	proto.RegisterType((*VolumeRequest)(nil), "logproto.VolumeRequest")
+	proto.RegisterType((*VolumeResponse)(nil), "logproto.VolumeResponse")
+	proto.RegisterType((*Volume)(nil), "logproto.Volume")
 }
 
 func init() { proto.RegisterFile("pkg/logproto/logproto.proto", fileDescriptor_c28a5f14f1f4c79a) }
 
 var fileDescriptor_c28a5f14f1f4c79a = []byte{
-	// 2181 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x19, 0x4d, 0x6f, 0x1b, 0xc7,
-	0x95, 0x4b, 0x2e, 0x29, 0xf2, 0x91, 0xa2, 0xe8, 0x31, 0x6d, 0x33, 0xb4, 0x4d, 0xca, 0x8b, 0xd4,
-	0x16, 0x1c, 0x9b, 0xaa, 0x95, 0x36, 0x71, 0xec, 0xa6, 0x85, 0x28, 0xc5, 0xb6, 0x6c, 0xd9, 0x56,
-	0x46, 0xae, 0x03, 0x04, 0x08, 0x8c, 0x15, 0x39, 0x24, 0x17, 0xe2, 0x72, 0xe9, 0xdd, 0x61, 0x1c,
-	0x01, 0x05, 0xda, 0x1f, 0xd0, 0x00, 0xe9, 0xa9, 0xe8, 0xbd, 0x40, 0x8b, 0x1e, 0x7a, 0x28, 0xd0,
-	0x6b, 0xdb, 0x5b, 0xdd, 0x9b, 0x7b, 0x0b, 0x72, 0x60, 0x6b, 0xf9, 0x52, 0xe8, 0x94, 0x5f, 0x50,
-	0x14, 0xf3, 0xb5, 0x3b, 0x5c, 0x51, 0x8d, 0xe9, 0x1a, 0x28, 0x72, 0x21, 0x67, 0xde, 0xbc, 0x99,
-	0x79, 0xdf, 0x1f, 0xb3, 0x70, 0x7a, 0xb8, 0xdb, 0x5d, 0xee, 0x7b, 0xdd, 0xa1, 0xef, 0x51, 0x2f,
-	0x1c, 0x34, 0xf8, 0x2f, 0xca, 0xaa, 0x79, 0xf5, 0x72, 0xd7, 0xa1, 0xbd, 0xd1, 0x4e, 0xa3, 0xe5,
-	0xb9, 0xcb, 0x5d, 0xaf, 0xeb, 0x2d, 0x73, 0xf0, 0xce, 0xa8, 0xc3, 0x67, 0x62, 0x33, 0x1b, 0x89,
-	0x8d, 0xd5, 0x7a, 0xd7, 0xf3, 0xba, 0x7d, 0x12, 0x61, 0x51, 0xc7, 0x25, 0x01, 0xb5, 0xdd, 0xa1,
-	0x44, 0x58, 0x94, 0xd7, 0x3e, 0xee, 0xbb, 0x5e, 0x9b, 0xf4, 0x97, 0x03, 0x6a, 0xd3, 0x40, 0xfc,
-	0x0a, 0x0c, 0xab, 0x0c, 0x68, 0x9b, 0xfa, 0xc4, 0x76, 0xb1, 0x4d, 0x49, 0x80, 0xc9, 0xe3, 0x11,
-	0x09, 0xa8, 0x75, 0x17, 0x8e, 0x4f, 0x40, 0x83, 0xa1, 0x37, 0x08, 0x08, 0x7a, 0x07, 0xf2, 0x41,
-	0x04, 0xae, 0x18, 0x8b, 0xa9, 0xa5, 0xfc, 0x4a, 0xb9, 0x11, 0xb2, 0x13, 0xed, 0xc1, 0x3a, 0xa2,
-	0x35, 0x00, 0x88, 0x96, 0x50, 0x0d, 0x40, 0x2c, 0xde, 0xb2, 0x83, 0x5e, 0xc5, 0x58, 0x34, 0x96,
-	0x4c, 0xac, 0x41, 0xd0, 0x25, 0x38, 0x16, 0xcd, 0xee, 0x79, 0xdb, 0x3d, 0xdb, 0x6f, 0x57, 0x92,
-	0x1c, 0xed, 0xf0, 0x02, 0x42, 0x60, 0xfa, 0x36, 0x25, 0x95, 0xd4, 0xa2, 0xb1, 0x94, 0xc2, 0x7c,
-	0x6c, 0x7d, 0x04, 0xf9, 0xad, 0x51, 0xd0, 0x93, 0xdc, 0xa0, 0x5b, 0x30, 0x27, 0xf6, 0x29, 0x92,
-	0x4f, 0xc5, 0x49, 0x5e, 0x6d, 0xdb, 0x43, 0x4a, 0xfc, 0xe6, 0x89, 0xaf, 0xc6, 0xf5, 0x8c, 0x00,
-	0x1d, 0x8c, 0xeb, 0x6a, 0x17, 0x56, 0x03, 0xab, 0x08, 0x05, 0x71, 0xb0, 0x10, 0x88, 0xf5, 0xd7,
-	0x24, 0x14, 0x3e, 0x1c, 0x11, 0x7f, 0x4f, 0x5d, 0x55, 0x85, 0x6c, 0x40, 0xfa, 0xa4, 0x45, 0x3d,
-	0x9f, 0x73, 0x96, 0xc3, 0xe1, 0x1c, 0x95, 0x21, 0xdd, 0x77, 0x5c, 0x87, 0x72, 0x5e, 0xe6, 0xb1,
-	0x98, 0xa0, 0x6b, 0x90, 0x0e, 0xa8, 0xed, 0x53, 0xce, 0x40, 0x7e, 0xa5, 0xda, 0x10, 0x3a, 0x6d,
-	0x28, 0x9d, 0x36, 0x1e, 0x28, 0x9d, 0x36, 0xb3, 0x4f, 0xc7, 0xf5, 0xc4, 0x17, 0xff, 0xa8, 0x1b,
-	0x58, 0x6c, 0x41, 0xef, 0x40, 0x8a, 0x0c, 0xda, 0x15, 0x73, 0x86, 0x9d, 0x6c, 0x03, 0xba, 0x02,
-	0xb9, 0xb6, 0xe3, 0x93, 0x16, 0x75, 0xbc, 0x41, 0x25, 0xbd, 0x68, 0x2c, 0x15, 0x57, 0x8e, 0x47,
-	0x22, 0x59, 0x57, 0x4b, 0x38, 0xc2, 0x42, 0x97, 0x20, 0x13, 0x30, 0x79, 0x07, 0x95, 0xb9, 0xc5,
-	0xd4, 0x52, 0xae, 0x59, 0x3e, 0x18, 0xd7, 0x4b, 0x02, 0x72, 0xc9, 0x73, 0x1d, 0x4a, 0xdc, 0x21,
-	0xdd, 0xc3, 0x12, 0x07, 0x5d, 0x84, 0xb9, 0x36, 0xe9, 0x13, 0x66, 0x24, 0x59, 0x2e, 0xf1, 0x92,
-	0x76, 0x3c, 0x5f, 0xc0, 0x0a, 0xe1, 0xb6, 0x99, 0xcd, 0x94, 0xe6, 0xac, 0x7f, 0x1b, 0x80, 0xb6,
-	0x6d, 0x77, 0xd8, 0x27, 0x2f, 0x2d, 0xcf, 0x50, 0x72, 0xc9, 0x57, 0x96, 0x5c, 0x6a, 0x56, 0xc9,
-	0x45, 0x62, 0x30, 0x67, 0x13, 0x43, 0xfa, 0x1b, 0xc4, 0x60, 0x6d, 0x42, 0x46, 0x80, 0xbe, 0xc9,
-	0x86, 0x22, 0x9e, 0x53, 0x8a, 0x9b, 0x52, 0xc4, 0x4d, 0x8a, 0xd3, 0x69, 0xfd, 0x14, 0xe6, 0xa5,
-	0x1c, 0xa5, 0xeb, 0xae, 0xbe, 0xb4, 0x0f, 0x14, 0x9f, 0x8e, 0xeb, 0x46, 0xe4, 0x07, 0xa1, 0xf1,
-	0xa3, 0xb7, 0xf8, 0xdd, 0x34, 0x90, 0xf2, 0x5e, 0x68, 0x88, 0x38, 0xb2, 0x31, 0xe8, 0x92, 0x80,
-	0x6d, 0x34, 0x99, 0xa8, 0xb0, 0xc0, 0xb1, 0x7e, 0x02, 0xc7, 0x27, 0xd4, 0x29, 0xc9, 0xb8, 0x0a,
-	0x99, 0x80, 0xf8, 0x4e, 0x18, 0x3c, 0x34, 0x81, 0x6c, 0x73, 0xb8, 0x76, 0x3d, 0x9f, 0x63, 0x89,
-	0x3f, 0xdb, 0xed, 0xbf, 0x37, 0xa0, 0xb0, 0x69, 0xef, 0x90, 0xbe, 0xb2, 0x23, 0x04, 0xe6, 0xc0,
-	0x76, 0x89, 0x94, 0x27, 0x1f, 0xa3, 0x93, 0x90, 0xf9, 0xd4, 0xee, 0x8f, 0x88, 0x38, 0x32, 0x8b,
-	0xe5, 0x6c, 0x56, 0x8f, 0x34, 0x5e, 0xd9, 0x23, 0x8d, 0xd0, 0xae, 0xac, 0x0b, 0x30, 0x2f, 0xe9,
-	0x95, 0x82, 0x8a, 0x88, 0x63, 0x82, 0xca, 0x29, 0xe2, 0xac, 0x5f, 0x18, 0x30, 0x3f, 0xa1, 0x2f,
-	0x64, 0x41, 0xa6, 0xcf, 0xb6, 0x06, 0x82, 0xb9, 0x26, 0x1c, 0x8c, 0xeb, 0x12, 0x82, 0xe5, 0x3f,
-	0xd3, 0x3e, 0x19, 0x50, 0x2e, 0xf7, 0x24, 0x97, 0xfb, 0xc9, 0x48, 0xee, 0x1f, 0x0c, 0xa8, 0xbf,
-	0xa7, 0x94, 0xbf, 0xc0, 0xa4, 0xc8, 0x42, 0x9f, 0x44, 0xc7, 0x6a, 0x80, 0xde, 0x00, 0xb3, 0xc7,
-	0xe2, 0x35, 0x13, 0x8a, 0xd9, 0x4c, 0x1f, 0x8c, 0xeb, 0xc6, 0x65, 0xcc, 0x41, 0xd6, 0xa7, 0x50,
-	0xd0, 0x0f, 0x41, 0xb7, 0x20, 0x17, 0x26, 0x22, 0x4e, 0xd4, 0x7f, 0x17, 0x45, 0x51, 0xde, 0x99,
-	0xa4, 0x01, 0x17, 0x48, 0xb4, 0x19, 0x9d, 0x01, 0xb3, 0xef, 0x0c, 0x08, 0x57, 0x50, 0xae, 0x99,
-	0x3d, 0x18, 0xd7, 0xf9, 0x1c, 0xf3, 0x5f, 0xcb, 0x85, 0x8c, 0xb0, 0x31, 0xf4, 0x66, 0xfc, 0xc6,
-	0x54, 0x33, 0x23, 0x4e, 0xd4, 0x4f, 0xab, 0x43, 0x9a, 0x4b, 0x91, 0x1f, 0x67, 0x34, 0x73, 0x07,
-	0xe3, 0xba, 0x00, 0x60, 0xf1, 0xc7, 0xae, 0xd3, 0x78, 0xe4, 0xd7, 0xb1, 0xb9, 0x64, 0xf3, 0x26,
-	0x14, 0x36, 0x49, 0xd7, 0x6e, 0xed, 0xc9, 0x4b, 0xcb, 0xea, 0x38, 0x76, 0xa1, 0xa1, 0xce, 0x38,
-	0x07, 0x85, 0xf0, 0xc6, 0x47, 0x6e, 0x20, 0x1d, 0x35, 0x1f, 0xc2, 0xee, 0x06, 0xd6, 0xaf, 0x0c,
-	0x90, 0xd6, 0xfd, 0x52, 0xca, 0xbb, 0x0e, 0x73, 0x01, 0xbf, 0x51, 0x29, 0x4f, 0x77, 0x1a, 0xbe,
-	0x10, 0xa9, 0x4d, 0x22, 0x62, 0x35, 0x40, 0x8d, 0x89, 0x64, 0x2b, 0x18, 0x2b, 0x1e, 0x8c, 0xeb,
-	0x1a, 0x54, 0x4f, 0xbe, 0xd6, 0x2f, 0x0d, 0xc8, 0x3f, 0xb0, 0x9d, 0xd0, 0x71, 0xca, 0x90, 0x7e,
-	0xcc, 0x3c, 0x58, 0x7a, 0x8e, 0x98, 0xb0, 0x10, 0xd5, 0x26, 0x7d, 0x7b, 0xef, 0x86, 0xe7, 0xf3,
-	0x33, 0xe7, 0x71, 0x38, 0x8f, 0xd2, 0x9c, 0x39, 0x35, 0xcd, 0xa5, 0x67, 0x0e, 0xd6, 0xb7, 0xcd,
-	0x6c, 0xb2, 0x94, 0xb2, 0x7e, 0x6e, 0x40, 0x41, 0x50, 0x26, 0x5d, 0xe4, 0x3a, 0x64, 0x04, 0xe1,
-	0xd2, 0xc6, 0x8e, 0x8c, 0x68, 0xa0, 0x45, 0x33, 0xb9, 0x05, 0xfd, 0x08, 0x8a, 0x6d, 0xdf, 0x1b,
-	0x0e, 0x49, 0x7b, 0x5b, 0x86, 0xc5, 0x64, 0x3c, 0x2c, 0xae, 0xeb, 0xeb, 0x38, 0x86, 0x6e, 0xfd,
-	0x8d, 0x39, 0xa2, 0x08, 0x51, 0x52, 0x54, 0x21, 0x8b, 0xc6, 0x2b, 0xe7, 0xa3, 0xe4, 0xac, 0xf9,
-	0xe8, 0x24, 0x64, 0xba, 0xbe, 0x37, 0x1a, 0x06, 0x95, 0x94, 0x08, 0x13, 0x62, 0x36, 0x5b, 0x9e,
-	0xb2, 0x6e, 0x43, 0x51, 0xb1, 0x72, 0x44, 0x9c, 0xae, 0xc6, 0xe3, 0xf4, 0x46, 0x9b, 0x0c, 0xa8,
-	0xd3, 0x71, 0xc2, 0xc8, 0x2b, 0xf1, 0xad, 0xcf, 0x0d, 0x28, 0xc5, 0x51, 0xd0, 0x0f, 0x35, 0x33,
-	0x67, 0xc7, 0x9d, 0x3f, 0xfa, 0xb8, 0x06, 0x8f, 0x83, 0x01, 0x0f, 0x28, 0xca, 0x05, 0xaa, 0xef,
-	0x41, 0x5e, 0x03, 0xb3, 0x7c, 0xb7, 0x4b, 0x94, 0x49, 0xb2, 0x61, 0xe4, 0x8b, 0x49, 0x61, 0xa6,
-	0x7c, 0x72, 0x2d, 0x79, 0xd5, 0x60, 0x06, 0x3d, 0x3f, 0xa1, 0x49, 0x74, 0x15, 0xcc, 0x8e, 0xef,
-	0xb9, 0x33, 0xa9, 0x89, 0xef, 0x40, 0xdf, 0x83, 0x24, 0xf5, 0x66, 0x52, 0x52, 0x92, 0x7a, 0x4c,
-	0x47, 0x92, 0xf9, 0x14, 0x27, 0x4e, 0xce, 0xac, 0xdf, 0x19, 0xb0, 0xc0, 0xf6, 0x08, 0x09, 0xac,
-	0xf5, 0x46, 0x83, 0x5d, 0xb4, 0x04, 0x25, 0x76, 0xd3, 0x23, 0x47, 0xa6, 0xb5, 0x47, 0x4e, 0x5b,
-	0xb2, 0x59, 0x64, 0x70, 0x95, 0xed, 0x36, 0xda, 0xe8, 0x14, 0xcc, 0x8d, 0x02, 0x81, 0x20, 0x78,
-	0xce, 0xb0, 0xe9, 0x46, 0x1b, 0xbd, 0xa5, 0x5d, 0xc7, 0x64, 0xad, 0x55, 0x76, 0x5c, 0x86, 0x5b,
-	0xb6, 0xe3, 0x87, 0xb1, 0xe5, 0x02, 0x64, 0x5a, 0xec, 0x62, 0x61, 0x27, 0x2c, 0xad, 0x86, 0xc8,
-	0x9c, 0x20, 0x2c, 0x97, 0xad, 0xef, 0x43, 0x2e, 0xdc, 0x3d, 0x35, 0x9b, 0x4e, 0xd5, 0x80, 0x75,
-	0x1d, 0x16, 0x44, 0xcc, 0x9c, 0xbe, 0xb9, 0x30, 0x6d, 0x73, 0x41, 0x6d, 0x3e, 0x0d, 0x69, 0x21,
-	0x15, 0x04, 0x66, 0xdb, 0xa6, 0xb6, 0xda, 0xc2, 0xc6, 0x56, 0x05, 0x4e, 0x3e, 0xf0, 0xed, 0x41,
-	0xd0, 0x21, 0x3e, 0x47, 0x0a, 0x6d, 0xd7, 0x3a, 0x01, 0xc7, 0x59, 0x9c, 0x20, 0x7e, 0xb0, 0xe6,
-	0x8d, 0x06, 0x54, 0xf5, 0x34, 0x97, 0xa0, 0x3c, 0x09, 0x96, 0xa6, 0x5e, 0x86, 0x74, 0x8b, 0x01,
-	0xf8, 0xe9, 0xf3, 0x58, 0x4c, 0xac, 0x5f, 0x1b, 0x80, 0x6e, 0x12, 0xca, 0x8f, 0xde, 0x58, 0x0f,
-	0xb4, 0x7a, 0xd4, 0xb5, 0x69, 0xab, 0x47, 0xfc, 0x40, 0xd5, 0x66, 0x6a, 0xfe, 0xff, 0xa8, 0x47,
-	0xad, 0x2b, 0x70, 0x7c, 0x82, 0x4a, 0xc9, 0x53, 0x15, 0xb2, 0x2d, 0x09, 0x93, 0xf5, 0x43, 0x38,
-	0xb7, 0xfe, 0x90, 0x84, 0xac, 0xd0, 0x2d, 0xe9, 0xa0, 0x2b, 0x90, 0xef, 0x30, 0x5b, 0xf3, 0x87,
-	0xbe, 0x23, 0x45, 0x60, 0x36, 0x17, 0x0e, 0xc6, 0x75, 0x1d, 0x8c, 0xf5, 0x09, 0xba, 0x1c, 0x33,
-	0xbc, 0x66, 0x79, 0x7f, 0x5c, 0xcf, 0xfc, 0x98, 0x19, 0xdf, 0x3a, 0xcb, 0x5e, 0xdc, 0x0c, 0xd7,
-	0x43, 0x73, 0xbc, 0x23, 0xbd, 0x8d, 0x17, 0xa7, 0xcd, 0x77, 0x19, 0xf9, 0x5f, 0x8d, 0xeb, 0x17,
-	0xb4, 0x46, 0x77, 0xe8, 0x7b, 0x2e, 0xa1, 0x3d, 0x32, 0x0a, 0x96, 0x5b, 0x9e, 0xeb, 0x7a, 0x83,
-	0x65, 0xde, 0xac, 0x72, 0xa6, 0x59, 0x0a, 0x66, 0xdb, 0xa5, 0x03, 0x3e, 0x80, 0x39, 0xda, 0xf3,
-	0xbd, 0x51, 0xb7, 0xc7, 0xb3, 0x4b, 0xaa, 0x79, 0x6d, 0xf6, 0xf3, 0xd4, 0x09, 0x58, 0x0d, 0xd0,
-	0x39, 0x26, 0x2d, 0xd2, 0xda, 0x0d, 0x46, 0x2e, 0x4f, 0x4f, 0xf3, 0xaa, 0xbc, 0x09, 0xc1, 0xd6,
-	0xe7, 0x49, 0xa8, 0x73, 0x13, 0x7e, 0xc8, 0xcb, 0xb0, 0x1b, 0x9e, 0x7f, 0x97, 0x50, 0xdf, 0x69,
-	0xdd, 0xb3, 0x5d, 0xa2, 0x6c, 0xa3, 0x0e, 0x79, 0x97, 0x03, 0x1f, 0x69, 0xce, 0x01, 0x6e, 0x88,
-	0x87, 0xce, 0x02, 0x70, 0xb7, 0x13, 0xeb, 0xc2, 0x4f, 0x72, 0x1c, 0xc2, 0x97, 0xd7, 0x26, 0x24,
-	0xb5, 0x3c, 0x23, 0x67, 0x52, 0x42, 0x1b, 0x71, 0x09, 0xcd, 0x7c, 0x4e, 0x28, 0x16, 0xdd, 0xd6,
-	0xd3, 0x93, 0xb6, 0x6e, 0xfd, 0xdd, 0x80, 0xda, 0xa6, 0xa2, 0xfc, 0x15, 0xc5, 0xa1, 0xf8, 0x4d,
-	0xbe, 0x26, 0x7e, 0x53, 0xff, 0x1b, 0xbf, 0xd6, 0x5f, 0x34, 0x97, 0xc7, 0xa4, 0xa3, 0xf8, 0x58,
-	0xd3, 0xd2, 0xc5, 0xeb, 0x20, 0x33, 0xf9, 0x1a, 0xd5, 0x92, 0x8a, 0xa9, 0xe5, 0xfd, 0x28, 0x1c,
-	0x70, 0x0e, 0x64, 0x38, 0x38, 0x0f, 0xa6, 0x4f, 0x3a, 0x2a, 0xf9, 0xa2, 0x78, 0x8c, 0x27, 0x1d,
-	0xcc, 0xd7, 0xad, 0x3f, 0x19, 0x50, 0xba, 0x49, 0xe8, 0x64, 0x59, 0xf3, 0x6d, 0xe2, 0xff, 0x16,
-	0x1c, 0xd3, 0xe8, 0x97, 0xdc, 0xbf, 0x1d, 0xab, 0x65, 0x4e, 0x44, 0xfc, 0x6f, 0x0c, 0xda, 0xe4,
-	0x33, 0xd9, 0x78, 0x4e, 0x96, 0x31, 0x5b, 0x90, 0xd7, 0x16, 0xd1, 0x6a, 0xac, 0x80, 0x99, 0x96,
-	0x54, 0x9b, 0x65, 0xc9, 0x93, 0x68, 0x3d, 0x65, 0xf5, 0x19, 0xa6, 0xfb, 0x6d, 0x40, 0xbc, 0x17,
-	0xe6, 0xc7, 0xea, 0x91, 0x9a, 0x43, 0xef, 0x84, 0xf5, 0x4c, 0x38, 0x47, 0xe7, 0xc0, 0xf4, 0xbd,
-	0x27, 0xaa, 0x32, 0x9d, 0x8f, 0xae, 0xc4, 0xde, 0x13, 0xcc, 0x97, 0xac, 0xeb, 0x90, 0xc2, 0xde,
-	0x13, 0x54, 0x03, 0xf0, 0xed, 0x41, 0x97, 0x3c, 0x0c, 0xfb, 0x91, 0x02, 0xd6, 0x20, 0x47, 0xe4,
-	0xd7, 0x35, 0x38, 0xa6, 0x53, 0x24, 0xd4, 0xdd, 0x80, 0x39, 0x06, 0x74, 0xa6, 0xbd, 0xef, 0x71,
-	0x44, 0xd1, 0xd0, 0x2b, 0x24, 0x66, 0x33, 0x10, 0xc1, 0xd1, 0x19, 0xc8, 0x51, 0x7b, 0xa7, 0x4f,
-	0xee, 0x45, 0x3e, 0x1f, 0x01, 0xd8, 0x2a, 0x6b, 0xa5, 0x1e, 0x6a, 0x85, 0x42, 0x04, 0x40, 0x17,
-	0xa1, 0x14, 0xd1, 0xbc, 0xe5, 0x93, 0x8e, 0xf3, 0x19, 0xd7, 0x70, 0x01, 0x1f, 0x82, 0xa3, 0x25,
-	0x58, 0x88, 0x60, 0xdb, 0x3c, 0xed, 0x9a, 0x1c, 0x35, 0x0e, 0x66, 0xb2, 0xe1, 0xec, 0x7e, 0xf0,
-	0x78, 0x64, 0xf7, 0x79, 0x20, 0x2b, 0x60, 0x0d, 0x62, 0xfd, 0xd9, 0x80, 0x63, 0x42, 0xd5, 0xd4,
-	0xa6, 0xdf, 0x4a, 0xab, 0xff, 0x8d, 0x01, 0x48, 0xe7, 0x40, 0x9a, 0xd6, 0x77, 0xf4, 0x27, 0x1f,
-	0x96, 0xd7, 0xf3, 0xd3, 0xde, 0x34, 0x59, 0x0b, 0x2a, 0x4b, 0x40, 0xfe, 0xc6, 0x2a, 0x5a, 0x50,
-	0x01, 0x51, 0xd5, 0x1f, 0xeb, 0x9c, 0x77, 0xf6, 0x28, 0x09, 0x64, 0x03, 0xc9, 0x3b, 0x67, 0x0e,
-	0xc0, 0xe2, 0x8f, 0xdd, 0xa5, 0x1e, 0x18, 0xcc, 0xe8, 0xae, 0xf8, 0x23, 0xc2, 0xc5, 0xf3, 0x90,
-	0x0b, 0x5f, 0x17, 0x51, 0x1e, 0xe6, 0x6e, 0xdc, 0xc7, 0x1f, 0xad, 0xe2, 0xf5, 0x52, 0x02, 0x15,
-	0x20, 0xdb, 0x5c, 0x5d, 0xbb, 0xc3, 0x67, 0xc6, 0xca, 0x2a, 0x64, 0xb6, 0x46, 0x41, 0x8f, 0xf8,
-	0xe8, 0x5d, 0x30, 0xd9, 0x08, 0x69, 0x4e, 0xab, 0x3d, 0xed, 0x56, 0x4f, 0xc6, 0xc1, 0xb2, 0x06,
-	0x4c, 0xac, 0xfc, 0xd1, 0x54, 0x86, 0xec, 0xa3, 0x1f, 0x40, 0x5a, 0x58, 0xa7, 0x86, 0xae, 0x3f,
-	0x33, 0x56, 0x4f, 0x1d, 0x82, 0xab, 0x73, 0xbe, 0x6b, 0xa0, 0x7b, 0x90, 0xe7, 0x40, 0xd9, 0xf6,
-	0x9f, 0x89, 0x77, 0xdf, 0x13, 0x27, 0x9d, 0x3d, 0x62, 0x55, 0x3b, 0xef, 0x1a, 0xa4, 0x79, 0x80,
-	0xd0, 0xa9, 0xd1, 0x1f, 0xab, 0x74, 0x6a, 0x26, 0x1e, 0x85, 0xac, 0x04, 0x7a, 0x0f, 0x4c, 0x56,
-	0xc4, 0xea, 0xe2, 0xd0, 0xba, 0x75, 0x5d, 0x1c, 0x7a, 0xab, 0xcc, 0xaf, 0x7d, 0x3f, 0x7c, 0x74,
-	0x38, 0x15, 0xef, 0xbe, 0xd4, 0xf6, 0xca, 0xe1, 0x85, 0xf0, 0xe6, 0xfb, 0xa2, 0xfb, 0x56, 0xe5,
-	0x33, 0x3a, 0x3b, 0x79, 0x55, 0xac, 0xda, 0xae, 0xd6, 0x8e, 0x5a, 0x0e, 0x0f, 0xdc, 0x84, 0xbc,
-	0x56, 0xba, 0xea, 0x62, 0x3d, 0x5c, 0x77, 0xeb, 0x62, 0x9d, 0x52, 0xef, 0x5a, 0x09, 0x74, 0x13,
-	0xb2, 0x2c, 0xf2, 0x33, 0x07, 0x40, 0xa7, 0xe3, 0x01, 0x5e, 0x73, 0xec, 0xea, 0x99, 0xe9, 0x8b,
-	0xa1, 0xdd, 0x7c, 0x02, 0x59, 0xd5, 0x65, 0xa1, 0x0f, 0xa1, 0x38, 0xd9, 0x63, 0xa0, 0x37, 0x34,
-	0xb6, 0x26, 0x5b, 0xb7, 0xea, 0xa2, 0xb6, 0x34, 0xbd, 0x31, 0x49, 0x2c, 0x19, 0x2b, 0x9f, 0xa8,
-	0x4f, 0x21, 0xeb, 0x36, 0xb5, 0xd1, 0x7d, 0x28, 0x72, 0xaa, 0xc3, 0x4f, 0x25, 0x13, 0xd6, 0x75,
-	0xe8, 0xbb, 0xcc, 0x84, 0x75, 0x1d, 0xfe, 0x3e, 0x63, 0x25, 0x9a, 0x1f, 0x3f, 0x7b, 0x5e, 0x4b,
-	0x7c, 0xf9, 0xbc, 0x96, 0xf8, 0xfa, 0x79, 0xcd, 0xf8, 0xd9, 0x7e, 0xcd, 0xf8, 0xed, 0x7e, 0xcd,
-	0x78, 0xba, 0x5f, 0x33, 0x9e, 0xed, 0xd7, 0x8c, 0x7f, 0xee, 0xd7, 0x8c, 0x7f, 0xed, 0xd7, 0x12,
-	0x5f, 0xef, 0xd7, 0x8c, 0x2f, 0x5e, 0xd4, 0x12, 0xcf, 0x5e, 0xd4, 0x12, 0x5f, 0xbe, 0xa8, 0x25,
-	0x3e, 0x7e, 0x53, 0xff, 0xec, 0xe4, 0xdb, 0x1d, 0x7b, 0x60, 0x2f, 0xf7, 0xbd, 0x5d, 0x67, 0x59,
-	0xff, 0x6a, 0xb5, 0x93, 0xe1, 0x7f, 0x6f, 0xff, 0x27, 0x00, 0x00, 0xff, 0xff, 0x24, 0x30, 0x33,
-	0x0c, 0xcc, 0x1a, 0x00, 0x00,
+	// 2424 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x59, 0xcd, 0x6f, 0x1b, 0xc7,
+	0x15, 0xe7, 0xf2, 0x9b, 0x8f, 0x14, 0x45, 0x8f, 0x68, 0x89, 0xa1, 0x2d, 0x52, 0x5e, 0xb8, 0x8e,
+	0xe0, 0xd8, 0x92, 0xad, 0xb4, 0x89, 0x63, 0x37, 0x6d, 0x45, 0xc9, 0x1f, 0xb2, 0xe5, 0xaf, 0x95,
+	0xea, 0x00, 0x01, 0x02, 0x61, 0x45, 0x0e, 0x29, 0x42, 0xdc, 0x5d, 0x7a, 0x77, 0x18, 0x5b, 0x40,
+	0x81, 0x16, 0x3d, 0xf4, 0xd4, 0x02, 0xe9, 0x29, 0xe8, 0xbd, 0x40, 0x8b, 0x1e, 0xfb, 0x07, 0xb4,
+	0x45, 0x2f, 0xf5, 0xd1, 0xc7, 0x20, 0x07, 0xb6, 0x96, 0x2f, 0x85, 0x7a, 0x09, 0xd0, 0x73, 0x8b,
+	0x62, 0xbe, 0x76, 0x67, 0x57, 0x14, 0x6c, 0x2a, 0x06, 0x0a, 0x5f, 0xc8, 0x99, 0x37, 0x33, 0xef,
+	0xcd, 0xfb, 0xcd, 0xfb, 0x9a, 0x59, 0x38, 0xd5, 0xdf, 0xed, 0x2c, 0xf6, 0x9c, 0x4e, 0xdf, 0x75,
+	0x88, 0xe3, 0x37, 0x16, 0xd8, 0x2f, 0xca, 0xca, 0x7e, 0xf5, 0x62, 0xa7, 0x4b, 0x76, 0x06, 0xdb,
+	0x0b, 0x4d, 0xc7, 0x5a, 0xec, 0x38, 0x1d, 0x67, 0x91, 0x91, 0xb7, 0x07, 0x6d, 0xd6, 0xe3, 0x8b,
+	0x69, 0x8b, 0x2f, 0xac, 0xd6, 0x3b, 0x8e, 0xd3, 0xe9, 0xe1, 0x60, 0x16, 0xe9, 0x5a, 0xd8, 0x23,
+	0xa6, 0xd5, 0x17, 0x13, 0xe6, 0x84, 0xd8, 0xc7, 0x3d, 0xcb, 0x69, 0xe1, 0xde, 0xa2, 0x47, 0x4c,
+	0xe2, 0xf1, 0x5f, 0x3e, 0x43, 0x2f, 0x03, 0xda, 0x20, 0x2e, 0x36, 0x2d, 0xc3, 0x24, 0xd8, 0x33,
+	0xf0, 0xe3, 0x01, 0xf6, 0x88, 0x7e, 0x17, 0xa6, 0x42, 0x54, 0xaf, 0xef, 0xd8, 0x1e, 0x46, 0x1f,
+	0x40, 0xde, 0x0b, 0xc8, 0x15, 0x6d, 0x2e, 0x31, 0x9f, 0x5f, 0x2a, 0x2f, 0xf8, 0xea, 0x04, 0x6b,
+	0x0c, 0x75, 0xa2, 0x6e, 0x03, 0x04, 0x43, 0xa8, 0x06, 0xc0, 0x07, 0x6f, 0x99, 0xde, 0x4e, 0x45,
+	0x9b, 0xd3, 0xe6, 0x93, 0x86, 0x42, 0x41, 0x17, 0xe0, 0x44, 0xd0, 0xbb, 0xe7, 0x6c, 0xec, 0x98,
+	0x6e, 0xab, 0x12, 0x67, 0xd3, 0x0e, 0x0f, 0x20, 0x04, 0x49, 0xd7, 0x24, 0xb8, 0x92, 0x98, 0xd3,
+	0xe6, 0x13, 0x06, 0x6b, 0xeb, 0x9f, 0x40, 0xfe, 0xc1, 0xc0, 0xdb, 0x11, 0xda, 0xa0, 0x5b, 0x90,
+	0xe1, 0xeb, 0xe4, 0x96, 0x67, 0xa2, 0x5b, 0x5e, 0x6e, 0x99, 0x7d, 0x82, 0xdd, 0xc6, 0xc9, 0xaf,
+	0x87, 0xf5, 0x34, 0x27, 0x1d, 0x0c, 0xeb, 0x72, 0x95, 0x21, 0x1b, 0x7a, 0x11, 0x0a, 0x9c, 0x31,
+	0x07, 0x44, 0xff, 0x5b, 0x1c, 0x0a, 0x0f, 0x07, 0xd8, 0xdd, 0x93, 0xa2, 0xaa, 0x90, 0xf5, 0x70,
+	0x0f, 0x37, 0x89, 0xe3, 0x32, 0xcd, 0x72, 0x86, 0xdf, 0x47, 0x65, 0x48, 0xf5, 0xba, 0x56, 0x97,
+	0x30, 0x5d, 0x26, 0x0c, 0xde, 0x41, 0x57, 0x21, 0xe5, 0x11, 0xd3, 0x25, 0x4c, 0x81, 0xfc, 0x52,
+	0x75, 0x81, 0x9f, 0xe9, 0x82, 0x3c, 0xd3, 0x85, 0x4d, 0x79, 0xa6, 0x8d, 0xec, 0xb3, 0x61, 0x3d,
+	0xf6, 0xc5, 0xdf, 0xeb, 0x9a, 0xc1, 0x97, 0xa0, 0x0f, 0x20, 0x81, 0xed, 0x56, 0x25, 0x39, 0xc6,
+	0x4a, 0xba, 0x00, 0x5d, 0x86, 0x5c, 0xab, 0xeb, 0xe2, 0x26, 0xe9, 0x3a, 0x76, 0x25, 0x35, 0xa7,
+	0xcd, 0x17, 0x97, 0xa6, 0x02, 0x48, 0x56, 0xe5, 0x90, 0x11, 0xcc, 0x42, 0x17, 0x20, 0xed, 0x51,
+	0xbc, 0xbd, 0x4a, 0x66, 0x2e, 0x31, 0x9f, 0x6b, 0x94, 0x0f, 0x86, 0xf5, 0x12, 0xa7, 0x5c, 0x70,
+	0xac, 0x2e, 0xc1, 0x56, 0x9f, 0xec, 0x19, 0x62, 0x0e, 0x3a, 0x0f, 0x99, 0x16, 0xee, 0x61, 0x6a,
+	0x24, 0x59, 0x86, 0x78, 0x49, 0x61, 0xcf, 0x06, 0x0c, 0x39, 0xe1, 0x76, 0x32, 0x9b, 0x2e, 0x65,
+	0xf4, 0xff, 0x6a, 0x80, 0x36, 0x4c, 0xab, 0xdf, 0xc3, 0xaf, 0x8d, 0xa7, 0x8f, 0x5c, 0xfc, 0xd8,
+	0xc8, 0x25, 0xc6, 0x45, 0x2e, 0x80, 0x21, 0x39, 0x1e, 0x0c, 0xa9, 0x57, 0xc0, 0xa0, 0xaf, 0x43,
+	0x9a, 0x93, 0x5e, 0x65, 0x43, 0x81, 0xce, 0x09, 0xa9, 0x4d, 0x29, 0xd0, 0x26, 0xc1, 0xf6, 0xa9,
+	0xff, 0x14, 0x26, 0x04, 0x8e, 0xc2, 0x75, 0x97, 0x5f, 0xdb, 0x07, 0x8a, 0xcf, 0x86, 0x75, 0x2d,
+	0xf0, 0x03, 0xdf, 0xf8, 0xd1, 0x7b, 0x4c, 0x36, 0xf1, 0x04, 0xde, 0x93, 0x0b, 0xac, 0xb7, 0xb0,
+	0x66, 0x77, 0xb0, 0x47, 0x17, 0x26, 0x29, 0x54, 0x06, 0x9f, 0xa3, 0xff, 0x04, 0xa6, 0x42, 0xc7,
+	0x29, 0xb6, 0x71, 0x05, 0xd2, 0x1e, 0x76, 0xbb, 0x7e, 0xf0, 0x50, 0x00, 0xd9, 0x60, 0x74, 0x45,
+	0x3c, 0xeb, 0x1b, 0x62, 0xfe, 0x78, 0xd2, 0xf7, 0x35, 0x28, 0xac, 0x9b, 0xdb, 0xb8, 0x27, 0xed,
+	0x08, 0x41, 0xd2, 0x36, 0x2d, 0x2c, 0xf0, 0x64, 0x6d, 0x34, 0x0d, 0xe9, 0xcf, 0xcd, 0xde, 0x00,
+	0x73, 0x96, 0x59, 0x43, 0xf4, 0xc6, 0xf5, 0x48, 0xed, 0xd8, 0x1e, 0xa9, 0x05, 0x76, 0xe5, 0xc7,
+	0x86, 0x94, 0x1a, 0x1b, 0x4e, 0x43, 0xae, 0x6f, 0x76, 0xf0, 0xa6, 0xb3, 0x8b, 0xed, 0x4a, 0x9a,
+	0x6d, 0x3d, 0x20, 0xe8, 0x77, 0x61, 0x42, 0xe8, 0x28, 0xc0, 0x0d, 0x14, 0xa2, 0xe0, 0xe6, 0x7c,
+	0x85, 0xce, 0xc2, 0x84, 0x8d, 0x9f, 0x92, 0x07, 0x3e, 0xab, 0x38, 0x63, 0x15, 0x26, 0xea, 0xbf,
+	0xd6, 0x60, 0x22, 0x64, 0x09, 0x48, 0x87, 0x74, 0x8f, 0x0a, 0xf0, 0x38, 0x6c, 0x0d, 0x38, 0x18,
+	0xd6, 0x05, 0xc5, 0x10, 0xff, 0xd4, 0xae, 0xb0, 0x4d, 0xd8, 0x89, 0xc6, 0xd9, 0x89, 0x4e, 0x07,
+	0x27, 0x7a, 0xdd, 0x26, 0xee, 0x9e, 0x34, 0xab, 0x49, 0x7a, 0x3e, 0x34, 0xa8, 0x8a, 0xe9, 0x86,
+	0x6c, 0xa0, 0x77, 0x20, 0xb9, 0x43, 0x33, 0x01, 0x85, 0x3b, 0xd9, 0x48, 0x1d, 0x0c, 0xeb, 0xda,
+	0x45, 0x83, 0x91, 0xf4, 0x7f, 0x69, 0x50, 0x50, 0xb9, 0xa0, 0x5b, 0x90, 0xf3, 0x73, 0x5c, 0x45,
+	0x7b, 0x25, 0xca, 0x45, 0x21, 0x34, 0x4e, 0x3c, 0x86, 0x75, 0xb0, 0x18, 0x9d, 0x86, 0x64, 0xaf,
+	0x6b, 0x63, 0x8e, 0x45, 0x23, 0x7b, 0x30, 0xac, 0xb3, 0xbe, 0xc1, 0x7e, 0x91, 0x07, 0x25, 0xdb,
+	0xb1, 0xd7, 0xec, 0x16, 0x7e, 0x8a, 0x5b, 0xeb, 0x1c, 0x84, 0x04, 0xd3, 0x4f, 0x09, 0x94, 0x8c,
+	0xfe, 0xc0, 0xec, 0xba, 0x8d, 0x25, 0x2a, 0xe7, 0xeb, 0x61, 0x9d, 0x1b, 0x9d, 0xd8, 0xec, 0xc1,
+	0xb0, 0x5e, 0x8d, 0x32, 0x51, 0x82, 0xc4, 0x21, 0x01, 0xba, 0x05, 0x69, 0xee, 0x33, 0xe8, 0x6c,
+	0x54, 0xcd, 0x44, 0x23, 0xcd, 0xd5, 0x50, 0x55, 0xa8, 0x43, 0x8a, 0x9d, 0x30, 0xd3, 0x41, 0x6b,
+	0xe4, 0x0e, 0x86, 0x75, 0x4e, 0x30, 0xf8, 0x1f, 0xd5, 0x51, 0x41, 0x96, 0xe9, 0x48, 0xfb, 0x02,
+	0xdc, 0x9b, 0x50, 0x58, 0xc7, 0x1d, 0xb3, 0xb9, 0x27, 0x84, 0x96, 0x25, 0x3b, 0x2a, 0x50, 0x93,
+	0x3c, 0xce, 0x40, 0xc1, 0x97, 0xb8, 0x65, 0x79, 0x22, 0xf0, 0xe4, 0x7d, 0xda, 0x5d, 0x4f, 0xff,
+	0x8d, 0x06, 0xc2, 0x5b, 0x5f, 0xcb, 0x64, 0xae, 0x41, 0xc6, 0x63, 0x12, 0xa5, 0xc9, 0xa8, 0x41,
+	0x80, 0x0d, 0x04, 0xc6, 0x22, 0x26, 0x1a, 0xb2, 0x81, 0x16, 0x42, 0xc5, 0x03, 0x57, 0xac, 0x78,
+	0x30, 0xac, 0x2b, 0x54, 0xb5, 0x98, 0xd0, 0xbf, 0xd4, 0x20, 0xbf, 0x69, 0x76, 0xfd, 0x40, 0x50,
+	0x86, 0xd4, 0x63, 0x1a, 0x91, 0x44, 0x24, 0xe0, 0x1d, 0x1a, 0x72, 0x5b, 0xb8, 0x67, 0xee, 0xdd,
+	0x70, 0x5c, 0xc6, 0x73, 0xc2, 0xf0, 0xfb, 0x81, 0x6b, 0x26, 0x47, 0xa6, 0xed, 0xd4, 0xd8, 0xc9,
+	0xe7, 0x76, 0x32, 0x1b, 0x2f, 0x25, 0xf4, 0x5f, 0x6a, 0x50, 0xe0, 0x3b, 0x13, 0xee, 0x7b, 0x0d,
+	0xd2, 0x7c, 0xe3, 0xc2, 0xb0, 0x8f, 0x8c, 0xd0, 0xa0, 0x44, 0x67, 0xb1, 0x04, 0xfd, 0x10, 0x8a,
+	0x2d, 0xd7, 0xe9, 0xf7, 0x71, 0x6b, 0x43, 0x84, 0xf9, 0x78, 0x34, 0xcc, 0xaf, 0xaa, 0xe3, 0x46,
+	0x64, 0xba, 0xfe, 0x1f, 0xea, 0xfe, 0x3c, 0xe4, 0x0a, 0xa8, 0x7c, 0x15, 0xb5, 0x63, 0xe7, 0xd7,
+	0xf8, 0xb8, 0xf9, 0x75, 0x1a, 0xd2, 0x1d, 0xd7, 0x19, 0xf4, 0xb9, 0xb7, 0xe5, 0x0c, 0xd1, 0x1b,
+	0x33, 0xef, 0x1e, 0x27, 0x9a, 0xf6, 0xa1, 0x28, 0xd5, 0x3f, 0x22, 0x57, 0x55, 0xa3, 0xb9, 0x6a,
+	0xad, 0x85, 0x6d, 0xd2, 0x6d, 0x77, 0xfd, 0xec, 0x23, 0x73, 0xd5, 0xeb, 0x05, 0xdc, 0x5f, 0x69,
+	0x50, 0x8a, 0x32, 0x42, 0x3f, 0x50, 0x1c, 0x88, 0x0a, 0x3d, 0x77, 0xb4, 0x50, 0x1e, 0x7f, 0x3c,
+	0x16, 0x1f, 0xa5, 0x73, 0x55, 0x3f, 0x82, 0xbc, 0x42, 0xa6, 0x95, 0xc1, 0x2e, 0x96, 0xc6, 0x4e,
+	0x9b, 0x81, 0x97, 0xf3, 0x3d, 0xf1, 0xce, 0xd5, 0xf8, 0x15, 0x8d, 0xba, 0xca, 0x44, 0xc8, 0x46,
+	0xd0, 0x15, 0x48, 0xb6, 0x5d, 0xc7, 0x1a, 0xcb, 0x00, 0xd8, 0x0a, 0xf4, 0x5d, 0x88, 0x13, 0x67,
+	0xac, 0xe3, 0x8f, 0x13, 0x87, 0x9e, 0x7e, 0x4f, 0xc6, 0x5a, 0xba, 0x39, 0xd1, 0xd3, 0xff, 0xa0,
+	0xc1, 0x24, 0x5d, 0xc3, 0x11, 0x58, 0xd9, 0x19, 0xd8, 0xbb, 0x68, 0x1e, 0x4a, 0x54, 0xd2, 0x56,
+	0x57, 0x14, 0x00, 0x5b, 0xdd, 0x96, 0x50, 0xb3, 0x48, 0xe9, 0xb2, 0x2e, 0x58, 0x6b, 0xa1, 0x19,
+	0xc8, 0x0c, 0x3c, 0x3e, 0x81, 0xeb, 0x9c, 0xa6, 0xdd, 0xb5, 0x16, 0x7a, 0x4f, 0x11, 0x77, 0x54,
+	0x68, 0xf7, 0xa3, 0xd6, 0xbb, 0x90, 0x6e, 0x52, 0xc1, 0xdc, 0x02, 0x69, 0x01, 0xe2, 0x4f, 0x66,
+	0x1b, 0x32, 0xc4, 0xb0, 0xfe, 0x3d, 0xc8, 0xf9, 0xab, 0x47, 0xd6, 0x1d, 0x23, 0x4f, 0x40, 0xbf,
+	0x06, 0x93, 0x3c, 0x1a, 0x8f, 0x5e, 0x5c, 0x18, 0xb5, 0xb8, 0x20, 0x17, 0x9f, 0x82, 0x14, 0x47,
+	0x05, 0x41, 0xb2, 0x65, 0x12, 0x53, 0x2e, 0xa1, 0x6d, 0xbd, 0x02, 0xd3, 0x9b, 0xae, 0x69, 0x7b,
+	0x6d, 0xec, 0xb2, 0x49, 0xbe, 0x85, 0xeb, 0x27, 0x61, 0x8a, 0x46, 0x20, 0xec, 0x7a, 0x2b, 0xce,
+	0xc0, 0x26, 0xf2, 0xf6, 0x77, 0x01, 0xca, 0x61, 0xb2, 0x70, 0x88, 0x32, 0xa4, 0x9a, 0x94, 0xc0,
+	0xb8, 0x4f, 0x18, 0xbc, 0xa3, 0xff, 0x56, 0x03, 0x74, 0x13, 0x13, 0xc6, 0x7a, 0x6d, 0xd5, 0x53,
+	0x2a, 0x77, 0xcb, 0x24, 0xcd, 0x1d, 0xec, 0x7a, 0xb2, 0x8a, 0x95, 0xfd, 0xff, 0x47, 0xe5, 0xae,
+	0x5f, 0x86, 0xa9, 0xd0, 0x2e, 0x85, 0x4e, 0x55, 0xc8, 0x36, 0x05, 0x4d, 0x54, 0x4d, 0x7e, 0x5f,
+	0xff, 0x63, 0x1c, 0xb2, 0xfc, 0x6c, 0x71, 0x1b, 0x5d, 0x86, 0x7c, 0x9b, 0xda, 0x9a, 0xdb, 0x77,
+	0xbb, 0x02, 0x82, 0x64, 0x63, 0xf2, 0x60, 0x58, 0x57, 0xc9, 0x86, 0xda, 0x41, 0x17, 0x23, 0x86,
+	0xd7, 0x28, 0xef, 0x0f, 0xeb, 0xe9, 0x1f, 0x53, 0xe3, 0x5b, 0xa5, 0x79, 0x91, 0x99, 0xe1, 0xaa,
+	0x6f, 0x8e, 0x77, 0x84, 0xb7, 0xb1, 0x32, 0xbe, 0xf1, 0xa1, 0x28, 0x29, 0xde, 0x55, 0x9e, 0x04,
+	0xfa, 0xae, 0x63, 0x61, 0xb2, 0x83, 0x07, 0xde, 0x62, 0xd3, 0xb1, 0x2c, 0xc7, 0x5e, 0x64, 0xd7,
+	0x7a, 0xa6, 0x34, 0x4d, 0xee, 0x74, 0xb9, 0x70, 0xc0, 0x4d, 0xc8, 0x90, 0x1d, 0xd7, 0x19, 0x74,
+	0x76, 0x58, 0xde, 0x4a, 0x34, 0xae, 0x8e, 0xcf, 0x4f, 0x72, 0x30, 0x64, 0x03, 0x9d, 0xa1, 0x68,
+	0xe1, 0xe6, 0xae, 0x37, 0xb0, 0x78, 0x6c, 0x95, 0xe5, 0x9a, 0x4f, 0xd6, 0xff, 0x1a, 0x87, 0x3a,
+	0x33, 0xe1, 0x47, 0xac, 0xf8, 0xbc, 0xe1, 0xb8, 0x77, 0x31, 0x71, 0xbb, 0xcd, 0x7b, 0xa6, 0x85,
+	0xa5, 0x6d, 0xd4, 0x21, 0x6f, 0x31, 0xe2, 0x96, 0xe2, 0x1c, 0x60, 0xf9, 0xf3, 0xd0, 0x2c, 0x00,
+	0x73, 0x3b, 0x3e, 0xce, 0xfd, 0x24, 0xc7, 0x28, 0x6c, 0x78, 0x25, 0x84, 0xd4, 0xe2, 0x98, 0x9a,
+	0x09, 0x84, 0xd6, 0xa2, 0x08, 0x8d, 0xcd, 0xc7, 0x87, 0x45, 0xb5, 0xf5, 0x54, 0xc4, 0xd6, 0xfd,
+	0x5c, 0x94, 0x56, 0x73, 0xd1, 0x2c, 0x00, 0x4d, 0x3d, 0x5b, 0x84, 0xa5, 0x87, 0x4c, 0x34, 0x19,
+	0xfd, 0x22, 0x0e, 0xb5, 0x75, 0xa9, 0xee, 0x31, 0x31, 0x94, 0x20, 0xc5, 0xdf, 0x10, 0x48, 0x89,
+	0x6f, 0x09, 0xd2, 0xe8, 0x3a, 0x2a, 0x0c, 0x44, 0x2a, 0x0a, 0xc4, 0x5f, 0x94, 0xe0, 0x62, 0xe0,
+	0xb6, 0x54, 0x7e, 0x45, 0x49, 0x4c, 0x6f, 0x42, 0xb7, 0xf8, 0x1b, 0x34, 0x80, 0x44, 0xd8, 0x00,
+	0xf4, 0x8f, 0x61, 0x2a, 0xa4, 0x81, 0x08, 0x3c, 0xe7, 0x20, 0xe9, 0xe2, 0xb6, 0x4c, 0xf3, 0x28,
+	0x9a, 0x4d, 0x70, 0xdb, 0x60, 0xe3, 0xfa, 0xbf, 0x35, 0x28, 0xdd, 0xc4, 0x24, 0x5c, 0x9a, 0xbd,
+	0x45, 0xfa, 0x1f, 0xef, 0xdc, 0xfb, 0x70, 0x42, 0x51, 0x5a, 0x40, 0xf6, 0x7e, 0xa4, 0x20, 0x3b,
+	0x19, 0x80, 0xc6, 0x2e, 0x52, 0x7c, 0x7a, 0xa4, 0x16, 0x3b, 0x07, 0x93, 0xb4, 0xec, 0xda, 0x52,
+	0xa4, 0x8d, 0xac, 0xc6, 0x1e, 0x40, 0x5e, 0x61, 0x82, 0x96, 0x23, 0x75, 0xd8, 0xc8, 0x6b, 0x5f,
+	0x79, 0xd4, 0xb5, 0xcf, 0xaf, 0x5a, 0x36, 0x00, 0xb1, 0xc7, 0x0f, 0xc6, 0x56, 0x4d, 0x38, 0x8c,
+	0x7a, 0xc7, 0x2f, 0xcb, 0xfc, 0x3e, 0x3a, 0x03, 0x49, 0xd7, 0x79, 0x22, 0x4b, 0xf7, 0x89, 0x40,
+	0xa4, 0xe1, 0x3c, 0x31, 0xd8, 0x90, 0x7e, 0x0d, 0x12, 0x86, 0xf3, 0x84, 0xbe, 0xa1, 0xba, 0xa6,
+	0xdd, 0xc1, 0x8f, 0xfc, 0x0b, 0x5b, 0xc1, 0x50, 0x28, 0x47, 0x94, 0x09, 0x2b, 0x70, 0x42, 0xdd,
+	0x11, 0xb7, 0xa5, 0x05, 0xc8, 0x3c, 0x1c, 0xa8, 0xb0, 0x96, 0x23, 0xb0, 0xb2, 0x25, 0x86, 0x9c,
+	0xa4, 0xff, 0x49, 0x03, 0x08, 0xe8, 0xb4, 0xaa, 0x26, 0xe6, 0x76, 0x0f, 0xdf, 0x0b, 0xa2, 0x50,
+	0x40, 0xa0, 0xa3, 0xf4, 0xae, 0xf9, 0x48, 0xa9, 0x77, 0x02, 0x02, 0x3a, 0x0f, 0xa5, 0x60, 0xcf,
+	0x0f, 0x5c, 0xdc, 0xee, 0x3e, 0x65, 0xe6, 0x53, 0x30, 0x0e, 0xd1, 0xd1, 0x3c, 0x4c, 0x06, 0xb4,
+	0x0d, 0x56, 0x3d, 0x24, 0xd9, 0xd4, 0x28, 0x99, 0x62, 0xc3, 0xd4, 0xbd, 0xfe, 0x78, 0x60, 0xf6,
+	0x98, 0x69, 0x15, 0x0c, 0x85, 0xa2, 0xff, 0x59, 0x83, 0x13, 0xfc, 0xa8, 0x89, 0x49, 0xde, 0x46,
+	0x97, 0xd2, 0x7f, 0xa7, 0x01, 0x52, 0x35, 0x10, 0xa6, 0xf5, 0x1d, 0xf5, 0x8d, 0x8f, 0x96, 0x27,
+	0xf9, 0x51, 0x8f, 0xd8, 0xf4, 0x8e, 0x2e, 0x2a, 0x59, 0xf6, 0xa8, 0xce, 0xef, 0xe8, 0x9c, 0x22,
+	0x8b, 0x58, 0xfa, 0xb4, 0xb0, 0xbd, 0x47, 0xb0, 0x27, 0x6e, 0xd8, 0xec, 0x69, 0x81, 0x11, 0x0c,
+	0xfe, 0x47, 0x65, 0xc9, 0x77, 0x9f, 0x64, 0x20, 0x2b, 0xfa, 0xb6, 0xa3, 0x7f, 0x19, 0x87, 0x89,
+	0x47, 0x4e, 0x6f, 0x60, 0xe1, 0xb7, 0x10, 0xe7, 0x70, 0xe8, 0x4a, 0xc9, 0xd0, 0xa5, 0x43, 0x81,
+	0x98, 0x6e, 0x07, 0x13, 0xf1, 0x2e, 0x94, 0x62, 0x65, 0x63, 0x88, 0x86, 0xe6, 0x20, 0x6f, 0x76,
+	0x3a, 0x2e, 0xee, 0x98, 0x04, 0x37, 0xf6, 0xc4, 0x6d, 0x53, 0x25, 0xe9, 0x4d, 0x28, 0x4a, 0x60,
+	0xfc, 0xfb, 0x7f, 0xe6, 0x73, 0x46, 0x19, 0xf1, 0x38, 0xca, 0xa7, 0x06, 0xef, 0x22, 0x62, 0xa2,
+	0x21, 0x1b, 0xe1, 0x8f, 0x0b, 0x72, 0xab, 0xfa, 0x6d, 0x48, 0xf3, 0x95, 0xf4, 0x29, 0x28, 0xa8,
+	0x13, 0xf8, 0x53, 0x10, 0xed, 0x8b, 0x5b, 0x85, 0x0e, 0x69, 0xce, 0x48, 0x35, 0x09, 0x4e, 0x31,
+	0xc4, 0xff, 0xf9, 0x73, 0x90, 0xf3, 0xbf, 0x0c, 0xa0, 0x3c, 0x64, 0x6e, 0xdc, 0x37, 0x3e, 0x59,
+	0x36, 0x56, 0x4b, 0x31, 0x54, 0x80, 0x6c, 0x63, 0x79, 0xe5, 0x0e, 0xeb, 0x69, 0x4b, 0xcb, 0x90,
+	0xa6, 0xdf, 0x48, 0xb0, 0x8b, 0x3e, 0x84, 0x24, 0x6d, 0x21, 0x25, 0x4e, 0x2b, 0x9f, 0x65, 0xaa,
+	0xd3, 0x51, 0xb2, 0xb8, 0x95, 0xc4, 0x96, 0x7e, 0x9e, 0x96, 0x31, 0xc9, 0x45, 0xdf, 0x87, 0x14,
+	0x0f, 0x34, 0xca, 0x74, 0xf5, 0x13, 0x41, 0x75, 0xe6, 0x10, 0x5d, 0xf2, 0xb9, 0xa4, 0xa1, 0x7b,
+	0x90, 0x67, 0x44, 0xf1, 0xc4, 0x75, 0x3a, 0xfa, 0xd2, 0x14, 0xe2, 0x34, 0x7b, 0xc4, 0xa8, 0xc2,
+	0xef, 0x2a, 0xa4, 0xd8, 0x09, 0xab, 0xbb, 0x51, 0x1f, 0x9a, 0xab, 0x33, 0x87, 0xe8, 0x72, 0x35,
+	0xfa, 0x08, 0x92, 0xf4, 0x5a, 0xa5, 0xc2, 0xa1, 0xbc, 0x4c, 0x55, 0xa7, 0xa3, 0x64, 0x45, 0xec,
+	0xc7, 0xfe, 0x03, 0xdb, 0x4c, 0xf4, 0x3d, 0x40, 0x2e, 0xaf, 0x1c, 0x1e, 0xf0, 0x25, 0xdf, 0x87,
+	0x82, 0x7a, 0xa1, 0x43, 0xb3, 0x61, 0x51, 0x91, 0xfb, 0x5f, 0xb5, 0x76, 0xd4, 0xb0, 0xcf, 0x70,
+	0x1d, 0xf2, 0xca, 0x65, 0x4a, 0x85, 0xf5, 0xf0, 0x4d, 0xb0, 0x3a, 0x7b, 0xc4, 0xa8, 0xcf, 0xed,
+	0x26, 0x64, 0x69, 0xb2, 0xa7, 0xb1, 0x0c, 0x9d, 0x8a, 0xe6, 0x74, 0x25, 0x46, 0x57, 0x4f, 0x8f,
+	0x1e, 0xf4, 0x19, 0x35, 0x20, 0xcf, 0x5f, 0x2e, 0x8e, 0x7b, 0x46, 0x97, 0x34, 0x74, 0x1d, 0x0a,
+	0x9c, 0xc7, 0xb7, 0x00, 0xfc, 0x92, 0x86, 0x7e, 0x04, 0xb9, 0x9b, 0x98, 0x08, 0xe7, 0x9b, 0x89,
+	0x3a, 0xf2, 0x08, 0x1e, 0xe1, 0x60, 0xa0, 0xc7, 0x96, 0x3e, 0x83, 0xac, 0x7c, 0xc4, 0x40, 0x0f,
+	0xa1, 0x18, 0xbe, 0xc2, 0xa3, 0x77, 0x94, 0x33, 0x0a, 0xbf, 0x8c, 0x54, 0xe7, 0x94, 0xa1, 0xd1,
+	0xf7, 0xfe, 0xd8, 0xbc, 0xb6, 0xf4, 0x99, 0xfc, 0x26, 0xbb, 0x6a, 0x12, 0x13, 0xdd, 0x87, 0x22,
+	0x3b, 0x02, 0xff, 0x9b, 0x6d, 0xc8, 0x55, 0x0e, 0x7d, 0x20, 0xae, 0xce, 0x1e, 0x31, 0x2a, 0x05,
+	0x34, 0x3e, 0x7d, 0xfe, 0xa2, 0x16, 0xfb, 0xea, 0x45, 0x2d, 0xf6, 0xcd, 0x8b, 0x9a, 0xf6, 0xb3,
+	0xfd, 0x9a, 0xf6, 0xfb, 0xfd, 0x9a, 0xf6, 0x6c, 0xbf, 0xa6, 0x3d, 0xdf, 0xaf, 0x69, 0xff, 0xd8,
+	0xaf, 0x69, 0xff, 0xdc, 0xaf, 0xc5, 0xbe, 0xd9, 0xaf, 0x69, 0x5f, 0xbc, 0xac, 0xc5, 0x9e, 0xbf,
+	0xac, 0xc5, 0xbe, 0x7a, 0x59, 0x8b, 0x7d, 0x7a, 0x56, 0xfd, 0xfe, 0xed, 0x9a, 0x6d, 0xd3, 0x36,
+	0x17, 0x7b, 0xce, 0x6e, 0x77, 0x51, 0xfd, 0x7c, 0xbe, 0x9d, 0x66, 0x7f, 0xef, 0xff, 0x6f, 0x00,
+	0x73, 0x59, 0x74, 0xe2, 0x55, 0x1f, 0x00, 0x00,
 }
 
 func (x Direction) String() string {
@@ -3003,6 +3311,12 @@ func (this *LabelRequest) Equal(that interface{}) bool {
 	} else if !this.End.Equal(*that1.End) {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *LabelResponse) Equal(that interface{}) bool {
@@ -3032,6 +3346,9 @@ func (this *LabelResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.NextPageToken != that1.NextPageToken {
+		return false
+	}
 	return true
 }
 func (this *StreamAdapter) Equal(that interface{}) bool {
@@ -3094,6 +3411,14 @@ func (this *EntryAdapter) Equal(that interface{}) bool {
 	if this.Line != that1.Line {
 		return false
 	}
+	if len(this.NonIndexedLabels) != len(that1.NonIndexedLabels) {
+		return false
+	}
+	for i := range this.NonIndexedLabels {
+		if !this.NonIndexedLabels[i].Equal(that1.NonIndexedLabels[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *Sample) Equal(that interface{}) bool {
@@ -3298,6 +3623,12 @@ func (this *SeriesRequest) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *SeriesResponse) Equal(that interface{}) bool {
@@ -3327,6 +3658,9 @@ func (this *SeriesResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.NextPageToken != that1.NextPageToken {
+		return false
+	}
 	return true
 }
 func (this *SeriesIdentifier) Equal(that interface{}) bool {
@@ -3704,6 +4038,12 @@ func (this *LabelValuesForMetricNameRequest) Equal(that interface{}) bool {
 	if this.Matchers != that1.Matchers {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *LabelNamesForMetricNameRequest) Equal(that interface{}) bool {
@@ -3734,6 +4074,12 @@ func (this *LabelNamesForMetricNameRequest) Equal(that interface{}) bool {
 	if !this.Through.Equal(that1.Through) {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *GetChunkRefRequest) Equal(that interface{}) bool {
@@ -3823,6 +4169,12 @@ func (this *GetSeriesRequest) Equal(that interface{}) bool {
 	if this.Matchers != that1.Matchers {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *GetSeriesResponse) Equal(that interface{}) bool {
@@ -3852,6 +4204,9 @@ func (this *GetSeriesResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.NextPageToken != that1.NextPageToken {
+		return false
+	}
 	return true
 }
 func (this *IndexSeries) Equal(that interface{}) bool {
@@ -4070,38 +4425,141 @@ func (this *IndexStatsResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *StreamRatesRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *VolumeRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 4)
-	s = append(s, "&logproto.StreamRatesRequest{")
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *StreamRatesResponse) GoString() string {
-	if this == nil {
-		return "nil"
+
+	that1, ok := that.(*VolumeRequest)
+	if !ok {
+		that2, ok := that.(VolumeRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&logproto.StreamRatesResponse{")
-	if this.StreamRates != nil {
-		s = append(s, "StreamRates: "+fmt.Sprintf("%#v", this.StreamRates)+",\n")
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *StreamRate) GoString() string {
-	if this == nil {
-		return "nil"
+	if !this.From.Equal(that1.From) {
+		return false
 	}
-	s := make([]string, 0, 7)
-	s = append(s, "&logproto.StreamRate{")
-	s = append(s, "StreamHash: "+fmt.Sprintf("%#v", this.StreamHash)+",\n")
-	s = append(s, "StreamHashNoShard: "+fmt.Sprintf("%#v", this.StreamHashNoShard)+",\n")
-	s = append(s, "Rate: "+fmt.Sprintf("%#v", this.Rate)+",\n")
-	s = append(s, "}")
-	return strings.Join(s, "")
+	if !this.Through.Equal(that1.Through) {
+		return false
+	}
+	if this.Matchers != that1.Matchers {
+		return false
+	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if len(this.TargetLabels) != len(that1.TargetLabels) {
+		return false
+	}
+	for i := range this.TargetLabels {
+		if this.TargetLabels[i] != that1.TargetLabels[i] {
+			return false
+		}
+	}
+	if this.AggregateBy != that1.AggregateBy {
+		return false
+	}
+	return true
+}
+func (this *VolumeResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*VolumeResponse)
+	if !ok {
+		that2, ok := that.(VolumeResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Volumes) != len(that1.Volumes) {
+		return false
+	}
+	for i := range this.Volumes {
+		if !this.Volumes[i].Equal(&that1.Volumes[i]) {
+			return false
+		}
+	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	return true
+}
+func (this *Volume) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Volume)
+	if !ok {
+		that2, ok := that.(Volume)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Volume != that1.Volume {
+		return false
+	}
+	return true
+}
+func (this *StreamRatesRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 4)
+	s = append(s, "&logproto.StreamRatesRequest{")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *StreamRatesResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&logproto.StreamRatesResponse{")
+	if this.StreamRates != nil {
+		s = append(s, "StreamRates: "+fmt.Sprintf("%#v", this.StreamRates)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *StreamRate) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&logproto.StreamRate{")
+	s = append(s, "StreamHash: "+fmt.Sprintf("%#v", this.StreamHash)+",\n")
+	s = append(s, "StreamHashNoShard: "+fmt.Sprintf("%#v", this.StreamHashNoShard)+",\n")
+	s = append(s, "Rate: "+fmt.Sprintf("%#v", this.Rate)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
 func (this *PushRequest) GoString() string {
 	if this == nil {
@@ -4194,12 +4652,14 @@ func (this *LabelRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 10)
 	s = append(s, "&logproto.LabelRequest{")
 	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
 	s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
 	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
 	s = append(s, "End: "+fmt.Sprintf("%#v", this.End)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4207,9 +4667,10 @@ func (this *LabelResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&logproto.LabelResponse{")
 	s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
+	s = append(s, "NextPageToken: "+fmt.Sprintf("%#v", this.NextPageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4235,10 +4696,11 @@ func (this *EntryAdapter) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 6)
+	s := make([]string, 0, 7)
 	s = append(s, "&logproto.EntryAdapter{")
 	s = append(s, "Timestamp: "+fmt.Sprintf("%#v", this.Timestamp)+",\n")
 	s = append(s, "Line: "+fmt.Sprintf("%#v", this.Line)+",\n")
+	s = append(s, "NonIndexedLabels: "+fmt.Sprintf("%#v", this.NonIndexedLabels)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4313,12 +4775,14 @@ func (this *SeriesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 10)
 	s = append(s, "&logproto.SeriesRequest{")
 	s = append(s, "Start: "+fmt.Sprintf("%#v", this.Start)+",\n")
 	s = append(s, "End: "+fmt.Sprintf("%#v", this.End)+",\n")
 	s = append(s, "Groups: "+fmt.Sprintf("%#v", this.Groups)+",\n")
 	s = append(s, "Shards: "+fmt.Sprintf("%#v", this.Shards)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4326,7 +4790,7 @@ func (this *SeriesResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&logproto.SeriesResponse{")
 	if this.Series != nil {
 		vs := make([]*SeriesIdentifier, len(this.Series))
@@ -4335,6 +4799,7 @@ func (this *SeriesResponse) GoString() string {
 		}
 		s = append(s, "Series: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
+	s = append(s, "NextPageToken: "+fmt.Sprintf("%#v", this.NextPageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4489,13 +4954,15 @@ func (this *LabelValuesForMetricNameRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 11)
 	s = append(s, "&logproto.LabelValuesForMetricNameRequest{")
 	s = append(s, "MetricName: "+fmt.Sprintf("%#v", this.MetricName)+",\n")
 	s = append(s, "LabelName: "+fmt.Sprintf("%#v", this.LabelName)+",\n")
 	s = append(s, "From: "+fmt.Sprintf("%#v", this.From)+",\n")
 	s = append(s, "Through: "+fmt.Sprintf("%#v", this.Through)+",\n")
 	s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4503,11 +4970,13 @@ func (this *LabelNamesForMetricNameRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 9)
 	s = append(s, "&logproto.LabelNamesForMetricNameRequest{")
 	s = append(s, "MetricName: "+fmt.Sprintf("%#v", this.MetricName)+",\n")
 	s = append(s, "From: "+fmt.Sprintf("%#v", this.From)+",\n")
 	s = append(s, "Through: "+fmt.Sprintf("%#v", this.Through)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4539,11 +5008,13 @@ func (this *GetSeriesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 9)
 	s = append(s, "&logproto.GetSeriesRequest{")
 	s = append(s, "From: "+fmt.Sprintf("%#v", this.From)+",\n")
 	s = append(s, "Through: "+fmt.Sprintf("%#v", this.Through)+",\n")
 	s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4551,7 +5022,7 @@ func (this *GetSeriesResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&logproto.GetSeriesResponse{")
 	if this.Series != nil {
 		vs := make([]*IndexSeries, len(this.Series))
@@ -4560,6 +5031,7 @@ func (this *GetSeriesResponse) GoString() string {
 		}
 		s = append(s, "Series: "+fmt.Sprintf("%#v", vs)+",\n")
 	}
+	s = append(s, "NextPageToken: "+fmt.Sprintf("%#v", this.NextPageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -4648,6 +5120,49 @@ func (this *IndexStatsResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *VolumeRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 10)
+	s = append(s, "&logproto.VolumeRequest{")
+	s = append(s, "From: "+fmt.Sprintf("%#v", this.From)+",\n")
+	s = append(s, "Through: "+fmt.Sprintf("%#v", this.Through)+",\n")
+	s = append(s, "Matchers: "+fmt.Sprintf("%#v", this.Matchers)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "TargetLabels: "+fmt.Sprintf("%#v", this.TargetLabels)+",\n")
+	s = append(s, "AggregateBy: "+fmt.Sprintf("%#v", this.AggregateBy)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *VolumeResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&logproto.VolumeResponse{")
+	if this.Volumes != nil {
+		vs := make([]*Volume, len(this.Volumes))
+		for i := range vs {
+			vs[i] = &this.Volumes[i]
+		}
+		s = append(s, "Volumes: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *Volume) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&logproto.Volume{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Volume: "+fmt.Sprintf("%#v", this.Volume)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringLogproto(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -4751,6 +5266,13 @@ type QuerierClient interface {
 	// Note: this MUST be the same as the variant defined in
 	// indexgateway.proto on the IndexGateway service.
 	GetStats(ctx context.Context, in *IndexStatsRequest, opts ...grpc.CallOption) (*IndexStatsResponse, error)
+	// StreamLabel is the same as Label, sending the values in batches.
+	StreamLabel(ctx context.Context, in *LabelRequest, opts ...grpc.CallOption) (Querier_StreamLabelClient, error)
+	// StreamSeries is the same as Series, sending the series in batches.
+	StreamSeries(ctx context.Context, in *SeriesRequest, opts ...grpc.CallOption) (Querier_StreamSeriesClient, error)
+	// Note: this MUST be the same as the variant defined in
+	// indexgateway.proto on the IndexGateway service.
+	GetVolume(ctx context.Context, in *VolumeRequest, opts ...grpc.CallOption) (*VolumeResponse, error)
 }
 
 type querierClient struct {
@@ -4902,6 +5424,79 @@ func (c *querierClient) GetStats(ctx context.Context, in *IndexStatsRequest, opt
 	return out, nil
 }
 
+func (c *querierClient) StreamLabel(ctx context.Context, in *LabelRequest, opts ...grpc.CallOption) (Querier_StreamLabelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Querier_serviceDesc.Streams[3], "/logproto.Querier/StreamLabel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &querierStreamLabelClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Querier_StreamLabelClient interface {
+	Recv() (*LabelResponse, error)
+	grpc.ClientStream
+}
+
+type querierStreamLabelClient struct {
+	grpc.ClientStream
+}
+
+func (x *querierStreamLabelClient) Recv() (*LabelResponse, error) {
+	m := new(LabelResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *querierClient) StreamSeries(ctx context.Context, in *SeriesRequest, opts ...grpc.CallOption) (Querier_StreamSeriesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Querier_serviceDesc.Streams[4], "/logproto.Querier/StreamSeries", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &querierStreamSeriesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Querier_StreamSeriesClient interface {
+	Recv() (*SeriesResponse, error)
+	grpc.ClientStream
+}
+
+type querierStreamSeriesClient struct {
+	grpc.ClientStream
+}
+
+func (x *querierStreamSeriesClient) Recv() (*SeriesResponse, error) {
+	m := new(SeriesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *querierClient) GetVolume(ctx context.Context, in *VolumeRequest, opts ...grpc.CallOption) (*VolumeResponse, error) {
+	out := new(VolumeResponse)
+	err := c.cc.Invoke(ctx, "/logproto.Querier/GetVolume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // QuerierServer is the server API for Querier service.
 type QuerierServer interface {
 	Query(*QueryRequest, Querier_QueryServer) error
@@ -4914,6 +5509,13 @@ type QuerierServer interface {
 	// Note: this MUST be the same as the variant defined in
 	// indexgateway.proto on the IndexGateway service.
 	GetStats(context.Context, *IndexStatsRequest) (*IndexStatsResponse, error)
+	// StreamLabel is the same as Label, sending the values in batches.
+	StreamLabel(*LabelRequest, Querier_StreamLabelServer) error
+	// StreamSeries is the same as Series, sending the series in batches.
+	StreamSeries(*SeriesRequest, Querier_StreamSeriesServer) error
+	// Note: this MUST be the same as the variant defined in
+	// indexgateway.proto on the IndexGateway service.
+	GetVolume(context.Context, *VolumeRequest) (*VolumeResponse, error)
 }
 
 // UnimplementedQuerierServer can be embedded to have forward compatible implementations.
@@ -4944,6 +5546,15 @@ func (*UnimplementedQuerierServer) GetChunkIDs(ctx context.Context, req *GetChun
 func (*UnimplementedQuerierServer) GetStats(ctx context.Context, req *IndexStatsRequest) (*IndexStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (*UnimplementedQuerierServer) StreamLabel(req *LabelRequest, srv Querier_StreamLabelServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLabel not implemented")
+}
+func (*UnimplementedQuerierServer) StreamSeries(req *SeriesRequest, srv Querier_StreamSeriesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSeries not implemented")
+}
+func (*UnimplementedQuerierServer) GetVolume(ctx context.Context, req *VolumeRequest) (*VolumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVolume not implemented")
+}
 
 func RegisterQuerierServer(s *grpc.Server, srv QuerierServer) {
 	s.RegisterService(&_Querier_serviceDesc, srv)
@@ -5102,6 +5713,66 @@ func _Querier_GetStats_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Querier_StreamLabel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LabelRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuerierServer).StreamLabel(m, &querierStreamLabelServer{stream})
+}
+
+type Querier_StreamLabelServer interface {
+	Send(*LabelResponse) error
+	grpc.ServerStream
+}
+
+type querierStreamLabelServer struct {
+	grpc.ServerStream
+}
+
+func (x *querierStreamLabelServer) Send(m *LabelResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Querier_StreamSeries_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SeriesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuerierServer).StreamSeries(m, &querierStreamSeriesServer{stream})
+}
+
+type Querier_StreamSeriesServer interface {
+	Send(*SeriesResponse) error
+	grpc.ServerStream
+}
+
+type querierStreamSeriesServer struct {
+	grpc.ServerStream
+}
+
+func (x *querierStreamSeriesServer) Send(m *SeriesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Querier_GetVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuerierServer).GetVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logproto.Querier/GetVolume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuerierServer).GetVolume(ctx, req.(*VolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Querier_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "logproto.Querier",
 	HandlerType: (*QuerierServer)(nil),
@@ -5126,6 +5797,10 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetStats",
 			Handler:    _Querier_GetStats_Handler,
 		},
+		{
+			MethodName: "GetVolume",
+			Handler:    _Querier_GetVolume_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -5143,9 +5818,19 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Querier_Tail_Handler,
 			ServerStreams: true,
 		},
-	},
-	Metadata: "pkg/logproto/logproto.proto",
-}
+		{
+			StreamName:    "StreamLabel",
+			Handler:       _Querier_StreamLabel_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamSeries",
+			Handler:       _Querier_StreamSeries_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/logproto/logproto.proto",
+}
 
 // IngesterClient is the client API for Ingester service.
 //
@@ -5785,6 +6470,18 @@ func (m *LabelRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x28
+	}
 	if m.End != nil {
 		n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.End, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.End):])
 		if err7 != nil {
@@ -5845,6 +6542,13 @@ func (m *LabelResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPageToken) > 0 {
+		i -= len(m.NextPageToken)
+		copy(dAtA[i:], m.NextPageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.NextPageToken)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Values) > 0 {
 		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Values[iNdEx])
@@ -5926,6 +6630,20 @@ func (m *EntryAdapter) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.NonIndexedLabels) > 0 {
+		for iNdEx := len(m.NonIndexedLabels) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.NonIndexedLabels[iNdEx].Size()
+				i -= size
+				if _, err := m.NonIndexedLabels[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if len(m.Line) > 0 {
 		i -= len(m.Line)
 		copy(dAtA[i:], m.Line)
@@ -6183,6 +6901,18 @@ func (m *SeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x28
+	}
 	if len(m.Shards) > 0 {
 		for iNdEx := len(m.Shards) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Shards[iNdEx])
@@ -6240,6 +6970,13 @@ func (m *SeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPageToken) > 0 {
+		i -= len(m.NextPageToken)
+		copy(dAtA[i:], m.NextPageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.NextPageToken)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Series) > 0 {
 		for iNdEx := len(m.Series) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -6736,6 +7473,18 @@ func (m *LabelValuesForMetricNameRequest) MarshalToSizedBuffer(dAtA []byte) (int
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Matchers) > 0 {
 		i -= len(m.Matchers)
 		copy(dAtA[i:], m.Matchers)
@@ -6790,6 +7539,18 @@ func (m *LabelNamesForMetricNameRequest) MarshalToSizedBuffer(dAtA []byte) (int,
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.Through != 0 {
 		i = encodeVarintLogproto(dAtA, i, uint64(m.Through))
 		i--
@@ -6907,6 +7668,18 @@ func (m *GetSeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.Matchers) > 0 {
 		i -= len(m.Matchers)
 		copy(dAtA[i:], m.Matchers)
@@ -6947,6 +7720,13 @@ func (m *GetSeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPageToken) > 0 {
+		i -= len(m.NextPageToken)
+		copy(dAtA[i:], m.NextPageToken)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.NextPageToken)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Series) > 0 {
 		for iNdEx := len(m.Series) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -7260,6 +8040,144 @@ func (m *IndexStatsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *VolumeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *VolumeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *VolumeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.AggregateBy) > 0 {
+		i -= len(m.AggregateBy)
+		copy(dAtA[i:], m.AggregateBy)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.AggregateBy)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.TargetLabels) > 0 {
+		for iNdEx := len(m.TargetLabels) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TargetLabels[iNdEx])
+			copy(dAtA[i:], m.TargetLabels[iNdEx])
+			i = encodeVarintLogproto(dAtA, i, uint64(len(m.TargetLabels[iNdEx])))
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Matchers) > 0 {
+		i -= len(m.Matchers)
+		copy(dAtA[i:], m.Matchers)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Matchers)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Through != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Through))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.From != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.From))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *VolumeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *VolumeResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *VolumeResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Volumes) > 0 {
+		for iNdEx := len(m.Volumes) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Volumes[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintLogproto(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Volume) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Volume) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Volume) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Volume != 0 {
+		i = encodeVarintLogproto(dAtA, i, uint64(m.Volume))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintLogproto(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintLogproto(dAtA []byte, offset int, v uint64) int {
 	offset -= sovLogproto(v)
 	base := offset
@@ -7475,6 +8393,13 @@ func (m *LabelRequest) Size() (n int) {
 		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.End)
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7490,6 +8415,10 @@ func (m *LabelResponse) Size() (n int) {
 			n += 1 + l + sovLogproto(uint64(l))
 		}
 	}
+	l = len(m.NextPageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7527,6 +8456,12 @@ func (m *EntryAdapter) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if len(m.NonIndexedLabels) > 0 {
+		for _, e := range m.NonIndexedLabels {
+			l = e.Size()
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -7647,6 +8582,13 @@ func (m *SeriesRequest) Size() (n int) {
 			n += 1 + l + sovLogproto(uint64(l))
 		}
 	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7662,6 +8604,10 @@ func (m *SeriesResponse) Size() (n int) {
 			n += 1 + l + sovLogproto(uint64(l))
 		}
 	}
+	l = len(m.NextPageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7886,6 +8832,13 @@ func (m *LabelValuesForMetricNameRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7905,6 +8858,13 @@ func (m *LabelNamesForMetricNameRequest) Size() (n int) {
 	if m.Through != 0 {
 		n += 1 + sovLogproto(uint64(m.Through))
 	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7958,6 +8918,13 @@ func (m *GetSeriesRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -7973,6 +8940,10 @@ func (m *GetSeriesResponse) Size() (n int) {
 			n += 1 + l + sovLogproto(uint64(l))
 		}
 	}
+	l = len(m.NextPageToken)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -8111,36 +9082,102 @@ func (m *IndexStatsResponse) Size() (n int) {
 	return n
 }
 
-func sovLogproto(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozLogproto(x uint64) (n int) {
-	return sovLogproto(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *StreamRatesRequest) String() string {
-	if this == nil {
-		return "nil"
+func (m *VolumeRequest) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	s := strings.Join([]string{`&StreamRatesRequest{`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *StreamRatesResponse) String() string {
-	if this == nil {
-		return "nil"
+	var l int
+	_ = l
+	if m.From != 0 {
+		n += 1 + sovLogproto(uint64(m.From))
 	}
-	repeatedStringForStreamRates := "[]*StreamRate{"
-	for _, f := range this.StreamRates {
-		repeatedStringForStreamRates += strings.Replace(f.String(), "StreamRate", "StreamRate", 1) + ","
+	if m.Through != 0 {
+		n += 1 + sovLogproto(uint64(m.Through))
 	}
-	repeatedStringForStreamRates += "}"
-	s := strings.Join([]string{`&StreamRatesResponse{`,
-		`StreamRates:` + repeatedStringForStreamRates + `,`,
-		`}`,
-	}, "")
-	return s
-}
+	l = len(m.Matchers)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	if len(m.TargetLabels) > 0 {
+		for _, s := range m.TargetLabels {
+			l = len(s)
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
+	l = len(m.AggregateBy)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	return n
+}
+
+func (m *VolumeResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Volumes) > 0 {
+		for _, e := range m.Volumes {
+			l = e.Size()
+			n += 1 + l + sovLogproto(uint64(l))
+		}
+	}
+	if m.Limit != 0 {
+		n += 1 + sovLogproto(uint64(m.Limit))
+	}
+	return n
+}
+
+func (m *Volume) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovLogproto(uint64(l))
+	}
+	if m.Volume != 0 {
+		n += 1 + sovLogproto(uint64(m.Volume))
+	}
+	return n
+}
+
+func sovLogproto(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozLogproto(x uint64) (n int) {
+	return sovLogproto(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *StreamRatesRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&StreamRatesRequest{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *StreamRatesResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForStreamRates := "[]*StreamRate{"
+	for _, f := range this.StreamRates {
+		repeatedStringForStreamRates += strings.Replace(f.String(), "StreamRate", "StreamRate", 1) + ","
+	}
+	repeatedStringForStreamRates += "}"
+	s := strings.Join([]string{`&StreamRatesResponse{`,
+		`StreamRates:` + repeatedStringForStreamRates + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *StreamRate) String() string {
 	if this == nil {
 		return "nil"
@@ -8255,6 +9292,8 @@ func (this *LabelRequest) String() string {
 		`Values:` + fmt.Sprintf("%v", this.Values) + `,`,
 		`Start:` + strings.Replace(fmt.Sprintf("%v", this.Start), "Timestamp", "types.Timestamp", 1) + `,`,
 		`End:` + strings.Replace(fmt.Sprintf("%v", this.End), "Timestamp", "types.Timestamp", 1) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8265,6 +9304,7 @@ func (this *LabelResponse) String() string {
 	}
 	s := strings.Join([]string{`&LabelResponse{`,
 		`Values:` + fmt.Sprintf("%v", this.Values) + `,`,
+		`NextPageToken:` + fmt.Sprintf("%v", this.NextPageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8293,6 +9333,7 @@ func (this *EntryAdapter) String() string {
 	s := strings.Join([]string{`&EntryAdapter{`,
 		`Timestamp:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Timestamp), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Line:` + fmt.Sprintf("%v", this.Line) + `,`,
+		`NonIndexedLabels:` + fmt.Sprintf("%v", this.NonIndexedLabels) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8375,6 +9416,8 @@ func (this *SeriesRequest) String() string {
 		`End:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.End), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Groups:` + fmt.Sprintf("%v", this.Groups) + `,`,
 		`Shards:` + fmt.Sprintf("%v", this.Shards) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8390,6 +9433,7 @@ func (this *SeriesResponse) String() string {
 	repeatedStringForSeries += "}"
 	s := strings.Join([]string{`&SeriesResponse{`,
 		`Series:` + repeatedStringForSeries + `,`,
+		`NextPageToken:` + fmt.Sprintf("%v", this.NextPageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8555,6 +9599,8 @@ func (this *LabelValuesForMetricNameRequest) String() string {
 		`From:` + fmt.Sprintf("%v", this.From) + `,`,
 		`Through:` + fmt.Sprintf("%v", this.Through) + `,`,
 		`Matchers:` + fmt.Sprintf("%v", this.Matchers) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8567,6 +9613,8 @@ func (this *LabelNamesForMetricNameRequest) String() string {
 		`MetricName:` + fmt.Sprintf("%v", this.MetricName) + `,`,
 		`From:` + fmt.Sprintf("%v", this.From) + `,`,
 		`Through:` + fmt.Sprintf("%v", this.Through) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8606,6 +9654,8 @@ func (this *GetSeriesRequest) String() string {
 		`From:` + fmt.Sprintf("%v", this.From) + `,`,
 		`Through:` + fmt.Sprintf("%v", this.Through) + `,`,
 		`Matchers:` + fmt.Sprintf("%v", this.Matchers) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8621,6 +9671,7 @@ func (this *GetSeriesResponse) String() string {
 	repeatedStringForSeries += "}"
 	s := strings.Join([]string{`&GetSeriesResponse{`,
 		`Series:` + repeatedStringForSeries + `,`,
+		`NextPageToken:` + fmt.Sprintf("%v", this.NextPageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -8716,6 +9767,48 @@ func (this *IndexStatsResponse) String() string {
 	}, "")
 	return s
 }
+func (this *VolumeRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&VolumeRequest{`,
+		`From:` + fmt.Sprintf("%v", this.From) + `,`,
+		`Through:` + fmt.Sprintf("%v", this.Through) + `,`,
+		`Matchers:` + fmt.Sprintf("%v", this.Matchers) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`TargetLabels:` + fmt.Sprintf("%v", this.TargetLabels) + `,`,
+		`AggregateBy:` + fmt.Sprintf("%v", this.AggregateBy) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *VolumeResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForVolumes := "[]Volume{"
+	for _, f := range this.Volumes {
+		repeatedStringForVolumes += strings.Replace(strings.Replace(f.String(), "Volume", "Volume", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForVolumes += "}"
+	s := strings.Join([]string{`&VolumeResponse{`,
+		`Volumes:` + repeatedStringForVolumes + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Volume) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Volume{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Volume:` + fmt.Sprintf("%v", this.Volume) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringLogproto(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -10102,6 +11195,57 @@ func (m *LabelRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -10187,6 +11331,38 @@ func (m *LabelResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -10443,6 +11619,40 @@ func (m *EntryAdapter) Unmarshal(dAtA []byte) error {
 			}
 			m.Line = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NonIndexedLabels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NonIndexedLabels = append(m.NonIndexedLabels, LabelAdapter{})
+			if err := m.NonIndexedLabels[len(m.NonIndexedLabels)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -11228,30 +12438,81 @@ func (m *SeriesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Shards = append(m.Shards, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipLogproto(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthLogproto
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
 			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthLogproto
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *SeriesResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -11315,6 +12576,38 @@ func (m *SeriesResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -12918,6 +14211,57 @@ func (m *LabelValuesForMetricNameRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Matchers = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -13041,6 +14385,57 @@ func (m *LabelNamesForMetricNameRequest) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -13374,6 +14769,57 @@ func (m *GetSeriesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Matchers = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -13461,19 +14907,51 @@ func (m *GetSeriesResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipLogproto(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPageToken", wireType)
 			}
-			if skippy < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthLogproto
 			}
-			if (iNdEx + skippy) < 0 {
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
 				return ErrInvalidLengthLogproto
 			}
-			if (iNdEx + skippy) > l {
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
 			iNdEx += skippy
@@ -14370,6 +15848,422 @@ func (m *IndexStatsResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *VolumeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: VolumeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: VolumeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			m.From = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.From |= github_com_prometheus_common_model.Time(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Through", wireType)
+			}
+			m.Through = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Through |= github_com_prometheus_common_model.Time(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Matchers", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Matchers = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TargetLabels", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TargetLabels = append(m.TargetLabels, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AggregateBy", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AggregateBy = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *VolumeResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: VolumeResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: VolumeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Volumes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Volumes = append(m.Volumes, Volume{})
+			if err := m.Volumes[len(m.Volumes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Volume) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLogproto
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Volume: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Volume: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Volume", wireType)
+			}
+			m.Volume = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Volume |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLogproto(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipLogproto(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
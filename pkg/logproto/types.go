@@ -3,6 +3,7 @@ package logproto
 import (
 	fmt "fmt"
 	io "io"
+	"strings"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -21,6 +22,9 @@ type Stream struct {
 type Entry struct {
 	Timestamp time.Time `protobuf:"bytes,1,opt,name=timestamp,proto3,stdtime" json:"ts"`
 	Line      string    `protobuf:"bytes,2,opt,name=line,proto3" json:"line"`
+	// NonIndexedLabels are labels attached to the entry which are stored in the chunks
+	// along with the line but, unlike the stream labels, are not part of the index.
+	NonIndexedLabels []LabelAdapter `protobuf:"bytes,3,rep,name=nonIndexedLabels,proto3" json:"nonIndexedLabels,omitempty"`
 }
 
 func (m *Stream) Marshal() (dAtA []byte, err error) {
@@ -92,6 +96,18 @@ func (m *Entry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	for iNdEx := len(m.NonIndexedLabels) - 1; iNdEx >= 0; iNdEx-- {
+		{
+			size, err := m.NonIndexedLabels[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintLogproto(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.Line) > 0 {
 		i -= len(m.Line)
 		copy(dAtA[i:], m.Line)
@@ -343,6 +359,45 @@ func (m *Entry) Unmarshal(dAtA []byte) error {
 			}
 			m.Line = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NonIndexedLabels", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLogproto
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLogproto
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var lbl LabelAdapter
+			if err := lbl.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			// LabelAdapter doesn't copy the bytes it unmarshals, but entries outlive the request buffer.
+			m.NonIndexedLabels = append(m.NonIndexedLabels, LabelAdapter{
+				Name:  strings.Clone(lbl.Name),
+				Value: strings.Clone(lbl.Value),
+			})
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipLogproto(dAtA[iNdEx:])
@@ -402,6 +457,10 @@ func (m *Entry) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovLogproto(uint64(l))
 	}
+	for _, lbl := range m.NonIndexedLabels {
+		l = lbl.Size()
+		n += 1 + l + sovLogproto(uint64(l))
+	}
 	return n
 }
 
@@ -463,6 +522,14 @@ func (m *Entry) Equal(that interface{}) bool {
 	if m.Line != that1.Line {
 		return false
 	}
+	if len(m.NonIndexedLabels) != len(that1.NonIndexedLabels) {
+		return false
+	}
+	for i := range m.NonIndexedLabels {
+		if !m.NonIndexedLabels[i].Equal(that1.NonIndexedLabels[i]) {
+			return false
+		}
+	}
 	return true
 }
 
@@ -14,20 +14,20 @@ var (
 		Labels: `{job="foobar", cluster="foo-central1", namespace="bar", container_name="buzz"}`,
 		Hash:   1234*10 ^ 9,
 		Entries: []Entry{
-			{now, line},
-			{now.Add(1 * time.Second), line},
-			{now.Add(2 * time.Second), line},
-			{now.Add(3 * time.Second), line},
+			{Timestamp: now, Line: line},
+			{Timestamp: now.Add(1 * time.Second), Line: line},
+			{Timestamp: now.Add(2 * time.Second), Line: line},
+			{Timestamp: now.Add(3 * time.Second), Line: line},
 		},
 	}
 	streamAdapter = StreamAdapter{
 		Labels: `{job="foobar", cluster="foo-central1", namespace="bar", container_name="buzz"}`,
 		Hash:   1234*10 ^ 9,
 		Entries: []EntryAdapter{
-			{now, line},
-			{now.Add(1 * time.Second), line},
-			{now.Add(2 * time.Second), line},
-			{now.Add(3 * time.Second), line},
+			{Timestamp: now, Line: line},
+			{Timestamp: now.Add(1 * time.Second), Line: line},
+			{Timestamp: now.Add(2 * time.Second), Line: line},
+			{Timestamp: now.Add(3 * time.Second), Line: line},
 		},
 	}
 )
@@ -46,6 +46,37 @@ func TestStream(t *testing.T) {
 	t.Log("avg allocs per run:", avg)
 }
 
+func TestEntryNonIndexedLabels(t *testing.T) {
+	entry := Entry{
+		Timestamp: now,
+		Line:      line,
+		NonIndexedLabels: []LabelAdapter{
+			{Name: "trace_id", Value: "3c0e3dcd33e7"},
+			{Name: "user", Value: "bob"},
+		},
+	}
+
+	b, err := entry.Marshal()
+	require.NoError(t, err)
+	require.Len(t, b, entry.Size())
+
+	var new Entry
+	err = new.Unmarshal(b)
+	require.NoError(t, err)
+	require.Equal(t, entry, new)
+	require.True(t, entry.Equal(new))
+
+	// Entry has the same wire format as EntryAdapter.
+	var adapter EntryAdapter
+	err = adapter.Unmarshal(b)
+	require.NoError(t, err)
+	require.Equal(t, EntryAdapter{Timestamp: now, Line: line, NonIndexedLabels: entry.NonIndexedLabels}, adapter)
+
+	ab, err := adapter.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, b, ab)
+}
+
 func TestStreamAdapter(t *testing.T) {
 	avg := testing.AllocsPerRun(200, func() {
 		b, err := streamAdapter.Marshal()
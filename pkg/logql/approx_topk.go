@@ -0,0 +1,174 @@
+package logql
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/pkg/logql/sketch"
+	"github.com/grafana/loki/pkg/logql/syntax"
+)
+
+// Error bounds of the count-min sketches of approx_topk. The value of the k-th series of a step is at most the sum of
+// all the series divided by k, so the estimates are off by at most a quarter of that to barely affect the ranking of
+// the top k, within 2% and 0.02% of the sum. The latter bounds the sketches to about 13600 counters per row, while
+// each shard only sends the non-empty ones.
+const (
+	approxTopKRelativeError    = 0.25
+	approxTopKMaxError         = 0.02
+	approxTopKMinError         = 0.0002
+	approxTopKErrorProbability = 0.02
+)
+
+// Labels of the series which carry the counters of the sketches of approx_topk from the shards.
+const (
+	approxTopKRowLabel    = "__approx_topk_row__"
+	approxTopKColumnLabel = "__approx_topk_column__"
+)
+
+// approxTopKEvaluator evaluates approx_topk.
+//
+// Evaluated for a shard, it returns the top k series of the shard along with a count-min sketch of
+// all of its series, sent as one series per non-empty counter. The evaluator of the sharded query
+// then merges the sketches and ranks the candidates of all the shards by their estimated value,
+// which saves sending every series of every shard for high cardinality aggregations.
+// Anywhere else it's the same as topk.
+func approxTopKEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *syntax.VectorAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	sharded := len(q.Shards()) > 0
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		if !next {
+			return false, 0, promql.Vector{}
+		}
+		if sharded {
+			return next, ts, approxTopKShard(expr.Params, ts, vec)
+		}
+		return next, ts, approxTopKMerge(expr.Params, ts, vec)
+	}, nextEvaluator.Close, nextEvaluator.Error)
+}
+
+// approxTopKSketchDimensions returns the depth and width of the sketches of approx_topk(k), which both the shards and
+// the sharded query derive from k.
+func approxTopKSketchDimensions(k int) (depth, width uint32) {
+	epsilon := approxTopKMaxError
+	if k > 0 {
+		epsilon = math.Max(math.Min(approxTopKRelativeError/float64(k), approxTopKMaxError), approxTopKMinError)
+	}
+	return sketch.CountMinSketchDimensions(epsilon, approxTopKErrorProbability)
+}
+
+// approxTopKShard returns the top k series of a shard followed by the counters of the sketch of the shard.
+func approxTopKShard(k int, ts int64, vec promql.Vector) promql.Vector {
+	cms := sketch.NewCountMinSketch(approxTopKSketchDimensions(k))
+	for _, s := range vec {
+		cms.Add(s.Metric.Hash(), s.V)
+	}
+
+	res := topKVector(k, ts, vec)
+	for row, counters := range cms.Counters {
+		for column, v := range counters {
+			if v == 0 {
+				continue
+			}
+			res = append(res, promql.Sample{
+				Metric: labels.FromStrings(
+					approxTopKRowLabel, strconv.Itoa(row),
+					approxTopKColumnLabel, strconv.Itoa(column),
+				),
+				Point: promql.Point{T: ts, V: v},
+			})
+		}
+	}
+	return res
+}
+
+// approxTopKMerge merges the sketches of the shards and returns the top k of their candidates by estimated value.
+// Without any sketch, e.g. when the query isn't sharded, it's topk.
+func approxTopKMerge(k int, ts int64, vec promql.Vector) promql.Vector {
+	var (
+		cms        *sketch.CountMinSketch
+		candidates = make(map[uint64]promql.Sample, len(vec))
+	)
+	for _, s := range vec {
+		row, column, ok := approxTopKCounter(s.Metric)
+		if !ok {
+			candidates[s.Metric.Hash()] = s
+			continue
+		}
+		if cms == nil {
+			cms = sketch.NewCountMinSketch(approxTopKSketchDimensions(k))
+		}
+		cms.AddCounter(row, column, s.V)
+	}
+	if cms == nil {
+		return topKVector(k, ts, vec)
+	}
+
+	vec = make(promql.Vector, 0, len(candidates))
+	for hash, s := range candidates {
+		s.V = cms.Count(hash)
+		vec = append(vec, s)
+	}
+	return topKVector(k, ts, vec)
+}
+
+// approxTopKCounter returns the counter of the sketch carried by the series, if any.
+func approxTopKCounter(metric labels.Labels) (row, column uint32, ok bool) {
+	rowValue, columnValue := metric.Get(approxTopKRowLabel), metric.Get(approxTopKColumnLabel)
+	if rowValue == "" || columnValue == "" {
+		return 0, 0, false
+	}
+	r, err := strconv.ParseUint(rowValue, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	c, err := strconv.ParseUint(columnValue, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint32(r), uint32(c), true
+}
+
+// topKVector returns the k samples with the highest values, highest first.
+func topKVector(k int, ts int64, vec promql.Vector) promql.Vector {
+	if k < 1 {
+		return promql.Vector{}
+	}
+	h := make(vectorByValueHeap, 0, k)
+	for i := range vec {
+		s := vec[i]
+		if len(h) < k {
+			heap.Push(&h, &s)
+			continue
+		}
+		if h[0].V < s.V || math.IsNaN(h[0].V) {
+			h[0] = s
+			heap.Fix(&h, 0)
+		}
+	}
+
+	// The heap keeps the lowest value on top, so reverse it.
+	sort.Sort(sort.Reverse(h))
+	res := make(promql.Vector, 0, len(h))
+	for _, s := range h {
+		res = append(res, promql.Sample{
+			Metric: s.Metric,
+			Point:  promql.Point{T: ts, V: s.V},
+		})
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+package logql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestApproxTopKMerge(t *testing.T) {
+	sample := func(x string, v float64) promql.Sample {
+		return promql.Sample{Metric: labels.FromStrings("x", x), Point: promql.Point{V: v}}
+	}
+
+	var vec promql.Vector
+	vec = append(vec, approxTopKShard(1, 0, promql.Vector{sample("1", 10), sample("2", 9), sample("3", 1)})...)
+	vec = append(vec, approxTopKShard(1, 0, promql.Vector{sample("3", 15), sample("1", 1), sample("2", 1)})...)
+
+	// only 1 and 3 are candidates, ranked by their total over both shards.
+	require.Equal(t, promql.Vector{sample("3", 16)}, approxTopKMerge(1, 0, vec))
+	require.Equal(t, promql.Vector{sample("3", 16), sample("1", 11)}, approxTopKMerge(2, 0, vec))
+
+	// without sketches it's topk
+	require.Equal(t, promql.Vector{sample("3", 15), sample("1", 1)}, approxTopKMerge(2, 0, promql.Vector{sample("1", 1), sample("3", 15)}))
+}
+
+func TestApproxTopKSketchDimensions(t *testing.T) {
+	for _, tc := range []struct {
+		k            int
+		depth, width uint32
+	}{
+		// at most 2% of the sum
+		{k: 1, depth: 4, width: 136},
+		{k: 10, depth: 4, width: 136},
+		// a quarter of the sum divided by k
+		{k: 100, depth: 4, width: 1088},
+		// at least 0.02% of the sum
+		{k: 10000, depth: 4, width: 13592},
+	} {
+		t.Run(fmt.Sprint(tc.k), func(t *testing.T) {
+			depth, width := approxTopKSketchDimensions(tc.k)
+			require.Equal(t, tc.depth, depth)
+			require.Equal(t, tc.width, width)
+		})
+	}
+}
+
+func TestApproxTopKSharded(t *testing.T) {
+	var (
+		shards  = 3
+		start   = time.Unix(10, 0)
+		streams []logproto.Stream
+	)
+	// streams of the same app are spread over the shards and the total of each app differs.
+	for i := 0; i < 20; i++ {
+		ls := labels.FromStrings("app", fmt.Sprintf("%d", i%10), "index", fmt.Sprintf("%d", i))
+		stream := logproto.Stream{Labels: ls.String(), Hash: ls.Hash()}
+		for j := 0; j <= i; j++ {
+			stream.Entries = append(stream.Entries, logproto.Entry{
+				Timestamp: time.Unix(0, int64(j)*int64(time.Millisecond)),
+				Line:      fmt.Sprintf("line=%d", j),
+			})
+		}
+		streams = append(streams, stream)
+	}
+
+	query := `approx_topk(3, sum by (app) (count_over_time({app=~".+"}[1m])))`
+	params := NewLiteralParams(query, start, start, 0, 0, logproto.FORWARD, 100, nil)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	regular := NewEngine(EngineOpts{}, NewMockQuerier(shards, streams), NoLimits, log.NewNopLogger())
+	sharded := NewDownstreamEngine(EngineOpts{}, MockDownstreamer{regular}, NoLimits, log.NewNopLogger())
+
+	_, mapped, err := NewShardMapper(ConstantShards(shards), nilShardMetrics).Parse(query)
+	require.Nil(t, err)
+	res, err := sharded.Query(ctx, params, mapped).Exec(ctx)
+	require.Nil(t, err)
+
+	require.Equal(t, promql.Vector{
+		{Metric: labels.FromStrings("app", "7"), Point: promql.Point{T: start.UnixMilli(), V: 26}},
+		{Metric: labels.FromStrings("app", "8"), Point: promql.Point{T: start.UnixMilli(), V: 28}},
+		{Metric: labels.FromStrings("app", "9"), Point: promql.Point{T: start.UnixMilli(), V: 30}},
+	}, res.Data)
+
+	// the same as the exact query
+	exact, err := regular.Query(params).Exec(ctx)
+	require.Nil(t, err)
+	require.Equal(t, exact.Data, res.Data)
+}
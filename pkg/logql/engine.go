@@ -276,6 +276,13 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 	}
 }
 
+// sortedByValue tells if the results of an instant query of expr are sorted by value instead of labels.
+// As in PromQL, this is the case of the queries of sort and sort_desc aggregations only.
+func sortedByValue(expr syntax.SampleExpr) bool {
+	e, ok := expr.(*syntax.VectorAggregationExpr)
+	return ok && (e.Operation == syntax.OpTypeSort || e.Operation == syntax.OpTypeSortDesc)
+}
+
 // evalSample evaluate a sampleExpr
 func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_parser.Value, error) {
 	if lit, ok := expr.(*syntax.LiteralExpr); ok {
@@ -301,6 +308,11 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 		return nil, err
 	}
 	maxSeries := validation.SmallestPositiveIntPerTenant(tenantIDs, q.limits.MaxQuerySeries)
+	if e, ok := expr.(*syntax.VectorAggregationExpr); ok && e.Operation == syntax.OpTypeApproxTopK && len(q.params.Shards()) > 0 {
+		// the counters of the sketch sent along with the top k series of the shard don't count as series.
+		depth, width := approxTopKSketchDimensions(e.Params)
+		maxSeries += int(depth * width)
+	}
 	seriesIndex := map[uint64]*promql.Series{}
 
 	next, ts, vec := stepEvaluator.Next()
@@ -314,7 +326,10 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 	}
 
 	if GetRangeType(q.params) == InstantType {
-		sort.Slice(vec, func(i, j int) bool { return labels.Compare(vec[i].Metric, vec[j].Metric) < 0 })
+		// the vector is already sorted by value by sort and sort_desc.
+		if !sortedByValue(expr) {
+			sort.Slice(vec, func(i, j int) bool { return labels.Compare(vec[i].Metric, vec[j].Metric) < 0 })
+		}
 		return vec, nil
 	}
 
@@ -514,6 +514,42 @@ func TestEngine_LogsInstantQuery(t *testing.T) {
 				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.25}, Metric: labels.Labels{labels.Label{Name: "app", Value: "bar"}}},
 			},
 		},
+		{
+			`sort(rate(({app=~"foo|bar"} |~".+bar")[1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(10, identity), `{app="foo"}`), newSeries(testSize, offset(46, identity), `{app="bar"}`),
+					newSeries(testSize, factor(5, identity), `{app="fuzz"}`), newSeries(testSize, factor(10, identity), `{app="buzz"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `rate({app=~"foo|bar"}|~".+bar"[1m])`}},
+			},
+			promql.Vector{
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.1}, Metric: labels.FromStrings("app", "buzz")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.1}, Metric: labels.FromStrings("app", "foo")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.2}, Metric: labels.FromStrings("app", "fuzz")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.25}, Metric: labels.FromStrings("app", "bar")},
+			},
+		},
+		{
+			`sort_desc(rate(({app=~"foo|bar"} |~".+bar")[1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{
+					newSeries(testSize, factor(10, identity), `{app="foo"}`), newSeries(testSize, offset(46, identity), `{app="bar"}`),
+					newSeries(testSize, factor(5, identity), `{app="fuzz"}`), newSeries(testSize, factor(10, identity), `{app="buzz"}`),
+				},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `rate({app=~"foo|bar"}|~".+bar"[1m])`}},
+			},
+			promql.Vector{
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.25}, Metric: labels.FromStrings("app", "bar")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.2}, Metric: labels.FromStrings("app", "fuzz")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.1}, Metric: labels.FromStrings("app", "buzz")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0.1}, Metric: labels.FromStrings("app", "foo")},
+			},
+		},
 		{
 			`topk(1,rate(({app=~"foo|bar"} |~".+bar")[1m])) by (app)`, time.Unix(60, 0), logproto.FORWARD, 100,
 			[][]logproto.Series{
@@ -896,6 +932,40 @@ func TestEngine_LogsInstantQuery(t *testing.T) {
 				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 0}, Metric: labels.Labels{labels.Label{Name: "app", Value: "foo"}, labels.Label{Name: "machine", Value: "fuzz"}, labels.Label{Name: "pool", Value: "foo"}}},
 			},
 		},
+		{
+			`sum by (app,machine) (count_over_time({app="foo"}[1m])) * on (machine) group_left (team) sum by (machine,team) (count_over_time({app="info"}[1m]))`,
+			time.Unix(60, 0),
+			logproto.FORWARD,
+			0,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo",machine="fuzz"}`), newSeries(testSize, identity, `{app="bar",machine="fuzz"}`), newSeries(testSize, identity, `{app="foo",machine="buzz"}`)},
+				{newSeries(testSize, identity, `{machine="fuzz",team="a"}`), newSeries(testSize, identity, `{machine="buzz",team="b"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (app,machine) (count_over_time({app="foo"}[1m]))`}},
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (machine,team) (count_over_time({app="info"}[1m]))`}},
+			},
+			promql.Vector{
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 3600}, Metric: labels.FromStrings("app", "bar", "machine", "fuzz", "team", "a")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 3600}, Metric: labels.FromStrings("app", "foo", "machine", "buzz", "team", "b")},
+				promql.Sample{Point: promql.Point{T: 60 * 1000, V: 3600}, Metric: labels.FromStrings("app", "foo", "machine", "fuzz", "team", "a")},
+			},
+		},
+		{
+			`sum by (app,machine) (count_over_time({app="foo"}[1m])) * on (machine) group_left (team) sum by (machine,team) (count_over_time({app="info"}[1m]))`,
+			time.Unix(60, 0),
+			logproto.FORWARD,
+			0,
+			[][]logproto.Series{
+				{newSeries(testSize, identity, `{app="foo",machine="fuzz"}`)},
+				{newSeries(testSize, identity, `{machine="fuzz",team="a"}`), newSeries(testSize, identity, `{machine="fuzz",team="b"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (app,machine) (count_over_time({app="foo"}[1m]))`}},
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `sum by (machine,team) (count_over_time({app="info"}[1m]))`}},
+			},
+			errors.New(`found duplicate series for the match group {machine="fuzz"} on the right hand-side of the operation: [{machine="fuzz", team="a"}, {machine="fuzz", team="b"}];many-to-many matching not allowed: matching labels must be unique on one side`),
+		},
 	} {
 		test := test
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
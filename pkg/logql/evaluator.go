@@ -171,6 +171,12 @@ func (ev *DefaultEvaluator) StepEvaluator(
 ) (StepEvaluator, error) {
 	switch e := expr.(type) {
 	case *syntax.VectorAggregationExpr:
+		if e.Operation == syntax.OpTypeApproxTopK {
+			return approxTopKEvaluator(ctx, nextEv, e, q)
+		}
+		if e.Operation == syntax.OpTypeSort || e.Operation == syntax.OpTypeSortDesc {
+			return sortEvaluator(ctx, nextEv, e, q)
+		}
 		if rangExpr, ok := e.Left.(*syntax.RangeAggregationExpr); ok && e.Operation == syntax.OpTypeSum {
 			// if range expression is wrapped with a vector expression
 			// we should send the vector expression for allowing reducing labels at the source.
@@ -218,6 +224,36 @@ func (ev *DefaultEvaluator) StepEvaluator(
 	}
 }
 
+// sortEvaluator sorts the vector of each step by value, ascending for sort and descending for sort_desc.
+// Series with the same value are sorted by labels, so that the order is stable across steps.
+func sortEvaluator(
+	ctx context.Context,
+	ev SampleEvaluator,
+	expr *syntax.VectorAggregationExpr,
+	q Params,
+) (StepEvaluator, error) {
+	nextEvaluator, err := ev.StepEvaluator(ctx, ev, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+	desc := expr.Operation == syntax.OpTypeSortDesc
+	return newStepEvaluator(func() (bool, int64, promql.Vector) {
+		next, ts, vec := nextEvaluator.Next()
+		sort.Slice(vec, func(i, j int) bool {
+			vi, vj := vec[i].V, vec[j].V
+			if vi != vj {
+				// NaN values go last, whatever the order.
+				if math.IsNaN(vi) || math.IsNaN(vj) {
+					return math.IsNaN(vj)
+				}
+				return (vi < vj) != desc
+			}
+			return labels.Compare(vec[i].Metric, vec[j].Metric) < 0
+		})
+		return next, ts, vec
+	}, nextEvaluator.Close, nextEvaluator.Error)
+}
+
 func vectorAggEvaluator(
 	ctx context.Context,
 	ev SampleEvaluator,
@@ -659,12 +695,17 @@ func binOpStepEvaluator(
 }
 
 func matchingSignature(sample promql.Sample, opts *syntax.BinOpOptions) uint64 {
+	return matchingGroup(sample.Metric, opts).Hash()
+}
+
+// matchingGroup returns the labels the sample is matched by in binary operations.
+func matchingGroup(metric labels.Labels, opts *syntax.BinOpOptions) labels.Labels {
 	if opts == nil || opts.VectorMatching == nil {
-		return sample.Metric.Hash()
+		return metric
 	} else if opts.VectorMatching.On {
-		return labels.NewBuilder(sample.Metric).Keep(opts.VectorMatching.MatchingLabels...).Labels(nil).Hash()
+		return labels.NewBuilder(metric).Keep(opts.VectorMatching.MatchingLabels...).Labels(nil)
 	} else {
-		return labels.NewBuilder(sample.Metric).Del(opts.VectorMatching.MatchingLabels...).Labels(nil).Hash()
+		return labels.NewBuilder(metric).Del(opts.VectorMatching.MatchingLabels...).Labels(nil)
 	}
 }
 
@@ -682,13 +723,18 @@ func vectorBinop(op string, opts *syntax.BinOpOptions, lhs, rhs promql.Vector, l
 	// Add all rhs samples to a map, so we can easily find matches later.
 	for i, sample := range rhs {
 		sig := rsigs[i]
-		if rightSigs[sig] != nil {
+		if duplicate := rightSigs[sig]; duplicate != nil {
 			side := "right"
-			if opts.VectorMatching.Card == syntax.CardOneToMany {
+			if opts != nil && opts.VectorMatching.Card == syntax.CardOneToMany {
 				side = "left"
 			}
-			return nil, fmt.Errorf("found duplicate series on the %s hand-side"+
-				";many-to-many matching not allowed: matching labels must be unique on one side", side)
+			first, second := duplicate.Metric, sample.Metric
+			if labels.Compare(first, second) > 0 {
+				first, second = second, first
+			}
+			return nil, fmt.Errorf("found duplicate series for the match group %s on the %s hand-side of the operation: [%s, %s]"+
+				";many-to-many matching not allowed: matching labels must be unique on one side",
+				matchingGroup(sample.Metric, opts), side, first, second)
 		}
 		rightSigs[sig] = &promql.Sample{
 			Metric: sample.Metric,
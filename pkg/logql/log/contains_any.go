@@ -0,0 +1,185 @@
+package log
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// containsAnyFilter matches the lines containing any of its literals, in a single pass over the line using an
+// Aho–Corasick automaton, instead of one pass per literal as a chain of `or` contains filters does.
+type containsAnyFilter struct {
+	matches         [][]byte
+	caseInsensitive bool
+
+	// The automaton is built on the first use, as filters are combined one by one when a query is parsed.
+	once    sync.Once
+	matcher *multiMatcher
+}
+
+// newContainsAnyFilter combines contains filters into a containsAnyFilter, if they have the same case sensitivity.
+// Case-insensitive literals are only combined if they are ASCII, the automaton folding the ASCII case only.
+func newContainsAnyFilter(left, right Filterer) (Filterer, bool) {
+	leftMatches, leftCaseInsensitive, ok := containsMatches(left)
+	if !ok {
+		return nil, false
+	}
+	rightMatches, rightCaseInsensitive, ok := containsMatches(right)
+	if !ok || leftCaseInsensitive != rightCaseInsensitive {
+		return nil, false
+	}
+
+	matches := make([][]byte, 0, len(leftMatches)+len(rightMatches))
+	matches = append(matches, leftMatches...)
+	matches = append(matches, rightMatches...)
+	return &containsAnyFilter{
+		matches:         matches,
+		caseInsensitive: leftCaseInsensitive,
+	}, true
+}
+
+func containsMatches(f Filterer) ([][]byte, bool, bool) {
+	switch c := f.(type) {
+	case *containsFilter:
+		if c.caseInsensitive && !isASCII(c.match) {
+			return nil, false, false
+		}
+		return [][]byte{c.match}, c.caseInsensitive, true
+	case *containsAnyFilter:
+		return c.matches, c.caseInsensitive, true
+	}
+	return nil, false, false
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *containsAnyFilter) Filter(line []byte) bool {
+	f.once.Do(func() {
+		f.matcher = newMultiMatcher(f.matches, f.caseInsensitive)
+	})
+	return f.matcher.match(line)
+}
+
+func (f *containsAnyFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, f.Filter(line)
+		},
+	}
+}
+
+// multiMatcher is an Aho–Corasick automaton compiled into a DFA over the classes of the bytes of the literals.
+type multiMatcher struct {
+	caseInsensitive bool
+	// classes maps each byte to its class, 0 being the class of the bytes which are in none of the literals.
+	classes    [256]int32
+	numClasses int32
+	// transitions is indexed by state*numClasses+class.
+	transitions []int32
+	// terminal is true for the states where a literal has been matched.
+	terminal []bool
+}
+
+func newMultiMatcher(matches [][]byte, caseInsensitive bool) *multiMatcher {
+	m := &multiMatcher{caseInsensitive: caseInsensitive, numClasses: 1}
+	for _, match := range matches {
+		for _, c := range match {
+			if m.classes[c] == 0 {
+				m.classes[c] = m.numClasses
+				m.numClasses++
+			}
+		}
+	}
+	if caseInsensitive {
+		for c := 'A'; c <= 'Z'; c++ {
+			m.classes[c] = m.classes[c+'a'-'A']
+		}
+	}
+
+	// Build the trie of the literals, -1 marking the missing transitions.
+	m.addState()
+	for _, match := range matches {
+		state := int32(0)
+		for _, c := range match {
+			idx := state*m.numClasses + m.classes[c]
+			if m.transitions[idx] < 0 {
+				m.transitions[idx] = m.addState()
+			}
+			state = m.transitions[idx]
+		}
+		m.terminal[state] = true
+	}
+
+	// Fill the missing transitions with the transitions of the failure states, in breadth first order so that
+	// the failure states are complete when they are used.
+	failure := make([]int32, len(m.terminal))
+	queue := make([]int32, 0, len(m.terminal))
+	for class := int32(0); class < m.numClasses; class++ {
+		next := m.transitions[class]
+		if next <= 0 {
+			m.transitions[class] = 0
+			continue
+		}
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.terminal[state] = m.terminal[state] || m.terminal[failure[state]]
+
+		for class := int32(0); class < m.numClasses; class++ {
+			idx := state*m.numClasses + class
+			fallback := m.transitions[failure[state]*m.numClasses+class]
+			if next := m.transitions[idx]; next >= 0 {
+				failure[next] = fallback
+				queue = append(queue, next)
+				continue
+			}
+			m.transitions[idx] = fallback
+		}
+	}
+	return m
+}
+
+func (m *multiMatcher) addState() int32 {
+	state := int32(len(m.terminal))
+	for class := int32(0); class < m.numClasses; class++ {
+		m.transitions = append(m.transitions, -1)
+	}
+	m.terminal = append(m.terminal, false)
+	return state
+}
+
+func (m *multiMatcher) match(line []byte) bool {
+	if m.terminal[0] {
+		return true
+	}
+	state := int32(0)
+	for i := 0; i < len(line); {
+		c := line[i]
+		class := m.classes[c]
+		if m.caseInsensitive && c >= utf8.RuneSelf {
+			// A few non-ASCII runes, such as the Kelvin sign, are lowercased to ASCII.
+			r, size := utf8.DecodeRune(line[i:])
+			class = 0
+			if lr := unicode.To(unicode.LowerCase, r); lr < utf8.RuneSelf {
+				class = m.classes[lr]
+			}
+			i += size
+		} else {
+			i++
+		}
+		state = m.transitions[state*m.numClasses+class]
+		if m.terminal[state] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ContainsAnyFilter(t *testing.T) {
+	lines := []string{
+		"",
+		"foo",
+		"a foobar line",
+		"aaab",
+		"she said hers",
+		"HERS IN CAPITALS",
+		"ushers",
+		"unicode ünïcödé ушерс",
+		"the Kelvin sign",
+		"nothing to see",
+	}
+
+	for _, tc := range []struct {
+		re      string
+		matches []string
+	}{
+		{"foo|bar", []string{"foo", "bar"}},
+		{"he|she|his|hers", []string{"he", "she", "his", "hers"}},
+		{"aab|ab|b", []string{"aab", "ab", "b"}},
+		{"(?i)HERS|usher|kelvin", []string{"hers", "usher", "kelvin"}},
+		{"ünï|ушер", []string{"ünï", "ушер"}},
+	} {
+		t.Run(tc.re, func(t *testing.T) {
+			f, err := NewFilter(tc.re, labels.MatchRegexp)
+			require.NoError(t, err)
+			require.IsType(t, &containsAnyFilter{}, f)
+			require.Len(t, f.(*containsAnyFilter).matches, len(tc.matches))
+
+			re := regexp.MustCompile(tc.re)
+			for _, line := range lines {
+				require.Equal(t, re.MatchString(line), f.Filter([]byte(line)), line)
+			}
+		})
+	}
+}
+
+func Test_ContainsAnyFilter_NotChain(t *testing.T) {
+	filters := make([]Filterer, 0, 3)
+	for _, match := range []string{"foo", "bar", "buzz"} {
+		f, err := NewFilter(match, labels.MatchNotEqual)
+		require.NoError(t, err)
+		filters = append(filters, f)
+	}
+	f := NewAndFilters(filters)
+	require.IsType(t, notFilter{}, f)
+
+	for line, expected := range map[string]bool{
+		"nothing":    true,
+		"foo":        false,
+		"a bar line": false,
+		"buz":        true,
+		"buzz":       false,
+	} {
+		require.Equal(t, expected, f.Filter([]byte(line)), line)
+	}
+}
+
+func Benchmark_ContainsAnyFilter(b *testing.B) {
+	keywords := []string{"error", "panic", "fatal", "timeout", "refused", "exception", "denied", "unavailable"}
+	f, err := NewFilter(strings.Join(keywords, "|"), labels.MatchRegexp)
+	require.NoError(b, err)
+	line := []byte(`level=info ts=2022-08-10T10:00:00.000Z caller=main.go:42 msg="request processed" path=/api/v1/push duration=12ms status=200`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Filter(line)
+	}
+}
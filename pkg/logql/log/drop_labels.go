@@ -0,0 +1,64 @@
+package log
+
+import (
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+// DropLabels is a stage removing labels by name.
+type DropLabels struct {
+	names []string
+}
+
+// NewDropLabels creates a stage removing the labels of the given names.
+// Dropping the error labels clears the error of the line.
+func NewDropLabels(names []string) *DropLabels {
+	return &DropLabels{names: names}
+}
+
+func (dl *DropLabels) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	for _, name := range dl.names {
+		switch name {
+		case logqlmodel.ErrorLabel:
+			lbs.SetErr("")
+		case logqlmodel.ErrorDetailsLabel:
+			lbs.SetErrorDetails("")
+		default:
+			lbs.Del(name)
+		}
+	}
+	return line, true
+}
+
+func (dl *DropLabels) RequiredLabelNames() []string { return []string{} }
+
+// KeepLabels is a stage removing all labels but the ones of the given names.
+type KeepLabels struct {
+	names map[string]struct{}
+	buf   []string
+}
+
+// NewKeepLabels creates a stage keeping only the labels of the given names.
+// The error labels are always kept, so that errors aren't silently ignored.
+func NewKeepLabels(names []string) *KeepLabels {
+	kl := &KeepLabels{names: make(map[string]struct{}, len(names))}
+	for _, name := range names {
+		kl.names[name] = struct{}{}
+	}
+	return kl
+}
+
+func (kl *KeepLabels) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	kl.buf = kl.buf[:0]
+	for _, l := range lbs.UnsortedLabels(nil) {
+		if l.Name == logqlmodel.ErrorLabel || l.Name == logqlmodel.ErrorDetailsLabel {
+			continue
+		}
+		if _, ok := kl.names[l.Name]; !ok {
+			kl.buf = append(kl.buf, l.Name)
+		}
+	}
+	lbs.Del(kl.buf...)
+	return line, true
+}
+
+func (kl *KeepLabels) RequiredLabelNames() []string { return []string{} }
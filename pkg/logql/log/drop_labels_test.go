@@ -0,0 +1,72 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+func Test_DropLabels(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		stage    Stage
+		err      string
+		expected labels.Labels
+	}{
+		{
+			"drop stream and parsed labels",
+			NewDropLabels([]string{"app", "status"}),
+			"",
+			labels.FromStrings("namespace", "prod", "pod", "pod-1"),
+		},
+		{
+			"drop missing label",
+			NewDropLabels([]string{"foo"}),
+			"",
+			labels.FromStrings("app", "foo", "namespace", "prod", "pod", "pod-1", "status", "200"),
+		},
+		{
+			"drop error",
+			NewDropLabels([]string{logqlmodel.ErrorLabel, logqlmodel.ErrorDetailsLabel}),
+			errJSON,
+			labels.FromStrings("app", "foo", "namespace", "prod", "pod", "pod-1", "status", "200"),
+		},
+		{
+			"keep stream and parsed labels",
+			NewKeepLabels([]string{"namespace", "status"}),
+			"",
+			labels.FromStrings("namespace", "prod", "status", "200"),
+		},
+		{
+			"keep missing label",
+			NewKeepLabels([]string{"foo"}),
+			"",
+			labels.EmptyLabels(),
+		},
+		{
+			"keep error",
+			NewKeepLabels([]string{"app"}),
+			errJSON,
+			labels.FromStrings("app", "foo", logqlmodel.ErrorLabel, errJSON, logqlmodel.ErrorDetailsLabel, "details"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lbs := labels.FromStrings("app", "foo", "namespace", "prod", "pod", "pod-1")
+			b := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+			b.Reset()
+			b.Set("status", "200")
+			if tc.err != "" {
+				b.SetErr(tc.err)
+				b.SetErrorDetails("details")
+			}
+
+			line, ok := tc.stage.Process(0, []byte("line"), b)
+			require.True(t, ok)
+			require.Equal(t, []byte("line"), line)
+			require.Equal(t, tc.expected, b.LabelsResult().Labels())
+		})
+	}
+}
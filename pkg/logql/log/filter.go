@@ -10,6 +10,8 @@ import (
 	"github.com/grafana/regexp/syntax"
 
 	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logql/log/pattern"
 )
 
 // Filterer is a interface to filter log lines.
@@ -100,6 +102,8 @@ type andFilters struct {
 // NewAndFilters creates a new filter which matches only if all filters match
 func NewAndFilters(filters []Filterer) Filterer {
 	var containsFilterAcc *containsAllFilter
+	// not(a) and not(b) = not(a or b), which is matched in a single pass over the line.
+	var notContainsFilterAcc Filterer
 	regexpFilters := make([]Filterer, 0)
 	n := 0
 	for _, filter := range filters {
@@ -116,6 +120,13 @@ func NewAndFilters(filters []Filterer) Filterer {
 				containsFilterAcc.Add(*c)
 			case regexpFilter:
 				regexpFilters = append(regexpFilters, c)
+			case notFilter:
+				if _, _, ok := containsMatches(c.Filterer); ok {
+					notContainsFilterAcc = newOrFilter(notContainsFilterAcc, c.Filterer)
+					continue
+				}
+				filters[n] = filter
+				n++
 
 			default:
 				// Finish accumulating contains filters.
@@ -137,6 +148,10 @@ func NewAndFilters(filters []Filterer) Filterer {
 		filters = append(filters, containsFilterAcc)
 	}
 
+	if notContainsFilterAcc != nil {
+		filters = append(filters, notFilter{Filterer: notContainsFilterAcc})
+	}
+
 	// Push regex filters to end
 	if len(regexpFilters) > 0 {
 		filters = append(filters, regexpFilters...)
@@ -176,6 +191,7 @@ type orFilter struct {
 }
 
 // newOrFilter creates a new filter which matches only if left or right matches.
+// Contains filters are combined into a single filter matching any of their literals.
 func newOrFilter(left Filterer, right Filterer) Filterer {
 	if left == nil || left == TrueFilter {
 		return right
@@ -185,6 +201,10 @@ func newOrFilter(left Filterer, right Filterer) Filterer {
 		return left
 	}
 
+	if f, ok := newContainsAnyFilter(left, right); ok {
+		return f
+	}
+
 	return orFilter{
 		left:  left,
 		right: right,
@@ -368,6 +388,38 @@ func NewFilter(match string, mt labels.MatchType) (Filterer, error) {
 	}
 }
 
+type patternFilter struct {
+	matcher *pattern.LineMatcher
+}
+
+// NewPatternFilter creates a new filter matching lines against a pattern, e.g. `<_> error <_>`.
+func NewPatternFilter(p string, mt labels.MatchType) (Filterer, error) {
+	m, err := pattern.NewLineMatcher(p)
+	if err != nil {
+		return nil, err
+	}
+	switch mt {
+	case labels.MatchEqual:
+		return patternFilter{matcher: m}, nil
+	case labels.MatchNotEqual:
+		return newNotFilter(patternFilter{matcher: m}), nil
+	default:
+		return nil, fmt.Errorf("unknown matcher: %v", p)
+	}
+}
+
+func (f patternFilter) Filter(line []byte) bool {
+	return f.matcher.Test(line)
+}
+
+func (f patternFilter) ToStage() Stage {
+	return StageFunc{
+		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+			return line, f.Filter(line)
+		},
+	}
+}
+
 // parseRegexpFilter parses a regexp and attempt to simplify it with only literal filters.
 // If not possible it will returns the original regexp filter.
 func parseRegexpFilter(re string, match bool) (Filterer, error) {
@@ -71,6 +71,8 @@ type BaseLabelsBuilder struct {
 	err string
 	// nolint:structcheck
 	errDetails string
+	// non-indexed labels of the entry being processed.
+	nonIndexedLabels labels.Labels
 
 	groups            []string
 	parserKeyHints    ParserHint // label key hints for metric queries that allows to limit parser extractions to only this list of labels.
@@ -137,6 +139,17 @@ func (b *LabelsBuilder) Reset() {
 	b.add = b.add[:0]
 	b.err = ""
 	b.errDetails = ""
+	b.nonIndexedLabels = nil
+}
+
+// SetNonIndexedLabels sets the non-indexed labels of the entry being processed.
+func (b *LabelsBuilder) SetNonIndexedLabels(lbs labels.Labels) {
+	b.nonIndexedLabels = lbs
+}
+
+// NonIndexedLabels returns the non-indexed labels of the entry being processed.
+func (b *LabelsBuilder) NonIndexedLabels() labels.Labels {
+	return b.nonIndexedLabels
 }
 
 // ParserLabelHints returns a limited list of expected labels to extract for metric queries.
@@ -38,14 +38,32 @@ type JSONParser struct {
 	buf []byte // buffer used to build json keys
 	lbs *LabelsBuilder
 
-	keys internedStringSet
+	keys   internedStringSet
+	limits JSONParserLimits
+	// number of labels extracted from the current line.
+	extracted int
+}
+
+// JSONParserLimits limit the labels extracted by the json parser from each line. Zero values mean no limit.
+type JSONParserLimits struct {
+	// MaxDepth is the maximum depth of the nested objects flattened into labels, top level keys being at depth 1.
+	// The objects at the maximum depth are extracted as their json value instead.
+	MaxDepth int
+	// MaxKeys is the maximum number of labels extracted. The extraction stops at the limit and the line gets an error.
+	MaxKeys int
 }
 
 // NewJSONParser creates a log stage that can parse a json log line and add properties as labels.
 func NewJSONParser() *JSONParser {
+	return NewJSONParserWithLimits(JSONParserLimits{})
+}
+
+// NewJSONParserWithLimits is like NewJSONParser, limiting the labels extracted from each line.
+func NewJSONParserWithLimits(limits JSONParserLimits) *JSONParser {
 	return &JSONParser{
-		buf:  make([]byte, 0, 1024),
-		keys: internedStringSet{},
+		buf:    make([]byte, 0, 1024),
+		keys:   internedStringSet{},
+		limits: limits,
 	}
 }
 
@@ -59,6 +77,7 @@ func (j *JSONParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte,
 	// reset the state.
 	j.buf = j.buf[:0]
 	j.lbs = lbs
+	j.extracted = 0
 
 	if err := j.readObject(it); err != nil {
 		lbs.SetErr(errJSON)
@@ -73,23 +92,35 @@ func (j *JSONParser) readObject(it *jsoniter.Iterator) error {
 	if nextType := it.WhatIsNext(); nextType != jsoniter.ObjectValue {
 		return errUnexpectedJSONObject
 	}
-	_ = it.ReadMapCB(j.parseMap(""))
+	completed := it.ReadMapCB(j.parseMap("", 1))
 	if it.Error != nil && it.Error != io.EOF {
 		return it.Error
 	}
+	if !completed && j.limitKeys() {
+		return fmt.Errorf("json parser extracted more than the maximum of %d keys", j.limits.MaxKeys)
+	}
 	return nil
 }
 
-func (j *JSONParser) parseMap(prefix string) func(iter *jsoniter.Iterator, field string) bool {
+// limitKeys tells if the maximum number of keys were extracted from the current line.
+func (j *JSONParser) limitKeys() bool {
+	return j.limits.MaxKeys > 0 && j.extracted >= j.limits.MaxKeys
+}
+
+func (j *JSONParser) parseMap(prefix string, depth int) func(iter *jsoniter.Iterator, field string) bool {
 	return func(iter *jsoniter.Iterator, field string) bool {
 		switch iter.WhatIsNext() {
 		// are we looking at a value that needs to be added ?
 		case jsoniter.StringValue, jsoniter.NumberValue, jsoniter.BoolValue:
-			j.parseLabelValue(iter, prefix, field)
+			return j.parseLabelValue(iter, prefix, field, false)
 		// Or another new object based on a prefix.
 		case jsoniter.ObjectValue:
+			// the objects at the maximum depth are not flattened.
+			if j.limits.MaxDepth > 0 && depth >= j.limits.MaxDepth {
+				return j.parseLabelValue(iter, prefix, field, true)
+			}
 			if key, ok := j.nextKeyPrefix(prefix, field); ok {
-				return iter.ReadMapCB(j.parseMap(key))
+				return iter.ReadMapCB(j.parseMap(key, depth+1))
 			}
 			// If this keys is not expected we skip the object
 			iter.Skip()
@@ -121,10 +152,31 @@ func (j *JSONParser) nextKeyPrefix(prefix, field string) (string, bool) {
 	return "", false
 }
 
-func (j *JSONParser) parseLabelValue(iter *jsoniter.Iterator, prefix, field string) {
+// parseLabelValue adds the value as a label, raw being whether the json of the value is added as is.
+// It returns false when the maximum number of keys is reached, to stop the parsing.
+func (j *JSONParser) parseLabelValue(iter *jsoniter.Iterator, prefix, field string, raw bool) bool {
+	key, ok := j.labelKey(prefix, field)
+	if !ok {
+		iter.Skip()
+		return true
+	}
+	if j.limitKeys() {
+		iter.Skip()
+		return false
+	}
+	if raw {
+		j.lbs.Set(key, string(iter.SkipAndReturnBytes()))
+	} else {
+		j.lbs.Set(key, readValue(iter))
+	}
+	j.extracted++
+	return true
+}
+
+func (j *JSONParser) labelKey(prefix, field string) (string, bool) {
 	// the first time we use the field as label key.
 	if len(prefix) == 0 {
-		key, ok := j.keys.Get(unsafeGetBytes(field), func() (string, bool) {
+		return j.keys.Get(unsafeGetBytes(field), func() (string, bool) {
 			field = sanitizeLabelKey(field, true)
 			if !j.lbs.ParserLabelHints().ShouldExtract(field) {
 				return "", false
@@ -134,20 +186,13 @@ func (j *JSONParser) parseLabelValue(iter *jsoniter.Iterator, prefix, field stri
 			}
 			return field, true
 		})
-		if !ok {
-			iter.Skip()
-			return
-		}
-		j.lbs.Set(key, readValue(iter))
-		return
-
 	}
 	// otherwise we build the label key using the buffer
 	j.buf = j.buf[:0]
 	j.buf = append(j.buf, prefix...)
 	j.buf = append(j.buf, byte(jsonSpacer))
 	j.buf = append(j.buf, sanitizeLabelKey(field, false)...)
-	key, ok := j.keys.Get(j.buf, func() (string, bool) {
+	return j.keys.Get(j.buf, func() (string, bool) {
 		if j.lbs.BaseHas(string(j.buf)) {
 			j.buf = append(j.buf, duplicateSuffix...)
 		}
@@ -156,11 +201,6 @@ func (j *JSONParser) parseLabelValue(iter *jsoniter.Iterator, prefix, field stri
 		}
 		return string(j.buf), true
 	})
-	if !ok {
-		iter.Skip()
-		return
-	}
-	j.lbs.Set(key, readValue(iter))
 }
 
 func (j *JSONParser) RequiredLabelNames() []string { return []string{} }
@@ -489,3 +529,30 @@ func (u *UnpackParser) unpack(it *jsoniter.Iterator, entry []byte, lbs *LabelsBu
 	}
 	return entry, nil
 }
+
+// MetadataParser extracts the non-indexed labels attached to an entry.
+type MetadataParser struct{}
+
+// NewMetadataParser creates a parser that extracts each non-indexed label of an entry into a label.
+func NewMetadataParser() *MetadataParser {
+	return &MetadataParser{}
+}
+
+func (m *MetadataParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+	for _, l := range lbs.NonIndexedLabels() {
+		if !lbs.ParserLabelHints().ShouldExtract(l.Name) {
+			continue
+		}
+		name := l.Name
+		if lbs.BaseHas(name) {
+			name = name + duplicateSuffix
+		}
+		lbs.Set(name, l.Value)
+	}
+	return line, true
+}
+
+func (m *MetadataParser) RequiredLabelNames() []string { return []string{} }
@@ -111,6 +111,53 @@ func Test_jsonParser_Parse(t *testing.T) {
 	}
 }
 
+func Test_jsonParser_Limits(t *testing.T) {
+	line := []byte(`{"app":"foo","pod":{"uuid":"foo","deployment":{"ref":"foobar","replicas":3}},"namespace":"prod"}`)
+	tests := []struct {
+		name   string
+		limits JSONParserLimits
+		want   labels.Labels
+	}{
+		{
+			"no limits",
+			JSONParserLimits{},
+			labels.FromStrings("app", "foo", "pod_uuid", "foo", "pod_deployment_ref", "foobar", "pod_deployment_replicas", "3", "namespace", "prod"),
+		},
+		{
+			"max depth",
+			JSONParserLimits{MaxDepth: 2},
+			labels.FromStrings("app", "foo", "pod_uuid", "foo", "pod_deployment", `{"ref":"foobar","replicas":3}`, "namespace", "prod"),
+		},
+		{
+			"max depth of top level keys",
+			JSONParserLimits{MaxDepth: 1},
+			labels.FromStrings("app", "foo", "pod", `{"uuid":"foo","deployment":{"ref":"foobar","replicas":3}}`, "namespace", "prod"),
+		},
+		{
+			"max keys not exceeded",
+			JSONParserLimits{MaxDepth: 1, MaxKeys: 3},
+			labels.FromStrings("app", "foo", "pod", `{"uuid":"foo","deployment":{"ref":"foobar","replicas":3}}`, "namespace", "prod"),
+		},
+		{
+			"max keys exceeded",
+			JSONParserLimits{MaxKeys: 3},
+			labels.FromStrings("app", "foo", "pod_uuid", "foo", "pod_deployment_ref", "foobar",
+				logqlmodel.ErrorLabel, errJSON,
+				logqlmodel.ErrorDetailsLabel, "json parser extracted more than the maximum of 3 keys",
+			),
+		},
+	}
+	for _, tt := range tests {
+		j := NewJSONParserWithLimits(tt.limits)
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBaseLabelsBuilder().ForLabels(labels.EmptyLabels(), 0)
+			b.Reset()
+			_, _ = j.Process(0, line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
 func TestJSONExpressionParser(t *testing.T) {
 	testLine := []byte(`{"app":"foo","field with space":"value","field with ÜFT8👌":"value","null_field":null,"bool_field":false,"namespace":"prod","pod":{"uuid":"foo","deployment":{"ref":"foobar", "params": [1,2,3]}}}`)
 
@@ -885,3 +932,51 @@ func Test_PatternParser(t *testing.T) {
 		})
 	}
 }
+
+func Test_MetadataParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		nonIndexed labels.Labels
+		lbs        labels.Labels
+		want       labels.Labels
+	}{
+		{
+			"no non-indexed labels",
+			nil,
+			labels.Labels{{Name: "foo", Value: "bar"}},
+			labels.Labels{{Name: "foo", Value: "bar"}},
+		},
+		{
+			"non-indexed labels",
+			labels.Labels{{Name: "trace_id", Value: "3c0e3dcd33e7"}, {Name: "user", Value: "bob"}},
+			labels.Labels{{Name: "foo", Value: "bar"}},
+			labels.Labels{
+				{Name: "foo", Value: "bar"},
+				{Name: "trace_id", Value: "3c0e3dcd33e7"},
+				{Name: "user", Value: "bob"},
+			},
+		},
+		{
+			"duplicate stream label",
+			labels.Labels{{Name: "foo", Value: "buzz"}},
+			labels.Labels{{Name: "foo", Value: "bar"}},
+			labels.Labels{
+				{Name: "foo", Value: "bar"},
+				{Name: "foo_extracted", Value: "buzz"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			b.SetNonIndexedLabels(tt.nonIndexed)
+			_, _ = NewMetadataParser().Process(0, []byte("line"), b)
+			sort.Sort(tt.want)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
@@ -19,16 +19,8 @@ func (e expr) validate() error {
 	if !e.hasCapture() {
 		return ErrNoCapture
 	}
-	// Consecutive captures are not allowed.
-	for i, n := range e {
-		if i+1 >= len(e) {
-			break
-		}
-		if _, ok := n.(capture); ok {
-			if _, ok := e[i+1].(capture); ok {
-				return fmt.Errorf("found consecutive capture '%s': %w", n.String()+e[i+1].String(), ErrInvalidExpr)
-			}
-		}
+	if err := e.validateNoConsecutiveCaptures(); err != nil {
+		return err
 	}
 
 	caps := e.captures()
@@ -42,6 +34,21 @@ func (e expr) validate() error {
 	return nil
 }
 
+// Consecutive captures are not allowed.
+func (e expr) validateNoConsecutiveCaptures() error {
+	for i, n := range e {
+		if i+1 >= len(e) {
+			break
+		}
+		if _, ok := n.(capture); ok {
+			if _, ok := e[i+1].(capture); ok {
+				return fmt.Errorf("found consecutive capture '%s': %w", n.String()+e[i+1].String(), ErrInvalidExpr)
+			}
+		}
+	}
+	return nil
+}
+
 func (e expr) captures() (captures []string) {
 	for _, n := range e {
 		if c, ok := n.(capture); ok && !c.isUnamed() {
@@ -93,3 +93,56 @@ func (m *matcher) Matches(in []byte) [][]byte {
 func (m *matcher) Names() []string {
 	return m.names
 }
+
+// LineMatcher tests whether whole lines match a pattern, e.g. `<_> error <_>`.
+// Unlike Matcher, patterns don't require any capture and capture names are ignored.
+// Captures never match empty text.
+type LineMatcher struct {
+	e expr
+}
+
+func NewLineMatcher(in string) (*LineMatcher, error) {
+	e, err := parseExpr(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.validateNoConsecutiveCaptures(); err != nil {
+		return nil, err
+	}
+	return &LineMatcher{e: e}, nil
+}
+
+// Test returns true if the line matches the pattern.
+func (m *LineMatcher) Test(in []byte) bool {
+	expr := m.e
+	if ls, ok := expr[0].(literals); ok {
+		if !bytes.HasPrefix(in, ls) {
+			return false
+		}
+		in = in[len(ls):]
+		expr = expr[1:]
+	}
+	// from now we have capture - literals - capture ... (literals)?
+	for len(expr) != 0 {
+		if len(expr) == 1 { // we're ending on a capture.
+			return len(in) != 0
+		}
+		ls := expr[1].(literals)
+		expr = expr[2:]
+		if len(expr) == 0 {
+			// we're ending on literals, which must end the line.
+			return len(in) > len(ls) && bytes.HasSuffix(in, ls)
+		}
+		// the capture takes at least one byte, the literals are looked for after it.
+		if len(in) == 0 {
+			return false
+		}
+		i := bytes.Index(in[1:], ls)
+		if i == -1 {
+			return false
+		}
+		in = in[1+i+len(ls):]
+	}
+	// the pattern is only literals.
+	return len(in) == 0
+}
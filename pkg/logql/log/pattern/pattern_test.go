@@ -162,3 +162,57 @@ func Test_Error(t *testing.T) {
 		})
 	}
 }
+
+func Test_LineMatcher_Test(t *testing.T) {
+	for _, tt := range []struct {
+		expr    string
+		in      string
+		matches bool
+	}{
+		{"<_> error <_>", "an error occurred", true},
+		{"<_> error <_>", "error occurred", false},
+		{"<_> error <_>", "an error ", false},
+		{"<_> error <_>", "no errors", false},
+		{"foo <_>", "foo bar", true},
+		{"foo <_>", "foo ", false},
+		{"foo <_>", "a foo bar", false},
+		{"<_> bar", "foo bar", true},
+		{"<_> bar", "foo bar baz", false},
+		{"<_> bar", "foo bar bar", true},
+		{"<_> bar", " bar", false},
+		{"<_>bar<_>", "barbarbar", true},
+		{"<_>bar<_>", "barbar", false},
+		{"foo bar", "foo bar", true},
+		{"foo bar", "foo bar baz", false},
+		{"<_> [<level>] <_>", "2020-08-06T14:25:02.835618Z 0 [Note] [MY-012487]", true},
+		{"<_> [<level>] <_>", "2020-08-06T14:25:02.835618Z 0 [] [MY-012487]", false},
+		{"<_> [<level>] <_>", "2020-08-06T14:25:02.835618Z 0 Note MY-012487", false},
+		{"<_>", "", false},
+	} {
+		tt := tt
+		t.Run(tt.expr+" "+tt.in, func(t *testing.T) {
+			t.Parallel()
+			m, err := NewLineMatcher(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.matches, m.Test([]byte(tt.in)))
+		})
+	}
+}
+
+func Test_LineMatcher_Error(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{"<_>", nil},
+		{"foo bar buzz", nil},
+		{"<f> f<f>", nil},
+		{"", newParseError("syntax error: unexpected $end, expecting IDENTIFIER or LITERAL", 1, 1)},
+		{"<f><_>", fmt.Errorf("found consecutive capture '<f><_>': %w", ErrInvalidExpr)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLineMatcher(tt.name)
+			require.Equal(t, tt.err, err)
+		})
+	}
+}
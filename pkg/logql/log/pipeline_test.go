@@ -50,6 +50,26 @@ func TestPipeline(t *testing.T) {
 	require.Equal(t, false, matches)
 }
 
+func TestPipelineWithNonIndexedLabels(t *testing.T) {
+	lbs := labels.Labels{{Name: "foo", Value: "bar"}}
+	p := NewPipeline([]Stage{
+		NewMetadataParser(),
+		NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "1")),
+	}).ForStream(lbs)
+
+	l, lbr, matches := p.Process(0, []byte("line"), labels.Label{Name: "trace_id", Value: "1"})
+	require.Equal(t, []byte("line"), l)
+	require.Equal(t, labels.Labels{{Name: "foo", Value: "bar"}, {Name: "trace_id", Value: "1"}}, lbr.Labels())
+	require.Equal(t, true, matches)
+
+	_, _, matches = p.ProcessString(0, "line", labels.Label{Name: "trace_id", Value: "2"})
+	require.Equal(t, false, matches)
+
+	// non-indexed labels don't carry over to the next entry.
+	_, _, matches = p.ProcessString(0, "line")
+	require.Equal(t, false, matches)
+}
+
 func TestFilteringPipeline(t *testing.T) {
 	p := NewFilteringPipeline([]PipelineFilter{
 		newPipelineFilter(2, 4, labels.Labels{{Name: "foo", Value: "bar"}, {Name: "bar", Value: "baz"}}, "e"),
@@ -118,11 +138,11 @@ func (p *stubStreamPipeline) BaseLabels() LabelsResult {
 	return nil
 }
 
-func (p *stubStreamPipeline) Process(ts int64, line []byte) ([]byte, LabelsResult, bool) {
+func (p *stubStreamPipeline) Process(ts int64, line []byte, _ ...labels.Label) ([]byte, LabelsResult, bool) {
 	return nil, nil, true
 }
 
-func (p *stubStreamPipeline) ProcessString(ts int64, line string) (string, LabelsResult, bool) {
+func (p *stubStreamPipeline) ProcessString(ts int64, line string, _ ...labels.Label) (string, LabelsResult, bool) {
 	return "", nil, true
 }
 
@@ -174,6 +174,22 @@ func (m ShardMapper) mapSampleExpr(expr syntax.SampleExpr, r *downstreamRecorder
 // technically, std{dev,var} are also parallelizable if there is no cross-shard merging
 // in descendent nodes in the AST. This optimization is currently avoided for simplicity.
 func (m ShardMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr, r *downstreamRecorder) (syntax.SampleExpr, error) {
+	// approx_topk(k, x) -> approx_topk(k, approx_topk(k, x, shard=1) ++ approx_topk(k, x, shard=2)...)
+	// where each shard sends its top k along with a sketch of all of its series.
+	// This isn't part of Shardable as approx_topk can't be sharded as part of another expression.
+	if expr.Operation == syntax.OpTypeApproxTopK && approxTopKShardable(expr.Left) {
+		sharded, err := m.mapSampleExpr(expr, r)
+		if err != nil {
+			return nil, err
+		}
+		return &syntax.VectorAggregationExpr{
+			Left:      sharded,
+			Grouping:  expr.Grouping,
+			Params:    expr.Params,
+			Operation: expr.Operation,
+		}, nil
+	}
+
 	// if this AST contains unshardable operations, don't shard this at this level,
 	// but attempt to shard a child node.
 	if !expr.Shardable() {
@@ -285,6 +301,21 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 	}
 }
 
+// approxTopKShardable tells if the sketches of the shards of an expression can be merged, which requires the
+// value of each series to be the sum of its values in each shard.
+func approxTopKShardable(expr syntax.SampleExpr) bool {
+	switch e := expr.(type) {
+	case *syntax.VectorAggregationExpr:
+		return (e.Operation == syntax.OpTypeSum || e.Operation == syntax.OpTypeCount) && e.Shardable()
+	case *syntax.RangeAggregationExpr:
+		switch e.Operation {
+		case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeBytes:
+			return e.Shardable() && !hasLabelModifier(e)
+		}
+	}
+	return false
+}
+
 // hasLabelModifier tells if an expression contains pipelines that can modify stream labels
 // parsers introduce new labels but does not alter original one for instance.
 func hasLabelModifier(expr *syntax.RangeAggregationExpr) bool {
@@ -293,7 +324,8 @@ func hasLabelModifier(expr *syntax.RangeAggregationExpr) bool {
 		return false
 	case *syntax.PipelineExpr:
 		for _, p := range ex.MultiStages {
-			if _, ok := p.(*syntax.LabelFmtExpr); ok {
+			switch p.(type) {
+			case *syntax.LabelFmtExpr, *syntax.DropLabelsExpr, *syntax.KeepLabelsExpr:
 				return true
 			}
 		}
@@ -263,6 +263,34 @@ func TestMappingStrings(t *testing.T) {
 			in:  `avg_over_time({job=~"myapps.*"} |= "stats" | json busy="utilization" | unwrap busy [5m])`,
 			out: `avg_over_time({job=~"myapps.*"} |= "stats" | json busy="utilization" | unwrap busy [5m])`,
 		},
+		{
+			in: `approx_topk(3, sum by (app) (rate({foo="bar"}[5m])))`,
+			out: `approx_topk(3,
+				downstream<approx_topk(3, sum by (app) (rate({foo="bar"}[5m]))), shard=0_of_2>
+				++ downstream<approx_topk(3, sum by (app) (rate({foo="bar"}[5m]))), shard=1_of_2>
+			)`,
+		},
+		{
+			in: `approx_topk(3, max by (app) (rate({foo="bar"}[5m])))`,
+			out: `approx_topk(3, max by (app) (
+				downstream<rate({foo="bar"}[5m]), shard=0_of_2>
+				++ downstream<rate({foo="bar"}[5m]), shard=1_of_2>
+			))`,
+		},
+		{
+			in: `sum(approx_topk(3, rate({foo="bar"}[5m])))`,
+			out: `sum(approx_topk(3,
+				downstream<approx_topk(3, rate({foo="bar"}[5m])), shard=0_of_2>
+				++ downstream<approx_topk(3, rate({foo="bar"}[5m])), shard=1_of_2>
+			))`,
+		},
+		{
+			in: `sort_desc(sum by (app) (rate({foo="bar"}[5m])))`,
+			out: `sort_desc(sum by (app) (
+				downstream<sum by (app) (rate({foo="bar"}[5m])), shard=0_of_2>
+				++ downstream<sum by (app) (rate({foo="bar"}[5m])), shard=1_of_2>
+			))`,
+		},
 		// should be noop if VectorExpr
 		{
 			in:  `vector(0)`,
@@ -0,0 +1,63 @@
+package sketch
+
+import "math"
+
+// CountMinSketch estimates the sum of the values added for each key using a fixed amount of memory.
+// Estimates never underestimate the sum as long as values are positive, and overestimate it by at most
+// e/width times the sum of all the values added, with a probability of 1-e^-depth.
+type CountMinSketch struct {
+	Depth, Width uint32
+	Counters     [][]float64
+}
+
+// NewCountMinSketch creates an empty CountMinSketch.
+func NewCountMinSketch(depth, width uint32) *CountMinSketch {
+	counters := make([][]float64, depth)
+	for i := range counters {
+		counters[i] = make([]float64, width)
+	}
+	return &CountMinSketch{
+		Depth:    depth,
+		Width:    width,
+		Counters: counters,
+	}
+}
+
+// CountMinSketchDimensions returns the depth and width of the sketches overestimating the sums by at most epsilon
+// times the sum of all the values added, with a probability of 1-delta.
+func CountMinSketchDimensions(epsilon, delta float64) (depth, width uint32) {
+	return uint32(math.Ceil(math.Log(1 / delta))), uint32(math.Ceil(math.E / epsilon))
+}
+
+// Add adds the value to the counters of the key, given by its hash.
+func (s *CountMinSketch) Add(hash uint64, value float64) {
+	for i := uint32(0); i < s.Depth; i++ {
+		s.Counters[i][s.column(hash, i)] += value
+	}
+}
+
+// Count returns the estimated sum of the values added for the key, given by its hash.
+func (s *CountMinSketch) Count(hash uint64) float64 {
+	count := math.Inf(1)
+	for i := uint32(0); i < s.Depth; i++ {
+		count = math.Min(count, s.Counters[i][s.column(hash, i)])
+	}
+	return count
+}
+
+// AddCounter adds the value to a single counter, e.g. when merging a sketch which was sent counter by counter.
+// It returns false if the counter is out of the sketch's bounds.
+func (s *CountMinSketch) AddCounter(row, column uint32, value float64) bool {
+	if row >= s.Depth || column >= s.Width {
+		return false
+	}
+	s.Counters[row][column] += value
+	return true
+}
+
+// column returns the counter of the key in the given row, derived from the two halves of its hash
+// (Kirsch-Mitzenmacher double hashing), which avoids hashing the key once per row.
+func (s *CountMinSketch) column(hash uint64, row uint32) uint32 {
+	h1, h2 := uint32(hash), uint32(hash>>32)
+	return (h1 + row*h2) % s.Width
+}
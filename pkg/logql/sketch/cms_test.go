@@ -0,0 +1,50 @@
+package sketch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	s := NewCountMinSketch(4, 16)
+
+	var total float64
+	for i := 0; i < 100; i++ {
+		v := float64(i)
+		s.Add(labels.FromStrings("i", fmt.Sprintf("%d", i)).Hash(), v)
+		total += v
+	}
+
+	for i := 0; i < 100; i++ {
+		count := s.Count(labels.FromStrings("i", fmt.Sprintf("%d", i)).Hash())
+		// estimates never undercount and, with 4 rows, are very unlikely to be off by more than e/16 of the total.
+		require.GreaterOrEqual(t, count, float64(i))
+		require.LessOrEqual(t, count, float64(i)+total*2.72/16)
+	}
+}
+
+func TestCountMinSketchDimensions(t *testing.T) {
+	depth, width := CountMinSketchDimensions(0.01, 0.02)
+	require.Equal(t, uint32(4), depth)
+	require.Equal(t, uint32(272), width)
+}
+
+func TestCountMinSketch_AddCounter(t *testing.T) {
+	a, b := NewCountMinSketch(2, 8), NewCountMinSketch(2, 8)
+	a.Add(1, 5)
+	b.Add(1<<32|1, 3)
+	b.Add(1, 2)
+
+	// merge b into a counter by counter
+	for row, counters := range b.Counters {
+		for column, v := range counters {
+			require.True(t, a.AddCounter(uint32(row), uint32(column), v))
+		}
+	}
+	require.Equal(t, float64(7), a.Count(1))
+	require.False(t, a.AddCounter(2, 0, 1))
+	require.False(t, a.AddCounter(0, 8, 1))
+}
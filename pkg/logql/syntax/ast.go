@@ -207,6 +207,34 @@ func (e *PipelineExpr) HasFilter() bool {
 	return false
 }
 
+// RequiredLineFilters returns the strings that every line selected by the expression
+// contains, as required by its case-sensitive `|=` line filters. Line filters placed
+// after a stage which can rewrite the line are not taken into account.
+func RequiredLineFilters(expr LogSelectorExpr) []string {
+	p, ok := expr.(*PipelineExpr)
+	if !ok {
+		return nil
+	}
+	var res []string
+	for _, stage := range p.MultiStages {
+		switch s := stage.(type) {
+		case *LineFilterExpr:
+			for f := s; f != nil; f = f.Left {
+				if f.Ty == labels.MatchEqual && f.Op == "" && f.Match != "" {
+					res = append(res, f.Match)
+				}
+			}
+		case *LineFmtExpr:
+			return res
+		case *LabelParserExpr:
+			if s.Op == OpParserTypeUnpack {
+				return res
+			}
+		}
+	}
+	return res
+}
+
 type LineFilterExpr struct {
 	Left  *LineFilterExpr
 	Ty    labels.MatchType
@@ -262,6 +290,16 @@ func (e *LineFilterExpr) String() string {
 		sb.WriteString(e.Left.String())
 		sb.WriteString(" ")
 	}
+	if e.Op == OpFilterPattern {
+		if e.Ty == labels.MatchNotEqual {
+			sb.WriteString("!>")
+		} else {
+			sb.WriteString("|>")
+		}
+		sb.WriteString(" ")
+		sb.WriteString(strconv.Quote(e.Match))
+		return sb.String()
+	}
 	switch e.Ty {
 	case labels.MatchRegexp:
 		sb.WriteString("|~")
@@ -295,6 +333,12 @@ func (e *LineFilterExpr) Filter() (log.Filterer, error) {
 				return nil, err
 			}
 			acc = append(acc, next)
+		case OpFilterPattern:
+			next, err := log.NewPatternFilter(curr.Match, curr.Ty)
+			if err != nil {
+				return nil, err
+			}
+			acc = append(acc, next)
 		default:
 			next, err := log.NewFilter(curr.Match, curr.Ty)
 			if err != nil {
@@ -328,6 +372,8 @@ func (e *LineFilterExpr) Stage() (log.Stage, error) {
 type LabelParserExpr struct {
 	Op    string
 	Param string
+	// JSONLimits are the limits of the json parser, given as flags.
+	JSONLimits log.JSONParserLimits
 	implicit
 }
 
@@ -338,6 +384,30 @@ func newLabelParserExpr(op, param string) *LabelParserExpr {
 	}
 }
 
+func newJSONParserExpr(limits log.JSONParserLimits) *LabelParserExpr {
+	return &LabelParserExpr{
+		Op:         OpParserTypeJSON,
+		JSONLimits: limits,
+	}
+}
+
+// withJSONParserFlag sets the limit of the json parser given by the flag.
+func withJSONParserFlag(limits log.JSONParserLimits, flag, value string) log.JSONParserLimits {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid value for parser flag %s: %s, expecting a positive integer", flag, value), 0, 0))
+	}
+	switch flag {
+	case OpFlagMaxDepth:
+		limits.MaxDepth = n
+	case OpFlagMaxKeys:
+		limits.MaxKeys = n
+	default:
+		panic(logqlmodel.NewParseError(fmt.Sprintf("parser flag %s not supported for json parser", flag), 0, 0))
+	}
+	return limits
+}
+
 func (e *LabelParserExpr) Shardable() bool { return true }
 
 func (e *LabelParserExpr) Walk(f WalkFn) { f(e) }
@@ -345,7 +415,7 @@ func (e *LabelParserExpr) Walk(f WalkFn) { f(e) }
 func (e *LabelParserExpr) Stage() (log.Stage, error) {
 	switch e.Op {
 	case OpParserTypeJSON:
-		return log.NewJSONParser(), nil
+		return log.NewJSONParserWithLimits(e.JSONLimits), nil
 	case OpParserTypeLogfmt:
 		return log.NewLogfmtParser(), nil
 	case OpParserTypeRegexp:
@@ -354,6 +424,8 @@ func (e *LabelParserExpr) Stage() (log.Stage, error) {
 		return log.NewUnpackParser(), nil
 	case OpParserTypePattern:
 		return log.NewPatternParser(e.Param)
+	case OpParserTypeMetadata:
+		return log.NewMetadataParser(), nil
 	default:
 		return nil, fmt.Errorf("unknown parser operator: %s", e.Op)
 	}
@@ -368,6 +440,12 @@ func (e *LabelParserExpr) String() string {
 		sb.WriteString(" ")
 		sb.WriteString(strconv.Quote(e.Param))
 	}
+	if e.JSONLimits.MaxDepth > 0 {
+		sb.WriteString(fmt.Sprintf(" %s=%d", OpFlagMaxDepth, e.JSONLimits.MaxDepth))
+	}
+	if e.JSONLimits.MaxKeys > 0 {
+		sb.WriteString(fmt.Sprintf(" %s=%d", OpFlagMaxKeys, e.JSONLimits.MaxKeys))
+	}
 	return sb.String()
 }
 
@@ -459,6 +537,54 @@ func (e *LabelFmtExpr) String() string {
 	return sb.String()
 }
 
+type DropLabelsExpr struct {
+	Names []string
+
+	implicit
+}
+
+func newDropLabelsExpr(names []string) *DropLabelsExpr {
+	return &DropLabelsExpr{
+		Names: names,
+	}
+}
+
+func (e *DropLabelsExpr) Shardable() bool { return false }
+
+func (e *DropLabelsExpr) Walk(f WalkFn) { f(e) }
+
+func (e *DropLabelsExpr) Stage() (log.Stage, error) {
+	return log.NewDropLabels(e.Names), nil
+}
+
+func (e *DropLabelsExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpDrop, strings.Join(e.Names, ","))
+}
+
+type KeepLabelsExpr struct {
+	Names []string
+
+	implicit
+}
+
+func newKeepLabelsExpr(names []string) *KeepLabelsExpr {
+	return &KeepLabelsExpr{
+		Names: names,
+	}
+}
+
+func (e *KeepLabelsExpr) Shardable() bool { return false }
+
+func (e *KeepLabelsExpr) Walk(f WalkFn) { f(e) }
+
+func (e *KeepLabelsExpr) Stage() (log.Stage, error) {
+	return log.NewKeepLabels(e.Names), nil
+}
+
+func (e *KeepLabelsExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpKeep, strings.Join(e.Names, ","))
+}
+
 type JSONExpressionParser struct {
 	Expressions []log.JSONExpression
 
@@ -614,6 +740,11 @@ const (
 	OpTypeStdvar  = "stdvar"
 	OpTypeBottomK = "bottomk"
 	OpTypeTopK    = "topk"
+	// approx_topk is topk which, when sharded, merges count-min sketches of the shards in place of all their series.
+	OpTypeApproxTopK = "approx_topk"
+	// sort and sort_desc order the series of instant queries by value.
+	OpTypeSort     = "sort"
+	OpTypeSortDesc = "sort_desc"
 
 	// range vector ops
 	OpRangeTypeCount       = "count_over_time"
@@ -657,15 +788,23 @@ const (
 	OpTypeLTE   = "<="
 
 	// parsers
-	OpParserTypeJSON    = "json"
-	OpParserTypeLogfmt  = "logfmt"
-	OpParserTypeRegexp  = "regexp"
-	OpParserTypeUnpack  = "unpack"
-	OpParserTypePattern = "pattern"
+	OpParserTypeJSON     = "json"
+	OpParserTypeLogfmt   = "logfmt"
+	OpParserTypeRegexp   = "regexp"
+	OpParserTypeUnpack   = "unpack"
+	OpParserTypePattern  = "pattern"
+	OpParserTypeMetadata = "metadata"
+
+	// parser flags
+	OpFlagMaxDepth = "--max-depth"
+	OpFlagMaxKeys  = "--max-keys"
 
 	OpFmtLine  = "line_format"
 	OpFmtLabel = "label_format"
 
+	OpDrop = "drop"
+	OpKeep = "keep"
+
 	OpPipe   = "|"
 	OpUnwrap = "unwrap"
 	OpOffset = "offset"
@@ -685,6 +824,9 @@ const (
 
 	// function filters
 	OpFilterIP = "ip"
+
+	// pattern filters, written `|> "<pattern>"` or `!> "<pattern>"`
+	OpFilterPattern = "pattern"
 )
 
 func IsComparisonOperator(op string) bool {
@@ -868,7 +1010,7 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 	var p int
 	var err error
 	switch operation {
-	case OpTypeBottomK, OpTypeTopK:
+	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK:
 		if params == nil {
 			panic(logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0))
 		}
@@ -879,6 +1021,17 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 		if p <= 0 {
 			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid parameter (must be greater than 0) %s(%s", operation, *params), 0, 0))
 		}
+		if operation == OpTypeApproxTopK && gr != nil {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("grouping not allowed for %s aggregation", operation), 0, 0))
+		}
+
+	case OpTypeSort, OpTypeSortDesc:
+		if params != nil {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("unsupported parameter for operation %s(%s,", operation, *params), 0, 0))
+		}
+		if gr != nil {
+			panic(logqlmodel.NewParseError(fmt.Sprintf("grouping not allowed for %s aggregation", operation), 0, 0))
+		}
 
 	default:
 		if params != nil {
@@ -933,7 +1086,7 @@ func (e *VectorAggregationExpr) String() string {
 	var params []string
 	switch e.Operation {
 	// bottomK and topk can have first parameter as 0
-	case OpTypeBottomK, OpTypeTopK:
+	case OpTypeBottomK, OpTypeTopK, OpTypeApproxTopK:
 		params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
 	default:
 		if e.Params != 0 {
@@ -1038,17 +1191,12 @@ func (e *BinOpExpr) String() string {
 			op = fmt.Sprintf("%s bool", op)
 		}
 		if e.Opts.VectorMatching != nil {
-			group := ""
-			if e.Opts.VectorMatching.Card == CardManyToOne {
-				group = OpGroupLeft
-			} else if e.Opts.VectorMatching.Card == CardOneToMany {
-				group = OpGroupRight
-			}
+			group := e.Opts.VectorMatching.Card.group()
 			if e.Opts.VectorMatching.Include != nil {
 				group = fmt.Sprintf("%s (%s)", group, strings.Join(e.Opts.VectorMatching.Include, ","))
 			}
 
-			if e.Opts.VectorMatching.On || e.Opts.VectorMatching.MatchingLabels != nil {
+			if e.Opts.VectorMatching.On || e.Opts.VectorMatching.MatchingLabels != nil || group != "" {
 				on := OpOn
 				if !e.Opts.VectorMatching.On {
 					on = OpIgnoring
@@ -1095,6 +1243,10 @@ func mustNewBinOpExpr(op string, opts *BinOpOptions, lhs, rhs Expr) SampleExpr {
 	leftLit, lOk := left.(*LiteralExpr)
 	rightLit, rOk := right.(*LiteralExpr)
 
+	if opts != nil && opts.VectorMatching != nil {
+		validateVectorMatching(op, opts.VectorMatching, lOk || rOk)
+	}
+
 	if IsLogicalBinOp(op) {
 		if lOk {
 			panic(logqlmodel.NewParseError(fmt.Sprintf(
@@ -1126,6 +1278,37 @@ func mustNewBinOpExpr(op string, opts *BinOpOptions, lhs, rhs Expr) SampleExpr {
 	}
 }
 
+// validateVectorMatching panics with a parse error if the vector matching of a binary operation is invalid,
+// hasLiteral being whether a leg of the operation is a literal.
+func validateVectorMatching(op string, vm *VectorMatching, hasLiteral bool) {
+	if vm.Card != CardOneToOne && IsLogicalBinOp(op) {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("no grouping allowed for %s operation", op), 0, 0))
+	}
+	if hasLiteral && (vm.On || vm.MatchingLabels != nil || vm.Card != CardOneToOne) {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("vector matching only allowed between vectors in %s operation", op), 0, 0))
+	}
+	if vm.On {
+		for _, include := range vm.Include {
+			for _, matching := range vm.MatchingLabels {
+				if include == matching {
+					panic(logqlmodel.NewParseError(fmt.Sprintf("label %s must not occur in %s and %s clause at once", include, OpOn, vm.Card.group()), 0, 0))
+				}
+			}
+		}
+	}
+}
+
+// group returns the modifier of the cardinality in binary operations.
+func (vmc VectorMatchCardinality) group() string {
+	switch vmc {
+	case CardManyToOne:
+		return OpGroupLeft
+	case CardOneToMany:
+		return OpGroupRight
+	}
+	return ""
+}
+
 // Reduces a binary operation expression. A binop is reducible if both of its legs are literal expressions.
 // This is because literals need match all labels, which is currently difficult to encode into StepEvaluators.
 // Therefore, we ensure a binop can be reduced/simplified, maintaining the invariant that it does not have two literal legs.
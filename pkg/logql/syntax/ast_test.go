@@ -28,6 +28,8 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar", bar!="baz"} |= ""`, false},
 		{`{foo="bar", bar!="baz"} |= "" |= ip("::1")`, true},
 		{`{foo="bar", bar!="baz"} |= "" != ip("127.0.0.1")`, true},
+		{`{foo="bar", bar!="baz"} |> "<_> error <_>"`, true},
+		{`{foo="bar", bar!="baz"} |= "baz" !> "<_> level=debug <_>"`, true},
 		{`{foo="bar", bar!="baz"} |~ ""`, false},
 		{`{foo="bar", bar!="baz"} |~ ".*"`, false},
 		{`{foo="bar", bar!="baz"} |= "" |= ""`, false},
@@ -42,6 +44,9 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | logfmt | b=ip("127.0.0.1") | level="error" | c=ip("::1")`, true}, // chain inside label filters.
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)"`, true},
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)" | ( ( foo<5.01 , bar>20ms ) or foo="bar" ) | line_format "blip{{.boop}}bap" | label_format foo=bar,bar="blip{{.blop}}"`, true},
+		{`{foo="bar"} | logfmt | drop foo,__error__`, true},
+		{`{foo="bar"} | json --max-depth=2 --max-keys=100`, true},
+		{`{foo="bar"} | logfmt | keep foo,bar | drop bar`, true},
 	}
 
 	for _, tt := range tests {
@@ -83,10 +88,13 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum(count_over_time({job="mysql"} | logfmt [5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | pattern "<foo> bar <buzz>" | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | unpack | json [5m]))`,
+		`sum(count_over_time({job="mysql"} | metadata | trace_id="abc" [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m] offset 10y))`,
 		`topk(10,sum(rate({region="us-east1"}[5m])) by (name))`,
 		`topk by (name)(10,sum(rate({region="us-east1"}[5m])))`,
+		`sort(sum by (name) (rate({region="us-east1"}[5m])))`,
+		`sort_desc(sum by (name) (rate({region="us-east1"}[5m])))`,
 		`avg( rate( ( {job="nginx"} |= "GET" ) [10s] ) ) by (region)`,
 		`avg(min_over_time({job="nginx"} |= "GET" | unwrap foo[10s])) by (region)`,
 		`avg(min_over_time({job="nginx"} |= "GET" | unwrap foo[10s] offset 10m)) by (region)`,
@@ -156,6 +164,9 @@ func Test_SampleExpr_String(t *testing.T) {
 		`(count_over_time({job="postgres"}[5m])/2) or vector(2)`,
 		`10 / (count_over_time({job="postgres"}[5m])/2)`,
 		`{app="foo"} | json response_status="response.status.code", first_param="request.params[0]"`,
+		`sum by (pod) (rate({app="foo"}[5m])) * on (pod) group_left (team) sum by (pod, team) (count_over_time({app="info"}[5m]))`,
+		`sum by (pod) (rate({app="foo"}[5m])) * ignoring () group_left (team) sum by (pod, team) (count_over_time({app="info"}[5m]))`,
+		`sum by (pod, team) (count_over_time({app="info"}[5m])) > bool ignoring (team) group_right sum by (pod) (rate({app="foo"}[5m]))`,
 		`label_replace(
 			sum by (job) (
 				sum_over_time(
@@ -182,6 +193,32 @@ func Test_SampleExpr_String(t *testing.T) {
 	}
 }
 
+func Test_BinOpExpr_VectorMatching_Fail(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		query string
+		err   string
+	}{
+		{
+			`sum by (pod) (rate({app="foo"}[5m])) and on (pod) group_left sum by (pod) (rate({app="bar"}[5m]))`,
+			"no grouping allowed for and operation",
+		},
+		{
+			`sum by (pod) (rate({app="foo"}[5m])) * on (pod) 2`,
+			"vector matching only allowed between vectors in * operation",
+		},
+		{
+			`sum by (pod) (rate({app="foo"}[5m])) * on (pod) group_left (pod) sum by (pod) (rate({app="bar"}[5m]))`,
+			"label pod must not occur in on and group_left clause at once",
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			_, err := ParseExpr(tc.query)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
 func Test_SampleExpr_String_Fail(t *testing.T) {
 	t.Parallel()
 	for _, tc := range []string{
@@ -262,6 +299,30 @@ func Test_NilFilterDoesntPanic(t *testing.T) {
 	}
 }
 
+func Test_RequiredLineFilters(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{`{app="foo"}`, nil},
+		{`{app="foo"} |= "bar"`, []string{"bar"}},
+		{`{app="foo"} |= "bar" |= "buzz" != "fizz" |~ "b.*"`, []string{"buzz", "bar"}},
+		{`{app="foo"} |= ""`, nil},
+		{`{app="foo"} |= ip("127.0.0.1")`, nil},
+		{`{app="foo"} |> "<_> bar <_>"`, nil},
+		{`{app="foo"} |= "bar" | json | level="error" |= "buzz"`, []string{"bar", "buzz"}},
+		{`{app="foo"} |= "bar" | line_format "{{.msg}}" |= "buzz"`, []string{"bar"}},
+		{`{app="foo"} | unpack |= "buzz"`, nil},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := ParseLogSelector(tc.query, true)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, RequiredLineFilters(expr))
+		})
+	}
+}
+
 type linecheck struct {
 	l string
 	e bool
@@ -328,6 +389,20 @@ func Test_FilterMatcher(t *testing.T) {
 			},
 			[]linecheck{{"foo", true}, {"bar", false}, {"foobar", true}},
 		},
+		{
+			`{app="foo"} |> "<_> error <_>"`,
+			[]*labels.Matcher{
+				mustNewMatcher(labels.MatchEqual, "app", "foo"),
+			},
+			[]linecheck{{"an error occurred", true}, {"error occurred", false}, {"no errors", false}},
+		},
+		{
+			`{app="foo"} |= "foo" !> "foo <_>"`,
+			[]*labels.Matcher{
+				mustNewMatcher(labels.MatchEqual, "app", "foo"),
+			},
+			[]linecheck{{"foo bar", false}, {"foo", true}, {"a foo bar", true}, {"bar", false}},
+		},
 		{
 			`{app="foo"} | logfmt | duration > 1s and total_bytes < 1GB`,
 			[]*labels.Matcher{
@@ -51,11 +51,14 @@ import (
   IPLabelFilter           log.LabelFilterer
   LineFormatExpr          *LineFmtExpr
   LabelFormatExpr         *LabelFmtExpr
+  DropLabelsExpr          *DropLabelsExpr
+  KeepLabelsExpr          *KeepLabelsExpr
   LabelFormat             log.LabelFmt
   LabelsFormat            []log.LabelFmt
   JSONExpressionParser    *JSONExpressionParser
   JSONExpression          log.JSONExpression
   JSONExpressionList      []log.JSONExpression
+  JSONParserLimits        log.JSONParserLimits
   UnwrapExpr              *UnwrapExpr
   OffsetExpr              *OffsetExpr
 }
@@ -97,24 +100,28 @@ import (
 %type <LineFilter>            lineFilter
 %type <LineFormatExpr>        lineFormatExpr
 %type <LabelFormatExpr>       labelFormatExpr
+%type <DropLabelsExpr>        dropLabelsExpr
+%type <KeepLabelsExpr>        keepLabelsExpr
 %type <LabelFormat>           labelFormat
 %type <LabelsFormat>          labelsFormat
 %type <JSONExpressionParser>  jsonExpressionParser
 %type <JSONExpression>        jsonExpression
 %type <JSONExpressionList>    jsonExpressionList
+%type <JSONParserLimits>      jsonParserFlags
 %type <UnwrapExpr>            unwrapExpr
 %type <UnitFilter>            unitFilter
 %type <IPLabelFilter>         ipLabelFilter
 %type <OffsetExpr>            offsetExpr
 
 %token <bytes> BYTES
-%token <str>      IDENTIFIER STRING NUMBER
+%token <str>      IDENTIFIER STRING NUMBER PARSER_FLAG
 %token <duration> DURATION RANGE
 %token <val>      MATCHERS LABELS EQ RE NRE OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT
                   OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM AVG MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK
                   BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
                   MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
-                  FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
+                  FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT APPROX_TOPK METADATA
+                  PIPE_PATTERN NPA DROP KEEP SORT SORT_DESC
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -250,6 +257,8 @@ pipelineStage:
   | PIPE labelFilter             { $$ = &LabelFilterExpr{LabelFilterer: $2 }}
   | PIPE lineFormatExpr          { $$ = $2 }
   | PIPE labelFormatExpr         { $$ = $2 }
+  | PIPE dropLabelsExpr          { $$ = $2 }
+  | PIPE keepLabelsExpr          { $$ = $2 }
   ;
 
 filterOp:
@@ -259,6 +268,8 @@ filterOp:
 lineFilter:
     filter STRING                                                   { $$ = newLineFilterExpr($1, "", $2) }
   | filter filterOp OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS       { $$ = newLineFilterExpr($1, $2, $4) }
+  | PIPE_PATTERN STRING                                             { $$ = newLineFilterExpr(labels.MatchEqual, OpFilterPattern, $2) }
+  | NPA STRING                                                      { $$ = newLineFilterExpr(labels.MatchNotEqual, OpFilterPattern, $2) }
   ;
 
 lineFilters:
@@ -268,15 +279,22 @@ lineFilters:
 
 labelParser:
     JSON           { $$ = newLabelParserExpr(OpParserTypeJSON, "") }
+  | JSON jsonParserFlags { $$ = newJSONParserExpr($2) }
   | LOGFMT         { $$ = newLabelParserExpr(OpParserTypeLogfmt, "") }
   | REGEXP STRING  { $$ = newLabelParserExpr(OpParserTypeRegexp, $2) }
   | UNPACK         { $$ = newLabelParserExpr(OpParserTypeUnpack, "") }
   | PATTERN STRING { $$ = newLabelParserExpr(OpParserTypePattern, $2) }
+  | METADATA       { $$ = newLabelParserExpr(OpParserTypeMetadata, "") }
   ;
 
 jsonExpressionParser:
     JSON jsonExpressionList { $$ = newJSONExpressionParser($2) }
 
+jsonParserFlags:
+    PARSER_FLAG EQ NUMBER                 { $$ = withJSONParserFlag(log.JSONParserLimits{}, $1, $3) }
+  | jsonParserFlags PARSER_FLAG EQ NUMBER { $$ = withJSONParserFlag($1, $2, $4) }
+  ;
+
 lineFormatExpr: LINE_FMT STRING { $$ = newLineFmtExpr($2) };
 
 labelFormat:
@@ -292,6 +310,10 @@ labelsFormat:
 
 labelFormatExpr: LABEL_FMT labelsFormat { $$ = newLabelFmtExpr($2) };
 
+dropLabelsExpr: DROP labels { $$ = newDropLabelsExpr($2) };
+
+keepLabelsExpr: KEEP labels { $$ = newKeepLabelsExpr($2) };
+
 labelFilter:
       matcher                                        { $$ = log.NewStringLabelFilter($1) }
     | ipLabelFilter                                       { $$ = $1 }
@@ -464,6 +486,9 @@ vectorOp:
       | STDVAR  { $$ = OpTypeStdvar }
       | BOTTOMK { $$ = OpTypeBottomK }
       | TOPK    { $$ = OpTypeTopK }
+      | APPROX_TOPK { $$ = OpTypeApproxTopK }
+      | SORT        { $$ = OpTypeSort }
+      | SORT_DESC   { $$ = OpTypeSortDesc }
       ;
 
 rangeOp:
@@ -57,11 +57,14 @@ type exprSymType struct {
 	IPLabelFilter         log.LabelFilterer
 	LineFormatExpr        *LineFmtExpr
 	LabelFormatExpr       *LabelFmtExpr
+	DropLabelsExpr        *DropLabelsExpr
+	KeepLabelsExpr        *KeepLabelsExpr
 	LabelFormat           log.LabelFmt
 	LabelsFormat          []log.LabelFmt
 	JSONExpressionParser  *JSONExpressionParser
 	JSONExpression        log.JSONExpression
 	JSONExpressionList    []log.JSONExpression
+	JSONParserLimits      log.JSONParserLimits
 	UnwrapExpr            *UnwrapExpr
 	OffsetExpr            *OffsetExpr
 }
@@ -70,85 +73,94 @@ const BYTES = 57346
 const IDENTIFIER = 57347
 const STRING = 57348
 const NUMBER = 57349
-const DURATION = 57350
-const RANGE = 57351
-const MATCHERS = 57352
-const LABELS = 57353
-const EQ = 57354
-const RE = 57355
-const NRE = 57356
-const OPEN_BRACE = 57357
-const CLOSE_BRACE = 57358
-const OPEN_BRACKET = 57359
-const CLOSE_BRACKET = 57360
-const COMMA = 57361
-const DOT = 57362
-const PIPE_MATCH = 57363
-const PIPE_EXACT = 57364
-const OPEN_PARENTHESIS = 57365
-const CLOSE_PARENTHESIS = 57366
-const BY = 57367
-const WITHOUT = 57368
-const COUNT_OVER_TIME = 57369
-const RATE = 57370
-const RATE_COUNTER = 57371
-const SUM = 57372
-const AVG = 57373
-const MAX = 57374
-const MIN = 57375
-const COUNT = 57376
-const STDDEV = 57377
-const STDVAR = 57378
-const BOTTOMK = 57379
-const TOPK = 57380
-const BYTES_OVER_TIME = 57381
-const BYTES_RATE = 57382
-const BOOL = 57383
-const JSON = 57384
-const REGEXP = 57385
-const LOGFMT = 57386
-const PIPE = 57387
-const LINE_FMT = 57388
-const LABEL_FMT = 57389
-const UNWRAP = 57390
-const AVG_OVER_TIME = 57391
-const SUM_OVER_TIME = 57392
-const MIN_OVER_TIME = 57393
-const MAX_OVER_TIME = 57394
-const STDVAR_OVER_TIME = 57395
-const STDDEV_OVER_TIME = 57396
-const QUANTILE_OVER_TIME = 57397
-const BYTES_CONV = 57398
-const DURATION_CONV = 57399
-const DURATION_SECONDS_CONV = 57400
-const FIRST_OVER_TIME = 57401
-const LAST_OVER_TIME = 57402
-const ABSENT_OVER_TIME = 57403
-const VECTOR = 57404
-const LABEL_REPLACE = 57405
-const UNPACK = 57406
-const OFFSET = 57407
-const PATTERN = 57408
-const IP = 57409
-const ON = 57410
-const IGNORING = 57411
-const GROUP_LEFT = 57412
-const GROUP_RIGHT = 57413
-const OR = 57414
-const AND = 57415
-const UNLESS = 57416
-const CMP_EQ = 57417
-const NEQ = 57418
-const LT = 57419
-const LTE = 57420
-const GT = 57421
-const GTE = 57422
-const ADD = 57423
-const SUB = 57424
-const MUL = 57425
-const DIV = 57426
-const MOD = 57427
-const POW = 57428
+const PARSER_FLAG = 57350
+const DURATION = 57351
+const RANGE = 57352
+const MATCHERS = 57353
+const LABELS = 57354
+const EQ = 57355
+const RE = 57356
+const NRE = 57357
+const OPEN_BRACE = 57358
+const CLOSE_BRACE = 57359
+const OPEN_BRACKET = 57360
+const CLOSE_BRACKET = 57361
+const COMMA = 57362
+const DOT = 57363
+const PIPE_MATCH = 57364
+const PIPE_EXACT = 57365
+const OPEN_PARENTHESIS = 57366
+const CLOSE_PARENTHESIS = 57367
+const BY = 57368
+const WITHOUT = 57369
+const COUNT_OVER_TIME = 57370
+const RATE = 57371
+const RATE_COUNTER = 57372
+const SUM = 57373
+const AVG = 57374
+const MAX = 57375
+const MIN = 57376
+const COUNT = 57377
+const STDDEV = 57378
+const STDVAR = 57379
+const BOTTOMK = 57380
+const TOPK = 57381
+const BYTES_OVER_TIME = 57382
+const BYTES_RATE = 57383
+const BOOL = 57384
+const JSON = 57385
+const REGEXP = 57386
+const LOGFMT = 57387
+const PIPE = 57388
+const LINE_FMT = 57389
+const LABEL_FMT = 57390
+const UNWRAP = 57391
+const AVG_OVER_TIME = 57392
+const SUM_OVER_TIME = 57393
+const MIN_OVER_TIME = 57394
+const MAX_OVER_TIME = 57395
+const STDVAR_OVER_TIME = 57396
+const STDDEV_OVER_TIME = 57397
+const QUANTILE_OVER_TIME = 57398
+const BYTES_CONV = 57399
+const DURATION_CONV = 57400
+const DURATION_SECONDS_CONV = 57401
+const FIRST_OVER_TIME = 57402
+const LAST_OVER_TIME = 57403
+const ABSENT_OVER_TIME = 57404
+const VECTOR = 57405
+const LABEL_REPLACE = 57406
+const UNPACK = 57407
+const OFFSET = 57408
+const PATTERN = 57409
+const IP = 57410
+const ON = 57411
+const IGNORING = 57412
+const GROUP_LEFT = 57413
+const GROUP_RIGHT = 57414
+const APPROX_TOPK = 57415
+const METADATA = 57416
+const PIPE_PATTERN = 57417
+const NPA = 57418
+const DROP = 57419
+const KEEP = 57420
+const SORT = 57421
+const SORT_DESC = 57422
+const OR = 57423
+const AND = 57424
+const UNLESS = 57425
+const CMP_EQ = 57426
+const NEQ = 57427
+const LT = 57428
+const LTE = 57429
+const GT = 57430
+const GTE = 57431
+const ADD = 57432
+const SUB = 57433
+const MUL = 57434
+const DIV = 57435
+const MOD = 57436
+const POW = 57437
 
 var exprToknames = [...]string{
 	"$end",
@@ -158,6 +170,7 @@ var exprToknames = [...]string{
 	"IDENTIFIER",
 	"STRING",
 	"NUMBER",
+	"PARSER_FLAG",
 	"DURATION",
 	"RANGE",
 	"MATCHERS",
@@ -222,6 +235,14 @@ var exprToknames = [...]string{
 	"IGNORING",
 	"GROUP_LEFT",
 	"GROUP_RIGHT",
+	"APPROX_TOPK",
+	"METADATA",
+	"PIPE_PATTERN",
+	"NPA",
+	"DROP",
+	"KEEP",
+	"SORT",
+	"SORT_DESC",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -245,7 +266,7 @@ const exprEofCode = 1
 const exprErrCode = 2
 const exprInitialStackSize = 16
 
-//line pkg/logql/syntax/expr.y:501
+//line pkg/logql/syntax/expr.y:526
 
 //line yacctab:1
 var exprExca = [...]int8{
@@ -256,110 +277,119 @@ var exprExca = [...]int8{
 
 const exprPrivate = 57344
 
-const exprLast = 543
+const exprLast = 614
 
 var exprAct = [...]int16{
-	255, 201, 80, 4, 182, 62, 170, 5, 175, 210,
-	71, 117, 54, 61, 258, 140, 73, 2, 49, 50,
-	51, 52, 53, 54, 65, 76, 46, 47, 48, 55,
-	56, 59, 60, 57, 58, 49, 50, 51, 52, 53,
-	54, 47, 48, 55, 56, 59, 60, 57, 58, 49,
-	50, 51, 52, 53, 54, 55, 56, 59, 60, 57,
-	58, 49, 50, 51, 52, 53, 54, 105, 184, 138,
-	139, 109, 51, 52, 53, 54, 124, 69, 136, 138,
-	139, 154, 155, 144, 67, 68, 142, 261, 106, 149,
-	172, 263, 69, 261, 121, 226, 152, 153, 69, 67,
-	68, 90, 309, 260, 151, 67, 68, 127, 156, 157,
-	158, 159, 160, 161, 162, 163, 164, 165, 166, 167,
-	168, 169, 327, 203, 302, 200, 212, 327, 179, 203,
-	69, 190, 185, 188, 189, 186, 187, 67, 68, 70,
-	264, 69, 137, 173, 171, 282, 192, 258, 67, 68,
-	208, 204, 200, 347, 70, 342, 202, 69, 213, 205,
-	70, 203, 69, 335, 67, 68, 81, 82, 129, 67,
-	68, 124, 203, 124, 69, 304, 305, 306, 221, 222,
-	223, 67, 68, 334, 324, 172, 301, 172, 203, 121,
-	259, 121, 70, 203, 332, 235, 311, 194, 236, 234,
-	292, 253, 256, 70, 262, 64, 265, 142, 105, 268,
-	109, 269, 272, 258, 257, 254, 301, 318, 266, 70,
-	259, 270, 260, 216, 70, 206, 260, 276, 278, 281,
-	283, 330, 286, 284, 212, 308, 70, 131, 173, 171,
-	231, 171, 193, 232, 230, 79, 124, 81, 82, 272,
-	272, 197, 260, 280, 317, 316, 260, 294, 233, 296,
-	298, 272, 300, 105, 121, 272, 315, 299, 310, 295,
-	274, 130, 105, 293, 124, 312, 124, 272, 197, 212,
-	212, 345, 273, 112, 114, 113, 124, 122, 123, 263,
-	172, 212, 121, 291, 121, 197, 321, 322, 279, 277,
-	267, 105, 323, 229, 121, 115, 212, 116, 325, 326,
-	214, 112, 114, 113, 331, 122, 123, 198, 141, 290,
-	13, 220, 219, 16, 135, 211, 13, 337, 143, 338,
-	339, 13, 218, 115, 143, 116, 217, 191, 148, 6,
-	147, 343, 146, 21, 22, 23, 36, 37, 39, 40,
-	38, 41, 42, 43, 44, 24, 25, 86, 85, 78,
-	341, 314, 271, 227, 224, 26, 27, 28, 29, 30,
-	31, 32, 133, 215, 207, 33, 34, 35, 45, 19,
-	209, 199, 297, 228, 225, 340, 132, 250, 13, 134,
-	251, 249, 336, 329, 328, 307, 6, 17, 18, 150,
-	21, 22, 23, 36, 37, 39, 40, 38, 41, 42,
-	43, 44, 24, 25, 247, 84, 244, 248, 246, 245,
-	243, 83, 26, 27, 28, 29, 30, 31, 32, 288,
-	289, 346, 33, 34, 35, 45, 19, 145, 241, 344,
-	238, 242, 240, 239, 237, 13, 87, 333, 320, 3,
-	319, 285, 275, 6, 17, 18, 72, 21, 22, 23,
-	36, 37, 39, 40, 38, 41, 42, 43, 44, 24,
-	25, 287, 252, 196, 183, 118, 195, 194, 193, 26,
-	27, 28, 29, 30, 31, 32, 180, 178, 177, 33,
-	34, 35, 45, 19, 91, 92, 93, 94, 95, 96,
-	97, 98, 99, 100, 101, 102, 103, 104, 313, 176,
-	75, 17, 18, 77, 77, 183, 119, 174, 108, 181,
-	111, 110, 63, 125, 120, 126, 107, 89, 88, 11,
-	10, 9, 128, 20, 12, 15, 8, 303, 14, 7,
-	74, 66, 1,
+	274, 218, 85, 4, 196, 65, 182, 189, 125, 198,
+	76, 78, 2, 64, 57, 5, 152, 54, 55, 56,
+	57, 137, 81, 49, 50, 51, 58, 59, 62, 63,
+	60, 61, 52, 53, 54, 55, 56, 57, 50, 51,
+	58, 59, 62, 63, 60, 61, 52, 53, 54, 55,
+	56, 57, 58, 59, 62, 63, 60, 61, 52, 53,
+	54, 55, 56, 57, 52, 53, 54, 55, 56, 57,
+	110, 348, 74, 68, 114, 201, 150, 151, 277, 72,
+	73, 166, 167, 139, 148, 150, 151, 134, 156, 164,
+	165, 277, 282, 74, 161, 322, 279, 348, 95, 154,
+	72, 73, 184, 220, 163, 278, 129, 345, 168, 169,
+	170, 171, 172, 173, 174, 175, 176, 177, 178, 179,
+	180, 181, 280, 277, 220, 86, 87, 74, 368, 322,
+	278, 279, 70, 71, 72, 73, 193, 330, 214, 363,
+	111, 279, 75, 200, 351, 329, 207, 202, 205, 206,
+	203, 204, 74, 70, 71, 209, 149, 356, 220, 72,
+	73, 314, 225, 75, 183, 279, 279, 355, 219, 227,
+	229, 221, 222, 280, 84, 312, 86, 87, 74, 247,
+	134, 353, 332, 67, 338, 72, 73, 70, 71, 313,
+	237, 238, 239, 217, 74, 184, 289, 75, 74, 129,
+	244, 72, 73, 232, 199, 72, 73, 214, 283, 220,
+	247, 247, 70, 71, 199, 337, 336, 199, 272, 275,
+	199, 281, 75, 284, 300, 110, 287, 114, 288, 220,
+	286, 276, 154, 273, 298, 285, 247, 297, 70, 71,
+	295, 335, 199, 294, 296, 299, 301, 92, 75, 303,
+	306, 199, 214, 223, 70, 71, 185, 183, 70, 71,
+	323, 217, 230, 143, 75, 134, 74, 254, 75, 211,
+	255, 228, 253, 72, 73, 215, 315, 134, 317, 319,
+	184, 321, 110, 142, 129, 311, 320, 331, 316, 236,
+	250, 110, 210, 251, 333, 249, 129, 220, 96, 97,
+	98, 99, 100, 101, 102, 103, 104, 105, 106, 107,
+	108, 109, 325, 326, 327, 247, 247, 342, 343, 134,
+	292, 291, 110, 344, 13, 235, 70, 71, 153, 346,
+	347, 252, 155, 234, 184, 352, 75, 13, 129, 233,
+	208, 185, 183, 16, 160, 155, 159, 158, 358, 91,
+	359, 360, 13, 90, 248, 83, 302, 366, 362, 334,
+	6, 290, 364, 145, 21, 22, 23, 36, 37, 39,
+	40, 38, 41, 42, 43, 44, 24, 25, 144, 247,
+	245, 146, 241, 231, 224, 216, 26, 27, 28, 29,
+	30, 31, 32, 147, 246, 243, 33, 34, 35, 48,
+	19, 242, 269, 361, 350, 270, 349, 268, 226, 45,
+	328, 318, 240, 339, 304, 46, 47, 13, 266, 263,
+	162, 267, 264, 265, 262, 6, 17, 18, 89, 21,
+	22, 23, 36, 37, 39, 40, 38, 41, 42, 43,
+	44, 24, 25, 260, 257, 88, 261, 258, 259, 256,
+	367, 26, 27, 28, 29, 30, 31, 32, 308, 309,
+	3, 33, 34, 35, 48, 19, 190, 77, 365, 188,
+	354, 341, 357, 157, 45, 340, 307, 305, 293, 197,
+	46, 47, 13, 271, 213, 212, 211, 210, 194, 192,
+	6, 17, 18, 134, 21, 22, 23, 36, 37, 39,
+	40, 38, 41, 42, 43, 44, 24, 25, 191, 141,
+	140, 80, 129, 310, 82, 190, 26, 27, 28, 29,
+	30, 31, 32, 82, 199, 197, 33, 34, 35, 48,
+	19, 119, 121, 120, 126, 130, 131, 282, 127, 45,
+	134, 186, 187, 113, 195, 46, 47, 118, 117, 116,
+	115, 66, 135, 122, 128, 123, 17, 18, 136, 129,
+	112, 94, 124, 93, 11, 132, 133, 10, 9, 138,
+	20, 12, 15, 8, 324, 14, 7, 79, 119, 121,
+	120, 69, 130, 131, 1, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	122, 0, 123, 0, 0, 0, 0, 0, 0, 124,
+	0, 0, 132, 133,
 }
 
 var exprPact = [...]int16{
-	316, -1000, -46, -1000, -1000, 160, 316, -1000, -1000, -1000,
-	-1000, -1000, -1000, 508, 336, 222, -1000, 414, 408, 335,
-	334, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	336, -1000, -58, -1000, -1000, 137, 336, -1000, -1000, -1000,
+	-1000, -1000, -1000, 509, 331, 150, -1000, 438, 421, 329,
+	325, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 60, 60, 60, 60,
-	60, 60, 60, 60, 60, 60, 60, 60, 60, 60,
-	60, 160, -1000, 63, 269, -1000, 101, -1000, -1000, -1000,
-	-1000, 247, 213, -46, 370, 308, -1000, 66, 311, 430,
-	319, 317, 315, -1000, -1000, 316, 392, 316, 28, 11,
-	-1000, 316, 316, 316, 316, 316, 316, 316, 316, 316,
-	316, 316, 316, 316, 316, -1000, -1000, -1000, -1000, 166,
-	-1000, -1000, 504, -1000, 482, -1000, 481, -1000, -1000, -1000,
-	-1000, 281, 480, 510, 56, -1000, -1000, -1000, 314, -1000,
-	-1000, -1000, -1000, -1000, 509, -1000, 472, 471, 470, 467,
-	293, 362, 143, 305, 201, 355, 373, 301, 286, 354,
-	199, -32, 313, 309, 299, 298, -20, -20, -11, -11,
-	-74, -74, -74, -74, -63, -63, -63, -63, -63, -63,
-	166, 281, 281, 281, 345, -1000, 372, -1000, -1000, 71,
-	-1000, 344, -1000, 371, 236, 191, 436, 434, 412, 410,
-	383, 466, -1000, -1000, -1000, -1000, -1000, -1000, 141, 305,
-	148, 181, 84, 241, 116, 276, 141, 316, 197, 343,
-	258, -1000, -1000, 246, -1000, 446, -1000, 275, 274, 229,
-	121, 271, 166, 168, 504, 445, -1000, 469, 424, 296,
-	-1000, -1000, -1000, 270, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 56,
+	56, 56, 56, 56, 56, 56, 56, 56, 56, 56,
+	56, 56, 56, 56, 137, -1000, 179, 535, -1000, 15,
+	504, 503, -1000, -1000, -1000, -1000, 258, 238, -58, 361,
+	376, -1000, 71, 321, 466, 323, 322, 320, -1000, -1000,
+	336, 413, 336, 20, 10, -1000, 336, 336, 336, 336,
+	336, 336, 336, 336, 336, 336, 336, 336, 336, 336,
+	-1000, -1000, -1000, -1000, 260, -1000, -1000, -1000, -1000, 461,
+	-1000, 502, -1000, 483, -1000, -1000, -1000, -1000, -1000, 272,
+	482, 520, 519, 519, 62, -1000, -1000, -1000, 316, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 518, -1000, 481, 480,
+	479, 478, 250, 365, 251, 308, 228, 364, 401, 246,
+	237, 363, 178, -44, 315, 309, 301, 265, -32, -32,
+	-75, -75, -81, -81, -81, -81, -26, -26, -26, -26,
+	-26, -26, 260, 272, 272, 272, 404, 362, 388, -1000,
+	382, -1000, -1000, 175, -1000, 360, -1000, 381, 359, -1000,
+	359, 286, 263, 440, 439, 415, 414, 398, 477, -1000,
+	-1000, -1000, -1000, -1000, -1000, 99, 308, 57, 95, 163,
+	488, 183, 205, 99, 336, 171, 341, 296, -1000, 295,
+	-1000, 472, -1000, 215, 212, 209, 199, 314, 260, 82,
+	343, 510, 407, 471, -1000, 474, 453, 508, 261, -1000,
+	-1000, -1000, 151, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, 176, -1000, 249, 127, 58, 127, 374, -51,
-	281, -51, 177, 119, 386, 211, 78, -1000, -1000, 172,
-	-1000, 316, 503, -1000, -1000, 342, 242, -1000, 231, -1000,
-	-1000, 230, -1000, 193, -1000, -1000, -1000, -1000, -1000, -1000,
-	444, 442, -1000, 141, 58, 127, 58, -1000, -1000, 166,
-	-1000, -51, -1000, 161, -1000, -1000, -1000, 82, 385, 384,
-	207, 141, 170, -1000, 441, -1000, -1000, -1000, -1000, 159,
-	139, -1000, 58, -1000, 387, 77, 58, 43, -51, -51,
-	376, -1000, -1000, 341, -1000, -1000, 131, 58, -1000, -1000,
-	-51, 433, -1000, -1000, 262, 425, 129, -1000,
+	-1000, 164, -1000, 136, 78, 50, 78, 402, 12, 272,
+	12, 85, 255, 400, 120, 112, -1000, -1000, 157, -1000,
+	336, -1000, -1000, 339, 216, -1000, 191, -1000, -1000, 190,
+	-1000, 159, 406, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, 469, 465, -1000, 99, 50, 78, 50, -1000, -1000,
+	260, -1000, 12, -1000, 83, -1000, -1000, -1000, 25, 396,
+	394, 119, 99, 156, 464, -1000, -1000, -1000, -1000, -1000,
+	142, 132, -1000, 50, -1000, 467, 51, 50, 43, 12,
+	12, 393, -1000, -1000, 338, -1000, -1000, 114, 50, -1000,
+	-1000, 12, 462, -1000, -1000, 337, 444, 103, -1000,
 }
 
 var exprPgo = [...]int16{
-	0, 542, 16, 541, 2, 9, 449, 3, 15, 11,
-	540, 539, 538, 537, 7, 536, 535, 534, 533, 532,
-	531, 530, 529, 446, 528, 527, 526, 13, 5, 525,
-	524, 523, 6, 522, 24, 521, 520, 4, 519, 518,
-	8, 517, 1, 516, 475, 0,
+	0, 584, 11, 581, 2, 9, 460, 3, 16, 8,
+	577, 576, 575, 574, 15, 573, 572, 571, 570, 569,
+	568, 567, 564, 247, 563, 561, 560, 13, 5, 558,
+	554, 552, 6, 551, 73, 550, 549, 548, 547, 4,
+	544, 543, 7, 542, 541, 1, 538, 534, 0,
 }
 
 var exprR1 = [...]int8{
@@ -367,22 +397,24 @@ var exprR1 = [...]int8{
 	7, 6, 6, 6, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
-	42, 42, 42, 13, 13, 13, 11, 11, 11, 11,
+	45, 45, 45, 13, 13, 13, 11, 11, 11, 11,
 	15, 15, 15, 15, 15, 15, 22, 3, 3, 3,
 	3, 14, 14, 14, 10, 10, 9, 9, 9, 9,
-	27, 27, 28, 28, 28, 28, 28, 28, 19, 34,
-	34, 33, 33, 26, 26, 26, 26, 26, 39, 35,
-	37, 37, 38, 38, 38, 36, 32, 32, 32, 32,
-	32, 32, 32, 32, 32, 40, 40, 41, 41, 44,
-	44, 43, 43, 31, 31, 31, 31, 31, 31, 31,
+	27, 27, 28, 28, 28, 28, 28, 28, 28, 28,
+	19, 34, 34, 34, 34, 33, 33, 26, 26, 26,
+	26, 26, 26, 26, 41, 44, 44, 35, 39, 39,
+	40, 40, 40, 36, 37, 38, 32, 32, 32, 32,
+	32, 32, 32, 32, 32, 42, 42, 43, 43, 47,
+	47, 46, 46, 31, 31, 31, 31, 31, 31, 31,
 	29, 29, 29, 29, 29, 29, 29, 30, 30, 30,
 	30, 30, 30, 30, 20, 20, 20, 20, 20, 20,
 	20, 20, 20, 20, 20, 20, 20, 20, 20, 24,
 	24, 25, 25, 25, 25, 23, 23, 23, 23, 23,
 	23, 23, 23, 21, 21, 21, 17, 18, 16, 16,
-	16, 16, 16, 16, 16, 16, 16, 12, 12, 12,
+	16, 16, 16, 16, 16, 16, 16, 16, 16, 16,
 	12, 12, 12, 12, 12, 12, 12, 12, 12, 12,
-	12, 12, 45, 5, 5, 4, 4, 4, 4,
+	12, 12, 12, 12, 12, 48, 5, 5, 4, 4,
+	4, 4,
 }
 
 var exprR2 = [...]int8{
@@ -393,9 +425,10 @@ var exprR2 = [...]int8{
 	3, 6, 3, 1, 1, 1, 4, 6, 5, 7,
 	4, 5, 5, 6, 7, 7, 12, 1, 1, 1,
 	1, 3, 3, 3, 1, 3, 3, 3, 3, 3,
-	1, 2, 1, 2, 2, 2, 2, 2, 1, 2,
-	5, 1, 2, 1, 1, 2, 1, 2, 2, 2,
-	3, 3, 1, 3, 3, 2, 1, 1, 1, 1,
+	1, 2, 1, 2, 2, 2, 2, 2, 2, 2,
+	1, 2, 5, 2, 2, 1, 2, 1, 2, 1,
+	2, 1, 2, 1, 2, 3, 4, 2, 3, 3,
+	1, 3, 3, 2, 2, 2, 1, 1, 1, 1,
 	3, 2, 3, 3, 3, 3, 1, 1, 3, 6,
 	6, 1, 1, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
@@ -405,83 +438,88 @@ var exprR2 = [...]int8{
 	2, 4, 5, 1, 2, 2, 4, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 2, 1, 3, 4, 4, 3, 3,
+	1, 1, 1, 1, 1, 2, 1, 3, 4, 4,
+	3, 3,
 }
 
 var exprChk = [...]int16{
-	-1000, -1, -2, -6, -7, -14, 23, -11, -15, -20,
-	-21, -22, -17, 15, -12, -16, 7, 81, 82, 63,
-	-18, 27, 28, 29, 39, 40, 49, 50, 51, 52,
-	53, 54, 55, 59, 60, 61, 30, 31, 34, 32,
-	33, 35, 36, 37, 38, 62, 72, 73, 74, 81,
-	82, 83, 84, 85, 86, 75, 76, 79, 80, 77,
-	78, -27, -28, -33, 45, -34, -3, 21, 22, 14,
-	76, -7, -6, -2, -10, 2, -9, 5, 23, 23,
-	-4, 25, 26, 7, 7, 23, 23, -23, -24, -25,
-	41, -23, -23, -23, -23, -23, -23, -23, -23, -23,
-	-23, -23, -23, -23, -23, -28, -34, -26, -39, -32,
-	-35, -36, 42, 44, 43, 64, 66, -9, -44, -43,
-	-30, 23, 46, 47, 5, -31, -29, 6, -19, 67,
-	24, 24, 16, 2, 19, 16, 12, 76, 13, 14,
-	-8, 7, -14, 23, -7, 7, 23, 23, 23, -7,
-	7, -2, 68, 69, 70, 71, -2, -2, -2, -2,
+	-1000, -1, -2, -6, -7, -14, 24, -11, -15, -20,
+	-21, -22, -17, 16, -12, -16, 7, 90, 91, 64,
+	-18, 28, 29, 30, 40, 41, 50, 51, 52, 53,
+	54, 55, 56, 60, 61, 62, 31, 32, 35, 33,
+	34, 36, 37, 38, 39, 73, 79, 80, 63, 81,
+	82, 83, 90, 91, 92, 93, 94, 95, 84, 85,
+	88, 89, 86, 87, -27, -28, -33, 46, -34, -3,
+	75, 76, 22, 23, 15, 85, -7, -6, -2, -10,
+	2, -9, 5, 24, 24, -4, 26, 27, 7, 7,
+	24, 24, -23, -24, -25, 42, -23, -23, -23, -23,
+	-23, -23, -23, -23, -23, -23, -23, -23, -23, -23,
+	-28, -34, -26, -41, -32, -35, -36, -37, -38, 43,
+	45, 44, 65, 67, 74, -9, -47, -46, -30, 24,
+	47, 48, 77, 78, 5, -31, -29, 6, -19, 68,
+	6, 6, 25, 25, 17, 2, 20, 17, 13, 85,
+	14, 15, -8, 7, -14, 24, -7, 7, 24, 24,
+	24, -7, 7, -2, 69, 70, 71, 72, -2, -2,
 	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
-	-32, 73, 19, 72, -41, -40, 5, 6, 6, -32,
-	6, -38, -37, 5, 12, 76, 79, 80, 77, 78,
-	75, 23, -9, 6, 6, 6, 6, 2, 24, 19,
-	9, -42, -27, 45, -14, -8, 24, 19, -7, 7,
-	-5, 24, 5, -5, 24, 19, 24, 23, 23, 23,
-	23, -32, -32, -32, 19, 12, 24, 19, 12, 67,
-	8, 4, 7, 67, 8, 4, 7, 8, 4, 7,
-	8, 4, 7, 8, 4, 7, 8, 4, 7, 8,
-	4, 7, 6, -4, -8, -45, -42, -27, 65, 9,
-	45, 9, -42, 48, 24, -42, -27, 24, -4, -7,
-	24, 19, 19, 24, 24, 6, -5, 24, -5, 24,
-	24, -5, 24, -5, -40, 6, -37, 2, 5, 6,
-	23, 23, 24, 24, -42, -27, -42, 8, -45, -32,
-	-45, 9, 5, -13, 56, 57, 58, 9, 24, 24,
-	-42, 24, -7, 5, 19, 24, 24, 24, 24, 6,
-	6, -4, -42, -45, 23, -45, -42, 45, 9, 9,
-	24, -4, 24, 6, 24, 24, 5, -42, -45, -45,
-	9, 19, 24, -45, 6, 19, 6, 24,
+	-2, -2, -32, 82, 20, 81, -44, -43, 8, -42,
+	5, 6, 6, -32, 6, -40, -39, 5, -5, 5,
+	-5, 13, 85, 88, 89, 86, 87, 84, 24, -9,
+	6, 6, 6, 6, 2, 25, 20, 10, -45, -27,
+	46, -14, -8, 25, 20, -7, 7, -5, 25, -5,
+	25, 20, 25, 24, 24, 24, 24, -32, -32, -32,
+	8, 20, 13, 13, 25, 20, 13, 20, 68, 9,
+	4, 7, 68, 9, 4, 7, 9, 4, 7, 9,
+	4, 7, 9, 4, 7, 9, 4, 7, 9, 4,
+	7, 6, -4, -8, -48, -45, -27, 66, 10, 46,
+	10, -45, 49, 25, -45, -27, 25, -4, -7, 25,
+	20, 25, 25, 6, -5, 25, -5, 25, 25, -5,
+	25, -5, 13, -42, 7, 6, -39, 2, 5, 6,
+	5, 24, 24, 25, 25, -45, -27, -45, 9, -48,
+	-32, -48, 10, 5, -13, 57, 58, 59, 10, 25,
+	25, -45, 25, -7, 20, 25, 25, 25, 25, 7,
+	6, 6, -4, -45, -48, 24, -48, -45, 46, 10,
+	10, 25, -4, 25, 6, 25, 25, 5, -45, -48,
+	-48, 10, 20, 25, -48, 6, 20, 6, 25,
 }
 
 var exprDef = [...]int16{
 	0, -2, 1, 2, 3, 11, 0, 4, 5, 6,
-	7, 8, 9, 0, 0, 0, 163, 0, 0, 0,
-	0, 177, 178, 179, 180, 181, 182, 183, 184, 185,
-	186, 187, 188, 189, 190, 191, 168, 169, 170, 171,
-	172, 173, 174, 175, 176, 167, 149, 149, 149, 149,
-	149, 149, 149, 149, 149, 149, 149, 149, 149, 149,
-	149, 12, 70, 72, 0, 81, 0, 57, 58, 59,
-	60, 3, 2, 0, 0, 0, 64, 0, 0, 0,
-	0, 0, 0, 164, 165, 0, 0, 0, 155, 156,
-	150, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 71, 82, 73, 74, 75,
-	76, 77, 83, 84, 0, 86, 0, 96, 97, 98,
-	99, 0, 0, 0, 0, 111, 112, 79, 0, 78,
-	10, 13, 61, 62, 0, 63, 0, 0, 0, 0,
-	0, 0, 0, 0, 3, 163, 0, 0, 0, 3,
-	0, 134, 0, 0, 157, 160, 135, 136, 137, 138,
-	139, 140, 141, 142, 143, 144, 145, 146, 147, 148,
-	101, 0, 0, 0, 88, 107, 106, 85, 87, 0,
-	89, 95, 92, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 65, 66, 67, 68, 69, 39, 46, 0,
-	14, 0, 0, 0, 0, 0, 50, 0, 3, 163,
-	0, 197, 193, 0, 198, 0, 166, 0, 0, 0,
-	0, 102, 103, 104, 0, 0, 100, 0, 0, 0,
-	118, 125, 132, 0, 117, 124, 131, 113, 120, 127,
-	114, 121, 128, 115, 122, 129, 116, 123, 130, 119,
-	126, 133, 0, 48, 0, 15, 18, 34, 0, 22,
-	0, 26, 0, 0, 0, 0, 0, 38, 52, 3,
-	51, 0, 0, 195, 196, 0, 0, 152, 0, 154,
-	158, 0, 161, 0, 108, 105, 93, 94, 90, 91,
-	0, 0, 80, 47, 19, 35, 36, 192, 23, 42,
-	27, 30, 40, 0, 43, 44, 45, 16, 0, 0,
-	0, 53, 3, 194, 0, 151, 153, 159, 162, 0,
-	0, 49, 37, 31, 0, 17, 20, 0, 24, 28,
-	0, 54, 55, 0, 109, 110, 0, 21, 25, 29,
-	32, 0, 41, 33, 0, 0, 0, 56,
+	7, 8, 9, 0, 0, 0, 173, 0, 0, 0,
+	0, 190, 191, 192, 193, 194, 195, 196, 197, 198,
+	199, 200, 201, 202, 203, 204, 178, 179, 180, 181,
+	182, 183, 184, 185, 186, 187, 188, 189, 177, 159,
+	159, 159, 159, 159, 159, 159, 159, 159, 159, 159,
+	159, 159, 159, 159, 12, 70, 72, 0, 85, 0,
+	0, 0, 57, 58, 59, 60, 3, 2, 0, 0,
+	0, 64, 0, 0, 0, 0, 0, 0, 174, 175,
+	0, 0, 0, 165, 166, 160, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	71, 86, 73, 74, 75, 76, 77, 78, 79, 87,
+	89, 0, 91, 0, 93, 106, 107, 108, 109, 0,
+	0, 0, 0, 0, 0, 121, 122, 81, 0, 80,
+	83, 84, 10, 13, 61, 62, 0, 63, 0, 0,
+	0, 0, 0, 0, 0, 0, 3, 173, 0, 0,
+	0, 3, 0, 144, 0, 0, 167, 170, 145, 146,
+	147, 148, 149, 150, 151, 152, 153, 154, 155, 156,
+	157, 158, 111, 0, 0, 0, 88, 94, 0, 117,
+	116, 90, 92, 0, 97, 103, 100, 0, 104, 206,
+	105, 0, 0, 0, 0, 0, 0, 0, 0, 65,
+	66, 67, 68, 69, 39, 46, 0, 14, 0, 0,
+	0, 0, 0, 50, 0, 3, 173, 0, 210, 0,
+	211, 0, 176, 0, 0, 0, 0, 112, 113, 114,
+	0, 0, 0, 0, 110, 0, 0, 0, 0, 128,
+	135, 142, 0, 127, 134, 141, 123, 130, 137, 124,
+	131, 138, 125, 132, 139, 126, 133, 140, 129, 136,
+	143, 0, 48, 0, 15, 18, 34, 0, 22, 0,
+	26, 0, 0, 0, 0, 0, 38, 52, 3, 51,
+	0, 208, 209, 0, 0, 162, 0, 164, 168, 0,
+	171, 0, 0, 118, 95, 115, 101, 102, 98, 99,
+	207, 0, 0, 82, 47, 19, 35, 36, 205, 23,
+	42, 27, 30, 40, 0, 43, 44, 45, 16, 0,
+	0, 0, 53, 3, 0, 161, 163, 169, 172, 96,
+	0, 0, 49, 37, 31, 0, 17, 20, 0, 24,
+	28, 0, 54, 55, 0, 119, 120, 0, 21, 25,
+	29, 32, 0, 41, 33, 0, 0, 0, 56,
 }
 
 var exprTok1 = [...]int8{
@@ -497,7 +535,8 @@ var exprTok2 = [...]int8{
 	52, 53, 54, 55, 56, 57, 58, 59, 60, 61,
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
-	82, 83, 84, 85, 86,
+	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
+	92, 93, 94, 95,
 }
 
 var exprTok3 = [...]int8{
@@ -843,1188 +882,1266 @@ exprdefault:
 
 	case 1:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:129
+//line pkg/logql/syntax/expr.y:136
 		{
 			exprlex.(*parser).expr = exprDollar[1].Expr
 		}
 	case 2:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:132
+//line pkg/logql/syntax/expr.y:139
 		{
 			exprVAL.Expr = exprDollar[1].LogExpr
 		}
 	case 3:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:133
+//line pkg/logql/syntax/expr.y:140
 		{
 			exprVAL.Expr = exprDollar[1].MetricExpr
 		}
 	case 4:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:137
+//line pkg/logql/syntax/expr.y:144
 		{
 			exprVAL.MetricExpr = exprDollar[1].RangeAggregationExpr
 		}
 	case 5:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:138
+//line pkg/logql/syntax/expr.y:145
 		{
 			exprVAL.MetricExpr = exprDollar[1].VectorAggregationExpr
 		}
 	case 6:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:139
+//line pkg/logql/syntax/expr.y:146
 		{
 			exprVAL.MetricExpr = exprDollar[1].BinOpExpr
 		}
 	case 7:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:140
+//line pkg/logql/syntax/expr.y:147
 		{
 			exprVAL.MetricExpr = exprDollar[1].LiteralExpr
 		}
 	case 8:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:141
+//line pkg/logql/syntax/expr.y:148
 		{
 			exprVAL.MetricExpr = exprDollar[1].LabelReplaceExpr
 		}
 	case 9:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:142
+//line pkg/logql/syntax/expr.y:149
 		{
 			exprVAL.MetricExpr = exprDollar[1].VectorExpr
 		}
 	case 10:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:143
+//line pkg/logql/syntax/expr.y:150
 		{
 			exprVAL.MetricExpr = exprDollar[2].MetricExpr
 		}
 	case 11:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:147
+//line pkg/logql/syntax/expr.y:154
 		{
 			exprVAL.LogExpr = newMatcherExpr(exprDollar[1].Selector)
 		}
 	case 12:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:148
+//line pkg/logql/syntax/expr.y:155
 		{
 			exprVAL.LogExpr = newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr)
 		}
 	case 13:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:149
+//line pkg/logql/syntax/expr.y:156
 		{
 			exprVAL.LogExpr = exprDollar[2].LogExpr
 		}
 	case 14:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:153
+//line pkg/logql/syntax/expr.y:160
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, nil)
 		}
 	case 15:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:154
+//line pkg/logql/syntax/expr.y:161
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
 	case 16:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:155
+//line pkg/logql/syntax/expr.y:162
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, nil)
 		}
 	case 17:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:156
+//line pkg/logql/syntax/expr.y:163
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, exprDollar[5].OffsetExpr)
 		}
 	case 18:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:157
+//line pkg/logql/syntax/expr.y:164
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 19:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:158
+//line pkg/logql/syntax/expr.y:165
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[4].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
 	case 20:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:159
+//line pkg/logql/syntax/expr.y:166
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[5].UnwrapExpr, nil)
 		}
 	case 21:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:160
+//line pkg/logql/syntax/expr.y:167
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[6].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
 	case 22:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:161
+//line pkg/logql/syntax/expr.y:168
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, nil)
 		}
 	case 23:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:162
+//line pkg/logql/syntax/expr.y:169
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, exprDollar[4].OffsetExpr)
 		}
 	case 24:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:163
+//line pkg/logql/syntax/expr.y:170
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 25:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:164
+//line pkg/logql/syntax/expr.y:171
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, exprDollar[6].OffsetExpr)
 		}
 	case 26:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:165
+//line pkg/logql/syntax/expr.y:172
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, nil)
 		}
 	case 27:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:166
+//line pkg/logql/syntax/expr.y:173
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, exprDollar[4].OffsetExpr)
 		}
 	case 28:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:167
+//line pkg/logql/syntax/expr.y:174
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, nil)
 		}
 	case 29:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:168
+//line pkg/logql/syntax/expr.y:175
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, exprDollar[6].OffsetExpr)
 		}
 	case 30:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:169
+//line pkg/logql/syntax/expr.y:176
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, nil)
 		}
 	case 31:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:170
+//line pkg/logql/syntax/expr.y:177
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
 	case 32:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:171
+//line pkg/logql/syntax/expr.y:178
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, nil)
 		}
 	case 33:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line pkg/logql/syntax/expr.y:172
+//line pkg/logql/syntax/expr.y:179
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, exprDollar[7].OffsetExpr)
 		}
 	case 34:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:173
+//line pkg/logql/syntax/expr.y:180
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, nil, nil)
 		}
 	case 35:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:174
+//line pkg/logql/syntax/expr.y:181
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
 	case 36:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:175
+//line pkg/logql/syntax/expr.y:182
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, exprDollar[4].UnwrapExpr, nil)
 		}
 	case 37:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:176
+//line pkg/logql/syntax/expr.y:183
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, exprDollar[5].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
 	case 38:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:177
+//line pkg/logql/syntax/expr.y:184
 		{
 			exprVAL.LogRangeExpr = exprDollar[2].LogRangeExpr
 		}
 	case 40:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:182
+//line pkg/logql/syntax/expr.y:189
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[3].str, "")
 		}
 	case 41:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:183
+//line pkg/logql/syntax/expr.y:190
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[5].str, exprDollar[3].ConvOp)
 		}
 	case 42:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:184
+//line pkg/logql/syntax/expr.y:191
 		{
 			exprVAL.UnwrapExpr = exprDollar[1].UnwrapExpr.addPostFilter(exprDollar[3].LabelFilter)
 		}
 	case 43:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:188
+//line pkg/logql/syntax/expr.y:195
 		{
 			exprVAL.ConvOp = OpConvBytes
 		}
 	case 44:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:189
+//line pkg/logql/syntax/expr.y:196
 		{
 			exprVAL.ConvOp = OpConvDuration
 		}
 	case 45:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:190
+//line pkg/logql/syntax/expr.y:197
 		{
 			exprVAL.ConvOp = OpConvDurationSeconds
 		}
 	case 46:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:194
+//line pkg/logql/syntax/expr.y:201
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, nil, nil)
 		}
 	case 47:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:195
+//line pkg/logql/syntax/expr.y:202
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, nil, &exprDollar[3].str)
 		}
 	case 48:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:196
+//line pkg/logql/syntax/expr.y:203
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[5].Grouping, nil)
 		}
 	case 49:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line pkg/logql/syntax/expr.y:197
+//line pkg/logql/syntax/expr.y:204
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
 	case 50:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:202
+//line pkg/logql/syntax/expr.y:209
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, nil, nil)
 		}
 	case 51:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:203
+//line pkg/logql/syntax/expr.y:210
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[4].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, nil)
 		}
 	case 52:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:204
+//line pkg/logql/syntax/expr.y:211
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, exprDollar[5].Grouping, nil)
 		}
 	case 53:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:206
+//line pkg/logql/syntax/expr.y:213
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, nil, &exprDollar[3].str)
 		}
 	case 54:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line pkg/logql/syntax/expr.y:207
+//line pkg/logql/syntax/expr.y:214
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
 	case 55:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line pkg/logql/syntax/expr.y:208
+//line pkg/logql/syntax/expr.y:215
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[6].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, &exprDollar[4].str)
 		}
 	case 56:
 		exprDollar = exprS[exprpt-12 : exprpt+1]
-//line pkg/logql/syntax/expr.y:213
+//line pkg/logql/syntax/expr.y:220
 		{
 			exprVAL.LabelReplaceExpr = mustNewLabelReplaceExpr(exprDollar[3].MetricExpr, exprDollar[5].str, exprDollar[7].str, exprDollar[9].str, exprDollar[11].str)
 		}
 	case 57:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:217
+//line pkg/logql/syntax/expr.y:224
 		{
 			exprVAL.Filter = labels.MatchRegexp
 		}
 	case 58:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:218
+//line pkg/logql/syntax/expr.y:225
 		{
 			exprVAL.Filter = labels.MatchEqual
 		}
 	case 59:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:219
+//line pkg/logql/syntax/expr.y:226
 		{
 			exprVAL.Filter = labels.MatchNotRegexp
 		}
 	case 60:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:220
+//line pkg/logql/syntax/expr.y:227
 		{
 			exprVAL.Filter = labels.MatchNotEqual
 		}
 	case 61:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:224
+//line pkg/logql/syntax/expr.y:231
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
 	case 62:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:225
+//line pkg/logql/syntax/expr.y:232
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
 	case 63:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:226
+//line pkg/logql/syntax/expr.y:233
 		{
 		}
 	case 64:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:230
+//line pkg/logql/syntax/expr.y:237
 		{
 			exprVAL.Matchers = []*labels.Matcher{exprDollar[1].Matcher}
 		}
 	case 65:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:231
+//line pkg/logql/syntax/expr.y:238
 		{
 			exprVAL.Matchers = append(exprDollar[1].Matchers, exprDollar[3].Matcher)
 		}
 	case 66:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:235
+//line pkg/logql/syntax/expr.y:242
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchEqual, exprDollar[1].str, exprDollar[3].str)
 		}
 	case 67:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:236
+//line pkg/logql/syntax/expr.y:243
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotEqual, exprDollar[1].str, exprDollar[3].str)
 		}
 	case 68:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:237
+//line pkg/logql/syntax/expr.y:244
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
 	case 69:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:238
+//line pkg/logql/syntax/expr.y:245
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
 	case 70:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:242
+//line pkg/logql/syntax/expr.y:249
 		{
 			exprVAL.PipelineExpr = MultiStageExpr{exprDollar[1].PipelineStage}
 		}
 	case 71:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:243
+//line pkg/logql/syntax/expr.y:250
 		{
 			exprVAL.PipelineExpr = append(exprDollar[1].PipelineExpr, exprDollar[2].PipelineStage)
 		}
 	case 72:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:247
+//line pkg/logql/syntax/expr.y:254
 		{
 			exprVAL.PipelineStage = exprDollar[1].LineFilters
 		}
 	case 73:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:248
+//line pkg/logql/syntax/expr.y:255
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelParser
 		}
 	case 74:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:249
+//line pkg/logql/syntax/expr.y:256
 		{
 			exprVAL.PipelineStage = exprDollar[2].JSONExpressionParser
 		}
 	case 75:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:250
+//line pkg/logql/syntax/expr.y:257
 		{
 			exprVAL.PipelineStage = &LabelFilterExpr{LabelFilterer: exprDollar[2].LabelFilter}
 		}
 	case 76:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:251
+//line pkg/logql/syntax/expr.y:258
 		{
 			exprVAL.PipelineStage = exprDollar[2].LineFormatExpr
 		}
 	case 77:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:252
+//line pkg/logql/syntax/expr.y:259
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelFormatExpr
 		}
 	case 78:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:260
+		{
+			exprVAL.PipelineStage = exprDollar[2].DropLabelsExpr
+		}
+	case 79:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:261
+		{
+			exprVAL.PipelineStage = exprDollar[2].KeepLabelsExpr
+		}
+	case 80:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:256
+//line pkg/logql/syntax/expr.y:265
 		{
 			exprVAL.FilterOp = OpFilterIP
 		}
-	case 79:
+	case 81:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:260
+//line pkg/logql/syntax/expr.y:269
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str)
 		}
-	case 80:
+	case 82:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:261
+//line pkg/logql/syntax/expr.y:270
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, exprDollar[2].FilterOp, exprDollar[4].str)
 		}
-	case 81:
+	case 83:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:271
+		{
+			exprVAL.LineFilter = newLineFilterExpr(labels.MatchEqual, OpFilterPattern, exprDollar[2].str)
+		}
+	case 84:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:272
+		{
+			exprVAL.LineFilter = newLineFilterExpr(labels.MatchNotEqual, OpFilterPattern, exprDollar[2].str)
+		}
+	case 85:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:265
+//line pkg/logql/syntax/expr.y:276
 		{
 			exprVAL.LineFilters = exprDollar[1].LineFilter
 		}
-	case 82:
+	case 86:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:266
+//line pkg/logql/syntax/expr.y:277
 		{
 			exprVAL.LineFilters = newNestedLineFilterExpr(exprDollar[1].LineFilters, exprDollar[2].LineFilter)
 		}
-	case 83:
+	case 87:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:270
+//line pkg/logql/syntax/expr.y:281
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 84:
+	case 88:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:282
+		{
+			exprVAL.LabelParser = newJSONParserExpr(exprDollar[2].JSONParserLimits)
+		}
+	case 89:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:271
+//line pkg/logql/syntax/expr.y:283
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeLogfmt, "")
 		}
-	case 85:
+	case 90:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:272
+//line pkg/logql/syntax/expr.y:284
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeRegexp, exprDollar[2].str)
 		}
-	case 86:
+	case 91:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:273
+//line pkg/logql/syntax/expr.y:285
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 87:
+	case 92:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:274
+//line pkg/logql/syntax/expr.y:286
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypePattern, exprDollar[2].str)
 		}
-	case 88:
+	case 93:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:287
+		{
+			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeMetadata, "")
+		}
+	case 94:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:278
+//line pkg/logql/syntax/expr.y:291
 		{
 			exprVAL.JSONExpressionParser = newJSONExpressionParser(exprDollar[2].JSONExpressionList)
 		}
-	case 89:
+	case 95:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line pkg/logql/syntax/expr.y:294
+		{
+			exprVAL.JSONParserLimits = withJSONParserFlag(log.JSONParserLimits{}, exprDollar[1].str, exprDollar[3].str)
+		}
+	case 96:
+		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line pkg/logql/syntax/expr.y:295
+		{
+			exprVAL.JSONParserLimits = withJSONParserFlag(exprDollar[1].JSONParserLimits, exprDollar[2].str, exprDollar[4].str)
+		}
+	case 97:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:280
+//line pkg/logql/syntax/expr.y:298
 		{
 			exprVAL.LineFormatExpr = newLineFmtExpr(exprDollar[2].str)
 		}
-	case 90:
+	case 98:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:283
+//line pkg/logql/syntax/expr.y:301
 		{
 			exprVAL.LabelFormat = log.NewRenameLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 91:
+	case 99:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:284
+//line pkg/logql/syntax/expr.y:302
 		{
 			exprVAL.LabelFormat = log.NewTemplateLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 92:
+	case 100:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:288
+//line pkg/logql/syntax/expr.y:306
 		{
 			exprVAL.LabelsFormat = []log.LabelFmt{exprDollar[1].LabelFormat}
 		}
-	case 93:
+	case 101:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:289
+//line pkg/logql/syntax/expr.y:307
 		{
 			exprVAL.LabelsFormat = append(exprDollar[1].LabelsFormat, exprDollar[3].LabelFormat)
 		}
-	case 95:
+	case 103:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:293
+//line pkg/logql/syntax/expr.y:311
 		{
 			exprVAL.LabelFormatExpr = newLabelFmtExpr(exprDollar[2].LabelsFormat)
 		}
-	case 96:
+	case 104:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:313
+		{
+			exprVAL.DropLabelsExpr = newDropLabelsExpr(exprDollar[2].Labels)
+		}
+	case 105:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line pkg/logql/syntax/expr.y:315
+		{
+			exprVAL.KeepLabelsExpr = newKeepLabelsExpr(exprDollar[2].Labels)
+		}
+	case 106:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:296
+//line pkg/logql/syntax/expr.y:318
 		{
 			exprVAL.LabelFilter = log.NewStringLabelFilter(exprDollar[1].Matcher)
 		}
-	case 97:
+	case 107:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:297
+//line pkg/logql/syntax/expr.y:319
 		{
 			exprVAL.LabelFilter = exprDollar[1].IPLabelFilter
 		}
-	case 98:
+	case 108:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:298
+//line pkg/logql/syntax/expr.y:320
 		{
 			exprVAL.LabelFilter = exprDollar[1].UnitFilter
 		}
-	case 99:
+	case 109:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:299
+//line pkg/logql/syntax/expr.y:321
 		{
 			exprVAL.LabelFilter = exprDollar[1].NumberFilter
 		}
-	case 100:
+	case 110:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:300
+//line pkg/logql/syntax/expr.y:322
 		{
 			exprVAL.LabelFilter = exprDollar[2].LabelFilter
 		}
-	case 101:
+	case 111:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:301
+//line pkg/logql/syntax/expr.y:323
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[2].LabelFilter)
 		}
-	case 102:
+	case 112:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:302
+//line pkg/logql/syntax/expr.y:324
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 103:
+	case 113:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:303
+//line pkg/logql/syntax/expr.y:325
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 104:
+	case 114:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:304
+//line pkg/logql/syntax/expr.y:326
 		{
 			exprVAL.LabelFilter = log.NewOrLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 105:
+	case 115:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:308
+//line pkg/logql/syntax/expr.y:330
 		{
 			exprVAL.JSONExpression = log.NewJSONExpr(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 106:
+	case 116:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:309
+//line pkg/logql/syntax/expr.y:331
 		{
 			exprVAL.JSONExpression = log.NewJSONExpr(exprDollar[1].str, exprDollar[1].str)
 		}
-	case 107:
+	case 117:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:312
+//line pkg/logql/syntax/expr.y:334
 		{
 			exprVAL.JSONExpressionList = []log.JSONExpression{exprDollar[1].JSONExpression}
 		}
-	case 108:
+	case 118:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:313
+//line pkg/logql/syntax/expr.y:335
 		{
 			exprVAL.JSONExpressionList = append(exprDollar[1].JSONExpressionList, exprDollar[3].JSONExpression)
 		}
-	case 109:
+	case 119:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:317
+//line pkg/logql/syntax/expr.y:339
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
 		}
-	case 110:
+	case 120:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line pkg/logql/syntax/expr.y:318
+//line pkg/logql/syntax/expr.y:340
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 111:
+	case 121:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:322
+//line pkg/logql/syntax/expr.y:344
 		{
 			exprVAL.UnitFilter = exprDollar[1].DurationFilter
 		}
-	case 112:
+	case 122:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:323
+//line pkg/logql/syntax/expr.y:345
 		{
 			exprVAL.UnitFilter = exprDollar[1].BytesFilter
 		}
-	case 113:
+	case 123:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:326
+//line pkg/logql/syntax/expr.y:348
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 114:
+	case 124:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:327
+//line pkg/logql/syntax/expr.y:349
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 115:
+	case 125:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:328
+//line pkg/logql/syntax/expr.y:350
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 116:
+	case 126:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:329
+//line pkg/logql/syntax/expr.y:351
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 117:
+	case 127:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:330
+//line pkg/logql/syntax/expr.y:352
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 118:
+	case 128:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:331
+//line pkg/logql/syntax/expr.y:353
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 119:
+	case 129:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:332
+//line pkg/logql/syntax/expr.y:354
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 120:
+	case 130:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:336
+//line pkg/logql/syntax/expr.y:358
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 121:
+	case 131:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:337
+//line pkg/logql/syntax/expr.y:359
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 122:
+	case 132:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:338
+//line pkg/logql/syntax/expr.y:360
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 123:
+	case 133:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:339
+//line pkg/logql/syntax/expr.y:361
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 124:
+	case 134:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:340
+//line pkg/logql/syntax/expr.y:362
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 125:
+	case 135:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:341
+//line pkg/logql/syntax/expr.y:363
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 126:
+	case 136:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:342
+//line pkg/logql/syntax/expr.y:364
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 127:
+	case 137:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:346
+//line pkg/logql/syntax/expr.y:368
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 128:
+	case 138:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:347
+//line pkg/logql/syntax/expr.y:369
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 129:
+	case 139:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:348
+//line pkg/logql/syntax/expr.y:370
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 130:
+	case 140:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:349
+//line pkg/logql/syntax/expr.y:371
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 131:
+	case 141:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:350
+//line pkg/logql/syntax/expr.y:372
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 132:
+	case 142:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:351
+//line pkg/logql/syntax/expr.y:373
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 133:
+	case 143:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:352
+//line pkg/logql/syntax/expr.y:374
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 134:
+	case 144:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:357
+//line pkg/logql/syntax/expr.y:379
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("or", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 135:
+	case 145:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:358
+//line pkg/logql/syntax/expr.y:380
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("and", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 136:
+	case 146:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:359
+//line pkg/logql/syntax/expr.y:381
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("unless", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 137:
+	case 147:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:360
+//line pkg/logql/syntax/expr.y:382
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("+", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 138:
+	case 148:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:361
+//line pkg/logql/syntax/expr.y:383
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("-", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 139:
+	case 149:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:362
+//line pkg/logql/syntax/expr.y:384
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("*", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 140:
+	case 150:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:363
+//line pkg/logql/syntax/expr.y:385
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("/", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 141:
+	case 151:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:364
+//line pkg/logql/syntax/expr.y:386
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("%", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 142:
+	case 152:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:365
+//line pkg/logql/syntax/expr.y:387
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("^", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 143:
+	case 153:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:366
+//line pkg/logql/syntax/expr.y:388
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("==", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 144:
+	case 154:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:367
+//line pkg/logql/syntax/expr.y:389
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("!=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 145:
+	case 155:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:368
+//line pkg/logql/syntax/expr.y:390
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 146:
+	case 156:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:369
+//line pkg/logql/syntax/expr.y:391
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 147:
+	case 157:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:370
+//line pkg/logql/syntax/expr.y:392
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 148:
+	case 158:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:371
+//line pkg/logql/syntax/expr.y:393
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 149:
+	case 159:
 		exprDollar = exprS[exprpt-0 : exprpt+1]
-//line pkg/logql/syntax/expr.y:375
+//line pkg/logql/syntax/expr.y:397
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 150:
+	case 160:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:379
+//line pkg/logql/syntax/expr.y:401
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 151:
+	case 161:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:386
+//line pkg/logql/syntax/expr.y:408
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 152:
+	case 162:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:392
+//line pkg/logql/syntax/expr.y:414
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 		}
-	case 153:
+	case 163:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:397
+//line pkg/logql/syntax/expr.y:419
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 154:
+	case 164:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:402
+//line pkg/logql/syntax/expr.y:424
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 		}
-	case 155:
+	case 165:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:408
+//line pkg/logql/syntax/expr.y:430
 		{
 			exprVAL.BinOpModifier = exprDollar[1].BoolModifier
 		}
-	case 156:
+	case 166:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:409
+//line pkg/logql/syntax/expr.y:431
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 		}
-	case 157:
+	case 167:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:411
+//line pkg/logql/syntax/expr.y:433
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 158:
+	case 168:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:416
+//line pkg/logql/syntax/expr.y:438
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 159:
+	case 169:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:421
+//line pkg/logql/syntax/expr.y:443
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 160:
+	case 170:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:427
+//line pkg/logql/syntax/expr.y:449
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 161:
+	case 171:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:432
+//line pkg/logql/syntax/expr.y:454
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 162:
+	case 172:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line pkg/logql/syntax/expr.y:437
+//line pkg/logql/syntax/expr.y:459
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 163:
+	case 173:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:445
+//line pkg/logql/syntax/expr.y:467
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[1].str, false)
 		}
-	case 164:
+	case 174:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:446
+//line pkg/logql/syntax/expr.y:468
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, false)
 		}
-	case 165:
+	case 175:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:447
+//line pkg/logql/syntax/expr.y:469
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, true)
 		}
-	case 166:
+	case 176:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:451
+//line pkg/logql/syntax/expr.y:473
 		{
 			exprVAL.VectorExpr = NewVectorExpr(exprDollar[3].str)
 		}
-	case 167:
+	case 177:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:454
+//line pkg/logql/syntax/expr.y:476
 		{
 			exprVAL.Vector = OpTypeVector
 		}
-	case 168:
+	case 178:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:458
+//line pkg/logql/syntax/expr.y:480
 		{
 			exprVAL.VectorOp = OpTypeSum
 		}
-	case 169:
+	case 179:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:459
+//line pkg/logql/syntax/expr.y:481
 		{
 			exprVAL.VectorOp = OpTypeAvg
 		}
-	case 170:
+	case 180:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:460
+//line pkg/logql/syntax/expr.y:482
 		{
 			exprVAL.VectorOp = OpTypeCount
 		}
-	case 171:
+	case 181:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:461
+//line pkg/logql/syntax/expr.y:483
 		{
 			exprVAL.VectorOp = OpTypeMax
 		}
-	case 172:
+	case 182:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:462
+//line pkg/logql/syntax/expr.y:484
 		{
 			exprVAL.VectorOp = OpTypeMin
 		}
-	case 173:
+	case 183:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:463
+//line pkg/logql/syntax/expr.y:485
 		{
 			exprVAL.VectorOp = OpTypeStddev
 		}
-	case 174:
+	case 184:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:464
+//line pkg/logql/syntax/expr.y:486
 		{
 			exprVAL.VectorOp = OpTypeStdvar
 		}
-	case 175:
+	case 185:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:465
+//line pkg/logql/syntax/expr.y:487
 		{
 			exprVAL.VectorOp = OpTypeBottomK
 		}
-	case 176:
+	case 186:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:466
+//line pkg/logql/syntax/expr.y:488
 		{
 			exprVAL.VectorOp = OpTypeTopK
 		}
-	case 177:
+	case 187:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:489
+		{
+			exprVAL.VectorOp = OpTypeApproxTopK
+		}
+	case 188:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:490
+		{
+			exprVAL.VectorOp = OpTypeSort
+		}
+	case 189:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line pkg/logql/syntax/expr.y:491
+		{
+			exprVAL.VectorOp = OpTypeSortDesc
+		}
+	case 190:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:470
+//line pkg/logql/syntax/expr.y:495
 		{
 			exprVAL.RangeOp = OpRangeTypeCount
 		}
-	case 178:
+	case 191:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:471
+//line pkg/logql/syntax/expr.y:496
 		{
 			exprVAL.RangeOp = OpRangeTypeRate
 		}
-	case 179:
+	case 192:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:472
+//line pkg/logql/syntax/expr.y:497
 		{
 			exprVAL.RangeOp = OpRangeTypeRateCounter
 		}
-	case 180:
+	case 193:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:473
+//line pkg/logql/syntax/expr.y:498
 		{
 			exprVAL.RangeOp = OpRangeTypeBytes
 		}
-	case 181:
+	case 194:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:474
+//line pkg/logql/syntax/expr.y:499
 		{
 			exprVAL.RangeOp = OpRangeTypeBytesRate
 		}
-	case 182:
+	case 195:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:475
+//line pkg/logql/syntax/expr.y:500
 		{
 			exprVAL.RangeOp = OpRangeTypeAvg
 		}
-	case 183:
+	case 196:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:476
+//line pkg/logql/syntax/expr.y:501
 		{
 			exprVAL.RangeOp = OpRangeTypeSum
 		}
-	case 184:
+	case 197:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:477
+//line pkg/logql/syntax/expr.y:502
 		{
 			exprVAL.RangeOp = OpRangeTypeMin
 		}
-	case 185:
+	case 198:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:478
+//line pkg/logql/syntax/expr.y:503
 		{
 			exprVAL.RangeOp = OpRangeTypeMax
 		}
-	case 186:
+	case 199:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:479
+//line pkg/logql/syntax/expr.y:504
 		{
 			exprVAL.RangeOp = OpRangeTypeStdvar
 		}
-	case 187:
+	case 200:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:480
+//line pkg/logql/syntax/expr.y:505
 		{
 			exprVAL.RangeOp = OpRangeTypeStddev
 		}
-	case 188:
+	case 201:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:481
+//line pkg/logql/syntax/expr.y:506
 		{
 			exprVAL.RangeOp = OpRangeTypeQuantile
 		}
-	case 189:
+	case 202:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:482
+//line pkg/logql/syntax/expr.y:507
 		{
 			exprVAL.RangeOp = OpRangeTypeFirst
 		}
-	case 190:
+	case 203:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:483
+//line pkg/logql/syntax/expr.y:508
 		{
 			exprVAL.RangeOp = OpRangeTypeLast
 		}
-	case 191:
+	case 204:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:484
+//line pkg/logql/syntax/expr.y:509
 		{
 			exprVAL.RangeOp = OpRangeTypeAbsent
 		}
-	case 192:
+	case 205:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line pkg/logql/syntax/expr.y:488
+//line pkg/logql/syntax/expr.y:513
 		{
 			exprVAL.OffsetExpr = newOffsetExpr(exprDollar[2].duration)
 		}
-	case 193:
+	case 206:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line pkg/logql/syntax/expr.y:491
+//line pkg/logql/syntax/expr.y:516
 		{
 			exprVAL.Labels = []string{exprDollar[1].str}
 		}
-	case 194:
+	case 207:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:492
+//line pkg/logql/syntax/expr.y:517
 		{
 			exprVAL.Labels = append(exprDollar[1].Labels, exprDollar[3].str)
 		}
-	case 195:
+	case 208:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:496
+//line pkg/logql/syntax/expr.y:521
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: exprDollar[3].Labels}
 		}
-	case 196:
+	case 209:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line pkg/logql/syntax/expr.y:497
+//line pkg/logql/syntax/expr.y:522
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: exprDollar[3].Labels}
 		}
-	case 197:
+	case 210:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:498
+//line pkg/logql/syntax/expr.y:523
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 198:
+	case 211:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line pkg/logql/syntax/expr.y:499
+//line pkg/logql/syntax/expr.y:524
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: nil}
 		}
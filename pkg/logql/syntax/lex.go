@@ -25,6 +25,8 @@ var tokens = map[string]int{
 	"!~":           NRE,
 	"|=":           PIPE_EXACT,
 	"|~":           PIPE_MATCH,
+	"|>":           PIPE_PATTERN,
+	"!>":           NPA,
 	OpPipe:         PIPE,
 	OpUnwrap:       UNWRAP,
 	"(":            OPEN_PARENTHESIS,
@@ -59,16 +61,21 @@ var tokens = map[string]int{
 	OpTypeLTE:   LTE,
 
 	// parsers
-	OpParserTypeJSON:    JSON,
-	OpParserTypeRegexp:  REGEXP,
-	OpParserTypeLogfmt:  LOGFMT,
-	OpParserTypeUnpack:  UNPACK,
-	OpParserTypePattern: PATTERN,
+	OpParserTypeJSON:     JSON,
+	OpParserTypeRegexp:   REGEXP,
+	OpParserTypeLogfmt:   LOGFMT,
+	OpParserTypeUnpack:   UNPACK,
+	OpParserTypePattern:  PATTERN,
+	OpParserTypeMetadata: METADATA,
 
 	// fmt
 	OpFmtLabel: LABEL_FMT,
 	OpFmtLine:  LINE_FMT,
 
+	// label manipulation
+	OpDrop: DROP,
+	OpKeep: KEEP,
+
 	// filter functions
 	OpFilterIP: IP,
 }
@@ -94,16 +101,19 @@ var functionTokens = map[string]int{
 	OpTypeVector:           VECTOR,
 
 	// vec ops
-	OpTypeSum:      SUM,
-	OpTypeAvg:      AVG,
-	OpTypeMax:      MAX,
-	OpTypeMin:      MIN,
-	OpTypeCount:    COUNT,
-	OpTypeStddev:   STDDEV,
-	OpTypeStdvar:   STDVAR,
-	OpTypeBottomK:  BOTTOMK,
-	OpTypeTopK:     TOPK,
-	OpLabelReplace: LABEL_REPLACE,
+	OpTypeSum:        SUM,
+	OpTypeAvg:        AVG,
+	OpTypeMax:        MAX,
+	OpTypeMin:        MIN,
+	OpTypeCount:      COUNT,
+	OpTypeStddev:     STDDEV,
+	OpTypeStdvar:     STDVAR,
+	OpTypeBottomK:    BOTTOMK,
+	OpTypeTopK:       TOPK,
+	OpTypeApproxTopK: APPROX_TOPK,
+	OpTypeSort:       SORT,
+	OpTypeSortDesc:   SORT_DESC,
+	OpLabelReplace:   LABEL_REPLACE,
 
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
@@ -186,6 +196,23 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		return 0
 	}
 
+	// scanning parser flags, e.g. --max-depth
+	if r == '-' && l.Peek() == '-' {
+		// create a copy to check that the flag name follows without advancing.
+		sc := l.Scanner
+		sc.Next()
+		if unicode.IsLetter(sc.Peek()) {
+			l.Next()
+			l.builder.Reset()
+			_, _ = l.builder.WriteString("--")
+			for r := l.Peek(); unicode.IsLetter(r) || r == '-'; r = l.Peek() {
+				_, _ = l.builder.WriteRune(l.Next())
+			}
+			lval.str = l.builder.String()
+			return PARSER_FLAG
+		}
+	}
+
 	tokenText := l.TokenText()
 	tokenNext := tokenText + string(l.Peek())
 	if tok, ok := functionTokens[tokenNext]; ok {
@@ -300,6 +300,26 @@ func TestParse(t *testing.T) {
 				Groups:  []string{"bar"},
 			}, NewStringLabelFilter("10")),
 		},
+		{
+			in: `approx_topk(10,count_over_time({ foo = "bar" }[5h]))`,
+			exp: mustNewVectorAggregationExpr(&RangeAggregationExpr{
+				Left: &LogRange{
+					Left:     &MatchersExpr{Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+					Interval: 5 * time.Hour,
+				},
+				Operation: "count_over_time",
+			}, "approx_topk", nil, NewStringLabelFilter("10")),
+		},
+		{
+			in: `sort_desc(count_over_time({ foo = "bar" }[5h]))`,
+			exp: mustNewVectorAggregationExpr(&RangeAggregationExpr{
+				Left: &LogRange{
+					Left:     &MatchersExpr{Mts: []*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}},
+					Interval: 5 * time.Hour,
+				},
+				Operation: "count_over_time",
+			}, "sort_desc", nil, nil),
+		},
 		{
 			in: `bottomk(30 ,sum(rate({ foo = "bar" }[5h])) by (foo))`,
 			exp: mustNewVectorAggregationExpr(mustNewVectorAggregationExpr(&RangeAggregationExpr{
@@ -385,6 +405,18 @@ func TestParse(t *testing.T) {
 				},
 			),
 		},
+		{
+			in: `{foo="bar"} |> "<_> error <_>" !> "<_> timeout"`,
+			exp: &PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				MultiStages: MultiStageExpr{
+					newNestedLineFilterExpr(
+						newLineFilterExpr(labels.MatchEqual, OpFilterPattern, "<_> error <_>"),
+						newLineFilterExpr(labels.MatchNotEqual, OpFilterPattern, "<_> timeout"),
+					),
+				},
+			},
+		},
 		{
 			in: `{foo="bar"} |= ip("123.123.123.123")`,
 			exp: newPipelineExpr(
@@ -612,6 +644,22 @@ func TestParse(t *testing.T) {
 			in:  `topk(count_over_time({ foo = "bar" }[5h]))`,
 			err: logqlmodel.NewParseError("parameter required for operation topk", 0, 0),
 		},
+		{
+			in:  `approx_topk(count_over_time({ foo = "bar" }[5h]))`,
+			err: logqlmodel.NewParseError("parameter required for operation approx_topk", 0, 0),
+		},
+		{
+			in:  `approx_topk(10,count_over_time({ foo = "bar" }[5h])) by (foo)`,
+			err: logqlmodel.NewParseError("grouping not allowed for approx_topk aggregation", 0, 0),
+		},
+		{
+			in:  `sort(10,count_over_time({ foo = "bar" }[5h]))`,
+			err: logqlmodel.NewParseError("unsupported parameter for operation sort(10,", 0, 0),
+		},
+		{
+			in:  `sort_desc(count_over_time({ foo = "bar" }[5h])) by (foo)`,
+			err: logqlmodel.NewParseError("grouping not allowed for sort_desc aggregation", 0, 0),
+		},
 		{
 			in:  `bottomk(he,count_over_time({ foo = "bar" }[5h]))`,
 			err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 9),
@@ -1375,6 +1423,18 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			in: `{app="foo"} | metadata | trace_id="3c0e3dcd33e7"`,
+			exp: &PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+				MultiStages: MultiStageExpr{
+					newLabelParserExpr(OpParserTypeMetadata, ""),
+					&LabelFilterExpr{
+						LabelFilterer: log.NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "3c0e3dcd33e7")),
+					},
+				},
+			},
+		},
 		{
 			in: `{app="foo"} |= "bar" | json | (duration > 1s or status!= 200) and method!="POST"`,
 			exp: &PipelineExpr{
@@ -2987,6 +3047,56 @@ func TestParse(t *testing.T) {
 				Operation: "count_over_time",
 			},
 		},
+		{
+			in: `sum by (app) (count_over_time({app="foo"} | logfmt | drop level, __error__ | keep app,status [5m]))`,
+			exp: &VectorAggregationExpr{
+				Left: newRangeAggregationExpr(
+					newLogRange(&PipelineExpr{
+						Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+						MultiStages: MultiStageExpr{
+							newLabelParserExpr(OpParserTypeLogfmt, ""),
+							newDropLabelsExpr([]string{"level", "__error__"}),
+							newKeepLabelsExpr([]string{"app", "status"}),
+						},
+					},
+						5*time.Minute,
+						nil, nil),
+					OpRangeTypeCount,
+					nil,
+					nil,
+				),
+				Grouping:  &Grouping{Groups: []string{"app"}},
+				Operation: OpTypeSum,
+			},
+		},
+		{
+			in: `{app="foo"} | json --max-keys=100 --max-depth=2`,
+			exp: newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStageExpr{
+					newJSONParserExpr(log.JSONParserLimits{MaxDepth: 2, MaxKeys: 100}),
+				},
+			),
+		},
+		{
+			in:  `{app="foo"} | json --max-depth=0`,
+			exp: nil,
+			err: logqlmodel.NewParseError("invalid value for parser flag --max-depth: 0, expecting a positive integer", 0, 0),
+		},
+		{
+			in:  `{app="foo"} | json --strict=1`,
+			exp: nil,
+			err: logqlmodel.NewParseError("parser flag --strict not supported for json parser", 0, 0),
+		},
+		{
+			in:  `1 --1`,
+			exp: mustNewBinOpExpr(OpTypeSub, &BinOpOptions{}, &LiteralExpr{Val: 1}, &LiteralExpr{Val: -1}),
+		},
+		{
+			in:  `{app="foo"} | drop`,
+			exp: nil,
+			err: logqlmodel.NewParseError("syntax error: unexpected $end, expecting IDENTIFIER", 1, 19),
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := ParseExpr(tc.in)
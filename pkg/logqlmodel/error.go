@@ -87,6 +87,13 @@ func NewSeriesLimitError(limit int) *LimitError {
 	}
 }
 
+// NewLimitError returns an error for a limit reached while evaluating a query.
+func NewLimitError(msg string) *LimitError {
+	return &LimitError{
+		error: errors.New(msg),
+	}
+}
+
 // Is allows to use errors.Is(err,ErrLimit) on this error.
 func (e LimitError) Is(target error) bool {
 	return target == ErrLimit
@@ -167,6 +167,7 @@ func (r *Result) ComputeSummary(execTime time.Duration, queueTime time.Duration,
 func (s *Store) Merge(m Store) {
 	s.TotalChunksRef += m.TotalChunksRef
 	s.TotalChunksDownloaded += m.TotalChunksDownloaded
+	s.TotalChunksDownloadedBytes += m.TotalChunksDownloadedBytes
 	s.ChunksDownloadTime += m.ChunksDownloadTime
 	s.Chunk.HeadChunkBytes += m.Chunk.HeadChunkBytes
 	s.Chunk.HeadChunkLines += m.Chunk.HeadChunkLines
@@ -236,6 +237,10 @@ func (r Result) TotalChunksRef() int64 {
 	return r.Querier.Store.TotalChunksRef + r.Ingester.Store.TotalChunksRef
 }
 
+func (r Result) TotalChunksDownloadedBytes() int64 {
+	return r.Querier.Store.TotalChunksDownloadedBytes + r.Ingester.Store.TotalChunksDownloadedBytes
+}
+
 func (r Result) TotalDecompressedBytes() int64 {
 	return r.Querier.Store.Chunk.DecompressedBytes + r.Ingester.Store.Chunk.DecompressedBytes
 }
@@ -289,6 +294,10 @@ func (c *Context) AddChunksDownloaded(i int64) {
 	atomic.AddInt64(&c.store.TotalChunksDownloaded, i)
 }
 
+func (c *Context) AddChunksDownloadedBytes(i int64) {
+	atomic.AddInt64(&c.store.TotalChunksDownloadedBytes, i)
+}
+
 func (c *Context) AddChunksRef(i int64) {
 	atomic.AddInt64(&c.store.TotalChunksRef, i)
 }
@@ -381,6 +390,7 @@ func (r Result) Log(log log.Logger) {
 		"Ingester.TotalLinesSent", r.Ingester.TotalLinesSent,
 		"Ingester.TotalChunksRef", r.Ingester.Store.TotalChunksRef,
 		"Ingester.TotalChunksDownloaded", r.Ingester.Store.TotalChunksDownloaded,
+		"Ingester.TotalChunksDownloadedBytes", humanize.Bytes(uint64(r.Ingester.Store.TotalChunksDownloadedBytes)),
 		"Ingester.ChunksDownloadTime", time.Duration(r.Ingester.Store.ChunksDownloadTime),
 		"Ingester.HeadChunkBytes", humanize.Bytes(uint64(r.Ingester.Store.Chunk.HeadChunkBytes)),
 		"Ingester.HeadChunkLines", r.Ingester.Store.Chunk.HeadChunkLines,
@@ -391,6 +401,7 @@ func (r Result) Log(log log.Logger) {
 
 		"Querier.TotalChunksRef", r.Querier.Store.TotalChunksRef,
 		"Querier.TotalChunksDownloaded", r.Querier.Store.TotalChunksDownloaded,
+		"Querier.TotalChunksDownloadedBytes", humanize.Bytes(uint64(r.Querier.Store.TotalChunksDownloadedBytes)),
 		"Querier.ChunksDownloadTime", time.Duration(r.Querier.Store.ChunksDownloadTime),
 		"Querier.HeadChunkBytes", humanize.Bytes(uint64(r.Querier.Store.Chunk.HeadChunkBytes)),
 		"Querier.HeadChunkLines", r.Querier.Store.Chunk.HeadChunkLines,
@@ -395,6 +395,8 @@ type Store struct {
 	// Time spent fetching chunks in nanoseconds.
 	ChunksDownloadTime int64 `protobuf:"varint,3,opt,name=chunksDownloadTime,proto3" json:"chunksDownloadTime"`
 	Chunk              Chunk `protobuf:"bytes,4,opt,name=chunk,proto3" json:"chunk"`
+	// Total bytes of chunks fetched.
+	TotalChunksDownloadedBytes int64 `protobuf:"varint,5,opt,name=totalChunksDownloadedBytes,proto3" json:"totalChunksDownloadedBytes"`
 }
 
 func (m *Store) Reset()      { *m = Store{} }
@@ -457,6 +459,13 @@ func (m *Store) GetChunk() Chunk {
 	return Chunk{}
 }
 
+func (m *Store) GetTotalChunksDownloadedBytes() int64 {
+	if m != nil {
+		return m.TotalChunksDownloadedBytes
+	}
+	return 0
+}
+
 type Chunk struct {
 	// Total bytes processed but was already in memory. (found in the headchunk)
 	HeadChunkBytes int64 `protobuf:"varint,4,opt,name=headChunkBytes,proto3" json:"headChunkBytes"`
@@ -643,66 +652,67 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 932 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0x4b, 0x6f, 0xe4, 0x44,
-	0x10, 0x1e, 0xcf, 0xc4, 0x33, 0xd9, 0x26, 0xaf, 0xed, 0xdd, 0x65, 0x0d, 0x48, 0x76, 0x34, 0xa7,
-	0x48, 0x40, 0x46, 0x3c, 0x24, 0x04, 0x62, 0x25, 0xe4, 0x2c, 0x2b, 0x45, 0x5a, 0xc4, 0x52, 0x81,
-	0x0b, 0x37, 0x8f, 0xdd, 0x99, 0x58, 0xf1, 0xb8, 0x13, 0x3f, 0x60, 0xf7, 0xc6, 0x8d, 0x23, 0xfc,
-	0x06, 0xc4, 0x81, 0x0b, 0xff, 0x23, 0xc7, 0x1c, 0xf7, 0x64, 0x91, 0xc9, 0x05, 0xf9, 0x14, 0x89,
-	0x3f, 0x80, 0xba, 0xba, 0xc7, 0x76, 0x7b, 0x3c, 0xd2, 0x5e, 0xa6, 0xab, 0xbe, 0xaf, 0xbe, 0x7e,
-	0x56, 0xd5, 0x98, 0xec, 0x5f, 0x9c, 0xcf, 0x26, 0x11, 0x9f, 0x5d, 0x46, 0x73, 0x1e, 0xb0, 0x68,
-	0x92, 0x66, 0x5e, 0x96, 0xca, 0xdf, 0xc3, 0x8b, 0x84, 0x67, 0x9c, 0x9a, 0xe8, 0xbc, 0xfb, 0xe1,
-	0x2c, 0xcc, 0xce, 0xf2, 0xe9, 0xa1, 0xcf, 0xe7, 0x93, 0x19, 0x9f, 0xf1, 0x09, 0xb2, 0xd3, 0xfc,
-	0x14, 0x3d, 0x74, 0xd0, 0x92, 0xaa, 0xf1, 0x7f, 0x06, 0x19, 0x02, 0x4b, 0xf3, 0x28, 0xa3, 0x9f,
-	0x93, 0x51, 0x9a, 0xcf, 0xe7, 0x5e, 0xf2, 0xca, 0x32, 0xf6, 0x8d, 0x83, 0xb7, 0x3e, 0xde, 0x39,
-	0x94, 0xf3, 0x9f, 0x48, 0xd4, 0xdd, 0xbd, 0x2a, 0x9c, 0x5e, 0x59, 0x38, 0xcb, 0x30, 0x58, 0x1a,
-	0x42, 0x7a, 0x99, 0xb3, 0x24, 0x64, 0x89, 0xd5, 0xd7, 0xa4, 0xdf, 0x49, 0xb4, 0x96, 0xaa, 0x30,
-	0x58, 0x1a, 0xf4, 0x09, 0xd9, 0x0c, 0xe3, 0x19, 0x4b, 0x33, 0x96, 0x58, 0x03, 0xd4, 0xee, 0x2a,
-	0xed, 0xb1, 0x82, 0xdd, 0x3d, 0x25, 0xae, 0x02, 0xa1, 0xb2, 0xe8, 0xa7, 0x64, 0xe8, 0x7b, 0xfe,
-	0x19, 0x4b, 0xad, 0x0d, 0x14, 0x6f, 0x2b, 0xf1, 0x11, 0x82, 0xee, 0xb6, 0x92, 0x9a, 0x18, 0x04,
-	0x2a, 0x76, 0xfc, 0xa7, 0x41, 0x86, 0x32, 0x82, 0x7e, 0x44, 0x4c, 0xff, 0x2c, 0x8f, 0xcf, 0xd5,
-	0x99, 0xb7, 0x9a, 0xfa, 0x86, 0x5c, 0x84, 0x80, 0x1c, 0x84, 0x24, 0x8c, 0x03, 0xf6, 0x52, 0x9d,
-	0x75, 0x8d, 0x04, 0x43, 0x40, 0x0e, 0x62, 0x9b, 0x09, 0xde, 0xb2, 0x3a, 0xa3, 0xae, 0xd9, 0x51,
-	0x1a, 0x15, 0x03, 0x6a, 0x1c, 0xff, 0xb1, 0x41, 0x46, 0xea, 0xf2, 0xe9, 0x0f, 0xe4, 0xf1, 0xf4,
-	0x55, 0xc6, 0xd2, 0x17, 0x09, 0xf7, 0x59, 0x9a, 0xb2, 0xe0, 0x05, 0x4b, 0x4e, 0x98, 0xcf, 0xe3,
-	0x00, 0x77, 0x3e, 0x70, 0xdf, 0x2b, 0x0b, 0x67, 0x5d, 0x08, 0xac, 0x23, 0xc4, 0xb4, 0x51, 0x18,
-	0x77, 0x4e, 0xdb, 0xaf, 0xa7, 0x5d, 0x13, 0x02, 0xeb, 0x08, 0x7a, 0x4c, 0x1e, 0x64, 0x3c, 0xf3,
-	0x22, 0x57, 0x5b, 0x16, 0x0f, 0x3f, 0x70, 0x1f, 0x97, 0x85, 0xd3, 0x45, 0x43, 0x17, 0x58, 0x4d,
-	0xf5, 0x5c, 0x5b, 0x0a, 0x9f, 0xbb, 0x39, 0x95, 0x4e, 0x43, 0x17, 0x48, 0x0f, 0xc8, 0x26, 0x7b,
-	0xc9, 0xfc, 0xef, 0xc3, 0x39, 0xb3, 0xcc, 0x7d, 0xe3, 0xc0, 0x70, 0xb7, 0x44, 0x5a, 0x2d, 0x31,
-	0xa8, 0x2c, 0xfa, 0x3e, 0xb9, 0x77, 0x99, 0xb3, 0x9c, 0x61, 0xe8, 0x10, 0x43, 0xb7, 0xcb, 0xc2,
-	0xa9, 0x41, 0xa8, 0x4d, 0x7a, 0x48, 0x48, 0x9a, 0x4f, 0x65, 0x42, 0xa7, 0xd6, 0x08, 0x37, 0xb6,
-	0x53, 0x16, 0x4e, 0x03, 0x85, 0x86, 0x4d, 0x9f, 0x93, 0x87, 0xb8, 0xbb, 0xaf, 0xe3, 0x0c, 0x39,
-	0x96, 0xe5, 0x49, 0xcc, 0x02, 0x6b, 0x13, 0x95, 0x56, 0x59, 0x38, 0x9d, 0x3c, 0x74, 0xa2, 0xe3,
-	0x2f, 0xc9, 0x48, 0x55, 0x99, 0x48, 0xcc, 0x34, 0xe3, 0x09, 0x6b, 0xe5, 0xf2, 0x89, 0xc0, 0xea,
-	0xc4, 0xc4, 0x10, 0x90, 0xc3, 0xf8, 0xef, 0x3e, 0xd9, 0x3c, 0xae, 0x8b, 0x69, 0x0b, 0x97, 0x00,
-	0x26, 0xd2, 0x52, 0x26, 0x96, 0xe9, 0xee, 0x95, 0x85, 0xa3, 0xe1, 0xa0, 0x79, 0xf4, 0x19, 0xa1,
-	0xe8, 0x1f, 0x89, 0xe2, 0x48, 0xbf, 0xf1, 0x32, 0xd4, 0xca, 0xec, 0x79, 0xbb, 0x2c, 0x9c, 0x0e,
-	0x16, 0x3a, 0xb0, 0x6a, 0x75, 0x17, 0xfd, 0x54, 0x25, 0x4b, 0xbd, 0xba, 0xc2, 0x41, 0xf3, 0xe8,
-	0x17, 0x64, 0xa7, 0x7e, 0xea, 0x13, 0x16, 0x67, 0x2a, 0x33, 0x68, 0x59, 0x38, 0x2d, 0x06, 0x5a,
-	0x7e, 0x7d, 0x5f, 0xe6, 0x1b, 0xdf, 0xd7, 0x6f, 0x7d, 0x62, 0x22, 0x5f, 0x2d, 0x2c, 0x0f, 0x01,
-	0xec, 0x54, 0xd5, 0x61, 0xbd, 0x70, 0xc5, 0x40, 0xcb, 0xa7, 0xdf, 0x92, 0x47, 0x0d, 0xe4, 0x29,
-	0xff, 0x39, 0x8e, 0xb8, 0x17, 0x54, 0xb7, 0xf6, 0x4e, 0x59, 0x38, 0xdd, 0x01, 0xd0, 0x0d, 0x8b,
-	0x37, 0xf0, 0x35, 0x0c, 0x13, 0x77, 0x50, 0xbf, 0xc1, 0x2a, 0x0b, 0x1d, 0x58, 0xdd, 0x0d, 0x37,
-	0xf4, 0x36, 0x25, 0xb0, 0xee, 0x6e, 0x38, 0xfe, 0x75, 0x40, 0x4c, 0xe4, 0xc5, 0x8d, 0x9c, 0x31,
-	0x2f, 0x90, 0xc1, 0xa2, 0x88, 0x9b, 0x4f, 0xa1, 0x33, 0xd0, 0xf2, 0x35, 0x2d, 0x3e, 0x10, 0xbe,
-	0x49, 0x5b, 0x8b, 0x0c, 0xb4, 0x7c, 0x7a, 0x44, 0xee, 0x07, 0xcc, 0xe7, 0xf3, 0x8b, 0x04, 0xcb,
-	0x5c, 0x2e, 0x3d, 0x44, 0xf9, 0xa3, 0xb2, 0x70, 0x56, 0x49, 0x58, 0x85, 0xda, 0x93, 0xc8, 0x3d,
-	0x8c, 0xba, 0x27, 0x91, 0xdb, 0x58, 0x85, 0xe8, 0x13, 0xb2, 0xdb, 0xde, 0x87, 0x2c, 0xea, 0x07,
-	0x65, 0xe1, 0xb4, 0x29, 0x68, 0x03, 0x42, 0x8e, 0xcf, 0xfb, 0x34, 0xbf, 0x88, 0x42, 0xdf, 0x13,
-	0xf2, 0x7b, 0xb5, 0xbc, 0x45, 0x41, 0x1b, 0x18, 0x5f, 0xf5, 0x89, 0x89, 0x7f, 0x28, 0xa2, 0x94,
-	0x98, 0x6c, 0x13, 0xcf, 0x78, 0x1e, 0x6b, 0x85, 0xdc, 0xc4, 0x41, 0xf3, 0xe8, 0x57, 0x64, 0x8f,
-	0x2d, 0x9b, 0xcb, 0x65, 0x2e, 0x5a, 0x82, 0x4c, 0x48, 0xd3, 0x7d, 0x58, 0x16, 0xce, 0x0a, 0x07,
-	0x2b, 0x08, 0xfd, 0x8c, 0x6c, 0x2b, 0x0c, 0x6b, 0x44, 0x36, 0x7c, 0xd3, 0xbd, 0x5f, 0x16, 0x8e,
-	0x4e, 0x80, 0xee, 0x0a, 0x21, 0xfe, 0x43, 0x01, 0xf3, 0x59, 0xf8, 0x53, 0xd5, 0xde, 0x51, 0xa8,
-	0x11, 0xa0, 0xbb, 0xa2, 0x51, 0x23, 0x80, 0x95, 0x2f, 0x53, 0x06, 0x1b, 0x75, 0x05, 0x42, 0x6d,
-	0x8a, 0xfe, 0x9f, 0xc8, 0xbd, 0xca, 0xfc, 0x30, 0x65, 0xff, 0x5f, 0x62, 0x50, 0x59, 0xee, 0xf4,
-	0xfa, 0xc6, 0xee, 0xbd, 0xbe, 0xb1, 0x7b, 0x77, 0x37, 0xb6, 0xf1, 0xcb, 0xc2, 0x36, 0xfe, 0x5a,
-	0xd8, 0xc6, 0xd5, 0xc2, 0x36, 0xae, 0x17, 0xb6, 0xf1, 0xcf, 0xc2, 0x36, 0xfe, 0x5d, 0xd8, 0xbd,
-	0xbb, 0x85, 0x6d, 0xfc, 0x7e, 0x6b, 0xf7, 0xae, 0x6f, 0xed, 0xde, 0xeb, 0x5b, 0xbb, 0xf7, 0xe3,
-	0x07, 0xcd, 0x6f, 0xaf, 0xc4, 0x3b, 0xf5, 0x62, 0x6f, 0x12, 0xf1, 0xf3, 0x70, 0xd2, 0xf5, 0xf1,
-	0x36, 0x1d, 0xe2, 0x17, 0xd8, 0x27, 0xff, 0x07, 0x00, 0x00, 0xff, 0xff, 0xb1, 0xee, 0x30, 0x71,
-	0xdb, 0x09, 0x00, 0x00,
+	// 951 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0x4f, 0x6f, 0xe3, 0x44,
+	0x14, 0x8f, 0x93, 0x3a, 0xe9, 0x0e, 0xfd, 0xb7, 0xb3, 0xbb, 0xac, 0x59, 0x24, 0xbb, 0xca, 0xa9,
+	0x12, 0xd0, 0x88, 0x3f, 0x12, 0x02, 0xb1, 0x12, 0x72, 0x97, 0x95, 0x2a, 0x2d, 0x62, 0x79, 0x85,
+	0x0b, 0x07, 0x24, 0xc7, 0x99, 0xa6, 0x56, 0x1d, 0xbb, 0xf1, 0x1f, 0xd8, 0xbd, 0x71, 0xe3, 0xca,
+	0x67, 0x40, 0x1c, 0xb8, 0xf0, 0x3d, 0x7a, 0xec, 0x71, 0x4f, 0x16, 0x4d, 0x2f, 0xc8, 0x17, 0x56,
+	0xe2, 0x0b, 0xa0, 0x79, 0x33, 0xb1, 0x3d, 0xce, 0xa4, 0xda, 0x4b, 0xfc, 0xde, 0xef, 0xf7, 0x7e,
+	0x6f, 0x5e, 0xc6, 0x6f, 0xde, 0x98, 0xec, 0x5f, 0x9c, 0x4f, 0x47, 0x61, 0x3c, 0x9d, 0x87, 0xb3,
+	0x78, 0xc2, 0xc2, 0x51, 0x9a, 0x79, 0x59, 0x2a, 0x7e, 0x0f, 0x2f, 0x92, 0x38, 0x8b, 0xa9, 0x89,
+	0xce, 0xa3, 0x0f, 0xa6, 0x41, 0x76, 0x96, 0x8f, 0x0f, 0xfd, 0x78, 0x36, 0x9a, 0xc6, 0xd3, 0x78,
+	0x84, 0xec, 0x38, 0x3f, 0x45, 0x0f, 0x1d, 0xb4, 0x84, 0x6a, 0xf8, 0x9f, 0x41, 0xfa, 0xc0, 0xd2,
+	0x3c, 0xcc, 0xe8, 0x67, 0x64, 0x90, 0xe6, 0xb3, 0x99, 0x97, 0xbc, 0xb4, 0x8c, 0x7d, 0xe3, 0xe0,
+	0xad, 0x8f, 0x76, 0x0e, 0x45, 0xfe, 0x13, 0x81, 0xba, 0xbb, 0x97, 0x85, 0xd3, 0x29, 0x0b, 0x67,
+	0x19, 0x06, 0x4b, 0x83, 0x4b, 0xe7, 0x39, 0x4b, 0x02, 0x96, 0x58, 0x5d, 0x45, 0xfa, 0xad, 0x40,
+	0x6b, 0xa9, 0x0c, 0x83, 0xa5, 0x41, 0x1f, 0x93, 0xcd, 0x20, 0x9a, 0xb2, 0x34, 0x63, 0x89, 0xd5,
+	0x43, 0xed, 0xae, 0xd4, 0x1e, 0x4b, 0xd8, 0xdd, 0x93, 0xe2, 0x2a, 0x10, 0x2a, 0x8b, 0x7e, 0x42,
+	0xfa, 0xbe, 0xe7, 0x9f, 0xb1, 0xd4, 0xda, 0x40, 0xf1, 0xb6, 0x14, 0x1f, 0x21, 0xe8, 0x6e, 0x4b,
+	0xa9, 0x89, 0x41, 0x20, 0x63, 0x87, 0x7f, 0x18, 0xa4, 0x2f, 0x22, 0xe8, 0x87, 0xc4, 0xf4, 0xcf,
+	0xf2, 0xe8, 0x5c, 0xfe, 0xe7, 0xad, 0xa6, 0xbe, 0x21, 0xe7, 0x21, 0x20, 0x1e, 0x5c, 0x12, 0x44,
+	0x13, 0xf6, 0xc2, 0xea, 0xde, 0x26, 0xc1, 0x10, 0x10, 0x0f, 0x5e, 0x66, 0x82, 0xbb, 0x6c, 0xf5,
+	0x34, 0x9a, 0x1d, 0xa9, 0x91, 0x31, 0x20, 0x9f, 0xc3, 0xdf, 0x37, 0xc8, 0x40, 0x6e, 0x3e, 0xfd,
+	0x9e, 0x3c, 0x1c, 0xbf, 0xcc, 0x58, 0xfa, 0x3c, 0x89, 0x7d, 0x96, 0xa6, 0x6c, 0xf2, 0x9c, 0x25,
+	0x27, 0xcc, 0x8f, 0xa3, 0x09, 0x56, 0xde, 0x73, 0xdf, 0x2d, 0x0b, 0x67, 0x5d, 0x08, 0xac, 0x23,
+	0x78, 0xda, 0x30, 0x88, 0xb4, 0x69, 0xbb, 0x75, 0xda, 0x35, 0x21, 0xb0, 0x8e, 0xa0, 0xc7, 0xe4,
+	0x5e, 0x16, 0x67, 0x5e, 0xe8, 0x2a, 0xcb, 0xe2, 0x9f, 0xef, 0xb9, 0x0f, 0xcb, 0xc2, 0xd1, 0xd1,
+	0xa0, 0x03, 0xab, 0x54, 0xcf, 0x94, 0xa5, 0xac, 0x8d, 0x56, 0x2a, 0x95, 0x06, 0x1d, 0x48, 0x0f,
+	0xc8, 0x26, 0x7b, 0xc1, 0xfc, 0xef, 0x82, 0x19, 0xb3, 0xcc, 0x7d, 0xe3, 0xc0, 0x70, 0xb7, 0x78,
+	0x5b, 0x2d, 0x31, 0xa8, 0x2c, 0xfa, 0x1e, 0xb9, 0x33, 0xcf, 0x59, 0xce, 0x30, 0xb4, 0x8f, 0xa1,
+	0xdb, 0x65, 0xe1, 0xd4, 0x20, 0xd4, 0x26, 0x3d, 0x24, 0x24, 0xcd, 0xc7, 0xa2, 0xa1, 0x53, 0x6b,
+	0x80, 0x85, 0xed, 0x94, 0x85, 0xd3, 0x40, 0xa1, 0x61, 0xd3, 0x67, 0xe4, 0x3e, 0x56, 0xf7, 0x55,
+	0x94, 0x21, 0xc7, 0xb2, 0x3c, 0x89, 0xd8, 0xc4, 0xda, 0x44, 0xa5, 0x55, 0x16, 0x8e, 0x96, 0x07,
+	0x2d, 0x3a, 0xfc, 0x82, 0x0c, 0xe4, 0x29, 0xe3, 0x8d, 0x99, 0x66, 0x71, 0xc2, 0x5a, 0xbd, 0x7c,
+	0xc2, 0xb1, 0xba, 0x31, 0x31, 0x04, 0xc4, 0x63, 0xf8, 0x57, 0x97, 0x6c, 0x1e, 0xd7, 0x87, 0x69,
+	0x0b, 0x97, 0x00, 0xc6, 0xdb, 0x52, 0x34, 0x96, 0xe9, 0xee, 0x95, 0x85, 0xa3, 0xe0, 0xa0, 0x78,
+	0xf4, 0x29, 0xa1, 0xe8, 0x1f, 0xf1, 0xc3, 0x91, 0x7e, 0xed, 0x65, 0xa8, 0x15, 0xdd, 0xf3, 0x76,
+	0x59, 0x38, 0x1a, 0x16, 0x34, 0x58, 0xb5, 0xba, 0x8b, 0x7e, 0x2a, 0x9b, 0xa5, 0x5e, 0x5d, 0xe2,
+	0xa0, 0x78, 0xf4, 0x73, 0xb2, 0x53, 0xbf, 0xea, 0x13, 0x16, 0x65, 0xb2, 0x33, 0x68, 0x59, 0x38,
+	0x2d, 0x06, 0x5a, 0x7e, 0xbd, 0x5f, 0xe6, 0x1b, 0xef, 0xd7, 0xbf, 0x5d, 0x62, 0x22, 0x5f, 0x2d,
+	0x2c, 0xfe, 0x04, 0xb0, 0x53, 0xcb, 0x68, 0x2d, 0x5c, 0x31, 0xd0, 0xf2, 0xe9, 0x37, 0xe4, 0x41,
+	0x03, 0x79, 0x12, 0xff, 0x1c, 0x85, 0xb1, 0x37, 0xa9, 0x76, 0xed, 0x9d, 0xb2, 0x70, 0xf4, 0x01,
+	0xa0, 0x87, 0xf9, 0x3b, 0xf0, 0x15, 0x0c, 0x1b, 0xb7, 0x57, 0xbf, 0x83, 0x55, 0x16, 0x34, 0x58,
+	0x3d, 0x0d, 0x37, 0xd4, 0x31, 0xc5, 0xb1, 0x35, 0xd3, 0xf0, 0x47, 0xf2, 0x48, 0x5b, 0x13, 0x1e,
+	0x63, 0xdc, 0xd9, 0x9e, 0x6b, 0x97, 0x85, 0x73, 0x4b, 0x14, 0xdc, 0xc2, 0x0d, 0x7f, 0xed, 0x11,
+	0x13, 0x19, 0xbe, 0xe3, 0x67, 0xcc, 0x9b, 0x88, 0x62, 0x30, 0x7b, 0xe3, 0x55, 0xab, 0x0c, 0xb4,
+	0x7c, 0x45, 0x8b, 0x0d, 0x60, 0x99, 0x1a, 0x2d, 0x32, 0xd0, 0xf2, 0xe9, 0x11, 0xb9, 0x3b, 0x61,
+	0x7e, 0x3c, 0xbb, 0x48, 0x70, 0x8c, 0x88, 0xa5, 0xfb, 0x28, 0x7f, 0x50, 0x16, 0xce, 0x2a, 0x09,
+	0xab, 0x50, 0x3b, 0x89, 0xa8, 0x61, 0xa0, 0x4f, 0x22, 0xca, 0x58, 0x85, 0xe8, 0x63, 0xb2, 0xdb,
+	0xae, 0x43, 0x0c, 0x8d, 0x7b, 0x65, 0xe1, 0xb4, 0x29, 0x68, 0x03, 0x5c, 0x8e, 0x1b, 0xfd, 0x24,
+	0xbf, 0x08, 0x03, 0xdf, 0xe3, 0xf2, 0x3b, 0xb5, 0xbc, 0x45, 0x41, 0x1b, 0x18, 0x5e, 0x76, 0x89,
+	0x89, 0x17, 0x16, 0x3f, 0xaa, 0x4c, 0x8c, 0xa1, 0xa7, 0x71, 0x1e, 0x29, 0x83, 0xa2, 0x89, 0x83,
+	0xe2, 0xd1, 0x2f, 0xc9, 0x1e, 0x5b, 0x0e, 0xaf, 0x79, 0xce, 0xd2, 0x4c, 0x36, 0xbc, 0xe9, 0xde,
+	0x2f, 0x0b, 0x67, 0x85, 0x83, 0x15, 0x84, 0x7e, 0x4a, 0xb6, 0x25, 0x86, 0x67, 0x50, 0x5c, 0x28,
+	0xa6, 0x7b, 0xb7, 0x2c, 0x1c, 0x95, 0x00, 0xd5, 0xe5, 0x42, 0xbc, 0x01, 0x81, 0xf9, 0x2c, 0xf8,
+	0xa9, 0xba, 0x3e, 0x50, 0xa8, 0x10, 0xa0, 0xba, 0xfc, 0x22, 0x40, 0x00, 0x27, 0x8b, 0x68, 0x19,
+	0xbc, 0x08, 0x2a, 0x10, 0x6a, 0x93, 0xdf, 0x2f, 0x89, 0xa8, 0x55, 0xf4, 0x87, 0x29, 0xee, 0x97,
+	0x25, 0x06, 0x95, 0xe5, 0x8e, 0xaf, 0xae, 0xed, 0xce, 0xab, 0x6b, 0xbb, 0xf3, 0xfa, 0xda, 0x36,
+	0x7e, 0x59, 0xd8, 0xc6, 0x9f, 0x0b, 0xdb, 0xb8, 0x5c, 0xd8, 0xc6, 0xd5, 0xc2, 0x36, 0xfe, 0x5e,
+	0xd8, 0xc6, 0x3f, 0x0b, 0xbb, 0xf3, 0x7a, 0x61, 0x1b, 0xbf, 0xdd, 0xd8, 0x9d, 0xab, 0x1b, 0xbb,
+	0xf3, 0xea, 0xc6, 0xee, 0xfc, 0xf0, 0x7e, 0xf3, 0xdb, 0x2e, 0xf1, 0x4e, 0xbd, 0xc8, 0x1b, 0x85,
+	0xf1, 0x79, 0x30, 0xd2, 0x7d, 0x1c, 0x8e, 0xfb, 0xf8, 0x85, 0xf7, 0xf1, 0xff, 0x03, 0x00, 0xfe,
+	0xab, 0x9a, 0xdd, 0x3b, 0x0a, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -904,6 +914,9 @@ func (this *Store) Equal(that interface{}) bool {
 	if !this.Chunk.Equal(&that1.Chunk) {
 		return false
 	}
+	if this.TotalChunksDownloadedBytes != that1.TotalChunksDownloadedBytes {
+		return false
+	}
 	return true
 }
 func (this *Chunk) Equal(that interface{}) bool {
@@ -1054,12 +1067,13 @@ func (this *Store) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 8)
+	s := make([]string, 0, 9)
 	s = append(s, "&stats.Store{")
 	s = append(s, "TotalChunksRef: "+fmt.Sprintf("%#v", this.TotalChunksRef)+",\n")
 	s = append(s, "TotalChunksDownloaded: "+fmt.Sprintf("%#v", this.TotalChunksDownloaded)+",\n")
 	s = append(s, "ChunksDownloadTime: "+fmt.Sprintf("%#v", this.ChunksDownloadTime)+",\n")
 	s = append(s, "Chunk: "+strings.Replace(this.Chunk.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "TotalChunksDownloadedBytes: "+fmt.Sprintf("%#v", this.TotalChunksDownloadedBytes)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1388,6 +1402,11 @@ func (m *Store) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.TotalChunksDownloadedBytes != 0 {
+		i = encodeVarintStats(dAtA, i, uint64(m.TotalChunksDownloadedBytes))
+		i--
+		dAtA[i] = 0x28
+	}
 	{
 		size, err := m.Chunk.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -1649,6 +1668,9 @@ func (m *Store) Size() (n int) {
 	}
 	l = m.Chunk.Size()
 	n += 1 + l + sovStats(uint64(l))
+	if m.TotalChunksDownloadedBytes != 0 {
+		n += 1 + sovStats(uint64(m.TotalChunksDownloadedBytes))
+	}
 	return n
 }
 
@@ -1787,6 +1809,7 @@ func (this *Store) String() string {
 		`TotalChunksDownloaded:` + fmt.Sprintf("%v", this.TotalChunksDownloaded) + `,`,
 		`ChunksDownloadTime:` + fmt.Sprintf("%v", this.ChunksDownloadTime) + `,`,
 		`Chunk:` + strings.Replace(strings.Replace(this.Chunk.String(), "Chunk", "Chunk", 1), `&`, ``, 1) + `,`,
+		`TotalChunksDownloadedBytes:` + fmt.Sprintf("%v", this.TotalChunksDownloadedBytes) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1996,10 +2019,7 @@ func (m *Result) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2148,10 +2168,7 @@ func (m *Caches) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2337,10 +2354,7 @@ func (m *Summary) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2423,10 +2437,7 @@ func (m *Querier) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2585,10 +2596,7 @@ func (m *Ingester) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2722,16 +2730,32 @@ func (m *Store) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalChunksDownloadedBytes", wireType)
+			}
+			m.TotalChunksDownloadedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalChunksDownloadedBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -2895,10 +2919,7 @@ func (m *Chunk) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -3062,10 +3083,7 @@ func (m *Cache) Unmarshal(dAtA []byte) error {
 			if err != nil {
 				return err
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthStats
-			}
-			if (iNdEx + skippy) < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthStats
 			}
 			if (iNdEx + skippy) > l {
@@ -3083,6 +3101,7 @@ func (m *Cache) Unmarshal(dAtA []byte) error {
 func skipStats(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
+	depth := 0
 	for iNdEx < l {
 		var wire uint64
 		for shift := uint(0); ; shift += 7 {
@@ -3114,10 +3133,8 @@ func skipStats(dAtA []byte) (n int, err error) {
 					break
 				}
 			}
-			return iNdEx, nil
 		case 1:
 			iNdEx += 8
-			return iNdEx, nil
 		case 2:
 			var length int
 			for shift := uint(0); ; shift += 7 {
@@ -3138,55 +3155,30 @@ func skipStats(dAtA []byte) (n int, err error) {
 				return 0, ErrInvalidLengthStats
 			}
 			iNdEx += length
-			if iNdEx < 0 {
-				return 0, ErrInvalidLengthStats
-			}
-			return iNdEx, nil
 		case 3:
-			for {
-				var innerWire uint64
-				var start int = iNdEx
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return 0, ErrIntOverflowStats
-					}
-					if iNdEx >= l {
-						return 0, io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					innerWire |= (uint64(b) & 0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				innerWireType := int(innerWire & 0x7)
-				if innerWireType == 4 {
-					break
-				}
-				next, err := skipStats(dAtA[start:])
-				if err != nil {
-					return 0, err
-				}
-				iNdEx = start + next
-				if iNdEx < 0 {
-					return 0, ErrInvalidLengthStats
-				}
-			}
-			return iNdEx, nil
+			depth++
 		case 4:
-			return iNdEx, nil
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupStats
+			}
+			depth--
 		case 5:
 			iNdEx += 4
-			return iNdEx, nil
 		default:
 			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
 		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthStats
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
 	}
-	panic("unreachable")
+	return 0, io.ErrUnexpectedEOF
 }
 
 var (
-	ErrInvalidLengthStats = fmt.Errorf("proto: negative length found during unmarshaling")
-	ErrIntOverflowStats   = fmt.Errorf("proto: integer overflow")
+	ErrInvalidLengthStats        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowStats          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupStats = fmt.Errorf("proto: unexpected end of group")
 )
@@ -27,6 +27,7 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/grafana/loki/pkg/distributor"
+	"github.com/grafana/loki/pkg/distributor/kafka"
 	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/loki/common"
@@ -48,12 +49,14 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletion"
 	"github.com/grafana/loki/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
+	"github.com/grafana/loki/pkg/tenantusage"
 	"github.com/grafana/loki/pkg/tracing"
 	"github.com/grafana/loki/pkg/usagestats"
 	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/util/fakeauth"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	serverutil "github.com/grafana/loki/pkg/util/server"
+	"github.com/grafana/loki/pkg/util/tenantremap"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -93,6 +96,8 @@ type Config struct {
 	CompactorConfig  compactor.Config         `yaml:"compactor,omitempty"`
 	QueryScheduler   scheduler.Config         `yaml:"query_scheduler"`
 	UsageReport      usagestats.Config        `yaml:"analytics"`
+	KafkaConsumer    kafka.Config             `yaml:"kafka_consumer"`
+	TenantUsage      tenantusage.Config       `yaml:"tenant_usage"`
 }
 
 // RegisterFlags registers flag.
@@ -134,6 +139,8 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.CompactorConfig.RegisterFlags(f)
 	c.QueryScheduler.RegisterFlags(f)
 	c.UsageReport.RegisterFlags(f)
+	c.KafkaConsumer.RegisterFlagsWithPrefix("kafka-consumer", f)
+	c.TenantUsage.RegisterFlags(f)
 }
 
 func (c *Config) registerServerFlagsWithChangedDefaultValues(fs *flag.FlagSet) {
@@ -193,6 +200,18 @@ func (c *Config) Validate() error {
 	if err := c.Ingester.Validate(); err != nil {
 		return errors.Wrap(err, "invalid ingester config")
 	}
+	if err := c.QueryScheduler.Validate(); err != nil {
+		return errors.Wrap(err, "invalid query_scheduler config")
+	}
+	if err := c.Frontend.AuditLog.Validate(); err != nil {
+		return errors.Wrap(err, "invalid audit log config")
+	}
+	if err := c.TenantUsage.Validate(); err != nil {
+		return errors.Wrap(err, "invalid tenant_usage config")
+	}
+	if err := c.Distributor.Validate(); err != nil {
+		return errors.Wrap(err, "invalid distributor config")
+	}
 	if err := c.LimitsConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid limits config")
 	}
@@ -202,6 +221,9 @@ func (c *Config) Validate() error {
 	if err := c.StorageConfig.BoltDBShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid boltdb-shipper config")
 	}
+	if err := c.IndexGateway.Validate(); err != nil {
+		return errors.Wrap(err, "invalid index_gateway config")
+	}
 	if err := c.CompactorConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid compactor config")
 	}
@@ -268,6 +290,7 @@ type Loki struct {
 	QueryFrontEndTripperware basetripper.Tripperware
 	queryScheduler           *scheduler.Scheduler
 	usageReport              *usagestats.Reporter
+	tenantUsage              *tenantusage.Tracker
 	indexGatewayRingManager  *indexgateway.RingManager
 
 	clientMetrics       storage.ClientMetrics
@@ -296,7 +319,7 @@ func New(cfg Config) (*Loki, error) {
 func (t *Loki) setupAuthMiddleware() {
 	// Don't check auth header on TransferChunks, as we weren't originally
 	// sending it and this could cause transfers to fail on update.
-	t.HTTPAuthMiddleware = fakeauth.SetupAuthMiddleware(&t.Cfg.Server, t.Cfg.AuthEnabled,
+	authMiddleware := fakeauth.SetupAuthMiddleware(&t.Cfg.Server, t.Cfg.AuthEnabled,
 		// Also don't check auth for these gRPC methods, since single call is used for multiple users (or no user like health check).
 		[]string{
 			"/grpc.health.v1.Health/Check",
@@ -308,6 +331,11 @@ func (t *Loki) setupAuthMiddleware() {
 			"/schedulerpb.SchedulerForQuerier/QuerierLoop",
 			"/schedulerpb.SchedulerForQuerier/NotifyQuerierShutdown",
 		})
+	// The authenticated tenants are remapped to their canonical tenants, on both the write and the read paths. The
+	// runtime config is loaded after the middleware is set up, so the remap table is looked up for each request.
+	t.HTTPAuthMiddleware = middleware.Merge(authMiddleware, tenantremap.Middleware(func() map[string]string {
+		return tenantRemapFromRuntimeConfig(t.runtimeConfig)
+	}))
 }
 
 func (t *Loki) setupGRPCRecoveryMiddleware() {
@@ -519,7 +547,9 @@ func (t *Loki) setupModuleManager() error {
 	mm.RegisterModule(QueryScheduler, t.initQueryScheduler)
 	mm.RegisterModule(IndexGatewayRing, t.initIndexGatewayRing, modules.UserInvisibleModule)
 	mm.RegisterModule(UsageReport, t.initUsageReport)
+	mm.RegisterModule(TenantUsage, t.initTenantUsage, modules.UserInvisibleModule)
 	mm.RegisterModule(CacheGenerationLoader, t.initCacheGenerationLoader)
+	mm.RegisterModule(KafkaConsumer, t.initKafkaConsumer)
 
 	mm.RegisterModule(All, nil)
 	mm.RegisterModule(Read, nil)
@@ -529,25 +559,27 @@ func (t *Loki) setupModuleManager() error {
 	deps := map[string][]string{
 		Ring:                     {RuntimeConfig, Server, MemberlistKV},
 		UsageReport:              {},
+		TenantUsage:              {},
 		Overrides:                {RuntimeConfig},
 		OverridesExporter:        {Overrides, Server},
 		TenantConfigs:            {RuntimeConfig},
-		Distributor:              {Ring, Server, Overrides, TenantConfigs, UsageReport},
+		Distributor:              {Ring, Server, Overrides, TenantConfigs, UsageReport, TenantUsage},
 		Store:                    {Overrides, IndexGatewayRing},
 		Ingester:                 {Store, Server, MemberlistKV, TenantConfigs, UsageReport},
 		Querier:                  {Store, Ring, Server, IngesterQuerier, TenantConfigs, UsageReport, CacheGenerationLoader},
 		QueryFrontendTripperware: {Server, Overrides, TenantConfigs},
-		QueryFrontend:            {QueryFrontendTripperware, UsageReport, CacheGenerationLoader},
+		QueryFrontend:            {QueryFrontendTripperware, UsageReport, CacheGenerationLoader, TenantUsage},
 		QueryScheduler:           {Server, Overrides, MemberlistKV, UsageReport},
 		Ruler:                    {Ring, Server, Store, RulerStorage, IngesterQuerier, Overrides, TenantConfigs, UsageReport},
 		TableManager:             {Server, UsageReport},
 		Compactor:                {Server, Overrides, MemberlistKV, UsageReport},
 		IndexGateway:             {Server, Store, Overrides, UsageReport, MemberlistKV, IndexGatewayRing},
-		IngesterQuerier:          {Ring},
+		IngesterQuerier:          {Ring, Overrides},
 		IndexGatewayRing:         {RuntimeConfig, Server, MemberlistKV},
 		All:                      {QueryScheduler, QueryFrontend, Querier, Ingester, Distributor, Ruler, Compactor},
 		Read:                     {QueryScheduler, QueryFrontend, Querier, Ruler, Compactor, IndexGateway},
 		Write:                    {Ingester, Distributor},
+		KafkaConsumer:            {Distributor},
 		MemberlistKV:             {Server},
 	}
 
@@ -32,11 +32,15 @@ import (
 	"github.com/weaveworks/common/middleware"
 	"github.com/weaveworks/common/server"
 	"github.com/weaveworks/common/user"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
 
 	"github.com/grafana/loki/pkg/distributor"
+	"github.com/grafana/loki/pkg/distributor/kafka"
 	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/lokifrontend"
+	"github.com/grafana/loki/pkg/lokifrontend/audit"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/transport"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/v1/frontendv1pb"
@@ -61,6 +65,7 @@ import (
 	boltdb_shipper_compactor "github.com/grafana/loki/pkg/storage/stores/shipper/index/compactor"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
 	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	"github.com/grafana/loki/pkg/tenantusage"
 	"github.com/grafana/loki/pkg/usagestats"
 	"github.com/grafana/loki/pkg/util/httpreq"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -99,6 +104,8 @@ const (
 	Read                     string = "read"
 	Write                    string = "write"
 	UsageReport              string = "usage-report"
+	TenantUsage              string = "tenant-usage"
+	KafkaConsumer            string = "kafka-consumer"
 )
 
 func (t *Loki) initServer() (services.Service, error) {
@@ -278,6 +285,7 @@ func (t *Loki) initDistributor() (services.Service, error) {
 		t.tenantConfigs,
 		t.ring,
 		t.overrides,
+		t.tenantUsage,
 		prometheus.DefaultRegisterer,
 	)
 	if err != nil {
@@ -289,6 +297,7 @@ func (t *Loki) initDistributor() (services.Service, error) {
 	if !t.Cfg.isModuleEnabled(All) && !t.Cfg.isModuleEnabled(Write) && !t.Cfg.isModuleEnabled(Ingester) {
 		logproto.RegisterPusherServer(t.Server.GRPC, t.distributor)
 	}
+	collogspb.RegisterLogsServiceServer(t.Server.GRPC, t.distributor)
 
 	// If the querier module is not part of this process we need to check if multi-tenant queries are enabled.
 	// If the querier module is part of this process the querier module will configure everything.
@@ -309,9 +318,24 @@ func (t *Loki) initDistributor() (services.Service, error) {
 
 	t.Server.HTTP.Path("/api/prom/push").Methods("POST").Handler(pushHandler)
 	t.Server.HTTP.Path("/loki/api/v1/push").Methods("POST").Handler(pushHandler)
+
+	otlpPushHandler := middleware.Merge(
+		serverutil.RecoveryHTTPMiddleware,
+		t.HTTPAuthMiddleware,
+	).Wrap(http.HandlerFunc(t.distributor.OTLPPushHandler))
+	t.Server.HTTP.Path("/otlp/v1/logs").Methods("POST").Handler(otlpPushHandler)
 	return t.distributor, nil
 }
 
+func (t *Loki) initKafkaConsumer() (services.Service, error) {
+	cfg := t.Cfg.KafkaConsumer
+	// Without auth, everything is pushed to the same tenant as HTTP pushes.
+	if !t.Cfg.AuthEnabled && cfg.TenantID == "" {
+		cfg.TenantID = "fake"
+	}
+	return kafka.New(cfg, t.distributor, util_log.Logger, prometheus.DefaultRegisterer)
+}
+
 func (t *Loki) initQuerier() (services.Service, error) {
 	if t.Cfg.Ingester.QueryStoreMaxLookBackPeriod != 0 {
 		t.Cfg.Querier.IngesterQueryStoreMaxLookback = t.Cfg.Ingester.QueryStoreMaxLookBackPeriod
@@ -330,7 +354,7 @@ func (t *Loki) initQuerier() (services.Service, error) {
 	}
 
 	if t.Cfg.Querier.MultiTenantQueriesEnabled {
-		t.Querier = querier.NewMultiTenantQuerier(q, util_log.Logger)
+		t.Querier = querier.NewMultiTenantQuerier(q, t.overrides, util_log.Logger)
 		tenant.WithDefaultResolver(tenant.NewMultiResolver())
 	} else {
 		t.Querier = q
@@ -375,8 +399,9 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		"/loki/api/v1/labels":              querier.WrapQuerySpanAndTimeout("query.Label", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.LabelHandler)),
 		"/loki/api/v1/label/{name}/values": querier.WrapQuerySpanAndTimeout("query.Label", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.LabelHandler)),
 
-		"/loki/api/v1/series":      querier.WrapQuerySpanAndTimeout("query.Series", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.SeriesHandler)),
-		"/loki/api/v1/index/stats": querier.WrapQuerySpanAndTimeout("query.IndexStats", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.IndexStatsHandler)),
+		"/loki/api/v1/series":       querier.WrapQuerySpanAndTimeout("query.Series", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.SeriesHandler)),
+		"/loki/api/v1/index/stats":  querier.WrapQuerySpanAndTimeout("query.IndexStats", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.IndexStatsHandler)),
+		"/loki/api/v1/index/volume": querier.WrapQuerySpanAndTimeout("query.Volume", t.querierAPI).Wrap(http.HandlerFunc(t.querierAPI.VolumeHandler)),
 
 		"/api/prom/query": middleware.Merge(
 			httpMiddleware,
@@ -450,6 +475,29 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 	t.Server.HTTP.Methods("POST").Path("/ingester/shutdown").Handler(
 		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.ShutdownHandler)),
 	)
+	t.Server.HTTP.Methods("GET").Path("/ingester/flush_progress").Handler(
+		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.FlushProgressHandler)),
+	)
+	t.Server.HTTP.Methods("GET").Path("/ingester/series_stats").Handler(
+		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.SeriesStatsHandler)),
+	)
+	t.Server.HTTP.Methods("GET").Path("/ingester/local_tsdbs").Handler(
+		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.LocalTSDBsHandler)),
+	)
+	t.Server.HTTP.Methods("GET").Path("/ingester/tsdb_build_plan").Handler(
+		httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.TSDBBuildPlanHandler)),
+	)
+	if t.Cfg.Ingester.DangerZoneAPIEnabled {
+		t.Server.HTTP.Methods("GET", "POST", "DELETE").Path("/ingester/prepare_shutdown").Handler(
+			httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.PrepareShutdownHandler)),
+		)
+		t.Server.HTTP.Methods("GET", "POST", "PUT", "DELETE").Path("/ingester/unregister").Handler(
+			httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.UnregisterHandler)),
+		)
+		t.Server.HTTP.Methods("POST").Path("/ingester/ring/forget").Handler(
+			httpMiddleware.Wrap(http.HandlerFunc(t.Ingester.ForgetHandler)),
+		)
+	}
 	return t.Ingester, nil
 }
 
@@ -497,6 +545,8 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 	// Always set these configs
 	t.Cfg.StorageConfig.BoltDBShipperConfig.IndexGatewayClientConfig.Mode = t.Cfg.IndexGateway.Mode
 	t.Cfg.StorageConfig.TSDBShipperConfig.IndexGatewayClientConfig.Mode = t.Cfg.IndexGateway.Mode
+	t.Cfg.StorageConfig.BoltDBShipperConfig.IndexGatewayClientConfig.ShardingStrategy = t.Cfg.IndexGateway.ShardingStrategy
+	t.Cfg.StorageConfig.TSDBShipperConfig.IndexGatewayClientConfig.ShardingStrategy = t.Cfg.IndexGateway.ShardingStrategy
 	if t.Cfg.IndexGateway.Mode == indexgateway.RingMode {
 		t.Cfg.StorageConfig.BoltDBShipperConfig.IndexGatewayClientConfig.Ring = t.indexGatewayRingManager.Ring
 		t.Cfg.StorageConfig.TSDBShipperConfig.IndexGatewayClientConfig.Ring = t.indexGatewayRingManager.Ring
@@ -640,7 +690,7 @@ func (t *Loki) initStore() (_ services.Service, err error) {
 }
 
 func (t *Loki) initIngesterQuerier() (_ services.Service, err error) {
-	t.ingesterQuerier, err = querier.NewIngesterQuerier(t.Cfg.IngesterClient, t.ring, t.Cfg.Querier.ExtraQueryDelay)
+	t.ingesterQuerier, err = querier.NewIngesterQuerier(t.Cfg.IngesterClient, t.ring, t.Cfg.Querier.ExtraQueryDelay, t.overrides, t.Cfg.Querier.QueryIngestersWithin)
 	if err != nil {
 		return nil, err
 	}
@@ -745,22 +795,38 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		frontendHandler = gziphandler.GzipHandler(frontendHandler)
 	}
 
-	frontendHandler = middleware.Merge(
+	frontendMiddlewares := []middleware.Interface{
 		httpreq.ExtractQueryTagsMiddleware(),
+		httpreq.ExtractQueryPriorityMiddleware(t.Cfg.QueryScheduler.QueryPriority.Header),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
-		queryrange.StatsHTTPMiddleware,
+		queryrange.NewStatsHTTPMiddleware(t.tenantUsage),
 		serverutil.NewPrepopulateMiddleware(),
 		serverutil.ResponseJSONMiddleware(),
-	).Wrap(frontendHandler)
+	}
+	var auditLog *audit.Logger
+	if t.Cfg.Frontend.AuditLog.Enabled {
+		auditLog, err = audit.New(t.Cfg.Frontend.AuditLog, util_log.Logger, prometheus.DefaultRegisterer)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				auditLog.Stop()
+			}
+		}()
+		// The audit log needs the tenant and the parsed form of the request.
+		frontendMiddlewares = append(frontendMiddlewares, auditLog)
+	}
+	frontendHandler = middleware.Merge(frontendMiddlewares...).Wrap(frontendHandler)
 
-	var defaultHandler http.Handler
+	var defaultHandler, tailHandler http.Handler
 	// If this process also acts as a Querier we don't do any proxying of tail requests
 	if t.Cfg.Frontend.TailProxyURL != "" && !t.isModuleActive(Querier) {
 		httpMiddleware := middleware.Merge(
 			httpreq.ExtractQueryTagsMiddleware(),
 			t.HTTPAuthMiddleware,
-			queryrange.StatsHTTPMiddleware,
+			queryrange.NewStatsHTTPMiddleware(t.tenantUsage),
 		)
 		tailURL, err := url.Parse(t.Cfg.Frontend.TailProxyURL)
 		if err != nil {
@@ -784,6 +850,10 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		}
 
 		defaultHandler = httpMiddleware.Wrap(tp)
+		if t.Cfg.Frontend.TailMaxReconnects > 0 {
+			// The legacy tail responses aren't resumed, as the proxy only decodes the v1 responses.
+			tailHandler = httpMiddleware.Wrap(lokifrontend.NewTailProxy(tailURL, cfg, t.Cfg.Frontend.TailMaxReconnects, t.Cfg.Frontend.TailReconnectBackoff, t.Cfg.Frontend.TailMaxBackfill, util_log.Logger))
+		}
 	} else {
 		defaultHandler = frontendHandler
 	}
@@ -794,6 +864,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/loki/api/v1/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/series").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/index/stats").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/index/volume").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/query").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
@@ -803,7 +874,10 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	// If this process is also a Querier the Querier will register the tail endpoints.
 	if !t.isModuleActive(Querier) {
 		// defer tail endpoints to the default handler
-		t.Server.HTTP.Path("/loki/api/v1/tail").Methods("GET", "POST").Handler(defaultHandler)
+		if tailHandler == nil {
+			tailHandler = defaultHandler
+		}
+		t.Server.HTTP.Path("/loki/api/v1/tail").Methods("GET", "POST").Handler(tailHandler)
 		t.Server.HTTP.Path("/api/prom/tail").Methods("GET", "POST").Handler(defaultHandler)
 	}
 
@@ -813,6 +887,9 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 				t.stopper.Stop()
 				t.stopper = nil
 			}
+			if auditLog != nil {
+				auditLog.Stop()
+			}
 			return nil
 		}), nil
 	}
@@ -829,6 +906,9 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 		if t.stopper != nil {
 			t.stopper.Stop()
 		}
+		if auditLog != nil {
+			auditLog.Stop()
+		}
 		return nil
 	}), nil
 }
@@ -869,16 +949,34 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		return nil, err
 	}
 
-	q, err := querier.New(t.Cfg.Querier, t.Store, t.ingesterQuerier, t.overrides, deleteStore, nil)
-	if err != nil {
-		return nil, err
-	}
+	var evaluator ruler.Evaluator
+	switch t.Cfg.Ruler.Evaluation.Mode {
+	case ruler.EvalModeRemote:
+		client, err := ruler.DialQueryFrontend(t.Cfg.Ruler.Evaluation.QueryFrontend)
+		if err != nil {
+			return nil, err
+		}
 
-	engine := logql.NewEngine(t.Cfg.Querier.Engine, q, t.overrides, log.With(util_log.Logger, "component", "ruler"))
+		evaluator, err = ruler.NewRemoteEvaluator(client, log.With(util_log.Logger, "component", "ruler"))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		q, err := querier.New(t.Cfg.Querier, t.Store, t.ingesterQuerier, t.overrides, deleteStore, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		engine := logql.NewEngine(t.Cfg.Querier.Engine, q, t.overrides, log.With(util_log.Logger, "component", "ruler"))
+		evaluator, err = ruler.NewLocalEvaluator(engine)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	t.ruler, err = ruler.NewRuler(
 		t.Cfg.Ruler,
-		engine,
+		evaluator,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 		t.RulerStorage,
@@ -912,6 +1010,8 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteNamespace)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}/{groupName}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.GetRuleGroup)))
 		t.Server.HTTP.Path("/api/prom/rules/{namespace}/{groupName}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteRuleGroup)))
+		t.Server.HTTP.Path("/api/prom/rules/{namespace}/{groupName}/versions").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRuleGroupVersions)))
+		t.Server.HTTP.Path("/api/prom/rules/{namespace}/{groupName}/versions/{version}/restore").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RestoreRuleGroupVersion)))
 
 		// Ruler API Routes
 		t.Server.HTTP.Path("/loki/api/v1/rules").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRules)))
@@ -920,6 +1020,8 @@ func (t *Loki) initRuler() (_ services.Service, err error) {
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteNamespace)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.GetRuleGroup)))
 		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}").Methods("DELETE").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.DeleteRuleGroup)))
+		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}/versions").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.ListRuleGroupVersions)))
+		t.Server.HTTP.Path("/loki/api/v1/rules/{namespace}/{groupName}/versions/{version}/restore").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.rulerAPI.RestoreRuleGroupVersion)))
 	}
 
 	t.ruler.AddListener(deleteRequestsStoreListener(deleteStore))
@@ -977,7 +1079,7 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		return nil, err
 	}
 
-	objectClient, err := storage.NewObjectClient(t.Cfg.CompactorConfig.SharedStoreType, t.Cfg.StorageConfig, t.clientMetrics)
+	objectClient, err := storage.NewObjectClient(t.Cfg.CompactorConfig.SharedStoreType, t.Cfg.StorageConfig, t.overrides, t.clientMetrics)
 	if err != nil {
 		return nil, err
 	}
@@ -996,10 +1098,11 @@ func (t *Loki) initCompactor() (services.Service, error) {
 	}
 
 	if t.Cfg.CompactorConfig.RetentionEnabled {
-		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("PUT", "POST").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.AddDeleteRequestHandler))
-		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.GetAllDeleteRequestsHandler))
-		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("DELETE").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.CancelDeleteRequestHandler))
-		t.Server.HTTP.Path("/loki/api/v1/cache/generation_numbers").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.GetCacheGenerationNumberHandler))
+		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("PUT", "POST").Handler(t.addCompactorMiddleware(t.compactor.ServedByLeader(t.compactor.DeleteRequestsHandler.AddDeleteRequestHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Queries("request_id", "{request_id}").Handler(t.addCompactorMiddleware(t.compactor.ServedByLeader(t.compactor.DeleteRequestsHandler.GetDeleteRequestHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.ServedByLeader(t.compactor.DeleteRequestsHandler.GetAllDeleteRequestsHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("DELETE").Handler(t.addCompactorMiddleware(t.compactor.ServedByLeader(t.compactor.DeleteRequestsHandler.CancelDeleteRequestHandler)))
+		t.Server.HTTP.Path("/loki/api/v1/cache/generation_numbers").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.ServedByLeader(t.compactor.DeleteRequestsHandler.GetCacheGenerationNumberHandler)))
 	}
 
 	return t.compactor, nil
@@ -1097,7 +1200,7 @@ func (t *Loki) initUsageReport() (services.Service, error) {
 		return nil, err
 	}
 
-	objectClient, err := storage.NewObjectClient(period.ObjectType, t.Cfg.StorageConfig, t.clientMetrics)
+	objectClient, err := storage.NewObjectClient(period.ObjectType, t.Cfg.StorageConfig, nil, t.clientMetrics)
 	if err != nil {
 		level.Info(util_log.Logger).Log("msg", "failed to initialize usage report", "err", err)
 		return nil, nil
@@ -1111,6 +1214,24 @@ func (t *Loki) initUsageReport() (services.Service, error) {
 	return ur, nil
 }
 
+func (t *Loki) initTenantUsage() (services.Service, error) {
+	// The tracker is a no-op until the reporter enables it.
+	t.tenantUsage = tenantusage.NewTracker()
+	if !t.Cfg.TenantUsage.Enabled {
+		return nil, nil
+	}
+
+	period, err := t.Cfg.SchemaConfig.SchemaForTime(model.Now())
+	if err != nil {
+		return nil, err
+	}
+	objectClient, err := storage.NewObjectClient(period.ObjectType, t.Cfg.StorageConfig, nil, t.clientMetrics)
+	if err != nil {
+		return nil, gerrors.Wrap(err, "failed to create the object client of the tenant usage report")
+	}
+	return tenantusage.NewReporter(t.Cfg.TenantUsage, t.tenantUsage, objectClient, util_log.Logger), nil
+}
+
 func (t *Loki) deleteRequestsClient(clientType string, limits *validation.Overrides) (deletion.DeleteRequestsClient, error) {
 	if !t.supportIndexDeleteRequest() {
 		return deletion.NewNoOpDeleteRequestsStore(), nil
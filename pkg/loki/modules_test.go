@@ -305,6 +305,7 @@ func minimalWorkingConfig(t *testing.T, dir, target string) Config {
 
 	cfg.Common.InstanceAddr = localhost
 	cfg.Ingester.LifecyclerConfig.Addr = localhost
+	cfg.Ingester.WAL.Dir = filepath.Join(dir, "wal")
 	cfg.Distributor.DistributorRing.InstanceAddr = localhost
 	cfg.IndexGateway.Mode = indexgateway.SimpleMode
 	cfg.IndexGateway.Ring.InstanceAddr = localhost
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/loki/pkg/runtime"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/util/tenantremap"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -20,6 +21,7 @@ import (
 type runtimeConfigValues struct {
 	TenantLimits map[string]*validation.Limits `yaml:"overrides"`
 	TenantConfig map[string]*runtime.Config    `yaml:"configs"`
+	TenantRemap  map[string]string             `yaml:"tenant_remap"`
 
 	Multi kv.MultiRuntimeConfig `yaml:"multi_kv_config"`
 }
@@ -35,7 +37,7 @@ func (r runtimeConfigValues) validate() error {
 			return fmt.Errorf("invalid override for tenant %s: %w", t, err)
 		}
 	}
-	return nil
+	return tenantremap.Validate(r.TenantRemap)
 }
 
 func loadRuntimeConfig(r io.Reader) (interface{}, error) {
@@ -95,6 +97,18 @@ func tenantConfigFromRuntimeConfig(c *runtimeconfig.Manager) runtime.TenantConfi
 	}
 }
 
+// tenantRemapFromRuntimeConfig returns the canonical tenants of the aliased tenant IDs.
+func tenantRemapFromRuntimeConfig(c *runtimeconfig.Manager) map[string]string {
+	if c == nil {
+		return nil
+	}
+	cfg, ok := c.GetConfig().(*runtimeConfigValues)
+	if !ok || cfg == nil {
+		return nil
+	}
+	return cfg.TenantRemap
+}
+
 func multiClientRuntimeConfigChannel(manager *runtimeconfig.Manager) func() <-chan kv.MultiRuntimeConfig {
 	if manager == nil {
 		return nil
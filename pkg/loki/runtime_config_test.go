@@ -83,6 +83,23 @@ overrides:
 	require.Equal(t, "invalid override for tenant 29: retention period must be >= 24h was 5h", err.Error())
 }
 
+func Test_LoadTenantRemap(t *testing.T) {
+	cfg, err := loadRuntimeConfig(strings.NewReader(`
+tenant_remap:
+    old-team: team
+    other-old-team: team
+`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"old-team": "team", "other-old-team": "team"}, cfg.(*runtimeConfigValues).TenantRemap)
+
+	_, err = loadRuntimeConfig(strings.NewReader(`
+tenant_remap:
+    a: b
+    b: c
+`))
+	require.EqualError(t, err, `the canonical tenant "b" of tenant alias "a" is an alias itself`)
+}
+
 func newTestOverrides(t *testing.T, yaml string) *validation.Overrides {
 	t.Helper()
 	f, err := os.CreateTemp(t.TempDir(), "bar")
@@ -0,0 +1,307 @@
+// Package audit implements the audit log of the query frontend, which records every query executed by the tenants
+// to a sink for compliance review.
+package audit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+// Sinks of the audit log.
+const (
+	SinkFile  = "file"
+	SinkLoki  = "loki"
+	SinkKafka = "kafka"
+)
+
+// maxBatchSize is the number of records after which a batch is written without waiting for the flush interval.
+const maxBatchSize = 1000
+
+var supportedSinks = []string{SinkFile, SinkLoki, SinkKafka}
+
+// Config configures the audit log.
+type Config struct {
+	Enabled       bool          `yaml:"enabled"`
+	Sink          string        `yaml:"sink"`
+	UserHeader    string        `yaml:"user_header"`
+	BufferSize    int           `yaml:"buffer_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+
+	File  FileConfig  `yaml:"file"`
+	Loki  LokiConfig  `yaml:"loki"`
+	Kafka KafkaConfig `yaml:"kafka"`
+}
+
+// RegisterFlags registers the flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	prefix := "frontend.audit-log"
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Record every query executed through the query frontend in the audit log.")
+	f.StringVar(&cfg.Sink, prefix+".sink", SinkFile, fmt.Sprintf("Sink the audit records are written to. Supported values are %s.", strings.Join(supportedSinks, ", ")))
+	f.StringVar(&cfg.UserHeader, prefix+".user-header", "X-Grafana-User", "HTTP header holding the user who issued the query, recorded along with the tenant.")
+	f.IntVar(&cfg.BufferSize, prefix+".buffer-size", 10000, "Maximum number of audit records waiting to be written. Records are dropped when the buffer is full.")
+	f.DurationVar(&cfg.FlushInterval, prefix+".flush-interval", time.Second, "Interval at which the buffered audit records are written to the sink.")
+	cfg.File.RegisterFlagsWithPrefix(prefix+".file", f)
+	cfg.Loki.RegisterFlagsWithPrefix(prefix+".loki", f)
+	cfg.Kafka.RegisterFlagsWithPrefix(prefix+".kafka", f)
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.BufferSize <= 0 {
+		return errors.New("the audit log buffer size must be greater than 0")
+	}
+	if cfg.FlushInterval <= 0 {
+		return errors.New("the audit log flush interval must be greater than 0")
+	}
+	switch cfg.Sink {
+	case SinkFile:
+		return cfg.File.Validate()
+	case SinkLoki:
+		return cfg.Loki.Validate()
+	case SinkKafka:
+		return cfg.Kafka.Validate()
+	}
+	return fmt.Errorf("unsupported audit log sink %q, must be one of %s", cfg.Sink, strings.Join(supportedSinks, ", "))
+}
+
+// Record is the audit record of a query.
+type Record struct {
+	Timestamp      time.Time     `json:"ts"`
+	Tenant         string        `json:"tenant"`
+	User           string        `json:"user,omitempty"`
+	Method         string        `json:"method"`
+	Path           string        `json:"path"`
+	Query          string        `json:"query,omitempty"`
+	Start          *time.Time    `json:"start,omitempty"`
+	End            *time.Time    `json:"end,omitempty"`
+	Status         int           `json:"status"`
+	ResponseTime   time.Duration `json:"response_time"`
+	BytesProcessed int64         `json:"bytes_processed"`
+}
+
+// Sink writes batches of audit records.
+type Sink interface {
+	Write(records []Record) error
+	Close() error
+}
+
+// Logger buffers the audit records of the queries and writes them to its sink in the background.
+type Logger struct {
+	cfg    Config
+	sink   Sink
+	logger log.Logger
+
+	records chan Record
+	quit    chan struct{}
+	done    chan struct{}
+
+	written  prometheus.Counter
+	dropped  prometheus.Counter
+	failures prometheus.Counter
+}
+
+// New returns an audit logger writing to the sink of the config.
+func New(cfg Config, logger log.Logger, reg prometheus.Registerer) (*Logger, error) {
+	var (
+		sink Sink
+		err  error
+	)
+	switch cfg.Sink {
+	case SinkFile:
+		sink, err = newFileSink(cfg.File)
+	case SinkLoki:
+		sink, err = newLokiSink(cfg.Loki)
+	case SinkKafka:
+		sink, err = newKafkaSink(cfg.Kafka)
+	default:
+		err = fmt.Errorf("unsupported audit log sink %q", cfg.Sink)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newLogger(cfg, sink, logger, reg), nil
+}
+
+func newLogger(cfg Config, sink Sink, logger log.Logger, reg prometheus.Registerer) *Logger {
+	l := &Logger{
+		cfg:     cfg,
+		sink:    sink,
+		logger:  log.With(logger, "component", "audit-log"),
+		records: make(chan Record, cfg.BufferSize),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+
+		written: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "frontend_audit_records_written_total",
+			Help:      "The total number of audit records written to the sink.",
+		}),
+		dropped: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "frontend_audit_records_dropped_total",
+			Help:      "The total number of audit records dropped because the buffer was full or the sink failed.",
+		}),
+		failures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki",
+			Name:      "frontend_audit_write_failures_total",
+			Help:      "The total number of failed writes of audit records to the sink.",
+		}),
+	}
+	go l.loop()
+	return l
+}
+
+// Log buffers the record, which is dropped if the buffer is full so that queries are never slowed down by the sink.
+func (l *Logger) Log(rec Record) {
+	select {
+	case l.records <- rec:
+	default:
+		l.dropped.Inc()
+	}
+}
+
+// Stop writes the buffered records and closes the sink.
+func (l *Logger) Stop() {
+	close(l.quit)
+	<-l.done
+	if err := l.sink.Close(); err != nil {
+		level.Warn(l.logger).Log("msg", "failed to close the audit log sink", "err", err)
+	}
+}
+
+func (l *Logger) loop() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, maxBatchSize)
+	for {
+		select {
+		case rec := <-l.records:
+			batch = append(batch, rec)
+			if len(batch) >= maxBatchSize {
+				batch = l.flush(batch)
+			}
+		case <-ticker.C:
+			batch = l.flush(batch)
+		case <-l.quit:
+			for {
+				select {
+				case rec := <-l.records:
+					batch = append(batch, rec)
+				default:
+					l.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (l *Logger) flush(batch []Record) []Record {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := l.sink.Write(batch); err != nil {
+		level.Error(l.logger).Log("msg", "failed to write audit records", "records", len(batch), "err", err)
+		l.failures.Inc()
+		l.dropped.Add(float64(len(batch)))
+	} else {
+		l.written.Add(float64(len(batch)))
+	}
+	return batch[:0]
+}
+
+type ctxKeyType struct{}
+
+var ctxKey ctxKeyType
+
+// SetBytesProcessed sets the bytes processed by the query of the context, if it's audited.
+func SetBytesProcessed(ctx context.Context, bytes int64) {
+	if processed, ok := ctx.Value(ctxKey).(*atomic.Int64); ok {
+		processed.Store(bytes)
+	}
+}
+
+// Wrap implements middleware.Interface. It records the queries served by the next handler, which must be called
+// with the tenant in the context and the form of the request parsed.
+func (l *Logger) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := Record{
+			User:   r.Header.Get(l.cfg.UserHeader),
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.Form.Get("query"),
+		}
+		if rec.Query == "" {
+			// Series queries.
+			matchers := make([]string, 0, len(r.Form["match[]"])+len(r.Form["match"]))
+			matchers = append(matchers, r.Form["match[]"]...)
+			rec.Query = strings.Join(append(matchers, r.Form["match"]...), ",")
+		}
+		if tenantIDs, err := tenant.TenantIDs(r.Context()); err == nil {
+			rec.Tenant = tenant.JoinTenantIDs(tenantIDs)
+		}
+		rec.Start, rec.End = timeRange(r)
+
+		processed := atomic.NewInt64(0)
+		interceptor := &interceptor{ResponseWriter: w, statusCode: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(interceptor, r.WithContext(context.WithValue(r.Context(), ctxKey, processed)))
+
+		rec.Timestamp = time.Now()
+		rec.ResponseTime = rec.Timestamp.Sub(start)
+		rec.Status = interceptor.statusCode
+		rec.BytesProcessed = processed.Load()
+		l.Log(rec)
+	})
+}
+
+// timeRange returns the time range of the query, which is a single point in time for instant queries.
+func timeRange(r *http.Request) (*time.Time, *time.Time) {
+	if r.URL.Path == "/loki/api/v1/query" {
+		q, err := loghttp.ParseInstantQuery(r)
+		if err != nil {
+			return nil, nil
+		}
+		return &q.Ts, &q.Ts
+	}
+	q, err := loghttp.ParseRangeQuery(r)
+	if err != nil {
+		return nil, nil
+	}
+	return &q.Start, &q.End
+}
+
+// interceptor captures the status code of the response, which is 200 if WriteHeader isn't called.
+type interceptor struct {
+	http.ResponseWriter
+	statusCode int
+	recorded   bool
+}
+
+func (i *interceptor) WriteHeader(code int) {
+	if !i.recorded {
+		i.statusCode = code
+		i.recorded = true
+	}
+	i.ResponseWriter.WriteHeader(code)
+}
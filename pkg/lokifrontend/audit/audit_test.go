@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+type mockSink struct {
+	mtx     sync.Mutex
+	records []Record
+}
+
+func (s *mockSink) Write(records []Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *mockSink) Close() error { return nil }
+
+func testConfig() Config {
+	return Config{
+		Enabled:       true,
+		Sink:          SinkFile,
+		UserHeader:    "X-Grafana-User",
+		BufferSize:    100,
+		FlushInterval: time.Hour,
+	}
+}
+
+func TestLogger_Wrap(t *testing.T) {
+	sink := &mockSink{}
+	l := newLogger(testConfig(), sink, log.NewNopLogger(), prometheus.NewRegistry())
+
+	handler := l.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Form.Get("time") != "" {
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		SetBytesProcessed(r.Context(), 1234)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, `/loki/api/v1/query_range?query={app="foo"}&start=1&end=2`, nil)
+	req.Header.Set("X-Grafana-User", "alice")
+	require.NoError(t, req.ParseForm())
+	req = req.WithContext(user.InjectOrgID(req.Context(), "tenant-a"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, `/loki/api/v1/query?query=count_over_time({app="foo"}[1m])&time=3`, nil)
+	require.NoError(t, req.ParseForm())
+	req = req.WithContext(user.InjectOrgID(req.Context(), "tenant-b"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	l.Stop()
+
+	require.Len(t, sink.records, 2)
+	rec := sink.records[0]
+	require.Equal(t, "tenant-a", rec.Tenant)
+	require.Equal(t, "alice", rec.User)
+	require.Equal(t, `{app="foo"}`, rec.Query)
+	require.Equal(t, int64(1), rec.Start.Unix())
+	require.Equal(t, int64(2), rec.End.Unix())
+	require.Equal(t, http.StatusBadRequest, rec.Status)
+	require.Equal(t, int64(1234), rec.BytesProcessed)
+
+	rec = sink.records[1]
+	require.Equal(t, "tenant-b", rec.Tenant)
+	require.Empty(t, rec.User)
+	require.Equal(t, `count_over_time({app="foo"}[1m])`, rec.Query)
+	require.Equal(t, int64(3), rec.Start.Unix())
+	require.Equal(t, int64(3), rec.End.Unix())
+	require.Equal(t, http.StatusOK, rec.Status)
+	require.Equal(t, int64(0), rec.BytesProcessed)
+}
+
+func TestLogger_DropsWhenBufferIsFull(t *testing.T) {
+	cfg := testConfig()
+	cfg.BufferSize = 1
+	sink := &mockSink{}
+	l := newLogger(cfg, sink, log.NewNopLogger(), prometheus.NewRegistry())
+
+	// Block the writer so that the buffer fills up.
+	sink.mtx.Lock()
+	for i := 0; i < maxBatchSize+10; i++ {
+		l.Log(Record{Tenant: "fake"})
+	}
+	sink.mtx.Unlock()
+	l.Stop()
+
+	require.Less(t, len(sink.records), maxBatchSize+10)
+	require.Greater(t, len(sink.records), 0)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := newFileSink(FileConfig{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]Record{{Tenant: "a", Query: "{app=\"a\"}"}, {Tenant: "b"}}))
+	require.NoError(t, sink.Close())
+
+	// Records are appended to the existing file.
+	sink, err = newFileSink(FileConfig{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]Record{{Tenant: "c"}}))
+	require.NoError(t, sink.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var tenants []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		tenants = append(tenants, rec.Tenant)
+	}
+	require.Equal(t, []string{"a", "b", "c"}, tenants)
+}
+
+func TestLokiSink(t *testing.T) {
+	var (
+		orgID string
+		req   lokiPushRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID = r.Header.Get("X-Scope-OrgID")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := newLokiSink(LokiConfig{URL: server.URL, TenantID: "audit", Timeout: time.Second})
+	require.NoError(t, err)
+	require.NoError(t, sink.Write([]Record{
+		{Timestamp: time.Unix(1, 0), Tenant: "a"},
+		{Timestamp: time.Unix(2, 0), Tenant: "b"},
+		{Timestamp: time.Unix(3, 0), Tenant: "a"},
+	}))
+
+	require.Equal(t, "audit", orgID)
+	require.Len(t, req.Streams, 2)
+	require.Equal(t, map[string]string{"source": "loki-audit", "tenant": "a"}, req.Streams[0].Stream)
+	require.Len(t, req.Streams[0].Values, 2)
+	require.Equal(t, "3000000000", req.Streams[0].Values[1][0])
+	require.Equal(t, map[string]string{"source": "loki-audit", "tenant": "b"}, req.Streams[1].Stream)
+	require.Len(t, req.Streams[1].Values, 1)
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	})
+	require.Error(t, sink.Write([]Record{{Tenant: "a"}}))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := testConfig()
+	require.Error(t, cfg.Validate())
+	cfg.File.Path = "audit.log"
+	require.NoError(t, cfg.Validate())
+
+	cfg.Sink = SinkKafka
+	cfg.Kafka.Topic = "audit"
+	cfg.Kafka.Version = "2.2.1"
+	require.Error(t, cfg.Validate())
+	cfg.Kafka.Brokers = []string{"kafka:9092"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Sink = "syslog"
+	require.Error(t, cfg.Validate())
+
+	cfg.Enabled = false
+	require.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,219 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/grafana/dskit/flagext"
+)
+
+// FileConfig configures the file sink, which appends the records to a file as JSON lines.
+type FileConfig struct {
+	Path string `yaml:"path"`
+}
+
+func (cfg *FileConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Path, prefix+".path", "", "File the audit records are appended to, one JSON object per line.")
+}
+
+func (cfg *FileConfig) Validate() error {
+	if cfg.Path == "" {
+		return errors.New("the audit log file path must be set when the file sink is used")
+	}
+	return nil
+}
+
+type fileSink struct {
+	f *os.File
+}
+
+func newFileSink(cfg FileConfig) (*fileSink, error) {
+	f, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("opening the audit log file: %w", err)
+	}
+	return &fileSink{f: f}, nil
+}
+
+func (s *fileSink) Write(records []Record) error {
+	w := bufio.NewWriter(s.f)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
+
+// LokiConfig configures the Loki sink, which pushes the records to a Loki cluster, in streams labeled with the
+// tenant of the queries.
+type LokiConfig struct {
+	URL      string        `yaml:"url"`
+	TenantID string        `yaml:"tenant_id"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+func (cfg *LokiConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.URL, prefix+".url", "", "URL of the push API the audit records are sent to, e.g. http://loki:3100/loki/api/v1/push.")
+	f.StringVar(&cfg.TenantID, prefix+".tenant-id", "", "Tenant the audit records are pushed as. Empty means no X-Scope-OrgID header is sent.")
+	f.DurationVar(&cfg.Timeout, prefix+".timeout", 10*time.Second, "Timeout of the pushes of the audit records.")
+}
+
+func (cfg *LokiConfig) Validate() error {
+	if cfg.URL == "" {
+		return errors.New("the audit log Loki URL must be set when the loki sink is used")
+	}
+	return nil
+}
+
+type lokiSink struct {
+	cfg    LokiConfig
+	client *http.Client
+}
+
+func newLokiSink(cfg LokiConfig) (*lokiSink, error) {
+	return &lokiSink{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) Write(records []Record) error {
+	// Index of the stream of each tenant.
+	streams := map[string]int{}
+	req := lokiPushRequest{}
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		idx, ok := streams[rec.Tenant]
+		if !ok {
+			idx = len(req.Streams)
+			streams[rec.Tenant] = idx
+			req.Streams = append(req.Streams, lokiStream{Stream: map[string]string{"source": "loki-audit", "tenant": rec.Tenant}})
+		}
+		req.Streams[idx].Values = append(req.Streams[idx].Values, [2]string{strconv.FormatInt(rec.Timestamp.UnixNano(), 10), string(line)})
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if s.cfg.TenantID != "" {
+		httpReq.Header.Set("X-Scope-OrgID", s.cfg.TenantID)
+	}
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushing the audit records failed with status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+func (s *lokiSink) Close() error {
+	return nil
+}
+
+// KafkaConfig configures the Kafka sink, which publishes each record as a JSON message keyed by its tenant.
+type KafkaConfig struct {
+	Brokers flagext.StringSliceCSV `yaml:"brokers"`
+	Topic   string                 `yaml:"topic"`
+	Version string                 `yaml:"version"`
+}
+
+func (cfg *KafkaConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.Var(&cfg.Brokers, prefix+".brokers", "Comma separated list of the Kafka brokers the audit records are published to.")
+	f.StringVar(&cfg.Topic, prefix+".topic", "loki-audit", "Kafka topic the audit records are published to.")
+	f.StringVar(&cfg.Version, prefix+".version", "2.2.1", "Kafka version of the brokers.")
+}
+
+func (cfg *KafkaConfig) Validate() error {
+	if len(cfg.Brokers) == 0 {
+		return errors.New("the audit log Kafka brokers must be set when the kafka sink is used")
+	}
+	if cfg.Topic == "" {
+		return errors.New("the audit log Kafka topic must be set when the kafka sink is used")
+	}
+	if _, err := sarama.ParseKafkaVersion(cfg.Version); err != nil {
+		return fmt.Errorf("invalid audit log Kafka version: %w", err)
+	}
+	return nil
+}
+
+type kafkaSink struct {
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func newKafkaSink(cfg KafkaConfig) (*kafkaSink, error) {
+	version, err := sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		return nil, err
+	}
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Version = version
+	saramaCfg.ClientID = "loki-audit"
+	saramaCfg.Producer.Return.Successes = true
+	saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, saramaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating the audit log Kafka producer: %w", err)
+	}
+	return &kafkaSink{topic: cfg.Topic, producer: producer}, nil
+}
+
+func (s *kafkaSink) Write(records []Record) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, rec := range records {
+		value, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, &sarama.ProducerMessage{
+			Topic:     s.topic,
+			Key:       sarama.StringEncoder(rec.Tenant),
+			Value:     sarama.ByteEncoder(value),
+			Timestamp: rec.Timestamp,
+		})
+	}
+	return s.producer.SendMessages(msgs)
+}
+
+func (s *kafkaSink) Close() error {
+	return s.producer.Close()
+}
@@ -2,9 +2,11 @@ package lokifrontend
 
 import (
 	"flag"
+	"time"
 
 	"github.com/grafana/dskit/crypto/tls"
 
+	"github.com/grafana/loki/pkg/lokifrontend/audit"
 	"github.com/grafana/loki/pkg/lokifrontend/frontend/transport"
 	v1 "github.com/grafana/loki/pkg/lokifrontend/frontend/v1"
 	v2 "github.com/grafana/loki/pkg/lokifrontend/frontend/v2"
@@ -18,8 +20,13 @@ type Config struct {
 	CompressResponses bool   `yaml:"compress_responses"`
 	DownstreamURL     string `yaml:"downstream_url"`
 
-	TailProxyURL string           `yaml:"tail_proxy_url"`
-	TLS          tls.ClientConfig `yaml:"tail_tls_config"`
+	TailProxyURL         string           `yaml:"tail_proxy_url"`
+	TLS                  tls.ClientConfig `yaml:"tail_tls_config"`
+	TailMaxReconnects    int              `yaml:"tail_max_reconnects"`
+	TailReconnectBackoff time.Duration    `yaml:"tail_reconnect_backoff"`
+	TailMaxBackfill      time.Duration    `yaml:"tail_max_backfill"`
+
+	AuditLog audit.Config `yaml:"audit_log"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
@@ -28,8 +35,12 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.FrontendV1.RegisterFlags(f)
 	cfg.FrontendV2.RegisterFlags(f)
 	cfg.TLS.RegisterFlagsWithPrefix("frontend.tail-tls-config", f)
+	cfg.AuditLog.RegisterFlags(f)
 
 	f.BoolVar(&cfg.CompressResponses, "querier.compress-http-responses", false, "Compress HTTP responses.")
 	f.StringVar(&cfg.DownstreamURL, "frontend.downstream-url", "", "URL of downstream Prometheus.")
 	f.StringVar(&cfg.TailProxyURL, "frontend.tail-proxy-url", "", "URL of querier for tail proxy.")
+	f.IntVar(&cfg.TailMaxReconnects, "frontend.tail-max-reconnects", 0, "Maximum number of attempts to resume a tail through another querier when the connection to the querier is lost, for instance during a rollout. 0 disables the resumption.")
+	f.DurationVar(&cfg.TailReconnectBackoff, "frontend.tail-reconnect-backoff", time.Second, "Delay before each attempt to resume a tail.")
+	f.DurationVar(&cfg.TailMaxBackfill, "frontend.tail-max-backfill", time.Minute, "Period before the last entry sent to the client from which the entries are backfilled when a tail is resumed. The entries already sent are skipped.")
 }
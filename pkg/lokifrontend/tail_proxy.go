@@ -0,0 +1,304 @@
+package lokifrontend
+
+import (
+	"container/heap"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/websocket"
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const tailPingPeriod = time.Second
+
+// TailProxy proxies the tail websockets of the clients to the queriers. When the connection to a querier is lost,
+// for instance because the querier is restarted during a rollout, the tail is resumed through another querier from
+// the last entry sent to the client, the entries already sent being skipped from the backfill of the new querier.
+type TailProxy struct {
+	url           *url.URL
+	dialer        *websocket.Dialer
+	maxReconnects int
+	backoff       time.Duration
+	maxBackfill   time.Duration
+	logger        log.Logger
+}
+
+func NewTailProxy(tailURL *url.URL, tlsConfig *tls.Config, maxReconnects int, backoff, maxBackfill time.Duration, logger log.Logger) *TailProxy {
+	return &TailProxy{
+		url: tailURL,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 45 * time.Second,
+			TLSClientConfig:  tlsConfig,
+		},
+		maxReconnects: maxReconnects,
+		backoff:       backoff,
+		maxBackfill:   maxBackfill,
+		logger:        logger,
+	}
+}
+
+func (p *TailProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := util_log.WithContext(r.Context(), p.logger)
+
+	upstream, resp, err := p.dial(r, nil)
+	if err != nil {
+		status := http.StatusBadGateway
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		level.Error(logger).Log("msg", "failed to connect to querier for tailing", "err", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	client, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		upstream.Close()
+		level.Error(logger).Log("msg", "Error in upgrading websocket", "err", err)
+		return
+	}
+	defer client.Close()
+
+	// The client only sends the close message.
+	clientDone := make(chan struct{})
+	go func() {
+		defer close(clientDone)
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(tailPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-clientDone:
+				return
+			case <-ticker.C:
+				if err := client.WriteControl(websocket.PingMessage, nil, time.Now().Add(tailPingPeriod)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	var start time.Time
+	if req, err := loghttp.ParseTailQuery(r); err == nil {
+		start = req.Start
+	}
+	dedup := newTailDeduper(p.maxBackfill, start)
+	for {
+		closeMsg, resumable := p.forward(upstream, client, dedup, clientDone)
+		upstream.Close()
+		if !resumable {
+			if closeMsg != nil {
+				_ = client.WriteMessage(websocket.CloseMessage, closeMsg)
+			}
+			return
+		}
+
+		level.Warn(logger).Log("msg", "lost the connection to the querier, resuming the tail", "from", dedup.resumeFrom())
+		upstream = p.reconnect(r, dedup, clientDone, logger)
+		if upstream == nil {
+			_ = client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "failed to resume tailing"))
+			return
+		}
+	}
+}
+
+// forward sends the responses of the querier to the client until either connection is closed. It returns the close
+// message to send to the client, and whether the tail can be resumed through another querier.
+func (p *TailProxy) forward(upstream, client *websocket.Conn, dedup *tailDeduper, clientDone <-chan struct{}) ([]byte, bool) {
+	// Unblock the read of the querier connection when the client goes away.
+	upstreamDone := make(chan struct{})
+	defer close(upstreamDone)
+	go func() {
+		select {
+		case <-clientDone:
+			_ = upstream.Close()
+		case <-upstreamDone:
+		}
+	}()
+
+	for {
+		_, data, err := upstream.ReadMessage()
+		if err != nil {
+			select {
+			case <-clientDone:
+				return nil, false
+			default:
+			}
+			if closeErr, ok := err.(*websocket.CloseError); ok && closeErr.Code != websocket.CloseGoingAway && closeErr.Code != websocket.CloseAbnormalClosure {
+				// The querier ended the tail, for instance because of a limit.
+				return websocket.FormatCloseMessage(closeErr.Code, closeErr.Text), false
+			}
+			return nil, true
+		}
+
+		var resp loghttp.TailResponse
+		if err := jsoniter.Unmarshal(data, &resp); err != nil {
+			return websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()), false
+		}
+		if !dedup.filter(&resp) {
+			continue
+		}
+		if data, err = jsoniter.Marshal(resp); err != nil {
+			return websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()), false
+		}
+		if err := client.WriteMessage(websocket.TextMessage, data); err != nil {
+			return nil, false
+		}
+	}
+}
+
+func (p *TailProxy) reconnect(r *http.Request, dedup *tailDeduper, clientDone <-chan struct{}, logger log.Logger) *websocket.Conn {
+	for attempt := 1; attempt <= p.maxReconnects; attempt++ {
+		select {
+		case <-clientDone:
+			return nil
+		case <-time.After(p.backoff):
+		}
+
+		start := dedup.resumeFrom()
+		conn, _, err := p.dial(r, &start)
+		if err == nil {
+			return conn
+		}
+		level.Warn(logger).Log("msg", "failed to resume tailing", "attempt", attempt, "err", err)
+	}
+	return nil
+}
+
+// dial connects to the tail endpoint of a querier with the request of the client, starting from the given time if any.
+func (p *TailProxy) dial(r *http.Request, start *time.Time) (*websocket.Conn, *http.Response, error) {
+	u := *p.url
+	u.Path = r.URL.Path
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http", "":
+		u.Scheme = "ws"
+	}
+	query := r.URL.Query()
+	if start != nil {
+		query.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	}
+	u.RawQuery = query.Encode()
+
+	header := http.Header{}
+	for k, v := range r.Header {
+		switch k {
+		case "Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol":
+			continue
+		}
+		header[k] = v
+	}
+	return p.dialer.DialContext(r.Context(), u.String(), header)
+}
+
+// tailDeduper tracks the entries sent to the client, so that the entries which a querier sends again when the tail is
+// resumed are skipped. The tail is resumed from the window before the newest entry sent, which is the window of the
+// entries tracked, so that the backfill of the new querier overlaps with the entries already sent and none is lost.
+type tailDeduper struct {
+	window time.Duration
+	// start is the start of the tail requested by the client, before which no entry is backfilled, and created the
+	// time at which the tail was proxied, from which it is resumed when no entry was sent yet.
+	start   time.Time
+	created time.Time
+	// newest is the timestamp of the newest entry sent. sent holds the entries sent within the window before it, which
+	// are ordered by timestamp in expiry so that the entries leaving the window are dropped without scanning them all.
+	newest time.Time
+	sent   map[tailEntryKey]struct{}
+	expiry tailEntryHeap
+}
+
+type tailEntryKey struct {
+	labels string
+	ts     int64
+	line   string
+}
+
+func newTailDeduper(window time.Duration, start time.Time) *tailDeduper {
+	return &tailDeduper{
+		window:  window,
+		start:   start,
+		created: time.Now(),
+		sent:    map[tailEntryKey]struct{}{},
+	}
+}
+
+// filter removes the entries already sent from the response, and returns false if nothing is left to send.
+func (d *tailDeduper) filter(resp *loghttp.TailResponse) bool {
+	streams := resp.Streams[:0]
+	for _, s := range resp.Streams {
+		labels := s.Labels.String()
+		entries := s.Entries[:0]
+		for _, e := range s.Entries {
+			key := tailEntryKey{labels: labels, ts: e.Timestamp.UnixNano(), line: e.Line}
+			if _, ok := d.sent[key]; ok {
+				continue
+			}
+			d.sent[key] = struct{}{}
+			heap.Push(&d.expiry, key)
+			if e.Timestamp.After(d.newest) {
+				d.newest = e.Timestamp
+			}
+			entries = append(entries, e)
+		}
+		if len(entries) > 0 {
+			s.Entries = entries
+			streams = append(streams, s)
+		}
+	}
+	resp.Streams = streams
+
+	// The entries at the start of the window are kept, as the resumed tail starts from it.
+	threshold := d.newest.Add(-d.window).UnixNano()
+	for len(d.expiry) > 0 && d.expiry[0].ts < threshold {
+		delete(d.sent, heap.Pop(&d.expiry).(tailEntryKey))
+	}
+	return len(resp.Streams) > 0 || len(resp.DroppedStreams) > 0
+}
+
+// resumeFrom returns the time from which a querier must backfill the entries when the tail is resumed.
+func (d *tailDeduper) resumeFrom() time.Time {
+	newest := d.newest
+	if newest.IsZero() {
+		newest = d.created
+	}
+	from := newest.Add(-d.window)
+	if from.Before(d.start) {
+		return d.start
+	}
+	return from
+}
+
+// tailEntryHeap is a min-heap of the entries sent ordered by timestamp.
+type tailEntryHeap []tailEntryKey
+
+func (h tailEntryHeap) Len() int            { return len(h) }
+func (h tailEntryHeap) Less(i, j int) bool  { return h[i].ts < h[j].ts }
+func (h tailEntryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *tailEntryHeap) Push(x interface{}) { *h = append(*h, x.(tailEntryKey)) }
+func (h *tailEntryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
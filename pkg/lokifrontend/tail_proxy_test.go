@@ -0,0 +1,139 @@
+package lokifrontend
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/websocket"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/loghttp"
+)
+
+func TestTailProxy_ResumesTail(t *testing.T) {
+	base := time.Now().Add(-10 * time.Second).Truncate(time.Second)
+	entry := func(i int) loghttp.Entry {
+		return loghttp.Entry{Timestamp: base.Add(time.Duration(i) * time.Second), Line: strconv.Itoa(i)}
+	}
+	send := func(t *testing.T, conn *websocket.Conn, entries ...loghttp.Entry) {
+		data, err := jsoniter.Marshal(loghttp.TailResponse{
+			Streams: []loghttp.Stream{{Labels: loghttp.LabelSet{"app": "foo"}, Entries: entries}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, data))
+	}
+
+	var (
+		mtx    sync.Mutex
+		starts []string
+		orgIDs []string
+	)
+	upgrader := websocket.Upgrader{}
+	querier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		starts = append(starts, r.URL.Query().Get("start"))
+		orgIDs = append(orgIDs, r.Header.Get("X-Scope-OrgID"))
+		attempt := len(starts)
+		mtx.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		if attempt == 1 {
+			send(t, conn, entry(0), entry(1))
+			// The querier goes away without closing the websocket.
+			return
+		}
+		// The backfill of the new querier overlaps with the entries already sent.
+		send(t, conn, entry(0), entry(1), entry(2))
+		send(t, conn, entry(3))
+		require.NoError(t, conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer querier.Close()
+
+	querierURL, err := url.Parse(querier.URL)
+	require.NoError(t, err)
+	proxy := httptest.NewServer(NewTailProxy(querierURL, nil, 3, 10*time.Millisecond, time.Minute, log.NewNopLogger()))
+	defer proxy.Close()
+
+	header := http.Header{}
+	header.Set("X-Scope-OrgID", "tenant")
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(proxy.URL, "http")+"/loki/api/v1/tail?query={app=\"foo\"}", header)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var lines []string
+	for {
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			closeErr, ok := err.(*websocket.CloseError)
+			require.True(t, ok, err)
+			require.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+			break
+		}
+		var resp loghttp.TailResponse
+		require.NoError(t, jsoniter.Unmarshal(data, &resp))
+		for _, s := range resp.Streams {
+			for _, e := range s.Entries {
+				lines = append(lines, e.Line)
+			}
+		}
+	}
+	require.Equal(t, []string{"0", "1", "2", "3"}, lines)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	require.Equal(t, []string{"tenant", "tenant"}, orgIDs)
+	require.Equal(t, "", starts[0])
+	resumedFrom, err := strconv.ParseInt(starts[1], 10, 64)
+	require.NoError(t, err)
+	require.LessOrEqual(t, resumedFrom, base.UnixNano())
+}
+
+func TestTailDeduper(t *testing.T) {
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	response := func(offsets ...int) *loghttp.TailResponse {
+		var entries []loghttp.Entry
+		for _, o := range offsets {
+			entries = append(entries, loghttp.Entry{Timestamp: base.Add(time.Duration(o) * time.Second), Line: strconv.Itoa(o)})
+		}
+		return &loghttp.TailResponse{Streams: []loghttp.Stream{{Labels: loghttp.LabelSet{"app": "foo"}, Entries: entries}}}
+	}
+	lines := func(resp *loghttp.TailResponse) []string {
+		var lines []string
+		for _, s := range resp.Streams {
+			for _, e := range s.Entries {
+				lines = append(lines, e.Line)
+			}
+		}
+		return lines
+	}
+
+	d := newTailDeduper(10*time.Second, time.Time{})
+	require.True(t, d.filter(response(0, 5, 10)))
+	require.True(t, d.filter(response(20)))
+	require.Len(t, d.sent, 2)
+
+	// The tail is resumed from the window before the newest entry, even long after it was sent, and the entries of
+	// the window are skipped.
+	require.Equal(t, base.Add(10*time.Second), d.resumeFrom())
+	resp := response(10, 15, 20, 25)
+	require.True(t, d.filter(resp))
+	require.Equal(t, []string{"15", "25"}, lines(resp))
+	require.False(t, d.filter(response(15, 25)))
+
+	// No entry is backfilled before the start of the tail.
+	d = newTailDeduper(10*time.Second, base)
+	d.filter(response(5))
+	require.Equal(t, base, d.resumeFrom())
+}
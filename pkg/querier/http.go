@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	index_stats "github.com/grafana/loki/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/pkg/util/httpreq"
+	"github.com/grafana/loki/pkg/util/limiter"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/marshal"
 	marshal_legacy "github.com/grafana/loki/pkg/util/marshal/legacy"
@@ -76,6 +77,11 @@ func (q *QuerierAPI) RangeQueryHandler(w http.ResponseWriter, r *http.Request) {
 		serverutil.WriteError(err, w)
 		return
 	}
+	ctx, err = q.withQueryLimiter(ctx)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
 
 	params := logql.NewLiteralParams(
 		request.Query,
@@ -112,6 +118,11 @@ func (q *QuerierAPI) InstantQueryHandler(w http.ResponseWriter, r *http.Request)
 		serverutil.WriteError(err, w)
 		return
 	}
+	ctx, err = q.withQueryLimiter(ctx)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
 
 	params := logql.NewLiteralParams(
 		request.Query,
@@ -166,6 +177,11 @@ func (q *QuerierAPI) LogQueryHandler(w http.ResponseWriter, r *http.Request) {
 		serverutil.WriteError(err, w)
 		return
 	}
+	ctx, err = q.withQueryLimiter(ctx)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
 
 	params := logql.NewLiteralParams(
 		request.Query,
@@ -437,6 +453,28 @@ func (q *QuerierAPI) IndexStatsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// VolumeHandler queries the index for the volume of the data selected by a stream selector,
+// aggregated by series or by label.
+func (q *QuerierAPI) VolumeHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := loghttp.ParseVolumeQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+
+	resp, err := q.querier.Volume(r.Context(), req)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	err = marshal.WriteVolumeResponseJSON(resp, w)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+}
+
 // parseRegexQuery parses regex and query querystring from httpRequest and returns the combined LogQL query.
 // This is used only to keep regexp query string support until it gets fully deprecated.
 func parseRegexQuery(httpRequest *http.Request) (string, error) {
@@ -480,6 +518,21 @@ func (q *QuerierAPI) validateEntriesLimits(ctx context.Context, query string, li
 	return nil
 }
 
+// withQueryLimiter returns a context limiting the chunks and bytes fetched from the store by a query.
+func (q *QuerierAPI) withQueryLimiter(ctx context.Context) (context.Context, error) {
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	queryLimiter := limiter.NewQueryLimiter(
+		0,
+		util_validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, q.limits.MaxQueryBytesRead),
+		util_validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, q.limits.MaxChunksPerQuery),
+	)
+	return limiter.AddQueryLimiterToContext(ctx, queryLimiter), nil
+}
+
 // WrapQuerySpanAndTimeout applies a context deadline and a span logger to a query call.
 //
 // The timeout is based on the per-tenant query timeout configuration.
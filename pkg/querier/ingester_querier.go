@@ -2,6 +2,8 @@ package querier
 
 import (
 	"context"
+	"io"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/grafana/dskit/ring"
 	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
@@ -23,8 +26,10 @@ import (
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	index_stats "github.com/grafana/loki/pkg/storage/stores/index/stats"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	util_math "github.com/grafana/loki/pkg/util/math"
 )
 
 type responseFromIngesters struct {
@@ -32,28 +37,42 @@ type responseFromIngesters struct {
 	response interface{}
 }
 
+// IngesterQuerierLimits are the limits of the tenants used to select the ingesters to query.
+type IngesterQuerierLimits interface {
+	IngestionTenantShardSize(userID string) int
+}
+
 // IngesterQuerier helps with querying the ingesters.
 type IngesterQuerier struct {
 	ring            ring.ReadRing
 	pool            *ring_client.Pool
 	extraQueryDelay time.Duration
+
+	limits IngesterQuerierLimits
+	// shuffleShardingLookback is how long ingesters which left the shard of a tenant may still hold its data.
+	shuffleShardingLookback time.Duration
 }
 
-func NewIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration) (*IngesterQuerier, error) {
+// NewIngesterQuerier creates a new IngesterQuerier. With shuffle sharding, only the ingesters which have been
+// part of the shard of the tenant within shuffleShardingLookback are queried; all the ingesters are queried
+// if it's 0.
+func NewIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, limits IngesterQuerierLimits, shuffleShardingLookback time.Duration) (*IngesterQuerier, error) {
 	factory := func(addr string) (ring_client.PoolClient, error) {
 		return client.New(clientCfg, addr)
 	}
 
-	return newIngesterQuerier(clientCfg, ring, extraQueryDelay, factory)
+	return newIngesterQuerier(clientCfg, ring, extraQueryDelay, limits, shuffleShardingLookback, factory)
 }
 
 // newIngesterQuerier creates a new IngesterQuerier and allows to pass a custom ingester client factory
 // used for testing purposes
-func newIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, clientFactory ring_client.PoolFactory) (*IngesterQuerier, error) {
+func newIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryDelay time.Duration, limits IngesterQuerierLimits, shuffleShardingLookback time.Duration, clientFactory ring_client.PoolFactory) (*IngesterQuerier, error) {
 	iq := IngesterQuerier{
-		ring:            ring,
-		pool:            clientpool.NewPool(clientCfg.PoolConfig, ring, clientFactory, util_log.Logger),
-		extraQueryDelay: extraQueryDelay,
+		ring:                    ring,
+		pool:                    clientpool.NewPool(clientCfg.PoolConfig, ring, clientFactory, util_log.Logger),
+		extraQueryDelay:         extraQueryDelay,
+		limits:                  limits,
+		shuffleShardingLookback: shuffleShardingLookback,
 	}
 
 	err := services.StartAndAwaitRunning(context.Background(), iq.pool)
@@ -67,7 +86,7 @@ func newIngesterQuerier(clientCfg client.Config, ring ring.ReadRing, extraQueryD
 // forAllIngesters runs f, in parallel, for all ingesters
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (q *IngesterQuerier) forAllIngesters(ctx context.Context, f func(context.Context, logproto.QuerierClient) (interface{}, error)) ([]responseFromIngesters, error) {
-	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
+	replicationSet, err := q.tenantRing(ctx).GetReplicationSetForOperation(ring.Read)
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +94,24 @@ func (q *IngesterQuerier) forAllIngesters(ctx context.Context, f func(context.Co
 	return q.forGivenIngesters(ctx, replicationSet, f)
 }
 
+// tenantRing returns the ring of the ingesters which may hold data of the tenant of ctx. With shuffle sharding,
+// these are the ingesters which have been part of the shard of the tenant within the lookback, evenly selected
+// from each zone with zone-aware replication.
+func (q *IngesterQuerier) tenantRing(ctx context.Context) ring.ReadRing {
+	if q.limits == nil || q.shuffleShardingLookback <= 0 {
+		return q.ring
+	}
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return q.ring
+	}
+	size := q.limits.IngestionTenantShardSize(userID)
+	if size <= 0 {
+		return q.ring
+	}
+	return q.ring.ShuffleShardWithLookback(userID, size, q.shuffleShardingLookback, time.Now())
+}
+
 // forGivenIngesters runs f, in parallel, for given ingesters
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (q *IngesterQuerier) forGivenIngesters(ctx context.Context, replicationSet ring.ReplicationSet, f func(context.Context, logproto.QuerierClient) (interface{}, error)) ([]responseFromIngesters, error) {
@@ -137,7 +174,12 @@ func (q *IngesterQuerier) SelectSample(ctx context.Context, params logql.SelectS
 
 func (q *IngesterQuerier) Label(ctx context.Context, req *logproto.LabelRequest) ([][]string, error) {
 	resps, err := q.forAllIngesters(ctx, func(ctx context.Context, client logproto.QuerierClient) (interface{}, error) {
-		return client.Label(ctx, req)
+		resp, err := streamLabel(ctx, client, req)
+		if isUnimplementedCallError(err) {
+			// Fall back to the unary RPC for ingesters not supporting streaming yet.
+			return client.Label(ctx, req)
+		}
+		return resp, err
 	})
 	if err != nil {
 		return nil, err
@@ -175,7 +217,7 @@ func (q *IngesterQuerier) TailDisconnectedIngesters(ctx context.Context, req *lo
 	}
 
 	// Get the current replication set from the ring
-	replicationSet, err := q.ring.GetReplicationSetForOperation(ring.Read)
+	replicationSet, err := q.tenantRing(ctx).GetReplicationSetForOperation(ring.Read)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +260,12 @@ func (q *IngesterQuerier) TailDisconnectedIngesters(ctx context.Context, req *lo
 
 func (q *IngesterQuerier) Series(ctx context.Context, req *logproto.SeriesRequest) ([][]logproto.SeriesIdentifier, error) {
 	resps, err := q.forAllIngesters(ctx, func(ctx context.Context, client logproto.QuerierClient) (interface{}, error) {
-		return client.Series(ctx, req)
+		resp, err := streamSeries(ctx, client, req)
+		if isUnimplementedCallError(err) {
+			// Fall back to the unary RPC for ingesters not supporting streaming yet.
+			return client.Series(ctx, req)
+		}
+		return resp, err
 	})
 	if err != nil {
 		return nil, err
@@ -231,6 +278,48 @@ func (q *IngesterQuerier) Series(ctx context.Context, req *logproto.SeriesReques
 	return acc, nil
 }
 
+// streamLabel receives the batches of the label response of an ingester.
+func streamLabel(ctx context.Context, client logproto.QuerierClient, req *logproto.LabelRequest) (*logproto.LabelResponse, error) {
+	stream, err := client.StreamLabel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logproto.LabelResponse{}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Values = append(resp.Values, batch.Values...)
+		resp.NextPageToken = batch.NextPageToken
+	}
+}
+
+// streamSeries receives the batches of the series response of an ingester.
+func streamSeries(ctx context.Context, client logproto.QuerierClient, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
+	stream, err := client.StreamSeries(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logproto.SeriesResponse{}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Series = append(resp.Series, batch.Series...)
+		resp.NextPageToken = batch.NextPageToken
+	}
+}
+
 func (q *IngesterQuerier) TailersCount(ctx context.Context) ([]uint32, error) {
 	replicationSet, err := q.ring.GetAllHealthy(ring.Read)
 	if err != nil {
@@ -291,8 +380,15 @@ func (q *IngesterQuerier) GetChunkIDs(ctx context.Context, from, through model.T
 	return chunkIDs, nil
 }
 
+// Stats returns the stats of the data of the ingesters, each replica of the streams being counted once.
 func (q *IngesterQuerier) Stats(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) (*index_stats.Stats, error) {
-	resps, err := q.forAllIngesters(ctx, func(ctx context.Context, querierClient logproto.QuerierClient) (interface{}, error) {
+	tenantRing := q.tenantRing(ctx)
+	replicationSet, err := tenantRing.GetReplicationSetForOperation(ring.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	resps, err := q.forGivenIngesters(ctx, replicationSet, func(ctx context.Context, querierClient logproto.QuerierClient) (interface{}, error) {
 		return querierClient.GetStats(ctx, &logproto.IndexStatsRequest{
 			From:     from,
 			Through:  through,
@@ -308,13 +404,92 @@ func (q *IngesterQuerier) Stats(ctx context.Context, userID string, from, throug
 		return nil, err
 	}
 
+	zones := make(map[string]string, len(replicationSet.Instances))
+	for _, instance := range replicationSet.Instances {
+		zones[instance.Addr] = instance.Zone
+	}
+	merged := mergeReplicatedStats(resps, zones, tenantRing.ReplicationFactor())
+	return &merged, nil
+}
+
+// mergeReplicatedStats merges the stats of the ingesters, which each hold a replica of the streams. With zone-aware
+// replication, each zone holds a replica of every stream, so the stats of the zone with the most data are taken.
+// Otherwise, the replicas are spread across all the ingesters, so the summed stats are divided by the replication
+// factor.
+func mergeReplicatedStats(resps []responseFromIngesters, zones map[string]string, replicationFactor int) index_stats.Stats {
+	perZone := map[string][]*index_stats.Stats{}
+	for _, resp := range resps {
+		zone := zones[resp.addr]
+		perZone[zone] = append(perZone[zone], resp.response.(*index_stats.Stats))
+	}
+
+	if len(perZone) > 1 {
+		var merged index_stats.Stats
+		for _, zoneStats := range perZone {
+			if zoneMerged := index_stats.MergeStats(zoneStats...); zoneMerged.Bytes >= merged.Bytes {
+				merged = zoneMerged
+			}
+		}
+		return merged
+	}
+
 	casted := make([]*index_stats.Stats, 0, len(resps))
 	for _, resp := range resps {
 		casted = append(casted, resp.response.(*index_stats.Stats))
 	}
-
 	merged := index_stats.MergeStats(casted...)
-	return &merged, nil
+	if replicas := uint64(util_math.Min(replicationFactor, len(resps))); replicas > 1 {
+		merged.Streams /= replicas
+		merged.Chunks /= replicas
+		merged.Bytes /= replicas
+		merged.Entries /= replicas
+	}
+	return merged
+}
+
+// Volume returns the volumes of the data of the ingesters. The volumes of all the streams are fetched, so that each
+// replica of the streams is counted once, and then aggregated as requested.
+func (q *IngesterQuerier) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	resps, err := q.forAllIngesters(ctx, func(ctx context.Context, querierClient logproto.QuerierClient) (interface{}, error) {
+		return querierClient.GetVolume(ctx, &logproto.VolumeRequest{
+			From:        from,
+			Through:     through,
+			Matchers:    syntax.MatchersString(matchers),
+			Limit:       math.MaxInt32,
+			AggregateBy: seriesvolume.Streams,
+		})
+	})
+
+	if err != nil {
+		if isUnimplementedCallError(err) {
+			// Handle communication with older ingesters gracefully
+			return &logproto.VolumeResponse{}, nil
+		}
+		return nil, err
+	}
+
+	// The replicas of a stream have the same volume, unless some of them missed a push.
+	streams := map[string]uint64{}
+	for _, resp := range resps {
+		for _, v := range resp.response.(*logproto.VolumeResponse).Volumes {
+			if v.Volume > streams[v.Name] {
+				streams[v.Name] = v.Volume
+			}
+		}
+	}
+
+	acc := seriesvolume.NewAccumulator(limit)
+	targets := seriesvolume.TargetLabels(targetLabels, matchers)
+	for stream, size := range streams {
+		ls, err := syntax.ParseLabels(stream)
+		if err != nil {
+			return nil, err
+		}
+		seriesvolume.Names(ls, aggregateBy, targets, "", func(name string) {
+			acc.AddVolume(name, size)
+		})
+	}
+	return acc.Volumes(), nil
 }
 
 func convertMatchersToString(matchers []*labels.Matcher) string {
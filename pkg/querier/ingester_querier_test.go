@@ -8,14 +8,18 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/ring"
+	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
+	"github.com/grafana/loki/pkg/validation"
 )
 
 func TestIngesterQuerier_earlyExitOnQuorum(t *testing.T) {
@@ -100,6 +104,8 @@ func TestIngesterQuerier_earlyExitOnQuorum(t *testing.T) {
 					mockIngesterClientConfig(),
 					newReadRingMock(ringIngesters, 1),
 					mockQuerierConfig().ExtraQueryDelay,
+					nil,
+					0,
 					newIngesterClientMockFactory(ingesterClient),
 				)
 				require.NoError(t, err)
@@ -199,6 +205,8 @@ func TestIngesterQuerier_earlyExitOnQuorum(t *testing.T) {
 					mockIngesterClientConfig(),
 					newReadRingMock(ringIngesters, 1),
 					mockQuerierConfig().ExtraQueryDelay,
+					nil,
+					0,
 					newIngesterClientMockFactory(ingesterClient),
 				)
 				require.NoError(t, err)
@@ -226,6 +234,147 @@ func TestIngesterQuerier_earlyExitOnQuorum(t *testing.T) {
 	}
 }
 
+func TestIngesterQuerier_Streaming(t *testing.T) {
+	ingesterClient := &streamingQuerierClientMock{
+		querierClientMock: newQuerierClientMock(),
+		labels: []*logproto.LabelResponse{
+			{Values: []string{"a", "b"}},
+			{Values: []string{"c"}, NextPageToken: "next"},
+		},
+		series: []*logproto.SeriesResponse{
+			{Series: []logproto.SeriesIdentifier{{Labels: map[string]string{"a": "1"}}}},
+			{Series: []logproto.SeriesIdentifier{{Labels: map[string]string{"a": "2"}}}},
+		},
+	}
+	ingesterQuerier, err := newIngesterQuerier(
+		mockIngesterClientConfig(),
+		newReadRingMock([]ring.InstanceDesc{mockInstanceDesc("1.1.1.1", ring.ACTIVE)}, 0),
+		mockQuerierConfig().ExtraQueryDelay,
+		nil,
+		0,
+		func(addr string) (ring_client.PoolClient, error) { return ingesterClient, nil },
+	)
+	require.NoError(t, err)
+
+	values, err := ingesterQuerier.Label(context.Background(), &logproto.LabelRequest{Limit: 3})
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b", "c"}}, values)
+
+	series, err := ingesterQuerier.Series(context.Background(), &logproto.SeriesRequest{})
+	require.NoError(t, err)
+	require.Equal(t, [][]logproto.SeriesIdentifier{{{Labels: map[string]string{"a": "1"}}, {Labels: map[string]string{"a": "2"}}}}, series)
+
+	// The unary RPCs are only called by ingesters not supporting streaming.
+	ingesterClient.AssertNotCalled(t, "Label")
+	ingesterClient.AssertNotCalled(t, "Series")
+}
+
+func TestIngesterQuerier_ShuffleSharding(t *testing.T) {
+	ingesters := []ring.InstanceDesc{
+		mockInstanceDesc("1.1.1.1", ring.ACTIVE),
+		mockInstanceDesc("2.2.2.2", ring.ACTIVE),
+		mockInstanceDesc("3.3.3.3", ring.ACTIVE),
+	}
+	limits, err := validation.NewOverrides(validation.Limits{IngestionTenantShardSize: 1}, nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		desc     string
+		lookback time.Duration
+		expected int
+	}{
+		{desc: "shard of the tenant", lookback: 3 * time.Hour, expected: 1},
+		{desc: "all ingesters without lookback", lookback: 0, expected: 3},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ingesterClient := newQuerierClientMock()
+			ingesterClient.On("GetStats", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.IndexStatsResponse{}, nil)
+
+			ingesterQuerier, err := newIngesterQuerier(
+				mockIngesterClientConfig(),
+				newReadRingMock(ingesters, 0),
+				mockQuerierConfig().ExtraQueryDelay,
+				limits,
+				tc.lookback,
+				newIngesterClientMockFactory(ingesterClient),
+			)
+			require.NoError(t, err)
+
+			_, err = ingesterQuerier.Stats(user.InjectOrgID(context.Background(), "test"), "test", 0, 1)
+			require.NoError(t, err)
+			ingesterClient.AssertNumberOfCalls(t, "GetStats", tc.expected)
+		})
+	}
+}
+
+func TestIngesterQuerier_Volume(t *testing.T) {
+	ingesterClient := newQuerierClientMock()
+	ingesterClient.On("GetVolume", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="foo", pod="foo-1"}`, Volume: 10}, {Name: `{app="foo", pod="foo-2"}`, Volume: 5}},
+	}, nil).Once()
+	ingesterClient.On("GetVolume", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="foo", pod="foo-1"}`, Volume: 8}, {Name: `{app="bar", pod="bar-1"}`, Volume: 20}},
+	}, nil).Once()
+
+	ingesterQuerier, err := newIngesterQuerier(
+		mockIngesterClientConfig(),
+		newReadRingMock([]ring.InstanceDesc{mockInstanceDesc("1.1.1.1", ring.ACTIVE), mockInstanceDesc("2.2.2.2", ring.ACTIVE)}, 0),
+		mockQuerierConfig().ExtraQueryDelay,
+		nil,
+		0,
+		newIngesterClientMockFactory(ingesterClient),
+	)
+	require.NoError(t, err)
+
+	// The replicas of each stream are counted once, before the volumes are aggregated.
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")}
+	res, err := ingesterQuerier.Volume(user.InjectOrgID(context.Background(), "test"), "test", 0, 1, 10, nil, seriesvolume.Series, matchers...)
+	require.NoError(t, err)
+	require.Equal(t, &logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="bar"}`, Volume: 20}, {Name: `{app="foo"}`, Volume: 15}},
+		Limit:   10,
+	}, res)
+	ingesterClient.AssertCalled(t, "GetVolume", mock.Anything, mock.MatchedBy(func(req *logproto.VolumeRequest) bool {
+		return req.AggregateBy == seriesvolume.Streams
+	}), mock.Anything)
+}
+
+func TestMergeReplicatedStats(t *testing.T) {
+	resps := []responseFromIngesters{
+		{addr: "1.1.1.1", response: &logproto.IndexStatsResponse{Streams: 2, Chunks: 4, Bytes: 20, Entries: 40}},
+		{addr: "2.2.2.2", response: &logproto.IndexStatsResponse{Streams: 1, Chunks: 2, Bytes: 10, Entries: 20}},
+		{addr: "3.3.3.3", response: &logproto.IndexStatsResponse{Streams: 3, Chunks: 6, Bytes: 30, Entries: 60}},
+	}
+
+	for _, tc := range []struct {
+		desc              string
+		zones             map[string]string
+		replicationFactor int
+		expected          logproto.IndexStatsResponse
+	}{
+		{
+			desc:              "without replication",
+			replicationFactor: 1,
+			expected:          logproto.IndexStatsResponse{Streams: 6, Chunks: 12, Bytes: 60, Entries: 120},
+		},
+		{
+			desc:              "replicas spread across the ingesters",
+			replicationFactor: 3,
+			expected:          logproto.IndexStatsResponse{Streams: 2, Chunks: 4, Bytes: 20, Entries: 40},
+		},
+		{
+			desc:              "replicas in each zone",
+			zones:             map[string]string{"1.1.1.1": "a", "2.2.2.2": "a", "3.3.3.3": "b"},
+			replicationFactor: 2,
+			expected:          logproto.IndexStatsResponse{Streams: 3, Chunks: 6, Bytes: 30, Entries: 60},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expected, mergeReplicatedStats(resps, tc.zones, tc.replicationFactor))
+		})
+	}
+}
+
 func TestQuerier_tailDisconnectedIngesters(t *testing.T) {
 	t.Parallel()
 
@@ -296,6 +445,8 @@ func TestQuerier_tailDisconnectedIngesters(t *testing.T) {
 				mockIngesterClientConfig(),
 				newReadRingMock(testData.ringIngesters, 0),
 				mockQuerierConfig().ExtraQueryDelay,
+				nil,
+				0,
 				newIngesterClientMockFactory(ingesterClient),
 			)
 			require.NoError(t, err)
@@ -2,9 +2,11 @@ package querier
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/httpgrpc"
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/dskit/tenant"
@@ -14,7 +16,10 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/util/paging"
+	util_validation "github.com/grafana/loki/pkg/util/validation"
 )
 
 const (
@@ -22,24 +27,45 @@ const (
 	retainExistingPrefix = "original_"
 )
 
+// MultiTenantLimits returns the tenants which can be queried together with each tenant.
+type MultiTenantLimits interface {
+	MultiTenantQueryAllowlist(userID string) []string
+}
+
 // MultiTenantQuerier is able to query across different tenants.
 type MultiTenantQuerier struct {
 	Querier
+	limits MultiTenantLimits
 }
 
 // NewMultiTenantQuerier returns a new querier able to query across different tenants.
-func NewMultiTenantQuerier(querier Querier, logger log.Logger) *MultiTenantQuerier {
+func NewMultiTenantQuerier(querier Querier, limits MultiTenantLimits, logger log.Logger) *MultiTenantQuerier {
 	return &MultiTenantQuerier{
 		Querier: querier,
+		limits:  limits,
 	}
 }
 
-func (q *MultiTenantQuerier) SelectLogs(ctx context.Context, params logql.SelectLogParams) (iter.EntryIterator, error) {
+// tenantIDs returns the tenants of the query, once checked that each of them can be queried together with the
+// others.
+func (q *MultiTenantQuerier) tenantIDs(ctx context.Context) ([]string, error) {
 	tenantIDs, err := tenant.TenantIDs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if id, other, ok := util_validation.MultiTenantQueryAllowed(tenantIDs, q.limits.MultiTenantQueryAllowlist); !ok {
+		return nil, httpgrpc.Errorf(http.StatusForbidden, util_validation.ErrMultiTenantQueryNotAllowed, id, other)
+	}
+	return tenantIDs, nil
+}
+
+func (q *MultiTenantQuerier) SelectLogs(ctx context.Context, params logql.SelectLogParams) (iter.EntryIterator, error) {
+	tenantIDs, err := q.tenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(tenantIDs) == 1 {
 		return q.Querier.SelectLogs(ctx, params)
 	}
@@ -67,7 +93,7 @@ func (q *MultiTenantQuerier) SelectLogs(ctx context.Context, params logql.Select
 }
 
 func (q *MultiTenantQuerier) SelectSamples(ctx context.Context, params logql.SelectSampleParams) (iter.SampleIterator, error) {
-	tenantIDs, err := tenant.TenantIDs(ctx)
+	tenantIDs, err := q.tenantIDs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -98,13 +124,18 @@ func (q *MultiTenantQuerier) SelectSamples(ctx context.Context, params logql.Sel
 }
 
 func (q *MultiTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequest) (*logproto.LabelResponse, error) {
-	tenantIDs, err := tenant.TenantIDs(ctx)
+	tenantIDs, err := q.tenantIDs(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
 	if req.Values && req.Name == defaultTenantLabel {
-		return &logproto.LabelResponse{Values: tenantIDs}, nil
+		values, next, err := paging.Strings(tenantIDs, page)
+		if err != nil {
+			return nil, err
+		}
+		return &logproto.LabelResponse{Values: values, NextPageToken: next}, nil
 	}
 
 	if len(tenantIDs) == 1 {
@@ -127,11 +158,20 @@ func (q *MultiTenantQuerier) Label(ctx context.Context, req *logproto.LabelReque
 		responses = append(responses, &logproto.LabelResponse{Values: []string{defaultTenantLabel}})
 	}
 
-	return logproto.MergeLabelResponses(responses)
+	// The tenants' pages are merged into the page of all the tenants.
+	resp, err := logproto.MergeLabelResponses(responses)
+	if err != nil {
+		return nil, err
+	}
+	resp.Values, resp.NextPageToken, err = paging.Strings(resp.Values, page)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (q *MultiTenantQuerier) Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error) {
-	tenantIDs, err := tenant.TenantIDs(ctx)
+	tenantIDs, err := q.tenantIDs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -140,10 +180,15 @@ func (q *MultiTenantQuerier) Series(ctx context.Context, req *logproto.SeriesReq
 		return q.Querier.Series(ctx, req)
 	}
 
+	// The series of the tenants are paged once the tenant ID label is added, as it changes their order.
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
+	tenantReq := *req
+	tenantReq.Limit, tenantReq.PageToken = 0, ""
+
 	responses := make([]*logproto.SeriesResponse, len(tenantIDs))
 	for i, id := range tenantIDs {
 		singleContext := user.InjectOrgID(ctx, id)
-		resp, err := q.Querier.Series(singleContext, req)
+		resp, err := q.Querier.Series(singleContext, &tenantReq)
 		if err != nil {
 			return nil, err
 		}
@@ -157,11 +202,19 @@ func (q *MultiTenantQuerier) Series(ctx context.Context, req *logproto.SeriesReq
 		responses[i] = resp
 	}
 
-	return logproto.MergeSeriesResponses(responses)
+	resp, err := logproto.MergeSeriesResponses(responses)
+	if err != nil {
+		return nil, err
+	}
+	resp.Series, resp.NextPageToken, err = paging.Series(resp.Series, page)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
 func (q *MultiTenantQuerier) IndexStats(ctx context.Context, req *loghttp.RangeQuery) (*stats.Stats, error) {
-	tenantIDs, err := tenant.TenantIDs(ctx)
+	tenantIDs, err := q.tenantIDs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -186,6 +239,30 @@ func (q *MultiTenantQuerier) IndexStats(ctx context.Context, req *loghttp.RangeQ
 	return &merged, nil
 }
 
+func (q *MultiTenantQuerier) Volume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	tenantIDs, err := q.tenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tenantIDs) == 1 {
+		return q.Querier.Volume(ctx, req)
+	}
+
+	responses := make([]*logproto.VolumeResponse, len(tenantIDs))
+	for i, id := range tenantIDs {
+		singleContext := user.InjectOrgID(ctx, id)
+		resp, err := q.Querier.Volume(singleContext, req)
+		if err != nil {
+			return nil, err
+		}
+
+		responses[i] = resp
+	}
+
+	return seriesvolume.Merge(req.Limit, responses...), nil
+}
+
 // removeTenantSelector filters the given tenant IDs based on any tenant ID filter the in passed selector.
 func removeTenantSelector(params logql.SelectSampleParams, tenantIDs []string) (map[string]struct{}, syntax.Expr, error) {
 	expr, err := params.Expr()
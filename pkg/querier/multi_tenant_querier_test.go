@@ -21,8 +21,54 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
+// allowlistLimits allows all the multi-tenant queries, except for the tenants it has an allowlist for.
+type allowlistLimits map[string][]string
+
+func (l allowlistLimits) MultiTenantQueryAllowlist(userID string) []string {
+	if allowed, ok := l[userID]; ok {
+		return allowed
+	}
+	return []string{"*"}
+}
+
+func TestMultiTenantQuerier_Allowlist(t *testing.T) {
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+
+	querier := newQuerierMock()
+	querier.On("Label", mock.Anything, mock.Anything).Return(mockLabelResponse([]string{"test"}), nil)
+	multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{
+		"1": {"2"},
+		"3": {},
+	}, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		orgID   string
+		allowed bool
+	}{
+		{"1|2", true},
+		{"2|4", true},
+		{"1|4", false},
+		{"1|2|4", false},
+		// the tenants denying multi-tenant queries can still be queried on their own
+		{"3", true},
+		{"2|3", false},
+	} {
+		t.Run(tc.orgID, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), tc.orgID)
+			_, err := multiTenantQuerier.Label(ctx, &logproto.LabelRequest{Name: "test", Values: true})
+			if tc.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "can't be queried together with tenant")
+		})
+	}
+}
+
 func TestMultiTenantQuerier_SelectLogs(t *testing.T) {
 	tenant.WithDefaultResolver(tenant.NewMultiResolver())
 
@@ -80,7 +126,7 @@ func TestMultiTenantQuerier_SelectLogs(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("SelectLogs", mock.Anything, mock.Anything).Return(func() iter.EntryIterator { return mockStreamIterator(1, 2) }, nil)
 
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{}, log.NewNopLogger())
 
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 			params := logql.SelectLogParams{QueryRequest: &logproto.QueryRequest{
@@ -156,7 +202,7 @@ func TestMultiTenantQuerier_SelectSamples(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("SelectSamples", mock.Anything, mock.Anything).Return(func() iter.SampleIterator { return newSampleIterator() }, nil)
 
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{}, log.NewNopLogger())
 
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 			params := logql.SelectSampleParams{SampleQueryRequest: &logproto.SampleQueryRequest{
@@ -333,7 +379,7 @@ func TestMultiTenantQuerier_Label(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("Label", mock.Anything, mock.Anything).Return(mockLabelResponse([]string{"test"}), nil)
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{}, log.NewNopLogger())
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 
 			resp, err := multiTenantQuerier.Label(ctx, mockLabelRequest(tc.name))
@@ -397,7 +443,7 @@ func TestMultiTenantQuerierSeries(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			querier := newQuerierMock()
 			querier.On("Series", mock.Anything, mock.Anything).Return(func() *logproto.SeriesResponse { return mockSeriesResponse() }, nil)
-			multiTenantQuerier := NewMultiTenantQuerier(querier, log.NewNopLogger())
+			multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{}, log.NewNopLogger())
 			ctx := user.InjectOrgID(context.Background(), tc.orgID)
 
 			resp, err := multiTenantQuerier.Series(ctx, mockSeriesRequest())
@@ -407,6 +453,34 @@ func TestMultiTenantQuerierSeries(t *testing.T) {
 	}
 }
 
+func TestMultiTenantQuerierSeries_Paging(t *testing.T) {
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+
+	querier := newQuerierMock()
+	querier.On("Series", mock.Anything, mock.Anything).Return(func() *logproto.SeriesResponse { return mockSeriesResponse() }, nil)
+	multiTenantQuerier := NewMultiTenantQuerier(querier, allowlistLimits{}, log.NewNopLogger())
+	ctx := user.InjectOrgID(context.Background(), "1|2")
+
+	req := mockSeriesRequest()
+	req.Limit = 3
+	req.PageToken = paging.NextToken(`{__tenant_id__="1", a="1", b="3"}`)
+	resp, err := multiTenantQuerier.Series(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []logproto.SeriesIdentifier{
+		{Labels: map[string]string{"__tenant_id__": "1", "a": "1", "b": "4"}},
+		{Labels: map[string]string{"__tenant_id__": "1", "a": "1", "b": "5"}},
+		{Labels: map[string]string{"__tenant_id__": "2", "a": "1", "b": "2"}},
+	}, resp.GetSeries())
+	require.Equal(t, paging.NextToken(`{__tenant_id__="2", a="1", b="2"}`), resp.NextPageToken)
+
+	// The tenants are queried without paging, as the tenant ID label changes the order of their series.
+	for _, call := range querier.GetMockedCallsByMethod("Series") {
+		tenantReq := call.Arguments.Get(1).(*logproto.SeriesRequest)
+		require.Zero(t, tenantReq.Limit)
+		require.Empty(t, tenantReq.PageToken)
+	}
+}
+
 func mockSeriesRequest() *logproto.SeriesRequest {
 	return &logproto.SeriesRequest{
 		Start: time.Unix(0, 0),
@@ -7,24 +7,29 @@ import (
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/dskit/tenant"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/weaveworks/common/httpgrpc"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/grafana/loki/pkg/ingester"
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/loghttp"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletion"
 	listutil "github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/util/paging"
 	"github.com/grafana/loki/pkg/util/spanlogger"
 	util_validation "github.com/grafana/loki/pkg/util/validation"
 	"github.com/grafana/loki/pkg/validation"
@@ -62,7 +67,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Engine.RegisterFlagsWithPrefix("querier", f)
 	f.DurationVar(&cfg.TailMaxDuration, "querier.tail-max-duration", 1*time.Hour, "Limit the duration for which live tailing request would be served")
 	f.DurationVar(&cfg.ExtraQueryDelay, "querier.extra-query-delay", 0, "Time to wait before sending more than the minimum successful query requests.")
-	f.DurationVar(&cfg.QueryIngestersWithin, "querier.query-ingesters-within", 3*time.Hour, "Maximum lookback beyond which queries are not sent to ingester. 0 means all queries are sent to ingester.")
+	f.DurationVar(&cfg.QueryIngestersWithin, "querier.query-ingesters-within", 3*time.Hour, "Maximum lookback beyond which queries are not sent to ingester. 0 means all queries are sent to ingester. With shuffle sharding of the ingesters, the ingesters which have been part of the shard of a tenant within this lookback are queried, or all the ingesters if 0.")
 	f.IntVar(&cfg.MaxConcurrent, "querier.max-concurrent", 10, "The maximum number of concurrent queries.")
 	f.BoolVar(&cfg.QueryStoreOnly, "querier.query-store-only", false, "Queriers should only query the store and not try to query any ingesters")
 	f.BoolVar(&cfg.QueryIngesterOnly, "querier.query-ingester-only", false, "Queriers should only query the ingesters and not try to query any store")
@@ -84,6 +89,7 @@ type Querier interface {
 	Series(ctx context.Context, req *logproto.SeriesRequest) (*logproto.SeriesResponse, error)
 	Tail(ctx context.Context, req *logproto.TailRequest) (*Tailer, error)
 	IndexStats(ctx context.Context, req *loghttp.RangeQuery) (*stats.Stats, error)
+	Volume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error)
 }
 
 // SingleTenantQuerier handles single tenant queries.
@@ -162,9 +168,9 @@ func (q *SingleTenantQuerier) SelectLogs(ctx context.Context, params logql.Selec
 		iters = append(iters, storeIter)
 	}
 	if len(iters) == 1 {
-		return iters[0], nil
+		return newUnshardedEntryIterator(iters[0]), nil
 	}
-	return iter.NewMergeEntryIterator(ctx, iters, params.Direction), nil
+	return newUnshardedEntryIterator(iter.NewMergeEntryIterator(ctx, iters, params.Direction)), nil
 }
 
 func (q *SingleTenantQuerier) SelectSamples(ctx context.Context, params logql.SelectSampleParams) (iter.SampleIterator, error) {
@@ -211,7 +217,7 @@ func (q *SingleTenantQuerier) SelectSamples(ctx context.Context, params logql.Se
 
 		iters = append(iters, storeIter)
 	}
-	return iter.NewMergeSampleIterator(ctx, iters), nil
+	return newUnshardedSampleIterator(iter.NewMergeSampleIterator(ctx, iters)), nil
 }
 
 func (q *SingleTenantQuerier) deletesForUser(ctx context.Context, startT, endT time.Time) ([]*logproto.Delete, error) {
@@ -364,6 +370,8 @@ func (q *SingleTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequ
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(queryTimeout))
 	defer cancel()
 
+	// The context of the group is canceled once the values are fetched.
+	queryCtx := ctx
 	g, ctx := errgroup.WithContext(ctx)
 
 	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(*req.Start, *req.End)
@@ -381,6 +389,9 @@ func (q *SingleTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequ
 		})
 	}
 
+	// Ingesters get the page with the request, stores with the context.
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
+
 	var storeValues []string
 	if !q.cfg.QueryIngesterOnly && storeQueryInterval != nil {
 		g.Go(func() error {
@@ -388,6 +399,7 @@ func (q *SingleTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequ
 				err     error
 				from    = model.TimeFromUnixNano(storeQueryInterval.start.UnixNano())
 				through = model.TimeFromUnixNano(storeQueryInterval.end.UnixNano())
+				ctx     = paging.InjectPage(ctx, page)
 			)
 
 			if req.Values {
@@ -404,11 +416,47 @@ func (q *SingleTenantQuerier) Label(ctx context.Context, req *logproto.LabelRequ
 	}
 
 	results := append(ingesterValues, storeValues)
+	merged := listutil.MergeStringLists(results...)
+	if merged, err = q.unshardLabels(queryCtx, req, merged); err != nil {
+		return nil, err
+	}
+	values, next, err := paging.Strings(merged, page)
+	if err != nil {
+		return nil, err
+	}
 	return &logproto.LabelResponse{
-		Values: listutil.MergeStringLists(results...),
+		Values:        values,
+		NextPageToken: next,
 	}, nil
 }
 
+// unshardLabels removes the values of the shard label of the shards of the streams over their rate limit, and the
+// shard label itself if only those have it.
+func (q *SingleTenantQuerier) unshardLabels(ctx context.Context, req *logproto.LabelRequest, values []string) ([]string, error) {
+	if req.Values {
+		if req.Name == ingester.ShardLbName {
+			return withoutRateLimitedShards(values), nil
+		}
+		return values, nil
+	}
+
+	i := 0
+	for i < len(values) && values[i] != ingester.ShardLbName {
+		i++
+	}
+	if i == len(values) {
+		return values, nil
+	}
+	shards, err := q.Label(ctx, &logproto.LabelRequest{Name: ingester.ShardLbName, Values: true, Start: req.Start, End: req.End})
+	if err != nil {
+		return nil, err
+	}
+	if len(shards.Values) > 0 {
+		return values, nil
+	}
+	return append(values[:i], values[i+1:]...), nil
+}
+
 // Check implements the grpc healthcheck
 func (*SingleTenantQuerier) Check(_ context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
 	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
@@ -539,9 +587,12 @@ func (q *SingleTenantQuerier) awaitSeries(ctx context.Context, req *logproto.Ser
 		series <- [][]logproto.SeriesIdentifier{}
 	}
 
+	// Ingesters get the page with the request, stores with the context.
+	page := paging.Page{Limit: int(req.Limit), Token: req.PageToken}
+
 	if !q.cfg.QueryIngesterOnly && storeQueryInterval != nil {
 		go func() {
-			storeValues, err := q.seriesForMatchers(ctx, storeQueryInterval.start, storeQueryInterval.end, req.GetGroups(), req.Shards)
+			storeValues, err := q.seriesForMatchers(paging.InjectPage(ctx, page), storeQueryInterval.start, storeQueryInterval.end, req.GetGroups(), req.Shards)
 			if err != nil {
 				errs <- err
 				return
@@ -565,6 +616,7 @@ func (q *SingleTenantQuerier) awaitSeries(ctx context.Context, req *logproto.Ser
 
 	deduped := make(map[string]logproto.SeriesIdentifier)
 	for _, set := range sets {
+		unshardSeries(set)
 		for _, s := range set {
 			key := loghttp.LabelSet(s.Labels).String()
 			if _, exists := deduped[key]; !exists {
@@ -581,6 +633,11 @@ func (q *SingleTenantQuerier) awaitSeries(ctx context.Context, req *logproto.Ser
 		response.Series = append(response.Series, s)
 	}
 
+	var err error
+	response.Series, response.NextPageToken, err = paging.Series(response.Series, page)
+	if err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -720,10 +777,13 @@ func (q *SingleTenantQuerier) IndexStats(ctx context.Context, req *loghttp.Range
 		return nil, err
 	}
 
-	matchers, err := syntax.ParseMatchers(req.Query)
+	// The query can be any LogQL query, i.e. a metric query selecting several matcher groups,
+	// the stats of which are summed.
+	expr, err := syntax.ParseExpr(req.Query)
 	if err != nil {
 		return nil, err
 	}
+	grps := syntax.MatcherGroups(expr)
 
 	// Enforce the query timeout while querying backends
 	queryTimeout := q.limits.QueryTimeout(userID)
@@ -735,12 +795,134 @@ func (q *SingleTenantQuerier) IndexStats(ctx context.Context, req *loghttp.Range
 	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(queryTimeout))
 	defer cancel()
 
-	return q.store.Stats(
-		ctx,
-		userID,
-		model.TimeFromUnixNano(start.UnixNano()),
-		model.TimeFromUnixNano(end.UnixNano()),
-		matchers...,
-	)
+	results := make([]*stats.Stats, len(grps))
+	if err := concurrency.ForEachJob(ctx, len(grps), len(grps), func(ctx context.Context, i int) error {
+		// The range and offset of range aggregations move the data they select back in time.
+		from := start.Add(-grps[i].Interval - grps[i].Offset)
+		through := end.Add(-grps[i].Offset)
+		res, err := q.indexStats(ctx, userID, from, through, grps[i].Matchers)
+		results[i] = res
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	merged := stats.MergeStats(results...)
+	return &merged, nil
+}
+
+// indexStats returns the stats of the data selected by matchers from the ingesters and the store.
+func (q *SingleTenantQuerier) indexStats(ctx context.Context, userID string, from, through time.Time, matchers []*labels.Matcher) (*stats.Stats, error) {
+	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(from, through)
+
+	var jobs []func(context.Context) (*stats.Stats, error)
+	if !q.cfg.QueryStoreOnly && ingesterQueryInterval != nil {
+		jobs = append(jobs, func(ctx context.Context) (*stats.Stats, error) {
+			return q.ingesterQuerier.Stats(
+				ctx,
+				userID,
+				model.TimeFromUnixNano(ingesterQueryInterval.start.UnixNano()),
+				model.TimeFromUnixNano(ingesterQueryInterval.end.UnixNano()),
+				matchers...,
+			)
+		})
+	}
+	if !q.cfg.QueryIngesterOnly && storeQueryInterval != nil {
+		jobs = append(jobs, func(ctx context.Context) (*stats.Stats, error) {
+			return q.store.Stats(
+				ctx,
+				userID,
+				model.TimeFromUnixNano(storeQueryInterval.start.UnixNano()),
+				model.TimeFromUnixNano(storeQueryInterval.end.UnixNano()),
+				matchers...,
+			)
+		})
+	}
+
+	resps := make([]*stats.Stats, len(jobs))
+	if err := concurrency.ForEachJob(ctx, len(jobs), len(jobs), func(ctx context.Context, i int) error {
+		var err error
+		resps[i], err = jobs[i](ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	merged := stats.MergeStats(resps...)
+	return &merged, nil
+}
+
+func (q *SingleTenantQuerier) Volume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, through, err := validateQueryTimeRangeLimits(ctx, userID, q.limits, req.From.Time(), req.Through.Time())
+	if err != nil {
+		return nil, err
+	}
+
+	matchers, err := syntax.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	if err := seriesvolume.ValidateAggregateBy(req.AggregateBy); err != nil {
+		return nil, err
+	}
+
+	// Enforce the query timeout while querying backends
+	queryTimeout := q.limits.QueryTimeout(userID)
+	// TODO: remove this clause once we remove the deprecated query-timeout flag.
+	if q.cfg.QueryTimeout != 0 { // querier YAML configuration.
+		level.Warn(util_log.Logger).Log("msg", "deprecated querier:query_timeout YAML configuration identified. Please migrate to limits:query_timeout instead.", "call", "SingleTenantQuerier/Volume")
+		queryTimeout = q.cfg.QueryTimeout
+	}
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(queryTimeout))
+	defer cancel()
+
+	ingesterQueryInterval, storeQueryInterval := q.buildQueryIntervals(from, through)
+
+	var jobs []func(context.Context) (*logproto.VolumeResponse, error)
+	if !q.cfg.QueryStoreOnly && ingesterQueryInterval != nil {
+		jobs = append(jobs, func(ctx context.Context) (*logproto.VolumeResponse, error) {
+			return q.ingesterQuerier.Volume(
+				ctx,
+				userID,
+				model.TimeFromUnixNano(ingesterQueryInterval.start.UnixNano()),
+				model.TimeFromUnixNano(ingesterQueryInterval.end.UnixNano()),
+				req.Limit,
+				req.TargetLabels,
+				req.AggregateBy,
+				matchers...,
+			)
+		})
+	}
+	if !q.cfg.QueryIngesterOnly && storeQueryInterval != nil {
+		jobs = append(jobs, func(ctx context.Context) (*logproto.VolumeResponse, error) {
+			return q.store.Volume(
+				ctx,
+				userID,
+				model.TimeFromUnixNano(storeQueryInterval.start.UnixNano()),
+				model.TimeFromUnixNano(storeQueryInterval.end.UnixNano()),
+				req.Limit,
+				req.TargetLabels,
+				req.AggregateBy,
+				matchers...,
+			)
+		})
+	}
+
+	resps := make([]*logproto.VolumeResponse, len(jobs))
+	if err := concurrency.ForEachJob(ctx, len(jobs), len(jobs), func(ctx context.Context, i int) error {
+		var err error
+		resps[i], err = jobs[i](ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
+	// Each source returns its largest volumes only, so the merged volumes are approximate
+	// when the limit truncates the volumes of a source.
+	return seriesvolume.Merge(req.Limit, resps...), nil
 }
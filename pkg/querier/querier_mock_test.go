@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/grafana/dskit/grpcclient"
@@ -13,8 +14,10 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	grpc_metadata "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/grafana/loki/pkg/distributor/clientpool"
 	"github.com/grafana/loki/pkg/ingester/client"
@@ -86,6 +89,16 @@ func (c *querierClientMock) Series(ctx context.Context, in *logproto.SeriesReque
 	return res.(*logproto.SeriesResponse), args.Error(1)
 }
 
+// StreamLabel isn't supported by the mock, so that the tests get the responses of Label instead.
+func (c *querierClientMock) StreamLabel(ctx context.Context, in *logproto.LabelRequest, opts ...grpc.CallOption) (logproto.Querier_StreamLabelClient, error) {
+	return nil, status.Error(codes.Unimplemented, "StreamLabel")
+}
+
+// StreamSeries isn't supported by the mock, so that the tests get the responses of Series instead.
+func (c *querierClientMock) StreamSeries(ctx context.Context, in *logproto.SeriesRequest, opts ...grpc.CallOption) (logproto.Querier_StreamSeriesClient, error) {
+	return nil, status.Error(codes.Unimplemented, "StreamSeries")
+}
+
 func (c *querierClientMock) TailersCount(ctx context.Context, in *logproto.TailersCountRequest, opts ...grpc.CallOption) (*logproto.TailersCountResponse, error) {
 	args := c.Called(ctx, in, opts)
 	res := args.Get(0)
@@ -104,6 +117,24 @@ func (c *querierClientMock) GetChunkIDs(ctx context.Context, in *logproto.GetChu
 	return res.(*logproto.GetChunkIDsResponse), args.Error(1)
 }
 
+func (c *querierClientMock) GetStats(ctx context.Context, in *logproto.IndexStatsRequest, opts ...grpc.CallOption) (*logproto.IndexStatsResponse, error) {
+	args := c.Called(ctx, in, opts)
+	res := args.Get(0)
+	if res == nil {
+		return (*logproto.IndexStatsResponse)(nil), args.Error(1)
+	}
+	return res.(*logproto.IndexStatsResponse), args.Error(1)
+}
+
+func (c *querierClientMock) GetVolume(ctx context.Context, in *logproto.VolumeRequest, opts ...grpc.CallOption) (*logproto.VolumeResponse, error) {
+	args := c.Called(ctx, in, opts)
+	res := args.Get(0)
+	if res == nil {
+		return (*logproto.VolumeResponse)(nil), args.Error(1)
+	}
+	return res.(*logproto.VolumeResponse), args.Error(1)
+}
+
 func (c *querierClientMock) Context() context.Context {
 	return context.Background()
 }
@@ -112,6 +143,50 @@ func (c *querierClientMock) Close() error {
 	return nil
 }
 
+// streamingQuerierClientMock is a querierClientMock supporting the streaming of labels and series,
+// sending the responses in the given batches.
+type streamingQuerierClientMock struct {
+	*querierClientMock
+	labels []*logproto.LabelResponse
+	series []*logproto.SeriesResponse
+}
+
+func (c *streamingQuerierClientMock) StreamLabel(ctx context.Context, in *logproto.LabelRequest, opts ...grpc.CallOption) (logproto.Querier_StreamLabelClient, error) {
+	return &labelStreamMock{batches: c.labels}, nil
+}
+
+func (c *streamingQuerierClientMock) StreamSeries(ctx context.Context, in *logproto.SeriesRequest, opts ...grpc.CallOption) (logproto.Querier_StreamSeriesClient, error) {
+	return &seriesStreamMock{batches: c.series}, nil
+}
+
+type labelStreamMock struct {
+	grpc.ClientStream
+	batches []*logproto.LabelResponse
+}
+
+func (s *labelStreamMock) Recv() (*logproto.LabelResponse, error) {
+	if len(s.batches) == 0 {
+		return nil, io.EOF
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+type seriesStreamMock struct {
+	grpc.ClientStream
+	batches []*logproto.SeriesResponse
+}
+
+func (s *seriesStreamMock) Recv() (*logproto.SeriesResponse, error) {
+	if len(s.batches) == 0 {
+		return nil, io.EOF
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
 // newIngesterClientMockFactory creates a factory function always returning
 // the input querierClientMock
 func newIngesterClientMockFactory(c *querierClientMock) ring_client.PoolFactory {
@@ -352,7 +427,21 @@ func (s *storeMock) GetSeries(ctx context.Context, userID string, from, through
 }
 
 func (s *storeMock) Stats(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) (*stats.Stats, error) {
-	return nil, nil
+	args := s.Called(ctx, userID, from, through, matchers)
+	res := args.Get(0)
+	if res == nil {
+		return (*stats.Stats)(nil), args.Error(1)
+	}
+	return res.(*stats.Stats), args.Error(1)
+}
+
+func (s *storeMock) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	args := s.Called(ctx, userID, from, through, limit, targetLabels, aggregateBy, matchers)
+	res := args.Get(0)
+	if res == nil {
+		return (*logproto.VolumeResponse)(nil), args.Error(1)
+	}
+	return res.(*logproto.VolumeResponse), args.Error(1)
 }
 
 func (s *storeMock) Stop() {
@@ -425,7 +514,7 @@ func (r *readRingMock) HasInstance(instanceID string) bool {
 }
 
 func (r *readRingMock) ShuffleShardWithLookback(identifier string, size int, lookbackPeriod time.Duration, now time.Time) ring.ReadRing {
-	return r
+	return r.ShuffleShard(identifier, size)
 }
 
 func (r *readRingMock) CleanupShuffleShardCache(identifier string) {}
@@ -520,3 +609,7 @@ func (q *querierMock) Tail(ctx context.Context, req *logproto.TailRequest) (*Tai
 func (q *querierMock) IndexStats(ctx context.Context, req *loghttp.RangeQuery) (*stats.Stats, error) {
 	return nil, nil
 }
+
+func (q *querierMock) Volume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	return nil, nil
+}
@@ -19,10 +19,13 @@ import (
 	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/ingester/client"
+	"github.com/grafana/loki/pkg/loghttp"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/storage"
+	"github.com/grafana/loki/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletion"
+	"github.com/grafana/loki/pkg/util/paging"
 	"github.com/grafana/loki/pkg/validation"
 )
 
@@ -80,6 +83,50 @@ func TestQuerier_Label_QueryTimeoutConfigFlag(t *testing.T) {
 	store.AssertExpectations(t)
 }
 
+func TestQuerier_Label_Paging(t *testing.T) {
+	startTime := time.Now().Add(-1 * time.Minute)
+	endTime := time.Now()
+
+	request := logproto.LabelRequest{
+		Name:   "test",
+		Values: true,
+		Start:  &startTime,
+		End:    &endTime,
+		Limit:  3,
+	}
+
+	ingesterClient := newQuerierClientMock()
+	ingesterClient.On("Label", mock.Anything, mock.Anything, mock.Anything).Return(mockLabelResponse([]string{"a", "c"}), nil)
+
+	store := newStoreMock()
+	store.On("LabelValuesForMetricName", mock.Anything, "test", mock.Anything, mock.Anything, "logs", "test").Return([]string{"b", "d", "e"}, nil)
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	q, err := newQuerier(
+		mockQuerierConfig(),
+		mockIngesterClientConfig(),
+		newIngesterClientMockFactory(ingesterClient),
+		mockReadRingWithOneActiveIngester(),
+		&mockDeleteGettter{},
+		store, limits)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	resp, err := q.Label(ctx, &request)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, resp.Values)
+	require.Equal(t, paging.NextToken("c"), resp.NextPageToken)
+
+	// The ingesters get the page with the request, the store with the context.
+	calls := ingesterClient.GetMockedCallsByMethod("Label")
+	require.Len(t, calls, 1)
+	require.Equal(t, uint32(3), calls[0].Arguments.Get(1).(*logproto.LabelRequest).Limit)
+	calls = store.GetMockedCallsByMethod("LabelValuesForMetricName")
+	require.Len(t, calls, 1)
+	require.Equal(t, paging.Page{Limit: 3}, paging.PageFromContext(calls[0].Arguments.Get(0).(context.Context)))
+}
+
 func TestQuerier_Tail_QueryTimeoutConfigFlag(t *testing.T) {
 	request := logproto.TailRequest{
 		Query:    "{type=\"test\"}",
@@ -357,6 +404,100 @@ func TestQuerier_SeriesAPI(t *testing.T) {
 	}
 }
 
+func TestQuerier_IndexStats(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	end := time.Now()
+	start := end.Add(-6 * time.Hour)
+	req := &loghttp.RangeQuery{
+		Query: `sum(count_over_time({app="foo"}[1h])) / sum(count_over_time({app="bar"}[1h] offset 1h))`,
+		Start: start,
+		End:   end,
+	}
+
+	ingesterClient := newQuerierClientMock()
+	ingesterClient.On("GetStats", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.IndexStatsResponse{Streams: 1, Chunks: 1, Entries: 10, Bytes: 100}, nil)
+
+	store := newStoreMock()
+	mkTime := func(t time.Time) model.Time { return model.TimeFromUnixNano(t.UnixNano()) }
+	// The data selected by range aggregations is moved back in time by their range and offset.
+	store.On("Stats", mock.Anything, "test", mkTime(start.Add(-time.Hour)), mkTime(end), mock.Anything).
+		Return(&stats.Stats{Streams: 2, Chunks: 20, Entries: 200, Bytes: 2000}, nil)
+	store.On("Stats", mock.Anything, "test", mkTime(start.Add(-2*time.Hour)), mkTime(end.Add(-time.Hour)), mock.Anything).
+		Return(&stats.Stats{Streams: 3, Chunks: 30, Entries: 300, Bytes: 3000}, nil)
+
+	conf := mockQuerierConfig()
+	conf.QueryIngestersWithin = 3 * time.Hour
+	q, err := newQuerier(
+		conf,
+		mockIngesterClientConfig(),
+		newIngesterClientMockFactory(ingesterClient),
+		mockReadRingWithOneActiveIngester(),
+		&mockDeleteGettter{},
+		store, limits)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := q.IndexStats(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, &stats.Stats{Streams: 7, Chunks: 52, Entries: 520, Bytes: 5200}, res)
+	ingesterClient.AssertNumberOfCalls(t, "GetStats", 2)
+	store.AssertNumberOfCalls(t, "Stats", 2)
+
+	_, err = q.IndexStats(ctx, &loghttp.RangeQuery{Query: `{app=`, Start: start, End: end})
+	require.Error(t, err)
+}
+
+func TestQuerier_Volume(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+
+	end := time.Now()
+	start := end.Add(-6 * time.Hour)
+	req := &logproto.VolumeRequest{
+		From:     model.TimeFromUnixNano(start.UnixNano()),
+		Through:  model.TimeFromUnixNano(end.UnixNano()),
+		Matchers: `{app=~"foo|bar"}`,
+		Limit:    2,
+	}
+
+	ingesterClient := newQuerierClientMock()
+	ingesterClient.On("GetVolume", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="foo", pod="foo-1"}`, Volume: 10}, {Name: `{app="bar", pod="bar-1"}`, Volume: 5}},
+	}, nil)
+
+	store := newStoreMock()
+	store.On("Volume", mock.Anything, "test", mock.Anything, mock.Anything, int32(2), []string(nil), "", mock.Anything).Return(&logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="bar"}`, Volume: 20}, {Name: `{app="baz"}`, Volume: 1}},
+		Limit:   2,
+	}, nil)
+
+	conf := mockQuerierConfig()
+	conf.QueryIngestersWithin = 3 * time.Hour
+	q, err := newQuerier(
+		conf,
+		mockIngesterClientConfig(),
+		newIngesterClientMockFactory(ingesterClient),
+		mockReadRingWithOneActiveIngester(),
+		&mockDeleteGettter{},
+		store, limits)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := q.Volume(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, &logproto.VolumeResponse{
+		Volumes: []logproto.Volume{{Name: `{app="bar"}`, Volume: 25}, {Name: `{app="foo"}`, Volume: 10}},
+		Limit:   2,
+	}, res)
+	ingesterClient.AssertNumberOfCalls(t, "GetVolume", 1)
+	store.AssertNumberOfCalls(t, "Volume", 1)
+
+	_, err = q.Volume(ctx, &logproto.VolumeRequest{From: req.From, Through: req.Through, Matchers: `{app="foo"}`, AggregateBy: "values"})
+	require.Error(t, err)
+}
+
 func TestQuerier_IngesterMaxQueryLookback(t *testing.T) {
 	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
 	require.NoError(t, err)
@@ -1171,7 +1312,7 @@ func TestQuerier_SelectSamplesWithDeletes(t *testing.T) {
 }
 
 func newQuerier(cfg Config, clientCfg client.Config, clientFactory ring_client.PoolFactory, ring ring.ReadRing, dg *mockDeleteGettter, store storage.Store, limits *validation.Overrides) (*SingleTenantQuerier, error) {
-	iq, err := newIngesterQuerier(clientCfg, ring, cfg.ExtraQueryDelay, clientFactory)
+	iq, err := newIngesterQuerier(clientCfg, ring, cfg.ExtraQueryDelay, limits, cfg.QueryIngestersWithin, clientFactory)
 	if err != nil {
 		return nil, err
 	}
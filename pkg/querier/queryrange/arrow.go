@@ -0,0 +1,110 @@
+package queryrange
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/marshal/arrow"
+)
+
+const arrowCtxKey ctxKeyType = "arrow"
+
+var (
+	arrowStreamsFields = []arrow.Field{
+		{Name: "labels", Type: arrow.String},
+		{Name: "timestamp", Type: arrow.TimestampNanos},
+		{Name: "line", Type: arrow.String},
+	}
+	arrowMatrixFields = []arrow.Field{
+		{Name: "labels", Type: arrow.String},
+		{Name: "timestamp", Type: arrow.TimestampMillis},
+		{Name: "value", Type: arrow.Float64},
+	}
+)
+
+// acceptsArrow returns whether the client asked for the results of its query in the Arrow IPC streaming format.
+func acceptsArrow(req *http.Request) bool {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if mt, _, err := mime.ParseMediaType(mediaType); err == nil && mt == arrow.ContentType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withArrowResponse makes the codec encode the range query responses in the Arrow IPC streaming format.
+func withArrowResponse(req *http.Request) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), arrowCtxKey, true))
+}
+
+// encodeArrowResponse encodes the streams or the matrix of a range query response in the Arrow IPC streaming
+// format, one record batch per stream or series. The labels are the first column, and the statistics are left out.
+// It returns false if the response can't be encoded in this format.
+func encodeArrowResponse(ctx context.Context, res queryrangebase.Response) (*http.Response, bool, error) {
+	if v, _ := ctx.Value(arrowCtxKey).(bool); !v {
+		return nil, false, nil
+	}
+
+	var buf bytes.Buffer
+	switch response := res.(type) {
+	case *LokiResponse:
+		w := arrow.NewWriter(&buf, arrowStreamsFields...)
+		for _, stream := range response.Data.Result {
+			lbls := make([]string, len(stream.Entries))
+			timestamps := make([]int64, len(stream.Entries))
+			lines := make([]string, len(stream.Entries))
+			for i, e := range stream.Entries {
+				lbls[i] = stream.Labels
+				timestamps[i] = e.Timestamp.UnixNano()
+				lines[i] = e.Line
+			}
+			if err := w.Write(lbls, timestamps, lines); err != nil {
+				return nil, true, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, true, err
+		}
+	case *LokiPromResponse:
+		if response.Response.Data.ResultType != loghttp.ResultTypeMatrix {
+			return nil, false, nil
+		}
+		w := arrow.NewWriter(&buf, arrowMatrixFields...)
+		for _, series := range response.Response.Data.Result {
+			ls := logproto.FromLabelAdaptersToLabels(series.Labels).String()
+			lbls := make([]string, len(series.Samples))
+			timestamps := make([]int64, len(series.Samples))
+			values := make([]float64, len(series.Samples))
+			for i, s := range series.Samples {
+				lbls[i] = ls
+				timestamps[i] = s.TimestampMs
+				values[i] = s.Value
+			}
+			if err := w.Write(lbls, timestamps, values); err != nil {
+				return nil, true, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, true, err
+		}
+	default:
+		return nil, false, nil
+	}
+
+	return &http.Response{
+		Header: http.Header{
+			"Content-Type": []string{arrow.ContentType},
+		},
+		Body:       io.NopCloser(&buf),
+		StatusCode: http.StatusOK,
+	}, true, nil
+}
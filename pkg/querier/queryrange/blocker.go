@@ -0,0 +1,60 @@
+package queryrange
+
+import (
+	"net/http"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+const errQueryBlocked = "the query is blocked by the Loki operator (query hash: %d)"
+
+// BlockedQueriesLimits returns the queries the query-frontend rejects per tenant.
+type BlockedQueriesLimits interface {
+	BlockedQueries(string) []validation.BlockedQuery
+}
+
+// checkBlockedQuery returns an error if the LogQL query of the request is blocked for one of the tenants.
+func checkBlockedQuery(logger log.Logger, tenantIDs []string, limits BlockedQueriesLimits, r queryrangebase.Request) error {
+	var query string
+	switch req := r.(type) {
+	case *LokiRequest:
+		query = req.Query
+	case *LokiInstantRequest:
+		query = req.Query
+	default:
+		return nil
+	}
+
+	var (
+		hash      uint32
+		queryType string
+		hashed    bool
+	)
+	for _, tenantID := range tenantIDs {
+		blocked := limits.BlockedQueries(tenantID)
+		if len(blocked) == 0 {
+			continue
+		}
+		if !hashed {
+			hash = util.HashedQuery(query)
+			// the queries which can't be parsed are only matched by the blocked queries of all types
+			queryType, _ = logql.QueryType(query)
+			hashed = true
+		}
+
+		for i := range blocked {
+			if blocked[i].Matches(query, hash, queryType) {
+				level.Info(logger).Log("msg", "blocked query", "tenant", tenantID, "query", query, "query_hash", hash, "pattern", blocked[i].Pattern, "regex", blocked[i].Regex)
+				return httpgrpc.Errorf(http.StatusBadRequest, errQueryBlocked, hash)
+			}
+		}
+	}
+	return nil
+}
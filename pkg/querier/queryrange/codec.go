@@ -30,6 +30,7 @@ import (
 	"github.com/grafana/loki/pkg/util/httpreq"
 	"github.com/grafana/loki/pkg/util/marshal"
 	marshal_legacy "github.com/grafana/loki/pkg/util/marshal/legacy"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 var LokiCodec = &Codec{}
@@ -64,6 +65,12 @@ func (r *LokiRequest) WithQuery(query string) queryrangebase.Request {
 	return &new
 }
 
+func (r *LokiRequest) WithLimit(limit uint32) *LokiRequest {
+	new := *r
+	new.Limit = limit
+	return &new
+}
+
 func (r *LokiRequest) WithShards(shards logql.Shards) *LokiRequest {
 	new := *r
 	new.Shards = shards.Encode()
@@ -244,11 +251,13 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, forwardHeaders []
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 		}
 		return &LokiSeriesRequest{
-			Match:   req.Groups,
-			StartTs: req.Start.UTC(),
-			EndTs:   req.End.UTC(),
-			Path:    r.URL.Path,
-			Shards:  req.Shards,
+			Match:     req.Groups,
+			StartTs:   req.Start.UTC(),
+			EndTs:     req.End.UTC(),
+			Path:      r.URL.Path,
+			Shards:    req.Shards,
+			Limit:     req.Limit,
+			PageToken: req.PageToken,
 		}, nil
 	case LabelNamesOp:
 		req, err := loghttp.ParseLabelQuery(r)
@@ -256,9 +265,11 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, forwardHeaders []
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 		}
 		return &LokiLabelNamesRequest{
-			StartTs: *req.Start,
-			EndTs:   *req.End,
-			Path:    r.URL.Path,
+			StartTs:   *req.Start,
+			EndTs:     *req.End,
+			Path:      r.URL.Path,
+			Limit:     req.Limit,
+			PageToken: req.PageToken,
 		}, nil
 	case IndexStatsOp:
 		req, err := loghttp.ParseIndexStatsQuery(r)
@@ -282,6 +293,9 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 	if queryTags != "" {
 		header.Set(string(httpreq.QueryTagsHTTPHeader), queryTags)
 	}
+	if priority, ok := ctx.Value(httpreq.QueryPriorityHTTPHeader).(httpreq.QueryPriority); ok {
+		header.Set(priority.Header, priority.Value)
+	}
 
 	switch request := r.(type) {
 	case *LokiRequest:
@@ -324,6 +338,7 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 		if len(request.Shards) > 0 {
 			params["shards"] = request.Shards
 		}
+		encodePage(params, request.Limit, request.PageToken)
 		u := &url.URL{
 			Path:     "/loki/api/v1/series",
 			RawQuery: params.Encode(),
@@ -341,6 +356,7 @@ func (Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*http
 			"start": []string{fmt.Sprintf("%d", request.StartTs.UnixNano())},
 			"end":   []string{fmt.Sprintf("%d", request.EndTs.UnixNano())},
 		}
+		encodePage(params, request.Limit, request.PageToken)
 
 		u := &url.URL{
 			Path:     request.Path, // NOTE: this could be either /label or /label/{name}/values endpoint. So forward the original path as it is.
@@ -438,10 +454,11 @@ func (Codec) DecodeResponse(ctx context.Context, r *http.Response, req queryrang
 		}
 
 		return &LokiSeriesResponse{
-			Status:  resp.Status,
-			Version: uint32(loghttp.GetVersion(req.Path)),
-			Data:    data,
-			Headers: httpResponseHeadersToPromResponseHeaders(r.Header),
+			Status:        resp.Status,
+			Version:       uint32(loghttp.GetVersion(req.Path)),
+			Data:          data,
+			Headers:       httpResponseHeadersToPromResponseHeaders(r.Header),
+			NextPageToken: resp.NextPageToken,
 		}, nil
 	case *LokiLabelNamesRequest:
 		var resp loghttp.LabelResponse
@@ -449,10 +466,11 @@ func (Codec) DecodeResponse(ctx context.Context, r *http.Response, req queryrang
 			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "error decoding response: %v", err)
 		}
 		return &LokiLabelNamesResponse{
-			Status:  resp.Status,
-			Version: uint32(loghttp.GetVersion(req.Path)),
-			Data:    resp.Data,
-			Headers: httpResponseHeadersToPromResponseHeaders(r.Header),
+			Status:        resp.Status,
+			Version:       uint32(loghttp.GetVersion(req.Path)),
+			Data:          resp.Data,
+			Headers:       httpResponseHeadersToPromResponseHeaders(r.Header),
+			NextPageToken: resp.NextPageToken,
 		}, nil
 	case *logproto.IndexStatsRequest:
 		var resp logproto.IndexStatsResponse
@@ -530,6 +548,11 @@ func (Codec) DecodeResponse(ctx context.Context, r *http.Response, req queryrang
 func (Codec) EncodeResponse(ctx context.Context, res queryrangebase.Response) (*http.Response, error) {
 	sp, _ := opentracing.StartSpanFromContext(ctx, "codec.EncodeResponse")
 	defer sp.Finish()
+
+	if resp, ok, err := encodeArrowResponse(ctx, res); ok || err != nil {
+		return resp, err
+	}
+
 	var buf bytes.Buffer
 
 	switch response := res.(type) {
@@ -560,7 +583,8 @@ func (Codec) EncodeResponse(ctx context.Context, res queryrangebase.Response) (*
 
 	case *LokiSeriesResponse:
 		result := logproto.SeriesResponse{
-			Series: response.Data,
+			Series:        response.Data,
+			NextPageToken: response.NextPageToken,
 		}
 		if err := marshal.WriteSeriesResponseJSON(result, &buf); err != nil {
 			return nil, err
@@ -571,7 +595,7 @@ func (Codec) EncodeResponse(ctx context.Context, res queryrangebase.Response) (*
 				return nil, err
 			}
 		} else {
-			if err := marshal.WriteLabelResponseJSON(logproto.LabelResponse{Values: response.Data}, &buf); err != nil {
+			if err := marshal.WriteLabelResponseJSON(logproto.LabelResponse{Values: response.Data, NextPageToken: response.NextPageToken}, &buf); err != nil {
 				return nil, err
 			}
 		}
@@ -593,6 +617,9 @@ func (Codec) EncodeResponse(ctx context.Context, res queryrangebase.Response) (*
 		Body:       io.NopCloser(&buf),
 		StatusCode: http.StatusOK,
 	}
+	if isTruncated(res) {
+		resp.Header.Set(TruncatedResponseHeader, "true")
+	}
 	return &resp, nil
 }
 
@@ -669,6 +696,47 @@ func (Codec) MergeResponse(responses ...queryrangebase.Response) (queryrangebase
 	}
 }
 
+// encodePage adds the limit and page token of a paged label or series request to params.
+func encodePage(params url.Values, limit uint32, token string) {
+	if limit > 0 {
+		params["limit"] = []string{fmt.Sprintf("%d", limit)}
+	}
+	if token != "" {
+		params["page_token"] = []string{token}
+	}
+}
+
+// pageResponse returns the page requested by a paged label or series request of its merged response,
+// as merging the pages of the split or sharded requests results in up to one page per request.
+func pageResponse(req queryrangebase.Request, resp queryrangebase.Response) (queryrangebase.Response, error) {
+	var err error
+	switch r := req.(type) {
+	case *LokiSeriesRequest:
+		res, ok := resp.(*LokiSeriesResponse)
+		if !ok {
+			return resp, nil
+		}
+		res.Data, res.NextPageToken, err = paging.Series(res.Data, paging.Page{Limit: int(r.Limit), Token: r.PageToken})
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+	case *LokiLabelNamesRequest:
+		res, ok := resp.(*LokiLabelNamesResponse)
+		if !ok {
+			return resp, nil
+		}
+		page := paging.Page{Limit: int(r.Limit), Token: r.PageToken}
+		if page.Enabled() {
+			sort.Strings(res.Data)
+		}
+		res.Data, res.NextPageToken, err = paging.Strings(res.Data, page)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+	}
+	return resp, nil
+}
+
 // mergeOrderedNonOverlappingStreams merges a set of ordered, nonoverlapping responses by concatenating matching streams then running them through a heap to pull out limit values
 func mergeOrderedNonOverlappingStreams(resps []*LokiResponse, limit uint32, direction logproto.Direction) []logproto.Stream {
 	var total int
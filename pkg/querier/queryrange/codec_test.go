@@ -19,6 +19,8 @@ import (
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/pkg/util"
+	"github.com/grafana/loki/pkg/util/marshal/arrow"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 func init() {
@@ -350,6 +352,51 @@ func Test_codec_labels_EncodeRequest(t *testing.T) {
 	require.Equal(t, "/loki/api/v1/labels/__name__/values", req.(*LokiLabelNamesRequest).Path)
 }
 
+func Test_codec_paged_EncodeRequest(t *testing.T) {
+	token := paging.NextToken("foo")
+	for _, toEncode := range []queryrangebase.Request{
+		&LokiSeriesRequest{Match: []string{`{foo="bar"}`}, Path: "/loki/api/v1/series", StartTs: start, EndTs: end, Limit: 10, PageToken: token},
+		&LokiLabelNamesRequest{Path: "/loki/api/v1/labels", StartTs: start, EndTs: end, Limit: 10, PageToken: token},
+	} {
+		got, err := LokiCodec.EncodeRequest(context.Background(), toEncode)
+		require.NoError(t, err)
+		require.Equal(t, "10", got.URL.Query().Get("limit"))
+		require.Equal(t, token, got.URL.Query().Get("page_token"))
+
+		req, err := LokiCodec.DecodeRequest(context.TODO(), got, nil)
+		require.NoError(t, err)
+		require.Equal(t, toEncode, req)
+	}
+}
+
+func Test_pageResponse(t *testing.T) {
+	// The merged pages of two split requests.
+	labelsResp, err := pageResponse(
+		&LokiLabelNamesRequest{Limit: 2, PageToken: paging.NextToken("a")},
+		&LokiLabelNamesResponse{Status: "success", Data: []string{"b", "d", "c"}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &LokiLabelNamesResponse{Status: "success", Data: []string{"b", "c"}, NextPageToken: paging.NextToken("c")}, labelsResp)
+
+	seriesResp, err := pageResponse(
+		&LokiSeriesRequest{Limit: 2},
+		&LokiSeriesResponse{Status: "success", Data: []logproto.SeriesIdentifier{
+			{Labels: map[string]string{"a": "2"}},
+			{Labels: map[string]string{"a": "1"}},
+		}},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &LokiSeriesResponse{Status: "success", Data: []logproto.SeriesIdentifier{
+		{Labels: map[string]string{"a": "1"}},
+		{Labels: map[string]string{"a": "2"}},
+	}, NextPageToken: paging.NextToken(`{a="2"}`)}, seriesResp)
+
+	// Unpaged requests get the merged response as is.
+	labelsResp, err = pageResponse(&LokiLabelNamesRequest{}, &LokiLabelNamesResponse{Data: []string{"b", "a"}})
+	require.NoError(t, err)
+	require.Equal(t, &LokiLabelNamesResponse{Data: []string{"b", "a"}}, labelsResp)
+}
+
 func Test_codec_index_stats_EncodeRequest(t *testing.T) {
 	from, through := util.RoundToMilliseconds(start, end)
 	toEncode := &logproto.IndexStatsRequest{
@@ -464,6 +511,45 @@ func Test_codec_EncodeResponse(t *testing.T) {
 	}
 }
 
+func Test_codec_EncodeArrowResponse(t *testing.T) {
+	ctx := context.WithValue(context.Background(), arrowCtxKey, true)
+
+	res := &LokiResponse{
+		Status:    loghttp.QueryStatusSuccess,
+		Direction: logproto.FORWARD,
+		Version:   uint32(loghttp.VersionV1),
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+			Result:     logStreams,
+		},
+	}
+	var expected bytes.Buffer
+	w := arrow.NewWriter(&expected, arrowStreamsFields...)
+	for _, s := range logStreams {
+		var lbls, lines []string
+		var timestamps []int64
+		for _, e := range s.Entries {
+			lbls = append(lbls, s.Labels)
+			timestamps = append(timestamps, e.Timestamp.UnixNano())
+			lines = append(lines, e.Line)
+		}
+		require.NoError(t, w.Write(lbls, timestamps, lines))
+	}
+	require.NoError(t, w.Close())
+
+	got, err := LokiCodec.EncodeResponse(ctx, res)
+	require.NoError(t, err)
+	require.Equal(t, arrow.ContentType, got.Header.Get("Content-Type"))
+	body, err := io.ReadAll(got.Body)
+	require.NoError(t, err)
+	require.Equal(t, expected.Bytes(), body)
+
+	// the other responses are still encoded in JSON
+	got, err = LokiCodec.EncodeResponse(ctx, &LokiSeriesResponse{Status: "success", Version: uint32(loghttp.VersionV1), Data: seriesData})
+	require.NoError(t, err)
+	require.Equal(t, "application/json", got.Header.Get("Content-Type"))
+}
+
 func Test_codec_MergeResponse(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -954,7 +1040,8 @@ var (
 				},
 				"chunksDownloadTime": 0,
 				"totalChunksRef": 0,
-				"totalChunksDownloaded": 0
+				"totalChunksDownloaded": 0,
+				"totalChunksDownloadedBytes": 0
 			},
 			"totalBatches": 6,
 			"totalChunksMatched": 7,
@@ -973,7 +1060,8 @@ var (
 				},
 				"chunksDownloadTime": 16,
 				"totalChunksRef": 17,
-				"totalChunksDownloaded": 18
+				"totalChunksDownloaded": 18,
+				"totalChunksDownloadedBytes": 0
 			}
 		},
 		"cache": {
@@ -36,9 +36,13 @@ type Limits interface {
 	queryrangebase.Limits
 	logql.Limits
 	QuerySplitDuration(string) time.Duration
+	QuerySplitTargetBytes(string) int
 	MaxQuerySeries(string) int
 	MaxEntriesLimitPerQuery(string) int
 	MinShardingLookback(string) time.Duration
+	BlockedQueriesLimits
+	ResponseSizeLimits
+	MultiTenantQueryAllowlist(string) []string
 }
 
 type limits struct {
@@ -100,6 +104,14 @@ func (l limitsMiddleware) Do(ctx context.Context, r queryrangebase.Request) (que
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
 	}
 
+	if id, other, ok := validation.MultiTenantQueryAllowed(tenantIDs, l.MultiTenantQueryAllowlist); !ok {
+		return nil, httpgrpc.Errorf(http.StatusForbidden, validation.ErrMultiTenantQueryNotAllowed, id, other)
+	}
+
+	if err := checkBlockedQuery(log, tenantIDs, l.Limits, r); err != nil {
+		return nil, err
+	}
+
 	// Clamp the time range based on the max query lookback.
 
 	if maxQueryLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, l.MaxQueryLookback); maxQueryLookback > 0 {
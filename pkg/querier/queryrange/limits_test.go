@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/dskit/tenant"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/require"
@@ -18,8 +19,10 @@ import (
 	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/marshal"
+	"github.com/grafana/loki/pkg/validation"
 )
 
 func TestLimits(t *testing.T) {
@@ -282,3 +285,123 @@ func Test_GenerateCacheKey_NoDivideZero(t *testing.T) {
 		l.GenerateCacheKey("foo", r),
 	)
 }
+
+func Test_BlockedQueries(t *testing.T) {
+	tpw, stopper, err := NewTripperware(testConfig, util_log.Logger, fakeLimits{
+		maxQueryParallelism: 1,
+		blockedQueries: []validation.BlockedQuery{
+			{Pattern: `{app="foo"} |= "foo"`, Types: []string{"filter"}},
+			{Hash: util.HashedQuery(`sum(rate({app="foo"}[1d]))`)},
+		},
+	}, config.SchemaConfig{}, nil, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+	rt, err := newfakeRoundTripper()
+	require.NoError(t, err)
+	defer rt.Close()
+
+	for _, tc := range []struct {
+		query   string
+		blocked bool
+	}{
+		{query: `{app="foo"} |= "foo"`, blocked: true},
+		{query: `sum(rate({app="foo"}[1d]))`, blocked: true},
+		{query: `{app="foo"} |= "bar"`},
+		{query: `sum(rate({app="foo"}[1h]))`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			count, h := counter()
+			rt.setHandler(h)
+
+			lreq := &LokiRequest{
+				Query:     tc.query,
+				Limit:     1000,
+				Step:      30000,
+				StartTs:   testTime.Add(-time.Hour),
+				EndTs:     testTime,
+				Direction: logproto.FORWARD,
+				Path:      "/loki/api/v1/query_range",
+			}
+
+			ctx := user.InjectOrgID(context.Background(), "1")
+			req, err := LokiCodec.EncodeRequest(ctx, lreq)
+			require.NoError(t, err)
+
+			req = req.WithContext(ctx)
+			err = user.InjectOrgIDIntoHTTPRequest(ctx, req)
+			require.NoError(t, err)
+
+			_, err = tpw(rt).RoundTrip(req)
+			if !tc.blocked {
+				require.NotZero(t, *count)
+				return
+			}
+			require.Zero(t, *count)
+			require.EqualError(t, err, fmt.Sprintf("rpc error: code = Code(400) desc = the query is blocked by the Loki operator (query hash: %d)", util.HashedQuery(tc.query)))
+		})
+	}
+}
+
+func Test_MultiTenantQueryAllowlist(t *testing.T) {
+	tenant.WithDefaultResolver(tenant.NewMultiResolver())
+	defer tenant.WithDefaultResolver(tenant.NewSingleResolver())
+
+	tpw, stopper, err := NewTripperware(testConfig, util_log.Logger, fakeLimits{
+		maxQueryParallelism: 1,
+		multiTenantAllowlist: map[string][]string{
+			"a": {"b"},
+			"b": {"*"},
+			"c": {"a", "b"},
+		},
+	}, config.SchemaConfig{}, nil, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+	rt, err := newfakeRoundTripper()
+	require.NoError(t, err)
+	defer rt.Close()
+
+	for _, tc := range []struct {
+		orgID      string
+		notAllowed string
+	}{
+		{orgID: "a"},
+		{orgID: "a|b"},
+		{orgID: "b|c"},
+		{orgID: "a|c", notAllowed: "tenant a can't be queried together with tenant c"},
+		{orgID: "b|c|a", notAllowed: "tenant a can't be queried together with tenant c"},
+	} {
+		t.Run(tc.orgID, func(t *testing.T) {
+			count, h := counter()
+			rt.setHandler(h)
+
+			lreq := &LokiRequest{
+				Query:     `{app="foo"} |= "foo"`,
+				Limit:     1000,
+				StartTs:   testTime.Add(-time.Hour),
+				EndTs:     testTime,
+				Direction: logproto.FORWARD,
+				Path:      "/loki/api/v1/query_range",
+			}
+
+			ctx := user.InjectOrgID(context.Background(), tc.orgID)
+			req, err := LokiCodec.EncodeRequest(ctx, lreq)
+			require.NoError(t, err)
+
+			req = req.WithContext(ctx)
+			err = user.InjectOrgIDIntoHTTPRequest(ctx, req)
+			require.NoError(t, err)
+
+			_, err = tpw(rt).RoundTrip(req)
+			if tc.notAllowed == "" {
+				require.NotZero(t, *count)
+				return
+			}
+			require.Zero(t, *count)
+			require.EqualError(t, err, "rpc error: code = Code(403) desc = "+tc.notAllowed+", see the multi_tenant_query_allowlist limit")
+		})
+	}
+}
@@ -46,31 +46,38 @@ func NewLogResultCacheMetrics(registerer prometheus.Registerer) *LogResultCacheM
 }
 
 // NewLogResultCache creates a new log result cache middleware.
-// Currently it only caches empty filter queries, this is because those are usually easily and freely cacheable.
-// Log hits are difficult to handle because of the limit query parameter and the size of the response.
-// In the future it could be extended to cache non-empty query results.
+// It works on the subqueries of the split by interval middleware and caches:
+//   - empty results, extending the cached range as adjacent empty ranges are queried.
+//   - complete results of subqueries covering a whole split interval, that is results which weren't
+//     truncated by the limit. They are then used to answer any query within that interval,
+//     whatever its limit and direction.
+//
+// Only subqueries older than the max cache freshness are cached, as more recent logs can still be ingested.
+// Complete results are keyed by the results cache generation number so that deletions invalidate them.
 // see https://docs.google.com/document/d/1_mACOpxdWZ5K0cIedaja5gzMbv-m0lUVazqZd2O4mEU/edit
-func NewLogResultCache(logger log.Logger, limits Limits, cache cache.Cache, shouldCache queryrangebase.ShouldCacheFn, metrics *LogResultCacheMetrics) queryrangebase.Middleware {
+func NewLogResultCache(logger log.Logger, limits Limits, cache cache.Cache, cacheGenNumLoader queryrangebase.CacheGenNumberLoader, shouldCache queryrangebase.ShouldCacheFn, metrics *LogResultCacheMetrics) queryrangebase.Middleware {
 	if metrics == nil {
 		metrics = NewLogResultCacheMetrics(nil)
 	}
 	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
 		return &logResultCache{
-			next:        next,
-			limits:      limits,
-			cache:       cache,
-			logger:      logger,
-			shouldCache: shouldCache,
-			metrics:     metrics,
+			next:              next,
+			limits:            limits,
+			cache:             cache,
+			cacheGenNumLoader: cacheGenNumLoader,
+			logger:            logger,
+			shouldCache:       shouldCache,
+			metrics:           metrics,
 		}
 	})
 }
 
 type logResultCache struct {
-	next        queryrangebase.Handler
-	limits      Limits
-	cache       cache.Cache
-	shouldCache queryrangebase.ShouldCacheFn
+	next              queryrangebase.Handler
+	limits            Limits
+	cache             cache.Cache
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader
+	shouldCache       queryrangebase.ShouldCacheFn
 
 	metrics *LogResultCacheMetrics
 	logger  log.Logger
@@ -106,34 +113,61 @@ func (l *logResultCache) Do(ctx context.Context, req queryrangebase.Request) (qu
 	alignedStart := time.Unix(0, lokiReq.GetStartTs().UnixNano()-(lokiReq.GetStartTs().UnixNano()%interval.Nanoseconds()))
 	// generate the cache key based on query, tenant and start time.
 	cacheKey := fmt.Sprintf("log:%s:%s:%d:%d", tenant.JoinTenantIDs(tenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
+	// complete results are also keyed by the cache generation number, which is bumped when logs are deleted.
+	cacheGen := l.cacheGen(tenantIDs)
+	resultCacheKey := fmt.Sprintf("logresult:%s:%s:%s:%d:%d", cacheGen, tenant.JoinTenantIDs(tenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
+	// the interval this subquery belongs to, complete results are only cached for subqueries covering all of it.
+	cacheInterval := lokiReq.WithStartEndTime(alignedStart, alignedStart.Add(interval))
 
-	_, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey)})
+	found, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey), cache.HashKey(resultCacheKey)})
 	if err != nil {
 		level.Warn(l.logger).Log("msg", "error fetching cache", "err", err, "cacheKey", cacheKey)
 		return l.next.Do(ctx, req)
 	}
-	// we expect only one key to be found or missing.
-	if len(buff) > 1 {
+	// we expect each key to be found at most once.
+	if len(buff) > 2 || len(found) != len(buff) {
 		level.Warn(l.logger).Log("msg", "unexpected length of cache return values", "buff", len(buff))
 		return l.next.Do(ctx, req)
 	}
+	cached := make(map[string][]byte, len(found))
+	for i, key := range found {
+		cached[key] = buff[i]
+	}
+
+	if data, ok := cached[cache.HashKey(resultCacheKey)]; ok && !lokiReq.GetEndTs().After(cacheInterval.GetEndTs()) {
+		var cachedResponse LokiResponse
+		if err := proto.Unmarshal(data, &cachedResponse); err != nil {
+			level.Warn(l.logger).Log("msg", "error unmarshalling response from cache", "err", err)
+			return l.next.Do(ctx, req)
+		}
+		l.metrics.CacheHit.Inc()
+		return completeResponse(lokiReq, &cachedResponse), nil
+	}
 
-	if len(buff) == 0 {
+	data, ok := cached[cache.HashKey(cacheKey)]
+	if !ok {
 		// cache miss
-		return l.handleMiss(ctx, cacheKey, lokiReq)
+		return l.handleMiss(ctx, cacheKey, resultCacheKey, cacheGen, tenantIDs, cacheInterval, lokiReq)
 	}
 
 	// cache hit
 	var cachedRequest LokiRequest
-	err = proto.Unmarshal(buff[0], &cachedRequest)
+	err = proto.Unmarshal(data, &cachedRequest)
 	if err != nil {
 		level.Warn(l.logger).Log("msg", "error unmarshalling request from cache", "err", err)
 		return l.next.Do(ctx, req)
 	}
-	return l.handleHit(ctx, cacheKey, &cachedRequest, lokiReq)
+	return l.handleHit(ctx, cacheKey, resultCacheKey, cacheGen, tenantIDs, cacheInterval, &cachedRequest, lokiReq)
+}
+
+func (l *logResultCache) cacheGen(tenantIDs []string) string {
+	if l.cacheGenNumLoader == nil {
+		return ""
+	}
+	return l.cacheGenNumLoader.GetResultsCacheGenNumber(tenantIDs)
 }
 
-func (l *logResultCache) handleMiss(ctx context.Context, cacheKey string, req *LokiRequest) (queryrangebase.Response, error) {
+func (l *logResultCache) handleMiss(ctx context.Context, cacheKey, resultCacheKey, cacheGen string, tenantIDs []string, cacheInterval, req *LokiRequest) (queryrangebase.Response, error) {
 	l.metrics.CacheMiss.Inc()
 	level.Debug(l.logger).Log("msg", "cache miss", "key", cacheKey)
 	resp, err := l.next.Do(ctx, req)
@@ -144,8 +178,8 @@ func (l *logResultCache) handleMiss(ctx context.Context, cacheKey string, req *L
 	if !ok {
 		return nil, fmt.Errorf("unexpected response type %T", resp)
 	}
-	// At the moment we only cache empty results
 	if !isEmpty(lokiRes) {
+		l.storeCompleteResponse(ctx, resultCacheKey, cacheGen, tenantIDs, cacheInterval, req, lokiRes)
 		return resp, nil
 	}
 	data, err := proto.Marshal(req)
@@ -161,7 +195,7 @@ func (l *logResultCache) handleMiss(ctx context.Context, cacheKey string, req *L
 	return resp, nil
 }
 
-func (l *logResultCache) handleHit(ctx context.Context, cacheKey string, cachedRequest *LokiRequest, lokiReq *LokiRequest) (queryrangebase.Response, error) {
+func (l *logResultCache) handleHit(ctx context.Context, cacheKey, resultCacheKey, cacheGen string, tenantIDs []string, cacheInterval, cachedRequest, lokiReq *LokiRequest) (queryrangebase.Response, error) {
 	l.metrics.CacheHit.Inc()
 	// we start with an empty response
 	result := emptyResponse(cachedRequest)
@@ -258,9 +292,32 @@ func (l *logResultCache) handleHit(ctx context.Context, cacheKey string, cachedR
 			level.Warn(l.logger).Log("msg", "error storing cache", "err", err)
 		}
 	}
+	if !isEmpty(result) {
+		l.storeCompleteResponse(ctx, resultCacheKey, cacheGen, tenantIDs, cacheInterval, lokiReq, result)
+	}
 	return result, nil
 }
 
+// storeCompleteResponse caches the response of a subquery covering a whole split interval,
+// as long as it was not truncated by the limit and no deletion happened while it was executed.
+func (l *logResultCache) storeCompleteResponse(ctx context.Context, resultCacheKey, cacheGen string, tenantIDs []string, cacheInterval, req *LokiRequest, resp *LokiResponse) {
+	if resp.Status != loghttp.QueryStatusSuccess ||
+		!req.GetStartTs().Equal(cacheInterval.GetStartTs()) || !req.GetEndTs().Equal(cacheInterval.GetEndTs()) ||
+		countEntries(resp) >= int(req.Limit) || l.cacheGen(tenantIDs) != cacheGen {
+		return
+	}
+	cached := *resp
+	cached.Statistics = stats.Result{}
+	data, err := proto.Marshal(&cached)
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error marshalling response", "err", err)
+		return
+	}
+	if err := l.cache.Store(ctx, []string{cache.HashKey(resultCacheKey)}, [][]byte{data}); err != nil {
+		level.Warn(l.logger).Log("msg", "error storing cache", "err", err)
+	}
+}
+
 func isEmpty(lokiRes *LokiResponse) bool {
 	return lokiRes.Status == loghttp.QueryStatusSuccess && len(lokiRes.Data.Result) == 0
 }
@@ -278,3 +335,40 @@ func emptyResponse(lokiReq *LokiRequest) *LokiResponse {
 		},
 	}
 }
+
+// completeResponse answers a request from the cached complete response of its split interval:
+// entries are filtered to the request range, then ordered and limited according to the request.
+func completeResponse(lokiReq *LokiRequest, cached *LokiResponse) *LokiResponse {
+	filtered := &LokiResponse{}
+	for _, stream := range cached.Data.Result {
+		entries := make([]logproto.Entry, 0, len(stream.Entries))
+		for _, e := range stream.Entries {
+			if !e.Timestamp.Before(lokiReq.GetStartTs()) && e.Timestamp.Before(lokiReq.GetEndTs()) {
+				entries = append(entries, e)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if cached.Direction != lokiReq.Direction {
+			for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+				entries[i], entries[j] = entries[j], entries[i]
+			}
+		}
+		filtered.Data.Result = append(filtered.Data.Result, logproto.Stream{Labels: stream.Labels, Entries: entries})
+	}
+
+	result := emptyResponse(lokiReq)
+	if len(filtered.Data.Result) > 0 {
+		result.Data.Result = mergeOrderedNonOverlappingStreams([]*LokiResponse{filtered}, lokiReq.Limit, lokiReq.Direction)
+	}
+	return result
+}
+
+func countEntries(lokiRes *LokiResponse) int {
+	var n int
+	for _, stream := range lokiRes.Data.Result {
+		n += len(stream.Entries)
+	}
+	return n
+}
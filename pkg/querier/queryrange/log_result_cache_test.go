@@ -29,6 +29,7 @@ func Test_LogResultCacheSameRange(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -69,6 +70,7 @@ func Test_LogResultCacheSameRangeNonEmpty(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -115,6 +117,7 @@ func Test_LogResultCacheSmallerRange(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -161,6 +164,7 @@ func Test_LogResultCacheDifferentRange(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -230,6 +234,7 @@ func Test_LogResultCacheDifferentRangeNonEmpty(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -308,6 +313,7 @@ func Test_LogResultCacheDifferentRangeNonEmptyAndEmpty(t *testing.T) {
 			cache.NewMockCache(),
 			nil,
 			nil,
+			nil,
 		)
 	)
 
@@ -393,6 +399,200 @@ func Test_LogResultCacheDifferentRangeNonEmptyAndEmpty(t *testing.T) {
 	fake.AssertExpectations(t)
 }
 
+func Test_LogResultCacheCompleteResults(t *testing.T) {
+	var (
+		ctx = user.InjectOrgID(context.Background(), "foo")
+		lrc = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splits: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			nil,
+			nil,
+			nil,
+		)
+	)
+
+	req := &LokiRequest{
+		StartTs:   time.Unix(0, time.Minute.Nanoseconds()),
+		EndTs:     time.Unix(0, 2*time.Minute.Nanoseconds()),
+		Limit:     10,
+		Direction: logproto.FORWARD,
+	}
+	resp := streamsResponse(req,
+		logproto.Stream{Labels: `{foo="bar"}`, Entries: []logproto.Entry{
+			{Timestamp: time.Unix(70, 0), Line: "1"},
+			{Timestamp: time.Unix(100, 0), Line: "2"},
+		}},
+		logproto.Stream{Labels: `{foo="baz"}`, Entries: []logproto.Entry{
+			{Timestamp: time.Unix(110, 0), Line: "3"},
+		}},
+	)
+
+	// only the first request is sent downstream.
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: resp,
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	res, err := h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp, res)
+
+	res, err = h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp, res)
+
+	// a smaller range in the other direction with a lower limit.
+	smaller := &LokiRequest{
+		StartTs:   time.Unix(90, 0),
+		EndTs:     time.Unix(110, 0),
+		Limit:     1,
+		Direction: logproto.BACKWARD,
+	}
+	res, err = h.Do(ctx, smaller)
+	require.NoError(t, err)
+	require.Equal(t, streamsResponse(smaller,
+		logproto.Stream{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(100, 0), Line: "2"}}},
+	), res)
+
+	backward := &LokiRequest{
+		StartTs:   req.StartTs,
+		EndTs:     req.EndTs,
+		Limit:     2,
+		Direction: logproto.BACKWARD,
+	}
+	res, err = h.Do(ctx, backward)
+	require.NoError(t, err)
+	require.Equal(t, streamsResponse(backward,
+		logproto.Stream{Labels: `{foo="baz"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(110, 0), Line: "3"}}},
+		logproto.Stream{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(100, 0), Line: "2"}}},
+	), res)
+
+	fake.AssertExpectations(t)
+}
+
+func Test_LogResultCacheTruncatedResults(t *testing.T) {
+	var (
+		ctx = user.InjectOrgID(context.Background(), "foo")
+		lrc = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splits: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			nil,
+			nil,
+			nil,
+		)
+	)
+
+	req := &LokiRequest{
+		StartTs: time.Unix(0, time.Minute.Nanoseconds()),
+		EndTs:   time.Unix(0, 2*time.Minute.Nanoseconds()),
+		Limit:   1,
+	}
+
+	// the response reaches the limit, so there might be more logs and it can't be cached.
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: nonEmptyResponse(req, 1),
+			},
+		},
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: nonEmptyResponse(req, 2),
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	resp, err := h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req, 1), resp)
+	resp, err = h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req, 2), resp)
+
+	fake.AssertExpectations(t)
+}
+
+func Test_LogResultCacheGenNumber(t *testing.T) {
+	var (
+		ctx    = user.InjectOrgID(context.Background(), "foo")
+		loader = &fakeCacheGenNumberLoader{gen: "1"}
+		lrc    = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splits: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			loader,
+			nil,
+			nil,
+		)
+	)
+
+	req := &LokiRequest{
+		StartTs: time.Unix(0, time.Minute.Nanoseconds()),
+		EndTs:   time.Unix(0, 2*time.Minute.Nanoseconds()),
+		Limit:   10,
+	}
+	resp1 := streamsResponse(req, logproto.Stream{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(70, 0), Line: "1"}}})
+	resp2 := streamsResponse(req, logproto.Stream{Labels: `{foo="bar"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(70, 0), Line: "2"}}})
+
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: resp1,
+			},
+		},
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: resp2,
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	resp, err := h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp1, resp)
+	resp, err = h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp1, resp)
+
+	// a delete request bumps the generation number, invalidating the cached results.
+	loader.gen = "2"
+	resp, err = h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, resp2, resp)
+
+	fake.AssertExpectations(t)
+}
+
+type fakeCacheGenNumberLoader struct {
+	gen string
+}
+
+func (l *fakeCacheGenNumberLoader) GetResultsCacheGenNumber(_ []string) string {
+	return l.gen
+}
+
 type fakeResponse struct {
 	*mock.Mock
 }
@@ -450,3 +650,9 @@ func nonEmptyResponse(lokiReq *LokiRequest, i int) *LokiResponse {
 		},
 	}
 }
+
+func streamsResponse(lokiReq *LokiRequest, streams ...logproto.Stream) *LokiResponse {
+	resp := emptyResponse(lokiReq)
+	resp.Data.Result = streams
+	return resp
+}
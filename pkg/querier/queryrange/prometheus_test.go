@@ -18,6 +18,7 @@ var emptyStats = `"stats": {
 			"chunksDownloadTime": 0,
 			"totalChunksRef": 0,
 			"totalChunksDownloaded": 0,
+			"totalChunksDownloadedBytes": 0,
 			"chunk" :{
 				"compressedBytes": 0,
 				"decompressedBytes": 0,
@@ -37,6 +38,7 @@ var emptyStats = `"stats": {
 			"chunksDownloadTime": 0,
 			"totalChunksRef": 0,
 			"totalChunksDownloaded": 0,
+			"totalChunksDownloadedBytes": 0,
 			"chunk" :{
 				"compressedBytes": 0,
 				"decompressedBytes": 0,
@@ -326,11 +326,13 @@ func (m *LokiResponse) GetStatistics() stats.Result {
 }
 
 type LokiSeriesRequest struct {
-	Match   []string  `protobuf:"bytes,1,rep,name=match,proto3" json:"match,omitempty"`
-	StartTs time.Time `protobuf:"bytes,2,opt,name=startTs,proto3,stdtime" json:"startTs"`
-	EndTs   time.Time `protobuf:"bytes,3,opt,name=endTs,proto3,stdtime" json:"endTs"`
-	Path    string    `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
-	Shards  []string  `protobuf:"bytes,5,rep,name=shards,proto3" json:"shards"`
+	Match     []string  `protobuf:"bytes,1,rep,name=match,proto3" json:"match,omitempty"`
+	StartTs   time.Time `protobuf:"bytes,2,opt,name=startTs,proto3,stdtime" json:"startTs"`
+	EndTs     time.Time `protobuf:"bytes,3,opt,name=endTs,proto3,stdtime" json:"endTs"`
+	Path      string    `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Shards    []string  `protobuf:"bytes,5,rep,name=shards,proto3" json:"shards"`
+	Limit     uint32    `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	PageToken string    `protobuf:"bytes,7,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
 }
 
 func (m *LokiSeriesRequest) Reset()      { *m = LokiSeriesRequest{} }
@@ -400,12 +402,27 @@ func (m *LokiSeriesRequest) GetShards() []string {
 	return nil
 }
 
+func (m *LokiSeriesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *LokiSeriesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type LokiSeriesResponse struct {
-	Status     string                                                                                               `protobuf:"bytes,1,opt,name=Status,proto3" json:"status"`
-	Data       []logproto.SeriesIdentifier                                                                          `protobuf:"bytes,2,rep,name=Data,proto3" json:"data,omitempty"`
-	Version    uint32                                                                                               `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
-	Headers    []github_com_grafana_loki_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,4,rep,name=Headers,proto3,customtype=github.com/grafana/loki/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
-	Statistics stats.Result                                                                                         `protobuf:"bytes,5,opt,name=statistics,proto3" json:"statistics"`
+	Status        string                                                                                               `protobuf:"bytes,1,opt,name=Status,proto3" json:"status"`
+	Data          []logproto.SeriesIdentifier                                                                          `protobuf:"bytes,2,rep,name=Data,proto3" json:"data,omitempty"`
+	Version       uint32                                                                                               `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Headers       []github_com_grafana_loki_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,4,rep,name=Headers,proto3,customtype=github.com/grafana/loki/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
+	Statistics    stats.Result                                                                                         `protobuf:"bytes,5,opt,name=statistics,proto3" json:"statistics"`
+	NextPageToken string                                                                                               `protobuf:"bytes,6,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
 }
 
 func (m *LokiSeriesResponse) Reset()      { *m = LokiSeriesResponse{} }
@@ -468,10 +485,19 @@ func (m *LokiSeriesResponse) GetStatistics() stats.Result {
 	return stats.Result{}
 }
 
+func (m *LokiSeriesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type LokiLabelNamesRequest struct {
-	StartTs time.Time `protobuf:"bytes,1,opt,name=startTs,proto3,stdtime" json:"startTs"`
-	EndTs   time.Time `protobuf:"bytes,2,opt,name=endTs,proto3,stdtime" json:"endTs"`
-	Path    string    `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	StartTs   time.Time `protobuf:"bytes,1,opt,name=startTs,proto3,stdtime" json:"startTs"`
+	EndTs     time.Time `protobuf:"bytes,2,opt,name=endTs,proto3,stdtime" json:"endTs"`
+	Path      string    `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Limit     uint32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	PageToken string    `protobuf:"bytes,5,opt,name=pageToken,proto3" json:"pageToken,omitempty"`
 }
 
 func (m *LokiLabelNamesRequest) Reset()      { *m = LokiLabelNamesRequest{} }
@@ -527,12 +553,27 @@ func (m *LokiLabelNamesRequest) GetPath() string {
 	return ""
 }
 
+func (m *LokiLabelNamesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *LokiLabelNamesRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type LokiLabelNamesResponse struct {
-	Status     string                                                                                               `protobuf:"bytes,1,opt,name=Status,proto3" json:"status"`
-	Data       []string                                                                                             `protobuf:"bytes,2,rep,name=Data,proto3" json:"data,omitempty"`
-	Version    uint32                                                                                               `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
-	Headers    []github_com_grafana_loki_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,4,rep,name=Headers,proto3,customtype=github.com/grafana/loki/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
-	Statistics stats.Result                                                                                         `protobuf:"bytes,5,opt,name=statistics,proto3" json:"statistics"`
+	Status        string                                                                                               `protobuf:"bytes,1,opt,name=Status,proto3" json:"status"`
+	Data          []string                                                                                             `protobuf:"bytes,2,rep,name=Data,proto3" json:"data,omitempty"`
+	Version       uint32                                                                                               `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Headers       []github_com_grafana_loki_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,4,rep,name=Headers,proto3,customtype=github.com/grafana/loki/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
+	Statistics    stats.Result                                                                                         `protobuf:"bytes,5,opt,name=statistics,proto3" json:"statistics"`
+	NextPageToken string                                                                                               `protobuf:"bytes,6,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"`
 }
 
 func (m *LokiLabelNamesResponse) Reset()      { *m = LokiLabelNamesResponse{} }
@@ -595,6 +636,13 @@ func (m *LokiLabelNamesResponse) GetStatistics() stats.Result {
 	return stats.Result{}
 }
 
+func (m *LokiLabelNamesResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type LokiData struct {
 	ResultType string                                        `protobuf:"bytes,1,opt,name=ResultType,proto3" json:"resultType"`
 	Result     []github_com_grafana_loki_pkg_logproto.Stream `protobuf:"bytes,2,rep,name=Result,proto3,customtype=github.com/grafana/loki/pkg/logproto.Stream" json:"result"`
@@ -746,69 +794,71 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 982 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x55, 0xdd, 0x6e, 0x23, 0x35,
-	0x14, 0x8e, 0x33, 0xf9, 0x1b, 0x97, 0x5d, 0xc0, 0x5d, 0x76, 0x47, 0x05, 0xcd, 0x44, 0x91, 0x80,
-	0x20, 0xd8, 0x89, 0xe8, 0xf2, 0x23, 0xf1, 0x27, 0x76, 0x28, 0x88, 0x4a, 0x2b, 0x84, 0x66, 0xf3,
-	0x02, 0x4e, 0xc7, 0x4d, 0x46, 0x9d, 0xbf, 0xda, 0xce, 0x8a, 0xde, 0xf1, 0x00, 0x20, 0xed, 0x5b,
-	0x80, 0x80, 0x27, 0xe0, 0x09, 0x7a, 0xd9, 0xcb, 0x55, 0x25, 0x06, 0x9a, 0xde, 0x40, 0xae, 0xfa,
-	0x08, 0xc8, 0xf6, 0x4c, 0xe2, 0xf4, 0x87, 0x4d, 0xba, 0x37, 0x7b, 0xc1, 0x4d, 0x72, 0xce, 0xf1,
-	0xf9, 0x6c, 0x9f, 0xcf, 0xdf, 0x39, 0x03, 0xdf, 0xcc, 0xf6, 0x86, 0xbd, 0xfd, 0x31, 0xa1, 0x21,
-	0xa1, 0xf2, 0xff, 0x80, 0xe2, 0x64, 0x48, 0x34, 0xd3, 0xcd, 0x68, 0xca, 0x53, 0x04, 0xe7, 0x91,
-	0x8d, 0xbb, 0xc3, 0x90, 0x8f, 0xc6, 0x03, 0x77, 0x27, 0x8d, 0x7b, 0xc3, 0x74, 0x98, 0xf6, 0x64,
-	0xca, 0x60, 0xbc, 0x2b, 0x3d, 0xe9, 0x48, 0x4b, 0x41, 0x37, 0x9c, 0x61, 0x9a, 0x0e, 0x23, 0x32,
-	0xcf, 0xe2, 0x61, 0x4c, 0x18, 0xc7, 0x71, 0x56, 0x24, 0xbc, 0x2a, 0x2e, 0x11, 0xa5, 0x43, 0x85,
-	0x2c, 0x8d, 0x62, 0xb1, 0x5d, 0x2c, 0xee, 0x47, 0x71, 0x1a, 0x90, 0xa8, 0xc7, 0x38, 0xe6, 0x4c,
-	0xfd, 0x16, 0x19, 0x5f, 0x3c, 0xb5, 0x86, 0x01, 0x66, 0xa4, 0x17, 0x90, 0xdd, 0x30, 0x09, 0x79,
-	0x98, 0x26, 0x4c, 0xb7, 0x8b, 0x4d, 0x3e, 0x58, 0x6e, 0x93, 0xf3, 0xbc, 0x74, 0x8e, 0xaa, 0x70,
-	0xed, 0x41, 0xba, 0x17, 0xfa, 0x64, 0x7f, 0x4c, 0x18, 0x47, 0xb7, 0x60, 0x5d, 0xe6, 0x58, 0xa0,
-	0x0d, 0xba, 0xa6, 0xaf, 0x1c, 0x11, 0x8d, 0xc2, 0x38, 0xe4, 0x56, 0xb5, 0x0d, 0xba, 0x37, 0x7c,
-	0xe5, 0x20, 0x04, 0x6b, 0x8c, 0x93, 0xcc, 0x32, 0xda, 0xa0, 0x6b, 0xf8, 0xd2, 0x46, 0x1b, 0xb0,
-	0x15, 0x26, 0x9c, 0xd0, 0x47, 0x38, 0xb2, 0x4c, 0x19, 0x9f, 0xf9, 0xe8, 0x33, 0xd8, 0x64, 0x1c,
-	0x53, 0xde, 0x67, 0x56, 0xad, 0x0d, 0xba, 0x6b, 0x9b, 0x1b, 0xae, 0xa2, 0xd6, 0x2d, 0xa9, 0x75,
-	0xfb, 0x25, 0xb5, 0x5e, 0xeb, 0x30, 0x77, 0x2a, 0x8f, 0xff, 0x74, 0x80, 0x5f, 0x82, 0xd0, 0x47,
-	0xb0, 0x4e, 0x92, 0xa0, 0xcf, 0xac, 0xfa, 0x0a, 0x68, 0x05, 0x41, 0xef, 0x42, 0x33, 0x08, 0x29,
-	0xd9, 0x11, 0x9c, 0x59, 0x8d, 0x36, 0xe8, 0xde, 0xdc, 0x5c, 0x77, 0x67, 0x4f, 0xb5, 0x55, 0x2e,
-	0xf9, 0xf3, 0x2c, 0x51, 0x5e, 0x86, 0xf9, 0xc8, 0x6a, 0x4a, 0x26, 0xa4, 0x8d, 0x3a, 0xb0, 0xc1,
-	0x46, 0x98, 0x06, 0xcc, 0x6a, 0xb5, 0x8d, 0xae, 0xe9, 0xc1, 0x69, 0xee, 0x14, 0x11, 0xbf, 0xf8,
-	0xef, 0xfc, 0x03, 0x20, 0x12, 0x94, 0x6e, 0x27, 0x8c, 0xe3, 0x84, 0x5f, 0x87, 0xd9, 0x4f, 0x60,
-	0x43, 0x88, 0xac, 0xcf, 0x24, 0xb7, 0xcb, 0x96, 0x5a, 0x60, 0x16, 0x6b, 0xad, 0xad, 0x54, 0x6b,
-	0xfd, 0xd2, 0x5a, 0x1b, 0x57, 0xd6, 0xfa, 0x6b, 0x0d, 0xbe, 0xa0, 0xe4, 0xc3, 0xb2, 0x34, 0x61,
-	0x44, 0x80, 0x1e, 0x72, 0xcc, 0xc7, 0x4c, 0x95, 0x59, 0x80, 0x64, 0xc4, 0x2f, 0x56, 0xd0, 0xe7,
-	0xb0, 0xb6, 0x85, 0x39, 0x96, 0x25, 0xaf, 0x6d, 0xde, 0x72, 0x35, 0x51, 0x8a, 0xbd, 0xc4, 0x9a,
-	0x77, 0x5b, 0x54, 0x35, 0xcd, 0x9d, 0x9b, 0x01, 0xe6, 0xf8, 0x9d, 0x34, 0x0e, 0x39, 0x89, 0x33,
-	0x7e, 0xe0, 0x4b, 0x24, 0x7a, 0x1f, 0x9a, 0x5f, 0x52, 0x9a, 0xd2, 0xfe, 0x41, 0x46, 0x24, 0x45,
-	0xa6, 0x77, 0x67, 0x9a, 0x3b, 0xeb, 0xa4, 0x0c, 0x6a, 0x88, 0x79, 0x26, 0x7a, 0x0b, 0xd6, 0xa5,
-	0x23, 0x49, 0x31, 0xbd, 0xf5, 0x69, 0xee, 0xbc, 0x28, 0x21, 0x5a, 0xba, 0xca, 0x58, 0xe4, 0xb0,
-	0xbe, 0x14, 0x87, 0xb3, 0xa7, 0x6c, 0xe8, 0x4f, 0x69, 0xc1, 0xe6, 0x23, 0x42, 0x99, 0xd8, 0xa6,
-	0x29, 0xe3, 0xa5, 0x8b, 0xee, 0x43, 0x28, 0x88, 0x09, 0x19, 0x0f, 0x77, 0x84, 0x9e, 0x04, 0x19,
-	0x37, 0x5c, 0x35, 0x19, 0x7c, 0xc2, 0xc6, 0x11, 0xf7, 0x50, 0xc1, 0x82, 0x96, 0xe8, 0x6b, 0x36,
-	0xfa, 0x0d, 0xc0, 0xe6, 0xd7, 0x04, 0x07, 0x84, 0x32, 0xcb, 0x6c, 0x1b, 0xdd, 0xb5, 0xcd, 0xd7,
-	0x5d, 0x7d, 0x36, 0x7c, 0x4b, 0xd3, 0x98, 0xf0, 0x11, 0x19, 0xb3, 0xf2, 0x81, 0x54, 0xb6, 0xb7,
-	0x77, 0x9c, 0x3b, 0x03, 0x7d, 0x0c, 0x52, 0xbc, 0x8b, 0x13, 0xdc, 0x8b, 0xd2, 0xbd, 0xb0, 0xb7,
-	0xf2, 0x3c, 0xba, 0xf2, 0x9c, 0x69, 0xee, 0x80, 0xbb, 0x7e, 0x79, 0xc5, 0xce, 0x1f, 0x00, 0xbe,
-	0x2c, 0x5e, 0xf8, 0xa1, 0xd8, 0x9b, 0x69, 0x8d, 0x11, 0x63, 0xbe, 0x33, 0xb2, 0x80, 0x90, 0x99,
-	0xaf, 0x1c, 0x7d, 0x58, 0x54, 0x9f, 0x69, 0x58, 0x18, 0xab, 0x0f, 0x8b, 0xb2, 0x1b, 0x6a, 0x97,
-	0x76, 0x43, 0xfd, 0xca, 0x6e, 0xf8, 0xc1, 0x50, 0x9d, 0x5f, 0xd6, 0xb7, 0x42, 0x4f, 0x7c, 0x35,
-	0xeb, 0x09, 0x43, 0xde, 0x76, 0x26, 0x35, 0xb5, 0xd7, 0x76, 0x40, 0x12, 0x1e, 0xee, 0x86, 0x84,
-	0x3e, 0xa5, 0x33, 0x34, 0xb9, 0x19, 0x8b, 0x72, 0xd3, 0xb5, 0x52, 0x7b, 0xee, 0xb5, 0x72, 0xae,
-	0x3b, 0xea, 0xd7, 0xe8, 0x8e, 0xce, 0x4f, 0x00, 0xbe, 0x22, 0x9e, 0xe3, 0x01, 0x1e, 0x90, 0xe8,
-	0x1b, 0x1c, 0xcf, 0x25, 0xa7, 0x89, 0x0b, 0x3c, 0x93, 0xb8, 0xaa, 0xd7, 0x17, 0x97, 0x31, 0x17,
-	0x57, 0xe7, 0xac, 0x0a, 0x6f, 0x9f, 0xbf, 0xe9, 0x0a, 0xe2, 0x79, 0x43, 0x13, 0x8f, 0xe9, 0xa1,
-	0xff, 0xc5, 0xb1, 0x84, 0x38, 0x7e, 0x01, 0xb0, 0x55, 0x7e, 0x6d, 0x90, 0x0b, 0xa1, 0x82, 0xc9,
-	0x0f, 0x8a, 0x22, 0xfa, 0xa6, 0x00, 0xd3, 0x59, 0xd4, 0xd7, 0x32, 0x50, 0x02, 0x1b, 0xca, 0x2b,
-	0xfa, 0xf5, 0x8e, 0xd6, 0xaf, 0x9c, 0x12, 0x1c, 0xdf, 0x0f, 0x70, 0xc6, 0x09, 0xf5, 0x3e, 0x15,
-	0xb7, 0x38, 0xce, 0x9d, 0xb7, 0xff, 0x8b, 0xa2, 0x73, 0x58, 0xf1, 0xc0, 0xea, 0x5c, 0xbf, 0x38,
-	0xa5, 0xf3, 0x23, 0x80, 0x2f, 0x89, 0xcb, 0x0a, 0x7a, 0x66, 0xca, 0xd8, 0x82, 0x2d, 0x5a, 0xd8,
-	0x85, 0x8a, 0x3b, 0xee, 0x22, 0xb5, 0x97, 0xd0, 0xe9, 0xd5, 0x0e, 0x73, 0x07, 0xf8, 0x33, 0x24,
-	0xba, 0xb7, 0x40, 0x65, 0xf5, 0x32, 0x2a, 0x05, 0xa4, 0xb2, 0x40, 0xde, 0xef, 0x55, 0x88, 0xb6,
-	0x93, 0x80, 0x7c, 0x27, 0x04, 0x38, 0xd7, 0xea, 0xf8, 0xc2, 0x8d, 0x5e, 0x9b, 0x13, 0x73, 0x31,
-	0xdf, 0xfb, 0xf8, 0x38, 0x77, 0x3e, 0x5c, 0x8a, 0x99, 0x8b, 0x60, 0xad, 0x04, 0x5d, 0xbc, 0xd5,
-	0xe7, 0x5e, 0xbc, 0xde, 0x7b, 0x47, 0x27, 0x76, 0xe5, 0xc9, 0x89, 0x5d, 0x39, 0x3b, 0xb1, 0xc1,
-	0xf7, 0x13, 0x1b, 0xfc, 0x3c, 0xb1, 0xc1, 0xe1, 0xc4, 0x06, 0x47, 0x13, 0x1b, 0xfc, 0x35, 0xb1,
-	0xc1, 0xdf, 0x13, 0xbb, 0x72, 0x36, 0xb1, 0xc1, 0xe3, 0x53, 0xbb, 0x72, 0x74, 0x6a, 0x57, 0x9e,
-	0x9c, 0xda, 0x95, 0x41, 0x43, 0x12, 0x71, 0xef, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x86, 0x5b,
-	0xf0, 0xeb, 0xf2, 0x0c, 0x00, 0x00,
+	// 1022 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x55, 0xcb, 0x6e, 0x23, 0x45,
+	0x14, 0x75, 0xb9, 0xfd, 0xea, 0x0a, 0x09, 0x50, 0x19, 0x66, 0x5a, 0x61, 0xd4, 0x6d, 0x59, 0x3c,
+	0x8c, 0x60, 0xda, 0x22, 0xc3, 0x43, 0xe2, 0x25, 0xa6, 0x09, 0x88, 0x48, 0x23, 0x34, 0xea, 0xf1,
+	0x0f, 0x94, 0xd3, 0x15, 0xbb, 0x65, 0xf7, 0x23, 0x55, 0xe5, 0xd1, 0x64, 0xc7, 0x0f, 0x20, 0x0d,
+	0x1b, 0xbe, 0x01, 0x01, 0x2b, 0x96, 0x7c, 0x41, 0x96, 0x59, 0x8e, 0x82, 0xd4, 0x10, 0x67, 0x03,
+	0x5e, 0xcd, 0x27, 0xa0, 0xaa, 0x7e, 0xb8, 0xec, 0xc4, 0x4c, 0x9c, 0x61, 0x31, 0x0b, 0x36, 0x76,
+	0xdd, 0x5b, 0xf7, 0x54, 0xd5, 0x3d, 0xf7, 0xdc, 0xdb, 0xf0, 0xcd, 0x78, 0xd8, 0xef, 0x1c, 0x8c,
+	0x09, 0xf5, 0x09, 0x95, 0xff, 0x87, 0x14, 0x87, 0x7d, 0xa2, 0x2c, 0xed, 0x98, 0x46, 0x3c, 0x42,
+	0x70, 0xe6, 0xd9, 0xba, 0xd5, 0xf7, 0xf9, 0x60, 0xdc, 0xb3, 0xf7, 0xa2, 0xa0, 0xd3, 0x8f, 0xfa,
+	0x51, 0x47, 0x86, 0xf4, 0xc6, 0xfb, 0xd2, 0x92, 0x86, 0x5c, 0xa5, 0xd0, 0x2d, 0xab, 0x1f, 0x45,
+	0xfd, 0x11, 0x99, 0x45, 0x71, 0x3f, 0x20, 0x8c, 0xe3, 0x20, 0xce, 0x02, 0x5e, 0x15, 0x8f, 0x18,
+	0x45, 0xfd, 0x14, 0x99, 0x2f, 0xb2, 0xcd, 0x66, 0xb6, 0x79, 0x30, 0x0a, 0x22, 0x8f, 0x8c, 0x3a,
+	0x8c, 0x63, 0xce, 0xd2, 0xdf, 0x2c, 0xe2, 0x8b, 0xa7, 0xe6, 0xd0, 0xc3, 0x8c, 0x74, 0x3c, 0xb2,
+	0xef, 0x87, 0x3e, 0xf7, 0xa3, 0x90, 0xa9, 0xeb, 0xec, 0x90, 0x0f, 0x2e, 0x77, 0xc8, 0x22, 0x2f,
+	0xad, 0xe3, 0x32, 0x5c, 0xbb, 0x1b, 0x0d, 0x7d, 0x97, 0x1c, 0x8c, 0x09, 0xe3, 0xe8, 0x1a, 0xac,
+	0xca, 0x18, 0x03, 0x34, 0x41, 0x5b, 0x77, 0x53, 0x43, 0x78, 0x47, 0x7e, 0xe0, 0x73, 0xa3, 0xdc,
+	0x04, 0xed, 0x75, 0x37, 0x35, 0x10, 0x82, 0x15, 0xc6, 0x49, 0x6c, 0x68, 0x4d, 0xd0, 0xd6, 0x5c,
+	0xb9, 0x46, 0x5b, 0xb0, 0xe1, 0x87, 0x9c, 0xd0, 0x07, 0x78, 0x64, 0xe8, 0xd2, 0x5f, 0xd8, 0xe8,
+	0x33, 0x58, 0x67, 0x1c, 0x53, 0xde, 0x65, 0x46, 0xa5, 0x09, 0xda, 0x6b, 0xdb, 0x5b, 0x76, 0x4a,
+	0xad, 0x9d, 0x53, 0x6b, 0x77, 0x73, 0x6a, 0x9d, 0xc6, 0x51, 0x62, 0x95, 0x1e, 0xfd, 0x61, 0x01,
+	0x37, 0x07, 0xa1, 0x8f, 0x60, 0x95, 0x84, 0x5e, 0x97, 0x19, 0xd5, 0x15, 0xd0, 0x29, 0x04, 0xbd,
+	0x0b, 0x75, 0xcf, 0xa7, 0x64, 0x4f, 0x70, 0x66, 0xd4, 0x9a, 0xa0, 0xbd, 0xb1, 0xbd, 0x69, 0x17,
+	0xa5, 0xda, 0xc9, 0xb7, 0xdc, 0x59, 0x94, 0x48, 0x2f, 0xc6, 0x7c, 0x60, 0xd4, 0x25, 0x13, 0x72,
+	0x8d, 0x5a, 0xb0, 0xc6, 0x06, 0x98, 0x7a, 0xcc, 0x68, 0x34, 0xb5, 0xb6, 0xee, 0xc0, 0x69, 0x62,
+	0x65, 0x1e, 0x37, 0xfb, 0x6f, 0xfd, 0x0d, 0x20, 0x12, 0x94, 0xee, 0x86, 0x8c, 0xe3, 0x90, 0x5f,
+	0x85, 0xd9, 0x4f, 0x60, 0x4d, 0x88, 0xac, 0xcb, 0x0c, 0x6d, 0x85, 0x54, 0x33, 0xcc, 0x7c, 0xae,
+	0x95, 0x95, 0x72, 0xad, 0x5e, 0x98, 0x6b, 0x6d, 0x69, 0xae, 0x3f, 0x57, 0xe0, 0x0b, 0xa9, 0x7c,
+	0x58, 0x1c, 0x85, 0x8c, 0x08, 0xd0, 0x7d, 0x8e, 0xf9, 0x98, 0xa5, 0x69, 0x66, 0x20, 0xe9, 0x71,
+	0xb3, 0x1d, 0xf4, 0x39, 0xac, 0xec, 0x60, 0x8e, 0x65, 0xca, 0x6b, 0xdb, 0xd7, 0x6c, 0x45, 0x94,
+	0xe2, 0x2c, 0xb1, 0xe7, 0x5c, 0x17, 0x59, 0x4d, 0x13, 0x6b, 0xc3, 0xc3, 0x1c, 0xbf, 0x13, 0x05,
+	0x3e, 0x27, 0x41, 0xcc, 0x0f, 0x5d, 0x89, 0x44, 0xef, 0x43, 0xfd, 0x4b, 0x4a, 0x23, 0xda, 0x3d,
+	0x8c, 0x89, 0xa4, 0x48, 0x77, 0x6e, 0x4c, 0x13, 0x6b, 0x93, 0xe4, 0x4e, 0x05, 0x31, 0x8b, 0x44,
+	0x6f, 0xc1, 0xaa, 0x34, 0x24, 0x29, 0xba, 0xb3, 0x39, 0x4d, 0xac, 0x17, 0x25, 0x44, 0x09, 0x4f,
+	0x23, 0xe6, 0x39, 0xac, 0x5e, 0x8a, 0xc3, 0xa2, 0x94, 0x35, 0xb5, 0x94, 0x06, 0xac, 0x3f, 0x20,
+	0x94, 0x89, 0x63, 0xea, 0xd2, 0x9f, 0x9b, 0xe8, 0x0e, 0x84, 0x82, 0x18, 0x9f, 0x71, 0x7f, 0x4f,
+	0xe8, 0x49, 0x90, 0xb1, 0x6e, 0xa7, 0x93, 0xc1, 0x25, 0x6c, 0x3c, 0xe2, 0x0e, 0xca, 0x58, 0x50,
+	0x02, 0x5d, 0x65, 0x8d, 0x7e, 0x01, 0xb0, 0xfe, 0x35, 0xc1, 0x1e, 0xa1, 0xcc, 0xd0, 0x9b, 0x5a,
+	0x7b, 0x6d, 0xfb, 0x75, 0x5b, 0x9d, 0x0d, 0xf7, 0x68, 0x14, 0x10, 0x3e, 0x20, 0x63, 0x96, 0x17,
+	0x28, 0x8d, 0x76, 0x86, 0x27, 0x89, 0xd5, 0x53, 0xc7, 0x20, 0xc5, 0xfb, 0x38, 0xc4, 0x9d, 0x51,
+	0x34, 0xf4, 0x3b, 0x2b, 0xcf, 0xa3, 0xa5, 0xf7, 0x4c, 0x13, 0x0b, 0xdc, 0x72, 0xf3, 0x27, 0xb6,
+	0xbe, 0x2f, 0xc3, 0x97, 0x45, 0x85, 0xef, 0x8b, 0xb3, 0x99, 0xd2, 0x18, 0x01, 0xe6, 0x7b, 0x03,
+	0x03, 0x08, 0x99, 0xb9, 0xa9, 0xa1, 0x0e, 0x8b, 0xf2, 0x33, 0x0d, 0x0b, 0x6d, 0xf5, 0x61, 0x91,
+	0x77, 0x43, 0xe5, 0xc2, 0x6e, 0xa8, 0x2e, 0xeb, 0x86, 0x25, 0x0a, 0xb8, 0x09, 0xf5, 0x18, 0xf7,
+	0x49, 0x37, 0x1a, 0x92, 0x30, 0x1b, 0x26, 0x33, 0x47, 0xeb, 0x57, 0x0d, 0x22, 0x95, 0x93, 0x15,
+	0xfa, 0xe8, 0xab, 0xa2, 0x8f, 0x34, 0x99, 0x61, 0x21, 0xcf, 0xf4, 0xac, 0x5d, 0x8f, 0x84, 0xdc,
+	0xdf, 0xf7, 0x09, 0x7d, 0x4a, 0x37, 0x29, 0x12, 0xd5, 0xe6, 0x25, 0xaa, 0xea, 0xab, 0xf2, 0xdc,
+	0xeb, 0x6b, 0xa1, 0xa3, 0xaa, 0x57, 0xe9, 0xa8, 0xd7, 0xe0, 0x7a, 0x48, 0x1e, 0xf2, 0x7b, 0x45,
+	0xc1, 0x6a, 0xb2, 0x60, 0xf3, 0xce, 0xd6, 0xef, 0x00, 0xbe, 0x22, 0x8a, 0x76, 0x17, 0xf7, 0xc8,
+	0xe8, 0x1b, 0x1c, 0xcc, 0xc4, 0xac, 0xc8, 0x16, 0x3c, 0x93, 0x6c, 0xcb, 0x57, 0x97, 0xad, 0xa6,
+	0xc8, 0xb6, 0x90, 0x64, 0x65, 0xa9, 0x24, 0xab, 0x8b, 0x92, 0xfc, 0x41, 0x83, 0xd7, 0x17, 0xb3,
+	0x5b, 0x41, 0x96, 0x6f, 0x28, 0xb2, 0xd4, 0x1d, 0xf4, 0xbf, 0xec, 0xfe, 0x33, 0xd9, 0xfd, 0x04,
+	0x60, 0x23, 0xff, 0x42, 0x22, 0x1b, 0xc2, 0xf4, 0x70, 0xf9, 0x11, 0x4c, 0xcb, 0xb1, 0x21, 0xae,
+	0xa0, 0x85, 0xd7, 0x55, 0x22, 0x50, 0x08, 0x6b, 0xa9, 0x95, 0xcd, 0x8b, 0x1b, 0xca, 0xbc, 0xe0,
+	0x94, 0xe0, 0xe0, 0x8e, 0x87, 0x63, 0x4e, 0xa8, 0xf3, 0xa9, 0x78, 0xeb, 0x49, 0x62, 0xbd, 0xfd,
+	0x6f, 0x44, 0x2e, 0x60, 0x85, 0x0c, 0xd2, 0x7b, 0xdd, 0xec, 0x96, 0xd6, 0x77, 0x00, 0xbe, 0x24,
+	0x1e, 0x2b, 0x48, 0x2c, 0xf4, 0xb3, 0x03, 0x1b, 0x34, 0x5b, 0x67, 0xfd, 0xd1, 0xb2, 0xe7, 0x0b,
+	0x70, 0x01, 0xe9, 0x4e, 0xe5, 0x28, 0xb1, 0x80, 0x5b, 0x20, 0xd1, 0xed, 0x39, 0xc2, 0xcb, 0x17,
+	0x11, 0x2e, 0x20, 0x25, 0x95, 0xe2, 0xd6, 0x6f, 0x65, 0x88, 0x76, 0x43, 0x8f, 0x3c, 0x14, 0x32,
+	0x9d, 0x29, 0x7a, 0x7c, 0xee, 0x45, 0x37, 0x67, 0xc4, 0x9c, 0x8f, 0x77, 0x3e, 0x3e, 0x49, 0xac,
+	0x0f, 0x2f, 0xc5, 0xcc, 0x79, 0xb0, 0x92, 0x82, 0x2a, 0xf1, 0xf2, 0x73, 0x2f, 0x71, 0xe7, 0xbd,
+	0xe3, 0x53, 0xb3, 0xf4, 0xf8, 0xd4, 0x2c, 0x3d, 0x39, 0x35, 0xc1, 0xb7, 0x13, 0x13, 0xfc, 0x38,
+	0x31, 0xc1, 0xd1, 0xc4, 0x04, 0xc7, 0x13, 0x13, 0xfc, 0x39, 0x31, 0xc1, 0x5f, 0x13, 0xb3, 0xf4,
+	0x64, 0x62, 0x82, 0x47, 0x67, 0x66, 0xe9, 0xf8, 0xcc, 0x2c, 0x3d, 0x3e, 0x33, 0x4b, 0xbd, 0x9a,
+	0x24, 0xe2, 0xf6, 0x3f, 0x03, 0x00, 0xef, 0xb3, 0x85, 0xf6, 0xa6, 0x0d, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -1005,6 +1055,12 @@ func (this *LokiSeriesRequest) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *LokiSeriesResponse) Equal(that interface{}) bool {
@@ -1051,6 +1107,9 @@ func (this *LokiSeriesResponse) Equal(that interface{}) bool {
 	if !this.Statistics.Equal(&that1.Statistics) {
 		return false
 	}
+	if this.NextPageToken != that1.NextPageToken {
+		return false
+	}
 	return true
 }
 func (this *LokiLabelNamesRequest) Equal(that interface{}) bool {
@@ -1081,6 +1140,12 @@ func (this *LokiLabelNamesRequest) Equal(that interface{}) bool {
 	if this.Path != that1.Path {
 		return false
 	}
+	if this.Limit != that1.Limit {
+		return false
+	}
+	if this.PageToken != that1.PageToken {
+		return false
+	}
 	return true
 }
 func (this *LokiLabelNamesResponse) Equal(that interface{}) bool {
@@ -1127,6 +1192,9 @@ func (this *LokiLabelNamesResponse) Equal(that interface{}) bool {
 	if !this.Statistics.Equal(&that1.Statistics) {
 		return false
 	}
+	if this.NextPageToken != that1.NextPageToken {
+		return false
+	}
 	return true
 }
 func (this *LokiData) Equal(that interface{}) bool {
@@ -1279,13 +1347,15 @@ func (this *LokiSeriesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 11)
 	s = append(s, "&queryrange.LokiSeriesRequest{")
 	s = append(s, "Match: "+fmt.Sprintf("%#v", this.Match)+",\n")
 	s = append(s, "StartTs: "+fmt.Sprintf("%#v", this.StartTs)+",\n")
 	s = append(s, "EndTs: "+fmt.Sprintf("%#v", this.EndTs)+",\n")
 	s = append(s, "Path: "+fmt.Sprintf("%#v", this.Path)+",\n")
 	s = append(s, "Shards: "+fmt.Sprintf("%#v", this.Shards)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1293,7 +1363,7 @@ func (this *LokiSeriesResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&queryrange.LokiSeriesResponse{")
 	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
 	if this.Data != nil {
@@ -1306,6 +1376,7 @@ func (this *LokiSeriesResponse) GoString() string {
 	s = append(s, "Version: "+fmt.Sprintf("%#v", this.Version)+",\n")
 	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
 	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "NextPageToken: "+fmt.Sprintf("%#v", this.NextPageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1313,11 +1384,13 @@ func (this *LokiLabelNamesRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 9)
 	s = append(s, "&queryrange.LokiLabelNamesRequest{")
 	s = append(s, "StartTs: "+fmt.Sprintf("%#v", this.StartTs)+",\n")
 	s = append(s, "EndTs: "+fmt.Sprintf("%#v", this.EndTs)+",\n")
 	s = append(s, "Path: "+fmt.Sprintf("%#v", this.Path)+",\n")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "PageToken: "+fmt.Sprintf("%#v", this.PageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1325,13 +1398,14 @@ func (this *LokiLabelNamesResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&queryrange.LokiLabelNamesResponse{")
 	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
 	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
 	s = append(s, "Version: "+fmt.Sprintf("%#v", this.Version)+",\n")
 	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
 	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "NextPageToken: "+fmt.Sprintf("%#v", this.NextPageToken)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1637,6 +1711,18 @@ func (m *LokiSeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintQueryrange(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Shards) > 0 {
 		for iNdEx := len(m.Shards) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Shards[iNdEx])
@@ -1701,6 +1787,13 @@ func (m *LokiSeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPageToken) > 0 {
+		i -= len(m.NextPageToken)
+		copy(dAtA[i:], m.NextPageToken)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.NextPageToken)))
+		i--
+		dAtA[i] = 0x32
+	}
 	{
 		size, err := m.Statistics.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -1774,6 +1867,18 @@ func (m *LokiLabelNamesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.PageToken) > 0 {
+		i -= len(m.PageToken)
+		copy(dAtA[i:], m.PageToken)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.PageToken)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintQueryrange(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x20
+	}
 	if len(m.Path) > 0 {
 		i -= len(m.Path)
 		copy(dAtA[i:], m.Path)
@@ -1820,6 +1925,13 @@ func (m *LokiLabelNamesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	_ = i
 	var l int
 	_ = l
+	if len(m.NextPageToken) > 0 {
+		i -= len(m.NextPageToken)
+		copy(dAtA[i:], m.NextPageToken)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.NextPageToken)))
+		i--
+		dAtA[i] = 0x32
+	}
 	{
 		size, err := m.Statistics.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -2153,6 +2265,13 @@ func (m *LokiSeriesRequest) Size() (n int) {
 			n += 1 + l + sovQueryrange(uint64(l))
 		}
 	}
+	if m.Limit != 0 {
+		n += 1 + sovQueryrange(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -2183,6 +2302,10 @@ func (m *LokiSeriesResponse) Size() (n int) {
 	}
 	l = m.Statistics.Size()
 	n += 1 + l + sovQueryrange(uint64(l))
+	l = len(m.NextPageToken)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -2200,6 +2323,13 @@ func (m *LokiLabelNamesRequest) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovQueryrange(uint64(l))
 	}
+	if m.Limit != 0 {
+		n += 1 + sovQueryrange(uint64(m.Limit))
+	}
+	l = len(m.PageToken)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -2230,6 +2360,10 @@ func (m *LokiLabelNamesResponse) Size() (n int) {
 	}
 	l = m.Statistics.Size()
 	n += 1 + l + sovQueryrange(uint64(l))
+	l = len(m.NextPageToken)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -2353,6 +2487,8 @@ func (this *LokiSeriesRequest) String() string {
 		`EndTs:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EndTs), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
 		`Shards:` + fmt.Sprintf("%v", this.Shards) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2372,6 +2508,7 @@ func (this *LokiSeriesResponse) String() string {
 		`Version:` + fmt.Sprintf("%v", this.Version) + `,`,
 		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
 		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
+		`NextPageToken:` + fmt.Sprintf("%v", this.NextPageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2384,6 +2521,8 @@ func (this *LokiLabelNamesRequest) String() string {
 		`StartTs:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.StartTs), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`EndTs:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.EndTs), "Timestamp", "types.Timestamp", 1), `&`, ``, 1) + `,`,
 		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`PageToken:` + fmt.Sprintf("%v", this.PageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -2398,6 +2537,7 @@ func (this *LokiLabelNamesResponse) String() string {
 		`Version:` + fmt.Sprintf("%v", this.Version) + `,`,
 		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
 		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
+		`NextPageToken:` + fmt.Sprintf("%v", this.NextPageToken) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3451,6 +3591,57 @@ func (m *LokiSeriesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Shards = append(m.Shards, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -3656,6 +3847,38 @@ func (m *LokiSeriesResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -3807,6 +4030,57 @@ func (m *LokiLabelNamesRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Path = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -4010,6 +4284,38 @@ func (m *LokiLabelNamesResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextPageToken", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.NextPageToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
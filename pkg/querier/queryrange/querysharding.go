@@ -360,5 +360,9 @@ func (ss *seriesShardingHandler) Do(ctx context.Context, r queryrangebase.Reques
 	for _, res := range requestResponses {
 		responses = append(responses, res.Response)
 	}
-	return ss.merger.MergeResponse(responses...)
+	resp, err := ss.merger.MergeResponse(responses...)
+	if err != nil {
+		return nil, err
+	}
+	return pageResponse(req, resp)
 }
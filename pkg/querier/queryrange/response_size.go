@@ -0,0 +1,111 @@
+package queryrange
+
+import (
+	"net/http"
+
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/util/validation"
+)
+
+const (
+	// TruncatedResponseHeader is set on the responses truncated to the maximum response size of the tenants.
+	TruncatedResponseHeader = "X-Loki-Response-Truncated"
+
+	errResponseTooLarge = "the query response exceeds the maximum response size of %d bytes, narrow down the query or reduce its limit"
+)
+
+// ResponseSizeLimits returns the maximum size of the responses the query-frontend merges per tenant.
+type ResponseSizeLimits interface {
+	MaxQueryResponseBytes(string) int
+	TruncateQueryResponse(string) bool
+}
+
+// responseSizeLimiter tracks the size of the responses of the sub-queries of a query, in the order they are merged.
+type responseSizeLimiter struct {
+	maxBytes int
+	truncate bool
+	bytes    int
+}
+
+func newResponseSizeLimiter(tenantIDs []string, limits ResponseSizeLimits) *responseSizeLimiter {
+	truncate := len(tenantIDs) > 0
+	for _, tenantID := range tenantIDs {
+		// a response is only truncated if all the tenants accept partial results
+		truncate = truncate && limits.TruncateQueryResponse(tenantID)
+	}
+	return &responseSizeLimiter{
+		maxBytes: validation.SmallestPositiveIntPerTenant(tenantIDs, limits.MaxQueryResponseBytes),
+		truncate: truncate,
+	}
+}
+
+// add adds the response of the next sub-query. It returns false when the response doesn't fit in the maximum size
+// and the responses merged so far must be returned truncated, and an error when the query must fail instead.
+func (l *responseSizeLimiter) add(resp queryrangebase.Response) (bool, error) {
+	if l.maxBytes <= 0 {
+		return true, nil
+	}
+	sized, ok := resp.(interface{ Size() int })
+	if !ok {
+		return true, nil
+	}
+	l.bytes += sized.Size()
+	if l.bytes <= l.maxBytes {
+		return true, nil
+	}
+	if !l.truncate {
+		return false, httpgrpc.Errorf(http.StatusBadRequest, errResponseTooLarge, l.maxBytes)
+	}
+	return false, nil
+}
+
+// markTruncated adds the truncation header to a merged response.
+func markTruncated(resp queryrangebase.Response) {
+	header := queryrangebase.PrometheusResponseHeader{Name: TruncatedResponseHeader, Values: []string{"true"}}
+	switch r := resp.(type) {
+	case *LokiResponse:
+		r.Headers = append(r.Headers, header)
+	case *LokiSeriesResponse:
+		r.Headers = append(r.Headers, header)
+	case *LokiLabelNamesResponse:
+		r.Headers = append(r.Headers, header)
+	}
+}
+
+// emptyTruncatedResponse returns the response of a request whose first sub-query response already exceeds the
+// maximum response size.
+func emptyTruncatedResponse(req queryrangebase.Request) queryrangebase.Response {
+	switch r := req.(type) {
+	case *LokiRequest:
+		return emptyResponse(r)
+	case *LokiSeriesRequest:
+		return &LokiSeriesResponse{
+			Status:  loghttp.QueryStatusSuccess,
+			Version: uint32(loghttp.GetVersion(r.Path)),
+		}
+	default:
+		return &LokiLabelNamesResponse{
+			Status:  loghttp.QueryStatusSuccess,
+			Version: uint32(loghttp.GetVersion(req.(*LokiLabelNamesRequest).Path)),
+		}
+	}
+}
+
+// isTruncated returns whether a response was truncated to the maximum response size.
+func isTruncated(resp queryrangebase.Response) bool {
+	h, ok := resp.(interface {
+		GetHeaders() []*queryrangebase.PrometheusResponseHeader
+	})
+	if !ok {
+		return false
+	}
+	for _, header := range h.GetHeaders() {
+		if header.Name == TruncatedResponseHeader {
+			return true
+		}
+	}
+	return false
+}
@@ -69,9 +69,7 @@ func NewTripperware(
 		return nil, nil, err
 	}
 
-	// NOTE: When we would start caching response from non-metric queries we would have to consider cache gen headers as well in
-	// MergeResponse implementation for Loki codecs same as it is done in Cortex at https://github.com/cortexproject/cortex/blob/21bad57b346c730d684d6d0205efef133422ab28/pkg/querier/queryrange/query_range.go#L170
-	logFilterTripperware, err := NewLogFilterTripperware(cfg, log, limits, schema, LokiCodec, c, metrics)
+	logFilterTripperware, err := NewLogFilterTripperware(cfg, log, limits, schema, LokiCodec, c, cacheGenNumLoader, metrics)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -127,6 +125,9 @@ func (r roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	switch op := getOperation(req.URL.Path); op {
 	case QueryRangeOp:
+		if acceptsArrow(req) {
+			req = withArrowResponse(req)
+		}
 		rangeQuery, err := loghttp.ParseRangeQuery(req)
 		if err != nil {
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
@@ -251,13 +252,31 @@ func NewLogFilterTripperware(
 	schema config.SchemaConfig,
 	codec queryrangebase.Codec,
 	c cache.Cache,
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader,
 	metrics *Metrics,
 ) (queryrangebase.Tripperware, error) {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return NewLimitedRoundTripper(next, codec, limits, logFilterMiddlewares(cfg, log, limits, schema, codec, c, cacheGenNumLoader, metrics, next)...)
+	}, nil
+}
+
+// logFilterMiddlewares returns the middlewares of log requests, sending the index stats requests to next.
+func logFilterMiddlewares(
+	cfg Config,
+	log log.Logger,
+	limits Limits,
+	schema config.SchemaConfig,
+	codec queryrangebase.Codec,
+	c cache.Cache,
+	cacheGenNumLoader queryrangebase.CacheGenNumberLoader,
+	metrics *Metrics,
+	next http.RoundTripper,
+) []queryrangebase.Middleware {
 	queryRangeMiddleware := []queryrangebase.Middleware{
 		StatsCollectorMiddleware(),
 		NewLimitsMiddleware(limits),
 		queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
-		SplitByIntervalMiddleware(limits, codec, splitByTime, metrics.SplitByMetrics),
+		SplitByDensityMiddleware(schema.Configs, limits, codec, splitByTime, metrics.SplitByMetrics, statsHandler(next, codec), log),
 	}
 
 	if cfg.CacheResults {
@@ -265,6 +284,7 @@ func NewLogFilterTripperware(
 			log,
 			limits,
 			c,
+			cacheGenNumLoader,
 			func(r queryrangebase.Request) bool {
 				return !r.GetCachingOptions().Disabled
 			},
@@ -296,12 +316,7 @@ func NewLogFilterTripperware(
 		)
 	}
 
-	return func(next http.RoundTripper) http.RoundTripper {
-		if len(queryRangeMiddleware) > 0 {
-			return NewLimitedRoundTripper(next, codec, limits, queryRangeMiddleware...)
-		}
-		return next
-	}, nil
+	return queryRangeMiddleware
 }
 
 // NewSeriesTripperware creates a new frontend tripperware responsible for handling series requests
@@ -397,23 +412,10 @@ func NewMetricTripperware(
 	metrics *Metrics,
 	registerer prometheus.Registerer,
 ) (queryrangebase.Tripperware, error) {
-	queryRangeMiddleware := []queryrangebase.Middleware{StatsCollectorMiddleware(), NewLimitsMiddleware(limits)}
-	if cfg.AlignQueriesWithStep {
-		queryRangeMiddleware = append(
-			queryRangeMiddleware,
-			queryrangebase.InstrumentMiddleware("step_align", metrics.InstrumentMiddlewareMetrics),
-			queryrangebase.StepAlignMiddleware,
-		)
-	}
-
-	queryRangeMiddleware = append(
-		queryRangeMiddleware,
-		queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
-		SplitByIntervalMiddleware(limits, codec, splitMetricByTime, metrics.SplitByMetrics),
-	)
-
+	var queryCacheMiddleware queryrangebase.Middleware
 	if cfg.CacheResults {
-		queryCacheMiddleware, err := queryrangebase.NewResultsCacheMiddleware(
+		var err error
+		queryCacheMiddleware, err = queryrangebase.NewResultsCacheMiddleware(
 			log,
 			c,
 			cacheKeyLimits{limits},
@@ -429,45 +431,60 @@ func NewMetricTripperware(
 		if err != nil {
 			return nil, err
 		}
-		queryRangeMiddleware = append(
-			queryRangeMiddleware,
-			queryrangebase.InstrumentMiddleware("results_cache", metrics.InstrumentMiddlewareMetrics),
-			queryCacheMiddleware,
-		)
 	}
 
-	if cfg.ShardedQueries {
-		queryRangeMiddleware = append(queryRangeMiddleware,
-			NewQueryShardMiddleware(
-				log,
-				schema.Configs,
-				metrics.InstrumentMiddlewareMetrics, // instrumentation is included in the sharding middleware
-				metrics.MiddlewareMapperMetrics.shardMapper,
-				limits,
-			),
-		)
-	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		queryRangeMiddleware := []queryrangebase.Middleware{StatsCollectorMiddleware(), NewLimitsMiddleware(limits)}
+		if cfg.AlignQueriesWithStep {
+			queryRangeMiddleware = append(
+				queryRangeMiddleware,
+				queryrangebase.InstrumentMiddleware("step_align", metrics.InstrumentMiddlewareMetrics),
+				queryrangebase.StepAlignMiddleware,
+			)
+		}
 
-	if cfg.MaxRetries > 0 {
 		queryRangeMiddleware = append(
 			queryRangeMiddleware,
-			queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
-			queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+			queryrangebase.InstrumentMiddleware("split_by_interval", metrics.InstrumentMiddlewareMetrics),
+			SplitByDensityMiddleware(schema.Configs, limits, codec, splitMetricByTime, metrics.SplitByMetrics, statsHandler(next, codec), log),
 		)
-	}
 
-	return func(next http.RoundTripper) http.RoundTripper {
-		// Finally, if the user selected any query range middleware, stitch it in.
-		if len(queryRangeMiddleware) > 0 {
-			rt := NewLimitedRoundTripper(next, codec, limits, queryRangeMiddleware...)
-			return queryrangebase.RoundTripFunc(func(r *http.Request) (*http.Response, error) {
-				if !strings.HasSuffix(r.URL.Path, "/query_range") {
-					return next.RoundTrip(r)
-				}
-				return rt.RoundTrip(r)
-			})
+		if queryCacheMiddleware != nil {
+			queryRangeMiddleware = append(
+				queryRangeMiddleware,
+				queryrangebase.InstrumentMiddleware("results_cache", metrics.InstrumentMiddlewareMetrics),
+				queryCacheMiddleware,
+			)
 		}
-		return next
+
+		if cfg.ShardedQueries {
+			queryRangeMiddleware = append(queryRangeMiddleware,
+				NewQueryShardMiddleware(
+					log,
+					schema.Configs,
+					metrics.InstrumentMiddlewareMetrics, // instrumentation is included in the sharding middleware
+					metrics.MiddlewareMapperMetrics.shardMapper,
+					limits,
+				),
+			)
+		}
+
+		if cfg.MaxRetries > 0 {
+			queryRangeMiddleware = append(
+				queryRangeMiddleware,
+				queryrangebase.InstrumentMiddleware("retry", metrics.InstrumentMiddlewareMetrics),
+				queryrangebase.NewRetryMiddleware(log, cfg.MaxRetries, metrics.RetryMiddlewareMetrics),
+			)
+		}
+
+		// Finally, stitch the query range middlewares in.
+		rt := NewLimitedRoundTripper(next, codec, limits, queryRangeMiddleware...)
+		return queryrangebase.RoundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(r.URL.Path, "/query_range") {
+				return next.RoundTrip(r)
+			}
+			return rt.RoundTrip(r)
+		})
 	}, nil
 }
 
@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/config"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/marshal"
+	"github.com/grafana/loki/pkg/validation"
 )
 
 var (
@@ -572,8 +573,13 @@ type fakeLimits struct {
 	maxEntriesLimitPerQuery int
 	maxSeries               int
 	splits                  map[string]time.Duration
+	splitTargetBytes        int
 	minShardingLookback     time.Duration
 	queryTimeout            time.Duration
+	blockedQueries          []validation.BlockedQuery
+	maxResponseBytes        int
+	truncateResponse        bool
+	multiTenantAllowlist    map[string][]string
 }
 
 func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
@@ -583,6 +589,10 @@ func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
 	return f.splits[key]
 }
 
+func (f fakeLimits) QuerySplitTargetBytes(string) int {
+	return f.splitTargetBytes
+}
+
 func (f fakeLimits) MaxQueryLength(string) time.Duration {
 	if f.maxQueryLength == 0 {
 		return time.Hour * 7
@@ -618,6 +628,25 @@ func (f fakeLimits) QueryTimeout(string) time.Duration {
 	return f.queryTimeout
 }
 
+func (f fakeLimits) MaxQueryResponseBytes(string) int {
+	return f.maxResponseBytes
+}
+
+func (f fakeLimits) TruncateQueryResponse(string) bool {
+	return f.truncateResponse
+}
+
+func (f fakeLimits) MultiTenantQueryAllowlist(userID string) []string {
+	if f.multiTenantAllowlist == nil {
+		return []string{"*"}
+	}
+	return f.multiTenantAllowlist[userID]
+}
+
+func (f fakeLimits) BlockedQueries(string) []validation.BlockedQuery {
+	return f.blockedQueries
+}
+
 func counter() (*int, http.Handler) {
 	count := 0
 	var lock sync.Mutex
@@ -701,3 +730,57 @@ func (s fakeRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 func toMs(t time.Time) int64 {
 	return t.UnixNano() / (int64(time.Millisecond) / int64(time.Nanosecond))
 }
+
+func TestTripperware_ArrowResponse(t *testing.T) {
+	tpw, stopper, err := NewTripperware(testConfig, util_log.Logger, fakeLimits{maxQueryParallelism: 1}, config.SchemaConfig{}, nil, nil)
+	if stopper != nil {
+		defer stopper.Stop()
+	}
+	require.NoError(t, err)
+	rt, err := newfakeRoundTripper()
+	require.NoError(t, err)
+	defer rt.Close()
+
+	ctx := user.InjectOrgID(context.Background(), "1")
+	for _, tc := range []struct {
+		query  string
+		result parser.Value
+	}{
+		{query: `{app="foo"} |= "foo"`, result: streams},
+		{query: `rate({app="foo"} |= "foo"[1m])`, result: matrix},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			lreq := &LokiRequest{
+				Query:     tc.query,
+				Limit:     1000,
+				Step:      30000,
+				StartTs:   testTime.Add(-time.Hour),
+				EndTs:     testTime,
+				Direction: logproto.FORWARD,
+				Path:      "/loki/api/v1/query_range",
+			}
+			_, h := promqlResult(tc.result)
+			rt.setHandler(h)
+
+			req, err := LokiCodec.EncodeRequest(ctx, lreq)
+			require.NoError(t, err)
+			req = req.WithContext(ctx)
+			require.NoError(t, user.InjectOrgIDIntoHTTPRequest(ctx, req))
+			req.Header.Set("Accept", "application/json;q=0.5, application/vnd.apache.arrow.stream")
+
+			resp, err := tpw(rt).RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, "application/vnd.apache.arrow.stream", resp.Header.Get("Content-Type"))
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			// the stream starts with the continuation marker of its schema, and ends with the end of stream marker
+			require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff}, body[:4])
+			require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, body[len(body)-8:])
+
+			req.Header.Del("Accept")
+			resp, err = tpw(rt).RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		})
+	}
+}
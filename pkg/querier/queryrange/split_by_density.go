@@ -0,0 +1,148 @@
+package queryrange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/config"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// minDensitySplitInterval is the smallest interval dense splits are split further by.
+const minDensitySplitInterval = time.Minute
+
+// densitySplitter adapts the splits of queries by a fixed interval to the density of the data they select,
+// so that each split selects about the target bytes: adjacent splits of sparse data are merged, and splits
+// of dense data are split further. The bytes are estimated by the TSDB index stats of each split.
+type densitySplitter struct {
+	confs   ShardingConfigs
+	handler queryrangebase.Handler
+	logger  log.Logger
+}
+
+// statsHandler sends the index stats requests to next, bypassing the middlewares of queries.
+func statsHandler(next http.RoundTripper, codec queryrangebase.Codec) queryrangebase.Handler {
+	return queryrangebase.HandlerFunc(limitedRoundTripper{next: next, codec: codec}.do)
+}
+
+// adapt returns the splits of r adapted to the density of their data, splitting dense splits further
+// with splitter. Splits of requests whose index has no stats are returned unchanged, as well as all
+// splits when the stats can't be queried.
+func (s *densitySplitter) adapt(
+	ctx context.Context,
+	r queryrangebase.Request,
+	splits []queryrangebase.Request,
+	splitter Splitter,
+	targetBytes uint64,
+	parallelism int,
+) ([]queryrangebase.Request, error) {
+	if _, ok := r.(*LokiRequest); !ok || len(splits) == 0 {
+		return splits, nil
+	}
+	if conf, err := s.confs.ValidRange(r.GetStart(), r.GetEnd()); err != nil || conf.IndexType != config.TSDBType {
+		return splits, nil
+	}
+
+	expr, err := syntax.ParseExpr(r.GetQuery())
+	if err != nil {
+		return nil, err
+	}
+	grps := syntax.MatcherGroups(expr)
+	if len(grps) == 0 {
+		return splits, nil
+	}
+
+	logger := util_log.WithContext(ctx, s.logger)
+	bytes, err := s.splitBytes(ctx, splits, grps, parallelism)
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed querying index stats, keeping fixed splits", "err", err)
+		return splits, nil
+	}
+
+	adapted := make([]queryrangebase.Request, 0, len(splits))
+	var (
+		merged      *LokiRequest
+		mergedBytes uint64
+	)
+	flush := func() {
+		if merged != nil {
+			adapted = append(adapted, merged)
+			merged = nil
+		}
+	}
+	for i, split := range splits {
+		req := split.(*LokiRequest)
+
+		if bytes[i] > targetBytes {
+			flush()
+			n := (bytes[i] + targetBytes - 1) / targetBytes
+			interval := req.EndTs.Sub(req.StartTs) / time.Duration(n)
+			if interval < minDensitySplitInterval {
+				interval = minDensitySplitInterval
+			}
+			dense, err := splitter(req, interval)
+			if err != nil {
+				return nil, err
+			}
+			adapted = append(adapted, dense...)
+			continue
+		}
+
+		if merged != nil && mergedBytes+bytes[i] <= targetBytes {
+			merged = merged.WithStartEndTime(merged.StartTs, req.EndTs)
+			mergedBytes += bytes[i]
+			continue
+		}
+		flush()
+		merged, mergedBytes = req, bytes[i]
+	}
+	flush()
+
+	level.Debug(logger).Log(
+		"msg", "adapted splits to data density",
+		"splits", len(splits),
+		"adapted", len(adapted),
+		"target_bytes", targetBytes,
+	)
+	return adapted, nil
+}
+
+// splitBytes returns the bytes selected by each split, summed over the matcher groups of the query.
+func (s *densitySplitter) splitBytes(ctx context.Context, splits []queryrangebase.Request, grps []syntax.MatcherRange, parallelism int) ([]uint64, error) {
+	perGroup := make([]uint64, len(splits)*len(grps))
+	if err := concurrency.ForEachJob(ctx, len(perGroup), parallelism, func(ctx context.Context, i int) error {
+		split, grp := splits[i/len(grps)], grps[i%len(grps)]
+		resp, err := s.handler.Do(ctx, &logproto.IndexStatsRequest{
+			From:     model.Time(split.GetStart()).Add(-grp.Interval - grp.Offset),
+			Through:  model.Time(split.GetEnd()).Add(-grp.Offset),
+			Matchers: syntax.MatchersString(grp.Matchers),
+		})
+		if err != nil {
+			return err
+		}
+		casted, ok := resp.(*IndexStatsResponse)
+		if !ok {
+			return fmt.Errorf("expected *IndexStatsResponse while querying index, got %T", resp)
+		}
+		perGroup[i] = casted.Response.Bytes
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	bytes := make([]uint64, len(splits))
+	for i, b := range perGroup {
+		bytes[i/len(grps)] += b
+	}
+	return bytes, nil
+}
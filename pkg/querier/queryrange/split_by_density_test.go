@@ -0,0 +1,120 @@
+package queryrange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/storage/config"
+)
+
+func Test_densitySplitter_adapt(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	start := time.Unix(0, 0)
+
+	// bytes of each hour, by the hour of the start of the stats request.
+	density := []uint64{10, 10, 100, 10, 10, 10}
+	statsHandler := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		req := r.(*logproto.IndexStatsRequest)
+		require.Equal(t, `{app="foo"}`, req.Matchers)
+		return &IndexStatsResponse{Response: &logproto.IndexStatsResponse{
+			Bytes: density[req.From.Time().Sub(start)/time.Hour],
+		}}, nil
+	})
+
+	req := &LokiRequest{
+		Query:     `{app="foo"} |= "bar"`,
+		Limit:     100,
+		StartTs:   start,
+		EndTs:     start.Add(6 * time.Hour),
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/query_range",
+	}
+	splits, err := splitByTime(req, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, splits, 6)
+
+	split := func(from, through time.Duration) queryrangebase.Request {
+		return req.WithStartEndTime(start.Add(from), start.Add(through))
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		confs    ShardingConfigs
+		expected []queryrangebase.Request
+	}{
+		{
+			desc:  "tsdb",
+			confs: ShardingConfigs{{IndexType: config.TSDBType}},
+			expected: []queryrangebase.Request{
+				split(0, 2*time.Hour),
+				split(2*time.Hour, 2*time.Hour+20*time.Minute),
+				split(2*time.Hour+20*time.Minute, 2*time.Hour+40*time.Minute),
+				split(2*time.Hour+40*time.Minute, 3*time.Hour),
+				split(3*time.Hour, 6*time.Hour),
+			},
+		},
+		{
+			desc:     "no index stats",
+			confs:    ShardingConfigs{{IndexType: config.BoltDBShipperType}},
+			expected: splits,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			s := &densitySplitter{confs: tc.confs, handler: statsHandler, logger: log.NewNopLogger()}
+			adapted, err := s.adapt(ctx, req, splits, splitByTime, 40, 2)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, adapted)
+		})
+	}
+}
+
+func Test_splitByDensity_Do(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+	start := time.Unix(0, 0)
+
+	statsHandler := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		return &IndexStatsResponse{Response: &logproto.IndexStatsResponse{Bytes: 1}}, nil
+	})
+
+	var queried []queryrangebase.Request
+	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		queried = append(queried, r)
+		return &LokiResponse{Status: loghttp.QueryStatusSuccess, Direction: logproto.FORWARD}, nil
+	})
+
+	split := SplitByDensityMiddleware(
+		ShardingConfigs{{IndexType: config.TSDBType}},
+		fakeLimits{
+			maxQueryParallelism: 1,
+			splits:              map[string]time.Duration{"1": time.Hour},
+			splitTargetBytes:    10,
+		},
+		LokiCodec,
+		splitByTime,
+		nilMetrics,
+		statsHandler,
+		log.NewNopLogger(),
+	).Wrap(next)
+
+	req := &LokiRequest{
+		Query:     `{app="foo"}`,
+		Limit:     100,
+		StartTs:   start,
+		EndTs:     start.Add(6 * time.Hour),
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/query_range",
+	}
+	_, err := split.Do(ctx, req)
+	require.NoError(t, err)
+
+	// The splits of sparse data are merged into a single request.
+	require.Equal(t, []queryrangebase.Request{req}, queried)
+}
@@ -3,8 +3,10 @@ package queryrange
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/go-kit/log"
 	"github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,6 +25,9 @@ import (
 type lokiResult struct {
 	req queryrangebase.Request
 	ch  chan *packedResp
+
+	// index of the sub-query in the order its response is merged.
+	index int
 }
 
 type packedResp struct {
@@ -30,6 +35,39 @@ type packedResp struct {
 	err  error
 }
 
+// limitPushdown tracks the number of entries returned by the sub-queries of a log query, so that each sub-query
+// only fetches the entries which can still be part of the result after the entries of the sub-queries merged before it.
+type limitPushdown struct {
+	mtx    sync.Mutex
+	limit  int64
+	counts []int64
+}
+
+func newLimitPushdown(limit int64, subqueries int) *limitPushdown {
+	return &limitPushdown{
+		limit:  limit,
+		counts: make([]int64, subqueries),
+	}
+}
+
+// remaining returns the number of entries the sub-query can contribute to the result.
+func (l *limitPushdown) remaining(index int) int64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	remaining := l.limit
+	for _, count := range l.counts[:index] {
+		remaining -= count
+	}
+	return remaining
+}
+
+func (l *limitPushdown) returned(index int, count int64) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.counts[index] = count
+}
+
 type SplitByMetrics struct {
 	splits prometheus.Histogram
 }
@@ -51,6 +89,7 @@ type splitByInterval struct {
 	merger   queryrangebase.Merger
 	metrics  *SplitByMetrics
 	splitter Splitter
+	density  *densitySplitter
 }
 
 type Splitter func(req queryrangebase.Request, interval time.Duration) ([]queryrangebase.Request, error)
@@ -68,6 +107,34 @@ func SplitByIntervalMiddleware(limits Limits, merger queryrangebase.Merger, spli
 	})
 }
 
+// SplitByDensityMiddleware is like SplitByIntervalMiddleware, but adapts the splits of queries to the density of
+// the data they select when the tenant has a split target bytes. The index stats of the splits are requested
+// from statsHandler.
+func SplitByDensityMiddleware(
+	confs ShardingConfigs,
+	limits Limits,
+	merger queryrangebase.Merger,
+	splitter Splitter,
+	metrics *SplitByMetrics,
+	statsHandler queryrangebase.Handler,
+	logger log.Logger,
+) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return &splitByInterval{
+			next:     next,
+			limits:   limits,
+			merger:   merger,
+			metrics:  metrics,
+			splitter: splitter,
+			density: &densitySplitter{
+				confs:   confs,
+				handler: statsHandler,
+				logger:  log.With(logger, "middleware", "SplitByDensity"),
+			},
+		}
+	})
+}
+
 func (h *splitByInterval) Feed(ctx context.Context, input []*lokiResult) chan *lokiResult {
 	ch := make(chan *lokiResult)
 
@@ -92,7 +159,8 @@ func (h *splitByInterval) Process(
 	threshold int64,
 	input []*lokiResult,
 	maxSeries int,
-) ([]queryrangebase.Response, error) {
+	sizeLimiter *responseSizeLimiter,
+) ([]queryrangebase.Response, bool, error) {
 	var responses []queryrangebase.Response
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -111,21 +179,36 @@ func (h *splitByInterval) Process(
 		p = len(input)
 	}
 
+	var pushdown *limitPushdown
+	if !unlimited {
+		pushdown = newLimitPushdown(threshold, len(input))
+		for i, x := range input {
+			x.index = i
+		}
+	}
+
 	// per request wrapped handler for limiting the amount of series.
 	next := newSeriesLimiter(maxSeries).Wrap(h.next)
 	for i := 0; i < p; i++ {
-		go h.loop(ctx, ch, next)
+		go h.loop(ctx, ch, next, pushdown)
 	}
 
 	for _, x := range input {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, false, ctx.Err()
 		case data := <-x.ch:
 			if data.err != nil {
-				return nil, data.err
+				return nil, false, data.err
 			}
 
+			// the responses are merged in order, so the ones fitting in the maximum size are a complete prefix of
+			// the result.
+			if fits, err := sizeLimiter.add(data.resp); err != nil {
+				return nil, false, err
+			} else if !fits {
+				return responses, true, nil
+			}
 			responses = append(responses, data.resp)
 
 			// see if we can exit early if a limit has been reached
@@ -133,7 +216,7 @@ func (h *splitByInterval) Process(
 				threshold -= casted.Count()
 
 				if threshold <= 0 {
-					return responses, nil
+					return responses, false, nil
 				}
 
 			}
@@ -141,18 +224,33 @@ func (h *splitByInterval) Process(
 		}
 	}
 
-	return responses, nil
+	return responses, false, nil
 }
 
-func (h *splitByInterval) loop(ctx context.Context, ch <-chan *lokiResult, next queryrangebase.Handler) {
+func (h *splitByInterval) loop(ctx context.Context, ch <-chan *lokiResult, next queryrangebase.Handler, pushdown *limitPushdown) {
 	for data := range ch {
+		req := data.req
+		if lokiReq, ok := req.(*LokiRequest); ok && pushdown != nil {
+			remaining := pushdown.remaining(data.index)
+			if remaining <= 0 {
+				// The sub-queries merged before this one already returned enough entries, so its response won't be read.
+				continue
+			}
+			if remaining < int64(lokiReq.Limit) {
+				req = lokiReq.WithLimit(uint32(remaining))
+			}
+		}
 
 		sp, ctx := opentracing.StartSpanFromContext(ctx, "interval")
-		data.req.LogToSpan(sp)
+		req.LogToSpan(sp)
 
-		resp, err := next.Do(ctx, data.req)
+		resp, err := next.Do(ctx, req)
 		sp.Finish()
 
+		if casted, ok := resp.(*LokiResponse); ok && err == nil && pushdown != nil {
+			pushdown.returned(data.index, casted.Count())
+		}
+
 		select {
 		case <-ctx.Done():
 			return
@@ -177,6 +275,13 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 	if err != nil {
 		return nil, err
 	}
+	if targetBytes := validation.SmallestPositiveIntPerTenant(tenantIDs, h.limits.QuerySplitTargetBytes); h.density != nil && targetBytes > 0 {
+		parallelism := validation.SmallestPositiveIntPerTenant(tenantIDs, h.limits.MaxQueryParallelism)
+		intervals, err = h.density.adapt(ctx, r, intervals, h.splitter, uint64(targetBytes), parallelism)
+		if err != nil {
+			return nil, err
+		}
+	}
 	h.metrics.splits.Observe(float64(len(intervals)))
 
 	// no interval should not be processed by the frontend.
@@ -218,11 +323,23 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 
 	maxSeries := validation.SmallestPositiveIntPerTenant(tenantIDs, h.limits.MaxQuerySeries)
 	maxParallelism := validation.SmallestPositiveIntPerTenant(tenantIDs, h.limits.MaxQueryParallelism)
-	resps, err := h.Process(ctx, maxParallelism, limit, input, maxSeries)
+	resps, truncated, err := h.Process(ctx, maxParallelism, limit, input, maxSeries, newResponseSizeLimiter(tenantIDs, h.limits))
+	if err != nil {
+		return nil, err
+	}
+	if len(resps) == 0 && truncated {
+		resp := emptyTruncatedResponse(r)
+		markTruncated(resp)
+		return resp, nil
+	}
+	resp, err := h.merger.MergeResponse(resps...)
 	if err != nil {
 		return nil, err
 	}
-	return h.merger.MergeResponse(resps...)
+	if truncated {
+		markTruncated(resp)
+	}
+	return pageResponse(r, resp)
 }
 
 func splitByTime(req queryrangebase.Request, interval time.Duration) ([]queryrangebase.Request, error) {
@@ -248,11 +365,13 @@ func splitByTime(req queryrangebase.Request, interval time.Duration) ([]queryran
 		// avoid querying duplicate data in adjacent queries.
 		util.ForInterval(interval, r.StartTs, r.EndTs, true, func(start, end time.Time) {
 			reqs = append(reqs, &LokiSeriesRequest{
-				Match:   r.Match,
-				Path:    r.Path,
-				StartTs: start,
-				EndTs:   end,
-				Shards:  r.Shards,
+				Match:     r.Match,
+				Path:      r.Path,
+				StartTs:   start,
+				EndTs:     end,
+				Shards:    r.Shards,
+				Limit:     r.Limit,
+				PageToken: r.PageToken,
 			})
 		})
 	case *LokiLabelNamesRequest:
@@ -261,9 +380,11 @@ func splitByTime(req queryrangebase.Request, interval time.Duration) ([]queryran
 		// avoid querying duplicate data in adjacent queries.
 		util.ForInterval(interval, r.StartTs, r.EndTs, true, func(start, end time.Time) {
 			reqs = append(reqs, &LokiLabelNamesRequest{
-				Path:    r.Path,
-				StartTs: start,
-				EndTs:   end,
+				Path:      r.Path,
+				StartTs:   start,
+				EndTs:     end,
+				Limit:     r.Limit,
+				PageToken: r.PageToken,
 			})
 		})
 	default:
@@ -904,6 +904,122 @@ func Test_ExitEarly(t *testing.T) {
 	require.Equal(t, expected, res)
 }
 
+func Test_LimitPushdown(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	var limits []uint32
+	var mtx sync.Mutex
+
+	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		req := r.(*LokiRequest)
+
+		mtx.Lock()
+		limits = append(limits, req.Limit)
+		mtx.Unlock()
+
+		// Each sub-query returns up to 2 entries.
+		var entries []logproto.Entry
+		for i := 0; i < 2 && i < int(req.Limit); i++ {
+			ts := req.StartTs.Add(time.Duration(i) * time.Minute)
+			entries = append(entries, logproto.Entry{Timestamp: ts, Line: fmt.Sprintf("%d", ts.UnixNano())})
+		}
+		return &LokiResponse{
+			Status:    loghttp.QueryStatusSuccess,
+			Direction: req.Direction,
+			Limit:     req.Limit,
+			Version:   uint32(loghttp.VersionV1),
+			Data: LokiData{
+				ResultType: loghttp.ResultTypeStream,
+				Result:     []logproto.Stream{{Labels: `{foo="bar"}`, Entries: entries}},
+			},
+		}, nil
+	})
+
+	split := SplitByIntervalMiddleware(
+		WithSplitByLimits(fakeLimits{maxQueryParallelism: 1}, time.Hour),
+		LokiCodec,
+		splitByTime,
+		nilMetrics,
+	).Wrap(next)
+
+	res, err := split.Do(ctx, &LokiRequest{
+		StartTs:   time.Unix(0, 0),
+		EndTs:     time.Unix(0, (4 * time.Hour).Nanoseconds()),
+		Limit:     3,
+		Direction: logproto.FORWARD,
+		Path:      "/api/prom/query_range",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), res.(*LokiResponse).Count())
+
+	// The second sub-query only fetches the entry missing after the 2 entries of the first one.
+	require.Equal(t, []uint32{3, 1}, limits)
+}
+
+func Test_MaxResponseBytes(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	response := func(req *LokiRequest) *LokiResponse {
+		return &LokiResponse{
+			Status:    loghttp.QueryStatusSuccess,
+			Direction: req.Direction,
+			Limit:     req.Limit,
+			Version:   uint32(loghttp.VersionV1),
+			Data: LokiData{
+				ResultType: loghttp.ResultTypeStream,
+				Result: []logproto.Stream{{
+					Labels:  `{foo="bar"}`,
+					Entries: []logproto.Entry{{Timestamp: req.StartTs, Line: fmt.Sprintf("%020d", req.StartTs.UnixNano())}},
+				}},
+			},
+		}
+	}
+	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		return response(r.(*LokiRequest)), nil
+	})
+	req := &LokiRequest{
+		StartTs:   time.Unix(0, 0).Add(24 * time.Hour),
+		EndTs:     time.Unix(0, 0).Add(28 * time.Hour),
+		Limit:     100,
+		Direction: logproto.BACKWARD,
+		Path:      "/loki/api/v1/query_range",
+	}
+	// all the sub-query responses have the same size
+	size := response(req).Size()
+
+	do := func(limits fakeLimits) (queryrangebase.Response, error) {
+		limits.maxQueryParallelism = 1
+		return SplitByIntervalMiddleware(WithSplitByLimits(limits, time.Hour), LokiCodec, splitByTime, nilMetrics).Wrap(next).Do(ctx, req)
+	}
+
+	res, err := do(fakeLimits{maxResponseBytes: 4 * size})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), res.(*LokiResponse).Count())
+	require.False(t, isTruncated(res))
+
+	_, err = do(fakeLimits{maxResponseBytes: 3 * size})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), fmt.Sprintf(errResponseTooLarge, 3*size))
+
+	// the truncated response holds the most recent entries, which are merged first
+	res, err = do(fakeLimits{maxResponseBytes: 3*size - 1, truncateResponse: true})
+	require.NoError(t, err)
+	require.True(t, isTruncated(res))
+	entries := res.(*LokiResponse).Data.Result[0].Entries
+	require.Len(t, entries, 2)
+	require.Equal(t, time.Unix(0, 0).Add(27*time.Hour), entries[0].Timestamp)
+	require.Equal(t, time.Unix(0, 0).Add(26*time.Hour), entries[1].Timestamp)
+
+	httpRes, err := LokiCodec.EncodeResponse(ctx, res)
+	require.NoError(t, err)
+	require.Equal(t, "true", httpRes.Header.Get(TruncatedResponseHeader))
+
+	res, err = do(fakeLimits{maxResponseBytes: 1, truncateResponse: true})
+	require.NoError(t, err)
+	require.True(t, isTruncated(res))
+	require.Zero(t, res.(*LokiResponse).Count())
+}
+
 func Test_DoesntDeadlock(t *testing.T) {
 	n := 10
 
@@ -12,13 +12,16 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
 	"github.com/weaveworks/common/middleware"
 
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/pkg/lokifrontend/audit"
 	"github.com/grafana/loki/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/pkg/tenantusage"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	"github.com/grafana/loki/pkg/util/spanlogger"
 )
@@ -42,6 +45,17 @@ var (
 	StatsHTTPMiddleware middleware.Interface = statsHTTPMiddleware(defaultMetricRecorder)
 )
 
+// NewStatsHTTPMiddleware returns a StatsHTTPMiddleware which also accounts the queries of each tenant to the given
+// usage tracker.
+func NewStatsHTTPMiddleware(tenantUsage *tenantusage.Tracker) middleware.Interface {
+	return statsHTTPMiddleware(metricRecorderFn(func(data *queryData) {
+		recordQueryMetrics(data)
+		if tenantIDs, err := tenant.TenantIDs(data.ctx); err == nil {
+			tenantUsage.AddQuery(tenant.JoinTenantIDs(tenantIDs), data.statistics.Summary.TotalBytesProcessed)
+		}
+	}))
+}
+
 // recordQueryMetrics will be called from Query Frontend middleware chain for any type of query.
 func recordQueryMetrics(data *queryData) {
 	logger := log.With(util_log.Logger, "component", "frontend")
@@ -158,6 +172,7 @@ func StatsCollectorMiddleware() queryrangebase.Middleware {
 				// Log and record metrics for the current query
 				statistics.ComputeSummary(time.Since(start), 0, totalEntries)
 				statistics.Log(level.Debug(logger))
+				audit.SetBytesProcessed(ctx, statistics.Summary.TotalBytesProcessed)
 			}
 			ctxValue := ctx.Value(ctxKey)
 			if data, ok := ctxValue.(*queryData); ok {
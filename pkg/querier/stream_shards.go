@@ -0,0 +1,90 @@
+package querier
+
+import (
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/ingester"
+	"github.com/grafana/loki/pkg/iter"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+)
+
+// isRateLimitedShard returns whether the value of the shard label is the one of a shard of a stream which was over its
+// rate limit. Only those are merged back, the shards of the streams split by their desired rate being left as is.
+func isRateLimitedShard(value string) bool {
+	return strings.HasPrefix(value, ingester.RateLimitedShardPrefix)
+}
+
+// streamUnsharder removes the label added by the distributors when they shard a stream over its rate limit,
+// so that the shards of the stream are merged back into the original stream at read time.
+type streamUnsharder struct {
+	// cache maps the labels of the shards to the labels of the original streams.
+	cache map[string]string
+}
+
+func (u *streamUnsharder) unshard(lbs string) string {
+	if !strings.Contains(lbs, ingester.RateLimitedShardPrefix) {
+		return lbs
+	}
+	if unsharded, ok := u.cache[lbs]; ok {
+		return unsharded
+	}
+
+	unsharded := lbs
+	if parsed, err := syntax.ParseLabels(lbs); err == nil && isRateLimitedShard(parsed.Get(ingester.ShardLbName)) {
+		unsharded = labels.NewBuilder(parsed).Del(ingester.ShardLbName).Labels(nil).String()
+	}
+	if u.cache == nil {
+		u.cache = map[string]string{}
+	}
+	u.cache[lbs] = unsharded
+	return unsharded
+}
+
+type unshardedEntryIterator struct {
+	iter.EntryIterator
+	streamUnsharder
+}
+
+func newUnshardedEntryIterator(it iter.EntryIterator) iter.EntryIterator {
+	return &unshardedEntryIterator{EntryIterator: it}
+}
+
+func (it *unshardedEntryIterator) Labels() string {
+	return it.unshard(it.EntryIterator.Labels())
+}
+
+type unshardedSampleIterator struct {
+	iter.SampleIterator
+	streamUnsharder
+}
+
+func newUnshardedSampleIterator(it iter.SampleIterator) iter.SampleIterator {
+	return &unshardedSampleIterator{SampleIterator: it}
+}
+
+func (it *unshardedSampleIterator) Labels() string {
+	return it.unshard(it.SampleIterator.Labels())
+}
+
+// unshardSeries removes the shard label from the series of the shards of the streams over their rate limit.
+func unshardSeries(series []logproto.SeriesIdentifier) {
+	for _, s := range series {
+		if isRateLimitedShard(s.Labels[ingester.ShardLbName]) {
+			delete(s.Labels, ingester.ShardLbName)
+		}
+	}
+}
+
+// withoutRateLimitedShards removes the values of the shard label of the shards of the streams over their rate limit.
+func withoutRateLimitedShards(values []string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if !isRateLimitedShard(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
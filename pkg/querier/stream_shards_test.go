@@ -0,0 +1,119 @@
+package querier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/ingester"
+	"github.com/grafana/loki/pkg/iter"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+func TestUnshardedEntryIterator(t *testing.T) {
+	it := newUnshardedEntryIterator(iter.NewMergeEntryIterator(context.Background(), []iter.EntryIterator{
+		iter.NewStreamIterator(logproto.Stream{
+			Labels:  `{app="foo", __stream_shard__="rate_limited_0"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "1"}, {Timestamp: time.Unix(0, 3), Line: "3"}},
+		}),
+		iter.NewStreamIterator(logproto.Stream{
+			Labels:  `{app="foo", __stream_shard__="rate_limited_1"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 2), Line: "2"}},
+		}),
+		iter.NewStreamIterator(logproto.Stream{
+			Labels:  `{app="bar"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 4), Line: "4"}},
+		}),
+		// The shards of the streams split by their desired rate are left as is.
+		iter.NewStreamIterator(logproto.Stream{
+			Labels:  `{app="baz", __stream_shard__="0"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 5), Line: "5"}},
+		}),
+	}, logproto.FORWARD))
+	defer it.Close()
+
+	var lines, labels []string
+	for it.Next() {
+		lines = append(lines, it.Entry().Line)
+		labels = append(labels, it.Labels())
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, []string{"1", "2", "3", "4", "5"}, lines)
+	require.Equal(t, []string{`{app="foo"}`, `{app="foo"}`, `{app="foo"}`, `{app="bar"}`, `{app="baz", __stream_shard__="0"}`}, labels)
+}
+
+func TestUnshardedSampleIterator(t *testing.T) {
+	it := newUnshardedSampleIterator(iter.NewMergeSampleIterator(context.Background(), []iter.SampleIterator{
+		iter.NewSeriesIterator(logproto.Series{
+			Labels:  `{app="foo", __stream_shard__="rate_limited_0"}`,
+			Samples: []logproto.Sample{{Timestamp: 1, Value: 1}},
+		}),
+		iter.NewSeriesIterator(logproto.Series{
+			Labels:  `{app="foo", __stream_shard__="rate_limited_1"}`,
+			Samples: []logproto.Sample{{Timestamp: 2, Value: 1}},
+		}),
+	}))
+	defer it.Close()
+
+	var labels []string
+	for it.Next() {
+		labels = append(labels, it.Labels())
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, []string{`{app="foo"}`, `{app="foo"}`}, labels)
+}
+
+func TestQuerier_UnshardsSeriesAndLabels(t *testing.T) {
+	start, end := time.Now().Add(-time.Minute), time.Now()
+	isValues := func(values bool) interface{} {
+		return mock.MatchedBy(func(req *logproto.LabelRequest) bool { return req.Values == values })
+	}
+
+	for _, tc := range []struct {
+		desc          string
+		shards        []string
+		expectedNames []string
+	}{
+		{"only rate limited shards", []string{"rate_limited_0", "rate_limited_1"}, []string{"app"}},
+		{"shards by desired rate", []string{"0", "rate_limited_0"}, []string{ingester.ShardLbName, "app"}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			ingesterClient := newQuerierClientMock()
+			ingesterClient.On("Label", mock.Anything, isValues(false), mock.Anything).Return(mockLabelResponse([]string{ingester.ShardLbName, "app"}), nil)
+			ingesterClient.On("Label", mock.Anything, isValues(true), mock.Anything).Return(mockLabelResponse(tc.shards), nil)
+			ingesterClient.On("Series", mock.Anything, mock.Anything, mock.Anything).Return(&logproto.SeriesResponse{Series: []logproto.SeriesIdentifier{
+				{Labels: map[string]string{"app": "foo", ingester.ShardLbName: "rate_limited_0"}},
+				{Labels: map[string]string{"app": "foo", ingester.ShardLbName: "rate_limited_1"}},
+				{Labels: map[string]string{"app": "bar", ingester.ShardLbName: "0"}},
+			}}, nil)
+
+			limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+			require.NoError(t, err)
+			cfg := mockQuerierConfig()
+			cfg.QueryIngesterOnly = true
+			q, err := newQuerier(cfg, mockIngesterClientConfig(), newIngesterClientMockFactory(ingesterClient), mockReadRingWithOneActiveIngester(), &mockDeleteGettter{}, newStoreMock(), limits)
+			require.NoError(t, err)
+			ctx := user.InjectOrgID(context.Background(), "test")
+
+			names, err := q.Label(ctx, &logproto.LabelRequest{Start: &start, End: &end})
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedNames, names.Values)
+
+			values, err := q.Label(ctx, &logproto.LabelRequest{Name: ingester.ShardLbName, Values: true, Start: &start, End: &end})
+			require.NoError(t, err)
+			require.Equal(t, withoutRateLimitedShards(tc.shards), values.Values)
+
+			series, err := q.Series(ctx, &logproto.SeriesRequest{Start: start, End: end})
+			require.NoError(t, err)
+			require.ElementsMatch(t, []logproto.SeriesIdentifier{
+				{Labels: map[string]string{"app": "foo"}},
+				{Labels: map[string]string{"app": "bar", ingester.ShardLbName: "0"}},
+			}, series.Series)
+		})
+	}
+}
@@ -289,6 +289,8 @@ var (
 	ErrNoRuleGroups = errors.New("no rule groups found")
 	// ErrBadRuleGroup is returned when the provided rule group can not be unmarshalled
 	ErrBadRuleGroup = errors.New("unable to decoded rule group")
+	// ErrNoRuleGroupVersions is returned when the rule store doesn't keep the versions of the rule groups
+	ErrNoRuleGroupVersions = errors.New("the rule store does not keep the versions of the rule groups")
 )
 
 func marshalAndSend(output interface{}, w http.ResponseWriter, logger log.Logger) {
@@ -509,6 +511,97 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 	respondAccepted(w, logger)
 }
 
+// ListRuleGroupVersions returns the stored versions of a rule group, from the oldest to the most recent one.
+func (a *API) ListRuleGroupVersions(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+	userID, namespace, groupName, err := parseRequest(req, true, true)
+	if err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	store, ok := a.store.(rulestore.VersionedRuleStore)
+	if !ok {
+		http.Error(w, ErrNoRuleGroupVersions.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	versions, err := store.ListRuleGroupVersions(req.Context(), userID, namespace, groupName)
+	if err != nil {
+		if errors.Is(err, rulestore.ErrGroupNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	marshalAndSend(versions, w, logger)
+}
+
+// RestoreRuleGroupVersion sets a rule group, deleted or not, to one of its stored versions. The restored rule
+// group becomes its most recent version.
+func (a *API) RestoreRuleGroupVersion(w http.ResponseWriter, req *http.Request) {
+	logger := util_log.WithContext(req.Context(), a.logger)
+	userID, namespace, groupName, err := parseRequest(req, true, true)
+	if err != nil {
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	store, ok := a.store.(rulestore.VersionedRuleStore)
+	if !ok {
+		http.Error(w, ErrNoRuleGroupVersions.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	rg, err := store.GetRuleGroupVersion(req.Context(), userID, namespace, groupName, mux.Vars(req)["version"])
+	if err != nil {
+		if errors.Is(err, rulestore.ErrGroupVersionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	// the limits may have changed since the version was set
+	if err := a.ruler.AssertMaxRulesPerRuleGroup(userID, len(rg.Rules)); err != nil {
+		level.Error(logger).Log("msg", "limit validation failure", "err", err.Error(), "user", userID)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = a.store.GetRuleGroup(req.Context(), userID, namespace, groupName)
+	switch {
+	case errors.Is(err, rulestore.ErrGroupNotFound):
+		// a deleted rule group counts again in the rule groups of the tenant
+		rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), userID, "")
+		if err != nil {
+			level.Error(logger).Log("msg", "unable to fetch current rule groups for validation", "err", err.Error(), "user", userID)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := a.ruler.AssertMaxRuleGroups(userID, len(rgs)+1); err != nil {
+			level.Error(logger).Log("msg", "limit validation failure", "err", err.Error(), "user", userID)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case err != nil:
+		respondError(logger, w, err.Error())
+		return
+	}
+
+	level.Debug(logger).Log("msg", "attempting to restore rulegroup", "userID", userID, "group", rg.String())
+	if err := a.store.SetRuleGroup(req.Context(), userID, namespace, rg); err != nil {
+		level.Error(logger).Log("msg", "unable to store rule group", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respondAccepted(w, logger)
+}
+
 func (a *API) DeleteNamespace(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), a.logger)
 
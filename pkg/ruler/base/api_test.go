@@ -15,8 +15,12 @@ import (
 	"github.com/grafana/dskit/services"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
+	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/loki/pkg/ruler/rulespb"
+	"github.com/grafana/loki/pkg/ruler/rulestore"
+	"github.com/grafana/loki/pkg/ruler/rulestore/objectclient"
+	"github.com/grafana/loki/pkg/storage/chunk/client/testutils"
 )
 
 func TestRuler_rules(t *testing.T) {
@@ -410,3 +414,79 @@ func requestFor(t *testing.T, method string, url string, body io.Reader, userID
 
 	return req.WithContext(ctx)
 }
+
+func TestRuler_RuleGroupVersions(t *testing.T) {
+	cfg := defaultRulerConfig(t, newMockRuleStore(make(map[string]rulespb.RuleGroupList)))
+
+	r := newTestRuler(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	testutils.ResetMockStorage()
+	defer testutils.ResetMockStorage()
+	store := objectclient.NewVersionedRuleStore(testutils.NewMockStorage(), 1, 2, log.NewNopLogger())
+	a := NewAPI(r, store, log.NewNopLogger())
+
+	router := mux.NewRouter()
+	router.Path("/api/v1/rules/{namespace}").Methods(http.MethodPost).HandlerFunc(a.CreateRuleGroup)
+	router.Path("/api/v1/rules/{namespace}").Methods(http.MethodDelete).HandlerFunc(a.DeleteNamespace)
+	router.Path("/api/v1/rules/{namespace}/{groupName}").Methods(http.MethodGet).HandlerFunc(a.GetRuleGroup)
+	router.Path("/api/v1/rules/{namespace}/{groupName}").Methods(http.MethodDelete).HandlerFunc(a.DeleteRuleGroup)
+	router.Path("/api/v1/rules/{namespace}/{groupName}/versions").Methods(http.MethodGet).HandlerFunc(a.ListRuleGroupVersions)
+	router.Path("/api/v1/rules/{namespace}/{groupName}/versions/{version}/restore").Methods(http.MethodPost).HandlerFunc(a.RestoreRuleGroupVersion)
+
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, requestFor(t, method, "https://localhost:8080"+url, strings.NewReader(body), "user1"))
+		return w
+	}
+
+	// only the last two versions are kept
+	for _, expr := range []string{"up", "up{a=\"b\"}", "up{a=\"c\"}"} {
+		w := do(http.MethodPost, "/api/v1/rules/namespace", "name: test\nrules:\n- record: up_rule\n  expr: "+expr+"\n")
+		require.Equal(t, http.StatusAccepted, w.Code)
+	}
+
+	w := do(http.MethodGet, "/api/v1/rules/namespace/test/versions", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var versions []rulestore.RuleGroupVersion
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &versions))
+	require.Len(t, versions, 2)
+	require.True(t, versions[0].Created.Before(versions[1].Created))
+
+	// a deleted rule group can be restored
+	require.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/v1/rules/namespace/test", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/rules/namespace/test", "").Code)
+
+	w = do(http.MethodPost, "/api/v1/rules/namespace/test/versions/"+versions[0].Version+"/restore", "")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	w = do(http.MethodGet, "/api/v1/rules/namespace/test", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "name: test\nrules:\n    - record: up_rule\n      expr: up{a=\"b\"}\n", w.Body.String())
+
+	// the restored version becomes the most recent one
+	w = do(http.MethodGet, "/api/v1/rules/namespace/test/versions", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored []rulestore.RuleGroupVersion
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &restored))
+	require.Len(t, restored, 2)
+	require.Equal(t, versions[1], restored[0])
+
+	require.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/rules/namespace/test/versions/00000000000000000001/restore", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/rules/namespace/test/versions/invalid/restore", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/rules/namespace/unknown/versions", "").Code)
+
+	// the versions are deleted along with their namespace, even the ones of the deleted rule groups
+	w = do(http.MethodPost, "/api/v1/rules/namespace", "name: other\nrules:\n- record: up_rule\n  expr: up\n")
+	require.Equal(t, http.StatusAccepted, w.Code)
+	require.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/v1/rules/namespace/other", "").Code)
+	require.Equal(t, http.StatusAccepted, do(http.MethodDelete, "/api/v1/rules/namespace", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/rules/namespace/test/versions", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/rules/namespace/other/versions", "").Code)
+	require.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/v1/rules/namespace", "").Code)
+
+	// the versions are only kept by the versioned rule stores
+	a = NewAPI(r, r.store, log.NewNopLogger())
+	w = httptest.NewRecorder()
+	a.ListRuleGroupVersions(w, mux.SetURLVars(requestFor(t, http.MethodGet, "https://localhost:8080/api/v1/rules/namespace/test/versions", nil, "user1"), map[string]string{"namespace": "namespace", "groupName": "test"}))
+	require.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sync"
 
 	"github.com/go-kit/log"
@@ -19,6 +20,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"golang.org/x/net/context/ctxhttp"
 
+	ruler_config "github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/rulespb"
 )
 
@@ -114,6 +116,8 @@ func (r *DefaultMultiTenantManager) SyncRuleGroups(ctx context.Context, ruleGrou
 	}
 
 	r.managersTotal.Set(float64(len(r.userManagers)))
+
+	r.syncNotifiersConfig()
 }
 
 // syncRulesToManager maps the rule files to disk, detects any changes and will create/update the
@@ -182,21 +186,13 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string) (*notifie
 		return n.notifier, nil
 	}
 
+	amCfg, err := r.getTenantAlertmanagerConfig(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	nCfg, ok := r.notifiersCfg[userID]
 	if !ok {
-		amCfg := r.cfg.AlertManagerConfig
-		amOverrides := r.limits.RulerAlertManagerConfig(userID)
-		var err error
-
-		if amOverrides != nil {
-			tenantAmCfg, err := getAlertmanagerTenantConfig(r.cfg.AlertManagerConfig, *amOverrides)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get alertmaanger config for tenant %s: %w", userID, err)
-			}
-
-			amCfg = tenantAmCfg
-		}
-
 		nCfg, err = buildNotifierConfig(&amCfg, r.cfg.ExternalLabels)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build notifier config for tenant %s: %w", userID, err)
@@ -210,7 +206,7 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string) (*notifie
 	reg := prometheus.WrapRegistererWith(prometheus.Labels{"user": userID}, r.registry)
 	reg = prometheus.WrapRegistererWithPrefix("cortex_", reg)
 	n = newRulerNotifier(&notifier.Options{
-		QueueCapacity: r.cfg.NotificationQueueCapacity,
+		QueueCapacity: amCfg.NotificationQueueCapacity,
 		Registerer:    reg,
 		Do: func(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
 			// Note: The passed-in context comes from the Prometheus notifier
@@ -240,6 +236,56 @@ func (r *DefaultMultiTenantManager) getOrCreateNotifier(userID string) (*notifie
 	return n.notifier, nil
 }
 
+// getTenantAlertmanagerConfig returns the ruler alertmanager config with the tenant's overrides applied.
+func (r *DefaultMultiTenantManager) getTenantAlertmanagerConfig(userID string) (ruler_config.AlertManagerConfig, error) {
+	amCfg := r.cfg.AlertManagerConfig
+
+	amOverrides := r.limits.RulerAlertManagerConfig(userID)
+	if amOverrides == nil {
+		return amCfg, nil
+	}
+
+	tenantAmCfg, err := getAlertmanagerTenantConfig(amCfg, *amOverrides)
+	if err != nil {
+		return amCfg, fmt.Errorf("failed to get alertmanager config for tenant %s: %w", userID, err)
+	}
+
+	return tenantAmCfg, nil
+}
+
+// syncNotifiersConfig re-applies the notifier config of every tenant whose alertmanager
+// overrides changed, e.g. after a runtime config reload.
+func (r *DefaultMultiTenantManager) syncNotifiersConfig() {
+	r.notifiersMtx.Lock()
+	defer r.notifiersMtx.Unlock()
+
+	for userID, n := range r.notifiers {
+		amCfg, err := r.getTenantAlertmanagerConfig(userID)
+		if err != nil {
+			level.Error(r.logger).Log("msg", "unable to get alertmanager config", "user", userID, "err", err)
+			continue
+		}
+
+		nCfg, err := buildNotifierConfig(&amCfg, r.cfg.ExternalLabels)
+		if err != nil {
+			level.Error(r.logger).Log("msg", "unable to build notifier config", "user", userID, "err", err)
+			continue
+		}
+
+		if reflect.DeepEqual(nCfg, r.notifiersCfg[userID]) {
+			continue
+		}
+
+		if err := n.applyConfig(nCfg); err != nil {
+			level.Error(r.logger).Log("msg", "unable to apply notifier config", "user", userID, "err", err)
+			continue
+		}
+
+		r.notifiersCfg[userID] = nCfg
+		level.Info(r.logger).Log("msg", "updated notifier config", "user", userID)
+	}
+}
+
 func (r *DefaultMultiTenantManager) GetRules(userID string) []*promRules.Group {
 	var groups []*promRules.Group
 	r.userManagerMtx.Lock()
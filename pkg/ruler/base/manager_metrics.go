@@ -23,6 +23,7 @@ type ManagerMetrics struct {
 	GroupLastDuration    *prometheus.Desc
 	GroupRules           *prometheus.Desc
 	GroupLastEvalSamples *prometheus.Desc
+	GroupLastEvalLatency *prometheus.Desc
 }
 
 // NewManagerMetrics returns a ManagerMetrics struct
@@ -101,6 +102,12 @@ func NewManagerMetrics(disableRuleGroupLabel bool) *ManagerMetrics {
 			commonLabels,
 			nil,
 		),
+		GroupLastEvalLatency: prometheus.NewDesc(
+			"loki_ruler_rule_group_last_evaluation_latency_seconds",
+			"The time between the scheduled evaluation of the rule group and the end of its last evaluation.",
+			commonLabels,
+			nil,
+		),
 	}
 }
 
@@ -127,6 +134,7 @@ func (m *ManagerMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- m.GroupLastDuration
 	out <- m.GroupRules
 	out <- m.GroupLastEvalSamples
+	out <- m.GroupLastEvalLatency
 }
 
 // Collect implements the Collector interface
@@ -152,4 +160,5 @@ func (m *ManagerMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfGaugesPerUserWithLabels(out, m.GroupLastDuration, "prometheus_rule_group_last_duration_seconds", labels...)
 	data.SendSumOfGaugesPerUserWithLabels(out, m.GroupRules, "prometheus_rule_group_rules", labels...)
 	data.SendSumOfGaugesPerUserWithLabels(out, m.GroupLastEvalSamples, "prometheus_rule_group_last_evaluation_samples", labels...)
+	data.SendSumOfGaugesPerUserWithLabels(out, m.GroupLastEvalLatency, "loki_ruler_rule_group_last_evaluation_latency_seconds", labels...)
 }
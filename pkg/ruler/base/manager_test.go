@@ -7,12 +7,15 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/notifier"
 	promRules "github.com/prometheus/prometheus/rules"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/rulespb"
 	"github.com/grafana/loki/pkg/util/test"
 )
@@ -92,6 +95,44 @@ func TestSyncRuleGroups(t *testing.T) {
 	})
 }
 
+func TestSyncNotifiersConfig(t *testing.T) {
+	const user = "testUser"
+
+	limits := ruleLimits{alertManagerConfig: map[string]*config.AlertManagerConfig{
+		user: {AlertmanagerURL: "http://alertmanager-0.default.svc.cluster.local/alertmanager"},
+	}}
+
+	m, err := NewDefaultMultiTenantManager(Config{RulePath: t.TempDir()}, factory, nil, log.NewNopLogger(), limits)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	userRules := map[string]rulespb.RuleGroupList{
+		user: {
+			&rulespb.RuleGroupDesc{
+				Name:      "group1",
+				Namespace: "ns",
+				Interval:  1 * time.Minute,
+				User:      user,
+			},
+		},
+	}
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Equal(t, "alertmanager-0.default.svc.cluster.local", notifierTarget(m, user))
+
+	// Tenant overrides changed, e.g. by a runtime config reload.
+	limits.alertManagerConfig[user] = &config.AlertManagerConfig{AlertmanagerURL: "http://alertmanager-1.default.svc.cluster.local/alertmanager"}
+	m.SyncRuleGroups(context.Background(), userRules)
+	require.Equal(t, "alertmanager-1.default.svc.cluster.local", notifierTarget(m, user))
+}
+
+func notifierTarget(m *DefaultMultiTenantManager, user string) string {
+	m.notifiersMtx.Lock()
+	defer m.notifiersMtx.Unlock()
+
+	sd := m.notifiersCfg[user].AlertingConfig.AlertmanagerConfigs[0].ServiceDiscoveryConfigs[0].(discovery.StaticConfig)
+	return string(sd[0].Targets[0][model.AddressLabel])
+}
+
 func getManager(m *DefaultMultiTenantManager, user string) RulesManager {
 	m.userManagerMtx.Lock()
 	defer m.userManagerMtx.Unlock()
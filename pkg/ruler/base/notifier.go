@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -112,11 +113,25 @@ func getAlertmanagerTenantConfig(amConfig ruler_config.AlertManagerConfig, amOve
 	return amConfig, nil
 }
 
+// Alertmanager URLs can request DNS service discovery by prefixing their scheme,
+// e.g. dnssrv+http://_http._tcp.alertmanager.svc or dns+http://alertmanager.svc:9093.
+const (
+	dnsSDPrefix    = "dns+"
+	dnsSRVSDPrefix = "dnssrv+"
+)
+
+// alertmanagerURL is an Alertmanager URL along with the DNS record type used to discover it.
+// An empty sdType means the URL host is used as a static target.
+type alertmanagerURL struct {
+	*url.URL
+	sdType string
+}
+
 // Builds a Prometheus config.Config from a ruler.Config with just the required
 // options to configure notifications to Alertmanager.
 func buildNotifierConfig(amConfig *ruler_config.AlertManagerConfig, externalLabels labels.Labels) (*config.Config, error) {
 	amURLs := strings.Split(amConfig.AlertmanagerURL, ",")
-	validURLs := make([]*url.URL, 0, len(amURLs))
+	validURLs := make([]alertmanagerURL, 0, len(amURLs))
 
 	srvDNSregexp := regexp.MustCompile(`^_.+._.+`)
 	for _, h := range amURLs {
@@ -129,14 +144,34 @@ func buildNotifierConfig(amConfig *ruler_config.AlertManagerConfig, externalLabe
 			continue
 		}
 
-		// Given we only support SRV lookups as part of service discovery, we need to ensure
-		// hosts provided follow this specification: _service._proto.name
-		// e.g. _http._tcp.alertmanager.com
-		if amConfig.AlertmanagerDiscovery && !srvDNSregexp.MatchString(url.Host) {
-			return nil, fmt.Errorf("when alertmanager-discovery is on, host name must be of the form _portname._tcp.service.fqdn (is %q)", url.Host)
+		var sdType string
+		switch {
+		case strings.HasPrefix(url.Scheme, dnsSRVSDPrefix):
+			url.Scheme = strings.TrimPrefix(url.Scheme, dnsSRVSDPrefix)
+			sdType = "SRV"
+		case strings.HasPrefix(url.Scheme, dnsSDPrefix):
+			url.Scheme = strings.TrimPrefix(url.Scheme, dnsSDPrefix)
+			sdType = "A"
+		case amConfig.AlertmanagerDiscovery:
+			sdType = "SRV"
+		}
+
+		switch sdType {
+		case "SRV":
+			// Given we only support SRV lookups as part of service discovery, we need to ensure
+			// hosts provided follow this specification: _service._proto.name
+			// e.g. _http._tcp.alertmanager.com
+			if !srvDNSregexp.MatchString(url.Host) {
+				return nil, fmt.Errorf("when alertmanager-discovery is on, host name must be of the form _portname._tcp.service.fqdn (is %q)", url.Host)
+			}
+		case "A":
+			// A records carry no port, so it must be part of the URL.
+			if url.Port() == "" {
+				return nil, fmt.Errorf("when using dns+ alertmanager discovery, a port must be given (is %q)", url.Host)
+			}
 		}
 
-		validURLs = append(validURLs, url)
+		validURLs = append(validURLs, alertmanagerURL{URL: url, sdType: sdType})
 	}
 
 	if len(validURLs) == 0 {
@@ -166,9 +201,10 @@ func buildNotifierConfig(amConfig *ruler_config.AlertManagerConfig, externalLabe
 	return promConfig, nil
 }
 
-func amConfigFromURL(cfg *ruler_config.AlertManagerConfig, url *url.URL, apiVersion config.AlertmanagerAPIVersion) *config.AlertmanagerConfig {
+func amConfigFromURL(cfg *ruler_config.AlertManagerConfig, url alertmanagerURL, apiVersion config.AlertmanagerAPIVersion) *config.AlertmanagerConfig {
 	var sdConfig discovery.Configs
-	if cfg.AlertmanagerDiscovery {
+	switch url.sdType {
+	case "SRV":
 		sdConfig = discovery.Configs{
 			&dns.SDConfig{
 				Names:           []string{url.Host},
@@ -177,8 +213,18 @@ func amConfigFromURL(cfg *ruler_config.AlertManagerConfig, url *url.URL, apiVers
 				Port:            0, // Ignored, because of SRV.
 			},
 		}
-
-	} else {
+	case "A":
+		// The port has been validated when building the notifier config.
+		port, _ := strconv.Atoi(url.Port())
+		sdConfig = discovery.Configs{
+			&dns.SDConfig{
+				Names:           []string{url.Hostname()},
+				RefreshInterval: model.Duration(cfg.AlertmanagerRefreshInterval),
+				Type:            "A",
+				Port:            port,
+			},
+		}
+	default:
 		sdConfig = discovery.Configs{
 			discovery.StaticConfig{
 				{
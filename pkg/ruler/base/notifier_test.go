@@ -173,6 +173,92 @@ func TestBuildNotifierConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with a dnssrv+ URL and service discovery disabled",
+			cfg: &Config{
+				AlertManagerConfig: ruler_config.AlertManagerConfig{
+					AlertmanagerURL:             "dnssrv+http://_http._tcp.alertmanager.default.svc.cluster.local/alertmanager",
+					AlertmanagerRefreshInterval: time.Duration(60),
+				},
+			},
+			ncfg: &config.Config{
+				AlertingConfig: config.AlertingConfig{
+					AlertmanagerConfigs: []*config.AlertmanagerConfig{
+						{
+							APIVersion: "v1",
+							Scheme:     "http",
+							PathPrefix: "/alertmanager",
+							ServiceDiscoveryConfigs: discovery.Configs{
+								&dns.SDConfig{
+									Names:           []string{"_http._tcp.alertmanager.default.svc.cluster.local"},
+									RefreshInterval: 60,
+									Type:            "SRV",
+									Port:            0,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with a dns+ URL and a static URL",
+			cfg: &Config{
+				AlertManagerConfig: ruler_config.AlertManagerConfig{
+					AlertmanagerURL:             "dns+https://alertmanager.default.svc.cluster.local:9093/alertmanager,http://alertmanager-0.default.svc.cluster.local/alertmanager",
+					AlertmanagerRefreshInterval: time.Duration(60),
+				},
+			},
+			ncfg: &config.Config{
+				AlertingConfig: config.AlertingConfig{
+					AlertmanagerConfigs: []*config.AlertmanagerConfig{
+						{
+							APIVersion: "v1",
+							Scheme:     "https",
+							PathPrefix: "/alertmanager",
+							ServiceDiscoveryConfigs: discovery.Configs{
+								&dns.SDConfig{
+									Names:           []string{"alertmanager.default.svc.cluster.local"},
+									RefreshInterval: 60,
+									Type:            "A",
+									Port:            9093,
+								},
+							},
+						},
+						{
+							APIVersion: "v1",
+							Scheme:     "http",
+							PathPrefix: "/alertmanager",
+							ServiceDiscoveryConfigs: discovery.Configs{
+								discovery.StaticConfig{
+									{
+										Targets: []model.LabelSet{{"__address__": "alertmanager-0.default.svc.cluster.local"}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "with a dnssrv+ URL and an invalid host",
+			cfg: &Config{
+				AlertManagerConfig: ruler_config.AlertManagerConfig{
+					AlertmanagerURL: "dnssrv+http://alertmanager.default.svc.cluster.local/alertmanager",
+				},
+			},
+			err: fmt.Errorf("when alertmanager-discovery is on, host name must be of the form _portname._tcp.service.fqdn (is \"alertmanager.default.svc.cluster.local\")"),
+		},
+		{
+			name: "with a dns+ URL and no port",
+			cfg: &Config{
+				AlertManagerConfig: ruler_config.AlertManagerConfig{
+					AlertmanagerURL: "dns+http://alertmanager.default.svc.cluster.local/alertmanager",
+				},
+			},
+			err: fmt.Errorf("when using dns+ alertmanager discovery, a port must be given (is \"alertmanager.default.svc.cluster.local\")"),
+		},
 		{
 			name: "with Basic Authentication URL",
 			cfg: &Config{
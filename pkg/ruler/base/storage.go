@@ -40,6 +40,8 @@ type RuleStoreConfig struct {
 	Swift openstack.SwiftConfig     `yaml:"swift"`
 	Local local.Config              `yaml:"local"`
 
+	MaxRuleGroupVersions int `yaml:"max_rule_group_versions"`
+
 	mock rulestore.RuleStore `yaml:"-"`
 }
 
@@ -52,6 +54,7 @@ func (cfg *RuleStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.Local.RegisterFlagsWithPrefix("ruler.storage.", f)
 	cfg.BOS.RegisterFlagsWithPrefix("ruler.storage.", f)
 	f.StringVar(&cfg.Type, "ruler.storage.type", "", "Method to use for backend rule storage (configdb, azure, gcs, s3, swift, local)")
+	f.IntVar(&cfg.MaxRuleGroupVersions, "ruler.storage.max-rule-group-versions", 0, "Number of versions to keep of each rule group, deleted ones included, so that they can be restored through the ruler API. Only object storage backends keep versions. The versions are deleted along with their namespace. 0 disables the versions.")
 }
 
 // Validate config and returns error on failure
@@ -65,6 +68,9 @@ func (cfg *RuleStoreConfig) Validate() error {
 	if err := cfg.S3.Validate(); err != nil {
 		return errors.Wrap(err, "invalid S3 Storage config")
 	}
+	if cfg.MaxRuleGroupVersions < 0 {
+		return errors.New("invalid max rule group versions, must not be negative")
+	}
 	return nil
 }
 
@@ -94,7 +100,7 @@ func NewLegacyRuleStore(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetr
 	case "gcs":
 		client, err = gcp.NewGCSObjectClient(context.Background(), cfg.GCS, hedgeCfg)
 	case "s3":
-		client, err = aws.NewS3ObjectClient(cfg.S3, hedgeCfg)
+		client, err = aws.NewS3ObjectClient(cfg.S3, hedgeCfg, nil)
 	case "bos":
 		client, err = baidubce.NewBOSObjectStorage(&cfg.BOS)
 	case "swift":
@@ -109,6 +115,9 @@ func NewLegacyRuleStore(cfg RuleStoreConfig, hedgeCfg hedging.Config, clientMetr
 		return nil, err
 	}
 
+	if cfg.MaxRuleGroupVersions > 0 {
+		return objectclient.NewVersionedRuleStore(client, loadRulesConcurrency, cfg.MaxRuleGroupVersions, logger), nil
+	}
 	return objectclient.NewRuleStore(client, loadRulesConcurrency, logger), nil
 }
 
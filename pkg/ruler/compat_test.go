@@ -305,9 +305,12 @@ func TestNonMetricQuery(t *testing.T) {
 	require.Nil(t, err)
 
 	engine := logql.NewEngine(logql.EngineOpts{}, &FakeQuerier{}, overrides, log.Logger)
-	queryFunc := engineQueryFunc(engine, overrides, fakeChecker{}, "fake")
+	eval, err := NewLocalEvaluator(engine)
+	require.NoError(t, err)
 
-	_, err = queryFunc(context.TODO(), `{job="nginx"}`, time.Now())
+	queryFn := queryFunc(eval, overrides, fakeChecker{}, "fake")
+
+	_, err = queryFn(context.TODO(), `{job="nginx"}`, time.Now())
 	require.Error(t, err, "rule result is not a vector or scalar")
 }
 
@@ -22,6 +22,8 @@ type Config struct {
 
 	WALCleaner  cleaner.Config    `yaml:"wal_cleaner,omitempty"`
 	RemoteWrite RemoteWriteConfig `yaml:"remote_write,omitempty"`
+
+	Evaluation EvaluationConfig `yaml:"evaluation,omitempty"`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -29,6 +31,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.RemoteWrite.RegisterFlags(f)
 	c.WAL.RegisterFlags(f)
 	c.WALCleaner.RegisterFlags(f)
+	c.Evaluation.RegisterFlags(f)
 
 	// TODO(owen-d, 3.0.0): remove deprecated experimental prefix in Cortex if they'll accept it.
 	f.BoolVar(&c.Config.EnableAPI, "ruler.enable-api", true, "Enable the ruler api")
@@ -44,6 +47,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid ruler remote-write config: %w", err)
 	}
 
+	if err := c.Evaluation.Validate(); err != nil {
+		return fmt.Errorf("invalid ruler evaluation config: %w", err)
+	}
+
 	return nil
 }
 
@@ -52,6 +59,7 @@ type RemoteWriteConfig struct {
 	Clients             map[string]config.RemoteWriteConfig `yaml:"clients,omitempty"`
 	Enabled             bool                                `yaml:"enabled"`
 	ConfigRefreshPeriod time.Duration                       `yaml:"config_refresh_period"`
+	AllowTenantClients  bool                                `yaml:"allow_tenant_clients"`
 }
 
 func (c *RemoteWriteConfig) Validate() error {
@@ -106,6 +114,7 @@ func (c *RemoteWriteConfig) Clone() (*RemoteWriteConfig, error) {
 func (c *RemoteWriteConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&c.Enabled, "ruler.remote-write.enabled", false, "Remote-write recording rule samples to Prometheus-compatible remote-write receiver.")
 	f.DurationVar(&c.ConfigRefreshPeriod, "ruler.remote-write.config-refresh-period", 10*time.Second, "Minimum period to wait between refreshing remote-write reconfigurations. This should be greater than or equivalent to -limits.per-user-override-period.")
+	f.BoolVar(&c.AllowTenantClients, "ruler.remote-write.allow-tenant-clients", false, "Allow the tenants to send their recording rule samples to their own remote-write clients, defined in the ruler_remote_write_config limit with ids which are not ones of the remote-write clients. Otherwise, the ruler_remote_write_config limit only overrides the settings of the remote-write clients.")
 
 	if c.Clients == nil {
 		c.Clients = make(map[string]config.RemoteWriteConfig)
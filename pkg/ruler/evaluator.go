@@ -0,0 +1,76 @@
+package ruler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/grafana/dskit/grpcclient"
+
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+const (
+	EvalModeLocal  = "local"
+	EvalModeRemote = "remote"
+)
+
+// Evaluator is the interface that must be satisfied in order to accept rule evaluations from the Ruler.
+type Evaluator interface {
+	// Eval evaluates the given rule and returns the result.
+	Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error)
+}
+
+type EvaluationConfig struct {
+	Mode string `yaml:"mode,omitempty"`
+
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	MaxJitter     time.Duration `yaml:"max_jitter"`
+
+	QueryFrontend QueryFrontendConfig `yaml:"query_frontend,omitempty"`
+}
+
+func (c *EvaluationConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.Mode, "ruler.evaluation.mode", EvalModeLocal, "The evaluation mode for the ruler. Can be either 'local' or 'remote'. If set to 'local', the ruler will evaluate rules locally. If set to 'remote', the ruler will evaluate rules remotely through the query-frontend.")
+
+	f.IntVar(&c.MaxConcurrent, "ruler.evaluation.max-concurrent", 0, "Maximum number of rule evaluations running at once, across all the tenants. The other evaluations wait for their turn, the tenants taking turns. 0 means no limit.")
+	f.DurationVar(&c.MaxJitter, "ruler.evaluation.max-jitter", 0, "Upper bound of the delay of the evaluations of each rule group after their scheduled time, to spread the evaluations of the groups having the same interval. The delay of a group is always the same, and the evaluation timestamp is unchanged. It should be smaller than the shortest evaluation interval. 0 means no delay.")
+
+	c.QueryFrontend.RegisterFlags(f)
+}
+
+func (c *EvaluationConfig) Validate() error {
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("max concurrent evaluations must not be negative")
+	}
+	if c.MaxJitter < 0 {
+		return fmt.Errorf("max evaluation jitter must not be negative")
+	}
+
+	switch c.Mode {
+	case EvalModeLocal:
+		return nil
+	case EvalModeRemote:
+		if c.QueryFrontend.Address == "" {
+			return fmt.Errorf("remote evaluation mode requires a query-frontend address")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown evaluation mode %q", c.Mode)
+	}
+}
+
+type QueryFrontendConfig struct {
+	// The address of the remote querier to connect to.
+	// See https://github.com/grpc/grpc/blob/master/doc/naming.md.
+	Address string `yaml:"address"`
+
+	GRPCClientConfig grpcclient.Config `yaml:"grpc_client_config"`
+}
+
+func (c *QueryFrontendConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&c.Address, "ruler.evaluation.query-frontend.address", "", "GRPC listen address of the query-frontend(s). Must be a DNS address (prefixed with dns:///) to enable client side load balancing.")
+
+	c.GRPCClientConfig.RegisterFlagsWithPrefix("ruler.evaluation.query-frontend", f)
+}
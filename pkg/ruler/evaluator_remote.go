@@ -0,0 +1,132 @@
+package ruler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logqlmodel"
+)
+
+const instantQueryPath = "/loki/api/v1/query"
+
+// RemoteEvaluator evaluates rules by sending them to the query-frontend over its HTTP-over-gRPC API,
+// so that rule queries benefit from the frontend's splitting, sharding and caching.
+type RemoteEvaluator struct {
+	client httpgrpc.HTTPClient
+	logger log.Logger
+}
+
+func NewRemoteEvaluator(client httpgrpc.HTTPClient, logger log.Logger) (*RemoteEvaluator, error) {
+	if client == nil {
+		return nil, fmt.Errorf("given client is nil")
+	}
+
+	return &RemoteEvaluator{client: client, logger: logger}, nil
+}
+
+// DialQueryFrontend creates a client to the query-frontend's HTTP-over-gRPC API.
+func DialQueryFrontend(cfg QueryFrontendConfig) (httpgrpc.HTTPClient, error) {
+	opts, err := cfg.GRPCClientConfig.DialOption([]grpc.UnaryClientInterceptor{middleware.ClientUserHeaderInterceptor}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(cfg.Address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial query-frontend %s: %w", cfg.Address, err)
+	}
+
+	return httpgrpc.NewHTTPClient(conn), nil
+}
+
+func (r *RemoteEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*logqlmodel.Result, error) {
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := url.Values{}
+	args.Set("query", qs)
+	args.Set("direction", "forward")
+	args.Set("time", strconv.FormatInt(now.UnixNano(), 10))
+
+	req := &httpgrpc.HTTPRequest{
+		Method: http.MethodGet,
+		Url:    instantQueryPath + "?" + args.Encode(),
+		Headers: []*httpgrpc.Header{
+			{Key: user.OrgIDHeaderName, Values: []string{orgID}},
+		},
+	}
+
+	resp, err := r.client.Handle(ctx, req)
+	if err != nil {
+		if errResp, ok := httpgrpc.HTTPResponseFromError(err); ok {
+			resp = errResp
+		} else {
+			level.Warn(r.logger).Log("msg", "failed to evaluate rule remotely", "query", qs, "err", err)
+			return nil, fmt.Errorf("rule evaluation failed: %w", err)
+		}
+	}
+
+	if resp.Code/100 != 2 {
+		return nil, fmt.Errorf("unsuccessful/unexpected response - status code %d: %s", resp.Code, resp.Body)
+	}
+
+	return decodeRemoteResponse(resp.Body)
+}
+
+func decodeRemoteResponse(body []byte) (*logqlmodel.Result, error) {
+	var resp loghttp.QueryResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode query-frontend response: %w", err)
+	}
+
+	if resp.Status != loghttp.QueryStatusSuccess {
+		return nil, fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+
+	switch v := resp.Data.Result.(type) {
+	case loghttp.Vector:
+		vec := make(promql.Vector, 0, len(v))
+		for _, s := range v {
+			vec = append(vec, promql.Sample{
+				Point:  promql.Point{T: int64(s.Timestamp), V: float64(s.Value)},
+				Metric: metricToLabels(s.Metric),
+			})
+		}
+		return &logqlmodel.Result{Data: vec, Statistics: resp.Data.Statistics}, nil
+	case loghttp.Scalar:
+		return &logqlmodel.Result{
+			Data:       promql.Scalar{T: int64(v.Timestamp), V: float64(v.Value)},
+			Statistics: resp.Data.Statistics,
+		}, nil
+	case loghttp.Streams:
+		return &logqlmodel.Result{Data: logqlmodel.Streams(v.ToProto()), Statistics: resp.Data.Statistics}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", resp.Data.ResultType)
+	}
+}
+
+func metricToLabels(m model.Metric) labels.Labels {
+	b := labels.NewBuilder(nil)
+	for k, v := range m {
+		b.Set(string(k), string(v))
+	}
+	return b.Labels(nil)
+}
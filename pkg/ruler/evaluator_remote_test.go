@@ -0,0 +1,104 @@
+package ruler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/pkg/util/log"
+)
+
+type mockClient struct {
+	handleFn func(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error)
+}
+
+func (m mockClient) Handle(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+	return m.handleFn(ctx, in, opts...)
+}
+
+func TestRemoteEvalQueryRequest(t *testing.T) {
+	now := time.Unix(1672531200, 0)
+	cli := mockClient{
+		handleFn: func(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+			require.Equal(t, http.MethodGet, in.Method)
+
+			u, err := url.Parse(in.Url)
+			require.NoError(t, err)
+			require.Equal(t, instantQueryPath, u.Path)
+			require.Equal(t, `sum(rate({job="nginx"}[1m]))`, u.Query().Get("query"))
+			require.Equal(t, "1672531200000000000", u.Query().Get("time"))
+
+			require.Len(t, in.Headers, 1)
+			require.Equal(t, user.OrgIDHeaderName, in.Headers[0].Key)
+			require.Equal(t, []string{"test"}, in.Headers[0].Values)
+
+			return &httpgrpc.HTTPResponse{
+				Code: http.StatusOK,
+				Body: []byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"nginx","cluster":"us"},"value":[1672531200,"1.5"]}]}}`),
+			}, nil
+		},
+	}
+
+	ev, err := NewRemoteEvaluator(cli, log.Logger)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	res, err := ev.Eval(ctx, `sum(rate({job="nginx"}[1m]))`, now)
+	require.NoError(t, err)
+
+	require.Equal(t, promql.Vector{
+		promql.Sample{
+			Point:  promql.Point{T: now.UnixMilli(), V: 1.5},
+			Metric: labels.FromStrings("cluster", "us", "job", "nginx"),
+		},
+	}, res.Data)
+}
+
+func TestRemoteEvalScalarResponse(t *testing.T) {
+	cli := mockClient{
+		handleFn: func(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+			return &httpgrpc.HTTPResponse{
+				Code: http.StatusOK,
+				Body: []byte(`{"status":"success","data":{"resultType":"scalar","result":[1672531200,"42"]}}`),
+			}, nil
+		},
+	}
+
+	ev, err := NewRemoteEvaluator(cli, log.Logger)
+	require.NoError(t, err)
+
+	res, err := ev.Eval(user.InjectOrgID(context.Background(), "test"), `vector(42)`, time.Unix(1672531200, 0))
+	require.NoError(t, err)
+	require.Equal(t, promql.Scalar{T: 1672531200000, V: 42}, res.Data)
+}
+
+func TestRemoteEvalErrorResponse(t *testing.T) {
+	cli := mockClient{
+		handleFn: func(ctx context.Context, in *httpgrpc.HTTPRequest, opts ...grpc.CallOption) (*httpgrpc.HTTPResponse, error) {
+			return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "too many outstanding requests")
+		},
+	}
+
+	ev, err := NewRemoteEvaluator(cli, log.Logger)
+	require.NoError(t, err)
+
+	_, err = ev.Eval(user.InjectOrgID(context.Background(), "test"), `vector(1)`, time.Now())
+	require.ErrorContains(t, err, "status code 429")
+}
+
+func TestRemoteEvalMissingOrgID(t *testing.T) {
+	ev, err := NewRemoteEvaluator(mockClient{}, log.Logger)
+	require.NoError(t, err)
+
+	_, err = ev.Eval(context.Background(), `vector(1)`, time.Now())
+	require.Error(t, err)
+}
@@ -190,21 +190,20 @@ func (r *walRegistry) getTenantConfig(tenant string) (instance.Config, error) {
 		return instance.Config{}, err
 	}
 
-	// TODO(dannyk): implement multiple RW configs
 	conf.RemoteWrite = []*config.RemoteWriteConfig{}
 	if rwCfg.Enabled {
-		for id := range r.config.RemoteWrite.Clients {
+		for id := range rwCfg.Clients {
 			clt := rwCfg.Clients[id]
-			if rwCfg.Clients[id].Headers == nil {
-				clt.Headers = make(map[string]string)
-			}
 
-			// ensure that no variation of the X-Scope-OrgId header can be added, which might trick authentication
-			for k := range clt.Headers {
-				if strings.ToLower(user.OrgIDHeaderName) == strings.ToLower(strings.TrimSpace(k)) {
-					delete(clt.Headers, k)
+			// copy the headers, which may be shared with the limits of the tenant, and ensure that no variation
+			// of the X-Scope-OrgId header can be added, which might trick authentication
+			headers := make(map[string]string, len(clt.Headers)+1)
+			for k, v := range clt.Headers {
+				if strings.ToLower(user.OrgIDHeaderName) != strings.ToLower(strings.TrimSpace(k)) {
+					headers[k] = v
 				}
 			}
+			clt.Headers = headers
 
 			// always inject the X-Scope-OrgId header for multi-tenant metrics backends
 			clt.Headers[user.OrgIDHeaderName] = tenant
@@ -244,7 +243,7 @@ func (r *walRegistry) getTenantRemoteWriteConfig(tenant string, base RemoteWrite
 			if err != nil {
 				return nil, fmt.Errorf("error parsing given remote-write URL: %w", err)
 			}
-			clt.URL = &promConfig.URL{u}
+			clt.URL = &promConfig.URL{URL: u}
 		}
 		if v := r.overrides.RulerRemoteWriteTimeout(tenant); v > 0 {
 			clt.RemoteTimeout = model.Duration(v)
@@ -325,6 +324,34 @@ func (r *walRegistry) getTenantRemoteWriteConfig(tenant string, base RemoteWrite
 		overrides.Clients[id] = clt
 	}
 
+	if !base.AllowTenantClients {
+		return overrides, nil
+	}
+
+	// the tenant can also send its samples to its own remote-write clients, the ones which are not configured for
+	// the ruler, starting from the Prometheus defaults
+	for id, v := range r.overrides.RulerRemoteWriteConfigs(tenant) {
+		if _, ok := overrides.Clients[id]; ok {
+			continue
+		}
+		if v.URL == nil {
+			return nil, fmt.Errorf("remote-write client %q of the tenant has no URL", id)
+		}
+
+		clt := config.DefaultRemoteWriteConfig
+		if err := mergo.Merge(&clt, v, mergo.WithOverride); err != nil {
+			return nil, fmt.Errorf("failed to apply remote write clients configs: %w", err)
+		}
+		clt.Name = fmt.Sprintf("%s-rw-%s", tenant, id)
+		clt.SendExemplars = false
+		clt.MetadataConfig = config.MetadataConfig{Send: false}
+
+		if overrides.Clients == nil {
+			overrides.Clients = make(map[string]config.RemoteWriteConfig)
+		}
+		overrides.Clients[id] = clt
+	}
+
 	return overrides, nil
 }
 
@@ -34,6 +34,8 @@ const nilRelabelsTenant = "nil-relabels"
 const emptySliceRelabelsTenant = "empty-slice-relabels"
 const sigV4ConfigTenant = "sigv4"
 const multiRemoteWriteTenant = "multi-remote-write-tenant"
+const tenantRemoteWriteTenant = "tenant-remote-write-tenant"
+const noURLRemoteWriteTenant = "no-url-remote-write-tenant"
 const sigV4GlobalRegion = "us-east-1"
 const sigV4TenantRegion = "us-east-2"
 
@@ -301,6 +303,22 @@ func newFakeLimits() fakeLimits {
 					},
 				},
 			},
+			tenantRemoteWriteTenant: {
+				RulerRemoteWriteConfig: map[string]config.RemoteWriteConfig{
+					"tenant-remote": {
+						URL:         &promConfig.URL{URL: newRemoteURL2},
+						QueueConfig: config.QueueConfig{Capacity: 800},
+						Headers:     map[string]string{"X-Foo": "bar", user.OrgIDHeaderName: "overridden"},
+					},
+				},
+			},
+			noURLRemoteWriteTenant: {
+				RulerRemoteWriteConfig: map[string]config.RemoteWriteConfig{
+					"tenant-remote": {
+						QueueConfig: config.QueueConfig{Capacity: 800},
+					},
+				},
+			},
 		},
 	}
 }
@@ -797,6 +815,50 @@ func TestRelabelConfigOverridesEmptySliceWriteRelabels(t *testing.T) {
 	assert.Len(t, tenantCfg.RemoteWrite[0].WriteRelabelConfigs, 0)
 }
 
+func TestTenantRemoteWriteClients(t *testing.T) {
+	limits := newFakeLimits()
+
+	// the clients of the tenant are ignored unless they are allowed
+	reg := setupRegistry(t, cfg, limits)
+	tenantCfg, err := reg.getTenantConfig(tenantRemoteWriteTenant)
+	require.NoError(t, err)
+	require.Len(t, tenantCfg.RemoteWrite, 2)
+	_, err = reg.getTenantConfig(noURLRemoteWriteTenant)
+	require.NoError(t, err)
+
+	allowCfg := cfg
+	allowCfg.RemoteWrite.AllowTenantClients = true
+	reg = setupRegistry(t, allowCfg, limits)
+
+	tenantCfg, err = reg.getTenantConfig(tenantRemoteWriteTenant)
+	require.NoError(t, err)
+
+	// the tenant's own client is added to the ones of the ruler
+	require.Len(t, tenantCfg.RemoteWrite, 3)
+	var clt *config.RemoteWriteConfig
+	for _, rw := range tenantCfg.RemoteWrite {
+		if rw.Name == fmt.Sprintf("%s-rw-tenant-remote", tenantRemoteWriteTenant) {
+			clt = rw
+		}
+	}
+	require.NotNil(t, clt)
+
+	assert.Equal(t, newRemoteURL2, clt.URL.URL)
+	assert.Equal(t, 800, clt.QueueConfig.Capacity)
+	// the settings which are not overridden are the Prometheus defaults
+	assert.Equal(t, config.DefaultQueueConfig.MaxShards, clt.QueueConfig.MaxShards)
+	assert.Equal(t, config.DefaultRemoteWriteConfig.RemoteTimeout, clt.RemoteTimeout)
+	assert.False(t, clt.SendExemplars)
+	assert.False(t, clt.MetadataConfig.Send)
+	assert.Equal(t, map[string]string{"X-Foo": "bar", user.OrgIDHeaderName: tenantRemoteWriteTenant}, clt.Headers)
+
+	// the limits of the tenant are left untouched
+	assert.Equal(t, "overridden", limits.limits[tenantRemoteWriteTenant].RulerRemoteWriteConfig["tenant-remote"].Headers[user.OrgIDHeaderName])
+
+	_, err = reg.getTenantConfig(noURLRemoteWriteTenant)
+	require.EqualError(t, err, `remote-write client "tenant-remote" of the tenant has no URL`)
+}
+
 func TestRelabelConfigOverridesWithErrors(t *testing.T) {
 	reg := setupRegistry(t, backCompatCfg, newFakeLimitsBackwardCompat())
 
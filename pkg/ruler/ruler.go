@@ -6,12 +6,11 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/config"
 
-	"github.com/grafana/loki/pkg/logql"
 	ruler "github.com/grafana/loki/pkg/ruler/base"
 	"github.com/grafana/loki/pkg/ruler/rulestore"
 )
 
-func NewRuler(cfg Config, engine *logql.Engine, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, limits RulesLimits) (*ruler.Ruler, error) {
+func NewRuler(cfg Config, evaluator Evaluator, reg prometheus.Registerer, logger log.Logger, ruleStore rulestore.RuleStore, limits RulesLimits) (*ruler.Ruler, error) {
 	// For backward compatibility, client and clients are defined in the remote_write config.
 	// When both are present, an error is thrown.
 	if len(cfg.RemoteWrite.Clients) > 0 && cfg.RemoteWrite.Client != nil {
@@ -28,7 +27,7 @@ func NewRuler(cfg Config, engine *logql.Engine, reg prometheus.Registerer, logge
 
 	mgr, err := ruler.NewDefaultMultiTenantManager(
 		cfg.Config,
-		MultiTenantRuleManager(cfg, engine, limits, logger, reg),
+		MultiTenantRuleManager(cfg, evaluator, limits, logger, reg),
 		reg,
 		logger,
 		limits,
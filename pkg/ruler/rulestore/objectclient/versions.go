@@ -0,0 +1,214 @@
+package objectclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/ruler/rulespb"
+	"github.com/grafana/loki/pkg/ruler/rulestore"
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+)
+
+// Object Rule Versions Storage Schema
+// =======================
+// Object Name: "rules_versions/<user_id>/<base64 URL Encoded: namespace>/<base64 URL Encoded: group_name>/<version>"
+// Storage Format: Encoded RuleGroupDesc
+//
+// The version is the time the rule group was set, in nanoseconds since the epoch, zero-padded so that the versions
+// of a rule group are listed in the order they were set. The versions are outside of the rules prefix, so that they
+// are not listed as rule groups. The versions of a deleted rule group are kept, but the ones of a deleted namespace or
+// tenant are deleted along with it.
+
+const (
+	versionsPrefix = "rules_versions" + delim
+	versionLength  = 20
+)
+
+// VersionedRuleStore is a RuleStore which keeps the last versions of each rule group, deleted ones included.
+type VersionedRuleStore struct {
+	*RuleStore
+	maxVersions int
+
+	now func() time.Time
+}
+
+// NewVersionedRuleStore returns a new VersionedRuleStore keeping up to maxVersions versions of each rule group.
+func NewVersionedRuleStore(client client.ObjectClient, loadConcurrency, maxVersions int, logger log.Logger) *VersionedRuleStore {
+	return &VersionedRuleStore{
+		RuleStore:   NewRuleStore(client, loadConcurrency, logger),
+		maxVersions: maxVersions,
+		now:         time.Now,
+	}
+}
+
+// SetRuleGroup sets provided rule group and stores it as its most recent version
+func (o *VersionedRuleStore) SetRuleGroup(ctx context.Context, userID string, namespace string, group *rulespb.RuleGroupDesc) error {
+	data, err := proto.Marshal(group)
+	if err != nil {
+		return err
+	}
+
+	// the version is stored first, so that the current rule group always has a version
+	versionKey := generateVersionObjectKey(userID, namespace, group.Name, formatVersion(o.now()))
+	if err := o.client.PutObject(ctx, versionKey, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	objectKey := generateRuleObjectKey(userID, namespace, group.Name)
+	if err := o.client.PutObject(ctx, objectKey, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	// the rule group is set even if the oldest versions can't be deleted, they will be the next time
+	if err := o.pruneVersions(ctx, userID, namespace, group.Name); err != nil {
+		level.Warn(o.logger).Log("msg", "unable to delete the oldest versions of rule group", "user", userID, "namespace", namespace, "group", group.Name, "err", err)
+	}
+	return nil
+}
+
+// ListRuleGroupVersions returns the stored versions of a rule group, from the oldest to the most recent one.
+func (o *VersionedRuleStore) ListRuleGroupVersions(ctx context.Context, userID, namespace, group string) ([]rulestore.RuleGroupVersion, error) {
+	prefix := generateVersionObjectKey(userID, namespace, group, "")
+	objects, _, err := o.client.List(ctx, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []rulestore.RuleGroupVersion
+	for _, obj := range objects {
+		version := strings.TrimPrefix(obj.Key, prefix)
+		created, ok := parseVersion(version)
+		if !ok {
+			continue
+		}
+		versions = append(versions, rulestore.RuleGroupVersion{Version: version, Created: created})
+	}
+
+	if len(versions) == 0 {
+		return nil, rulestore.ErrGroupNotFound
+	}
+	return versions, nil
+}
+
+// GetRuleGroupVersion returns a version of a rule group.
+func (o *VersionedRuleStore) GetRuleGroupVersion(ctx context.Context, userID, namespace, group, version string) (*rulespb.RuleGroupDesc, error) {
+	if _, ok := parseVersion(version); !ok {
+		return nil, rulestore.ErrGroupVersionNotFound
+	}
+
+	objectKey := generateVersionObjectKey(userID, namespace, group, version)
+	reader, _, err := o.client.GetObject(ctx, objectKey)
+	if err != nil {
+		if o.client.IsObjectNotFoundErr(err) {
+			return nil, rulestore.ErrGroupVersionNotFound
+		}
+		return nil, errors.Wrapf(err, "failed to get rule group version %s", objectKey)
+	}
+	defer func() { _ = reader.Close() }()
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rule group version %s", objectKey)
+	}
+
+	rg := &rulespb.RuleGroupDesc{}
+	if err := proto.Unmarshal(buf, rg); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal rule group version %s", objectKey)
+	}
+	return rg, nil
+}
+
+// DeleteNamespace deletes all the rule groups in the specified namespace along with their versions, or all the rule
+// groups and versions of the user if namespace is empty.
+func (o *VersionedRuleStore) DeleteNamespace(ctx context.Context, userID, namespace string) error {
+	err := o.RuleStore.DeleteNamespace(ctx, userID, namespace)
+	if err != nil && err != rulestore.ErrGroupNamespaceNotFound {
+		return err
+	}
+
+	// the namespace may only have the versions of its deleted rule groups left
+	versionObjects, _, listErr := o.client.List(ctx, generateVersionObjectKey(userID, namespace, "", ""), "")
+	if listErr != nil {
+		return listErr
+	}
+	if len(versionObjects) == 0 {
+		return err
+	}
+
+	for _, obj := range versionObjects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		level.Debug(o.logger).Log("msg", "deleting rule group version", "user", userID, "namespace", namespace, "key", obj.Key)
+		if err := o.client.DeleteObject(ctx, obj.Key); err != nil && !o.client.IsObjectNotFoundErr(err) {
+			level.Error(o.logger).Log("msg", "unable to delete rule group version from namespace", "err", err, "namespace", namespace, "key", obj.Key)
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneVersions deletes the oldest versions of a rule group, above the maximum number of versions.
+func (o *VersionedRuleStore) pruneVersions(ctx context.Context, userID, namespace, group string) error {
+	versions, err := o.ListRuleGroupVersions(ctx, userID, namespace, group)
+	if err != nil {
+		return err
+	}
+
+	for len(versions) > o.maxVersions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		objectKey := generateVersionObjectKey(userID, namespace, group, versions[0].Version)
+		level.Debug(o.logger).Log("msg", "deleting rule group version", "user", userID, "key", objectKey)
+		if err := o.client.DeleteObject(ctx, objectKey); err != nil && !o.client.IsObjectNotFoundErr(err) {
+			return err
+		}
+		versions = versions[1:]
+	}
+	return nil
+}
+
+// generateVersionObjectKey returns the key of a version, or the prefix of the versions of the user or namespace if
+// namespace or groupName is empty.
+func generateVersionObjectKey(userID, namespace, groupName, version string) string {
+	prefix := versionsPrefix + userID + delim
+	if namespace == "" {
+		return prefix
+	}
+
+	prefix += base64.URLEncoding.EncodeToString([]byte(namespace)) + delim
+	if groupName == "" {
+		return prefix
+	}
+
+	return prefix + base64.URLEncoding.EncodeToString([]byte(groupName)) + delim + version
+}
+
+func formatVersion(t time.Time) string {
+	return fmt.Sprintf("%0*d", versionLength, t.UnixNano())
+}
+
+func parseVersion(version string) (time.Time, bool) {
+	if len(version) != versionLength {
+		return time.Time{}, false
+	}
+	ns, err := strconv.ParseInt(version, 10, 64)
+	if err != nil || ns < 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ns).UTC(), true
+}
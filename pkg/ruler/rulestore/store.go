@@ -3,6 +3,7 @@ package rulestore
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/grafana/loki/pkg/ruler/rulespb"
 )
@@ -14,6 +15,8 @@ var (
 	ErrGroupNamespaceNotFound = errors.New("group namespace does not exist")
 	// ErrUserNotFound is returned if the user does not currently exist
 	ErrUserNotFound = errors.New("no rule groups found for user")
+	// ErrGroupVersionNotFound is returned if a version of a rule group does not exist
+	ErrGroupVersionNotFound = errors.New("group version does not exist")
 )
 
 // RuleStore is used to store and retrieve rules.
@@ -45,3 +48,21 @@ type RuleStore interface {
 	// If namespace is empty, deletes all rule groups for user.
 	DeleteNamespace(ctx context.Context, userID, namespace string) error
 }
+
+// RuleGroupVersion identifies a stored version of a rule group.
+type RuleGroupVersion struct {
+	Version string    `yaml:"version"`
+	Created time.Time `yaml:"created"`
+}
+
+// VersionedRuleStore is a RuleStore which keeps the last versions of the rule groups, including the deleted ones,
+// so that they can be restored.
+type VersionedRuleStore interface {
+	RuleStore
+
+	// ListRuleGroupVersions returns the stored versions of a rule group, from the oldest to the most recent one.
+	ListRuleGroupVersions(ctx context.Context, userID, namespace, group string) ([]RuleGroupVersion, error)
+
+	// GetRuleGroupVersion returns a version of a rule group.
+	GetRuleGroupVersion(ctx context.Context, userID, namespace, group, version string) (*rulespb.RuleGroupDesc, error)
+}
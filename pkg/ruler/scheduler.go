@@ -0,0 +1,191 @@
+package ruler
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/rules"
+)
+
+// evaluationScheduler spreads the rule evaluations of all the tenants over time: the rule groups start their
+// evaluations after a jitter of their own, and at most maxConcurrent evaluations run at once, the tenants taking
+// turns to run theirs.
+type evaluationScheduler struct {
+	maxConcurrent int
+	maxJitter     time.Duration
+
+	mtx     sync.Mutex
+	running int
+	// waiting holds the evaluations waiting for their turn per tenant, and tenants the round-robin order of the
+	// tenants having waiting evaluations.
+	waiting map[string][]chan struct{}
+	tenants []string
+
+	queueDuration prometheus.Histogram
+}
+
+func newEvaluationScheduler(cfg EvaluationConfig, reg prometheus.Registerer) *evaluationScheduler {
+	return &evaluationScheduler{
+		maxConcurrent: cfg.MaxConcurrent,
+		maxJitter:     cfg.MaxJitter,
+		waiting:       map[string][]chan struct{}{},
+		queueDuration: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki",
+			Name:      "ruler_evaluation_queue_duration_seconds",
+			Help:      "Time the rule evaluations waited for their turn, because of the maximum number of concurrent evaluations.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}),
+	}
+}
+
+// jitter returns the delay of the evaluations of a rule group after their scheduled time. It is the same for all the
+// evaluations of a group, so that they stay evenly spaced.
+func (s *evaluationScheduler) jitter(tenant, group string) time.Duration {
+	if s.maxJitter <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(tenant))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(group))
+	return time.Duration(h.Sum64() % uint64(s.maxJitter))
+}
+
+// acquire waits for the turn of an evaluation of the tenant. The evaluation must call release once done.
+func (s *evaluationScheduler) acquire(ctx context.Context, tenant string) error {
+	if s.maxConcurrent <= 0 {
+		return nil
+	}
+
+	s.mtx.Lock()
+	if s.running < s.maxConcurrent {
+		s.running++
+		s.mtx.Unlock()
+		return nil
+	}
+	turn := make(chan struct{})
+	if len(s.waiting[tenant]) == 0 {
+		s.tenants = append(s.tenants, tenant)
+	}
+	s.waiting[tenant] = append(s.waiting[tenant], turn)
+	s.mtx.Unlock()
+
+	start := time.Now()
+	defer func() { s.queueDuration.Observe(time.Since(start).Seconds()) }()
+
+	select {
+	case <-turn:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mtx.Lock()
+	queue := s.waiting[tenant]
+	for i, c := range queue {
+		if c == turn {
+			s.waiting[tenant] = append(queue[:i:i], queue[i+1:]...)
+			if len(s.waiting[tenant]) == 0 {
+				s.removeTenant(tenant)
+			}
+			s.mtx.Unlock()
+			return ctx.Err()
+		}
+	}
+	s.mtx.Unlock()
+
+	// the turn was given in the meantime, pass it on
+	s.release()
+	return ctx.Err()
+}
+
+// release ends an evaluation, giving its turn to the next tenant with waiting evaluations.
+func (s *evaluationScheduler) release() {
+	if s.maxConcurrent <= 0 {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if len(s.tenants) == 0 {
+		s.running--
+		return
+	}
+	tenant := s.tenants[0]
+	queue := s.waiting[tenant]
+	turn := queue[0]
+	s.tenants = s.tenants[1:]
+	if len(queue) > 1 {
+		s.waiting[tenant] = queue[1:]
+		s.tenants = append(s.tenants, tenant)
+	} else {
+		delete(s.waiting, tenant)
+	}
+	close(turn)
+}
+
+func (s *evaluationScheduler) removeTenant(tenant string) {
+	delete(s.waiting, tenant)
+	for i, t := range s.tenants {
+		if t == tenant {
+			s.tenants = append(s.tenants[:i:i], s.tenants[i+1:]...)
+			return
+		}
+	}
+}
+
+// newEvaluationLatency returns the latency of the evaluations of the rule groups of a tenant, from their scheduled
+// time to the end of their rules. It is registered in the registry of the tenant, which the ruler exports.
+func newEvaluationLatency(reg prometheus.Registerer) *prometheus.GaugeVec {
+	return promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "loki",
+		Name:      "ruler_rule_group_last_evaluation_latency_seconds",
+		Help:      "The time between the scheduled evaluation of the rule group and the end of its last evaluation.",
+	}, []string{"rule_group"})
+}
+
+// scheduledQueryFunc runs the queries of the rules of the tenant according to the scheduler.
+func scheduledQueryFunc(queryFn rules.QueryFunc, scheduler *evaluationScheduler, latency *prometheus.GaugeVec, tenant string) rules.QueryFunc {
+	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		group, ok := ruleGroupFromContext(ctx)
+		if ok {
+			// the rules of a group are evaluated in sequence with the same timestamp, so only the first one waits
+			if wait := time.Until(t.Add(scheduler.jitter(tenant, group))); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				}
+			}
+		}
+
+		if err := scheduler.acquire(ctx, tenant); err != nil {
+			return nil, err
+		}
+		defer scheduler.release()
+
+		res, err := queryFn(ctx, qs, t)
+		if ok {
+			latency.WithLabelValues(group).Set(time.Since(t).Seconds())
+		}
+		return res, err
+	}
+}
+
+// ruleGroupFromContext returns the key of the rule group being evaluated, which the rules manager adds to the
+// context of the evaluations.
+func ruleGroupFromContext(ctx context.Context) (string, bool) {
+	origin, _ := ctx.Value(promql.QueryOrigin{}).(map[string]interface{})
+	group, ok := origin["ruleGroup"].(map[string]string)
+	if !ok {
+		return "", false
+	}
+	return rules.GroupKey(group["file"], group["name"]), true
+}
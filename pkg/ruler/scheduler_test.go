@@ -0,0 +1,97 @@
+package ruler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/require"
+)
+
+// queued returns the number of evaluations waiting for their turn.
+func (s *evaluationScheduler) queued() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	n := 0
+	for _, q := range s.waiting {
+		n += len(q)
+	}
+	return n
+}
+
+func TestEvaluationScheduler_TenantsTakeTurns(t *testing.T) {
+	s := newEvaluationScheduler(EvaluationConfig{MaxConcurrent: 1}, prometheus.NewRegistry())
+	require.NoError(t, s.acquire(context.Background(), "a"))
+
+	order := make(chan string, 3)
+	for i, tenant := range []string{"a", "a", "b"} {
+		tenant := tenant
+		go func() {
+			require.NoError(t, s.acquire(context.Background(), tenant))
+			order <- tenant
+		}()
+		require.Eventually(t, func() bool { return s.queued() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	for _, expected := range []string{"a", "b", "a"} {
+		s.release()
+		require.Equal(t, expected, <-order)
+	}
+	s.release()
+	require.Zero(t, s.running)
+	require.Empty(t, s.tenants)
+}
+
+func TestEvaluationScheduler_CanceledWhileWaiting(t *testing.T) {
+	s := newEvaluationScheduler(EvaluationConfig{MaxConcurrent: 1}, prometheus.NewRegistry())
+	require.NoError(t, s.acquire(context.Background(), "a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, s.acquire(ctx, "b"), context.DeadlineExceeded)
+	require.Zero(t, s.queued())
+	require.Empty(t, s.tenants)
+
+	s.release()
+	require.NoError(t, s.acquire(context.Background(), "b"))
+}
+
+func TestScheduledQueryFunc(t *testing.T) {
+	s := newEvaluationScheduler(EvaluationConfig{MaxJitter: 50 * time.Millisecond}, prometheus.NewRegistry())
+	reg := prometheus.NewRegistry()
+	latency := newEvaluationLatency(reg)
+
+	var started time.Time
+	queryFn := scheduledQueryFunc(func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
+		started = time.Now()
+		return promql.Vector{}, nil
+	}, s, latency, "tenant")
+
+	group := rules.GroupKey("file", "group")
+	jitter := s.jitter("tenant", group)
+	require.Less(t, jitter, 50*time.Millisecond)
+	require.Equal(t, jitter, s.jitter("tenant", group))
+	require.NotEqual(t, jitter, s.jitter("other-tenant", group))
+
+	// the evaluations of the rule groups wait for the jitter of the group
+	ctx := promql.NewOriginContext(context.Background(), map[string]interface{}{
+		"ruleGroup": map[string]string{"file": "file", "name": "group"},
+	})
+	ts := time.Now()
+	_, err := queryFn(ctx, "sum(rate({foo=\"bar\"}[1m]))", ts)
+	require.NoError(t, err)
+	require.False(t, started.Before(ts.Add(jitter)))
+	require.Equal(t, 1, testutil.CollectAndCount(latency))
+	require.GreaterOrEqual(t, testutil.ToFloat64(latency.WithLabelValues(group)), jitter.Seconds())
+
+	// the other queries, as the ones restoring the state of the alerts, don't
+	ts = time.Now().Add(time.Hour)
+	_, err = queryFn(context.Background(), "sum(rate({foo=\"bar\"}[1m]))", ts)
+	require.NoError(t, err)
+	require.True(t, started.Before(ts))
+	require.Equal(t, 1, testutil.CollectAndCount(latency))
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/loki/pkg/scheduler/queue"
+)
+
+// Priority classes of the queries, the interactive queries being dequeued first.
+const (
+	priorityInteractive = iota
+	priorityBatch
+
+	priorityInteractiveValue = "interactive"
+)
+
+// QueryPriorityConfig configures the priority of the interactive queries over the batch queries of a tenant.
+type QueryPriorityConfig struct {
+	Enabled           bool          `yaml:"enabled"`
+	Header            string        `yaml:"header"`
+	InteractiveWeight int           `yaml:"interactive_weight"`
+	BatchWeight       int           `yaml:"batch_weight"`
+	StarvationTimeout time.Duration `yaml:"starvation_timeout"`
+}
+
+func (cfg *QueryPriorityConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Dequeue the interactive queries of a tenant before its batch queries, such as the rule evaluations.")
+	f.StringVar(&cfg.Header, prefix+".header", "X-Query-Priority", "HTTP header identifying the interactive queries, which have the value 'interactive'. The queries without this header value are batch queries. The query frontend forwards this header to the scheduler with the split and sharded queries.")
+	f.IntVar(&cfg.InteractiveWeight, prefix+".interactive-weight", 10, "Number of interactive queries dequeued for every batch-weight batch queries, when a tenant has both kinds of queries queued.")
+	f.IntVar(&cfg.BatchWeight, prefix+".batch-weight", 1, "Number of batch queries dequeued for every interactive-weight interactive queries, when a tenant has both kinds of queries queued.")
+	f.DurationVar(&cfg.StarvationTimeout, prefix+".starvation-timeout", 30*time.Second, "Queries queued for longer than this are dequeued first regardless of their priority. 0 disables the starvation protection.")
+}
+
+func (cfg *QueryPriorityConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Header == "" {
+		return errors.New("the query priority header must be set when the query priority is enabled")
+	}
+	if cfg.InteractiveWeight < 1 || cfg.BatchWeight < 1 {
+		return errors.New("the query priority weights must be greater than 0")
+	}
+	return nil
+}
+
+func (cfg *QueryPriorityConfig) queueConfig() queue.PriorityConfig {
+	if !cfg.Enabled {
+		return queue.PriorityConfig{}
+	}
+	return queue.PriorityConfig{
+		Weights:           []int{priorityInteractive: cfg.InteractiveWeight, priorityBatch: cfg.BatchWeight},
+		StarvationTimeout: cfg.StarvationTimeout,
+	}
+}
+
+// priority returns the priority class of the request.
+func (cfg *QueryPriorityConfig) priority(req *httpgrpc.HTTPRequest) int {
+	if !cfg.Enabled || req == nil {
+		return priorityInteractive
+	}
+	header := http.CanonicalHeaderKey(cfg.Header)
+	for _, h := range req.Headers {
+		if http.CanonicalHeaderKey(h.Key) != header {
+			continue
+		}
+		for _, v := range h.Values {
+			if strings.EqualFold(strings.TrimSpace(v), priorityInteractiveValue) {
+				return priorityInteractive
+			}
+		}
+	}
+	return priorityBatch
+}
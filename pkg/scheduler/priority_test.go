@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/httpgrpc/server"
+
+	"github.com/grafana/loki/pkg/querier/queryrange"
+	"github.com/grafana/loki/pkg/util/httpreq"
+)
+
+func TestQueryPriority_FromFrontend(t *testing.T) {
+	cfg := QueryPriorityConfig{Enabled: true, Header: "X-Query-Priority", InteractiveWeight: 10, BatchWeight: 1}
+
+	for _, tc := range []struct {
+		desc     string
+		header   string
+		expected int
+	}{
+		{desc: "interactive", header: "interactive", expected: priorityInteractive},
+		{desc: "case insensitive", header: " Interactive ", expected: priorityInteractive},
+		{desc: "batch", header: "batch", expected: priorityBatch},
+		{desc: "no header", expected: priorityBatch},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			// The frontend decodes the request and encodes the split and sharded requests it sends to the scheduler.
+			var sent *httpgrpc.HTTPRequest
+			frontend := httpreq.ExtractQueryPriorityMiddleware(cfg.Header).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req, err := queryrange.LokiCodec.DecodeRequest(r.Context(), r, nil)
+				require.NoError(t, err)
+				encoded, err := queryrange.LokiCodec.EncodeRequest(r.Context(), req)
+				require.NoError(t, err)
+				sent, err = server.HTTPRequest(encoded)
+				require.NoError(t, err)
+			}))
+
+			r := httptest.NewRequest("GET", `/loki/api/v1/query_range?query={app="foo"}&start=1&end=2`, nil)
+			if tc.header != "" {
+				r.Header.Set(cfg.Header, tc.header)
+			}
+			frontend.ServeHTTP(httptest.NewRecorder(), r)
+
+			require.NotNil(t, sent)
+			require.Equal(t, tc.expected, cfg.priority(sent))
+		})
+	}
+}
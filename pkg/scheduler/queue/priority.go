@@ -0,0 +1,125 @@
+package queue
+
+import (
+	"time"
+)
+
+// PriorityConfig configures the priority classes of the requests of a tenant.
+type PriorityConfig struct {
+	// Weights of the priority classes, the first class having the highest priority. When requests of several
+	// classes are queued, each class gets a share of the dequeued requests proportional to its weight, the
+	// higher priority classes being dequeued first. No weights means a single class.
+	Weights []int
+
+	// StarvationTimeout is how long a request can be queued before it's dequeued regardless of its priority.
+	// 0 disables the starvation protection.
+	StarvationTimeout time.Duration
+}
+
+func (cfg PriorityConfig) classes() int {
+	if len(cfg.Weights) == 0 {
+		return 1
+	}
+	return len(cfg.Weights)
+}
+
+type queuedRequest struct {
+	request    Request
+	enqueuedAt time.Time
+}
+
+// priorityQueue holds the requests of a tenant in one FIFO per priority class.
+type priorityQueue struct {
+	cfg PriorityConfig
+
+	classes [][]queuedRequest
+	// Number of requests each class can still dequeue before the credits of all classes are reset to their weights.
+	credits []int
+	length  int
+}
+
+func newPriorityQueue(cfg PriorityConfig) *priorityQueue {
+	return &priorityQueue{
+		cfg:     cfg,
+		classes: make([][]queuedRequest, cfg.classes()),
+		credits: make([]int, cfg.classes()),
+	}
+}
+
+func (q *priorityQueue) len() int {
+	return q.length
+}
+
+// enqueue adds the request to its priority class. Out of range priorities are clamped to the existing classes.
+func (q *priorityQueue) enqueue(req Request, priority int, now time.Time) {
+	if priority < 0 {
+		priority = 0
+	}
+	if priority >= len(q.classes) {
+		priority = len(q.classes) - 1
+	}
+	q.classes[priority] = append(q.classes[priority], queuedRequest{request: req, enqueuedAt: now})
+	q.length++
+}
+
+// dequeue returns the next request, or nil if the queue is empty.
+func (q *priorityQueue) dequeue(now time.Time) Request {
+	if q.length == 0 {
+		return nil
+	}
+
+	class := q.starvingClass(now)
+	if class < 0 {
+		class = q.nextClass()
+		if class < 0 {
+			for i := range q.credits {
+				q.credits[i] = q.weight(i)
+			}
+			class = q.nextClass()
+		}
+		q.credits[class]--
+	}
+
+	req := q.classes[class][0]
+	q.classes[class][0] = queuedRequest{}
+	q.classes[class] = q.classes[class][1:]
+	q.length--
+	return req.request
+}
+
+// nextClass returns the highest priority class with queued requests and credits left, or -1 if there is none.
+func (q *priorityQueue) nextClass() int {
+	for i, requests := range q.classes {
+		if len(requests) > 0 && q.credits[i] > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// starvingClass returns the class whose oldest request has been queued for longer than the starvation timeout,
+// the oldest first, or -1 if no request is starving.
+func (q *priorityQueue) starvingClass(now time.Time) int {
+	if q.cfg.StarvationTimeout <= 0 {
+		return -1
+	}
+
+	class := -1
+	var oldest time.Time
+	for i, requests := range q.classes {
+		if len(requests) == 0 || now.Sub(requests[0].enqueuedAt) < q.cfg.StarvationTimeout {
+			continue
+		}
+		if class < 0 || requests[0].enqueuedAt.Before(oldest) {
+			class, oldest = i, requests[0].enqueuedAt
+		}
+	}
+	return class
+}
+
+func (q *priorityQueue) weight(class int) int {
+	if len(q.cfg.Weights) == 0 || q.cfg.Weights[class] < 1 {
+		return 1
+	}
+	return q.cfg.Weights[class]
+}
@@ -0,0 +1,51 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityQueue_Weights(t *testing.T) {
+	now := time.Now()
+	q := newPriorityQueue(PriorityConfig{Weights: []int{2, 1}})
+
+	for i := 0; i < 3; i++ {
+		q.enqueue("low", 1, now)
+		q.enqueue("high", 0, now)
+	}
+	// Out of range priorities are clamped.
+	q.enqueue("low", 5, now)
+	require.Equal(t, 7, q.len())
+
+	var dequeued []Request
+	for q.len() > 0 {
+		dequeued = append(dequeued, q.dequeue(now))
+	}
+	require.Equal(t, []Request{"high", "high", "low", "high", "low", "low", "low"}, dequeued)
+	require.Nil(t, q.dequeue(now))
+}
+
+func TestPriorityQueue_StarvationTimeout(t *testing.T) {
+	now := time.Now()
+	q := newPriorityQueue(PriorityConfig{Weights: []int{10, 1}, StarvationTimeout: time.Minute})
+
+	q.enqueue("low", 1, now.Add(-2*time.Minute))
+	q.enqueue("high-1", 0, now)
+	q.enqueue("high-2", 0, now)
+
+	require.Equal(t, "low", q.dequeue(now))
+	require.Equal(t, "high-1", q.dequeue(now))
+	require.Equal(t, "high-2", q.dequeue(now))
+}
+
+func TestPriorityQueue_SingleClass(t *testing.T) {
+	now := time.Now()
+	q := newPriorityQueue(PriorityConfig{})
+
+	q.enqueue("1", 1, now)
+	q.enqueue("2", 0, now)
+	require.Equal(t, "1", q.dequeue(now))
+	require.Equal(t, "2", q.dequeue(now))
+}
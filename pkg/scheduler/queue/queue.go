@@ -60,9 +60,9 @@ type RequestQueue struct {
 	discardedRequests *prometheus.CounterVec // Per user.
 }
 
-func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
+func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, priorities PriorityConfig, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
 	q := &RequestQueue{
-		queues:                  newUserQueues(maxOutstandingPerTenant, forgetDelay),
+		queues:                  newUserQueues(maxOutstandingPerTenant, forgetDelay, priorities),
 		connectedQuerierWorkers: atomic.NewInt32(0),
 		queueLength:             queueLength,
 		discardedRequests:       discardedRequests,
@@ -76,10 +76,10 @@ func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, que
 
 // EnqueueRequest puts the request into the queue. MaxQueries is user-specific value that specifies how many queriers can
 // this user use (zero or negative = all queriers). It is passed to each EnqueueRequest, because it can change
-// between calls.
+// between calls. Priority is the index of the priority class of the request, 0 being the highest priority.
 //
 // If request is successfully enqueued, successFn is called with the lock held, before any querier can receive the request.
-func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers int, successFn func()) error {
+func (q *RequestQueue) EnqueueRequest(userID string, req Request, priority int, maxQueriers int, successFn func()) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
@@ -93,19 +93,19 @@ func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers in
 		return errors.New("no queue found")
 	}
 
-	select {
-	case queue <- req:
-		q.queueLength.WithLabelValues(userID).Inc()
-		q.cond.Broadcast()
-		// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
-		if successFn != nil {
-			successFn()
-		}
-		return nil
-	default:
+	if queue.len() >= q.queues.maxUserQueueSize {
 		q.discardedRequests.WithLabelValues(userID).Inc()
 		return ErrTooManyRequests
 	}
+
+	queue.enqueue(req, priority, time.Now())
+	q.queueLength.WithLabelValues(userID).Inc()
+	q.cond.Broadcast()
+	// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
+	if successFn != nil {
+		successFn()
+	}
+	return nil
 }
 
 // GetNextRequestForQuerier find next user queue and takes the next request off of it. Will block if there are no requests.
@@ -141,8 +141,8 @@ FindQueue:
 
 		// Pick next request from the queue.
 		for {
-			request := <-queue
-			if len(queue) == 0 {
+			request := queue.dequeue(time.Now())
+			if queue.len() == 0 {
 				q.queues.deleteQueue(userID)
 			}
 
@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/chunk/fetcher"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/util/limiter"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -676,10 +677,11 @@ outer:
 
 func fetchLazyChunks(ctx context.Context, s config.SchemaConfig, chunks []*LazyChunk) error {
 	var (
-		totalChunks int64
-		start       = time.Now()
-		stats       = stats.FromContext(ctx)
-		logger      = util_log.WithContext(ctx, util_log.Logger)
+		totalChunks  int64
+		start        = time.Now()
+		stats        = stats.FromContext(ctx)
+		queryLimiter = limiter.QueryLimiterFromContextWithFallback(ctx)
+		logger       = util_log.WithContext(ctx, util_log.Logger)
 	)
 	defer func() {
 		stats.AddChunksDownloadTime(time.Since(start))
@@ -696,6 +698,9 @@ func fetchLazyChunks(ctx context.Context, s config.SchemaConfig, chunks []*LazyC
 	if len(chksByFetcher) == 0 {
 		return nil
 	}
+	if err := queryLimiter.AddChunks(int(totalChunks)); err != nil {
+		return err
+	}
 	level.Debug(logger).Log("msg", "loading lazy chunks", "chunks", totalChunks)
 
 	errChan := make(chan error)
@@ -728,11 +733,16 @@ func fetchLazyChunks(ctx context.Context, s config.SchemaConfig, chunks []*LazyC
 
 			}
 			// assign fetched chunk by key as FetchChunks doesn't guarantee the order.
+			var totalBytes int
 			for _, chk := range chks {
 				index[s.ExternalKey(chk.ChunkRef)].Chunk = chk
+				if chk.Data != nil {
+					totalBytes += chk.Data.Size()
+				}
 			}
+			stats.AddChunksDownloadedBytes(int64(totalBytes))
 
-			errChan <- nil
+			errChan <- queryLimiter.AddChunkBytes(totalBytes)
 		}(f, chunks)
 	}
 
@@ -18,8 +18,10 @@ import (
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
 	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/logqlmodel"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/util/limiter"
 )
 
 var NilMetrics = NewChunkMetrics(nil, 0)
@@ -1631,6 +1633,59 @@ func Test_IsInvalidChunkError(t *testing.T) {
 	}
 }
 
+func Test_FetchLazyChunksLimits(t *testing.T) {
+	store := newMockChunkStore([]*logproto.Stream{
+		{
+			Labels:  `{foo="bar"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "1"}, {Timestamp: time.Unix(0, 2), Line: "2"}},
+		},
+		{
+			Labels:  `{foo="baz"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Unix(0, 1), Line: "3"}},
+		},
+	})
+	var expectedBytes int
+	for _, c := range store.chunks {
+		expectedBytes += c.Data.Size()
+	}
+
+	lazyChunks := func() []*LazyChunk {
+		refs, fetchers, err := store.GetChunkRefs(context.Background(), "fake", 0, 10)
+		require.NoError(t, err)
+		chunks := make([]*LazyChunk, 0, len(refs[0]))
+		for _, ref := range refs[0] {
+			chunks = append(chunks, &LazyChunk{Chunk: ref, Fetcher: fetchers[0]})
+		}
+		return chunks
+	}
+
+	for _, tc := range []struct {
+		name                 string
+		maxBytes, maxChunks  int
+		expectedLimitReached bool
+	}{
+		{name: "no limits"},
+		{name: "under the limits", maxBytes: expectedBytes, maxChunks: 2},
+		{name: "chunks limit", maxChunks: 1, expectedLimitReached: true},
+		{name: "bytes limit", maxBytes: expectedBytes - 1, expectedLimitReached: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			statsCtx, ctx := stats.NewContext(context.Background())
+			ctx = limiter.AddQueryLimiterToContext(ctx, limiter.NewQueryLimiter(0, tc.maxBytes, tc.maxChunks))
+
+			err := fetchLazyChunks(ctx, store.schemas, lazyChunks())
+			if tc.expectedLimitReached {
+				require.ErrorIs(t, err, logqlmodel.ErrLimit)
+				return
+			}
+			require.NoError(t, err)
+			res := statsCtx.Result(0, 0, 0)
+			require.Equal(t, int64(2), res.TotalChunksDownloaded())
+			require.Equal(t, int64(expectedBytes), res.TotalChunksDownloadedBytes())
+		})
+	}
+}
+
 func TestBatchCancel(t *testing.T) {
 	createChunk := func(from time.Time) *LazyChunk {
 		return newLazyChunk(logproto.Stream{
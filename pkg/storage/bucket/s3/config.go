@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/grafana/dskit/flagext"
@@ -27,14 +28,19 @@ const (
 	// SSES3 config type constant to configure S3 server side encryption with AES-256
 	// https://docs.aws.amazon.com/AmazonS3/latest/dev/UsingServerSideEncryption.html
 	SSES3 = "SSE-S3"
+
+	// SSEC config type constant to configure S3 server side encryption with a customer-provided key
+	// https://docs.aws.amazon.com/AmazonS3/latest/userguide/ServerSideEncryptionCustomerKeys.html
+	SSEC = "SSE-C"
 )
 
 var (
 	supportedSignatureVersions     = []string{SignatureVersionV4, SignatureVersionV2}
-	supportedSSETypes              = []string{SSEKMS, SSES3}
+	supportedSSETypes              = []string{SSEKMS, SSES3, SSEC}
 	errUnsupportedSignatureVersion = errors.New("unsupported signature version")
 	errUnsupportedSSEType          = errors.New("unsupported S3 SSE type")
 	errInvalidSSEContext           = errors.New("invalid S3 SSE encryption context")
+	errMissingSSECustomerKey       = errors.New("S3 SSE-C requires a customer key file")
 )
 
 // HTTPConfig stores the http.Transport configuration for the s3 minio client.
@@ -97,6 +103,7 @@ type SSEConfig struct {
 	Type                 string `yaml:"type"`
 	KMSKeyID             string `yaml:"kms_key_id"`
 	KMSEncryptionContext string `yaml:"kms_encryption_context"`
+	CustomerKeyFile      string `yaml:"customer_key_file"`
 }
 
 func (cfg *SSEConfig) RegisterFlags(f *flag.FlagSet) {
@@ -108,6 +115,7 @@ func (cfg *SSEConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.StringVar(&cfg.Type, prefix+"type", "", fmt.Sprintf("Enable AWS Server Side Encryption. Supported values: %s.", strings.Join(supportedSSETypes, ", ")))
 	f.StringVar(&cfg.KMSKeyID, prefix+"kms-key-id", "", "KMS Key ID used to encrypt objects in S3")
 	f.StringVar(&cfg.KMSEncryptionContext, prefix+"kms-encryption-context", "", "KMS Encryption Context used for object encryption. It expects JSON formatted string.")
+	f.StringVar(&cfg.CustomerKeyFile, prefix+"customer-key-file", "", "Path to a file containing the 256-bit customer-provided key used to encrypt and decrypt objects when SSE-C is enabled.")
 }
 
 func (cfg *SSEConfig) Validate() error {
@@ -119,6 +127,10 @@ func (cfg *SSEConfig) Validate() error {
 		return errInvalidSSEContext
 	}
 
+	if cfg.Type == SSEC && cfg.CustomerKeyFile == "" {
+		return errMissingSSECustomerKey
+	}
+
 	return nil
 }
 
@@ -142,6 +154,11 @@ func (cfg *SSEConfig) BuildThanosConfig() (s3.SSEConfig, error) {
 		return s3.SSEConfig{
 			Type: s3.SSES3,
 		}, nil
+	case SSEC:
+		return s3.SSEConfig{
+			Type:          s3.SSEC,
+			EncryptionKey: cfg.CustomerKeyFile,
+		}, nil
 	default:
 		return s3.SSEConfig{}, errUnsupportedSSEType
 	}
@@ -165,11 +182,33 @@ func (cfg *SSEConfig) BuildMinioConfig() (encrypt.ServerSide, error) {
 		return encrypt.NewSSEKMS(cfg.KMSKeyID, encryptionCtx)
 	case SSES3:
 		return encrypt.NewSSE(), nil
+	case SSEC:
+		key, err := ReadSSECustomerKey(cfg.CustomerKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return encrypt.NewSSEC(key)
 	default:
 		return nil, errUnsupportedSSEType
 	}
 }
 
+// ReadSSECustomerKey reads the 256-bit SSE-C customer key from the given file.
+func ReadSSECustomerKey(path string) ([]byte, error) {
+	if path == "" {
+		return nil, errMissingSSECustomerKey
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read S3 SSE-C customer key")
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid S3 SSE-C customer key: expected 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
 func parseKMSEncryptionContext(data string) (map[string]string, error) {
 	if data == "" {
 		return nil, nil
@@ -146,6 +146,14 @@ func TestSSEConfig_Validate(t *testing.T) {
 			},
 			expected: errInvalidSSEContext,
 		},
+		"should fail on SSE-C without customer key file": {
+			setup: func() *SSEConfig {
+				return &SSEConfig{
+					Type: SSEC,
+				}
+			},
+			expected: errMissingSSECustomerKey,
+		},
 		"should pass on valid SSE KMS encryption context": {
 			setup: func() *SSEConfig {
 				return &SSEConfig{
@@ -22,6 +22,9 @@ type TenantConfigProvider interface {
 
 	// S3SSEKMSEncryptionContext returns the per-tenant S3 KMS-SSE key id or an empty string if not set.
 	S3SSEKMSEncryptionContext(userID string) string
+
+	// S3SSECustomerKeyFile returns the path of the per-tenant S3 SSE-C customer key file or an empty string if not set.
+	S3SSECustomerKeyFile(userID string) string
 }
 
 // SSEBucketClient is a wrapper around a objstore.BucketReader that configures the object
@@ -48,12 +51,9 @@ func (b *SSEBucketClient) Close() error {
 
 // Upload the contents of the reader as an object into the bucket.
 func (b *SSEBucketClient) Upload(ctx context.Context, name string, r io.Reader) error {
-	if sse, err := b.getCustomS3SSEConfig(); err != nil {
+	ctx, err := b.contextWithCustomS3SSEConfig(ctx)
+	if err != nil {
 		return err
-	} else if sse != nil {
-		// If the underlying bucket client is not S3 and a custom S3 SSE config has been
-		// provided, the config option will be ignored.
-		ctx = thanos_s3.ContextWithSSEConfig(ctx, sse)
 	}
 
 	return b.bucket.Upload(ctx, name, r)
@@ -69,6 +69,21 @@ func (b *SSEBucketClient) Name() string {
 	return b.bucket.Name()
 }
 
+// contextWithCustomS3SSEConfig returns a context carrying the custom S3 SSE config of the user, if any.
+// If the underlying bucket client is not S3 and a custom S3 SSE config has been
+// provided, the config option will be ignored.
+func (b *SSEBucketClient) contextWithCustomS3SSEConfig(ctx context.Context) (context.Context, error) {
+	sse, err := b.getCustomS3SSEConfig()
+	if err != nil {
+		return nil, err
+	}
+	if sse == nil {
+		return ctx, nil
+	}
+
+	return thanos_s3.ContextWithSSEConfig(ctx, sse), nil
+}
+
 func (b *SSEBucketClient) getCustomS3SSEConfig() (encrypt.ServerSide, error) {
 	if b.cfgProvider == nil {
 		return nil, nil
@@ -84,6 +99,7 @@ func (b *SSEBucketClient) getCustomS3SSEConfig() (encrypt.ServerSide, error) {
 		Type:                 sseType,
 		KMSKeyID:             b.cfgProvider.S3SSEKMSKeyID(b.userID),
 		KMSEncryptionContext: b.cfgProvider.S3SSEKMSEncryptionContext(b.userID),
+		CustomerKeyFile:      b.cfgProvider.S3SSECustomerKeyFile(b.userID),
 	}
 
 	sse, err := cfg.BuildMinioConfig()
@@ -99,13 +115,23 @@ func (b *SSEBucketClient) Iter(ctx context.Context, dir string, f func(string) e
 	return b.bucket.Iter(ctx, dir, f, options...)
 }
 
-// Get implements objstore.Bucket.
+// Get implements objstore.Bucket. The custom S3 SSE config is required to read objects encrypted with SSE-C.
 func (b *SSEBucketClient) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	ctx, err := b.contextWithCustomS3SSEConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return b.bucket.Get(ctx, name)
 }
 
-// GetRange implements objstore.Bucket.
+// GetRange implements objstore.Bucket. The custom S3 SSE config is required to read objects encrypted with SSE-C.
 func (b *SSEBucketClient) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	ctx, err := b.contextWithCustomS3SSEConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return b.bucket.GetRange(ctx, name, off, length)
 }
 
@@ -109,6 +109,7 @@ type mockTenantConfigProvider struct {
 	s3SseType              string
 	s3KmsKeyID             string
 	s3KmsEncryptionContext string
+	s3CustomerKeyFile      string
 }
 
 func (m *mockTenantConfigProvider) S3SSEType(_ string) string {
@@ -122,3 +123,7 @@ func (m *mockTenantConfigProvider) S3SSEKMSKeyID(_ string) string {
 func (m *mockTenantConfigProvider) S3SSEKMSEncryptionContext(_ string) string {
 	return m.s3KmsEncryptionContext
 }
+
+func (m *mockTenantConfigProvider) S3SSECustomerKeyFile(_ string) string {
+	return m.s3CustomerKeyFile
+}
@@ -0,0 +1,156 @@
+// Package bloom implements chunk-level bloom filters of the n-grams contained
+// in the log lines of a chunk. They are stored next to the chunks in the object
+// store and allow queries with line filters to skip chunks which can't match.
+package bloom
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/willf/bloom"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/log"
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+const (
+	// NGramLength is the length in bytes of the n-grams added to the filters.
+	// Strings shorter than that can't be looked up.
+	NGramLength = 3
+
+	// FalsePositiveRate is the false positive rate of a single n-gram lookup.
+	FalsePositiveRate = 0.01
+
+	// KeySuffix is appended to the object key of a chunk to obtain the key of its bloom filter.
+	KeySuffix = ".bloom"
+
+	formatV1 = byte(1)
+)
+
+var (
+	ErrUnsupportedChunk  = errors.New("bloom filters can only be built for Loki chunks")
+	ErrInvalidFormat     = errors.New("invalid bloom filter format")
+	ErrUnsupportedFormat = errors.New("unsupported bloom filter format")
+)
+
+// Key returns the object key of the bloom filter of the chunk stored under the given key.
+func Key(chunkKey string) string {
+	return chunkKey + KeySuffix
+}
+
+// Filter is a bloom filter of all the n-grams of the lines of a chunk.
+type Filter struct {
+	b *bloom.BloomFilter
+}
+
+// MayContain returns false if no line of the chunk contains s.
+// A true result doesn't mean a line does, either because of a false positive
+// or because s is shorter than NGramLength.
+func (f *Filter) MayContain(s string) bool {
+	for i := 0; i+NGramLength <= len(s); i++ {
+		if !f.b.TestString(s[i : i+NGramLength]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MayContainAll returns false if at least one of the strings is contained in no line of the chunk.
+func (f *Filter) MayContainAll(ss []string) bool {
+	for _, s := range ss {
+		if !f.MayContain(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode returns the binary representation of the filter.
+func (f *Filter) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(formatV1)
+	if _, err := f.b.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode reads a filter written by Encode.
+func Decode(b []byte) (*Filter, error) {
+	if len(b) == 0 {
+		return nil, ErrInvalidFormat
+	}
+	if b[0] != formatV1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormat, b[0])
+	}
+	f := &Filter{b: &bloom.BloomFilter{}}
+	if _, err := f.b.ReadFrom(bytes.NewReader(b[1:])); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, err)
+	}
+	return f, nil
+}
+
+// Builder collects the distinct n-grams of lines to size the filter built from them.
+type Builder struct {
+	ngrams map[[NGramLength]byte]struct{}
+}
+
+func NewBuilder() *Builder {
+	return &Builder{ngrams: map[[NGramLength]byte]struct{}{}}
+}
+
+// Add adds all the n-grams of the line.
+func (b *Builder) Add(line string) {
+	var ngram [NGramLength]byte
+	for i := 0; i+NGramLength <= len(line); i++ {
+		copy(ngram[:], line[i:i+NGramLength])
+		b.ngrams[ngram] = struct{}{}
+	}
+}
+
+// Build returns a filter containing all the n-grams added so far.
+func (b *Builder) Build() *Filter {
+	n := uint(len(b.ngrams))
+	if n == 0 {
+		n = 1
+	}
+	f := &Filter{b: bloom.NewWithEstimates(n, FalsePositiveRate)}
+	for ngram := range b.ngrams {
+		f.b.Add(ngram[:])
+	}
+	return f
+}
+
+// FromChunk builds the filter of the lines of a Loki chunk.
+func FromChunk(c chunk.Chunk) (*Filter, error) {
+	facade, ok := c.Data.(*chunkenc.Facade)
+	if !ok || facade.LokiChunk() == nil {
+		return nil, ErrUnsupportedChunk
+	}
+	it, err := facade.LokiChunk().Iterator(
+		context.Background(),
+		time.Unix(0, 0),
+		time.Unix(0, math.MaxInt64),
+		logproto.FORWARD,
+		log.NewNoopPipeline().ForStream(c.Metric),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	b := NewBuilder()
+	for it.Next() {
+		b.Add(it.Entry().Line)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
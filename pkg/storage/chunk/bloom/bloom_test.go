@@ -0,0 +1,76 @@
+package bloom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/chunk"
+)
+
+func TestFilter_MayContain(t *testing.T) {
+	b := NewBuilder()
+	b.Add(`level=error msg="connection refused" traceID=3c0e3dcd33e7`)
+	b.Add(`level=info msg="request served" duration=12ms`)
+	f := b.Build()
+
+	for _, s := range []string{"connection refused", "3c0e3dcd33e7", "served", "level=info", "ms", ""} {
+		require.True(t, f.MayContain(s), s)
+	}
+	for _, s := range []string{"timeout", "4ddf98a2", "LEVEL"} {
+		require.False(t, f.MayContain(s), s)
+	}
+
+	require.True(t, f.MayContainAll([]string{"refused", "served"}))
+	require.False(t, f.MayContainAll([]string{"refused", "timeout"}))
+}
+
+func TestFilter_EncodeDecode(t *testing.T) {
+	b := NewBuilder()
+	b.Add("foo bar buzz")
+	f := b.Build()
+
+	buf, err := f.Encode()
+	require.NoError(t, err)
+
+	decoded, err := Decode(buf)
+	require.NoError(t, err)
+	require.True(t, f.b.Equal(decoded.b))
+	require.True(t, decoded.MayContain("bar"))
+	require.False(t, decoded.MayContain("fizz"))
+
+	_, err = Decode(nil)
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = Decode([]byte{42})
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = Decode(buf[:5])
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestFromChunk(t *testing.T) {
+	lbs := labels.FromStrings(labels.MetricName, "logs", "app", "foo")
+	mc := chunkenc.NewMemChunk(chunkenc.EncSnappy, chunkenc.UnorderedHeadBlockFmt, 256*1024, 0)
+	now := time.Unix(0, 0)
+	for i, line := range []string{"caller=main.go msg=starting", "caller=server.go msg=listening"} {
+		require.NoError(t, mc.Append(&logproto.Entry{Timestamp: now.Add(time.Duration(i)), Line: line}))
+	}
+	require.NoError(t, mc.Close())
+	c := chunk.NewChunk("fake", 1, lbs, chunkenc.NewFacade(mc, 0, 0), model.TimeFromUnixNano(0), model.TimeFromUnixNano(1))
+
+	f, err := FromChunk(c)
+	require.NoError(t, err)
+	require.True(t, f.MayContainAll([]string{"server.go", "listening"}))
+	require.False(t, f.MayContain("stopping"))
+
+	_, err = FromChunk(chunk.Chunk{})
+	require.ErrorIs(t, err, ErrUnsupportedChunk)
+}
+
+func TestKey(t *testing.T) {
+	require.Equal(t, "fake/1c8/17e1815f800:17e1d3c5400:7b.bloom", Key("fake/1c8/17e1815f800:17e1d3c5400:7b"))
+}
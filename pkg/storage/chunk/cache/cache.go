@@ -35,6 +35,7 @@ type Config struct {
 	MemcacheClient MemcachedClientConfig `yaml:"memcached_client"`
 	Redis          RedisConfig           `yaml:"redis"`
 	EmbeddedCache  EmbeddedCacheConfig   `yaml:"embedded_cache"`
+	DiskCache      DiskCacheConfig       `yaml:"disk_cache"`
 	Fifocache      FifoCacheConfig       `yaml:"fifocache"` // deprecated
 
 	// This is to name the cache metrics properly.
@@ -57,6 +58,7 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, description string, f
 	cfg.Redis.RegisterFlagsWithPrefix(prefix, description, f)
 	cfg.Fifocache.RegisterFlagsWithPrefix(prefix, description, f)
 	cfg.EmbeddedCache.RegisterFlagsWithPrefix(prefix, description, f)
+	cfg.DiskCache.RegisterFlagsWithPrefix(prefix, description, f)
 	f.IntVar(&cfg.AsyncCacheWriteBackConcurrency, prefix+"max-async-cache-write-back-concurrency", 16, "The maximum number of concurrent asynchronous writeback cache can occur.")
 	f.IntVar(&cfg.AsyncCacheWriteBackBufferSize, prefix+"max-async-cache-write-back-buffer-size", 500, "The maximum number of enqueued asynchronous writeback cache allowed.")
 	f.DurationVar(&cfg.DefaultValidity, prefix+"default-validity", time.Hour, description+"The default validity of entries for caches unless overridden.")
@@ -66,6 +68,9 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, description string, f
 }
 
 func (cfg *Config) Validate() error {
+	if err := cfg.DiskCache.Validate(); err != nil {
+		return err
+	}
 	return cfg.Fifocache.Validate()
 }
 
@@ -88,9 +93,13 @@ func IsEmbeddedCacheSet(cfg Config) bool {
 	return cfg.EmbeddedCache.Enabled
 }
 
-// IsCacheConfigured determines if memcached, redis, or embedded-cache have been configured
+func IsDiskCacheSet(cfg Config) bool {
+	return cfg.DiskCache.Enabled
+}
+
+// IsCacheConfigured determines if memcached, redis, embedded-cache or disk-cache have been configured
 func IsCacheConfigured(cfg Config) bool {
-	return IsMemcacheSet(cfg) || IsRedisSet(cfg) || IsEmbeddedCacheSet(cfg)
+	return IsMemcacheSet(cfg) || IsRedisSet(cfg) || IsEmbeddedCacheSet(cfg) || IsDiskCacheSet(cfg)
 }
 
 // New creates a new Cache using Config.
@@ -132,6 +141,20 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger, cacheType sta
 		}
 	}
 
+	if IsDiskCacheSet(cfg) {
+		diskcfg := cfg.DiskCache
+		if diskcfg.TTL == 0 && cfg.DefaultValidity != 0 {
+			diskcfg.TTL = cfg.DefaultValidity
+		}
+
+		cacheName := cfg.Prefix + "disk-cache"
+		cache, err := NewDiskCache(cacheName, diskcfg, reg, logger, cacheType)
+		if err != nil {
+			return nil, fmt.Errorf("disk cache setup failed: %w", err)
+		}
+		caches = append(caches, CollectStats(Instrument(cacheName, cache, reg)))
+	}
+
 	if IsMemcacheSet(cfg) && IsRedisSet(cfg) {
 		return nil, errors.New("use of multiple cache storage systems is not supported")
 	}
@@ -141,7 +164,10 @@ func New(cfg Config, reg prometheus.Registerer, logger log.Logger, cacheType sta
 			cfg.Memcache.Expiration = cfg.DefaultValidity
 		}
 
-		client := NewMemcachedClient(cfg.MemcacheClient, cfg.Prefix, reg, logger)
+		client, err := NewMemcachedClient(cfg.MemcacheClient, cfg.Prefix, reg, logger)
+		if err != nil {
+			return nil, fmt.Errorf("memcached client setup failed: %w", err)
+		}
 		cache := NewMemcached(cfg.Memcache, client, cfg.Prefix, reg, logger, cacheType)
 
 		cacheName := cfg.Prefix + "memcache"
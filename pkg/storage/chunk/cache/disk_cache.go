@@ -0,0 +1,314 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const (
+	// diskCacheFilePrefix is the prefix of the files storing the items, the cache never adopts nor removes other files.
+	diskCacheFilePrefix = "item-"
+	diskCacheTempSuffix = ".tmp"
+)
+
+// DiskCacheConfig represents the config of a cache stored in a local directory.
+type DiskCacheConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	Directory string        `yaml:"directory"`
+	MaxSizeMB int64         `yaml:"max_size_mb"`
+	TTL       time.Duration `yaml:"ttl"`
+}
+
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"disk-cache.enabled", false, description+"Whether the disk cache is enabled. The disk cache is checked after the embedded cache and before memcached or redis.")
+	f.StringVar(&cfg.Directory, prefix+"disk-cache.directory", "", description+"Directory in which the cached items are stored, in a subdirectory named after the cache, so that several caches can share it. The items stored by a previous run are kept until they expire.")
+	f.Int64Var(&cfg.MaxSizeMB, prefix+"disk-cache.max-size-mb", 1000, description+"Maximum disk size of the cache in MB.")
+	f.DurationVar(&cfg.TTL, prefix+"disk-cache.ttl", time.Hour, description+"The time to live for items in the cache before they get purged.")
+}
+
+func (cfg *DiskCacheConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Directory == "" {
+		return errors.New("the disk cache directory must be set when the disk cache is enabled")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return errors.New("the disk cache max size must be greater than 0")
+	}
+	return nil
+}
+
+// DiskCache is a FIFO cache which stores each item in its own file of a local directory,
+// so that the cache can be much larger than the memory and survives restarts.
+type DiskCache struct {
+	cacheType stats.CacheType
+	dir       string
+	maxSize   int64
+	ttl       time.Duration
+	logger    log.Logger
+
+	mtx      sync.Mutex
+	size     int64
+	entries  map[string]*list.Element
+	fifo     *list.List
+	tempSeqs int
+
+	entriesCurrent prometheus.Gauge
+	entriesEvicted *prometheus.CounterVec
+	diskBytes      prometheus.Gauge
+}
+
+type diskCacheEntry struct {
+	file    string
+	size    int64
+	updated time.Time
+}
+
+// NewDiskCache returns a disk cache stored in the subdirectory of the name, which indexes the items stored by a
+// previous run.
+func NewDiskCache(name string, cfg DiskCacheConfig, reg prometheus.Registerer, logger log.Logger, cacheType stats.CacheType) (*DiskCache, error) {
+	util_log.WarnExperimentalUse(fmt.Sprintf("Disk cache - %s", name), logger)
+
+	dir := filepath.Join(cfg.Directory, name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "creating the disk cache directory")
+	}
+
+	c := &DiskCache{
+		cacheType: cacheType,
+		dir:       dir,
+		maxSize:   cfg.MaxSizeMB * 1e6,
+		ttl:       cfg.TTL,
+		logger:    logger,
+		entries:   map[string]*list.Element{},
+		fifo:      list.New(),
+
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "entries",
+			Help:        "The total number of entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+		entriesEvicted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "evicted_total",
+			Help:        "The total number of evicted entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, []string{"reason"}),
+		diskBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "querier",
+			Subsystem:   "cache",
+			Name:        "disk_bytes",
+			Help:        "The current size of the disk cache in bytes",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// load indexes the items of the cache directory, oldest first. The files which don't store an item are left untouched.
+func (c *DiskCache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return errors.Wrap(err, "reading the disk cache directory")
+	}
+
+	entries := make([]*diskCacheEntry, 0, len(dirEntries))
+	for _, e := range dirEntries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		file, _, temp := strings.Cut(e.Name(), ".")
+		if !isDiskCacheFile(file) {
+			continue
+		}
+		if temp {
+			if strings.HasSuffix(e.Name(), diskCacheTempSuffix) {
+				// Left behind by a write which didn't complete.
+				c.remove(e.Name())
+			}
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &diskCacheEntry{file: e.Name(), size: info.Size(), updated: info.ModTime()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].updated.Before(entries[j].updated) })
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for _, e := range entries {
+		c.entries[e.file] = c.fifo.PushBack(e)
+		c.size += e.size
+	}
+	c.evict(time.Now())
+	c.updateMetrics()
+	return nil
+}
+
+// Fetch implements Cache.
+func (c *DiskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	now := time.Now()
+	for _, key := range keys {
+		file := diskCacheFile(key)
+
+		c.mtx.Lock()
+		element, ok := c.entries[file]
+		if ok && c.expired(element.Value.(*diskCacheEntry), now) {
+			c.drop(element, expiredReason)
+			c.updateMetrics()
+			ok = false
+		}
+		c.mtx.Unlock()
+
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		// The file can be evicted concurrently, in which case the item is missing.
+		buf, err := os.ReadFile(filepath.Join(c.dir, file))
+		if err != nil {
+			missing = append(missing, key)
+			continue
+		}
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+	return
+}
+
+// Store implements Cache.
+func (c *DiskCache) Store(_ context.Context, keys []string, bufs [][]byte) error {
+	for i := range keys {
+		if err := c.put(keys[i], bufs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DiskCache) put(key string, buf []byte) error {
+	size := int64(len(buf))
+	if size > c.maxSize {
+		return nil
+	}
+	file := diskCacheFile(key)
+
+	c.mtx.Lock()
+	c.tempSeqs++
+	tmp := fmt.Sprintf("%s.%d%s", file, c.tempSeqs, diskCacheTempSuffix)
+	c.mtx.Unlock()
+
+	// Write to a temporary file first, so that readers never see a partially written item.
+	if err := os.WriteFile(filepath.Join(c.dir, tmp), buf, 0o640); err != nil {
+		c.remove(tmp)
+		return errors.Wrap(err, "writing to the disk cache")
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if err := os.Rename(filepath.Join(c.dir, tmp), filepath.Join(c.dir, file)); err != nil {
+		c.remove(tmp)
+		return errors.Wrap(err, "writing to the disk cache")
+	}
+	if element, ok := c.entries[file]; ok {
+		c.size -= element.Value.(*diskCacheEntry).size
+		c.fifo.Remove(element)
+	}
+	c.entries[file] = c.fifo.PushBack(&diskCacheEntry{file: file, size: size, updated: time.Now()})
+	c.size += size
+
+	c.evict(time.Now())
+	c.updateMetrics()
+	return nil
+}
+
+// evict removes the expired items and the oldest items until the cache fits its max size.
+// It must be called with the lock held.
+func (c *DiskCache) evict(now time.Time) {
+	for element := c.fifo.Front(); element != nil; element = c.fifo.Front() {
+		entry := element.Value.(*diskCacheEntry)
+		switch {
+		case c.expired(entry, now):
+			c.drop(element, expiredReason)
+		case c.size > c.maxSize:
+			c.drop(element, fullReason)
+		default:
+			return
+		}
+	}
+}
+
+func (c *DiskCache) expired(entry *diskCacheEntry, now time.Time) bool {
+	return c.ttl > 0 && now.Sub(entry.updated) > c.ttl
+}
+
+// drop removes an item from the cache. It must be called with the lock held.
+func (c *DiskCache) drop(element *list.Element, reason string) {
+	entry := c.fifo.Remove(element).(*diskCacheEntry)
+	delete(c.entries, entry.file)
+	c.size -= entry.size
+	c.remove(entry.file)
+	c.entriesEvicted.WithLabelValues(reason).Inc()
+}
+
+func (c *DiskCache) remove(file string) {
+	if err := os.Remove(filepath.Join(c.dir, file)); err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "failed to remove disk cache file", "file", file, "err", err)
+	}
+}
+
+func (c *DiskCache) updateMetrics() {
+	c.entriesCurrent.Set(float64(len(c.entries)))
+	c.diskBytes.Set(float64(c.size))
+}
+
+// Stop implements Cache. The cached items are kept on disk for the next run.
+func (c *DiskCache) Stop() {}
+
+func (c *DiskCache) GetCacheType() stats.CacheType {
+	return c.cacheType
+}
+
+// diskCacheFile returns the name of the file storing the item of the key, which can contain any character.
+func diskCacheFile(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return diskCacheFilePrefix + hex.EncodeToString(sum[:])
+}
+
+// isDiskCacheFile returns whether a file name has the format of the files storing the items.
+func isDiskCacheFile(file string) bool {
+	if !strings.HasPrefix(file, diskCacheFilePrefix) {
+		return false
+	}
+	sum, err := hex.DecodeString(strings.TrimPrefix(file, diskCacheFilePrefix))
+	return err == nil && len(sum) == sha256.Size
+}
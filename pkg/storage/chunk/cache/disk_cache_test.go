@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1, TTL: time.Hour}
+
+	c, err := NewDiskCache("test", cfg, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	c.maxSize = 10
+
+	require.NoError(t, c.Store(ctx, []string{"a/1", "b/2"}, [][]byte{[]byte("1234"), []byte("5678")}))
+	found, bufs, missing, err := c.Fetch(ctx, []string{"a/1", "b/2", "c/3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/1", "b/2"}, found)
+	require.Equal(t, [][]byte{[]byte("1234"), []byte("5678")}, bufs)
+	require.Equal(t, []string{"c/3"}, missing)
+
+	// Storing over the max size evicts the oldest item.
+	require.NoError(t, c.Store(ctx, []string{"c/3"}, [][]byte{[]byte("9012")}))
+	found, _, missing, err = c.Fetch(ctx, []string{"a/1", "b/2", "c/3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b/2", "c/3"}, found)
+	require.Equal(t, []string{"a/1"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(fullReason)))
+	require.Equal(t, float64(8), testutil.ToFloat64(c.diskBytes))
+
+	// Items bigger than the cache aren't stored.
+	require.NoError(t, c.Store(ctx, []string{"d/4"}, [][]byte{make([]byte, 11)}))
+	_, _, missing, _ = c.Fetch(ctx, []string{"d/4"})
+	require.Equal(t, []string{"d/4"}, missing)
+
+	// The items are kept across restarts.
+	c, err = NewDiskCache("test", cfg, prometheus.NewRegistry(), log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	found, bufs, _, err = c.Fetch(ctx, []string{"b/2", "c/3"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b/2", "c/3"}, found)
+	require.Equal(t, [][]byte{[]byte("5678"), []byte("9012")}, bufs)
+}
+
+func TestDiskCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewDiskCache("test", DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1, TTL: time.Hour}, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Store(ctx, []string{"a"}, [][]byte{[]byte("1")}))
+	c.entries[diskCacheFile("a")].Value.(*diskCacheEntry).updated = time.Now().Add(-2 * time.Hour)
+
+	_, _, missing, err := c.Fetch(ctx, []string{"a"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(expiredReason)))
+	require.Empty(t, c.entries)
+}
+
+func TestDiskCacheDirectory(t *testing.T) {
+	ctx := context.Background()
+	cfg := DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1, TTL: time.Hour}
+
+	// The files which aren't cache items are never adopted nor evicted.
+	other := filepath.Join(cfg.Directory, "chunks", "other")
+	require.NoError(t, os.MkdirAll(filepath.Dir(other), 0o750))
+	require.NoError(t, os.WriteFile(other, []byte("other"), 0o640))
+
+	chunks, err := NewDiskCache("chunks", cfg, nil, log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	require.Empty(t, chunks.entries)
+	chunks.maxSize = 1
+	require.NoError(t, chunks.Store(ctx, []string{"a"}, [][]byte{[]byte("1")}))
+	require.NoError(t, chunks.Store(ctx, []string{"b"}, [][]byte{[]byte("2")}))
+	require.FileExists(t, other)
+
+	// Each cache has its own directory, so that the caches sharing a directory don't evict each other's items.
+	results, err := NewDiskCache("results", cfg, prometheus.NewRegistry(), log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	require.Empty(t, results.entries)
+	require.NoError(t, results.Store(ctx, []string{"a"}, [][]byte{[]byte("3")}))
+
+	found, bufs, _, err := chunks.Fetch(ctx, []string{"b"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"b"}, found)
+	require.Equal(t, [][]byte{[]byte("2")}, bufs)
+}
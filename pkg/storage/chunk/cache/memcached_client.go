@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"fmt"
 	"net"
@@ -13,6 +14,7 @@ import (
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	dstls "github.com/grafana/dskit/crypto/tls"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -41,8 +43,10 @@ type memcachedClient struct {
 	*memcache.Client
 	serverList serverSelector
 
-	hostname string
-	service  string
+	hostname      string
+	service       string
+	autoDiscovery bool
+	tlsConfig     *tls.Config
 
 	addresses []string
 	provider  *dns.Provider
@@ -76,6 +80,10 @@ type MemcachedClientConfig struct {
 	CBFailures     uint          `yaml:"circuit_breaker_consecutive_failures"`
 	CBTimeout      time.Duration `yaml:"circuit_breaker_timeout"`  // reset error count after this long
 	CBInterval     time.Duration `yaml:"circuit_breaker_interval"` // remain closed for this long after CBFailures errors
+	AutoDiscovery  bool          `yaml:"auto_discovery"`
+
+	TLSEnabled bool               `yaml:"tls_enabled"`
+	TLS        dstls.ClientConfig `yaml:",inline"`
 }
 
 // RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
@@ -91,11 +99,14 @@ func (cfg *MemcachedClientConfig) RegisterFlagsWithPrefix(prefix, description st
 	f.DurationVar(&cfg.CBTimeout, prefix+"memcached.circuit-breaker-timeout", 10*time.Second, description+"Duration circuit-breaker remains open after tripping (if zero then 60 seconds is used).")
 	f.DurationVar(&cfg.CBInterval, prefix+"memcached.circuit-breaker-interval", 10*time.Second, description+"Reset circuit-breaker counts after this long (if zero then never reset).")
 	f.IntVar(&cfg.MaxItemSize, prefix+"memcached.max-item-size", 0, description+"The maximum size of an item stored in memcached. Bigger items are not stored. If set to 0, no maximum size is enforced.")
+	f.BoolVar(&cfg.AutoDiscovery, prefix+"memcached.auto-discovery", false, description+"Discover the memcached servers with the cluster config protocol, as supported by AWS ElastiCache auto discovery. The hostname is then the configuration endpoint of the cluster, with port 11211 if none is given.")
+	f.BoolVar(&cfg.TLSEnabled, prefix+"memcached.tls-enabled", false, description+"Enable connecting to memcached with TLS.")
+	cfg.TLS.RegisterFlagsWithPrefix(prefix+"memcached", f)
 }
 
 // NewMemcachedClient creates a new MemcacheClient that gets its server list
-// from SRV and updates the server list on a regular basis.
-func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Registerer, logger log.Logger) MemcachedClient {
+// from SRV or the cluster configuration endpoint and updates the server list on a regular basis.
+func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Registerer, logger log.Logger) (MemcachedClient, error) {
 	var selector serverSelector
 	if cfg.ConsistentHash {
 		selector = DefaultMemcachedJumpHashSelector()
@@ -112,19 +123,20 @@ func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Reg
 	}, r))
 
 	newClient := &memcachedClient{
-		name:        name,
-		Client:      client,
-		serverList:  selector,
-		hostname:    cfg.Host,
-		service:     cfg.Service,
-		logger:      logger,
-		provider:    dns.NewProvider(logger, dnsProviderRegisterer, dns.GolangResolverType),
-		cbs:         make(map[string]*gobreaker.CircuitBreaker),
-		cbFailures:  cfg.CBFailures,
-		cbInterval:  cfg.CBInterval,
-		cbTimeout:   cfg.CBTimeout,
-		maxItemSize: cfg.MaxItemSize,
-		quit:        make(chan struct{}),
+		name:          name,
+		Client:        client,
+		serverList:    selector,
+		hostname:      cfg.Host,
+		service:       cfg.Service,
+		autoDiscovery: cfg.AutoDiscovery,
+		logger:        logger,
+		provider:      dns.NewProvider(logger, dnsProviderRegisterer, dns.GolangResolverType),
+		cbs:           make(map[string]*gobreaker.CircuitBreaker),
+		cbFailures:    cfg.CBFailures,
+		cbInterval:    cfg.CBInterval,
+		cbTimeout:     cfg.CBTimeout,
+		maxItemSize:   cfg.MaxItemSize,
+		quit:          make(chan struct{}),
 
 		numServers: promauto.With(r).NewGauge(prometheus.GaugeOpts{
 			Namespace:   "loki",
@@ -140,6 +152,14 @@ func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Reg
 			ConstLabels: prometheus.Labels{"name": name},
 		}),
 	}
+	if cfg.TLSEnabled {
+		tlsConfig, err := cfg.TLS.GetTLSConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "memcached TLS config")
+		}
+		newClient.tlsConfig = tlsConfig
+		newClient.Client.DialTimeout = newClient.dial
+	}
 	if cfg.CBFailures > 0 {
 		newClient.Client.DialTimeout = newClient.dialViaCircuitBreaker
 	}
@@ -156,7 +176,7 @@ func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Reg
 
 	newClient.wait.Add(1)
 	go newClient.updateLoop(cfg.UpdateInterval)
-	return newClient
+	return newClient, nil
 }
 
 func (c *memcachedClient) circuitBreakerStateChange(name string, from gobreaker.State, to gobreaker.State) {
@@ -181,7 +201,7 @@ func (c *memcachedClient) dialViaCircuitBreaker(network, address string, timeout
 	c.Unlock()
 
 	conn, err := cb.Execute(func() (interface{}, error) {
-		return net.DialTimeout(network, address, timeout)
+		return c.dial(network, address, timeout)
 	})
 	if err != nil {
 		return nil, err
@@ -189,6 +209,14 @@ func (c *memcachedClient) dialViaCircuitBreaker(network, address string, timeout
 	return conn.(net.Conn), nil
 }
 
+// dial connects to a memcached server, with TLS if enabled.
+func (c *memcachedClient) dial(network, address string, timeout time.Duration) (net.Conn, error) {
+	if c.tlsConfig == nil {
+		return net.DialTimeout(network, address, timeout)
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, c.tlsConfig)
+}
+
 // Stop the memcache client.
 func (c *memcachedClient) Stop() {
 	close(c.quit)
@@ -239,7 +267,13 @@ func (c *memcachedClient) updateLoop(updateInterval time.Duration) {
 func (c *memcachedClient) updateMemcacheServers() error {
 	var servers []string
 
-	if len(c.addresses) > 0 {
+	if c.autoDiscovery {
+		var err error
+		servers, err = c.discoverClusterServers()
+		if err != nil {
+			return err
+		}
+	} else if len(c.addresses) > 0 {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
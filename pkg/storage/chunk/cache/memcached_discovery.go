@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultMemcachedPort is the port of the configuration endpoint when none is given.
+const defaultMemcachedPort = "11211"
+
+// discoverClusterServers asks the configuration endpoint of the cluster for its nodes,
+// using the cluster config protocol of AWS ElastiCache auto discovery.
+func (c *memcachedClient) discoverClusterServers() ([]string, error) {
+	address := c.hostname
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultMemcachedPort)
+	}
+
+	conn, err := c.dial("tcp", address, 10*time.Second)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to the memcached configuration endpoint %s", address)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(conn, "config get cluster\r\n"); err != nil {
+		return nil, errors.Wrap(err, "sending the memcached cluster config request")
+	}
+	return parseClusterConfig(bufio.NewReader(conn))
+}
+
+// parseClusterConfig parses the response to the config get cluster command, which is:
+//
+//	CONFIG cluster 0 <length>
+//	<version>
+//	<hostname>|<ip>|<port> <hostname>|<ip>|<port> ...
+//
+//	END
+func parseClusterConfig(r *bufio.Reader) ([]string, error) {
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the memcached cluster config")
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 4 || fields[0] != "CONFIG" || fields[1] != "cluster" {
+		return nil, fmt.Errorf("unexpected memcached cluster config response: %q", strings.TrimSpace(header))
+	}
+	length, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid memcached cluster config length: %q", fields[3])
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errors.Wrap(err, "reading the memcached cluster config")
+	}
+	lines := bytes.Split(bytes.TrimSpace(payload), []byte("\n"))
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("unexpected memcached cluster config: %q", payload)
+	}
+
+	var servers []string
+	for _, node := range strings.Fields(string(lines[1])) {
+		parts := strings.Split(node, "|")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid memcached cluster node: %q", node)
+		}
+		host := parts[0]
+		if host == "" {
+			host = parts[1]
+		}
+		servers = append(servers, net.JoinHostPort(host, parts[2]))
+	}
+	return servers, nil
+}
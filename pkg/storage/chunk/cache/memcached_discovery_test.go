@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClusterConfig(t *testing.T) {
+	nodes := "cache-1.example.com|10.0.0.1|11211 |10.0.0.2|11212\n"
+	response := "CONFIG cluster 0 " + strconv.Itoa(len("12\n"+nodes)) + "\r\n12\n" + nodes + "\r\nEND\r\n"
+
+	servers, err := parseClusterConfig(bufio.NewReader(strings.NewReader(response)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"cache-1.example.com:11211", "10.0.0.2:11212"}, servers)
+}
+
+func TestParseClusterConfig_Invalid(t *testing.T) {
+	for _, response := range []string{
+		"ERROR\r\n",
+		"CONFIG cluster 0 abc\r\n",
+		"CONFIG cluster 0 20\r\n12\n",
+		"CONFIG cluster 0 12\r\n12\nhost|11211\n\r\nEND\r\n",
+	} {
+		_, err := parseClusterConfig(bufio.NewReader(strings.NewReader(response)))
+		require.Error(t, err, response)
+	}
+}
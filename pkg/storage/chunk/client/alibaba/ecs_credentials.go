@@ -0,0 +1,88 @@
+package alibaba
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// ecsMetadataCredentialsURL is where the ECS instance metadata service exposes the
+// temporary credentials of the RAM role attached to the instance.
+var ecsMetadataCredentialsURL = "http://100.100.100.200/latest/meta-data/ram/security-credentials/"
+
+// Credentials are refreshed this long before they expire.
+const ecsCredentialsExpiryWindow = 5 * time.Minute
+
+type ecsCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	AccessKeySecret string    `json:"AccessKeySecret"`
+	SecurityToken   string    `json:"SecurityToken"`
+	Expiration      time.Time `json:"Expiration"`
+	Code            string    `json:"Code"`
+}
+
+func (c *ecsCredentials) GetAccessKeyID() string     { return c.AccessKeyID }
+func (c *ecsCredentials) GetAccessKeySecret() string { return c.AccessKeySecret }
+func (c *ecsCredentials) GetSecurityToken() string   { return c.SecurityToken }
+
+// ecsRAMRoleCredentialsProvider provides the temporary credentials of an ECS RAM role,
+// fetching them again from the instance metadata service shortly before they expire.
+type ecsRAMRoleCredentialsProvider struct {
+	role   string
+	client *http.Client
+
+	mtx   sync.Mutex
+	creds *ecsCredentials
+}
+
+func newECSRAMRoleCredentialsProvider(role string) *ecsRAMRoleCredentialsProvider {
+	return &ecsRAMRoleCredentialsProvider{
+		role:   role,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetCredentials implements oss.CredentialsProvider.
+func (p *ecsRAMRoleCredentialsProvider) GetCredentials() oss.Credentials {
+	creds, err := p.GetCredentialsE()
+	if err != nil {
+		return &ecsCredentials{}
+	}
+	return creds
+}
+
+// GetCredentialsE implements oss.CredentialsProviderE, so that the OSS client fails
+// the request when the credentials cannot be fetched.
+func (p *ecsRAMRoleCredentialsProvider) GetCredentialsE() (oss.Credentials, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.creds != nil && time.Until(p.creds.Expiration) > ecsCredentialsExpiryWindow {
+		return p.creds, nil
+	}
+
+	resp, err := p.client.Get(ecsMetadataCredentialsURL + p.role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credentials of ECS RAM role %s: %w", p.role, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch credentials of ECS RAM role %s: unexpected status code %d", p.role, resp.StatusCode)
+	}
+
+	var creds ecsCredentials
+	if err := json.NewDecoder(resp.Body).Decode(&creds); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials of ECS RAM role %s: %w", p.role, err)
+	}
+	if creds.Code != "Success" {
+		return nil, fmt.Errorf("failed to fetch credentials of ECS RAM role %s: %s", p.role, creds.Code)
+	}
+
+	p.creds = &creds
+	return p.creds, nil
+}
@@ -0,0 +1,168 @@
+package alibaba
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/instrument"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+)
+
+// NoSuchKeyErr The resource you requested does not exist.
+// refer to: https://www.alibabacloud.com/help/en/object-storage-service/latest/error-codes
+const NoSuchKeyErr = "NoSuchKey"
+
+var ossRequestDuration = instrument.NewHistogramCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "loki",
+	Name:      "oss_request_duration_seconds",
+	Help:      "Time spent doing OSS requests.",
+	Buckets:   prometheus.ExponentialBuckets(0.005, 4, 6),
+}, []string{"operation", "status_code"}))
+
+func init() {
+	ossRequestDuration.Register()
+}
+
+// OssConfig is config for the OSS Chunk Client.
+type OssConfig struct {
+	Bucket          string         `yaml:"bucket"`
+	Endpoint        string         `yaml:"endpoint"`
+	AccessKeyID     string         `yaml:"access_key_id"`
+	SecretAccessKey flagext.Secret `yaml:"secret_access_key"`
+	ECSRAMRole      string         `yaml:"ecs_ram_role"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *OssConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("", f)
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *OssConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Bucket, prefix+"oss.bucketname", "", "Name of OSS bucket.")
+	f.StringVar(&cfg.Endpoint, prefix+"oss.endpoint", "", "OSS endpoint to connect to, e.g. oss-cn-hangzhou.aliyuncs.com.")
+	f.StringVar(&cfg.AccessKeyID, prefix+"oss.access-key-id", "", "Alibaba Cloud Access Key ID.")
+	f.Var(&cfg.SecretAccessKey, prefix+"oss.secret-access-key", "Alibaba Cloud Secret Access Key.")
+	f.StringVar(&cfg.ECSRAMRole, prefix+"oss.ecs-ram-role", "", "Name of the RAM role attached to the ECS instance, whose temporary credentials are used instead of the access keys.")
+}
+
+type OssObjectClient struct {
+	defaultBucket *oss.Bucket
+}
+
+// NewOssObjectClient makes a new OSS backed ObjectClient.
+func NewOssObjectClient(_ context.Context, cfg OssConfig) (client.ObjectClient, error) {
+	var options []oss.ClientOption
+	if cfg.ECSRAMRole != "" {
+		options = append(options, oss.SetCredentialsProvider(newECSRAMRoleCredentialsProvider(cfg.ECSRAMRole)))
+	}
+
+	ossClient, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.SecretAccessKey.String(), options...)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := ossClient.Bucket(cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &OssObjectClient{
+		defaultBucket: bucket,
+	}, nil
+}
+
+func (s *OssObjectClient) Stop() {}
+
+// GetObject returns a reader and the size for the specified object key from the configured OSS bucket.
+func (s *OssObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	var resp *oss.GetObjectResult
+	err := instrument.CollectedRequest(ctx, "OSS.GetObject", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var requestErr error
+		resp, requestErr = s.defaultBucket.DoGetObject(&oss.GetObjectRequest{ObjectKey: objectKey}, nil)
+		return requestErr
+	})
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to get OSS object [ %s ]", objectKey)
+	}
+
+	length := resp.Response.Headers.Get(oss.HTTPHeaderContentLength)
+	size, err := strconv.ParseInt(length, 10, 64)
+	if err != nil {
+		resp.Response.Body.Close()
+		return nil, 0, errors.Wrapf(err, "invalid content length %q of OSS object [ %s ]", length, objectKey)
+	}
+	return resp.Response.Body, size, nil
+}
+
+// PutObject puts the specified bytes into the configured OSS bucket at the provided key
+func (s *OssObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	return instrument.CollectedRequest(ctx, "OSS.PutObject", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		return s.defaultBucket.PutObject(objectKey, object)
+	})
+}
+
+// List implements chunk.ObjectClient.
+func (s *OssObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
+	var storageObjects []client.StorageObject
+	var commonPrefixes []client.StorageCommonPrefix
+
+	err := instrument.CollectedRequest(ctx, "OSS.List", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		continuationToken := ""
+		for {
+			options := []oss.Option{
+				oss.Prefix(prefix),
+				oss.Delimiter(delimiter),
+			}
+			if continuationToken != "" {
+				options = append(options, oss.ContinuationToken(continuationToken))
+			}
+
+			listObjectResult, err := s.defaultBucket.ListObjectsV2(options...)
+			if err != nil {
+				return err
+			}
+			for _, content := range listObjectResult.Objects {
+				storageObjects = append(storageObjects, client.StorageObject{
+					Key:        content.Key,
+					ModifiedAt: content.LastModified,
+				})
+			}
+			for _, commonPrefix := range listObjectResult.CommonPrefixes {
+				commonPrefixes = append(commonPrefixes, client.StorageCommonPrefix(commonPrefix))
+			}
+			if !listObjectResult.IsTruncated {
+				break
+			}
+			continuationToken = listObjectResult.NextContinuationToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return storageObjects, commonPrefixes, nil
+}
+
+// DeleteObject deletes the specified object key from the configured OSS bucket.
+func (s *OssObjectClient) DeleteObject(ctx context.Context, objectKey string) error {
+	return instrument.CollectedRequest(ctx, "OSS.DeleteObject", ossRequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		return s.defaultBucket.DeleteObject(objectKey)
+	})
+}
+
+// IsObjectNotFoundErr returns true if error means that object is not found. Relevant to GetObject and DeleteObject operations.
+func (s *OssObjectClient) IsObjectNotFoundErr(err error) bool {
+	switch realErr := errors.Cause(err).(type) {
+	case oss.ServiceError:
+		return realErr.StatusCode == http.StatusNotFound || realErr.Code == NoSuchKeyErr
+	default:
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package alibaba
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_ConfigRedactsCredentials(t *testing.T) {
+	underTest := OssConfig{
+		AccessKeyID:     "access key id",
+		SecretAccessKey: flagext.SecretWithValue("secret access key"),
+	}
+
+	output, err := yaml.Marshal(underTest)
+	require.NoError(t, err)
+
+	require.True(t, bytes.Contains(output, []byte("access key id")))
+	require.False(t, bytes.Contains(output, []byte("secret access key")))
+}
+
+func Test_IsObjectNotFoundErr(t *testing.T) {
+	c := &OssObjectClient{}
+
+	require.True(t, c.IsObjectNotFoundErr(oss.ServiceError{StatusCode: http.StatusNotFound, Code: NoSuchKeyErr}))
+	require.True(t, c.IsObjectNotFoundErr(errors.Wrap(oss.ServiceError{StatusCode: http.StatusNotFound}, "failed to get OSS object")))
+	require.False(t, c.IsObjectNotFoundErr(oss.ServiceError{StatusCode: http.StatusForbidden, Code: "AccessDenied"}))
+	require.False(t, c.IsObjectNotFoundErr(fmt.Errorf("connection refused")))
+}
+
+func Test_ECSRAMRoleCredentialsProvider(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/loki-role", r.URL.Path)
+		fmt.Fprintf(w, `{"AccessKeyId":"id-%d","AccessKeySecret":"secret","SecurityToken":"token","Expiration":%q,"Code":"Success"}`,
+			requests, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer srv.Close()
+
+	defer func(url string) { ecsMetadataCredentialsURL = url }(ecsMetadataCredentialsURL)
+	ecsMetadataCredentialsURL = srv.URL + "/"
+
+	p := newECSRAMRoleCredentialsProvider("loki-role")
+	creds, err := p.GetCredentialsE()
+	require.NoError(t, err)
+	require.Equal(t, "id-1", creds.GetAccessKeyID())
+	require.Equal(t, "secret", creds.GetAccessKeySecret())
+	require.Equal(t, "token", creds.GetSecurityToken())
+
+	// Credentials are cached until they are about to expire.
+	creds, err = p.GetCredentialsE()
+	require.NoError(t, err)
+	require.Equal(t, "id-1", creds.GetAccessKeyID())
+	require.Equal(t, 1, requests)
+
+	p.creds.Expiration = time.Now().Add(time.Minute)
+	creds, err = p.GetCredentialsE()
+	require.NoError(t, err)
+	require.Equal(t, "id-2", creds.GetAccessKeyID())
+}
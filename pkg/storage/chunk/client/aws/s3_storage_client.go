@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -24,12 +25,14 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/tenant"
 	"github.com/minio/minio-go/v7/pkg/signer"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	awscommon "github.com/weaveworks/common/aws"
 	"github.com/weaveworks/common/instrument"
 
+	"github.com/grafana/loki/pkg/storage/bucket"
 	bucket_s3 "github.com/grafana/loki/pkg/storage/bucket/s3"
 	"github.com/grafana/loki/pkg/storage/chunk/client"
 	"github.com/grafana/loki/pkg/storage/chunk/client/hedging"
@@ -138,10 +141,16 @@ type S3ObjectClient struct {
 	S3          s3iface.S3API
 	hedgedS3    s3iface.S3API
 	sseConfig   *SSEParsedConfig
+
+	// Per-tenant SSE overrides, applied to the requests whose context carries the tenant ID. Can be nil.
+	tenantSSEConfigs bucket.TenantConfigProvider
+	// Parsed per-tenant SSE configs, keyed by bucket_s3.SSEConfig.
+	parsedTenantSSEConfigs sync.Map
 }
 
-// NewS3ObjectClient makes a new S3-backed ObjectClient.
-func NewS3ObjectClient(cfg S3Config, hedgingCfg hedging.Config) (*S3ObjectClient, error) {
+// NewS3ObjectClient makes a new S3-backed ObjectClient. tenantSSEConfigs provides the per-tenant SSE overrides, it can
+// be nil.
+func NewS3ObjectClient(cfg S3Config, hedgingCfg hedging.Config, tenantSSEConfigs bucket.TenantConfigProvider) (*S3ObjectClient, error) {
 	bucketNames, err := buckets(cfg)
 	if err != nil {
 		return nil, err
@@ -166,6 +175,8 @@ func NewS3ObjectClient(cfg S3Config, hedgingCfg hedging.Config) (*S3ObjectClient
 		hedgedS3:    s3ClientHedging,
 		bucketNames: bucketNames,
 		sseConfig:   sseCfg,
+
+		tenantSSEConfigs: tenantSSEConfigs,
 	}
 	return &client, nil
 }
@@ -185,6 +196,62 @@ func buildSSEParsedConfig(cfg S3Config) (*SSEParsedConfig, error) {
 	return nil, nil
 }
 
+// sseConfigFor returns the SSE config to write objects with, taking into account
+// the overrides of the tenant of the request, if any.
+func (a *S3ObjectClient) sseConfigFor(ctx context.Context) (*SSEParsedConfig, error) {
+	if a.tenantSSEConfigs == nil {
+		return a.sseConfig, nil
+	}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return a.sseConfig, nil
+	}
+
+	// No SSE override if the type override hasn't been provided.
+	sseType := a.tenantSSEConfigs.S3SSEType(userID)
+	if sseType == "" {
+		return a.sseConfig, nil
+	}
+
+	sseCfg := bucket_s3.SSEConfig{
+		Type:                 sseType,
+		KMSKeyID:             a.tenantSSEConfigs.S3SSEKMSKeyID(userID),
+		KMSEncryptionContext: a.tenantSSEConfigs.S3SSEKMSEncryptionContext(userID),
+		CustomerKeyFile:      a.tenantSSEConfigs.S3SSECustomerKeyFile(userID),
+	}
+	if parsed, ok := a.parsedTenantSSEConfigs.Load(sseCfg); ok {
+		return parsed.(*SSEParsedConfig), nil
+	}
+
+	parsed, err := NewSSEParsedConfig(sseCfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to customise S3 SSE config for tenant %s", userID)
+	}
+	a.parsedTenantSSEConfigs.Store(sseCfg, parsed)
+	return parsed, nil
+}
+
+// sseCustomerKeysFor returns the customer-provided keys (SSE-C) an object read for the request may have been written
+// with: the one of the tenant overrides, then the global one. Objects written with SSE-S3 or SSE-KMS are read without
+// key, and S3 doesn't tell which key an SSE-C object was written with unless it's given the right one, so GetObject
+// tries the keys in turn and then no key. This keeps the objects written before or after a change of the tenant
+// overrides readable.
+func (a *S3ObjectClient) sseCustomerKeysFor(ctx context.Context) ([]*SSEParsedConfig, error) {
+	var keys []*SSEParsedConfig
+	tenantCfg, err := a.sseConfigFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tenantCfg != nil && tenantCfg.SSECustomerKey != nil {
+		keys = append(keys, tenantCfg)
+	}
+	if a.sseConfig != nil && a.sseConfig.SSECustomerKey != nil && a.sseConfig != tenantCfg {
+		keys = append(keys, a.sseConfig)
+	}
+	return keys, nil
+}
+
 func v2SignRequestHandler(cfg S3Config) request.NamedHandler {
 	return request.NamedHandler{
 		Name: "v2.SignRequestHandler",
@@ -358,11 +425,37 @@ func (a *S3ObjectClient) bucketFromKey(key string) string {
 
 // GetObject returns a reader and the size for the specified object key from the configured S3 bucket.
 func (a *S3ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
-	var resp *s3.GetObjectOutput
-
 	// Map the key into a bucket
 	bucket := a.bucketFromKey(objectKey)
 
+	keys, err := a.sseCustomerKeysFor(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Objects encrypted with a customer-provided key can only be read with the same key, and the others only without
+	// key: S3 rejects both with a bad request.
+	for _, key := range keys {
+		input := &s3.GetObjectInput{
+			Bucket:               aws.String(bucket),
+			Key:                  aws.String(objectKey),
+			SSECustomerAlgorithm: key.SSECustomerAlgorithm,
+			SSECustomerKey:       key.SSECustomerKey,
+		}
+		body, size, err := a.getObject(ctx, input)
+		if err == nil || !isBadRequestErr(err) {
+			return body, size, err
+		}
+	}
+	return a.getObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(objectKey),
+	})
+}
+
+func (a *S3ObjectClient) getObject(ctx context.Context, input *s3.GetObjectInput) (io.ReadCloser, int64, error) {
+	var resp *s3.GetObjectOutput
+
 	retries := backoff.New(ctx, a.cfg.BackoffConfig)
 	err := ctx.Err()
 	for retries.Ongoing() {
@@ -371,10 +464,7 @@ func (a *S3ObjectClient) GetObject(ctx context.Context, objectKey string) (io.Re
 		}
 		err = instrument.CollectedRequest(ctx, "S3.GetObject", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
 			var requestErr error
-			resp, requestErr = a.hedgedS3.GetObjectWithContext(ctx, &s3.GetObjectInput{
-				Bucket: aws.String(bucket),
-				Key:    aws.String(objectKey),
-			})
+			resp, requestErr = a.hedgedS3.GetObjectWithContext(ctx, input)
 			return requestErr
 		})
 		var size int64
@@ -384,13 +474,27 @@ func (a *S3ObjectClient) GetObject(ctx context.Context, objectKey string) (io.Re
 		if err == nil && resp.Body != nil {
 			return resp.Body, size, nil
 		}
+		if isBadRequestErr(err) {
+			break
+		}
 		retries.Wait()
 	}
 	return nil, 0, errors.Wrap(err, "failed to get s3 object")
 }
 
+// isBadRequestErr returns whether S3 rejected the request as invalid, which retrying doesn't help with.
+func isBadRequestErr(err error) bool {
+	var rerr awserr.RequestFailure
+	return errors.As(err, &rerr) && rerr.StatusCode() == http.StatusBadRequest
+}
+
 // PutObject into the store
 func (a *S3ObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	sseConfig, err := a.sseConfigFor(ctx)
+	if err != nil {
+		return err
+	}
+
 	return instrument.CollectedRequest(ctx, "S3.PutObject", s3RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
 		putObjectInput := &s3.PutObjectInput{
 			Body:   object,
@@ -398,10 +502,14 @@ func (a *S3ObjectClient) PutObject(ctx context.Context, objectKey string, object
 			Key:    aws.String(objectKey),
 		}
 
-		if a.sseConfig != nil {
-			putObjectInput.ServerSideEncryption = aws.String(a.sseConfig.ServerSideEncryption)
-			putObjectInput.SSEKMSKeyId = a.sseConfig.KMSKeyID
-			putObjectInput.SSEKMSEncryptionContext = a.sseConfig.KMSEncryptionContext
+		if sseConfig != nil {
+			if sseConfig.ServerSideEncryption != "" {
+				putObjectInput.ServerSideEncryption = aws.String(sseConfig.ServerSideEncryption)
+			}
+			putObjectInput.SSEKMSKeyId = sseConfig.KMSKeyID
+			putObjectInput.SSEKMSEncryptionContext = sseConfig.KMSEncryptionContext
+			putObjectInput.SSECustomerAlgorithm = sseConfig.SSECustomerAlgorithm
+			putObjectInput.SSECustomerKey = sseConfig.SSECustomerKey
 		}
 
 		_, err := a.S3.PutObjectWithContext(ctx, putObjectInput)
@@ -3,12 +3,16 @@ package aws
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,8 +22,10 @@ import (
 	"github.com/grafana/dskit/flagext"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
 
+	bucket_s3 "github.com/grafana/loki/pkg/storage/bucket/s3"
 	"github.com/grafana/loki/pkg/storage/chunk/client/hedging"
 )
 
@@ -69,7 +75,7 @@ func TestRequestMiddleware(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg.Inject = tt.fn
-			client, err := NewS3ObjectClient(cfg, hedging.Config{})
+			client, err := NewS3ObjectClient(cfg, hedging.Config{}, nil)
 			require.NoError(t, err)
 
 			readCloser, _, err := client.GetObject(context.Background(), "key")
@@ -144,7 +150,7 @@ func Test_Hedging(t *testing.T) {
 				At:           tc.hedgeAt,
 				UpTo:         tc.upTo,
 				MaxPerSecond: 1000,
-			})
+			}, nil)
 			require.NoError(t, err)
 			tc.do(c)
 			require.Equal(t, tc.expectedCalls, count.Load())
@@ -152,6 +158,104 @@ func Test_Hedging(t *testing.T) {
 	}
 }
 
+type mockTenantSSEConfigs map[string]bucket_s3.SSEConfig
+
+func (m mockTenantSSEConfigs) S3SSEType(userID string) string { return m[userID].Type }
+
+func (m mockTenantSSEConfigs) S3SSEKMSKeyID(userID string) string { return m[userID].KMSKeyID }
+
+func (m mockTenantSSEConfigs) S3SSEKMSEncryptionContext(userID string) string {
+	return m[userID].KMSEncryptionContext
+}
+
+func (m mockTenantSSEConfigs) S3SSECustomerKeyFile(userID string) string {
+	return m[userID].CustomerKeyFile
+}
+
+func Test_TenantSSEConfigs(t *testing.T) {
+	customerKey := bytes.Repeat([]byte("k"), 32)
+	customerKeyFile := filepath.Join(t.TempDir(), "sse-c.key")
+	require.NoError(t, os.WriteFile(customerKeyFile, customerKey, 0o600))
+
+	// Like S3, the fake records the customer key each object is written with, and rejects reads with another key.
+	var (
+		lastHeader   http.Header
+		objectKeys   = map[string]string{}
+		objectKeysMu sync.Mutex
+	)
+	tenantSSEConfigs := mockTenantSSEConfigs{
+		"kms":  {Type: bucket_s3.SSEKMS, KMSKeyID: "arn:aws:kms:key"},
+		"ssec": {Type: bucket_s3.SSEC, CustomerKeyFile: customerKeyFile},
+	}
+	c, err := NewS3ObjectClient(S3Config{
+		AccessKeyID:     "foo",
+		SecretAccessKey: flagext.SecretWithValue("bar"),
+		BucketNames:     "foo",
+		SSEConfig:       bucket_s3.SSEConfig{Type: bucket_s3.SSES3},
+		Inject: func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				lastHeader = req.Header.Clone()
+				key := req.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key")
+
+				objectKeysMu.Lock()
+				defer objectKeysMu.Unlock()
+				switch req.Method {
+				case http.MethodPut:
+					objectKeys[req.URL.Path] = key
+				case http.MethodGet:
+					if objectKeys[req.URL.Path] != key {
+						return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+					}
+				}
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}, nil
+			})
+		},
+	}, hedging.Config{}, tenantSSEConfigs)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name               string
+		ctx                context.Context
+		expectedSSE        string
+		expectedKMSKeyID   string
+		expectedCustomerOK bool
+	}{
+		{name: "no tenant uses the default config", ctx: context.Background(), expectedSSE: sseS3Type},
+		{name: "tenant without override uses the default config", ctx: user.InjectOrgID(context.Background(), "other"), expectedSSE: sseS3Type},
+		{name: "tenant with SSE-KMS override", ctx: user.InjectOrgID(context.Background(), "kms"), expectedSSE: sseKMSType, expectedKMSKeyID: "arn:aws:kms:key"},
+		{name: "tenant with SSE-C override", ctx: user.InjectOrgID(context.Background(), "ssec"), expectedCustomerOK: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, c.PutObject(tc.ctx, "foo", bytes.NewReader([]byte("bar"))))
+			require.Equal(t, tc.expectedSSE, lastHeader.Get("X-Amz-Server-Side-Encryption"))
+			require.Equal(t, tc.expectedKMSKeyID, lastHeader.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+			if tc.expectedCustomerOK {
+				require.Equal(t, sseCustomerAlgorithm, lastHeader.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm"))
+				require.Equal(t, base64.StdEncoding.EncodeToString(customerKey), lastHeader.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+				require.NotEmpty(t, lastHeader.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
+			} else {
+				require.Empty(t, lastHeader.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+			}
+
+			_, _, err := c.GetObject(tc.ctx, "foo")
+			require.NoError(t, err)
+		})
+	}
+
+	// The tenant with the SSE-C override still reads the objects written without customer key, e.g. before the
+	// override was set.
+	require.NoError(t, c.PutObject(context.Background(), "plain", bytes.NewReader([]byte("bar"))))
+	_, _, err = c.GetObject(user.InjectOrgID(context.Background(), "ssec"), "plain")
+	require.NoError(t, err)
+	require.Empty(t, lastHeader.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+
+	// And the SSE-C objects still require the key once the override is removed.
+	require.NoError(t, c.PutObject(user.InjectOrgID(context.Background(), "ssec"), "ssec", bytes.NewReader([]byte("bar"))))
+	delete(tenantSSEConfigs, "ssec")
+	_, _, err = c.GetObject(user.InjectOrgID(context.Background(), "ssec"), "ssec")
+	require.Error(t, err)
+}
+
 func Test_ConfigRedactsCredentials(t *testing.T) {
 	underTest := S3Config{
 		AccessKeyID:     "access key id",
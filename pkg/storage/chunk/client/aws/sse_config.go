@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/pkg/errors"
 
 	bucket_s3 "github.com/grafana/loki/pkg/storage/bucket/s3"
@@ -12,6 +13,8 @@ import (
 const (
 	sseKMSType = "aws:kms"
 	sseS3Type  = "AES256"
+
+	sseCustomerAlgorithm = "AES256"
 )
 
 // SSEParsedConfig configures server side encryption (SSE)
@@ -20,6 +23,11 @@ type SSEParsedConfig struct {
 	ServerSideEncryption string
 	KMSKeyID             *string
 	KMSEncryptionContext *string
+
+	// Set when objects are encrypted with a customer-provided key (SSE-C),
+	// which has to be passed when reading the objects as well.
+	SSECustomerAlgorithm *string
+	SSECustomerKey       *string
 }
 
 // NewSSEParsedConfig creates a struct to configure server side encryption (SSE)
@@ -44,6 +52,17 @@ func NewSSEParsedConfig(cfg bucket_s3.SSEConfig) (*SSEParsedConfig, error) {
 			KMSKeyID:             &cfg.KMSKeyID,
 			KMSEncryptionContext: parsedKMSEncryptionContext,
 		}, nil
+	case bucket_s3.SSEC:
+		key, err := bucket_s3.ReadSSECustomerKey(cfg.CustomerKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		customerKey := string(key)
+		return &SSEParsedConfig{
+			SSECustomerAlgorithm: aws.String(sseCustomerAlgorithm),
+			SSECustomerKey:       &customerKey,
+		}, nil
 	default:
 		return nil, errors.New("SSE type is empty or invalid")
 	}
@@ -69,6 +69,13 @@ func TestNewSSEParsedConfig(t *testing.T) {
 			},
 			expectedErr: errors.New("KMS key id must be passed when SSE-KMS encryption is selected"),
 		},
+		{
+			name: "Test SSE encryption with SSEC type without customer key file",
+			params: s3.SSEConfig{
+				Type: s3.SSEC,
+			},
+			expectedErr: errors.New("S3 SSE-C requires a customer key file"),
+		},
 		{
 			name: "Test SSE with invalid KMS encryption context JSON",
 			params: s3.SSEConfig{
@@ -0,0 +1,201 @@
+package backblaze
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Backblaze/blazer/b2"
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/weaveworks/common/instrument"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+)
+
+var b2RequestDuration = instrument.NewHistogramCollector(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "loki",
+	Name:      "b2_request_duration_seconds",
+	Help:      "Time spent doing Backblaze B2 requests.",
+	Buckets:   prometheus.ExponentialBuckets(0.005, 4, 6),
+}, []string{"operation", "status_code"}))
+
+func init() {
+	b2RequestDuration.Register()
+}
+
+// errObjectNotFound is returned by DeleteObject when no version of the object exists.
+var errObjectNotFound = errors.New("object not found")
+
+// B2Config is config for the Backblaze B2 Chunk Client.
+type B2Config struct {
+	BucketName        string         `yaml:"bucket_name"`
+	KeyID             string         `yaml:"key_id"`
+	ApplicationKey    flagext.Secret `yaml:"application_key"`
+	ChunkSize         int            `yaml:"chunk_size"`
+	ConcurrentUploads int            `yaml:"concurrent_uploads"`
+	HideOnDelete      bool           `yaml:"hide_on_delete"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *B2Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("", f)
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *B2Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.BucketName, prefix+"b2.bucket-name", "", "Name of B2 bucket.")
+	f.StringVar(&cfg.KeyID, prefix+"b2.key-id", "", "B2 application key ID.")
+	f.Var(&cfg.ApplicationKey, prefix+"b2.application-key", "B2 application key.")
+	f.IntVar(&cfg.ChunkSize, prefix+"b2.chunk-size", 100e6, "Objects larger than this size in bytes are uploaded in parts of this size using the large file API. Must be at least 5MB.")
+	f.IntVar(&cfg.ConcurrentUploads, prefix+"b2.concurrent-uploads", 1, "Number of parts of a large file uploaded concurrently. Each upload buffers a whole part in memory.")
+	f.BoolVar(&cfg.HideOnDelete, prefix+"b2.hide-on-delete", false, "Hide deleted objects instead of deleting all of their versions, leaving their removal to the lifecycle rules of the bucket.")
+}
+
+// Validate config and returns error on failure
+func (cfg *B2Config) Validate() error {
+	if cfg.ChunkSize < 5e6 {
+		return fmt.Errorf("invalid B2 chunk size %d: must be at least 5MB", cfg.ChunkSize)
+	}
+	return nil
+}
+
+type B2ObjectClient struct {
+	cfg    B2Config
+	bucket *b2.Bucket
+}
+
+// NewB2ObjectClient makes a new Backblaze B2 backed ObjectClient using the native B2 API.
+func NewB2ObjectClient(ctx context.Context, cfg B2Config) (*B2ObjectClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	b2Client, err := b2.NewClient(ctx, cfg.KeyID, cfg.ApplicationKey.String(), b2.UserAgent("loki"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to authorize B2 account")
+	}
+	bucket, err := b2Client.Bucket(ctx, cfg.BucketName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get B2 bucket [ %s ]", cfg.BucketName)
+	}
+	return &B2ObjectClient{
+		cfg:    cfg,
+		bucket: bucket,
+	}, nil
+}
+
+func (s *B2ObjectClient) Stop() {}
+
+// GetObject returns a reader and the size for the specified object key from the configured B2 bucket.
+func (s *B2ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	var attrs *b2.Attrs
+	obj := s.bucket.Object(objectKey)
+	err := instrument.CollectedRequest(ctx, "B2.GetObject", b2RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		var requestErr error
+		attrs, requestErr = obj.Attrs(ctx)
+		return requestErr
+	})
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to get B2 object [ %s ]", objectKey)
+	}
+	return obj.NewReader(ctx), attrs.Size, nil
+}
+
+// PutObject puts the specified bytes into the configured B2 bucket at the provided key.
+// Objects larger than the configured chunk size are uploaded using the large file API.
+func (s *B2ObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	return instrument.CollectedRequest(ctx, "B2.PutObject", b2RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		w := s.bucket.Object(objectKey).NewWriter(ctx)
+		w.ChunkSize = s.cfg.ChunkSize
+		w.ConcurrentUploads = s.cfg.ConcurrentUploads
+
+		if _, err := io.Copy(w, object); err != nil {
+			// Cancel before closing so a partially written object is not committed.
+			cancel()
+			_ = w.Close()
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// List implements chunk.ObjectClient.
+func (s *B2ObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
+	var storageObjects []client.StorageObject
+	var commonPrefixes []client.StorageCommonPrefix
+
+	err := instrument.CollectedRequest(ctx, "B2.List", b2RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		options := []b2.ListOption{b2.ListPrefix(prefix)}
+		if delimiter != "" {
+			options = append(options, b2.ListDelimiter(delimiter))
+		}
+
+		iter := s.bucket.List(ctx, options...)
+		for iter.Next() {
+			// Attributes are part of the listing response, so this does not issue another request.
+			attrs, err := iter.Object().Attrs(ctx)
+			if err != nil {
+				return err
+			}
+			if attrs.Status == b2.Folder {
+				commonPrefixes = append(commonPrefixes, client.StorageCommonPrefix(attrs.Name))
+				continue
+			}
+			storageObjects = append(storageObjects, client.StorageObject{
+				Key:        attrs.Name,
+				ModifiedAt: attrs.UploadTimestamp,
+			})
+		}
+		return iter.Err()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return storageObjects, commonPrefixes, nil
+}
+
+// DeleteObject deletes the specified object key from the configured B2 bucket.
+// Since every upload of a key creates a new file version in B2, all versions are deleted,
+// unless objects are configured to be hidden and left for the bucket lifecycle rules to remove.
+func (s *B2ObjectClient) DeleteObject(ctx context.Context, objectKey string) error {
+	return instrument.CollectedRequest(ctx, "B2.DeleteObject", b2RequestDuration, instrument.ErrorCode, func(ctx context.Context) error {
+		if s.cfg.HideOnDelete {
+			return s.bucket.Object(objectKey).Hide(ctx)
+		}
+
+		deleted := false
+		iter := s.bucket.List(ctx, b2.ListPrefix(objectKey), b2.ListHidden())
+		for iter.Next() {
+			obj := iter.Object()
+			if obj.Name() > objectKey {
+				break
+			}
+			if obj.Name() != objectKey {
+				continue
+			}
+			if err := obj.Delete(ctx); err != nil {
+				return err
+			}
+			deleted = true
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if !deleted {
+			return errObjectNotFound
+		}
+		return nil
+	})
+}
+
+// IsObjectNotFoundErr returns true if error means that object is not found. Relevant to GetObject and DeleteObject operations.
+func (s *B2ObjectClient) IsObjectNotFoundErr(err error) bool {
+	err = errors.Cause(err)
+	return err == errObjectNotFound || b2.IsNotExist(err)
+}
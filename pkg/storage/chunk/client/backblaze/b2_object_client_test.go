@@ -0,0 +1,54 @@
+package backblaze
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_ConfigRedactsCredentials(t *testing.T) {
+	underTest := B2Config{
+		KeyID:          "key id",
+		ApplicationKey: flagext.SecretWithValue("application key"),
+	}
+
+	output, err := yaml.Marshal(underTest)
+	require.NoError(t, err)
+
+	require.True(t, bytes.Contains(output, []byte("key id")))
+	require.False(t, bytes.Contains(output, []byte("application key")))
+}
+
+func Test_ConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		chunkSize int
+		valid     bool
+	}{
+		{chunkSize: 100e6, valid: true},
+		{chunkSize: 5e6, valid: true},
+		{chunkSize: 1e6, valid: false},
+		{chunkSize: 0, valid: false},
+	} {
+		t.Run(fmt.Sprint(tc.chunkSize), func(t *testing.T) {
+			cfg := B2Config{ChunkSize: tc.chunkSize}
+			if tc.valid {
+				require.NoError(t, cfg.Validate())
+			} else {
+				require.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+func Test_IsObjectNotFoundErr(t *testing.T) {
+	c := &B2ObjectClient{}
+
+	require.True(t, c.IsObjectNotFoundErr(errObjectNotFound))
+	require.True(t, c.IsObjectNotFoundErr(errors.Wrap(errObjectNotFound, "failed to delete B2 object")))
+	require.False(t, c.IsObjectNotFoundErr(fmt.Errorf("connection refused")))
+}
@@ -24,6 +24,12 @@ type Client interface {
 	IsChunkNotFoundErr(err error) bool
 }
 
+// BloomFilterClient is implemented by chunk clients which store a bloom filter alongside each chunk.
+type BloomFilterClient interface {
+	// GetBloomFilters returns the encoded bloom filters of the chunks, an empty one for the chunks which have none.
+	GetBloomFilters(ctx context.Context, chunks []chunk.Chunk) ([][]byte, error)
+}
+
 // ObjectAndIndexClient allows optimisations where the same client handles both
 type ObjectAndIndexClient interface {
 	PutChunksAndIndex(ctx context.Context, chunks []chunk.Chunk, index index.WriteBatch) error
@@ -0,0 +1,79 @@
+package encryption
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/pkg/errors"
+)
+
+// AWSKMSConfig configures AWS KMS as key provider.
+type AWSKMSConfig struct {
+	Region   string        `yaml:"region"`
+	KeyID    string        `yaml:"key_id"`
+	Endpoint string        `yaml:"endpoint"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *AWSKMSConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Region, prefix+"aws-kms.region", "", "AWS region of the KMS key.")
+	f.StringVar(&cfg.KeyID, prefix+"aws-kms.key-id", "", "ID or ARN of the KMS key wrapping the data keys.")
+	f.StringVar(&cfg.Endpoint, prefix+"aws-kms.endpoint", "", "KMS endpoint to connect to. Defaults to the endpoint of the region.")
+	f.DurationVar(&cfg.Timeout, prefix+"aws-kms.timeout", 10*time.Second, "Timeout of requests to KMS.")
+}
+
+// awsKMSKeyProvider generates and decrypts data keys with AWS KMS. Credentials are
+// looked up the same way as for the other AWS clients, e.g. from the environment or
+// the instance role.
+type awsKMSKeyProvider struct {
+	cfg    AWSKMSConfig
+	client *kms.KMS
+}
+
+func newAWSKMSKeyProvider(cfg AWSKMSConfig) (*awsKMSKeyProvider, error) {
+	if cfg.Region == "" || cfg.KeyID == "" {
+		return nil, errors.New("AWS KMS region and key id are required")
+	}
+	kmsConfig := aws.NewConfig().
+		WithRegion(cfg.Region).
+		WithHTTPClient(&http.Client{Timeout: cfg.Timeout})
+	if cfg.Endpoint != "" {
+		kmsConfig = kmsConfig.WithEndpoint(cfg.Endpoint)
+	}
+	sess, err := session.NewSession(kmsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create AWS session")
+	}
+	return &awsKMSKeyProvider{
+		cfg:    cfg,
+		client: kms.New(sess),
+	}, nil
+}
+
+func (p *awsKMSKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	resp, err := p.client.GenerateDataKeyWithContext(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.cfg.KeyID),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate data key")
+	}
+	return resp.Plaintext, resp.CiphertextBlob, nil
+}
+
+func (p *awsKMSKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	resp, err := p.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(p.cfg.KeyID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data key")
+	}
+	return resp.Plaintext, nil
+}
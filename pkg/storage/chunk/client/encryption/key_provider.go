@@ -0,0 +1,86 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const dataKeySize = 32 // AES-256
+
+// KeyProvider generates the data keys used to encrypt objects, and decrypts them again.
+// Data keys are stored alongside the objects they encrypt, wrapped by a master key which
+// never leaves the provider.
+type KeyProvider interface {
+	// GenerateDataKey returns a new data key, both in plaintext and wrapped by the master key.
+	GenerateDataKey(ctx context.Context) (plaintext, wrapped []byte, err error)
+	// DecryptDataKey returns the plaintext of a data key wrapped by GenerateDataKey.
+	DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// staticKeyProvider wraps data keys with a master key read from a local file.
+type staticKeyProvider struct {
+	masterKey cipher.AEAD
+}
+
+func newStaticKeyProvider(path string) (*staticKeyProvider, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read encryption master key")
+	}
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("invalid encryption master key: expected %d bytes, got %d", dataKeySize, len(key))
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &staticKeyProvider{masterKey: aead}, nil
+}
+
+func (p *staticKeyProvider) GenerateDataKey(_ context.Context) ([]byte, []byte, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, nil, err
+	}
+	wrapped, err := seal(p.masterKey, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, wrapped, nil
+}
+
+func (p *staticKeyProvider) DecryptDataKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	return open(p.masterKey, wrapped)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the returned ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts a ciphertext returned by seal.
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
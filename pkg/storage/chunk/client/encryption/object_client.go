@@ -0,0 +1,246 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+)
+
+const (
+	KeyProviderStatic = "static"
+	KeyProviderVault  = "vault"
+	KeyProviderAWSKMS = "aws-kms"
+
+	// Size of the cache of decrypted data keys, to avoid a round trip to the key provider per object read.
+	dataKeyCacheSize = 1024
+)
+
+// magic prefixes encrypted objects. Objects without it are returned as is, so that
+// encryption can be enabled on a store which already contains data.
+var magic = []byte("LOKIENC1")
+
+// Config configures the client-side envelope encryption of objects.
+type Config struct {
+	KeyProvider           string        `yaml:"key_provider"`
+	StaticKeyFile         string        `yaml:"static_key_file"`
+	Vault                 VaultConfig   `yaml:"vault"`
+	AWSKMS                AWSKMSConfig  `yaml:"aws_kms"`
+	DataKeyRotationPeriod time.Duration `yaml:"data_key_rotation_period"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("", f)
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	prefix += "encryption."
+	f.StringVar(&cfg.KeyProvider, prefix+"key-provider", "", fmt.Sprintf("Provider of the keys used to encrypt objects before they are written to the object store. Supported values are: %s, %s, %s. Empty disables the encryption.", KeyProviderStatic, KeyProviderVault, KeyProviderAWSKMS))
+	f.StringVar(&cfg.StaticKeyFile, prefix+"static-key-file", "", "Path to a file containing the 256-bit master key of the static key provider.")
+	cfg.Vault.RegisterFlagsWithPrefix(prefix, f)
+	cfg.AWSKMS.RegisterFlagsWithPrefix(prefix, f)
+	f.DurationVar(&cfg.DataKeyRotationPeriod, prefix+"data-key-rotation-period", time.Hour, "How long a data key is used to encrypt new objects before a new one is generated. Must be positive.")
+}
+
+// Validate config and returns error on failure
+func (cfg *Config) Validate() error {
+	switch cfg.KeyProvider {
+	case "", KeyProviderVault, KeyProviderAWSKMS:
+	case KeyProviderStatic:
+		if cfg.StaticKeyFile == "" {
+			return errors.New("the static key provider requires a key file")
+		}
+	default:
+		return fmt.Errorf("unsupported encryption key provider: %s", cfg.KeyProvider)
+	}
+	// a data key would be generated, and wrapped by the key provider, for each object
+	if cfg.Enabled() && cfg.DataKeyRotationPeriod <= 0 {
+		return errors.New("the data key rotation period must be positive")
+	}
+	return nil
+}
+
+// Enabled returns whether objects are encrypted.
+func (cfg *Config) Enabled() bool {
+	return cfg.KeyProvider != ""
+}
+
+func newKeyProvider(cfg Config) (KeyProvider, error) {
+	switch cfg.KeyProvider {
+	case KeyProviderStatic:
+		return newStaticKeyProvider(cfg.StaticKeyFile)
+	case KeyProviderVault:
+		return newVaultKeyProvider(cfg.Vault)
+	case KeyProviderAWSKMS:
+		return newAWSKMSKeyProvider(cfg.AWSKMS)
+	default:
+		return nil, fmt.Errorf("unsupported encryption key provider: %s", cfg.KeyProvider)
+	}
+}
+
+type dataKey struct {
+	plaintext []byte
+	wrapped   []byte
+	createdAt time.Time
+}
+
+// ObjectClient encrypts objects with AES-GCM before writing them to the wrapped ObjectClient,
+// and decrypts them when reading. Each object is encrypted with a data key, which is stored
+// in the object wrapped by the master key of the KeyProvider.
+type ObjectClient struct {
+	client.ObjectClient
+
+	keys           KeyProvider
+	rotationPeriod time.Duration
+	decryptedKeys  *lru.Cache
+
+	mtx        sync.Mutex
+	currentKey *dataKey
+}
+
+// WrapObjectClient returns an ObjectClient encrypting the objects of c, if encryption is enabled.
+func WrapObjectClient(c client.ObjectClient, cfg Config) (client.ObjectClient, error) {
+	if !cfg.Enabled() {
+		return c, nil
+	}
+	keys, err := newKeyProvider(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create encryption key provider")
+	}
+	return NewObjectClient(c, keys, cfg.DataKeyRotationPeriod)
+}
+
+// NewObjectClient makes a new ObjectClient encrypting the objects of c with data keys from keys.
+func NewObjectClient(c client.ObjectClient, keys KeyProvider, rotationPeriod time.Duration) (*ObjectClient, error) {
+	cache, err := lru.New(dataKeyCacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectClient{
+		ObjectClient:   c,
+		keys:           keys,
+		rotationPeriod: rotationPeriod,
+		decryptedKeys:  cache,
+	}, nil
+}
+
+// PutObject encrypts the object before putting it into the wrapped ObjectClient.
+func (c *ObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	plaintext, err := io.ReadAll(object)
+	if err != nil {
+		return err
+	}
+	key, err := c.dataKey(ctx)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encrypt(key, plaintext)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt object [ %s ]", objectKey)
+	}
+	return c.ObjectClient.PutObject(ctx, objectKey, bytes.NewReader(encrypted))
+}
+
+// GetObject decrypts the object read from the wrapped ObjectClient.
+func (c *ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	readCloser, _, err := c.ObjectClient.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer readCloser.Close()
+
+	buf, err := io.ReadAll(readCloser)
+	if err != nil {
+		return nil, 0, err
+	}
+	plaintext, err := c.decrypt(ctx, buf)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to decrypt object [ %s ]", objectKey)
+	}
+	return io.NopCloser(bytes.NewReader(plaintext)), int64(len(plaintext)), nil
+}
+
+// dataKey returns the data key used to encrypt new objects, generating a new one
+// once the current one is older than the rotation period.
+func (c *ObjectClient) dataKey(ctx context.Context) (*dataKey, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.currentKey != nil && time.Since(c.currentKey.createdAt) < c.rotationPeriod {
+		return c.currentKey, nil
+	}
+	plaintext, wrapped, err := c.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.currentKey = &dataKey{plaintext: plaintext, wrapped: wrapped, createdAt: time.Now()}
+	c.decryptedKeys.Add(string(wrapped), plaintext)
+	return c.currentKey, nil
+}
+
+func (c *ObjectClient) decrypt(ctx context.Context, buf []byte) ([]byte, error) {
+	if !bytes.HasPrefix(buf, magic) {
+		return buf, nil
+	}
+	wrapped, ciphertext, err := decodeEnvelope(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintextKey []byte
+	if cached, ok := c.decryptedKeys.Get(string(wrapped)); ok {
+		plaintextKey = cached.([]byte)
+	} else {
+		plaintextKey, err = c.keys.DecryptDataKey(ctx, wrapped)
+		if err != nil {
+			return nil, err
+		}
+		c.decryptedKeys.Add(string(wrapped), plaintextKey)
+	}
+
+	aead, err := newAEAD(plaintextKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, ciphertext)
+}
+
+// encrypt returns the envelope of plaintext encrypted with key:
+// magic | uvarint(len(wrapped key)) | wrapped key | nonce | ciphertext.
+func encrypt(key *dataKey, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key.plaintext)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(magic)+binary.MaxVarintLen64+len(key.wrapped)+len(ciphertext))
+	buf = append(buf, magic...)
+	buf = binary.AppendUvarint(buf, uint64(len(key.wrapped)))
+	buf = append(buf, key.wrapped...)
+	return append(buf, ciphertext...), nil
+}
+
+func decodeEnvelope(buf []byte) (wrapped, ciphertext []byte, err error) {
+	buf = buf[len(magic):]
+	n, size := binary.Uvarint(buf)
+	if size <= 0 || uint64(len(buf)-size) < n {
+		return nil, nil, errors.New("invalid encrypted object envelope")
+	}
+	buf = buf[size:]
+	return buf[:n], buf[n:], nil
+}
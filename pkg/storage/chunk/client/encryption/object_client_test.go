@@ -0,0 +1,177 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+	"github.com/grafana/loki/pkg/storage/chunk/client/testutils"
+)
+
+func newTestStaticKeyProvider(t *testing.T) *staticKeyProvider {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, key, 0o600))
+
+	p, err := newStaticKeyProvider(path)
+	require.NoError(t, err)
+	return p
+}
+
+func getObject(t *testing.T, c client.ObjectClient, key string) []byte {
+	rc, size, err := c.GetObject(context.Background(), key)
+	require.NoError(t, err)
+	defer rc.Close()
+	buf, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(buf)), size)
+	return buf
+}
+
+func TestObjectClient_RoundTrip(t *testing.T) {
+	storage := testutils.NewMockStorage()
+	c, err := NewObjectClient(storage, newTestStaticKeyProvider(t), time.Hour)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.PutObject(ctx, "chunk", bytes.NewReader([]byte("some chunk data"))))
+
+	raw := getObject(t, storage, "chunk")
+	require.True(t, bytes.HasPrefix(raw, magic))
+	require.NotContains(t, string(raw), "some chunk data")
+
+	require.Equal(t, []byte("some chunk data"), getObject(t, c, "chunk"))
+
+	// decrypting with a fresh client requires unwrapping the data key.
+	c2, err := NewObjectClient(storage, c.keys, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []byte("some chunk data"), getObject(t, c2, "chunk"))
+}
+
+func TestObjectClient_PlaintextObjects(t *testing.T) {
+	storage := testutils.NewMockStorage()
+	require.NoError(t, storage.PutObject(context.Background(), "plain", bytes.NewReader([]byte("written before encryption"))))
+
+	c, err := NewObjectClient(storage, newTestStaticKeyProvider(t), time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, []byte("written before encryption"), getObject(t, c, "plain"))
+}
+
+func TestObjectClient_WrongMasterKey(t *testing.T) {
+	storage := testutils.NewMockStorage()
+	c, err := NewObjectClient(storage, newTestStaticKeyProvider(t), time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, c.PutObject(context.Background(), "chunk", bytes.NewReader([]byte("data"))))
+
+	other, err := NewObjectClient(storage, newTestStaticKeyProvider(t), time.Hour)
+	require.NoError(t, err)
+	_, _, err = other.GetObject(context.Background(), "chunk")
+	require.Error(t, err)
+}
+
+type countingKeyProvider struct {
+	KeyProvider
+	generated int
+}
+
+func (p *countingKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	p.generated++
+	return p.KeyProvider.GenerateDataKey(ctx)
+}
+
+func TestObjectClient_DataKeyRotation(t *testing.T) {
+	keys := &countingKeyProvider{KeyProvider: newTestStaticKeyProvider(t)}
+	c, err := NewObjectClient(testutils.NewMockStorage(), keys, time.Hour)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, c.PutObject(ctx, "a", bytes.NewReader([]byte("a"))))
+	require.NoError(t, c.PutObject(ctx, "b", bytes.NewReader([]byte("b"))))
+	require.Equal(t, 1, keys.generated)
+
+	c.currentKey.createdAt = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, c.PutObject(ctx, "c", bytes.NewReader([]byte("c"))))
+	require.Equal(t, 2, keys.generated)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.Equal(t, []byte(key), getObject(t, c, key))
+	}
+}
+
+func TestWrapObjectClient_Disabled(t *testing.T) {
+	storage := testutils.NewMockStorage()
+	c, err := WrapObjectClient(storage, Config{})
+	require.NoError(t, err)
+	require.Equal(t, storage, c)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, (&Config{}).Validate())
+	require.NoError(t, (&Config{KeyProvider: KeyProviderVault, DataKeyRotationPeriod: time.Hour}).Validate())
+	require.NoError(t, (&Config{KeyProvider: KeyProviderStatic, StaticKeyFile: "key", DataKeyRotationPeriod: time.Hour}).Validate())
+	require.Error(t, (&Config{KeyProvider: KeyProviderStatic, DataKeyRotationPeriod: time.Hour}).Validate())
+	require.Error(t, (&Config{KeyProvider: "unknown"}).Validate())
+	require.Error(t, (&Config{KeyProvider: KeyProviderVault}).Validate())
+	require.Error(t, (&Config{KeyProvider: KeyProviderVault, DataKeyRotationPeriod: -time.Hour}).Validate())
+}
+
+func TestVaultKeyProvider(t *testing.T) {
+	// fake transit engine which "wraps" keys by base64 encoding them.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var plaintext, ciphertext string
+		switch r.URL.Path {
+		case "/v1/transit/datakey/plaintext/loki":
+			key := make([]byte, int(req["bits"].(float64))/8)
+			_, err := rand.Read(key)
+			require.NoError(t, err)
+			plaintext = base64.StdEncoding.EncodeToString(key)
+			ciphertext = "vault:v1:" + plaintext
+		case "/v1/transit/decrypt/loki":
+			plaintext = req["ciphertext"].(string)[len("vault:v1:"):]
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"plaintext": plaintext, "ciphertext": ciphertext},
+		}))
+	}))
+	defer server.Close()
+
+	keys, err := newVaultKeyProvider(VaultConfig{
+		Address:   server.URL,
+		Token:     flagext.SecretWithValue("token"),
+		MountPath: "transit",
+		KeyName:   "loki",
+	})
+	require.NoError(t, err)
+
+	plaintext, wrapped, err := keys.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+	require.Len(t, plaintext, dataKeySize)
+
+	decrypted, err := keys.DecryptDataKey(context.Background(), wrapped)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+}
@@ -0,0 +1,106 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+)
+
+// VaultConfig configures the HashiCorp Vault transit secrets engine as key provider.
+type VaultConfig struct {
+	Address   string         `yaml:"address"`
+	Token     flagext.Secret `yaml:"token"`
+	MountPath string         `yaml:"mount_path"`
+	KeyName   string         `yaml:"key_name"`
+	Timeout   time.Duration  `yaml:"timeout"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *VaultConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Address, prefix+"vault.address", "", "Address of the Vault server.")
+	f.Var(&cfg.Token, prefix+"vault.token", "Vault token used to access the transit secrets engine.")
+	f.StringVar(&cfg.MountPath, prefix+"vault.mount-path", "transit", "Mount path of the transit secrets engine.")
+	f.StringVar(&cfg.KeyName, prefix+"vault.key-name", "", "Name of the transit key wrapping the data keys.")
+	f.DurationVar(&cfg.Timeout, prefix+"vault.timeout", 10*time.Second, "Timeout of requests to Vault.")
+}
+
+// vaultKeyProvider generates and decrypts data keys with the transit secrets engine of Vault.
+type vaultKeyProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+func newVaultKeyProvider(cfg VaultConfig) (*vaultKeyProvider, error) {
+	if cfg.Address == "" || cfg.KeyName == "" {
+		return nil, errors.New("Vault address and key name are required")
+	}
+	return &vaultKeyProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+func (p *vaultKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext  string `json:"plaintext"`
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, "datakey/plaintext", map[string]interface{}{"bits": dataKeySize * 8}, &resp); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate data key")
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plaintext, []byte(resp.Data.Ciphertext), nil
+}
+
+func (p *vaultKeyProvider) DecryptDataKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := p.do(ctx, "decrypt", map[string]interface{}{"ciphertext": string(wrapped)}, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data key")
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+func (p *vaultKeyProvider) do(ctx context.Context, operation string, body, out interface{}) error {
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", strings.TrimSuffix(p.cfg.Address, "/"), strings.Trim(p.cfg.MountPath, "/"), operation, p.cfg.KeyName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token.String())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected response from Vault: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -3,6 +3,7 @@ package hedging
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -44,6 +45,9 @@ type Config struct {
 	UpTo int `yaml:"up_to"`
 	// The maximun of hedge requests allowed per second.
 	MaxPerSecond int `yaml:"max_per_second"`
+	// AtPercentile is the percentile of the latencies of the recent requests of the same kind after which a second request
+	// will be issued, with At as minimum. 0 disables it.
+	AtPercentile float64 `yaml:"at_percentile"`
 }
 
 // RegisterFlags registers flags.
@@ -56,6 +60,15 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.IntVar(&cfg.UpTo, prefix+"hedge-requests-up-to", 2, "The maximun of hedge requests allowed.")
 	f.DurationVar(&cfg.At, prefix+"hedge-requests-at", 0, "If set to a non-zero value a second request will be issued at the provided duration. Default is 0 (disabled)")
 	f.IntVar(&cfg.MaxPerSecond, prefix+"hedge-max-per-second", 5, "The maximun of hedge requests allowed per seconds.")
+	f.Float64Var(&cfg.AtPercentile, prefix+"hedge-requests-at-percentile", 0, "If set to a value between 0 and 1 and hedging is enabled, a second request will be issued once a request has been outstanding for longer than this percentile of the latencies of the recent requests of the same kind, including the reading of their responses, with the duration of hedge-requests-at as minimum. Default is 0 (always use hedge-requests-at)")
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if cfg.AtPercentile < 0 || cfg.AtPercentile >= 1 {
+		return fmt.Errorf("hedging at_percentile must be between 0 and 1, got %v", cfg.AtPercentile)
+	}
+	return nil
 }
 
 // Client returns a hedged http client.
@@ -100,6 +113,9 @@ func (cfg *Config) RoundTripperWithRegisterer(next http.RoundTripper, reg promet
 		reg.MustRegister(totalHedgeRequests)
 		reg.MustRegister(totalRateLimitedHedgeRequests)
 	})
+	if cfg.AtPercentile > 0 {
+		return newPercentileHedgingRoundTripper(cfg.At, cfg.UpTo, cfg.AtPercentile, newLimitedHedgingRoundTripper(cfg.MaxPerSecond, next)), nil
+	}
 	return hedgedhttp.NewRoundTripper(
 		cfg.At,
 		cfg.UpTo,
@@ -125,7 +141,7 @@ func newLimitedHedgingRoundTripper(max int, next http.RoundTripper) *limitedHedg
 }
 
 func (rt *limitedHedgingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	if hedgedhttp.IsHedgedRequest(req) {
+	if isHedgedRequest(req) {
 		if !rt.limiter.Allow() {
 			totalRateLimitedHedgeRequests.Inc()
 			return nil, ErrTooManyHedgeRequests
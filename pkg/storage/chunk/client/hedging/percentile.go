@@ -0,0 +1,244 @@
+package hedging
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cristalhq/hedgedhttp"
+	"go.uber.org/atomic"
+)
+
+const (
+	// latencySamples is the number of recent request latencies the hedging delay is calculated from.
+	latencySamples = 1000
+	// latencyRecalculationInterval is the number of new samples after which the hedging delay is recalculated.
+	latencyRecalculationInterval = 100
+)
+
+type hedgedRequestKey struct{}
+
+// isHedgedRequest reports whether the request is a hedged request, issued by hedgedhttp or by the percentile hedging.
+func isHedgedRequest(req *http.Request) bool {
+	return hedgedhttp.IsHedgedRequest(req) || req.Context().Value(hedgedRequestKey{}) != nil
+}
+
+// latencyTracker keeps the latencies of the recent requests to calculate the given percentile of them.
+type latencyTracker struct {
+	percentile float64
+	delay      atomic.Duration
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	next      int
+	added     int
+}
+
+func newLatencyTracker(percentile float64) *latencyTracker {
+	return &latencyTracker{
+		percentile: percentile,
+		latencies:  make([]time.Duration, 0, latencySamples),
+	}
+}
+
+func (t *latencyTracker) observe(d time.Duration) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if len(t.latencies) < latencySamples {
+		t.latencies = append(t.latencies, d)
+	} else {
+		t.latencies[t.next] = d
+		t.next = (t.next + 1) % latencySamples
+	}
+
+	t.added++
+	if t.added%latencyRecalculationInterval != 0 {
+		return
+	}
+	sorted := make([]time.Duration, len(t.latencies))
+	copy(sorted, t.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	t.delay.Store(sorted[int(t.percentile*float64(len(sorted)-1))])
+}
+
+// Delay returns the percentile of the recent latencies, 0 until enough requests were observed.
+func (t *latencyTracker) Delay() time.Duration {
+	return t.delay.Load()
+}
+
+// routeKey identifies the kind of a request, whose latencies are tracked apart from the others: its method and the
+// names of its query parameters, which tell the object reads from the object listings but not the objects apart.
+func routeKey(req *http.Request) string {
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return req.Method + " " + strings.Join(names, "&")
+}
+
+// percentileHedgingRoundTripper issues a hedged request when a request has been outstanding for longer than
+// the given percentile of the latencies of the recent requests of the same route, with a minimum delay.
+// The latency of a request lasts until its response body was read, so that the slow reads are hedged too.
+type percentileHedgingRoundTripper struct {
+	next       http.RoundTripper
+	minDelay   time.Duration
+	upTo       int
+	percentile float64
+
+	mtx      sync.Mutex
+	trackers map[string]*latencyTracker
+}
+
+func newPercentileHedgingRoundTripper(minDelay time.Duration, upTo int, percentile float64, next http.RoundTripper) *percentileHedgingRoundTripper {
+	return &percentileHedgingRoundTripper{
+		next:       next,
+		minDelay:   minDelay,
+		upTo:       upTo,
+		percentile: percentile,
+		trackers:   map[string]*latencyTracker{},
+	}
+}
+
+// tracker returns the latency tracker of the route.
+func (rt *percentileHedgingRoundTripper) tracker(route string) *latencyTracker {
+	rt.mtx.Lock()
+	defer rt.mtx.Unlock()
+
+	t, ok := rt.trackers[route]
+	if !ok {
+		t = newLatencyTracker(rt.percentile)
+		rt.trackers[route] = t
+	}
+	return t
+}
+
+type attemptResult struct {
+	idx   int
+	start time.Time
+	resp  *http.Response
+	err   error
+}
+
+// RoundTrip returns the response of the first request to succeed, the other requests being canceled. A failed
+// request isn't hedged: no other request is sent once a request failed, and the error is returned unless a request
+// still running succeeds.
+func (rt *percentileHedgingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	tracker := rt.tracker(routeKey(req))
+
+	delay := tracker.Delay()
+	if delay < rt.minDelay {
+		delay = rt.minDelay
+	}
+
+	results := make(chan attemptResult, rt.upTo)
+	cancels := make([]context.CancelFunc, 0, rt.upTo)
+	received, winner := 0, -1
+	defer func() {
+		// Cancel the requests which lost the race and release their responses. The returned response's request
+		// is canceled once its body is closed.
+		for i, cancel := range cancels {
+			if i != winner {
+				cancel()
+			}
+		}
+		if pending := len(cancels) - received; pending > 0 {
+			go func() {
+				for i := 0; i < pending; i++ {
+					if res := <-results; res.resp != nil {
+						res.resp.Body.Close()
+					}
+				}
+			}()
+		}
+	}()
+
+	send := func() {
+		idx := len(cancels)
+		attemptCtx, cancel := context.WithCancel(ctx)
+		if idx > 0 {
+			attemptCtx = context.WithValue(attemptCtx, hedgedRequestKey{}, struct{}{})
+		}
+		cancels = append(cancels, cancel)
+
+		attempt := req.WithContext(attemptCtx)
+		go func() {
+			start := time.Now()
+			resp, err := rt.next.RoundTrip(attempt)
+			results <- attemptResult{idx: idx, start: start, resp: resp, err: err}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var err error
+	for received < len(cancels) {
+		select {
+		case res := <-results:
+			received++
+			if res.err == nil {
+				winner = res.idx
+				res.resp.Body = newTrackedBody(res.resp.Body, res.start, tracker, cancels[winner])
+				return res.resp, nil
+			}
+			// The error of the original request prevails over the ones of the hedged requests, i.e. when rate limited.
+			if err == nil || res.idx == 0 {
+				err = res.err
+			}
+		case <-timer.C:
+			if err == nil && len(cancels) < rt.upTo {
+				send()
+				timer.Reset(delay)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+// trackedBody observes the latency of the request once its response body was read, and cancels the request once
+// it's closed.
+type trackedBody struct {
+	io.ReadCloser
+	start   time.Time
+	tracker *latencyTracker
+	cancel  context.CancelFunc
+	once    sync.Once
+}
+
+func newTrackedBody(body io.ReadCloser, start time.Time, tracker *latencyTracker, cancel context.CancelFunc) *trackedBody {
+	b := &trackedBody{ReadCloser: body, start: start, tracker: tracker, cancel: cancel}
+	if body == http.NoBody {
+		b.observe()
+	}
+	return b
+}
+
+func (b *trackedBody) observe() {
+	b.once.Do(func() {
+		b.tracker.observe(time.Since(b.start))
+	})
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.observe()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,159 @@
+package hedging
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := newLatencyTracker(0.9)
+	for i := 1; i < latencyRecalculationInterval; i++ {
+		tracker.observe(time.Duration(i) * time.Millisecond)
+	}
+	// Not enough requests were observed yet.
+	require.Zero(t, tracker.Delay())
+
+	tracker.observe(latencyRecalculationInterval * time.Millisecond)
+	require.Equal(t, 90*time.Millisecond, tracker.Delay())
+
+	// Only the recent latencies are accounted for.
+	for i := 0; i < latencySamples; i++ {
+		tracker.observe(time.Second)
+	}
+	require.Equal(t, time.Second, tracker.Delay())
+}
+
+func TestPercentileHedging(t *testing.T) {
+	resetMetrics()
+	cfg := &Config{
+		At:           10 * time.Millisecond,
+		UpTo:         3,
+		MaxPerSecond: 1000,
+		AtPercentile: 0.99,
+	}
+	count := atomic.NewInt32(0)
+	client, err := cfg.Client(&http.Client{
+		Transport: RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			// Only the first request is slow.
+			if count.Inc() == 1 {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+					return nil, r.Context().Err()
+				}
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("hedged")),
+			}, nil
+		}),
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.Get("http://example.com")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hedged", string(body))
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+
+	require.Equal(t, int32(2), count.Load())
+	require.NoError(t, testutil.GatherAndCompare(prometheus.DefaultGatherer,
+		strings.NewReader(`
+# HELP hedged_requests_total The total number of hedged requests.
+# TYPE hedged_requests_total counter
+hedged_requests_total 1
+`,
+		), "hedged_requests_total"))
+}
+
+func TestPercentileHedgingDelay(t *testing.T) {
+	count := atomic.NewInt32(0)
+	rt := newPercentileHedgingRoundTripper(time.Millisecond, 2, 0.5, RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		count.Inc()
+		time.Sleep(20 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+
+	get, err := http.NewRequest(http.MethodGet, "http://example.com/object", nil)
+	require.NoError(t, err)
+	list, err := http.NewRequest(http.MethodGet, "http://example.com/?list-type=2&prefix=index", nil)
+	require.NoError(t, err)
+
+	// The recent reads took 100ms, so reads of 20ms aren't hedged.
+	for i := 0; i < latencyRecalculationInterval; i++ {
+		rt.tracker(routeKey(get)).observe(100 * time.Millisecond)
+		rt.tracker(routeKey(list)).observe(time.Millisecond)
+	}
+	_, err = rt.RoundTrip(get)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), count.Load())
+
+	// The latencies of the listings are tracked apart, and they are hedged.
+	_, err = rt.RoundTrip(list)
+	require.NoError(t, err)
+	require.Equal(t, int32(3), count.Load())
+}
+
+func TestPercentileHedgingErrors(t *testing.T) {
+	count := atomic.NewInt32(0)
+	rt := newPercentileHedgingRoundTripper(10*time.Millisecond, 3, 0.5, RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		count.Inc()
+		return nil, errors.New("failed")
+	}))
+
+	// The failed requests aren't hedged.
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.EqualError(t, err, "failed")
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), count.Load())
+}
+
+func TestPercentileHedgingCancel(t *testing.T) {
+	canceled := make(chan int, 2)
+	count := atomic.NewInt32(0)
+	rt := newPercentileHedgingRoundTripper(10*time.Millisecond, 2, 0.5, RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		idx := int(count.Inc())
+		go func() {
+			<-r.Context().Done()
+			canceled <- idx
+		}()
+		// Only the first request is slow.
+		if idx == 1 {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("hedged"))}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	// The request which lost is canceled, the one which won once its response was read.
+	require.Equal(t, 1, <-canceled)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hedged", string(body))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 2, <-canceled)
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, (&Config{AtPercentile: 0.99}).Validate())
+	require.Error(t, (&Config{AtPercentile: 1}).Validate())
+	require.Error(t, (&Config{AtPercentile: -0.5}).Validate())
+}
@@ -8,9 +8,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/grafana/dskit/concurrency"
 	"github.com/pkg/errors"
+	"github.com/weaveworks/common/user"
 
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/pkg/storage/config"
 )
@@ -81,6 +84,7 @@ type client struct {
 	keyEncoder          KeyEncoder
 	getChunkMaxParallel int
 	schema              config.SchemaConfig
+	bloomFilters        bool
 }
 
 // NewClient wraps the provided ObjectClient with a chunk.Client implementation
@@ -97,6 +101,19 @@ func NewClientWithMaxParallel(store ObjectClient, encoder KeyEncoder, maxParalle
 	}
 }
 
+// NewClientWithBloomFilters is like NewClientWithMaxParallel, but the returned client
+// also stores a bloom filter of the n-grams of its lines alongside each chunk, and
+// implements BloomFilterClient to retrieve them.
+func NewClientWithBloomFilters(store ObjectClient, encoder KeyEncoder, maxParallel int, schema config.SchemaConfig) Client {
+	return &client{
+		store:               store,
+		keyEncoder:          encoder,
+		getChunkMaxParallel: maxParallel,
+		schema:              schema,
+		bloomFilters:        true,
+	}
+}
+
 // Stop shuts down the object store and any underlying clients
 func (o *client) Stop() {
 	o.store.Stop()
@@ -106,8 +123,9 @@ func (o *client) Stop() {
 // returned, the last one sequentially will be propagated up.
 func (o *client) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
 	var (
-		chunkKeys []string
-		chunkBufs [][]byte
+		chunkKeys    []string
+		chunkBufs    [][]byte
+		chunkUserIDs []string
 	)
 
 	for i := range chunks {
@@ -116,21 +134,31 @@ func (o *client) PutChunks(ctx context.Context, chunks []chunk.Chunk) error {
 			return err
 		}
 
-		var key string
-		if o.keyEncoder != nil {
-			key = o.keyEncoder(o.schema, chunks[i])
-		} else {
-			key = o.schema.ExternalKey(chunks[i].ChunkRef)
-		}
-
+		key := o.objectKey(chunks[i])
 		chunkKeys = append(chunkKeys, key)
 		chunkBufs = append(chunkBufs, buf)
+		chunkUserIDs = append(chunkUserIDs, chunks[i].UserID)
+
+		if o.bloomFilters {
+			filter, err := bloom.FromChunk(chunks[i])
+			if err != nil {
+				return errors.Wrap(err, "failed to build chunk bloom filter")
+			}
+			buf, err := filter.Encode()
+			if err != nil {
+				return err
+			}
+			chunkKeys = append(chunkKeys, bloom.Key(key))
+			chunkBufs = append(chunkBufs, buf)
+			chunkUserIDs = append(chunkUserIDs, chunks[i].UserID)
+		}
 	}
 
 	incomingErrors := make(chan error)
 	for i := range chunkBufs {
 		go func(i int) {
-			incomingErrors <- o.store.PutObject(ctx, chunkKeys[i], bytes.NewReader(chunkBufs[i]))
+			// The tenant allows object stores to apply per-tenant settings, e.g. S3 server-side encryption.
+			incomingErrors <- o.store.PutObject(user.InjectOrgID(ctx, chunkUserIDs[i]), chunkKeys[i], bytes.NewReader(chunkBufs[i]))
 		}(i)
 	}
 
@@ -158,12 +186,7 @@ func (o *client) getChunk(ctx context.Context, decodeContext *chunk.DecodeContex
 		return chunk.Chunk{}, ctx.Err()
 	}
 
-	key := o.schema.ExternalKey(c.ChunkRef)
-	if o.keyEncoder != nil {
-		key = o.keyEncoder(o.schema, c)
-	}
-
-	readCloser, size, err := o.store.GetObject(ctx, key)
+	readCloser, size, err := o.store.GetObject(user.InjectOrgID(ctx, c.UserID), o.objectKey(c))
 	if err != nil {
 		return chunk.Chunk{}, errors.WithStack(err)
 	}
@@ -197,9 +220,54 @@ func (o *client) DeleteChunk(ctx context.Context, userID, chunkID string) error
 		}
 		key = o.keyEncoder(o.schema, c)
 	}
-	return o.store.DeleteObject(ctx, key)
+	if err := o.store.DeleteObject(ctx, key); err != nil {
+		return err
+	}
+	if o.bloomFilters {
+		if err := o.store.DeleteObject(ctx, bloom.Key(key)); err != nil && !o.store.IsObjectNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBloomFilters retrieves the encoded bloom filters stored alongside the specified chunks.
+// The filter of a chunk which has none is empty.
+func (o *client) GetBloomFilters(ctx context.Context, chunks []chunk.Chunk) ([][]byte, error) {
+	bufs := make([][]byte, len(chunks))
+	if !o.bloomFilters {
+		return bufs, nil
+	}
+	maxParallel := o.getChunkMaxParallel
+	if maxParallel == 0 {
+		maxParallel = defaultMaxParallel
+	}
+	err := concurrency.ForEachJob(ctx, len(chunks), maxParallel, func(ctx context.Context, i int) error {
+		readCloser, _, err := o.store.GetObject(ctx, bloom.Key(o.objectKey(chunks[i])))
+		if err != nil {
+			if o.store.IsObjectNotFoundErr(err) {
+				return nil
+			}
+			return errors.WithStack(err)
+		}
+		defer readCloser.Close()
+
+		bufs[i], err = io.ReadAll(readCloser)
+		return errors.WithStack(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bufs, nil
 }
 
 func (o *client) IsChunkNotFoundErr(err error) bool {
 	return o.store.IsObjectNotFoundErr(err)
 }
+
+func (o *client) objectKey(c chunk.Chunk) string {
+	if o.keyEncoder != nil {
+		return o.keyEncoder(o.schema, c)
+	}
+	return o.schema.ExternalKey(c.ChunkRef)
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/bloom"
 	"github.com/grafana/loki/pkg/storage/chunk/cache"
 	"github.com/grafana/loki/pkg/storage/chunk/client"
 	"github.com/grafana/loki/pkg/storage/config"
@@ -38,6 +39,11 @@ var (
 		Name:      "cache_corrupt_chunks_total",
 		Help:      "Total count of corrupt chunks found in cache.",
 	})
+	bloomFilterSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "chunk_fetcher_bloom_filter_skipped_chunks_total",
+		Help:      "Total number of chunks skipped because their bloom filter showed they can't match the line filters of a query.",
+	})
 )
 
 const chunkDecodeParallelism = 16
@@ -211,6 +217,93 @@ func (c *Fetcher) FetchChunks(ctx context.Context, chunks []chunk.Chunk, keys []
 	return allChunks, nil
 }
 
+// FilterChunksByBloom drops the chunks whose bloom filter shows that none of their lines
+// contains every one of the given strings. The chunks whose bloom filter can't be retrieved,
+// i.e. because they have none, are kept, and so are all chunks if the storage client doesn't
+// support bloom filters, as they are only an optimisation.
+func (c *Fetcher) FilterChunksByBloom(ctx context.Context, chunks []chunk.Chunk, contains []string) []chunk.Chunk {
+	bloomClient, ok := c.storage.(client.BloomFilterClient)
+	if !ok || len(contains) == 0 || len(chunks) == 0 {
+		return chunks
+	}
+	log, ctx := spanlogger.New(ctx, "ChunkStore.FilterChunksByBloom")
+	defer log.Span.Finish()
+
+	filters := c.fetchBloomFilters(ctx, log, bloomClient, chunks)
+	filtered := make([]chunk.Chunk, 0, len(chunks))
+	for i, f := range filters {
+		if f != nil && !f.MayContainAll(contains) {
+			continue
+		}
+		filtered = append(filtered, chunks[i])
+	}
+	bloomFilterSkipped.Add(float64(len(chunks) - len(filtered)))
+	level.Debug(log).Log("msg", "filtered chunks by bloom filters", "chunks", len(chunks), "skipped", len(chunks)-len(filtered))
+	return filtered
+}
+
+// fetchBloomFilters returns the bloom filters of the chunks, from the cache or else from the store, writing those
+// fetched from the store back to the cache. Chunks without bloom filter are cached as such, so that they aren't
+// looked up in the store again. The filter of a chunk is nil when it has none, or it can't be retrieved.
+func (c *Fetcher) fetchBloomFilters(ctx context.Context, log *spanlogger.SpanLogger, bloomClient client.BloomFilterClient, chunks []chunk.Chunk) []*bloom.Filter {
+	filters := make([]*bloom.Filter, len(chunks))
+	keys := make([]string, len(chunks))
+	indexes := make(map[string]int, len(chunks))
+	for i, chk := range chunks {
+		keys[i] = bloom.Key(c.schema.ExternalKey(chk.ChunkRef))
+		indexes[keys[i]] = i
+	}
+
+	missing := keys
+	if !c.cacheStubs {
+		found, bufs, notFound, err := c.cache.Fetch(ctx, keys)
+		if err != nil {
+			level.Warn(log).Log("msg", "error fetching chunk bloom filters from cache", "err", err)
+			found, bufs, notFound = nil, nil, keys
+		}
+		missing = notFound
+		for i, key := range found {
+			filters[indexes[key]] = decodeBloomFilter(log, key, bufs[i])
+		}
+	}
+	if len(missing) == 0 {
+		return filters
+	}
+
+	missingChunks := make([]chunk.Chunk, 0, len(missing))
+	for _, key := range missing {
+		missingChunks = append(missingChunks, chunks[indexes[key]])
+	}
+	bufs, err := bloomClient.GetBloomFilters(ctx, missingChunks)
+	if err != nil {
+		level.Warn(log).Log("msg", "error fetching chunk bloom filters", "err", err)
+		return filters
+	}
+	for i, key := range missing {
+		filters[indexes[key]] = decodeBloomFilter(log, key, bufs[i])
+	}
+
+	if !c.cacheStubs {
+		if err := c.cache.Store(ctx, missing, bufs); err != nil {
+			level.Warn(log).Log("msg", "could not store chunk bloom filters in cache", "err", err)
+		}
+	}
+	return filters
+}
+
+// decodeBloomFilter decodes the bloom filter stored under key, which is nil if the chunk has none or it's invalid.
+func decodeBloomFilter(log *spanlogger.SpanLogger, key string, buf []byte) *bloom.Filter {
+	if len(buf) == 0 {
+		return nil
+	}
+	f, err := bloom.Decode(buf)
+	if err != nil {
+		level.Warn(log).Log("msg", "invalid chunk bloom filter", "key", key, "err", err)
+		return nil
+	}
+	return f
+}
+
 func (c *Fetcher) WriteBackCache(ctx context.Context, chunks []chunk.Chunk) error {
 	keys := make([]string, 0, len(chunks))
 	bufs := make([][]byte, 0, len(chunks))
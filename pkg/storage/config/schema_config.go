@@ -22,9 +22,11 @@ import (
 const (
 	// Supported storage clients
 
+	StorageTypeAlibabaCloud   = "alibabacloud"
 	StorageTypeAWS            = "aws"
 	StorageTypeAWSDynamo      = "aws-dynamo"
 	StorageTypeAzure          = "azure"
+	StorageTypeBackblaze      = "backblaze"
 	StorageTypeBOS            = "bos"
 	StorageTypeBoltDB         = "boltdb"
 	StorageTypeCassandra      = "cassandra"
@@ -56,7 +58,7 @@ var (
 
 	errCurrentBoltdbShipperNon24Hours  = errors.New("boltdb-shipper works best with 24h periodic index config. Either add a new config with future date set to 24h to retain the existing index or change the existing config to use 24h period")
 	errUpcomingBoltdbShipperNon24Hours = errors.New("boltdb-shipper with future date must always have periodic config for index set to 24h")
-	errTSDBNon24HoursIndexPeriod       = errors.New("tsdb must always have periodic config for index set to 24h")
+	errTSDBNoIndexPeriod               = errors.New("tsdb must always have a periodic config for index set to a multiple of 24h")
 	errZeroLengthConfig                = errors.New("must specify at least one schema configuration")
 )
 
@@ -307,8 +309,8 @@ func (cfg PeriodConfig) validate() error {
 		return validateError
 	}
 
-	if cfg.IndexType == TSDBType && cfg.IndexTables.Period != ObjectStorageIndexRequiredPeriod {
-		return errTSDBNon24HoursIndexPeriod
+	if cfg.IndexType == TSDBType && cfg.IndexTables.Period <= 0 {
+		return errTSDBNoIndexPeriod
 	}
 
 	// Ensure the tables period is a multiple of the bucket period
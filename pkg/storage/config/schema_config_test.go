@@ -421,6 +421,25 @@ func TestPeriodConfig_Validate(t *testing.T) {
 				ChunkTables: PeriodicTableConfig{Period: 0},
 			},
 		},
+		{
+			desc: "tsdb with 7d index period",
+			in: PeriodConfig{
+				Schema:      "v12",
+				RowShards:   16,
+				IndexType:   TSDBType,
+				IndexTables: PeriodicTableConfig{Period: 7 * 24 * time.Hour},
+			},
+		},
+		{
+			desc: "error tsdb without index period",
+			in: PeriodConfig{
+				Schema:      "v12",
+				RowShards:   16,
+				IndexType:   TSDBType,
+				IndexTables: PeriodicTableConfig{Period: 0},
+			},
+			err: errTSDBNoIndexPeriod.Error(),
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			if tc.err == "" {
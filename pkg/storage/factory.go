@@ -11,12 +11,16 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/grafana/loki/pkg/storage/bucket"
 	"github.com/grafana/loki/pkg/storage/chunk/cache"
 	"github.com/grafana/loki/pkg/storage/chunk/client"
+	"github.com/grafana/loki/pkg/storage/chunk/client/alibaba"
 	"github.com/grafana/loki/pkg/storage/chunk/client/aws"
 	"github.com/grafana/loki/pkg/storage/chunk/client/azure"
+	"github.com/grafana/loki/pkg/storage/chunk/client/backblaze"
 	"github.com/grafana/loki/pkg/storage/chunk/client/baidubce"
 	"github.com/grafana/loki/pkg/storage/chunk/client/cassandra"
+	"github.com/grafana/loki/pkg/storage/chunk/client/encryption"
 	"github.com/grafana/loki/pkg/storage/chunk/client/gcp"
 	"github.com/grafana/loki/pkg/storage/chunk/client/grpc"
 	"github.com/grafana/loki/pkg/storage/chunk/client/hedging"
@@ -29,6 +33,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/gatewayclient"
 	"github.com/grafana/loki/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/pkg/storage/stores/shipper"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -49,7 +54,8 @@ func ResetBoltDBIndexClientWithShipper() {
 
 // StoreLimits helps get Limits specific to Queries for Stores
 type StoreLimits interface {
-	downloads.Limits
+	tsdb.Limits
+	bucket.TenantConfigProvider
 	CardinalityLimit(userID string) int
 	MaxChunksPerQueryFromStore(userID string) int
 	MaxQueryLength(userID string) time.Duration
@@ -57,8 +63,10 @@ type StoreLimits interface {
 
 // Config chooses which storage client to use.
 type Config struct {
+	AlibabaStorageConfig   alibaba.OssConfig         `yaml:"alibabacloud"`
 	AWSStorageConfig       aws.StorageConfig         `yaml:"aws"`
 	AzureStorageConfig     azure.BlobStorageConfig   `yaml:"azure"`
+	BackblazeConfig        backblaze.B2Config        `yaml:"backblaze"`
 	BOSStorageConfig       baidubce.BOSStorageConfig `yaml:"bos"`
 	GCPStorageConfig       gcp.Config                `yaml:"bigtable"`
 	GCSConfig              gcp.GCSConfig             `yaml:"gcs"`
@@ -68,6 +76,8 @@ type Config struct {
 	Swift                  openstack.SwiftConfig     `yaml:"swift"`
 	GrpcConfig             grpc.Config               `yaml:"grpc_store"`
 	Hedging                hedging.Config            `yaml:"hedging"`
+	NamedStores            NamedStores               `yaml:"named_stores"`
+	Encryption             encryption.Config         `yaml:"encryption"`
 
 	IndexCacheValidity time.Duration `yaml:"index_cache_validity"`
 
@@ -75,9 +85,10 @@ type Config struct {
 	DisableBroadIndexQueries bool         `yaml:"disable_broad_index_queries"`
 	MaxParallelGetChunk      int          `yaml:"max_parallel_get_chunk"`
 
-	MaxChunkBatchSize   int                 `yaml:"max_chunk_batch_size"`
-	BoltDBShipperConfig shipper.Config      `yaml:"boltdb_shipper"`
-	TSDBShipperConfig   indexshipper.Config `yaml:"tsdb_shipper"`
+	MaxChunkBatchSize   int            `yaml:"max_chunk_batch_size"`
+	ChunkBloomFilters   bool           `yaml:"chunk_bloom_filters"`
+	BoltDBShipperConfig shipper.Config `yaml:"boltdb_shipper"`
+	TSDBShipperConfig   tsdb.IndexCfg  `yaml:"tsdb_shipper"`
 
 	// Config for using AsyncStore when using async index stores like `boltdb-shipper`.
 	// It is required for getting chunk ids of recently flushed chunks from the ingesters.
@@ -87,8 +98,10 @@ type Config struct {
 
 // RegisterFlags adds the flags required to configure this flag set.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.AlibabaStorageConfig.RegisterFlags(f)
 	cfg.AWSStorageConfig.RegisterFlags(f)
 	cfg.AzureStorageConfig.RegisterFlags(f)
+	cfg.BackblazeConfig.RegisterFlags(f)
 	cfg.BOSStorageConfig.RegisterFlags(f)
 	cfg.GCPStorageConfig.RegisterFlags(f)
 	cfg.GCSConfig.RegisterFlags(f)
@@ -98,6 +111,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Swift.RegisterFlags(f)
 	cfg.GrpcConfig.RegisterFlags(f)
 	cfg.Hedging.RegisterFlagsWithPrefix("store.", f)
+	cfg.Encryption.RegisterFlagsWithPrefix("store.", f)
 
 	cfg.IndexQueriesCacheConfig.RegisterFlagsWithPrefix("store.index-cache-read.", "Cache config for index entry reading.", f)
 	f.DurationVar(&cfg.IndexCacheValidity, "store.index-cache-validity", 5*time.Minute, "Cache validity for active index entries. Should be no higher than -ingester.max-chunk-idle.")
@@ -105,6 +119,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxParallelGetChunk, "store.max-parallel-get-chunk", 150, "Maximum number of parallel chunk reads.")
 	cfg.BoltDBShipperConfig.RegisterFlags(f)
 	f.IntVar(&cfg.MaxChunkBatchSize, "store.max-chunk-batch-size", 50, "The maximum number of chunks to fetch per batch.")
+	f.BoolVar(&cfg.ChunkBloomFilters, "store.chunk-bloom-filters", false, "Store a bloom filter of the n-grams of its lines alongside each chunk written to an object store, and use them to skip chunks which can't match the line filters of queries.")
 	cfg.TSDBShipperConfig.RegisterFlagsWithPrefix("tsdb.", f)
 }
 
@@ -134,6 +149,20 @@ func (cfg *Config) Validate() error {
 	if err := cfg.TSDBShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid tsdb config")
 	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid encryption config")
+	}
+	if err := cfg.Hedging.Validate(); err != nil {
+		return errors.Wrap(err, "invalid hedging config")
+	}
+	if err := cfg.NamedStores.Validate(); err != nil {
+		return errors.Wrap(err, "invalid named stores config")
+	}
+	for _, store := range append(cfg.BoltDBShipperConfig.ReplicationStores, cfg.TSDBShipperConfig.ReplicationStores...) {
+		if _, _, ok := cfg.NamedStores.storeConfig(store, *cfg); !ok {
+			return fmt.Errorf("replication store %s is not configured under named_stores", store)
+		}
+	}
 	return nil
 }
 
@@ -180,14 +209,20 @@ func NewIndexClient(name string, cfg Config, schemaCfg config.SchemaConfig, limi
 			return gateway, nil
 		}
 
-		objectClient, err := NewObjectClient(cfg.BoltDBShipperConfig.SharedStoreType, cfg, cm)
+		objectClient, err := NewObjectClient(cfg.BoltDBShipperConfig.SharedStoreType, cfg, limits, cm)
+		if err != nil {
+			return nil, err
+		}
+
+		shipperCfg := cfg.BoltDBShipperConfig
+		shipperCfg.ReplicaObjectClients, err = newIndexReplicaClients(shipperCfg.Config, cfg, limits, cm)
 		if err != nil {
 			return nil, err
 		}
 
 		tableRanges := getIndexStoreTableRanges(config.BoltDBShipperType, schemaCfg.Configs)
 
-		boltDBIndexClientWithShipper, err = shipper.NewShipper(cfg.BoltDBShipperConfig, objectClient, limits,
+		boltDBIndexClientWithShipper, err = shipper.NewShipper(shipperCfg, objectClient, limits,
 			ownsTenantFn, tableRanges, registerer)
 
 		return boltDBIndexClientWithShipper, err
@@ -196,17 +231,31 @@ func NewIndexClient(name string, cfg Config, schemaCfg config.SchemaConfig, limi
 	}
 }
 
-// NewChunkClient makes a new chunk.Client of the desired types.
-func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clientMetrics ClientMetrics, registerer prometheus.Registerer) (client.Client, error) {
+// newObjectChunkClient wraps an object client with a chunk.Client, which also stores
+// chunk bloom filters when they are enabled, and encrypts chunks when encryption is enabled.
+func newObjectChunkClient(c client.ObjectClient, encoder client.KeyEncoder, maxParallel int, cfg Config, schemaCfg config.SchemaConfig) (client.Client, error) {
+	c, err := encryption.WrapObjectClient(c, cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ChunkBloomFilters {
+		return client.NewClientWithBloomFilters(c, encoder, maxParallel, schemaCfg), nil
+	}
+	return client.NewClientWithMaxParallel(c, encoder, maxParallel, schemaCfg), nil
+}
+
+// NewChunkClient makes a new chunk.Client of the desired types. tenantConfigs provides the per tenant overrides of the
+// object store settings, e.g. the S3 server-side encryption, it can be nil.
+func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, tenantConfigs bucket.TenantConfigProvider, clientMetrics ClientMetrics, registerer prometheus.Registerer) (client.Client, error) {
 	switch name {
 	case config.StorageTypeInMemory:
 		return testutils.NewMockStorage(), nil
 	case config.StorageTypeAWS, config.StorageTypeS3:
-		c, err := aws.NewS3ObjectClient(cfg.AWSStorageConfig.S3Config, cfg.Hedging)
+		c, err := aws.NewS3ObjectClient(cfg.AWSStorageConfig.S3Config, cfg.Hedging, tenantConfigs)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(c, nil, cfg.MaxParallelGetChunk, cfg, schemaCfg)
 	case config.StorageTypeAWSDynamo:
 		if cfg.AWSStorageConfig.DynamoDB.URL == nil {
 			return nil, fmt.Errorf("Must set -dynamodb.url in aws mode")
@@ -221,13 +270,25 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(c, nil, cfg.MaxParallelGetChunk, cfg, schemaCfg)
 	case config.StorageTypeBOS:
 		c, err := baidubce.NewBOSObjectStorage(&cfg.BOSStorageConfig)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxChunkBatchSize, schemaCfg), nil
+		return newObjectChunkClient(c, nil, cfg.MaxChunkBatchSize, cfg, schemaCfg)
+	case config.StorageTypeAlibabaCloud:
+		c, err := alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaStorageConfig)
+		if err != nil {
+			return nil, err
+		}
+		return newObjectChunkClient(c, nil, cfg.MaxChunkBatchSize, cfg, schemaCfg)
+	case config.StorageTypeBackblaze:
+		c, err := backblaze.NewB2ObjectClient(context.Background(), cfg.BackblazeConfig)
+		if err != nil {
+			return nil, err
+		}
+		return newObjectChunkClient(c, nil, cfg.MaxChunkBatchSize, cfg, schemaCfg)
 	case config.StorageTypeGCP:
 		return gcp.NewBigtableObjectClient(context.Background(), cfg.GCPStorageConfig, schemaCfg)
 	case config.StorageTypeGCPColumnKey, config.StorageTypeBigTable, config.StorageTypeBigTableHashed:
@@ -237,13 +298,13 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(c, nil, cfg.MaxParallelGetChunk, cfg, schemaCfg)
 	case config.StorageTypeSwift:
 		c, err := openstack.NewSwiftObjectClient(cfg.Swift, cfg.Hedging)
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(c, nil, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(c, nil, cfg.MaxParallelGetChunk, cfg, schemaCfg)
 	case config.StorageTypeCassandra:
 		return cassandra.NewObjectClient(cfg.CassandraStorageConfig, schemaCfg, registerer, cfg.MaxParallelGetChunk)
 	case config.StorageTypeFileSystem:
@@ -251,7 +312,7 @@ func NewChunkClient(name string, cfg Config, schemaCfg config.SchemaConfig, clie
 		if err != nil {
 			return nil, err
 		}
-		return client.NewClientWithMaxParallel(store, client.FSEncoder, cfg.MaxParallelGetChunk, schemaCfg), nil
+		return newObjectChunkClient(store, client.FSEncoder, cfg.MaxParallelGetChunk, cfg, schemaCfg)
 	case config.StorageTypeGrpc:
 		return grpc.NewStorageClient(cfg.GrpcConfig, schemaCfg)
 	default:
@@ -282,7 +343,8 @@ func NewTableClient(name string, cfg Config, cm ClientMetrics, registerer promet
 	case config.StorageTypeGrpc:
 		return grpc.NewTableClient(cfg.GrpcConfig)
 	case config.BoltDBShipperType, config.TSDBType:
-		objectClient, err := NewObjectClient(cfg.BoltDBShipperConfig.SharedStoreType, cfg, cm)
+		// The table clients only list and delete objects.
+		objectClient, err := NewObjectClient(cfg.BoltDBShipperConfig.SharedStoreType, cfg, nil, cm)
 		if err != nil {
 			return nil, err
 		}
@@ -329,11 +391,50 @@ func (c *ClientMetrics) Unregister() {
 	c.AzureMetrics.Unregister()
 }
 
-// NewObjectClient makes a new StorageClient of the desired types.
-func NewObjectClient(name string, cfg Config, clientMetrics ClientMetrics) (client.ObjectClient, error) {
+// newIndexReplicaClients creates the object clients for the named stores the index shipper replicates uploads to.
+func newIndexReplicaClients(shipperCfg indexshipper.Config, cfg Config, tenantConfigs bucket.TenantConfigProvider, clientMetrics ClientMetrics) ([]client.ObjectClient, error) {
+	if shipperCfg.Mode == indexshipper.ModeReadOnly {
+		return nil, nil
+	}
+
+	replicas := make([]client.ObjectClient, 0, len(shipperCfg.ReplicationStores))
+	for _, store := range shipperCfg.ReplicationStores {
+		storeType, storeCfg, ok := cfg.NamedStores.storeConfig(store, cfg)
+		if !ok {
+			err := fmt.Errorf("replication store %s is not configured under named_stores", store)
+			for _, r := range replicas {
+				r.Stop()
+			}
+			return nil, err
+		}
+
+		replica, err := NewObjectClient(storeType, storeCfg, tenantConfigs, clientMetrics)
+		if err != nil {
+			for _, r := range replicas {
+				r.Stop()
+			}
+			return nil, errors.Wrapf(err, "error creating object client for replication store %s", store)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
+
+// NewObjectClient makes a new StorageClient of the desired types, which encrypts objects when encryption is enabled.
+// tenantConfigs provides the per tenant overrides of the object store settings, e.g. the S3 server-side encryption,
+// it can be nil.
+func NewObjectClient(name string, cfg Config, tenantConfigs bucket.TenantConfigProvider, clientMetrics ClientMetrics) (client.ObjectClient, error) {
+	c, err := newObjectClient(name, cfg, tenantConfigs, clientMetrics)
+	if err != nil {
+		return nil, err
+	}
+	return encryption.WrapObjectClient(c, cfg.Encryption)
+}
+
+func newObjectClient(name string, cfg Config, tenantConfigs bucket.TenantConfigProvider, clientMetrics ClientMetrics) (client.ObjectClient, error) {
 	switch name {
 	case config.StorageTypeAWS, config.StorageTypeS3:
-		return aws.NewS3ObjectClient(cfg.AWSStorageConfig.S3Config, cfg.Hedging)
+		return aws.NewS3ObjectClient(cfg.AWSStorageConfig.S3Config, cfg.Hedging, tenantConfigs)
 	case config.StorageTypeGCS:
 		return gcp.NewGCSObjectClient(context.Background(), cfg.GCSConfig, cfg.Hedging)
 	case config.StorageTypeAzure:
@@ -346,6 +447,10 @@ func NewObjectClient(name string, cfg Config, clientMetrics ClientMetrics) (clie
 		return local.NewFSObjectClient(cfg.FSConfig)
 	case config.StorageTypeBOS:
 		return baidubce.NewBOSObjectStorage(&cfg.BOSStorageConfig)
+	case config.StorageTypeAlibabaCloud:
+		return alibaba.NewOssObjectClient(context.Background(), cfg.AlibabaStorageConfig)
+	case config.StorageTypeBackblaze:
+		return backblaze.NewB2ObjectClient(context.Background(), cfg.BackblazeConfig)
 	default:
 		return nil, fmt.Errorf("Unrecognized storage client %v, choose one of: %v, %v, %v, %v, %v", name, config.StorageTypeAWS, config.StorageTypeS3, config.StorageTypeGCS, config.StorageTypeAzure, config.StorageTypeFileSystem)
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/loki/pkg/storage/chunk/client/cassandra"
 	"github.com/grafana/loki/pkg/storage/config"
@@ -106,3 +107,44 @@ func DefaultSchemaConfig(store, schema string, from model.Time) config.SchemaCon
 	}
 	return s
 }
+
+func TestNamedStores(t *testing.T) {
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, yaml.UnmarshalStrict([]byte(`
+named_stores:
+  aws:
+    dr-region:
+      s3: s3://eu-west-1/dr-bucket
+  filesystem:
+    local-copy:
+      directory: /tmp/replica
+tsdb_shipper:
+  replication_stores: dr-region,local-copy
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+
+	// named stores default to the defaults of their type
+	require.Equal(t, cfg.AWSStorageConfig.S3Config.HTTPConfig, cfg.NamedStores.AWS["dr-region"].S3Config.HTTPConfig)
+
+	storeType, storeCfg, ok := cfg.NamedStores.storeConfig("dr-region", cfg)
+	require.True(t, ok)
+	require.Equal(t, config.StorageTypeAWS, storeType)
+	require.Equal(t, "s3://eu-west-1/dr-bucket", storeCfg.AWSStorageConfig.S3Config.S3.String())
+
+	// multiple stores of the same type can be replicated to
+	cfg = Config{NamedStores: NamedStores{
+		Filesystem: map[string]NamedFSConfig{"a": {Directory: t.TempDir()}, "b": {Directory: t.TempDir()}},
+	}}
+	cfg.TSDBShipperConfig.ReplicationStores = []string{"a", "b"}
+	replicas, err := newIndexReplicaClients(cfg.TSDBShipperConfig.Config, cfg, nil, cm)
+	require.NoError(t, err)
+	require.Len(t, replicas, 2)
+
+	cfg.TSDBShipperConfig.ReplicationStores = []string{"a", "unknown"}
+	_, err = newIndexReplicaClients(cfg.TSDBShipperConfig.Config, cfg, nil, cm)
+	require.Error(t, err)
+
+	cfg.NamedStores.AWS = map[string]NamedAWSStorageConfig{"a": {}}
+	require.Error(t, cfg.NamedStores.Validate())
+}
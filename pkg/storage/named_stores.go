@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client/aws"
+	"github.com/grafana/loki/pkg/storage/chunk/client/azure"
+	"github.com/grafana/loki/pkg/storage/chunk/client/gcp"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/chunk/client/openstack"
+	"github.com/grafana/loki/pkg/storage/config"
+)
+
+// NamedStores are object store configs referenced by their name in place of a store type,
+// which allows configuring multiple stores of the same type, e.g. for replicating the index
+// to a bucket in another region. Each config defaults to the defaults of its store type.
+type NamedStores struct {
+	AWS        map[string]NamedAWSStorageConfig  `yaml:"aws"`
+	Azure      map[string]NamedBlobStorageConfig `yaml:"azure"`
+	GCS        map[string]NamedGCSConfig         `yaml:"gcs"`
+	Filesystem map[string]NamedFSConfig          `yaml:"filesystem"`
+	Swift      map[string]NamedSwiftConfig       `yaml:"swift"`
+}
+
+type NamedAWSStorageConfig aws.StorageConfig
+
+func (cfg *NamedAWSStorageConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*aws.StorageConfig)(cfg))
+	return unmarshal((*aws.StorageConfig)(cfg))
+}
+
+type NamedBlobStorageConfig azure.BlobStorageConfig
+
+func (cfg *NamedBlobStorageConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*azure.BlobStorageConfig)(cfg))
+	return unmarshal((*azure.BlobStorageConfig)(cfg))
+}
+
+type NamedGCSConfig gcp.GCSConfig
+
+func (cfg *NamedGCSConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*gcp.GCSConfig)(cfg))
+	return unmarshal((*gcp.GCSConfig)(cfg))
+}
+
+type NamedFSConfig local.FSConfig
+
+func (cfg *NamedFSConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*local.FSConfig)(cfg))
+	return unmarshal((*local.FSConfig)(cfg))
+}
+
+type NamedSwiftConfig openstack.SwiftConfig
+
+func (cfg *NamedSwiftConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	flagext.DefaultValues((*openstack.SwiftConfig)(cfg))
+	return unmarshal((*openstack.SwiftConfig)(cfg))
+}
+
+// Validate ensures the names are unique and don't shadow a store type, and validates each config.
+func (ns *NamedStores) Validate() error {
+	seen := map[string]struct{}{}
+	for _, storeType := range []string{
+		config.StorageTypeAWS, config.StorageTypeS3, config.StorageTypeGCS, config.StorageTypeAzure,
+		config.StorageTypeSwift, config.StorageTypeInMemory, config.StorageTypeFileSystem, config.StorageTypeBOS, config.StorageTypeAlibabaCloud, config.StorageTypeBackblaze,
+	} {
+		seen[storeType] = struct{}{}
+	}
+	checkName := func(name string) error {
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("named store %q is a store type or is configured more than once", name)
+		}
+		seen[name] = struct{}{}
+		return nil
+	}
+
+	for name, cfg := range ns.AWS {
+		if err := checkName(name); err != nil {
+			return err
+		}
+		awsCfg := aws.StorageConfig(cfg)
+		if err := awsCfg.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid AWS Storage config for named store %s", name)
+		}
+	}
+	for name, cfg := range ns.Azure {
+		if err := checkName(name); err != nil {
+			return err
+		}
+		azureCfg := azure.BlobStorageConfig(cfg)
+		if err := azureCfg.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid Azure Storage config for named store %s", name)
+		}
+	}
+	for name := range ns.GCS {
+		if err := checkName(name); err != nil {
+			return err
+		}
+	}
+	for name := range ns.Filesystem {
+		if err := checkName(name); err != nil {
+			return err
+		}
+	}
+	for name, cfg := range ns.Swift {
+		if err := checkName(name); err != nil {
+			return err
+		}
+		swiftCfg := openstack.SwiftConfig(cfg)
+		if err := swiftCfg.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid Swift Storage config for named store %s", name)
+		}
+	}
+	return nil
+}
+
+// storeConfig returns the store type of the named store along with cfg updated to use
+// the named store's config in place of its type's one. ok is false for unknown names.
+func (ns *NamedStores) storeConfig(name string, cfg Config) (storeType string, res Config, ok bool) {
+	if c, ok := ns.AWS[name]; ok {
+		cfg.AWSStorageConfig = aws.StorageConfig(c)
+		return config.StorageTypeAWS, cfg, true
+	}
+	if c, ok := ns.Azure[name]; ok {
+		cfg.AzureStorageConfig = azure.BlobStorageConfig(c)
+		return config.StorageTypeAzure, cfg, true
+	}
+	if c, ok := ns.GCS[name]; ok {
+		cfg.GCSConfig = gcp.GCSConfig(c)
+		return config.StorageTypeGCS, cfg, true
+	}
+	if c, ok := ns.Filesystem[name]; ok {
+		cfg.FSConfig = local.FSConfig(c)
+		return config.StorageTypeFileSystem, cfg, true
+	}
+	if c, ok := ns.Swift[name]; ok {
+		cfg.Swift = openstack.SwiftConfig(c)
+		return config.StorageTypeSwift, cfg, true
+	}
+	return "", cfg, false
+}
@@ -14,9 +14,11 @@ import (
 
 	"github.com/grafana/dskit/tenant"
 
+	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/astmapper"
 	"github.com/grafana/loki/pkg/storage/chunk"
@@ -76,6 +78,9 @@ type store struct {
 	logger log.Logger
 
 	chunkFilterer chunk.RequestChunkFilterer
+
+	// writes the TSDB index of the flushed chunks, nil if the store doesn't write a TSDB index
+	tsdbHeadManager *tsdb.HeadManager
 }
 
 // NewStore creates a new Loki Store using configuration supplied.
@@ -182,7 +187,7 @@ func (s *store) chunkClientForPeriod(p config.PeriodConfig) (client.Client, erro
 	chunkClientReg := prometheus.WrapRegistererWith(
 		prometheus.Labels{"component": "chunk-store-" + p.From.String()}, s.registerer)
 
-	chunks, err := NewChunkClient(objectStoreType, s.cfg, s.schemaCfg, s.clientMetrics, chunkClientReg)
+	chunks, err := NewChunkClient(objectStoreType, s.cfg, s.schemaCfg, s.limits, s.clientMetrics, chunkClientReg)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating object client")
 	}
@@ -215,7 +220,7 @@ func (s *store) storeForPeriod(p config.PeriodConfig, chunkClient client.Client,
 		}, s.registerer)
 
 	if p.IndexType == config.TSDBType {
-		if shouldUseIndexGatewayClient(s.cfg.TSDBShipperConfig) {
+		if shouldUseIndexGatewayClient(s.cfg.TSDBShipperConfig.Config) {
 			// inject the index-gateway client into the index store
 			gw, err := gatewayclient.NewGatewayClient(s.cfg.TSDBShipperConfig.IndexGatewayClientConfig, indexClientReg, s.logger)
 			if err != nil {
@@ -229,7 +234,7 @@ func (s *store) storeForPeriod(p config.PeriodConfig, chunkClient client.Client,
 			}, nil
 		}
 
-		objectClient, err := NewObjectClient(s.cfg.TSDBShipperConfig.SharedStoreType, s.cfg, s.clientMetrics)
+		objectClient, err := NewObjectClient(s.cfg.TSDBShipperConfig.SharedStoreType, s.cfg, s.limits, s.clientMetrics)
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -246,12 +251,22 @@ func (s *store) storeForPeriod(p config.PeriodConfig, chunkClient client.Client,
 			}
 		}
 
-		indexReaderWriter, stopTSDBStoreFunc, err := tsdb.NewStore(s.cfg.TSDBShipperConfig, p, f, objectClient, s.limits,
+		tsdbCfg := s.cfg.TSDBShipperConfig
+		tsdbCfg.ReplicaObjectClients, err = newIndexReplicaClients(tsdbCfg.Config, s.cfg, s.limits, s.clientMetrics)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		indexReaderWriter, stopTSDBStoreFunc, err := tsdb.NewStore(tsdbCfg, p, f, objectClient, s.limits,
 			getIndexStoreTableRanges(config.TSDBType, s.schemaCfg.Configs), backupIndexWriter, indexClientReg)
 		if err != nil {
 			return nil, nil, nil, err
 		}
 
+		if hm, ok := indexReaderWriter.(interface{ HeadManager() *tsdb.HeadManager }); ok && hm.HeadManager() != nil {
+			s.tsdbHeadManager = hm.HeadManager()
+		}
+
 		indexReaderWriter = index.NewMonitoredReaderWriter(indexReaderWriter, indexClientReg)
 		chunkWriter := stores.NewChunkWriter(f, s.schemaCfg, indexReaderWriter, s.storeCfg.DisableIndexDeduplication)
 
@@ -464,6 +479,14 @@ func (s *store) SelectLogs(ctx context.Context, req logql.SelectLogParams) (iter
 		return nil, err
 	}
 
+	lineFilters := syntax.RequiredLineFilters(expr)
+	lazyChunks = s.filterChunksByBloom(ctx, lazyChunks, lineFilters)
+	if len(lazyChunks) == 0 {
+		return iter.NoopIterator, nil
+	}
+	// the blocks of chunks which have a bloom filter are skipped as well.
+	ctx = chunkenc.WithRequiredLineFilters(ctx, lineFilters)
+
 	var chunkFilterer chunk.Filterer
 	if s.chunkFilterer != nil {
 		chunkFilterer = s.chunkFilterer.ForRequest(ctx)
@@ -502,6 +525,13 @@ func (s *store) SelectSamples(ctx context.Context, req logql.SelectSampleParams)
 		return nil, err
 	}
 
+	lineFilters := syntax.RequiredLineFilters(expr.Selector())
+	lazyChunks = s.filterChunksByBloom(ctx, lazyChunks, lineFilters)
+	if len(lazyChunks) == 0 {
+		return iter.NoopIterator, nil
+	}
+	ctx = chunkenc.WithRequiredLineFilters(ctx, lineFilters)
+
 	var chunkFilterer chunk.Filterer
 	if s.chunkFilterer != nil {
 		chunkFilterer = s.chunkFilterer.ForRequest(ctx)
@@ -514,6 +544,51 @@ func (s *store) GetSchemaConfigs() []config.PeriodConfig {
 	return s.schemaCfg.Configs
 }
 
+// TSDBHeadManager returns the head manager writing the TSDB index of the flushed chunks, nil if the store doesn't write
+// a TSDB index.
+func (s *store) TSDBHeadManager() *tsdb.HeadManager {
+	return s.tsdbHeadManager
+}
+
+// filterChunksByBloom drops the chunks whose bloom filter shows that none of their
+// lines contains all the given strings. The bloom filters are fetched in batches of
+// MaxChunkBatchSize chunks, like the chunks.
+func (s *store) filterChunksByBloom(ctx context.Context, chunks []*LazyChunk, contains []string) []*LazyChunk {
+	if len(contains) == 0 {
+		return chunks
+	}
+
+	chksByFetcher := map[*fetcher.Fetcher][]chunk.Chunk{}
+	for _, c := range chunks {
+		chksByFetcher[c.Fetcher] = append(chksByFetcher[c.Fetcher], c.Chunk)
+	}
+
+	kept := make(map[string]struct{}, len(chunks))
+	for f, chks := range chksByFetcher {
+		batchSize := s.cfg.MaxChunkBatchSize
+		if batchSize <= 0 {
+			batchSize = len(chks)
+		}
+		for start := 0; start < len(chks); start += batchSize {
+			end := start + batchSize
+			if end > len(chks) {
+				end = len(chks)
+			}
+			for _, c := range f.FilterChunksByBloom(ctx, chks[start:end], contains) {
+				kept[s.schemaCfg.ExternalKey(c.ChunkRef)] = struct{}{}
+			}
+		}
+	}
+
+	filtered := make([]*LazyChunk, 0, len(kept))
+	for _, c := range chunks {
+		if _, ok := kept[s.schemaCfg.ExternalKey(c.Chunk.ChunkRef)]; ok {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
 func filterChunksByTime(from, through model.Time, chunks []chunk.Chunk) []chunk.Chunk {
 	filtered := make([]chunk.Chunk, 0, len(chunks))
 	for _, chunk := range chunks {
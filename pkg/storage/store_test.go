@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -22,9 +23,15 @@ import (
 	"github.com/grafana/loki/pkg/iter"
 	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/logql"
+	"github.com/grafana/loki/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/pkg/querier/astmapper"
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/chunk/bloom"
+	"github.com/grafana/loki/pkg/storage/chunk/cache"
+	chunkclient "github.com/grafana/loki/pkg/storage/chunk/client"
 	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/chunk/client/testutils"
+	"github.com/grafana/loki/pkg/storage/chunk/fetcher"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper"
 	"github.com/grafana/loki/pkg/storage/stores/shipper"
@@ -878,6 +885,70 @@ func Test_ChunkFilterer(t *testing.T) {
 	}
 }
 
+func Test_store_filterChunksByBloom(t *testing.T) {
+	schemaCfg := config.SchemaConfig{}
+	objectClient := testutils.NewMockStorage()
+	chunkClient := chunkclient.NewClientWithBloomFilters(objectClient, nil, 10, schemaCfg)
+
+	errorChunk := newChunk(logproto.Stream{
+		Labels: `{app="foo"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: from, Line: "level=error msg=\"connection refused\""},
+			{Timestamp: from.Add(time.Millisecond), Line: "level=error msg=\"connection reset\""},
+		},
+	})
+	requestChunk := newChunk(logproto.Stream{
+		Labels: `{app="bar"}`,
+		Entries: []logproto.Entry{
+			{Timestamp: from, Line: "level=info msg=\"request served\""},
+		},
+	})
+	require.NoError(t, chunkClient.PutChunks(ctx, []chunk.Chunk{errorChunk, requestChunk}))
+	require.Equal(t, 4, objectClient.GetObjectCount())
+
+	c, err := cache.New(cache.Config{Prefix: "chunks", EmbeddedCache: cache.EmbeddedCacheConfig{Enabled: true, MaxSizeMB: 1}}, prometheus.NewRegistry(), util_log.Logger, stats.ChunkCache)
+	require.NoError(t, err)
+	f, err := fetcher.New(c, false, schemaCfg, chunkClient, 1, 10)
+	require.NoError(t, err)
+	defer f.Stop()
+
+	s := &store{schemaCfg: schemaCfg}
+	lazyChunks := []*LazyChunk{
+		{Chunk: errorChunk, Fetcher: f},
+		{Chunk: requestChunk, Fetcher: f},
+	}
+
+	for _, tc := range []struct {
+		contains []string
+		expected []*LazyChunk
+	}{
+		{nil, lazyChunks},
+		{[]string{"connection"}, lazyChunks[:1]},
+		{[]string{"level=", "served"}, lazyChunks[1:]},
+		{[]string{"connection", "served"}, []*LazyChunk{}},
+		{[]string{"ms"}, lazyChunks},
+	} {
+		require.Equal(t, tc.expected, s.filterChunksByBloom(ctx, lazyChunks, tc.contains), "%v", tc.contains)
+	}
+
+	// the bloom filters are cached.
+	require.NoError(t, objectClient.DeleteObject(ctx, bloom.Key(schemaCfg.ExternalKey(errorChunk.ChunkRef))))
+	require.Equal(t, lazyChunks[1:], s.filterChunksByBloom(ctx, lazyChunks, []string{"served"}))
+
+	// chunks without a bloom filter are always kept, whether they're fetched in one batch or several.
+	noBloomChunk := newChunk(logproto.Stream{
+		Labels:  `{app="baz"}`,
+		Entries: []logproto.Entry{{Timestamp: from, Line: "level=debug"}},
+	})
+	require.NoError(t, chunkclient.NewClient(objectClient, nil, schemaCfg).PutChunks(ctx, []chunk.Chunk{noBloomChunk}))
+	lazyChunks = append(lazyChunks, &LazyChunk{Chunk: noBloomChunk, Fetcher: f})
+	for _, batchSize := range []int{0, 1} {
+		s.cfg.MaxChunkBatchSize = batchSize
+		require.Equal(t, lazyChunks[1:], s.filterChunksByBloom(ctx, lazyChunks, []string{"served"}))
+		require.Equal(t, lazyChunks[2:], s.filterChunksByBloom(ctx, lazyChunks, []string{"missing"}))
+	}
+}
+
 func Test_store_GetSeries(t *testing.T) {
 	tests := []struct {
 		name      string
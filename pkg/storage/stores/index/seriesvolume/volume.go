@@ -0,0 +1,151 @@
+// Package seriesvolume aggregates the volume of the data selected by label matchers, as recorded by the index.
+package seriesvolume
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+const (
+	// DefaultLimit is the number of volumes returned when the request has no limit.
+	DefaultLimit = 100
+
+	// Series aggregates the volumes by series, restricted to the target labels.
+	Series = "series"
+	// Labels aggregates the volumes by label name.
+	Labels = "labels"
+	// Streams aggregates the volumes by stream, i.e. by all the labels of the series.
+	Streams = "streams"
+)
+
+// ValidateAggregateBy returns an error if aggregateBy isn't a supported aggregation. An empty
+// aggregateBy aggregates by series.
+func ValidateAggregateBy(aggregateBy string) error {
+	switch aggregateBy {
+	case "", Series, Labels, Streams:
+		return nil
+	default:
+		return fmt.Errorf("unsupported volume aggregation %q, expected %q, %q or %q", aggregateBy, Series, Labels, Streams)
+	}
+}
+
+// TargetLabels returns the labels the volumes are aggregated by when aggregating by series:
+// the target labels if any, otherwise the names of the matchers.
+func TargetLabels(targetLabels []string, matchers []*labels.Matcher) map[string]struct{} {
+	res := make(map[string]struct{}, len(targetLabels))
+	for _, name := range targetLabels {
+		res[name] = struct{}{}
+	}
+	if len(res) > 0 {
+		return res
+	}
+	for _, m := range matchers {
+		res[m.Name] = struct{}{}
+	}
+	return res
+}
+
+// Names calls f with the name of each volume ls contributes to. reserved labels, i.e. the
+// tenant label of multi-tenant indices, are never part of the names.
+func Names(ls labels.Labels, aggregateBy string, targetLabels map[string]struct{}, reserved string, f func(name string)) {
+	switch aggregateBy {
+	case Labels:
+		for _, l := range ls {
+			if l.Name != reserved {
+				f(l.Name)
+			}
+		}
+		return
+	case Streams:
+		stream := make(labels.Labels, 0, len(ls))
+		for _, l := range ls {
+			if l.Name != reserved {
+				stream = append(stream, l)
+			}
+		}
+		f(stream.String())
+		return
+	}
+
+	target := make(labels.Labels, 0, len(targetLabels))
+	for _, l := range ls {
+		if _, ok := targetLabels[l.Name]; ok && l.Name != reserved {
+			target = append(target, l)
+		}
+	}
+	if len(target) > 0 {
+		f(target.String())
+	}
+}
+
+// Accumulator sums the volumes by name. It's safe for concurrent use.
+type Accumulator struct {
+	mtx     sync.Mutex
+	volumes map[string]uint64
+	limit   int32
+}
+
+// NewAccumulator returns an Accumulator of volumes responding with at most limit volumes.
+func NewAccumulator(limit int32) *Accumulator {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return &Accumulator{
+		volumes: make(map[string]uint64),
+		limit:   limit,
+	}
+}
+
+// AddVolume adds size bytes to the volume of name.
+func (acc *Accumulator) AddVolume(name string, size uint64) {
+	acc.mtx.Lock()
+	defer acc.mtx.Unlock()
+	acc.volumes[name] += size
+}
+
+// AddVolumes adds the volumes of a response.
+func (acc *Accumulator) AddVolumes(resp *logproto.VolumeResponse) {
+	if resp == nil {
+		return
+	}
+	acc.mtx.Lock()
+	defer acc.mtx.Unlock()
+	for _, v := range resp.Volumes {
+		acc.volumes[v.Name] += v.Volume
+	}
+}
+
+// Volumes returns the largest volumes, sorted by decreasing volume then by name.
+func (acc *Accumulator) Volumes() *logproto.VolumeResponse {
+	acc.mtx.Lock()
+	defer acc.mtx.Unlock()
+
+	volumes := make([]logproto.Volume, 0, len(acc.volumes))
+	for name, size := range acc.volumes {
+		volumes = append(volumes, logproto.Volume{Name: name, Volume: size})
+	}
+	sort.Slice(volumes, func(i, j int) bool {
+		if volumes[i].Volume != volumes[j].Volume {
+			return volumes[i].Volume > volumes[j].Volume
+		}
+		return volumes[i].Name < volumes[j].Name
+	})
+	if len(volumes) > int(acc.limit) {
+		volumes = volumes[:acc.limit]
+	}
+	return &logproto.VolumeResponse{Volumes: volumes, Limit: acc.limit}
+}
+
+// Merge sums the volumes of the responses and returns the largest limit ones.
+func Merge(limit int32, responses ...*logproto.VolumeResponse) *logproto.VolumeResponse {
+	acc := NewAccumulator(limit)
+	for _, resp := range responses {
+		acc.AddVolumes(resp)
+	}
+	return acc.Volumes()
+}
@@ -0,0 +1,63 @@
+package seriesvolume
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestNames(t *testing.T) {
+	ls := labels.FromStrings("app", "foo", "env", "prod", "pod", "foo-1", "__loki_tenant__", "fake")
+	matchers := []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "foo")}
+
+	for _, tc := range []struct {
+		desc         string
+		aggregateBy  string
+		targetLabels []string
+		expected     []string
+	}{
+		{desc: "matchers", aggregateBy: Series, expected: []string{`{app="foo"}`}},
+		{desc: "default aggregation", expected: []string{`{app="foo"}`}},
+		{desc: "target labels", aggregateBy: Series, targetLabels: []string{"env", "app", "missing"}, expected: []string{`{app="foo", env="prod"}`}},
+		{desc: "missing target labels", aggregateBy: Series, targetLabels: []string{"missing"}},
+		{desc: "labels", aggregateBy: Labels, expected: []string{"app", "env", "pod"}},
+		{desc: "streams", aggregateBy: Streams, targetLabels: []string{"app"}, expected: []string{`{app="foo", env="prod", pod="foo-1"}`}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var names []string
+			Names(ls, tc.aggregateBy, TargetLabels(tc.targetLabels, matchers), "__loki_tenant__", func(name string) {
+				names = append(names, name)
+			})
+			require.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	acc := NewAccumulator(2)
+	acc.AddVolume(`{app="foo"}`, 10)
+	acc.AddVolume(`{app="bar"}`, 20)
+	acc.AddVolume(`{app="baz"}`, 5)
+	acc.AddVolumes(&logproto.VolumeResponse{Volumes: []logproto.Volume{{Name: `{app="foo"}`, Volume: 10}}})
+
+	require.Equal(t, &logproto.VolumeResponse{
+		Volumes: []logproto.Volume{
+			{Name: `{app="bar"}`, Volume: 20},
+			{Name: `{app="foo"}`, Volume: 20},
+		},
+		Limit: 2,
+	}, acc.Volumes())
+
+	require.Equal(t, &logproto.VolumeResponse{Volumes: []logproto.Volume{}, Limit: DefaultLimit}, Merge(0))
+}
+
+func TestValidateAggregateBy(t *testing.T) {
+	require.NoError(t, ValidateAggregateBy(""))
+	require.NoError(t, ValidateAggregateBy(Series))
+	require.NoError(t, ValidateAggregateBy(Labels))
+	require.NoError(t, ValidateAggregateBy(Streams))
+	require.Error(t, ValidateAggregateBy("pods"))
+}
@@ -90,6 +90,7 @@ type Config struct {
 	RunOnce                   bool            `yaml:"_"`
 	TablesToCompact           int             `yaml:"tables_to_compact"`
 	SkipLatestNTables         int             `yaml:"skip_latest_n_tables"`
+	ShardingEnabled           bool            `yaml:"sharding_enabled"`
 
 	// Deprecated
 	DeletionMode string `yaml:"deletion_mode"`
@@ -119,6 +120,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.CompactorRing.RegisterFlagsWithPrefix("boltdb.shipper.compactor.", "collectors/", f)
 	f.IntVar(&cfg.TablesToCompact, "boltdb.shipper.compactor.tables-to-compact", 0, "The number of most recent tables to compact in a single run. Default: all")
 	f.IntVar(&cfg.SkipLatestNTables, "boltdb.shipper.compactor.skip-latest-n-tables", 0, "Skip compacting latest N tables")
+	f.BoolVar(&cfg.ShardingEnabled, "boltdb.shipper.compactor.sharding-enabled", false, "Shard the tables across the compactors of the ring, so that each compactor compacts, applies retention and applies the delete requests to its own tables. The delete requests API is served by the leader of the ring only.")
 
 }
 
@@ -159,11 +161,14 @@ type Compactor struct {
 	indexCompactors       map[string]IndexCompactor
 	schemaConfig          config.SchemaConfig
 
-	// Ring used for running a single compactor
+	// Ring used for running a single compactor, or for sharding the tables across the compactors
 	ringLifecycler *ring.BasicLifecycler
 	ring           *ring.Ring
 	ringPollPeriod time.Duration
 
+	// the store of the delete requests when the tables are sharded, also set as deleteRequestsStore
+	shardedDeleteRequestsStore *shardedDeleteRequestsStore
+
 	// Subservices manager.
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
@@ -197,7 +202,7 @@ func NewCompactor(cfg Config, objectClient client.ObjectClient, schemaConfig con
 	if err != nil {
 		return nil, errors.Wrap(err, "create KV store client")
 	}
-	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(ringNumTokens, util_log.Logger)
+	lifecyclerCfg, err := cfg.CompactorRing.ToLifecyclerConfig(numRingTokens(cfg), util_log.Logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid ring lifecycler config")
 	}
@@ -273,14 +278,21 @@ func (c *Compactor) init(objectClient client.ObjectClient, schemaConfig config.S
 func (c *Compactor) initDeletes(r prometheus.Registerer, limits *validation.Overrides) error {
 	deletionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "deletion")
 
-	store, err := deletion.NewDeleteStore(deletionWorkDir, c.indexStorageClient)
-	if err != nil {
-		return err
+	if c.cfg.ShardingEnabled {
+		// the delete requests table is only opened by the leader
+		c.shardedDeleteRequestsStore = newShardedDeleteRequestsStore(deletionWorkDir, c.indexStorageClient)
+		c.deleteRequestsStore = c.shardedDeleteRequestsStore
+	} else {
+		store, err := deletion.NewDeleteStore(deletionWorkDir, c.indexStorageClient)
+		if err != nil {
+			return err
+		}
+		c.deleteRequestsStore = store
 	}
-	c.deleteRequestsStore = store
 
 	c.DeleteRequestsHandler = deletion.NewDeleteRequestHandler(
 		c.deleteRequestsStore,
+		c.cfg.DeleteRequestCancelPeriod,
 		c.cfg.DeleteMaxInterval,
 		r,
 	)
@@ -386,19 +398,21 @@ func (c *Compactor) loop(ctx context.Context) error {
 			level.Info(util_log.Logger).Log("msg", "compactor exiting")
 			return nil
 		case <-syncTicker.C:
-			bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
-			rs, err := c.ring.Get(ringKeyOfLeader, ring.Write, bufDescs, bufHosts, bufZones)
-			if err != nil {
-				level.Error(util_log.Logger).Log("msg", "error asking ring for who should run the compactor, will check again", "err", err)
-				continue
-			}
-
-			addrs := rs.GetAddresses()
-			if len(addrs) != 1 {
-				level.Error(util_log.Logger).Log("msg", "too many addresses (more that one) return when asking the ring who should run the compactor, will check again")
-				continue
+			// when the tables are sharded, all the compactors run, each one on its own tables
+			leader := c.cfg.ShardingEnabled
+			if leader {
+				if err := c.updateShardedRole(); err != nil {
+					level.Error(util_log.Logger).Log("msg", "failed to update the role of the compactor, will check again", "err", err)
+				}
+			} else {
+				var err error
+				leader, err = c.isLeader()
+				if err != nil {
+					level.Error(util_log.Logger).Log("msg", "error asking ring for who should run the compactor, will check again", "err", err)
+					continue
+				}
 			}
-			if c.ringLifecycler.GetInstanceAddr() == addrs[0] {
+			if leader {
 				// If not running, start
 				if !c.running {
 					level.Info(util_log.Logger).Log("msg", "this instance has been chosen to run the compactor, starting compactor")
@@ -532,6 +546,13 @@ func (c *Compactor) RegisterIndexCompactor(indexType string, indexCompactor Inde
 }
 
 func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) error {
+	shardedDeletion := applyRetention && c.shardedDeleteRequestsStore != nil
+	if shardedDeletion {
+		if err := c.startShardedDeletion(); err != nil {
+			return err
+		}
+	}
+
 	status := statusSuccess
 	start := time.Now()
 
@@ -582,6 +603,30 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 		return err
 	}
 
+	// the tables which must be compacted for the delete requests to be applied to all of them
+	allTables := make([]string, 0, len(tables))
+	for _, tableName := range tables {
+		if tableName == deletion.DeleteRequestsTableName {
+			continue
+		}
+		if _, ok := schemaPeriodForTable(c.schemaConfig, tableName); ok {
+			allTables = append(allTables, tableName)
+		}
+	}
+
+	if c.cfg.ShardingEnabled {
+		tables, err = c.ownedTables(tables)
+		if err != nil {
+			status = statusFailure
+			return err
+		}
+		c.metrics.compactorOwnedTables.Set(float64(len(tables)))
+	}
+
+	var (
+		compactedTablesMtx sync.Mutex
+		compactedTables    []string
+	)
 	compactTablesChan := make(chan string)
 	errChan := make(chan error)
 
@@ -599,12 +644,29 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 						return
 					}
 
+					if c.cfg.ShardingEnabled {
+						// the table may have moved to another compactor since the run started
+						var owned bool
+						owned, err = c.ownsTable(tableName)
+						if err != nil {
+							return
+						}
+						if !owned {
+							level.Info(util_log.Logger).Log("msg", "skipping table no longer owned by this compactor", "table-name", tableName)
+							continue
+						}
+					}
+
 					level.Info(util_log.Logger).Log("msg", "compacting table", "table-name", tableName)
 					err = c.CompactTable(ctx, tableName, applyRetention)
 					if err != nil {
 						return
 					}
 					level.Info(util_log.Logger).Log("msg", "finished compacting table", "table-name", tableName)
+
+					compactedTablesMtx.Lock()
+					compactedTables = append(compactedTables, tableName)
+					compactedTablesMtx.Unlock()
 				case <-ctx.Done():
 					return
 				}
@@ -639,6 +701,14 @@ func (c *Compactor) RunCompaction(ctx context.Context, applyRetention bool) erro
 		}
 	}
 
+	// the delete requests were applied to the compacted tables, even if other tables failed
+	if shardedDeletion {
+		if err := c.finishShardedDeletion(ctx, allTables, compactedTables); err != nil && firstErr == nil {
+			status = statusFailure
+			firstErr = err
+		}
+	}
+
 	return firstErr
 }
 
@@ -648,7 +718,7 @@ type expirationChecker struct {
 }
 
 func newExpirationChecker(retentionExpiryChecker, deletionExpiryChecker retention.ExpirationChecker) retention.ExpirationChecker {
-	return &expirationChecker{retentionExpiryChecker, deletionExpiryChecker}
+	return &expirationChecker{retentionExpiryChecker: retentionExpiryChecker, deletionExpiryChecker: deletionExpiryChecker}
 }
 
 func (e *expirationChecker) Expired(ref retention.ChunkEntry, now model.Time) (bool, []retention.IntervalFilter) {
@@ -697,7 +767,7 @@ func (c *Compactor) OnRingInstanceRegister(_ *ring.BasicLifecycler, ringDesc rin
 	}
 
 	takenTokens := ringDesc.GetTokens()
-	newTokens := ring.GenerateTokens(ringNumTokens-len(tokens), takenTokens)
+	newTokens := ring.GenerateTokens(numRingTokens(c.cfg)-len(tokens), takenTokens)
 
 	// Tokens sorting will be enforced by the parent caller.
 	tokens = append(tokens, newTokens...)
@@ -42,6 +42,10 @@ type DeleteRequestsManager struct {
 	done                       chan struct{}
 	batchSize                  int
 	limits                     Limits
+
+	// sharded is set when the tables are sharded across the compactors. The delete requests are then applied by each
+	// compactor to its own tables, and only marked as processed by the leader once applied to all the tables.
+	sharded, leader bool
 }
 
 func NewDeleteRequestsManager(store DeleteRequestsStore, deleteRequestCancelPeriod time.Duration, batchSize int, limits Limits, registerer prometheus.Registerer) *DeleteRequestsManager {
@@ -85,7 +89,15 @@ func (d *DeleteRequestsManager) Stop() {
 }
 
 func (d *DeleteRequestsManager) updateMetrics() error {
-	deleteRequests, err := d.deleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusReceived)
+	d.deleteRequestsToProcessMtx.Lock()
+	follower := d.sharded && !d.leader
+	d.deleteRequestsToProcessMtx.Unlock()
+	if follower {
+		// the pending delete requests are reported by the leader
+		return nil
+	}
+
+	deleteRequests, err := d.pendingDeleteRequests()
 	if err != nil {
 		return err
 	}
@@ -95,7 +107,7 @@ func (d *DeleteRequestsManager) updateMetrics() error {
 
 	for _, deleteRequest := range deleteRequests {
 		// adding an extra minute here to avoid a race between cancellation of request and picking up the request for processing
-		if deleteRequest.Status != StatusReceived || deleteRequest.CreatedAt.Add(d.deleteRequestCancelPeriod).Add(time.Minute).After(model.Now()) {
+		if (deleteRequest.Status != StatusReceived && deleteRequest.Status != StatusProcessing) || deleteRequest.CreatedAt.Add(d.deleteRequestCancelPeriod).Add(time.Minute).After(model.Now()) {
 			continue
 		}
 
@@ -123,6 +135,10 @@ func (d *DeleteRequestsManager) loadDeleteRequestsToProcess() error {
 	// Reset this first so any errors result in a clear map
 	d.deleteRequestsToProcess = map[string]*userDeleteRequests{}
 
+	if d.sharded && !d.leader {
+		return d.loadDeleteRequestsOfLeader()
+	}
+
 	deleteRequests, err := d.filteredSortedDeleteRequests()
 	if err != nil {
 		return err
@@ -139,28 +155,113 @@ func (d *DeleteRequestsManager) loadDeleteRequestsToProcess() error {
 			"delete_request_id", deleteRequest.RequestID,
 			"user", deleteRequest.UserID,
 		)
+		d.updateStatus(deleteRequest, StatusProcessing)
+		d.addDeleteRequestToProcess(deleteRequest)
+	}
+
+	return nil
+}
 
-		deleteRequest.Metrics = d.metrics
+// loadDeleteRequestsOfLeader loads the delete requests the leader is processing, without updating their status, as
+// only the leader updates the delete requests.
+func (d *DeleteRequestsManager) loadDeleteRequestsOfLeader() error {
+	deleteRequests, err := d.deleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusProcessing)
+	if err != nil {
+		return err
+	}
 
-		ur := d.requestsForUser(deleteRequest)
-		ur.requests = append(ur.requests, deleteRequest)
-		if deleteRequest.StartTime < ur.requestsInterval.Start {
-			ur.requestsInterval.Start = deleteRequest.StartTime
+	for _, deleteRequest := range deleteRequests {
+		processRequest, err := d.shouldProcessRequest(deleteRequest)
+		if err != nil {
+			return err
 		}
-		if deleteRequest.EndTime > ur.requestsInterval.End {
-			ur.requestsInterval.End = deleteRequest.EndTime
+		if processRequest {
+			d.addDeleteRequestToProcess(deleteRequest)
 		}
 	}
-
 	return nil
 }
 
-func (d *DeleteRequestsManager) filteredSortedDeleteRequests() ([]DeleteRequest, error) {
+func (d *DeleteRequestsManager) addDeleteRequestToProcess(deleteRequest DeleteRequest) {
+	deleteRequest.Metrics = d.metrics
+
+	ur := d.requestsForUser(deleteRequest)
+	ur.requests = append(ur.requests, deleteRequest)
+	if deleteRequest.StartTime < ur.requestsInterval.Start {
+		ur.requestsInterval.Start = deleteRequest.StartTime
+	}
+	if deleteRequest.EndTime > ur.requestsInterval.End {
+		ur.requestsInterval.End = deleteRequest.EndTime
+	}
+}
+
+// SetShardedRole is called when the tables are sharded across the compactors, before each phase, with whether the
+// compactor is the leader. The leader picks the delete requests to process like a single compactor does, but doesn't
+// mark them as processed when a phase finishes, as the other compactors apply them to their own tables. The other
+// compactors apply the delete requests the leader is processing.
+func (d *DeleteRequestsManager) SetShardedRole(leader bool) {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	d.sharded = true
+	d.leader = leader
+}
+
+// ProcessingRequests returns the delete requests applied by the current phase.
+func (d *DeleteRequestsManager) ProcessingRequests() []DeleteRequest {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	var deleteRequests []DeleteRequest
+	for _, userDeleteRequests := range d.deleteRequestsToProcess {
+		deleteRequests = append(deleteRequests, userDeleteRequests.requests...)
+	}
+	return deleteRequests
+}
+
+// pendingDeleteRequests returns the delete requests not processed yet, including the ones left processing by a
+// compaction which didn't finish.
+func (d *DeleteRequestsManager) pendingDeleteRequests() ([]DeleteRequest, error) {
 	deleteRequests, err := d.deleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusReceived)
 	if err != nil {
 		return nil, err
 	}
 
+	processing, err := d.deleteRequestsStore.GetDeleteRequestsByStatus(context.Background(), StatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	return append(deleteRequests, processing...), nil
+}
+
+// updateStatus updates the status of a delete request being processed. It only logs the failures, as the status of
+// the requests being processed is only informative.
+func (d *DeleteRequestsManager) updateStatus(deleteRequest DeleteRequest, status DeleteRequestStatus) {
+	if err := d.deleteRequestsStore.UpdateStatus(context.Background(), deleteRequest, status); err != nil {
+		level.Error(util_log.Logger).Log(
+			"msg", "failed to update the status of delete request for user",
+			"delete_request_id", deleteRequest.RequestID,
+			"sequence_num", deleteRequest.SequenceNum,
+			"user", deleteRequest.UserID,
+			"status", status,
+			"err", err,
+		)
+	}
+}
+
+// resetDeleteRequestsToProcess drops the batch of delete requests being processed, which will be processed again by
+// the next compaction. The delete requests stay processing, as they may have been applied to some of the tables
+// already, so that they can't be cancelled anymore.
+func (d *DeleteRequestsManager) resetDeleteRequestsToProcess() {
+	d.deleteRequestsToProcess = map[string]*userDeleteRequests{}
+}
+
+func (d *DeleteRequestsManager) filteredSortedDeleteRequests() ([]DeleteRequest, error) {
+	deleteRequests, err := d.pendingDeleteRequests()
+	if err != nil {
+		return nil, err
+	}
+
 	deleteRequests, err = d.filteredRequests(deleteRequests)
 	if err != nil {
 		return nil, err
@@ -301,7 +402,7 @@ func (d *DeleteRequestsManager) MarkPhaseFailed() {
 	defer d.deleteRequestsToProcessMtx.Unlock()
 
 	d.metrics.deletionFailures.WithLabelValues("error").Inc()
-	d.deleteRequestsToProcess = map[string]*userDeleteRequests{}
+	d.resetDeleteRequestsToProcess()
 }
 
 func (d *DeleteRequestsManager) MarkPhaseTimedOut() {
@@ -309,39 +410,57 @@ func (d *DeleteRequestsManager) MarkPhaseTimedOut() {
 	defer d.deleteRequestsToProcessMtx.Unlock()
 
 	d.metrics.deletionFailures.WithLabelValues("timeout").Inc()
-	d.deleteRequestsToProcess = map[string]*userDeleteRequests{}
+	d.resetDeleteRequestsToProcess()
 }
 
 func (d *DeleteRequestsManager) MarkPhaseFinished() {
 	d.deleteRequestsToProcessMtx.Lock()
 	defer d.deleteRequestsToProcessMtx.Unlock()
 
+	if d.sharded {
+		// the leader marks the delete requests as processed once they are applied to all the tables, see MarkProcessed
+		return
+	}
+
 	for _, userDeleteRequests := range d.deleteRequestsToProcess {
 		if userDeleteRequests == nil {
 			continue
 		}
+		d.markProcessed(userDeleteRequests.requests)
+	}
+}
 
-		for _, deleteRequest := range userDeleteRequests.requests {
-			if err := d.deleteRequestsStore.UpdateStatus(context.Background(), deleteRequest, StatusProcessed); err != nil {
-				level.Error(util_log.Logger).Log(
-					"msg", "failed to mark delete request for user as processed",
-					"delete_request_id", deleteRequest.RequestID,
-					"sequence_num", deleteRequest.SequenceNum,
-					"user", deleteRequest.UserID,
-					"err", err,
-					"deleted_lines", deleteRequest.DeletedLines,
-				)
-			} else {
-				level.Info(util_log.Logger).Log(
-					"msg", "delete request for user marked as processed",
-					"delete_request_id", deleteRequest.RequestID,
-					"sequence_num", deleteRequest.SequenceNum,
-					"user", deleteRequest.UserID,
-					"deleted_lines", deleteRequest.DeletedLines,
-				)
-			}
-			d.metrics.deleteRequestsProcessedTotal.WithLabelValues(deleteRequest.UserID).Inc()
+// MarkProcessed marks the delete requests as processed, once the leader knows they are applied to all the tables when
+// the tables are sharded across the compactors.
+func (d *DeleteRequestsManager) MarkProcessed(deleteRequests []DeleteRequest) {
+	d.deleteRequestsToProcessMtx.Lock()
+	defer d.deleteRequestsToProcessMtx.Unlock()
+
+	d.markProcessed(deleteRequests)
+}
+
+func (d *DeleteRequestsManager) markProcessed(deleteRequests []DeleteRequest) {
+	for _, deleteRequest := range deleteRequests {
+		if err := d.deleteRequestsStore.UpdateStatus(context.Background(), deleteRequest, StatusProcessed); err != nil {
+			level.Error(util_log.Logger).Log(
+				"msg", "failed to mark delete request for user as processed",
+				"delete_request_id", deleteRequest.RequestID,
+				"sequence_num", deleteRequest.SequenceNum,
+				"user", deleteRequest.UserID,
+				"err", err,
+				"deleted_lines", deleteRequest.DeletedLines,
+			)
+		} else {
+			level.Info(util_log.Logger).Log(
+				"msg", "delete request for user marked as processed",
+				"delete_request_id", deleteRequest.RequestID,
+				"sequence_num", deleteRequest.SequenceNum,
+				"user", deleteRequest.UserID,
+				"deleted_lines", deleteRequest.DeletedLines,
+			)
 		}
+		d.metrics.deleteRequestsProcessedTotal.WithLabelValues(deleteRequest.UserID).Inc()
+		d.metrics.deletedLinesPerRequest.Observe(float64(deleteRequest.DeletedLines))
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletionmode"
 
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
@@ -483,9 +484,60 @@ func TestDeleteRequestsManager_IntervalMayHaveExpiredChunks(t *testing.T) {
 	}
 }
 
+func TestDeleteRequestsManager_Status(t *testing.T) {
+	store := &mockDeleteRequestsStore{deleteRequests: []DeleteRequest{
+		{RequestID: "1", Query: `{foo="bar"}`, UserID: testUserID, StartTime: 0, EndTime: 100},
+		{RequestID: "2", Query: `{foo="bar"}`, UserID: testUserID, StartTime: 200, EndTime: 300},
+	}}
+	mgr := NewDeleteRequestsManager(store, time.Hour, 70, &fakeLimits{mode: deletionmode.FilterAndDelete.String()}, nil)
+	defer mgr.Stop()
+
+	// the requests are processing until the compaction finishes
+	mgr.MarkPhaseStarted()
+	require.Equal(t, map[string]DeleteRequestStatus{"1": StatusProcessing, "2": StatusProcessing}, store.statuses)
+
+	// they stay processing and are picked up again by the next compaction if it fails
+	mgr.MarkPhaseFailed()
+	require.Equal(t, map[string]DeleteRequestStatus{"1": StatusProcessing, "2": StatusProcessing}, store.statuses)
+
+	mgr.MarkPhaseStarted()
+	mgr.MarkPhaseFinished()
+	require.Equal(t, map[string]DeleteRequestStatus{"1": StatusProcessed, "2": StatusProcessed}, store.statuses)
+
+	var m dto.Metric
+	require.NoError(t, mgr.metrics.deletedLinesPerRequest.Write(&m))
+	require.EqualValues(t, 2, m.GetHistogram().GetSampleCount())
+}
+
+func TestDeleteRequestsManager_ShardedRole(t *testing.T) {
+	store := &mockDeleteRequestsStore{deleteRequests: []DeleteRequest{
+		{RequestID: "1", Query: `{foo="bar"}`, UserID: testUserID, StartTime: 0, EndTime: 100, Status: StatusProcessing},
+	}}
+	mgr := NewDeleteRequestsManager(store, time.Hour, 70, &fakeLimits{mode: deletionmode.FilterAndDelete.String()}, nil)
+	defer mgr.Stop()
+
+	// the other compactors apply the delete requests of the leader without updating them
+	mgr.SetShardedRole(false)
+	mgr.MarkPhaseStarted()
+	require.Len(t, mgr.ProcessingRequests(), 1)
+	mgr.MarkPhaseFailed()
+	mgr.MarkPhaseStarted()
+	mgr.MarkPhaseFinished()
+	require.Nil(t, store.statuses)
+
+	// the leader marks the delete requests as processed once applied to all the tables
+	mgr.SetShardedRole(true)
+	mgr.MarkPhaseStarted()
+	mgr.MarkPhaseFinished()
+	require.Equal(t, map[string]DeleteRequestStatus{"1": StatusProcessing}, store.statuses)
+	mgr.MarkProcessed(mgr.ProcessingRequests())
+	require.Equal(t, map[string]DeleteRequestStatus{"1": StatusProcessed}, store.statuses)
+}
+
 type mockDeleteRequestsStore struct {
 	DeleteRequestsStore
 	deleteRequests           []DeleteRequest
+	statuses                 map[string]DeleteRequestStatus
 	addReqs                  []DeleteRequest
 	addErr                   error
 	returnZeroDeleteRequests bool
@@ -503,8 +555,26 @@ type mockDeleteRequestsStore struct {
 	getAllErr    error
 }
 
-func (m *mockDeleteRequestsStore) GetDeleteRequestsByStatus(_ context.Context, _ DeleteRequestStatus) ([]DeleteRequest, error) {
-	return m.deleteRequests, nil
+func (m *mockDeleteRequestsStore) GetDeleteRequestsByStatus(_ context.Context, status DeleteRequestStatus) ([]DeleteRequest, error) {
+	var reqs []DeleteRequest
+	for _, req := range m.deleteRequests {
+		reqStatus := req.Status
+		if reqStatus == "" {
+			reqStatus = StatusReceived
+		}
+		if reqStatus == status {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, nil
+}
+
+func (m *mockDeleteRequestsStore) UpdateStatus(_ context.Context, req DeleteRequest, newStatus DeleteRequestStatus) error {
+	if m.statuses == nil {
+		m.statuses = map[string]DeleteRequestStatus{}
+	}
+	m.statuses[req.RequestID] = newStatus
+	return nil
 }
 
 func (m *mockDeleteRequestsStore) AddDeleteRequestGroup(ctx context.Context, reqs []DeleteRequest) ([]DeleteRequest, error) {
@@ -27,8 +27,9 @@ type (
 )
 
 const (
-	StatusReceived  DeleteRequestStatus = "received"
-	StatusProcessed DeleteRequestStatus = "processed"
+	StatusReceived   DeleteRequestStatus = "received"
+	StatusProcessing DeleteRequestStatus = "processing"
+	StatusProcessed  DeleteRequestStatus = "processed"
 
 	deleteRequestID      indexType = "1"
 	deleteRequestDetails indexType = "2"
@@ -71,6 +72,19 @@ func NewDeleteStore(workingDirectory string, indexStorageClient storage.Client)
 	}, nil
 }
 
+// NewReadOnlyDeleteStore creates a store reading the latest uploaded delete requests, which can't be updated.
+func NewReadOnlyDeleteStore(workingDirectory string, indexStorageClient storage.Client) (DeleteRequestsStore, error) {
+	indexClient, err := newReadOnlyDeleteRequestsTable(workingDirectory, indexStorageClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deleteRequestsStore{
+		indexClient: indexClient,
+		now:         model.Now,
+	}, nil
+}
+
 func (ds *deleteRequestsStore) Stop() {
 	ds.indexClient.Stop()
 }
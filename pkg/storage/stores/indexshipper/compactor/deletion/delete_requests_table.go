@@ -29,11 +29,32 @@ type deleteRequestsTable struct {
 	db                *bbolt.DB
 	done              chan struct{}
 	wg                sync.WaitGroup
+
+	// readOnly tables are a copy of the table of another compactor, which is never uploaded
+	readOnly bool
 }
 
 const deleteRequestsIndexFileName = DeleteRequestsTableName + ".gz"
 
 func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client) (index.Client, error) {
+	table, err := openDeleteRequestsTable(workingDirectory, indexStorageClient, false)
+	if err != nil {
+		return nil, err
+	}
+
+	go table.loop()
+	return table, nil
+}
+
+// newReadOnlyDeleteRequestsTable downloads the latest uploaded delete requests table, replacing the local copy.
+func newReadOnlyDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client) (index.Client, error) {
+	if err := os.RemoveAll(filepath.Join(workingDirectory, DeleteRequestsTableName)); err != nil {
+		return nil, err
+	}
+	return openDeleteRequestsTable(workingDirectory, indexStorageClient, true)
+}
+
+func openDeleteRequestsTable(workingDirectory string, indexStorageClient storage.Client, readOnly bool) (*deleteRequestsTable, error) {
 	dbPath := filepath.Join(workingDirectory, DeleteRequestsTableName, DeleteRequestsTableName)
 	boltdbIndexClient, err := local.NewBoltDBIndexClient(local.BoltDBConfig{Directory: filepath.Dir(dbPath)})
 	if err != nil {
@@ -45,6 +66,7 @@ func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.
 		dbPath:             dbPath,
 		boltdbIndexClient:  boltdbIndexClient,
 		done:               make(chan struct{}),
+		readOnly:           readOnly,
 	}
 
 	err = table.init()
@@ -52,7 +74,6 @@ func newDeleteRequestsTable(workingDirectory string, indexStorageClient storage.
 		return nil, err
 	}
 
-	go table.loop()
 	return table, nil
 }
 
@@ -65,7 +86,7 @@ func (t *deleteRequestsTable) init() error {
 
 	_, err := os.Stat(t.dbPath)
 	if err != nil {
-		err = storage.DownloadFileFromStorage(t.dbPath, true,
+		err = storage.DownloadFileFromStorage(t.dbPath, storage.CompressionGzip,
 			true, storage.LoggerWithFilename(util_log.Logger, deleteRequestsIndexFileName), func() (io.ReadCloser, error) {
 				return t.indexStorageClient.GetFile(context.Background(), DeleteRequestsTableName, deleteRequestsIndexFileName)
 			})
@@ -150,8 +171,10 @@ func (t *deleteRequestsTable) Stop() {
 	close(t.done)
 	t.wg.Wait()
 
-	if err := t.uploadFile(); err != nil {
-		level.Error(util_log.Logger).Log("msg", "failed to upload delete requests file during shutdown", "err", err)
+	if !t.readOnly {
+		if err := t.uploadFile(); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to upload delete requests file during shutdown", "err", err)
+		}
 	}
 
 	if err := t.db.Close(); err != nil {
@@ -166,6 +189,10 @@ func (t *deleteRequestsTable) NewWriteBatch() index.WriteBatch {
 }
 
 func (t *deleteRequestsTable) BatchWrite(ctx context.Context, batch index.WriteBatch) error {
+	if t.readOnly {
+		return errors.New("the delete requests table is read-only")
+	}
+
 	boltWriteBatch, ok := batch.(*local.BoltWriteBatch)
 	if !ok {
 		return errors.New("invalid write batch")
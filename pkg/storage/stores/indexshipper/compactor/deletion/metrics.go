@@ -52,6 +52,7 @@ type deleteRequestsManagerMetrics struct {
 	oldestPendingDeleteRequestAgeSeconds prometheus.Gauge
 	pendingDeleteRequestsCount           prometheus.Gauge
 	deletedLinesTotal                    *prometheus.CounterVec
+	deletedLinesPerRequest               prometheus.Histogram
 }
 
 func newDeleteRequestsManagerMetrics(r prometheus.Registerer) *deleteRequestsManagerMetrics {
@@ -92,6 +93,12 @@ func newDeleteRequestsManagerMetrics(r prometheus.Registerer) *deleteRequestsMan
 		Name:      "compactor_deleted_lines",
 		Help:      "Number of deleted lines per user",
 	}, []string{"user"})
+	m.deletedLinesPerRequest = promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+		Namespace: "loki",
+		Name:      "compactor_delete_request_deleted_lines",
+		Help:      "Number of lines deleted by each processed delete request",
+		Buckets:   prometheus.ExponentialBuckets(1, 10, 9),
+	})
 
 	return &m
 }
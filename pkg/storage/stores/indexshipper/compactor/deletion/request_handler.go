@@ -23,17 +23,19 @@ import (
 
 // DeleteRequestHandler provides handlers for delete requests
 type DeleteRequestHandler struct {
-	deleteRequestsStore DeleteRequestsStore
-	metrics             *deleteRequestHandlerMetrics
-	maxInterval         time.Duration
+	deleteRequestsStore       DeleteRequestsStore
+	metrics                   *deleteRequestHandlerMetrics
+	deleteRequestCancelPeriod time.Duration
+	maxInterval               time.Duration
 }
 
 // NewDeleteRequestHandler creates a DeleteRequestHandler
-func NewDeleteRequestHandler(deleteStore DeleteRequestsStore, maxInterval time.Duration, registerer prometheus.Registerer) *DeleteRequestHandler {
+func NewDeleteRequestHandler(deleteStore DeleteRequestsStore, deleteRequestCancelPeriod, maxInterval time.Duration, registerer prometheus.Registerer) *DeleteRequestHandler {
 	deleteMgr := DeleteRequestHandler{
-		deleteRequestsStore: deleteStore,
-		maxInterval:         maxInterval,
-		metrics:             newDeleteRequestHandlerMetrics(registerer),
+		deleteRequestsStore:       deleteStore,
+		deleteRequestCancelPeriod: deleteRequestCancelPeriod,
+		maxInterval:               maxInterval,
+		metrics:                   newDeleteRequestHandlerMetrics(registerer),
 	}
 
 	return &deleteMgr
@@ -196,9 +198,10 @@ func mergeDeletes(groups map[string][]DeleteRequest) []DeleteRequest {
 
 func mergeData(deletes []DeleteRequest) (model.Time, model.Time, DeleteRequestStatus) {
 	var (
-		startTime    = model.Time(math.MaxInt64)
-		endTime      = model.Time(0)
-		numProcessed = 0
+		startTime     = model.Time(math.MaxInt64)
+		endTime       = model.Time(0)
+		numProcessed  = 0
+		numProcessing = 0
 	)
 
 	for _, del := range deletes {
@@ -210,16 +213,22 @@ func mergeData(deletes []DeleteRequest) (model.Time, model.Time, DeleteRequestSt
 			endTime = del.EndTime
 		}
 
-		if del.Status == StatusProcessed {
+		switch del.Status {
+		case StatusProcessed:
 			numProcessed++
+		case StatusProcessing:
+			numProcessing++
 		}
 	}
 
-	return startTime, endTime, deleteRequestStatus(numProcessed, len(deletes))
+	return startTime, endTime, deleteRequestStatus(numProcessed, numProcessing, len(deletes))
 }
 
-func deleteRequestStatus(processed, total int) DeleteRequestStatus {
+func deleteRequestStatus(processed, processing, total int) DeleteRequestStatus {
 	if processed == 0 {
+		if processing > 0 {
+			return StatusProcessing
+		}
 		return StatusReceived
 	}
 
@@ -231,6 +240,35 @@ func deleteRequestStatus(processed, total int) DeleteRequestStatus {
 	return DeleteRequestStatus(fmt.Sprintf("%d%% Complete", int(percentCompleted*100)))
 }
 
+// GetDeleteRequestHandler handles get the status of a delete request
+func (dm *DeleteRequestHandler) GetDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requestID := r.URL.Query().Get("request_id")
+	deleteRequests, err := dm.deleteRequestsStore.GetDeleteRequestGroup(ctx, userID, requestID)
+	if err != nil {
+		if errors.Is(err, ErrDeleteRequestNotFound) {
+			http.Error(w, "could not find delete request with given id", http.StatusNotFound)
+			return
+		}
+
+		level.Error(util_log.Logger).Log("msg", "error getting delete request from the store", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	deleteRequest := mergeDeletes(map[string][]DeleteRequest{requestID: deleteRequests})[0]
+	if err := json.NewEncoder(w).Encode(deleteRequest); err != nil {
+		level.Error(util_log.Logger).Log("msg", "error marshalling response", "err", err)
+		http.Error(w, fmt.Sprintf("Error marshalling response: %v", err), http.StatusInternalServerError)
+	}
+}
+
 // CancelDeleteRequestHandler handles delete request cancellation
 func (dm *DeleteRequestHandler) CancelDeleteRequestHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -265,6 +303,12 @@ func (dm *DeleteRequestHandler) CancelDeleteRequestHandler(w http.ResponseWriter
 		return
 	}
 
+	// the compactor may pick the request for processing once its cancellation period is over
+	if toDelete[0].CreatedAt.Add(dm.deleteRequestCancelPeriod).Before(model.Now()) {
+		http.Error(w, "deletion of request past its cancellation period is not allowed", http.StatusBadRequest)
+		return
+	}
+
 	if err := dm.deleteRequestsStore.RemoveDeleteRequests(ctx, toDelete); err != nil {
 		level.Error(util_log.Logger).Log("msg", "error cancelling the delete request", "err", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -23,7 +23,7 @@ import (
 func TestAddDeleteRequestHandler(t *testing.T) {
 	t.Run("it adds the delete request to the store", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
 
@@ -40,7 +40,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("an error is returned if adding delete request group returned zero", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{returnZeroDeleteRequests: true}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
 
@@ -52,7 +52,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("it shards deletes based on a query param", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		from := model.TimeFromUnix(model.Now().Add(-3 * time.Hour).Unix())
 		to := model.TimeFromUnix(from.Add(3 * time.Hour).Unix())
@@ -82,7 +82,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("it uses the default for sharding when the query param isn't present", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
-		h := NewDeleteRequestHandler(store, time.Hour, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, time.Hour, nil)
 
 		from := model.TimeFromUnix(model.Now().Add(-3 * time.Hour).Unix())
 		to := model.TimeFromUnix(from.Add(3 * time.Hour).Unix())
@@ -109,7 +109,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("it works with RFC3339", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", `{foo="bar"}`, "2006-01-02T15:04:05Z", "2006-01-03T15:04:05Z")
 
@@ -126,7 +126,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("it fills in end time if blank", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "")
 
@@ -143,7 +143,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 
 	t.Run("it returns 500 when the delete store errors", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{addErr: errors.New("something bad")}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", `{foo="bar"}`, "0000000000", "0000000001")
 
@@ -153,7 +153,7 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 	})
 
 	t.Run("Validation", func(t *testing.T) {
-		h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, time.Minute, nil)
+		h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, time.Hour, time.Minute, nil)
 
 		for _, tc := range []struct {
 			orgID, query, startTime, endTime, interval, error string
@@ -191,13 +191,13 @@ func TestAddDeleteRequestHandler(t *testing.T) {
 func TestCancelDeleteRequestHandler(t *testing.T) {
 	t.Run("it removes unprocessed delete requests from the store when force is true", func(t *testing.T) {
 		stored := []DeleteRequest{
-			{RequestID: "test-request", UserID: "org-id", Query: "test-query", SequenceNum: 0, Status: StatusProcessed},
-			{RequestID: "test-request", UserID: "org-id", Query: "test-query", SequenceNum: 1, Status: StatusReceived},
+			{RequestID: "test-request", UserID: "org-id", Query: "test-query", SequenceNum: 0, CreatedAt: now, Status: StatusProcessed},
+			{RequestID: "test-request", UserID: "org-id", Query: "test-query", SequenceNum: 1, CreatedAt: now, Status: StatusReceived},
 		}
 		store := &mockDeleteRequestsStore{}
 		store.getResult = stored
 
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 		params := req.URL.Query()
@@ -224,7 +224,7 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
 		store.getResult = stored
 
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 		params := req.URL.Query()
@@ -242,7 +242,7 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 	t.Run("error getting from store", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
 		store.getErr = errors.New("something bad")
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org id", ``, "", "")
 		params := req.URL.Query()
@@ -257,12 +257,12 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 	})
 
 	t.Run("error removing from the store", func(t *testing.T) {
-		stored := []DeleteRequest{{RequestID: "test-request", UserID: "org-id", Query: "test-query", Status: StatusReceived}}
+		stored := []DeleteRequest{{RequestID: "test-request", UserID: "org-id", Query: "test-query", CreatedAt: now, Status: StatusReceived}}
 		store := &mockDeleteRequestsStore{}
 		store.getResult = stored
 		store.removeErr = errors.New("something bad")
 
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 		params := req.URL.Query()
@@ -278,7 +278,7 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 
 	t.Run("Validation", func(t *testing.T) {
 		t.Run("no org id", func(t *testing.T) {
-			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, 0, nil)
+			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, time.Hour, 0, nil)
 
 			req := buildRequest("", ``, "", "")
 			params := req.URL.Query()
@@ -293,7 +293,7 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 		})
 
 		t.Run("request not found", func(t *testing.T) {
-			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{getErr: ErrDeleteRequestNotFound}, 0, nil)
+			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{getErr: ErrDeleteRequestNotFound}, time.Hour, 0, nil)
 
 			req := buildRequest("org-id", ``, "", "")
 			params := req.URL.Query()
@@ -307,12 +307,32 @@ func TestCancelDeleteRequestHandler(t *testing.T) {
 			require.Equal(t, "could not find delete request with given id\n", w.Body.String())
 		})
 
+		t.Run("the cancellation period is over", func(t *testing.T) {
+			stored := []DeleteRequest{{RequestID: "test-request", UserID: "org-id", Query: "test-query", CreatedAt: now.Add(-2 * time.Hour), Status: StatusReceived}}
+			store := &mockDeleteRequestsStore{}
+			store.getResult = stored
+
+			h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
+
+			req := buildRequest("org-id", ``, "", "")
+			params := req.URL.Query()
+			params.Set("request_id", "test-request")
+			req.URL.RawQuery = params.Encode()
+
+			w := httptest.NewRecorder()
+			h.CancelDeleteRequestHandler(w, req)
+
+			require.Equal(t, w.Code, http.StatusBadRequest)
+			require.Equal(t, "deletion of request past its cancellation period is not allowed\n", w.Body.String())
+			require.Nil(t, store.removeReqs)
+		})
+
 		t.Run("all requests in group are already processed", func(t *testing.T) {
 			stored := []DeleteRequest{{RequestID: "test-request", UserID: "org-id", Query: "test-query", Status: StatusProcessed}}
 			store := &mockDeleteRequestsStore{}
 			store.getResult = stored
 
-			h := NewDeleteRequestHandler(store, 0, nil)
+			h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 			req := buildRequest("org-id", ``, "", "")
 			params := req.URL.Query()
@@ -332,7 +352,7 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 	t.Run("it gets all the delete requests for the user", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
 		store.getAllResult = []DeleteRequest{{RequestID: "test-request-1", Status: StatusReceived}, {RequestID: "test-request-2", Status: StatusReceived}}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 
@@ -355,7 +375,7 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 			{RequestID: "test-request-2", CreatedAt: now.Add(time.Minute), StartTime: now.Add(30 * time.Minute), EndTime: now.Add(90 * time.Minute)},
 			{RequestID: "test-request-1", CreatedAt: now, StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour)},
 		}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 
@@ -385,7 +405,7 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 			{RequestID: "test-request-2", CreatedAt: now.Add(time.Minute), Status: StatusProcessed},
 			{RequestID: "test-request-3", CreatedAt: now.Add(2 * time.Minute), Status: StatusReceived},
 		}
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org-id", ``, "", "")
 
@@ -408,7 +428,7 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 	t.Run("error getting from store", func(t *testing.T) {
 		store := &mockDeleteRequestsStore{}
 		store.getAllErr = errors.New("something bad")
-		h := NewDeleteRequestHandler(store, 0, nil)
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
 
 		req := buildRequest("org id", ``, "", "")
 		params := req.URL.Query()
@@ -424,7 +444,7 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 
 	t.Run("validation", func(t *testing.T) {
 		t.Run("no org id", func(t *testing.T) {
-			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, 0, nil)
+			h := NewDeleteRequestHandler(&mockDeleteRequestsStore{}, time.Hour, 0, nil)
 
 			req := buildRequest("", ``, "", "")
 
@@ -437,6 +457,48 @@ func TestGetAllDeleteRequestsHandler(t *testing.T) {
 	})
 }
 
+func TestGetDeleteRequestHandler(t *testing.T) {
+	t.Run("it gets the merged delete request", func(t *testing.T) {
+		store := &mockDeleteRequestsStore{}
+		store.getResult = []DeleteRequest{
+			{RequestID: "test-request", CreatedAt: now, StartTime: now, EndTime: now.Add(time.Hour), Status: StatusProcessing},
+			{RequestID: "test-request", CreatedAt: now, StartTime: now.Add(time.Hour), EndTime: now.Add(2 * time.Hour), Status: StatusReceived},
+		}
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
+
+		req := buildRequest("org-id", ``, "", "")
+		params := req.URL.Query()
+		params.Set("request_id", "test-request")
+		req.URL.RawQuery = params.Encode()
+
+		w := httptest.NewRecorder()
+		h.GetDeleteRequestHandler(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "org-id", store.getUser)
+		require.Equal(t, "test-request", store.getID)
+
+		var result DeleteRequest
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Equal(t, DeleteRequest{RequestID: "test-request", Status: StatusProcessing, CreatedAt: now, StartTime: now, EndTime: now.Add(2 * time.Hour)}, result)
+	})
+
+	t.Run("it returns 404 for unknown requests", func(t *testing.T) {
+		store := &mockDeleteRequestsStore{getErr: ErrDeleteRequestNotFound}
+		h := NewDeleteRequestHandler(store, time.Hour, 0, nil)
+
+		req := buildRequest("org-id", ``, "", "")
+		params := req.URL.Query()
+		params.Set("request_id", "test-request")
+		req.URL.RawQuery = params.Encode()
+
+		w := httptest.NewRecorder()
+		h.GetDeleteRequestHandler(w, req)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func buildRequest(orgID, query, start, end string) *http.Request {
 	var req *http.Request
 	if orgID == "" {
@@ -6,7 +6,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
@@ -123,13 +122,9 @@ func (is *indexSet) ListSourceFiles() []storage.IndexFile {
 }
 
 func (is *indexSet) GetSourceFile(indexFile storage.IndexFile) (string, error) {
-	decompress := storage.IsCompressedFile(indexFile.Name)
-	dst := filepath.Join(is.workingDir, indexFile.Name)
-	if decompress {
-		dst = strings.Trim(dst, gzipExtension)
-	}
+	dst := filepath.Join(is.workingDir, storage.TrimCompressionExtension(indexFile.Name))
 
-	err := storage.DownloadFileFromStorage(dst, storage.IsCompressedFile(indexFile.Name),
+	err := storage.DownloadFileFromStorage(dst, storage.CompressionForFile(indexFile.Name),
 		false, storage.LoggerWithFilename(is.logger, indexFile.Name),
 		func() (io.ReadCloser, error) {
 			return is.baseIndexSet.GetFile(is.ctx, is.tableName, is.userID, indexFile.Name)
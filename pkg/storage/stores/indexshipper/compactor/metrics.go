@@ -16,6 +16,7 @@ type metrics struct {
 	compactTablesOperationLastSuccess     prometheus.Gauge
 	applyRetentionLastSuccess             prometheus.Gauge
 	compactorRunning                      prometheus.Gauge
+	compactorOwnedTables                  prometheus.Gauge
 }
 
 func newMetrics(r prometheus.Registerer) *metrics {
@@ -45,6 +46,11 @@ func newMetrics(r prometheus.Registerer) *metrics {
 			Name:      "compactor_running",
 			Help:      "Value will be 1 if compactor is currently running on this instance",
 		}),
+		compactorOwnedTables: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_boltdb_shipper",
+			Name:      "compactor_owned_tables",
+			Help:      "Number of tables compacted by this instance in its last run, when the tables are sharded across the compactors",
+		}),
 	}
 
 	return &m
@@ -0,0 +1,504 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/ring"
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletion"
+	shipper_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// When the tables are sharded across the compactors, each compactor compacts, applies retention and applies the
+// delete requests to the tables it owns in the ring, which is the one owning the hash of the table name. The ownership
+// is checked again right before compacting each table, so that a compactor stops compacting the tables it lost on a
+// ring change.
+//
+// Only the leader holds the delete requests table and serves the delete requests API. It picks the delete requests to
+// process, which the other compactors read from the last uploaded copy of the table. Each compactor records the tables
+// it applied each delete request being processed to in its own progress file, stored with the delete requests, and the
+// leader marks a delete request as processed once it is applied to all the tables.
+
+const (
+	// ringNumTokensWithSharding is the number of tokens of each compactor when the tables are sharded across them,
+	// enough for the tables to be spread evenly.
+	ringNumTokensWithSharding = 128
+
+	deletionProgressFilePrefix = "deletion_progress_"
+)
+
+var errNotLeader = errors.New("this compactor is not the leader of the compactors ring, which serves the delete requests")
+
+func numRingTokens(cfg Config) int {
+	if cfg.ShardingEnabled {
+		return ringNumTokensWithSharding
+	}
+	return ringNumTokens
+}
+
+// isLeader returns whether this compactor owns the leader key of the ring.
+func (c *Compactor) isLeader() (bool, error) {
+	return c.ownsKey(ringKeyOfLeader)
+}
+
+// ownsTable returns whether this compactor owns a table when the tables are sharded across the compactors.
+func (c *Compactor) ownsTable(tableName string) (bool, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(tableName))
+	return c.ownsKey(h.Sum32())
+}
+
+func (c *Compactor) ownsKey(key uint32) (bool, error) {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	rs, err := c.ring.Get(key, ring.Write, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return false, err
+	}
+
+	addrs := rs.GetAddresses()
+	if len(addrs) != 1 {
+		return false, fmt.Errorf("too many addresses (more that one) returned by the ring for key %d", key)
+	}
+	return c.ringLifecycler.GetInstanceAddr() == addrs[0], nil
+}
+
+// ownedTables returns the tables this compactor owns.
+func (c *Compactor) ownedTables(tables []string) ([]string, error) {
+	var owned []string
+	for _, tableName := range tables {
+		ok, err := c.ownsTable(tableName)
+		if err != nil {
+			return nil, errors.Wrap(err, "error asking ring for the owner of the table")
+		}
+		if ok {
+			owned = append(owned, tableName)
+		}
+	}
+	return owned, nil
+}
+
+// updateShardedRole opens the delete requests table when this compactor becomes the leader, and releases it when it
+// stops being the leader.
+func (c *Compactor) updateShardedRole() error {
+	if c.shardedDeleteRequestsStore == nil {
+		return nil
+	}
+
+	leader, err := c.isLeader()
+	if err != nil {
+		return errors.Wrap(err, "error asking ring for the leader of the compactors")
+	}
+	return c.shardedDeleteRequestsStore.setLeader(leader)
+}
+
+// startShardedDeletion prepares a compaction run applying the delete requests when the tables are sharded: the leader
+// picks the delete requests to process from its table, while the other compactors read the ones the leader is
+// processing from the last uploaded table.
+func (c *Compactor) startShardedDeletion() error {
+	if err := c.updateShardedRole(); err != nil {
+		return err
+	}
+
+	leader := c.shardedDeleteRequestsStore.isLeader()
+	if !leader {
+		if err := c.shardedDeleteRequestsStore.refresh(); err != nil {
+			return errors.Wrap(err, "failed to download the delete requests of the leader")
+		}
+	}
+	c.deleteRequestsManager.SetShardedRole(leader)
+	return nil
+}
+
+// finishShardedDeletion records the tables the delete requests being processed were applied to, out of all the tables
+// to compact, and marks the delete requests applied to all of them as processed on the leader.
+func (c *Compactor) finishShardedDeletion(ctx context.Context, allTables, appliedTables []string) error {
+	deleteRequests := c.deleteRequestsManager.ProcessingRequests()
+	if err := c.recordDeletionProgress(ctx, deleteRequests, appliedTables); err != nil {
+		return errors.Wrap(err, "failed to record the deletion progress")
+	}
+	if !c.shardedDeleteRequestsStore.isLeader() {
+		return nil
+	}
+
+	applied, err := c.appliedDeleteRequests(ctx, deleteRequests, allTables)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the deletion progress")
+	}
+	c.deleteRequestsManager.MarkProcessed(applied)
+
+	processing, err := c.shardedDeleteRequestsStore.GetDeleteRequestsByStatus(ctx, deletion.StatusProcessing)
+	if err != nil {
+		return err
+	}
+	return c.removeDeletionProgress(ctx, processing)
+}
+
+// deletionProgress maps the delete requests being processed to the tables a compactor applied them to.
+type deletionProgress map[string][]string
+
+func deleteRequestKey(req deletion.DeleteRequest) string {
+	return fmt.Sprintf("%s/%s/%d", req.UserID, req.RequestID, req.SequenceNum)
+}
+
+func (c *Compactor) deletionProgressFileName() string {
+	return deletionProgressFilePrefix + c.ringLifecycler.GetInstanceID() + ".json"
+}
+
+// recordDeletionProgress adds the tables to the progress of the delete requests being processed, forgetting the
+// delete requests which aren't processed anymore.
+func (c *Compactor) recordDeletionProgress(ctx context.Context, deleteRequests []deletion.DeleteRequest, tables []string) error {
+	progress, err := c.readDeletionProgress(ctx, c.deletionProgressFileName())
+	if err != nil && !c.indexStorageClient.IsFileNotFoundErr(err) {
+		return err
+	}
+
+	updated := deletionProgress{}
+	for _, req := range deleteRequests {
+		key := deleteRequestKey(req)
+		updated[key] = mergeTables(progress[key], tables)
+	}
+	if len(updated) == 0 {
+		if len(progress) == 0 {
+			return nil
+		}
+		return c.indexStorageClient.DeleteFile(ctx, deletion.DeleteRequestsTableName, c.deletionProgressFileName())
+	}
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		return err
+	}
+	return c.indexStorageClient.PutFile(ctx, deletion.DeleteRequestsTableName, c.deletionProgressFileName(), bytes.NewReader(data))
+}
+
+func (c *Compactor) readDeletionProgress(ctx context.Context, fileName string) (deletionProgress, error) {
+	r, err := c.indexStorageClient.GetFile(ctx, deletion.DeleteRequestsTableName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	progress := deletionProgress{}
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the deletion progress file %s", fileName)
+	}
+	return progress, nil
+}
+
+// readAllDeletionProgress reads the progress files of all the compactors.
+func (c *Compactor) readAllDeletionProgress(ctx context.Context) (map[string]deletionProgress, error) {
+	files, _, err := c.indexStorageClient.ListFiles(ctx, deletion.DeleteRequestsTableName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	progressFiles := map[string]deletionProgress{}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, deletionProgressFilePrefix) {
+			continue
+		}
+		progress, err := c.readDeletionProgress(ctx, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		progressFiles[file.Name] = progress
+	}
+	return progressFiles, nil
+}
+
+// appliedDeleteRequests returns the delete requests the compactors applied to all the tables.
+func (c *Compactor) appliedDeleteRequests(ctx context.Context, deleteRequests []deletion.DeleteRequest, tables []string) ([]deletion.DeleteRequest, error) {
+	progressFiles, err := c.readAllDeletionProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedTables := map[string]map[string]struct{}{}
+	for _, progress := range progressFiles {
+		for key, tables := range progress {
+			if appliedTables[key] == nil {
+				appliedTables[key] = map[string]struct{}{}
+			}
+			for _, table := range tables {
+				appliedTables[key][table] = struct{}{}
+			}
+		}
+	}
+
+	var applied []deletion.DeleteRequest
+	for _, req := range deleteRequests {
+		if appliedToAll(appliedTables[deleteRequestKey(req)], tables) {
+			applied = append(applied, req)
+		}
+	}
+	return applied, nil
+}
+
+// removeDeletionProgress removes the progress files of the other compactors which don't record any of the delete
+// requests still processing, such as the ones of the compactors which left the ring.
+func (c *Compactor) removeDeletionProgress(ctx context.Context, processing []deletion.DeleteRequest) error {
+	progressFiles, err := c.readAllDeletionProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	stillProcessing := make(map[string]struct{}, len(processing))
+	for _, req := range processing {
+		stillProcessing[deleteRequestKey(req)] = struct{}{}
+	}
+	for fileName, progress := range progressFiles {
+		if fileName == c.deletionProgressFileName() || recordsProcessing(progress, stillProcessing) {
+			continue
+		}
+		if err := c.indexStorageClient.DeleteFile(ctx, deletion.DeleteRequestsTableName, fileName); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to remove deletion progress file", "file", fileName, "err", err)
+		}
+	}
+	return nil
+}
+
+func appliedToAll(applied map[string]struct{}, tables []string) bool {
+	for _, table := range tables {
+		if _, ok := applied[table]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func recordsProcessing(progress deletionProgress, processing map[string]struct{}) bool {
+	for key := range progress {
+		if _, ok := processing[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeTables(a, b []string) []string {
+	merged := make([]string, 0, len(a)+len(b))
+	merged = append(merged, a...)
+	merged = append(merged, b...)
+	sort.Strings(merged)
+
+	// remove the duplicates
+	out := merged[:0]
+	for i, table := range merged {
+		if i == 0 || table != merged[i-1] {
+			out = append(out, table)
+		}
+	}
+	return out
+}
+
+// ServedByLeader wraps a delete requests API handler so that it is only served by the leader when the tables are
+// sharded across the compactors, as only the leader holds the delete requests table.
+func (c *Compactor) ServedByLeader(h http.HandlerFunc) http.HandlerFunc {
+	if !c.cfg.ShardingEnabled {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		leader, err := c.isLeader()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !leader {
+			http.Error(w, errNotLeader.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// shardedDeleteRequestsStore holds the delete requests when the tables are sharded across the compactors: the delete
+// requests table on the leader, and a read-only copy of the last uploaded table on the other compactors, so that only
+// the leader uploads the table.
+type shardedDeleteRequestsStore struct {
+	workingDir         string
+	indexStorageClient shipper_storage.Client
+
+	mtx    sync.RWMutex
+	store  deletion.DeleteRequestsStore
+	leader bool
+}
+
+func newShardedDeleteRequestsStore(workingDir string, indexStorageClient shipper_storage.Client) *shardedDeleteRequestsStore {
+	return &shardedDeleteRequestsStore{
+		workingDir:         workingDir,
+		indexStorageClient: indexStorageClient,
+	}
+}
+
+func (s *shardedDeleteRequestsStore) isLeader() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.leader
+}
+
+// setLeader opens the delete requests table, downloading its latest copy, when the compactor becomes the leader, and
+// uploads and closes it when it stops being the leader.
+func (s *shardedDeleteRequestsStore) setLeader(leader bool) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if leader == s.leader && (s.store != nil || !leader) {
+		return nil
+	}
+
+	s.stop()
+	s.leader = false
+	if !leader {
+		return nil
+	}
+
+	// the local copy of the table is stale if this compactor was the leader before
+	if err := os.RemoveAll(s.workingDir); err != nil {
+		return err
+	}
+	store, err := deletion.NewDeleteStore(s.workingDir, s.indexStorageClient)
+	if err != nil {
+		return err
+	}
+	s.store = store
+	s.leader = true
+	level.Info(util_log.Logger).Log("msg", "this compactor is the leader, serving the delete requests")
+	return nil
+}
+
+// refresh downloads the last uploaded delete requests table, on the compactors which aren't the leader.
+func (s *shardedDeleteRequestsStore) refresh() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.leader {
+		return nil
+	}
+	s.stop()
+	store, err := deletion.NewReadOnlyDeleteStore(s.workingDir, s.indexStorageClient)
+	if err != nil {
+		return err
+	}
+	s.store = store
+	return nil
+}
+
+func (s *shardedDeleteRequestsStore) stop() {
+	if s.store != nil {
+		s.store.Stop()
+		s.store = nil
+	}
+}
+
+func (s *shardedDeleteRequestsStore) get(write bool) (deletion.DeleteRequestsStore, error) {
+	if s.store == nil || (write && !s.leader) {
+		return nil, errNotLeader
+	}
+	return s.store, nil
+}
+
+func (s *shardedDeleteRequestsStore) AddDeleteRequestGroup(ctx context.Context, reqs []deletion.DeleteRequest) ([]deletion.DeleteRequest, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(true)
+	if err != nil {
+		return nil, err
+	}
+	return store.AddDeleteRequestGroup(ctx, reqs)
+}
+
+func (s *shardedDeleteRequestsStore) GetDeleteRequestsByStatus(ctx context.Context, status deletion.DeleteRequestStatus) ([]deletion.DeleteRequest, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(false)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetDeleteRequestsByStatus(ctx, status)
+}
+
+func (s *shardedDeleteRequestsStore) GetAllDeleteRequestsForUser(ctx context.Context, userID string) ([]deletion.DeleteRequest, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(false)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetAllDeleteRequestsForUser(ctx, userID)
+}
+
+func (s *shardedDeleteRequestsStore) UpdateStatus(ctx context.Context, req deletion.DeleteRequest, newStatus deletion.DeleteRequestStatus) error {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(true)
+	if err != nil {
+		return err
+	}
+	return store.UpdateStatus(ctx, req, newStatus)
+}
+
+func (s *shardedDeleteRequestsStore) GetDeleteRequestGroup(ctx context.Context, userID, requestID string) ([]deletion.DeleteRequest, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(false)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetDeleteRequestGroup(ctx, userID, requestID)
+}
+
+func (s *shardedDeleteRequestsStore) RemoveDeleteRequests(ctx context.Context, reqs []deletion.DeleteRequest) error {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(true)
+	if err != nil {
+		return err
+	}
+	return store.RemoveDeleteRequests(ctx, reqs)
+}
+
+func (s *shardedDeleteRequestsStore) GetCacheGenerationNumber(ctx context.Context, userID string) (string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	store, err := s.get(false)
+	if err != nil {
+		return "", err
+	}
+	return store.GetCacheGenerationNumber(ctx, userID)
+}
+
+func (s *shardedDeleteRequestsStore) Stop() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.stop()
+	s.leader = false
+}
+
+func (s *shardedDeleteRequestsStore) Name() string {
+	return "sharded"
+}
@@ -0,0 +1,230 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletion"
+	shipper_storage "github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+)
+
+func setupShardedTestCompactor(t *testing.T, tempDir string, port int) *Compactor {
+	cfg := Config{}
+	flagext.DefaultValues(&cfg)
+	cfg.WorkingDirectory = filepath.Join(tempDir, workingDirName, fmt.Sprint(port))
+	cfg.SharedStoreType = "filesystem"
+	cfg.ShardingEnabled = true
+	// the compactions are run by the test
+	cfg.CompactionInterval = time.Hour
+	cfg.CompactorRing.KVStore.Store = "inmemory"
+	cfg.CompactorRing.InstanceID = fmt.Sprintf("compactor-%d", port)
+	cfg.CompactorRing.InstanceAddr = "127.0.0.1"
+	cfg.CompactorRing.InstancePort = port
+	require.NoError(t, cfg.Validate())
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	indexType := "dummy"
+	c, err := NewCompactor(cfg, objectClient, config.SchemaConfig{
+		Configs: []config.PeriodConfig{
+			{
+				From:        config.DayTime{Time: model.Time(0)},
+				IndexType:   indexType,
+				IndexTables: config.PeriodicTableConfig{Prefix: indexTablePrefix},
+			},
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	c.RegisterIndexCompactor(indexType, testIndexCompactor{})
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), c))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), c))
+	})
+	return c
+}
+
+// setupShardedTestCompactors sets up two compactors sharing the tables, once they see each other in the ring.
+func setupShardedTestCompactors(t *testing.T, tempDir string) []*Compactor {
+	compactors := []*Compactor{
+		setupShardedTestCompactor(t, tempDir, 9001),
+		setupShardedTestCompactor(t, tempDir, 9002),
+	}
+	for _, c := range compactors {
+		c := c
+		require.Eventually(t, func() bool {
+			rs, err := c.ring.GetAllHealthy(ring.Write)
+			return err == nil && len(rs.Instances) == len(compactors)
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	return compactors
+}
+
+func TestCompactor_ShardedRunCompaction(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tablesPath := filepath.Join(tempDir, "index")
+	commonDBsConfig := IndexesConfig{NumUnCompactedFiles: 5}
+	perUserDBsConfig := PerUserIndexesConfig{}
+
+	daySeconds := int64(24 * time.Hour / time.Second)
+	tableNumEnd := time.Now().Unix() / daySeconds
+	tableNumStart := tableNumEnd - 9
+
+	var tables []string
+	for i := tableNumStart; i <= tableNumEnd; i++ {
+		name := fmt.Sprintf("%s%d", indexTablePrefix, i)
+		SetupTable(t, filepath.Join(tablesPath, name), commonDBsConfig, perUserDBsConfig)
+		tables = append(tables, name)
+	}
+
+	compactors := setupShardedTestCompactors(t, tempDir)
+
+	// each table is owned by a single compactor
+	for _, table := range tables {
+		owners := 0
+		for _, c := range compactors {
+			ok, err := c.ownsTable(table)
+			require.NoError(t, err)
+			if ok {
+				owners++
+			}
+		}
+		require.Equal(t, 1, owners, table)
+	}
+
+	// each compactor compacts its own tables
+	compacted := func(table string) bool {
+		files, err := os.ReadDir(filepath.Join(tablesPath, table))
+		require.NoError(t, err)
+		return len(files) == 1
+	}
+	for i, c := range compactors {
+		require.NoError(t, c.RunCompaction(context.Background(), false))
+		for _, table := range tables {
+			ok, err := c.ownsTable(table)
+			require.NoError(t, err)
+			if ok {
+				require.True(t, compacted(table), table)
+			} else if i == 0 {
+				require.False(t, compacted(table), table)
+			}
+		}
+	}
+
+	for _, table := range tables {
+		verifyCompactedIndexTable(t, commonDBsConfig, perUserDBsConfig, filepath.Join(tablesPath, table))
+	}
+}
+
+func TestCompactor_ShardedDeletionProgress(t *testing.T) {
+	compactors := setupShardedTestCompactors(t, t.TempDir())
+	ctx := context.Background()
+
+	req1 := deletion.DeleteRequest{UserID: "user", RequestID: "1"}
+	req2 := deletion.DeleteRequest{UserID: "user", RequestID: "2"}
+	req2Shard := deletion.DeleteRequest{UserID: "user", RequestID: "2", SequenceNum: 1}
+	tables := []string{"table_1", "table_2", "table_3"}
+
+	// each compactor applies the delete requests it knows about to its own tables
+	require.NoError(t, compactors[0].recordDeletionProgress(ctx, []deletion.DeleteRequest{req1, req2, req2Shard}, []string{"table_1"}))
+	require.NoError(t, compactors[0].recordDeletionProgress(ctx, []deletion.DeleteRequest{req1, req2, req2Shard}, []string{"table_2"}))
+	require.NoError(t, compactors[1].recordDeletionProgress(ctx, []deletion.DeleteRequest{req1, req2}, []string{"table_3"}))
+
+	applied, err := compactors[0].appliedDeleteRequests(ctx, []deletion.DeleteRequest{req1, req2, req2Shard}, tables)
+	require.NoError(t, err)
+	require.Equal(t, []deletion.DeleteRequest{req1, req2}, applied)
+
+	// a table created meanwhile must be compacted too
+	applied, err = compactors[0].appliedDeleteRequests(ctx, []deletion.DeleteRequest{req1, req2, req2Shard}, append(tables, "table_4"))
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	// the progress of the delete requests which aren't processing anymore is forgotten
+	require.NoError(t, compactors[1].recordDeletionProgress(ctx, []deletion.DeleteRequest{req2Shard}, nil))
+	progress, err := compactors[1].readDeletionProgress(ctx, compactors[1].deletionProgressFileName())
+	require.NoError(t, err)
+	require.Equal(t, deletionProgress{deleteRequestKey(req2Shard): {}}, progress)
+
+	// the leader removes the progress files only recording processed delete requests
+	require.NoError(t, compactors[0].removeDeletionProgress(ctx, []deletion.DeleteRequest{req1}))
+	progressFiles, err := compactors[0].readAllDeletionProgress(ctx)
+	require.NoError(t, err)
+	require.Len(t, progressFiles, 1)
+	require.Contains(t, progressFiles, compactors[0].deletionProgressFileName())
+}
+
+func TestShardedDeleteRequestsStore(t *testing.T) {
+	tempDir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+	indexStorageClient := shipper_storage.NewIndexStorageClient(objectClient, "index/")
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	leader := newShardedDeleteRequestsStore(filepath.Join(tempDir, "leader"), indexStorageClient)
+	follower := newShardedDeleteRequestsStore(filepath.Join(tempDir, "follower"), indexStorageClient)
+	defer leader.Stop()
+	defer follower.Stop()
+
+	// only the leader accepts delete requests
+	req := deletion.DeleteRequest{UserID: "user", Query: `{foo="bar"}`, StartTime: 0, EndTime: model.Now()}
+	_, err = follower.AddDeleteRequestGroup(ctx, []deletion.DeleteRequest{req})
+	require.ErrorIs(t, err, errNotLeader)
+
+	require.NoError(t, leader.setLeader(true))
+	added, err := leader.AddDeleteRequestGroup(ctx, []deletion.DeleteRequest{req})
+	require.NoError(t, err)
+	require.NoError(t, leader.UpdateStatus(ctx, added[0], deletion.StatusProcessing))
+
+	// the other compactors read the delete requests the leader uploaded when it stopped being the leader
+	require.NoError(t, leader.setLeader(false))
+	require.NoError(t, follower.refresh())
+	processing, err := follower.GetDeleteRequestsByStatus(ctx, deletion.StatusProcessing)
+	require.NoError(t, err)
+	require.Len(t, processing, 1)
+	require.Equal(t, added[0].RequestID, processing[0].RequestID)
+	require.ErrorIs(t, follower.UpdateStatus(ctx, processing[0], deletion.StatusProcessed), errNotLeader)
+
+	// the new leader starts from the latest uploaded table
+	require.NoError(t, follower.setLeader(true))
+	require.NoError(t, follower.UpdateStatus(ctx, processing[0], deletion.StatusProcessed))
+	processed, err := follower.GetDeleteRequestsByStatus(ctx, deletion.StatusProcessed)
+	require.NoError(t, err)
+	require.Len(t, processed, 1)
+}
+
+func TestCompactor_ServedByLeader(t *testing.T) {
+	compactors := setupShardedTestCompactors(t, t.TempDir())
+
+	served := 0
+	for _, c := range compactors {
+		rec := httptest.NewRecorder()
+		c.ServedByLeader(func(w http.ResponseWriter, r *http.Request) {})(rec, httptest.NewRequest("GET", "/loki/api/v1/delete", nil))
+
+		leader, err := c.isLeader()
+		require.NoError(t, err)
+		if leader {
+			served++
+			require.Equal(t, http.StatusOK, rec.Code)
+		} else {
+			require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		}
+	}
+	require.Equal(t, 1, served)
+}
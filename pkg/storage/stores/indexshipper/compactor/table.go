@@ -19,10 +19,6 @@ import (
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
-const (
-	gzipExtension = ".gz"
-)
-
 var errRetentionFileCountNotOne = fmt.Errorf("can't apply retention when index file count is not one")
 
 type tableExpirationChecker interface {
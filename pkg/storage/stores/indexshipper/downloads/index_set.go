@@ -7,13 +7,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/concurrency"
+	"go.uber.org/atomic"
 
 	"github.com/grafana/loki/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
@@ -23,20 +23,24 @@ import (
 )
 
 const (
-	gzipExtension  = ".gz"
 	maxSyncRetries = 1
 )
 
-var errIndexListCacheTooStale = fmt.Errorf("index list cache too stale")
+var (
+	errIndexListCacheTooStale = fmt.Errorf("index list cache too stale")
+	errIndexSetEvicted        = fmt.Errorf("index set evicted from the cache")
+)
 
 type IndexSet interface {
 	Init(forQuerying bool) error
 	Close()
 	ForEach(ctx context.Context, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error
 	DropAllDBs() error
+	Evict() error
 	Err() error
 	LastUsedAt() time.Time
 	UpdateLastUsedAt()
+	Size() int64
 	Sync(ctx context.Context) (err error)
 	AwaitReady(ctx context.Context) error
 }
@@ -50,10 +54,13 @@ type indexSet struct {
 	cacheLocation     string
 	logger            log.Logger
 
-	lastUsedAt time.Time
+	lastUsedAt *atomic.Time
 	index      map[string]index.Index
 	indexMtx   *mtxWithReadiness
 	err        error
+	// evicted is set once the index set is evicted from the cache, so that the queries which got it before get the
+	// index set again.
+	evicted bool
 
 	cancelFunc context.CancelFunc // helps with cancellation of initialization if we are asked to stop.
 }
@@ -78,7 +85,7 @@ func NewIndexSet(tableName, userID, cacheLocation string, baseIndexSet storage.I
 		userID:            userID,
 		cacheLocation:     cacheLocation,
 		logger:            logger,
-		lastUsedAt:        time.Now(),
+		lastUsedAt:        atomic.NewTime(time.Now()),
 		index:             map[string]index.Index{},
 		indexMtx:          newMtxWithReadiness(),
 		cancelFunc:        func() {},
@@ -179,6 +186,11 @@ func (t *indexSet) ForEach(ctx context.Context, doneChan <-chan struct{}, callba
 		return err
 	}
 
+	if t.evicted {
+		t.indexMtx.rUnlock()
+		return errIndexSetEvicted
+	}
+
 	go func() {
 		<-doneChan
 		t.indexMtx.rUnlock()
@@ -213,6 +225,29 @@ func (t *indexSet) DropAllDBs() error {
 	return os.RemoveAll(t.cacheLocation)
 }
 
+// Evict closes reference to all the open index and removes the local files, to free up space in the cache.
+// It waits for the queries using the index set to finish, and the queries which got the index set before it got
+// evicted get errIndexSetEvicted. The directory of the common index set is kept since it holds the user index sets.
+func (t *indexSet) Evict() error {
+	err := t.indexMtx.lock(context.Background())
+	if err != nil {
+		return err
+	}
+	defer t.indexMtx.unlock()
+
+	t.evicted = true
+	for fileName := range t.index {
+		if err := t.cleanupDB(fileName); err != nil {
+			return err
+		}
+	}
+
+	if t.userID == CommonTenantIdentifier {
+		return nil
+	}
+	return os.RemoveAll(t.cacheLocation)
+}
+
 // Err returns the err which is usually set when there was any issue in Init.
 func (t *indexSet) Err() error {
 	return t.err
@@ -220,11 +255,34 @@ func (t *indexSet) Err() error {
 
 // LastUsedAt returns the time at which table was last used for querying.
 func (t *indexSet) LastUsedAt() time.Time {
-	return t.lastUsedAt
+	return t.lastUsedAt.Load()
 }
 
 func (t *indexSet) UpdateLastUsedAt() {
-	t.lastUsedAt = time.Now()
+	t.lastUsedAt.Store(time.Now())
+}
+
+// Size returns the size of the index files on disk, or 0 while they are being downloaded.
+func (t *indexSet) Size() int64 {
+	select {
+	case <-t.indexMtx.ready:
+	default:
+		return 0
+	}
+
+	t.indexMtx.mtx.RLock()
+	defer t.indexMtx.mtx.RUnlock()
+
+	var size int64
+	for _, idx := range t.index {
+		fi, err := os.Stat(idx.Path())
+		if err != nil {
+			continue
+		}
+		size += fi.Size()
+	}
+
+	return size
 }
 
 // cleanupDB closes and removes the local file.
@@ -301,6 +359,16 @@ func (t *indexSet) sync(ctx context.Context, lock, bypassListCache bool) (err er
 		defer t.indexMtx.unlock()
 	}
 
+	if t.evicted {
+		// the index set got evicted while the files were being downloaded.
+		for _, fileName := range downloadedFiles {
+			if err := os.Remove(filepath.Join(t.cacheLocation, fileName)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
 	for _, fileName := range downloadedFiles {
 		filePath := filepath.Join(t.cacheLocation, fileName)
 		idx, err := t.openIndexFileFunc(filePath)
@@ -342,7 +410,7 @@ func (t *indexSet) checkStorageForUpdates(ctx context.Context, lock, bypassListC
 	}
 
 	for _, file := range files {
-		normalized := strings.TrimSuffix(file.Name, gzipExtension)
+		normalized := storage.TrimCompressionExtension(file.Name)
 		listedDBs[normalized] = struct{}{}
 
 		// Checking whether file was already downloaded, if not, download it.
@@ -367,14 +435,10 @@ func (t *indexSet) AwaitReady(ctx context.Context) error {
 }
 
 func (t *indexSet) downloadFileFromStorage(ctx context.Context, fileName, folderPathForTable string) (string, error) {
-	decompress := storage.IsCompressedFile(fileName)
-	dst := filepath.Join(folderPathForTable, fileName)
-	if decompress {
-		dst = strings.Trim(dst, gzipExtension)
-	}
+	dst := filepath.Join(folderPathForTable, storage.TrimCompressionExtension(fileName))
 	return filepath.Base(dst), storage.DownloadFileFromStorage(
 		dst,
-		decompress,
+		storage.CompressionForFile(fileName),
 		true,
 		storage.LoggerWithFilename(t.logger, fileName),
 		func() (io.ReadCloser, error) {
@@ -8,12 +8,20 @@ import (
 const (
 	statusFailure = "failure"
 	statusSuccess = "success"
+
+	cacheHit  = "hit"
+	cacheMiss = "miss"
 )
 
 type metrics struct {
 	queryTimeTableDownloadDurationSeconds  *prometheus.CounterVec
 	tablesSyncOperationTotal               *prometheus.CounterVec
 	tablesDownloadOperationDurationSeconds prometheus.Gauge
+	downloadRetriesTotal                   prometheus.Counter
+	downloadCircuitBreakerRejectionsTotal  prometheus.Counter
+	indexSetCacheRequestsTotal             *prometheus.CounterVec
+	cacheSizeBytes                         prometheus.Gauge
+	cacheEvictionsTotal                    prometheus.Counter
 }
 
 func newMetrics(r prometheus.Registerer) *metrics {
@@ -30,6 +38,26 @@ func newMetrics(r prometheus.Registerer) *metrics {
 			Name: "tables_download_operation_duration_seconds",
 			Help: "Time (in seconds) spent in downloading updated files for all the tables",
 		}),
+		downloadRetriesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "download_retries_total",
+			Help: "Total number of retried requests for listing or downloading index files",
+		}),
+		downloadCircuitBreakerRejectionsTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "download_circuit_breaker_rejections_total",
+			Help: "Total number of requests for listing or downloading index files rejected due to the circuit breaker of the table being open",
+		}),
+		indexSetCacheRequestsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "index_set_cache_requests_total",
+			Help: "Total number of index sets requested by queries, by whether they were already downloaded (hit) or had to be downloaded (miss)",
+		}, []string{"result"}),
+		cacheSizeBytes: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Name: "cache_size_bytes",
+			Help: "Size (in bytes) of the index files downloaded for queries, updated when downloading the index on demand with a maximum cache size",
+		}),
+		cacheEvictionsTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of index sets evicted from the cache to keep it under its maximum size",
+		}),
 	}
 
 	return m
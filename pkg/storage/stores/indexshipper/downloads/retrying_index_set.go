@@ -0,0 +1,117 @@
+package downloads
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/backoff"
+	"github.com/sony/gobreaker"
+
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+)
+
+const (
+	// number of consecutive failed requests to the object store, after retries, which open the circuit breaker of a table.
+	circuitBreakerConsecutiveFailures = 5
+	// time for which the circuit breaker of a table stays open before letting a request through to probe the object store.
+	circuitBreakerTimeout = 30 * time.Second
+)
+
+// downloadBackoffConfig is used for retrying failed requests to the object store while syncing index sets.
+var downloadBackoffConfig = backoff.Config{
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+	MaxRetries: 5,
+}
+
+// retryingIndexSet retries failed listing and fetching of files from the object store with exponential backoff and jitter.
+// Requests go through a circuit breaker shared by all the index sets of a table, which fails them fast while the object
+// store keeps failing e.g. due to throttling, so that retries from syncs and queries do not amplify the outage.
+type retryingIndexSet struct {
+	storage.IndexSet
+
+	cb      *gobreaker.CircuitBreaker
+	metrics *metrics
+	logger  log.Logger
+}
+
+func newRetryingIndexSet(indexSet storage.IndexSet, cb *gobreaker.CircuitBreaker, metrics *metrics, logger log.Logger) storage.IndexSet {
+	return &retryingIndexSet{
+		IndexSet: indexSet,
+		cb:       cb,
+		metrics:  metrics,
+		logger:   logger,
+	}
+}
+
+// newTableCircuitBreaker creates the circuit breaker for the object store requests done for a table.
+func newTableCircuitBreaker(tableName string, logger log.Logger) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:    tableName,
+		Timeout: circuitBreakerTimeout,
+		OnStateChange: func(_ string, from gobreaker.State, to gobreaker.State) {
+			level.Info(logger).Log("msg", "circuit-breaker state change", "from-state", from, "to-state", to)
+		},
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= circuitBreakerConsecutiveFailures
+		},
+	})
+}
+
+func (r *retryingIndexSet) ListFiles(ctx context.Context, tableName, userID string, bypassCache bool) ([]storage.IndexFile, error) {
+	var files []storage.IndexFile
+	err := r.do(ctx, func() (err error) {
+		files, err = r.IndexSet.ListFiles(ctx, tableName, userID, bypassCache)
+		return err
+	})
+	return files, err
+}
+
+func (r *retryingIndexSet) GetFile(ctx context.Context, tableName, userID, fileName string) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := r.do(ctx, func() (err error) {
+		rc, err = r.IndexSet.GetFile(ctx, tableName, userID, fileName)
+		return err
+	})
+	return rc, err
+}
+
+// do runs the request via the circuit breaker, retrying it with backoff on failure.
+// Missing files and cancellation of the context are not considered failures of the object store and are not retried.
+func (r *retryingIndexSet) do(ctx context.Context, request func() error) error {
+	cfg := downloadBackoffConfig
+	bk := backoff.New(ctx, cfg)
+
+	var err error
+	for {
+		_, cbErr := r.cb.Execute(func() (interface{}, error) {
+			err = request()
+			if err != nil && !r.IsFileNotFoundErr(err) && ctx.Err() == nil {
+				return nil, err
+			}
+			return nil, nil
+		})
+		switch {
+		case cbErr == gobreaker.ErrOpenState || cbErr == gobreaker.ErrTooManyRequests:
+			r.metrics.downloadCircuitBreakerRejectionsTotal.Inc()
+			return cbErr
+		case cbErr == nil:
+			return err
+		}
+
+		// don't back off after the last attempt
+		if ctx.Err() != nil || (cfg.MaxRetries > 0 && bk.NumRetries()+1 >= cfg.MaxRetries) {
+			return err
+		}
+		bk.Wait()
+		if !bk.Ongoing() {
+			return err
+		}
+
+		r.metrics.downloadRetriesTotal.Inc()
+		level.Warn(r.logger).Log("msg", "request to object store failed, retrying", "retry", bk.NumRetries(), "err", err)
+	}
+}
@@ -0,0 +1,139 @@
+package downloads
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/backoff"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+var (
+	errThrottled   = errors.New("throttled")
+	errFileMissing = errors.New("file missing")
+)
+
+// failingIndexSet fails the first failures requests
+type failingIndexSet struct {
+	storage.IndexSet
+	failures, requests int
+	err                error
+}
+
+func (f *failingIndexSet) GetFile(_ context.Context, _, _, _ string) (io.ReadCloser, error) {
+	f.requests++
+	if f.requests <= f.failures {
+		return nil, f.err
+	}
+	return io.NopCloser(nil), nil
+}
+
+func (f *failingIndexSet) IsFileNotFoundErr(err error) bool {
+	return errors.Is(err, errFileMissing)
+}
+
+func withDownloadBackoff(t *testing.T, cfg backoff.Config) {
+	orig := downloadBackoffConfig
+	downloadBackoffConfig = cfg
+	t.Cleanup(func() {
+		downloadBackoffConfig = orig
+	})
+}
+
+func TestRetryingIndexSet(t *testing.T) {
+	withDownloadBackoff(t, backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 3})
+
+	for _, tc := range []struct {
+		name             string
+		failures         int
+		err              error
+		expectedErr      error
+		expectedRequests int
+		expectedRetries  float64
+	}{
+		{
+			name:             "no failures",
+			expectedRequests: 1,
+		},
+		{
+			name:             "recovers after retries",
+			failures:         2,
+			err:              errThrottled,
+			expectedRequests: 3,
+			expectedRetries:  2,
+		},
+		{
+			name:             "gives up after max retries",
+			failures:         10,
+			err:              errThrottled,
+			expectedErr:      errThrottled,
+			expectedRequests: 3,
+			expectedRetries:  2,
+		},
+		{
+			name:             "missing files are not retried",
+			failures:         10,
+			err:              errFileMissing,
+			expectedErr:      errFileMissing,
+			expectedRequests: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newMetrics(nil)
+			base := &failingIndexSet{failures: tc.failures, err: tc.err}
+			r := newRetryingIndexSet(base, newTableCircuitBreaker(tableName, util_log.Logger), m, util_log.Logger)
+
+			_, err := r.GetFile(context.Background(), tableName, "", "file")
+			require.Equal(t, tc.expectedErr, err)
+			require.Equal(t, tc.expectedRequests, base.requests)
+			require.Equal(t, tc.expectedRetries, testutil.ToFloat64(m.downloadRetriesTotal))
+		})
+	}
+}
+
+func TestRetryingIndexSet_NoBackoffAfterLastAttempt(t *testing.T) {
+	// backing off at all would only end with the context
+	withDownloadBackoff(t, backoff.Config{MinBackoff: time.Hour, MaxBackoff: time.Hour, MaxRetries: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	base := &failingIndexSet{failures: 10, err: errThrottled}
+	r := newRetryingIndexSet(base, newTableCircuitBreaker(tableName, util_log.Logger), newMetrics(nil), util_log.Logger)
+
+	start := time.Now()
+	_, err := r.GetFile(ctx, tableName, "", "file")
+	require.Equal(t, errThrottled, err)
+	require.Equal(t, 1, base.requests)
+	require.Less(t, time.Since(start), time.Second)
+	require.NoError(t, ctx.Err())
+}
+
+func TestRetryingIndexSet_CircuitBreaker(t *testing.T) {
+	withDownloadBackoff(t, backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetries: 2 * circuitBreakerConsecutiveFailures})
+
+	m := newMetrics(nil)
+	cb := newTableCircuitBreaker(tableName, util_log.Logger)
+	base := &failingIndexSet{failures: 100, err: errThrottled}
+	r := newRetryingIndexSet(base, cb, m, util_log.Logger)
+
+	// the last retry gets rejected by the circuit breaker which got opened by the previous failures
+	_, err := r.GetFile(context.Background(), tableName, "", "file")
+	require.Equal(t, gobreaker.ErrOpenState, err)
+	require.Equal(t, circuitBreakerConsecutiveFailures, base.requests)
+	require.Equal(t, gobreaker.StateOpen, cb.State())
+
+	// index sets sharing the circuit breaker fail fast without hitting the store
+	other := &failingIndexSet{}
+	_, err = newRetryingIndexSet(other, cb, m, util_log.Logger).GetFile(context.Background(), tableName, "user", "file")
+	require.Equal(t, gobreaker.ErrOpenState, err)
+	require.Equal(t, 0, other.requests)
+	require.Equal(t, float64(2), testutil.ToFloat64(m.downloadCircuitBreakerRejectionsTotal))
+}
@@ -33,6 +33,15 @@ type Table interface {
 	DropUnusedIndex(ttl time.Duration, now time.Time) (bool, error)
 	Sync(ctx context.Context) error
 	EnsureQueryReadiness(ctx context.Context, userIDs []string) error
+	IndexSetsUsage() []IndexSetUsage
+	EvictIndexSet(userID string, lastUsedAt time.Time) (bool, error)
+}
+
+// IndexSetUsage describes the disk usage of an index set downloaded for queries.
+type IndexSetUsage struct {
+	UserID     string
+	Size       int64
+	LastUsedAt time.Time
 }
 
 // table is a collection of multiple files created for a same table by various ingesters.
@@ -54,19 +63,26 @@ type table struct {
 // NewTable just creates an instance of table without trying to load files from local storage or object store.
 // It is used for initializing table at query time.
 func NewTable(name, cacheLocation string, storageClient storage.Client, openIndexFileFunc index.OpenIndexFileFunc, metrics *metrics) Table {
-	table := table{
+	table := newTable(name, cacheLocation, storageClient, openIndexFileFunc, metrics)
+	return &table
+}
+
+func newTable(name, cacheLocation string, storageClient storage.Client, openIndexFileFunc index.OpenIndexFileFunc, metrics *metrics) table {
+	logger := log.With(util_log.Logger, "table-name", name)
+	// all the index sets of the table share the circuit breaker since they are likely to be throttled together
+	cb := newTableCircuitBreaker(name, logger)
+
+	return table{
 		name:               name,
 		cacheLocation:      cacheLocation,
 		storageClient:      storageClient,
-		baseUserIndexSet:   storage.NewIndexSet(storageClient, true),
-		baseCommonIndexSet: storage.NewIndexSet(storageClient, false),
-		logger:             log.With(util_log.Logger, "table-name", name),
+		baseUserIndexSet:   newRetryingIndexSet(storage.NewIndexSet(storageClient, true), cb, metrics, logger),
+		baseCommonIndexSet: newRetryingIndexSet(storage.NewIndexSet(storageClient, false), cb, metrics, logger),
+		logger:             logger,
 		openIndexFileFunc:  openIndexFileFunc,
 		metrics:            metrics,
 		indexSets:          map[string]IndexSet{},
 	}
-
-	return &table
 }
 
 // LoadTable loads a table from local storage(syncs the table too if we have it locally) or downloads it from the shared store.
@@ -82,17 +98,7 @@ func LoadTable(name, cacheLocation string, storageClient storage.Client, openInd
 		return nil, err
 	}
 
-	table := table{
-		name:               name,
-		cacheLocation:      cacheLocation,
-		storageClient:      storageClient,
-		baseUserIndexSet:   storage.NewIndexSet(storageClient, true),
-		baseCommonIndexSet: storage.NewIndexSet(storageClient, false),
-		logger:             log.With(util_log.Logger, "table-name", name),
-		indexSets:          map[string]IndexSet{},
-		openIndexFileFunc:  openIndexFileFunc,
-		metrics:            metrics,
-	}
+	table := newTable(name, cacheLocation, storageClient, openIndexFileFunc, metrics)
 
 	level.Debug(table.logger).Log("msg", fmt.Sprintf("opening locally present files for table %s", name), "files", fmt.Sprint(dirEntries))
 
@@ -162,7 +168,18 @@ func (t *table) ForEach(ctx context.Context, userID string, doneChan <-chan stru
 			return indexSet.Err()
 		}
 
+		indexSet.UpdateLastUsedAt()
 		err = indexSet.ForEach(ctx, doneChan, callback)
+		for errors.Is(err, errIndexSetEvicted) {
+			// the index set got evicted from the cache after we got it, get it again to download it back.
+			t.removeIndexSet(ids[i], indexSet)
+			indexSet, err = t.getOrCreateIndexSet(ctx, ids[i])
+			if err != nil {
+				return err
+			}
+			indexSet.UpdateLastUsedAt()
+			err = indexSet.ForEach(ctx, doneChan, callback)
+		}
 		if err != nil {
 			return err
 		}
@@ -171,6 +188,28 @@ func (t *table) ForEach(ctx context.Context, userID string, doneChan <-chan stru
 	return nil
 }
 
+func (t *table) getOrCreateIndexSet(ctx context.Context, id string) (IndexSet, error) {
+	sets, err := t.getOrCreateIndexSets(ctx, []string{id}, true)
+	if err != nil {
+		return nil, err
+	}
+	if sets[0].Err() != nil {
+		t.cleanupBrokenIndexSet(ctx, id)
+		return nil, sets[0].Err()
+	}
+	return sets[0], nil
+}
+
+// removeIndexSet removes the index set of the given user from the table, unless it got replaced already.
+func (t *table) removeIndexSet(userID string, indexSet IndexSet) {
+	t.indexSetsMtx.Lock()
+	defer t.indexSetsMtx.Unlock()
+
+	if t.indexSets[userID] == indexSet {
+		delete(t.indexSets, userID)
+	}
+}
+
 func (t *table) findExpiredIndexSets(ttl time.Duration, now time.Time) []string {
 	t.indexSetsMtx.RLock()
 	defer t.indexSetsMtx.RUnlock()
@@ -231,6 +270,43 @@ func (t *table) DropUnusedIndex(ttl time.Duration, now time.Time) (bool, error)
 	return false, nil
 }
 
+// IndexSetsUsage returns the disk usage of the index sets of the table.
+func (t *table) IndexSetsUsage() []IndexSetUsage {
+	t.indexSetsMtx.RLock()
+	defer t.indexSetsMtx.RUnlock()
+
+	usage := make([]IndexSetUsage, 0, len(t.indexSets))
+	for userID, indexSet := range t.indexSets {
+		usage = append(usage, IndexSetUsage{
+			UserID:     userID,
+			Size:       indexSet.Size(),
+			LastUsedAt: indexSet.LastUsedAt(),
+		})
+	}
+
+	return usage
+}
+
+// EvictIndexSet evicts the index set of the given user to free up space in the cache, unless it got used since
+// lastUsedAt. The index set is evicted without holding the lock of the table, since it waits for the queries using it
+// to finish. It returns whether the index set got evicted.
+func (t *table) EvictIndexSet(userID string, lastUsedAt time.Time) (bool, error) {
+	t.indexSetsMtx.RLock()
+	indexSet, ok := t.indexSets[userID]
+	t.indexSetsMtx.RUnlock()
+	if !ok || indexSet.LastUsedAt().After(lastUsedAt) {
+		return false, nil
+	}
+
+	level.Info(t.logger).Log("msg", fmt.Sprintf("evicting index set %s from the cache", userID))
+	if err := indexSet.Evict(); err != nil {
+		return false, err
+	}
+
+	t.removeIndexSet(userID, indexSet)
+	return true, nil
+}
+
 // Sync downloads updated and new files from the storage relevant for the table and removes the deleted ones
 func (t *table) Sync(ctx context.Context) error {
 	level.Debug(t.logger).Log("msg", fmt.Sprintf("syncing files for table %s", t.name))
@@ -277,12 +353,23 @@ func (t *table) getOrCreateIndexSets(ctx context.Context, ids []string, forQuery
 
 	// happy path: all required index sets were found
 	if found == len(ids) {
+		if forQuerying {
+			t.metrics.indexSetCacheRequestsTotal.WithLabelValues(cacheHit).Add(float64(found))
+		}
 		return results, nil
 	}
 
 	t.indexSetsMtx.Lock()
 	defer t.indexSetsMtx.Unlock()
 
+	created := 0
+	if forQuerying {
+		defer func() {
+			t.metrics.indexSetCacheRequestsTotal.WithLabelValues(cacheHit).Add(float64(len(ids) - created))
+			t.metrics.indexSetCacheRequestsTotal.WithLabelValues(cacheMiss).Add(float64(created))
+		}()
+	}
+
 	for i := range withLock {
 
 		// since we're starting goroutines with references to this item
@@ -305,6 +392,7 @@ func (t *table) getOrCreateIndexSets(ctx context.Context, ids []string, forQuery
 				return nil, err
 			}
 			t.indexSets[id] = set
+			created++
 
 			// initialize the index set in async mode, it would be upto the caller to wait for its readiness using IndexSet.AwaitReady()
 			go func() {
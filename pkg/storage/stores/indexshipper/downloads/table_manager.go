@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -35,10 +36,10 @@ type Limits interface {
 	DefaultLimits() *validation.Limits
 }
 
-// IndexGatewayOwnsTenant is invoked by an IndexGateway instance and answers whether if the given tenant is assigned to this instance or not.
+// IndexGatewayOwnsTenant is invoked by an IndexGateway instance and answers whether if the index of the given tenant in the given table is assigned to this instance or not.
 //
-// It is only relevant by an IndexGateway in the ring mode and if it returns false for a given tenant, that tenant will be ignored by this IndexGateway during query readiness.
-type IndexGatewayOwnsTenant func(tenant string) bool
+// It is only relevant by an IndexGateway in the ring mode and if it returns false for a given tenant, the index of that tenant in the table will be ignored by this IndexGateway during query readiness.
+type IndexGatewayOwnsTenant func(tenant, tableName string) bool
 
 type TableManager interface {
 	Stop()
@@ -51,6 +52,10 @@ type Config struct {
 	CacheTTL          time.Duration
 	QueryReadyNumDays int
 	Limits            Limits
+	// OnDemand disables the download of the tables for query readiness, so that only the index sets used by the queries
+	// get downloaded, and evicts the least recently used ones when the cache grows above MaxCacheSize bytes.
+	OnDemand     bool
+	MaxCacheSize int64
 }
 
 type tableManager struct {
@@ -67,6 +72,9 @@ type tableManager struct {
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
+	// evictNotify is notified after queries to enforce the maximum size of the cache.
+	evictNotify chan struct{}
+
 	ownsTenant IndexGatewayOwnsTenant
 }
 
@@ -87,6 +95,7 @@ func NewTableManager(cfg Config, openIndexFileFunc index.OpenIndexFileFunc, inde
 		metrics:             newMetrics(reg),
 		ctx:                 ctx,
 		cancel:              cancel,
+		evictNotify:         make(chan struct{}, 1),
 	}
 
 	// load the existing tables first.
@@ -98,9 +107,14 @@ func NewTableManager(cfg Config, openIndexFileFunc index.OpenIndexFileFunc, inde
 	}
 
 	// download the missing tables.
-	err = tm.ensureQueryReadiness(ctx)
-	if err != nil {
-		// call Stop to close open file references.
+	if !cfg.OnDemand {
+		err = tm.ensureQueryReadiness(ctx)
+		if err != nil {
+			// call Stop to close open file references.
+			tm.Stop()
+			return nil, err
+		}
+	} else if err := tm.enforceMaxCacheSize(); err != nil {
 		tm.Stop()
 		return nil, err
 	}
@@ -128,15 +142,22 @@ func (tm *tableManager) loop() {
 			}
 
 			// we need to keep ensuring query readiness to download every days new table which would otherwise be downloaded only during queries.
-			err = tm.ensureQueryReadiness(tm.ctx)
-			if err != nil {
-				level.Error(util_log.Logger).Log("msg", "error ensuring query readiness of tables", "err", err)
+			if !tm.cfg.OnDemand {
+				err = tm.ensureQueryReadiness(tm.ctx)
+				if err != nil {
+					level.Error(util_log.Logger).Log("msg", "error ensuring query readiness of tables", "err", err)
+				}
 			}
 		case <-cacheCleanupTicker.C:
 			err := tm.cleanupCache()
 			if err != nil {
 				level.Error(util_log.Logger).Log("msg", "error cleaning up expired tables", "err", err)
 			}
+		case <-tm.evictNotify:
+			err := tm.enforceMaxCacheSize()
+			if err != nil {
+				level.Error(util_log.Logger).Log("msg", "error evicting index sets from the cache", "err", err)
+			}
 		case <-tm.ctx.Done():
 			return
 		}
@@ -160,7 +181,15 @@ func (tm *tableManager) ForEach(ctx context.Context, tableName, userID string, d
 	if err != nil {
 		return err
 	}
-	return table.ForEach(ctx, userID, doneChan, callback)
+	err = table.ForEach(ctx, userID, doneChan, callback)
+	if tm.cfg.OnDemand && tm.cfg.MaxCacheSize > 0 {
+		// the index sets may have been downloaded by the query, let the loop evict the least recently used ones if needed.
+		select {
+		case tm.evictNotify <- struct{}{}:
+		default:
+		}
+	}
+	return err
 }
 
 func (tm *tableManager) getOrCreateTable(tableName string) (Table, error) {
@@ -243,6 +272,75 @@ func (tm *tableManager) cleanupCache() error {
 	return nil
 }
 
+// enforceMaxCacheSize evicts the least recently used index sets until the cache fits in the maximum size.
+func (tm *tableManager) enforceMaxCacheSize() error {
+	if !tm.cfg.OnDemand || tm.cfg.MaxCacheSize <= 0 {
+		return nil
+	}
+
+	type indexSetUsage struct {
+		IndexSetUsage
+		table Table
+	}
+
+	var (
+		usages    []indexSetUsage
+		cacheSize int64
+	)
+	// the index sets to evict are chosen under the lock, but evicted without it since the eviction of an index set waits
+	// for the queries using it to finish.
+	tm.tablesMtx.RLock()
+	for _, table := range tm.tables {
+		for _, usage := range table.IndexSetsUsage() {
+			usages = append(usages, indexSetUsage{IndexSetUsage: usage, table: table})
+			cacheSize += usage.Size
+		}
+	}
+	tm.tablesMtx.RUnlock()
+
+	defer func() {
+		tm.metrics.cacheSizeBytes.Set(float64(cacheSize))
+	}()
+
+	if cacheSize <= tm.cfg.MaxCacheSize {
+		return nil
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].LastUsedAt.Before(usages[j].LastUsedAt)
+	})
+
+	var victims []indexSetUsage
+	for _, usage := range usages {
+		if cacheSize <= tm.cfg.MaxCacheSize {
+			break
+		}
+		// index sets being downloaded or without files don't free up any space.
+		if usage.Size == 0 {
+			continue
+		}
+		victims = append(victims, usage)
+		cacheSize -= usage.Size
+	}
+
+	for _, victim := range victims {
+		evicted, err := victim.table.EvictIndexSet(victim.UserID, victim.LastUsedAt)
+		if err != nil {
+			return err
+		}
+
+		if evicted {
+			tm.metrics.cacheEvictionsTotal.Inc()
+		} else {
+			// the index set got used since it was chosen.
+			cacheSize += victim.Size
+		}
+	}
+
+	level.Info(util_log.Logger).Log("msg", "evicted index sets from the cache", "cache_size", cacheSize, "max_cache_size", tm.cfg.MaxCacheSize)
+	return nil
+}
+
 // ensureQueryReadiness compares tables required for being query ready with the tables we already have and downloads the missing ones.
 func (tm *tableManager) ensureQueryReadiness(ctx context.Context) error {
 	start := time.Now()
@@ -304,7 +402,7 @@ func (tm *tableManager) ensureQueryReadiness(ctx context.Context) error {
 		listFilesDuration := time.Since(operationStart)
 
 		// find the users whos index we need to keep ready for querying from this table
-		usersToBeQueryReadyFor := tm.findUsersInTableForQueryReadiness(tableNumber, usersWithIndex, queryReadinessNumByUserID)
+		usersToBeQueryReadyFor := tm.findUsersInTableForQueryReadiness(tableName, tableNumber, usersWithIndex, queryReadinessNumByUserID)
 
 		// continue if both user index and common index is not required to be downloaded for query readiness
 		if len(usersToBeQueryReadyFor) == 0 && activeTableNumber-tableNumber > int64(tm.cfg.QueryReadyNumDays) {
@@ -343,7 +441,7 @@ func (tm *tableManager) ensureQueryReadiness(ctx context.Context) error {
 
 // findUsersInTableForQueryReadiness returns the users that needs their index to be query ready based on the tableNumber and
 // query readiness number provided per user
-func (tm *tableManager) findUsersInTableForQueryReadiness(tableNumber int64, usersWithIndexInTable []string,
+func (tm *tableManager) findUsersInTableForQueryReadiness(tableName string, tableNumber int64, usersWithIndexInTable []string,
 	queryReadinessNumByUserID map[string]int) []string {
 	activeTableNumber := getActiveTableNumber()
 	usersToBeQueryReadyFor := []string{}
@@ -359,7 +457,7 @@ func (tm *tableManager) findUsersInTableForQueryReadiness(tableNumber int64, use
 			continue
 		}
 
-		if tm.ownsTenant != nil && !tm.ownsTenant(userID) {
+		if tm.ownsTenant != nil && !tm.ownsTenant(userID, tableName) {
 			continue
 		}
 
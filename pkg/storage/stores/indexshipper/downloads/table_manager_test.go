@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/storage/chunk/client/local"
@@ -33,7 +34,7 @@ func buildTestStorageClient(t *testing.T, path string) storage.Client {
 
 type stopFunc func()
 
-func buildTestTableManager(t *testing.T, path string, tableRangesToHandle config.TableRanges) (*tableManager, stopFunc) {
+func buildTestTableManager(t *testing.T, path string, tableRangesToHandle config.TableRanges, opts ...func(*Config)) (*tableManager, stopFunc) {
 	indexStorageClient := buildTestStorageClient(t, path)
 	cachePath := filepath.Join(path, cacheDirName)
 
@@ -43,6 +44,9 @@ func buildTestTableManager(t *testing.T, path string, tableRangesToHandle config
 		CacheTTL:     time.Hour,
 		Limits:       &mockLimits{},
 	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
 	if tableRangesToHandle == nil {
 		tableRangesToHandle = config.TableRanges{
@@ -127,6 +131,60 @@ func TestTableManager_cleanupCache(t *testing.T) {
 	require.True(t, ok)
 }
 
+func TestTableManager_OnDemand(t *testing.T) {
+	tempDir := t.TempDir()
+	objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+
+	// the common index files are 1 byte each and the user index files 7 bytes each, i.e. 32 bytes per table.
+	tables := []string{buildTableName(0), buildTableName(1)}
+	for _, tableName := range tables {
+		for _, userID := range []string{"", "user1"} {
+			setupIndexesAtPath(t, userID, filepath.Join(objectStoragePath, tableName, userID), 1, 5)
+		}
+	}
+
+	tableManager, stopFunc := buildTestTableManager(t, tempDir, nil, func(cfg *Config) {
+		cfg.QueryReadyNumDays = 5
+		cfg.OnDemand = true
+		cfg.MaxCacheSize = 40
+	})
+	defer stopFunc()
+
+	// no table is downloaded for query readiness.
+	tableManager.tablesMtx.RLock()
+	require.Len(t, tableManager.tables, 0)
+	tableManager.tablesMtx.RUnlock()
+
+	query := func(tableName string) {
+		expectedIndexes := append(buildListOfExpectedIndexes("", 1, 5), buildListOfExpectedIndexes("user1", 1, 5)...)
+		verifyIndexForEach(t, expectedIndexes, func(callbackFunc index.ForEachIndexCallback) error {
+			doneChan := make(chan struct{})
+			defer close(doneChan)
+
+			return tableManager.ForEach(context.Background(), tableName, "user1", doneChan, callbackFunc)
+		})
+	}
+
+	query(tables[0])
+	query(tables[1])
+	require.NoError(t, tableManager.enforceMaxCacheSize())
+
+	// the index of user1 in the least recently used table got evicted.
+	tableManager.tablesMtx.RLock()
+	usage := tableManager.tables[tables[0]].IndexSetsUsage()
+	tableManager.tablesMtx.RUnlock()
+	require.Len(t, usage, 1)
+	require.Equal(t, CommonTenantIdentifier, usage[0].UserID)
+	require.Equal(t, float64(36), testutil.ToFloat64(tableManager.metrics.cacheSizeBytes))
+	require.Equal(t, float64(1), testutil.ToFloat64(tableManager.metrics.cacheEvictionsTotal))
+	require.NoDirExists(t, filepath.Join(tempDir, cacheDirName, tables[0], "user1"))
+
+	// querying the evicted index downloads it again.
+	query(tables[0])
+	require.Equal(t, float64(5), testutil.ToFloat64(tableManager.metrics.indexSetCacheRequestsTotal.WithLabelValues(cacheMiss)))
+	require.Equal(t, float64(1), testutil.ToFloat64(tableManager.metrics.indexSetCacheRequestsTotal.WithLabelValues(cacheHit)))
+}
+
 func TestTableManager_ensureQueryReadiness(t *testing.T) {
 	mockIndexStorageClient := &mockIndexStorageClient{
 		userIndexesInTables: map[string][]string{},
@@ -465,6 +523,14 @@ func (m *mockTable) DropUnusedIndex(ttl time.Duration, now time.Time) (bool, err
 	return m.tableExpired, nil
 }
 
+func (m *mockTable) IndexSetsUsage() []IndexSetUsage {
+	return nil
+}
+
+func (m *mockTable) EvictIndexSet(userID string, lastUsedAt time.Time) (bool, error) {
+	return false, nil
+}
+
 func (m *mockTable) Sync(ctx context.Context) error {
 	return nil
 }
@@ -103,6 +103,10 @@ func (m *mockIndexSet) DropAllDBs() error {
 	return nil
 }
 
+func (m *mockIndexSet) Evict() error {
+	return nil
+}
+
 func (m *mockIndexSet) LastUsedAt() time.Time {
 	return m.lastUsedAt
 }
@@ -132,6 +136,7 @@ func TestTable_ForEach(t *testing.T) {
 			table := table{
 				indexSets: map[string]IndexSet{},
 				logger:    util_log.Logger,
+				metrics:   newMetrics(nil),
 			}
 
 			table.indexSets[""] = &mockIndexSet{}
@@ -270,12 +275,12 @@ func TestTable_EnsureQueryReadiness(t *testing.T) {
 			require.Len(t, table.indexSets, len(tc.usersToDoQueryReadinessFor)+1)
 			for _, userID := range append(tc.usersToDoQueryReadinessFor, "") {
 				ensureIndexSetExistsInTable(t, table, userID)
-				require.InDelta(t, time.Now().Unix(), table.indexSets[userID].(*indexSet).lastUsedAt.Unix(), 5)
+				require.InDelta(t, time.Now().Unix(), table.indexSets[userID].(*indexSet).LastUsedAt().Unix(), 5)
 			}
 
 			// change the last used at to verify that it gets updated when we do the query readiness again
 			for _, idxSet := range table.indexSets {
-				idxSet.(*indexSet).lastUsedAt = time.Now().Add(-time.Hour)
+				idxSet.(*indexSet).lastUsedAt.Store(time.Now().Add(-time.Hour))
 			}
 
 			// Running it multiple times should not have an impact other than updating last used at time
@@ -284,13 +289,61 @@ func TestTable_EnsureQueryReadiness(t *testing.T) {
 				require.Len(t, table.indexSets, len(tc.usersToDoQueryReadinessFor)+1)
 				for _, userID := range append(tc.usersToDoQueryReadinessFor, "") {
 					ensureIndexSetExistsInTable(t, table, userID)
-					require.InDelta(t, time.Now().Unix(), table.indexSets[userID].(*indexSet).lastUsedAt.Unix(), 5)
+					require.InDelta(t, time.Now().Unix(), table.indexSets[userID].(*indexSet).LastUsedAt().Unix(), 5)
 				}
 			}
 		})
 	}
 }
 
+func TestTable_EvictIndexSet(t *testing.T) {
+	tempDir := t.TempDir()
+	objectStoragePath := filepath.Join(tempDir, objectsStorageDirName)
+	setupIndexesAtPath(t, "", filepath.Join(objectStoragePath, tableName), 0, 5)
+	setupIndexesAtPath(t, userID, filepath.Join(objectStoragePath, tableName, userID), 0, 5)
+	expectedIndexes := append(buildListOfExpectedIndexes(userID, 0, 5), buildListOfExpectedIndexes("", 0, 5)...)
+
+	table, stopFunc := buildTestTable(t, tempDir)
+	defer stopFunc()
+
+	forEach := func(callbackFunc index.ForEachIndexCallback) error {
+		doneChan := make(chan struct{})
+		defer close(doneChan)
+		return table.ForEach(context.Background(), userID, doneChan, callbackFunc)
+	}
+	verifyIndexForEach(t, expectedIndexes, forEach)
+
+	// the index set isn't evicted when it got used after it was chosen.
+	evicted, err := table.EvictIndexSet(userID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.False(t, evicted)
+
+	evicted, err = table.EvictIndexSet(userID, time.Now())
+	require.NoError(t, err)
+	require.True(t, evicted)
+	require.NoDirExists(t, filepath.Join(tempDir, cacheDirName, userID))
+	require.Len(t, table.indexSets, 1)
+
+	// the common index set keeps its directory, which holds the user index sets.
+	evicted, err = table.EvictIndexSet("", time.Now())
+	require.NoError(t, err)
+	require.True(t, evicted)
+	require.DirExists(t, filepath.Join(tempDir, cacheDirName))
+	require.Len(t, table.indexSets, 0)
+
+	// the queries download the evicted index sets again.
+	verifyIndexForEach(t, expectedIndexes, forEach)
+
+	// the queries which got an index set before it got evicted get it again.
+	table.indexSetsMtx.RLock()
+	userIndexSet := table.indexSets[userID]
+	table.indexSetsMtx.RUnlock()
+	require.NoError(t, userIndexSet.Evict())
+
+	verifyIndexForEach(t, expectedIndexes, forEach)
+	require.NotSame(t, userIndexSet, table.indexSets[userID])
+}
+
 func TestTable_Sync(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -5,10 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"math/rand"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/gogo/status"
 	"github.com/grafana/dskit/concurrency"
 	"github.com/grafana/dskit/grpcclient"
 	"github.com/grafana/dskit/ring"
@@ -16,17 +19,22 @@ import (
 	"github.com/grafana/dskit/tenant"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/weaveworks/common/instrument"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/grafana/loki/pkg/distributor/clientpool"
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
 	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
-	"github.com/grafana/loki/pkg/util"
+	listutil "github.com/grafana/loki/pkg/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
 	util_math "github.com/grafana/loki/pkg/util/math"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 const (
@@ -41,6 +49,12 @@ type IndexGatewayClientConfig struct {
 	// index_gateway YAML section and reused here.
 	Mode indexgateway.Mode `yaml:"-"`
 
+	// ShardingStrategy sets how the index is sharded across the Index Gateway instances.
+	// It is actually defined at the index_gateway YAML section and reused here.
+	//
+	// Only relevant for the ring mode.
+	ShardingStrategy indexgateway.ShardingStrategy `yaml:"-"`
+
 	// PoolConfig defines the behavior of the gRPC connection pool used to communicate
 	// with the Index Gateway.
 	//
@@ -71,6 +85,11 @@ type IndexGatewayClientConfig struct {
 	// LogGatewayRequests configures if requests sent to the gateway should be logged or not.
 	// The log messages are of type debug and contain the address of the gateway and the relevant tenant.
 	LogGatewayRequests bool `yaml:"log_gateway_requests"`
+
+	// HedgeAfter is the time after which a request still running is also sent to another Index Gateway instance.
+	//
+	// Only relevant for the ring mode.
+	HedgeAfter time.Duration `yaml:"hedge_after"`
 }
 
 // RegisterFlagsWithPrefix register client-specific flags with the given prefix.
@@ -80,6 +99,7 @@ func (i *IndexGatewayClientConfig) RegisterFlagsWithPrefix(prefix string, f *fla
 	i.GRPCClientConfig.RegisterFlagsWithPrefix(prefix+".grpc", f)
 	f.StringVar(&i.Address, prefix+".server-address", "", "Hostname or IP of the Index Gateway gRPC server running in simple mode.")
 	f.BoolVar(&i.LogGatewayRequests, prefix+".log-gateway-requests", false, "Whether requests sent to the gateway should be logged or not.")
+	f.DurationVar(&i.HedgeAfter, prefix+".hedge-after", 0, "Time after which a request to an Index Gateway instance still running is also sent to another instance of the ring, and the first response is used. 0 to disable. Only relevant for the ring mode.")
 }
 
 func (i *IndexGatewayClientConfig) RegisterFlags(f *flag.FlagSet) {
@@ -90,6 +110,7 @@ type GatewayClient struct {
 	cfg IndexGatewayClientConfig
 
 	storeGatewayClientRequestDuration *prometheus.HistogramVec
+	hedgedRequests                    prometheus.Counter
 
 	conn       *grpc.ClientConn
 	grpcClient logproto.IndexGatewayClient
@@ -97,6 +118,8 @@ type GatewayClient struct {
 	pool *ring_client.Pool
 
 	ring ring.ReadRing
+
+	latencies *replicaLatencies
 }
 
 // NewGatewayClient instantiates a new client used to communicate with an Index Gateway instance.
@@ -110,6 +133,11 @@ func NewGatewayClient(cfg IndexGatewayClientConfig, r prometheus.Registerer, log
 		Help:      "Time (in seconds) spent serving requests when using boltdb shipper store gateway",
 		Buckets:   instrument.DefBuckets,
 	}, []string{"operation", "status_code"})
+	hedged := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "index_gateway_client_hedged_requests_total",
+		Help:      "Total number of requests to an Index Gateway instance also sent to another instance because of their latency.",
+	})
 	if r != nil {
 		err := r.Register(latency)
 		if err != nil {
@@ -119,12 +147,22 @@ func NewGatewayClient(cfg IndexGatewayClientConfig, r prometheus.Registerer, log
 			}
 			latency = alreadyErr.ExistingCollector.(*prometheus.HistogramVec)
 		}
+		err = r.Register(hedged)
+		if err != nil {
+			alreadyErr, ok := err.(prometheus.AlreadyRegisteredError)
+			if !ok {
+				return nil, err
+			}
+			hedged = alreadyErr.ExistingCollector.(prometheus.Counter)
+		}
 	}
 
 	sgClient := &GatewayClient{
 		cfg:                               cfg,
 		storeGatewayClientRequestDuration: latency,
+		hedgedRequests:                    hedged,
 		ring:                              cfg.Ring,
+		latencies:                         newReplicaLatencies(),
 	}
 
 	dialOpts, err := cfg.GRPCClientConfig.DialOption(grpcclient.Instrument(sgClient.storeGatewayClientRequestDuration))
@@ -180,80 +218,216 @@ func (s *GatewayClient) QueryPages(ctx context.Context, queries []index.Query, c
 
 func (s *GatewayClient) GetChunkRef(ctx context.Context, in *logproto.GetChunkRefRequest, opts ...grpc.CallOption) (*logproto.GetChunkRefResponse, error) {
 	if s.cfg.Mode == indexgateway.RingMode {
-		var (
-			resp *logproto.GetChunkRefResponse
-			err  error
-		)
-		err = s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			resp, err = client.GetChunkRef(ctx, in, opts...)
-			return err
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return client.GetChunkRef(ctx, &req, opts...)
 		})
-		return resp, err
+		if err != nil {
+			return nil, err
+		}
+		return mergeChunkRefResponses(resps), nil
 	}
 	return s.grpcClient.GetChunkRef(ctx, in, opts...)
 }
 
 func (s *GatewayClient) GetSeries(ctx context.Context, in *logproto.GetSeriesRequest, opts ...grpc.CallOption) (*logproto.GetSeriesResponse, error) {
 	if s.cfg.Mode == indexgateway.RingMode {
-		var (
-			resp *logproto.GetSeriesResponse
-			err  error
-		)
-		err = s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			resp, err = client.GetSeries(ctx, in, opts...)
-			return err
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return getSeries(ctx, client, &req, opts...)
 		})
-		return resp, err
+		if err != nil {
+			return nil, err
+		}
+		return mergeSeriesResponses(resps, paging.Page{Limit: int(in.Limit), Token: in.PageToken})
 	}
-	return s.grpcClient.GetSeries(ctx, in, opts...)
+	return getSeries(ctx, s.grpcClient, in, opts...)
 }
 
 func (s *GatewayClient) LabelNamesForMetricName(ctx context.Context, in *logproto.LabelNamesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error) {
 	if s.cfg.Mode == indexgateway.RingMode {
-		var (
-			resp *logproto.LabelResponse
-			err  error
-		)
-		err = s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			resp, err = client.LabelNamesForMetricName(ctx, in, opts...)
-			return err
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return labelNamesForMetricName(ctx, client, &req, opts...)
 		})
-		return resp, err
+		if err != nil {
+			return nil, err
+		}
+		return mergeLabelResponses(resps, paging.Page{Limit: int(in.Limit), Token: in.PageToken})
 	}
-	return s.grpcClient.LabelNamesForMetricName(ctx, in, opts...)
+	return labelNamesForMetricName(ctx, s.grpcClient, in, opts...)
 }
 
 func (s *GatewayClient) LabelValuesForMetricName(ctx context.Context, in *logproto.LabelValuesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error) {
 	if s.cfg.Mode == indexgateway.RingMode {
-		var (
-			resp *logproto.LabelResponse
-			err  error
-		)
-		err = s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			resp, err = client.LabelValuesForMetricName(ctx, in, opts...)
-			return err
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return labelValuesForMetricName(ctx, client, &req, opts...)
 		})
-		return resp, err
+		if err != nil {
+			return nil, err
+		}
+		return mergeLabelResponses(resps, paging.Page{Limit: int(in.Limit), Token: in.PageToken})
+	}
+	return labelValuesForMetricName(ctx, s.grpcClient, in, opts...)
+}
+
+// getSeries receives the series from the streaming RPC of the gateway, falling back to
+// the unary RPC for gateways not supporting streaming yet.
+func getSeries(ctx context.Context, client logproto.IndexGatewayClient, in *logproto.GetSeriesRequest, opts ...grpc.CallOption) (*logproto.GetSeriesResponse, error) {
+	stream, err := client.StreamSeries(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logproto.GetSeriesResponse{}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return resp, nil
+		}
+		if isUnimplementedCallError(err) {
+			return client.GetSeries(ctx, in, opts...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Series = append(resp.Series, batch.Series...)
+		resp.NextPageToken = batch.NextPageToken
 	}
-	return s.grpcClient.LabelValuesForMetricName(ctx, in, opts...)
+}
+
+// labelNamesForMetricName is like getSeries, for label names.
+func labelNamesForMetricName(ctx context.Context, client logproto.IndexGatewayClient, in *logproto.LabelNamesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error) {
+	stream, err := client.StreamLabelNamesForMetricName(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := recvLabelResponse(stream)
+	if isUnimplementedCallError(err) {
+		return client.LabelNamesForMetricName(ctx, in, opts...)
+	}
+	return resp, err
+}
+
+// labelValuesForMetricName is like getSeries, for label values.
+func labelValuesForMetricName(ctx context.Context, client logproto.IndexGatewayClient, in *logproto.LabelValuesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error) {
+	stream, err := client.StreamLabelValuesForMetricName(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := recvLabelResponse(stream)
+	if isUnimplementedCallError(err) {
+		return client.LabelValuesForMetricName(ctx, in, opts...)
+	}
+	return resp, err
+}
+
+func recvLabelResponse(stream interface {
+	Recv() (*logproto.LabelResponse, error)
+}) (*logproto.LabelResponse, error) {
+	resp := &logproto.LabelResponse{}
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Values = append(resp.Values, batch.Values...)
+		resp.NextPageToken = batch.NextPageToken
+	}
+}
+
+// isUnimplementedCallError tells if the GRPC error is a gRPC error with code Unimplemented.
+func isUnimplementedCallError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	return (s.Code() == codes.Unimplemented)
 }
 
 func (s *GatewayClient) GetStats(ctx context.Context, in *logproto.IndexStatsRequest, opts ...grpc.CallOption) (*logproto.IndexStatsResponse, error) {
 	if s.cfg.Mode == indexgateway.RingMode {
-		var (
-			resp *logproto.IndexStatsResponse
-			err  error
-		)
-		err = s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			resp, err = client.GetStats(ctx, in, opts...)
-			return err
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return client.GetStats(ctx, &req, opts...)
 		})
-		return resp, err
+		if err != nil {
+			return nil, err
+		}
+		return mergeStatsResponses(resps), nil
 	}
 	return s.grpcClient.GetStats(ctx, in, opts...)
 }
 
+func (s *GatewayClient) GetVolume(ctx context.Context, in *logproto.VolumeRequest, opts ...grpc.CallOption) (*logproto.VolumeResponse, error) {
+	if s.cfg.Mode == indexgateway.RingMode {
+		resps, err := s.ringModeSplitDo(ctx, in.From, in.Through, func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error) {
+			req := *in
+			req.From, req.Through = from, through
+			return client.GetVolume(ctx, &req, opts...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		volumes := make([]*logproto.VolumeResponse, 0, len(resps))
+		for _, resp := range resps {
+			volumes = append(volumes, resp.(*logproto.VolumeResponse))
+		}
+		return seriesvolume.Merge(in.Limit, volumes...), nil
+	}
+	return s.grpcClient.GetVolume(ctx, in, opts...)
+}
+
 func (s *GatewayClient) doQueries(ctx context.Context, queries []index.Query, callback index.QueryPagesCallback) error {
+	if s.cfg.Mode != indexgateway.RingMode {
+		gatewayQueries, queryKeyQueryMap := toGatewayQueries(queries)
+		return s.clientDoQueries(ctx, gatewayQueries, queryKeyQueryMap, callback, s.grpcClient)
+	}
+
+	if s.cfg.ShardingStrategy != indexgateway.TableShardingStrategy {
+		gatewayQueries, queryKeyQueryMap := toGatewayQueries(queries)
+		return s.ringModeDo(ctx, -1, func(client logproto.IndexGatewayClient) error {
+			return s.clientDoQueries(ctx, gatewayQueries, queryKeyQueryMap, callback, client)
+		})
+	}
+
+	// Each table is owned by different Index Gateway instances, so the queries are sent per table.
+	var tables []string
+	queriesByTable := map[string][]index.Query{}
+	for _, query := range queries {
+		if _, ok := queriesByTable[query.TableName]; !ok {
+			tables = append(tables, query.TableName)
+		}
+		queriesByTable[query.TableName] = append(queriesByTable[query.TableName], query)
+	}
+	for _, table := range tables {
+		gatewayQueries, queryKeyQueryMap := toGatewayQueries(queriesByTable[table])
+		err := s.ringModeDo(ctx, indexgateway.TableNumberFromName(table), func(client logproto.IndexGatewayClient) error {
+			return s.clientDoQueries(ctx, gatewayQueries, queryKeyQueryMap, callback, client)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toGatewayQueries(queries []index.Query) ([]*logproto.IndexQuery, map[string]index.Query) {
 	queryKeyQueryMap := make(map[string]index.Query, len(queries))
 	gatewayQueries := make([]*logproto.IndexQuery, 0, len(queries))
 
@@ -267,14 +441,7 @@ func (s *GatewayClient) doQueries(ctx context.Context, queries []index.Query, ca
 			ValueEqual:       query.ValueEqual,
 		})
 	}
-
-	if s.cfg.Mode == indexgateway.RingMode {
-		return s.ringModeDo(ctx, func(client logproto.IndexGatewayClient) error {
-			return s.clientDoQueries(ctx, gatewayQueries, queryKeyQueryMap, callback, client)
-		})
-	}
-
-	return s.clientDoQueries(ctx, gatewayQueries, queryKeyQueryMap, callback, s.grpcClient)
+	return gatewayQueries, queryKeyQueryMap
 }
 
 // clientDoQueries send a query request to an Index Gateway instance using the given gRPC client.
@@ -310,49 +477,230 @@ func (s *GatewayClient) clientDoQueries(ctx context.Context, gatewayQueries []*l
 }
 
 // ringModeDo executes the given function for each Index Gateway instance in the ring mapping to the correct tenant in the index.
+// With the table sharding strategy, the instances are the ones mapping to the table with the given number for that tenant.
 // In case of callback failure, we'll try another member of the ring for that tenant ID.
-func (s *GatewayClient) ringModeDo(ctx context.Context, callback func(client logproto.IndexGatewayClient) error) error {
+// The requests whose callback can be called several times at once should use ringModeHedgedDo instead.
+func (s *GatewayClient) ringModeDo(ctx context.Context, tableNumber int64, callback func(client logproto.IndexGatewayClient) error) error {
+	userID, addrs, err := s.replicasFor(ctx, tableNumber)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		client, err := s.clientFor(addr, userID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := s.timed(ctx, addr, func() error { return callback(client) }); err != nil {
+			lastErr = err
+			level.Error(util_log.Logger).Log("msg", fmt.Sprintf("client do failed for instance %s", addr), "err", err)
+			continue
+		}
+
+		return nil
+	}
+
+	return noReplicaError(lastErr)
+}
+
+// replicasFor returns the addresses of the Index Gateway instances in the ring mapping to the tenant, in the order they
+// should be tried.
+func (s *GatewayClient) replicasFor(ctx context.Context, tableNumber int64) (string, []string, error) {
 	userID, err := tenant.TenantID(ctx)
 	if err != nil {
-		return errors.Wrap(err, "index gateway client get tenant ID")
+		return "", nil, errors.Wrap(err, "index gateway client get tenant ID")
 	}
 
 	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
 
-	key := util.TokenFor(userID, "" /* labels */)
+	key := indexgateway.TokenFor(s.cfg.ShardingStrategy, userID, tableNumber)
 	rs, err := s.ring.Get(key, ring.WriteNoExtend, bufDescs, bufHosts, bufZones)
 	if err != nil {
-		return errors.Wrap(err, "index gateway get ring")
+		return "", nil, errors.Wrap(err, "index gateway get ring")
 	}
 
 	addrs := rs.GetAddresses()
-	// shuffle addresses to make sure we don't always access the same Index Gateway instances in sequence for same tenant.
-	rand.Shuffle(len(addrs), func(i, j int) {
-		addrs[i], addrs[j] = addrs[j], addrs[i]
-	})
-	var lastErr error
-	for _, addr := range addrs {
-		if s.cfg.LogGatewayRequests {
-			level.Debug(util_log.Logger).Log("msg", "sending request to gateway", "gateway", addr, "tenant", userID)
-		}
+	// order the addresses to make sure we don't always access the same Index Gateway instances in sequence for same
+	// tenant, while favoring the fastest ones.
+	s.latencies.order(addrs)
+	return userID, addrs, nil
+}
 
-		genericClient, err := s.pool.GetClientFor(addr)
+// tableRange is the part of the time range of a request within consecutive tables owned by the same Index Gateway
+// replicas, the first of them being tableNumber.
+type tableRange struct {
+	tableNumber   int64
+	from, through model.Time
+}
+
+// tableRanges splits the time range [from, through] of a request into the ranges of the consecutive tables owned by
+// the same Index Gateway replicas for the tenant. Without the table sharding strategy, the same replicas own all the
+// tables of the tenant.
+func (s *GatewayClient) tableRanges(ctx context.Context, from, through model.Time) ([]tableRange, error) {
+	fromTable, throughTable := indexgateway.TableNumberForTime(from), indexgateway.TableNumberForTime(through)
+	if s.cfg.ShardingStrategy != indexgateway.TableShardingStrategy || fromTable >= throughTable {
+		return []tableRange{{tableNumber: fromTable, from: from, through: through}}, nil
+	}
+
+	userID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "index gateway client get tenant ID")
+	}
+
+	var (
+		ranges                       []tableRange
+		lastReplicas                 string
+		bufDescs, bufHosts, bufZones = ring.MakeBuffersForGet()
+	)
+	for table := fromTable; table <= throughTable; table++ {
+		rs, err := s.ring.Get(indexgateway.TokenFor(s.cfg.ShardingStrategy, userID, table), ring.WriteNoExtend, bufDescs, bufHosts, bufZones)
 		if err != nil {
-			level.Error(util_log.Logger).Log("msg", fmt.Sprintf("failed to get client for instance %s", addr), "err", err)
-			continue
+			return nil, errors.Wrap(err, "index gateway get ring")
 		}
+		addrs := rs.GetAddresses()
+		sort.Strings(addrs)
+		replicas := strings.Join(addrs, ",")
 
-		client := (genericClient.(logproto.IndexGatewayClient))
-		if err := callback(client); err != nil {
-			lastErr = err
-			level.Error(util_log.Logger).Log("msg", fmt.Sprintf("client do failed for instance %s", addr), "err", err)
+		end := indexgateway.TableStart(table+1) - 1
+		if end > through {
+			end = through
+		}
+		if len(ranges) > 0 && replicas == lastReplicas {
+			ranges[len(ranges)-1].through = end
 			continue
 		}
+		start := indexgateway.TableStart(table)
+		if start < from {
+			start = from
+		}
+		ranges = append(ranges, tableRange{tableNumber: table, from: start, through: end})
+		lastReplicas = replicas
+	}
+	return ranges, nil
+}
 
-		return nil
+// ringModeSplitDo is like ringModeHedgedDo, for a request over the time range [from, through]: the request is sent
+// for each of its table ranges to the replicas owning them, and their responses are returned in the order of the
+// ranges, to be merged by the caller.
+func (s *GatewayClient) ringModeSplitDo(ctx context.Context, from, through model.Time, callback func(ctx context.Context, client logproto.IndexGatewayClient, from, through model.Time) (interface{}, error)) ([]interface{}, error) {
+	ranges, err := s.tableRanges(ctx, from, through)
+	if err != nil {
+		return nil, err
+	}
+
+	resps := make([]interface{}, len(ranges))
+	err = concurrency.ForEachJob(ctx, len(ranges), maxConcurrentGrpcCalls, func(ctx context.Context, idx int) error {
+		r := ranges[idx]
+		resp, err := s.ringModeHedgedDo(ctx, r.tableNumber, func(ctx context.Context, client logproto.IndexGatewayClient) (interface{}, error) {
+			return callback(ctx, client, r.from, r.through)
+		})
+		resps[idx] = resp
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resps, nil
+}
+
+// mergeChunkRefResponses merges the chunk refs of the table ranges, the chunks overlapping several ranges being
+// returned by each of them.
+func mergeChunkRefResponses(resps []interface{}) *logproto.GetChunkRefResponse {
+	if len(resps) == 1 {
+		return resps[0].(*logproto.GetChunkRefResponse)
+	}
+
+	merged := &logproto.GetChunkRefResponse{}
+	seen := map[logproto.ChunkRef]struct{}{}
+	for _, resp := range resps {
+		for _, ref := range resp.(*logproto.GetChunkRefResponse).Refs {
+			if _, ok := seen[*ref]; ok {
+				continue
+			}
+			seen[*ref] = struct{}{}
+			merged.Refs = append(merged.Refs, ref)
+		}
+	}
+	return merged
+}
+
+// mergeSeriesResponses merges the series of the table ranges, and returns their page p. Each range returned its
+// page p, so the merged page is part of their union.
+func mergeSeriesResponses(resps []interface{}, p paging.Page) (*logproto.GetSeriesResponse, error) {
+	if len(resps) == 1 {
+		return resps[0].(*logproto.GetSeriesResponse), nil
+	}
+
+	var series []labels.Labels
+	seen := map[string]struct{}{}
+	for _, resp := range resps {
+		for _, s := range resp.(*logproto.GetSeriesResponse).Series {
+			ls := logproto.FromLabelAdaptersToLabels(s.Labels)
+			key := paging.SeriesKey(ls)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			series = append(series, ls)
+		}
+	}
+
+	series, next, err := paging.Labels(series, p)
+	if err != nil {
+		return nil, err
+	}
+	merged := &logproto.GetSeriesResponse{Series: make([]logproto.IndexSeries, len(series)), NextPageToken: next}
+	for i, ls := range series {
+		merged.Series[i] = logproto.IndexSeries{Labels: logproto.FromLabelsToLabelAdapters(ls)}
+	}
+	return merged, nil
+}
+
+// mergeLabelResponses is like mergeSeriesResponses, for label names or values.
+func mergeLabelResponses(resps []interface{}, p paging.Page) (*logproto.LabelResponse, error) {
+	if len(resps) == 1 {
+		return resps[0].(*logproto.LabelResponse), nil
+	}
+
+	values := make([][]string, 0, len(resps))
+	for _, resp := range resps {
+		values = append(values, resp.(*logproto.LabelResponse).Values)
+	}
+	merged, next, err := paging.Strings(listutil.MergeStringLists(values...), p)
+	if err != nil {
+		return nil, err
 	}
+	return &logproto.LabelResponse{Values: merged, NextPageToken: next}, nil
+}
 
-	return lastErr
+// mergeStatsResponses sums the stats of the table ranges. As the gateways don't return which streams and chunks they
+// counted, the streams in several ranges and the chunks overlapping them are counted once per range.
+func mergeStatsResponses(resps []interface{}) *logproto.IndexStatsResponse {
+	merged := &logproto.IndexStatsResponse{}
+	for _, resp := range resps {
+		stats := resp.(*logproto.IndexStatsResponse)
+		merged.Streams += stats.Streams
+		merged.Chunks += stats.Chunks
+		merged.Bytes += stats.Bytes
+		merged.Entries += stats.Entries
+	}
+	return merged
+}
+
+func (s *GatewayClient) clientFor(addr, userID string) (logproto.IndexGatewayClient, error) {
+	if s.cfg.LogGatewayRequests {
+		level.Debug(util_log.Logger).Log("msg", "sending request to gateway", "gateway", addr, "tenant", userID)
+	}
+
+	genericClient, err := s.pool.GetClientFor(addr)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", fmt.Sprintf("failed to get client for instance %s", addr), "err", err)
+		return nil, err
+	}
+	return genericClient.(logproto.IndexGatewayClient), nil
 }
 
 func (s *GatewayClient) NewWriteBatch() index.WriteBatch {
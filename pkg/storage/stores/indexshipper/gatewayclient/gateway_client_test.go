@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc"
@@ -151,6 +155,172 @@ func TestGatewayClient(t *testing.T) {
 	require.Equal(t, len(queries), numCallbacks)
 }
 
+// labelsServer streams the label values in two batches, but only supports the unary RPC for label names,
+// like gateways running a version without streaming.
+type labelsServer struct {
+	logproto.UnimplementedIndexGatewayServer
+}
+
+func (labelsServer) LabelNamesForMetricName(context.Context, *logproto.LabelNamesForMetricNameRequest) (*logproto.LabelResponse, error) {
+	return &logproto.LabelResponse{Values: []string{"foo", "bar"}, NextPageToken: "next"}, nil
+}
+
+func (labelsServer) StreamLabelValuesForMetricName(_ *logproto.LabelValuesForMetricNameRequest, server logproto.IndexGateway_StreamLabelValuesForMetricNameServer) error {
+	if err := server.Send(&logproto.LabelResponse{Values: []string{"a", "b"}}); err != nil {
+		return err
+	}
+	return server.Send(&logproto.LabelResponse{Values: []string{"c"}, NextPageToken: "next"})
+}
+
+func TestGatewayClient_StreamLabels(t *testing.T) {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	logproto.RegisterIndexGatewayServer(s, &labelsServer{})
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			t.Logf("Failed to serve: %v", err)
+		}
+	}()
+	t.Cleanup(s.GracefulStop)
+
+	var cfg IndexGatewayClientConfig
+	cfg.Mode = indexgateway.SimpleMode
+	flagext.DefaultValues(&cfg)
+	cfg.Address = lis.Addr().String()
+
+	gatewayClient, err := NewGatewayClient(cfg, prometheus.NewRegistry(), util_log.Logger)
+	require.NoError(t, err)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	values, err := gatewayClient.LabelValuesForMetricName(ctx, &logproto.LabelValuesForMetricNameRequest{})
+	require.NoError(t, err)
+	require.Equal(t, &logproto.LabelResponse{Values: []string{"a", "b", "c"}, NextPageToken: "next"}, values)
+
+	names, err := gatewayClient.LabelNamesForMetricName(ctx, &logproto.LabelNamesForMetricNameRequest{})
+	require.NoError(t, err)
+	require.Equal(t, &logproto.LabelResponse{Values: []string{"foo", "bar"}, NextPageToken: "next"}, names)
+}
+
+// tableRecordingServer answers every query with an empty response and records the tables it was queried for.
+type tableRecordingServer struct {
+	logproto.IndexGatewayServer
+
+	mtx    sync.Mutex
+	tables []string
+}
+
+func (m *tableRecordingServer) QueryIndex(request *logproto.QueryIndexRequest, server logproto.IndexGateway_QueryIndexServer) error {
+	for _, query := range request.Queries {
+		m.mtx.Lock()
+		m.tables = append(m.tables, query.TableName)
+		m.mtx.Unlock()
+
+		if err := server.Send(&logproto.QueryIndexResponse{QueryKey: util.QueryKey(index.Query{
+			TableName: query.TableName,
+			HashValue: query.HashValue,
+		})}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetChunkRef answers with a chunk of the requested time range, and a chunk returned by every request.
+func (m *tableRecordingServer) GetChunkRef(_ context.Context, req *logproto.GetChunkRefRequest) (*logproto.GetChunkRefResponse, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.tables = append(m.tables, "chunk-ref")
+	return &logproto.GetChunkRefResponse{Refs: []*logproto.ChunkRef{
+		{Fingerprint: 1},
+		{Fingerprint: 2, From: req.From, Through: req.Through},
+	}}, nil
+}
+
+func (m *tableRecordingServer) queriedTables() []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return append([]string(nil), m.tables...)
+}
+
+// mockRing assigns each token to the instance registered for it, or to the default instance.
+type mockRing struct {
+	ring.ReadRing
+
+	instances       map[uint32]string
+	defaultInstance string
+}
+
+func (r *mockRing) Get(key uint32, _ ring.Operation, _ []ring.InstanceDesc, _, _ []string) (ring.ReplicationSet, error) {
+	addr, ok := r.instances[key]
+	if !ok {
+		addr = r.defaultInstance
+	}
+	return ring.ReplicationSet{Instances: []ring.InstanceDesc{{Addr: addr}}}, nil
+}
+
+func TestGatewayClient_TableShardingStrategy(t *testing.T) {
+	var servers [2]*tableRecordingServer
+	var addrs [2]string
+	for i := range servers {
+		servers[i] = &tableRecordingServer{}
+		lis, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		s := grpc.NewServer()
+		logproto.RegisterIndexGatewayServer(s, servers[i])
+		go func() {
+			_ = s.Serve(lis)
+		}()
+		t.Cleanup(s.GracefulStop)
+		addrs[i] = lis.Addr().String()
+	}
+
+	var cfg IndexGatewayClientConfig
+	flagext.DefaultValues(&cfg)
+	cfg.Mode = indexgateway.RingMode
+	cfg.ShardingStrategy = indexgateway.TableShardingStrategy
+	// the second table of the tenant and the tables of the chunk ref request are owned by the second instance.
+	cfg.Ring = &mockRing{
+		instances: map[uint32]string{
+			indexgateway.TokenFor(indexgateway.TableShardingStrategy, "fake", 19001): addrs[1],
+			indexgateway.TokenFor(indexgateway.TableShardingStrategy, "fake", 19002): addrs[1],
+		},
+		defaultInstance: addrs[0],
+	}
+
+	gatewayClient, err := NewGatewayClient(cfg, prometheus.NewRegistry(), util_log.Logger)
+	require.NoError(t, err)
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	queries := []index.Query{
+		{TableName: "index_19000", HashValue: "a"},
+		{TableName: "index_19001", HashValue: "b"},
+		{TableName: "index_19000", HashValue: "c"},
+	}
+	err = gatewayClient.QueryPages(ctx, queries, func(index.Query, index.ReadBatchResult) bool { return true })
+	require.NoError(t, err)
+
+	through := model.TimeFromUnix(19002*24*3600 + 60)
+	_, err = gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{From: through.Add(-time.Hour), Through: through})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"index_19000", "index_19000"}, servers[0].queriedTables())
+	require.Equal(t, []string{"index_19001", "chunk-ref"}, servers[1].queriedTables())
+
+	// the request over the three tables is split between the instances owning them, the consecutive tables owned by
+	// the second instance being requested at once.
+	from := model.TimeFromUnix(19000*24*3600 + 60)
+	resp, err := gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{From: from, Through: through})
+	require.NoError(t, err)
+	require.Equal(t, []*logproto.ChunkRef{
+		{Fingerprint: 1},
+		{Fingerprint: 2, From: from, Through: model.TimeFromUnix(19001*24*3600) - 1},
+		{Fingerprint: 2, From: model.TimeFromUnix(19001 * 24 * 3600), Through: through},
+	}, resp.Refs)
+	require.Equal(t, []string{"index_19000", "index_19000", "chunk-ref"}, servers[0].queriedTables())
+	require.Equal(t, []string{"index_19001", "chunk-ref", "chunk-ref"}, servers[1].queriedTables())
+}
+
 /*
 ToDo(Sandeep): Comment out benchmark code for now to fix circular dependency
 func buildTableName(i int) string {
@@ -0,0 +1,204 @@
+package gatewayclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/pkg/logproto"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+const (
+	// latencyWeight is the weight of the latency of a new request in the average latency of a replica.
+	latencyWeight = 0.2
+	// failedRequestLatency is the minimum latency the failed requests count as, so that the replicas failing fast
+	// aren't preferred.
+	failedRequestLatency = time.Second
+	// latencyTTL is how long the latency of a replica is kept without new requests to it, so that the replicas which
+	// left the ring are forgotten.
+	latencyTTL = 10 * time.Minute
+)
+
+// replicaLatencies tracks the latency of the requests to each Index Gateway replica, as an exponentially weighted
+// moving average, so that the fastest replicas are preferred.
+type replicaLatencies struct {
+	mtx       sync.Mutex
+	latencies map[string]replicaLatency
+	lastEvict time.Time
+}
+
+type replicaLatency struct {
+	avg     time.Duration
+	updated time.Time
+}
+
+func newReplicaLatencies() *replicaLatencies {
+	return &replicaLatencies{latencies: map[string]replicaLatency{}, lastEvict: time.Now()}
+}
+
+func (l *replicaLatencies) observe(addr string, latency time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := time.Now()
+	l.evictStale(now)
+
+	prev, ok := l.latencies[addr]
+	if !ok {
+		l.latencies[addr] = replicaLatency{avg: latency, updated: now}
+		return
+	}
+	l.latencies[addr] = replicaLatency{
+		avg:     prev.avg + time.Duration(latencyWeight*float64(latency-prev.avg)),
+		updated: now,
+	}
+}
+
+// evictStale forgets the replicas without requests for latencyTTL, at most once per latencyTTL.
+func (l *replicaLatencies) evictStale(now time.Time) {
+	if now.Sub(l.lastEvict) < latencyTTL {
+		return
+	}
+	l.lastEvict = now
+	for addr, latency := range l.latencies {
+		if now.Sub(latency.updated) >= latencyTTL {
+			delete(l.latencies, addr)
+		}
+	}
+}
+
+func (l *replicaLatencies) get(addr string) time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.latencies[addr].avg
+}
+
+// order shuffles the addresses of the replicas, then puts the fastest of the first two first. Picking the fastest of
+// two random replicas spreads the requests across the replicas while avoiding the slow ones. The replicas without
+// requests yet count as the fastest, so that their latency gets known.
+func (l *replicaLatencies) order(addrs []string) {
+	rand.Shuffle(len(addrs), func(i, j int) {
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	})
+	if len(addrs) > 1 && l.get(addrs[1]) < l.get(addrs[0]) {
+		addrs[0], addrs[1] = addrs[1], addrs[0]
+	}
+}
+
+type replicaResult struct {
+	resp interface{}
+	err  error
+}
+
+// ringModeHedgedDo sends the request of the callback to the Index Gateway replicas mapping to the tenant, like
+// ringModeDo, and returns the first successful response. The request is sent to the next replica when a replica
+// fails, and also when it doesn't answer within the hedging delay, in which case the first of the two replicas to
+// answer wins and the other request is canceled.
+//
+// The callback must not have side effects, as several of its calls may run at once.
+func (s *GatewayClient) ringModeHedgedDo(ctx context.Context, tableNumber int64, callback func(ctx context.Context, client logproto.IndexGatewayClient) (interface{}, error)) (interface{}, error) {
+	userID, addrs, err := s.replicasFor(ctx, tableNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// the requests still running are canceled once a replica answered
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var lastErr error
+	results := make(chan replicaResult, len(addrs))
+	next, running := 0, 0
+	sendToNextReplica := func() bool {
+		for next < len(addrs) {
+			addr := addrs[next]
+			next++
+			client, err := s.clientFor(addr, userID)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+
+			running++
+			go func() {
+				var resp interface{}
+				err := s.timed(ctx, addr, func() error {
+					var err error
+					resp, err = callback(ctx, client)
+					return err
+				})
+				// the requests canceled because another replica answered first aren't failures
+				if err != nil && ctx.Err() == nil {
+					level.Error(util_log.Logger).Log("msg", fmt.Sprintf("client do failed for instance %s", addr), "err", err)
+				}
+				results <- replicaResult{resp: resp, err: err}
+			}()
+			return true
+		}
+		return false
+	}
+
+	var hedge <-chan time.Time
+	if s.cfg.HedgeAfter > 0 {
+		timer := time.NewTimer(s.cfg.HedgeAfter)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	if !sendToNextReplica() {
+		return nil, noReplicaError(lastErr)
+	}
+	for running > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			if sendToNextReplica() {
+				s.hedgedRequests.Inc()
+			}
+		case res := <-results:
+			running--
+			if res.err == nil {
+				return res.resp, nil
+			}
+			lastErr = res.err
+			if ctx.Err() != nil {
+				return nil, lastErr
+			}
+			sendToNextReplica()
+		}
+	}
+	return nil, lastErr
+}
+
+// noReplicaError is the error of a request which couldn't be sent to any replica, either because there are none or
+// because the clients of all of them failed with lastErr.
+func noReplicaError(lastErr error) error {
+	if lastErr != nil {
+		return lastErr
+	}
+	return errors.New("no index gateway instance to send the request to")
+}
+
+// timed runs the request to a replica, and tracks its latency. The requests canceled because another replica
+// answered first don't count, as the time they ran is lower than their latency.
+func (s *GatewayClient) timed(ctx context.Context, addr string, do func() error) error {
+	start := time.Now()
+	err := do()
+	latency := time.Since(start)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		if latency < failedRequestLatency {
+			latency = failedRequestLatency
+		}
+	}
+	s.latencies.observe(addr, latency)
+	return err
+}
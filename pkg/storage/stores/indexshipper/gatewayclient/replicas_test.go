@@ -0,0 +1,161 @@
+package gatewayclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/indexgateway"
+	util_log "github.com/grafana/loki/pkg/util/log"
+)
+
+// replicaServer answers the chunk ref requests after its delay, or with its error.
+type replicaServer struct {
+	logproto.IndexGatewayServer
+
+	delay    time.Duration
+	err      error
+	canceled chan struct{}
+}
+
+func (m *replicaServer) GetChunkRef(ctx context.Context, _ *logproto.GetChunkRefRequest) (*logproto.GetChunkRefResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	select {
+	case <-time.After(m.delay):
+		return &logproto.GetChunkRefResponse{Refs: []*logproto.ChunkRef{{UserID: m.delay.String()}}}, nil
+	case <-ctx.Done():
+		close(m.canceled)
+		return nil, ctx.Err()
+	}
+}
+
+// replicasRing maps every token to all its instances.
+type replicasRing struct {
+	ring.ReadRing
+
+	addrs []string
+}
+
+func (r *replicasRing) Get(uint32, ring.Operation, []ring.InstanceDesc, []string, []string) (ring.ReplicationSet, error) {
+	instances := make([]ring.InstanceDesc, 0, len(r.addrs))
+	for _, addr := range r.addrs {
+		instances = append(instances, ring.InstanceDesc{Addr: addr})
+	}
+	return ring.ReplicationSet{Instances: instances}, nil
+}
+
+func newReplicasClient(t *testing.T, hedgeAfter time.Duration, servers ...*replicaServer) (*GatewayClient, []string) {
+	addrs := make([]string, 0, len(servers))
+	for _, server := range servers {
+		server.canceled = make(chan struct{})
+		lis, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		s := grpc.NewServer()
+		logproto.RegisterIndexGatewayServer(s, server)
+		go func() {
+			_ = s.Serve(lis)
+		}()
+		t.Cleanup(s.Stop)
+		addrs = append(addrs, lis.Addr().String())
+	}
+
+	var cfg IndexGatewayClientConfig
+	flagext.DefaultValues(&cfg)
+	cfg.Mode = indexgateway.RingMode
+	cfg.HedgeAfter = hedgeAfter
+	cfg.Ring = &replicasRing{addrs: addrs}
+
+	gatewayClient, err := NewGatewayClient(cfg, prometheus.NewRegistry(), util_log.Logger)
+	require.NoError(t, err)
+	return gatewayClient, addrs
+}
+
+func TestGatewayClient_Hedging(t *testing.T) {
+	slow := &replicaServer{delay: time.Minute}
+	fast := &replicaServer{delay: time.Millisecond}
+	gatewayClient, addrs := newReplicasClient(t, 50*time.Millisecond, slow, fast)
+	// the fast replica isn't known to be fast yet, so the slow one is tried first
+	gatewayClient.latencies.observe(addrs[1], time.Hour)
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	resp, err := gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{})
+	require.NoError(t, err)
+	require.Equal(t, fast.delay.String(), resp.Refs[0].UserID)
+	require.Equal(t, 1.0, testutil.ToFloat64(gatewayClient.hedgedRequests))
+
+	// the request to the slow replica is canceled, and doesn't count as its latency
+	<-slow.canceled
+	require.Never(t, func() bool {
+		return gatewayClient.latencies.get(addrs[0]) != 0
+	}, 100*time.Millisecond, time.Millisecond)
+}
+
+func TestGatewayClient_ReplicaFallback(t *testing.T) {
+	failing := &replicaServer{err: errors.New("failed")}
+	fast := &replicaServer{delay: time.Millisecond}
+	gatewayClient, addrs := newReplicasClient(t, 0, failing, fast)
+	gatewayClient.latencies.observe(addrs[1], time.Hour)
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	resp, err := gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{})
+	require.NoError(t, err)
+	require.Equal(t, fast.delay.String(), resp.Refs[0].UserID)
+	require.Equal(t, 0.0, testutil.ToFloat64(gatewayClient.hedgedRequests))
+
+	// the failures count as slow requests
+	require.Equal(t, failedRequestLatency, gatewayClient.latencies.get(addrs[0]))
+
+	// all the replicas failing fails the request
+	fast.err = errors.New("failed too")
+	_, err = gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{})
+	require.Error(t, err)
+}
+
+func TestGatewayClient_NoReplica(t *testing.T) {
+	gatewayClient, _ := newReplicasClient(t, 50*time.Millisecond)
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	_, err := gatewayClient.GetChunkRef(ctx, &logproto.GetChunkRefRequest{})
+	require.Error(t, err)
+}
+
+func TestReplicaLatencies_Order(t *testing.T) {
+	l := newReplicaLatencies()
+	l.observe("fast", 10*time.Millisecond)
+	l.observe("slow", time.Second)
+	for i := 0; i < 10; i++ {
+		addrs := []string{"slow", "fast"}
+		l.order(addrs)
+		require.Equal(t, []string{"fast", "slow"}, addrs)
+	}
+
+	// the latencies are averaged
+	l.observe("fast", 110*time.Millisecond)
+	require.Equal(t, 30*time.Millisecond, l.get("fast"))
+}
+
+func TestReplicaLatencies_EvictStale(t *testing.T) {
+	l := newReplicaLatencies()
+	l.observe("gone", time.Second)
+	l.observe("active", time.Second)
+
+	// the replica without requests for latencyTTL is forgotten
+	now := time.Now().Add(latencyTTL)
+	l.latencies["active"] = replicaLatency{avg: time.Second, updated: now}
+	l.evictStale(now)
+	require.Equal(t, time.Duration(0), l.get("gone"))
+	require.Equal(t, time.Second, l.get("active"))
+}
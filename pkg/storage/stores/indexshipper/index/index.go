@@ -14,3 +14,16 @@ type Index interface {
 // the implementation should take care of gracefully handling failures in opening corrupted files.
 type OpenIndexFileFunc func(string) (Index, error)
 type ForEachIndexCallback func(isMultiTenantIndex bool, idx Index) error
+
+// InMemoryIndex is implemented by the indexes held in memory rather than in a local file, which are uploaded without
+// writing them to the disk and have no file to remove once dropped.
+type InMemoryIndex interface {
+	Index
+	InMemory() bool
+}
+
+// IsInMemory returns whether the index is held in memory.
+func IsInMemory(idx Index) bool {
+	m, ok := idx.(InMemoryIndex)
+	return ok && m.InMemory()
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/loki/pkg/storage/chunk/client"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/uploads"
+	loki_flagext "github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
 
@@ -45,6 +47,9 @@ type Index interface {
 type IndexShipper interface {
 	// AddIndex adds an immutable index to a logical table which would eventually get uploaded to the object store.
 	AddIndex(tableName, userID string, index index.Index) error
+	// UploadIndex uploads an immutable index to the object store right away, instead of waiting for the next upload,
+	// and adds it to the logical table as already uploaded so that it's only retained locally for queries.
+	UploadIndex(ctx context.Context, tableName, userID string, index index.Index) error
 	// ForEach lets us iterates through each index file in a table for a specific user.
 	// On the write path, it would iterate on the files given to the shipper for uploading, until they eventually get dropped from local disk.
 	// On the read path, it would iterate through the files if already downloaded else it would download and iterate through them.
@@ -62,8 +67,17 @@ type Config struct {
 	CacheTTL                 time.Duration                          `yaml:"cache_ttl"`
 	ResyncInterval           time.Duration                          `yaml:"resync_interval"`
 	QueryReadyNumDays        int                                    `yaml:"query_ready_num_days"`
+	CacheOnDemand            bool                                   `yaml:"cache_on_demand"`
+	CacheMaxSize             loki_flagext.ByteSize                  `yaml:"cache_max_size"`
 	IndexGatewayClientConfig gatewayclient.IndexGatewayClientConfig `yaml:"index_gateway_client"`
 	UseBoltDBShipperAsBackup bool                                   `yaml:"use_boltdb_shipper_as_backup"`
+	IndexCompression         string                                 `yaml:"index_compression"`
+	ReplicationStores        flagext.StringSliceCSV                 `yaml:"replication_stores"`
+	ReplicationQuorum        int                                    `yaml:"replication_quorum"`
+
+	// ReplicaObjectClients are the clients for ReplicationStores, set by the caller creating the object clients.
+	// The index shipper takes ownership of them, stopping them when it gets stopped.
+	ReplicaObjectClients []client.ObjectClient `yaml:"-"`
 
 	IngesterName           string
 	Mode                   Mode
@@ -81,7 +95,12 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.DurationVar(&cfg.CacheTTL, prefix+"shipper.cache-ttl", 24*time.Hour, "TTL for index files restored in cache for queries")
 	f.DurationVar(&cfg.ResyncInterval, prefix+"shipper.resync-interval", 5*time.Minute, "Resync downloaded files with the storage")
 	f.IntVar(&cfg.QueryReadyNumDays, prefix+"shipper.query-ready-num-days", 0, "Number of days of common index to be kept downloaded for queries. For per tenant index query readiness, use limits overrides config.")
+	f.BoolVar(&cfg.CacheOnDemand, prefix+"shipper.cache-on-demand", false, "Download only the index files needed by the queries instead of the tables within the query readiness range, which is ignored. Use it with the cache max size to bound the disk used by the index of queriers handling many tables.")
+	f.Var(&cfg.CacheMaxSize, prefix+"shipper.cache-max-size", "Maximum size of the index files restored in cache for queries when they are downloaded on demand. The least recently queried index files are evicted when the cache grows above it. 0 means unlimited.")
 	f.BoolVar(&cfg.UseBoltDBShipperAsBackup, prefix+"shipper.use-boltdb-shipper-as-backup", false, "Use boltdb-shipper index store as backup for indexing chunks. When enabled, boltdb-shipper needs to be configured under storage_config")
+	f.StringVar(&cfg.IndexCompression, prefix+"shipper.index-compression", storage.CompressionGzip, "Codec used to compress index files before uploading them. Supported values: gzip, zstd. Index files are always decompressed according to their extension, so changing this only affects new uploads; make sure every component reading the index supports the codec before switching.")
+	f.Var(&cfg.ReplicationStores, prefix+"shipper.replication-stores", "Comma separated list of named stores, configured under storage_config.named_stores, which uploaded index files are replicated to, e.g. for keeping a copy in a bucket in another region. Reads are always served from the shared store.")
+	f.IntVar(&cfg.ReplicationQuorum, prefix+"shipper.replication-quorum", 0, "Number of replication stores which must acknowledge an upload for it to succeed, in addition to the shared store which must always acknowledge it. 0 means a majority of the replication stores.")
 }
 
 func (cfg *Config) Validate() error {
@@ -89,9 +108,35 @@ func (cfg *Config) Validate() error {
 	if cfg.Mode == "" {
 		cfg.Mode = ModeReadWrite
 	}
+	if cfg.IndexCompression != "" {
+		if err := storage.ValidateCompression(cfg.IndexCompression); err != nil {
+			return err
+		}
+	}
+	if cfg.CacheMaxSize > 0 && !cfg.CacheOnDemand {
+		return fmt.Errorf("the cache max size can only be set when downloading the index on demand")
+	}
+	if err := cfg.validateReplication(); err != nil {
+		return err
+	}
 	return storage.ValidateSharedStoreKeyPrefix(cfg.SharedStoreKeyPrefix)
 }
 
+func (cfg *Config) validateReplication() error {
+	seen := map[string]struct{}{}
+	for _, store := range cfg.ReplicationStores {
+		if _, ok := seen[store]; ok {
+			return fmt.Errorf("replication store %s is configured more than once", store)
+		}
+		seen[store] = struct{}{}
+	}
+
+	if cfg.ReplicationQuorum < 0 || cfg.ReplicationQuorum > len(cfg.ReplicationStores) {
+		return fmt.Errorf("replication quorum must be between 0 and the number of replication stores %d, got %d", len(cfg.ReplicationStores), cfg.ReplicationQuorum)
+	}
+	return nil
+}
+
 type indexShipper struct {
 	cfg               Config
 	openIndexFileFunc index.OpenIndexFileFunc
@@ -121,6 +166,7 @@ func NewIndexShipper(cfg Config, storageClient client.ObjectClient, limits downl
 
 	err := shipper.init(storageClient, limits, ownsTenantFn, tableRangesToHandle, reg)
 	if err != nil {
+		shipper.stopReplicas()
 		return nil, err
 	}
 
@@ -134,11 +180,26 @@ func (s *indexShipper) init(storageClient client.ObjectClient, limits downloads.
 	indexStorageClient := storage.NewIndexStorageClient(storageClient, s.cfg.SharedStoreKeyPrefix)
 
 	if s.cfg.Mode != ModeReadOnly {
+		uploadsStorageClient := indexStorageClient
+		if len(s.cfg.ReplicaObjectClients) > 0 {
+			replicas := make([]storage.Client, 0, len(s.cfg.ReplicaObjectClients))
+			for _, replica := range s.cfg.ReplicaObjectClients {
+				replicas = append(replicas, storage.NewIndexStorageClient(replica, s.cfg.SharedStoreKeyPrefix))
+			}
+
+			var err error
+			uploadsStorageClient, err = storage.NewReplicatedClient(indexStorageClient, replicas, s.cfg.ReplicationQuorum, util_log.Logger)
+			if err != nil {
+				return err
+			}
+		}
+
 		cfg := uploads.Config{
 			UploadInterval: UploadInterval,
 			DBRetainPeriod: s.cfg.IngesterDBRetainPeriod,
+			Compression:    s.cfg.IndexCompression,
 		}
-		uploadsManager, err := uploads.NewTableManager(cfg, indexStorageClient, reg)
+		uploadsManager, err := uploads.NewTableManager(cfg, uploadsStorageClient, reg)
 		if err != nil {
 			return err
 		}
@@ -153,6 +214,8 @@ func (s *indexShipper) init(storageClient client.ObjectClient, limits downloads.
 			CacheTTL:          s.cfg.CacheTTL,
 			QueryReadyNumDays: s.cfg.QueryReadyNumDays,
 			Limits:            limits,
+			OnDemand:          s.cfg.CacheOnDemand,
+			MaxCacheSize:      int64(s.cfg.CacheMaxSize),
 		}
 		downloadsManager, err := downloads.NewTableManager(cfg, s.openIndexFileFunc, indexStorageClient, ownsTenantFn, tableRangesToHandle, reg)
 		if err != nil {
@@ -169,6 +232,10 @@ func (s *indexShipper) AddIndex(tableName, userID string, index index.Index) err
 	return s.uploadsManager.AddIndex(tableName, userID, index)
 }
 
+func (s *indexShipper) UploadIndex(ctx context.Context, tableName, userID string, index index.Index) error {
+	return s.uploadsManager.UploadIndex(ctx, tableName, userID, index)
+}
+
 func (s *indexShipper) ForEach(ctx context.Context, tableName, userID string, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error {
 	if s.downloadsManager != nil {
 		if err := s.downloadsManager.ForEach(ctx, tableName, userID, doneChan, callback); err != nil {
@@ -197,4 +264,13 @@ func (s *indexShipper) stop() {
 	if s.downloadsManager != nil {
 		s.downloadsManager.Stop()
 	}
+
+	// stopped last since the uploads manager uploads the pending files while stopping
+	s.stopReplicas()
+}
+
+func (s *indexShipper) stopReplicas() {
+	for _, replica := range s.cfg.ReplicaObjectClients {
+		replica.Stop()
+	}
 }
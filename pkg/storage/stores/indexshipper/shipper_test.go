@@ -0,0 +1,42 @@
+package indexshipper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+)
+
+// stopCountingObjectClient counts how many times it got stopped
+type stopCountingObjectClient struct {
+	client.ObjectClient
+	stops int
+}
+
+func (c *stopCountingObjectClient) Stop() {
+	c.stops++
+}
+
+func TestIndexShipper_StopsReplicas(t *testing.T) {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	replica := &stopCountingObjectClient{ObjectClient: objectClient}
+	cfg := Config{
+		ActiveIndexDirectory: t.TempDir(),
+		SharedStoreKeyPrefix: "index/",
+		IndexCompression:     storage.CompressionGzip,
+		ReplicationStores:    []string{"replica"},
+		ReplicaObjectClients: []client.ObjectClient{replica},
+		Mode:                 ModeWriteOnly,
+	}
+	shipper, err := NewIndexShipper(cfg, objectClient, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	shipper.Stop()
+	shipper.Stop()
+	require.Equal(t, 1, replica.stops)
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/weaveworks/common/user"
+
 	"github.com/grafana/loki/pkg/storage/chunk/client"
 )
 
@@ -130,7 +132,8 @@ func (s *indexStorageClient) GetFile(ctx context.Context, tableName, fileName st
 }
 
 func (s *indexStorageClient) GetUserFile(ctx context.Context, tableName, userID, fileName string) (io.ReadCloser, error) {
-	readCloser, _, err := s.objectClient.GetObject(ctx, path.Join(tableName, userID, fileName))
+	// The tenant allows object stores to apply per-tenant settings, e.g. S3 server-side encryption.
+	readCloser, _, err := s.objectClient.GetObject(user.InjectOrgID(ctx, userID), path.Join(tableName, userID, fileName))
 	return readCloser, err
 }
 
@@ -139,7 +142,7 @@ func (s *indexStorageClient) PutFile(ctx context.Context, tableName, fileName st
 }
 
 func (s *indexStorageClient) PutUserFile(ctx context.Context, tableName, userID, fileName string, file io.ReadSeeker) error {
-	return s.objectClient.PutObject(ctx, path.Join(tableName, userID, fileName), file)
+	return s.objectClient.PutObject(user.InjectOrgID(ctx, userID), path.Join(tableName, userID, fileName), file)
 }
 
 func (s *indexStorageClient) DeleteFile(ctx context.Context, tableName, fileName string) error {
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/multierror"
+)
+
+// replicatedClient writes index files to the primary store and all its replicas, only reporting success once the
+// primary and at least quorum of the replicas acknowledged the write. Since reads and listings are always served by
+// the primary store, a write is never considered successful without it.
+//
+// Replicas that failed a write which still reached quorum are not repaired, they would be missing the file until it
+// gets rewritten e.g. by compaction.
+type replicatedClient struct {
+	Client
+
+	replicas []Client
+	quorum   int
+	logger   log.Logger
+}
+
+// NewReplicatedClient returns a Client fanning out writes and deletes to primary and replicas.
+// quorum is the number of replicas which must acknowledge each write besides the primary,
+// a quorum <= 0 requires a majority of the replicas.
+func NewReplicatedClient(primary Client, replicas []Client, quorum int, logger log.Logger) (Client, error) {
+	if quorum <= 0 {
+		quorum = len(replicas)/2 + 1
+	}
+	if quorum > len(replicas) {
+		return nil, fmt.Errorf("replication quorum %d is greater than the number of replicas %d", quorum, len(replicas))
+	}
+
+	return &replicatedClient{
+		Client:   primary,
+		replicas: replicas,
+		quorum:   quorum,
+		logger:   logger,
+	}, nil
+}
+
+func (r *replicatedClient) PutFile(ctx context.Context, tableName, fileName string, file io.ReadSeeker) error {
+	return r.put(ctx, file, func(c Client, file io.ReadSeeker) error {
+		return c.PutFile(ctx, tableName, fileName, file)
+	}, "table", tableName, "file", fileName)
+}
+
+func (r *replicatedClient) PutUserFile(ctx context.Context, tableName, userID, fileName string, file io.ReadSeeker) error {
+	return r.put(ctx, file, func(c Client, file io.ReadSeeker) error {
+		return c.PutUserFile(ctx, tableName, userID, fileName, file)
+	}, "table", tableName, "user", userID, "file", fileName)
+}
+
+func (r *replicatedClient) DeleteFile(ctx context.Context, tableName, fileName string) error {
+	return r.forEachStore(func(c Client) error {
+		return c.DeleteFile(ctx, tableName, fileName)
+	}, "table", tableName, "file", fileName)
+}
+
+func (r *replicatedClient) DeleteUserFile(ctx context.Context, tableName, userID, fileName string) error {
+	return r.forEachStore(func(c Client) error {
+		return c.DeleteUserFile(ctx, tableName, userID, fileName)
+	}, "table", tableName, "user", userID, "file", fileName)
+}
+
+// Stop is a no-op, the primary and the replicas are owned and stopped by whoever created them.
+func (r *replicatedClient) Stop() {}
+
+// put buffers the file in memory so that it can be uploaded to all the stores concurrently.
+func (r *replicatedClient) put(ctx context.Context, file io.ReadSeeker, putFunc func(Client, io.ReadSeeker) error, logKeyvals ...interface{}) error {
+	buf, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	return r.forEachStore(func(c Client) error {
+		return putFunc(c, bytes.NewReader(buf))
+	}, logKeyvals...)
+}
+
+// forEachStore runs do against the primary store and every replica concurrently, returning an error if the primary
+// or more replicas than quorum allows failed. A file not being found counts as success so that deleting a file
+// missing from a replica does not fail.
+func (r *replicatedClient) forEachStore(do func(Client) error, logKeyvals ...interface{}) error {
+	stores := append([]Client{r.Client}, r.replicas...)
+	errs := make([]error, len(stores))
+
+	var wg sync.WaitGroup
+	for i := range stores {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := do(stores[i]); err != nil && !stores[i].IsFileNotFoundErr(err) {
+				errs[i] = err
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		return errs[0]
+	}
+
+	var failed multierror.MultiError
+	for i, err := range errs[1:] {
+		if err == nil {
+			continue
+		}
+		failed.Add(err)
+		level.Warn(r.logger).Log(append([]interface{}{"msg", "failed to replicate index file", "replica", i, "err", err}, logKeyvals...)...)
+	}
+
+	if acks := len(r.replicas) - len(failed); acks < r.quorum {
+		return fmt.Errorf("index file acknowledged by %d replicas, quorum is %d: %w", acks, r.quorum, failed.Err())
+	}
+	return nil
+}
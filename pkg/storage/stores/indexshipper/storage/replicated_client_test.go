@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+)
+
+// failingClient fails all writes and deletes
+type failingClient struct {
+	Client
+}
+
+func (f failingClient) PutFile(_ context.Context, _, _ string, _ io.ReadSeeker) error {
+	return errors.New("put failed")
+}
+
+func (f failingClient) PutUserFile(_ context.Context, _, _, _ string, _ io.ReadSeeker) error {
+	return errors.New("put failed")
+}
+
+func (f failingClient) DeleteFile(_ context.Context, _, _ string) error {
+	return errors.New("delete failed")
+}
+
+func newFSIndexStorageClient(t *testing.T) Client {
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	return NewIndexStorageClient(objectClient, "index/")
+}
+
+func readFile(t *testing.T, c Client, tableName, userID, fileName string) []byte {
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+	if userID == "" {
+		rc, err = c.GetFile(context.Background(), tableName, fileName)
+	} else {
+		rc, err = c.GetUserFile(context.Background(), tableName, userID, fileName)
+	}
+	require.NoError(t, err)
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return b
+}
+
+func TestReplicatedClient(t *testing.T) {
+	primary, replica := newFSIndexStorageClient(t), newFSIndexStorageClient(t)
+
+	c, err := NewReplicatedClient(primary, []Client{replica}, 1, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, c.PutFile(context.Background(), "table", "common", bytes.NewReader([]byte("common"))))
+	require.NoError(t, c.PutUserFile(context.Background(), "table", "user", "user-file", bytes.NewReader([]byte("user"))))
+
+	for _, store := range []Client{primary, replica} {
+		require.Equal(t, []byte("common"), readFile(t, store, "table", "", "common"))
+		require.Equal(t, []byte("user"), readFile(t, store, "table", "user", "user-file"))
+	}
+
+	// deleting a file missing from one of the stores still succeeds
+	require.NoError(t, replica.DeleteFile(context.Background(), "table", "common"))
+	require.NoError(t, c.DeleteFile(context.Background(), "table", "common"))
+	_, err = primary.GetFile(context.Background(), "table", "common")
+	require.True(t, primary.IsFileNotFoundErr(err))
+}
+
+func TestReplicatedClient_Quorum(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		primaryFailing  bool
+		failingReplicas int
+		quorum          int
+		expectErr       bool
+	}{
+		{name: "majority, none of three replicas failing"},
+		{name: "majority, one of three replicas failing", failingReplicas: 1},
+		{name: "majority, two of three replicas failing", failingReplicas: 2, expectErr: true},
+		{name: "all, one of three replicas failing", failingReplicas: 1, quorum: 3, expectErr: true},
+		{name: "one, two of three replicas failing", failingReplicas: 2, quorum: 1},
+		{name: "primary failing, all replicas succeeding", primaryFailing: true, expectErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			primary := newFSIndexStorageClient(t)
+			if tc.primaryFailing {
+				primary = failingClient{primary}
+			}
+			var replicas []Client
+			for i := 0; i < 3; i++ {
+				replica := newFSIndexStorageClient(t)
+				if i < tc.failingReplicas {
+					replica = failingClient{replica}
+				}
+				replicas = append(replicas, replica)
+			}
+
+			c, err := NewReplicatedClient(primary, replicas, tc.quorum, log.NewNopLogger())
+			require.NoError(t, err)
+
+			err = c.PutFile(context.Background(), "table", "file", bytes.NewReader([]byte("data")))
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []byte("data"), readFile(t, primary, "table", "", "file"))
+		})
+	}
+
+	_, err := NewReplicatedClient(newFSIndexStorageClient(t), []Client{newFSIndexStorageClient(t)}, 2, log.NewNopLogger())
+	require.Error(t, err)
+}
@@ -12,12 +12,58 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	gzip "github.com/klauspost/pgzip"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+)
+
+// Codecs which index files can be compressed with.
+// The codec of a stored file is identified by its extension.
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
 )
 
 var (
 	gzipReader = sync.Pool{}
+
+	compressionExtensions = map[string]string{
+		CompressionGzip: ".gz",
+		CompressionZstd: ".zst",
+	}
 )
 
+// ValidateCompression checks whether index files can be compressed with the given codec.
+func ValidateCompression(codec string) error {
+	if _, ok := compressionExtensions[codec]; !ok {
+		return fmt.Errorf("unsupported index compression codec %q, supported values: %s, %s", codec, CompressionGzip, CompressionZstd)
+	}
+	return nil
+}
+
+// CompressionExtension returns the file extension of files compressed with the given codec.
+func CompressionExtension(codec string) string {
+	return compressionExtensions[codec]
+}
+
+// CompressionForFile returns the codec a file was compressed with based on its extension,
+// or an empty string if it isn't compressed.
+func CompressionForFile(filename string) string {
+	for codec, ext := range compressionExtensions {
+		if strings.HasSuffix(filename, ext) {
+			return codec
+		}
+	}
+	return ""
+}
+
+// TrimCompressionExtension strips the compression extension, if any, from the file name.
+func TrimCompressionExtension(filename string) string {
+	if codec := CompressionForFile(filename); codec != "" {
+		return strings.TrimSuffix(filename, compressionExtensions[codec])
+	}
+	return filename
+}
+
 // getGzipReader gets or creates a new CompressionReader and reset it to read from src
 func getGzipReader(src io.Reader) (io.Reader, error) {
 	if r := gzipReader.Get(); r != nil {
@@ -42,8 +88,9 @@ func putGzipReader(reader io.Reader) {
 
 type GetFileFunc func() (io.ReadCloser, error)
 
-// DownloadFileFromStorage downloads a file from storage to given location.
-func DownloadFileFromStorage(destination string, decompressFile bool, sync bool, logger log.Logger, getFileFunc GetFileFunc) error {
+// DownloadFileFromStorage downloads a file from storage to given location,
+// decompressing it with the given codec unless it's empty.
+func DownloadFileFromStorage(destination string, compression string, sync bool, logger log.Logger, getFileFunc GetFileFunc) error {
 	start := time.Now()
 	readCloser, err := getFileFunc()
 	if err != nil {
@@ -67,7 +114,9 @@ func DownloadFileFromStorage(destination string, decompressFile bool, sync bool,
 		}
 	}()
 	var objectReader io.Reader = readCloser
-	if decompressFile {
+	switch compression {
+	case "":
+	case CompressionGzip:
 		decompressedReader, err := getGzipReader(readCloser)
 		if err != nil {
 			return err
@@ -75,6 +124,16 @@ func DownloadFileFromStorage(destination string, decompressFile bool, sync bool,
 		defer putGzipReader(decompressedReader)
 
 		objectReader = decompressedReader
+	case CompressionZstd:
+		decompressedReader, err := chunkenc.Zstd.GetReader(readCloser)
+		if err != nil {
+			return err
+		}
+		defer chunkenc.Zstd.PutReader(decompressedReader)
+
+		objectReader = decompressedReader
+	default:
+		return ValidateCompression(compression)
 	}
 
 	_, err = io.Copy(f, objectReader)
@@ -90,7 +149,7 @@ func DownloadFileFromStorage(destination string, decompressFile bool, sync bool,
 }
 
 func IsCompressedFile(filename string) bool {
-	return strings.HasSuffix(filename, ".gz")
+	return CompressionForFile(filename) != ""
 }
 
 func LoggerWithFilename(logger log.Logger, filename string) log.Logger {
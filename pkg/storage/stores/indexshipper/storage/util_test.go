@@ -10,6 +10,7 @@ import (
 	gzip "github.com/klauspost/pgzip"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/storage/chunk/client/local"
 	"github.com/grafana/loki/pkg/storage/chunk/client/util"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -30,7 +31,7 @@ func Test_GetFileFromStorage(t *testing.T) {
 
 	indexStorageClient := NewIndexStorageClient(objectClient, "")
 
-	require.NoError(t, DownloadFileFromStorage(filepath.Join(tempDir, "dest"), false,
+	require.NoError(t, DownloadFileFromStorage(filepath.Join(tempDir, "dest"), "",
 		false, util_log.Logger, func() (io.ReadCloser, error) {
 			return indexStorageClient.GetFile(context.Background(), tableName, "src")
 		}))
@@ -45,7 +46,7 @@ func Test_GetFileFromStorage(t *testing.T) {
 	compressFile(t, filepath.Join(tempDir, tableName, "src"), filepath.Join(tempDir, tableName, "src.gz"), true)
 
 	// get the compressed file from storage
-	require.NoError(t, DownloadFileFromStorage(filepath.Join(tempDir, "dest.gz"), true,
+	require.NoError(t, DownloadFileFromStorage(filepath.Join(tempDir, "dest.gz"), CompressionGzip,
 		false, util_log.Logger, func() (io.ReadCloser, error) {
 			return indexStorageClient.GetFile(context.Background(), tableName, "src.gz")
 		}))
@@ -55,6 +56,45 @@ func Test_GetFileFromStorage(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Equal(t, testData, b)
+
+	// compress the file in storage with zstd
+	f, err := os.Create(filepath.Join(tempDir, tableName, "src.zst"))
+	require.NoError(t, err)
+	w := chunkenc.Zstd.GetWriter(f)
+	_, err = w.Write(testData)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	require.NoError(t, DownloadFileFromStorage(filepath.Join(tempDir, "dest.zst"), CompressionForFile("src.zst"),
+		false, util_log.Logger, func() (io.ReadCloser, error) {
+			return indexStorageClient.GetFile(context.Background(), tableName, "src.zst")
+		}))
+
+	b, err = os.ReadFile(filepath.Join(tempDir, "dest.zst"))
+	require.NoError(t, err)
+
+	require.Equal(t, testData, b)
+}
+
+func TestCompressionForFile(t *testing.T) {
+	for _, tc := range []struct {
+		name, compression, trimmed string
+	}{
+		{name: "index.tsdb", compression: "", trimmed: "index.tsdb"},
+		{name: "index.tsdb.gz", compression: CompressionGzip, trimmed: "index.tsdb"},
+		{name: "index.tsdb.zst", compression: CompressionZstd, trimmed: "index.tsdb"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.compression, CompressionForFile(tc.name))
+			require.Equal(t, tc.compression != "", IsCompressedFile(tc.name))
+			require.Equal(t, tc.trimmed, TrimCompressionExtension(tc.name))
+		})
+	}
+
+	require.NoError(t, ValidateCompression(CompressionGzip))
+	require.NoError(t, ValidateCompression(CompressionZstd))
+	require.Error(t, ValidateCompression("lz4"))
 }
 
 func compressFile(t *testing.T, src, dest string, sync bool) {
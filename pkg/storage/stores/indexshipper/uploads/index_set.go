@@ -1,6 +1,7 @@
 package uploads
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -19,6 +20,7 @@ import (
 
 type IndexSet interface {
 	Add(idx index.Index)
+	AddUploaded(ctx context.Context, idx index.Index) error
 	Upload(ctx context.Context) error
 	Cleanup(indexRetainPeriod time.Duration) error
 	ForEach(ctx context.Context, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error
@@ -30,6 +32,7 @@ type IndexSet interface {
 type indexSet struct {
 	storageIndexSet   storage.IndexSet
 	tableName, userID string
+	compression       string
 	logger            log.Logger
 
 	index    map[string]index.Index
@@ -39,7 +42,15 @@ type indexSet struct {
 	indexUploadTimeMtx sync.RWMutex
 }
 
-func NewIndexSet(tableName, userID string, baseIndexSet storage.IndexSet, logger log.Logger) (IndexSet, error) {
+func NewIndexSet(tableName, userID string, baseIndexSet storage.IndexSet, compression string, logger log.Logger) (IndexSet, error) {
+	// default to gzip, which used to be the only supported codec
+	if compression == "" {
+		compression = storage.CompressionGzip
+	}
+	if err := storage.ValidateCompression(compression); err != nil {
+		return nil, err
+	}
+
 	if baseIndexSet.IsUserBasedIndexSet() && userID == "" {
 		return nil, fmt.Errorf("userID must not be empty")
 	} else if !baseIndexSet.IsUserBasedIndexSet() && userID != "" {
@@ -52,6 +63,7 @@ func NewIndexSet(tableName, userID string, baseIndexSet storage.IndexSet, logger
 		index:           map[string]index.Index{},
 		indexUploadTime: map[string]time.Time{},
 		userID:          userID,
+		compression:     compression,
 		logger:          logger,
 	}
 
@@ -65,6 +77,21 @@ func (t *indexSet) Add(idx index.Index) {
 	t.index[idx.Name()] = idx
 }
 
+// AddUploaded uploads the index right away and adds it as already uploaded, so that it's only retained for queries.
+func (t *indexSet) AddUploaded(ctx context.Context, idx index.Index) error {
+	if err := t.uploadIndex(ctx, idx); err != nil {
+		return err
+	}
+
+	// the upload time must be known before the index gets added, so that Upload doesn't upload it again.
+	t.indexUploadTimeMtx.Lock()
+	t.indexUploadTime[idx.Name()] = time.Now()
+	t.indexUploadTimeMtx.Unlock()
+
+	t.Add(idx)
+	return nil
+}
+
 func (t *indexSet) ForEach(_ context.Context, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error {
 	t.indexMtx.RLock()
 
@@ -131,6 +158,15 @@ func (t *indexSet) uploadIndex(ctx context.Context, idx index.Index) error {
 	fileName := idx.Name()
 	level.Debug(t.logger).Log("msg", fmt.Sprintf("uploading index %s", fileName))
 
+	// indexes held in memory are compressed in memory too, without going through the disk.
+	if index.IsInMemory(idx) {
+		var buf bytes.Buffer
+		if err := t.compressIndex(idx, &buf); err != nil {
+			return err
+		}
+		return t.storageIndexSet.PutFile(ctx, t.tableName, t.userID, t.buildFileName(fileName), bytes.NewReader(buf.Bytes()))
+	}
+
 	idxPath := idx.Path()
 
 	filePath := fmt.Sprintf("%s%s", idxPath, tempFileSuffix)
@@ -149,39 +185,44 @@ func (t *indexSet) uploadIndex(ctx context.Context, idx index.Index) error {
 		}
 	}()
 
-	compressedWriter := chunkenc.Gzip.GetWriter(f)
-	defer chunkenc.Gzip.PutWriter(compressedWriter)
-
-	idxReader, err := idx.Reader()
-	if err != nil {
+	if err := t.compressIndex(idx, f); err != nil {
 		return err
 	}
 
-	_, err = idxReader.Seek(0, 0)
-	if err != nil {
+	// flush the file to disk and seek the file to the beginning.
+	if err := f.Sync(); err != nil {
 		return err
 	}
 
-	_, err = io.Copy(compressedWriter, idxReader)
-	if err != nil {
+	if _, err := f.Seek(0, 0); err != nil {
 		return err
 	}
 
-	err = compressedWriter.Close()
+	return t.storageIndexSet.PutFile(ctx, t.tableName, t.userID, t.buildFileName(fileName), f)
+}
+
+// compressIndex writes the index compressed with the codec of the index set to w.
+func (t *indexSet) compressIndex(idx index.Index, w io.Writer) error {
+	pool := compressionWriterPool(t.compression)
+	compressedWriter := pool.GetWriter(w)
+	defer pool.PutWriter(compressedWriter)
+
+	idxReader, err := idx.Reader()
 	if err != nil {
 		return err
 	}
 
-	// flush the file to disk and seek the file to the beginning.
-	if err := f.Sync(); err != nil {
+	_, err = idxReader.Seek(0, 0)
+	if err != nil {
 		return err
 	}
 
-	if _, err := f.Seek(0, 0); err != nil {
+	_, err = io.Copy(compressedWriter, idxReader)
+	if err != nil {
 		return err
 	}
 
-	return t.storageIndexSet.PutFile(ctx, t.tableName, t.userID, t.buildFileName(fileName), f)
+	return compressedWriter.Close()
 }
 
 // Cleanup removes indexes which are already uploaded and have been retained for period longer than indexRetainPeriod since they were uploaded.
@@ -237,9 +278,19 @@ func (t *indexSet) removeIndex(name string) error {
 	delete(t.indexUploadTime, name)
 	t.indexUploadTimeMtx.Unlock()
 
+	if index.IsInMemory(idx) {
+		return nil
+	}
 	return os.Remove(idx.Path())
 }
 
 func (t *indexSet) buildFileName(indexName string) string {
-	return fmt.Sprintf("%s.gz", indexName)
+	return fmt.Sprintf("%s%s", indexName, storage.CompressionExtension(t.compression))
+}
+
+func compressionWriterPool(compression string) chunkenc.WriterPool {
+	if compression == storage.CompressionZstd {
+		return &chunkenc.Zstd
+	}
+	return &chunkenc.Gzip
 }
@@ -1,6 +1,7 @@
 package uploads
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
@@ -24,7 +26,7 @@ func TestIndexSet_Add(t *testing.T) {
 
 	for _, userID := range []string{userID, ""} {
 		t.Run(userID, func(t *testing.T) {
-			indexSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), util_log.Logger)
+			indexSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), storage.CompressionGzip, util_log.Logger)
 			require.NoError(t, err)
 
 			defer indexSet.Close()
@@ -50,37 +52,101 @@ func TestIndexSet_Add(t *testing.T) {
 }
 
 func TestIndexSet_Upload(t *testing.T) {
-	tempDir := t.TempDir()
-	testStorageClient := buildTestStorageClient(t, tempDir)
+	for _, tc := range []struct {
+		compression, extension string
+	}{
+		{compression: "", extension: ".gz"},
+		{compression: storage.CompressionGzip, extension: ".gz"},
+		{compression: storage.CompressionZstd, extension: ".zst"},
+	} {
+		for _, userID := range []string{userID, ""} {
+			t.Run(tc.compression+"/"+userID, func(t *testing.T) {
+				tempDir := t.TempDir()
+				testStorageClient := buildTestStorageClient(t, tempDir)
+
+				idxSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), tc.compression, util_log.Logger)
+				require.NoError(t, err)
 
-	for _, userID := range []string{userID, ""} {
-		t.Run(userID, func(t *testing.T) {
-			idxSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), util_log.Logger)
-			require.NoError(t, err)
+				defer idxSet.Close()
 
-			defer idxSet.Close()
+				testIndexes := buildTestIndexes(t, t.TempDir(), 5)
+				for _, testIndex := range testIndexes {
+					idxSet.Add(testIndex)
+				}
 
-			testIndexes := buildTestIndexes(t, t.TempDir(), 5)
-			for _, testIndex := range testIndexes {
-				idxSet.Add(testIndex)
-			}
+				err = idxSet.Upload(context.Background())
+				require.NoError(t, err)
 
-			err = idxSet.Upload(context.Background())
-			require.NoError(t, err)
+				for _, testIndex := range testIndexes {
+					fileName := idxSet.(*indexSet).buildFileName(testIndex.Name())
+					require.Equal(t, testIndex.Name()+tc.extension, fileName)
+					indexPathInStorage := filepath.Join(tempDir, objectsStorageDirName, testTableName, userID, fileName)
+					require.FileExists(t, indexPathInStorage)
+
+					// compare the contents of created test index and uploaded index in storage
+					_, err = testIndex.Seek(0, 0)
+					require.NoError(t, err)
+					expectedIndexContent, err := io.ReadAll(testIndex.File)
+					require.NoError(t, err)
+					require.Equal(t, expectedIndexContent, readCompressedFile(t, indexPathInStorage))
+				}
+			})
+		}
+	}
+}
 
-			for _, testIndex := range testIndexes {
-				indexPathInStorage := filepath.Join(tempDir, objectsStorageDirName, testTableName, userID, idxSet.(*indexSet).buildFileName(testIndex.Name()))
-				require.FileExists(t, indexPathInStorage)
+type mockInMemoryIndex struct {
+	name string
+	data []byte
+}
 
-				// compare the contents of created test index and uploaded index in storage
-				_, err = testIndex.Seek(0, 0)
-				require.NoError(t, err)
-				expectedIndexContent, err := io.ReadAll(testIndex.File)
-				require.NoError(t, err)
-				require.Equal(t, expectedIndexContent, readCompressedFile(t, indexPathInStorage))
-			}
-		})
-	}
+func (m mockInMemoryIndex) Name() string   { return m.name }
+func (m mockInMemoryIndex) Path() string   { return filepath.Join("nonexistent", m.name) }
+func (m mockInMemoryIndex) Close() error   { return nil }
+func (m mockInMemoryIndex) InMemory() bool { return true }
+func (m mockInMemoryIndex) Reader() (io.ReadSeeker, error) {
+	return bytes.NewReader(m.data), nil
+}
+
+func TestIndexSet_AddUploaded(t *testing.T) {
+	tempDir := t.TempDir()
+	testStorageClient := buildTestStorageClient(t, tempDir)
+
+	idxSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, true), storage.CompressionGzip, util_log.Logger)
+	require.NoError(t, err)
+	defer idxSet.Close()
+
+	idx := mockInMemoryIndex{name: "index-in-memory", data: []byte("index-in-memory")}
+	require.NoError(t, idxSet.AddUploaded(context.Background(), idx))
+
+	indexPathInStorage := filepath.Join(tempDir, objectsStorageDirName, testTableName, userID, idx.name+".gz")
+	require.Equal(t, idx.data, readCompressedFile(t, indexPathInStorage))
+
+	// the index is served to queries but not uploaded again.
+	require.NoError(t, os.Remove(indexPathInStorage))
+	require.NoError(t, idxSet.Upload(context.Background()))
+	require.NoFileExists(t, indexPathInStorage)
+
+	var found []string
+	doneChan := make(chan struct{})
+	err = idxSet.ForEach(context.Background(), doneChan, func(_ bool, index index.Index) error {
+		found = append(found, index.Name())
+		return nil
+	})
+	close(doneChan)
+	require.NoError(t, err)
+	require.Equal(t, []string{idx.name}, found)
+
+	// it's dropped once retained for long enough, without any file to remove.
+	idxSet.(*indexSet).indexUploadTime[idx.name] = time.Now().Add(-time.Hour)
+	require.NoError(t, idxSet.Cleanup(time.Minute))
+	require.Empty(t, idxSet.(*indexSet).index)
+}
+
+func TestNewIndexSet_InvalidCompression(t *testing.T) {
+	testStorageClient := buildTestStorageClient(t, t.TempDir())
+	_, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, true), "lz4", util_log.Logger)
+	require.Error(t, err)
 }
 
 func TestIndexSet_Cleanup(t *testing.T) {
@@ -90,7 +156,7 @@ func TestIndexSet_Cleanup(t *testing.T) {
 
 	for _, userID := range []string{userID, ""} {
 		t.Run(userID, func(t *testing.T) {
-			idxSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), util_log.Logger)
+			idxSet, err := NewIndexSet(testTableName, userID, storage.NewIndexSet(testStorageClient, userID != ""), storage.CompressionGzip, util_log.Logger)
 			require.NoError(t, err)
 			defer idxSet.Close()
 
@@ -158,10 +224,24 @@ func TestIndexSet_Cleanup(t *testing.T) {
 }
 
 // readCompressedFile reads the contents of a compressed file at given path.
+// It's decompressed independently of the storage package, based on the file extension.
 func readCompressedFile(t *testing.T, path string) []byte {
-	tempDir := t.TempDir()
-	decompressedFilePath := filepath.Join(tempDir, "decompressed")
-	testutil.DecompressFile(t, path, decompressedFilePath)
+	decompressedFilePath := filepath.Join(t.TempDir(), "decompressed")
+	switch filepath.Ext(path) {
+	case ".gz":
+		testutil.DecompressFile(t, path, decompressedFilePath)
+	case ".zst":
+		compressed, err := os.ReadFile(path)
+		require.NoError(t, err)
+		decoder, err := zstd.NewReader(nil)
+		require.NoError(t, err)
+		defer decoder.Close()
+		decompressed, err := decoder.DecodeAll(compressed, nil)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(decompressedFilePath, decompressed, 0o644))
+	default:
+		t.Fatalf("unexpected compressed file %s", path)
+	}
 
 	fileContent, err := os.ReadFile(decompressedFilePath)
 	require.NoError(t, err)
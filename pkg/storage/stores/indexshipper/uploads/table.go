@@ -20,6 +20,7 @@ const (
 type Table interface {
 	Name() string
 	AddIndex(userID string, idx index.Index) error
+	UploadIndex(ctx context.Context, userID string, idx index.Index) error
 	ForEach(ctx context.Context, userID string, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error
 	Upload(ctx context.Context) error
 	Cleanup(indexRetainPeriod time.Duration) error
@@ -33,18 +34,20 @@ type Table interface {
 type table struct {
 	name                                 string
 	baseUserIndexSet, baseCommonIndexSet storage.IndexSet
+	compression                          string
 	logger                               log.Logger
 
 	indexSet    map[string]IndexSet
 	indexSetMtx sync.RWMutex
 }
 
-// NewTable create a new table instance.
-func NewTable(name string, storageClient storage.Client) Table {
+// NewTable create a new table instance, compressing uploaded index files with the given codec.
+func NewTable(name string, storageClient storage.Client, compression string) Table {
 	return &table{
 		name:               name,
 		baseUserIndexSet:   storage.NewIndexSet(storageClient, true),
 		baseCommonIndexSet: storage.NewIndexSet(storageClient, false),
+		compression:        compression,
 		logger:             log.With(util_log.Logger, "table-name", name),
 		indexSet:           make(map[string]IndexSet),
 	}
@@ -60,23 +63,46 @@ func (lt *table) AddIndex(userID string, idx index.Index) error {
 	lt.indexSetMtx.Lock()
 	defer lt.indexSetMtx.Unlock()
 
-	if _, ok := lt.indexSet[userID]; !ok {
-		baseIndexSet := lt.baseUserIndexSet
-		if userID == "" {
-			baseIndexSet = lt.baseCommonIndexSet
-		}
-		idxSet, err := NewIndexSet(lt.name, userID, baseIndexSet, loggerWithUserID(lt.logger, userID))
-		if err != nil {
-			return err
-		}
-
-		lt.indexSet[userID] = idxSet
+	idxSet, err := lt.getOrCreateIndexSet(userID)
+	if err != nil {
+		return err
 	}
 
-	lt.indexSet[userID].Add(idx)
+	idxSet.Add(idx)
 	return nil
 }
 
+// UploadIndex uploads a new index right away and adds it to the table as already uploaded.
+func (lt *table) UploadIndex(ctx context.Context, userID string, idx index.Index) error {
+	lt.indexSetMtx.Lock()
+	idxSet, err := lt.getOrCreateIndexSet(userID)
+	lt.indexSetMtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return idxSet.AddUploaded(ctx, idx)
+}
+
+// getOrCreateIndexSet must be called with the lock held.
+func (lt *table) getOrCreateIndexSet(userID string) (IndexSet, error) {
+	if idxSet, ok := lt.indexSet[userID]; ok {
+		return idxSet, nil
+	}
+
+	baseIndexSet := lt.baseUserIndexSet
+	if userID == "" {
+		baseIndexSet = lt.baseCommonIndexSet
+	}
+	idxSet, err := NewIndexSet(lt.name, userID, baseIndexSet, lt.compression, loggerWithUserID(lt.logger, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	lt.indexSet[userID] = idxSet
+	return idxSet, nil
+}
+
 // ForEach iterates over all the indexes belonging to the user.
 func (lt *table) ForEach(ctx context.Context, userID string, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error {
 	lt.indexSetMtx.RLock()
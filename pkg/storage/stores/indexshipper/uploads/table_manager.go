@@ -16,11 +16,14 @@ import (
 type Config struct {
 	UploadInterval time.Duration
 	DBRetainPeriod time.Duration
+	// Compression is the codec index files are compressed with before uploading
+	Compression string
 }
 
 type TableManager interface {
 	Stop()
 	AddIndex(tableName, userID string, index index.Index) error
+	UploadIndex(ctx context.Context, tableName, userID string, index index.Index) error
 	ForEach(ctx context.Context, tableName, userID string, doneChan <-chan struct{}, callback index.ForEachIndexCallback) error
 }
 
@@ -92,6 +95,10 @@ func (tm *tableManager) AddIndex(tableName, userID string, index index.Index) er
 	return tm.getOrCreateTable(tableName).AddIndex(userID, index)
 }
 
+func (tm *tableManager) UploadIndex(ctx context.Context, tableName, userID string, index index.Index) error {
+	return tm.getOrCreateTable(tableName).UploadIndex(ctx, userID, index)
+}
+
 func (tm *tableManager) getTable(tableName string) (Table, bool) {
 	tm.tablesMtx.RLock()
 	defer tm.tablesMtx.RUnlock()
@@ -111,7 +118,7 @@ func (tm *tableManager) getOrCreateTable(tableName string) Table {
 
 	table, ok = tm.tables[tableName]
 	if !ok {
-		table = NewTable(tableName, tm.storageClient)
+		table = NewTable(tableName, tm.storageClient, tm.cfg.Compression)
 		tm.tables[tableName] = table
 	}
 
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
 )
 
 const (
@@ -19,7 +20,7 @@ const (
 func TestTable(t *testing.T) {
 	tempDir := t.TempDir()
 	storageClient := buildTestStorageClient(t, tempDir)
-	testTable := NewTable(testTableName, storageClient)
+	testTable := NewTable(testTableName, storageClient, storage.CompressionGzip)
 	defer testTable.Stop()
 
 	for userIdx := 0; userIdx < 2; userIdx++ {
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/stores/index"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 type IndexGatewayClientStore struct {
@@ -32,6 +33,7 @@ type IndexGatewayClient interface {
 	LabelNamesForMetricName(ctx context.Context, in *logproto.LabelNamesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error)
 	LabelValuesForMetricName(ctx context.Context, in *logproto.LabelValuesForMetricNameRequest, opts ...grpc.CallOption) (*logproto.LabelResponse, error)
 	GetStats(ctx context.Context, req *logproto.IndexStatsRequest, opts ...grpc.CallOption) (*logproto.IndexStatsResponse, error)
+	GetVolume(ctx context.Context, req *logproto.VolumeRequest, opts ...grpc.CallOption) (*logproto.VolumeResponse, error)
 }
 
 func NewIndexGatewayClientStore(client IndexGatewayClient, fallbackStore index.Reader) index.ReaderWriter {
@@ -63,10 +65,13 @@ func (c *IndexGatewayClientStore) GetChunkRefs(ctx context.Context, userID strin
 }
 
 func (c *IndexGatewayClientStore) GetSeries(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]labels.Labels, error) {
+	page := paging.PageFromContext(ctx)
 	resp, err := c.client.GetSeries(ctx, &logproto.GetSeriesRequest{
-		From:     from,
-		Through:  through,
-		Matchers: (&syntax.MatchersExpr{Mts: matchers}).String(),
+		From:      from,
+		Through:   through,
+		Matchers:  (&syntax.MatchersExpr{Mts: matchers}).String(),
+		Limit:     uint32(page.Limit),
+		PageToken: page.Token,
 	})
 	if err != nil {
 		if isUnimplementedCallError(err) && c.fallbackStore != nil {
@@ -86,10 +91,13 @@ func (c *IndexGatewayClientStore) GetSeries(ctx context.Context, userID string,
 
 // LabelNamesForMetricName retrieves all label names for a metric name.
 func (c *IndexGatewayClientStore) LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error) {
+	page := paging.PageFromContext(ctx)
 	resp, err := c.client.LabelNamesForMetricName(ctx, &logproto.LabelNamesForMetricNameRequest{
 		MetricName: metricName,
 		From:       from,
 		Through:    through,
+		Limit:      uint32(page.Limit),
+		PageToken:  page.Token,
 	})
 	if isUnimplementedCallError(err) && c.fallbackStore != nil {
 		// Handle communication with older index gateways gracefully, by falling back to the index store calls.
@@ -102,12 +110,15 @@ func (c *IndexGatewayClientStore) LabelNamesForMetricName(ctx context.Context, u
 }
 
 func (c *IndexGatewayClientStore) LabelValuesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, labelName string, matchers ...*labels.Matcher) ([]string, error) {
+	page := paging.PageFromContext(ctx)
 	resp, err := c.client.LabelValuesForMetricName(ctx, &logproto.LabelValuesForMetricNameRequest{
 		MetricName: metricName,
 		LabelName:  labelName,
 		From:       from,
 		Through:    through,
 		Matchers:   (&syntax.MatchersExpr{Mts: matchers}).String(),
+		Limit:      uint32(page.Limit),
+		PageToken:  page.Token,
 	})
 	if isUnimplementedCallError(err) && c.fallbackStore != nil {
 		// Handle communication with older index gateways gracefully, by falling back to the index store calls.
@@ -139,6 +150,26 @@ func (c *IndexGatewayClientStore) Stats(ctx context.Context, userID string, from
 	return resp, nil
 }
 
+func (c *IndexGatewayClientStore) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	resp, err := c.client.GetVolume(ctx, &logproto.VolumeRequest{
+		From:         from,
+		Through:      through,
+		Matchers:     (&syntax.MatchersExpr{Mts: matchers}).String(),
+		Limit:        limit,
+		TargetLabels: targetLabels,
+		AggregateBy:  aggregateBy,
+	})
+	if err != nil {
+		if isUnimplementedCallError(err) && c.fallbackStore != nil {
+			// Handle communication with older index gateways gracefully, by falling back to the index store calls.
+			return c.fallbackStore.Volume(ctx, userID, from, through, limit, targetLabels, aggregateBy, matchers...)
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (c *IndexGatewayClientStore) SetChunkFilterer(chunkFilter chunk.RequestChunkFilterer) {
 	// if there is no fallback store, we can't set the chunk filterer and index gateway would take care of filtering out data
 	if c.fallbackStore != nil {
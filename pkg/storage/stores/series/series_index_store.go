@@ -705,3 +705,8 @@ func (c *indexReaderWriter) convertChunkIDsToChunkRefs(_ context.Context, userID
 func (c *indexReaderWriter) Stats(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) (*stats.Stats, error) {
 	return nil, nil
 }
+
+// old index stores do not implement volume -- skip
+func (c *indexReaderWriter) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	return nil, nil
+}
@@ -83,13 +83,9 @@ func (m *mockIndexSet) ListSourceFiles() []storage.IndexFile {
 }
 
 func (m *mockIndexSet) GetSourceFile(indexFile storage.IndexFile) (string, error) {
-	decompress := storage.IsCompressedFile(indexFile.Name)
-	dst := filepath.Join(m.workingDir, indexFile.Name)
-	if decompress {
-		dst = strings.Trim(dst, ".gz")
-	}
+	dst := filepath.Join(m.workingDir, storage.TrimCompressionExtension(indexFile.Name))
 
-	err := storage.DownloadFileFromStorage(dst, storage.IsCompressedFile(indexFile.Name),
+	err := storage.DownloadFileFromStorage(dst, storage.CompressionForFile(indexFile.Name),
 		false, storage.LoggerWithFilename(util_log.Logger, indexFile.Name),
 		func() (io.ReadCloser, error) {
 			rc, _, err := m.objectClient.GetObject(context.Background(), path.Join(m.tableName, m.userID, indexFile.Name))
@@ -124,7 +124,7 @@ func newTestObjectClient(path string, clientMetrics storage.ClientMetrics) clien
 		FSConfig: local.FSConfig{
 			Directory: path,
 		},
-	}, clientMetrics)
+	}, nil, clientMetrics)
 	if err != nil {
 		panic(err)
 	}
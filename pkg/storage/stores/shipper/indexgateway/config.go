@@ -47,6 +47,19 @@ const (
 	RingMode Mode = "ring"
 )
 
+// ShardingStrategy defines how the index is distributed across the Index Gateway instances running in ring mode.
+type ShardingStrategy string
+
+const (
+	// TenantShardingStrategy assigns all the tables of a tenant to the same Index Gateway instances.
+	TenantShardingStrategy ShardingStrategy = "tenant"
+
+	// TableShardingStrategy assigns each table of a tenant to different Index Gateway instances.
+	//
+	// The index of a tenant is spread over more instances, which only keep the tables they own in memory.
+	TableShardingStrategy ShardingStrategy = "table"
+)
+
 // RingCfg is a wrapper for our Index Gateway ring configuration plus the replication factor.
 type RingCfg struct {
 	// InternalRingCfg configures the Index Gateway ring.
@@ -76,10 +89,24 @@ type Config struct {
 	// In case it isn't explicitly set, it follows the same behavior of the other rings (ex: using the common configuration
 	// section and the ingester configuration by default).
 	Ring RingCfg `yaml:"ring,omitempty"`
+
+	// ShardingStrategy configures how the index is distributed across the Index Gateway instances in ring mode.
+	ShardingStrategy ShardingStrategy `yaml:"sharding_strategy"`
 }
 
 // RegisterFlags register all IndexGatewayClientConfig flags and all the flags of its subconfigs but with a prefix (ex: shipper).
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.Ring.RegisterFlags("index-gateway.", "collectors/", f)
 	f.StringVar((*string)(&cfg.Mode), "index-gateway.mode", SimpleMode.String(), "mode in which the index gateway client will be running")
+	f.StringVar((*string)(&cfg.ShardingStrategy), "index-gateway.sharding-strategy", string(TenantShardingStrategy), "How the index is sharded across the index gateway instances in ring mode. Supported values are: tenant (each tenant is assigned to replication_factor instances) and table (each table of a tenant is assigned to replication_factor instances).")
+}
+
+// Validate validates the Index Gateway config.
+func (cfg *Config) Validate() error {
+	switch cfg.ShardingStrategy {
+	case "", TenantShardingStrategy, TableShardingStrategy:
+	default:
+		return fmt.Errorf("sharding strategy %s not supported. list of supported strategies: tenant (default), table", cfg.ShardingStrategy)
+	}
+	return nil
 }
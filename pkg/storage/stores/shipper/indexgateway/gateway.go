@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 const (
@@ -30,6 +31,7 @@ type IndexQuerier interface {
 	LabelValuesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string, labelName string, matchers ...*labels.Matcher) ([]string, error)
 	LabelNamesForMetricName(ctx context.Context, userID string, from, through model.Time, metricName string) ([]string, error)
 	Stats(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) (*stats.Stats, error)
+	Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error)
 	Stop()
 }
 
@@ -183,9 +185,14 @@ func (g *Gateway) GetSeries(ctx context.Context, req *logproto.GetSeriesRequest)
 	if err != nil {
 		return nil, err
 	}
+	series, next, err := paging.Labels(series, paging.Page{Limit: int(req.Limit), Token: req.PageToken})
+	if err != nil {
+		return nil, err
+	}
 
 	resp := &logproto.GetSeriesResponse{
-		Series: make([]logproto.IndexSeries, len(series)),
+		Series:        make([]logproto.IndexSeries, len(series)),
+		NextPageToken: next,
 	}
 	for i := range series {
 		resp.Series[i] = logproto.IndexSeries{
@@ -195,6 +202,22 @@ func (g *Gateway) GetSeries(ctx context.Context, req *logproto.GetSeriesRequest)
 	return resp, nil
 }
 
+// StreamSeries is like GetSeries, sending the series in batches.
+func (g *Gateway) StreamSeries(req *logproto.GetSeriesRequest, server logproto.IndexGateway_StreamSeriesServer) error {
+	resp, err := g.GetSeries(server.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return paging.Batches(len(resp.Series), func(start, end int, last bool) error {
+		batch := &logproto.GetSeriesResponse{Series: resp.Series[start:end]}
+		if last {
+			batch.NextPageToken = resp.NextPageToken
+		}
+		return server.Send(batch)
+	})
+}
+
 func (g *Gateway) LabelNamesForMetricName(ctx context.Context, req *logproto.LabelNamesForMetricNameRequest) (*logproto.LabelResponse, error) {
 	instanceID, err := tenant.TenantID(ctx)
 	if err != nil {
@@ -204,11 +227,25 @@ func (g *Gateway) LabelNamesForMetricName(ctx context.Context, req *logproto.Lab
 	if err != nil {
 		return nil, err
 	}
+	names, next, err := paging.Strings(names, paging.Page{Limit: int(req.Limit), Token: req.PageToken})
+	if err != nil {
+		return nil, err
+	}
 	return &logproto.LabelResponse{
-		Values: names,
+		Values:        names,
+		NextPageToken: next,
 	}, nil
 }
 
+// StreamLabelNamesForMetricName is like LabelNamesForMetricName, sending the names in batches.
+func (g *Gateway) StreamLabelNamesForMetricName(req *logproto.LabelNamesForMetricNameRequest, server logproto.IndexGateway_StreamLabelNamesForMetricNameServer) error {
+	resp, err := g.LabelNamesForMetricName(server.Context(), req)
+	if err != nil {
+		return err
+	}
+	return sendLabelResponse(resp, server.Send)
+}
+
 func (g *Gateway) LabelValuesForMetricName(ctx context.Context, req *logproto.LabelValuesForMetricNameRequest) (*logproto.LabelResponse, error) {
 	instanceID, err := tenant.TenantID(ctx)
 	if err != nil {
@@ -227,11 +264,36 @@ func (g *Gateway) LabelValuesForMetricName(ctx context.Context, req *logproto.La
 	if err != nil {
 		return nil, err
 	}
+	names, next, err := paging.Strings(names, paging.Page{Limit: int(req.Limit), Token: req.PageToken})
+	if err != nil {
+		return nil, err
+	}
 	return &logproto.LabelResponse{
-		Values: names,
+		Values:        names,
+		NextPageToken: next,
 	}, nil
 }
 
+// StreamLabelValuesForMetricName is like LabelValuesForMetricName, sending the values in batches.
+func (g *Gateway) StreamLabelValuesForMetricName(req *logproto.LabelValuesForMetricNameRequest, server logproto.IndexGateway_StreamLabelValuesForMetricNameServer) error {
+	resp, err := g.LabelValuesForMetricName(server.Context(), req)
+	if err != nil {
+		return err
+	}
+	return sendLabelResponse(resp, server.Send)
+}
+
+// sendLabelResponse sends the values of resp in batches, the last one carrying the token of the next page.
+func sendLabelResponse(resp *logproto.LabelResponse, send func(*logproto.LabelResponse) error) error {
+	return paging.Batches(len(resp.Values), func(start, end int, last bool) error {
+		batch := &logproto.LabelResponse{Values: resp.Values[start:end]}
+		if last {
+			batch.NextPageToken = resp.NextPageToken
+		}
+		return send(batch)
+	})
+}
+
 func (g *Gateway) GetStats(ctx context.Context, req *logproto.IndexStatsRequest) (*logproto.IndexStatsResponse, error) {
 	instanceID, err := tenant.TenantID(ctx)
 	if err != nil {
@@ -244,3 +306,16 @@ func (g *Gateway) GetStats(ctx context.Context, req *logproto.IndexStatsRequest)
 
 	return g.indexQuerier.Stats(ctx, instanceID, req.From, req.Through, matchers...)
 }
+
+func (g *Gateway) GetVolume(ctx context.Context, req *logproto.VolumeRequest) (*logproto.VolumeResponse, error) {
+	instanceID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matchers, err := syntax.ParseMatchers(req.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.indexQuerier.Volume(ctx, instanceID, req.From, req.Through, req.Limit, req.TargetLabels, req.AggregateBy, matchers...)
+}
@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc"
 
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
 	"github.com/grafana/loki/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/pkg/storage/stores/shipper/util"
 	util_math "github.com/grafana/loki/pkg/util/math"
+	"github.com/grafana/loki/pkg/util/paging"
 )
 
 const (
@@ -142,3 +147,73 @@ func TestGateway_QueryIndex(t *testing.T) {
 		require.Len(t, expectedRanges, 0)
 	}
 }
+
+type mockIndexQuerier struct {
+	IndexQuerier
+	series []labels.Labels
+	values []string
+}
+
+func (m mockIndexQuerier) GetSeries(_ context.Context, _ string, _, _ model.Time, _ ...*labels.Matcher) ([]labels.Labels, error) {
+	return m.series, nil
+}
+
+func (m mockIndexQuerier) LabelValuesForMetricName(_ context.Context, _ string, _, _ model.Time, _ string, _ string, _ ...*labels.Matcher) ([]string, error) {
+	return m.values, nil
+}
+
+type mockStreamLabelServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*logproto.LabelResponse
+}
+
+func (m *mockStreamLabelServer) Send(resp *logproto.LabelResponse) error {
+	m.responses = append(m.responses, resp)
+	return nil
+}
+
+func (m *mockStreamLabelServer) Context() context.Context {
+	return m.ctx
+}
+
+func TestGateway_Paging(t *testing.T) {
+	values := make([]string, paging.BatchSize+2)
+	for i := range values {
+		values[i] = fmt.Sprintf("value-%06d", i)
+	}
+	gateway := Gateway{indexQuerier: mockIndexQuerier{
+		series: []labels.Labels{labels.FromStrings("a", "2"), labels.FromStrings("a", "1"), labels.FromStrings("a", "3")},
+		values: values,
+	}}
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	t.Run("series", func(t *testing.T) {
+		resp, err := gateway.GetSeries(ctx, &logproto.GetSeriesRequest{Matchers: `{a=~".+"}`, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, []logproto.IndexSeries{
+			{Labels: logproto.FromLabelsToLabelAdapters(labels.FromStrings("a", "1"))},
+			{Labels: logproto.FromLabelsToLabelAdapters(labels.FromStrings("a", "2"))},
+		}, resp.Series)
+
+		resp, err = gateway.GetSeries(ctx, &logproto.GetSeriesRequest{Matchers: `{a=~".+"}`, Limit: 2, PageToken: resp.NextPageToken})
+		require.NoError(t, err)
+		require.Equal(t, []logproto.IndexSeries{{Labels: logproto.FromLabelsToLabelAdapters(labels.FromStrings("a", "3"))}}, resp.Series)
+		require.Empty(t, resp.NextPageToken)
+	})
+
+	t.Run("streamed label values", func(t *testing.T) {
+		server := &mockStreamLabelServer{ctx: ctx}
+		err := gateway.StreamLabelValuesForMetricName(&logproto.LabelValuesForMetricNameRequest{
+			Matchers: syntax.EmptyMatchers,
+			Limit:    paging.BatchSize + 1,
+		}, server)
+		require.NoError(t, err)
+
+		require.Len(t, server.responses, 2)
+		require.Equal(t, values[:paging.BatchSize], server.responses[0].Values)
+		require.Empty(t, server.responses[0].NextPageToken)
+		require.Equal(t, values[paging.BatchSize:paging.BatchSize+1], server.responses[1].Values)
+		require.Equal(t, paging.NextToken(values[paging.BatchSize]), server.responses[1].NextPageToken)
+	})
+}
@@ -227,12 +227,12 @@ func (rm *RingManager) stopping(_ error) error {
 	return services.StopManagerAndAwaitStopped(context.Background(), rm.subservices)
 }
 
-// IndexGatewayOwnsTenant dictates if a given tenant should be ignored by an IndexGateway or not.
+// IndexGatewayOwnsTenant dictates if the index of a given tenant in a given table should be ignored by an IndexGateway or not.
 //
 // It fallbacks to true so that the IndexGateway will only skip tenants if it is certain of that.
 // This implementation relies on the tokens assigned to an IndexGateway instance to define if a tenant
-// is assigned or not.
-func (rm *RingManager) IndexGatewayOwnsTenant(tenant string) bool {
+// is assigned or not. With the table sharding strategy, each table of a tenant is assigned separately.
+func (rm *RingManager) IndexGatewayOwnsTenant(tenant, tableName string) bool {
 	if rm.cfg.Mode != RingMode {
 		return true
 	}
@@ -242,7 +242,13 @@ func (rm *RingManager) IndexGatewayOwnsTenant(tenant string) bool {
 		return true
 	}
 
-	return loki_util.IsAssignedKey(rm.Ring, rm.RingLifecycler.GetInstanceAddr(), tenant)
+	token := TokenFor(rm.cfg.ShardingStrategy, tenant, TableNumberFromName(tableName))
+	inSet, err := loki_util.IsInReplicationSet(rm.Ring, token, rm.RingLifecycler.GetInstanceAddr())
+	if err != nil {
+		level.Error(rm.log).Log("msg", "error checking if tenant is in replicationset", "err", err, "tenant", tenant, "table", tableName)
+		return false
+	}
+	return inSet
 }
 
 // ServeHTTP serves the HTTP route /indexgateway/ring.
@@ -0,0 +1,49 @@
+package indexgateway
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	loki_util "github.com/grafana/loki/pkg/util"
+)
+
+const daySeconds = int64(24 * time.Hour / time.Second)
+
+var tableNumberRegex = regexp.MustCompile(`[0-9]+$`)
+
+// TableNumberForTime returns the number of the daily index table containing t.
+func TableNumberForTime(t model.Time) int64 {
+	return t.Unix() / daySeconds
+}
+
+// TableStart returns the start time of the daily index table with the given number.
+func TableStart(tableNumber int64) model.Time {
+	return model.TimeFromUnix(tableNumber * daySeconds)
+}
+
+// TableNumberFromName returns the number of the index table with the given name, or -1 if it has none.
+func TableNumberFromName(tableName string) int64 {
+	match := tableNumberRegex.FindString(tableName)
+	if match == "" {
+		return -1
+	}
+	tableNumber, err := strconv.ParseInt(match, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return tableNumber
+}
+
+// TokenFor returns the ring token of the Index Gateway instances responsible for the index of the given tenant
+// in the table with the given number.
+//
+// With the tenant sharding strategy, or for tables without number, the token only depends on the tenant.
+func TokenFor(strategy ShardingStrategy, tenant string, tableNumber int64) uint32 {
+	if strategy != TableShardingStrategy || tableNumber < 0 {
+		return loki_util.TokenFor(tenant, "" /* labels */)
+	}
+	return loki_util.TokenFor(tenant, strconv.FormatInt(tableNumber, 10))
+}
@@ -0,0 +1,39 @@
+package indexgateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	loki_util "github.com/grafana/loki/pkg/util"
+)
+
+func TestTableNumber(t *testing.T) {
+	require.Equal(t, int64(19000), TableNumberForTime(model.TimeFromUnix(19000*24*3600+10)))
+	require.Equal(t, int64(19000), TableNumberForTime(model.TimeFromUnix(19001*24*3600).Add(-time.Millisecond)))
+
+	require.Equal(t, int64(19000), TableNumberFromName("index_19000"))
+	require.Equal(t, int64(-1), TableNumberFromName("index"))
+}
+
+func TestTokenFor(t *testing.T) {
+	// the tenant strategy ignores the table.
+	require.Equal(t, loki_util.TokenFor("tenant", ""), TokenFor(TenantShardingStrategy, "tenant", 19000))
+	require.Equal(t, loki_util.TokenFor("tenant", ""), TokenFor("", "tenant", 19000))
+
+	require.Equal(t, loki_util.TokenFor("tenant", "19000"), TokenFor(TableShardingStrategy, "tenant", 19000))
+	require.NotEqual(t, TokenFor(TableShardingStrategy, "tenant", 19000), TokenFor(TableShardingStrategy, "tenant", 19001))
+	// tables without number fall back to the tenant token.
+	require.Equal(t, loki_util.TokenFor("tenant", ""), TokenFor(TableShardingStrategy, "tenant", -1))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, strategy := range []ShardingStrategy{"", TenantShardingStrategy, TableShardingStrategy} {
+		cfg := Config{ShardingStrategy: strategy}
+		require.NoError(t, cfg.Validate())
+	}
+	cfg := Config{ShardingStrategy: "series"}
+	require.Error(t, cfg.Validate())
+}
@@ -72,6 +72,9 @@ func NewShipper(cfg Config, storageClient client.ObjectClient, limits downloads.
 
 	err := i.init(storageClient, limits, ownsTenantFn, tableRanges, registerer)
 	if err != nil {
+		if i.indexShipper != nil {
+			i.indexShipper.Stop()
+		}
 		return nil, err
 	}
 
@@ -3,6 +3,7 @@ package tsdb
 import (
 	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -19,11 +20,14 @@ import (
 // Builder is a helper used to create tsdb indices.
 // It can accept streams in any order and will create the tsdb
 // index appropriately via `Build()`
-// It can even receive multiple writes for the same stream with the caveat
-// that chunks must be added in order and not duplicated
+// It can even receive multiple writes for the same stream, i.e. when the same
+// chunks are replayed from multiple WALs after a crash. The chunks are sorted and
+// the duplicated ones dropped when they are finalized.
 type Builder struct {
 	streams         map[string]*stream
 	chunksFinalized bool
+	// nil unless the symbols are shared with other builders, see symbolPool
+	symbols *symbolPool
 }
 
 type stream struct {
@@ -33,13 +37,21 @@ type stream struct {
 }
 
 func NewBuilder() *Builder {
-	return &Builder{streams: make(map[string]*stream)}
+	return newBuilder(nil)
+}
+
+// newBuilder creates a Builder interning the labels of its series into symbols, if not nil.
+func newBuilder(symbols *symbolPool) *Builder {
+	return &Builder{streams: make(map[string]*stream), symbols: symbols}
 }
 
 func (b *Builder) AddSeries(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) {
 	id := ls.String()
 	s, ok := b.streams[id]
 	if !ok {
+		if b.symbols != nil {
+			ls = b.symbols.internLabels(ls)
+		}
 		s = &stream{
 			labels: ls,
 			fp:     fp,
@@ -50,6 +62,11 @@ func (b *Builder) AddSeries(ls labels.Labels, fp model.Fingerprint, chks []index
 	s.chunks = append(s.chunks, chks...)
 }
 
+// Series returns the number of series added to the builder.
+func (b *Builder) Series() int {
+	return len(b.streams)
+}
+
 func (b *Builder) FinalizeChunks() {
 	for id := range b.streams {
 		b.streams[id].chunks = b.streams[id].chunks.Finalize()
@@ -111,6 +128,36 @@ func (b *Builder) Build(
 	if err != nil {
 		return id, err
 	}
+	if err := b.write(writer); err != nil {
+		return id, err
+	}
+
+	return moveToIdentifier(tmpPath, createFn)
+}
+
+// BuildTo builds the index in memory and writes it to w, instead of writing it to a scratch directory first.
+func (b *Builder) BuildTo(ctx context.Context, w io.Writer) error {
+	data, err := b.buildInMemory(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (b *Builder) buildInMemory(ctx context.Context) ([]byte, error) {
+	writer, err := index.NewMemWriter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.write(writer); err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// write writes the series to the index writer and closes it.
+func (b *Builder) write(writer *index.Writer) error {
 	// TODO(owen-d): multithread
 
 	// Sort series
@@ -130,26 +177,10 @@ func (b *Builder) Build(
 		return labels.Compare(streams[i].labels, streams[j].labels) < 0
 	})
 
-	// Build symbols
-	symbolsMap := make(map[string]struct{})
-	for _, s := range streams {
-		for _, l := range s.labels {
-			symbolsMap[l.Name] = struct{}{}
-			symbolsMap[l.Value] = struct{}{}
-		}
-	}
-
-	// Sort symbols
-	symbols := make([]string, 0, len(symbolsMap))
-	for s := range symbolsMap {
-		symbols = append(symbols, s)
-	}
-	sort.Strings(symbols)
-
 	// Add symbols
-	for _, symbol := range symbols {
+	for _, symbol := range b.sortedSymbols(streams) {
 		if err := writer.AddSymbol(symbol); err != nil {
-			return id, err
+			return err
 		}
 	}
 
@@ -159,14 +190,43 @@ func (b *Builder) Build(
 			s.chunks = s.chunks.Finalize()
 		}
 		if err := writer.AddSeries(storage.SeriesRef(i), s.labels, s.fp, s.chunks...); err != nil {
-			return id, err
+			return err
 		}
 	}
 
-	if err := writer.Close(); err != nil {
-		return id, err
+	return writer.Close()
+}
+
+// sortedSymbols returns the sorted, deduplicated label names and values of the streams.
+func (b *Builder) sortedSymbols(streams []*stream) []string {
+	if b.symbols != nil {
+		if symbols, ok := b.symbols.sortedSymbols(streams); ok {
+			return symbols
+		}
+	}
+
+	symbolsMap := make(map[string]struct{})
+	for _, s := range streams {
+		for _, l := range s.labels {
+			symbolsMap[l.Name] = struct{}{}
+			symbolsMap[l.Value] = struct{}{}
+		}
+	}
+
+	symbols := make([]string, 0, len(symbolsMap))
+	for s := range symbolsMap {
+		symbols = append(symbols, s)
 	}
+	sort.Strings(symbols)
+	return symbols
+}
 
+// moveToIdentifier loads the bounds+checksum of the freshly written index at tmpPath,
+// resolves its Identifier via createFn and moves the index into place.
+func moveToIdentifier(
+	tmpPath string,
+	createFn func(from, through model.Time, checksum uint32) Identifier,
+) (id Identifier, err error) {
 	reader, err := index.NewFileReader(tmpPath)
 	if err != nil {
 		return id, err
@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package tsdb
+
+import "golang.org/x/sys/unix"
+
+// freeDiskSpace returns the disk space available to unprivileged users on the filesystem containing dir.
+func freeDiskSpace(dir string) (uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
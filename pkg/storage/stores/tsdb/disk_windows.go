@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package tsdb
+
+import "golang.org/x/sys/windows"
+
+// freeDiskSpace returns the disk space available to the current user on the volume containing dir.
+func freeDiskSpace(dir string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
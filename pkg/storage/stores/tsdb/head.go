@@ -42,6 +42,7 @@ const (
 
 	statusLabel          = "status"
 	tsdbBuildSourceLabel = "source"
+	tenantLabel          = "tenant"
 
 	statusFailure = "failure"
 	statusSuccess = "success"
@@ -62,11 +63,37 @@ guaranteeing we maintain querying consistency for the entire data lifecycle.
 
 // TODO(owen-d)
 type Metrics struct {
-	seriesNotFound       prometheus.Counter
-	headRotations        *prometheus.CounterVec
-	walTruncations       *prometheus.CounterVec
-	tsdbBuilds           *prometheus.CounterVec
-	tsdbBuildLastSuccess prometheus.Gauge
+	seriesNotFound        prometheus.Counter
+	headRotations         *prometheus.CounterVec
+	headRotationReasons   *prometheus.CounterVec
+	walTruncations        *prometheus.CounterVec
+	walCorruptRecords     prometheus.Counter
+	walQuarantines        *prometheus.CounterVec
+	walRecoveryDuration   prometheus.Histogram
+	corruptIndices        *prometheus.CounterVec
+	preShipCompactions    *prometheus.CounterVec
+	directUploads         *prometheus.CounterVec
+	tsdbBuilds            *prometheus.CounterVec
+	tsdbBuildLastSuccess  prometheus.Gauge
+	tsdbBuildDiskHeadroom prometheus.Gauge
+
+	// per tenant/bucket build stats, used to attribute index build cost to tenants
+	tsdbBuildSeries         *prometheus.CounterVec
+	tsdbBuildChunks         *prometheus.CounterVec
+	tsdbBuildDuration       prometheus.Histogram
+	tsdbBuildTenantDuration *prometheus.CounterVec
+	tsdbBuildBytesWritten   *prometheus.CounterVec
+	tsdbBuildPrunedChunks   *prometheus.CounterVec
+
+	// distributions of the built indices, used for head rotation SLOs.
+	// TODO: switch to native histograms once client_golang is upgraded to v1.14+,
+	// exponential buckets approximate them until then.
+	tsdbBuildIndexSize   prometheus.Histogram
+	tsdbBuildIndexSeries prometheus.Histogram
+
+	// garbage collection of the WALs left behind after their TSDBs were shipped
+	walGCRemovals       *prometheus.CounterVec
+	walGCReclaimedBytes prometheus.Counter
 }
 
 func NewMetrics(r prometheus.Registerer) *Metrics {
@@ -81,11 +108,48 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			Name:      "head_rotation_attempts_total",
 			Help:      "Total number of tsdb head rotations partitioned by status",
 		}, []string{statusLabel}),
+		headRotationReasons: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "head_rotations_total",
+			Help:      "Total number of successful tsdb head rotations partitioned by what triggered them: the end of their period, the size of their WAL or their number of series",
+		}, []string{"reason"}),
 		walTruncations: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
 			Namespace: "loki_tsdb",
 			Name:      "wal_truncation_attempts_total",
 			Help:      "Total number of WAL truncations partitioned by status",
 		}, []string{statusLabel}),
+		walCorruptRecords: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "wal_corrupt_records_skipped_total",
+			Help:      "Total number of corrupt WAL records skipped during replay",
+		}),
+		walQuarantines: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "wal_quarantine_attempts_total",
+			Help:      "Total number of corrupt WALs moved to the quarantine dir partitioned by status",
+		}, []string{statusLabel}),
+		walRecoveryDuration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_tsdb",
+			Name:      "wal_recovery_duration_seconds",
+			Help:      "Time taken to replay a single WAL when building tsdb indices from WALs",
+			// 10ms -> ~3m
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+		}),
+		corruptIndices: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "corrupt_leftover_indices_total",
+			Help:      "Total number of corrupt leftover local indices found on startup partitioned by action taken and status",
+		}, []string{"action", statusLabel}),
+		preShipCompactions: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "pre_ship_compactions_total",
+			Help:      "Total number of merges of TSDBs built for the same period before shipping them partitioned by status",
+		}, []string{statusLabel}),
+		directUploads: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "direct_uploads_total",
+			Help:      "Total number of TSDBs built in memory and uploaded right away partitioned by status, failed ones being written to the disk to be shipped later",
+		}, []string{statusLabel}),
 		tsdbBuilds: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
 			Namespace: "loki_tsdb",
 			Name:      "build_index_attempts_total",
@@ -96,6 +160,66 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			Name:      "build_index_last_successful_timestamp_seconds",
 			Help:      "Unix timestamp of the last successful tsdb index build",
 		}),
+		tsdbBuildDiskHeadroom: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_disk_headroom_bytes",
+			Help:      "Free disk space left in the tsdb directory after the space estimated for the last build and the configured minimum free disk space. Builds are refused while it's negative.",
+		}),
+		tsdbBuildSeries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_series_total",
+			Help:      "Total number of series written into multitenant tsdb indices partitioned by tenant",
+		}, []string{tenantLabel}),
+		tsdbBuildChunks: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_chunks_total",
+			Help:      "Total number of chunk refs written into multitenant tsdb indices partitioned by tenant",
+		}, []string{tenantLabel}),
+		tsdbBuildDuration: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_duration_seconds",
+			Help:      "Time taken to build a tsdb index for a period bucket",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		tsdbBuildTenantDuration: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_tenant_duration_seconds_total",
+			Help:      "Total time spent building tsdb indices partitioned by tenant. A multitenant index is built at once, so its build time is attributed to tenants proportionally to the number of chunk refs they contributed",
+		}, []string{tenantLabel}),
+		tsdbBuildBytesWritten: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_bytes_written_total",
+			Help:      "Total number of bytes written to multitenant tsdb indices partitioned by tenant. Bytes are attributed to tenants proportionally to the number of chunk refs they contributed",
+		}, []string{tenantLabel}),
+		tsdbBuildPrunedChunks: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_chunks_pruned_total",
+			Help:      "Total number of chunk refs skipped when building tsdb indices because they were past their retention period partitioned by tenant",
+		}, []string{tenantLabel}),
+		tsdbBuildIndexSize: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_size_bytes",
+			Help:      "Size of the tsdb indices built per period bucket",
+			// 1KB -> 256MB
+			Buckets: prometheus.ExponentialBuckets(1<<10, 4, 10),
+		}),
+		tsdbBuildIndexSeries: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_tsdb",
+			Name:      "build_index_series",
+			Help:      "Number of series written into the tsdb indices built per period bucket",
+			// 10 -> ~2.6M
+			Buckets: prometheus.ExponentialBuckets(10, 4, 10),
+		}),
+		walGCRemovals: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "wal_gc_removals_total",
+			Help:      "Total number of stale WALs, whose TSDBs were already shipped, removed by the WAL garbage collection partitioned by status",
+		}, []string{statusLabel}),
+		walGCReclaimedBytes: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "wal_gc_reclaimed_bytes_total",
+			Help:      "Total number of bytes reclaimed by removing stale WALs",
+		}),
 	}
 }
 
@@ -245,6 +369,15 @@ func newStripeSeries() *stripeSeries {
 	return s
 }
 
+// contains returns whether the series with the given labels exists.
+func (s *stripeSeries) contains(ls labels.Labels) bool {
+	fp := ls.Hash()
+	hashes := s.hashes[fp&uint64(s.shards-1)]
+	hashes.RLock()
+	defer hashes.RUnlock()
+	return hashes.get(fp, ls) != nil
+}
+
 func (s *stripeSeries) getByID(id uint64) *memSeries {
 	x := s.series[id&uint64(s.shards-1)]
 	x.RLock()
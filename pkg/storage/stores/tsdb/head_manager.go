@@ -42,6 +42,13 @@ const (
 	defaultRotationPeriod = period(15 * time.Minute)
 	// defines the period to check for active head rotation
 	defaultRotationCheckPeriod = 1 * time.Minute
+	// how long in-flight TSDB builds are given to finish on shutdown before being cancelled
+	defaultShutdownTimeout = 30 * time.Second
+
+	// what triggered the rotation of the active head
+	rotationReasonPeriod  = "period"
+	rotationReasonWALSize = "wal_size"
+	rotationReasonSeries  = "series"
 )
 
 func (p period) PeriodFor(t time.Time) int {
@@ -56,6 +63,14 @@ func (p period) TimeForPeriod(n int) time.Time {
 // do shard index calcuations via bitwise & rather than modulos.
 const defaultHeadManagerStripeSize = 1 << 7
 
+// TenantSeriesStats are the series of a tenant in the active heads.
+type TenantSeriesStats struct {
+	Tenant       string `json:"tenant"`
+	ActiveSeries uint64 `json:"active_series"`
+	// Limit is the maximum number of active series of the tenant, 0 for no limit.
+	Limit int `json:"limit"`
+}
+
 /*
 HeadManager both accepts flushed chunk writes
 and exposes the index interface for multiple tenants.
@@ -69,6 +84,9 @@ tsdb/
      v1/
 		# scratch directory used for temp tsdb files during build stage
 		scratch/
+			# corrupt WALs which could only be partially recovered
+			quarantine/
+				<timestamp>
 		# wal directory used to store WALs being written on the ingester.
 		# These are eventually shipped to storage as multi-tenant TSDB files
 		# and compacted into per tenant indices
@@ -78,11 +96,12 @@ tsdb/
 		multitenant/
 					<timestamp>-<ingester-name>.tsdb
 		per_tenant/
-		 		  # post-compaction tenant tsdbs which are grouped per
-				  # period bucket
+		 		  # per tenant tsdbs which are created on the ingesters when
+				  # configured to build per tenant rather than multitenant tsdbs,
+				  # grouped per period bucket
 				  <tenant>/
 						   <bucket>/
-									index-<from>-<through>-<checksum>.tsdb
+									<timestamp>-<ingester-name>.tsdb
 */
 
 type HeadManager struct {
@@ -96,6 +115,14 @@ type HeadManager struct {
 
 	// how often WALs should be rotated and TSDBs cut
 	period period
+	// deadline for the in-flight builds on Stop
+	shutdownTimeout time.Duration
+	// how many rotations ago shipped WALs are garbage collected, 0 disables it
+	walGCMinRotations int
+	// the active head is rotated before the end of its period once its WAL reaches maxWALSize bytes
+	// or it holds maxSeries series, 0 disables them
+	maxWALSize int64
+	maxSeries  int
 
 	tsdbManager  TSDBManager
 	active, prev *headWAL
@@ -107,6 +134,11 @@ type HeadManager struct {
 
 	wg     sync.WaitGroup
 	cancel chan struct{}
+
+	// buildCtx is derived on Start and passed to the tsdbManager builds,
+	// cancelBuilds aborts them on Stop
+	buildCtx     context.Context
+	cancelBuilds context.CancelFunc
 }
 
 func NewHeadManager(logger log.Logger, dir string, metrics *Metrics, tsdbManager TSDBManager) *HeadManager {
@@ -117,8 +149,9 @@ func NewHeadManager(logger log.Logger, dir string, metrics *Metrics, tsdbManager
 		metrics:     metrics,
 		tsdbManager: tsdbManager,
 
-		period: defaultRotationPeriod,
-		shards: shards,
+		period:          defaultRotationPeriod,
+		shutdownTimeout: defaultShutdownTimeout,
+		shards:          shards,
 
 		cancel: make(chan struct{}),
 	}
@@ -181,17 +214,23 @@ func (m *HeadManager) loop() {
 			}
 
 			now := time.Now()
-			if activePeriod := m.period.PeriodFor(m.activeHeads.start); m.period.PeriodFor(now) > activePeriod {
+			if reason, ok := m.rotationReason(now); ok {
+				activePeriod := m.period.PeriodFor(m.activeHeads.start)
 				if err := m.Rotate(now); err != nil {
 					m.metrics.headRotations.WithLabelValues(statusFailure).Inc()
 					level.Error(m.log).Log(
 						"msg", "failed rotating tsdb head",
 						"period", activePeriod,
+						"reason", reason,
 						"err", err,
 					)
 					continue
 				}
 				m.metrics.headRotations.WithLabelValues(statusSuccess).Inc()
+				m.metrics.headRotationReasons.WithLabelValues(reason).Inc()
+				if reason != rotationReasonPeriod {
+					level.Info(m.log).Log("msg", "rotated tsdb head before the end of its period", "period", activePeriod, "reason", reason)
+				}
 			}
 
 			// build tsdb from rotated-out period
@@ -202,34 +241,33 @@ func (m *HeadManager) loop() {
 					"err", err,
 				)
 			}
+
+			m.removeStaleWALs()
 		case <-m.cancel:
 			return
 		}
 	}
 }
 
+// Stop stops the tsdbManager before draining the loop, so that a build the loop is
+// running gets cancelled once the shutdown timeout elapses instead of blocking shutdown.
+// Heads aren't built on shutdown: their WALs are only truncated after a successful build,
+// so both the cancelled and the never started builds are done from the WALs on the next Start.
 func (m *HeadManager) Stop() error {
 	close(m.cancel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	err := m.tsdbManager.Stop(ctx)
+	m.cancelBuilds()
 	m.wg.Wait()
 
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
-	if err := m.active.Stop(); err != nil {
-		return err
+	if stopErr := m.active.Stop(); stopErr != nil && err == nil {
+		err = stopErr
 	}
-
-	if m.prev != nil {
-		if err := m.buildTSDBFromHead(m.prevHeads); err != nil {
-			// log the error and start building active head
-			level.Error(m.log).Log(
-				"msg", "failed building tsdb from prev head",
-				"period", m.period.PeriodFor(m.prev.initialized),
-				"err", err,
-			)
-		}
-	}
-
-	return m.buildTSDBFromHead(m.activeHeads)
+	return err
 }
 
 func (m *HeadManager) Append(userID string, ls labels.Labels, fprint uint64, chks index.ChunkMetas) error {
@@ -247,8 +285,46 @@ func (m *HeadManager) Append(userID string, ls labels.Labels, fprint uint64, chk
 	return m.active.Log(rec)
 }
 
+// HeadSeries returns the number of series of the tenant in the active heads and whether the series with the given
+// labels is one of them. The labels must not have a metric name, which Append drops.
+func (m *HeadManager) HeadSeries(userID string, ls labels.Labels) (uint64, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if m.activeHeads == nil {
+		return 0, false
+	}
+	return m.activeHeads.headSeries(userID, ls)
+}
+
+// SeriesStats returns the active series of each tenant in the active heads, sorted by decreasing number of series.
+// The limits of the returned stats are left to the caller.
+func (m *HeadManager) SeriesStats() []TenantSeriesStats {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if m.activeHeads == nil {
+		return nil
+	}
+	stats := m.activeHeads.seriesStats()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ActiveSeries != stats[j].ActiveSeries {
+			return stats[i].ActiveSeries > stats[j].ActiveSeries
+		}
+		return stats[i].Tenant < stats[j].Tenant
+	})
+	return stats
+}
+
+// LocalTSDBs returns the TSDBs built from the heads which are still held locally, see tsdbManager.LocalTSDBs.
+func (m *HeadManager) LocalTSDBs() ([]LocalTSDB, error) {
+	return m.tsdbManager.LocalTSDBs()
+}
+
 func (m *HeadManager) Start() error {
-	if err := os.RemoveAll(filepath.Join(m.dir, "scratch")); err != nil {
+	m.buildCtx, m.cancelBuilds = context.WithCancel(context.Background())
+
+	if err := cleanScratchDir(m.dir); err != nil {
 		return errors.Wrap(err, "removing tsdb scratch dir")
 	}
 
@@ -277,6 +353,7 @@ func (m *HeadManager) Start() error {
 
 	now := time.Now()
 	if err := m.tsdbManager.BuildFromWALs(
+		m.buildCtx,
 		now,
 		allWALs,
 	); err != nil {
@@ -300,9 +377,33 @@ func (m *HeadManager) Start() error {
 	return nil
 }
 
+// cleanScratchDir removes everything in the scratch dir
+// except for quarantined WALs, which are kept for inspection.
+func cleanScratchDir(parent string) error {
+	entries, err := os.ReadDir(managerScratchDir(parent))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		p := filepath.Join(managerScratchDir(parent), e.Name())
+		if p == managerQuarantineDir(parent) {
+			continue
+		}
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func managerRequiredDirs(parent string) []string {
 	return []string{
 		managerScratchDir(parent),
+		managerQuarantineDir(parent),
 		managerWalDir(parent),
 		managerMultitenantDir(parent),
 		managerPerTenantDir(parent),
@@ -312,6 +413,11 @@ func managerScratchDir(parent string) string {
 	return filepath.Join(parent, "scratch")
 }
 
+// quarantined (corrupt) WALs are moved here rather than being deleted
+func managerQuarantineDir(parent string) string {
+	return filepath.Join(managerScratchDir(parent), "quarantine")
+}
+
 func managerWalDir(parent string) string {
 	return filepath.Join(parent, "wal")
 }
@@ -357,14 +463,60 @@ func (m *HeadManager) Rotate(t time.Time) (err error) {
 	return nil
 }
 
+// rotationReason returns why the active head should be rotated at now, if it should: its period has elapsed,
+// its WAL grew past maxWALSize or it holds maxSeries series.
+func (m *HeadManager) rotationReason(now time.Time) (string, bool) {
+	m.mtx.RLock()
+	heads := m.activeHeads
+	m.mtx.RUnlock()
+
+	if m.period.PeriodFor(now) > m.period.PeriodFor(heads.start) {
+		return rotationReasonPeriod, true
+	}
+	// WALs are named after the second they were created at
+	if now.Unix() <= heads.start.Unix() {
+		return "", false
+	}
+	if m.maxSeries > 0 && heads.numSeries() >= uint64(m.maxSeries) {
+		return rotationReasonSeries, true
+	}
+	if m.maxWALSize > 0 {
+		size, err := dirSize(walPath(m.dir, heads.start))
+		if err != nil {
+			level.Warn(m.log).Log("msg", "failed getting the size of the active wal", "err", err)
+			return "", false
+		}
+		if size >= m.maxWALSize {
+			return rotationReasonWALSize, true
+		}
+	}
+	return "", false
+}
+
+// Plan reports the TSDBs which would be built from the WALs currently on disk,
+// including the active one, without building them. See TSDBManager.PlanFromWALs.
+func (m *HeadManager) Plan(ctx context.Context) ([]BuildPlan, error) {
+	groups, err := walsByPeriod(m.dir, m.period)
+	if err != nil {
+		return nil, err
+	}
+
+	var wals []WALIdentifier
+	for _, group := range groups {
+		wals = append(wals, group.wals...)
+	}
+	return m.tsdbManager.PlanFromWALs(ctx, wals)
+}
+
 func (m *HeadManager) buildTSDBFromHead(head *tenantHeads) error {
 	period := m.period.PeriodFor(head.start)
-	if err := m.tsdbManager.BuildFromHead(head); err != nil {
+	if err := m.tsdbManager.BuildFromHead(m.buildCtx, head); err != nil {
 		return errors.Wrap(err, "building tsdb from head")
 	}
 
-	// Now that a TSDB has been created from this group, it's safe to remove them
-	if err := m.truncateWALForPeriod(period); err != nil {
+	// Now that a TSDB has been created from this group, it's safe to remove them.
+	// The heads rotated early share their period with the next ones, whose WALs are kept.
+	if err := m.truncateWALForPeriod(period, head.start); err != nil {
 		level.Error(m.log).Log(
 			"msg", "failed truncating wal files",
 			"period", period,
@@ -375,7 +527,24 @@ func (m *HeadManager) buildTSDBFromHead(head *tenantHeads) error {
 	return nil
 }
 
-func (m *HeadManager) truncateWALForPeriod(period int) (err error) {
+// removeStaleWALs removes the WALs left behind at least walGCMinRotations rotations ago whose TSDBs have all been shipped.
+func (m *HeadManager) removeStaleWALs() {
+	if m.walGCMinRotations <= 0 {
+		return
+	}
+
+	before := m.period.TimeForPeriod(m.period.PeriodFor(m.activeHeads.start) - m.walGCMinRotations + 1)
+	if err := m.tsdbManager.RemoveShippedWALs(before); err != nil {
+		level.Error(m.log).Log(
+			"msg", "failed removing stale wals",
+			"before", before,
+			"err", err,
+		)
+	}
+}
+
+// truncateWALForPeriod removes the WALs of the period created no later than through.
+func (m *HeadManager) truncateWALForPeriod(period int, through time.Time) (err error) {
 	defer func() {
 		status := statusSuccess
 		if err != nil {
@@ -391,6 +560,14 @@ func (m *HeadManager) truncateWALForPeriod(period int) (err error) {
 	}
 	level.Debug(m.log).Log("msg", "listed WALs", "pd", grp.period, "n", len(grp.wals))
 
+	wals := grp.wals[:0]
+	for _, id := range grp.wals {
+		if !id.ts.After(through) {
+			wals = append(wals, id)
+		}
+	}
+	grp.wals = wals
+
 	if err := m.removeWALGroup(grp); err != nil {
 		return errors.Wrapf(err, "removing TSDB WALs for period %d", grp.period)
 	}
@@ -422,36 +599,49 @@ func walsByPeriod(dir string, period period) ([]WalGroup, error) {
 }
 
 func walGroups(dir string, period period) (map[int]*WalGroup, error) {
-	files, err := os.ReadDir(managerWalDir(dir))
+	// Ensure the earliest wals are seen first
+	wals, err := ListWALs(dir)
 	if err != nil {
 		return nil, err
 	}
 
 	groupsMap := map[int]*WalGroup{}
 
-	for _, f := range files {
-		if id, ok := parseWALPath(f.Name()); ok {
-			pd := period.PeriodFor(id.ts)
-			grp, ok := groupsMap[pd]
-			if !ok {
-				grp = &WalGroup{
-					period: pd,
-				}
-				groupsMap[pd] = grp
+	for _, id := range wals {
+		pd := period.PeriodFor(id.ts)
+		grp, ok := groupsMap[pd]
+		if !ok {
+			grp = &WalGroup{
+				period: pd,
 			}
-			grp.wals = append(grp.wals, id)
+			groupsMap[pd] = grp
 		}
+		grp.wals = append(grp.wals, id)
 	}
 
-	for _, grp := range groupsMap {
-		// Ensure the earliest wals are seen first
-		sort.Slice(grp.wals, func(i, j int) bool {
-			return grp.wals[i].ts.Before(grp.wals[j].ts)
-		})
-	}
 	return groupsMap, nil
 }
 
+// ListWALs returns the WALs in the wal dir of the TSDB directory dir, the earliest first.
+func ListWALs(dir string) ([]WALIdentifier, error) {
+	files, err := os.ReadDir(managerWalDir(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var wals []WALIdentifier
+	for _, f := range files {
+		if id, ok := parseWALPath(f.Name()); ok {
+			wals = append(wals, id)
+		}
+	}
+
+	sort.Slice(wals, func(i, j int) bool {
+		return wals[i].ts.Before(wals[j].ts)
+	})
+	return wals, nil
+}
+
 func walsForPeriod(dir string, period period, offset int) (WalGroup, bool, error) {
 	groupsMap, err := walGroups(dir, period)
 	if err != nil {
@@ -485,11 +675,53 @@ func walPath(parent string, t time.Time) string {
 // recoverHead recovers from all WALs belonging to some period
 // and inserts it into the active *tenantHeads
 func recoverHead(dir string, heads *tenantHeads, wals []WALIdentifier) error {
+	_, err := recoverWALs(context.Background(), dir, wals, false, func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error {
+		_ = heads.Append(user, ls, fp, chks)
+		return nil
+	})
+	return err
+}
+
+// corruptWAL describes a WAL which could only be partially recovered
+type corruptWAL struct {
+	id WALIdentifier
+	// number of undecodable or orphaned records which were skipped
+	skippedRecords int
+	err            error
+}
+
+// recoverWALs replays all chunk metas in the given WALs, in order, through fn.
+// When skipCorrupt is set, undecodable records are skipped and unreadable WALs
+// are recovered up to the point of corruption instead of failing the replay.
+// Such WALs are returned so callers can act on them, i.e. quarantining.
+func recoverWALs(
+	ctx context.Context,
+	dir string,
+	wals []WALIdentifier,
+	skipCorrupt bool,
+	fn func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error,
+) (corrupted []corruptWAL, err error) {
 	for _, id := range wals {
+		corrupt := corruptWAL{id: id}
+
+		// skip returns whether a corrupt record can be skipped, tracking it if so
+		skip := func(err error) bool {
+			if !skipCorrupt {
+				return false
+			}
+			corrupt.skippedRecords++
+			corrupt.err = err
+			return true
+		}
+
 		// use anonymous function for ease of cleanup
 		if err := func(id WALIdentifier) error {
 			reader, closer, err := wal.NewWalReader(walPath(dir, id.ts), -1)
 			if err != nil {
+				if skipCorrupt {
+					corrupt.err = err
+					return nil
+				}
 				return err
 			}
 			defer closer.Close()
@@ -504,8 +736,15 @@ func recoverHead(dir string, heads *tenantHeads, wals []WALIdentifier) error {
 			seriesMap := make(map[string]map[uint64]*labelsWithFp)
 
 			for reader.Next() {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
 				rec := &WALRecord{}
 				if err := decodeWALRecord(reader.Record(), rec); err != nil {
+					if skip(err) {
+						continue
+					}
 					return err
 				}
 
@@ -525,31 +764,60 @@ func recoverHead(dir string, heads *tenantHeads, wals []WALIdentifier) error {
 				if len(rec.Chks.Chks) > 0 {
 					tenant, ok := seriesMap[rec.UserID]
 					if !ok {
-						return errors.New("found tsdb chunk metas without user in WAL replay")
+						err := errors.New("found tsdb chunk metas without user in WAL replay")
+						if skip(err) {
+							continue
+						}
+						return err
 					}
 					x, ok := tenant[rec.Chks.Ref]
 					if !ok {
-						return errors.New("found tsdb chunk metas without series in WAL replay")
+						err := errors.New("found tsdb chunk metas without series in WAL replay")
+						if skip(err) {
+							continue
+						}
+						return err
+					}
+					if err := fn(rec.UserID, x.ls, x.fp, rec.Chks.Chks); err != nil {
+						return err
 					}
-					_ = heads.Append(rec.UserID, x.ls, x.fp, rec.Chks.Chks)
 				}
 			}
-			return reader.Err()
+
+			// The reader can't continue past a corrupt segment;
+			// keep whatever was recovered before it.
+			if err := reader.Err(); err != nil {
+				if skipCorrupt {
+					corrupt.err = err
+					return nil
+				}
+				return err
+			}
+			return nil
 
 		}(id); err != nil {
-			return errors.Wrap(
+			return corrupted, errors.Wrap(
 				err,
 				"error recovering from TSDB WAL",
 			)
 		}
+
+		if corrupt.err != nil {
+			corrupted = append(corrupted, corrupt)
+		}
 	}
-	return nil
+	return corrupted, nil
 }
 
 type WALIdentifier struct {
 	ts time.Time
 }
 
+// Timestamp returns the time the WAL was created at, which it is named after.
+func (id WALIdentifier) Timestamp() time.Time {
+	return id.ts
+}
+
 func parseWALPath(p string) (id WALIdentifier, ok bool) {
 	ts, err := strconv.Atoi(p)
 	if err != nil {
@@ -649,6 +917,47 @@ func (t *tenantHeads) getOrCreateTenantHead(userID string) *Head {
 	return head
 }
 
+// headSeries returns the number of series of the tenant and whether ls is one of them.
+func (t *tenantHeads) headSeries(userID string, ls labels.Labels) (uint64, bool) {
+	i := t.shardForTenant(userID)
+	t.locks[i].RLock()
+	head, ok := t.tenants[i][userID]
+	t.locks[i].RUnlock()
+	if !ok {
+		return 0, false
+	}
+	return head.numSeries.Load(), head.series.contains(ls)
+}
+
+// numSeries returns the number of series of all the tenants.
+func (t *tenantHeads) numSeries() uint64 {
+	var n uint64
+	for i, shard := range t.tenants {
+		t.locks[i].RLock()
+		for _, head := range shard {
+			n += head.numSeries.Load()
+		}
+		t.locks[i].RUnlock()
+	}
+	return n
+}
+
+// seriesStats returns the number of series of each tenant.
+func (t *tenantHeads) seriesStats() []TenantSeriesStats {
+	var stats []TenantSeriesStats
+	for i, shard := range t.tenants {
+		t.locks[i].RLock()
+		for user, head := range shard {
+			stats = append(stats, TenantSeriesStats{
+				Tenant:       user,
+				ActiveSeries: head.numSeries.Load(),
+			})
+		}
+		t.locks[i].RUnlock()
+	}
+	return stats
+}
+
 func (t *tenantHeads) shardForTenant(userID string) uint64 {
 	return xxhash.Sum64String(userID) & uint64(t.shards-1)
 }
@@ -725,6 +1034,14 @@ func (t *tenantHeads) Stats(ctx context.Context, userID string, from, through mo
 	return idx.Stats(ctx, userID, from, through, acc, shard, shouldIncludeChunk, matchers...)
 }
 
+func (t *tenantHeads) Volume(ctx context.Context, userID string, from, through model.Time, acc VolumeAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) error {
+	idx, ok := t.tenantIndex(userID, from, through)
+	if !ok {
+		return nil
+	}
+	return idx.Volume(ctx, userID, from, through, acc, shard, shouldIncludeChunk, targetLabels, aggregateBy, matchers...)
+}
+
 // helper only used in building TSDBs
 func (t *tenantHeads) forAll(fn func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error) error {
 	for i, shard := range t.tenants {
@@ -31,14 +31,20 @@ func newNoopTSDBManager(dir string) noopTSDBManager {
 	}
 }
 
-func (m noopTSDBManager) BuildFromHead(_ *tenantHeads) error {
+func (m noopTSDBManager) BuildFromHead(_ context.Context, _ *tenantHeads) error {
 	panic("BuildFromHead not implemented")
 }
 
-func (m noopTSDBManager) BuildFromWALs(_ time.Time, wals []WALIdentifier) error {
+func (m noopTSDBManager) BuildFromWALs(_ context.Context, _ time.Time, wals []WALIdentifier) error {
 	return recoverHead(m.dir, m.tenantHeads, wals)
 }
-func (m noopTSDBManager) Start() error { return nil }
+func (m noopTSDBManager) PlanFromWALs(_ context.Context, _ []WALIdentifier) ([]BuildPlan, error) {
+	return nil, nil
+}
+func (m noopTSDBManager) LocalTSDBs() ([]LocalTSDB, error)    { return nil, nil }
+func (m noopTSDBManager) Start() error                        { return nil }
+func (m noopTSDBManager) Stop(_ context.Context) error        { return nil }
+func (m noopTSDBManager) RemoveShippedWALs(_ time.Time) error { return nil }
 
 func chunkMetasToChunkRefs(user string, fp uint64, xs index.ChunkMetas) (res []ChunkRef) {
 	for _, x := range xs {
@@ -390,3 +396,185 @@ func BenchmarkTenantHeads(b *testing.B) {
 		})
 	}
 }
+
+// stopRecordingTSDBManager records the context its Stop got called with
+type stopRecordingTSDBManager struct {
+	noopTSDBManager
+	stopCtx context.Context
+}
+
+func (m *stopRecordingTSDBManager) Stop(ctx context.Context) error {
+	m.stopCtx = ctx
+	return nil
+}
+
+func Test_HeadManager_Stop(t *testing.T) {
+	dir := t.TempDir()
+	tsdbManager := &stopRecordingTSDBManager{noopTSDBManager: newNoopTSDBManager(dir)}
+	mgr := NewHeadManager(log.NewNopLogger(), dir, NewMetrics(nil), tsdbManager)
+	require.Nil(t, mgr.Start())
+
+	ls := mustParseLabels(`{foo="bar"}`)
+	chks := index.ChunkMetas{{MinTime: 1, MaxTime: 10, Checksum: 3}}
+	require.Nil(t, mgr.Append("tenant", ls, ls.Hash(), chks))
+	require.Nil(t, mgr.Stop())
+
+	// in-flight builds are given a deadline
+	_, ok := tsdbManager.stopCtx.Deadline()
+	require.True(t, ok)
+
+	// the unbuilt head is kept in its WAL and built on the next start
+	recovered := newNoopTSDBManager(dir)
+	restarted := NewHeadManager(log.NewNopLogger(), dir, NewMetrics(nil), recovered)
+	require.Nil(t, restarted.Start())
+	defer restarted.Stop()
+	refs, err := recovered.GetChunkRefs(context.Background(), "tenant", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.Nil(t, err)
+	require.Equal(t, chunkMetasToChunkRefs("tenant", ls.Hash(), chks), refs)
+}
+
+// blockingTSDBManager blocks head builds until their context is done
+type blockingTSDBManager struct {
+	noopTSDBManager
+	building chan struct{}
+}
+
+func (m blockingTSDBManager) BuildFromHead(ctx context.Context, _ *tenantHeads) error {
+	close(m.building)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func Test_HeadManager_StopCancelsBuilds(t *testing.T) {
+	dir := t.TempDir()
+	tsdbManager := blockingTSDBManager{noopTSDBManager: newNoopTSDBManager(dir), building: make(chan struct{})}
+	mgr := NewHeadManager(log.NewNopLogger(), dir, NewMetrics(nil), tsdbManager)
+	require.Nil(t, mgr.Start())
+
+	built := make(chan error)
+	go func() {
+		built <- mgr.buildTSDBFromHead(mgr.activeHeads)
+	}()
+	<-tsdbManager.building
+
+	require.Nil(t, mgr.Stop())
+	require.ErrorIs(t, <-built, context.Canceled)
+}
+
+func Test_HeadManager_HeadSeries(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewHeadManager(log.NewNopLogger(), dir, NewMetrics(nil), newNoopTSDBManager(dir))
+	require.Nil(t, mgr.Start())
+	defer mgr.Stop()
+
+	chks := index.ChunkMetas{{MinTime: 1, MaxTime: 10, Checksum: 3}}
+	for _, s := range []string{`{foo="a"}`, `{foo="b"}`} {
+		ls := mustParseLabels(s)
+		require.Nil(t, mgr.Append("tenant1", ls, ls.Hash(), chks))
+	}
+	ls := mustParseLabels(`{foo="c"}`)
+	require.Nil(t, mgr.Append("tenant2", ls, ls.Hash(), chks))
+
+	series, ok := mgr.HeadSeries("tenant1", mustParseLabels(`{foo="a"}`))
+	require.Equal(t, uint64(2), series)
+	require.True(t, ok)
+	series, ok = mgr.HeadSeries("tenant1", mustParseLabels(`{foo="c"}`))
+	require.Equal(t, uint64(2), series)
+	require.False(t, ok)
+	series, ok = mgr.HeadSeries("tenant3", mustParseLabels(`{foo="a"}`))
+	require.Equal(t, uint64(0), series)
+	require.False(t, ok)
+
+	require.Equal(t, []TenantSeriesStats{
+		{Tenant: "tenant1", ActiveSeries: 2},
+		{Tenant: "tenant2", ActiveSeries: 1},
+	}, mgr.SeriesStats())
+
+	// only the active heads are counted
+	require.Nil(t, mgr.Rotate(time.Now()))
+	require.Nil(t, mgr.Append("tenant1", ls, ls.Hash(), chks))
+	series, ok = mgr.HeadSeries("tenant1", mustParseLabels(`{foo="a"}`))
+	require.Equal(t, uint64(1), series)
+	require.False(t, ok)
+	require.Equal(t, []TenantSeriesStats{{Tenant: "tenant1", ActiveSeries: 1}}, mgr.SeriesStats())
+}
+
+func Test_HeadManager_Plan(t *testing.T) {
+	tsdbManager, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{}, nil)
+	mgr := NewHeadManager(log.NewNopLogger(), tsdbManager.dir, NewMetrics(nil), tsdbManager)
+	require.Nil(t, mgr.Start())
+	defer mgr.Stop()
+
+	chks := index.ChunkMetas{{MinTime: 1, MaxTime: 10, Checksum: 3}}
+	for _, s := range []string{`{foo="a"}`, `{foo="b"}`} {
+		ls := mustParseLabels(s)
+		require.Nil(t, mgr.Append("tenant1", ls, ls.Hash(), chks))
+	}
+	ls := mustParseLabels(`{foo="c"}`)
+	require.Nil(t, mgr.Append("tenant2", ls, ls.Hash(), chks))
+
+	// the active WAL is planned, without building anything
+	plan, err := mgr.Plan(context.Background())
+	require.Nil(t, err)
+	require.Len(t, plan, 1)
+	require.Equal(t, 1, plan[0].TSDBs)
+	require.Equal(t, 3, plan[0].Series)
+	require.Equal(t, 3, plan[0].Chunks)
+	require.Empty(t, plan[0].Tenant)
+	require.Empty(t, shipper.indices)
+}
+
+// buildingTSDBManager builds heads successfully without building anything
+type buildingTSDBManager struct {
+	noopTSDBManager
+}
+
+func (m buildingTSDBManager) BuildFromHead(_ context.Context, _ *tenantHeads) error {
+	return nil
+}
+
+func Test_HeadManager_EarlyRotation(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewHeadManager(log.NewNopLogger(), dir, NewMetrics(nil), buildingTSDBManager{newNoopTSDBManager(dir)})
+	require.Nil(t, mgr.Start())
+	defer mgr.Stop()
+	mgr.maxSeries = 2
+
+	// rotate into the next period, so that its start is known
+	start := mgr.period.TimeForPeriod(mgr.period.PeriodFor(time.Now()) + 1)
+	require.Nil(t, mgr.Rotate(start))
+
+	_, ok := mgr.rotationReason(start.Add(time.Minute))
+	require.False(t, ok)
+	chks := index.ChunkMetas{{MinTime: 1, MaxTime: 10, Checksum: 3}}
+	for _, s := range []string{`{foo="a"}`, `{foo="b"}`} {
+		ls := mustParseLabels(s)
+		require.Nil(t, mgr.Append("tenant1", ls, ls.Hash(), chks))
+	}
+	reason, ok := mgr.rotationReason(start.Add(time.Minute))
+	require.True(t, ok)
+	require.Equal(t, rotationReasonSeries, reason)
+
+	// the end of the period takes precedence
+	reason, ok = mgr.rotationReason(start.Add(time.Duration(mgr.period)))
+	require.True(t, ok)
+	require.Equal(t, rotationReasonPeriod, reason)
+
+	// building the rotated head only truncates its WAL, not the one of the active head in the same period
+	next := start.Add(time.Minute)
+	require.Nil(t, mgr.Rotate(next))
+	require.Nil(t, mgr.buildTSDBFromHead(mgr.prevHeads))
+	require.NoDirExists(t, walPath(dir, start))
+	require.DirExists(t, walPath(dir, next))
+
+	// the WAL size is checked once written to the disk
+	mgr.maxSeries = 0
+	mgr.maxWALSize = 1
+	for i := 0; i < 1000; i++ {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="%d"}`, i))
+		require.Nil(t, mgr.Append("tenant1", ls, ls.Hash(), chks))
+	}
+	reason, ok = mgr.rotationReason(next.Add(time.Minute))
+	require.True(t, ok)
+	require.Equal(t, rotationReasonWALSize, reason)
+}
@@ -2,6 +2,7 @@ package tsdb
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -9,6 +10,8 @@ import (
 	"time"
 
 	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 )
 
 const compactedFileUploader = "compactor"
@@ -133,13 +136,33 @@ func parseSingleTenantTSDBPath(p string) (id SingleTenantTSDBIdentifier, ok bool
 
 }
 
+// shardPrefix precedes the shard of a TSDB sharded by fingerprint in its name
+const shardPrefix = "shard_"
+
+// seqPrefix precedes the sequence number of a TSDB rebuilt for the same node, timestamp and shard in its name
+const seqPrefix = "seq_"
+
 type MultitenantTSDBIdentifier struct {
 	nodeName string
 	ts       time.Time
+	// only set when the TSDB holds a single fingerprint shard of the period's series
+	shard index.ShardAnnotation
+	// distinguishes the TSDBs built for the same rotation, i.e. when rebuilding it after a partial failure,
+	// so that they don't overwrite each other. The TSDBs of older versions have none.
+	seq int
 }
 
 func (id MultitenantTSDBIdentifier) Name() string {
-	return fmt.Sprintf("%d-%s.tsdb", id.ts.Unix(), id.nodeName)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d-%s", id.ts.Unix(), id.nodeName)
+	if id.shard.Of > 1 {
+		fmt.Fprintf(&b, "-%s%s", shardPrefix, id.shard)
+	}
+	if id.seq > 0 {
+		fmt.Fprintf(&b, "-%s%d", seqPrefix, id.seq)
+	}
+	b.WriteString(".tsdb")
+	return b.String()
 }
 
 func (id MultitenantTSDBIdentifier) Path() string {
@@ -171,8 +194,92 @@ func parseMultitenantTSDBNameFromBase(name string) (res MultitenantTSDBIdentifie
 		return
 	}
 
-	return MultitenantTSDBIdentifier{
-		ts:       time.Unix(int64(ts), 0),
-		nodeName: strings.Join(xs[1:], "-"),
-	}, true
+	res = MultitenantTSDBIdentifier{
+		ts: time.Unix(int64(ts), 0),
+	}
+	if seq, ok := parseTSDBSeq(xs[len(xs)-1]); ok && len(xs) > 2 {
+		res.seq = seq
+		xs = xs[:len(xs)-1]
+	}
+	if shard, ok := parseTSDBShard(xs[len(xs)-1]); ok && len(xs) > 2 {
+		res.shard = shard
+		xs = xs[:len(xs)-1]
+	}
+	res.nodeName = strings.Join(xs[1:], "-")
+	return res, true
+}
+
+// parseTSDBSeq parses the sequence number suffix of a rebuilt TSDB's name, i.e. seq_1.
+func parseTSDBSeq(s string) (seq int, ok bool) {
+	trimmed := strings.TrimPrefix(s, seqPrefix)
+	if trimmed == s {
+		return
+	}
+
+	seq, err := strconv.Atoi(trimmed)
+	if err != nil || seq < 1 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// nextTSDBSeq returns the sequence number of a TSDB with the node, timestamp and shard of id built into dir at now.
+// It's the time of the build, so that rebuilding a rotation never overwrites a TSDB which was shipped already,
+// even when it's no longer in dir, i.e. after it was uploaded directly or dropped from the disk. It's one past the
+// highest one already in dir when that is higher, in case the clock went backwards.
+func nextTSDBSeq(dir string, id MultitenantTSDBIdentifier, now time.Time) int {
+	seq := int(now.UnixNano())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return seq
+	}
+
+	for _, e := range entries {
+		other, ok := parseMultitenantTSDBPath(e.Name())
+		if !ok || other.nodeName != id.nodeName || other.ts.Unix() != id.ts.Unix() || other.shard != id.shard {
+			continue
+		}
+		if other.seq >= seq {
+			seq = other.seq + 1
+		}
+	}
+	return seq
+}
+
+// parseTSDBShard parses the shard suffix of a sharded TSDB's name, i.e. shard_1_of_16.
+func parseTSDBShard(s string) (shard index.ShardAnnotation, ok bool) {
+	trimmed := strings.TrimPrefix(s, shardPrefix)
+	if trimmed == s {
+		return
+	}
+
+	xs := strings.Split(trimmed, "_of_")
+	if len(xs) != 2 {
+		return
+	}
+	x, err := strconv.ParseUint(xs[0], 10, 32)
+	if err != nil {
+		return
+	}
+	of, err := strconv.ParseUint(xs[1], 10, 32)
+	if err != nil {
+		return
+	}
+
+	shard = index.NewShard(uint32(x), uint32(of))
+	if shard.Validate() != nil || shard.Shard >= shard.Of {
+		return index.ShardAnnotation{}, false
+	}
+	return shard, true
+}
+
+// shardsOverlap returns whether a TSDB holding the fingerprint shard tsdbShard may contain
+// series matching the query shard. Unsharded TSDBs and queries overlap with everything.
+func shardsOverlap(tsdbShard index.ShardAnnotation, shard *index.ShardAnnotation) bool {
+	if tsdbShard.Of < 2 || shard == nil || shard.Of < 2 {
+		return true
+	}
+	from, through := tsdbShard.Bounds()
+	shardFrom, shardThrough := shard.Bounds()
+	return from < shardThrough && shardFrom < through
 }
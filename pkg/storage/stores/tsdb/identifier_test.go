@@ -1,10 +1,14 @@
 package tsdb
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 )
 
 func TestParseSingleTenantTSDBPath(t *testing.T) {
@@ -50,3 +54,111 @@ func TestParseSingleTenantTSDBPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMultitenantTSDBPath(t *testing.T) {
+	for _, tc := range []struct {
+		desc  string
+		input string
+		id    MultitenantTSDBIdentifier
+		ok    bool
+	}{
+		{
+			desc:  "simple_works",
+			input: "1-node-a.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-a",
+			},
+			ok: true,
+		},
+		{
+			desc:  "sharded",
+			input: "1-node-a-shard_3_of_4.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-a",
+				shard:    index.NewShard(3, 4),
+			},
+			ok: true,
+		},
+		{
+			desc:  "invalid shard is part of the node name",
+			input: "1-node-shard_4_of_4.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-shard_4_of_4",
+			},
+			ok: true,
+		},
+		{
+			desc:  "node named like a shard",
+			input: "1-shard_0_of_2.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "shard_0_of_2",
+			},
+			ok: true,
+		},
+		{
+			desc:  "rebuilt",
+			input: "1-node-a-seq_2.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-a",
+				seq:      2,
+			},
+			ok: true,
+		},
+		{
+			desc:  "sharded and rebuilt",
+			input: "1-node-a-shard_3_of_4-seq_1.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-a",
+				shard:    index.NewShard(3, 4),
+				seq:      1,
+			},
+			ok: true,
+		},
+		{
+			desc:  "invalid sequence is part of the node name",
+			input: "1-node-seq_0.tsdb",
+			id: MultitenantTSDBIdentifier{
+				ts:       time.Unix(1, 0),
+				nodeName: "node-seq_0",
+			},
+			ok: true,
+		},
+		{
+			desc:  "wrong suffix",
+			input: "1-node.tsdb.sources",
+			ok:    false,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			id, ok := parseMultitenantTSDBPath(tc.input)
+			require.Equal(t, tc.ok, ok)
+			if !tc.ok {
+				return
+			}
+			require.Equal(t, tc.id, id)
+			require.Equal(t, tc.input, id.Name())
+		})
+	}
+}
+
+func TestNextTSDBSeq(t *testing.T) {
+	dir := t.TempDir()
+	id := MultitenantTSDBIdentifier{nodeName: "node", ts: time.Unix(1, 0)}
+	now := time.Unix(0, 5)
+	require.Equal(t, 5, nextTSDBSeq(dir, id, now))
+
+	for _, name := range []string{"1-node.tsdb", "1-node-seq_2.tsdb", "1-other-seq_5.tsdb", "2-node-seq_7.tsdb", "1-node-shard_0_of_2-seq_9.tsdb"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	require.Equal(t, 5, nextTSDBSeq(dir, id, now))
+
+	// the clock went backwards
+	id.shard = index.NewShard(0, 2)
+	require.Equal(t, 10, nextTSDBSeq(dir, id, now))
+}
@@ -53,6 +53,9 @@ type Index interface {
 	LabelNames(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error)
 	LabelValues(ctx context.Context, userID string, from, through model.Time, name string, matchers ...*labels.Matcher) ([]string, error)
 	Stats(ctx context.Context, userID string, from, through model.Time, acc IndexStatsAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, matchers ...*labels.Matcher) error
+	// Volume adds the bytes of the chunks of the matching series to acc, aggregated by series restricted to
+	// targetLabels or by label name, see seriesvolume.
+	Volume(ctx context.Context, userID string, from, through model.Time, acc VolumeAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) error
 }
 
 type NoopIndex struct{}
@@ -78,4 +81,8 @@ func (NoopIndex) Stats(ctx context.Context, userID string, from, through model.T
 	return nil
 }
 
+func (NoopIndex) Volume(ctx context.Context, userID string, from, through model.Time, acc VolumeAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) error {
+	return nil
+}
+
 func (NoopIndex) SetChunkFilterer(chunkFilter chunk.RequestChunkFilterer) {}
@@ -124,7 +124,7 @@ type Writer struct {
 
 	numSymbols  int
 	symbols     *Symbols
-	symbolFile  *fileutil.MmapFile
+	symbolFile  io.Closer
 	lastSymbol  string
 	symbolCache map[string]symbolCacheEntry
 
@@ -238,7 +238,25 @@ func NewWriter(ctx context.Context, fn string) (*Writer, error) {
 		return nil, errors.Wrap(err, "sync dir")
 	}
 
-	iw := &Writer{
+	iw := newWriter(ctx, f, fP, fPO)
+	if err := iw.writeMeta(); err != nil {
+		return nil, err
+	}
+	return iw, nil
+}
+
+// NewMemWriter returns a new Writer building the index in memory, without any file, which is returned by Bytes once
+// the Writer is closed. It serializes data in format version 2.
+func NewMemWriter(ctx context.Context) (*Writer, error) {
+	iw := newWriter(ctx, newMemFileWriter(IndexFilename), newMemFileWriter(IndexFilename+"_tmp_p"), newMemFileWriter(IndexFilename+"_tmp_po"))
+	if err := iw.writeMeta(); err != nil {
+		return nil, err
+	}
+	return iw, nil
+}
+
+func newWriter(ctx context.Context, f, fP, fPO *FileWriter) *Writer {
+	return &Writer{
 		ctx:   ctx,
 		f:     f,
 		fP:    fP,
@@ -253,10 +271,11 @@ func NewWriter(ctx context.Context, fn string) (*Writer, error) {
 		labelNames:  make(map[string]uint64, 1<<8),
 		crc32:       newCRC32(),
 	}
-	if err := iw.writeMeta(); err != nil {
-		return nil, err
-	}
-	return iw, nil
+}
+
+// Bytes returns the index built by a Writer created with NewMemWriter once it's closed, nil for the other Writers.
+func (w *Writer) Bytes() []byte {
+	return w.f.buf
 }
 
 func (w *Writer) write(bufs ...[]byte) error {
@@ -276,6 +295,8 @@ type FileWriter struct {
 	fbuf *bufio.Writer
 	pos  uint64
 	name string
+	// buf holds what was written to in-memory FileWriters, which have no file.
+	buf []byte
 }
 
 func NewFileWriter(name string) (*FileWriter, error) {
@@ -291,16 +312,29 @@ func NewFileWriter(name string) (*FileWriter, error) {
 	}, nil
 }
 
+func newMemFileWriter(name string) *FileWriter {
+	return &FileWriter{name: name}
+}
+
+func (fw *FileWriter) inMemory() bool {
+	return fw.f == nil
+}
+
 func (fw *FileWriter) Pos() uint64 {
 	return fw.pos
 }
 
 func (fw *FileWriter) Write(bufs ...[]byte) error {
 	for _, b := range bufs {
-		n, err := fw.fbuf.Write(b)
-		fw.pos += uint64(n)
-		if err != nil {
-			return err
+		if fw.inMemory() {
+			fw.buf = append(fw.buf, b...)
+			fw.pos += uint64(len(b))
+		} else {
+			n, err := fw.fbuf.Write(b)
+			fw.pos += uint64(n)
+			if err != nil {
+				return err
+			}
 		}
 		// For now the index file must not grow beyond 64GiB. Some of the fixed-sized
 		// offset references in v1 are only 4 bytes large.
@@ -314,10 +348,20 @@ func (fw *FileWriter) Write(bufs ...[]byte) error {
 }
 
 func (fw *FileWriter) Flush() error {
+	if fw.inMemory() {
+		return nil
+	}
 	return fw.fbuf.Flush()
 }
 
 func (fw *FileWriter) WriteAt(buf []byte, pos uint64) error {
+	if fw.inMemory() {
+		if pos+uint64(len(buf)) > uint64(len(fw.buf)) {
+			return errors.Errorf("%q writing %d bytes at %d beyond its size of %d", fw.name, len(buf), pos, len(fw.buf))
+		}
+		copy(fw.buf[pos:], buf)
+		return nil
+	}
 	if err := fw.Flush(); err != nil {
 		return err
 	}
@@ -339,7 +383,26 @@ func (fw *FileWriter) AddPadding(size int) error {
 	return nil
 }
 
+// mmap returns what was written so far, mapping the file into memory unless the FileWriter is in memory. The returned
+// io.Closer releases the mapping.
+func (fw *FileWriter) mmap() ([]byte, io.Closer, error) {
+	if fw.inMemory() {
+		return fw.buf, io.NopCloser(nil), nil
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, nil, err
+	}
+	f, err := fileutil.OpenMmapFile(fw.name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.Bytes(), f, nil
+}
+
 func (fw *FileWriter) Close() error {
+	if fw.inMemory() {
+		return nil
+	}
 	if err := fw.Flush(); err != nil {
 		return err
 	}
@@ -350,6 +413,10 @@ func (fw *FileWriter) Close() error {
 }
 
 func (fw *FileWriter) Remove() error {
+	if fw.inMemory() {
+		fw.buf = nil
+		return nil
+	}
 	return os.Remove(fw.name)
 }
 
@@ -599,12 +666,12 @@ func (w *Writer) finishSymbols() error {
 		return err
 	}
 
-	sf, err := fileutil.OpenMmapFile(w.f.name)
+	symbols, sf, err := w.f.mmap()
 	if err != nil {
 		return err
 	}
 	w.symbolFile = sf
-	hash := crc32.Checksum(w.symbolFile.Bytes()[w.toc.Symbols+4:hashPos], castagnoliTable)
+	hash := crc32.Checksum(symbols[w.toc.Symbols+4:hashPos], castagnoliTable)
 	w.buf1.Reset()
 	w.buf1.PutBE32(hash)
 	if err := w.writeAt(w.buf1.Get(), hashPos); err != nil {
@@ -612,7 +679,7 @@ func (w *Writer) finishSymbols() error {
 	}
 
 	// Load in the symbol table efficiently for the rest of the index writing.
-	w.symbols, err = NewSymbols(RealByteSlice(w.symbolFile.Bytes()), FormatV2, int(w.toc.Symbols))
+	w.symbols, err = NewSymbols(RealByteSlice(symbols), FormatV2, int(w.toc.Symbols))
 	if err != nil {
 		return errors.Wrap(err, "read symbols")
 	}
@@ -625,13 +692,13 @@ func (w *Writer) writeLabelIndices() error {
 	}
 
 	// Find all the label values in the tmp posting offset table.
-	f, err := fileutil.OpenMmapFile(w.fPO.name)
+	b, f, err := w.fPO.mmap()
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(f.Bytes()), int(w.fPO.pos)))
+	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(b), int(w.fPO.pos)))
 	cnt := w.cntPO
 	current := []byte{}
 	values := []uint32{}
@@ -792,7 +859,7 @@ func (w *Writer) writePostingsOffsetTable() error {
 		return err
 	}
 
-	f, err := fileutil.OpenMmapFile(w.fPO.name)
+	b, f, err := w.fPO.mmap()
 	if err != nil {
 		return err
 	}
@@ -801,7 +868,7 @@ func (w *Writer) writePostingsOffsetTable() error {
 			f.Close()
 		}
 	}()
-	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(f.Bytes()), int(w.fPO.pos)))
+	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(b), int(w.fPO.pos)))
 	cnt := w.cntPO
 	for d.Err() == nil && cnt > 0 {
 		w.buf1.Reset()
@@ -913,7 +980,7 @@ func (w *Writer) writePostingsToTmpFiles() error {
 	if err := w.f.Flush(); err != nil {
 		return err
 	}
-	f, err := fileutil.OpenMmapFile(w.f.name)
+	b, f, err := w.f.mmap()
 	if err != nil {
 		return err
 	}
@@ -921,7 +988,7 @@ func (w *Writer) writePostingsToTmpFiles() error {
 
 	// Write out the special all posting.
 	offsets := []uint32{}
-	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(f.Bytes()), int(w.toc.LabelIndices)))
+	d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(b), int(w.toc.LabelIndices)))
 	d.Skip(int(w.toc.Series))
 	for d.Len() > 0 {
 		d.ConsumePadding()
@@ -967,7 +1034,7 @@ func (w *Writer) writePostingsToTmpFiles() error {
 		// Label name -> label value -> positions.
 		postings := map[uint32]map[uint32][]uint32{}
 
-		d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(f.Bytes()), int(w.toc.LabelIndices)))
+		d := encoding.DecWrap(tsdb_enc.NewDecbufRaw(RealByteSlice(b), int(w.toc.LabelIndices)))
 		d.Skip(int(w.toc.Series))
 		for d.Len() > 0 {
 			d.ConsumePadding()
@@ -1074,21 +1141,27 @@ func (w *Writer) writePostings() error {
 	w.postingsStart = w.f.pos
 
 	// Copy temporary file into main index.
-	if err := w.fP.Flush(); err != nil {
-		return err
-	}
-	if _, err := w.fP.f.Seek(0, 0); err != nil {
-		return err
-	}
-	// Don't need to calculate a checksum, so can copy directly.
-	n, err := io.CopyBuffer(w.f.fbuf, w.fP.f, make([]byte, 1<<20))
-	if err != nil {
-		return err
-	}
-	if uint64(n) != w.fP.pos {
-		return errors.Errorf("wrote %d bytes to posting temporary file, but only read back %d", w.fP.pos, n)
+	if w.fP.inMemory() {
+		if err := w.f.Write(w.fP.buf); err != nil {
+			return err
+		}
+	} else {
+		if err := w.fP.Flush(); err != nil {
+			return err
+		}
+		if _, err := w.fP.f.Seek(0, 0); err != nil {
+			return err
+		}
+		// Don't need to calculate a checksum, so can copy directly.
+		n, err := io.CopyBuffer(w.f.fbuf, w.fP.f, make([]byte, 1<<20))
+		if err != nil {
+			return err
+		}
+		if uint64(n) != w.fP.pos {
+			return errors.Errorf("wrote %d bytes to posting temporary file, but only read back %d", w.fP.pos, n)
+		}
+		w.f.pos += uint64(n)
 	}
-	w.f.pos += uint64(n)
 
 	if err := w.fP.Close(); err != nil {
 		return err
@@ -464,6 +464,60 @@ func TestPersistence_index_e2e(t *testing.T) {
 	require.NoError(t, ir.Close())
 }
 
+func TestMemWriter(t *testing.T) {
+	lbls, err := labels.ReadLabels(filepath.Join("..", "testdata", "20kseries.json"), 2000)
+	require.NoError(t, err)
+	sort.Slice(lbls, func(i, j int) bool {
+		return lbls[i].Hash() < lbls[j].Hash()
+	})
+
+	symbols := map[string]struct{}{}
+	for _, lset := range lbls {
+		for _, l := range lset {
+			symbols[l.Name] = struct{}{}
+			symbols[l.Value] = struct{}{}
+		}
+	}
+	syms := make([]string, 0, len(symbols))
+	for s := range symbols {
+		syms = append(syms, s)
+	}
+	sort.Strings(syms)
+
+	write := func(iw *Writer) {
+		for _, s := range syms {
+			require.NoError(t, iw.AddSymbol(s))
+		}
+		for i, lset := range lbls {
+			chks := []ChunkMeta{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(i)}}
+			require.NoError(t, iw.AddSeries(storage.SeriesRef(i), lset, model.Fingerprint(lset.Hash()), chks...))
+		}
+		require.NoError(t, iw.Close())
+	}
+
+	fn := filepath.Join(t.TempDir(), IndexFilename)
+	fw, err := NewWriter(context.Background(), fn)
+	require.NoError(t, err)
+	write(fw)
+	require.Nil(t, fw.Bytes())
+
+	mw, err := NewMemWriter(context.Background())
+	require.NoError(t, err)
+	write(mw)
+
+	// the index built in memory is the same as the one written to a file.
+	expected, err := os.ReadFile(fn)
+	require.NoError(t, err)
+	require.Equal(t, expected, mw.Bytes())
+
+	ir, err := NewReader(RealByteSlice(mw.Bytes()))
+	require.NoError(t, err)
+	p, err := ir.Postings(lbls[0][0].Name, nil, lbls[0][0].Value)
+	require.NoError(t, err)
+	require.True(t, p.Next())
+	require.NoError(t, ir.Close())
+}
+
 func TestDecbufUvarintWithInvalidBuffer(t *testing.T) {
 	b := RealByteSlice([]byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x81})
 
@@ -2,7 +2,6 @@ package tsdb
 
 import (
 	"context"
-	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
@@ -12,16 +11,17 @@ import (
 	"github.com/grafana/loki/pkg/querier/astmapper"
 	"github.com/grafana/loki/pkg/storage/chunk"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	"github.com/grafana/loki/pkg/storage/stores/index/stats"
 	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
-	"github.com/grafana/loki/pkg/util"
 	"github.com/grafana/loki/pkg/util/spanlogger"
 )
 
 // implements stores.Index
 type IndexClient struct {
-	idx  Index
-	opts IndexClientOptions
+	idx         Index
+	opts        IndexClientOptions
+	tableRanges config.TableRanges
 }
 
 type IndexClientOptions struct {
@@ -45,10 +45,16 @@ type IndexStatsAccumulator interface {
 	Stats() stats.Stats
 }
 
-func NewIndexClient(idx Index, opts IndexClientOptions) *IndexClient {
+type VolumeAccumulator interface {
+	AddVolume(name string, size uint64)
+	Volumes() *logproto.VolumeResponse
+}
+
+func NewIndexClient(idx Index, opts IndexClientOptions, tableRanges config.TableRanges) *IndexClient {
 	return &IndexClient{
-		idx:  idx,
-		opts: opts,
+		idx:         idx,
+		opts:        opts,
+		tableRanges: tableRanges,
 	}
 }
 
@@ -174,7 +180,7 @@ func (c *IndexClient) Stats(ctx context.Context, userID string, from, through mo
 		return nil, err
 	}
 
-	// split the query range to align with table intervals i.e. ObjectStorageIndexRequiredPeriod
+	// split the query range to align with table intervals, i.e. the index table period of each period config
 	// This is to avoid explicitly deduping chunks by leveraging the table intervals.
 	// The idea is to make each split process chunks that have start time >= start time of the table interval.
 	// In other terms, table interval that contains start time of the chunk, owns it.
@@ -188,11 +194,8 @@ func (c *IndexClient) Stats(ctx context.Context, userID string, from, through mo
 	// The caveat here is that we will overestimate the data we will be processing if the index is not compacted yet
 	// since it could have duplicate chunks when RF > 1
 	var intervals []model.Interval
-	util.ForInterval(config.ObjectStorageIndexRequiredPeriod, from.Time(), through.Time(), true, func(start, end time.Time) {
-		intervals = append(intervals, model.Interval{
-			Start: model.TimeFromUnixNano(start.UnixNano()),
-			End:   model.TimeFromUnixNano(end.UnixNano()),
-		})
+	forIndexTables(from, through, c.tableRanges, func(_ string, start, end model.Time) {
+		intervals = append(intervals, model.Interval{Start: start, End: end})
 	})
 
 	var acc IndexStatsAccumulator
@@ -229,6 +232,33 @@ func (c *IndexClient) Stats(ctx context.Context, userID string, from, through mo
 	return &res, nil
 }
 
+// Volume returns the largest volumes of the data selected by matchers, aggregated by series restricted to
+// targetLabels or by label name. Like Stats, chunks are attributed to the index table containing their start.
+func (c *IndexClient) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	matchers, shard, err := cleanMatchers(matchers...)
+	if err != nil {
+		return nil, err
+	}
+
+	var intervals []model.Interval
+	forIndexTables(from, through, c.tableRanges, func(_ string, start, end model.Time) {
+		intervals = append(intervals, model.Interval{Start: start, End: end})
+	})
+
+	acc := seriesvolume.NewAccumulator(limit)
+	queryBounds := newBounds(from, through)
+
+	for idx, interval := range intervals {
+		if err := c.idx.Volume(ctx, userID, interval.Start, interval.End, acc, shard, func(chk index.ChunkMeta) bool {
+			return Overlap(queryBounds, chk) && (idx == 0 || chk.From() >= interval.Start)
+		}, targetLabels, aggregateBy, matchers...); err != nil {
+			return nil, err
+		}
+	}
+
+	return acc.Volumes(), nil
+}
+
 // SetChunkFilterer sets a chunk filter to be used when retrieving chunks.
 // This is only used for GetSeries implementation.
 // Todo we might want to pass it as a parameter to GetSeries instead.
@@ -10,8 +10,11 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/pkg/logproto"
 	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	index_shipper "github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 )
 
 type mockIndexShipperIndexIterator struct {
@@ -74,7 +77,7 @@ func BenchmarkIndexClient_Stats(b *testing.B) {
 		},
 	})
 
-	indexClient := NewIndexClient(idx, IndexClientOptions{UseBloomFilters: true})
+	indexClient := NewIndexClient(idx, IndexClientOptions{UseBloomFilters: true}, tableRanges)
 
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -144,7 +147,7 @@ func TestIndexClient_Stats(t *testing.T) {
 		},
 	})
 
-	indexClient := NewIndexClient(idx, IndexClientOptions{UseBloomFilters: true})
+	indexClient := NewIndexClient(idx, IndexClientOptions{UseBloomFilters: true}, tableRanges)
 
 	for _, tc := range []struct {
 		name               string
@@ -200,3 +203,104 @@ func TestIndexClient_Stats(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexClient_Volume(t *testing.T) {
+	tempDir := t.TempDir()
+	tableRanges := config.TableRanges{
+		{
+			Start: 0,
+			End:   math.MaxInt64,
+			PeriodConfig: &config.PeriodConfig{
+				IndexTables: config.PeriodicTableConfig{
+					Period: config.ObjectStorageIndexRequiredPeriod,
+				},
+			},
+		},
+	}
+
+	indexStartToday := model.TimeFromUnixNano(time.Now().Truncate(config.ObjectStorageIndexRequiredPeriod).UnixNano())
+	withKB := func(kb uint32) index.ChunkMetas {
+		chks := buildChunkMetas(int64(indexStartToday), int64(indexStartToday+9))
+		for i := range chks {
+			chks[i].KB = kb
+		}
+		return chks
+	}
+
+	tables := map[string][]*TSDBFile{
+		tableRanges[0].PeriodConfig.IndexTables.TableFor(indexStartToday): {
+			BuildIndex(t, tempDir, []LoadableSeries{
+				{
+					Labels: mustParseLabels(`{app="foo", env="prod"}`),
+					Chunks: withKB(1),
+				},
+				{
+					Labels: mustParseLabels(`{app="foo", env="dev"}`),
+					Chunks: withKB(2),
+				},
+				{
+					Labels: mustParseLabels(`{app="bar", env="prod"}`),
+					Chunks: withKB(4),
+				},
+			}),
+		},
+	}
+
+	idx := newIndexShipperQuerier(mockIndexShipperIndexIterator{tables: tables}, config.TableRanges{
+		{
+			Start:        0,
+			End:          math.MaxInt64,
+			PeriodConfig: &config.PeriodConfig{},
+		},
+	})
+
+	indexClient := NewIndexClient(idx, IndexClientOptions{UseBloomFilters: true}, tableRanges)
+	matcher := labels.MustNewMatcher(labels.MatchRegexp, "app", ".+")
+
+	for _, tc := range []struct {
+		name         string
+		limit        int32
+		targetLabels []string
+		aggregateBy  string
+		expected     []logproto.Volume
+	}{
+		{
+			name: "by matched labels",
+			expected: []logproto.Volume{
+				{Name: `{app="foo"}`, Volume: 30 << 10},
+				{Name: `{app="bar"}`, Volume: 40 << 10},
+			},
+		},
+		{
+			name:         "by target labels",
+			targetLabels: []string{"env"},
+			expected: []logproto.Volume{
+				{Name: `{env="prod"}`, Volume: 50 << 10},
+				{Name: `{env="dev"}`, Volume: 20 << 10},
+			},
+		},
+		{
+			name:        "by label names",
+			aggregateBy: seriesvolume.Labels,
+			expected: []logproto.Volume{
+				{Name: "app", Volume: 70 << 10},
+				{Name: "env", Volume: 70 << 10},
+			},
+		},
+		{
+			name:         "limited",
+			limit:        1,
+			targetLabels: []string{"app", "env"},
+			expected: []logproto.Volume{
+				{Name: `{app="bar", env="prod"}`, Volume: 40 << 10},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// start time is not inclusive
+			res, err := indexClient.Volume(context.Background(), "", indexStartToday-1, indexStartToday+1000, tc.limit, tc.targetLabels, tc.aggregateBy, matcher)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tc.expected, res.Volumes)
+		})
+	}
+}
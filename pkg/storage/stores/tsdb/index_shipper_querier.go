@@ -29,7 +29,9 @@ func newIndexShipperQuerier(shipper indexShipperIterator, tableRanges config.Tab
 	return &indexShipperQuerier{shipper: shipper, tableRanges: tableRanges}
 }
 
-func (i *indexShipperQuerier) indices(ctx context.Context, from, through model.Time, user string, doneChan <-chan struct{}) (Index, error) {
+// indices returns the indices for user in the given range, skipping the TSDBs
+// sharded by fingerprint which can't contain series of the query shard, if any.
+func (i *indexShipperQuerier) indices(ctx context.Context, from, through model.Time, user string, shard *index.ShardAnnotation, doneChan <-chan struct{}) (Index, error) {
 	var indices []Index
 
 	// Ensure we query both per tenant and multitenant TSDBs
@@ -40,6 +42,9 @@ func (i *indexShipperQuerier) indices(ctx context.Context, from, through model.T
 			if !ok {
 				return fmt.Errorf("unexpected shipper index type: %T", idx)
 			}
+			if id, ok := parseMultitenantTSDBPath(idx.Name()); ok && !shardsOverlap(id.shard, shard) {
+				return nil
+			}
 			if multitenant {
 				indices = append(indices, NewMultiTenantIndex(impl))
 			} else {
@@ -87,7 +92,7 @@ func (i *indexShipperQuerier) GetChunkRefs(ctx context.Context, userID string, f
 	doneChan := make(chan struct{})
 	defer close(doneChan)
 
-	idx, err := i.indices(ctx, from, through, userID, doneChan)
+	idx, err := i.indices(ctx, from, through, userID, shard, doneChan)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +103,7 @@ func (i *indexShipperQuerier) Series(ctx context.Context, userID string, from, t
 	doneChan := make(chan struct{})
 	defer close(doneChan)
 
-	idx, err := i.indices(ctx, from, through, userID, doneChan)
+	idx, err := i.indices(ctx, from, through, userID, shard, doneChan)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +114,7 @@ func (i *indexShipperQuerier) LabelNames(ctx context.Context, userID string, fro
 	doneChan := make(chan struct{})
 	defer close(doneChan)
 
-	idx, err := i.indices(ctx, from, through, userID, doneChan)
+	idx, err := i.indices(ctx, from, through, userID, nil, doneChan)
 	if err != nil {
 		return nil, err
 	}
@@ -120,18 +125,30 @@ func (i *indexShipperQuerier) LabelValues(ctx context.Context, userID string, fr
 	doneChan := make(chan struct{})
 	defer close(doneChan)
 
-	idx, err := i.indices(ctx, from, through, userID, doneChan)
+	idx, err := i.indices(ctx, from, through, userID, nil, doneChan)
 	if err != nil {
 		return nil, err
 	}
 	return idx.LabelValues(ctx, userID, from, through, name, matchers...)
 }
 
+func (i *indexShipperQuerier) Volume(ctx context.Context, userID string, from, through model.Time, acc VolumeAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) error {
+	doneChan := make(chan struct{})
+	defer close(doneChan)
+
+	idx, err := i.indices(ctx, from, through, userID, shard, doneChan)
+	if err != nil {
+		return err
+	}
+
+	return idx.Volume(ctx, userID, from, through, acc, shard, shouldIncludeChunk, targetLabels, aggregateBy, matchers...)
+}
+
 func (i *indexShipperQuerier) Stats(ctx context.Context, userID string, from, through model.Time, acc IndexStatsAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, matchers ...*labels.Matcher) error {
 	doneChan := make(chan struct{})
 	defer close(doneChan)
 
-	idx, err := i.indices(ctx, from, through, userID, doneChan)
+	idx, err := i.indices(ctx, from, through, userID, shard, doneChan)
 	if err != nil {
 		return err
 	}
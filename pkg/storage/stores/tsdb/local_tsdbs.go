@@ -0,0 +1,161 @@
+package tsdb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+// LocalTSDB describes a TSDB built by the manager which is still on local disk, waiting to be shipped or retained by
+// the shipper for the queries, or which was built in memory and uploaded directly and is retained for the queries.
+type LocalTSDB struct {
+	Table    string            `json:"table"`
+	Name     string            `json:"name"`
+	InMemory bool              `json:"in_memory,omitempty"`
+	From     model.Time        `json:"from"`
+	Through  model.Time        `json:"through"`
+	Tenants  []LocalTSDBTenant `json:"tenants"`
+	// set instead of the tenants when the TSDB can't be read
+	Error string `json:"error,omitempty"`
+}
+
+// LocalTSDBTenant is what a tenant contributed to a local TSDB.
+type LocalTSDBTenant struct {
+	Tenant string `json:"tenant"`
+	Series int    `json:"series"`
+	Chunks int    `json:"chunks"`
+}
+
+// LocalTSDBs returns the TSDBs on local disk and the ones built in memory which are retained for the queries along
+// with the tenants present in each of them, sorted by table and name. Both the multitenant and the per tenant TSDBs
+// are read entirely, so it's meant for debugging only.
+func (m *tsdbManager) LocalTSDBs() ([]LocalTSDB, error) {
+	res := m.inMemoryTSDBs()
+
+	// the multitenant dir is only created along with the first TSDB built on disk
+	multitenantDir := managerMultitenantDir(m.dir)
+	buckets, err := os.ReadDir(multitenantDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, b := range buckets {
+		if b.IsDir() && m.isIndexBucket(b.Name()) {
+			res = append(res, localTSDBsIn(filepath.Join(multitenantDir, b.Name()), b.Name(), "")...)
+		}
+	}
+
+	perTenantDir := managerPerTenantDir(m.dir)
+	tenants, err := os.ReadDir(perTenantDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, tenant := range tenants {
+		if !tenant.IsDir() {
+			continue
+		}
+		buckets, err := os.ReadDir(filepath.Join(perTenantDir, tenant.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range buckets {
+			if b.IsDir() && m.isIndexBucket(b.Name()) {
+				res = append(res, localTSDBsIn(filepath.Join(perTenantDir, tenant.Name(), b.Name()), b.Name(), tenant.Name())...)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Table != res[j].Table {
+			return res[i].Table < res[j].Table
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// inMemoryTSDBs returns the local TSDBs built in memory.
+func (m *tsdbManager) inMemoryTSDBs() []LocalTSDB {
+	m.local.RLock()
+	defer m.local.RUnlock()
+
+	var res []LocalTSDB
+	for _, l := range m.local.indices {
+		if l.inMemory == nil {
+			continue
+		}
+		local := LocalTSDB{Table: l.key.period, Name: l.name, InMemory: true}
+		if err := localTSDBStats(l.inMemory, l.key.tenant, &local); err != nil {
+			local.Error = err.Error()
+		}
+		res = append(res, local)
+	}
+	return res
+}
+
+// localTSDBsIn reads the TSDBs in dir, which belong to tenant unless they are multitenant.
+func localTSDBsIn(dir, table, tenant string) []LocalTSDB {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var res []LocalTSDB
+	for _, f := range files {
+		if _, ok := parseMultitenantTSDBPath(f.Name()); !ok {
+			continue
+		}
+		local := LocalTSDB{Table: table, Name: f.Name()}
+		if err := readLocalTSDB(filepath.Join(dir, f.Name()), tenant, &local); err != nil {
+			local.Error = err.Error()
+		}
+		res = append(res, local)
+	}
+	return res
+}
+
+func readLocalTSDB(path, tenant string, local *LocalTSDB) error {
+	idx, _, err := NewTSDBIndexFromFile(path)
+	if err != nil {
+		return err
+	}
+	defer idx.Close()
+	return localTSDBStats(idx, tenant, local)
+}
+
+// localTSDBStats sets the bounds of the TSDB and the series and chunks of the tenants present in it.
+func localTSDBStats(idx *TSDBIndex, tenant string, local *LocalTSDB) error {
+	local.From, local.Through = idx.Bounds()
+
+	byTenant := map[string]*LocalTSDBTenant{}
+	err := idx.forSeries(context.Background(), nil, func(ls labels.Labels, _ model.Fingerprint, chks []index.ChunkMeta) {
+		t := tenant
+		if t == "" {
+			t = ls.Get(TenantLabel)
+		}
+		stats, ok := byTenant[t]
+		if !ok {
+			stats = &LocalTSDBTenant{Tenant: t}
+			byTenant[t] = stats
+		}
+		stats.Series++
+		stats.Chunks += len(chks)
+	}, labels.MustNewMatcher(labels.MatchEqual, "", ""))
+	if err != nil {
+		return err
+	}
+
+	local.Tenants = make([]LocalTSDBTenant, 0, len(byTenant))
+	for _, stats := range byTenant {
+		local.Tenants = append(local.Tenants, *stats)
+	}
+	sort.Slice(local.Tenants, func(i, j int) bool {
+		return local.Tenants[i].Tenant < local.Tenants[j].Tenant
+	})
+	return nil
+}
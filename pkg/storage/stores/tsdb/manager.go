@@ -1,23 +1,31 @@
 package tsdb
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/multierror"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/pkg/storage/config"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
 	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 	util_log "github.com/grafana/loki/pkg/util/log"
 )
@@ -28,11 +36,37 @@ import (
 type TSDBManager interface {
 	Start() error
 	// Builds a new TSDB file from a set of WALs
-	BuildFromWALs(time.Time, []WALIdentifier) error
+	BuildFromWALs(context.Context, time.Time, []WALIdentifier) error
 	// Builds a new TSDB file from tenantHeads
-	BuildFromHead(*tenantHeads) error
+	BuildFromHead(context.Context, *tenantHeads) error
+	// Reports the TSDBs BuildFromWALs would build from a set of WALs, without building them
+	PlanFromWALs(context.Context, []WALIdentifier) ([]BuildPlan, error)
+	// Lists the TSDBs built which are still held locally
+	LocalTSDBs() ([]LocalTSDB, error)
+	// Stop rejects new builds and waits for in-flight ones to finish.
+	// If ctx is done first, in-flight builds are cancelled. Their WALs are left
+	// untouched so the builds are resumed on the next Start.
+	// The shipper is stopped afterwards, uploading the indices handed to it so far.
+	Stop(ctx context.Context) error
+	// Removes the WALs created before the given time whose TSDBs have all been shipped
+	RemoveShippedWALs(before time.Time) error
+
+	// Reads served from the TSDBs built by the manager which are still retained locally.
+	// These follow the same semantics as the corresponding Index methods.
+	GetChunkRefs(ctx context.Context, userID string, from, through model.Time, res []ChunkRef, shard *index.ShardAnnotation, matchers ...*labels.Matcher) ([]ChunkRef, error)
+	Series(ctx context.Context, userID string, from, through model.Time, res []Series, shard *index.ShardAnnotation, matchers ...*labels.Matcher) ([]Series, error)
+	LabelNames(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error)
 }
 
+var (
+	errManagerStopped        = errors.New("tsdb manager is stopped")
+	errInsufficientDiskSpace = errors.New("insufficient disk space for building tsdb")
+)
+
+// buildDiskSpaceFactor is how many times the size of the WALs a build may take up on disk:
+// the built (or partial) indices in the scratch dir, plus merging them into another one.
+const buildDiskSpaceFactor = 2
+
 /*
 tsdbManager is used for managing active index and is responsible for:
   - Turning WALs into optimized multi-tenant TSDBs when requested
@@ -47,10 +81,35 @@ type tsdbManager struct {
 	dir         string
 	metrics     *Metrics
 	tableRanges config.TableRanges
+	cfg         IndexCfg
+	// nil unless limits are given, in which case they're read at every build,
+	// so that changes to the tenants' overrides are honored from the next build on
+	limits    Limits
+	retention *retention.TenantsRetention
 
+	// ctx is cancelled to abort in-flight builds on Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// guards stopped; builds register with inflight while holding it
 	sync.RWMutex
+	stopped  bool
+	inflight sync.WaitGroup
 
 	shipper indexshipper.IndexShipper
+	// shipped TSDBs, opened for serving reads directly
+	local localIndices
+	// TSDBs built from heads which are held back from the shipper to be merged
+	pending pendingIndices
+	// WALs whose TSDBs have all been shipped
+	walManifest walManifest
+}
+
+// pendingIndices are TSDBs built from heads which haven't been handed to the shipper yet,
+// waiting for more head rotations to land in their bucket to be merged with, see compactPending.
+type pendingIndices struct {
+	sync.Mutex
+	indices map[buildKey][]builtIndex
 }
 
 func NewTSDBManager(
@@ -58,16 +117,38 @@ func NewTSDBManager(
 	dir string,
 	shipper indexshipper.IndexShipper,
 	tableRanges config.TableRanges,
+	cfg IndexCfg,
+	limits Limits,
 	logger log.Logger,
 	metrics *Metrics,
 ) TSDBManager {
+	if cfg.BuildConcurrency < 1 {
+		cfg.BuildConcurrency = 1
+	}
+	if cfg.CorruptIndexAction == "" {
+		cfg.CorruptIndexAction = CorruptIndexActionQuarantine
+	}
+
+	var tenantsRetention *retention.TenantsRetention
+	if limits != nil {
+		tenantsRetention = retention.NewTenantsRetention(limits)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &tsdbManager{
+		ctx:         ctx,
+		cancel:      cancel,
 		nodeName:    nodeName,
 		log:         log.With(logger, "component", "tsdb-manager"),
 		dir:         dir,
 		metrics:     metrics,
 		tableRanges: tableRanges,
+		cfg:         cfg,
+		limits:      limits,
+		retention:   tenantsRetention,
 		shipper:     shipper,
+		pending:     pendingIndices{indices: make(map[buildKey][]builtIndex)},
+		walManifest: walManifest{path: managerWALManifestPath(dir)},
 	}
 }
 
@@ -87,12 +168,6 @@ func (m *tsdbManager) Start() (err error) {
 		)
 	}()
 
-	// regexp for finding the trailing index bucket number at the end of table name
-	extractBucketNumberRegex, err := regexp.Compile(`[0-9]+$`)
-	if err != nil {
-		return err
-	}
-
 	// load list of multitenant tsdbs
 	mulitenantDir := managerMultitenantDir(m.dir)
 	files, err := os.ReadDir(mulitenantDir)
@@ -106,11 +181,10 @@ func (m *tsdbManager) Start() (err error) {
 		}
 
 		bucket := f.Name()
-		if !extractBucketNumberRegex.MatchString(f.Name()) {
+		if !m.isIndexBucket(bucket) {
 			level.Warn(m.log).Log(
-				"msg", "directory name does not match expected bucket name pattern",
+				"msg", "directory name does not match any index table of the period configs",
 				"name", bucket,
-				"err", err.Error(),
 			)
 			continue
 		}
@@ -125,6 +199,7 @@ func (m *tsdbManager) Start() (err error) {
 			)
 			continue
 		}
+		removeStaleIndexSources(filepath.Join(mulitenantDir, bucket), tsdbs)
 
 		for _, db := range tsdbs {
 			id, ok := parseMultitenantTSDBPath(db.Name())
@@ -134,113 +209,858 @@ func (m *tsdbManager) Start() (err error) {
 			indices++
 
 			prefixed := newPrefixedIdentifier(id, filepath.Join(mulitenantDir, bucket), "")
-			loaded, err := NewShippableTSDBFile(prefixed)
+			loaded, err := m.loadLeftoverIndex(bucket, "", id, prefixed)
+			if err != nil {
+				loadingErrors++
+				return err
+			}
+			if !loaded {
+				loadingErrors++
+			}
+		}
+
+	}
+
+	// load per tenant tsdbs, laid out as per_tenant/<tenant>/<bucket>/
+	perTenantDir := managerPerTenantDir(m.dir)
+	tenants, err := os.ReadDir(perTenantDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, tenant := range tenants {
+		if !tenant.IsDir() {
+			continue
+		}
+
+		bucketDirs, err := os.ReadDir(filepath.Join(perTenantDir, tenant.Name()))
+		if err != nil {
+			level.Warn(m.log).Log(
+				"msg", "failed to open tenant dir",
+				"tenant", tenant.Name(),
+				"err", err.Error(),
+			)
+			continue
+		}
 
+		for _, b := range bucketDirs {
+			bucket := b.Name()
+			if !b.IsDir() || !m.isIndexBucket(bucket) {
+				continue
+			}
+			buckets++
+
+			bucketDir := filepath.Join(perTenantDir, tenant.Name(), bucket)
+			tsdbs, err := os.ReadDir(bucketDir)
 			if err != nil {
 				level.Warn(m.log).Log(
-					"msg", "",
-					"tsdbPath", prefixed.Path(),
+					"msg", "failed to open period bucket dir",
+					"bucket", bucket,
+					"tenant", tenant.Name(),
 					"err", err.Error(),
 				)
-				loadingErrors++
+				continue
 			}
+			removeStaleIndexSources(bucketDir, tsdbs)
 
-			if err := m.shipper.AddIndex(bucket, "", loaded); err != nil {
-				loadingErrors++
-				return err
+			for _, db := range tsdbs {
+				id, ok := parseMultitenantTSDBPath(db.Name())
+				if !ok {
+					continue
+				}
+				indices++
+
+				prefixed := newPrefixedIdentifier(id, bucketDir, "")
+				loaded, err := m.loadLeftoverIndex(bucket, tenant.Name(), id, prefixed)
+				if err != nil {
+					loadingErrors++
+					return err
+				}
+				if !loaded {
+					loadingErrors++
+				}
 			}
 		}
-
 	}
 
 	return nil
 }
 
-func (m *tsdbManager) buildFromHead(heads *tenantHeads) (err error) {
-	periods := make(map[string]*Builder)
+// bucketNumberRegex finds the trailing index table number at the end of a table name
+var bucketNumberRegex = regexp.MustCompile(`[0-9]+$`)
 
-	if err := heads.forAll(func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error {
+// isIndexBucket returns whether name is an index table (bucket) of one of the period configs,
+// i.e. has their prefix and a table number falling into their range for their index table period.
+func (m *tsdbManager) isIndexBucket(name string) bool {
+	n, err := strconv.ParseInt(bucketNumberRegex.FindString(name), 10, 64)
+	if err != nil {
+		return false
+	}
+	return m.tableRanges.TableInRange(n, name)
+}
+
+// loadLeftoverIndex verifies a leftover local index before handing it to the shipper.
+// Corrupt indices, i.e. truncated by a crash, are handled according to the configured
+// CorruptIndexAction instead of being shipped. It returns whether the index was loaded;
+// errors are only returned when the shipper fails to accept a verified index.
+func (m *tsdbManager) loadLeftoverIndex(bucket, tenant string, id MultitenantTSDBIdentifier, prefixed prefixedIdentifier) (bool, error) {
+	loaded, err := NewShippableTSDBFile(prefixed)
+	if err == nil {
+		if err = verifyIndex(loaded.Index.(*TSDBIndex)); err != nil {
+			_ = loaded.Close()
+		}
+	}
 
-		// chunks may overlap index period bounds, in which case they're written to multiple
-		pds := make(map[string]index.ChunkMetas)
-		for _, chk := range chks {
-			idxBuckets := indexBuckets(chk.From(), chk.Through(), m.tableRanges)
+	if err != nil {
+		level.Warn(m.log).Log(
+			"msg", "found corrupt leftover local index",
+			"tsdbPath", prefixed.Path(),
+			"action", m.cfg.CorruptIndexAction,
+			"err", err.Error(),
+		)
+		if err := m.handleCorruptIndex(id, prefixed); err != nil {
+			m.metrics.corruptIndices.WithLabelValues(m.cfg.CorruptIndexAction, statusFailure).Inc()
+			level.Error(m.log).Log("msg", "failed handling corrupt leftover local index", "tsdbPath", prefixed.Path(), "err", err)
+		} else {
+			m.metrics.corruptIndices.WithLabelValues(m.cfg.CorruptIndexAction, statusSuccess).Inc()
+		}
+		return false, nil
+	}
+
+	if err := m.shipper.AddIndex(bucket, tenant, loaded); err != nil {
+		return true, err
+	}
+	m.addLocalIndex(buildKey{period: bucket, tenant: tenant, shard: id.shard}, id.ts, prefixed, nil)
+	return true, nil
+}
+
+// verifyIndex ensures every series in the index can be decoded.
+// The magic bytes and TOC checksum are already verified when opening the index.
+func verifyIndex(idx *TSDBIndex) error {
+	return idx.forSeries(context.Background(), nil, func(_ labels.Labels, _ model.Fingerprint, _ []index.ChunkMeta) {}, labels.MustNewMatcher(labels.MatchEqual, "", ""))
+}
+
+func (m *tsdbManager) handleCorruptIndex(id MultitenantTSDBIdentifier, prefixed prefixedIdentifier) error {
+	switch m.cfg.CorruptIndexAction {
+	case CorruptIndexActionDelete:
+		return removeIndex(prefixed.Path())
+	case CorruptIndexActionRebuild:
+		// The WAL an index was built from shares its timestamp, unless the index was merged
+		// from several ones, which are recorded next to it. If none of them have been truncated
+		// yet, removing the index is enough for it to be rebuilt during WAL replay.
+		sources, err := indexSources(prefixed.Path(), id.ts)
+		if err == nil && m.walsExist(sources) {
+			return removeIndex(prefixed.Path())
+		}
+		level.Warn(m.log).Log("msg", "wals for corrupt index no longer exist, quarantining instead", "tsdbPath", prefixed.Path(), "err", err)
+	}
+
+	if err := removeIndexSources(prefixed.Path()); err != nil {
+		return err
+	}
+
+	// quarantine, preserving the layout relative to the manager dir
+	rel, err := filepath.Rel(m.dir, prefixed.Path())
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(managerQuarantineDir(m.dir), rel)
+	if err := chunk_util.EnsureDirectory(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	return os.Rename(prefixed.Path(), dst)
+}
+
+func (m *tsdbManager) walsExist(ts []time.Time) bool {
+	for _, t := range ts {
+		if _, err := os.Stat(walPath(m.dir, t)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// tenantBuildStats tracks what a single tenant contributed to a period's TSDB
+type tenantBuildStats struct {
+	series, chunks int
+}
+
+// indexBuilder is implemented by both the Builder and StreamingBuilder
+type indexBuilder interface {
+	AddSeries(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta)
+	Build(ctx context.Context, scratchDir string, createFn func(from, through model.Time, checksum uint32) Identifier) (Identifier, error)
+}
+
+// buildKey identifies the TSDB a series is written to.
+// tenant is empty for multitenant TSDBs and shard is empty unless sharding the output.
+type buildKey struct {
+	period, tenant string
+	shard          index.ShardAnnotation
+}
+
+func (k buildKey) String() string {
+	s := k.period
+	if k.tenant != "" {
+		s = fmt.Sprintf("%s/%s", s, k.tenant)
+	}
+	if k.shard.Of > 1 {
+		s = fmt.Sprintf("%s/%s", s, k.shard)
+	}
+	return s
+}
+
+// periodBuilders splits series across the index period buckets their chunks belong to,
+// accumulating them in one indexBuilder per period, or per (period, tenant)
+// when building per tenant TSDBs. When sharding the output, each of these is further
+// split by fingerprint into shards. Whether to build per tenant, the number of shards and
+// whether to build retention aware are decided per tenant, see tenantSettings.
+type periodBuilders struct {
+	tableRanges config.TableRanges
+	cfg         IndexCfg
+	// nil unless the tenants' overrides are available
+	limits     Limits
+	newBuilder func() indexBuilder
+	// nil unless the tenants' overrides are available
+	retention *retentionFilter
+	// tenant -> settings, resolved once per build
+	tenants map[string]tenantBuildSettings
+
+	builders map[buildKey]indexBuilder
+	// nil unless planning, see PlanFromWALs
+	touched map[buildKey]struct{}
+	// tsdb -> tenant -> stats
+	stats map[buildKey]map[string]*tenantBuildStats
+}
+
+// newPeriodBuilders returns the builders of a build, interning the labels of their series into symbols if not nil.
+func (m *tsdbManager) newPeriodBuilders(ctx context.Context, symbols *symbolPool) *periodBuilders {
+	newBuilder := func() indexBuilder { return newBuilder(symbols) }
+	if m.cfg.StreamingBatchSize > 0 {
+		newBuilder = func() indexBuilder {
+			return newStreamingBuilder(ctx, managerScratchDir(m.dir), m.cfg.StreamingBatchSize, m.log, symbols)
+		}
+	}
+
+	var filter *retentionFilter
+	if m.retention != nil {
+		filter = &retentionFilter{
+			retention: m.retention,
+			now:       model.Now(),
+			pruned:    m.metrics.tsdbBuildPrunedChunks,
+		}
+	}
+
+	return &periodBuilders{
+		tableRanges: m.tableRanges,
+		cfg:         m.cfg,
+		limits:      m.limits,
+		newBuilder:  newBuilder,
+		retention:   filter,
+		tenants:     make(map[string]tenantBuildSettings),
+		builders:    make(map[buildKey]indexBuilder),
+		stats:       make(map[buildKey]map[string]*tenantBuildStats),
+	}
+}
+
+// retentionFilter drops chunks which are already past their retention period.
+type retentionFilter struct {
+	retention *retention.TenantsRetention
+	now       model.Time
+	// nil when planning
+	pruned *prometheus.CounterVec
+}
+
+// filter returns the chunks which haven't expired yet, using the same rules as
+// the compactor's retention. The passed chunks are never modified.
+func (f *retentionFilter) filter(user string, ls labels.Labels, chks index.ChunkMetas) index.ChunkMetas {
+	period := f.retention.RetentionPeriodFor(user, ls)
+	if period <= 0 {
+		return chks
+	}
 
-			for _, bucket := range idxBuckets {
-				pds[bucket] = append(pds[bucket], chk)
+	var res index.ChunkMetas
+	for i, chk := range chks {
+		if f.now.Sub(chk.Through()) <= period {
+			if res != nil {
+				res = append(res, chk)
 			}
+			continue
+		}
+
+		// first expired chunk, copy the ones we've kept so far
+		if res == nil {
+			res = make(index.ChunkMetas, i, len(chks))
+			copy(res, chks[:i])
 		}
+	}
+
+	if res == nil {
+		return chks
+	}
+	if f.pruned != nil {
+		f.pruned.WithLabelValues(user).Add(float64(len(chks) - len(res)))
+	}
+	return res
+}
+
+// tenantBuildSettings are the build time decisions taken for the series of a tenant.
+type tenantBuildSettings struct {
+	perTenant bool
+	shards    uint32
+	retention bool
+}
 
-		// Embed the tenant label into TSDB
+// tenantSettings returns the build settings of user: the ones of the config, overridden by the
+// tenant's limits. They're read the first time the tenant is seen in a build and kept for the rest
+// of it, so that all the series of a tenant built together end up in the same TSDBs.
+func (p *periodBuilders) tenantSettings(user string) tenantBuildSettings {
+	if s, ok := p.tenants[user]; ok {
+		return s
+	}
+
+	s := tenantBuildSettings{
+		perTenant: p.cfg.PerTenantOutput,
+		shards:    uint32(p.cfg.OutputShards),
+		retention: p.cfg.RetentionAwareBuild,
+	}
+	if p.limits != nil {
+		s.perTenant = s.perTenant || p.limits.TSDBPerTenantOutput(user)
+		if shards := p.limits.TSDBOutputShards(user); shards > 0 {
+			s.shards = uint32(shards)
+		}
+		s.retention = s.retention || p.limits.TSDBRetentionAwareBuild(user)
+	}
+	p.tenants[user] = s
+	return s
+}
+
+func (p *periodBuilders) add(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error {
+	settings := p.tenantSettings(user)
+	if p.retention != nil && settings.retention {
+		if chks = p.retention.filter(user, ls, chks); len(chks) == 0 {
+			return nil
+		}
+	}
+
+	// chunks may overlap index period bounds, in which case they're written to multiple
+	pds := make(map[string]index.ChunkMetas)
+	for _, chk := range chks {
+		idxBuckets := indexBuckets(chk.From(), chk.Through(), p.tableRanges)
+
+		for _, bucket := range idxBuckets {
+			pds[bucket] = append(pds[bucket], chk)
+		}
+	}
+
+	// Per tenant TSDBs don't need the tenant label,
+	// otherwise embed it into the multitenant TSDB
+	tenant := user
+	if !settings.perTenant {
 		lb := labels.NewBuilder(ls)
 		lb.Set(TenantLabel, user)
-		withTenant := lb.Labels(nil)
+		ls = lb.Labels(nil)
+		tenant = ""
+	}
 
-		// Add the chunks to all relevant builders
-		for pd, matchingChks := range pds {
-			b, ok := periods[pd]
-			if !ok {
-				b = NewBuilder()
-				periods[pd] = b
-			}
+	var shard index.ShardAnnotation
+	if settings.shards > 1 {
+		shard = fingerprintShard(model.Fingerprint(fp), settings.shards)
+	}
 
-			b.AddSeries(
-				withTenant,
-				// use the fingerprint without the added tenant label
-				// so queries route to the chunks which actually exist.
-				model.Fingerprint(fp),
-				matchingChks,
-			)
+	// Add the chunks to all relevant builders
+	for pd, matchingChks := range pds {
+		key := buildKey{period: pd, tenant: tenant, shard: shard}
+		b, ok := p.builders[key]
+		if !ok {
+			b = p.newBuilder()
+			p.builders[key] = b
+		}
+		if p.touched != nil {
+			p.touched[key] = struct{}{}
 		}
 
-		return nil
-	}); err != nil {
+		b.AddSeries(
+			ls,
+			// use the fingerprint without the added tenant label
+			// so queries route to the chunks which actually exist.
+			model.Fingerprint(fp),
+			matchingChks,
+		)
+
+		tenants, ok := p.stats[key]
+		if !ok {
+			tenants = make(map[string]*tenantBuildStats)
+			p.stats[key] = tenants
+		}
+		st, ok := tenants[user]
+		if !ok {
+			st = &tenantBuildStats{}
+			tenants[user] = st
+		}
+		st.series++
+		st.chunks += len(matchingChks)
+	}
+
+	return nil
+}
+
+// fingerprintShard returns the shard out of shards, which must be a power of 2, fp belongs to.
+func fingerprintShard(fp model.Fingerprint, shards uint32) index.ShardAnnotation {
+	shard := index.NewShard(0, shards)
+	shard.Shard = uint32(uint64(fp) >> (64 - shard.RequiredBits()))
+	return shard
+}
+
+func (m *tsdbManager) buildFromHead(ctx context.Context, heads *tenantHeads, symbols *symbolPool) ([]builtIndex, error) {
+	periods := m.newPeriodBuilders(ctx, symbols)
+	defer m.cleanupPeriodBuilders(periods)
+
+	if err := heads.forAll(periods.add); err != nil {
 		level.Error(m.log).Log("err", err.Error(), "msg", "building TSDB")
-		return err
+		return nil, err
 	}
 
-	for p, b := range periods {
-		dstDir := filepath.Join(managerMultitenantDir(m.dir), fmt.Sprint(p))
-		dst := newPrefixedIdentifier(
-			MultitenantTSDBIdentifier{
-				nodeName: m.nodeName,
-				ts:       heads.start,
-			},
-			dstDir,
-			"",
-		)
+	return m.buildPeriods(ctx, heads.start, periods)
+}
 
-		level.Debug(m.log).Log("msg", "building tsdb for period", "pd", p, "dst", dst.Path())
+// builtIndex is a TSDB which has been built locally but not yet handed to the shipper
+type builtIndex struct {
+	key buildKey
+	ts  time.Time
+	id  prefixedIdentifier
+	// timestamps of the WALs a merged TSDB was built from, nil unless merged
+	sources []time.Time
+	// the TSDB built in memory with tsdb_direct_upload, nil if it's on disk
+	data []byte
+}
+
+// buildPeriods builds the TSDBs for all periods, returning the ones which were
+// built successfully even if others failed.
+func (m *tsdbManager) buildPeriods(ctx context.Context, ts time.Time, periods *periodBuilders) ([]builtIndex, error) {
+	keys := make([]buildKey, 0, len(periods.builders))
+	for k := range periods.builders {
+		keys = append(keys, k)
+	}
+
+	// Build each period's TSDB concurrently. Jobs never return errors to ForEachJob
+	// so that a failing period doesn't cancel the others; instead all errors are aggregated.
+	// ForEachJob itself only errors when ctx is cancelled.
+	var (
+		mtx   sync.Mutex
+		merr  multierror.MultiError
+		built = make([]builtIndex, 0, len(keys))
+	)
+	err := concurrency.ForEachJob(ctx, len(keys), m.cfg.BuildConcurrency, func(ctx context.Context, idx int) error {
+		k := keys[idx]
+		id, data, err := m.buildPeriod(ctx, ts, k, periods.builders[k], periods.stats[k])
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		if err != nil {
+			merr.Add(errors.Wrapf(err, "building tsdb for %s", k))
+			return nil
+		}
+		built = append(built, builtIndex{key: k, ts: ts, id: id, data: data})
+		return nil
+	})
+	if err != nil {
+		return built, err
+	}
+
+	return built, merr.Err()
+}
+
+// periodIdentifier returns where the TSDB for k built at ts is stored: the multitenant
+// dir for multitenant TSDBs, otherwise the tenant's dir under the per tenant dir.
+// TSDBs already built for k at ts are never overwritten, the new one gets a higher sequence number.
+func (m *tsdbManager) periodIdentifier(k buildKey, ts time.Time) prefixedIdentifier {
+	dstDir := filepath.Join(managerMultitenantDir(m.dir), k.period)
+	if k.tenant != "" {
+		dstDir = filepath.Join(managerPerTenantDir(m.dir), k.tenant, k.period)
+	}
+	id := MultitenantTSDBIdentifier{
+		nodeName: m.nodeName,
+		ts:       ts,
+		shard:    k.shard,
+	}
+	id.seq = nextTSDBSeq(dstDir, id, time.Now())
+	return newPrefixedIdentifier(id, dstDir, "")
+}
+
+// buildPeriod builds the TSDB for a single period bucket and moves it into the multitenant
+// (or per tenant) dir. It's handed off to the shipper separately via shipIndices.
+// With tsdb_direct_upload, the TSDB is built in memory instead and returned to be uploaded.
+func (m *tsdbManager) buildPeriod(ctx context.Context, ts time.Time, k buildKey, b indexBuilder, stats map[string]*tenantBuildStats) (prefixedIdentifier, []byte, error) {
+	p := k.period
+	dst := m.periodIdentifier(k, ts)
+
+	level.Debug(m.log).Log("msg", "building tsdb for period", "pd", p, "dst", dst.Path())
+	start := time.Now()
+	var (
+		data []byte
+		err  error
+	)
+	if builder, ok := b.(*Builder); ok && m.cfg.DirectUpload {
+		data, err = builder.buildInMemory(ctx)
+	} else {
 		// build+move tsdb to multitenant dir
-		start := time.Now()
 		_, err = b.Build(
-			context.Background(),
+			ctx,
 			managerScratchDir(m.dir),
 			func(from, through model.Time, checksum uint32) Identifier {
 				return dst
 			},
 		)
+	}
+	if err != nil {
+		return dst, nil, err
+	}
+
+	elapsed := time.Since(start)
+	level.Debug(m.log).Log("msg", "finished building tsdb for period", "pd", p, "dst", dst.Path(), "duration", elapsed)
+	m.metrics.tsdbBuildDuration.Observe(elapsed.Seconds())
+
+	size := int64(len(data))
+	if data == nil {
+		if fi, err := os.Stat(dst.Path()); err == nil {
+			size = fi.Size()
+		}
+	}
+	m.observeTenantBuildStats(stats, size, elapsed)
+
+	return dst, data, nil
+}
+
+// finishBuild ships whatever was built, even if other periods failed with buildErr,
+// and records the build's success.
+func (m *tsdbManager) finishBuild(ctx context.Context, built []builtIndex, buildErr error) error {
+	shipErr := m.shipIndices(ctx, built)
+	switch {
+	case buildErr != nil && shipErr != nil:
+		return multierror.New(buildErr, shipErr).Err()
+	case buildErr != nil:
+		return buildErr
+	case shipErr != nil:
+		return shipErr
+	}
+
+	m.recordShippedWALs(built)
+	m.metrics.tsdbBuildLastSuccess.SetToCurrentTime()
+	return nil
+}
+
+// shipIndices hands the built TSDBs off to the shipper.
+func (m *tsdbManager) shipIndices(ctx context.Context, built []builtIndex) error {
+	var merr multierror.MultiError
+	for _, b := range built {
+		if b.data != nil {
+			if err := m.uploadIndex(ctx, b); err != nil {
+				merr.Add(err)
+			}
+			continue
+		}
+
+		loaded, err := NewShippableTSDBFile(b.id)
 		if err != nil {
+			merr.Add(errors.Wrapf(err, "loading tsdb for %s", b.key))
+			continue
+		}
+
+		// don't hand the index off to the shipper if we've been cancelled in the meantime
+		if err := ctx.Err(); err != nil {
+			_ = loaded.Close()
 			return err
 		}
 
-		level.Debug(m.log).Log("msg", "finished building tsdb for period", "pd", p, "dst", dst.Path(), "duration", time.Since(start))
+		if err := m.shipper.AddIndex(b.key.period, b.key.tenant, loaded); err != nil {
+			merr.Add(errors.Wrapf(err, "shipping tsdb for %s", b.key))
+			continue
+		}
+		m.addLocalIndex(b.key, b.ts, b.id, b.sources)
+	}
+
+	return merr.Err()
+}
+
+// uploadIndex uploads a TSDB built in memory right away. If the upload fails, the TSDB is written to the disk and
+// shipped like the ones built there, to be uploaded with the next upload or as a leftover on the next Start.
+func (m *tsdbManager) uploadIndex(ctx context.Context, b builtIndex) error {
+	loaded, err := NewInMemoryTSDBFile(b.id, b.data)
+	if err != nil {
+		return errors.Wrapf(err, "loading tsdb for %s", b.key)
+	}
+
+	if err := m.shipper.UploadIndex(ctx, b.key.period, b.key.tenant, loaded); err != nil {
+		m.metrics.directUploads.WithLabelValues(statusFailure).Inc()
+		level.Warn(m.log).Log("msg", "failed uploading tsdb, writing it to the disk to ship it later", "pd", b.key, "err", err)
+		_ = loaded.Close()
+
+		if err := writeBuiltIndex(managerScratchDir(m.dir), b); err != nil {
+			return errors.Wrapf(err, "writing tsdb for %s", b.key)
+		}
+		b.data = nil
+		return m.shipIndices(ctx, []builtIndex{b})
+	}
+
+	m.metrics.directUploads.WithLabelValues(statusSuccess).Inc()
+	m.addLocalInMemoryIndex(b.key, b.ts, b.id, b.data)
+	return nil
+}
+
+// writeBuiltIndex writes a TSDB built in memory to the scratch dir before moving it to its path.
+func writeBuiltIndex(scratchDir string, b builtIndex) error {
+	if err := chunk_util.EnsureDirectory(scratchDir); err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(scratchDir, fmt.Sprintf("%s-%x.staging", index.IndexFilename, rand.Int63()))
+	if err := os.WriteFile(tmpPath, b.data, 0o644); err != nil {
+		return err
+	}
+
+	if _, err := moveToIdentifier(tmpPath, func(_, _ model.Time, _ uint32) Identifier {
+		return b.id
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// compactIndices merges the TSDBs built for the same period (and tenant) into a single
+// TSDB when there are at least CompactionMinMerge of them, which happens when replaying
+// multiple WALs or after multiple head rotations, see compactPending. This ships fewer,
+// larger TSDBs which queries need to open.
+// Groups which fail to merge are returned as is, to be shipped uncompacted.
+func (m *tsdbManager) compactIndices(ctx context.Context, built []builtIndex) []builtIndex {
+	if m.cfg.CompactionMinMerge < 2 || len(built) < m.cfg.CompactionMinMerge {
+		return built
+	}
+
+	var (
+		keys   []buildKey
+		groups = make(map[buildKey][]builtIndex)
+	)
+	for _, b := range built {
+		if _, ok := groups[b.key]; !ok {
+			keys = append(keys, b.key)
+		}
+		groups[b.key] = append(groups[b.key], b)
+	}
+
+	res := make([]builtIndex, 0, len(keys))
+	for _, k := range keys {
+		group := groups[k]
+		if len(group) < m.cfg.CompactionMinMerge {
+			res = append(res, group...)
+			continue
+		}
 
-		loaded, err := NewShippableTSDBFile(dst)
+		merged, err := m.mergeBuilt(ctx, k, group)
 		if err != nil {
-			return err
+			m.metrics.preShipCompactions.WithLabelValues(statusFailure).Inc()
+			level.Warn(m.log).Log("msg", "failed merging tsdbs before shipping, shipping them uncompacted", "pd", k, "n", len(group), "err", err)
+			res = append(res, group...)
+			continue
 		}
 
-		if err := m.shipper.AddIndex(p, "", loaded); err != nil {
-			return err
+		m.metrics.preShipCompactions.WithLabelValues(statusSuccess).Inc()
+		res = append(res, merged)
+	}
+
+	return res
+}
+
+// compactPending holds back the TSDBs built from heads, which only cover a single rotation
+// each, until at least CompactionMinMerge of them are pending for the same period, returning
+// them merged to be shipped. Meanwhile the pending TSDBs are served by the local reads.
+// Periods whose bucket is no longer active at now won't get more rotations, so their pending
+// TSDBs are returned regardless of how many there are.
+func (m *tsdbManager) compactPending(ctx context.Context, built []builtIndex, now time.Time) []builtIndex {
+	if m.cfg.CompactionMinMerge < 2 {
+		return built
+	}
+
+	active := make(map[string]struct{})
+	nowTs := model.TimeFromUnixNano(now.UnixNano())
+	for _, bucket := range indexBuckets(nowTs, nowTs, m.tableRanges) {
+		active[bucket] = struct{}{}
+	}
+
+	m.pending.Lock()
+	defer m.pending.Unlock()
+
+	for _, b := range built {
+		m.pending.indices[b.key] = append(m.pending.indices[b.key], b)
+		m.addLocalIndex(b.key, b.ts, b.id, nil)
+	}
+
+	var ready []builtIndex
+	for k, group := range m.pending.indices {
+		if _, ok := active[k.period]; ok && len(group) < m.cfg.CompactionMinMerge {
+			continue
 		}
+		ready = append(ready, group...)
+		delete(m.pending.indices, k)
 	}
 
-	m.metrics.tsdbBuildLastSuccess.SetToCurrentTime()
+	return m.compactIndices(ctx, ready)
+}
+
+// flushPending ships all the pending TSDBs, merging them where enough of them are pending.
+// TSDBs which don't get shipped, i.e. because ctx is done, are left on disk and shipped
+// as leftovers on the next Start.
+func (m *tsdbManager) flushPending(ctx context.Context) error {
+	m.pending.Lock()
+	var pending []builtIndex
+	for k, group := range m.pending.indices {
+		pending = append(pending, group...)
+		delete(m.pending.indices, k)
+	}
+	m.pending.Unlock()
+
+	shipped := m.compactIndices(ctx, pending)
+	if err := m.shipIndices(ctx, shipped); err != nil {
+		return err
+	}
+	m.recordShippedWALs(shipped)
 	return nil
 }
 
-func (m *tsdbManager) BuildFromHead(heads *tenantHeads) (err error) {
+// mergeBuilt merges a group of TSDBs for the same period into one named after the latest
+// of them, removing the others. The timestamps of the WALs they were built from are
+// recorded next to the merged TSDB so that it can be rebuilt, see handleCorruptIndex.
+func (m *tsdbManager) mergeBuilt(ctx context.Context, k buildKey, group []builtIndex) (builtIndex, error) {
+	latest := group[0].ts
+	paths := make([]string, 0, len(group))
+	var sources []time.Time
+	for _, b := range group {
+		if b.ts.After(latest) {
+			latest = b.ts
+		}
+		paths = append(paths, b.id.Path())
+		if b.sources != nil {
+			sources = append(sources, b.sources...)
+		} else {
+			sources = append(sources, b.ts)
+		}
+	}
+
+	dst := m.periodIdentifier(k, latest)
+	level.Debug(m.log).Log("msg", "merging tsdbs before shipping", "pd", k, "n", len(group), "dst", dst.Path())
+	if err := writeIndexSources(dst.Path(), sources); err != nil {
+		return builtIndex{}, err
+	}
+	if _, err := mergeIndices(ctx, managerScratchDir(m.dir), paths, func(_, _ model.Time, _ uint32) Identifier {
+		return dst
+	}); err != nil {
+		if err := removeIndexSources(dst.Path()); err != nil {
+			level.Warn(m.log).Log("msg", "failed removing merged tsdb sources", "tsdbPath", dst.Path(), "err", err)
+		}
+		return builtIndex{}, err
+	}
+
+	// the merged TSDB replaces the ones it was merged from
+	for _, p := range paths {
+		if p == dst.Path() {
+			continue
+		}
+		if err := removeIndex(p); err != nil {
+			level.Warn(m.log).Log("msg", "failed removing merged tsdb", "tsdbPath", p, "err", err)
+		}
+	}
+
+	return builtIndex{key: k, ts: latest, id: dst, sources: sources}, nil
+}
+
+// indexSourcesPath is where the timestamps of the WALs a merged TSDB was built from are recorded.
+// The file doesn't match the TSDB naming, so it's never loaded or shipped.
+func indexSourcesPath(tsdbPath string) string {
+	return tsdbPath + ".sources"
+}
+
+func writeIndexSources(tsdbPath string, sources []time.Time) error {
+	var buf bytes.Buffer
+	for _, ts := range sources {
+		fmt.Fprintf(&buf, "%d\n", ts.Unix())
+	}
+	return os.WriteFile(indexSourcesPath(tsdbPath), buf.Bytes(), 0o644)
+}
+
+// indexSources returns the timestamps of the WALs the TSDB at tsdbPath was built from,
+// which is just ts unless it was merged.
+func indexSources(tsdbPath string, ts time.Time) ([]time.Time, error) {
+	b, err := os.ReadFile(indexSourcesPath(tsdbPath))
+	if os.IsNotExist(err) {
+		return []time.Time{ts}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []time.Time
+	for _, line := range strings.Fields(string(b)) {
+		unix, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing sources of %s", tsdbPath)
+		}
+		sources = append(sources, time.Unix(unix, 0))
+	}
+	return sources, nil
+}
+
+func removeIndexSources(tsdbPath string) error {
+	if err := os.Remove(indexSourcesPath(tsdbPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeIndex removes a TSDB along with its recorded sources, if any.
+func removeIndex(tsdbPath string) error {
+	if err := os.Remove(tsdbPath); err != nil {
+		return err
+	}
+	return removeIndexSources(tsdbPath)
+}
+
+// removeStaleIndexSources removes the recorded sources of TSDBs in dir which no longer exist,
+// i.e. after the shipper removed them from local disk.
+func removeStaleIndexSources(dir string, entries []os.DirEntry) {
+	for _, e := range entries {
+		tsdbName := strings.TrimSuffix(e.Name(), ".sources")
+		if tsdbName == e.Name() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, tsdbName)); os.IsNotExist(err) {
+			_ = os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// observeTenantBuildStats records the per tenant series and chunks written into
+// a period's TSDB. Since a multitenant TSDB is a single file built at once, the bytes
+// written and build time are attributed to each tenant proportionally to the chunk refs
+// it contributed.
+func (m *tsdbManager) observeTenantBuildStats(tenants map[string]*tenantBuildStats, size int64, elapsed time.Duration) {
+	var totalSeries, totalChunks int
+	for _, st := range tenants {
+		totalSeries += st.series
+		totalChunks += st.chunks
+	}
+	m.metrics.tsdbBuildIndexSize.Observe(float64(size))
+	m.metrics.tsdbBuildIndexSeries.Observe(float64(totalSeries))
+
+	for tenant, st := range tenants {
+		m.metrics.tsdbBuildSeries.WithLabelValues(tenant).Add(float64(st.series))
+		m.metrics.tsdbBuildChunks.WithLabelValues(tenant).Add(float64(st.chunks))
+		if totalChunks > 0 {
+			share := float64(st.chunks) / float64(totalChunks)
+			m.metrics.tsdbBuildBytesWritten.WithLabelValues(tenant).Add(float64(size) * share)
+			m.metrics.tsdbBuildTenantDuration.WithLabelValues(tenant).Add(elapsed.Seconds() * share)
+		}
+	}
+}
+
+func (m *tsdbManager) BuildFromHead(ctx context.Context, heads *tenantHeads) (err error) {
 	level.Debug(m.log).Log("msg", "building heads")
 	defer func() {
 		status := statusSuccess
@@ -251,10 +1071,136 @@ func (m *tsdbManager) BuildFromHead(heads *tenantHeads) (err error) {
 		m.metrics.tsdbBuilds.WithLabelValues(status, "head").Inc()
 	}()
 
-	return m.buildFromHead(heads)
+	if err := m.startBuild(); err != nil {
+		return err
+	}
+	defer m.inflight.Done()
+
+	if err := m.checkDiskSpace([]WALIdentifier{{ts: heads.start}}); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.buildContext(ctx)
+	defer cancel()
+
+	built, err := m.buildFromHead(ctx, heads, newSymbolPool())
+	// Only hold back TSDBs when every period was built; otherwise ship what was built as is.
+	if err == nil {
+		built = m.compactPending(ctx, built, time.Now())
+	}
+	return m.finishBuild(ctx, built, err)
+}
+
+// buildContext returns a context which is cancelled when either
+// the caller's ctx is done or the manager is stopped.
+func (m *tsdbManager) buildContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-m.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// startBuild registers an in-flight build unless the manager is stopped.
+// Callers must call m.inflight.Done() when finished.
+func (m *tsdbManager) startBuild() error {
+	m.RLock()
+	defer m.RUnlock()
+	if m.stopped {
+		return errManagerStopped
+	}
+	m.inflight.Add(1)
+	return nil
+}
+
+// checkDiskSpace estimates the disk space required for building the WALs from their size and
+// refuses the build if it would leave less than MinFreeDiskSpace free. The WALs are left untouched,
+// so the build is retried on the next Start.
+func (m *tsdbManager) checkDiskSpace(ids []WALIdentifier) error {
+	var walSize int64
+	for _, id := range ids {
+		size, err := dirSize(walPath(m.dir, id.ts))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "estimating tsdb build size")
+		}
+		walSize += size
+	}
+	required := buildDiskSpaceFactor * walSize
+
+	free, err := freeDiskSpace(m.dir)
+	if err != nil {
+		// don't block builds on platforms or filesystems we can't check
+		level.Warn(m.log).Log("msg", "failed checking free disk space", "dir", m.dir, "err", err)
+		return nil
+	}
+
+	headroom := float64(free) - float64(required) - float64(m.cfg.MinFreeDiskSpace)
+	m.metrics.tsdbBuildDiskHeadroom.Set(headroom)
+	if m.cfg.MinFreeDiskSpace > 0 && headroom < 0 {
+		level.Error(m.log).Log("msg", "refusing tsdb build, not enough free disk space", "free", free, "required", required, "min_free", m.cfg.MinFreeDiskSpace)
+		return errors.Wrapf(errInsufficientDiskSpace, "%d bytes free, %d bytes required, %d bytes to keep free", free, required, m.cfg.MinFreeDiskSpace)
+	}
+	return nil
+}
+
+// dirSize returns the total size of the files under dir.
+func dirSize(dir string) (size int64, err error) {
+	err = filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += fi.Size()
+		return nil
+	})
+	return size, err
+}
+
+func (m *tsdbManager) Stop(ctx context.Context) error {
+	m.Lock()
+	m.stopped = true
+	m.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		level.Warn(m.log).Log("msg", "cancelling in-flight tsdb builds", "err", ctx.Err())
+		m.cancel()
+		<-done
+	}
+	if err := m.flushPending(ctx); err != nil {
+		level.Warn(m.log).Log("msg", "failed shipping pending tsdbs, they will be shipped on the next start", "err", err)
+	}
+	m.cancel()
+	m.closeLocalIndices()
+	m.shipper.Stop()
+
+	// remove any partially built indices; they'll be rebuilt from the WALs which
+	// are only truncated after a successful build.
+	if err := cleanScratchDir(m.dir); err != nil {
+		return errors.Wrap(err, "cleaning tsdb scratch dir")
+	}
+
+	return nil
 }
 
-func (m *tsdbManager) BuildFromWALs(t time.Time, ids []WALIdentifier) (err error) {
+func (m *tsdbManager) BuildFromWALs(ctx context.Context, t time.Time, ids []WALIdentifier) (err error) {
 	level.Debug(m.log).Log("msg", "building WALs", "n", len(ids), "ts", t)
 	defer func() {
 		status := statusSuccess
@@ -265,33 +1211,194 @@ func (m *tsdbManager) BuildFromWALs(t time.Time, ids []WALIdentifier) (err error
 		m.metrics.tsdbBuilds.WithLabelValues(status, "wal").Inc()
 	}()
 
+	if err := m.startBuild(); err != nil {
+		return err
+	}
+	defer m.inflight.Done()
+
+	if err := m.checkDiskSpace(ids); err != nil {
+		return err
+	}
+
+	ctx, cancel := m.buildContext(ctx)
+	defer cancel()
+
+	// the label names and values are shared by the builders of all the periods of all the WALs
+	symbols := newSymbolPool()
+	var built []builtIndex
+	if m.cfg.StreamingBatchSize > 0 {
+		built, err = m.buildFromWALsStreaming(ctx, ids, symbols)
+	} else {
+		built, err = m.buildFromWALs(ctx, ids, symbols)
+	}
+
+	// Only compact when every WAL was built; otherwise ship what was built as is.
+	if err == nil {
+		built = m.compactIndices(ctx, built)
+	}
+	return m.finishBuild(ctx, built, err)
+}
+
+// buildFromWALs replays each WAL into tenantHeads and builds TSDBs from them,
+// returning every TSDB built.
+func (m *tsdbManager) buildFromWALs(ctx context.Context, ids []WALIdentifier, symbols *symbolPool) (built []builtIndex, err error) {
 	level.Debug(m.log).Log("msg", "recovering tenant heads")
 	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return built, err
+		}
+
 		tmp := newTenantHeads(id.ts, defaultHeadManagerStripeSize, m.metrics, m.log)
-		if err = recoverHead(m.dir, tmp, []WALIdentifier{id}); err != nil {
-			return errors.Wrap(err, "building TSDB from WALs")
+		if err = m.recoverWAL(ctx, id, func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error {
+			_ = tmp.Append(user, ls, fp, chks)
+			return nil
+		}); err != nil {
+			return built, errors.Wrap(err, "building TSDB from WALs")
 		}
 
-		err := m.buildFromHead(tmp)
+		b, err := m.buildFromHead(ctx, tmp, symbols)
+		built = append(built, b...)
 		if err != nil {
-			return err
+			return built, err
 		}
 	}
 
-	return nil
+	return built, nil
 }
 
-func indexBuckets(from, through model.Time, tableRanges config.TableRanges) (res []string) {
-	start := from.Time().UnixNano() / int64(config.ObjectStorageIndexRequiredPeriod)
-	end := through.Time().UnixNano() / int64(config.ObjectStorageIndexRequiredPeriod)
-	for cur := start; cur <= end; cur++ {
-		cfg := tableRanges.ConfigForTableNumber(cur)
-		if cfg != nil {
-			res = append(res, cfg.IndexTables.Prefix+strconv.Itoa(int(cur)))
+// buildFromWALsStreaming replays the WALs directly into StreamingBuilders
+// rather than materializing them as tenantHeads first, bounding the memory used.
+func (m *tsdbManager) buildFromWALsStreaming(ctx context.Context, ids []WALIdentifier, symbols *symbolPool) (built []builtIndex, err error) {
+	level.Debug(m.log).Log("msg", "streaming WALs into tsdb builders", "batch_size", m.cfg.StreamingBatchSize)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return built, err
+		}
+
+		b, err := m.buildWALStreaming(ctx, id, symbols)
+		built = append(built, b...)
+		if err != nil {
+			return built, err
 		}
 	}
+
+	return built, nil
+}
+
+func (m *tsdbManager) buildWALStreaming(ctx context.Context, id WALIdentifier, symbols *symbolPool) ([]builtIndex, error) {
+	periods := m.newPeriodBuilders(ctx, symbols)
+	defer m.cleanupPeriodBuilders(periods)
+
+	if err := m.recoverWAL(ctx, id, periods.add); err != nil {
+		return nil, errors.Wrap(err, "building TSDB from WALs")
+	}
+	return m.buildPeriods(ctx, id.ts, periods)
+}
+
+// cleanupPeriodBuilders removes the partial indices left behind by StreamingBuilders
+// which were never built, i.e. due to a failed WAL replay or cancellation.
+func (m *tsdbManager) cleanupPeriodBuilders(periods *periodBuilders) {
+	for k, b := range periods.builders {
+		sb, ok := b.(*StreamingBuilder)
+		if !ok {
+			continue
+		}
+		if err := sb.Cleanup(); err != nil {
+			level.Warn(m.log).Log("msg", "failed removing partial tsdb indices", "pd", k, "err", err)
+		}
+	}
+}
+
+// recoverWAL replays a single WAL through fn. If configured to skip corrupt WALs,
+// corrupt records are skipped and damaged WALs are moved to the quarantine dir
+// after recovering what's readable, rather than failing the build.
+func (m *tsdbManager) recoverWAL(ctx context.Context, id WALIdentifier, fn func(user string, ls labels.Labels, fp uint64, chks index.ChunkMetas) error) error {
+	start := time.Now()
+	corrupted, err := recoverWALs(ctx, m.dir, []WALIdentifier{id}, m.cfg.SkipCorruptWALs, fn)
+	m.metrics.walRecoveryDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return err
+	}
+
+	for _, c := range corrupted {
+		m.metrics.walCorruptRecords.Add(float64(c.skippedRecords))
+		level.Warn(m.log).Log(
+			"msg", "recovered partially from corrupt tsdb wal, quarantining",
+			"wal", walPath(m.dir, c.id.ts),
+			"skipped_records", c.skippedRecords,
+			"err", c.err,
+		)
+
+		if err := m.quarantineWAL(c.id); err != nil {
+			m.metrics.walQuarantines.WithLabelValues(statusFailure).Inc()
+			level.Error(m.log).Log("msg", "failed quarantining tsdb wal", "wal", walPath(m.dir, c.id.ts), "err", err)
+			continue
+		}
+		m.metrics.walQuarantines.WithLabelValues(statusSuccess).Inc()
+	}
+
+	return nil
+}
+
+func (m *tsdbManager) quarantineWAL(id WALIdentifier) error {
+	if err := chunk_util.EnsureDirectory(managerQuarantineDir(m.dir)); err != nil {
+		return err
+	}
+
+	src := walPath(m.dir, id.ts)
+	return os.Rename(src, filepath.Join(managerQuarantineDir(m.dir), filepath.Base(src)))
+}
+
+func indexBuckets(from, through model.Time, tableRanges config.TableRanges) (res []string) {
+	forIndexTables(from, through, tableRanges, func(table string, _, _ model.Time) {
+		res = append(res, table)
+	})
 	if len(res) == 0 {
-		level.Warn(util_log.Logger).Log("err", "could not find config for table(s) from: %d, through %d", start, end)
+		level.Warn(util_log.Logger).Log("msg", "could not find config for table(s)", "from", from, "through", through)
 	}
 	return
 }
+
+// forIndexTables calls fn, in order, for every index table (bucket) overlapping with [from, through],
+// along with the part of the range falling into it. Each period config's index table period is
+// honored, and tables only cover the time during which their period config is active.
+func forIndexTables(from, through model.Time, tableRanges config.TableRanges, fn func(table string, from, through model.Time)) {
+	for i, r := range tableRanges {
+		cfg := r.PeriodConfig
+		period := cfg.IndexTables.Period
+		if period <= 0 {
+			period = config.ObjectStorageIndexRequiredPeriod
+		}
+
+		rangeFrom, rangeThrough := from, through
+		if rangeFrom < cfg.From.Time {
+			rangeFrom = cfg.From.Time
+		}
+		if i+1 < len(tableRanges) {
+			if next := tableRanges[i+1].PeriodConfig.From.Time; rangeThrough >= next {
+				rangeThrough = next - 1
+			}
+		}
+		if rangeFrom > rangeThrough {
+			continue
+		}
+
+		start := rangeFrom.Time().UnixNano() / int64(period)
+		end := rangeThrough.Time().UnixNano() / int64(period)
+		for cur := start; cur <= end; cur++ {
+			if cur < r.Start || cur > r.End {
+				continue
+			}
+
+			tableFrom := model.TimeFromUnixNano(cur * int64(period))
+			tableThrough := model.TimeFromUnixNano((cur+1)*int64(period)) - 1
+			if tableFrom < rangeFrom {
+				tableFrom = rangeFrom
+			}
+			if tableThrough > rangeThrough {
+				tableThrough = rangeThrough
+			}
+			fn(cfg.IndexTables.Prefix+strconv.Itoa(int(cur)), tableFrom, tableThrough)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package tsdb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// BuildPlan describes a TSDB which building the planned WALs would produce.
+type BuildPlan struct {
+	Period string `json:"period"`
+	// empty for multitenant TSDBs
+	Tenant string `json:"tenant,omitempty"`
+	// empty unless sharding the output
+	Shard string `json:"shard,omitempty"`
+	// number of TSDBs built for this period, one per WAL containing series for it,
+	// before they get merged by pre-ship compaction, if enabled.
+	TSDBs  int `json:"tsdbs"`
+	Series int `json:"series"`
+	Chunks int `json:"chunks"`
+	// rough estimate of the size of the TSDB in bytes, after merging the ones built per WAL
+	EstimatedSize int64 `json:"estimated_size_bytes"`
+}
+
+// PlanFromWALs replays the WALs the same way BuildFromWALs does and reports the TSDBs
+// it would build, without writing, shipping or quarantining anything.
+// It can run alongside builds; only the WALs are read.
+func (m *tsdbManager) PlanFromWALs(ctx context.Context, ids []WALIdentifier) ([]BuildPlan, error) {
+	periods := m.newPeriodBuilders(ctx, nil)
+	// Accumulate in memory regardless of streaming builds; nothing is ever built.
+	periods.newBuilder = func() indexBuilder { return NewBuilder() }
+	if periods.retention != nil {
+		periods.retention.pruned = nil
+	}
+
+	tsdbs := make(map[buildKey]int)
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// keys which are added to while replaying this WAL get a TSDB built for it
+		periods.touched = make(map[buildKey]struct{})
+		corrupted, err := recoverWALs(ctx, m.dir, []WALIdentifier{id}, m.cfg.SkipCorruptWALs, periods.add)
+		if err != nil {
+			return nil, errors.Wrap(err, "planning TSDB from WALs")
+		}
+		for _, c := range corrupted {
+			level.Warn(m.log).Log("msg", "tsdb wal is corrupt, it would be quarantined when built", "wal", walPath(m.dir, c.id.ts), "skipped_records", c.skippedRecords, "err", c.err)
+		}
+
+		for k := range periods.touched {
+			tsdbs[k]++
+		}
+	}
+
+	res := make([]BuildPlan, 0, len(periods.builders))
+	for k, b := range periods.builders {
+		p := BuildPlan{
+			Period: k.period,
+			Tenant: k.tenant,
+			TSDBs:  tsdbs[k],
+		}
+		if k.shard.Of > 1 {
+			p.Shard = k.shard.String()
+		}
+
+		// the chunks replayed from multiple WALs are only deduped once finalized
+		b.(*Builder).FinalizeChunks()
+		streams := b.(*Builder).streams
+		p.Series = len(streams)
+		for _, s := range streams {
+			p.Chunks += len(s.chunks)
+		}
+		p.EstimatedSize = estimateIndexSize(streams)
+		res = append(res, p)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Period != res[j].Period {
+			return res[i].Period < res[j].Period
+		}
+		if res[i].Tenant != res[j].Tenant {
+			return res[i].Tenant < res[j].Tenant
+		}
+		return res[i].Shard < res[j].Shard
+	})
+	return res, nil
+}
+
+// Approximate sizes of the entries of a TSDB index, assuming varints take 2-3 bytes.
+const (
+	// header, TOC and fingerprint offsets table
+	estimatedIndexOverhead = 1 << 10
+	// fingerprint, label and chunk counts, checksum and padding to 16 bytes
+	estimatedSeriesOverhead = 24
+	// a pair of symbol references
+	estimatedLabelSize = 4
+	// min/max time, checksum, KB and entries
+	estimatedChunkSize = 16
+	// length, checksum and offset table entry of a postings list
+	estimatedPostingsOverhead = 16
+	// series reference in a postings list
+	estimatedPostingSize = 4
+)
+
+// estimateIndexSize returns a rough estimate of the size of the TSDB the streams would be written to.
+func estimateIndexSize(streams map[string]*stream) int64 {
+	var (
+		size     int64 = estimatedIndexOverhead
+		symbols        = make(map[string]struct{})
+		postings       = make(map[labels.Label]struct{})
+	)
+
+	for _, s := range streams {
+		size += estimatedSeriesOverhead + int64(len(s.labels)*estimatedLabelSize+len(s.chunks)*estimatedChunkSize)
+		// every series is referenced by the all postings list and the one for each of its labels
+		size += int64((len(s.labels) + 1) * estimatedPostingSize)
+		for _, l := range s.labels {
+			symbols[l.Name] = struct{}{}
+			symbols[l.Value] = struct{}{}
+			postings[l] = struct{}{}
+		}
+	}
+
+	for s := range symbols {
+		size += int64(len(s) + 2)
+	}
+	for l := range postings {
+		size += int64(len(l.Name)+len(l.Value)) + estimatedPostingsOverhead
+	}
+	return size
+}
@@ -0,0 +1,177 @@
+package tsdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+// localIndex is a TSDB built by the manager which is still kept on local disk by the shipper.
+type localIndex struct {
+	key buildKey
+	ts  time.Time
+	idx Index
+	// inMemory is the TSDB built in memory and uploaded directly, named name, nil if it's on disk
+	inMemory *TSDBIndex
+	name     string
+}
+
+// localIndices serves reads from the TSDBs built by the manager directly, instead of
+// through the shipper. Indices are held as long as the shipper retains them locally
+// after uploading them, i.e. IngesterDBRetainPeriod, after which queriers
+// are expected to find them in the object store.
+type localIndices struct {
+	sync.RWMutex
+	indices []localIndex
+}
+
+// addLocalIndex opens a separate reader for a shipped TSDB, so that it stays
+// readable independently of the shipper closing and removing its own copy.
+// It replaces the local indices for the same key built at ts or at any of the
+// timestamps in merged, which are the indices a merged TSDB was built from.
+func (m *tsdbManager) addLocalIndex(key buildKey, ts time.Time, id Identifier, merged []time.Time) {
+	if ts.Before(time.Now().Add(-m.cfg.IngesterDBRetainPeriod)) {
+		return
+	}
+
+	tsdbIndex, _, err := NewTSDBIndexFromFile(id.Path())
+	if err != nil {
+		level.Warn(m.log).Log("msg", "failed opening built tsdb for local reads", "tsdbPath", id.Path(), "err", err)
+		return
+	}
+	m.addLocalTSDBIndex(localIndex{key: key, ts: ts, idx: tsdbIndex}, merged)
+}
+
+// addLocalInMemoryIndex is addLocalIndex for the TSDBs built in memory and uploaded directly.
+func (m *tsdbManager) addLocalInMemoryIndex(key buildKey, ts time.Time, id Identifier, data []byte) {
+	if ts.Before(time.Now().Add(-m.cfg.IngesterDBRetainPeriod)) {
+		return
+	}
+
+	reader, err := index.NewReader(index.RealByteSlice(data))
+	if err != nil {
+		level.Warn(m.log).Log("msg", "failed opening built tsdb for local reads", "pd", key, "err", err)
+		return
+	}
+	tsdbIndex := NewTSDBIndex(reader)
+	m.addLocalTSDBIndex(localIndex{key: key, ts: ts, idx: tsdbIndex, inMemory: tsdbIndex, name: id.Name()}, nil)
+}
+
+func (m *tsdbManager) addLocalTSDBIndex(l localIndex, merged []time.Time) {
+	if l.key.tenant == "" {
+		l.idx = NewMultiTenantIndex(l.idx)
+	}
+
+	m.local.Lock()
+	defer m.local.Unlock()
+	m.removeLocalIndices(l.key, append(merged, l.ts))
+	m.local.indices = append(m.local.indices, l)
+	m.evictLocalIndices(time.Now())
+}
+
+// removeLocalIndices closes the indices for key built at any of the timestamps.
+// Callers must hold the write lock.
+func (m *tsdbManager) removeLocalIndices(key buildKey, timestamps []time.Time) {
+	retained := m.local.indices[:0]
+Outer:
+	for _, l := range m.local.indices {
+		if l.key == key {
+			for _, ts := range timestamps {
+				if l.ts.Equal(ts) {
+					if err := l.idx.Close(); err != nil {
+						level.Warn(m.log).Log("msg", "failed closing local tsdb", "pd", l.key, "err", err)
+					}
+					continue Outer
+				}
+			}
+		}
+		retained = append(retained, l)
+	}
+	m.local.indices = retained
+}
+
+// evictLocalIndices closes the indices which outlived the retain period.
+// Callers must hold the write lock.
+func (m *tsdbManager) evictLocalIndices(now time.Time) {
+	cutoff := now.Add(-m.cfg.IngesterDBRetainPeriod)
+	retained := m.local.indices[:0]
+	for _, l := range m.local.indices {
+		if l.ts.Before(cutoff) {
+			if err := l.idx.Close(); err != nil {
+				level.Warn(m.log).Log("msg", "failed closing local tsdb", "pd", l.key, "err", err)
+			}
+			continue
+		}
+		retained = append(retained, l)
+	}
+	m.local.indices = retained
+}
+
+func (m *tsdbManager) closeLocalIndices() {
+	m.local.Lock()
+	defer m.local.Unlock()
+	for _, l := range m.local.indices {
+		if err := l.idx.Close(); err != nil {
+			level.Warn(m.log).Log("msg", "failed closing local tsdb", "pd", l.key, "err", err)
+		}
+	}
+	m.local.indices = nil
+}
+
+// forLocalIndices calls fn with the local indices which may contain data for userID in the given range
+// and shard, if any. fn is not called when there are none.
+func (m *tsdbManager) forLocalIndices(userID string, from, through model.Time, shard *index.ShardAnnotation, fn func(Index) error) error {
+	m.local.RLock()
+	defer m.local.RUnlock()
+
+	queryBounds := newBounds(from, through)
+	var matching []Index
+	for _, l := range m.local.indices {
+		if (l.key.tenant == "" || l.key.tenant == userID) && shardsOverlap(l.key.shard, shard) && Overlap(queryBounds, l.idx) {
+			matching = append(matching, l.idx)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	// MultiIndex dedupes the results across indices, which may overlap when chunks
+	// were flushed to multiple WALs.
+	idx, err := NewMultiIndex(matching...)
+	if err != nil {
+		return err
+	}
+	return fn(idx)
+}
+
+func (m *tsdbManager) GetChunkRefs(ctx context.Context, userID string, from, through model.Time, res []ChunkRef, shard *index.ShardAnnotation, matchers ...*labels.Matcher) ([]ChunkRef, error) {
+	err := m.forLocalIndices(userID, from, through, shard, func(idx Index) (err error) {
+		res, err = idx.GetChunkRefs(ctx, userID, from, through, res, shard, matchers...)
+		return err
+	})
+	return res, err
+}
+
+// Series follows the same semantics regarding the passed slice and shard as GetChunkRefs.
+func (m *tsdbManager) Series(ctx context.Context, userID string, from, through model.Time, res []Series, shard *index.ShardAnnotation, matchers ...*labels.Matcher) ([]Series, error) {
+	err := m.forLocalIndices(userID, from, through, shard, func(idx Index) (err error) {
+		res, err = idx.Series(ctx, userID, from, through, res, shard, matchers...)
+		return err
+	})
+	return res, err
+}
+
+func (m *tsdbManager) LabelNames(ctx context.Context, userID string, from, through model.Time, matchers ...*labels.Matcher) ([]string, error) {
+	var res []string
+	err := m.forLocalIndices(userID, from, through, nil, func(idx Index) (err error) {
+		res, err = idx.LabelNames(ctx, userID, from, through, matchers...)
+		return err
+	})
+	return res, err
+}
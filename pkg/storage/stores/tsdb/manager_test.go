@@ -0,0 +1,1233 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/record"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper"
+	shipper_index "github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+	"github.com/grafana/loki/pkg/util/flagext"
+	"github.com/grafana/loki/pkg/validation"
+)
+
+type mockIndexShipper struct {
+	mtx sync.Mutex
+	// keyed by <table>[/<user>]
+	indices map[string][]shipper_index.Index
+	stopped bool
+	// returned by UploadIndex if set
+	uploadErr error
+}
+
+func newMockIndexShipper() *mockIndexShipper {
+	return &mockIndexShipper{indices: make(map[string][]shipper_index.Index)}
+}
+
+func (s *mockIndexShipper) AddIndex(tableName, userID string, idx shipper_index.Index) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	key := path.Join(tableName, userID)
+	s.indices[key] = append(s.indices[key], idx)
+	return nil
+}
+
+func (s *mockIndexShipper) UploadIndex(_ context.Context, tableName, userID string, idx shipper_index.Index) error {
+	if s.uploadErr != nil {
+		return s.uploadErr
+	}
+	return s.AddIndex(tableName, userID, idx)
+}
+
+func (s *mockIndexShipper) ForEach(_ context.Context, tableName, userID string, _ <-chan struct{}, callback shipper_index.ForEachIndexCallback) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, idx := range s.indices[tableName] {
+		if err := callback(true, idx); err != nil {
+			return err
+		}
+	}
+	for _, idx := range s.indices[path.Join(tableName, userID)] {
+		if err := callback(false, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mockIndexShipper) Stop() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.stopped = true
+}
+
+func testTableRanges() config.TableRanges {
+	return config.TableRanges{
+		{
+			Start: 0,
+			End:   math.MaxInt64,
+			PeriodConfig: &config.PeriodConfig{
+				IndexTables: config.PeriodicTableConfig{
+					Prefix: "index_",
+					Period: config.ObjectStorageIndexRequiredPeriod,
+				},
+			},
+		},
+	}
+}
+
+func newTestTSDBManager(t *testing.T, metrics *Metrics, cfg IndexCfg, limits Limits) (*tsdbManager, *mockIndexShipper) {
+	dir := t.TempDir()
+	for _, d := range managerRequiredDirs(dir) {
+		require.Nil(t, util.EnsureDirectory(d))
+	}
+
+	shipper := newMockIndexShipper()
+	mgr := NewTSDBManager("node", dir, shipper, testTableRanges(), cfg, limits, log.NewNopLogger(), metrics)
+	return mgr.(*tsdbManager), shipper
+}
+
+// corruptWALRecord makes writeTestWAL write an undecodable record
+var corruptWALRecord = &WALRecord{}
+
+// testWALRecord adds chks to the series with the given ref
+func testWALRecord(tenant string, ls labels.Labels, ref int, chks index.ChunkMetas) *WALRecord {
+	return &WALRecord{
+		UserID:      tenant,
+		Fingerprint: ls.Hash(),
+		Series: record.RefSeries{
+			Ref:    chunks.HeadSeriesRef(ref),
+			Labels: ls,
+		},
+		Chks: ChunkMetasRecord{
+			Chks: chks,
+			Ref:  uint64(ref),
+		},
+	}
+}
+
+// writeTestWAL writes the records to the WAL for ts under dir
+func writeTestWAL(t *testing.T, dir string, ts time.Time, records ...*WALRecord) {
+	w, err := newHeadWAL(log.NewNopLogger(), walPath(dir, ts), ts)
+	require.Nil(t, err)
+	for _, r := range records {
+		if r == corruptWALRecord {
+			require.Nil(t, w.wal.Log([]byte{0xff, 0xff, 0xff}))
+			continue
+		}
+		require.Nil(t, w.Log(r))
+	}
+	require.Nil(t, w.Stop())
+}
+
+type fakeRetentionLimits struct {
+	retention           map[string]time.Duration
+	streamRetention     map[string][]validation.StreamRetention
+	outputShards        map[string]int
+	perTenantOutput     map[string]bool
+	retentionAwareBuild map[string]bool
+}
+
+func (f fakeRetentionLimits) RetentionPeriod(userID string) time.Duration {
+	return f.retention[userID]
+}
+
+func (f fakeRetentionLimits) StreamRetention(userID string) []validation.StreamRetention {
+	return f.streamRetention[userID]
+}
+
+func (f fakeRetentionLimits) TSDBOutputShards(userID string) int {
+	return f.outputShards[userID]
+}
+
+func (f fakeRetentionLimits) TSDBPerTenantOutput(userID string) bool {
+	return f.perTenantOutput[userID]
+}
+
+func (f fakeRetentionLimits) TSDBRetentionAwareBuild(userID string) bool {
+	return f.retentionAwareBuild[userID]
+}
+
+func (f fakeRetentionLimits) AllByUserID() map[string]*validation.Limits { return nil }
+func (f fakeRetentionLimits) DefaultLimits() *validation.Limits          { return &validation.Limits{} }
+
+func histogramSampleCount(t *testing.T, h prometheus.Histogram) uint64 {
+	var m dto.Metric
+	require.Nil(t, h.Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+func Test_TSDBManager_BuildFromHead_TenantMetrics(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{}, nil)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+	for _, ls := range []string{`{foo="bar"}`, `{foo="baz"}`} {
+		lbls := mustParseLabels(ls)
+		heads.Append("tenant1", lbls, lbls.Hash(), index.ChunkMetas{
+			{MinTime: 1, MaxTime: 10, Checksum: 1},
+			{MinTime: 11, MaxTime: 20, Checksum: 2},
+		})
+	}
+	lbls := mustParseLabels(`{foo="bar"}`)
+	heads.Append("tenant2", lbls, lbls.Hash(), index.ChunkMetas{
+		{MinTime: 1, MaxTime: 10, Checksum: 3},
+	})
+
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+	bucket := "index_0"
+	require.Len(t, shipper.indices[bucket], 1)
+
+	require.Equal(t, 2., testutil.ToFloat64(metrics.tsdbBuildSeries.WithLabelValues("tenant1")))
+	require.Equal(t, 4., testutil.ToFloat64(metrics.tsdbBuildChunks.WithLabelValues("tenant1")))
+	require.Equal(t, 1., testutil.ToFloat64(metrics.tsdbBuildSeries.WithLabelValues("tenant2")))
+	require.Equal(t, 1., testutil.ToFloat64(metrics.tsdbBuildChunks.WithLabelValues("tenant2")))
+
+	// bytes and build time are attributed proportionally to the chunks contributed by each tenant
+	tenant1Bytes := testutil.ToFloat64(metrics.tsdbBuildBytesWritten.WithLabelValues("tenant1"))
+	tenant2Bytes := testutil.ToFloat64(metrics.tsdbBuildBytesWritten.WithLabelValues("tenant2"))
+	require.Greater(t, tenant2Bytes, 0.)
+	require.InDelta(t, 4*tenant2Bytes, tenant1Bytes, 1e-6)
+
+	tenant1Duration := testutil.ToFloat64(metrics.tsdbBuildTenantDuration.WithLabelValues("tenant1"))
+	tenant2Duration := testutil.ToFloat64(metrics.tsdbBuildTenantDuration.WithLabelValues("tenant2"))
+	require.Greater(t, tenant2Duration, 0.)
+	require.InDelta(t, 4*tenant2Duration, tenant1Duration, 1e-9)
+
+	require.Equal(t, uint64(1), histogramSampleCount(t, metrics.tsdbBuildDuration))
+
+	// a single index with 3 series was built
+	require.Nil(t, testutil.CollectAndCompare(metrics.tsdbBuildIndexSeries, strings.NewReader(`
+# HELP loki_tsdb_build_index_series Number of series written into the tsdb indices built per period bucket
+# TYPE loki_tsdb_build_index_series histogram
+loki_tsdb_build_index_series_bucket{le="10"} 1
+loki_tsdb_build_index_series_bucket{le="40"} 1
+loki_tsdb_build_index_series_bucket{le="160"} 1
+loki_tsdb_build_index_series_bucket{le="640"} 1
+loki_tsdb_build_index_series_bucket{le="2560"} 1
+loki_tsdb_build_index_series_bucket{le="10240"} 1
+loki_tsdb_build_index_series_bucket{le="40960"} 1
+loki_tsdb_build_index_series_bucket{le="163840"} 1
+loki_tsdb_build_index_series_bucket{le="655360"} 1
+loki_tsdb_build_index_series_bucket{le="2.62144e+06"} 1
+loki_tsdb_build_index_series_bucket{le="+Inf"} 1
+loki_tsdb_build_index_series_sum 3
+loki_tsdb_build_index_series_count 1
+`)))
+	require.Equal(t, uint64(1), histogramSampleCount(t, metrics.tsdbBuildIndexSize))
+}
+
+func Test_TSDBManager_BuildFromHead_MultiplePeriods(t *testing.T) {
+	for _, concurrency := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("concurrency-%d", concurrency), func(t *testing.T) {
+			metrics := NewMetrics(nil)
+			mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{BuildConcurrency: concurrency}, nil)
+
+			day := int64(config.ObjectStorageIndexRequiredPeriod / time.Millisecond)
+			heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+			lbls := mustParseLabels(`{foo="bar"}`)
+			heads.Append("tenant1", lbls, lbls.Hash(), index.ChunkMetas{
+				{MinTime: 1, MaxTime: 10, Checksum: 1},
+				// spans two index buckets
+				{MinTime: day - 10, MaxTime: day + 10, Checksum: 2},
+				{MinTime: 2*day + 1, MaxTime: 2*day + 10, Checksum: 3},
+			})
+
+			require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+			expected := []string{"index_0", "index_1", "index_2"}
+			require.Len(t, shipper.indices, len(expected))
+			for _, bucket := range expected {
+				require.Len(t, shipper.indices[bucket], 1)
+			}
+			// the chunk spanning two buckets is written to both
+			require.Equal(t, 4., testutil.ToFloat64(metrics.tsdbBuildChunks.WithLabelValues("tenant1")))
+		})
+	}
+}
+
+func Test_TSDBManager_BuildFromHead_Rebuild(t *testing.T) {
+	mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{}, nil)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, mgr.metrics, log.NewNopLogger())
+	lbls := mustParseLabels(`{foo="bar"}`)
+	heads.Append("tenant1", lbls, lbls.Hash(), index.ChunkMetas{{MinTime: 1, MaxTime: 10, Checksum: 1}})
+
+	// rebuilding the same rotation, i.e. after a partial failure, doesn't overwrite the shipped TSDB
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+	require.Len(t, shipper.indices["index_0"], 2)
+	first, second := shipper.indices["index_0"][0].Name(), shipper.indices["index_0"][1].Name()
+	require.NotEqual(t, first, second)
+
+	files, err := filepath.Glob(filepath.Join(managerMultitenantDir(mgr.dir), "index_0", "*.tsdb"))
+	require.Nil(t, err)
+	require.Len(t, files, 2)
+	firstID, ok := parseMultitenantTSDBPath(first)
+	require.True(t, ok)
+	secondID, ok := parseMultitenantTSDBPath(second)
+	require.True(t, ok)
+	require.Greater(t, secondID.seq, firstID.seq)
+}
+
+// weekly index tables until day 10, daily ones afterwards
+func testMixedPeriodTableRanges() config.TableRanges {
+	day := config.ObjectStorageIndexRequiredPeriod
+	weekly := &config.PeriodConfig{
+		From:        config.DayTime{Time: 0},
+		IndexTables: config.PeriodicTableConfig{Prefix: "weekly_", Period: 7 * day},
+	}
+	daily := &config.PeriodConfig{
+		From:        config.DayTime{Time: model.Time(10 * day / time.Millisecond)},
+		IndexTables: config.PeriodicTableConfig{Prefix: "daily_", Period: day},
+	}
+	return config.TableRanges{
+		weekly.GetIndexTableNumberRange(config.DayTime{Time: daily.From.Time - 1}),
+		daily.GetIndexTableNumberRange(config.DayTime{Time: math.MaxInt64}),
+	}
+}
+
+func Test_forIndexTables(t *testing.T) {
+	day := model.Time(config.ObjectStorageIndexRequiredPeriod / time.Millisecond)
+	type table struct {
+		name          string
+		from, through model.Time
+	}
+
+	for _, tc := range []struct {
+		desc          string
+		from, through model.Time
+		expected      []table
+	}{
+		{
+			desc:    "within a weekly table",
+			from:    day + 1,
+			through: 2 * day,
+			expected: []table{
+				{"weekly_0", day + 1, 2 * day},
+			},
+		},
+		{
+			desc:    "weekly table cut short by the next config",
+			from:    8 * day,
+			through: 11*day + 1,
+			expected: []table{
+				{"weekly_1", 8 * day, 10*day - 1},
+				{"daily_10", 10 * day, 11*day - 1},
+				{"daily_11", 11 * day, 11*day + 1},
+			},
+		},
+		{
+			desc:    "spanning weekly tables",
+			from:    6 * day,
+			through: 7 * day,
+			expected: []table{
+				{"weekly_0", 6 * day, 7*day - 1},
+				{"weekly_1", 7 * day, 7 * day},
+			},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			var tables []table
+			forIndexTables(tc.from, tc.through, testMixedPeriodTableRanges(), func(name string, from, through model.Time) {
+				tables = append(tables, table{name, from, through})
+			})
+			require.Equal(t, tc.expected, tables)
+		})
+	}
+}
+
+func Test_TSDBManager_MixedIndexPeriods(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{}, nil)
+	mgr.tableRanges = testMixedPeriodTableRanges()
+
+	day := int64(config.ObjectStorageIndexRequiredPeriod / time.Millisecond)
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+	lbls := mustParseLabels(`{foo="bar"}`)
+	heads.Append("tenant1", lbls, lbls.Hash(), index.ChunkMetas{
+		{MinTime: 1, MaxTime: 10, Checksum: 1},
+		{MinTime: 3 * day, MaxTime: 3*day + 10, Checksum: 2},
+		// spans the switch to daily tables
+		{MinTime: 10*day - 10, MaxTime: 10*day + 10, Checksum: 3},
+	})
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+	expected := []string{"weekly_0", "weekly_1", "daily_10"}
+	require.Len(t, shipper.indices, len(expected))
+	for _, bucket := range expected {
+		require.Len(t, shipper.indices[bucket], 1)
+	}
+
+	// leftover tsdbs in buckets of any period config are loaded on start
+	reloaded := newMockIndexShipper()
+	mgr.shipper = reloaded
+	require.Nil(t, mgr.Start())
+	require.Len(t, reloaded.indices, len(expected))
+}
+
+func Test_TSDBManager_BuildFromWALs_Streaming(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{StreamingBatchSize: 2}, nil)
+
+	now := time.Now()
+	var (
+		records  []*WALRecord
+		expected []ChunkRef
+	)
+	for i := 0; i < 5; i++ {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+		chks := index.ChunkMetas{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(i)}}
+		records = append(records, testWALRecord("tenant1", ls, i, chks))
+		expected = append(expected, chunkMetasToChunkRefs("tenant1", ls.Hash(), chks)...)
+	}
+	writeTestWAL(t, mgr.dir, now, records...)
+
+	require.Nil(t, mgr.BuildFromWALs(context.Background(), now, []WALIdentifier{{ts: now}}))
+	require.Len(t, shipper.indices["index_0"], 1)
+
+	idx := shipper.indices["index_0"][0].(*TSDBFile)
+	refs, err := idx.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, TenantLabel, "tenant1"))
+	require.Nil(t, err)
+	require.ElementsMatch(t, expected, refs)
+	require.Equal(t, 5., testutil.ToFloat64(metrics.tsdbBuildSeries.WithLabelValues("tenant1")))
+	require.Equal(t, uint64(1), histogramSampleCount(t, metrics.walRecoveryDuration))
+}
+
+func Test_TSDBManager_BuildFromWALs_StreamingFailureCleansPartials(t *testing.T) {
+	mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{StreamingBatchSize: 1}, nil)
+
+	now := time.Now()
+	var records []*WALRecord
+	for i := 0; i < 3; i++ {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+		records = append(records, testWALRecord("tenant1", ls, i, index.ChunkMetas{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(i)}}))
+	}
+	// fails the replay after partials have been flushed
+	writeTestWAL(t, mgr.dir, now, append(records, corruptWALRecord)...)
+
+	require.Error(t, mgr.BuildFromWALs(context.Background(), now, []WALIdentifier{{ts: now}}))
+	require.Empty(t, shipper.indices)
+
+	partials, err := filepath.Glob(filepath.Join(managerScratchDir(mgr.dir), "*.partial"))
+	require.Nil(t, err)
+	require.Empty(t, partials)
+}
+
+func Test_TSDBManager_BuildFromWALs_QuarantineCorrupt(t *testing.T) {
+	now := time.Now()
+	writeWAL := func(t *testing.T, dir string) {
+		writeTestWAL(t, dir, now,
+			testWALRecord("tenant1", mustParseLabels(`{foo="bar"}`), 1, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 3}}),
+			corruptWALRecord,
+			// chunks for an unknown series
+			&WALRecord{
+				UserID: "tenant1",
+				Chks: ChunkMetasRecord{
+					Chks: index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 4}},
+					Ref:  2,
+				},
+			},
+		)
+	}
+
+	t.Run("fails without skipping", func(t *testing.T) {
+		mgr, _ := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{}, nil)
+		writeWAL(t, mgr.dir)
+		require.Error(t, mgr.BuildFromWALs(context.Background(), now, []WALIdentifier{{ts: now}}))
+	})
+
+	t.Run("skips and quarantines", func(t *testing.T) {
+		metrics := NewMetrics(nil)
+		mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{SkipCorruptWALs: true}, nil)
+		writeWAL(t, mgr.dir)
+		require.Nil(t, mgr.BuildFromWALs(context.Background(), now, []WALIdentifier{{ts: now}}))
+
+		// the healthy record is still indexed
+		require.Len(t, shipper.indices["index_0"], 1)
+		require.Equal(t, 2., testutil.ToFloat64(metrics.walCorruptRecords))
+		require.Equal(t, 1., testutil.ToFloat64(metrics.walQuarantines.WithLabelValues(statusSuccess)))
+
+		_, err := os.Stat(walPath(mgr.dir, now))
+		require.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(managerQuarantineDir(mgr.dir), filepath.Base(walPath(mgr.dir, now))))
+		require.Nil(t, err)
+
+		// quarantined wals survive scratch dir cleanup
+		require.Nil(t, cleanScratchDir(mgr.dir))
+		_, err = os.Stat(managerQuarantineDir(mgr.dir))
+		require.Nil(t, err)
+	})
+}
+
+func Test_TSDBManager_Stop(t *testing.T) {
+	mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{}, nil)
+
+	// leftover partial index from an interrupted build
+	staging := filepath.Join(managerScratchDir(mgr.dir), "index-1.staging")
+	require.Nil(t, os.WriteFile(staging, []byte("partial"), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Nil(t, mgr.Stop(ctx))
+	require.ErrorIs(t, mgr.ctx.Err(), context.Canceled)
+	require.True(t, shipper.stopped)
+
+	_, err := os.Stat(staging)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(managerQuarantineDir(mgr.dir))
+	require.Nil(t, err)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, NewMetrics(nil), log.NewNopLogger())
+	require.ErrorIs(t, mgr.BuildFromHead(context.Background(), heads), errManagerStopped)
+	require.ErrorIs(t, mgr.BuildFromWALs(context.Background(), time.Now(), nil), errManagerStopped)
+}
+
+func Test_TSDBManager_BuildFromWALs_Cancelled(t *testing.T) {
+	for _, streamingBatchSize := range []int{0, 1} {
+		t.Run(fmt.Sprintf("streaming-batch-%d", streamingBatchSize), func(t *testing.T) {
+			mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{StreamingBatchSize: streamingBatchSize}, nil)
+
+			now := time.Now()
+			writeTestWAL(t, mgr.dir, now, testWALRecord("tenant1", mustParseLabels(`{foo="bar"}`), 1, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 3}}))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			require.ErrorIs(t, mgr.BuildFromWALs(ctx, now, []WALIdentifier{{ts: now}}), context.Canceled)
+			require.Empty(t, shipper.indices)
+
+			// the WAL is untouched so the build can be resumed
+			_, err := os.Stat(walPath(mgr.dir, now))
+			require.Nil(t, err)
+		})
+	}
+}
+
+func Test_TSDBManager_PerTenantOutput(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{PerTenantOutput: true}, nil)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+	expected := map[string][]ChunkRef{}
+	for i, tenant := range []string{"tenant1", "tenant2"} {
+		ls := mustParseLabels(`{foo="bar"}`)
+		chks := index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}}
+		heads.Append(tenant, ls, ls.Hash(), chks)
+		expected[tenant] = chunkMetasToChunkRefs(tenant, ls.Hash(), chks)
+	}
+
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	require.Empty(t, shipper.indices["index_0"])
+
+	querier := newIndexShipperQuerier(shipper, testTableRanges())
+	for tenant, refs := range expected {
+		require.Len(t, shipper.indices["index_0/"+tenant], 1)
+
+		// per tenant tsdbs don't contain the tenant label
+		idx := shipper.indices["index_0/"+tenant][0].(*TSDBFile)
+		names, err := idx.LabelNames(context.Background(), tenant, 0, math.MaxInt64)
+		require.Nil(t, err)
+		require.Equal(t, []string{"foo"}, names)
+
+		res, err := querier.GetChunkRefs(context.Background(), tenant, 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+		require.Nil(t, err)
+		require.Equal(t, refs, res)
+	}
+
+	// leftover per tenant tsdbs are loaded on start
+	reloaded := newMockIndexShipper()
+	mgr.shipper = reloaded
+	require.Nil(t, mgr.Start())
+	for tenant := range expected {
+		require.Len(t, reloaded.indices["index_0/"+tenant], 1)
+	}
+}
+
+func Test_TSDBManager_OutputShards(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{Config: indexshipper.Config{IngesterDBRetainPeriod: time.Hour}, OutputShards: 4}, nil)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+	var expected []ChunkRef
+	for i := 0; i < 100; i++ {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+		chks := index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}}
+		heads.Append("tenant1", ls, ls.Hash(), chks)
+		expected = append(expected, chunkMetasToChunkRefs("tenant1", ls.Hash(), chks)...)
+	}
+
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	require.Len(t, shipper.indices["index_0"], 4)
+
+	// each shard only holds the series of its fingerprint range
+	for _, idx := range shipper.indices["index_0"] {
+		id, ok := parseMultitenantTSDBPath(idx.Name())
+		require.True(t, ok)
+		require.Equal(t, uint32(4), id.shard.Of)
+
+		refs, err := NewMultiTenantIndex(idx.(*TSDBFile)).GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+		require.Nil(t, err)
+		require.NotEmpty(t, refs)
+		for _, ref := range refs {
+			require.True(t, id.shard.Match(ref.Fingerprint))
+		}
+	}
+
+	querier := newIndexShipperQuerier(shipper, testTableRanges())
+	res, err := querier.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.Nil(t, err)
+	require.ElementsMatch(t, expected, res)
+
+	// sharded queries get the same results whether or not the tsdbs are sharded
+	for _, of := range []uint32{2, 4, 8} {
+		for i := uint32(0); i < of; i++ {
+			shard := index.NewShard(i, of)
+			var want []ChunkRef
+			for _, ref := range expected {
+				if shard.Match(ref.Fingerprint) {
+					want = append(want, ref)
+				}
+			}
+
+			res, err := querier.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, &shard, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+			require.Nil(t, err)
+			require.ElementsMatch(t, want, res, shard.String())
+
+			res, err = mgr.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, &shard, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+			require.Nil(t, err)
+			require.ElementsMatch(t, want, res, shard.String())
+		}
+	}
+
+	// leftover shards are loaded on start
+	reloaded := newMockIndexShipper()
+	mgr.shipper = reloaded
+	require.Nil(t, mgr.Start())
+	require.Len(t, reloaded.indices["index_0"], 4)
+}
+
+func Test_TSDBManager_TenantOverrides(t *testing.T) {
+	limits := fakeRetentionLimits{
+		outputShards:    map[string]int{"tenant2": 2},
+		perTenantOutput: map[string]bool{"tenant1": true},
+	}
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{Config: indexshipper.Config{IngesterDBRetainPeriod: time.Hour}}, limits)
+
+	build := func() {
+		heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+		for i := 0; i < 20; i++ {
+			ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+			for _, tenant := range []string{"tenant1", "tenant2"} {
+				heads.Append(tenant, ls, ls.Hash(), index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}})
+			}
+		}
+		require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	}
+
+	// tenant1 is built per tenant, tenant2 into 2 multitenant shards
+	build()
+	require.Len(t, shipper.indices["index_0/tenant1"], 1)
+	require.Len(t, shipper.indices["index_0"], 2)
+	for _, idx := range shipper.indices["index_0"] {
+		id, ok := parseMultitenantTSDBPath(idx.Name())
+		require.True(t, ok)
+		require.Equal(t, uint32(2), id.shard.Of)
+	}
+
+	// changes to the overrides apply from the next build on
+	delete(limits.perTenantOutput, "tenant1")
+	limits.outputShards["tenant2"] = 1
+	shipper = newMockIndexShipper()
+	mgr.shipper = shipper
+	build()
+	require.Empty(t, shipper.indices["index_0/tenant1"])
+	require.Len(t, shipper.indices["index_0"], 1)
+
+	querier := newIndexShipperQuerier(shipper, testTableRanges())
+	for _, tenant := range []string{"tenant1", "tenant2"} {
+		res, err := querier.GetChunkRefs(context.Background(), tenant, 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+		require.Nil(t, err)
+		require.Len(t, res, 20)
+	}
+}
+
+func Test_TSDBManager_Start_CorruptIndex(t *testing.T) {
+	for _, tc := range []struct {
+		action              string
+		keepWAL, quarantine bool
+	}{
+		{action: CorruptIndexActionDelete},
+		{action: CorruptIndexActionQuarantine, quarantine: true},
+		{action: CorruptIndexActionRebuild, keepWAL: true},
+		// falls back to quarantining when the wal no longer exists
+		{action: CorruptIndexActionRebuild, quarantine: true},
+	} {
+		t.Run(fmt.Sprintf("%s-wal-%v", tc.action, tc.keepWAL), func(t *testing.T) {
+			metrics := NewMetrics(nil)
+			mgr, _ := newTestTSDBManager(t, metrics, IndexCfg{CorruptIndexAction: tc.action}, nil)
+
+			now := time.Now()
+			heads := newTenantHeads(now, defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+			for i := 0; i < 10; i++ {
+				ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+				heads.Append("tenant1", ls, ls.Hash(), index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}})
+			}
+			require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+			if tc.keepWAL {
+				require.Nil(t, util.EnsureDirectory(walPath(mgr.dir, now)))
+			}
+
+			// truncate the index as if we crashed while writing it
+			rel := builtTSDBPath(t, mgr.dir, now)
+			p := filepath.Join(mgr.dir, rel)
+			fi, err := os.Stat(p)
+			require.Nil(t, err)
+			require.Nil(t, os.Truncate(p, fi.Size()/2))
+
+			shipper := newMockIndexShipper()
+			mgr.shipper = shipper
+			require.Nil(t, mgr.Start())
+			require.Empty(t, shipper.indices)
+			require.Equal(t, 1., testutil.ToFloat64(metrics.corruptIndices.WithLabelValues(tc.action, statusSuccess)))
+
+			_, err = os.Stat(p)
+			require.True(t, os.IsNotExist(err))
+			_, err = os.Stat(filepath.Join(managerQuarantineDir(mgr.dir), rel))
+			require.Equal(t, tc.quarantine, err == nil)
+		})
+	}
+}
+
+func Test_TSDBManager_PlanFromWALs(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{PerTenantOutput: true}, nil)
+
+	var (
+		ids []WALIdentifier
+		now = time.Now()
+	)
+	for i := 0; i < 3; i++ {
+		ts := now.Add(time.Duration(i-3) * time.Second)
+
+		// tenant1 is in every WAL with a stream repeated across them, tenant2 only in the last one
+		records := []*WALRecord{
+			testWALRecord("tenant1", mustParseLabels(`{foo="bar"}`), 0, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}}),
+			testWALRecord("tenant1", mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i)), 1, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 10}}),
+		}
+		if i == 2 {
+			records = append(records, testWALRecord("tenant2", mustParseLabels(`{foo="bar"}`), 0, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 0}}))
+		}
+		writeTestWAL(t, mgr.dir, ts, records...)
+		ids = append(ids, WALIdentifier{ts: ts})
+	}
+
+	plan, err := mgr.PlanFromWALs(context.Background(), ids)
+	require.Nil(t, err)
+	require.Len(t, plan, 2)
+	for i := range plan {
+		require.Greater(t, plan[i].EstimatedSize, int64(0))
+		plan[i].EstimatedSize = 0
+	}
+	require.Equal(t, []BuildPlan{
+		{Period: "index_0", Tenant: "tenant1", TSDBs: 3, Series: 4, Chunks: 6},
+		{Period: "index_0", Tenant: "tenant2", TSDBs: 1, Series: 1, Chunks: 1},
+	}, plan)
+
+	// nothing was built or shipped and the WALs are left alone
+	require.Empty(t, shipper.indices)
+	files, err := filepath.Glob(filepath.Join(managerPerTenantDir(mgr.dir), "*", "*", "*.tsdb"))
+	require.Nil(t, err)
+	require.Empty(t, files)
+	for _, id := range ids {
+		_, err := os.Stat(walPath(mgr.dir, id.ts))
+		require.Nil(t, err)
+	}
+
+	// the plan matches what's actually built
+	require.Nil(t, mgr.BuildFromWALs(context.Background(), now, ids))
+	require.Len(t, shipper.indices["index_0/tenant1"], 3)
+	require.Len(t, shipper.indices["index_0/tenant2"], 1)
+}
+
+func Test_TSDBManager_MinFreeDiskSpace(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{MinFreeDiskSpace: flagext.ByteSize(1 << 62)}, nil)
+
+	now := time.Now()
+	ls := mustParseLabels(`{foo="bar"}`)
+	writeTestWAL(t, mgr.dir, now, testWALRecord("tenant1", ls, 0, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 1}}))
+	ids := []WALIdentifier{{ts: now}}
+
+	// the build is refused, leaving the wal to be built later
+	require.ErrorIs(t, mgr.BuildFromWALs(context.Background(), now, ids), errInsufficientDiskSpace)
+	require.Empty(t, shipper.indices)
+	_, err := os.Stat(walPath(mgr.dir, now))
+	require.Nil(t, err)
+	require.Less(t, testutil.ToFloat64(metrics.tsdbBuildDiskHeadroom), 0.)
+
+	heads := newTenantHeads(now, defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+	heads.Append("tenant1", ls, ls.Hash(), index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: 1}})
+	require.ErrorIs(t, mgr.BuildFromHead(context.Background(), heads), errInsufficientDiskSpace)
+	require.Empty(t, shipper.indices)
+
+	mgr.cfg.MinFreeDiskSpace = 1
+	require.Nil(t, mgr.BuildFromWALs(context.Background(), now, ids))
+	require.Len(t, shipper.indices["index_0"], 1)
+	require.Greater(t, testutil.ToFloat64(metrics.tsdbBuildDiskHeadroom), 0.)
+}
+
+func Test_TSDBManager_BuildFromWALs_Compaction(t *testing.T) {
+	for _, tc := range []struct {
+		minMerge, expected int
+	}{
+		{minMerge: 0, expected: 3},
+		{minMerge: 2, expected: 1},
+		{minMerge: 3, expected: 1},
+		{minMerge: 4, expected: 3},
+	} {
+		t.Run(fmt.Sprintf("min-merge-%d", tc.minMerge), func(t *testing.T) {
+			metrics := NewMetrics(nil)
+			mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{CompactionMinMerge: tc.minMerge}, nil)
+
+			var (
+				ids      []WALIdentifier
+				expected []ChunkRef
+				now      = time.Now()
+			)
+			for i := 0; i < 3; i++ {
+				ts := now.Add(time.Duration(i-3) * time.Second)
+
+				// the same stream is written to every WAL along with a distinct one
+				var records []*WALRecord
+				for j, s := range []string{`{foo="bar"}`, fmt.Sprintf(`{foo="bar", i="%d"}`, i)} {
+					ls := mustParseLabels(s)
+					chks := index.ChunkMetas{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(2*i + j)}}
+					records = append(records, testWALRecord("tenant1", ls, j, chks))
+					expected = append(expected, chunkMetasToChunkRefs("tenant1", ls.Hash(), chks)...)
+				}
+				writeTestWAL(t, mgr.dir, ts, records...)
+				ids = append(ids, WALIdentifier{ts: ts})
+			}
+
+			require.Nil(t, mgr.BuildFromWALs(context.Background(), now, ids))
+			require.Len(t, shipper.indices["index_0"], tc.expected)
+
+			// merged away indices are removed from disk
+			files, err := filepath.Glob(filepath.Join(managerMultitenantDir(mgr.dir), "index_0", "*.tsdb"))
+			require.Nil(t, err)
+			require.Len(t, files, tc.expected)
+
+			var refs []ChunkRef
+			for _, idx := range shipper.indices["index_0"] {
+				res, err := idx.(*TSDBFile).GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, TenantLabel, "tenant1"))
+				require.Nil(t, err)
+				refs = append(refs, res...)
+			}
+			require.ElementsMatch(t, expected, refs)
+		})
+	}
+}
+
+// builtTSDBPath returns the path, relative to dir, of the only TSDB built for the first table, which must be
+// named after ts.
+func builtTSDBPath(t *testing.T, dir string, ts time.Time) string {
+	paths, err := filepath.Glob(filepath.Join(managerMultitenantDir(dir), "index_0", "*.tsdb"))
+	require.Nil(t, err)
+	require.Len(t, paths, 1)
+
+	id, ok := parseMultitenantTSDBPath(paths[0])
+	require.True(t, ok)
+	require.Equal(t, ts.Unix(), id.ts.Unix())
+
+	rel, err := filepath.Rel(dir, paths[0])
+	require.Nil(t, err)
+	return rel
+}
+
+func Test_TSDBManager_Start_CorruptMergedIndex(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		keepAll    bool
+		quarantine bool
+	}{
+		{name: "rebuilds when all source wals exist", keepAll: true},
+		{name: "quarantines when a source wal was truncated", quarantine: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := NewMetrics(nil)
+			mgr, _ := newTestTSDBManager(t, metrics, IndexCfg{CompactionMinMerge: 2, CorruptIndexAction: CorruptIndexActionRebuild}, nil)
+
+			now := time.Now()
+			var ids []WALIdentifier
+			for i := 0; i < 2; i++ {
+				ts := now.Add(time.Duration(i-2) * time.Second)
+				ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+				writeTestWAL(t, mgr.dir, ts, testWALRecord("tenant1", ls, 0, index.ChunkMetas{{MinTime: 1, MaxTime: 2, Checksum: uint32(i)}}))
+				ids = append(ids, WALIdentifier{ts: ts})
+			}
+			require.Nil(t, mgr.BuildFromWALs(context.Background(), now, ids))
+
+			// the merged index is named after the latest wal
+			rel := builtTSDBPath(t, mgr.dir, ids[1].ts)
+			p := filepath.Join(mgr.dir, rel)
+			_, err := os.Stat(indexSourcesPath(p))
+			require.Nil(t, err)
+
+			if !tc.keepAll {
+				require.Nil(t, os.RemoveAll(walPath(mgr.dir, ids[0].ts)))
+			}
+
+			fi, err := os.Stat(p)
+			require.Nil(t, err)
+			require.Nil(t, os.Truncate(p, fi.Size()/2))
+
+			shipper := newMockIndexShipper()
+			mgr.shipper = shipper
+			require.Nil(t, mgr.Start())
+			require.Empty(t, shipper.indices)
+
+			_, err = os.Stat(p)
+			require.True(t, os.IsNotExist(err))
+			_, err = os.Stat(indexSourcesPath(p))
+			require.True(t, os.IsNotExist(err))
+			_, err = os.Stat(filepath.Join(managerQuarantineDir(mgr.dir), rel))
+			require.Equal(t, tc.quarantine, err == nil)
+		})
+	}
+}
+
+func Test_TSDBManager_BuildFromHead_Compaction(t *testing.T) {
+	now := time.Now()
+	activeBucket := indexBuckets(model.TimeFromUnixNano(now.UnixNano()), model.TimeFromUnixNano(now.UnixNano()), testTableRanges())[0]
+
+	// builds a head with a single chunk in the bucket for chkTs
+	buildHead := func(t *testing.T, mgr *tsdbManager, headTs time.Time, chkTs model.Time, checksum uint32) ChunkRef {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, checksum))
+		chks := index.ChunkMetas{{MinTime: int64(chkTs), MaxTime: int64(chkTs), Checksum: checksum}}
+		heads := newTenantHeads(headTs, defaultHeadManagerStripeSize, mgr.metrics, log.NewNopLogger())
+		heads.Append("tenant1", ls, ls.Hash(), chks)
+		require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+		return chunkMetasToChunkRefs("tenant1", ls.Hash(), chks)[0]
+	}
+	getLocal := func(t *testing.T, mgr *tsdbManager) []ChunkRef {
+		refs, err := mgr.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+		require.Nil(t, err)
+		return refs
+	}
+
+	t.Run("merges head rotations in the active bucket", func(t *testing.T) {
+		metrics := NewMetrics(nil)
+		mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{Config: indexshipper.Config{IngesterDBRetainPeriod: time.Hour}, CompactionMinMerge: 2}, nil)
+		chkTs := model.TimeFromUnixNano(now.UnixNano())
+
+		first := buildHead(t, mgr, now.Add(-2*time.Second), chkTs, 1)
+		// held back from the shipper, but readable locally
+		require.Empty(t, shipper.indices)
+		require.Equal(t, []ChunkRef{first}, getLocal(t, mgr))
+
+		second := buildHead(t, mgr, now.Add(-time.Second), chkTs, 2)
+		require.Len(t, shipper.indices[activeBucket], 1)
+		require.Equal(t, 1., testutil.ToFloat64(metrics.preShipCompactions.WithLabelValues(statusSuccess)))
+		require.ElementsMatch(t, []ChunkRef{first, second}, getLocal(t, mgr))
+		require.Len(t, mgr.local.indices, 1)
+
+		files, err := filepath.Glob(filepath.Join(managerMultitenantDir(mgr.dir), activeBucket, "*.tsdb"))
+		require.Nil(t, err)
+		require.Len(t, files, 1)
+	})
+
+	t.Run("ships past buckets without waiting", func(t *testing.T) {
+		mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{CompactionMinMerge: 2}, nil)
+		buildHead(t, mgr, now, 1, 1)
+		require.Len(t, shipper.indices["index_0"], 1)
+	})
+
+	t.Run("ships pending on stop", func(t *testing.T) {
+		mgr, shipper := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{CompactionMinMerge: 2}, nil)
+		buildHead(t, mgr, now, model.TimeFromUnixNano(now.UnixNano()), 1)
+		require.Empty(t, shipper.indices)
+
+		require.Nil(t, mgr.Stop(context.Background()))
+		require.Len(t, shipper.indices[activeBucket], 1)
+	})
+}
+
+func Test_TSDBManager_BuildFromHead_RetentionAware(t *testing.T) {
+	now := model.Now()
+	limits := fakeRetentionLimits{
+		retention: map[string]time.Duration{
+			"tenant1": 2 * time.Hour,
+			"tenant3": 2 * time.Hour,
+			// tenant2 has no retention, nothing is pruned
+		},
+		retentionAwareBuild: map[string]bool{
+			"tenant3": true,
+		},
+		streamRetention: map[string][]validation.StreamRetention{
+			"tenant1": {
+				{Period: model.Duration(4 * time.Hour), Priority: 1, Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "foo", "long")}},
+			},
+		},
+	}
+
+	old := index.ChunkMeta{MinTime: int64(now.Add(-4 * time.Hour)), MaxTime: int64(now.Add(-3 * time.Hour)), Checksum: 1}
+	recent := index.ChunkMeta{MinTime: int64(now.Add(-time.Hour)), MaxTime: int64(now), Checksum: 2}
+	ancient := index.ChunkMeta{MinTime: int64(now.Add(-6 * time.Hour)), MaxTime: int64(now.Add(-5 * time.Hour)), Checksum: 3}
+
+	for _, tc := range []struct {
+		desc     string
+		enabled  bool
+		tenant   string
+		ls       string
+		expected index.ChunkMetas
+		pruned   float64
+	}{
+		{desc: "disabled", tenant: "tenant1", ls: `{foo="bar"}`, expected: index.ChunkMetas{ancient, old, recent}},
+		{desc: "tenant retention", enabled: true, tenant: "tenant1", ls: `{foo="bar"}`, expected: index.ChunkMetas{recent}, pruned: 2},
+		{desc: "stream retention", enabled: true, tenant: "tenant1", ls: `{foo="long"}`, expected: index.ChunkMetas{old, recent}, pruned: 1},
+		{desc: "no retention", enabled: true, tenant: "tenant2", ls: `{foo="bar"}`, expected: index.ChunkMetas{ancient, old, recent}},
+		{desc: "enabled by tenant override", tenant: "tenant3", ls: `{foo="bar"}`, expected: index.ChunkMetas{recent}, pruned: 2},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			metrics := NewMetrics(nil)
+			mgr, shipper := newTestTSDBManager(t, metrics, IndexCfg{RetentionAwareBuild: tc.enabled}, limits)
+
+			ls := mustParseLabels(tc.ls)
+			chks := index.ChunkMetas{ancient, old, recent}
+			heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+			heads.Append(tc.tenant, ls, ls.Hash(), chks)
+
+			require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+			// the chunks in the head are untouched
+			require.Equal(t, index.ChunkMetas{ancient, old, recent}, chks)
+
+			// chunks may span multiple period buckets depending on the current time
+			seen := map[ChunkRef]struct{}{}
+			var refs []ChunkRef
+			for _, idx := range shipper.indices {
+				require.Len(t, idx, 1)
+				res, err := idx[0].(*TSDBFile).GetChunkRefs(context.Background(), tc.tenant, 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, TenantLabel, tc.tenant))
+				require.Nil(t, err)
+				for _, ref := range res {
+					if _, ok := seen[ref]; !ok {
+						seen[ref] = struct{}{}
+						refs = append(refs, ref)
+					}
+				}
+			}
+			require.ElementsMatch(t, chunkMetasToChunkRefs(tc.tenant, ls.Hash(), tc.expected), refs)
+			require.Equal(t, tc.pruned, testutil.ToFloat64(metrics.tsdbBuildPrunedChunks.WithLabelValues(tc.tenant)))
+		})
+	}
+}
+
+func Test_TSDBManager_LocalReads(t *testing.T) {
+	mgr, _ := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{Config: indexshipper.Config{IngesterDBRetainPeriod: time.Hour}}, nil)
+
+	now := model.Now()
+	chk := index.ChunkMeta{MinTime: int64(now.Add(-time.Minute)), MaxTime: int64(now), Checksum: 1}
+	foo, bar := mustParseLabels(`{foo="bar"}`), mustParseLabels(`{bar="baz"}`)
+
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, NewMetrics(nil), log.NewNopLogger())
+	heads.Append("tenant1", foo, foo.Hash(), index.ChunkMetas{chk})
+	heads.Append("tenant2", bar, bar.Hash(), index.ChunkMetas{chk})
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+	refs, err := mgr.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.Nil(t, err)
+	require.Equal(t, chunkMetasToChunkRefs("tenant1", foo.Hash(), index.ChunkMetas{chk}), refs)
+
+	series, err := mgr.Series(context.Background(), "tenant2", 0, math.MaxInt64, nil, nil)
+	require.Nil(t, err)
+	require.Equal(t, []Series{{Labels: bar, Fingerprint: model.Fingerprint(bar.Hash())}}, series)
+
+	names, err := mgr.LabelNames(context.Background(), "tenant1", 0, math.MaxInt64)
+	require.Nil(t, err)
+	require.Equal(t, []string{"foo"}, names)
+
+	// queries outside of the built indices' bounds don't match anything
+	refs, err = mgr.GetChunkRefs(context.Background(), "tenant1", 0, now.Add(-time.Hour), nil, nil)
+	require.Nil(t, err)
+	require.Empty(t, refs)
+
+	// indices past the retain period are evicted
+	mgr.local.Lock()
+	mgr.evictLocalIndices(time.Now().Add(2 * time.Hour))
+	mgr.local.Unlock()
+	refs, err = mgr.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil)
+	require.Nil(t, err)
+	require.Empty(t, refs)
+}
+
+func Test_TSDBManager_RemoveShippedWALs(t *testing.T) {
+	metrics := NewMetrics(nil)
+	mgr, _ := newTestTSDBManager(t, metrics, IndexCfg{CompactionMinMerge: 2}, nil)
+
+	now := time.Now()
+	shipped, unbuilt, pending, recent := now.Add(-4*time.Hour), now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Hour)
+	buildHead := func(headTs time.Time, chkTs model.Time) {
+		ls := mustParseLabels(`{foo="bar"}`)
+		heads := newTenantHeads(headTs, defaultHeadManagerStripeSize, mgr.metrics, log.NewNopLogger())
+		heads.Append("tenant1", ls, ls.Hash(), index.ChunkMetas{{MinTime: int64(chkTs), MaxTime: int64(chkTs), Checksum: 1}})
+		require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	}
+
+	for _, ts := range []time.Time{shipped, unbuilt, pending, recent} {
+		ls := mustParseLabels(`{foo="bar"}`)
+		writeTestWAL(t, mgr.dir, ts, testWALRecord("tenant1", ls, 0, index.ChunkMetas{{MinTime: 1, MaxTime: 1, Checksum: 1}}))
+	}
+	// past buckets are shipped right away, the active one is held back to be merged
+	buildHead(shipped, 1)
+	buildHead(pending, model.TimeFromUnixNano(now.UnixNano()))
+	buildHead(recent, 1)
+
+	require.Nil(t, mgr.RemoveShippedWALs(now.Add(-90*time.Minute)))
+
+	wals, err := ListWALs(mgr.dir)
+	require.Nil(t, err)
+	var remaining []int64
+	for _, id := range wals {
+		remaining = append(remaining, id.Timestamp().Unix())
+	}
+	require.Equal(t, []int64{unbuilt.Unix(), pending.Unix(), recent.Unix()}, remaining)
+	require.Equal(t, 1., testutil.ToFloat64(metrics.walGCRemovals.WithLabelValues(statusSuccess)))
+	require.Greater(t, testutil.ToFloat64(metrics.walGCReclaimedBytes), 0.)
+
+	// the removed wal is forgotten, the recent one is collected once old enough
+	manifest, err := mgr.walManifest.read()
+	require.Nil(t, err)
+	require.Equal(t, map[int64]struct{}{recent.Unix(): {}}, manifest)
+
+	require.Nil(t, mgr.RemoveShippedWALs(now))
+	wals, err = ListWALs(mgr.dir)
+	require.Nil(t, err)
+	require.Len(t, wals, 2)
+}
+
+func Test_TSDBManager_DirectUpload(t *testing.T) {
+	metrics := NewMetrics(nil)
+	cfg := IndexCfg{Config: indexshipper.Config{IngesterDBRetainPeriod: time.Hour}, DirectUpload: true}
+	mgr, shipper := newTestTSDBManager(t, metrics, cfg, nil)
+
+	now := model.Now()
+	chk := index.ChunkMeta{MinTime: int64(now.Add(-time.Minute)), MaxTime: int64(now), Checksum: 1}
+	foo := mustParseLabels(`{foo="bar"}`)
+	build := func() {
+		heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, metrics, log.NewNopLogger())
+		heads.Append("tenant1", foo, foo.Hash(), index.ChunkMetas{chk})
+		require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+	}
+	bucket := indexBuckets(now, now, testTableRanges())[0]
+	localTSDBs := func() []string {
+		files, err := filepath.Glob(filepath.Join(managerMultitenantDir(mgr.dir), bucket, "*.tsdb"))
+		require.Nil(t, err)
+		return files
+	}
+
+	// the TSDB is uploaded from memory, without being written to the disk
+	build()
+	require.Len(t, shipper.indices[bucket], 1)
+	require.True(t, shipper_index.IsInMemory(shipper.indices[bucket][0]))
+	require.Empty(t, localTSDBs())
+	require.Equal(t, 1., testutil.ToFloat64(metrics.directUploads.WithLabelValues(statusSuccess)))
+
+	refs, err := shipper.indices[bucket][0].(*TSDBFile).GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.Nil(t, err)
+	require.Len(t, refs, 1)
+
+	// it's served by the local reads
+	refs, err = mgr.GetChunkRefs(context.Background(), "tenant1", 0, math.MaxInt64, nil, nil, labels.MustNewMatcher(labels.MatchEqual, "foo", "bar"))
+	require.Nil(t, err)
+	require.Equal(t, chunkMetasToChunkRefs("tenant1", foo.Hash(), index.ChunkMetas{chk}), refs)
+
+	// and listed along with the local TSDBs
+	tsdbs, err := mgr.LocalTSDBs()
+	require.Nil(t, err)
+	require.Len(t, tsdbs, 1)
+	require.True(t, tsdbs[0].InMemory)
+	require.Equal(t, bucket, tsdbs[0].Table)
+	require.Equal(t, shipper.indices[bucket][0].Name(), tsdbs[0].Name)
+	require.Equal(t, []LocalTSDBTenant{{Tenant: "tenant1", Series: 1, Chunks: 1}}, tsdbs[0].Tenants)
+
+	// TSDBs which fail to upload are written to the disk and shipped as usual
+	shipper.uploadErr = errors.New("upload failed")
+	build()
+	require.Len(t, shipper.indices[bucket], 2)
+	require.False(t, shipper_index.IsInMemory(shipper.indices[bucket][1]))
+	require.Len(t, localTSDBs(), 1)
+	require.Equal(t, 1., testutil.ToFloat64(metrics.directUploads.WithLabelValues(statusFailure)))
+}
+
+func Test_TSDBManager_LocalTSDBs(t *testing.T) {
+	limits := fakeRetentionLimits{perTenantOutput: map[string]bool{"tenant3": true}}
+	mgr, _ := newTestTSDBManager(t, NewMetrics(nil), IndexCfg{}, limits)
+
+	// nothing was built yet
+	require.Nil(t, os.RemoveAll(managerMultitenantDir(mgr.dir)))
+	tsdbs, err := mgr.LocalTSDBs()
+	require.Nil(t, err)
+	require.Empty(t, tsdbs)
+
+	chk := func(checksum uint32) index.ChunkMeta {
+		return index.ChunkMeta{MinTime: 1, MaxTime: 10, Checksum: checksum}
+	}
+	foo, bar := mustParseLabels(`{foo="bar"}`), mustParseLabels(`{bar="baz"}`)
+	heads := newTenantHeads(time.Now(), defaultHeadManagerStripeSize, NewMetrics(nil), log.NewNopLogger())
+	heads.Append("tenant1", foo, foo.Hash(), index.ChunkMetas{chk(1), {MinTime: 11, MaxTime: 20, Checksum: 2}})
+	heads.Append("tenant1", bar, bar.Hash(), index.ChunkMetas{chk(3)})
+	heads.Append("tenant2", foo, foo.Hash(), index.ChunkMetas{chk(4)})
+	heads.Append("tenant3", foo, foo.Hash(), index.ChunkMetas{chk(5)})
+	require.Nil(t, mgr.BuildFromHead(context.Background(), heads))
+
+	// a corrupt TSDB is reported as such
+	corrupt := MultitenantTSDBIdentifier{nodeName: "other", ts: time.Unix(0, 0)}
+	require.Nil(t, os.WriteFile(filepath.Join(managerMultitenantDir(mgr.dir), "index_0", corrupt.Name()), []byte("corrupt"), 0o644))
+
+	tsdbs, err = mgr.LocalTSDBs()
+	require.Nil(t, err)
+	require.Len(t, tsdbs, 3)
+
+	require.Equal(t, corrupt.Name(), tsdbs[0].Name)
+	require.NotEmpty(t, tsdbs[0].Error)
+	require.Empty(t, tsdbs[0].Tenants)
+
+	// the multitenant and the per tenant TSDBs are named differently, so their order depends on when they're built
+	multitenant, perTenant := tsdbs[1], tsdbs[2]
+	if len(multitenant.Tenants) == 1 {
+		multitenant, perTenant = perTenant, multitenant
+	}
+	require.Equal(t, "index_0", multitenant.Table)
+	require.Equal(t, []LocalTSDBTenant{
+		{Tenant: "tenant1", Series: 2, Chunks: 3},
+		{Tenant: "tenant2", Series: 1, Chunks: 1},
+	}, multitenant.Tenants)
+	require.Equal(t, model.Time(1), multitenant.From)
+	require.Equal(t, model.Time(20), multitenant.Through)
+
+	require.Equal(t, []LocalTSDBTenant{{Tenant: "tenant3", Series: 1, Chunks: 1}}, perTenant.Tenants)
+}
@@ -0,0 +1,175 @@
+package tsdb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/multierror"
+	"github.com/pkg/errors"
+)
+
+// managerWALManifestPath is where the manifest of the WALs whose TSDBs have all been shipped is stored.
+// It's outside of the dirs holding TSDBs, so it's never loaded or shipped.
+func managerWALManifestPath(parent string) string {
+	return filepath.Join(parent, "shipped_wals")
+}
+
+// walManifest records the timestamps of the WALs all TSDBs built from which have been shipped,
+// one unix timestamp per line. These WALs are no longer needed and can be garbage collected,
+// see tsdbManager.RemoveShippedWALs.
+type walManifest struct {
+	sync.Mutex
+	path string
+}
+
+func (w *walManifest) read() (map[int64]struct{}, error) {
+	b, err := os.ReadFile(w.path)
+	if os.IsNotExist(err) {
+		return map[int64]struct{}{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shipped := make(map[int64]struct{})
+	for _, line := range strings.Fields(string(b)) {
+		unix, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing wal manifest %s", w.path)
+		}
+		shipped[unix] = struct{}{}
+	}
+	return shipped, nil
+}
+
+// write replaces the manifest atomically, so that it's never left partially written.
+func (w *walManifest) write(shipped map[int64]struct{}) error {
+	sorted := make([]int64, 0, len(shipped))
+	for unix := range shipped {
+		sorted = append(sorted, unix)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var buf bytes.Buffer
+	for _, unix := range sorted {
+		fmt.Fprintf(&buf, "%d\n", unix)
+	}
+	tmp := w.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
+
+// add records the WALs with the given timestamps as shipped.
+func (w *walManifest) add(ts []time.Time) error {
+	if len(ts) == 0 {
+		return nil
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	shipped, err := w.read()
+	if err != nil {
+		return err
+	}
+	for _, t := range ts {
+		shipped[t.Unix()] = struct{}{}
+	}
+	return w.write(shipped)
+}
+
+// recordShippedWALs adds the WALs the shipped TSDBs were built from to the WAL manifest,
+// except for the ones other TSDBs still pending to be shipped were built from.
+func (m *tsdbManager) recordShippedWALs(shipped []builtIndex) {
+	m.pending.Lock()
+	pending := make(map[int64]struct{})
+	for _, group := range m.pending.indices {
+		for _, b := range group {
+			pending[b.ts.Unix()] = struct{}{}
+		}
+	}
+	m.pending.Unlock()
+
+	var ts []time.Time
+	for _, b := range shipped {
+		sources := b.sources
+		if sources == nil {
+			sources = []time.Time{b.ts}
+		}
+		for _, t := range sources {
+			if _, ok := pending[t.Unix()]; !ok {
+				ts = append(ts, t)
+			}
+		}
+	}
+
+	if err := m.walManifest.add(ts); err != nil {
+		level.Warn(m.log).Log("msg", "failed recording shipped wals, they won't be garbage collected", "err", err)
+	}
+}
+
+// RemoveShippedWALs removes the WALs created before the given time whose TSDBs have all been shipped,
+// which are left behind i.e. when truncating the WALs fails after a build. WALs which no longer exist
+// are forgotten from the manifest.
+func (m *tsdbManager) RemoveShippedWALs(before time.Time) error {
+	m.walManifest.Lock()
+	defer m.walManifest.Unlock()
+
+	shipped, err := m.walManifest.read()
+	if err != nil {
+		return err
+	}
+	wals, err := ListWALs(m.dir)
+	if err != nil {
+		return errors.Wrap(err, "listing wals")
+	}
+
+	var merr multierror.MultiError
+	existing := make(map[int64]struct{}, len(wals))
+	for _, id := range wals {
+		unix := id.Timestamp().Unix()
+		existing[unix] = struct{}{}
+		if _, ok := shipped[unix]; !ok || !id.Timestamp().Before(before) {
+			continue
+		}
+
+		path := walPath(m.dir, id.Timestamp())
+		size, err := dirSize(path)
+		if err != nil {
+			m.metrics.walGCRemovals.WithLabelValues(statusFailure).Inc()
+			merr.Add(errors.Wrapf(err, "sizing tsdb wal: %s", path))
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			m.metrics.walGCRemovals.WithLabelValues(statusFailure).Inc()
+			merr.Add(errors.Wrapf(err, "removing tsdb wal: %s", path))
+			continue
+		}
+
+		level.Info(m.log).Log("msg", "removed stale tsdb wal", "wal", path, "bytes", size)
+		m.metrics.walGCRemovals.WithLabelValues(statusSuccess).Inc()
+		m.metrics.walGCReclaimedBytes.Add(float64(size))
+		delete(existing, unix)
+	}
+
+	for unix := range shipped {
+		if _, ok := existing[unix]; !ok {
+			delete(shipped, unix)
+		}
+	}
+	if err := m.walManifest.write(shipped); err != nil {
+		merr.Add(errors.Wrap(err, "writing wal manifest"))
+	}
+
+	return merr.Err()
+}
@@ -0,0 +1,253 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.etcd.io/bbolt"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/retention"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/index/compactor"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+const (
+	// The boltdb-shipper index doesn't record the size of the chunks, so the migrated chunks get these estimates,
+	// which are only used for planning the query shards: half of the default target chunk size and 10k entries.
+	migratedChunkKB      = ((3 << 20) / 4) / 1024
+	migratedChunkEntries = 10000
+)
+
+// BoltDBMigrator converts the boltdb-shipper index of tables to TSDB, uploading one TSDB per tenant and table
+// like the compactor does, so that a period can be switched to the tsdb store without re-ingesting its logs.
+type BoltDBMigrator struct {
+	source, dest storage.Client
+	compression  string
+	workingDir   string
+	logger       log.Logger
+}
+
+// TableMigration describes what was migrated from a table.
+type TableMigration struct {
+	Tenants, SkippedTenants int
+	Series, Chunks          int
+}
+
+// NewBoltDBMigrator creates a BoltDBMigrator reading the boltdb-shipper index from source and uploading the TSDBs,
+// compressed with the given codec, to dest. The index files are downloaded and the TSDBs built in workingDir.
+func NewBoltDBMigrator(source, dest storage.Client, compression, workingDir string, logger log.Logger) *BoltDBMigrator {
+	if compression == "" {
+		compression = storage.CompressionGzip
+	}
+	return &BoltDBMigrator{
+		source:      source,
+		dest:        dest,
+		compression: compression,
+		workingDir:  workingDir,
+		logger:      logger,
+	}
+}
+
+// MigrateTable converts the boltdb-shipper index of the table, whose period is configured by periodConfig.
+// The series of all the tenants of the table are held in memory until their TSDBs are built.
+// Tenants which already have a TSDB in the table are skipped, so that an interrupted migration can be resumed.
+func (m *BoltDBMigrator) MigrateTable(ctx context.Context, tableName string, periodConfig config.PeriodConfig) (TableMigration, error) {
+	var res TableMigration
+	logger := log.With(m.logger, "table", tableName)
+
+	_, migratedTenants, err := m.dest.ListFiles(ctx, tableName, true)
+	if err != nil {
+		return res, err
+	}
+	migrated := make(map[string]struct{}, len(migratedTenants))
+	for _, tenant := range migratedTenants {
+		migrated[tenant] = struct{}{}
+	}
+
+	dir := filepath.Join(m.workingDir, tableName)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return res, err
+	}
+	defer os.RemoveAll(dir)
+
+	builders := map[string]*tsdb.Builder{}
+	skipped := map[string]struct{}{}
+	addChunk := func(entry retention.ChunkEntry) (bool, error) {
+		tenant := string(entry.UserID)
+		if _, ok := migrated[tenant]; ok {
+			skipped[tenant] = struct{}{}
+			return false, nil
+		}
+
+		chk, err := chunk.ParseExternalKey(tenant, string(entry.ChunkID))
+		if err != nil {
+			return false, err
+		}
+
+		builder, ok := builders[tenant]
+		if !ok {
+			builder = tsdb.NewBuilder()
+			builders[tenant] = builder
+		}
+
+		// TSDB doesn't need the __name__="logs" label the boltdb index stores with each series.
+		b := labels.NewBuilder(entry.Labels)
+		b.Del(labels.MetricName)
+		builder.AddSeries(b.Labels(nil), model.Fingerprint(chk.Fingerprint), []index.ChunkMeta{{
+			Checksum: chk.Checksum,
+			MinTime:  int64(chk.From),
+			MaxTime:  int64(chk.Through),
+			KB:       migratedChunkKB,
+			Entries:  migratedChunkEntries,
+		}})
+		res.Chunks++
+		return false, nil
+	}
+
+	files, tenants, err := m.source.ListFiles(ctx, tableName, true)
+	if err != nil {
+		return res, err
+	}
+	for _, file := range files {
+		if err := m.readFile(ctx, dir, file.Name, periodConfig, func() (io.ReadCloser, error) {
+			return m.source.GetFile(ctx, tableName, file.Name)
+		}, addChunk); err != nil {
+			return res, err
+		}
+	}
+	for _, tenant := range tenants {
+		if _, ok := migrated[tenant]; ok {
+			skipped[tenant] = struct{}{}
+			continue
+		}
+		files, err := m.source.ListUserFiles(ctx, tableName, tenant, true)
+		if err != nil {
+			return res, err
+		}
+		for _, file := range files {
+			if err := m.readFile(ctx, dir, file.Name, periodConfig, func() (io.ReadCloser, error) {
+				return m.source.GetUserFile(ctx, tableName, tenant, file.Name)
+			}, addChunk); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	tenants = tenants[:0]
+	for tenant := range builders {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		builder := builders[tenant]
+		series := builder.Series()
+		if err := m.uploadTSDB(ctx, dir, tableName, tenant, builder); err != nil {
+			return res, fmt.Errorf("migrating the index of tenant %s: %w", tenant, err)
+		}
+		// release the series of the tenant as soon as its TSDB is uploaded
+		delete(builders, tenant)
+
+		level.Info(logger).Log("msg", "migrated tenant index", "tenant", tenant, "series", series)
+		res.Tenants++
+		res.Series += series
+	}
+	res.SkippedTenants = len(skipped)
+
+	return res, nil
+}
+
+// readFile downloads the boltdb index file and calls fn for each chunk it indexes.
+func (m *BoltDBMigrator) readFile(ctx context.Context, dir, fileName string, periodConfig config.PeriodConfig, getFile storage.GetFileFunc, fn retention.ChunkEntryCallback) error {
+	path := filepath.Join(dir, storage.TrimCompressionExtension(fileName))
+	if err := storage.DownloadFileFromStorage(path, storage.CompressionForFile(fileName), false, storage.LoggerWithFilename(m.logger, fileName), getFile); err != nil {
+		return fmt.Errorf("downloading index file %s: %w", fileName, err)
+	}
+	defer os.Remove(path)
+
+	db, err := shipper_util.SafeOpenBoltdbFile(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		// the common index is in the index bucket while the per tenant index is in a bucket named after the tenant,
+		// the chunk entries of both embed the tenant.
+		return tx.ForEach(func(_ []byte, b *bbolt.Bucket) error {
+			return compactor.ForEachChunk(ctx, b, periodConfig, fn)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("reading index file %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// uploadTSDB builds the TSDB of the tenant and uploads it to the table, compressed.
+func (m *BoltDBMigrator) uploadTSDB(ctx context.Context, dir, tableName, tenant string, builder *tsdb.Builder) error {
+	tenantDir := filepath.Join(dir, "tsdb", tenant)
+	id, err := builder.Build(ctx, tenantDir, func(from, through model.Time, checksum uint32) tsdb.Identifier {
+		return tenantIdentifier{
+			SingleTenantTSDBIdentifier: tsdb.SingleTenantTSDBIdentifier{
+				TS:       time.Now(),
+				From:     from,
+				Through:  through,
+				Checksum: checksum,
+			},
+			dir: tenantDir,
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer os.Remove(id.Path())
+
+	f, err := os.Open(id.Path())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pool := chunkenc.WriterPool(&chunkenc.Gzip)
+	if m.compression == storage.CompressionZstd {
+		pool = &chunkenc.Zstd
+	}
+	var buf bytes.Buffer
+	w := pool.GetWriter(&buf)
+	defer pool.PutWriter(w)
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return m.dest.PutUserFile(ctx, tableName, tenant, id.Name()+storage.CompressionExtension(m.compression), bytes.NewReader(buf.Bytes()))
+}
+
+// tenantIdentifier places the TSDB of a tenant in the directory it is built in.
+type tenantIdentifier struct {
+	tsdb.SingleTenantTSDBIdentifier
+	dir string
+}
+
+func (i tenantIdentifier) Path() string {
+	return filepath.Join(i.dir, i.SingleTenantTSDBIdentifier.Path())
+}
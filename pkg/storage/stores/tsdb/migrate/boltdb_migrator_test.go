@@ -0,0 +1,178 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/chunkenc"
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/pkg/storage/config"
+	"github.com/grafana/loki/pkg/storage/stores/indexshipper/storage"
+	series_index "github.com/grafana/loki/pkg/storage/stores/series/index"
+	shipper_util "github.com/grafana/loki/pkg/storage/stores/shipper/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb"
+)
+
+func TestBoltDBMigrator_MigrateTable(t *testing.T) {
+	periodConfig := config.PeriodConfig{
+		From:       config.DayTime{Time: model.TimeFromUnix(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Unix())},
+		IndexType:  config.BoltDBShipperType,
+		ObjectType: config.StorageTypeFileSystem,
+		Schema:     "v12",
+		IndexTables: config.PeriodicTableConfig{
+			Prefix: "index_",
+			Period: 24 * time.Hour,
+		},
+		RowShards: 16,
+	}
+	schemaConfig := config.SchemaConfig{Configs: []config.PeriodConfig{periodConfig}}
+	tableName := periodConfig.IndexTables.TableFor(periodConfig.From.Time)
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	source := storage.NewIndexStorageClient(objectClient, "index/")
+	dest := storage.NewIndexStorageClient(objectClient, "tsdb_index/")
+
+	type testChunk struct {
+		tenant string
+		ls     labels.Labels
+		ref    logproto.ChunkRef
+	}
+	from := periodConfig.From.Time
+	newChunk := func(tenant, ls string, from, through model.Time, checksum uint32) testChunk {
+		lbls, err := syntax.ParseLabels(ls)
+		require.NoError(t, err)
+		return testChunk{
+			tenant: tenant,
+			ls:     lbls,
+			ref: logproto.ChunkRef{
+				Fingerprint: lbls.Hash(),
+				UserID:      tenant,
+				From:        from,
+				Through:     through,
+				Checksum:    checksum,
+			},
+		}
+	}
+	chunks := []testChunk{
+		newChunk("tenant1", `{foo="bar"}`, from, from.Add(time.Hour), 1),
+		newChunk("tenant1", `{foo="bar"}`, from.Add(time.Hour), from.Add(2*time.Hour), 2),
+		newChunk("tenant1", `{foo="baz", app="a"}`, from, from.Add(time.Hour), 3),
+		newChunk("tenant2", `{foo="bar"}`, from, from.Add(time.Hour), 4),
+		newChunk("tenant3", `{foo="bar"}`, from, from.Add(time.Hour), 5),
+	}
+
+	// write the chunks to a boltdb index file the way the series store does
+	schema, err := series_index.CreateSchema(periodConfig)
+	require.NoError(t, err)
+	batch := local.NewWriteBatch()
+	for _, c := range chunks {
+		b := labels.NewBuilder(c.ls)
+		b.Set(labels.MetricName, "logs")
+		metric := b.Labels(nil)
+		chunkID := schemaConfig.ExternalKey(c.ref)
+
+		_, labelEntries, err := schema.GetCacheKeysAndLabelWriteEntries(c.ref.From, c.ref.Through, c.tenant, "logs", metric, chunkID)
+		require.NoError(t, err)
+		chunkEntries, err := schema.GetChunkWriteEntries(c.ref.From, c.ref.Through, c.tenant, "logs", metric, chunkID)
+		require.NoError(t, err)
+		for _, entries := range append(labelEntries, chunkEntries) {
+			for _, e := range entries {
+				batch.Add(tableName, e.HashValue, e.RangeValue, e.Value)
+			}
+		}
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "ingester-1")
+	db, err := shipper_util.SafeOpenBoltdbFile(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, local.WriteToDB(context.Background(), db, local.IndexBucketName, batch.(*local.BoltWriteBatch).Writes[tableName]))
+	require.NoError(t, db.Close())
+
+	var compressed bytes.Buffer
+	w := chunkenc.Gzip.GetWriter(&compressed)
+	data, err := os.ReadFile(dbPath)
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, source.PutFile(context.Background(), tableName, "ingester-1.gz", bytes.NewReader(compressed.Bytes())))
+
+	// tenant3 was already migrated
+	require.NoError(t, dest.PutUserFile(context.Background(), tableName, "tenant3", "migrated.tsdb.gz", bytes.NewReader(nil)))
+
+	migrator := NewBoltDBMigrator(source, dest, storage.CompressionGzip, t.TempDir(), log.NewNopLogger())
+	res, err := migrator.MigrateTable(context.Background(), tableName, periodConfig)
+	require.NoError(t, err)
+	require.Equal(t, TableMigration{Tenants: 2, SkippedTenants: 1, Series: 3, Chunks: 4}, res)
+
+	readTSDB := func(tenant string) map[string][]tsdb.ChunkRef {
+		files, err := dest.ListUserFiles(context.Background(), tableName, tenant, true)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		require.True(t, strings.HasSuffix(files[0].Name, ".tsdb.gz"))
+
+		path := filepath.Join(t.TempDir(), storage.TrimCompressionExtension(files[0].Name))
+		require.NoError(t, storage.DownloadFileFromStorage(path, storage.CompressionForFile(files[0].Name), false, log.NewNopLogger(), func() (io.ReadCloser, error) {
+			return dest.GetUserFile(context.Background(), tableName, tenant, files[0].Name)
+		}))
+		idx, _, err := tsdb.NewTSDBIndexFromFile(path)
+		require.NoError(t, err)
+		defer idx.Close()
+
+		matcher := labels.MustNewMatcher(labels.MatchEqual, "", "")
+		series, err := idx.Series(context.Background(), tenant, 0, math.MaxInt64, nil, nil, matcher)
+		require.NoError(t, err)
+		byFP := map[model.Fingerprint]string{}
+		for _, s := range series {
+			// the fingerprint must be the one of the chunks for them to be fetched
+			require.Equal(t, model.Fingerprint(s.Labels.Hash()), s.Fingerprint)
+			byFP[s.Fingerprint] = s.Labels.String()
+		}
+
+		refs, err := idx.GetChunkRefs(context.Background(), tenant, 0, math.MaxInt64, nil, nil, matcher)
+		require.NoError(t, err)
+		res := map[string][]tsdb.ChunkRef{}
+		for _, ref := range refs {
+			ls := byFP[ref.Fingerprint]
+			res[ls] = append(res[ls], ref)
+		}
+		return res
+	}
+	ref := func(c testChunk) tsdb.ChunkRef {
+		return tsdb.ChunkRef{
+			User:        c.tenant,
+			Fingerprint: model.Fingerprint(c.ref.Fingerprint),
+			Start:       c.ref.From,
+			End:         c.ref.Through,
+			Checksum:    c.ref.Checksum,
+		}
+	}
+
+	require.Equal(t, map[string][]tsdb.ChunkRef{
+		`{foo="bar"}`:          {ref(chunks[0]), ref(chunks[1])},
+		`{app="a", foo="baz"}`: {ref(chunks[2])},
+	}, readTSDB("tenant1"))
+	require.Equal(t, map[string][]tsdb.ChunkRef{
+		`{foo="bar"}`: {ref(chunks[3])},
+	}, readTSDB("tenant2"))
+
+	// migrating the table again is a noop
+	res, err = migrator.MigrateTable(context.Background(), tableName, periodConfig)
+	require.NoError(t, err)
+	require.Equal(t, TableMigration{SkippedTenants: 3}, res)
+}
@@ -0,0 +1,214 @@
+package tsdb
+
+import (
+	"container/list"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	index_shipper "github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+const (
+	postingsCacheHit  = "hit"
+	postingsCacheMiss = "miss"
+
+	// estimated size of a cache entry besides its key and series refs
+	postingsCacheEntryOverhead = 128
+)
+
+type postingsCacheKey struct {
+	// path of the TSDB, which holds the name of its table
+	path     string
+	shard    string
+	matchers string
+}
+
+type postingsCacheEntry struct {
+	key  postingsCacheKey
+	refs []storage.SeriesRef
+	size int64
+}
+
+// postingsCache caches the series matched by the matchers of the queries on the TSDBs downloaded by the index shipper,
+// so that the postings of the matchers repeated across queries, i.e. {cluster="prod"}, are resolved once per TSDB.
+// It is shared by all the downloaded TSDBs and bounded by the size of the cached series refs, evicting the least
+// recently used ones. The downloaded TSDBs are immutable, so their entries are only invalidated when they are closed
+// after being removed from their table by a refresh.
+type postingsCache struct {
+	maxSize int64
+
+	mtx     sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[postingsCacheKey]*list.Element
+	byPath  map[string]map[postingsCacheKey]struct{}
+
+	requests  *prometheus.CounterVec
+	evictions prometheus.Counter
+	sizeBytes prometheus.Gauge
+}
+
+func newPostingsCache(maxSize int64, r prometheus.Registerer) *postingsCache {
+	return &postingsCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: map[postingsCacheKey]*list.Element{},
+		byPath:  map[string]map[postingsCacheKey]struct{}{},
+		requests: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "postings_cache_requests_total",
+			Help:      "Total number of postings resolved for the matchers of queries on downloaded TSDBs partitioned by whether they were cached",
+		}, []string{"result"}),
+		evictions: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_tsdb",
+			Name:      "postings_cache_evictions_total",
+			Help:      "Total number of postings evicted from the cache to keep it under its maximum size",
+		}),
+		sizeBytes: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_tsdb",
+			Name:      "postings_cache_size_bytes",
+			Help:      "Estimated size of the postings cached for the downloaded TSDBs",
+		}),
+	}
+}
+
+// openShippableTSDBFunc returns the function opening the TSDBs downloaded by the index shipper, which resolves their
+// postings through the cache, if any.
+func openShippableTSDBFunc(cache *postingsCache) index_shipper.OpenIndexFileFunc {
+	if cache == nil {
+		return OpenShippableTSDB
+	}
+
+	return func(p string) (index_shipper.Index, error) {
+		id, err := identifierFromPath(p)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := NewShippableTSDBFile(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx, ok := f.Index.(*TSDBIndex); ok {
+			idx.postingsCache = cache
+			idx.postingsCacheKey = p
+		}
+		return f, nil
+	}
+}
+
+// PostingsForMatchers returns the postings of the matchers in the TSDB at the given path, resolving them with
+// PostingsForMatchers if they aren't cached yet.
+func (c *postingsCache) PostingsForMatchers(path string, ix IndexReader, shard *index.ShardAnnotation, ms ...*labels.Matcher) (index.Postings, error) {
+	key := postingsCacheKey{path: path, matchers: matchersKey(ms)}
+	if shard != nil {
+		key.shard = shard.String()
+	}
+
+	if refs, ok := c.get(key); ok {
+		c.requests.WithLabelValues(postingsCacheHit).Inc()
+		return index.NewListPostings(refs), nil
+	}
+	c.requests.WithLabelValues(postingsCacheMiss).Inc()
+
+	p, err := PostingsForMatchers(ix, shard, ms...)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := index.ExpandPostings(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(key, refs)
+	return index.NewListPostings(refs), nil
+}
+
+func (c *postingsCache) get(key postingsCacheKey) ([]storage.SeriesRef, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*postingsCacheEntry).refs, true
+}
+
+func (c *postingsCache) put(key postingsCacheKey, refs []storage.SeriesRef) {
+	entry := &postingsCacheEntry{
+		key:  key,
+		refs: refs,
+		size: int64(len(key.path)+len(key.shard)+len(key.matchers)+8*len(refs)) + postingsCacheEntryOverhead,
+	}
+	// don't let a single entry flush the whole cache.
+	if entry.size > c.maxSize {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// the postings may have been resolved by a concurrent query.
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	keys, ok := c.byPath[key.path]
+	if !ok {
+		keys = map[postingsCacheKey]struct{}{}
+		c.byPath[key.path] = keys
+	}
+	keys[key] = struct{}{}
+	c.size += entry.size
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+		c.evictions.Inc()
+	}
+	c.sizeBytes.Set(float64(c.size))
+}
+
+// Invalidate drops the postings cached for the TSDB at the given path.
+func (c *postingsCache) Invalidate(path string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for key := range c.byPath[path] {
+		c.remove(c.entries[key])
+	}
+	c.sizeBytes.Set(float64(c.size))
+}
+
+// remove must be called with the lock held.
+func (c *postingsCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*postingsCacheEntry)
+	delete(c.entries, entry.key)
+	if keys, ok := c.byPath[entry.key.path]; ok {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.byPath, entry.key.path)
+		}
+	}
+	c.size -= entry.size
+}
+
+// matchersKey returns a key identifying the set of matchers, whatever their order.
+func matchersKey(ms []*labels.Matcher) string {
+	strs := make([]string, 0, len(ms))
+	for _, m := range ms {
+		strs = append(strs, m.String())
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,99 @@
+package tsdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+func TestPostingsCache(t *testing.T) {
+	cases := []LoadableSeries{
+		{
+			Labels: mustParseLabels(`{foo="bar", cluster="prod"}`),
+			Chunks: []index.ChunkMeta{{MinTime: 0, MaxTime: 3, Checksum: 0}},
+		},
+		{
+			Labels: mustParseLabels(`{foo="bar", cluster="dev"}`),
+			Chunks: []index.ChunkMeta{{MinTime: 1, MaxTime: 4, Checksum: 1}},
+		},
+		{
+			Labels: mustParseLabels(`{foo="baz", cluster="prod"}`),
+			Chunks: []index.ChunkMeta{{MinTime: 2, MaxTime: 5, Checksum: 2}},
+		},
+	}
+
+	cache := newPostingsCache(1<<20, nil)
+	f := BuildIndex(t, t.TempDir(), cases)
+	f.Index.(*TSDBIndex).postingsCache = cache
+	f.Index.(*TSDBIndex).postingsCacheKey = f.Path()
+
+	uncached := BuildIndex(t, t.TempDir(), cases)
+	defer uncached.Close()
+
+	query := func(idx Index, ms ...*labels.Matcher) []ChunkRef {
+		refs, err := idx.GetChunkRefs(context.Background(), "fake", 0, 10, nil, nil, ms...)
+		require.NoError(t, err)
+		return refs
+	}
+
+	prod := labels.MustNewMatcher(labels.MatchEqual, "cluster", "prod")
+	bar := labels.MustNewMatcher(labels.MatchRegexp, "foo", "ba.")
+
+	expected := query(uncached, prod, bar)
+	require.Len(t, expected, 2)
+	require.Equal(t, expected, query(f, prod, bar))
+	// the order of the matchers doesn't matter.
+	require.Equal(t, expected, query(f, bar, prod))
+	require.Len(t, query(f, prod), 2)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(cache.requests.WithLabelValues(postingsCacheMiss)))
+	require.Equal(t, float64(1), testutil.ToFloat64(cache.requests.WithLabelValues(postingsCacheHit)))
+	require.Len(t, cache.entries, 2)
+
+	// the entries of the TSDB are dropped when it's closed.
+	require.NoError(t, f.Close())
+	require.Len(t, cache.entries, 0)
+	require.Len(t, cache.byPath, 0)
+	require.Equal(t, int64(0), cache.size)
+}
+
+func TestPostingsCache_Eviction(t *testing.T) {
+	refs := []storage.SeriesRef{1, 2, 3, 4}
+	key := func(path, matchers string) postingsCacheKey {
+		return postingsCacheKey{path: path, matchers: matchers}
+	}
+	entrySize := int64(len("a")+len(`{foo="bar"}`)+8*len(refs)) + postingsCacheEntryOverhead
+
+	cache := newPostingsCache(2*entrySize, nil)
+	cache.put(key("a", `{foo="bar"}`), refs)
+	cache.put(key("a", `{foo="baz"}`), refs)
+
+	// using the first entry makes the second one the least recently used.
+	_, ok := cache.get(key("a", `{foo="bar"}`))
+	require.True(t, ok)
+
+	cache.put(key("b", `{foo="bar"}`), refs)
+	require.Equal(t, float64(1), testutil.ToFloat64(cache.evictions))
+	_, ok = cache.get(key("a", `{foo="baz"}`))
+	require.False(t, ok)
+	_, ok = cache.get(key("a", `{foo="bar"}`))
+	require.True(t, ok)
+	_, ok = cache.get(key("b", `{foo="bar"}`))
+	require.True(t, ok)
+	require.Equal(t, 2*entrySize, cache.size)
+
+	// entries larger than the cache aren't cached.
+	cache.put(key("c", `{foo="bar"}`), make([]storage.SeriesRef, 1000))
+	_, ok = cache.get(key("c", `{foo="bar"}`))
+	require.False(t, ok)
+
+	cache.Invalidate("a")
+	require.Len(t, cache.entries, 1)
+	require.Equal(t, entrySize, cache.size)
+}
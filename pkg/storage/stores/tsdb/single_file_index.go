@@ -1,6 +1,7 @@
 package tsdb
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/pkg/storage/chunk"
+	"github.com/grafana/loki/pkg/storage/stores/index/seriesvolume"
 	index_shipper "github.com/grafana/loki/pkg/storage/stores/indexshipper/index"
 	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
 )
@@ -36,6 +38,9 @@ type TSDBFile struct {
 
 	// to sastisfy Reader() and Close() methods
 	getRawFileReader GetRawFileReaderFunc
+
+	// set when the index was built in memory and never written to its path
+	inMemory bool
 }
 
 func NewShippableTSDBFile(id Identifier) (*TSDBFile, error) {
@@ -51,7 +56,33 @@ func NewShippableTSDBFile(id Identifier) (*TSDBFile, error) {
 	}, err
 }
 
+// NewInMemoryTSDBFile returns a TSDBFile reading the index built in memory, see Builder.BuildTo, which is shipped
+// without being written to the disk.
+func NewInMemoryTSDBFile(id Identifier, data []byte) (*TSDBFile, error) {
+	reader, err := index.NewReader(index.RealByteSlice(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TSDBFile{
+		Identifier: id,
+		Index:      NewTSDBIndex(reader),
+		getRawFileReader: func() (io.ReadSeeker, error) {
+			return bytes.NewReader(data), nil
+		},
+		inMemory: true,
+	}, nil
+}
+
+// InMemory implements indexshipper/index.InMemoryIndex.
+func (f *TSDBFile) InMemory() bool {
+	return f.inMemory
+}
+
 func (f *TSDBFile) Close() error {
+	if idx, ok := f.Index.(*TSDBIndex); ok && idx.postingsCache != nil {
+		idx.postingsCache.Invalidate(idx.postingsCacheKey)
+	}
 	return f.Index.Close()
 }
 
@@ -66,6 +97,10 @@ func (f *TSDBFile) Reader() (io.ReadSeeker, error) {
 type TSDBIndex struct {
 	reader      IndexReader
 	chunkFilter chunk.RequestChunkFilterer
+
+	// postingsCache, if set, caches the postings of the matchers under postingsCacheKey.
+	postingsCache    *postingsCache
+	postingsCacheKey string
 }
 
 // Return the index as well as the underlying raw file reader which isn't exposed as an index
@@ -108,7 +143,15 @@ func (i *TSDBIndex) forSeries(
 	fn func(labels.Labels, model.Fingerprint, []index.ChunkMeta),
 	matchers ...*labels.Matcher,
 ) error {
-	p, err := PostingsForMatchers(i.reader, shard, matchers...)
+	var (
+		p   index.Postings
+		err error
+	)
+	if i.postingsCache != nil {
+		p, err = i.postingsCache.PostingsForMatchers(i.postingsCacheKey, i.reader, shard, matchers...)
+	} else {
+		p, err = PostingsForMatchers(i.reader, shard, matchers...)
+	}
 	if err != nil {
 		return err
 	}
@@ -253,3 +296,23 @@ func (i *TSDBIndex) Stats(ctx context.Context, userID string, from, through mode
 
 	return nil
 }
+
+func (i *TSDBIndex) Volume(ctx context.Context, userID string, from, through model.Time, acc VolumeAccumulator, shard *index.ShardAnnotation, shouldIncludeChunk shouldIncludeChunk, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) error {
+	targets := seriesvolume.TargetLabels(targetLabels, matchers)
+	return i.forSeries(ctx, shard,
+		func(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) {
+			var size uint64
+			for _, chk := range chks {
+				if shouldIncludeChunk(chk) {
+					size += uint64(chk.KB) << 10
+				}
+			}
+			if size == 0 {
+				return
+			}
+			seriesvolume.Names(ls, aggregateBy, targets, TenantLabel, func(name string) {
+				acc.AddVolume(name, size)
+			})
+		},
+		matchers...)
+}
@@ -2,9 +2,11 @@ package tsdb
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
@@ -19,9 +21,96 @@ import (
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/downloads"
 	tsdb_index "github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+	"github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
+	"github.com/grafana/loki/pkg/validation"
 )
 
+type IndexCfg struct {
+	indexshipper.Config `yaml:",inline"`
+	BuildConcurrency    int              `yaml:"tsdb_build_concurrency"`
+	StreamingBatchSize  int              `yaml:"tsdb_streaming_build_batch_size"`
+	SkipCorruptWALs     bool             `yaml:"tsdb_skip_corrupt_wals"`
+	PerTenantOutput     bool             `yaml:"tsdb_per_tenant_output"`
+	CorruptIndexAction  string           `yaml:"tsdb_corrupt_index_action"`
+	CompactionMinMerge  int              `yaml:"tsdb_compaction_min_merge"`
+	RetentionAwareBuild bool             `yaml:"tsdb_retention_aware_build"`
+	OutputShards        int              `yaml:"tsdb_output_shards"`
+	MinFreeDiskSpace    flagext.ByteSize `yaml:"tsdb_min_free_disk_space"`
+	WALGCMinRotations   int              `yaml:"tsdb_wal_gc_min_rotations"`
+	PostingsCacheSize   flagext.ByteSize `yaml:"tsdb_postings_cache_size"`
+	DirectUpload        bool             `yaml:"tsdb_direct_upload"`
+	HeadMaxWALSize      flagext.ByteSize `yaml:"tsdb_head_max_wal_size"`
+	HeadMaxSeries       int              `yaml:"tsdb_head_max_series"`
+}
+
+// Limits are the per tenant limits used by the tsdb store
+type Limits interface {
+	downloads.Limits
+	RetentionPeriod(userID string) time.Duration
+	StreamRetention(userID string) []validation.StreamRetention
+	// Per tenant overrides of the build settings of IndexCfg, re-read on every build.
+	TSDBOutputShards(userID string) int
+	TSDBPerTenantOutput(userID string) bool
+	TSDBRetentionAwareBuild(userID string) bool
+}
+
+const (
+	CorruptIndexActionDelete     = "delete"
+	CorruptIndexActionQuarantine = "quarantine"
+	CorruptIndexActionRebuild    = "rebuild"
+)
+
+func (cfg *IndexCfg) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.Config.RegisterFlagsWithPrefix(prefix, f)
+	f.IntVar(&cfg.BuildConcurrency, prefix+"shipper.build-concurrency", 1, "Maximum number of index period TSDBs to build and ship concurrently when rotating heads or replaying WALs.")
+	f.IntVar(&cfg.StreamingBatchSize, prefix+"shipper.streaming-build-batch-size", 0, "When greater than 0, TSDBs are built in a streaming fashion, flushing a partial index to the scratch directory every time this many series have been accumulated for a period. This bounds memory usage during head builds and WAL replay at the cost of additional disk IO. 0 disables streaming builds.")
+	f.BoolVar(&cfg.SkipCorruptWALs, prefix+"shipper.skip-corrupt-wals", false, "Skip corrupt records when replaying TSDB WALs instead of failing the build. WALs which could only be partially recovered are moved to a quarantine directory under the scratch directory.")
+	f.BoolVar(&cfg.PerTenantOutput, prefix+"shipper.per-tenant-output", false, "Build one TSDB per tenant per index period instead of a single multitenant TSDB per period. Per tenant TSDBs are shipped under per tenant prefixes, making per tenant retention and deletion cheaper at the cost of more index files.")
+	f.StringVar(&cfg.CorruptIndexAction, prefix+"shipper.corrupt-index-action", CorruptIndexActionQuarantine, "What to do with leftover local TSDBs which fail verification on startup. Supported values: delete, quarantine (move to the quarantine directory under the scratch directory), rebuild (delete and rebuild from the WAL if it still exists, quarantine otherwise).")
+	f.IntVar(&cfg.CompactionMinMerge, prefix+"shipper.compaction-min-merge", 0, "Minimum number of TSDBs built for the same index period, during a single WAL replay or over consecutive head rotations, before they are merged into one TSDB prior to shipping. TSDBs built from heads are held back from shipping until this many accumulate or their index period ends. Reduces the number of small TSDBs queries have to open. 0 disables pre-ship compaction.")
+	f.BoolVar(&cfg.RetentionAwareBuild, prefix+"shipper.retention-aware-build", false, "Skip chunk refs which are already past their tenant's or stream's retention period when building TSDBs. Should only be enabled when retention is enabled in the compactor.")
+	f.IntVar(&cfg.OutputShards, prefix+"shipper.output-shards", 0, "When greater than 1, the TSDB built for each index period is split by series fingerprint into this many shards, which must be a power of 2. Sharded queries only fetch the TSDB shards overlapping with the query shard. 0 or 1 disables sharding.")
+	f.Var(&cfg.MinFreeDiskSpace, prefix+"shipper.min-free-disk-space", "Minimum free disk space, i.e. 1GB, to leave in the active index directory after the space estimated for a TSDB build from the size of its WALs. Builds which would exceed it are refused, leaving their WALs to be built later, instead of filling the disk and leaving partial indices behind. 0 disables the check.")
+	f.IntVar(&cfg.WALGCMinRotations, prefix+"shipper.wal-gc-min-rotations", 0, "When greater than 0, WALs left behind at least this many head rotations ago, whose TSDBs have all been shipped, are removed. Such WALs are left behind when truncating them fails after a build. 0 disables the WAL garbage collection.")
+	f.BoolVar(&cfg.DirectUpload, prefix+"shipper.direct-upload", false, "Build the TSDBs in memory when rotating heads or replaying WALs and upload them right away, instead of writing them to the scratch directory and shipping them on the next upload. Halves the disk IO of the builds, at the cost of holding the built TSDBs in memory while they are retained for queries. TSDBs which fail to upload are written to the disk and shipped as usual. Not supported with streaming builds or pre-ship compaction.")
+	f.Var(&cfg.HeadMaxWALSize, prefix+"shipper.head-max-wal-size", "Maximum size, i.e. 512MB, of the WAL of the active head, above which the head is rotated and its TSDBs built before the end of its 15m period, so that bursty tenants don't produce huge TSDBs. It's checked every minute. 0 disables it.")
+	f.IntVar(&cfg.HeadMaxSeries, prefix+"shipper.head-max-series", 0, "Maximum number of series across all the tenants of the active head, above which the head is rotated and its TSDBs built before the end of its 15m period. It's checked every minute. 0 disables it.")
+	f.Var(&cfg.PostingsCacheSize, prefix+"shipper.postings-cache-size", "Maximum size, i.e. 100MB, of the in-memory cache of the series matched by the matchers of queries on the downloaded TSDBs, shared across queries by queriers and index gateways so that repeated matchers are resolved once per TSDB. The least recently used entries are evicted above it, and the entries of a TSDB are dropped when it's removed from its table. 0 disables the cache.")
+}
+
+func (cfg *IndexCfg) Validate() error {
+	if cfg.BuildConcurrency < 1 {
+		return fmt.Errorf("tsdb_build_concurrency must be greater than 0, got %d", cfg.BuildConcurrency)
+	}
+	if cfg.CompactionMinMerge < 0 || cfg.CompactionMinMerge == 1 {
+		return fmt.Errorf("tsdb_compaction_min_merge must be 0 (disabled) or at least 2, got %d", cfg.CompactionMinMerge)
+	}
+	if cfg.DirectUpload && (cfg.StreamingBatchSize > 0 || cfg.CompactionMinMerge > 0) {
+		return errors.New("tsdb_direct_upload can't be enabled along with tsdb_streaming_build_batch_size or tsdb_compaction_min_merge, which build the TSDBs on disk")
+	}
+	if cfg.HeadMaxSeries < 0 {
+		return fmt.Errorf("tsdb_head_max_series must not be negative, got %d", cfg.HeadMaxSeries)
+	}
+	if cfg.WALGCMinRotations < 0 {
+		return fmt.Errorf("tsdb_wal_gc_min_rotations must not be negative, got %d", cfg.WALGCMinRotations)
+	}
+	if cfg.OutputShards < 0 {
+		return fmt.Errorf("tsdb_output_shards must not be negative, got %d", cfg.OutputShards)
+	}
+	if cfg.OutputShards > 1 {
+		if err := tsdb_index.NewShard(0, uint32(cfg.OutputShards)).Validate(); err != nil {
+			return errors.Wrap(err, "invalid tsdb_output_shards")
+		}
+	}
+	switch cfg.CorruptIndexAction {
+	case CorruptIndexActionDelete, CorruptIndexActionQuarantine, CorruptIndexActionRebuild:
+	default:
+		return fmt.Errorf("invalid tsdb_corrupt_index_action: %q", cfg.CorruptIndexAction)
+	}
+	return cfg.Config.Validate()
+}
+
 type IndexWriter interface {
 	Append(userID string, ls labels.Labels, fprint uint64, chks tsdb_index.ChunkMetas) error
 }
@@ -47,11 +136,11 @@ func ResetStoreInstance() {
 }
 
 type newStoreFactoryFunc func(
-	indexShipperCfg indexshipper.Config,
+	indexShipperCfg IndexCfg,
 	p config.PeriodConfig,
 	f *fetcher.Fetcher,
 	objectClient client.ObjectClient,
-	limits downloads.Limits,
+	limits Limits,
 	tableRanges config.TableRanges,
 	backupIndexWriter index.Writer,
 	reg prometheus.Registerer,
@@ -71,11 +160,11 @@ type newStoreFactoryFunc func(
 // running multiple head managers would be complicated and wasteful.
 var NewStore = func() newStoreFactoryFunc {
 	return func(
-		indexShipperCfg indexshipper.Config,
+		indexShipperCfg IndexCfg,
 		p config.PeriodConfig,
 		f *fetcher.Fetcher,
 		objectClient client.ObjectClient,
-		limits downloads.Limits,
+		limits Limits,
 		tableRanges config.TableRanges,
 		backupIndexWriter index.Writer,
 		reg prometheus.Registerer,
@@ -95,22 +184,32 @@ var NewStore = func() newStoreFactoryFunc {
 			if err != nil {
 				return nil, nil, err
 			}
+		} else {
+			// the index shipper owns the replica clients, the one already running has its own
+			for _, replica := range indexShipperCfg.ReplicaObjectClients {
+				replica.Stop()
+			}
 		}
 
 		return storeInstance, storeInstance.Stop, nil
 	}
 }()
 
-func (s *store) init(indexShipperCfg indexshipper.Config, objectClient client.ObjectClient,
-	limits downloads.Limits, tableRanges config.TableRanges, reg prometheus.Registerer) error {
+func (s *store) init(indexShipperCfg IndexCfg, objectClient client.ObjectClient,
+	limits Limits, tableRanges config.TableRanges, reg prometheus.Registerer) error {
+
+	var postingsCache *postingsCache
+	if indexShipperCfg.PostingsCacheSize > 0 && indexShipperCfg.Mode != indexshipper.ModeWriteOnly {
+		postingsCache = newPostingsCache(int64(indexShipperCfg.PostingsCacheSize), reg)
+	}
 
 	var err error
 	s.indexShipper, err = indexshipper.NewIndexShipper(
-		indexShipperCfg,
+		indexShipperCfg.Config,
 		objectClient,
 		limits,
 		nil,
-		OpenShippableTSDB,
+		openShippableTSDBFunc(postingsCache),
 		tableRanges,
 		prometheus.WrapRegistererWithPrefix("loki_tsdb_shipper_", reg),
 	)
@@ -144,6 +243,8 @@ func (s *store) init(indexShipperCfg indexshipper.Config, objectClient client.Ob
 			dir,
 			s.indexShipper,
 			tableRanges,
+			indexShipperCfg,
+			limits,
 			util_log.Logger,
 			tsdbMetrics,
 		)
@@ -154,6 +255,9 @@ func (s *store) init(indexShipperCfg indexshipper.Config, objectClient client.Ob
 			tsdbMetrics,
 			tsdbManager,
 		)
+		headManager.walGCMinRotations = indexShipperCfg.WALGCMinRotations
+		headManager.maxWALSize = int64(indexShipperCfg.HeadMaxWALSize)
+		headManager.maxSeries = indexShipperCfg.HeadMaxSeries
 		if err := headManager.Start(); err != nil {
 			return err
 		}
@@ -170,11 +274,17 @@ func (s *store) init(indexShipperCfg indexshipper.Config, objectClient client.Ob
 		return err
 	}
 
-	s.Reader = NewIndexClient(multiIndex, opts)
+	s.Reader = NewIndexClient(multiIndex, opts, tableRanges)
 
 	return nil
 }
 
+// HeadManager returns the head manager writing the index of the store, nil if the store doesn't write the index.
+func (s *store) HeadManager() *HeadManager {
+	hm, _ := s.indexWriter.(*HeadManager)
+	return hm
+}
+
 func (s *store) Stop() {
 	s.stopOnce.Do(func() {
 		if hm, ok := s.indexWriter.(*HeadManager); ok {
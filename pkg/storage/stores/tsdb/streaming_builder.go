@@ -0,0 +1,325 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/multierror"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+
+	chunk_util "github.com/grafana/loki/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+// partialIdentifier is used for the intermediate TSDBs written by the StreamingBuilder
+type partialIdentifier string
+
+func (p partialIdentifier) Name() string { return string(p) }
+func (p partialIdentifier) Path() string { return string(p) }
+
+// StreamingBuilder bounds the memory used to build a tsdb index.
+// Rather than holding every series in memory until `Build()`, it flushes
+// the accumulated series to a partial TSDB in the scratch directory every
+// `batchSize` series. On `Build()`, the partial TSDBs are merged into the
+// final index, streaming series from each partial in order.
+// Like the Builder, it accepts streams in any order and multiple writes
+// for the same stream, even across batches.
+type StreamingBuilder struct {
+	ctx        context.Context
+	scratchDir string
+	batchSize  int
+	logger     log.Logger
+
+	cur      *Builder
+	partials []string
+	err      error
+	// nil unless the symbols are shared with other builders, see symbolPool
+	symbols *symbolPool
+}
+
+// NewStreamingBuilder creates a StreamingBuilder which flushes partial indices to scratchDir.
+// ctx is used for flushes triggered by `AddSeries()`.
+// A batchSize <= 0 disables flushing, making it equivalent to a Builder.
+func NewStreamingBuilder(ctx context.Context, scratchDir string, batchSize int, logger log.Logger) *StreamingBuilder {
+	return newStreamingBuilder(ctx, scratchDir, batchSize, logger, nil)
+}
+
+func newStreamingBuilder(ctx context.Context, scratchDir string, batchSize int, logger log.Logger, symbols *symbolPool) *StreamingBuilder {
+	return &StreamingBuilder{
+		ctx:        ctx,
+		scratchDir: scratchDir,
+		batchSize:  batchSize,
+		logger:     logger,
+		cur:        newBuilder(symbols),
+		symbols:    symbols,
+	}
+}
+
+// AddSeries adds the series to the current batch, flushing it if it's full.
+// Flush errors are deferred until `Build()`.
+func (b *StreamingBuilder) AddSeries(ls labels.Labels, fp model.Fingerprint, chks []index.ChunkMeta) {
+	if b.err != nil {
+		return
+	}
+
+	b.cur.AddSeries(ls, fp, chks)
+	if b.batchSize > 0 && len(b.cur.streams) >= b.batchSize {
+		b.err = b.flush(b.ctx)
+	}
+}
+
+func (b *StreamingBuilder) flush(ctx context.Context) error {
+	if len(b.cur.streams) == 0 {
+		return nil
+	}
+
+	name := fmt.Sprintf("%s-%x.partial", index.IndexFilename, rand.Int63())
+	dst := partialIdentifier(filepath.Join(b.scratchDir, name))
+	if _, err := b.cur.Build(ctx, b.scratchDir, func(_, _ model.Time, _ uint32) Identifier {
+		return dst
+	}); err != nil {
+		return err
+	}
+
+	b.partials = append(b.partials, dst.Path())
+	b.cur = newBuilder(b.symbols)
+	return nil
+}
+
+// Build writes the final index, merging any partial indices which have been flushed.
+// Partial indices are removed afterwards, regardless of the outcome.
+// Callers which end up not calling Build must call Cleanup instead.
+func (b *StreamingBuilder) Build(
+	ctx context.Context,
+	scratchDir string,
+	createFn func(from, through model.Time, checksum uint32) Identifier,
+) (id Identifier, err error) {
+	defer func() {
+		// the index has been built regardless, leftover partials are removed on the next start
+		if err := b.Cleanup(); err != nil {
+			level.Warn(b.logger).Log("msg", "failed removing partial tsdb indices", "err", err)
+		}
+	}()
+
+	if b.err != nil {
+		return id, b.err
+	}
+
+	// nothing was flushed, build directly from memory
+	if len(b.partials) == 0 {
+		return b.cur.Build(ctx, scratchDir, createFn)
+	}
+
+	if err := b.flush(ctx); err != nil {
+		return id, err
+	}
+
+	return mergeIndices(ctx, scratchDir, b.partials, createFn)
+}
+
+// Cleanup removes the partial indices flushed so far.
+func (b *StreamingBuilder) Cleanup() error {
+	var errs multierror.MultiError
+	for _, p := range b.partials {
+		if err := os.RemoveAll(p); err != nil {
+			errs.Add(err)
+		}
+	}
+	b.partials = nil
+	return errs.Err()
+}
+
+// mergeIndices merges the series from the tsdb indices at the given paths into a single index.
+// Chunks belonging to the same series in different indices are combined.
+func mergeIndices(
+	ctx context.Context,
+	scratchDir string,
+	paths []string,
+	createFn func(from, through model.Time, checksum uint32) Identifier,
+) (id Identifier, err error) {
+	readers := make([]*index.Reader, 0, len(paths))
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+
+	for _, p := range paths {
+		r, err := index.NewFileReader(p)
+		if err != nil {
+			return id, err
+		}
+		readers = append(readers, r)
+	}
+
+	if scratchDir != "" {
+		if err := chunk_util.EnsureDirectory(scratchDir); err != nil {
+			return id, err
+		}
+	}
+
+	name := fmt.Sprintf("%s-%x.staging", index.IndexFilename, rand.Int63())
+	tmpPath := filepath.Join(scratchDir, name)
+
+	writer, err := index.NewWriter(ctx, tmpPath)
+	if err != nil {
+		return id, err
+	}
+
+	if err := mergeSymbols(writer, readers); err != nil {
+		return id, err
+	}
+
+	if err := mergeSeries(writer, readers); err != nil {
+		return id, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return id, err
+	}
+
+	return moveToIdentifier(tmpPath, createFn)
+}
+
+// mergeSymbols adds the sorted, deduplicated union of all readers' symbols.
+func mergeSymbols(writer *index.Writer, readers []*index.Reader) error {
+	iters := make([]index.StringIter, 0, len(readers))
+	for _, r := range readers {
+		it := r.Symbols()
+		if it.Next() {
+			iters = append(iters, it)
+		} else if err := it.Err(); err != nil {
+			return err
+		}
+	}
+
+	var (
+		last    string
+		written bool
+	)
+	for len(iters) > 0 {
+		minIdx := 0
+		for i := 1; i < len(iters); i++ {
+			if iters[i].At() < iters[minIdx].At() {
+				minIdx = i
+			}
+		}
+
+		sym := iters[minIdx].At()
+		if !written || sym != last {
+			if err := writer.AddSymbol(sym); err != nil {
+				return err
+			}
+			last, written = sym, true
+		}
+
+		if !iters[minIdx].Next() {
+			if err := iters[minIdx].Err(); err != nil {
+				return err
+			}
+			iters = append(iters[:minIdx], iters[minIdx+1:]...)
+		}
+	}
+
+	return nil
+}
+
+// seriesCursor iterates the series of a single index in order.
+type seriesCursor struct {
+	reader   *index.Reader
+	postings index.Postings
+
+	ls   labels.Labels
+	fp   model.Fingerprint
+	chks []index.ChunkMeta
+}
+
+func (c *seriesCursor) next() (bool, error) {
+	if !c.postings.Next() {
+		return false, c.postings.Err()
+	}
+
+	c.ls, c.chks = nil, nil
+	fp, err := c.reader.Series(c.postings.At(), &c.ls, &c.chks)
+	if err != nil {
+		return false, err
+	}
+	c.fp = model.Fingerprint(fp)
+	return true, nil
+}
+
+func (c *seriesCursor) less(other *seriesCursor) bool {
+	if c.fp != other.fp {
+		return c.fp < other.fp
+	}
+	return labels.Compare(c.ls, other.ls) < 0
+}
+
+// mergeSeries writes the series of all readers in (fingerprint, labels) order.
+// Each index is already sorted this way, so this is a k-way merge.
+func mergeSeries(writer *index.Writer, readers []*index.Reader) error {
+	cursors := make([]*seriesCursor, 0, len(readers))
+	for _, r := range readers {
+		name, value := index.AllPostingsKey()
+		ps, err := r.Postings(name, nil, value)
+		if err != nil {
+			return err
+		}
+
+		c := &seriesCursor{reader: r, postings: ps}
+		ok, err := c.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			cursors = append(cursors, c)
+		}
+	}
+
+	var ref int
+	for len(cursors) > 0 {
+		minIdx := 0
+		for i := 1; i < len(cursors); i++ {
+			if cursors[i].less(cursors[minIdx]) {
+				minIdx = i
+			}
+		}
+
+		var (
+			ls   = cursors[minIdx].ls
+			fp   = cursors[minIdx].fp
+			chks index.ChunkMetas
+		)
+
+		// collect the chunks for this series from every index containing it
+		remaining := cursors[:0]
+		for _, c := range cursors {
+			if c.fp == fp && labels.Equal(c.ls, ls) {
+				chks = append(chks, c.chks...)
+				ok, err := c.next()
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+			}
+			remaining = append(remaining, c)
+		}
+
+		if err := writer.AddSeries(storage.SeriesRef(ref), ls, fp, chks.Finalize()...); err != nil {
+			return err
+		}
+		ref++
+		cursors = remaining
+	}
+
+	return nil
+}
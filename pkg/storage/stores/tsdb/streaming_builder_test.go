@@ -0,0 +1,174 @@
+package tsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/storage/stores/tsdb/index"
+)
+
+func readAllSeries(t *testing.T, path string) (res []Series, chks map[model.Fingerprint][]index.ChunkMeta) {
+	idx, _, err := NewTSDBIndexFromFile(path)
+	require.Nil(t, err)
+	defer idx.Close()
+
+	chks = make(map[model.Fingerprint][]index.ChunkMeta)
+	require.Nil(t, idx.forSeries(context.Background(), nil, func(ls labels.Labels, fp model.Fingerprint, xs []index.ChunkMeta) {
+		res = append(res, Series{Labels: ls.Copy(), Fingerprint: fp})
+		chks[fp] = append([]index.ChunkMeta(nil), xs...)
+	}, labels.MustNewMatcher(labels.MatchEqual, "", "")))
+	return res, chks
+}
+
+func TestStreamingBuilder(t *testing.T) {
+	type entry struct {
+		ls   labels.Labels
+		chks []index.ChunkMeta
+	}
+
+	var entries []entry
+	for i := 0; i < 20; i++ {
+		ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i%7))
+		entries = append(entries, entry{
+			ls: ls,
+			chks: []index.ChunkMeta{
+				{MinTime: int64(i), MaxTime: int64(i + 10), Checksum: uint32(i)},
+				// duplicated across writes, should be deduped
+				{MinTime: 0, MaxTime: 1, Checksum: 0},
+			},
+		})
+	}
+
+	build := func(t *testing.T, b indexBuilder, dir string) string {
+		for _, e := range entries {
+			b.AddSeries(e.ls, model.Fingerprint(e.ls.Hash()), e.chks)
+		}
+		dst := filepath.Join(dir, "index.tsdb")
+		_, err := b.Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) Identifier {
+			return partialIdentifier(dst)
+		})
+		require.Nil(t, err)
+		return dst
+	}
+
+	expectedSeries, expectedChks := readAllSeries(t, build(t, NewBuilder(), t.TempDir()))
+	require.Len(t, expectedSeries, 7)
+
+	for _, batchSize := range []int{0, 1, 2, 5, 100} {
+		t.Run(fmt.Sprintf("batch-%d", batchSize), func(t *testing.T) {
+			dir := t.TempDir()
+			scratch := filepath.Join(dir, "scratch")
+			b := NewStreamingBuilder(context.Background(), scratch, batchSize, log.NewNopLogger())
+
+			series, chks := readAllSeries(t, build(t, b, dir))
+			require.Equal(t, expectedSeries, series)
+			require.Equal(t, expectedChks, chks)
+
+			// partials are removed after building
+			files, err := os.ReadDir(scratch)
+			require.Nil(t, err)
+			require.Empty(t, files)
+		})
+	}
+}
+
+func TestBuilder_DedupesChunks(t *testing.T) {
+	ls := mustParseLabels(`{foo="bar"}`)
+	fp := model.Fingerprint(ls.Hash())
+	chks := []index.ChunkMeta{
+		{MinTime: 0, MaxTime: 1, Checksum: 1},
+		{MinTime: 1, MaxTime: 2, Checksum: 2},
+	}
+
+	b := NewBuilder()
+	// the same chunks replayed from multiple WALs
+	b.AddSeries(ls, fp, chks)
+	b.AddSeries(ls, fp, chks[1:])
+	b.AddSeries(ls, fp, append(chks, index.ChunkMeta{MinTime: 1, MaxTime: 2, Checksum: 3}))
+
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "index.tsdb")
+	_, err := b.Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) Identifier {
+		return partialIdentifier(dst)
+	})
+	require.Nil(t, err)
+
+	_, built := readAllSeries(t, dst)
+	require.Equal(t, map[model.Fingerprint][]index.ChunkMeta{
+		fp: append(chks, index.ChunkMeta{MinTime: 1, MaxTime: 2, Checksum: 3}),
+	}, built)
+
+	// building again yields an identical index
+	b.AddSeries(ls, fp, chks)
+	dst2 := filepath.Join(dir, "index2.tsdb")
+	_, err = b.Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) Identifier {
+		return partialIdentifier(dst2)
+	})
+	require.Nil(t, err)
+	_, rebuilt := readAllSeries(t, dst2)
+	require.Equal(t, built, rebuilt)
+}
+
+func TestBuilder_SharedSymbols(t *testing.T) {
+	build := func(t *testing.T, b indexBuilder, dir string, from, through int) []byte {
+		for i := from; i < through; i++ {
+			ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d", period="%d"}`, i, from))
+			b.AddSeries(ls, model.Fingerprint(ls.Hash()), []index.ChunkMeta{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(i)}})
+		}
+		dst := filepath.Join(dir, "index.tsdb")
+		_, err := b.Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) Identifier {
+			return partialIdentifier(dst)
+		})
+		require.Nil(t, err)
+
+		data, err := os.ReadFile(dst)
+		require.Nil(t, err)
+		return data
+	}
+
+	// the builders of two periods with overlapping symbols yield the same indices as without sharing them.
+	symbols := newSymbolPool()
+	require.Equal(t, build(t, NewBuilder(), t.TempDir(), 0, 5), build(t, newBuilder(symbols), t.TempDir(), 0, 5))
+	require.Equal(t, build(t, NewBuilder(), t.TempDir(), 3, 8), build(t, newBuilder(symbols), t.TempDir(), 3, 8))
+	// foo, bar, i, period, 0-7
+	require.Len(t, symbols.symbols, 12)
+
+	// so do streaming builders flushing partial indices.
+	b := newStreamingBuilder(context.Background(), filepath.Join(t.TempDir(), "scratch"), 2, log.NewNopLogger(), symbols)
+	require.Equal(t, build(t, NewBuilder(), t.TempDir(), 2, 9), build(t, b, t.TempDir(), 2, 9))
+	require.Len(t, symbols.symbols, 13)
+}
+
+func TestBuilder_BuildTo(t *testing.T) {
+	newTestBuilder := func() *Builder {
+		b := NewBuilder()
+		for i := 0; i < 10; i++ {
+			ls := mustParseLabels(fmt.Sprintf(`{foo="bar", i="%d"}`, i))
+			b.AddSeries(ls, model.Fingerprint(ls.Hash()), []index.ChunkMeta{{MinTime: int64(i), MaxTime: int64(i + 1), Checksum: uint32(i)}})
+		}
+		return b
+	}
+
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "index.tsdb")
+	_, err := newTestBuilder().Build(context.Background(), filepath.Join(dir, "scratch"), func(_, _ model.Time, _ uint32) Identifier {
+		return partialIdentifier(dst)
+	})
+	require.Nil(t, err)
+	expected, err := os.ReadFile(dst)
+	require.Nil(t, err)
+
+	// the index built in memory is the same as the one built through the scratch dir.
+	var buf bytes.Buffer
+	require.Nil(t, newTestBuilder().BuildTo(context.Background(), &buf))
+	require.Equal(t, expected, buf.Bytes())
+}
@@ -0,0 +1,98 @@
+package tsdb
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// symbolPool interns the label names and values of the series added to the builders sharing it, i.e. the builders
+// of all the index periods of a build. Label names and values repeat heavily across series and periods, so interning
+// them keeps a single copy of each in memory, and the symbols are only sorted once for all the builders instead of
+// once per period.
+type symbolPool struct {
+	mtx     sync.Mutex
+	symbols map[string]string
+	// sorted symbols and their positions, reset when new symbols get interned.
+	sorted    []string
+	positions map[string]int
+}
+
+func newSymbolPool() *symbolPool {
+	return &symbolPool{symbols: map[string]string{}}
+}
+
+// internLabels returns a copy of ls referencing the interned names and values.
+func (p *symbolPool) internLabels(ls labels.Labels) labels.Labels {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	res := make(labels.Labels, len(ls))
+	for i, l := range ls {
+		res[i] = labels.Label{Name: p.intern(l.Name), Value: p.intern(l.Value)}
+	}
+	return res
+}
+
+// intern must be called with the lock held.
+func (p *symbolPool) intern(s string) string {
+	if interned, ok := p.symbols[s]; ok {
+		return interned
+	}
+	p.symbols[s] = s
+	p.sorted, p.positions = nil, nil
+	return s
+}
+
+// sortedSymbols returns the sorted symbols of the streams. It returns false if some of them weren't interned.
+func (p *symbolPool) sortedSymbols(streams []*stream) ([]string, bool) {
+	sorted, positions := p.snapshot()
+
+	used := make([]bool, len(sorted))
+	n := 0
+	for _, s := range streams {
+		for _, l := range s.labels {
+			for _, symbol := range [2]string{l.Name, l.Value} {
+				i, ok := positions[symbol]
+				if !ok {
+					return nil, false
+				}
+				if !used[i] {
+					used[i] = true
+					n++
+				}
+			}
+		}
+	}
+
+	res := make([]string, 0, n)
+	for i, symbol := range sorted {
+		if used[i] {
+			res = append(res, symbol)
+		}
+	}
+	return res, true
+}
+
+// snapshot returns the sorted symbols and their positions, sorting them if new symbols got interned since the
+// previous call. The returned values are never modified, so that concurrent builds can use them.
+func (p *symbolPool) snapshot() ([]string, map[string]int) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.sorted == nil {
+		sorted := make([]string, 0, len(p.symbols))
+		for s := range p.symbols {
+			sorted = append(sorted, s)
+		}
+		sort.Strings(sorted)
+
+		positions := make(map[string]int, len(sorted))
+		for i, s := range sorted {
+			positions[s] = i
+		}
+		p.sorted, p.positions = sorted, positions
+	}
+	return p.sorted, p.positions
+}
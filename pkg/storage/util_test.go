@@ -259,6 +259,10 @@ func (m *mockChunkStore) Stats(ctx context.Context, userID string, from, through
 	return nil, nil
 }
 
+func (m *mockChunkStore) Volume(ctx context.Context, userID string, from, through model.Time, limit int32, targetLabels []string, aggregateBy string, matchers ...*labels.Matcher) (*logproto.VolumeResponse, error) {
+	return nil, nil
+}
+
 type mockChunkStoreClient struct {
 	chunks []chunk.Chunk
 	scfg   config.SchemaConfig
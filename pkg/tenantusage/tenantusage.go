@@ -0,0 +1,283 @@
+// Package tenantusage meters the resources consumed by each tenant, the bytes ingested by the distributors and the
+// bytes processed by the queries of the query frontends, and periodically writes snapshots of the usage to the object
+// storage for chargeback.
+package tenantusage
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/services"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/storage/chunk/client"
+)
+
+// Kinds of usage.
+const (
+	IngestedBytes = "ingested_bytes"
+	IngestedLines = "ingested_lines"
+	QueryBytes    = "query_bytes_processed"
+	Queries       = "queries"
+)
+
+// maxTrackedDimensions bounds the number of distinct dimension values tracked per tenant between two snapshots.
+// The usage of the values above the limit is accounted without dimensions, so that the totals are still correct.
+const maxTrackedDimensions = 10000
+
+// Config configures the tenant usage report.
+type Config struct {
+	Enabled    bool                   `yaml:"enabled"`
+	Interval   time.Duration          `yaml:"interval"`
+	Dimensions flagext.StringSliceCSV `yaml:"dimensions"`
+	Prefix     string                 `yaml:"prefix"`
+	InstanceID string                 `yaml:"instance_id"`
+}
+
+// RegisterFlags registers the flags.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	hostname, _ := os.Hostname()
+	f.BoolVar(&cfg.Enabled, "tenant-usage.enabled", false, "Write snapshots of the bytes ingested by the distributors and processed by the queries of the query frontends of each tenant to the object storage.")
+	f.DurationVar(&cfg.Interval, "tenant-usage.interval", time.Hour, "Interval at which the usage snapshots are written. Each snapshot holds the usage since the previous one.")
+	f.Var(&cfg.Dimensions, "tenant-usage.dimensions", "Comma separated list of stream labels by whose values the ingested bytes of each tenant are broken down, for instance namespace,team.")
+	f.StringVar(&cfg.Prefix, "tenant-usage.prefix", "tenant-usage/", "Prefix of the objects of the usage snapshots.")
+	f.StringVar(&cfg.InstanceID, "tenant-usage.instance-id", hostname, "Instance ID added to the name of the snapshots of this instance, which must be unique in the cluster.")
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("the tenant usage interval must be greater than 0")
+	}
+	if cfg.InstanceID == "" {
+		return errors.New("the tenant usage instance ID must be set")
+	}
+	return nil
+}
+
+type usageKey struct {
+	tenant, kind string
+	// dimensions holds the values of the dimensions, separated by a zero byte.
+	dimensions string
+}
+
+// Tracker accumulates the usage of the tenants between two snapshots.
+type Tracker struct {
+	enabled    atomic.Bool
+	dimensions []string
+
+	mtx   sync.Mutex
+	usage map[usageKey]int64
+	// Number of distinct dimension values per tenant.
+	tracked map[string]int
+}
+
+// NewTracker returns a disabled tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		usage:   map[usageKey]int64{},
+		tracked: map[string]int{},
+	}
+}
+
+// Enable starts the tracking of the usage, broken down by the values of the given stream labels.
+func (t *Tracker) Enable(dimensions []string) {
+	t.mtx.Lock()
+	t.dimensions = dimensions
+	t.mtx.Unlock()
+	t.enabled.Store(true)
+}
+
+// AddIngested accounts the entries of a stream pushed by a tenant, whose parsed labels are given. It is a no-op if
+// the tracker is nil or isn't enabled.
+func (t *Tracker) AddIngested(tenant string, ls labels.Labels, entries []logproto.Entry) {
+	if t == nil || !t.enabled.Load() {
+		return
+	}
+
+	size := 0
+	for _, e := range entries {
+		size += len(e.Line)
+	}
+	var dimensions string
+	if len(t.dimensions) > 0 {
+		values := make([]string, 0, len(t.dimensions))
+		for _, name := range t.dimensions {
+			values = append(values, ls.Get(name))
+		}
+		dimensions = strings.Join(values, "\x00")
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	key := t.keyFor(tenant, IngestedBytes, dimensions)
+	t.usage[key] += int64(size)
+	key.kind = IngestedLines
+	t.usage[key] += int64(len(entries))
+}
+
+// AddQuery accounts a query of a tenant. It is a no-op if the tracker is nil or isn't enabled.
+func (t *Tracker) AddQuery(tenant string, bytesProcessed int64) {
+	if t == nil || !t.enabled.Load() {
+		return
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.usage[usageKey{tenant: tenant, kind: QueryBytes}] += bytesProcessed
+	t.usage[usageKey{tenant: tenant, kind: Queries}]++
+}
+
+// keyFor returns the key of the usage, without the dimensions if the tenant already has too many distinct values.
+// It must be called with the lock held.
+func (t *Tracker) keyFor(tenant, kind, dimensions string) usageKey {
+	key := usageKey{tenant: tenant, kind: kind, dimensions: dimensions}
+	if dimensions == "" {
+		return key
+	}
+	if _, ok := t.usage[key]; ok {
+		return key
+	}
+	if t.tracked[tenant] >= maxTrackedDimensions {
+		key.dimensions = ""
+		return key
+	}
+	t.tracked[tenant]++
+	return key
+}
+
+// swap returns the usage accumulated since the previous call and resets it.
+func (t *Tracker) swap() map[usageKey]int64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	usage := t.usage
+	t.usage = map[usageKey]int64{}
+	t.tracked = map[string]int{}
+	return usage
+}
+
+// Reporter periodically writes the usage accumulated by a tracker to the object storage, as a CSV object per
+// interval and instance.
+type Reporter struct {
+	services.Service
+
+	cfg          Config
+	tracker      *Tracker
+	objectClient client.ObjectClient
+	logger       log.Logger
+
+	from time.Time
+}
+
+// NewReporter returns a reporter of the usage accumulated by the given tracker, which it enables when started.
+func NewReporter(cfg Config, tracker *Tracker, objectClient client.ObjectClient, logger log.Logger) *Reporter {
+	r := &Reporter{
+		cfg:          cfg,
+		tracker:      tracker,
+		objectClient: objectClient,
+		logger:       log.With(logger, "component", "tenant-usage"),
+	}
+	r.Service = services.
+		NewTimerService(cfg.Interval, r.starting, r.iteration, r.stopping).
+		WithName("tenant usage reporter")
+	return r
+}
+
+func (r *Reporter) starting(_ context.Context) error {
+	r.from = time.Now()
+	r.tracker.Enable(r.cfg.Dimensions)
+	return nil
+}
+
+func (r *Reporter) iteration(ctx context.Context) error {
+	if err := r.report(ctx, time.Now()); err != nil {
+		// The usage of the interval is lost, but the next snapshots are still written.
+		level.Error(r.logger).Log("msg", "failed to write the tenant usage snapshot", "err", err)
+	}
+	return nil
+}
+
+// stopping writes the usage accumulated since the last snapshot, so that it isn't lost on shutdown.
+func (r *Reporter) stopping(_ error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return r.report(ctx, time.Now())
+}
+
+func (r *Reporter) report(ctx context.Context, now time.Time) error {
+	from := r.from
+	r.from = now
+
+	usage := r.tracker.swap()
+	if len(usage) == 0 {
+		return nil
+	}
+	buf, err := r.encode(usage, from, now)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s%s/%d-%s.csv", r.cfg.Prefix, from.UTC().Format("2006-01-02"), from.Unix(), r.cfg.InstanceID)
+	if err := r.objectClient.PutObject(ctx, key, bytes.NewReader(buf)); err != nil {
+		return errors.Wrapf(err, "writing %s", key)
+	}
+	level.Info(r.logger).Log("msg", "wrote the tenant usage snapshot", "key", key, "rows", len(usage))
+	return nil
+}
+
+// encode writes the usage as CSV, with a column per dimension, sorted by tenant, kind and dimensions.
+func (r *Reporter) encode(usage map[usageKey]int64, from, through time.Time) ([]byte, error) {
+	keys := make([]usageKey, 0, len(usage))
+	for key := range usage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].tenant != keys[j].tenant {
+			return keys[i].tenant < keys[j].tenant
+		}
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].dimensions < keys[j].dimensions
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := append([]string{"from", "through", "instance", "tenant", "usage"}, r.cfg.Dimensions...)
+	if err := w.Write(append(header, "value")); err != nil {
+		return nil, err
+	}
+
+	fromStr, throughStr := from.UTC().Format(time.RFC3339), through.UTC().Format(time.RFC3339)
+	for _, key := range keys {
+		row := make([]string, 0, len(header)+1)
+		row = append(row, fromStr, throughStr, r.cfg.InstanceID, key.tenant, key.kind)
+		dimensions := make([]string, len(r.cfg.Dimensions))
+		if key.dimensions != "" {
+			copy(dimensions, strings.Split(key.dimensions, "\x00"))
+		}
+		row = append(row, dimensions...)
+		if err := w.Write(append(row, strconv.FormatInt(usage[key], 10))); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,110 @@
+package tenantusage
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logql/syntax"
+	"github.com/grafana/loki/pkg/storage/chunk/client/local"
+)
+
+func ingest(t *testing.T, tracker *Tracker, tenant, lbs string, lines ...string) {
+	ls, err := syntax.ParseLabels(lbs)
+	require.NoError(t, err)
+	var entries []logproto.Entry
+	for _, line := range lines {
+		entries = append(entries, logproto.Entry{Timestamp: time.Now(), Line: line})
+	}
+	tracker.AddIngested(tenant, ls, entries)
+}
+
+func TestTracker_DisabledIsNoop(t *testing.T) {
+	tracker := NewTracker()
+	ingest(t, tracker, "fake", `{app="foo"}`, "line")
+	tracker.AddQuery("fake", 100)
+	require.Empty(t, tracker.swap())
+}
+
+func TestTracker_Dimensions(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Enable([]string{"team"})
+
+	ingest(t, tracker, "a", `{app="foo", team="ops"}`, "12345", "123")
+	ingest(t, tracker, "a", `{app="bar", team="ops"}`, "1")
+	ingest(t, tracker, "a", `{app="baz"}`, "12")
+	ingest(t, tracker, "b", `{app="foo", team="dev"}`, "1234")
+	tracker.AddQuery("a", 1000)
+	tracker.AddQuery("a", 500)
+
+	require.Equal(t, map[usageKey]int64{
+		{tenant: "a", kind: IngestedBytes, dimensions: "ops"}: 9,
+		{tenant: "a", kind: IngestedLines, dimensions: "ops"}: 3,
+		{tenant: "a", kind: IngestedBytes}:                    2,
+		{tenant: "a", kind: IngestedLines}:                    1,
+		{tenant: "b", kind: IngestedBytes, dimensions: "dev"}: 4,
+		{tenant: "b", kind: IngestedLines, dimensions: "dev"}: 1,
+		{tenant: "a", kind: QueryBytes}:                       1500,
+		{tenant: "a", kind: Queries}:                          2,
+	}, tracker.swap())
+	require.Empty(t, tracker.swap())
+}
+
+func TestTracker_MaxTrackedDimensions(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Enable([]string{"team"})
+	tracker.tracked["a"] = maxTrackedDimensions
+
+	ingest(t, tracker, "a", `{team="ops"}`, "123")
+	require.Equal(t, map[usageKey]int64{
+		{tenant: "a", kind: IngestedBytes}: 3,
+		{tenant: "a", kind: IngestedLines}: 1,
+	}, tracker.swap())
+}
+
+func TestReporter(t *testing.T) {
+	dir := t.TempDir()
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: dir})
+	require.NoError(t, err)
+
+	tracker := NewTracker()
+	cfg := Config{
+		Enabled:    true,
+		Interval:   time.Hour,
+		Dimensions: []string{"namespace", "team"},
+		Prefix:     "usage/",
+		InstanceID: "distributor-1",
+	}
+	r := NewReporter(cfg, tracker, objectClient, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+
+	ingest(t, tracker, "a", `{namespace="prod", team="ops"}`, "12345")
+	tracker.AddQuery("b", 42)
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), r))
+
+	files, err := filepath.Glob(filepath.Join(dir, "usage", "*", "*-distributor-1.csv"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"from", "through", "instance", "tenant", "usage", "namespace", "team", "value"}, rows[0])
+	rows = rows[1:]
+	require.Len(t, rows, 4)
+	require.Equal(t, []string{"distributor-1", "a", IngestedBytes, "prod", "ops", "5"}, rows[0][2:])
+	require.Equal(t, []string{"distributor-1", "a", IngestedLines, "prod", "ops", "1"}, rows[1][2:])
+	require.Equal(t, []string{"distributor-1", "b", Queries, "", "", "1"}, rows[2][2:])
+	require.Equal(t, []string{"distributor-1", "b", QueryBytes, "", "", "42"}, rows[3][2:])
+}
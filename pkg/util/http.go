@@ -18,6 +18,8 @@ import (
 	"github.com/golang/snappy"
 	"github.com/opentracing/opentracing-go"
 	otlog "github.com/opentracing/opentracing-go/log"
+	protov2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"gopkg.in/yaml.v2"
 )
 
@@ -171,9 +173,13 @@ func ParseProtoReader(ctx context.Context, reader io.Reader, expectedSize, maxSi
 	// We re-implement proto.Unmarshal here as it calls XXX_Unmarshal first,
 	// which we can't override without upsetting golint.
 	req.Reset()
-	if u, ok := req.(proto.Unmarshaler); ok {
-		err = u.Unmarshal(body)
-	} else {
+	switch m := req.(type) {
+	case proto.Unmarshaler:
+		err = m.Unmarshal(body)
+	case protoreflect.ProtoMessage:
+		// Messages generated with google.golang.org/protobuf, e.g. the OTLP ones, aren't supported by gogo.
+		err = protov2.Unmarshal(body, m)
+	default:
 		err = proto.NewBuffer(body).Unmarshal(req)
 	}
 	if err != nil {
@@ -18,8 +18,18 @@ var (
 	safeQueryTags              = regexp.MustCompile("[^a-zA-Z0-9-=, ]+") // only alpha-numeric, ' ', ',', '=' and `-`
 
 	QueryQueueTimeHTTPHeader ctxKey = "X-Query-Queue-Time"
+
+	// QueryPriorityHTTPHeader is the context key of the priority header of a query, which the
+	// frontend forwards to the scheduler.
+	QueryPriorityHTTPHeader ctxKey = "X-Query-Priority"
 )
 
+// QueryPriority is the priority header of a query.
+type QueryPriority struct {
+	Header string
+	Value  string
+}
+
 func ExtractQueryTagsMiddleware() middleware.Interface {
 	return middleware.Func(func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -54,3 +64,19 @@ func ExtractQueryMetricsMiddleware() middleware.Interface {
 		})
 	})
 }
+
+// ExtractQueryPriorityMiddleware stores the value of the given priority header in the context,
+// so that the requests sent downstream keep the priority of the original request.
+func ExtractQueryPriorityMiddleware(header string) middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if header != "" {
+				if v := req.Header.Get(header); v != "" {
+					ctx := context.WithValue(req.Context(), QueryPriorityHTTPHeader, QueryPriority{Header: header, Value: v})
+					req = req.WithContext(ctx)
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
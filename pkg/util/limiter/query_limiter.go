@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/loki/pkg/ingester/client"
 	"github.com/grafana/loki/pkg/logproto"
+	"github.com/grafana/loki/pkg/logqlmodel"
 )
 
 type queryLimiterCtxKey struct{}
@@ -17,8 +18,8 @@ type queryLimiterCtxKey struct{}
 var (
 	ctxKey                    = &queryLimiterCtxKey{}
 	ErrMaxSeriesHit           = "the query hit the max number of series limit (limit: %d series)"
-	ErrMaxChunkBytesHit       = "the query hit the aggregated chunks size limit (limit: %d bytes)"
-	ErrMaxChunksPerQueryLimit = "the query hit the max number of chunks limit (limit: %d chunks)"
+	ErrMaxChunkBytesHit       = "the query hit the max bytes read limit (limit: %d bytes), reduce the time range or select fewer streams"
+	ErrMaxChunksPerQueryLimit = "the query hit the max number of chunks limit (limit: %d chunks), reduce the time range or select fewer streams"
 )
 
 type QueryLimiter struct {
@@ -86,7 +87,7 @@ func (ql *QueryLimiter) AddChunkBytes(chunkSizeInBytes int) error {
 		return nil
 	}
 	if ql.chunkBytesCount.Add(int64(chunkSizeInBytes)) > int64(ql.maxChunkBytesPerQuery) {
-		return fmt.Errorf(ErrMaxChunkBytesHit, ql.maxChunkBytesPerQuery)
+		return logqlmodel.NewLimitError(fmt.Sprintf(ErrMaxChunkBytesHit, ql.maxChunkBytesPerQuery))
 	}
 	return nil
 }
@@ -97,7 +98,7 @@ func (ql *QueryLimiter) AddChunks(count int) error {
 	}
 
 	if ql.chunkCount.Add(int64(count)) > int64(ql.maxChunksPerQuery) {
-		return fmt.Errorf(fmt.Sprintf(ErrMaxChunksPerQueryLimit, ql.maxChunksPerQuery))
+		return logqlmodel.NewLimitError(fmt.Sprintf(ErrMaxChunksPerQueryLimit, ql.maxChunksPerQuery))
 	}
 	return nil
 }
@@ -0,0 +1,156 @@
+// Package arrow writes tabular data in the Apache Arrow IPC streaming format, which dataframe libraries read
+// without parsing it. Only the few column types of the query results are supported, and no column is nullable.
+package arrow
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	apache_arrow "github.com/apache/arrow/go/v6/arrow"
+	"github.com/apache/arrow/go/v6/arrow/array"
+	"github.com/apache/arrow/go/v6/arrow/ipc"
+	"github.com/apache/arrow/go/v6/arrow/memory"
+)
+
+// ContentType is the media type of the Arrow IPC streaming format.
+const ContentType = "application/vnd.apache.arrow.stream"
+
+// Type is the type of a column.
+type Type int
+
+const (
+	// String columns hold UTF-8 strings.
+	String Type = iota
+	// Float64 columns hold double precision floating point numbers.
+	Float64
+	// TimestampMillis columns hold UTC timestamps in milliseconds since the epoch.
+	TimestampMillis
+	// TimestampNanos columns hold UTC timestamps in nanoseconds since the epoch.
+	TimestampNanos
+)
+
+func (t Type) dataType() apache_arrow.DataType {
+	switch t {
+	case String:
+		return apache_arrow.BinaryTypes.String
+	case Float64:
+		return apache_arrow.PrimitiveTypes.Float64
+	case TimestampMillis:
+		return &apache_arrow.TimestampType{Unit: apache_arrow.Millisecond, TimeZone: "UTC"}
+	default:
+		return &apache_arrow.TimestampType{Unit: apache_arrow.Nanosecond, TimeZone: "UTC"}
+	}
+}
+
+// Field is a column of the schema of the stream.
+type Field struct {
+	Name string
+	Type Type
+}
+
+// Column holds the values of a field in a record batch: []string for String fields, []float64 for Float64
+// fields and []int64 for timestamp fields.
+type Column interface{}
+
+// Writer writes a stream made of a schema followed by record batches.
+type Writer struct {
+	fields []Field
+	schema *apache_arrow.Schema
+	mem    memory.Allocator
+	w      *ipc.Writer
+}
+
+// NewWriter returns a Writer writing a stream of the given fields to w. The schema is written along with the
+// first record batch, or on Close if there is none.
+func NewWriter(w io.Writer, fields ...Field) *Writer {
+	arrowFields := make([]apache_arrow.Field, 0, len(fields))
+	for _, f := range fields {
+		arrowFields = append(arrowFields, apache_arrow.Field{Name: f.Name, Type: f.Type.dataType()})
+	}
+	schema := apache_arrow.NewSchema(arrowFields, nil)
+	mem := memory.NewGoAllocator()
+	return &Writer{
+		fields: fields,
+		schema: schema,
+		mem:    mem,
+		w:      ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(mem)),
+	}
+}
+
+// Write writes a record batch made of one column per field, all of the same length.
+func (w *Writer) Write(columns ...Column) error {
+	if len(columns) != len(w.fields) {
+		return errors.New("arrow: the number of columns doesn't match the schema")
+	}
+
+	rows := -1
+	arrays := make([]array.Interface, 0, len(columns))
+	defer func() {
+		for _, a := range arrays {
+			a.Release()
+		}
+	}()
+	for i, column := range columns {
+		a, err := w.newArray(w.fields[i], column)
+		if err != nil {
+			return err
+		}
+		arrays = append(arrays, a)
+		if rows >= 0 && a.Len() != rows {
+			return errors.New("arrow: columns of different lengths")
+		}
+		rows = a.Len()
+	}
+
+	record := array.NewRecord(w.schema, arrays, int64(rows))
+	defer record.Release()
+	return w.w.Write(record)
+}
+
+func (w *Writer) newArray(f Field, column Column) (array.Interface, error) {
+	switch values := column.(type) {
+	case []string:
+		if f.Type != String {
+			return nil, errors.New("arrow: column type doesn't match the field " + f.Name)
+		}
+		size := 0
+		for _, v := range values {
+			size += len(v)
+			// the offsets of the values are 32-bit
+			if size > math.MaxInt32 {
+				return nil, errors.New("arrow: string column too large for a single record batch")
+			}
+		}
+		b := array.NewStringBuilder(w.mem)
+		defer b.Release()
+		b.AppendValues(values, nil)
+		return b.NewArray(), nil
+	case []float64:
+		if f.Type != Float64 {
+			return nil, errors.New("arrow: column type doesn't match the field " + f.Name)
+		}
+		b := array.NewFloat64Builder(w.mem)
+		defer b.Release()
+		b.AppendValues(values, nil)
+		return b.NewArray(), nil
+	case []int64:
+		if f.Type != TimestampMillis && f.Type != TimestampNanos {
+			return nil, errors.New("arrow: column type doesn't match the field " + f.Name)
+		}
+		b := array.NewTimestampBuilder(w.mem, f.Type.dataType().(*apache_arrow.TimestampType))
+		defer b.Release()
+		b.Reserve(len(values))
+		for _, v := range values {
+			b.UnsafeAppend(apache_arrow.Timestamp(v))
+		}
+		return b.NewArray(), nil
+	default:
+		return nil, errors.New("arrow: unsupported column type")
+	}
+}
+
+// Close writes the end of the stream. It doesn't close the underlying writer.
+func (w *Writer) Close() error {
+	return w.w.Close()
+}
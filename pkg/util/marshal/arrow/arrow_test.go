@@ -0,0 +1,110 @@
+package arrow
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	apache_arrow "github.com/apache/arrow/go/v6/arrow"
+	"github.com/apache/arrow/go/v6/arrow/array"
+	"github.com/apache/arrow/go/v6/arrow/ipc"
+	"github.com/apache/arrow/go/v6/arrow/memory"
+	"github.com/stretchr/testify/require"
+)
+
+type decodedStream struct {
+	fields  []Field
+	batches [][]Column
+}
+
+// decodeStream reads the stream with the Arrow Go reader.
+func decodeStream(t *testing.T, b []byte) decodedStream {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	r, err := ipc.NewReader(bytes.NewReader(b), ipc.WithAllocator(mem))
+	require.NoError(t, err)
+	defer r.Release()
+
+	var res decodedStream
+	for _, f := range r.Schema().Fields() {
+		require.False(t, f.Nullable)
+		field := Field{Name: f.Name}
+		switch typ := f.Type.(type) {
+		case *apache_arrow.StringType:
+			field.Type = String
+		case *apache_arrow.Float64Type:
+			field.Type = Float64
+		case *apache_arrow.TimestampType:
+			require.Equal(t, "UTC", typ.TimeZone)
+			field.Type = TimestampNanos
+			if typ.Unit == apache_arrow.Millisecond {
+				field.Type = TimestampMillis
+			}
+		default:
+			t.Fatalf("unexpected type %s", f.Type)
+		}
+		res.fields = append(res.fields, field)
+	}
+
+	for r.Next() {
+		var columns []Column
+		for _, c := range r.Record().Columns() {
+			require.Zero(t, c.NullN())
+			switch c := c.(type) {
+			case *array.String:
+				values := make([]string, c.Len())
+				for i := range values {
+					values[i] = c.Value(i)
+				}
+				columns = append(columns, values)
+			case *array.Float64:
+				columns = append(columns, append([]float64{}, c.Float64Values()...))
+			case *array.Timestamp:
+				values := make([]int64, c.Len())
+				for i, v := range c.TimestampValues() {
+					values[i] = int64(v)
+				}
+				columns = append(columns, values)
+			}
+		}
+		res.batches = append(res.batches, columns)
+	}
+	require.NoError(t, r.Err())
+	return res
+}
+
+func TestWriter(t *testing.T) {
+	fields := []Field{
+		{Name: "labels", Type: String},
+		{Name: "timestamp", Type: TimestampNanos},
+		{Name: "ts_ms", Type: TimestampMillis},
+		{Name: "value", Type: Float64},
+	}
+	batches := [][]Column{
+		{[]string{`{foo="bar"}`, `{foo="bar"}`, ""}, []int64{1, 2, 3}, []int64{4, 5, 6}, []float64{0.5, math.Inf(1), -2}},
+		{[]string{}, []int64{}, []int64{}, []float64{}},
+		{[]string{"a"}, []int64{-1}, []int64{0}, []float64{1}},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, fields...)
+	for _, b := range batches {
+		require.NoError(t, w.Write(b...))
+	}
+	require.NoError(t, w.Close())
+	require.Equal(t, decodedStream{fields: fields, batches: batches}, decodeStream(t, buf.Bytes()))
+
+	// a stream without record batches still has a schema
+	buf.Reset()
+	require.NoError(t, NewWriter(&buf, fields...).Close())
+	require.Equal(t, decodedStream{fields: fields}, decodeStream(t, buf.Bytes()))
+}
+
+func TestWriter_InvalidColumns(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{}, Field{Name: "line", Type: String}, Field{Name: "value", Type: Float64})
+	require.Error(t, w.Write([]string{"a"}))
+	require.Error(t, w.Write([]float64{1}, []string{"a"}))
+	require.Error(t, w.Write([]string{"a"}, []float64{1, 2}))
+	require.NoError(t, w.Write([]string{"a"}, []float64{1}))
+}
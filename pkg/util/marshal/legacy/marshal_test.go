@@ -49,6 +49,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -68,6 +69,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -39,8 +39,9 @@ func WriteQueryResponseJSON(v logqlmodel.Result, w io.Writer) error {
 // and then writes it to the provided io.Writer.
 func WriteLabelResponseJSON(l logproto.LabelResponse, w io.Writer) error {
 	v1Response := loghttp.LabelResponse{
-		Status: "success",
-		Data:   l.GetValues(),
+		Status:        "success",
+		Data:          l.GetValues(),
+		NextPageToken: l.GetNextPageToken(),
 	}
 
 	return jsoniter.NewEncoder(w).Encode(v1Response)
@@ -69,8 +70,9 @@ func WriteTailResponseJSON(r legacy.TailResponse, c WebsocketWriter) error {
 // writes it to the provided io.Writer.
 func WriteSeriesResponseJSON(r logproto.SeriesResponse, w io.Writer) error {
 	adapter := &seriesResponseAdapter{
-		Status: "success",
-		Data:   make([]map[string]string, 0, len(r.GetSeries())),
+		Status:        "success",
+		Data:          make([]map[string]string, 0, len(r.GetSeries())),
+		NextPageToken: r.GetNextPageToken(),
 	}
 
 	for _, series := range r.GetSeries() {
@@ -83,8 +85,9 @@ func WriteSeriesResponseJSON(r logproto.SeriesResponse, w io.Writer) error {
 // This struct exists primarily because we can't specify a repeated map in proto v3.
 // Otherwise, we'd use that + gogoproto.jsontag to avoid this layer of indirection
 type seriesResponseAdapter struct {
-	Status string              `json:"status"`
-	Data   []map[string]string `json:"data"`
+	Status        string              `json:"status"`
+	Data          []map[string]string `json:"data"`
+	NextPageToken string              `json:"nextPageToken,omitempty"`
 }
 
 // WriteIndexStatsResponseJSON marshals a gatewaypb.Stats to JSON and then
@@ -92,3 +95,9 @@ type seriesResponseAdapter struct {
 func WriteIndexStatsResponseJSON(r *stats.Stats, w io.Writer) error {
 	return jsoniter.NewEncoder(w).Encode(r)
 }
+
+// WriteVolumeResponseJSON marshals a logproto.VolumeResponse to JSON and then
+// writes it to the provided io.Writer.
+func WriteVolumeResponseJSON(r *logproto.VolumeResponse, w io.Writer) error {
+	return jsoniter.NewEncoder(w).Encode(r)
+}
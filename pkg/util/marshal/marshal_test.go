@@ -55,6 +55,7 @@ var queryTests = []struct {
 							"chunksDownloadTime": 0,
 							"totalChunksRef": 0,
 							"totalChunksDownloaded": 0,
+							"totalChunksDownloadedBytes": 0,
 							"chunk" :{
 								"compressedBytes": 0,
 								"decompressedBytes": 0,
@@ -74,6 +75,7 @@ var queryTests = []struct {
 							"chunksDownloadTime": 0,
 							"totalChunksRef": 0,
 							"totalChunksDownloaded": 0,
+							"totalChunksDownloadedBytes": 0,
 							"chunk" :{
 								"compressedBytes": 0,
 								"decompressedBytes": 0,
@@ -191,6 +193,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -210,6 +213,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -344,6 +348,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -363,6 +368,7 @@ var queryTests = []struct {
 						"chunksDownloadTime": 0,
 						"totalChunksRef": 0,
 						"totalChunksDownloaded": 0,
+						"totalChunksDownloadedBytes": 0,
 						"chunk" :{
 							"compressedBytes": 0,
 							"decompressedBytes": 0,
@@ -609,6 +615,19 @@ func Test_WriteSeriesResponseJSON(t *testing.T) {
 			},
 			`{"status":"success","data":[{"a":"1","b":"2"},{"c":"3","d":"4"}]}`,
 		},
+		{
+			logproto.SeriesResponse{
+				Series: []logproto.SeriesIdentifier{
+					{
+						Labels: map[string]string{
+							"a": "1",
+						},
+					},
+				},
+				NextPageToken: "next",
+			},
+			`{"status":"success","data":[{"a":"1"}],"nextPageToken":"next"}`,
+		},
 	} {
 		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
 			var b bytes.Buffer
@@ -0,0 +1,264 @@
+// Package paging implements the pagination of the label names, label values and series APIs.
+//
+// Paged values are sorted, and the token of a page encodes the last value of the page before it.
+// This way every component holding some of the values, i.e. ingesters and index gateways, can return
+// its part of a page without keeping any state: the values after the token, up to the limit. Merging
+// the parts and keeping the first limit values results in the page of all the values.
+package paging
+
+import (
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+// Page selects at most Limit values after the ones of the pages before Token.
+// A zero Limit selects all values, an empty Token the first page.
+type Page struct {
+	Limit int
+	Token string
+}
+
+// Enabled returns whether the values are paged.
+func (p Page) Enabled() bool {
+	return p.Limit > 0 || p.Token != ""
+}
+
+// NextToken returns the token of the page following the one ending with last.
+func NextToken(last string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(last))
+}
+
+// After returns the last value of the page before token.
+func After(token string) (string, error) {
+	last, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid page token %q", token)
+	}
+	return string(last), nil
+}
+
+// Bounds returns the bounds [start, end) of the page p of n values sorted by key,
+// and the token of the next page, which is empty if the page isn't full.
+func Bounds(n int, key func(i int) string, p Page) (start, end int, next string, err error) {
+	if p.Token != "" {
+		after, err := After(p.Token)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		start = sort.Search(n, func(i int) bool { return key(i) > after })
+	}
+
+	end = n
+	if p.Limit > 0 && end-start >= p.Limit {
+		end = start + p.Limit
+		next = NextToken(key(end - 1))
+	}
+	return start, end, next, nil
+}
+
+// Strings returns the page p of the sorted and deduplicated values, and the token of the next page.
+func Strings(values []string, p Page) ([]string, string, error) {
+	if !p.Enabled() {
+		return values, "", nil
+	}
+
+	start, end, next, err := Bounds(len(values), func(i int) string { return values[i] }, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return values[start:end], next, nil
+}
+
+// Series sorts the deduplicated series by SeriesMapKey and returns their page p, and the token of the next page.
+func Series(series []logproto.SeriesIdentifier, p Page) ([]logproto.SeriesIdentifier, string, error) {
+	if !p.Enabled() {
+		return series, "", nil
+	}
+
+	keys := make([]string, len(series))
+	for i, s := range series {
+		keys[i] = SeriesMapKey(s.Labels)
+	}
+	sort.Sort(seriesByKey{series: series, keys: keys})
+
+	start, end, next, err := Bounds(len(series), func(i int) string { return keys[i] }, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return series[start:end], next, nil
+}
+
+type seriesByKey struct {
+	series []logproto.SeriesIdentifier
+	keys   []string
+}
+
+func (s seriesByKey) Len() int           { return len(s.series) }
+func (s seriesByKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s seriesByKey) Swap(i, j int) {
+	s.series[i], s.series[j] = s.series[j], s.series[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// Labels is like Series, for series given as labels, sorted by SeriesKey.
+func Labels(series []labels.Labels, p Page) ([]labels.Labels, string, error) {
+	if !p.Enabled() {
+		return series, "", nil
+	}
+
+	keys := make([]string, len(series))
+	for i, ls := range series {
+		keys[i] = SeriesKey(ls)
+	}
+	sort.Sort(labelsByKey{series: series, keys: keys})
+
+	start, end, next, err := Bounds(len(series), func(i int) string { return keys[i] }, p)
+	if err != nil {
+		return nil, "", err
+	}
+	return series[start:end], next, nil
+}
+
+type labelsByKey struct {
+	series []labels.Labels
+	keys   []string
+}
+
+func (s labelsByKey) Len() int           { return len(s.series) }
+func (s labelsByKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s labelsByKey) Swap(i, j int) {
+	s.series[i], s.series[j] = s.series[j], s.series[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// SeriesKey returns the key series are sorted by when paged.
+func SeriesKey(ls labels.Labels) string {
+	return ls.String()
+}
+
+// SeriesMapKey is like SeriesKey, for series whose labels are a map.
+func SeriesMapKey(ls map[string]string) string {
+	return SeriesKey(labels.FromMap(ls))
+}
+
+// Collector collects the values of a page as they are iterated, in any order and possibly more than once,
+// keeping only the values which are part of the page so far.
+type Collector struct {
+	page   Page
+	after  string
+	keys   keyHeap
+	values map[string]interface{}
+}
+
+// NewCollector returns a Collector of the values of the page p.
+func NewCollector(p Page) (*Collector, error) {
+	c := &Collector{page: p, values: map[string]interface{}{}}
+	if p.Token != "" {
+		after, err := After(p.Token)
+		if err != nil {
+			return nil, err
+		}
+		c.after = after
+	}
+	return c, nil
+}
+
+// Add adds the value of key, which is kept if it is part of the page so far.
+func (c *Collector) Add(key string, value interface{}) {
+	if c.page.Token != "" && key <= c.after {
+		return
+	}
+	if _, ok := c.values[key]; ok {
+		return
+	}
+	if c.page.Limit > 0 && len(c.keys) == c.page.Limit {
+		if key >= c.keys[0] {
+			return
+		}
+		delete(c.values, heap.Pop(&c.keys).(string))
+	}
+	c.values[key] = value
+	heap.Push(&c.keys, key)
+}
+
+// Values returns the values of the page sorted by key, and the token of the next page.
+func (c *Collector) Values() ([]interface{}, string) {
+	keys := make([]string, len(c.keys))
+	copy(keys, c.keys)
+	sort.Strings(keys)
+
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+
+	var next string
+	if c.page.Limit > 0 && len(keys) == c.page.Limit {
+		next = NextToken(keys[len(keys)-1])
+	}
+	return values, next
+}
+
+// keyHeap is a max-heap of keys, so that the last key of the page is the first one to go when a lower key is added.
+type keyHeap []string
+
+func (h keyHeap) Len() int            { return len(h) }
+func (h keyHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x interface{}) { *h = append(*h, x.(string)) }
+func (h *keyHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// BatchSize is the number of values sent per message by the streaming RPCs.
+const BatchSize = 1024
+
+// Batches calls f with the bounds [start, end) of the consecutive batches of n values, of at most BatchSize
+// values each. f is called once with empty bounds when there are no values, so that the last message is
+// always sent, as it carries the token of the next page.
+func Batches(n int, f func(start, end int, last bool) error) error {
+	start := 0
+	for {
+		end := start + BatchSize
+		if end > n {
+			end = n
+		}
+		if err := f(start, end, end == n); err != nil {
+			return err
+		}
+		if end == n {
+			return nil
+		}
+		start = end
+	}
+}
+
+type contextKey int
+
+const pageKey contextKey = 0
+
+// InjectPage returns a context carrying the page p of the values requested with it from the store.
+// Stores may use it to only return the values of the page, i.e. the index gateway client, while
+// the others return all the values, which are paged by the caller anyway.
+func InjectPage(ctx context.Context, p Page) context.Context {
+	if !p.Enabled() {
+		return ctx
+	}
+	return context.WithValue(ctx, pageKey, p)
+}
+
+// PageFromContext returns the page injected into ctx, if any.
+func PageFromContext(ctx context.Context) Page {
+	p, _ := ctx.Value(pageKey).(Page)
+	return p
+}
@@ -0,0 +1,181 @@
+package paging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/logproto"
+)
+
+func TestStrings(t *testing.T) {
+	values := []string{"a", "b", "c", "d", "e"}
+
+	for _, tc := range []struct {
+		desc     string
+		page     Page
+		expected []string
+		next     string
+	}{
+		{desc: "disabled", expected: values},
+		{desc: "first page", page: Page{Limit: 2}, expected: []string{"a", "b"}, next: NextToken("b")},
+		{desc: "next page", page: Page{Limit: 2, Token: NextToken("b")}, expected: []string{"c", "d"}, next: NextToken("d")},
+		{desc: "last full page", page: Page{Limit: 1, Token: NextToken("d")}, expected: []string{"e"}, next: NextToken("e")},
+		{desc: "last page", page: Page{Limit: 2, Token: NextToken("d")}, expected: []string{"e"}},
+		{desc: "past the end", page: Page{Limit: 2, Token: NextToken("e")}, expected: []string{}},
+		{desc: "token of a missing value", page: Page{Limit: 2, Token: NextToken("bb")}, expected: []string{"c", "d"}, next: NextToken("d")},
+		{desc: "no limit", page: Page{Token: NextToken("c")}, expected: []string{"d", "e"}},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, next, err := Strings(values, tc.page)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+			require.Equal(t, tc.next, next)
+		})
+	}
+
+	_, _, err := Strings(values, Page{Limit: 1, Token: "not base64!"})
+	require.Error(t, err)
+}
+
+// Merging the pages of subsets of the values and keeping the first limit values results in the page of all the values.
+func TestStrings_MergePages(t *testing.T) {
+	sets := [][]string{
+		{"a", "c", "e", "g"},
+		{"b", "c", "d"},
+		{"f", "h"},
+	}
+	all := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var pages [][]string
+	p := Page{Limit: 3}
+	for {
+		var merged []string
+		for _, set := range sets {
+			page, _, err := Strings(set, p)
+			require.NoError(t, err)
+			merged = mergeSorted(merged, page)
+		}
+		page, next, err := Strings(merged, Page{Limit: p.Limit})
+		require.NoError(t, err)
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		p.Token = next
+	}
+
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e", "f"}, {"g", "h"}}, pages)
+	var res []string
+	for _, page := range pages {
+		res = append(res, page...)
+	}
+	require.Equal(t, all, res)
+}
+
+func mergeSorted(a, b []string) []string {
+	res := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			res = append(res, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			res = append(res, b[j])
+			j++
+		default:
+			res = append(res, a[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
+
+func TestSeriesKey(t *testing.T) {
+	require.Equal(t, SeriesKey(labels.FromStrings("a", "1", "b", "2")), SeriesMapKey(map[string]string{"b": "2", "a": "1"}))
+}
+
+func TestPageFromContext(t *testing.T) {
+	require.Equal(t, Page{}, PageFromContext(context.Background()))
+	require.Equal(t, Page{}, PageFromContext(InjectPage(context.Background(), Page{})))
+
+	p := Page{Limit: 10, Token: NextToken("foo")}
+	require.Equal(t, p, PageFromContext(InjectPage(context.Background(), p)))
+}
+
+func TestSeries(t *testing.T) {
+	series := []logproto.SeriesIdentifier{
+		{Labels: map[string]string{"a": "3"}},
+		{Labels: map[string]string{"a": "1"}},
+		{Labels: map[string]string{"a": "2", "b": "1"}},
+	}
+
+	page, next, err := Series(series, Page{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []logproto.SeriesIdentifier{
+		{Labels: map[string]string{"a": "1"}},
+		{Labels: map[string]string{"a": "2", "b": "1"}},
+	}, page)
+	require.Equal(t, NextToken(`{a="2", b="1"}`), next)
+
+	page, next, err = Series(series, Page{Limit: 2, Token: next})
+	require.NoError(t, err)
+	require.Equal(t, []logproto.SeriesIdentifier{{Labels: map[string]string{"a": "3"}}}, page)
+	require.Empty(t, next)
+}
+
+func TestBatches(t *testing.T) {
+	for _, tc := range []struct {
+		n        int
+		expected [][2]int
+	}{
+		{n: 0, expected: [][2]int{{0, 0}}},
+		{n: 1, expected: [][2]int{{0, 1}}},
+		{n: BatchSize, expected: [][2]int{{0, BatchSize}}},
+		{n: BatchSize + 1, expected: [][2]int{{0, BatchSize}, {BatchSize, BatchSize + 1}}},
+	} {
+		var batches [][2]int
+		err := Batches(tc.n, func(start, end int, last bool) error {
+			require.Equal(t, end == tc.n, last)
+			batches = append(batches, [2]int{start, end})
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, batches)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	values := []string{"e", "b", "d", "a", "b", "c", "e"}
+	collect := func(p Page) ([]interface{}, string) {
+		c, err := NewCollector(p)
+		require.NoError(t, err)
+		for _, v := range values {
+			c.Add(v, v)
+		}
+		return c.Values()
+	}
+
+	page, next := collect(Page{Limit: 2})
+	require.Equal(t, []interface{}{"a", "b"}, page)
+	require.Equal(t, NextToken("b"), next)
+
+	page, next = collect(Page{Limit: 2, Token: next})
+	require.Equal(t, []interface{}{"c", "d"}, page)
+	require.Equal(t, NextToken("d"), next)
+
+	page, next = collect(Page{Limit: 2, Token: next})
+	require.Equal(t, []interface{}{"e"}, page)
+	require.Empty(t, next)
+
+	page, next = collect(Page{Token: NextToken("c")})
+	require.Equal(t, []interface{}{"d", "e"}, page)
+	require.Empty(t, next)
+
+	_, err := NewCollector(Page{Token: "!"})
+	require.Error(t, err)
+}
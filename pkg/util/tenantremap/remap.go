@@ -0,0 +1,99 @@
+// Package tenantremap rewrites the tenant IDs of the incoming requests into their canonical tenants, so that the
+// clients of renamed or merged tenants keep working with their former tenant IDs.
+package tenantremap
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
+)
+
+const tenantIDsSeparator = "|"
+
+var remappedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "loki",
+	Name:      "tenant_remapped_requests_total",
+	Help:      "The total number of requests whose tenant ID was remapped to its canonical tenant.",
+}, []string{"alias", "tenant"})
+
+// Remap returns the canonical tenants of the aliased tenant IDs. It is called for each request, so that the remap
+// table can be reloaded.
+type Remap func() map[string]string
+
+// Validate returns an error if the remap table isn't valid: both the aliases and the canonical tenants must be valid
+// tenant IDs, and the canonical tenants must not be aliases themselves.
+func Validate(remap map[string]string) error {
+	for alias, canonical := range remap {
+		if alias == "" || canonical == "" {
+			return fmt.Errorf("empty tenant ID in tenant alias %q of tenant %q", alias, canonical)
+		}
+		if err := tenant.ValidTenantID(alias); err != nil {
+			return fmt.Errorf("invalid tenant alias %q: %w", alias, err)
+		}
+		if err := tenant.ValidTenantID(canonical); err != nil {
+			return fmt.Errorf("invalid canonical tenant %q of tenant alias %q: %w", canonical, alias, err)
+		}
+		if _, ok := remap[canonical]; ok {
+			return fmt.Errorf("the canonical tenant %q of tenant alias %q is an alias itself", canonical, alias)
+		}
+	}
+	return nil
+}
+
+// Middleware rewrites the tenant ID of the authenticated requests, in their context and their X-Scope-OrgID header.
+// Each of the tenants of the multi-tenant requests is remapped.
+func Middleware(remap Remap) middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			orgID, err := user.ExtractOrgID(r.Context())
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			remapped, ok := remapOrgID(remap(), orgID)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r = r.WithContext(user.InjectOrgID(r.Context(), remapped))
+			r.Header.Set(user.OrgIDHeaderName, remapped)
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// remapOrgID returns the org ID with its tenants remapped, and whether any of them was.
+func remapOrgID(remap map[string]string, orgID string) (string, bool) {
+	if len(remap) == 0 {
+		return orgID, false
+	}
+
+	tenantIDs := strings.Split(orgID, tenantIDsSeparator)
+	remapped := make([]string, 0, len(tenantIDs))
+	seen := make(map[string]struct{}, len(tenantIDs))
+	changed := false
+	for _, id := range tenantIDs {
+		if canonical, ok := remap[id]; ok {
+			remappedRequests.WithLabelValues(id, canonical).Inc()
+			id = canonical
+			changed = true
+		}
+		// merged tenants may be queried together with their former IDs
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		remapped = append(remapped, id)
+	}
+	if !changed {
+		return orgID, false
+	}
+	return tenant.JoinTenantIDs(remapped), true
+}
@@ -0,0 +1,51 @@
+package tenantremap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+)
+
+func TestMiddleware(t *testing.T) {
+	remap := map[string]string{"old-team": "team", "other-old-team": "team"}
+	handler := Middleware(func() map[string]string { return remap }).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := user.ExtractOrgID(r.Context())
+		require.NoError(t, err)
+		require.Equal(t, orgID, r.Header.Get(user.OrgIDHeaderName))
+		_, _ = w.Write([]byte(orgID))
+	}))
+
+	before := testutil.ToFloat64(remappedRequests.WithLabelValues("old-team", "team"))
+	for _, tc := range []struct {
+		orgID, expected string
+	}{
+		{"team", "team"},
+		{"old-team", "team"},
+		{"other", "other"},
+		// the tenants of the multi-tenant requests are remapped one by one, without duplicates
+		{"old-team|other", "team|other"},
+		{"old-team|other-old-team|team", "team"},
+	} {
+		t.Run(tc.orgID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range", nil)
+			req.Header.Set(user.OrgIDHeaderName, tc.orgID)
+			req = req.WithContext(user.InjectOrgID(req.Context(), tc.orgID))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tc.expected, rec.Body.String())
+		})
+	}
+	require.Equal(t, float64(3), testutil.ToFloat64(remappedRequests.WithLabelValues("old-team", "team"))-before)
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(nil))
+	require.NoError(t, Validate(map[string]string{"a": "c", "b": "c"}))
+	require.EqualError(t, Validate(map[string]string{"a": "b", "b": "c"}), `the canonical tenant "b" of tenant alias "a" is an alias itself`)
+	require.Error(t, Validate(map[string]string{"a|b": "c"}))
+	require.Error(t, Validate(map[string]string{"a": ""}))
+}
@@ -45,6 +45,34 @@ var pushTests = []struct {
 			]
 		}`,
 	},
+	{
+		[]logproto.Stream{
+			{
+				Entries: []logproto.Entry{
+					{
+						Timestamp: time.Unix(0, 123456789012345),
+						Line:      "super line",
+						NonIndexedLabels: []logproto.LabelAdapter{
+							{Name: "trace_id", Value: "abc"},
+						},
+					},
+				},
+				Labels: `{test="test"}`,
+			},
+		},
+		`{
+			"streams": [
+				{
+					"stream": {
+						"test": "test"
+					},
+					"values":[
+						[ "123456789012345", "super line", { "trace_id": "abc" } ]
+					]
+				}
+			]
+		}`,
+	},
 }
 
 func Test_DecodePushRequest(t *testing.T) {
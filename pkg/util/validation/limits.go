@@ -694,6 +694,32 @@ func SmallestPositiveNonZeroDurationPerTenant(tenantIDs []string, f func(string)
 	return *result
 }
 
+// MultiTenantQueryAllowed checks that each of the given tenants allows querying
+// all the others, according to the supplied allowlist function, in which "*"
+// allows all the tenants. It returns a tenant and one of the others it doesn't
+// allow if not.
+func MultiTenantQueryAllowed(tenantIDs []string, allowlist func(string) []string) (tenantID, notAllowed string, ok bool) {
+	if len(tenantIDs) < 2 {
+		return "", "", true
+	}
+
+	for _, id := range tenantIDs {
+		allowed := make(map[string]struct{})
+		for _, other := range allowlist(id) {
+			allowed[other] = struct{}{}
+		}
+		if _, ok := allowed["*"]; ok {
+			continue
+		}
+		for _, other := range tenantIDs {
+			if _, ok := allowed[other]; !ok && other != id {
+				return id, other, false
+			}
+		}
+	}
+	return "", "", true
+}
+
 // MaxDurationPerTenant is returning the maximum duration per tenant. Without
 // tenants given it will return a time.Duration(0).
 func MaxDurationPerTenant(tenantIDs []string, f func(string) time.Duration) time.Duration {
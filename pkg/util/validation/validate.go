@@ -4,6 +4,9 @@ const (
 	// ErrQueryTooLong is used in chunk store, querier and query frontend.
 	ErrQueryTooLong = "the query time range exceeds the limit (query length: %s, limit: %s)"
 
+	// ErrMultiTenantQueryNotAllowed is used in querier and query frontend.
+	ErrMultiTenantQueryNotAllowed = "tenant %s can't be queried together with tenant %s, see the multi_tenant_query_allowlist limit"
+
 	// RateLimited is one of the values for the reason to discard samples.
 	// Declared here to avoid duplication in ingester and distributor.
 	RateLimited = "rate_limited"
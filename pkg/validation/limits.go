@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"regexp"
 	"strconv"
 	"time"
 
@@ -18,10 +19,12 @@ import (
 	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v2"
 
+	"github.com/grafana/loki/pkg/chunkenc"
 	"github.com/grafana/loki/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/pkg/logql/syntax"
 	ruler_config "github.com/grafana/loki/pkg/ruler/config"
 	"github.com/grafana/loki/pkg/ruler/util"
+	bucket_s3 "github.com/grafana/loki/pkg/storage/bucket/s3"
 	"github.com/grafana/loki/pkg/storage/stores/indexshipper/compactor/deletionmode"
 	"github.com/grafana/loki/pkg/util/flagext"
 	util_log "github.com/grafana/loki/pkg/util/log"
@@ -47,6 +50,9 @@ const (
 
 	defaultPerStreamRateLimit  = 3 << 20 // 3MB
 	defaultPerStreamBurstLimit = 5 * defaultPerStreamRateLimit
+
+	// MaxFlushPriority is the highest absolute value of the flush priority of a tenant.
+	MaxFlushPriority = 1000
 )
 
 // Limits describe all the limits for users; can be used to describe global default
@@ -58,9 +64,13 @@ type Limits struct {
 	IngestionRateStrategy       string           `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
 	IngestionRateMB             float64          `yaml:"ingestion_rate_mb" json:"ingestion_rate_mb"`
 	IngestionBurstSizeMB        float64          `yaml:"ingestion_burst_size_mb" json:"ingestion_burst_size_mb"`
+	IngestionBurstMaxDelay      model.Duration   `yaml:"ingestion_burst_max_delay" json:"ingestion_burst_max_delay"`
+	IngestionBurstMaxQueued     int              `yaml:"ingestion_burst_max_queued_pushes" json:"ingestion_burst_max_queued_pushes"`
+	MaxStreamRateBytes          flagext.ByteSize `yaml:"max_stream_rate_bytes" json:"max_stream_rate_bytes"`
 	MaxLabelNameLength          int              `yaml:"max_label_name_length" json:"max_label_name_length"`
 	MaxLabelValueLength         int              `yaml:"max_label_value_length" json:"max_label_value_length"`
 	MaxLabelNamesPerSeries      int              `yaml:"max_label_names_per_series" json:"max_label_names_per_series"`
+	TruncateLabels              bool             `yaml:"truncate_labels" json:"truncate_labels"`
 	RejectOldSamples            bool             `yaml:"reject_old_samples" json:"reject_old_samples"`
 	RejectOldSamplesMaxAge      model.Duration   `yaml:"reject_old_samples_max_age" json:"reject_old_samples_max_age"`
 	CreationGracePeriod         model.Duration   `yaml:"creation_grace_period" json:"creation_grace_period"`
@@ -68,32 +78,44 @@ type Limits struct {
 	MaxLineSize                 flagext.ByteSize `yaml:"max_line_size" json:"max_line_size"`
 	MaxLineSizeTruncate         bool             `yaml:"max_line_size_truncate" json:"max_line_size_truncate"`
 	IncrementDuplicateTimestamp bool             `yaml:"increment_duplicate_timestamp" json:"increment_duplicate_timestamp"`
+	AllowNonIndexedLabels       bool             `yaml:"allow_non_indexed_labels" json:"allow_non_indexed_labels"`
+	IngestionTenantShardSize    int              `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
 
 	// Ingester enforced limits.
 	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
 	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
+	MaxGlobalSeriesPerUser  int              `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	UnorderedWrites         bool             `yaml:"unordered_writes" json:"unordered_writes"`
 	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
 	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	ChunkEncoding           string           `yaml:"chunk_encoding" json:"chunk_encoding"`
+	FlushPriority           int              `yaml:"flush_priority" json:"flush_priority"`
 
 	// Querier enforced limits.
-	MaxChunksPerQuery          int            `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
-	MaxQuerySeries             int            `yaml:"max_query_series" json:"max_query_series"`
-	MaxQueryLookback           model.Duration `yaml:"max_query_lookback" json:"max_query_lookback"`
-	MaxQueryLength             model.Duration `yaml:"max_query_length" json:"max_query_length"`
-	MaxQueryParallelism        int            `yaml:"max_query_parallelism" json:"max_query_parallelism"`
-	CardinalityLimit           int            `yaml:"cardinality_limit" json:"cardinality_limit"`
-	MaxStreamsMatchersPerQuery int            `yaml:"max_streams_matchers_per_query" json:"max_streams_matchers_per_query"`
-	MaxConcurrentTailRequests  int            `yaml:"max_concurrent_tail_requests" json:"max_concurrent_tail_requests"`
-	MaxEntriesLimitPerQuery    int            `yaml:"max_entries_limit_per_query" json:"max_entries_limit_per_query"`
-	MaxCacheFreshness          model.Duration `yaml:"max_cache_freshness_per_query" json:"max_cache_freshness_per_query"`
-	MaxQueriersPerTenant       int            `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
-	QueryReadyIndexNumDays     int            `yaml:"query_ready_index_num_days" json:"query_ready_index_num_days"`
-	QueryTimeout               model.Duration `yaml:"query_timeout" json:"query_timeout"`
+	MaxChunksPerQuery          int              `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
+	MaxQueryBytesRead          flagext.ByteSize `yaml:"max_query_bytes_read" json:"max_query_bytes_read"`
+	MaxQuerySeries             int              `yaml:"max_query_series" json:"max_query_series"`
+	MaxQueryLookback           model.Duration   `yaml:"max_query_lookback" json:"max_query_lookback"`
+	MaxQueryLength             model.Duration   `yaml:"max_query_length" json:"max_query_length"`
+	MaxQueryParallelism        int              `yaml:"max_query_parallelism" json:"max_query_parallelism"`
+	CardinalityLimit           int              `yaml:"cardinality_limit" json:"cardinality_limit"`
+	MaxStreamsMatchersPerQuery int              `yaml:"max_streams_matchers_per_query" json:"max_streams_matchers_per_query"`
+	MaxConcurrentTailRequests  int              `yaml:"max_concurrent_tail_requests" json:"max_concurrent_tail_requests"`
+	MaxEntriesLimitPerQuery    int              `yaml:"max_entries_limit_per_query" json:"max_entries_limit_per_query"`
+	MaxCacheFreshness          model.Duration   `yaml:"max_cache_freshness_per_query" json:"max_cache_freshness_per_query"`
+	MaxQueriersPerTenant       int              `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
+	QueryReadyIndexNumDays     int              `yaml:"query_ready_index_num_days" json:"query_ready_index_num_days"`
+	QueryTimeout               model.Duration   `yaml:"query_timeout" json:"query_timeout"`
+
+	MultiTenantQueryAllowlist dskit_flagext.StringSliceCSV `yaml:"multi_tenant_query_allowlist" json:"multi_tenant_query_allowlist"`
 
 	// Query frontend enforced limits. The default is actually parameterized by the queryrange config.
-	QuerySplitDuration  model.Duration `yaml:"split_queries_by_interval" json:"split_queries_by_interval"`
-	MinShardingLookback model.Duration `yaml:"min_sharding_lookback" json:"min_sharding_lookback"`
+	QuerySplitDuration    model.Duration   `yaml:"split_queries_by_interval" json:"split_queries_by_interval"`
+	QuerySplitTargetBytes flagext.ByteSize `yaml:"split_queries_target_bytes" json:"split_queries_target_bytes"`
+	MinShardingLookback   model.Duration   `yaml:"min_sharding_lookback" json:"min_sharding_lookback"`
+	BlockedQueries        []BlockedQuery   `yaml:"blocked_queries,omitempty" json:"blocked_queries,omitempty"`
+	MaxQueryResponseBytes flagext.ByteSize `yaml:"max_query_response_bytes" json:"max_query_response_bytes"`
+	TruncateQueryResponse bool             `yaml:"truncate_query_response" json:"truncate_query_response"`
 
 	// Ruler defaults and limits.
 	RulerEvaluationDelay        model.Duration                   `yaml:"ruler_evaluation_delay_duration" json:"ruler_evaluation_delay_duration"`
@@ -144,6 +166,17 @@ type Limits struct {
 	RetentionPeriod model.Duration    `yaml:"retention_period" json:"retention_period"`
 	StreamRetention []StreamRetention `yaml:"retention_stream,omitempty" json:"retention_stream,omitempty"`
 
+	// Per tenant S3 server-side encryption, overriding the storage config.
+	S3SSEType                 string `yaml:"s3_sse_type" json:"s3_sse_type"`
+	S3SSEKMSKeyID             string `yaml:"s3_sse_kms_key_id" json:"s3_sse_kms_key_id"`
+	S3SSEKMSEncryptionContext string `yaml:"s3_sse_kms_encryption_context" json:"s3_sse_kms_encryption_context"`
+	S3SSECustomerKeyFile      string `yaml:"s3_sse_customer_key_file" json:"s3_sse_customer_key_file"`
+
+	// Per tenant TSDB index building, overriding the storage config.
+	TSDBOutputShards        int  `yaml:"tsdb_output_shards" json:"tsdb_output_shards"`
+	TSDBPerTenantOutput     bool `yaml:"tsdb_per_tenant_output" json:"tsdb_per_tenant_output"`
+	TSDBRetentionAwareBuild bool `yaml:"tsdb_retention_aware_build" json:"tsdb_retention_aware_build"`
+
 	// Config for overrides, convenient if it goes here.
 	PerTenantOverrideConfig string         `yaml:"per_tenant_override_config" json:"per_tenant_override_config"`
 	PerTenantOverridePeriod model.Duration `yaml:"per_tenant_override_period" json:"per_tenant_override_period"`
@@ -161,18 +194,57 @@ type StreamRetention struct {
 	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
 }
 
+// BlockedQuery matches the queries of a tenant which the query-frontend rejects: the queries equal to the pattern,
+// matching it if it is a regular expression, or whose hash is the given one. It only applies to the given types of
+// queries, or to all of them if no type is given.
+type BlockedQuery struct {
+	Pattern string                       `yaml:"pattern" json:"pattern"`
+	Regex   bool                         `yaml:"regex" json:"regex"`
+	Hash    uint32                       `yaml:"hash" json:"hash"`
+	Types   dskit_flagext.StringSliceCSV `yaml:"types" json:"types"`
+	Regexp  *regexp.Regexp               `yaml:"-" json:"-"` // populated during validation.
+}
+
+// Matches returns whether the blocked query matches the query with the given hash and type.
+func (b *BlockedQuery) Matches(query string, hash uint32, queryType string) bool {
+	if len(b.Types) > 0 {
+		found := false
+		for _, t := range b.Types {
+			found = found || t == queryType
+		}
+		if !found {
+			return false
+		}
+	}
+
+	switch {
+	case b.Hash != 0:
+		return b.Hash == hash
+	case b.Regexp != nil:
+		return b.Regexp.MatchString(query)
+	default:
+		return b.Pattern == query
+	}
+}
+
 // RegisterFlags adds the flags required to config this to the given FlagSet
 func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&l.IngestionRateStrategy, "distributor.ingestion-rate-limit-strategy", "global", "Whether the ingestion rate limit should be applied individually to each distributor instance (local), or evenly shared across the cluster (global).")
 	f.Float64Var(&l.IngestionRateMB, "distributor.ingestion-rate-limit-mb", 4, "Per-user ingestion rate limit in sample size per second. Units in MB.")
 	f.Float64Var(&l.IngestionBurstSizeMB, "distributor.ingestion-burst-size-mb", 6, "Per-user allowed ingestion burst size (in sample size). Units in MB.")
+	f.Var(&l.IngestionBurstMaxDelay, "distributor.ingestion-burst-max-delay", "Maximum time a push over the ingestion rate limit is held by a distributor, waiting for the limit to allow it, before it is rejected. Smooths the bursts of clients which don't back off. 0 to reject such pushes right away.")
+	f.IntVar(&l.IngestionBurstMaxQueued, "distributor.ingestion-burst-max-queued-pushes", 10, "Maximum number of pushes of a tenant a distributor holds at once while they wait for the ingestion rate limit, the others being rejected right away. Only used when ingestion_burst_max_delay is set.")
+	f.Var(&l.MaxStreamRateBytes, "distributor.max-stream-rate-bytes", "Maximum byte rate per second of a single stream, enforced by the distributors on the whole stream before it is sharded. With the global ingestion rate strategy, the rate is evenly shared across distributors. Also expressible in human readable forms (1MB, 256KB, etc). Default (0) means unlimited.")
 	f.Var(&l.MaxLineSize, "distributor.max-line-size", "maximum line length allowed, i.e. 100mb. Default (0) means unlimited.")
 	f.BoolVar(&l.MaxLineSizeTruncate, "distributor.max-line-size-truncate", false, "Whether to truncate lines that exceed max_line_size")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 30, "Maximum number of label names per series.")
+	f.BoolVar(&l.TruncateLabels, "validation.truncate-labels", false, "Drop the labels with a name longer than max_label_name_length, truncate the label values longer than max_label_value_length and drop the labels above max_label_names_per_series, instead of rejecting their streams. The labels after the first max_label_names_per_series ones in alphabetical order are dropped.")
 	f.BoolVar(&l.RejectOldSamples, "validation.reject-old-samples", true, "Reject old samples.")
 	f.BoolVar(&l.IncrementDuplicateTimestamp, "validation.increment-duplicate-timestamps", false, "Increment the timestamp of a log line by one nanosecond in the future from a previous entry for the same stream with the same timestamp; guarantees sort order at query time.")
+	f.BoolVar(&l.AllowNonIndexedLabels, "validation.allow-non-indexed-labels", false, "Allow log entries to carry non-indexed labels, which are stored alongside the line in chunks but not in the index. Requires unordered writes.")
+	f.IntVar(&l.IngestionTenantShardSize, "distributor.ingestion-tenant-shard-size", 0, "The number of ingesters the streams of a tenant are shuffle sharded to, 0 to use all the ingesters. With zone-aware replication the ingesters are evenly selected from each zone. Queriers only query the ingesters of the shard of the tenant within query_ingesters_within.")
 
 	_ = l.RejectOldSamplesMaxAge.Set("7d")
 	f.Var(&l.RejectOldSamplesMaxAge, "validation.reject-old-samples.max-age", "Maximum accepted sample age before rejecting.")
@@ -183,20 +255,26 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.IntVar(&l.MaxLocalStreamsPerUser, "ingester.max-streams-per-user", 0, "Maximum number of active streams per user, per ingester. 0 to disable.")
 	f.IntVar(&l.MaxGlobalStreamsPerUser, "ingester.max-global-streams-per-user", 5000, "Maximum number of active streams per user, across the cluster. 0 to disable.")
+	f.IntVar(&l.MaxGlobalSeriesPerUser, "ingester.max-global-series-per-user", 0, "Maximum number of active series per user in the TSDB index heads, across the cluster. The pushes of new streams whose series would exceed the limit are rejected until the heads are rotated. 0 to disable.")
 	f.BoolVar(&l.UnorderedWrites, "ingester.unordered-writes", true, "Allow out of order writes.")
 
 	_ = l.PerStreamRateLimit.Set(strconv.Itoa(defaultPerStreamRateLimit))
 	f.Var(&l.PerStreamRateLimit, "ingester.per-stream-rate-limit", "Maximum byte rate per second per stream, also expressible in human readable forms (1MB, 256KB, etc).")
 	_ = l.PerStreamRateLimitBurst.Set(strconv.Itoa(defaultPerStreamBurstLimit))
 	f.Var(&l.PerStreamRateLimitBurst, "ingester.per-stream-rate-limit-burst", "Maximum burst bytes per stream, also expressible in human readable forms (1MB, 256KB, etc).")
+	f.StringVar(&l.ChunkEncoding, "ingester.tenant-chunk-encoding", "", fmt.Sprintf("The algorithm to use for compressing the chunks of the tenant, overriding -ingester.chunk-encoding. (%s) Empty to use -ingester.chunk-encoding.", chunkenc.SupportedEncoding()))
+	f.IntVar(&l.FlushPriority, "ingester.flush-priority", 0, fmt.Sprintf("Priority of the chunks of the tenant in the flush queues of the ingesters, between -%d and %d. The chunks of the tenants with a higher priority are flushed first, which drains the most important tenants first when an ingester is flushed on shutdown.", MaxFlushPriority, MaxFlushPriority))
 
-	f.IntVar(&l.MaxChunksPerQuery, "store.query-chunk-limit", 2e6, "Maximum number of chunks that can be fetched in a single query.")
+	f.IntVar(&l.MaxChunksPerQuery, "store.query-chunk-limit", 2e6, "Maximum number of chunks that can be fetched from the store in a single query. When the limit is reached the query fails. 0 to disable.")
+	f.Var(&l.MaxQueryBytesRead, "querier.max-query-bytes-read", "Maximum bytes of chunks that can be fetched from the store in a single query, also expressible in human readable forms (1GB, 256MB, etc). When the limit is reached the query fails. 0 to disable.")
 
 	_ = l.MaxQueryLength.Set("721h")
 	f.Var(&l.MaxQueryLength, "store.max-query-length", "Limit to length of chunk store queries, 0 to disable.")
 	f.IntVar(&l.MaxQuerySeries, "querier.max-query-series", 500, "Limit the maximum of unique series returned by a metric query. When the limit is reached an error is returned.")
 	_ = l.QueryTimeout.Set("1m")
 	f.Var(&l.QueryTimeout, "querier.query-timeout", "Timeout when querying backends (ingesters or storage) during the execution of a query request. If a specific per-tenant timeout is used, this timeout is ignored.")
+	_ = l.MultiTenantQueryAllowlist.Set("*")
+	f.Var(&l.MultiTenantQueryAllowlist, "querier.multi-tenant-query-allowlist", "Comma-separated list of the tenants which can be queried together with the tenant in a multi-tenant query, when -querier.multi-tenant-queries-enabled is true. A multi-tenant query is only allowed if each of its tenants allows all the others. '*' allows all the tenants, and an empty list denies all multi-tenant queries including the tenant.")
 
 	_ = l.MaxQueryLookback.Set("0s")
 	f.Var(&l.MaxQueryLookback, "querier.max-query-lookback", "Limit how long back data (series and metadata) can be queried, up until <lookback> duration ago. This limit is enforced in the query-frontend, querier and ruler. If the requested time range is outside the allowed range, the request will not fail but will be manipulated to only query data within the allowed time range. 0 to disable.")
@@ -229,6 +307,9 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	_ = l.QuerySplitDuration.Set("30m")
 	f.Var(&l.QuerySplitDuration, "querier.split-queries-by-interval", "Split queries by an interval and execute in parallel, 0 disables it. This also determines how cache keys are chosen when result caching is enabled")
+	f.Var(&l.QuerySplitTargetBytes, "querier.split-queries-target-bytes", "Adapt the splits of queries by interval to the density of the data they select, as estimated by the TSDB index stats: adjacent splits selecting less than these bytes in total are merged, and splits selecting more are split further. Also expressible in human readable forms (1GB, 256MB, etc). 0 to disable.")
+	f.Var(&l.MaxQueryResponseBytes, "querier.max-query-response-bytes", "Maximum size of the merged response of the log, series and label queries split by interval, estimated from the size of the responses of their sub-queries, also expressible in human readable forms (1GB, 256MB, etc). When the limit is reached the query fails, unless -querier.truncate-query-response is enabled. 0 to disable.")
+	f.BoolVar(&l.TruncateQueryResponse, "querier.truncate-query-response", false, "Return the responses exceeding -querier.max-query-response-bytes truncated instead of failing the queries. The responses hold the results of the sub-queries merged before the limit was reached, and have the X-Loki-Response-Truncated header set.")
 
 	f.StringVar(&l.DeletionMode, "compactor.deletion-mode", "filter-and-delete", "Set the deletion mode for the user. Options are: disabled, filter-only, and filter-and-delete")
 
@@ -275,10 +356,66 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	for i, q := range l.BlockedQueries {
+		if q.Pattern == "" && q.Hash == 0 {
+			return fmt.Errorf("blocked query must have a pattern or a hash")
+		}
+		for _, t := range q.Types {
+			if t != "metric" && t != "filter" && t != "limited" {
+				return fmt.Errorf("invalid blocked query type %q, must be one of metric, filter or limited", t)
+			}
+		}
+		if q.Regex && q.Hash == 0 {
+			re, err := regexp.Compile(q.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid blocked query regex: %w", err)
+			}
+			// populate the regex during validation
+			l.BlockedQueries[i].Regexp = re
+		}
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
 
+	if l.ChunkEncoding != "" {
+		if _, err := chunkenc.ParseEncoding(l.ChunkEncoding); err != nil {
+			return err
+		}
+	}
+
+	if l.S3SSEType != "" {
+		sseCfg := bucket_s3.SSEConfig{
+			Type:                 l.S3SSEType,
+			KMSKeyID:             l.S3SSEKMSKeyID,
+			KMSEncryptionContext: l.S3SSEKMSEncryptionContext,
+			CustomerKeyFile:      l.S3SSECustomerKeyFile,
+		}
+		if err := sseCfg.Validate(); err != nil {
+			return errors.Wrap(err, "invalid S3 SSE override")
+		}
+	}
+
+	if l.TSDBOutputShards < 0 {
+		return fmt.Errorf("tsdb_output_shards must not be negative, got %d", l.TSDBOutputShards)
+	}
+	if l.TSDBOutputShards > 1 && l.TSDBOutputShards&(l.TSDBOutputShards-1) != 0 {
+		return fmt.Errorf("tsdb_output_shards must be a power of 2, got %d", l.TSDBOutputShards)
+	}
+
+	if l.IngestionBurstMaxQueued < 0 {
+		return fmt.Errorf("ingestion_burst_max_queued_pushes must not be negative, got %d", l.IngestionBurstMaxQueued)
+	}
+
+	if l.IngestionTenantShardSize < 0 {
+		return fmt.Errorf("ingestion_tenant_shard_size must not be negative, got %d", l.IngestionTenantShardSize)
+	}
+
+	if l.FlushPriority < -MaxFlushPriority || l.FlushPriority > MaxFlushPriority {
+		return fmt.Errorf("flush_priority must be between -%d and %d, got %d", MaxFlushPriority, MaxFlushPriority, l.FlushPriority)
+	}
+
 	if l.CompactorDeletionEnabled {
 		level.Warn(util_log.Logger).Log("msg", "The compactor.allow-deletes configuration option has been deprecated and will be ignored. Instead, use deletion_mode in the limits_configs to adjust deletion functionality")
 	}
@@ -347,6 +484,26 @@ func (o *Overrides) IngestionBurstSizeBytes(userID string) int {
 	return int(o.getOverridesForUser(userID).IngestionBurstSizeMB * bytesInMB)
 }
 
+// IngestionBurstMaxDelay returns how long a push over the ingestion rate limit may wait for it.
+func (o *Overrides) IngestionBurstMaxDelay(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).IngestionBurstMaxDelay)
+}
+
+// IngestionBurstMaxQueued returns how many pushes of a tenant may wait for the ingestion rate limit at once.
+func (o *Overrides) IngestionBurstMaxQueued(userID string) int {
+	return o.getOverridesForUser(userID).IngestionBurstMaxQueued
+}
+
+// IngestionTenantShardSize returns the number of ingesters the streams of a given user are shuffle sharded to.
+func (o *Overrides) IngestionTenantShardSize(userID string) int {
+	return o.getOverridesForUser(userID).IngestionTenantShardSize
+}
+
+// MaxStreamRateBytes returns the maximum byte rate per second of a single stream.
+func (o *Overrides) MaxStreamRateBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxStreamRateBytes.Val()
+}
+
 // MaxLabelNameLength returns maximum length a label name can be.
 func (o *Overrides) MaxLabelNameLength(userID string) int {
 	return o.getOverridesForUser(userID).MaxLabelNameLength
@@ -358,6 +515,12 @@ func (o *Overrides) MaxLabelValueLength(userID string) int {
 	return o.getOverridesForUser(userID).MaxLabelValueLength
 }
 
+// TruncateLabels returns whether the stream labels exceeding the label limits are dropped or truncated instead of
+// rejecting their streams.
+func (o *Overrides) TruncateLabels(userID string) bool {
+	return o.getOverridesForUser(userID).TruncateLabels
+}
+
 // MaxLabelNamesPerSeries returns maximum number of label/value pairs timeseries.
 func (o *Overrides) MaxLabelNamesPerSeries(userID string) int {
 	return o.getOverridesForUser(userID).MaxLabelNamesPerSeries
@@ -392,11 +555,22 @@ func (o *Overrides) MaxGlobalStreamsPerUser(userID string) int {
 	return o.getOverridesForUser(userID).MaxGlobalStreamsPerUser
 }
 
+// MaxGlobalSeriesPerUser returns the maximum number of series a user is allowed to have in the TSDB index heads
+// across the cluster.
+func (o *Overrides) MaxGlobalSeriesPerUser(userID string) int {
+	return o.getOverridesForUser(userID).MaxGlobalSeriesPerUser
+}
+
 // MaxChunksPerQuery returns the maximum number of chunks allowed per query.
 func (o *Overrides) MaxChunksPerQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxChunksPerQuery
 }
 
+// MaxQueryBytesRead returns the maximum bytes of chunks fetched per query.
+func (o *Overrides) MaxQueryBytesRead(userID string) int {
+	return o.getOverridesForUser(userID).MaxQueryBytesRead.Val()
+}
+
 // MaxQueryLength returns the limit of the length (in time) of a query.
 func (o *Overrides) MaxQueryLength(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxQueryLength)
@@ -452,6 +626,21 @@ func (o *Overrides) QuerySplitDuration(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).QuerySplitDuration)
 }
 
+// QuerySplitTargetBytes returns the tenant specific bytes the query frontend targets per split of a query.
+func (o *Overrides) QuerySplitTargetBytes(userID string) int {
+	return o.getOverridesForUser(userID).QuerySplitTargetBytes.Val()
+}
+
+// MaxQueryResponseBytes returns the maximum size of the responses merged by the query frontend.
+func (o *Overrides) MaxQueryResponseBytes(userID string) int {
+	return o.getOverridesForUser(userID).MaxQueryResponseBytes.Val()
+}
+
+// TruncateQueryResponse returns whether the query frontend truncates the responses exceeding their maximum size.
+func (o *Overrides) TruncateQueryResponse(userID string) bool {
+	return o.getOverridesForUser(userID).TruncateQueryResponse
+}
+
 // MaxConcurrentTailRequests returns the limit to number of concurrent tail requests.
 func (o *Overrides) MaxConcurrentTailRequests(userID string) int {
 	return o.getOverridesForUser(userID).MaxConcurrentTailRequests
@@ -476,6 +665,11 @@ func (o *Overrides) QueryTimeout(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).QueryTimeout)
 }
 
+// MultiTenantQueryAllowlist returns the tenants which can be queried together with the tenant.
+func (o *Overrides) MultiTenantQueryAllowlist(userID string) []string {
+	return o.getOverridesForUser(userID).MultiTenantQueryAllowlist
+}
+
 func (o *Overrides) MaxCacheFreshness(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxCacheFreshness)
 }
@@ -602,6 +796,11 @@ func (o *Overrides) RulerRemoteWriteConfig(userID string, id string) *config.Rem
 	return nil
 }
 
+// RulerRemoteWriteConfigs returns the remote-write configurations of all the remote clients of a given user.
+func (o *Overrides) RulerRemoteWriteConfigs(userID string) map[string]config.RemoteWriteConfig {
+	return o.getOverridesForUser(userID).RulerRemoteWriteConfig
+}
+
 // RetentionPeriod returns the retention period for a given user.
 func (o *Overrides) RetentionPeriod(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).RetentionPeriod)
@@ -612,10 +811,61 @@ func (o *Overrides) StreamRetention(userID string) []StreamRetention {
 	return o.getOverridesForUser(userID).StreamRetention
 }
 
+// BlockedQueries returns the queries the query-frontend rejects for a given user.
+func (o *Overrides) BlockedQueries(userID string) []BlockedQuery {
+	return o.getOverridesForUser(userID).BlockedQueries
+}
+
+// TSDBOutputShards returns the number of shards the TSDBs holding the series of a given user are split into,
+// or 0 to use the storage config.
+func (o *Overrides) TSDBOutputShards(userID string) int {
+	return o.getOverridesForUser(userID).TSDBOutputShards
+}
+
+// TSDBPerTenantOutput returns whether the series of a given user are built into per tenant TSDBs.
+func (o *Overrides) TSDBPerTenantOutput(userID string) bool {
+	return o.getOverridesForUser(userID).TSDBPerTenantOutput
+}
+
+// TSDBRetentionAwareBuild returns whether the expired chunks of a given user are skipped when building TSDBs.
+func (o *Overrides) TSDBRetentionAwareBuild(userID string) bool {
+	return o.getOverridesForUser(userID).TSDBRetentionAwareBuild
+}
+
+// S3SSEType returns the per-tenant S3 SSE type.
+func (o *Overrides) S3SSEType(userID string) string {
+	return o.getOverridesForUser(userID).S3SSEType
+}
+
+// S3SSEKMSKeyID returns the per-tenant S3 SSE-KMS key id.
+func (o *Overrides) S3SSEKMSKeyID(userID string) string {
+	return o.getOverridesForUser(userID).S3SSEKMSKeyID
+}
+
+// S3SSEKMSEncryptionContext returns the per-tenant S3 SSE-KMS encryption context.
+func (o *Overrides) S3SSEKMSEncryptionContext(userID string) string {
+	return o.getOverridesForUser(userID).S3SSEKMSEncryptionContext
+}
+
+// S3SSECustomerKeyFile returns the path of the per-tenant S3 SSE-C customer key file.
+func (o *Overrides) S3SSECustomerKeyFile(userID string) string {
+	return o.getOverridesForUser(userID).S3SSECustomerKeyFile
+}
+
 func (o *Overrides) UnorderedWrites(userID string) bool {
 	return o.getOverridesForUser(userID).UnorderedWrites
 }
 
+// ChunkEncoding returns the chunk encoding overriding the ingester's one for a given user, empty if none.
+func (o *Overrides) ChunkEncoding(userID string) string {
+	return o.getOverridesForUser(userID).ChunkEncoding
+}
+
+// FlushPriority returns the priority of the chunks of a given user in the flush queues of the ingesters.
+func (o *Overrides) FlushPriority(userID string) int {
+	return o.getOverridesForUser(userID).FlushPriority
+}
+
 func (o *Overrides) DeletionMode(userID string) string {
 	return o.getOverridesForUser(userID).DeletionMode
 }
@@ -641,6 +891,10 @@ func (o *Overrides) IncrementDuplicateTimestamps(userID string) bool {
 	return o.getOverridesForUser(userID).IncrementDuplicateTimestamp
 }
 
+func (o *Overrides) AllowNonIndexedLabels(userID string) bool {
+	return o.getOverridesForUser(userID).AllowNonIndexedLabels
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if o.tenantLimits != nil {
 		l := o.tenantLimits.TenantLimits(userID)
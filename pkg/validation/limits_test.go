@@ -291,4 +291,88 @@ func TestLimitsValidation(t *testing.T) {
 		limits := Limits{DeletionMode: tc.mode}
 		require.True(t, errors.Is(limits.Validate(), tc.expected))
 	}
+
+	for _, tc := range []struct {
+		encoding string
+		valid    bool
+	}{
+		{encoding: "", valid: true},
+		{encoding: "zstd", valid: true},
+		{encoding: "snappy", valid: true},
+		{encoding: "something-else", valid: false},
+	} {
+		limits := Limits{DeletionMode: "disabled", ChunkEncoding: tc.encoding}
+		if tc.valid {
+			require.NoError(t, limits.Validate())
+		} else {
+			require.Error(t, limits.Validate())
+		}
+	}
+	for _, tc := range []struct {
+		shards int
+		valid  bool
+	}{
+		{shards: 0, valid: true},
+		{shards: 1, valid: true},
+		{shards: 16, valid: true},
+		{shards: 12, valid: false},
+		{shards: -2, valid: false},
+	} {
+		limits := Limits{DeletionMode: "disabled", TSDBOutputShards: tc.shards}
+		if tc.valid {
+			require.NoError(t, limits.Validate())
+		} else {
+			require.Error(t, limits.Validate())
+		}
+	}
+}
+
+func TestBlockedQueries(t *testing.T) {
+	for _, tc := range []struct {
+		blocked BlockedQuery
+		valid   bool
+	}{
+		{blocked: BlockedQuery{Pattern: `{app="foo"}`}, valid: true},
+		{blocked: BlockedQuery{Pattern: `.*foo.*`, Regex: true, Types: []string{"metric", "filter"}}, valid: true},
+		{blocked: BlockedQuery{Hash: 42}, valid: true},
+		{blocked: BlockedQuery{}, valid: false},
+		{blocked: BlockedQuery{Pattern: `(`, Regex: true}, valid: false},
+		{blocked: BlockedQuery{Pattern: `{app="foo"}`, Types: []string{"labels"}}, valid: false},
+	} {
+		limits := Limits{DeletionMode: "disabled", BlockedQueries: []BlockedQuery{tc.blocked}}
+		if tc.valid {
+			require.NoError(t, limits.Validate())
+		} else {
+			require.Error(t, limits.Validate())
+		}
+	}
+
+	var limits Limits
+	require.NoError(t, yaml.Unmarshal([]byte(`
+deletion_mode: disabled
+blocked_queries:
+- pattern: '{app="foo"}'
+- pattern: 'rate\(.*\[1d\]\)'
+  regex: true
+  types: metric
+- hash: 42
+`), &limits))
+	require.NoError(t, limits.Validate())
+
+	for _, tc := range []struct {
+		query     string
+		hash      uint32
+		queryType string
+		blocked   []bool
+	}{
+		{query: `{app="foo"}`, queryType: "limited", blocked: []bool{true, false, false}},
+		{query: `{app="foo"} |= "bar"`, queryType: "filter", blocked: []bool{false, false, false}},
+		{query: `sum(rate({app="foo"}[1d]))`, queryType: "metric", blocked: []bool{false, true, false}},
+		{query: `sum(rate({app="foo"}[1d]))`, queryType: "filter", blocked: []bool{false, false, false}},
+		{query: `{app="bar"}`, hash: 42, queryType: "limited", blocked: []bool{false, false, true}},
+	} {
+		for i, b := range limits.BlockedQueries {
+			require.Equal(t, tc.blocked[i], b.Matches(tc.query, tc.hash, tc.queryType), "%s matching %s", b.Pattern, tc.query)
+		}
+	}
 }
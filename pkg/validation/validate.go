@@ -2,6 +2,7 @@ package validation
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -28,11 +29,18 @@ const (
 	// because the limit of active streams has been reached.
 	StreamLimit         = "stream_limit"
 	StreamLimitErrorMsg = "Maximum active stream limit exceeded, reduce the number of active streams (reduce labels or reduce label values), or contact your Loki administrator to see if the limit can be increased"
+	// SeriesLimit is a reason for discarding lines when we can't create a new stream
+	// because the limit of series in the TSDB index head has been reached.
+	SeriesLimit         = "series_limit"
+	SeriesLimitErrorMsg = "Maximum series limit exceeded, reduce the number of series (reduce labels or reduce label values), or contact your Loki administrator to see if the limit can be increased"
 	// StreamRateLimit is a reason for discarding lines when the streams own rate limit is hit
 	// rather than the overall ingestion rate limit.
 	StreamRateLimit = "per_stream_rate_limit"
-	OutOfOrder      = "out_of_order"
-	TooFarBehind    = "too_far_behind"
+	// MaxStreamRate is a reason for discarding lines when a stream exceeds the
+	// max_stream_rate_bytes limit enforced by the distributors.
+	MaxStreamRate = "max_stream_rate"
+	OutOfOrder    = "out_of_order"
+	TooFarBehind  = "too_far_behind"
 	// GreaterThanMaxSampleAge is a reason for discarding log lines which are older than the current time - `reject_old_samples_max_age`
 	GreaterThanMaxSampleAge         = "greater_than_max_sample_age"
 	GreaterThanMaxSampleAgeErrorMsg = "entry for stream '%s' has timestamp too old: %v, oldest acceptable timestamp is: %v"
@@ -51,6 +59,11 @@ const (
 	// DuplicateLabelNames is a reason for discarding a log line which has duplicate label names
 	DuplicateLabelNames         = "duplicate_label_names"
 	DuplicateLabelNamesErrorMsg = "stream '%s' has duplicate label name: '%s'"
+	// DisallowedNonIndexedLabels is a reason for discarding a log line which has non-indexed labels
+	// while they are not enabled for the tenant, or which has invalid non-indexed labels.
+	DisallowedNonIndexedLabels         = "disallowed_non_indexed_labels"
+	DisallowedNonIndexedLabelsErrorMsg = "entry for stream '%s' has non-indexed labels, but they are not enabled for this tenant"
+	InvalidNonIndexedLabelsErrorMsg    = "entry for stream '%s' has invalid non-indexed label: '%s'"
 )
 
 type ErrStreamRateLimit struct {
@@ -66,6 +79,32 @@ func (e *ErrStreamRateLimit) Error() string {
 		e.Bytes.String())
 }
 
+// ErrMaxStreamRate is returned by the distributors when streams of a push request
+// exceed their max_stream_rate_bytes limit. It identifies the offending streams.
+type ErrMaxStreamRate struct {
+	Tenant  string              `json:"tenant"`
+	Limit   int                 `json:"limit_bytes_per_second"`
+	Streams []RateLimitedStream `json:"streams"`
+}
+
+// RateLimitedStream is a stream whose entries were discarded because of a rate limit.
+type RateLimitedStream struct {
+	Labels string `json:"labels"`
+	Lines  int    `json:"lines"`
+	Bytes  int    `json:"bytes"`
+}
+
+func (e *ErrMaxStreamRate) Error() string {
+	streams := make([]string, 0, len(e.Streams))
+	for _, s := range e.Streams {
+		streams = append(streams, s.Labels)
+	}
+	return fmt.Sprintf("Per stream rate limit exceeded for user %s (limit: %s/sec) for streams %s, reduce the volume of these streams or contact your Loki administrator to see if the limit can be increased",
+		e.Tenant,
+		flagext.ByteSize(e.Limit).String(),
+		strings.Join(streams, ", "))
+}
+
 // MutatedSamples is a metric of the total number of lines mutated, by reason.
 var MutatedSamples = promauto.NewCounterVec(
 	prometheus.CounterOpts{
@@ -76,6 +115,16 @@ var MutatedSamples = promauto.NewCounterVec(
 	[]string{ReasonLabel, "truncated"},
 )
 
+// MutatedLabels is a metric of the total number of stream labels dropped or truncated, by reason.
+var MutatedLabels = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "loki",
+		Name:      "mutated_labels_total",
+		Help:      "The total number of stream labels that have been dropped or truncated instead of rejecting their streams.",
+	},
+	[]string{ReasonLabel, "truncated"},
+)
+
 // MutatedBytes is a metric of the total mutated bytes, by reason.
 var MutatedBytes = promauto.NewCounterVec(
 	prometheus.CounterOpts{